@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/pkg/cloudclient"
+)
+
+// readBlueprint reads the blueprint in TOML format at `path`.
+func readBlueprint(path string) (*blueprint.Blueprint, error) {
+	var bp blueprint.Blueprint
+	_, err := toml.DecodeFile(path, &bp)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read blueprint: %v", err)
+	}
+	return &bp, nil
+}
+
+// blueprintCustomizations converts the parts of `bp` which the cloud API
+// supports into customizations of a compose request: its packages, modules
+// without streams, groups and users. Other customizations can't be requested
+// from the cloud API, and are reported as errors instead of being dropped
+// silently.
+func blueprintCustomizations(bp *blueprint.Blueprint) (*cloudclient.Customizations, error) {
+	var customizations cloudclient.Customizations
+
+	var packages []string
+	for _, pkg := range bp.Packages {
+		packages = append(packages, pkg.ToNameVersion())
+	}
+	for _, module := range bp.Modules {
+		if _, ok := module.ModuleStream(); ok {
+			return nil, fmt.Errorf("module %s: module streams are not supported by the cloud API", module.Name)
+		}
+		packages = append(packages, module.ToNameVersion())
+	}
+	for _, group := range bp.Groups {
+		packages = append(packages, "@"+group.Name)
+	}
+	if len(packages) > 0 {
+		customizations.Packages = &packages
+	}
+
+	if bp.Customizations == nil {
+		return &customizations, nil
+	}
+
+	rest := *bp.Customizations
+	rest.User = nil
+	if !reflect.DeepEqual(rest, blueprint.Customizations{}) {
+		return nil, errors.New("the cloud API only supports the user customizations of blueprints")
+	}
+
+	var users []cloudclient.User
+	for _, u := range bp.Customizations.User {
+		if u.Password != nil {
+			return nil, fmt.Errorf("user %s: passwords are not supported by the cloud API, use keys instead", u.Name)
+		}
+		if u.Description != nil || u.Home != nil || u.Shell != nil {
+			return nil, fmt.Errorf("user %s: descriptions, home directories and shells are not supported by the cloud API", u.Name)
+		}
+
+		user := cloudclient.User{
+			Name:       u.Name,
+			Uid:        u.UID,
+			Gid:        u.GID,
+			ExpireDate: u.ExpireDate,
+		}
+		keys := u.Keys
+		if u.Key != nil {
+			keys = append([]string{*u.Key}, keys...)
+		}
+		if len(keys) > 0 {
+			user.Keys = &keys
+		}
+		if len(u.Groups) > 0 {
+			groups := u.Groups
+			user.Groups = &groups
+		}
+		if u.Locked {
+			locked := true
+			user.Locked = &locked
+		}
+		if u.ForcePasswordReset {
+			reset := true
+			user.ForcePasswordReset = &reset
+		}
+		users = append(users, user)
+	}
+	if len(users) > 0 {
+		customizations.Users = &users
+	}
+
+	return &customizations, nil
+}
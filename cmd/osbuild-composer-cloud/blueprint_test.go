@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/pkg/cloudclient"
+)
+
+func TestBlueprintCustomizations(t *testing.T) {
+	key := "ssh-ed25519 AAAA first"
+	locked := true
+	bp := blueprint.Blueprint{
+		Packages: []blueprint.Package{{Name: "tmux", Version: "3.1"}, {Name: "vim"}},
+		Modules:  []blueprint.Package{{Name: "nodejs", Version: "*"}},
+		Groups:   []blueprint.Group{{Name: "core"}},
+		Customizations: &blueprint.Customizations{
+			User: []blueprint.UserCustomization{{
+				Name:   "admin",
+				Key:    &key,
+				Keys:   []string{"ssh-ed25519 AAAA second"},
+				Groups: []string{"wheel"},
+				Locked: true,
+			}},
+		},
+	}
+
+	customizations, err := blueprintCustomizations(&bp)
+	require.NoError(t, err)
+	require.Equal(t, []string{"tmux-3.1", "vim", "nodejs", "@core"}, *customizations.Packages)
+	require.Equal(t, []cloudclient.User{{
+		Name:   "admin",
+		Keys:   &[]string{"ssh-ed25519 AAAA first", "ssh-ed25519 AAAA second"},
+		Groups: &[]string{"wheel"},
+		Locked: &locked,
+	}}, *customizations.Users)
+
+	customizations, err = blueprintCustomizations(&blueprint.Blueprint{})
+	require.NoError(t, err)
+	require.Equal(t, &cloudclient.Customizations{}, customizations)
+}
+
+func TestBlueprintCustomizationsUnsupported(t *testing.T) {
+	value := "example"
+	_, err := blueprintCustomizations(&blueprint.Blueprint{
+		Customizations: &blueprint.Customizations{Hostname: &value},
+	})
+	require.Error(t, err)
+
+	_, err = blueprintCustomizations(&blueprint.Blueprint{
+		Modules: []blueprint.Package{{Name: "nodejs:14"}},
+	})
+	require.Error(t, err)
+
+	_, err = blueprintCustomizations(&blueprint.Blueprint{
+		Customizations: &blueprint.Customizations{
+			User: []blueprint.UserCustomization{{Name: "admin", Password: &value}},
+		},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,490 @@
+// osbuild-composer-cloud is a command line client of version 2 of the cloud
+// API of osbuild-composer, for administering a remote composer. It talks to
+// composer over TLS, and authenticates either with a client certificate or
+// with OAuth.
+//
+// Usage:
+//
+//	osbuild-composer-cloud [options] <command> [arguments]
+//
+// The options can also be set with environment variables: COMPOSER_URL,
+// COMPOSER_CA_CERT, COMPOSER_CLIENT_CERT, COMPOSER_CLIENT_KEY,
+// COMPOSER_TOKEN, COMPOSER_OFFLINE_TOKEN and COMPOSER_OAUTH_URL.
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/osbuild/osbuild-composer/pkg/cloudclient"
+)
+
+const usage = `Usage: osbuild-composer-cloud [options] <command> [arguments]
+
+Commands:
+  distros                       list the distributions and architectures
+  compose [options] REQUEST     start the compose described by the JSON file REQUEST
+  status ID                     show the status of a compose
+  wait ID                       wait until a compose has finished
+  retry [-wait] ID              build a failed compose again from its manifests
+  list [options]                list the composes
+  download [options] ID KIND    download the image, log, manifests, metadata,
+                                sbom or provenance of a compose
+
+Options:
+`
+
+type options struct {
+	url          string
+	caCert       string
+	clientCert   string
+	clientKey    string
+	token        string
+	offlineToken string
+	oauthURL     string
+	clientID     string
+}
+
+func main() {
+	opts := options{}
+	flags := flag.NewFlagSet("osbuild-composer-cloud", flag.ExitOnError)
+	flags.StringVar(&opts.url, "url", os.Getenv("COMPOSER_URL"), "URL of the cloud API, such as https://composer.example.com/api/image-builder-composer/v2")
+	flags.StringVar(&opts.caCert, "ca", os.Getenv("COMPOSER_CA_CERT"), "file with the CA certificate of composer (default: the system's CAs)")
+	flags.StringVar(&opts.clientCert, "cert", os.Getenv("COMPOSER_CLIENT_CERT"), "file with the client certificate to authenticate with")
+	flags.StringVar(&opts.clientKey, "key", os.Getenv("COMPOSER_CLIENT_KEY"), "file with the key of the client certificate")
+	flags.StringVar(&opts.token, "token", os.Getenv("COMPOSER_TOKEN"), "access token to authenticate with")
+	flags.StringVar(&opts.offlineToken, "offline-token", os.Getenv("COMPOSER_OFFLINE_TOKEN"), "file with an offline token, which is exchanged for access tokens at -oauth-url")
+	flags.StringVar(&opts.oauthURL, "oauth-url", os.Getenv("COMPOSER_OAUTH_URL"), "URL of the token endpoint of the OAuth server")
+	flags.StringVar(&opts.clientID, "client-id", "rhsm-api", "OAuth client ID to refresh the offline token with")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	// stop waiting for composes on ^C
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	client, err := newClient(ctx, &opts)
+	if err != nil {
+		fail(err)
+	}
+
+	args := flags.Args()[1:]
+	switch flags.Arg(0) {
+	case "distros":
+		err = distros(ctx, client)
+	case "compose":
+		err = compose(ctx, client, args)
+	case "status":
+		err = status(ctx, client, args)
+	case "wait":
+		err = wait(ctx, client, args)
+	case "retry":
+		err = retry(ctx, client, args)
+	case "list":
+		err = list(ctx, client, args)
+	case "download":
+		err = download(ctx, client, args)
+	default:
+		err = fmt.Errorf("unknown command: %s", flags.Arg(0))
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "osbuild-composer-cloud: %v\n", err)
+	os.Exit(1)
+}
+
+// newClient returns a client of the cloud API at `opts.url`, which
+// authenticates with the client certificate, the access token, or the
+// offline token of `opts`.
+func newClient(ctx context.Context, opts *options) (*cloudclient.Composer, error) {
+	if opts.url == "" {
+		return nil, errors.New("the URL of composer is not set, pass -url or set COMPOSER_URL")
+	}
+
+	tlsConfig := &tls.Config{}
+	if opts.caCert != "" {
+		caCertPEM, err := ioutil.ReadFile(opts.caCert)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCertPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.caCert)
+		}
+	}
+	if opts.clientCert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.clientCert, opts.clientKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+
+	var clientOpts []cloudclient.ClientOption
+	switch {
+	case opts.token != "" && opts.offlineToken != "":
+		return nil, errors.New("pass either an access token or an offline token, not both")
+	case opts.token != "":
+		clientOpts = append(clientOpts, cloudclient.WithBearerToken(opts.token))
+	case opts.offlineToken != "":
+		if opts.oauthURL == "" {
+			return nil, errors.New("the OAuth URL must be set together with the offline token")
+		}
+		token, err := ioutil.ReadFile(opts.offlineToken)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read offline token: %v", err)
+		}
+		config := oauth2.Config{
+			ClientID: opts.clientID,
+			Endpoint: oauth2.Endpoint{TokenURL: opts.oauthURL},
+		}
+		// access tokens are refreshed with the same TLS configuration
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+		source := config.TokenSource(ctx, &oauth2.Token{RefreshToken: strings.TrimSpace(string(token))})
+		httpClient = oauth2.NewClient(ctx, source)
+	}
+	clientOpts = append(clientOpts, cloudclient.WithHTTPClient(httpClient))
+
+	client, err := cloudclient.New(strings.TrimSuffix(opts.url, "/"), clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	client.PollInterval = 5 * time.Second
+	return client, nil
+}
+
+func distros(ctx context.Context, client *cloudclient.Composer) error {
+	response, err := client.ListDistrosWithResponse(ctx)
+	if err != nil {
+		return err
+	}
+	if response.JSON200 == nil {
+		return cloudclient.ResponseError(response.HTTPResponse, response.Body)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "DISTRIBUTION\tARCHITECTURES")
+	for _, d := range *response.JSON200 {
+		fmt.Fprintf(w, "%s\t%s\n", d.Name, strings.Join(d.Architectures, ", "))
+	}
+	return w.Flush()
+}
+
+func compose(ctx context.Context, client *cloudclient.Composer, args []string) error {
+	flags := flag.NewFlagSet("compose", flag.ExitOnError)
+	blueprintPath := flags.String("blueprint", "", "TOML file with a blueprint, whose packages and users are added to the request")
+	waitForCompose := flags.Bool("wait", false, "wait until the compose has finished")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("usage: compose [-blueprint BLUEPRINT] [-wait] REQUEST")
+	}
+
+	data, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var request cloudclient.ComposeRequest
+	err = json.Unmarshal(data, &request)
+	if err != nil {
+		return fmt.Errorf("cannot parse compose request: %v", err)
+	}
+
+	if *blueprintPath != "" {
+		bp, err := readBlueprint(*blueprintPath)
+		if err != nil {
+			return err
+		}
+		customizations, err := blueprintCustomizations(bp)
+		if err != nil {
+			return err
+		}
+		if request.Customizations == nil {
+			request.Customizations = &cloudclient.Customizations{}
+		}
+		if request.Customizations.Packages != nil || request.Customizations.Users != nil {
+			return errors.New("the compose request already has packages or users, which would be replaced by the blueprint")
+		}
+		request.Customizations.Packages = customizations.Packages
+		request.Customizations.Users = customizations.Users
+	}
+
+	id, err := client.StartCompose(ctx, request)
+	if err != nil {
+		return err
+	}
+	fmt.Println(id)
+
+	if *waitForCompose {
+		return wait(ctx, client, []string{id})
+	}
+	return nil
+}
+
+func status(ctx context.Context, client *cloudclient.Composer, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: status ID")
+	}
+
+	s, err := client.Status(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printJSON(os.Stdout, s)
+}
+
+func wait(ctx context.Context, client *cloudclient.Composer, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: wait ID")
+	}
+
+	s, err := client.WaitForCompose(ctx, args[0])
+	if s != nil {
+		if e := printJSON(os.Stdout, s); e != nil {
+			return e
+		}
+	}
+	return err
+}
+
+func retry(ctx context.Context, client *cloudclient.Composer, args []string) error {
+	flags := flag.NewFlagSet("retry", flag.ExitOnError)
+	waitForCompose := flags.Bool("wait", false, "wait until the new compose has finished")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("usage: retry [-wait] ID")
+	}
+
+	// not retried on errors, a retry starts a new compose each time
+	response, err := client.ComposeRetryWithResponse(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if response.JSON201 == nil {
+		return cloudclient.ResponseError(response.HTTPResponse, response.Body)
+	}
+	fmt.Println(response.JSON201.Id)
+
+	if *waitForCompose {
+		return wait(ctx, client, []string{response.JSON201.Id})
+	}
+	return nil
+}
+
+func list(ctx context.Context, client *cloudclient.Composer, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	status := flags.String("status", "", "list only finished or unfinished composes")
+	offset := flags.Int("offset", 0, "number of composes to skip")
+	limit := flags.Int("limit", 0, "maximum number of composes to list (default: all)")
+	_ = flags.Parse(args)
+	if flags.NArg() != 0 {
+		return errors.New("usage: list [-status finished|unfinished] [-offset N] [-limit N]")
+	}
+
+	params := cloudclient.ListComposesParams{
+		Offset: offset,
+	}
+	if *status != "" {
+		params.Status = status
+	}
+	if *limit > 0 {
+		params.Limit = limit
+	}
+
+	response, err := client.ListComposesWithResponse(ctx, &params)
+	if err != nil {
+		return err
+	}
+	if response.JSON200 == nil {
+		return cloudclient.ResponseError(response.HTTPResponse, response.Body)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tCREATED")
+	for _, c := range *response.JSON200 {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Id, c.Status, c.CreatedAt.Local().Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func download(ctx context.Context, client *cloudclient.Composer, args []string) error {
+	flags := flag.NewFlagSet("download", flag.ExitOnError)
+	output := flags.String("o", "", "file to write to (default: standard output)")
+	follow := flags.Bool("follow", false, "keep printing the log of a running compose until it has finished")
+	sbomFormat := flags.String("format", "", "format of the sbom")
+	_ = flags.Parse(args)
+	if flags.NArg() != 2 {
+		return errors.New("usage: download [-o FILE] [-follow] [-format FORMAT] ID image|log|manifests|metadata|sbom|provenance")
+	}
+	id := flags.Arg(0)
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	var response *http.Response
+	var body []byte
+	var result interface{}
+	switch flags.Arg(1) {
+	case "image":
+		return downloadImage(ctx, client, id, out)
+	case "log":
+		return downloadLog(ctx, client, id, *follow, out)
+	case "manifests":
+		r, err := client.ComposeManifestsWithResponse(ctx, id)
+		if err != nil {
+			return err
+		}
+		response, body, result = r.HTTPResponse, r.Body, r.JSON200
+	case "metadata":
+		r, err := client.ComposeMetadataWithResponse(ctx, id)
+		if err != nil {
+			return err
+		}
+		response, body, result = r.HTTPResponse, r.Body, r.JSON200
+	case "sbom":
+		params := &cloudclient.ComposeSbomParams{}
+		if *sbomFormat != "" {
+			params.Format = sbomFormat
+		}
+		r, err := client.ComposeSbomWithResponse(ctx, id, params)
+		if err != nil {
+			return err
+		}
+		response, body = r.HTTPResponse, r.Body
+	case "provenance":
+		r, err := client.ComposeProvenanceWithResponse(ctx, id)
+		if err != nil {
+			return err
+		}
+		response, body, result = r.HTTPResponse, r.Body, r.JSON200
+	default:
+		return fmt.Errorf("cannot download %s, only image, log, manifests, metadata, sbom and provenance", flags.Arg(1))
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return cloudclient.ResponseError(response, body)
+	}
+	if result != nil {
+		return printJSON(out, result)
+	}
+	_, err := out.Write(body)
+	return err
+}
+
+// downloadImage writes the image of the compose `id`, which must have had a
+// local upload request, to `out`. It is checked against the digest composer
+// sends along, if there is one.
+func downloadImage(ctx context.Context, client *cloudclient.Composer, id string, out io.Writer) error {
+	response, err := client.ComposeDownload(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(response.Body)
+		return cloudclient.ResponseError(response, body)
+	}
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, h), response.Body)
+	if err != nil {
+		return err
+	}
+
+	if digest := response.Header.Get("Digest"); strings.HasPrefix(digest, "sha-256=") {
+		expected := strings.TrimPrefix(digest, "sha-256=")
+		if actual := base64.StdEncoding.EncodeToString(h.Sum(nil)); actual != expected {
+			return fmt.Errorf("the digest of the image is %s, but composer sent %s", actual, expected)
+		}
+	}
+	return nil
+}
+
+// downloadLog writes the log of the compose `id` to `out`. With `follow`, it
+// keeps requesting the rest of the log of a running compose until it has
+// finished. Composer returns the complete log of finished composes instead of
+// the rest of it, which is only written if none of the log was streamed.
+func downloadLog(ctx context.Context, client *cloudclient.Composer, id string, follow bool, out io.Writer) error {
+	params := &cloudclient.ComposeLogParams{}
+	for {
+		response, err := client.ComposeLogWithResponse(ctx, id, params)
+		if err != nil {
+			return err
+		}
+		if response.JSON200 == nil {
+			return cloudclient.ResponseError(response.HTTPResponse, response.Body)
+		}
+		if response.JSON200.Finished && params.Offset != nil && *params.Offset > 0 {
+			return nil
+		}
+
+		_, err = io.WriteString(out, response.JSON200.Log)
+		if err != nil {
+			return err
+		}
+		if !follow || response.JSON200.Finished {
+			return nil
+		}
+		offset := response.JSON200.Offset
+		params.Offset = &offset
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(client.PollInterval):
+		}
+	}
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
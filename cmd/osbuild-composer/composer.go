@@ -1,29 +1,49 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"path"
+	"strings"
+	"time"
 
+	"github.com/osbuild/osbuild-composer/internal/audit"
 	"github.com/osbuild/osbuild-composer/internal/cloudapi"
 	"github.com/osbuild/osbuild-composer/internal/common"
 	"github.com/osbuild/osbuild-composer/internal/distroregistry"
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+	"github.com/osbuild/osbuild-composer/internal/jobqueue/dbjobqueue"
 	"github.com/osbuild/osbuild-composer/internal/jobqueue/fsjobqueue"
 	"github.com/osbuild/osbuild-composer/internal/kojiapi"
+	"github.com/osbuild/osbuild-composer/internal/oidc"
+	"github.com/osbuild/osbuild-composer/internal/ostree"
+	"github.com/osbuild/osbuild-composer/internal/rbac"
 	"github.com/osbuild/osbuild-composer/internal/reporegistry"
+	"github.com/osbuild/osbuild-composer/internal/rhsm"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/secrets"
+	"github.com/osbuild/osbuild-composer/internal/sentry"
+	"github.com/osbuild/osbuild-composer/internal/signing"
 	"github.com/osbuild/osbuild-composer/internal/store"
+	"github.com/osbuild/osbuild-composer/internal/tracing"
 	"github.com/osbuild/osbuild-composer/internal/weldr"
 	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/amqpbroker"
 )
 
+// How often commits of finished composes are imported into the OSTree
+// repository.
+const ostreeRepoSyncInterval = 30 * time.Second
+
 type Composer struct {
 	config   *ComposerConfigFile
 	stateDir string
@@ -38,7 +58,24 @@ type Composer struct {
 	api     *cloudapi.Server
 	koji    *kojiapi.Server
 
-	weldrListener, localWorkerListener, workerListener, apiListener net.Listener
+	// Validates bearer tokens of clients of the composer API, if set
+	apiTokens *oidc.Validator
+
+	// Restricts the access to all APIs, if set
+	policy *rbac.Policy
+
+	// Records changes made via the APIs, if set
+	auditLog *audit.Log
+
+	// Traces Cloud API requests and jobs, if set
+	tracer *tracing.Tracer
+
+	reporter *sentry.Client
+
+	ostreeRepos *ostree.RepoServer
+	ostreeRepo  *ostree.Repo
+
+	weldrListener, localWorkerListener, workerListener, apiListener, ostreeReposListener net.Listener
 }
 
 func NewComposer(config *ComposerConfigFile, stateDir, cacheDir string, logger *log.Logger) (*Composer, error) {
@@ -59,16 +96,185 @@ func NewComposer(config *ComposerConfigFile, stateDir, cacheDir string, logger *
 		return nil, err
 	}
 
-	c.distros = distroregistry.NewDefault()
-
-	c.rpm = rpmmd.NewRPMMD(path.Join(c.cacheDir, "rpmmd"), "/usr/libexec/osbuild-composer/dnf-json")
+	c.distros, err = distroregistry.NewDefaultWithDefinitions(distroDefinitions...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load distro definitions: %v", err)
+	}
 
-	jobs, err := fsjobqueue.New(queueDir)
+	entitlements := rhsm.NewEntitlements(c.config.RHSM.EntitlementDir, c.config.RHSM.CACert)
+	c.rpm = rpmmd.NewRPMMDWithEntitlements(path.Join(c.cacheDir, "rpmmd"), "/usr/libexec/osbuild-composer/dnf-json", entitlements)
+
+	var jobs jobqueue.JobQueue
+	switch c.config.JobQueue.Backend {
+	case "", "fs":
+		jobs, err = fsjobqueue.New(queueDir)
+	case "postgres":
+		// connection parameters are read from the PG* environment variables
+		jobs, err = dbjobqueue.New("")
+	default:
+		err = fmt.Errorf("unknown backend '%s'", c.config.JobQueue.Backend)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("cannot create jobqueue: %v", err)
 	}
 
-	c.workers = worker.NewServer(c.logger, jobs, artifactsDir, c.config.WorkerAPI.IdentityFilter)
+	instanceID := c.config.WorkerAPI.InstanceID
+	if instanceID == "" {
+		instanceID, err = os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine instance id: %v", err)
+		}
+	}
+
+	var heartbeatTimeout time.Duration
+	if c.config.WorkerAPI.HeartbeatTimeout != "" {
+		heartbeatTimeout, err = time.ParseDuration(c.config.WorkerAPI.HeartbeatTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid heartbeat timeout: %v", err)
+		}
+	}
+
+	var transientRetryBackoff time.Duration
+	if c.config.WorkerAPI.TransientRetryBackoff != "" {
+		transientRetryBackoff, err = time.ParseDuration(c.config.WorkerAPI.TransientRetryBackoff)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transient retry backoff: %v", err)
+		}
+	}
+
+	jobTimeouts := make(map[string]time.Duration)
+	for jobType, timeout := range c.config.WorkerAPI.JobTimeouts {
+		jobTimeouts[jobType], err = time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout of %s jobs: %v", jobType, err)
+		}
+	}
+
+	switch c.config.WorkerAPI.Scheduling {
+	case "", worker.SchedulingFIFO, worker.SchedulingFair:
+	default:
+		return nil, fmt.Errorf("unknown scheduling policy '%s'", c.config.WorkerAPI.Scheduling)
+	}
+
+	var artifactMaxAge time.Duration
+	if c.config.WorkerAPI.ArtifactRetention.MaxAge != "" {
+		artifactMaxAge, err = time.ParseDuration(c.config.WorkerAPI.ArtifactRetention.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximum age of artifacts: %v", err)
+		}
+	}
+
+	// Publish jobs to a message broker for the configured architectures,
+	// if there is one. Workers not subscribed to it can still request jobs.
+	var broker worker.JobBroker
+	var brokerJobTypes []string
+	if c.config.WorkerAPI.Broker.URL != "" {
+		broker, err = amqpbroker.New(c.config.WorkerAPI.Broker.URL)
+		if err != nil {
+			return nil, fmt.Errorf("cannot connect to the message broker: %v", err)
+		}
+
+		brokerJobTypes = []string{"koji-init", "koji-finalize", "depsolve", "manifest-id-only"}
+		for _, arch := range c.config.WorkerAPI.Broker.Arches {
+			brokerJobTypes = append(brokerJobTypes, worker.ArchJobType("osbuild", arch), worker.ArchJobType("osbuild-koji", arch))
+		}
+	}
+
+	var secretsProvider worker.SecretsProvider
+	if c.config.Vault.Address != "" {
+		token, err := ioutil.ReadFile(c.config.Vault.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read vault token: %v", err)
+		}
+		client := secrets.NewVaultClient(c.config.Vault.Address, strings.TrimSpace(string(token)))
+		manager := secrets.NewManager(client, c.config.Vault.Credentials)
+		go manager.Run(context.Background())
+		secretsProvider = manager
+	}
+
+	// Remote workers connect via an identity-checking proxy if there is
+	// an identity filter
+	var clientCerts *worker.ClientCertConfig
+	if len(c.config.WorkerAPI.IdentityFilter) == 0 && c.config.Worker.ClientCertAuth {
+		roots, err := loadCertPool(c.config.Worker.CA)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load worker CA: %v", err)
+		}
+		clientCerts = &worker.ClientCertConfig{
+			CAs:            roots,
+			AllowedDomains: c.config.Worker.AllowedDomains,
+			CRLFiles:       c.config.Worker.CRLs,
+			OCSP:           c.config.Worker.OCSP,
+		}
+	}
+
+	switch c.config.Audit.Sink {
+	case "":
+	case "file":
+		c.auditLog, err = audit.OpenFile(c.config.Audit.Path)
+	case "syslog":
+		c.auditLog, err = audit.OpenSyslog("osbuild-composer-audit")
+	default:
+		err = fmt.Errorf("unknown sink '%s'", c.config.Audit.Sink)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open audit log: %v", err)
+	}
+
+	if c.config.Tracing.Endpoint != "" {
+		c.tracer, err = tracing.NewTracer("osbuild-composer", c.config.Tracing.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("cannot set up tracing: %v", err)
+		}
+	}
+
+	if c.config.Sentry.DSN != "" {
+		c.reporter, err = sentry.NewClient(c.config.Sentry.DSN, map[string]string{"service": "osbuild-composer"})
+		if err != nil {
+			return nil, fmt.Errorf("cannot set up error reporting: %v", err)
+		}
+	}
+
+	c.policy, err = c.accessPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	workerTokens, err := c.tokenValidator(c.config.WorkerAPI.AllowedClientIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	c.workers = worker.NewServer(c.logger, jobs, worker.Config{
+		ArtifactsDir:          artifactsDir,
+		ArtifactMaxAge:        artifactMaxAge,
+		ArtifactMaxSize:       c.config.WorkerAPI.ArtifactRetention.MaxSize,
+		IdentityFilter:        c.config.WorkerAPI.IdentityFilter,
+		TokenValidator:        workerTokens,
+		ClientCerts:           clientCerts,
+		AccessPolicy:          c.policy,
+		AuditLog:              c.auditLog,
+		Tracer:                c.tracer,
+		ErrorReporter:         c.reporter,
+		PriorityClasses:       c.config.WorkerAPI.PriorityClasses,
+		InstanceID:            instanceID,
+		HeartbeatTimeout:      heartbeatTimeout,
+		FailStaleJobs:         c.config.WorkerAPI.FailStaleJobs,
+		MaxJobFailures:        c.config.WorkerAPI.MaxJobFailures,
+		TransientRetries:      c.config.WorkerAPI.TransientRetries,
+		TransientRetryBackoff: transientRetryBackoff,
+		JobTimeouts:           jobTimeouts,
+		TenantQuota: worker.TenantQuota{
+			MaxConcurrent: c.config.WorkerAPI.TenantQuota.MaxConcurrent,
+			MaxQueued:     c.config.WorkerAPI.TenantQuota.MaxQueued,
+		},
+		Scheduling:          c.config.WorkerAPI.Scheduling,
+		MaxConcurrentBuilds: c.config.WorkerAPI.MaxConcurrentBuilds,
+		Broker:              broker,
+		BrokerJobTypes:      brokerJobTypes,
+		Secrets:             secretsProvider,
+		RateLimit:           c.config.WorkerAPI.RateLimit.limiterConfig(),
+	})
 
 	return &c, nil
 }
@@ -97,19 +303,75 @@ func (c *Composer) InitWeldr(repoPaths []string, weldrListener net.Listener) err
 		return fmt.Errorf("loaded repository definitions don't contain any for the host distro/arch: %v", err)
 	}
 
-	store := store.New(&c.stateDir, arch, c.logger)
+	var s store.Store
+	switch c.config.Store.Backend {
+	case "", "fs":
+		s, err = store.New(&c.stateDir, arch, c.logger)
+	case "postgres":
+		// connection parameters are read from the PG* environment variables
+		s, err = store.NewDB("", arch)
+	default:
+		err = fmt.Errorf("unknown backend '%s'", c.config.Store.Backend)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot create store: %v", err)
+	}
 	compatOutputDir := path.Join(c.stateDir, "outputs")
 
-	c.weldr = weldr.New(c.rpm, arch, hostDistro, rr, c.logger, store, c.workers, compatOutputDir)
+	c.weldr = weldr.New(c.rpm, arch, hostDistro, rr, c.logger, s, c.workers, compatOutputDir)
+	if c.policy != nil {
+		c.weldr.SetAccessPolicy(c.policy)
+	}
+	c.weldr.SetAuditLog(c.auditLog)
+	c.weldr.SetErrorReporter(c.reporter)
+
+	if sync := c.config.WeldrAPI.BlueprintSync; sync.Dir != "" {
+		if sync.GitURL != "" {
+			// keep the blueprints of the last checkout when the
+			// repository can't be reached
+			err = weldr.FetchBlueprints(sync.GitURL, sync.GitRef, sync.Dir)
+			if err != nil {
+				log.Printf("Error fetching blueprints from %s: %v", sync.GitURL, err)
+			}
+		}
+		err = c.weldr.SyncBlueprints(sync.Dir)
+		if err != nil {
+			return err
+		}
+	}
 
 	c.weldrListener = weldrListener
 
 	return nil
 }
 
-func (c *Composer) InitAPI(cert, key string, l net.Listener) error {
-	c.api = cloudapi.NewServer(c.workers, c.rpm, c.distros)
+func (c *Composer) InitAPI(cert, key string, repoPaths []string, l net.Listener) error {
+	var err error
+	var signer *signing.Signer
+	if c.config.ComposerAPI.SigningKey != "" {
+		signer, err = signing.LoadSigner(c.config.ComposerAPI.SigningKey)
+		if err != nil {
+			return fmt.Errorf("cannot load signing key: %v", err)
+		}
+	}
+
+	c.apiTokens, err = c.tokenValidator(c.config.ComposerAPI.AllowedClientIDs)
+	if err != nil {
+		return err
+	}
+
+	rr, err := reporegistry.New(repoPaths)
+	if err != nil {
+		return fmt.Errorf("error loading repository definitions: %v", err)
+	}
+
+	c.api = cloudapi.NewServer(c.workers, c.rpm, c.distros, signer)
+	c.api.SetRepoRegistry(rr)
 	c.koji = kojiapi.NewServer(c.logger, c.workers, c.rpm, c.distros)
+	c.api.SetAuditLog(c.auditLog)
+	c.api.SetTracer(c.tracer)
+	c.api.SetRateLimit(c.workers.NewRateLimiter(c.config.ComposerAPI.RateLimit.limiterConfig(), "cloud"))
+	c.koji.SetAuditLog(c.auditLog)
 
 	if len(c.config.ComposerAPI.IdentityFilter) > 0 {
 		c.apiListener = l
@@ -130,6 +392,62 @@ func (c *Composer) InitAPI(cert, key string, l net.Listener) error {
 	return nil
 }
 
+// InitOSTreeRepos serves the repositories of OSTree commits built through the
+// weldr API, so that they can be used as parents of subsequent commits. The
+// repository of each compose is available at `/{composeId}/`, and a
+// repository collecting all of them at `/repo/`.
+func (c *Composer) InitOSTreeRepos(l net.Listener) error {
+	if c.weldr == nil {
+		return errors.New("the OSTree repository server requires the weldr API")
+	}
+
+	reposDir := path.Join(c.cacheDir, "ostree-repos")
+	err := os.MkdirAll(reposDir, 0700)
+	if err != nil {
+		return fmt.Errorf("cannot create OSTree repository cache: %v", err)
+	}
+
+	repoDir, err := c.ensureStateDirectory("ostree", 0755)
+	if err != nil {
+		return err
+	}
+
+	c.ostreeRepo, err = ostree.InitRepo(repoDir)
+	if err != nil {
+		return err
+	}
+
+	c.ostreeRepos = ostree.NewRepoServer(c.weldr.OpenOSTreeCommit, reposDir)
+	c.ostreeReposListener = l
+
+	return nil
+}
+
+// Imports the commits of finished composes into the OSTree repository. Never
+// returns.
+func (c *Composer) syncOSTreeRepo() {
+	for {
+		for _, id := range c.weldr.OSTreeCommits() {
+			if c.ostreeRepo.Imported(id) {
+				continue
+			}
+
+			archive, err := c.weldr.OpenOSTreeCommit(id)
+			if err == nil {
+				err = c.ostreeRepo.Import(id, archive)
+				if closer, ok := archive.(io.Closer); ok {
+					closer.Close()
+				}
+			}
+			if err != nil {
+				log.Printf("Error importing OSTree commit of compose %s: %v", id, err)
+			}
+		}
+
+		time.Sleep(ostreeRepoSyncInterval)
+	}
+}
+
 func (c *Composer) InitLocalWorker(l net.Listener) {
 	c.localWorkerListener = l
 }
@@ -143,6 +461,8 @@ func (c *Composer) InitRemoteWorkers(cert, key string, l net.Listener) error {
 			ServerKeyFile:  key,
 			ServerCertFile: cert,
 			AllowedDomains: c.config.Worker.AllowedDomains,
+			// the worker server verifies them itself
+			RequestClientCerts: c.config.Worker.ClientCertAuth,
 		})
 		if err != nil {
 			return fmt.Errorf("Error creating TLS configuration for remote worker API: %v", err)
@@ -171,7 +491,7 @@ func (c *Composer) Start() error {
 		go func() {
 			s := &http.Server{
 				ErrorLog: c.logger,
-				Handler:  c.workers.Handler(),
+				Handler:  c.reporter.Middleware(c.workers.Handler()),
 			}
 			err := s.Serve(c.localWorkerListener)
 			if err != nil {
@@ -184,7 +504,7 @@ func (c *Composer) Start() error {
 		go func() {
 			s := &http.Server{
 				ErrorLog: c.logger,
-				Handler:  c.workers.Handler(),
+				Handler:  c.reporter.Middleware(c.workers.Handler()),
 			}
 			err := s.Serve(c.workerListener)
 			if err != nil {
@@ -196,6 +516,7 @@ func (c *Composer) Start() error {
 	if c.apiListener != nil {
 		go func() {
 			const apiRoute = "/api/composer/v1"
+			const apiV2Route = "/api/image-builder-composer/v2"
 			const kojiRoute = "/api/composer-koji/v1"
 
 			mux := http.NewServeMux()
@@ -203,12 +524,13 @@ func (c *Composer) Start() error {
 			// Add a "/" here, because http.ServeMux expects the
 			// trailing slash for rooted subtrees, whereas the
 			// handler functions don't.
-			mux.Handle(apiRoute+"/", c.api.Handler(apiRoute, c.config.ComposerAPI.IdentityFilter))
+			mux.Handle(apiRoute+"/", c.api.Handler(apiRoute, c.config.ComposerAPI.IdentityFilter, c.apiTokens, c.policy))
+			mux.Handle(apiV2Route+"/", c.api.HandlerV2(apiV2Route, c.config.ComposerAPI.IdentityFilter, c.apiTokens, c.policy))
 			mux.Handle(kojiRoute+"/", c.koji.Handler(kojiRoute))
 
 			s := &http.Server{
 				ErrorLog: c.logger,
-				Handler:  mux,
+				Handler:  c.reporter.Middleware(mux),
 			}
 
 			err := s.Serve(c.apiListener)
@@ -218,7 +540,28 @@ func (c *Composer) Start() error {
 		}()
 	}
 
+	if c.ostreeReposListener != nil {
+		go c.syncOSTreeRepo()
+
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/repo/", http.StripPrefix("/repo", c.ostreeRepo.Handler()))
+			mux.Handle("/", c.ostreeRepos)
+
+			s := &http.Server{
+				ErrorLog: c.logger,
+				Handler:  mux,
+			}
+			err := s.Serve(c.ostreeReposListener)
+			if err != nil {
+				panic(err)
+			}
+		}()
+	}
+
 	if c.weldrListener != nil {
+		go c.weldr.RunSchedules(context.Background())
+
 		go func() {
 			err := c.weldr.Serve(c.weldrListener)
 			if err != nil {
@@ -231,6 +574,64 @@ func (c *Composer) Start() error {
 	select {}
 }
 
+// tokenValidator returns a validator for bearer tokens of `clientIDs`, or nil
+// if there are none.
+func (c *Composer) tokenValidator(clientIDs []string) (*oidc.Validator, error) {
+	if len(clientIDs) == 0 {
+		return nil, nil
+	}
+	if c.config.OIDC.Issuer == "" {
+		return nil, errors.New("allowed_client_ids requires an OIDC issuer")
+	}
+
+	return oidc.NewValidator(oidc.Config{
+		Issuer:           c.config.OIDC.Issuer,
+		JWKSURL:          c.config.OIDC.JWKSURL,
+		Audience:         c.config.OIDC.Audience,
+		AllowedClientIDs: clientIDs,
+		TenantClaim:      c.config.OIDC.TenantClaim,
+		AllowedTenants:   c.config.OIDC.AllowedTenants,
+		RolesClaim:       c.config.OIDC.RolesClaim,
+	}), nil
+}
+
+// accessPolicy returns the policy configured in the [rbac] section, or nil if
+// it is empty.
+func (c *Composer) accessPolicy() (*rbac.Policy, error) {
+	config := c.config.RBAC
+	if len(config.Default) == 0 && len(config.Users) == 0 && len(config.Groups) == 0 && len(config.Clients) == 0 {
+		return nil, nil
+	}
+
+	parseMap := func(m map[string][]string) (map[string]rbac.Roles, error) {
+		roles := make(map[string]rbac.Roles, len(m))
+		for name, names := range m {
+			r, err := rbac.ParseRoles(names)
+			if err != nil {
+				return nil, fmt.Errorf("invalid roles of '%s': %v", name, err)
+			}
+			roles[name] = r
+		}
+		return roles, nil
+	}
+
+	var policy rbac.Policy
+	var err error
+	if policy.Default, err = rbac.ParseRoles(config.Default); err != nil {
+		return nil, fmt.Errorf("invalid default roles: %v", err)
+	}
+	if policy.Users, err = parseMap(config.Users); err != nil {
+		return nil, err
+	}
+	if policy.Groups, err = parseMap(config.Groups); err != nil {
+		return nil, err
+	}
+	if policy.Clients, err = parseMap(config.Clients); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
 func (c *Composer) ensureStateDirectory(name string, perm os.FileMode) (string, error) {
 	d := path.Join(c.stateDir, name)
 
@@ -250,28 +651,30 @@ type connectionConfig struct {
 	ServerKeyFile  string
 	ServerCertFile string
 	AllowedDomains []string
+
+	// Only request client certificates, without verifying them during
+	// the handshake. The handler must verify them.
+	RequestClientCerts bool
 }
 
 func createTLSConfig(c *connectionConfig) (*tls.Config, error) {
-	var roots *x509.CertPool
-
-	if c.CACertFile != "" {
-		caCertPEM, err := ioutil.ReadFile(c.CACertFile)
-		if err != nil {
-			return nil, err
-		}
+	cert, err := tls.LoadX509KeyPair(c.ServerCertFile, c.ServerKeyFile)
+	if err != nil {
+		return nil, err
+	}
 
-		roots = x509.NewCertPool()
-		ok := roots.AppendCertsFromPEM(caCertPEM)
-		if !ok {
-			panic("failed to parse root certificate")
-		}
+	if c.RequestClientCerts {
+		return &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientAuth:   tls.RequireAnyClientCert,
+		}, nil
 	}
 
-	cert, err := tls.LoadX509KeyPair(c.ServerCertFile, c.ServerKeyFile)
+	roots, err := loadCertPool(c.CACertFile)
 	if err != nil {
 		return nil, err
 	}
+
 	return &tls.Config{
 		Certificates: []tls.Certificate{cert},
 		ClientAuth:   tls.RequireAndVerifyClientCert,
@@ -289,3 +692,23 @@ func createTLSConfig(c *connectionConfig) (*tls.Config, error) {
 		},
 	}, nil
 }
+
+// loadCertPool returns a pool with the certificates in `caCertFile`, or nil
+// to use the CAs trusted by the host if it is empty.
+func loadCertPool(caCertFile string) (*x509.CertPool, error) {
+	if caCertFile == "" {
+		return nil, nil
+	}
+
+	caCertPEM, err := ioutil.ReadFile(caCertFile)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := x509.NewCertPool()
+	ok := roots.AppendCertsFromPEM(caCertPEM)
+	if !ok {
+		return nil, errors.New("failed to parse root certificate")
+	}
+	return roots, nil
+}
@@ -4,6 +4,8 @@ import (
 	"io"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/osbuild/osbuild-composer/internal/ratelimit"
 )
 
 type ComposerConfigFile struct {
@@ -14,15 +16,160 @@ type ComposerConfigFile struct {
 	Worker struct {
 		AllowedDomains []string `toml:"allowed_domains"`
 		CA             string   `toml:"ca"`
+		// Verify client certificates in the worker API instead of
+		// during the TLS handshake, which records the identity of
+		// workers and checks whether their certificates were revoked
+		ClientCertAuth bool     `toml:"client_cert_auth"`
+		CRLs           []string `toml:"crls"`
+		OCSP           bool     `toml:"ocsp"`
 	} `toml:"worker"`
+	JobQueue struct {
+		Backend string `toml:"backend"`
+	} `toml:"job_queue"`
+	// Where blueprints, composes and sources of the weldr API are kept:
+	// in the state directory ("fs") or in PostgreSQL ("postgres")
+	Store struct {
+		Backend string `toml:"backend"`
+	} `toml:"store"`
+	// Credentials of upload targets are read from HashiCorp Vault when
+	// jobs are handed to workers, instead of being stored in the
+	// configuration of the workers
+	Vault struct {
+		Address   string `toml:"address"`
+		TokenFile string `toml:"token_file"`
+		// Vault paths of the credentials of "aws", "azure", and "gcp"
+		Credentials map[string]string `toml:"credentials"`
+	} `toml:"vault"`
+	// Entitlement certificates for repositories on cdn.redhat.com,
+	// subscription-manager's by default
+	RHSM struct {
+		EntitlementDir string `toml:"entitlement_dir"`
+		CACert         string `toml:"ca_cert"`
+	} `toml:"rhsm"`
+	// OpenID Connect provider which issues the bearer tokens of clients of
+	// the composer and worker APIs
+	OIDC struct {
+		Issuer         string   `toml:"issuer"`
+		JWKSURL        string   `toml:"jwks_url"`
+		Audience       string   `toml:"audience"`
+		TenantClaim    string   `toml:"tenant_claim"`
+		AllowedTenants []string `toml:"allowed_tenants"`
+		// Claim which lists the roles of a client, see [rbac]
+		RolesClaim string `toml:"roles_claim"`
+	} `toml:"oidc"`
+	// Roles of the clients of all APIs: "submit-compose",
+	// "read-any-compose", "admin", and "worker". Access is not restricted
+	// unless any roles are configured.
+	RBAC struct {
+		// Roles of everyone, including unidentified clients
+		Default []string `toml:"default"`
+		// Roles of local users of the weldr API
+		Users  map[string][]string `toml:"users"`
+		Groups map[string][]string `toml:"groups"`
+		// Roles of clients of the HTTP APIs, by OIDC client id or
+		// subject, account number, or worker certificate identity
+		Clients map[string][]string `toml:"clients"`
+	} `toml:"rbac"`
+	// Changes made via the APIs are recorded in the audit log, which is
+	// either a "file" at `path` or sent to "syslog"
+	Audit struct {
+		Sink string `toml:"sink"`
+		Path string `toml:"path"`
+	} `toml:"audit"`
+	// OTLP/HTTP endpoint of an OpenTelemetry collector which receives
+	// the spans of Cloud API requests and of the jobs they enqueue
+	Tracing struct {
+		Endpoint string `toml:"endpoint"`
+	} `toml:"tracing"`
+	// DSN of a Sentry or GlitchTip project, to which panics and failed
+	// jobs are reported
+	Sentry struct {
+		DSN string `toml:"dsn"`
+	} `toml:"sentry"`
+	// Log entries are written to stderr in "text" (logfmt) or "json"
+	// format, at "debug", "info", "warn", or "error" level
+	Log struct {
+		Format string `toml:"format"`
+		Level  string `toml:"level"`
+	} `toml:"log"`
+	WeldrAPI struct {
+		// Blueprints are committed from the TOML and JSON files in
+		// `dir` when composer starts, after checking out `git_ref` of
+		// the repository at `git_url` into it, if it is set
+		BlueprintSync struct {
+			Dir    string `toml:"dir"`
+			GitURL string `toml:"git_url"`
+			GitRef string `toml:"git_ref"`
+		} `toml:"blueprint_sync"`
+	} `toml:"weldr_api"`
 	ComposerAPI struct {
 		IdentityFilter []string `toml:"identity_filter"`
+		// Clients which may authenticate with bearer tokens
+		AllowedClientIDs []string `toml:"allowed_client_ids"`
+		// PEM encoded ECDSA private key, which signs manifests, SBOMs,
+		// and provenance statements
+		SigningKey string          `toml:"signing_key"`
+		RateLimit  RateLimitConfig `toml:"rate_limit"`
 	} `toml:"composer_api"`
 	WorkerAPI struct {
 		IdentityFilter []string `toml:"identity_filter"`
+		// Workers which may authenticate with bearer tokens
+		AllowedClientIDs []string       `toml:"allowed_client_ids"`
+		PriorityClasses  map[string]int `toml:"priority_classes"`
+		HeartbeatTimeout string         `toml:"heartbeat_timeout"`
+		FailStaleJobs    bool           `toml:"fail_stale_jobs"`
+		MaxJobFailures   int            `toml:"max_job_failures"`
+		// Identifies this instance in a job queue shared with other
+		// instances, the host name by default
+		InstanceID string `toml:"instance_id"`
+		// Jobs which failed transiently are requeued up to
+		// TransientRetries times, waiting TransientRetryBackoff
+		// before the first retry and twice as long before each
+		// following one
+		TransientRetries      int    `toml:"transient_retries"`
+		TransientRetryBackoff string `toml:"transient_retry_backoff"`
+		TenantQuota           struct {
+			MaxConcurrent int `toml:"max_concurrent"`
+			MaxQueued     int `toml:"max_queued"`
+		} `toml:"tenant_quota"`
+		// Builds are handed out "fifo" (the default), or "fair":
+		// round-robin between tenants, which are blueprints for
+		// composes of the weldr API
+		Scheduling string `toml:"scheduling"`
+		// Maximum number of images which workers build at the same
+		// time, unlimited by default
+		MaxConcurrentBuilds int `toml:"max_concurrent_builds"`
+		// Durations after which jobs of a type, such as "osbuild" or
+		// "depsolve", are failed
+		JobTimeouts map[string]string `toml:"job_timeouts"`
+		// Artifacts of finished jobs, such as images, are deleted
+		// after `max_age`, and the oldest ones when all of them are
+		// larger than `max_size` bytes
+		ArtifactRetention struct {
+			MaxAge  string `toml:"max_age"`
+			MaxSize int64  `toml:"max_size"`
+		} `toml:"artifact_retention"`
+		Broker struct {
+			URL    string   `toml:"url"`
+			Arches []string `toml:"arches"`
+		} `toml:"broker"`
+		RateLimit RateLimitConfig `toml:"rate_limit"`
 	} `toml:"worker_api"`
 }
 
+// Limits the rate of requests of each client of an API, which is identified
+// by its token or identity header, or else by its address. Clients may make
+// `burst` requests at once and `rate` requests per second on average, which
+// is a float (e.g. "5.0"). Zero disables the limit.
+type RateLimitConfig struct {
+	Rate  float64 `toml:"rate"`
+	Burst int     `toml:"burst"`
+}
+
+func (c RateLimitConfig) limiterConfig() ratelimit.Config {
+	return ratelimit.Config{Rate: c.Rate, Burst: c.Burst}
+}
+
 func LoadConfig(name string) (*ComposerConfigFile, error) {
 	var c ComposerConfigFile
 	_, err := toml.DecodeFile(name, &c)
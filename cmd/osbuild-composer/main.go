@@ -6,6 +6,8 @@ import (
 	"os"
 
 	"github.com/coreos/go-systemd/activation"
+
+	"github.com/osbuild/osbuild-composer/internal/logging"
 )
 
 const (
@@ -19,6 +21,11 @@ var repositoryConfigs = []string{
 	"/usr/share/osbuild-composer",
 }
 
+var distroDefinitions = []string{
+	"/etc/osbuild-composer/distros",
+	"/usr/share/osbuild-composer/distros",
+}
+
 func main() {
 	var verbose bool
 	flag.BoolVar(&verbose, "v", false, "Print access log")
@@ -38,6 +45,19 @@ func main() {
 		}
 	}
 
+	level, err := logging.ParseLevel(config.Log.Level)
+	if err != nil {
+		log.Fatalf("Error in log configuration: %v", err)
+	}
+	defaultLogger, err := logging.New(os.Stderr, config.Log.Format, level)
+	if err != nil {
+		log.Fatalf("Error in log configuration: %v", err)
+	}
+	logging.SetDefault(defaultLogger)
+	// remaining log.Printf() calls are logged as messages of the logger
+	log.SetFlags(0)
+	log.SetOutput(defaultLogger.Writer())
+
 	log.Println("Loaded configuration:")
 	err = DumpConfig(config, log.Writer())
 	if err != nil {
@@ -88,7 +108,7 @@ func main() {
 			log.Fatal("The osbuild-composer-api.socket unit is misconfigured. It should contain only one socket.")
 		}
 
-		err = composer.InitAPI(ServerCertFile, ServerKeyFile, l[0])
+		err = composer.InitAPI(ServerCertFile, ServerKeyFile, repositoryConfigs, l[0])
 		if err != nil {
 			log.Fatalf("Error initializing koji API: %v", err)
 		}
@@ -105,6 +125,17 @@ func main() {
 		}
 	}
 
+	if l, exists := listeners["osbuild-composer-ostree.socket"]; exists {
+		if len(l) != 1 {
+			log.Fatal("The osbuild-composer-ostree.socket unit is misconfigured. It should contain only one socket.")
+		}
+
+		err = composer.InitOSTreeRepos(l[0])
+		if err != nil {
+			log.Fatalf("Error initializing OSTree repository server: %v", err)
+		}
+	}
+
 	err = composer.Start()
 	if err != nil {
 		log.Fatalf("%v", err)
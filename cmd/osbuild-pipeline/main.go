@@ -113,13 +113,9 @@ func main() {
 
 	rpm_md := rpmmd.NewRPMMD(path.Join(home, ".cache/osbuild-composer/rpmmd"), "/usr/libexec/osbuild-composer/dnf-json")
 
-	packageSpecSets := make(map[string][]rpmmd.PackageSpec)
-	for name, packages := range packageSets {
-		packageSpecs, _, err := rpm_md.Depsolve(packages, repos, d.ModulePlatformID(), arch.Name())
-		if err != nil {
-			panic("Could not depsolve: " + err.Error())
-		}
-		packageSpecSets[name] = packageSpecs
+	packageSpecSets, err := rpmmd.DepsolvePackageSets(rpm_md, packageSets, repos, d.ModulePlatformID(), arch.Name())
+	if err != nil {
+		panic("Could not depsolve: " + err.Error())
 	}
 
 	var bytes []byte
@@ -135,6 +131,7 @@ func main() {
 				OSTree: distro.OSTreeImageOptions{
 					Ref: imageType.OSTreeRef(), // use default OSTreeRef for image type
 				},
+				ModuleStreams: composeRequest.Blueprint.GetModuleStreams(),
 			},
 			repos,
 			packageSpecSets,
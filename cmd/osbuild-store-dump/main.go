@@ -18,14 +18,9 @@ import (
 )
 
 func getManifest(bp blueprint.Blueprint, t distro.ImageType, a distro.Arch, d distro.Distro, rpm_md rpmmd.RPMMD, repos []rpmmd.RepoConfig) (distro.Manifest, []rpmmd.PackageSpec) {
-	packageSets := t.PackageSets(bp)
-	pkgSpecSets := make(map[string][]rpmmd.PackageSpec)
-	for name, packages := range packageSets {
-		pkgs, _, err := rpm_md.Depsolve(packages, repos, d.ModulePlatformID(), a.Name())
-		if err != nil {
-			panic(err)
-		}
-		pkgSpecSets[name] = pkgs
+	pkgSpecSets, err := rpmmd.DepsolvePackageSets(rpm_md, t.PackageSets(bp), repos, d.ModulePlatformID(), a.Name())
+	if err != nil {
+		panic(err)
 	}
 	manifest, err := t.Manifest(bp.Customizations, distro.ImageOptions{}, repos, pkgSpecSets, 0)
 	if err != nil {
@@ -140,9 +135,9 @@ func main() {
 	}
 	rpmmd := rpmmd.NewRPMMD(path.Join(homeDir, ".cache/osbuild-composer/rpmmd"), "/usr/libexec/osbuild-composer/dnf-json")
 
-	s := store.New(&cwd, a, nil)
-	if s == nil {
-		panic("could not create store")
+	s, err := store.New(&cwd, a, nil)
+	if err != nil {
+		panic(err)
 	}
 	err = s.PushBlueprint(bp1, "message 1")
 	if err != nil {
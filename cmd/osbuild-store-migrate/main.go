@@ -0,0 +1,70 @@
+// osbuild-store-migrate copies the state of the weldr API (blueprints,
+// composes and sources) from the state directory of osbuild-composer into a
+// PostgreSQL database, so that composer can be switched to the "postgres"
+// store backend.
+//
+// Stop osbuild-composer before running it. The database is migrated to the
+// current schema, and must not contain a store yet.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+
+	"github.com/osbuild/osbuild-composer/internal/common"
+	"github.com/osbuild/osbuild-composer/internal/distroregistry"
+	"github.com/osbuild/osbuild-composer/internal/store"
+)
+
+var distroDefinitions = []string{
+	"/etc/osbuild-composer/distros",
+	"/usr/share/osbuild-composer/distros",
+}
+
+func main() {
+	var stateDir, url string
+	flag.StringVar(&stateDir, "state-dir", "/var/lib/osbuild-composer", "state directory of osbuild-composer")
+	flag.StringVar(&url, "url", "", "URL of the PostgreSQL database (default: taken from the PG* environment variables)")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "", 0)
+
+	// composes are loaded with the image types of the host, like
+	// osbuild-composer does
+	distros, err := distroregistry.NewDefaultWithDefinitions(distroDefinitions...)
+	if err != nil {
+		logger.Fatalf("cannot load distro definitions: %v", err)
+	}
+	hostDistro := distros.FromHost()
+	if hostDistro == nil {
+		logger.Fatal("host distro is not supported")
+	}
+	arch, err := hostDistro.GetArch(common.CurrentArch())
+	if err != nil {
+		logger.Fatalf("Host distro does not support host architecture: %v", err)
+	}
+
+	_, err = os.Stat(path.Join(stateDir, store.StoreDBName+".json"))
+	if err != nil {
+		logger.Fatalf("cannot find the state of osbuild-composer: %v", err)
+	}
+	src, err := store.New(&stateDir, arch, logger)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	dst, err := store.NewDB(url, arch)
+	if err != nil {
+		logger.Fatalf("cannot open the database: %v", err)
+	}
+	err = dst.ImportFileStore(src)
+	dst.Close()
+	if err != nil {
+		logger.Fatalf("cannot migrate the store: %v", err)
+	}
+
+	fmt.Printf("Migrated %d blueprints, %d composes and %d sources\n", len(src.ListBlueprints()), len(src.GetAllComposes()), len(src.ListSourcesById()))
+}
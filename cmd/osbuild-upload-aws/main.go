@@ -13,6 +13,8 @@ func main() {
 	var accessKeyID string
 	var secretAccessKey string
 	var sessionToken string
+	var roleARN string
+	var externalID string
 	var region string
 	var bucketName string
 	var keyName string
@@ -23,6 +25,8 @@ func main() {
 	flag.StringVar(&accessKeyID, "access-key-id", "", "access key ID")
 	flag.StringVar(&secretAccessKey, "secret-access-key", "", "secret access key")
 	flag.StringVar(&sessionToken, "session-token", "", "session token")
+	flag.StringVar(&roleARN, "role-arn", "", "IAM role to assume")
+	flag.StringVar(&externalID, "external-id", "", "external ID required by the role's trust policy")
 	flag.StringVar(&region, "region", "", "target region")
 	flag.StringVar(&bucketName, "bucket", "", "target S3 bucket name")
 	flag.StringVar(&keyName, "key", "", "target S3 key name")
@@ -32,7 +36,14 @@ func main() {
 	flag.StringVar(&arch, "arch", "", "arch (x86_64 or aarch64)")
 	flag.Parse()
 
-	a, err := awsupload.New(region, accessKeyID, secretAccessKey, sessionToken)
+	creds := awsupload.Credentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+		RoleARN:         roleARN,
+		ExternalID:      externalID,
+	}
+	a, err := awsupload.NewWithCredentials(region, creds, creds)
 	if err != nil {
 		println(err.Error())
 		return
@@ -50,7 +61,7 @@ func main() {
 	if shareWith != "" {
 		share = append(share, shareWith)
 	}
-	ami, err := a.Register(imageName, bucketName, keyName, share, arch)
+	ami, err := a.Register(imageName, bucketName, keyName, share, arch, nil)
 	if err != nil {
 		println(err.Error())
 		return
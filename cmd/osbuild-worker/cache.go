@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// osbuild keeps the files of both org.osbuild.files (version 1 manifests) and
+// org.osbuild.curl (version 2) sources in this directory of the store,
+// named by their checksum
+const fileSourcesDir = "sources/org.osbuild.files"
+
+// storeCache manages the osbuild store of the worker. It tells which sources
+// of a build were cached, and evicts the least recently used objects and
+// sources between jobs, so that the store doesn't grow beyond its maximum
+// size. A nil *storeCache does nothing.
+type storeCache struct {
+	dir string
+	// zero means that the store may grow without limit
+	maxSize uint64
+
+	// size of the store after the last collection
+	size uint64
+}
+
+func newStoreCache(dir string, maxSize uint64) *storeCache {
+	return &storeCache{
+		dir:     dir,
+		maxSize: maxSize,
+	}
+}
+
+// lookup returns how many of the file sources of `manifest` are in the store,
+// and the checksums of all of them, which should be passed to touch() after
+// the build.
+func (c *storeCache) lookup(manifest distro.Manifest) (*worker.CacheStats, []string) {
+	if c == nil {
+		return nil, nil
+	}
+
+	stats := &worker.CacheStats{StoreSize: c.size}
+
+	checksums := fileSources(manifest)
+	for _, checksum := range checksums {
+		if _, err := os.Stat(path.Join(c.dir, fileSourcesDir, checksum)); err == nil {
+			stats.Hits++
+		} else {
+			stats.Misses++
+		}
+	}
+
+	return stats, checksums
+}
+
+// touch marks the sources with `checksums` as used, which makes them the last
+// ones to be evicted.
+func (c *storeCache) touch(checksums []string) {
+	if c == nil {
+		return
+	}
+	now := time.Now()
+	for _, checksum := range checksums {
+		// sources which failed to download don't exist
+		_ = os.Chtimes(path.Join(c.dir, fileSourcesDir, checksum), now, now)
+	}
+}
+
+// cacheEntry is an object or a source in the store, which is evicted as a
+// whole.
+type cacheEntry struct {
+	path    string
+	size    uint64
+	modTime time.Time
+}
+
+// Collect evicts the objects and sources which were used least recently from
+// the store, until it is no larger than its maximum size. It must not be
+// called while osbuild is running.
+func (c *storeCache) Collect() {
+	if c == nil {
+		return
+	}
+	c.size = diskUsage(c.dir)
+	if c.maxSize == 0 || c.size <= c.maxSize {
+		return
+	}
+
+	var entries []cacheEntry
+	for _, dir := range []string{"objects", fileSourcesDir} {
+		infos, err := ioutil.ReadDir(path.Join(c.dir, dir))
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("Error reading the osbuild store: %v", err)
+		}
+		for _, info := range infos {
+			p := path.Join(c.dir, dir, info.Name())
+			entries = append(entries, cacheEntry{p, diskUsage(p), info.ModTime()})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+
+	var evicted int
+	var freed uint64
+	for _, entry := range entries {
+		if c.size <= c.maxSize {
+			break
+		}
+		err := os.RemoveAll(entry.path)
+		if err != nil {
+			log.Printf("Error evicting %s from the osbuild store: %v", entry.path, err)
+			continue
+		}
+		evicted++
+		freed += entry.size
+		c.size -= entry.size
+	}
+	removeDanglingRefs(path.Join(c.dir, "refs"))
+
+	log.Printf("Evicted %d objects and sources (%s) from the osbuild store, %s left", evicted, formatSize(freed), formatSize(c.size))
+}
+
+// fileSources returns the checksums of the files the sources of `manifest`
+// download.
+func fileSources(manifest distro.Manifest) []string {
+	var m struct {
+		Sources map[string]struct {
+			// version 1 manifests
+			URLs map[string]json.RawMessage `json:"urls"`
+			// version 2 manifests
+			Items map[string]json.RawMessage `json:"items"`
+		} `json:"sources"`
+	}
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return nil
+	}
+
+	var checksums []string
+	for name, source := range m.Sources {
+		if name != "org.osbuild.files" && name != "org.osbuild.curl" {
+			continue
+		}
+		for checksum := range source.URLs {
+			checksums = append(checksums, checksum)
+		}
+		for checksum := range source.Items {
+			checksums = append(checksums, checksum)
+		}
+	}
+	return checksums
+}
+
+// diskUsage returns the number of bytes the files under `p` occupy on disk.
+func diskUsage(p string) uint64 {
+	var size uint64
+	_ = filepath.Walk(p, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			size += uint64(stat.Blocks) * 512
+		} else {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size
+}
+
+// removeDanglingRefs removes the references of older versions of osbuild to
+// objects which were evicted.
+func removeDanglingRefs(dir string) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, info := range infos {
+		p := path.Join(dir, info.Name())
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			_ = os.Remove(p)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"google.golang.org/api/googleapi"
+
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// jobFailure classifies the error a job failed with. Timeouts, errors of the
+// network, and server errors or throttling of upload targets and registries
+// are transient: they may go away when the job runs again. All other errors
+// are permanent.
+func jobFailure(err error) string {
+	if errors.Is(err, context.Canceled) {
+		return worker.FailurePermanent
+	}
+
+	// net.Error, url.Error and the errors of Azure storage
+	var temporary interface {
+		Temporary() bool
+		Timeout() bool
+	}
+	if errors.As(err, &temporary) && (temporary.Temporary() || temporary.Timeout()) {
+		return worker.FailureTransient
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		// sending the request failed, see corehandlers.SendHandler
+		if awsErr.Code() == "RequestError" && request.IsErrorRetryable(awsErr) {
+			return worker.FailureTransient
+		}
+		if request.IsErrorThrottle(awsErr) {
+			return worker.FailureTransient
+		}
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() >= http.StatusInternalServerError {
+			return worker.FailureTransient
+		}
+	}
+
+	var gcpErr *googleapi.Error
+	if errors.As(err, &gcpErr) && (gcpErr.Code >= http.StatusInternalServerError || gcpErr.Code == http.StatusTooManyRequests) {
+		return worker.FailureTransient
+	}
+
+	return worker.FailurePermanent
+}
+
+// depsolveFailure classifies the error depsolving failed with. Packages
+// which are missing or conflict with each other fail every time, while
+// repositories which can't be loaded may be reachable the next time.
+func depsolveFailure(err error) string {
+	var dnfErr *rpmmd.DNFError
+	if errors.As(err, &dnfErr) {
+		switch dnfErr.Kind {
+		case "MarkingErrors", "DepsolveError":
+			return worker.FailurePermanent
+		default:
+			return worker.FailureTransient
+		}
+	}
+	return jobFailure(err)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// ComposeJobImpl finishes the job which groups the images of a compose once
+// all of them have been built and uploaded. Composer reports the status of
+// each image on its own, the job only records whether all of them succeeded.
+// Results of upload jobs have the same `success` field as those of osbuild
+// jobs.
+type ComposeJobImpl struct{}
+
+func (impl *ComposeJobImpl) Run(ctx context.Context, job worker.Job) error {
+	result := worker.ComposeJobResult{Success: true}
+	for i := 0; i < job.NDynamicArgs(); i++ {
+		var imageResult worker.OSBuildJobResult
+		err := job.DynamicArgs(i, &imageResult)
+		if err != nil {
+			return err
+		}
+
+		// OSBuildJobResult didn't use to have a top-level `Success` flag
+		success := imageResult.Success
+		if !success && imageResult.OSBuildOutput != nil {
+			success = imageResult.OSBuildOutput.Success && len(imageResult.TargetErrors) == 0
+		}
+		if !success {
+			result.Success = false
+		}
+	}
+
+	return job.Update(&result)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/tracing"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
+)
+
+type DepsolveJobImpl struct {
+	RPMMD rpmmd.RPMMD
+}
+
+func (impl *DepsolveJobImpl) depsolve(args *worker.DepsolveJob) (map[string][]rpmmd.PackageSpec, error) {
+	repos := args.Repos
+	packageSets := args.PackageSets
+	if args.StrictContentVerification {
+		var err error
+		repos, packageSets, err = enforceGPGCheck(repos, packageSets)
+		if err != nil {
+			return nil, clienterrors.Errorf(clienterrors.ErrorContentVerification, "strict content verification: %w", err)
+		}
+	}
+
+	packageSpecs, err := rpmmd.DepsolvePackageSets(impl.RPMMD, packageSets, repos, args.ModulePlatformID, args.Arch)
+	if err != nil {
+		return nil, fmt.Errorf("error depsolving packages: %w", err)
+	}
+	return packageSpecs, nil
+}
+
+// enforceGPGCheck enables the verification of packages and metadata for
+// `repos` and the repositories of each of the `packageSets`.
+func enforceGPGCheck(repos []rpmmd.RepoConfig, packageSets map[string]rpmmd.PackageSet) ([]rpmmd.RepoConfig, map[string]rpmmd.PackageSet, error) {
+	repos, err := rpmmd.EnforceGPGCheck(repos)
+	if err != nil {
+		return nil, nil, err
+	}
+	enforced := make(map[string]rpmmd.PackageSet, len(packageSets))
+	for name, packageSet := range packageSets {
+		packageSet.Repositories, err = rpmmd.EnforceGPGCheck(packageSet.Repositories)
+		if err != nil {
+			return nil, nil, err
+		}
+		enforced[name] = packageSet
+	}
+	return repos, enforced, nil
+}
+
+func (impl *DepsolveJobImpl) Run(ctx context.Context, job worker.Job) error {
+	var args worker.DepsolveJob
+	err := job.Args(&args)
+	if err != nil {
+		return err
+	}
+
+	_, span := tracing.Start(ctx, "depsolve")
+	var result worker.DepsolveJobResult
+	result.PackageSpecs, err = impl.depsolve(&args)
+	tracing.RecordError(span, err)
+	span.End()
+	if err != nil {
+		log.Printf("Error depsolving packages: %v", err)
+		result.Error = err.Error()
+		result.Failure = depsolveFailure(err)
+		result.JobError = depsolveError(err)
+	}
+
+	return job.Update(&result)
+}
+
+// depsolveError returns the error which is reported to clients when
+// depsolving failed with `err`.
+func depsolveError(err error) *clienterrors.Error {
+	var clientErr *clienterrors.Error
+	if errors.As(err, &clientErr) {
+		return clientErr
+	}
+
+	code := clienterrors.ErrorRepositoryUnavailable
+	var dnfErr *rpmmd.DNFError
+	if errors.As(err, &dnfErr) {
+		switch dnfErr.Kind {
+		case "MarkingErrors":
+			code = clienterrors.ErrorPackagesNotFound
+		case "DepsolveError":
+			code = clienterrors.ErrorDepsolveFailed
+		}
+	}
+	return clienterrors.Wrap(code, err)
+}
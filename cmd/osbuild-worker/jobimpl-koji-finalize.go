@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,18 +13,34 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 	"github.com/osbuild/osbuild-composer/internal/upload/koji"
 	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
 )
 
+// How often to poll the task which tags an imported build
+const kojiTagPollInterval = 10 * time.Second
+
 type KojiFinalizeJobImpl struct {
 	KojiServers map[string]koji.GSSAPICredentials
 }
 
+// kojiTagError is returned by kojiImport when the build was imported, but
+// could not be tagged
+type kojiTagError struct {
+	tag string
+	err error
+}
+
+func (e *kojiTagError) Error() string {
+	return fmt.Sprintf("Could not tag build into %s: %v", e.tag, e.err)
+}
+
 func (impl *KojiFinalizeJobImpl) kojiImport(
+	ctx context.Context,
 	server string,
 	build koji.ImageBuild,
 	buildRoots []koji.BuildRoot,
 	images []koji.Image,
-	directory, token string) error {
+	directory, token, tag string) error {
 	// Koji for some reason needs TLS renegotiation enabled.
 	// Clone the default http transport and enable renegotiation.
 	transport := http.DefaultTransport.(*http.Transport).Clone()
@@ -56,6 +74,18 @@ func (impl *KojiFinalizeJobImpl) kojiImport(
 		return fmt.Errorf("Could not import build into koji: %v", err)
 	}
 
+	if tag == "" {
+		return nil
+	}
+	taskID, err := k.TagBuild(tag, int(build.BuildID))
+	if err != nil {
+		return &kojiTagError{tag, err}
+	}
+	err = k.WaitForTask(ctx, taskID, kojiTagPollInterval)
+	if err != nil {
+		return &kojiTagError{tag, err}
+	}
+
 	return nil
 }
 
@@ -91,7 +121,7 @@ func (impl *KojiFinalizeJobImpl) kojiFail(server string, buildID int, token stri
 	return k.CGFailBuild(buildID, token)
 }
 
-func (impl *KojiFinalizeJobImpl) Run(job worker.Job) error {
+func (impl *KojiFinalizeJobImpl) Run(ctx context.Context, job worker.Job) error {
 	var args worker.KojiFinalizeJob
 	err := job.Args(&args)
 	if err != nil {
@@ -111,6 +141,7 @@ func (impl *KojiFinalizeJobImpl) Run(job worker.Job) error {
 		var result worker.KojiFinalizeJobResult
 		if err != nil {
 			result.KojiError = err.Error()
+			result.JobError = clienterrors.Wrap(clienterrors.ErrorKojiImport, err)
 		}
 		err = job.Update(&result)
 		if err != nil {
@@ -127,6 +158,7 @@ func (impl *KojiFinalizeJobImpl) Run(job worker.Job) error {
 		Release:   args.Release,
 		StartTime: int64(args.StartTime),
 		EndTime:   time.Now().Unix(),
+		Draft:     args.Draft,
 	}
 
 	var buildRoots []koji.BuildRoot
@@ -167,9 +199,14 @@ func (impl *KojiFinalizeJobImpl) Run(job worker.Job) error {
 	}
 
 	var result worker.KojiFinalizeJobResult
-	err = impl.kojiImport(args.Server, build, buildRoots, images, args.KojiDirectory, initArgs.Token)
-	if err != nil {
+	err = impl.kojiImport(ctx, args.Server, build, buildRoots, images, args.KojiDirectory, initArgs.Token, args.Tag)
+	var tagErr *kojiTagError
+	if errors.As(err, &tagErr) {
+		result.TagError = err.Error()
+		result.JobError = clienterrors.Wrap(clienterrors.ErrorKojiTag, err)
+	} else if err != nil {
 		result.KojiError = err.Error()
+		result.JobError = clienterrors.Wrap(clienterrors.ErrorKojiImport, err)
 	}
 
 	err = job.Update(&result)
@@ -183,6 +220,29 @@ func (impl *KojiFinalizeJobImpl) Run(job worker.Job) error {
 // Extracts dynamic args of the koji-finalize job. Returns an error if they
 // cannot be unmarshalled.
 func extractDynamicArgs(job worker.Job) (*worker.KojiInitJobResult, []worker.OSBuildKojiJobResult, error) {
+	var kojiInitResults []worker.KojiInitJobResult
+	var osbuildKojiResults []worker.OSBuildKojiJobResult
+
+	err := job.DynamicArgsOfType("koji-init", &kojiInitResults)
+	if err == worker.ErrNoDynamicArgsTypes {
+		// older composers always pass the init result first
+		return extractPositionalDynamicArgs(job)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if len(kojiInitResults) != 1 {
+		return nil, nil, fmt.Errorf("expected exactly one koji-init dependency, got %d", len(kojiInitResults))
+	}
+
+	err = job.DynamicArgsOfType("osbuild-koji", &osbuildKojiResults)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &kojiInitResults[0], osbuildKojiResults, nil
+}
+
+func extractPositionalDynamicArgs(job worker.Job) (*worker.KojiInitJobResult, []worker.OSBuildKojiJobResult, error) {
 	var kojiInitResult worker.KojiInitJobResult
 	err := job.DynamicArgs(0, &kojiInitResult)
 	if err != nil {
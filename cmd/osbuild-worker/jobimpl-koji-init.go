@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"log"
@@ -9,13 +10,14 @@ import (
 
 	"github.com/osbuild/osbuild-composer/internal/upload/koji"
 	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
 )
 
 type KojiInitJobImpl struct {
 	KojiServers map[string]koji.GSSAPICredentials
 }
 
-func (impl *KojiInitJobImpl) kojiInit(server, name, version, release string) (string, uint64, error) {
+func (impl *KojiInitJobImpl) kojiInit(server, name, version, release string, draft bool) (string, uint64, error) {
 	// Koji for some reason needs TLS renegotiation enabled.
 	// Clone the default http transport and enable renegotiation.
 	transport := http.DefaultTransport.(*http.Transport).Clone()
@@ -44,7 +46,11 @@ func (impl *KojiInitJobImpl) kojiInit(server, name, version, release string) (st
 		}
 	}()
 
-	buildInfo, err := k.CGInitBuild(name, version, release)
+	initBuild := k.CGInitBuild
+	if draft {
+		initBuild = k.CGInitDraftBuild
+	}
+	buildInfo, err := initBuild(name, version, release)
 	if err != nil {
 		return "", 0, err
 	}
@@ -52,7 +58,7 @@ func (impl *KojiInitJobImpl) kojiInit(server, name, version, release string) (st
 	return buildInfo.Token, uint64(buildInfo.BuildID), nil
 }
 
-func (impl *KojiInitJobImpl) Run(job worker.Job) error {
+func (impl *KojiInitJobImpl) Run(ctx context.Context, job worker.Job) error {
 	var args worker.KojiInitJob
 	err := job.Args(&args)
 	if err != nil {
@@ -60,9 +66,10 @@ func (impl *KojiInitJobImpl) Run(job worker.Job) error {
 	}
 
 	var result worker.KojiInitJobResult
-	result.Token, result.BuildID, err = impl.kojiInit(args.Server, args.Name, args.Version, args.Release)
+	result.Token, result.BuildID, err = impl.kojiInit(args.Server, args.Name, args.Version, args.Release, args.Draft)
 	if err != nil {
 		result.KojiError = err.Error()
+		result.JobError = clienterrors.Wrap(clienterrors.ErrorKojiInit, err)
 	}
 
 	err = job.Update(&result)
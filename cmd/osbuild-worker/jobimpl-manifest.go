@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/distroregistry"
+	"github.com/osbuild/osbuild-composer/internal/ostree"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/sbom"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
+)
+
+type ManifestJobByIDImpl struct {
+	Distros *distroregistry.Registry
+}
+
+func (impl *ManifestJobByIDImpl) manifest(args *worker.ManifestJobByID, packageSpecSets map[string][]rpmmd.PackageSpec) (distro.Manifest, error) {
+	d := impl.Distros.GetDistro(args.Distribution)
+	if d == nil {
+		return nil, fmt.Errorf("unsupported distribution: %s", args.Distribution)
+	}
+
+	arch, err := d.GetArch(args.Arch)
+	if err != nil {
+		return nil, err
+	}
+
+	imageType, err := arch.GetImageType(args.ImageType)
+	if err != nil {
+		return nil, err
+	}
+
+	options := args.ImageOptions
+	if options.OSTree.URL != "" && options.OSTree.Parent == "" {
+		options.OSTree.Parent, err = ostree.ResolveRef(options.OSTree.URL, options.OSTree.Ref)
+		if err != nil {
+			return nil, clienterrors.Errorf(clienterrors.ErrorOSTreeRefResolution, "error resolving OSTree repo %s: %w", options.OSTree.URL, err)
+		}
+	}
+
+	return imageType.Manifest(args.Blueprint.Customizations, options, args.Repositories, packageSpecSets, args.Seed)
+}
+
+func (impl *ManifestJobByIDImpl) Run(ctx context.Context, job worker.Job) error {
+	var args worker.ManifestJobByID
+	err := job.Args(&args)
+	if err != nil {
+		return err
+	}
+
+	if job.NDynamicArgs() != 1 {
+		return fmt.Errorf("manifest job must depend on exactly one depsolve job, got %d dependencies", job.NDynamicArgs())
+	}
+	var depsolveResult worker.DepsolveJobResult
+	err = job.DynamicArgs(0, &depsolveResult)
+	if err != nil {
+		return err
+	}
+
+	var result worker.ManifestJobByIDResult
+	if depsolveResult.Error != "" {
+		result.Error = "depsolve failed: " + depsolveResult.Error
+		result.Failure = worker.FailurePermanent
+		result.JobError = dependencyError("depsolve failed: "+depsolveResult.Error, depsolveResult.JobError)
+		return job.Update(&result)
+	}
+
+	result.Manifest, err = impl.manifest(&args, depsolveResult.PackageSpecs)
+	if err != nil {
+		log.Printf("Error generating manifest: %v", err)
+		result.Error = err.Error()
+		result.Failure = jobFailure(err)
+		result.JobError = manifestError(err)
+		return job.Update(&result)
+	}
+
+	// the image can be built without its SBOMs
+	err = uploadSBOMs(job, &args, depsolveResult.PackageSpecs)
+	if err != nil {
+		log.Printf("Error uploading SBOMs: %v", err)
+	}
+
+	return job.Update(&result)
+}
+
+// manifestError returns the error which is reported to clients when
+// generating the manifest failed with `err`.
+func manifestError(err error) *clienterrors.Error {
+	var clientErr *clienterrors.Error
+	if errors.As(err, &clientErr) {
+		return clientErr
+	}
+	return clienterrors.Wrap(clienterrors.ErrorManifestGeneration, err)
+}
+
+// dependencyError returns the error of a job which failed because the job
+// it depends on failed with `reason` and `depErr`, which is nil for results
+// of older workers.
+func dependencyError(reason string, depErr *clienterrors.Error) *clienterrors.Error {
+	if depErr == nil {
+		return clienterrors.New(clienterrors.ErrorDependencyFailed, reason, nil)
+	}
+	return clienterrors.New(clienterrors.ErrorDependencyFailed, reason, depErr)
+}
+
+// uploadSBOMs uploads the SBOMs of the image of the manifest job as its
+// artifacts. They list the packages of all package sets of the image except
+// those of the build root.
+func uploadSBOMs(job worker.Job, args *worker.ManifestJobByID, packageSpecSets map[string][]rpmmd.PackageSpec) error {
+	image := sbom.Image{
+		ID:           job.Id(),
+		Name:         fmt.Sprintf("%s-%s-%s", args.Distribution, args.ImageType, args.Arch),
+		Distribution: args.Distribution,
+		Created:      time.Now(),
+	}
+	for name, packageSpecs := range packageSpecSets {
+		if name != "build" {
+			image.Packages = append(image.Packages, packageSpecs...)
+		}
+	}
+
+	for name, doc := range map[string]interface{}{
+		sbom.SPDXArtifact:      image.SPDX(),
+		sbom.CycloneDXArtifact: image.CycloneDX(),
+	} {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		err = job.UploadArtifact(name, bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("error uploading %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
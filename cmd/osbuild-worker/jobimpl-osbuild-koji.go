@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io/ioutil"
@@ -14,12 +15,14 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/upload/koji"
 	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
 )
 
 type OSBuildKojiJobImpl struct {
 	Store       string
 	Output      string
 	KojiServers map[string]koji.GSSAPICredentials
+	Cache       *storeCache
 }
 
 func (impl *OSBuildKojiJobImpl) kojiUpload(file *os.File, server, directory, filename string) (string, uint64, error) {
@@ -54,7 +57,7 @@ func (impl *OSBuildKojiJobImpl) kojiUpload(file *os.File, server, directory, fil
 	return k.Upload(file, directory, filename)
 }
 
-func (impl *OSBuildKojiJobImpl) Run(job worker.Job) error {
+func (impl *OSBuildKojiJobImpl) Run(ctx context.Context, job worker.Job) error {
 	outputDirectory, err := ioutil.TempDir(impl.Output, job.Id().String()+"-*")
 	if err != nil {
 		return fmt.Errorf("error creating temporary output directory: %v", err)
@@ -95,7 +98,10 @@ func (impl *OSBuildKojiJobImpl) Run(job worker.Job) error {
 			// this worker only supports returning one (1) export
 			return fmt.Errorf("at most one build artifact can be exported")
 		}
-		result.OSBuildOutput, err = RunOSBuild(args.Manifest, impl.Store, outputDirectory, exports, os.Stderr)
+		var sources []string
+		result.Cache, sources = impl.Cache.lookup(args.Manifest)
+		result.OSBuildOutput, err = RunOSBuild(ctx, args.Manifest, impl.Store, outputDirectory, exports, os.Stderr, nil, nil)
+		impl.Cache.touch(sources)
 		if err != nil {
 			return err
 		}
@@ -112,8 +118,13 @@ func (impl *OSBuildKojiJobImpl) Run(job worker.Job) error {
 			result.ImageHash, result.ImageSize, err = impl.kojiUpload(f, args.KojiServer, args.KojiDirectory, args.KojiFilename)
 			if err != nil {
 				result.KojiError = err.Error()
+				result.JobError = clienterrors.Wrap(clienterrors.ErrorKojiUpload, err)
 			}
+		} else {
+			result.JobError = clienterrors.OSBuildFailed(result.OSBuildOutput, "")
 		}
+	} else {
+		result.JobError = dependencyError("initializing the Koji build failed: "+initArgs.KojiError, initArgs.JobError)
 	}
 
 	err = job.Update(&result)
@@ -2,24 +2,32 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/osbuild/osbuild-composer/internal/cloud/gcp"
 	"github.com/osbuild/osbuild-composer/internal/common"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
 	"github.com/osbuild/osbuild-composer/internal/target"
+	"github.com/osbuild/osbuild-composer/internal/tracing"
 	"github.com/osbuild/osbuild-composer/internal/upload/awsupload"
 	"github.com/osbuild/osbuild-composer/internal/upload/azure"
 	"github.com/osbuild/osbuild-composer/internal/upload/koji"
+	"github.com/osbuild/osbuild-composer/internal/upload/oci"
 	"github.com/osbuild/osbuild-composer/internal/upload/vmware"
 	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
 )
 
 type OSBuildJobImpl struct {
@@ -28,6 +36,8 @@ type OSBuildJobImpl struct {
 	KojiServers map[string]koji.GSSAPICredentials
 	GCPCreds    []byte
 	AzureCreds  *azure.Credentials
+	OCICreds    *oci.Credentials
+	Cache       *storeCache
 }
 
 func appendTargetError(res *worker.OSBuildJobResult, err error) {
@@ -36,7 +46,34 @@ func appendTargetError(res *worker.OSBuildJobResult, err error) {
 	res.TargetErrors = append(res.TargetErrors, errStr)
 }
 
-func (impl *OSBuildJobImpl) Run(job worker.Job) error {
+func awsCredentials(creds target.AWSCredentials) awsupload.Credentials {
+	return awsupload.Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		RoleARN:         creds.RoleARN,
+		ExternalID:      creds.ExternalID,
+	}
+}
+
+// Uploads the image in `directory` to the bucket in `options` and returns a
+// presigned URL to download it.
+func uploadToS3(a *awsupload.AWS, directory string, options *target.AWSS3TargetOptions) (string, error) {
+	key := options.Key
+	if key == "" {
+		key = uuid.New().String()
+	}
+	key += "-" + options.Filename
+
+	_, err := a.Upload(path.Join(directory, options.Filename), options.Bucket, key)
+	if err != nil {
+		return "", err
+	}
+
+	return a.S3ObjectPresignedURL(options.Bucket, key)
+}
+
+func (impl *OSBuildJobImpl) Run(ctx context.Context, job worker.Job) error {
 	// Initialize variable needed for reporting back to osbuild-composer.
 	var osbuildJobResult *worker.OSBuildJobResult = &worker.OSBuildJobResult{
 		Success: false,
@@ -50,6 +87,14 @@ func (impl *OSBuildJobImpl) Run(job worker.Job) error {
 
 	// In all cases it is necessary to report result back to osbuild-composer worker API.
 	defer func() {
+		// Only the failures which were classified as transient below
+		// are retried by composer.
+		if !osbuildJobResult.Success && osbuildJobResult.Failure == "" {
+			osbuildJobResult.Failure = worker.FailurePermanent
+		}
+		if !osbuildJobResult.Success && osbuildJobResult.JobError == nil {
+			osbuildJobResult.JobError = clienterrors.New(clienterrors.ErrorWorker, "The worker failed to build the image", nil)
+		}
 		err := job.Update(osbuildJobResult)
 		if err != nil {
 			log.Printf("Error reporting job result: %v", err)
@@ -72,11 +117,28 @@ func (impl *OSBuildJobImpl) Run(job worker.Job) error {
 	if err != nil {
 		return err
 	}
+	// Jobs enqueued with EnqueueOSBuildAsDependency() get their manifest
+	// from the manifest job they depend on.
+	if len(args.Manifest) == 0 && job.NDynamicArgs() > 0 {
+		var manifestResult worker.ManifestJobByIDResult
+		err = job.DynamicArgs(0, &manifestResult)
+		if err != nil {
+			return err
+		}
+		if manifestResult.Error != "" {
+			appendTargetError(osbuildJobResult, fmt.Errorf("manifest generation failed: %s", manifestResult.Error))
+			osbuildJobResult.JobError = dependencyError("manifest generation failed: "+manifestResult.Error, manifestResult.JobError)
+			return nil
+		}
+		args.Manifest = manifestResult.Manifest
+	}
+
 	// The specification allows multiple upload targets because it is an array, but we don't support it.
 	// Return an error to osbuild-composer.
 	if len(args.Targets) > 1 {
 		log.Printf("The job specification contains more than one upload target. This is not supported any more. " +
 			"This might indicate a deployment of incompatible osbuild-worker and osbuild-composer versions.")
+		osbuildJobResult.JobError = clienterrors.New(clienterrors.ErrorInvalidJob, "The job has more than one upload target", nil)
 		return nil
 	}
 
@@ -87,17 +149,31 @@ func (impl *OSBuildJobImpl) Run(job worker.Job) error {
 		exports = []string{"assembler"}
 	} else if len(exports) > 1 {
 		// this worker only supports returning one (1) export
+		osbuildJobResult.JobError = clienterrors.New(clienterrors.ErrorInvalidJob, "The job exports more than one build artifact", nil)
 		return fmt.Errorf("at most one build artifact can be exported")
 	}
 
 	// Run osbuild and handle two kinds of errors
-	osbuildJobResult.OSBuildOutput, err = RunOSBuild(args.Manifest, impl.Store, outputDirectory, exports, os.Stderr)
+	progress := newProgressReporter(job, args.Manifest)
+	logs := newLogStreamer(job)
+	var sources []string
+	osbuildJobResult.Cache, sources = impl.Cache.lookup(args.Manifest)
+	osbuildJobResult.OSBuildOutput, err = RunOSBuild(ctx, args.Manifest, impl.Store, outputDirectory, exports, os.Stderr, logs, progress.stageStarted)
+	impl.Cache.touch(sources)
+	osbuildJobResult.Progress = progress.stop()
+	logs.stop()
 	// First handle the case when "running" osbuild failed
 	if err != nil {
+		osbuildJobResult.JobError = clienterrors.Errorf(clienterrors.ErrorWorker, "running osbuild failed: %w", err)
 		return err
 	}
 	// Second handle the case when the build failed, but osbuild finished successfully
 	if !osbuildJobResult.OSBuildOutput.Success {
+		stage := ""
+		if osbuildJobResult.Progress != nil {
+			stage = osbuildJobResult.Progress.Stage
+		}
+		osbuildJobResult.JobError = clienterrors.OSBuildFailed(osbuildJobResult.OSBuildOutput, stage)
 		return nil
 	}
 
@@ -107,23 +183,33 @@ func (impl *OSBuildJobImpl) Run(job worker.Job) error {
 	// by any of the image types and it can't be specified during the request.
 	// Use the first (and presumably only) export for the imagePath.
 	exportPath := exports[0]
+
+	osbuildJobResult.ImageFiles, err = imageFiles(path.Join(outputDirectory, exportPath))
+	if err != nil {
+		// the image files are only needed for its provenance
+		log.Printf("Error computing the digests of the image files: %v", err)
+	}
 	if osbuildJobResult.OSBuildOutput.Success && args.ImageName != "" {
 		var f *os.File
 		imagePath := path.Join(outputDirectory, exportPath, args.ImageName)
 		if args.StreamOptimized {
 			f, err = vmware.OpenAsStreamOptimizedVmdk(imagePath)
 			if err != nil {
+				osbuildJobResult.JobError = clienterrors.Wrap(clienterrors.ErrorWorker, err)
 				return err
 			}
 			streamOptimizedPath = f.Name()
 		} else {
 			f, err = os.Open(imagePath)
 			if err != nil {
+				osbuildJobResult.JobError = clienterrors.Wrap(clienterrors.ErrorWorker, err)
 				return err
 			}
 		}
 		err = job.UploadArtifact(args.ImageName, f)
 		if err != nil {
+			osbuildJobResult.Failure = jobFailure(err)
+			osbuildJobResult.JobError = clienterrors.Wrap(clienterrors.ErrorWorker, err)
 			return err
 		}
 	}
@@ -133,327 +219,533 @@ func (impl *OSBuildJobImpl) Run(job worker.Job) error {
 		osbuildJobResult.Success = true
 		osbuildJobResult.UploadStatus = "success"
 	} else if len(args.Targets) == 1 {
-		switch options := args.Targets[0].Options.(type) {
-		case *target.VMWareTargetOptions:
-			credentials := vmware.Credentials{
-				Username:   options.Username,
-				Password:   options.Password,
-				Host:       options.Host,
-				Cluster:    options.Cluster,
-				Datacenter: options.Datacenter,
-				Datastore:  options.Datastore,
-			}
+		result, err := impl.upload(ctx, job.Id(), args.Targets[0], path.Join(outputDirectory, exportPath), streamOptimizedPath)
+		if result != nil {
+			osbuildJobResult.TargetResults = append(osbuildJobResult.TargetResults, result)
+		}
+		if err != nil {
+			appendTargetError(osbuildJobResult, err)
+			osbuildJobResult.Failure = jobFailure(err)
+			osbuildJobResult.JobError = uploadError(args.Targets[0], err)
+			return nil
+		}
+		osbuildJobResult.Success = true
+		osbuildJobResult.UploadStatus = "success"
+	}
 
-			tempDirectory, err := ioutil.TempDir(impl.Output, job.Id().String()+"-vmware-*")
-			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
-			}
+	return nil
+}
 
-			defer func() {
-				err := os.RemoveAll(tempDirectory)
-				if err != nil {
-					log.Printf("Error removing temporary directory for vmware symlink(%s): %v", tempDirectory, err)
-				}
-			}()
+// imageFiles returns the regular files in `exportDirectory` and below, with
+// their SHA-256 digests.
+func imageFiles(exportDirectory string) ([]worker.ImageFile, error) {
+	var files []worker.ImageFile
+	err := filepath.Walk(exportDirectory, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
 
-			// create a symlink so that uploaded image has the name specified by user
-			imageName := args.Targets[0].ImageName + ".vmdk"
-			imagePath := path.Join(tempDirectory, imageName)
-			err = os.Symlink(streamOptimizedPath, imagePath)
-			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
-			}
+		name, err := filepath.Rel(exportDirectory, p)
+		if err != nil {
+			return err
+		}
 
-			err = vmware.UploadImage(credentials, imagePath)
-			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
-			}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
 
-			osbuildJobResult.Success = true
-			osbuildJobResult.UploadStatus = "success"
-		case *target.AWSTargetOptions:
-			a, err := awsupload.New(options.Region, options.AccessKeyID, options.SecretAccessKey, "")
-			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
-			}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		if err != nil {
+			return err
+		}
 
-			key := options.Key
-			if key == "" {
-				key = uuid.New().String()
-			}
+		files = append(files, worker.ImageFile{
+			Name:   name,
+			SHA256: hex.EncodeToString(h.Sum(nil)),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
 
-			_, err = a.Upload(path.Join(outputDirectory, exportPath, options.Filename), options.Bucket, key)
-			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
-			}
+// uploadError returns the error which is reported to clients when uploading
+// the image to target `t` failed with `err`.
+func uploadError(t *target.Target, err error) *clienterrors.Error {
+	clientErr := clienterrors.Wrap(clienterrors.ErrorUploadFailed, err)
+	clientErr.Details = map[string]string{"target": t.Name}
+	return clientErr
+}
 
-			ami, err := a.Register(args.Targets[0].ImageName, options.Bucket, key, options.ShareWithAccounts, common.CurrentArch())
-			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
-			}
+// upload uploads the image in `exportDirectory` to target `t`. VMWare targets
+// upload the stream optimized image at `streamOptimizedPath` instead.
+func (impl *OSBuildJobImpl) upload(ctx context.Context, jobID uuid.UUID, t *target.Target, exportDirectory, streamOptimizedPath string) (*target.TargetResult, error) {
+	_, span := tracing.Start(ctx, "upload", attribute.String("target.name", t.Name))
+	defer span.End()
 
-			if ami == nil {
-				appendTargetError(osbuildJobResult, fmt.Errorf("No ami returned"))
-				return nil
-			}
+	result, err := impl.uploadTarget(jobID, t, exportDirectory, streamOptimizedPath)
+	tracing.RecordError(span, err)
+	return result, err
+}
 
-			osbuildJobResult.TargetResults = append(osbuildJobResult.TargetResults, target.NewAWSTargetResult(&target.AWSTargetResultOptions{
-				Ami:    *ami,
-				Region: options.Region,
-			}))
+func (impl *OSBuildJobImpl) uploadTarget(jobID uuid.UUID, t *target.Target, exportDirectory, streamOptimizedPath string) (*target.TargetResult, error) {
+	switch options := t.Options.(type) {
+	case *target.VMWareTargetOptions:
+		credentials := vmware.Credentials{
+			Username:   options.Username,
+			Password:   options.Password,
+			Host:       options.Host,
+			Cluster:    options.Cluster,
+			Datacenter: options.Datacenter,
+			Datastore:  options.Datastore,
+		}
 
-			osbuildJobResult.Success = true
-			osbuildJobResult.UploadStatus = "success"
-		case *target.AWSS3TargetOptions:
-			a, err := awsupload.New(options.Region, options.AccessKeyID, options.SecretAccessKey, "")
+		tempDirectory, err := ioutil.TempDir(impl.Output, jobID.String()+"-vmware-*")
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() {
+			err := os.RemoveAll(tempDirectory)
 			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
+				log.Printf("Error removing temporary directory for vmware symlink(%s): %v", tempDirectory, err)
 			}
+		}()
 
-			key := options.Key
-			if key == "" {
-				key = uuid.New().String()
-			}
-			key += "-" + options.Filename
+		// create a symlink so that uploaded image has the name specified by user
+		imageName := t.ImageName + ".vmdk"
+		imagePath := path.Join(tempDirectory, imageName)
+		err = os.Symlink(streamOptimizedPath, imagePath)
+		if err != nil {
+			return nil, err
+		}
 
-			_, err = a.Upload(path.Join(outputDirectory, exportPath, options.Filename), options.Bucket, key)
-			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
-			}
-			url, err := a.S3ObjectPresignedURL(options.Bucket, key)
-			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
-			}
+		err = vmware.UploadImage(credentials, imagePath)
+		if err != nil {
+			return nil, err
+		}
 
-			osbuildJobResult.TargetResults = append(osbuildJobResult.TargetResults, target.NewAWSS3TargetResult(&target.AWSS3TargetResultOptions{URL: url}))
+		return nil, nil
+	case *target.AWSTargetOptions:
+		a, err := awsupload.NewWithCredentials(options.Region, awsCredentials(options.S3Credentials()), awsCredentials(options.EC2Credentials()))
+		if err != nil {
+			return nil, err
+		}
 
-			osbuildJobResult.Success = true
-			osbuildJobResult.UploadStatus = "success"
-		case *target.AzureTargetOptions:
-			azureStorageClient, err := azure.NewStorageClient(options.StorageAccount, options.StorageAccessKey)
-			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return err
-			}
+		key := options.Key
+		if key == "" {
+			key = uuid.New().String()
+		}
 
-			metadata := azure.BlobMetadata{
-				StorageAccount: options.StorageAccount,
-				ContainerName:  options.Container,
-				BlobName:       args.Targets[0].ImageName,
-			}
+		_, err = a.Upload(path.Join(exportDirectory, options.Filename), options.Bucket, key)
+		if err != nil {
+			return nil, err
+		}
 
-			const azureMaxUploadGoroutines = 4
-			err = azureStorageClient.UploadPageBlob(
-				metadata,
-				path.Join(outputDirectory, exportPath, options.Filename),
-				azureMaxUploadGoroutines,
-			)
+		ami, err := a.Register(t.ImageName, options.Bucket, key, options.ShareWithAccounts, common.CurrentArch(), options.Tags)
+		if err != nil {
+			return nil, err
+		}
 
-			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
-			}
+		if ami == nil {
+			return nil, fmt.Errorf("No ami returned")
+		}
 
-			osbuildJobResult.Success = true
-			osbuildJobResult.UploadStatus = "success"
-		case *target.GCPTargetOptions:
-			ctx := context.Background()
+		result := &target.AWSTargetResultOptions{
+			Ami:    *ami,
+			Region: options.Region,
+		}
 
-			g, err := gcp.New(impl.GCPCreds)
+		for _, region := range options.CopyToRegions {
+			c, err := awsupload.NewWithCredentials(region, awsCredentials(options.S3Credentials()), awsCredentials(options.EC2Credentials()))
 			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
+				return target.NewAWSTargetResult(result), err
 			}
 
-			log.Printf("[GCP] 🚀 Uploading image to: %s/%s", options.Bucket, options.Object)
-			_, err = g.StorageObjectUpload(ctx, path.Join(outputDirectory, exportPath, options.Filename),
-				options.Bucket, options.Object, map[string]string{gcp.MetadataKeyImageName: args.Targets[0].ImageName})
+			copiedAmi, err := c.CopyImage(t.ImageName, *ami, options.Region, options.ShareWithAccounts, options.Tags)
 			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
+				return target.NewAWSTargetResult(result), fmt.Errorf("copying the AMI to %s failed: %w", region, err)
 			}
 
-			log.Printf("[GCP] 📥 Importing image into Compute Engine as '%s'", args.Targets[0].ImageName)
-			imageBuild, importErr := g.ComputeImageImport(ctx, options.Bucket, options.Object, args.Targets[0].ImageName, options.Os, options.Region)
-			if imageBuild != nil {
-				log.Printf("[GCP] 📜 Image import log URL: %s", imageBuild.LogUrl)
-				log.Printf("[GCP] 🎉 Image import finished with status: %s", imageBuild.Status)
+			result.Copies = append(result.Copies, target.AWSTargetResultOptions{
+				Ami:    copiedAmi,
+				Region: region,
+			})
+		}
 
-				// Cleanup all resources potentially left after the image import job
-				deleted, err := g.CloudbuildBuildCleanup(ctx, imageBuild.Id)
-				for _, d := range deleted {
-					log.Printf("[GCP] 🧹 Deleted resource after image import job: %s", d)
-				}
-				if err != nil {
-					log.Printf("[GCP] Encountered error during image import cleanup: %v", err)
-				}
-			}
+		return target.NewAWSTargetResult(result), nil
+	case *target.AWSS3TargetOptions:
+		creds := awsCredentials(options.Credentials())
+		a, err := awsupload.NewWithCredentials(options.Region, creds, creds)
+		if err != nil {
+			return nil, err
+		}
 
-			// Cleanup storage before checking for errors
-			log.Printf("[GCP] 🧹 Deleting uploaded image file: %s/%s", options.Bucket, options.Object)
-			if err = g.StorageObjectDelete(ctx, options.Bucket, options.Object); err != nil {
-				log.Printf("[GCP] Encountered error while deleting object: %v", err)
-			}
+		url, err := uploadToS3(a, exportDirectory, options)
+		if err != nil {
+			return nil, err
+		}
 
-			// check error from ComputeImageImport()
-			if importErr != nil {
-				appendTargetError(osbuildJobResult, importErr)
-				return nil
-			}
-			log.Printf("[GCP] 💿 Image URL: %s", g.ComputeImageURL(args.Targets[0].ImageName))
-
-			if len(options.ShareWithAccounts) > 0 {
-				log.Printf("[GCP] 🔗 Sharing the image with: %+v", options.ShareWithAccounts)
-				err = g.ComputeImageShare(ctx, args.Targets[0].ImageName, options.ShareWithAccounts)
-				if err != nil {
-					appendTargetError(osbuildJobResult, err)
-					return nil
-				}
-			}
+		return target.NewAWSS3TargetResult(&target.AWSS3TargetResultOptions{URL: url}), nil
+	case *target.GenericS3TargetOptions:
+		a, err := awsupload.NewForEndpoint(options.Endpoint, options.Region, options.AccessKeyID, options.SecretAccessKey, options.SessionToken, options.CABundle, options.SkipSSLVerification)
+		if err != nil {
+			return nil, err
+		}
 
-			osbuildJobResult.TargetResults = append(osbuildJobResult.TargetResults, target.NewGCPTargetResult(&target.GCPTargetResultOptions{
-				ImageName: args.Targets[0].ImageName,
-				ProjectID: g.GetProjectID(),
-			}))
+		url, err := uploadToS3(a, exportDirectory, &options.AWSS3TargetOptions)
+		if err != nil {
+			return nil, err
+		}
 
-			osbuildJobResult.Success = true
-			osbuildJobResult.UploadStatus = "success"
-		case *target.AzureImageTargetOptions:
-			ctx := context.Background()
+		return target.NewGenericS3TargetResult(&target.GenericS3TargetResultOptions{URL: url}), nil
+	case *target.AzureTargetOptions:
+		ctx := context.Background()
 
+		// The service principal is needed for registering an
+		// image, and for uploading when no other credentials are
+		// given.
+		var c *azure.Client
+		var err error
+		if options.ImageName != "" || (options.StorageAccessKey == "" && options.SASToken == "") {
 			if impl.AzureCreds == nil {
-				appendTargetError(osbuildJobResult, fmt.Errorf("osbuild job has org.osbuild.azure.image target but this worker doesn't have azure credentials"))
-				return nil
+				return nil, fmt.Errorf("osbuild job has org.osbuild.azure target which needs azure credentials, but this worker doesn't have them")
 			}
-
-			c, err := azure.NewClient(*impl.AzureCreds, options.TenantID)
+			c, err = azure.NewClient(*impl.AzureCreds, options.TenantID)
 			if err != nil {
-				appendTargetError(osbuildJobResult, err)
-				return nil
+				return nil, err
 			}
-			log.Print("[Azure] 🔑 Logged in Azure")
+		}
 
-			storageAccountTag := azure.Tag{
-				Name:  "imageBuilderStorageAccount",
-				Value: fmt.Sprintf("location=%s", options.Location),
+		var azureStorageClient *azure.StorageClient
+		switch {
+		case options.SASToken != "":
+			azureStorageClient = azure.NewStorageClientWithSASToken(options.SASToken)
+		case options.StorageAccessKey != "":
+			azureStorageClient, err = azure.NewStorageClient(options.StorageAccount, options.StorageAccessKey)
+		default:
+			log.Print("[Azure] 🔑📦 Retrieving a storage account key")
+			var storageAccessKey string
+			storageAccessKey, err = c.GetStorageAccountKey(ctx, options.SubscriptionID, options.ResourceGroup, options.StorageAccount)
+			if err == nil {
+				azureStorageClient, err = azure.NewStorageClient(options.StorageAccount, storageAccessKey)
 			}
+		}
+		if err != nil {
+			return nil, err
+		}
 
-			storageAccount, err := c.GetResourceNameByTag(
-				ctx,
-				options.SubscriptionID,
-				options.ResourceGroup,
-				storageAccountTag,
-			)
-			if err != nil {
-				appendTargetError(osbuildJobResult, fmt.Errorf("searching for a storage account failed: %v", err))
-				return nil
-			}
+		metadata := azure.BlobMetadata{
+			StorageAccount: options.StorageAccount,
+			ContainerName:  options.Container,
+			BlobName:       t.ImageName,
+		}
+		if !strings.HasSuffix(metadata.BlobName, ".vhd") {
+			metadata.BlobName += ".vhd"
+		}
 
-			if storageAccount == "" {
-				log.Print("[Azure] 📦 Creating a new storage account")
-				const storageAccountPrefix = "ib"
-				storageAccount = azure.RandomStorageAccountName(storageAccountPrefix)
-
-				err := c.CreateStorageAccount(
-					ctx,
-					options.SubscriptionID,
-					options.ResourceGroup,
-					storageAccount,
-					options.Location,
-					storageAccountTag,
-				)
-				if err != nil {
-					appendTargetError(osbuildJobResult, fmt.Errorf("creating a new storage account failed: %v", err))
-					return nil
+		result := &target.AzureTargetResultOptions{
+			BlobURL: azure.BlobURL(metadata),
+		}
+
+		log.Printf("[Azure] ⬆ Uploading the image to %s", result.BlobURL)
+		const azureMaxUploadGoroutines = 4
+		lastReported := 0
+		err = azureStorageClient.UploadPageBlobWithProgress(
+			metadata,
+			path.Join(exportDirectory, options.Filename),
+			azureMaxUploadGoroutines,
+			func(uploaded, total int64) {
+				result.UploadedBytes = uploaded
+				result.TotalBytes = total
+				if percent := int(uploaded * 100 / total); percent >= lastReported+10 {
+					log.Printf("[Azure] ⬆ Uploaded %d%% (%d of %d bytes)", percent, uploaded, total)
+					lastReported = percent
 				}
-			}
+			},
+		)
 
-			log.Print("[Azure] 🔑📦 Retrieving a storage account key")
-			storageAccessKey, err := c.GetStorageAccountKey(
+		if err != nil {
+			return target.NewAzureTargetResult(result), err
+		}
+
+		if options.ImageName != "" {
+			log.Printf("[Azure] 📝 Registering the image as '%s'", options.ImageName)
+			err = c.RegisterImage(
 				ctx,
 				options.SubscriptionID,
 				options.ResourceGroup,
-				storageAccount,
+				options.StorageAccount,
+				options.Container,
+				metadata.BlobName,
+				options.ImageName,
+				options.Location,
 			)
 			if err != nil {
-				appendTargetError(osbuildJobResult, fmt.Errorf("retrieving the storage account key failed: %v", err))
-				return nil
+				return target.NewAzureTargetResult(result), fmt.Errorf("registering the image failed: %w", err)
 			}
+			result.ImageName = options.ImageName
+		}
+
+		return target.NewAzureTargetResult(result), nil
+	case *target.GCPTargetOptions:
+		ctx := context.Background()
+
+		creds := impl.GCPCreds
+		if len(options.Credentials) > 0 {
+			creds = options.Credentials
+		}
+		g, err := gcp.New(creds)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Printf("[GCP] 🚀 Uploading image to: %s/%s", options.Bucket, options.Object)
+		_, err = g.StorageObjectUpload(ctx, path.Join(exportDirectory, options.Filename),
+			options.Bucket, options.Object, map[string]string{gcp.MetadataKeyImageName: t.ImageName})
+		if err != nil {
+			return nil, err
+		}
+
+		// Guest OS features can only be enabled when creating an
+		// image. Import under a temporary name and copy the image
+		// with the features enabled afterwards.
+		importName := t.ImageName
+		if len(options.GuestOSFeatures) > 0 {
+			importName += "-import"
+		}
 
-			azureStorageClient, err := azure.NewStorageClient(storageAccount, storageAccessKey)
+		log.Printf("[GCP] 📥 Importing image into Compute Engine as '%s'", importName)
+		imageBuild, importErr := g.ComputeImageImport(ctx, options.Bucket, options.Object, importName, options.Os, options.Region)
+		if imageBuild != nil {
+			log.Printf("[GCP] 📜 Image import log URL: %s", imageBuild.LogUrl)
+			log.Printf("[GCP] 🎉 Image import finished with status: %s", imageBuild.Status)
+
+			// Cleanup all resources potentially left after the image import job
+			deleted, err := g.CloudbuildBuildCleanup(ctx, imageBuild.Id)
+			for _, d := range deleted {
+				log.Printf("[GCP] 🧹 Deleted resource after image import job: %s", d)
+			}
 			if err != nil {
-				appendTargetError(osbuildJobResult, fmt.Errorf("creating the storage client failed: %v", err))
-				return nil
+				log.Printf("[GCP] Encountered error during image import cleanup: %v", err)
 			}
+		}
 
-			storageContainer := "imagebuilder"
+		// Cleanup storage before checking for errors
+		log.Printf("[GCP] 🧹 Deleting uploaded image file: %s/%s", options.Bucket, options.Object)
+		if err = g.StorageObjectDelete(ctx, options.Bucket, options.Object); err != nil {
+			log.Printf("[GCP] Encountered error while deleting object: %v", err)
+		}
 
-			log.Print("[Azure] 📦 Ensuring that we have a storage container")
-			err = azureStorageClient.CreateStorageContainerIfNotExist(ctx, storageAccount, storageContainer)
-			if err != nil {
-				appendTargetError(osbuildJobResult, fmt.Errorf("cannot create a storage container: %v", err))
-				return nil
+		// check error from ComputeImageImport()
+		if importErr != nil {
+			return nil, importErr
+		}
+
+		if len(options.GuestOSFeatures) > 0 {
+			log.Printf("[GCP] 🧬 Enabling guest OS features: %+v", options.GuestOSFeatures)
+			copyErr := g.ComputeImageCopy(ctx, importName, t.ImageName, options.GuestOSFeatures)
+			if err = g.ComputeImageDelete(ctx, importName); err != nil {
+				log.Printf("[GCP] Encountered error while deleting image '%s': %v", importName, err)
 			}
+			if copyErr != nil {
+				return nil, copyErr
+			}
+		}
+		log.Printf("[GCP] 💿 Image URL: %s", g.ComputeImageURL(t.ImageName))
 
-			blobName := args.Targets[0].ImageName
-			if !strings.HasSuffix(blobName, ".vhd") {
-				blobName += ".vhd"
+		// ComputeImageShare() replaces the image's policy, share
+		// with accounts and projects at once
+		shareWith := options.ShareWithAccounts
+		if len(options.ShareWithProjects) > 0 {
+			members, err := g.ComputeProjectMembers(ctx, options.ShareWithProjects)
+			if err != nil {
+				return nil, err
 			}
+			shareWith = append(shareWith, members...)
+		}
 
-			log.Print("[Azure] ⬆ Uploading the image")
-			err = azureStorageClient.UploadPageBlob(
-				azure.BlobMetadata{
-					StorageAccount: storageAccount,
-					ContainerName:  storageContainer,
-					BlobName:       blobName,
-				},
-				path.Join(outputDirectory, exportPath, options.Filename),
-				azure.DefaultUploadThreads,
-			)
+		if len(shareWith) > 0 {
+			log.Printf("[GCP] 🔗 Sharing the image with: %+v", shareWith)
+			err = g.ComputeImageShare(ctx, t.ImageName, shareWith)
 			if err != nil {
-				appendTargetError(osbuildJobResult, fmt.Errorf("uploading the image failed: %v", err))
-				return nil
+				return nil, err
 			}
+		}
 
-			log.Print("[Azure] 📝 Registering the image")
-			err = c.RegisterImage(
+		return target.NewGCPTargetResult(&target.GCPTargetResultOptions{
+			ImageName: t.ImageName,
+			ProjectID: g.GetProjectID(),
+		}), nil
+	case *target.AzureImageTargetOptions:
+		ctx := context.Background()
+
+		creds := impl.AzureCreds
+		if options.ClientID != "" {
+			creds = azure.NewCredentials(options.ClientID, options.ClientSecret)
+		}
+		if creds == nil {
+			return nil, fmt.Errorf("osbuild job has org.osbuild.azure.image target but this worker doesn't have azure credentials")
+		}
+
+		c, err := azure.NewClient(*creds, options.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		log.Print("[Azure] 🔑 Logged in Azure")
+
+		storageAccountTag := azure.Tag{
+			Name:  "imageBuilderStorageAccount",
+			Value: fmt.Sprintf("location=%s", options.Location),
+		}
+
+		storageAccount, err := c.GetResourceNameByTag(
+			ctx,
+			options.SubscriptionID,
+			options.ResourceGroup,
+			storageAccountTag,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("searching for a storage account failed: %w", err)
+		}
+
+		if storageAccount == "" {
+			log.Print("[Azure] 📦 Creating a new storage account")
+			const storageAccountPrefix = "ib"
+			storageAccount = azure.RandomStorageAccountName(storageAccountPrefix)
+
+			err := c.CreateStorageAccount(
 				ctx,
 				options.SubscriptionID,
 				options.ResourceGroup,
 				storageAccount,
-				storageContainer,
-				blobName,
-				args.Targets[0].ImageName,
 				options.Location,
+				storageAccountTag,
 			)
 			if err != nil {
-				appendTargetError(osbuildJobResult, fmt.Errorf("registering the image failed: %v", err))
-				return nil
+				return nil, fmt.Errorf("creating a new storage account failed: %w", err)
 			}
+		}
 
-			log.Print("[Azure] 🎉 Image uploaded and registered!")
+		log.Print("[Azure] 🔑📦 Retrieving a storage account key")
+		storageAccessKey, err := c.GetStorageAccountKey(
+			ctx,
+			options.SubscriptionID,
+			options.ResourceGroup,
+			storageAccount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving the storage account key failed: %w", err)
+		}
 
-			osbuildJobResult.TargetResults = append(osbuildJobResult.TargetResults, target.NewAzureImageTargetResult(&target.AzureImageTargetResultOptions{
-				ImageName: args.Targets[0].ImageName,
-			}))
+		azureStorageClient, err := azure.NewStorageClient(storageAccount, storageAccessKey)
+		if err != nil {
+			return nil, fmt.Errorf("creating the storage client failed: %w", err)
+		}
 
-			osbuildJobResult.Success = true
-			osbuildJobResult.UploadStatus = "success"
-		default:
-			err = fmt.Errorf("invalid target type: %s", args.Targets[0].Name)
-			appendTargetError(osbuildJobResult, err)
-			return nil
+		storageContainer := "imagebuilder"
+
+		log.Print("[Azure] 📦 Ensuring that we have a storage container")
+		err = azureStorageClient.CreateStorageContainerIfNotExist(ctx, storageAccount, storageContainer)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create a storage container: %v", err)
 		}
-	}
 
-	return nil
+		blobName := t.ImageName
+		if !strings.HasSuffix(blobName, ".vhd") {
+			blobName += ".vhd"
+		}
+
+		log.Print("[Azure] ⬆ Uploading the image")
+		err = azureStorageClient.UploadPageBlob(
+			azure.BlobMetadata{
+				StorageAccount: storageAccount,
+				ContainerName:  storageContainer,
+				BlobName:       blobName,
+			},
+			path.Join(exportDirectory, options.Filename),
+			azure.DefaultUploadThreads,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("uploading the image failed: %w", err)
+		}
+
+		log.Print("[Azure] 📝 Registering the image")
+		err = c.RegisterImage(
+			ctx,
+			options.SubscriptionID,
+			options.ResourceGroup,
+			storageAccount,
+			storageContainer,
+			blobName,
+			t.ImageName,
+			options.Location,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("registering the image failed: %w", err)
+		}
+
+		log.Print("[Azure] 🎉 Image uploaded and registered!")
+
+		return target.NewAzureImageTargetResult(&target.AzureImageTargetResultOptions{
+			ImageName: t.ImageName,
+		}), nil
+	case *target.OCITargetOptions:
+		ctx := context.Background()
+
+		if impl.OCICreds == nil {
+			return nil, fmt.Errorf("osbuild job has org.osbuild.oci target but this worker doesn't have oci credentials")
+		}
+
+		c := oci.NewClient(*impl.OCICreds, options.Region)
+
+		imagePath := path.Join(exportDirectory, options.Filename)
+		file, err := os.Open(imagePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		stat, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+
+		log.Printf("[OCI] 🚀 Uploading image to: %s/%s/%s", options.Namespace, options.Bucket, options.Object)
+		err = c.UploadObject(ctx, options.Namespace, options.Bucket, options.Object, file, stat.Size())
+		if err != nil {
+			return nil, err
+		}
+
+		log.Printf("[OCI] 📥 Importing image '%s' into compartment %s", t.ImageName, options.Compartment)
+		imageID, importErr := c.ImportImage(ctx, options.Compartment, options.Namespace, options.Bucket, options.Object, t.ImageName)
+		if importErr == nil {
+			log.Printf("[OCI] ⏳ Waiting for image %s to become available", imageID)
+			importErr = c.WaitForImage(ctx, imageID)
+		}
+
+		// Cleanup storage before checking for errors
+		log.Printf("[OCI] 🧹 Deleting uploaded image file: %s/%s", options.Bucket, options.Object)
+		if err = c.DeleteObject(ctx, options.Namespace, options.Bucket, options.Object); err != nil {
+			log.Printf("[OCI] Encountered error while deleting object: %v", err)
+		}
+
+		if importErr != nil {
+			return nil, importErr
+		}
+
+		log.Printf("[OCI] 🎉 Image imported: %s", imageID)
+
+		return target.NewOCITargetResult(&target.OCITargetResultOptions{
+			ImageID: imageID,
+			Region:  options.Region,
+		}), nil
+	default:
+		return nil, fmt.Errorf("invalid target type: %s", t.Name)
+	}
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+
+	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
+)
+
+// UploadJobImpl uploads an image which an osbuild job has built to one more
+// target. It downloads the image from composer, to which the osbuild job has
+// uploaded it as an artifact, and uploads it like the osbuild job does.
+type UploadJobImpl struct {
+	OSBuild *OSBuildJobImpl
+}
+
+func (impl *UploadJobImpl) Run(ctx context.Context, job worker.Job) error {
+	result := &worker.UploadJobResult{
+		UploadStatus: "failure",
+	}
+
+	fail := func(err *clienterrors.Error) error {
+		log.Printf("target failed: %v", err)
+		result.TargetErrors = append(result.TargetErrors, err.Error())
+		result.Failure = jobFailure(err)
+		result.JobError = err
+		return job.Update(result)
+	}
+
+	var args worker.UploadJob
+	err := job.Args(&args)
+	if err != nil {
+		return err
+	}
+
+	var imageResult worker.OSBuildJobResult
+	err = job.DynamicArgs(0, &imageResult)
+	if err != nil {
+		return err
+	}
+	if imageResult.OSBuildOutput == nil || !imageResult.OSBuildOutput.Success {
+		return fail(dependencyError("the image was not built", imageResult.JobError))
+	}
+
+	directory, err := ioutil.TempDir(impl.OSBuild.Output, job.Id().String()+"-*")
+	if err != nil {
+		return fail(clienterrors.Errorf(clienterrors.ErrorWorker, "error creating temporary directory: %w", err))
+	}
+	defer func() {
+		err := os.RemoveAll(directory)
+		if err != nil {
+			log.Printf("Error removing temporary directory (%s): %v", directory, err)
+		}
+	}()
+
+	imagePath := path.Join(directory, args.ImageName)
+	f, err := os.Create(imagePath)
+	if err != nil {
+		return fail(clienterrors.Wrap(clienterrors.ErrorWorker, err))
+	}
+	err = job.DownloadDependencyArtifact(0, args.ImageName, f)
+	f.Close()
+	if err != nil {
+		return fail(clienterrors.Wrap(clienterrors.ErrorWorker, err))
+	}
+
+	// the osbuild job uploads the converted image for stream optimized
+	// images
+	streamOptimizedPath := ""
+	if args.StreamOptimized {
+		streamOptimizedPath = imagePath
+	}
+
+	targetResult, err := impl.OSBuild.upload(ctx, job.Id(), args.Target, directory, streamOptimizedPath)
+	if targetResult != nil {
+		result.TargetResults = append(result.TargetResults, targetResult)
+	}
+	if err != nil {
+		return fail(uploadError(args.Target, err))
+	}
+
+	result.Success = true
+	result.UploadStatus = "success"
+	return job.Update(result)
+}
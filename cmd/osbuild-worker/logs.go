@@ -0,0 +1,105 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// The log of a build is sent to composer when this much of it has been
+// written, or after this interval, whichever comes first.
+const logChunkSize = 64 * 1024
+const logSendInterval = 2 * time.Second
+
+// logStreamer sends the log of an osbuild job to composer while osbuild
+// writes it, so that users can follow the build. Writes never block on
+// composer: the log is buffered and sent in the background. Streaming stops
+// at the first chunk which cannot be sent, because the log composer has
+// would have a gap otherwise.
+type logStreamer struct {
+	job worker.Job
+
+	mu     sync.Mutex
+	buffer []byte
+	failed bool
+
+	full    chan struct{}
+	stopped chan struct{}
+	done    chan struct{}
+}
+
+func newLogStreamer(job worker.Job) *logStreamer {
+	s := &logStreamer{
+		job:     job,
+		full:    make(chan struct{}, 1),
+		stopped: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.send()
+	return s
+}
+
+func (s *logStreamer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed {
+		return len(p), nil
+	}
+
+	s.buffer = append(s.buffer, p...)
+	if len(s.buffer) >= logChunkSize {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+
+	return len(p), nil
+}
+
+func (s *logStreamer) send() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(logSendInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.full:
+		case <-ticker.C:
+		case <-s.stopped:
+			s.flush()
+			return
+		}
+		s.flush()
+	}
+}
+
+func (s *logStreamer) flush() {
+	s.mu.Lock()
+	chunk := s.buffer
+	s.buffer = nil
+	failed := s.failed
+	s.mu.Unlock()
+
+	if failed || len(chunk) == 0 {
+		return
+	}
+
+	err := s.job.AppendLog(chunk)
+	if err != nil {
+		log.Printf("Error sending log of job %s, not sending any more of it: %v", s.job.Id(), err)
+		s.mu.Lock()
+		s.failed = true
+		s.mu.Unlock()
+	}
+}
+
+// stop sends the rest of the log and waits until it has been sent.
+func (s *logStreamer) stop() {
+	close(s.stopped)
+	<-s.done
+}
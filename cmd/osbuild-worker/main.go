@@ -11,15 +11,26 @@ import (
 	"log"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/osbuild/osbuild-composer/internal/common"
+	"github.com/osbuild/osbuild-composer/internal/distroregistry"
+	"github.com/osbuild/osbuild-composer/internal/logging"
+	"github.com/osbuild/osbuild-composer/internal/rhsm"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/sentry"
+	"github.com/osbuild/osbuild-composer/internal/tracing"
 	"github.com/osbuild/osbuild-composer/internal/upload/azure"
 	"github.com/osbuild/osbuild-composer/internal/upload/koji"
+	"github.com/osbuild/osbuild-composer/internal/upload/oci"
 	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/amqpbroker"
 )
 
 const configFile = "/etc/osbuild-worker/osbuild-worker.toml"
@@ -31,8 +42,36 @@ type connectionConfig struct {
 }
 
 // Represents the implementation of a job type as defined by the worker API.
+// `ctx` is canceled when the job is canceled.
 type JobImplementation interface {
-	Run(job worker.Job) error
+	Run(ctx context.Context, job worker.Job) error
+}
+
+// Collects the capabilities the worker advertises when registering.
+// Information which cannot be determined is left out.
+func workerInfo(jobTypes []string, buildDir string) worker.WorkerInfo {
+	info := worker.WorkerInfo{
+		Arch:     common.CurrentArch(),
+		JobTypes: jobTypes,
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Printf("Could not determine hostname: %v", err)
+	}
+	info.Hostname = hostname
+
+	info.FreeDisk, err = freeDisk(buildDir)
+	if err != nil {
+		log.Printf("Could not determine free disk space in %s: %v", buildDir, err)
+	}
+
+	info.OSBuildVersion, err = OSBuildVersion()
+	if err != nil {
+		log.Printf("Could not determine osbuild version: %v", err)
+	}
+
+	return info
 }
 
 func createTLSConfig(config *connectionConfig) (*tls.Config, error) {
@@ -58,19 +97,14 @@ func createTLSConfig(config *connectionConfig) (*tls.Config, error) {
 	}, nil
 }
 
-// Regularly ask osbuild-composer if the compose we're currently working on was
-// canceled and exit the process if it was.
-// It would be cleaner to kill the osbuild process using (`exec.CommandContext`
-// or similar), but osbuild does not currently support this. Exiting here will
-// make systemd clean up the whole cgroup and restart this service.
-func WatchJob(ctx context.Context, job worker.Job) {
+// Regularly send a heartbeat to osbuild-composer until `ctx` is done.
+func SendHeartbeats(ctx context.Context, job worker.Job) {
 	for {
 		select {
 		case <-time.After(15 * time.Second):
-			canceled, err := job.Canceled()
-			if err == nil && canceled {
-				log.Println("Job was canceled. Exiting.")
-				os.Exit(0)
+			err := job.Heartbeat()
+			if err != nil {
+				log.Printf("Error sending heartbeat for job %s: %v", job.Id(), err)
 			}
 		case <-ctx.Done():
 			return
@@ -78,6 +112,34 @@ func WatchJob(ctx context.Context, job worker.Job) {
 	}
 }
 
+// Wait for the job to be canceled and call `cancel` when it is. Returns when
+// `ctx` is done.
+//
+// Cancellations are pushed by osbuild-composer over a long-polling request.
+// When that fails (for example, because composer is too old to support it),
+// fall back to asking composer for the job's status every 15 seconds.
+func WatchJob(ctx context.Context, job worker.Job, cancel context.CancelFunc) {
+	for {
+		canceled, err := job.WaitForCancellation(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Error waiting for cancellation of job %s: %v", job.Id(), err)
+			select {
+			case <-time.After(15 * time.Second):
+			case <-ctx.Done():
+				return
+			}
+			canceled, err = job.Canceled()
+		}
+		if err == nil && canceled {
+			cancel()
+			return
+		}
+	}
+}
+
 func main() {
 	var config struct {
 		KojiServers map[string]struct {
@@ -92,10 +154,44 @@ func main() {
 		Azure *struct {
 			Credentials string `toml:"credentials"`
 		} `toml:"azure"`
+		OCI *struct {
+			Credentials string `toml:"credentials"`
+		} `toml:"oci"`
+		Broker *struct {
+			URL string `toml:"url"`
+		} `toml:"broker"`
 		Authentication *struct {
 			OAuthURL         string `toml:"oauth_url"`
 			OfflineTokenPath string `toml:"offline_token"`
 		} `toml:"authentication"`
+		RHSM struct {
+			EntitlementDir string `toml:"entitlement_dir"`
+			CACert         string `toml:"ca_cert"`
+		} `toml:"rhsm"`
+		// OTLP/HTTP endpoint of an OpenTelemetry collector which
+		// receives the spans of jobs
+		Tracing struct {
+			Endpoint string `toml:"endpoint"`
+		} `toml:"tracing"`
+		// DSN of a Sentry or GlitchTip project, to which panics
+		// and errors running jobs are reported
+		Sentry struct {
+			DSN string `toml:"dsn"`
+		} `toml:"sentry"`
+		// Osbuild jobs are given back to composer when the cache
+		// directory has less free space than three times the size of
+		// the image plus `min_free_disk`, or when less than
+		// `min_free_memory` of memory is available, in bytes
+		Resources struct {
+			MinFreeDisk   uint64 `toml:"min_free_disk"`
+			MinFreeMemory uint64 `toml:"min_free_memory"`
+		} `toml:"resources"`
+		// The osbuild store is kept below `max_size` bytes by evicting
+		// the least recently used objects and sources between jobs.
+		// Zero means no limit.
+		Cache struct {
+			MaxSize uint64 `toml:"max_size"`
+		} `toml:"cache"`
 	}
 	var unix bool
 	flag.BoolVar(&unix, "unix", false, "Interpret 'address' as a path to a unix domain socket instead of a network address")
@@ -202,18 +298,49 @@ func main() {
 		}
 	}
 
+	// Load OCI credentials early, for the same reason as the Azure ones.
+	var ociCredentials *oci.Credentials
+	if config.OCI != nil {
+		ociCredentials, err = oci.ParseOCICredentialsFile(config.OCI.Credentials)
+		if err != nil {
+			log.Fatalf("cannot load oci credentials: %v", err)
+		}
+	}
+
+	// osbuild looks up the secrets of org.osbuild.rhsm sources itself, in
+	// the default location
+	entitlements := rhsm.NewEntitlements(config.RHSM.EntitlementDir, config.RHSM.CACert)
+	if config.RHSM.EntitlementDir != "" && filepath.Clean(config.RHSM.EntitlementDir) != rhsm.DefaultEntitlementDir {
+		log.Printf("Entitlements in %s are only used to depsolve, osbuild fetches packages with the ones in %s", config.RHSM.EntitlementDir, rhsm.DefaultEntitlementDir)
+	}
+
+	// Generic distributions are needed to generate manifests, load their
+	// definitions like composer does.
+	distros, err := distroregistry.NewDefaultWithDefinitions("/etc/osbuild-composer/distros", "/usr/share/osbuild-composer/distros")
+	if err != nil {
+		log.Fatalf("cannot load distro definitions: %v", err)
+	}
+
+	cache := newStoreCache(store, config.Cache.MaxSize)
+	cache.Collect()
+
+	osbuildJobImpl := &OSBuildJobImpl{
+		Store:       store,
+		Output:      output,
+		KojiServers: kojiServers,
+		GCPCreds:    gcpCredentials,
+		AzureCreds:  azureCredentials,
+		OCICreds:    ociCredentials,
+		Cache:       cache,
+	}
+
 	jobImpls := map[string]JobImplementation{
-		"osbuild": &OSBuildJobImpl{
-			Store:       store,
-			Output:      output,
-			KojiServers: kojiServers,
-			GCPCreds:    gcpCredentials,
-			AzureCreds:  azureCredentials,
-		},
+		"osbuild": osbuildJobImpl,
 		"osbuild-koji": &OSBuildKojiJobImpl{
 			Store:       store,
 			Output:      output,
 			KojiServers: kojiServers,
+			Cache:       cache,
 		},
 		"koji-init": &KojiInitJobImpl{
 			KojiServers: kojiServers,
@@ -221,6 +348,32 @@ func main() {
 		"koji-finalize": &KojiFinalizeJobImpl{
 			KojiServers: kojiServers,
 		},
+		"depsolve": &DepsolveJobImpl{
+			RPMMD: rpmmd.NewRPMMDWithEntitlements(path.Join(cacheDirectory, "rpmmd"), "/usr/libexec/osbuild-composer/dnf-json", entitlements),
+		},
+		"manifest-id-only": &ManifestJobByIDImpl{
+			Distros: distros,
+		},
+		"compose": &ComposeJobImpl{},
+		"upload": &UploadJobImpl{
+			OSBuild: osbuildJobImpl,
+		},
+	}
+
+	var tracer *tracing.Tracer
+	if config.Tracing.Endpoint != "" {
+		tracer, err = tracing.NewTracer("osbuild-worker", config.Tracing.Endpoint)
+		if err != nil {
+			log.Fatalf("Error setting up tracing: %v", err)
+		}
+	}
+
+	var reporter *sentry.Client
+	if config.Sentry.DSN != "" {
+		reporter, err = sentry.NewClient(config.Sentry.DSN, map[string]string{"service": "osbuild-worker"})
+		if err != nil {
+			log.Fatalf("Error setting up error reporting: %v", err)
+		}
 	}
 
 	acceptedJobTypes := []string{}
@@ -228,31 +381,138 @@ func main() {
 		acceptedJobTypes = append(acceptedJobTypes, jt)
 	}
 
+	// Announce the worker's capabilities, so that composer only hands out
+	// jobs it can run. Older composers don't support registration, keep
+	// working with them nonetheless.
+	err = client.Register(workerInfo(acceptedJobTypes, cacheDirectory))
+	if err != nil {
+		log.Printf("Could not register worker, continuing without registration: %v", err)
+	}
+
+	// With a message broker, composer pushes jobs to the worker instead of
+	// the worker polling for them.
+	var subscriber *amqpbroker.Subscriber
+	if config.Broker != nil {
+		var brokerJobTypes []string
+		for _, jt := range acceptedJobTypes {
+			brokerJobTypes = append(brokerJobTypes, worker.ArchJobType(jt, common.CurrentArch()))
+		}
+
+		subscriber, err = amqpbroker.Subscribe(config.Broker.URL, brokerJobTypes)
+		if err != nil {
+			log.Fatalf("Error subscribing to the message broker: %v", err)
+		}
+	}
+
 	for {
 		fmt.Println("Waiting for a new job...")
-		job, err := client.RequestJob(acceptedJobTypes, common.CurrentArch())
-		if err != nil {
-			log.Fatal(err)
+
+		var job worker.Job
+		if subscriber != nil {
+			message, err := subscriber.Next(context.Background())
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			job, err = client.JobFromMessage(message)
+			if err == nil {
+				// The job might have been requeued while it was
+				// waiting in the broker, which invalidates it
+				_, err = job.Canceled()
+			}
+			if err != nil {
+				log.Printf("Ignoring job from the message broker: %v", err)
+				continue
+			}
+		} else {
+			job, err = client.RequestJob(acceptedJobTypes, common.CurrentArch())
+			if err != nil {
+				log.Fatal(err)
+			}
 		}
 
-		impl, exists := jobImpls[job.Type()]
-		if !exists {
-			log.Printf("Ignoring job with unknown type %s", job.Type())
+		// Rather than failing in the middle of the build, let another
+		// worker build images this one doesn't have the resources for
+		err = checkResources(job, cacheDirectory, resourceLimits(config.Resources))
+		if err != nil {
+			log.Printf("Yielding job %s: %v", job.Id(), err)
+			err = job.Yield(err.Error())
+			if err != nil {
+				log.Printf("Error yielding job %s: %v", job.Id(), err)
+			}
+			time.Sleep(yieldBackoff)
 			continue
 		}
 
-		fmt.Printf("Running '%s' job %v\n", job.Type(), job.Id())
+		RunJob(job, jobImpls, store, tracer, reporter)
+		cache.Collect()
+	}
+}
+
+// Runs `job` with the matching implementation from `jobImpls`, while sending
+// heartbeats and watching for cancellation. The job is traced with `tracer`,
+// as part of the trace of the request which enqueued it. Panics and errors
+// are reported to `reporter`. Failed builds are not errors of the worker;
+// composer reports them.
+func RunJob(job worker.Job, jobImpls map[string]JobImplementation, store string, tracer *tracing.Tracer, reporter *sentry.Client) {
+	impl, exists := jobImpls[job.Type()]
+	if !exists {
+		log.Printf("Ignoring job with unknown type %s", job.Type())
+		return
+	}
 
-		ctx, cancelWatcher := context.WithCancel(context.Background())
-		go WatchJob(ctx, job)
+	fmt.Printf("Running '%s' job %v\n", job.Type(), job.Id())
 
-		err = impl.Run(job)
-		cancelWatcher()
-		if err != nil {
-			log.Printf("Job %s failed: %v", job.Id(), err)
-			continue
+	tags := map[string]string{
+		logging.JobID:   job.Id().String(),
+		logging.JobType: job.Type(),
+	}
+	defer reporter.Recover(tags)
+
+	// `jobCtx` is canceled when the job is canceled, `ctx` when the
+	// job is done.
+	traceCtx, span := tracer.Start(worker.JobContext(context.Background(), job), "job", trace.SpanKindConsumer,
+		attribute.String("job.id", job.Id().String()),
+		attribute.String("job.type", job.Type()),
+	)
+	defer span.End()
+
+	ctx, done := context.WithCancel(traceCtx)
+	jobCtx, cancelJob := context.WithCancel(ctx)
+	// stop the job when composer fails it for taking too long, even if
+	// composer cannot be reached to tell
+	if timeout := job.Timeout(); timeout > 0 {
+		jobCtx, cancelJob = context.WithTimeout(ctx, timeout)
+	}
+	go SendHeartbeats(ctx, job)
+	go WatchJob(ctx, job, cancelJob)
+
+	err := impl.Run(jobCtx, job)
+	canceled := jobCtx.Err() != nil && ctx.Err() == nil
+	timedOut := jobCtx.Err() == context.DeadlineExceeded
+	done()
+	cancelJob()
+	tracing.RecordError(span, err)
+	span.SetAttributes(attribute.Bool("job.canceled", canceled), attribute.Bool("job.timed_out", timedOut))
+	if canceled {
+		if timedOut {
+			log.Printf("Job %s timed out after %s", job.Id(), job.Timeout())
+		} else {
+			log.Printf("Job %s was canceled", job.Id())
 		}
 
-		log.Printf("Job %s finished", job.Id())
+		// osbuild might have left unfinished objects behind
+		err = os.RemoveAll(path.Join(store, "tmp"))
+		if err != nil {
+			log.Printf("Error cleaning up osbuild store: %v", err)
+		}
+		return
 	}
+	if err != nil {
+		log.Printf("Job %s failed: %v", job.Id(), err)
+		reporter.CaptureError(err, tags, nil)
+		return
+	}
+
+	log.Printf("Job %s finished", job.Id())
 }
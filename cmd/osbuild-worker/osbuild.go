@@ -1,14 +1,23 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
+	"github.com/osbuild/osbuild-composer/internal/tracing"
 )
 
 // Run an instance of osbuild, returning a parsed osbuild.Result.
@@ -16,7 +25,26 @@ import (
 // Note that osbuild returns non-zero when the pipeline fails. This function
 // does not return an error in this case. Instead, the failure is communicated
 // with its corresponding logs through osbuild.Result.
-func RunOSBuild(manifest distro.Manifest, store, outputDirectory string, exports []string, errorWriter io.Writer) (*osbuild.Result, error) {
+//
+// When `ctx` is done before osbuild exits, osbuild is sent SIGTERM, which
+// makes it tear down its build root and exit, and an error is returned.
+//
+// If `logWriter` is not nil, the human-readable log of the build is written
+// to it while osbuild runs. If `onStage` is not nil, it is called with the
+// names of the pipeline and the stage whenever osbuild starts running a stage.
+func RunOSBuild(ctx context.Context, manifest distro.Manifest, store, outputDirectory string, exports []string, errorWriter, logWriter io.Writer, onStage func(pipeline, stage string)) (*osbuild.Result, error) {
+	ctx, span := tracing.Start(ctx, "build")
+	defer span.End()
+
+	result, err := runOSBuild(ctx, manifest, store, outputDirectory, exports, errorWriter, logWriter, onStage)
+	tracing.RecordError(span, err)
+	if result != nil {
+		span.SetAttributes(attribute.Bool("osbuild.success", result.Success))
+	}
+	return result, err
+}
+
+func runOSBuild(ctx context.Context, manifest distro.Manifest, store, outputDirectory string, exports []string, errorWriter, logWriter io.Writer, onStage func(pipeline, stage string)) (*osbuild.Result, error) {
 	cmd := exec.Command(
 		"osbuild",
 		"--store", store,
@@ -37,11 +65,48 @@ func RunOSBuild(manifest distro.Manifest, store, outputDirectory string, exports
 	var stdoutBuffer bytes.Buffer
 	cmd.Stdout = &stdoutBuffer
 
+	// osbuild writes the human-readable log of the build, which names
+	// each pipeline and stage before running it, to file descriptor 3
+	var monitorReader, monitorWriter *os.File
+	if logWriter != nil || onStage != nil {
+		monitorReader, monitorWriter, err = os.Pipe()
+		if err != nil {
+			return nil, fmt.Errorf("error setting up the monitor pipe for osbuild: %v", err)
+		}
+		defer monitorReader.Close()
+		cmd.ExtraFiles = []*os.File{monitorWriter}
+		cmd.Args = append(cmd.Args, "--monitor", "LogMonitor", "--monitor-fd", "3")
+	}
+
 	err = cmd.Start()
+	if monitorWriter != nil {
+		// osbuild has its own copy
+		monitorWriter.Close()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error starting osbuild: %v", err)
 	}
 
+	monitorDone := make(chan struct{})
+	if monitorReader != nil {
+		go func() {
+			readMonitorLog(monitorReader, logWriter, onStage)
+			close(monitorDone)
+		}()
+	} else {
+		close(monitorDone)
+	}
+
+	exited := make(chan struct{})
+	defer close(exited)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = cmd.Process.Signal(syscall.SIGTERM)
+		case <-exited:
+		}
+	}()
+
 	err = json.NewEncoder(stdin).Encode(manifest)
 	if err != nil {
 		return nil, fmt.Errorf("error encoding osbuild pipeline: %v", err)
@@ -53,6 +118,11 @@ func RunOSBuild(manifest distro.Manifest, store, outputDirectory string, exports
 	}
 
 	err = cmd.Wait()
+	<-monitorDone
+
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("osbuild was stopped: %v", ctx.Err())
+	}
 
 	// try to decode the output even though the job could have failed
 	var result osbuild.Result
@@ -70,3 +140,44 @@ func RunOSBuild(manifest distro.Manifest, store, outputDirectory string, exports
 
 	return &result, nil
 }
+
+var (
+	monitorPipelineRegexp = regexp.MustCompile(`^Pipeline (\S+): [0-9a-f]{64}`)
+	monitorStageRegexp    = regexp.MustCompile(`^(org\.osbuild\.[\w.-]+): [0-9a-f]{64} \{`)
+)
+
+// readMonitorLog reads the log of osbuild's LogMonitor from `r` until it is
+// closed, copies it to `logWriter`, and calls `onStage` for each stage osbuild
+// starts running. The log also contains the options of the stages and their
+// output. Both `logWriter` and `onStage` may be nil.
+func readMonitorLog(r io.Reader, logWriter io.Writer, onStage func(pipeline, stage string)) {
+	if logWriter != nil {
+		r = io.TeeReader(r, logWriter)
+	}
+	if onStage == nil {
+		onStage = func(pipeline, stage string) {}
+	}
+	reader := bufio.NewReader(r)
+	pipeline := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if match := monitorPipelineRegexp.FindStringSubmatch(line); match != nil {
+			pipeline = match[1]
+		} else if match := monitorStageRegexp.FindStringSubmatch(line); match != nil {
+			onStage(pipeline, match[1])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Returns the version of the installed osbuild, as printed by `osbuild
+// --version` (e.g., "osbuild 24").
+func OSBuildVersion() (string, error) {
+	output, err := exec.Command("osbuild", "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("error running osbuild --version: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
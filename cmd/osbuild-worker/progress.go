@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// progressReporter reports the progress of an osbuild job to composer while
+// osbuild builds its manifest. Reports are sent in the background, so that
+// osbuild doesn't wait for composer. When stages start faster than reports
+// are sent, only the latest one is sent.
+type progressReporter struct {
+	job worker.Job
+
+	// Position of the first stage of each pipeline of the manifest, for
+	// manifests whose pipelines are named. osbuild doesn't report the
+	// stages of pipelines it takes from its cache.
+	offsets map[string]int
+	total   int
+
+	pipeline string
+	current  int
+	last     *worker.JobProgress
+
+	pending chan *worker.JobProgress
+	done    chan struct{}
+}
+
+func newProgressReporter(job worker.Job, manifest distro.Manifest) *progressReporter {
+	r := &progressReporter{
+		job:     job,
+		offsets: make(map[string]int),
+		pending: make(chan *worker.JobProgress, 1),
+		done:    make(chan struct{}),
+	}
+
+	var m struct {
+		Pipelines []struct {
+			Name   string            `json:"name"`
+			Stages []json.RawMessage `json:"stages"`
+		} `json:"pipelines"`
+		Pipeline *v1Pipeline `json:"pipeline"`
+	}
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		log.Printf("Error reading the stages of the manifest: %v", err)
+	}
+	for _, p := range m.Pipelines {
+		r.offsets[p.Name] = r.total
+		r.total += len(p.Stages)
+	}
+	if m.Pipeline != nil {
+		r.total = m.Pipeline.stages()
+	}
+
+	go r.send()
+	return r
+}
+
+// v1Pipeline is a pipeline of a version 1 manifest, which contains its
+// build pipeline.
+type v1Pipeline struct {
+	Build *struct {
+		Pipeline *v1Pipeline `json:"pipeline"`
+	} `json:"build"`
+	Stages    []json.RawMessage `json:"stages"`
+	Assembler json.RawMessage   `json:"assembler"`
+}
+
+// stages returns the number of stages of the pipeline and its build
+// pipelines, including the assembler.
+func (p *v1Pipeline) stages() int {
+	n := len(p.Stages)
+	if len(p.Assembler) > 0 {
+		n++
+	}
+	if p.Build != nil && p.Build.Pipeline != nil {
+		n += p.Build.Pipeline.stages()
+	}
+	return n
+}
+
+// stageStarted is called by RunOSBuild() when osbuild starts a stage.
+func (r *progressReporter) stageStarted(pipeline, stage string) {
+	if pipeline != r.pipeline {
+		r.pipeline = pipeline
+		if offset, ok := r.offsets[pipeline]; ok {
+			r.current = offset
+		}
+	}
+	r.current++
+
+	progress := &worker.JobProgress{
+		Pipeline: pipeline,
+		Stage:    stage,
+		Current:  r.current,
+		Total:    r.total,
+	}
+	if progress.Current > progress.Total {
+		progress.Total = progress.Current
+	}
+	progress.Percent = 100 * (progress.Current - 1) / progress.Total
+	r.last = progress
+
+	// replace a report which hasn't been sent yet
+	select {
+	case <-r.pending:
+	default:
+	}
+	r.pending <- progress
+}
+
+func (r *progressReporter) send() {
+	defer close(r.done)
+	for progress := range r.pending {
+		err := r.job.UpdateProgress(progress)
+		if err != nil {
+			log.Printf("Error reporting progress of job %s: %v", r.job.Id(), err)
+		}
+	}
+}
+
+// stop sends the last report and waits until it has been sent. Returns the
+// last report, or nil if osbuild didn't run any stage.
+func (r *progressReporter) stop() *worker.JobProgress {
+	close(r.pending)
+	<-r.done
+	return r.last
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// Builds need room for the build root and the image tree in the osbuild
+// store, besides the image itself
+const diskPerImageByte = 3
+
+// Memory a build needs at least, unless configured otherwise
+const defaultMinFreeMemory = 512 * 1024 * 1024
+
+// After yielding a job, the worker waits this long before requesting the
+// next one, so that the job goes to another worker
+const yieldBackoff = time.Minute
+
+// resourceLimits are the free resources a worker needs to accept a build.
+type resourceLimits struct {
+	// Free disk space in bytes, in addition to what the image needs
+	MinFreeDisk uint64
+	// Available memory in bytes
+	MinFreeMemory uint64
+}
+
+// insufficientResourcesError says which resource a worker lacks to run a job.
+type insufficientResourcesError struct {
+	resource  string
+	free      uint64
+	required  uint64
+	directory string
+}
+
+func (e *insufficientResourcesError) Error() string {
+	msg := fmt.Sprintf("insufficient resources: %s of %s free", formatSize(e.free), e.resource)
+	if e.directory != "" {
+		msg += " in " + e.directory
+	}
+	return msg + fmt.Sprintf(", %s required", formatSize(e.required))
+}
+
+// checkResources returns an *insufficientResourcesError if `dir` doesn't have
+// enough free space, or the system doesn't have enough available memory, to
+// build the image of `job`. Jobs which don't build images always pass.
+// Resources which cannot be determined are not checked, and neither are jobs
+// whose arguments cannot be read, which fail when they run.
+func checkResources(job worker.Job, dir string, limits resourceLimits) error {
+	if job.Type() != "osbuild" && job.Type() != "osbuild-koji" {
+		return nil
+	}
+
+	// both job types have the image size under the same name
+	var args struct {
+		ImageSize uint64 `json:"image_size"`
+	}
+	err := job.Args(&args)
+	if err != nil {
+		return nil
+	}
+
+	required := diskPerImageByte*args.ImageSize + limits.MinFreeDisk
+	free, err := freeDisk(dir)
+	if err != nil {
+		log.Printf("Could not determine free disk space in %s: %v", dir, err)
+	} else if free < required {
+		return &insufficientResourcesError{"disk space", free, required, dir}
+	}
+
+	required = limits.MinFreeMemory
+	if required == 0 {
+		required = defaultMinFreeMemory
+	}
+	free, err = availableMemory()
+	if err != nil {
+		log.Printf("Could not determine available memory: %v", err)
+	} else if free < required {
+		return &insufficientResourcesError{"memory", free, required, ""}
+	}
+
+	return nil
+}
+
+// freeDisk returns the number of bytes unprivileged users can still write to
+// the file system of `dir`.
+func freeDisk(dir string) (uint64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(dir, &stat)
+	if err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
+
+// availableMemory returns how many bytes of memory can be allocated without
+// swapping, as the kernel estimates it.
+func availableMemory() (uint64, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// MemAvailable:   12345678 kB
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "MemAvailable:" || fields[2] != "kB" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid MemAvailable in /proc/meminfo: %v", err)
+		}
+		return kb * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no MemAvailable in /proc/meminfo")
+}
+
+func formatSize(bytes uint64) string {
+	const gib = 1024 * 1024 * 1024
+	if bytes >= gib {
+		return fmt.Sprintf("%.1f GiB", float64(bytes)/gib)
+	}
+	return fmt.Sprintf("%d MiB", bytes/(1024*1024))
+}
@@ -0,0 +1,175 @@
+// Package audit records who changed what via composer's APIs.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"log/syslog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Event is an entry of the audit log.
+type Event struct {
+	Time time.Time `json:"time"`
+	// API which received the request: "weldr", "cloudapi", "koji", or
+	// "worker"
+	API string `json:"api"`
+	// Identity of the client, such as the local user of the weldr API or
+	// the subject of a bearer token
+	Actor string `json:"actor,omitempty"`
+	// Address of the client, "local" for the weldr socket
+	Source string `json:"source,omitempty"`
+	Action string `json:"action"`
+	// What the action was performed on, e.g. the UUID of a compose or the
+	// name of a blueprint
+	Object string `json:"object,omitempty"`
+	// HTTP status of the response
+	Status int `json:"status"`
+}
+
+// Log writes events as JSON objects to a sink. A nil *Log discards them.
+type Log struct {
+	mu   sync.Mutex
+	sink io.Writer
+}
+
+// NewLog returns a log which writes one event per line to `sink`.
+func NewLog(sink io.Writer) *Log {
+	return &Log{sink: sink}
+}
+
+// OpenFile returns a log which appends to the file at `path`.
+func OpenFile(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewLog(f), nil
+}
+
+// OpenSyslog returns a log which sends events to the local syslog daemon,
+// with the authpriv facility.
+func OpenSyslog(tag string) (*Log, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_AUTHPRIV, tag)
+	if err != nil {
+		return nil, err
+	}
+	return NewLog(w), nil
+}
+
+// Record writes `event` to the log. Failing to do so is logged, but doesn't
+// fail the request.
+func (l *Log) Record(event *Event) {
+	if l == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error encoding audit event: %v", err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.sink.Write(append(data, '\n')); err != nil {
+		log.Printf("Error writing audit event: %v", err)
+	}
+}
+
+// Handler returns a handler which serves requests with `next` and records
+// them with the status of the response, if `describe` returns an event for
+// them. Handlers can add the object of the action with SetObject.
+func (l *Log) Handler(next http.Handler, describe func(*http.Request) *Event) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := describe(r)
+		if event == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		event.Time = time.Now()
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(NewContext(r.Context(), event)))
+
+		event.Status = sw.status
+		l.Record(event)
+	})
+}
+
+// Middleware is like Handler, for echo servers. Errors returned by handlers
+// are recorded with the status they are reported with.
+func (l *Log) Middleware(describe func(echo.Context) *Event) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if l == nil {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			event := describe(ctx)
+			if event == nil {
+				return next(ctx)
+			}
+			event.Time = time.Now()
+
+			request := ctx.Request()
+			ctx.SetRequest(request.WithContext(NewContext(request.Context(), event)))
+			err := next(ctx)
+
+			switch e := err.(type) {
+			case nil:
+				event.Status = ctx.Response().Status
+			case *echo.HTTPError:
+				event.Status = e.Code
+			default:
+				event.Status = http.StatusInternalServerError
+			}
+			l.Record(event)
+			return err
+		}
+	}
+}
+
+type contextKey int
+
+const eventKey contextKey = iota
+
+// NewContext returns a context carrying `event`, which handlers can add to
+// with SetObject.
+func NewContext(ctx context.Context, event *Event) context.Context {
+	return context.WithValue(ctx, eventKey, event)
+}
+
+// SetObject sets the object of the event which is recorded for the request
+// with context `ctx`, if there is one.
+func SetObject(ctx context.Context, object string) {
+	if event, ok := ctx.Value(eventKey).(*Event); ok {
+		event.Object = object
+	}
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeEvents(t *testing.T, buf *bytes.Buffer) []Event {
+	var events []Event
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var event Event
+		require.NoError(t, decoder.Decode(&event))
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLog(&buf)
+
+	handler := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetObject(r.Context(), "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+		if r.URL.Path == "/fail" {
+			http.Error(w, "nope", http.StatusBadRequest)
+		}
+	}), func(r *http.Request) *Event {
+		if r.Method == http.MethodGet {
+			return nil
+		}
+		return &Event{API: "test", Actor: "alice", Source: r.RemoteAddr, Action: r.URL.Path[1:]}
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("POST", "/compose", nil),
+		httptest.NewRequest("GET", "/compose", nil),
+		httptest.NewRequest("DELETE", "/fail", nil),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	events := decodeEvents(t, &buf)
+	require.Len(t, events, 2)
+
+	require.False(t, events[0].Time.IsZero())
+	events[0].Time = time.Time{}
+	require.Equal(t, Event{
+		API:    "test",
+		Actor:  "alice",
+		Source: "192.0.2.1:1234",
+		Action: "compose",
+		Object: "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		Status: http.StatusOK,
+	}, events[0])
+	require.Equal(t, "fail", events[1].Action)
+	require.Equal(t, http.StatusBadRequest, events[1].Status)
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLog(&buf)
+
+	e := echo.New()
+	e.Use(l.Middleware(func(ctx echo.Context) *Event {
+		return &Event{API: "test", Action: ctx.Path()}
+	}))
+	e.POST("/ok", func(ctx echo.Context) error {
+		SetObject(ctx.Request().Context(), "job")
+		return ctx.NoContent(http.StatusCreated)
+	})
+	e.POST("/conflict", func(ctx echo.Context) error {
+		return echo.NewHTTPError(http.StatusConflict, "conflict")
+	})
+
+	for _, path := range []string{"/ok", "/conflict"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", path, nil))
+	}
+
+	events := decodeEvents(t, &buf)
+	require.Len(t, events, 2)
+	require.Equal(t, "job", events[0].Object)
+	require.Equal(t, http.StatusCreated, events[0].Status)
+	require.Equal(t, "/conflict", events[1].Action)
+	require.Equal(t, http.StatusConflict, events[1].Status)
+}
+
+func TestNilLog(t *testing.T) {
+	var l *Log
+	l.Record(&Event{})
+
+	next := http.NotFoundHandler()
+	require.NotNil(t, l.Handler(next, nil))
+}
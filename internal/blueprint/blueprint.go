@@ -4,10 +4,32 @@ package blueprint
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
 
 	"github.com/coreos/go-semver/semver"
 )
 
+// Names of the packages which can be selected as kernel
+var kernelNameRegex = regexp.MustCompile(`^[a-zA-Z0-9._+-]+$`)
+
+// User and group names as accepted by shadow-utils
+var userNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.][a-zA-Z0-9_.-]{0,30}[a-zA-Z0-9_.$-]?$`)
+
+// Names of the systemd units which blueprints can define
+var unitNameRegex = regexp.MustCompile(`^[a-zA-Z0-9:_.@\\-]+\.(service|timer|socket|path|target)$`)
+
+// Block devices, e.g. /dev/vda or /dev/disk/by-id/...
+var installationDeviceRegex = regexp.MustCompile(`^/dev/[a-zA-Z0-9:_.@/+-]+$`)
+
+// Module streams as understood by dnf: name:stream or name:stream/profile
+var moduleStreamRegex = regexp.MustCompile(`^([a-zA-Z0-9._+-]+):([a-zA-Z0-9._+-]+)(/([a-zA-Z0-9._+-]+))?$`)
+
+// IDs of yum repositories and the names of the files defining them
+var repoIDRegex = regexp.MustCompile(`^[a-zA-Z0-9_.:-]+$`)
+var repoFilenameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.:-]+\.repo$`)
+
 // A Blueprint is a high-level description of an image.
 type Blueprint struct {
 	Name           string          `json:"name" toml:"name"`
@@ -33,6 +55,16 @@ type Package struct {
 	Version string `json:"version,omitempty" toml:"version,omitempty"`
 }
 
+// A ModuleStream is a stream of a module which is enabled in an image, with
+// the profiles of it which are installed. A module of a blueprint selects it
+// with a name of the form "name:stream", or "name:stream/profile" to install
+// a profile other than the default one.
+type ModuleStream struct {
+	Name     string
+	Stream   string
+	Profiles []string
+}
+
 // A group specifies an package group.
 type Group struct {
 	Name string `json:"name" toml:"name"`
@@ -73,6 +105,233 @@ func (b *Blueprint) Initialize() error {
 	if err != nil {
 		return fmt.Errorf("Invalid 'version', must use Semantic Versioning: %s", err.Error())
 	}
+	if err := checkModules(b.Modules); err != nil {
+		return err
+	}
+	if firewall := b.Customizations.GetFirewall(); firewall != nil {
+		for _, zone := range firewall.Zones {
+			if zone.Name == "" {
+				return fmt.Errorf("Invalid firewall customization, zones must have a name")
+			}
+		}
+	}
+	if kernel := b.Customizations.GetKernel(); !kernelNameRegex.MatchString(kernel.Name) {
+		return fmt.Errorf("Invalid kernel customization, %q is not a valid package name", kernel.Name)
+	} else if strings.ContainsAny(kernel.Append, "\r\n") {
+		return fmt.Errorf("Invalid kernel customization, the kernel command line must be a single line")
+	}
+	if services := b.Customizations.GetServices(); services != nil {
+		for _, unit := range services.Units {
+			if !unitNameRegex.MatchString(unit.Name) {
+				return fmt.Errorf("Invalid services customization, %q is not a valid name for a unit", unit.Name)
+			}
+		}
+	}
+	if b.Customizations != nil {
+		if err := checkUsers(b.Customizations.User); err != nil {
+			return err
+		}
+	}
+	if subscription := b.Customizations.GetSubscription(); subscription != nil {
+		if err := checkSubscription(subscription); err != nil {
+			return err
+		}
+	}
+	if fdo := b.Customizations.GetFDO(); fdo != nil {
+		if err := checkFDO(fdo); err != nil {
+			return err
+		}
+	}
+	if ignition := b.Customizations.GetIgnition(); ignition != nil {
+		if err := checkIgnition(ignition); err != nil {
+			return err
+		}
+	}
+	if err := checkRepositories(b.Customizations.GetRepositories()); err != nil {
+		return err
+	}
+	// the device is passed on the installer's kernel command line
+	if device := b.Customizations.GetInstallationDevice(); device != "" && !installationDeviceRegex.MatchString(device) {
+		return fmt.Errorf("Invalid installation_device customization, %q is not a device below /dev", device)
+	}
+	return nil
+}
+
+// Redacted returns a copy of the blueprint without secrets, which can be
+// stored with composes.
+func (b *Blueprint) Redacted() Blueprint {
+	bp := b.DeepCopy()
+	if subscription := bp.Customizations.GetSubscription(); subscription != nil {
+		subscription.ActivationKey = RedactedActivationKey
+	}
+	return bp
+}
+
+func checkModules(modules []Package) error {
+	streams := make(map[string]string)
+	for _, module := range modules {
+		if !strings.Contains(module.Name, ":") {
+			continue
+		}
+		m, ok := module.ModuleStream()
+		if !ok {
+			return fmt.Errorf("Invalid module %q, module streams must be given as name:stream or name:stream/profile", module.Name)
+		}
+		if module.Version != "" && module.Version != "*" {
+			return fmt.Errorf("Invalid module %s, module streams cannot have a version", module.Name)
+		}
+		if stream, ok := streams[m.Name]; ok && stream != m.Stream {
+			return fmt.Errorf("Invalid module %s, only one stream of module %s can be enabled", module.Name, m.Name)
+		}
+		streams[m.Name] = m.Stream
+	}
+	return nil
+}
+
+func checkSubscription(s *SubscriptionCustomization) error {
+	if s.Organization <= 0 || s.ActivationKey == "" || s.ServerURL == "" || s.BaseURL == "" {
+		return fmt.Errorf("Invalid subscription customization, organization, activation_key, server_url and base_url are required")
+	}
+	// the values are passed to subscription-manager on the command line
+	for _, value := range []string{s.ActivationKey, s.ServerURL, s.BaseURL} {
+		if strings.ContainsAny(value, " \t\r\n'\"\\`$;&|<>") {
+			return fmt.Errorf("Invalid subscription customization, values must not contain whitespace or shell metacharacters")
+		}
+	}
+	return nil
+}
+
+func checkFDO(fdo *FDOCustomization) error {
+	if !isHTTPURL(fdo.ManufacturingServerURL) {
+		return fmt.Errorf("Invalid fdo customization, manufacturing_server_url must be an http or https URL")
+	}
+	options := 0
+	if fdo.DiunPubKeyInsecure {
+		options++
+	}
+	if fdo.DiunPubKeyHash != "" {
+		options++
+	}
+	if fdo.DiunPubKeyRootCerts != "" {
+		options++
+	}
+	if options != 1 {
+		return fmt.Errorf("Invalid fdo customization, exactly one of diun_pub_key_insecure, diun_pub_key_hash and diun_pub_key_root_certs is required")
+	}
+	// the hash is passed on the kernel command line
+	if strings.ContainsAny(fdo.DiunPubKeyHash, " \t\r\n") {
+		return fmt.Errorf("Invalid fdo customization, diun_pub_key_hash must not contain whitespace")
+	}
+	return nil
+}
+
+func checkIgnition(ignition *IgnitionCustomization) error {
+	switch {
+	case ignition.Embedded != nil && ignition.FirstBoot != nil:
+		return fmt.Errorf("Invalid ignition customization, embedded and firstboot cannot be combined")
+	case ignition.Embedded != nil:
+		if !json.Valid([]byte(ignition.Embedded.Config)) {
+			return fmt.Errorf("Invalid ignition customization, the embedded config must be valid JSON")
+		}
+	case ignition.FirstBoot != nil:
+		if !isHTTPURL(ignition.FirstBoot.ProvisioningURL) {
+			return fmt.Errorf("Invalid ignition customization, the firstboot url must be an http or https URL")
+		}
+	default:
+		return fmt.Errorf("Invalid ignition customization, either embedded or firstboot is required")
+	}
+	return nil
+}
+
+// Returns whether `s` is an http(s) URL which can be passed on the kernel
+// command line.
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	return !strings.ContainsAny(s, " \t\r\n")
+}
+
+func checkRepositories(repos []RepositoryCustomization) error {
+	seen := make(map[string]bool)
+	for _, repo := range repos {
+		if !repoIDRegex.MatchString(repo.ID) {
+			return fmt.Errorf("Invalid repository customization, %q is not a valid repository id", repo.ID)
+		}
+		if seen[repo.ID] {
+			return fmt.Errorf("Invalid repository customization, repository %s is defined more than once", repo.ID)
+		}
+		seen[repo.ID] = true
+
+		urls := 0
+		for _, u := range []string{repo.BaseURL, repo.Metalink, repo.Mirrorlist} {
+			if u == "" {
+				continue
+			}
+			urls++
+			if !isHTTPURL(u) {
+				return fmt.Errorf("Invalid repository customization, the urls of repository %s must be http or https URLs", repo.ID)
+			}
+		}
+		if urls != 1 {
+			return fmt.Errorf("Invalid repository customization, exactly one of baseurl, metalink and mirrorlist is required for repository %s", repo.ID)
+		}
+
+		if strings.ContainsAny(repo.Name, "\r\n") {
+			return fmt.Errorf("Invalid repository customization, the name of repository %s must be a single line", repo.ID)
+		}
+		if repo.Filename != "" && !repoFilenameRegex.MatchString(repo.Filename) {
+			return fmt.Errorf("Invalid repository customization, %q is not a valid name for a .repo file", repo.Filename)
+		}
+		if repo.Priority != nil && (*repo.Priority < 1 || *repo.Priority > 99) {
+			return fmt.Errorf("Invalid repository customization, the priority of repository %s must be between 1 and 99", repo.ID)
+		}
+
+		for _, key := range repo.GPGKeys {
+			key = strings.TrimSpace(key)
+			if !strings.HasPrefix(key, "-----BEGIN PGP PUBLIC KEY BLOCK-----") || !strings.HasSuffix(key, "-----END PGP PUBLIC KEY BLOCK-----") {
+				return fmt.Errorf("Invalid repository customization, the gpgkeys of repository %s must be ASCII-armored public keys", repo.ID)
+			}
+		}
+		if repo.CheckGPG() && len(repo.GPGKeys) == 0 {
+			return fmt.Errorf("Invalid repository customization, gpgcheck requires gpgkeys for repository %s", repo.ID)
+		}
+	}
+	return nil
+}
+
+func checkUsers(users []UserCustomization) error {
+	seen := make(map[string]bool)
+	for _, user := range users {
+		if !userNameRegex.MatchString(user.Name) {
+			return fmt.Errorf("Invalid user customization, %q is not a valid user name", user.Name)
+		}
+		if seen[user.Name] {
+			return fmt.Errorf("Invalid user customization, user %s is defined more than once", user.Name)
+		}
+		seen[user.Name] = true
+
+		for _, group := range user.Groups {
+			if !userNameRegex.MatchString(group) {
+				return fmt.Errorf("Invalid user customization, %q is not a valid group name", group)
+			}
+		}
+		if (user.UID != nil && *user.UID < 0) || (user.GID != nil && *user.GID < 0) {
+			return fmt.Errorf("Invalid user customization, uid and gid of user %s must not be negative", user.Name)
+		}
+		if user.ExpireDate != nil && *user.ExpireDate < 0 {
+			return fmt.Errorf("Invalid user customization, expiredate of user %s must not be negative", user.Name)
+		}
+		for _, key := range user.Keys {
+			if strings.TrimSpace(key) == "" || strings.ContainsAny(key, "\r\n") {
+				return fmt.Errorf("Invalid user customization, the keys of user %s must be single, non-empty lines", user.Name)
+			}
+		}
+		if user.ForcePasswordReset && user.Locked {
+			return fmt.Errorf("Invalid user customization, the password of user %s cannot be reset when it is locked", user.Name)
+		}
+	}
 	return nil
 }
 
@@ -98,6 +357,11 @@ func (b *Blueprint) GetPackages() []string {
 		packages = append(packages, pkg.ToNameVersion())
 	}
 	for _, pkg := range b.Modules {
+		if _, ok := pkg.ModuleStream(); ok {
+			// installs the profile of the module stream
+			packages = append(packages, "@"+pkg.Name)
+			continue
+		}
 		packages = append(packages, pkg.ToNameVersion())
 	}
 	for _, group := range b.Groups {
@@ -111,6 +375,65 @@ func (b *Blueprint) GetPackages() []string {
 	return packages
 }
 
+// GetModuleStreams returns the module streams which the modules of the
+// blueprint select, in order, with the profiles of all modules of the same
+// stream.
+func (b *Blueprint) GetModuleStreams() []ModuleStream {
+	var streams []ModuleStream
+	indices := make(map[string]int)
+	for _, pkg := range b.Modules {
+		m, ok := pkg.ModuleStream()
+		if !ok {
+			continue
+		}
+		i, ok := indices[m.Name]
+		if !ok {
+			indices[m.Name] = len(streams)
+			streams = append(streams, m)
+			continue
+		}
+		for _, profile := range m.Profiles {
+			if !containsString(streams[i].Profiles, profile) {
+				streams[i].Profiles = append(streams[i].Profiles, profile)
+			}
+		}
+	}
+	return streams
+}
+
+// GetEnabledModules returns the "name:stream" specs of the module streams
+// which must be enabled before depsolving the packages of the blueprint.
+func (b *Blueprint) GetEnabledModules() []string {
+	var specs []string
+	for _, m := range b.GetModuleStreams() {
+		specs = append(specs, m.Name+":"+m.Stream)
+	}
+	return specs
+}
+
+// ModuleStream returns the module stream `p` selects, and false if it is a
+// module without a stream.
+func (p Package) ModuleStream() (ModuleStream, bool) {
+	match := moduleStreamRegex.FindStringSubmatch(p.Name)
+	if match == nil {
+		return ModuleStream{}, false
+	}
+	m := ModuleStream{Name: match[1], Stream: match[2]}
+	if match[4] != "" {
+		m.Profiles = []string{match[4]}
+	}
+	return m, true
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 func (p Package) ToNameVersion() string {
 	// Omit version to prevent all packages with prefix of name to be installed
 	if p.Version == "*" || p.Version == "" {
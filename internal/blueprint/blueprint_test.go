@@ -1,6 +1,7 @@
 package blueprint
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,6 +32,19 @@ func TestDeepCopy(t *testing.T) {
 }
 
 func TestBlueprintInitialize(t *testing.T) {
+	expireDate := 19000
+	negative := -1
+	priority := 100
+	enabled := true
+	gpgKey := "-----BEGIN PGP PUBLIC KEY BLOCK-----\n\nmQINBGAcScoBEADLf8YHke\n-----END PGP PUBLIC KEY BLOCK-----\n"
+	subscription := SubscriptionCustomization{
+		Organization:  2040324,
+		ActivationKey: "my-secret-key",
+		ServerURL:     "subscription.rhsm.redhat.com",
+		BaseURL:       "http://cdn.redhat.com/",
+		Insights:      true,
+	}
+
 	cases := []struct {
 		NewBlueprint  Blueprint
 		ExpectedError bool
@@ -43,6 +57,129 @@ func TestBlueprintInitialize(t *testing.T) {
 		{Blueprint{Name: "bp-test-5", Description: "Invalid version 5", Version: "foo"}, true},
 		{Blueprint{Name: "bp-test-7", Description: "Zero version", Version: "0.0.0"}, false},
 		{Blueprint{Name: "bp-test-8", Description: "X.Y.Z version", Version: "2.1.3"}, false},
+		{Blueprint{Name: "bp-test-9", Description: "Firewall zone", Customizations: &Customizations{
+			Firewall: &FirewallCustomization{Zones: []FirewallZoneCustomization{{Name: "trusted", Sources: []string{"10.0.0.0/8"}}}},
+		}}, false},
+		{Blueprint{Name: "bp-test-10", Description: "Unnamed firewall zone", Customizations: &Customizations{
+			Firewall: &FirewallCustomization{Zones: []FirewallZoneCustomization{{Sources: []string{"10.0.0.0/8"}}}},
+		}}, true},
+		{Blueprint{Name: "bp-test-11", Description: "Custom units", Customizations: &Customizations{
+			Services: &ServicesCustomization{Units: []SystemdUnitCustomization{{Name: "firstboot.service"}, {Name: "cleanup@.timer"}}},
+		}}, false},
+		{Blueprint{Name: "bp-test-12", Description: "Invalid unit name", Customizations: &Customizations{
+			Services: &ServicesCustomization{Units: []SystemdUnitCustomization{{Name: "../firstboot.service"}}},
+		}}, true},
+		{Blueprint{Name: "bp-test-13", Description: "Unsupported unit type", Customizations: &Customizations{
+			Services: &ServicesCustomization{Units: []SystemdUnitCustomization{{Name: "foo.conf"}}},
+		}}, true},
+		{Blueprint{Name: "bp-test-14", Description: "Real-time kernel", Customizations: &Customizations{
+			Kernel: &KernelCustomization{Name: "kernel-rt", Append: "isolcpus=1-3 nohz_full=1-3"},
+		}}, false},
+		{Blueprint{Name: "bp-test-15", Description: "Kernel with version", Customizations: &Customizations{
+			Kernel: &KernelCustomization{Name: "kernel >= 4.18"},
+		}}, true},
+		{Blueprint{Name: "bp-test-16", Description: "Multi-line kernel command line", Customizations: &Customizations{
+			Kernel: &KernelCustomization{Append: "debug\nquiet"},
+		}}, true},
+		{Blueprint{Name: "bp-test-17", Description: "User with keys and expiration", Customizations: &Customizations{
+			User: []UserCustomization{{Name: "user", Keys: []string{"ssh-rsa AAAA", "ssh-ed25519 AAAA"}, Groups: []string{"wheel"}, ExpireDate: &expireDate, Locked: true}},
+		}}, false},
+		{Blueprint{Name: "bp-test-18", Description: "Duplicate user", Customizations: &Customizations{
+			User: []UserCustomization{{Name: "user"}, {Name: "user"}},
+		}}, true},
+		{Blueprint{Name: "bp-test-19", Description: "Invalid user name", Customizations: &Customizations{
+			User: []UserCustomization{{Name: "us er"}},
+		}}, true},
+		{Blueprint{Name: "bp-test-20", Description: "Invalid group name", Customizations: &Customizations{
+			User: []UserCustomization{{Name: "user", Groups: []string{""}}},
+		}}, true},
+		{Blueprint{Name: "bp-test-21", Description: "Negative uid", Customizations: &Customizations{
+			User: []UserCustomization{{Name: "user", UID: &negative}},
+		}}, true},
+		{Blueprint{Name: "bp-test-22", Description: "Negative expiration date", Customizations: &Customizations{
+			User: []UserCustomization{{Name: "user", ExpireDate: &negative}},
+		}}, true},
+		{Blueprint{Name: "bp-test-23", Description: "Multi-line key", Customizations: &Customizations{
+			User: []UserCustomization{{Name: "user", Keys: []string{"ssh-rsa AAAA\nssh-rsa BBBB"}}},
+		}}, true},
+		{Blueprint{Name: "bp-test-24", Description: "Password reset of locked user", Customizations: &Customizations{
+			User: []UserCustomization{{Name: "user", Locked: true, ForcePasswordReset: true}},
+		}}, true},
+		{Blueprint{Name: "bp-test-25", Description: "Subscription", Customizations: &Customizations{
+			Subscription: &subscription,
+		}}, false},
+		{Blueprint{Name: "bp-test-26", Description: "Subscription without activation key", Customizations: &Customizations{
+			Subscription: &SubscriptionCustomization{Organization: 2040324, ServerURL: "subscription.rhsm.redhat.com", BaseURL: "http://cdn.redhat.com/"},
+		}}, true},
+		{Blueprint{Name: "bp-test-27", Description: "Subscription with shell metacharacters", Customizations: &Customizations{
+			Subscription: &SubscriptionCustomization{Organization: 2040324, ActivationKey: "key; reboot", ServerURL: "subscription.rhsm.redhat.com", BaseURL: "http://cdn.redhat.com/"},
+		}}, true},
+		{Blueprint{Name: "bp-test-28", Description: "FDO", Customizations: &Customizations{
+			FDO: &FDOCustomization{ManufacturingServerURL: "http://10.0.0.2:8080", DiunPubKeyHash: "sha256:aabb"},
+		}}, false},
+		{Blueprint{Name: "bp-test-29", Description: "FDO without DIUN public key", Customizations: &Customizations{
+			FDO: &FDOCustomization{ManufacturingServerURL: "http://10.0.0.2:8080"},
+		}}, true},
+		{Blueprint{Name: "bp-test-30", Description: "FDO with two DIUN public keys", Customizations: &Customizations{
+			FDO: &FDOCustomization{ManufacturingServerURL: "http://10.0.0.2:8080", DiunPubKeyInsecure: true, DiunPubKeyHash: "sha256:aabb"},
+		}}, true},
+		{Blueprint{Name: "bp-test-31", Description: "FDO with invalid URL", Customizations: &Customizations{
+			FDO: &FDOCustomization{ManufacturingServerURL: "10.0.0.2", DiunPubKeyInsecure: true},
+		}}, true},
+		{Blueprint{Name: "bp-test-32", Description: "Embedded Ignition config", Customizations: &Customizations{
+			Ignition: &IgnitionCustomization{Embedded: &EmbeddedIgnitionCustomization{Config: `{"ignition": {"version": "3.3.0"}}`}},
+		}}, false},
+		{Blueprint{Name: "bp-test-33", Description: "Embedded Ignition config which is not JSON", Customizations: &Customizations{
+			Ignition: &IgnitionCustomization{Embedded: &EmbeddedIgnitionCustomization{Config: "ignition"}},
+		}}, true},
+		{Blueprint{Name: "bp-test-34", Description: "Embedded and firstboot Ignition config", Customizations: &Customizations{
+			Ignition: &IgnitionCustomization{
+				Embedded:  &EmbeddedIgnitionCustomization{Config: "{}"},
+				FirstBoot: &FirstBootIgnitionCustomization{ProvisioningURL: "https://example.com/config.ign"},
+			},
+		}}, true},
+		{Blueprint{Name: "bp-test-35", Description: "Empty Ignition customization", Customizations: &Customizations{
+			Ignition: &IgnitionCustomization{},
+		}}, true},
+		{Blueprint{Name: "bp-test-36", Description: "Installation device", Customizations: &Customizations{
+			InstallationDevice: "/dev/disk/by-path/pci-0000:00:04.0",
+		}}, false},
+		{Blueprint{Name: "bp-test-37", Description: "Installation device outside of /dev", Customizations: &Customizations{
+			InstallationDevice: "/tmp/vda",
+		}}, true},
+		{Blueprint{Name: "bp-test-38", Description: "Installation device with whitespace", Customizations: &Customizations{
+			InstallationDevice: "/dev/vda coreos.inst.insecure",
+		}}, true},
+		{Blueprint{Name: "bp-test-39", Description: "Repositories", Customizations: &Customizations{
+			Repositories: []RepositoryCustomization{
+				{ID: "example", BaseURL: "https://example.com/repo/$basearch/", GPGKeys: []string{gpgKey}},
+				{ID: "example-modules", Metalink: "https://example.com/metalink", ModuleHotfixes: true, Filename: "example.repo"},
+			},
+		}}, false},
+		{Blueprint{Name: "bp-test-40", Description: "Duplicate repository", Customizations: &Customizations{
+			Repositories: []RepositoryCustomization{{ID: "example", BaseURL: "https://example.com/a"}, {ID: "example", BaseURL: "https://example.com/b"}},
+		}}, true},
+		{Blueprint{Name: "bp-test-41", Description: "Repository with two URLs", Customizations: &Customizations{
+			Repositories: []RepositoryCustomization{{ID: "example", BaseURL: "https://example.com/a", Mirrorlist: "https://example.com/b"}},
+		}}, true},
+		{Blueprint{Name: "bp-test-42", Description: "Repository with a file URL", Customizations: &Customizations{
+			Repositories: []RepositoryCustomization{{ID: "example", BaseURL: "file:///srv/repo"}},
+		}}, true},
+		{Blueprint{Name: "bp-test-43", Description: "Repository with an invalid id", Customizations: &Customizations{
+			Repositories: []RepositoryCustomization{{ID: "exam ple", BaseURL: "https://example.com/a"}},
+		}}, true},
+		{Blueprint{Name: "bp-test-44", Description: "Repository with an invalid priority", Customizations: &Customizations{
+			Repositories: []RepositoryCustomization{{ID: "example", BaseURL: "https://example.com/a", Priority: &priority}},
+		}}, true},
+		{Blueprint{Name: "bp-test-45", Description: "Repository with a key which is not armored", Customizations: &Customizations{
+			Repositories: []RepositoryCustomization{{ID: "example", BaseURL: "https://example.com/a", GPGKeys: []string{"https://example.com/key"}}},
+		}}, true},
+		{Blueprint{Name: "bp-test-46", Description: "Repository with gpgcheck and without keys", Customizations: &Customizations{
+			Repositories: []RepositoryCustomization{{ID: "example", BaseURL: "https://example.com/a", GPGCheck: &enabled}},
+		}}, true},
+		{Blueprint{Name: "bp-test-47", Description: "Repository with an invalid filename", Customizations: &Customizations{
+			Repositories: []RepositoryCustomization{{ID: "example", BaseURL: "https://example.com/a", Filename: "../example.repo"}},
+		}}, true},
 	}
 
 	for _, c := range cases {
@@ -94,6 +231,37 @@ func TestGetPackages(t *testing.T) {
 	assert.ElementsMatch(t, []string{"tmux-1.2", "openssh-server", "@anaconda-tools", "kernel"}, Received_packages)
 }
 
+func TestModuleStreams(t *testing.T) {
+	bp := Blueprint{
+		Name: "modules-test",
+		Modules: []Package{
+			{Name: "nodejs:18"},
+			{Name: "openssh-server", Version: "*"},
+			{Name: "postgresql:15/server"},
+			{Name: "postgresql:15/client"},
+		},
+	}
+	require.NoError(t, bp.Initialize())
+	assert.ElementsMatch(t, []string{"@nodejs:18", "openssh-server", "@postgresql:15/server", "@postgresql:15/client", "kernel"}, bp.GetPackages())
+	assert.Equal(t, []ModuleStream{
+		{Name: "nodejs", Stream: "18"},
+		{Name: "postgresql", Stream: "15", Profiles: []string{"server", "client"}},
+	}, bp.GetModuleStreams())
+	assert.Equal(t, []string{"nodejs:18", "postgresql:15"}, bp.GetEnabledModules())
+
+	invalid := map[string]Package{
+		"Invalid module \"nodejs:\", module streams must be given as name:stream or name:stream/profile": {Name: "nodejs:"},
+		"Invalid module nodejs:18, module streams cannot have a version":                                 {Name: "nodejs:18", Version: "18.1"},
+	}
+	for msg, module := range invalid {
+		bp := Blueprint{Name: "invalid", Modules: []Package{module}}
+		assert.EqualError(t, bp.Initialize(), msg)
+	}
+
+	bp = Blueprint{Name: "two-streams", Modules: []Package{{Name: "nodejs:16"}, {Name: "nodejs:18"}}}
+	assert.EqualError(t, bp.Initialize(), "Invalid module nodejs:18, only one stream of module nodejs can be enabled")
+}
+
 func TestKernelNameCustomization(t *testing.T) {
 	kernels := []string{"kernel", "kernel-debug", "kernel-rt"}
 
@@ -167,3 +335,24 @@ func TestKernelNameCustomization(t *testing.T) {
 		}
 	}
 }
+
+func TestBlueprintRedacted(t *testing.T) {
+	bp := Blueprint{
+		Name: "subscribed",
+		Customizations: &Customizations{
+			Subscription: &SubscriptionCustomization{Organization: 2040324, ActivationKey: "my-secret-key"},
+		},
+	}
+
+	redacted := bp.Redacted()
+	assert.Equal(t, RedactedActivationKey, redacted.Customizations.Subscription.ActivationKey)
+	assert.Equal(t, 2040324, redacted.Customizations.Subscription.Organization)
+	assert.Equal(t, "my-secret-key", bp.Customizations.Subscription.ActivationKey)
+
+	// the key is not printed either
+	assert.NotContains(t, fmt.Sprintf("%v", *bp.Customizations.Subscription), "my-secret-key")
+	assert.Contains(t, fmt.Sprintf("%v", *bp.Customizations.Subscription), "Organization:2040324")
+
+	bp = Blueprint{Name: "unsubscribed"}
+	assert.Equal(t, bp, bp.Redacted())
+}
@@ -1,15 +1,30 @@
 package blueprint
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Customizations struct {
-	Hostname *string                `json:"hostname,omitempty" toml:"hostname,omitempty"`
-	Kernel   *KernelCustomization   `json:"kernel,omitempty" toml:"kernel,omitempty"`
-	SSHKey   []SSHKeyCustomization  `json:"sshkey,omitempty" toml:"sshkey,omitempty"`
-	User     []UserCustomization    `json:"user,omitempty" toml:"user,omitempty"`
-	Group    []GroupCustomization   `json:"group,omitempty" toml:"group,omitempty"`
-	Timezone *TimezoneCustomization `json:"timezone,omitempty" toml:"timezone,omitempty"`
-	Locale   *LocaleCustomization   `json:"locale,omitempty" toml:"locale,omitempty"`
-	Firewall *FirewallCustomization `json:"firewall,omitempty" toml:"firewall,omitempty"`
-	Services *ServicesCustomization `json:"services,omitempty" toml:"services,omitempty"`
+	Hostname     *string                    `json:"hostname,omitempty" toml:"hostname,omitempty"`
+	Kernel       *KernelCustomization       `json:"kernel,omitempty" toml:"kernel,omitempty"`
+	SSHKey       []SSHKeyCustomization      `json:"sshkey,omitempty" toml:"sshkey,omitempty"`
+	User         []UserCustomization        `json:"user,omitempty" toml:"user,omitempty"`
+	Group        []GroupCustomization       `json:"group,omitempty" toml:"group,omitempty"`
+	Timezone     *TimezoneCustomization     `json:"timezone,omitempty" toml:"timezone,omitempty"`
+	Locale       *LocaleCustomization       `json:"locale,omitempty" toml:"locale,omitempty"`
+	Firewall     *FirewallCustomization     `json:"firewall,omitempty" toml:"firewall,omitempty"`
+	Services     *ServicesCustomization     `json:"services,omitempty" toml:"services,omitempty"`
+	Filesystem   []FilesystemCustomization  `json:"filesystem,omitempty" toml:"filesystem,omitempty"`
+	Disk         *DiskCustomization         `json:"disk,omitempty" toml:"disk,omitempty"`
+	Files        []FileCustomization        `json:"files,omitempty" toml:"files,omitempty"`
+	Directories  []DirectoryCustomization   `json:"directories,omitempty" toml:"directories,omitempty"`
+	Subscription *SubscriptionCustomization `json:"subscription,omitempty" toml:"subscription,omitempty"`
+	FDO          *FDOCustomization          `json:"fdo,omitempty" toml:"fdo,omitempty"`
+	Ignition     *IgnitionCustomization     `json:"ignition,omitempty" toml:"ignition,omitempty"`
+	Repositories []RepositoryCustomization  `json:"repositories,omitempty" toml:"repositories,omitempty"`
+	// Device which the simplified installer installs the image to
+	InstallationDevice string `json:"installation_device,omitempty" toml:"installation_device,omitempty"`
 }
 
 type KernelCustomization struct {
@@ -22,16 +37,41 @@ type SSHKeyCustomization struct {
 	Key  string `json:"key" toml:"key"`
 }
 
+// UserCustomization creates a user. Key and Keys are SSH public keys, which
+// are all added to the user's authorized keys. ExpireDate is the day on which
+// the account is disabled, in days since 1970-01-01. A Locked user cannot log
+// in with a password, and ForcePasswordReset expires the password, so that it
+// has to be changed on the first login.
 type UserCustomization struct {
-	Name        string   `json:"name" toml:"name"`
-	Description *string  `json:"description,omitempty" toml:"description,omitempty"`
-	Password    *string  `json:"password,omitempty" toml:"password,omitempty"`
-	Key         *string  `json:"key,omitempty" toml:"key,omitempty"`
-	Home        *string  `json:"home,omitempty" toml:"home,omitempty"`
-	Shell       *string  `json:"shell,omitempty" toml:"shell,omitempty"`
-	Groups      []string `json:"groups,omitempty" toml:"groups,omitempty"`
-	UID         *int     `json:"uid,omitempty" toml:"uid,omitempty"`
-	GID         *int     `json:"gid,omitempty" toml:"gid,omitempty"`
+	Name               string   `json:"name" toml:"name"`
+	Description        *string  `json:"description,omitempty" toml:"description,omitempty"`
+	Password           *string  `json:"password,omitempty" toml:"password,omitempty"`
+	Key                *string  `json:"key,omitempty" toml:"key,omitempty"`
+	Keys               []string `json:"keys,omitempty" toml:"keys,omitempty"`
+	Home               *string  `json:"home,omitempty" toml:"home,omitempty"`
+	Shell              *string  `json:"shell,omitempty" toml:"shell,omitempty"`
+	Groups             []string `json:"groups,omitempty" toml:"groups,omitempty"`
+	UID                *int     `json:"uid,omitempty" toml:"uid,omitempty"`
+	GID                *int     `json:"gid,omitempty" toml:"gid,omitempty"`
+	ExpireDate         *int     `json:"expiredate,omitempty" toml:"expiredate,omitempty"`
+	Locked             bool     `json:"locked,omitempty" toml:"locked,omitempty"`
+	ForcePasswordReset bool     `json:"force_password_reset,omitempty" toml:"force_password_reset,omitempty"`
+}
+
+// AuthorizedKeys returns the content of the user's authorized_keys file, with
+// one key per line, or nil if the user has no keys.
+func (u *UserCustomization) AuthorizedKeys() *string {
+	var keys []string
+	if u.Key != nil {
+		keys = append(keys, *u.Key)
+	}
+	keys = append(keys, u.Keys...)
+
+	if len(keys) == 0 {
+		return nil
+	}
+	authorizedKeys := strings.Join(keys, "\n")
+	return &authorizedKeys
 }
 
 type GroupCustomization struct {
@@ -52,6 +92,7 @@ type LocaleCustomization struct {
 type FirewallCustomization struct {
 	Ports    []string                       `json:"ports,omitempty" toml:"ports,omitempty"`
 	Services *FirewallServicesCustomization `json:"services,omitempty" toml:"services,omitempty"`
+	Zones    []FirewallZoneCustomization    `json:"zones,omitempty" toml:"zones,omitempty"`
 }
 
 type FirewallServicesCustomization struct {
@@ -59,9 +100,190 @@ type FirewallServicesCustomization struct {
 	Disabled []string `json:"disabled,omitempty" toml:"disabled,omitempty"`
 }
 
+// FirewallZoneCustomization assigns sources (addresses or networks) to the
+// firewalld zone Name.
+type FirewallZoneCustomization struct {
+	Name    string   `json:"name" toml:"name"`
+	Sources []string `json:"sources,omitempty" toml:"sources,omitempty"`
+}
+
+// ServicesCustomization enables, disables and masks systemd units. Units
+// defines new units, which can be enabled like any other unit.
 type ServicesCustomization struct {
-	Enabled  []string `json:"enabled,omitempty" toml:"enabled,omitempty"`
-	Disabled []string `json:"disabled,omitempty" toml:"disabled,omitempty"`
+	Enabled  []string                   `json:"enabled,omitempty" toml:"enabled,omitempty"`
+	Disabled []string                   `json:"disabled,omitempty" toml:"disabled,omitempty"`
+	Masked   []string                   `json:"masked,omitempty" toml:"masked,omitempty"`
+	Units    []SystemdUnitCustomization `json:"units,omitempty" toml:"units,omitempty"`
+}
+
+// SystemdUnitCustomization is a systemd unit file, which is installed to
+// /etc/systemd/system/Name. Data is the content of the unit file.
+type SystemdUnitCustomization struct {
+	Name string `json:"name" toml:"name"`
+	Data string `json:"data" toml:"data"`
+}
+
+// FilesystemCustomization requests a separate partition for a mountpoint.
+// Size is the minimal size of the filesystem in bytes. The partition of at
+// most one filesystem grows to fill the rest of the disk, which is the root
+// filesystem unless another one sets Grow.
+type FilesystemCustomization struct {
+	Mountpoint string `json:"mountpoint" toml:"mountpoint"`
+	Size       uint64 `json:"size,omitempty" toml:"size,omitempty"`
+	Type       string `json:"type,omitempty" toml:"type,omitempty"`
+	Grow       bool   `json:"grow,omitempty" toml:"grow,omitempty"`
+}
+
+// DiskCustomization changes how the filesystems of a disk image are stored.
+// With LVM set, all filesystems except /boot and /boot/efi are put on logical
+// volumes of a single volume group, instead of on separate partitions.
+type DiskCustomization struct {
+	LVM        bool                     `json:"lvm,omitempty" toml:"lvm,omitempty"`
+	Encryption *EncryptionCustomization `json:"encryption,omitempty" toml:"encryption,omitempty"`
+}
+
+// EncryptionCustomization encrypts all filesystems except /boot and /boot/efi
+// with LUKS2. The volume is unlocked with Passphrase, or at boot by the
+// Clevis pin. At least one of them must be set.
+type EncryptionCustomization struct {
+	Passphrase string               `json:"passphrase,omitempty" toml:"passphrase,omitempty"`
+	Clevis     *ClevisCustomization `json:"clevis,omitempty" toml:"clevis,omitempty"`
+}
+
+// ClevisCustomization binds an encrypted volume to a Clevis pin, e.g. to a
+// Tang server with pin "tang" and policy '{"url": "http://tang.example.com"}'.
+type ClevisCustomization struct {
+	Pin    string `json:"pin" toml:"pin"`
+	Policy string `json:"policy" toml:"policy"`
+}
+
+// FileCustomization embeds a file in the image. Data is the content of the
+// file, which is base64 encoded if Encoding is "base64". User and Group are
+// names or numeric ids, and Mode is an octal string like "0644".
+type FileCustomization struct {
+	Path     string `json:"path" toml:"path"`
+	User     string `json:"user,omitempty" toml:"user,omitempty"`
+	Group    string `json:"group,omitempty" toml:"group,omitempty"`
+	Mode     string `json:"mode,omitempty" toml:"mode,omitempty"`
+	Data     string `json:"data,omitempty" toml:"data,omitempty"`
+	Encoding string `json:"encoding,omitempty" toml:"encoding,omitempty"`
+}
+
+// DirectoryCustomization creates a directory in the image. Its parents are
+// created as well if EnsureParents is set.
+type DirectoryCustomization struct {
+	Path          string `json:"path" toml:"path"`
+	User          string `json:"user,omitempty" toml:"user,omitempty"`
+	Group         string `json:"group,omitempty" toml:"group,omitempty"`
+	Mode          string `json:"mode,omitempty" toml:"mode,omitempty"`
+	EnsureParents bool   `json:"ensure_parents,omitempty" toml:"ensure_parents,omitempty"`
+}
+
+// SubscriptionCustomization registers the image with Red Hat Subscription
+// Management on its first boot, and optionally with Red Hat Insights.
+// ServerURL is the host to register with and BaseURL the URL of the content
+// delivery network which serves the repositories.
+type SubscriptionCustomization struct {
+	Organization  int    `json:"organization" toml:"organization"`
+	ActivationKey string `json:"activation_key" toml:"activation_key"`
+	ServerURL     string `json:"server_url" toml:"server_url"`
+	BaseURL       string `json:"base_url" toml:"base_url"`
+	Insights      bool   `json:"insights,omitempty" toml:"insights,omitempty"`
+}
+
+// RedactedActivationKey replaces activation keys in blueprints and logs.
+const RedactedActivationKey = "<redacted>"
+
+// String formats the customization without the activation key, so that it
+// doesn't end up in logs.
+func (s SubscriptionCustomization) String() string {
+	s.ActivationKey = RedactedActivationKey
+	type subscription SubscriptionCustomization
+	return fmt.Sprintf("%+v", subscription(s))
+}
+
+// FDOCustomization onboards devices installed from an installer image with
+// FIDO Device Onboard. The installer initializes the device with the
+// manufacturing server at ManufacturingServerURL, whose identity is verified
+// with exactly one of the DIUN (device interface unified) public key options:
+// DiunPubKeyInsecure skips the verification, DiunPubKeyHash is the hash of the
+// server's public key and DiunPubKeyRootCerts are PEM encoded root
+// certificates.
+type FDOCustomization struct {
+	ManufacturingServerURL string `json:"manufacturing_server_url" toml:"manufacturing_server_url"`
+	DiunPubKeyInsecure     bool   `json:"diun_pub_key_insecure,omitempty" toml:"diun_pub_key_insecure,omitempty"`
+	DiunPubKeyHash         string `json:"diun_pub_key_hash,omitempty" toml:"diun_pub_key_hash,omitempty"`
+	DiunPubKeyRootCerts    string `json:"diun_pub_key_root_certs,omitempty" toml:"diun_pub_key_root_certs,omitempty"`
+}
+
+// IgnitionCustomization provisions devices installed from an installer image
+// with Ignition on their first boot. The config is either embedded in the
+// image or fetched from a URL, but not both.
+type IgnitionCustomization struct {
+	Embedded  *EmbeddedIgnitionCustomization  `json:"embedded,omitempty" toml:"embedded,omitempty"`
+	FirstBoot *FirstBootIgnitionCustomization `json:"firstboot,omitempty" toml:"firstboot,omitempty"`
+}
+
+// EmbeddedIgnitionCustomization is an Ignition config (in JSON) which is
+// embedded in the image.
+type EmbeddedIgnitionCustomization struct {
+	Config string `json:"config" toml:"config"`
+}
+
+// FirstBootIgnitionCustomization is the URL from which Ignition fetches its
+// config on the first boot.
+type FirstBootIgnitionCustomization struct {
+	ProvisioningURL string `json:"url" toml:"url"`
+}
+
+// RepositoryCustomization adds a yum repository to the image, in the file
+// Filename in /etc/yum.repos.d, which defaults to the ID of the repository.
+// Enabled repositories are also used to build the image. GPGKeys are
+// ASCII-armored public keys, which are installed in /etc/pki/rpm-gpg. The
+// packages of the repository are verified with them if GPGCheck is set,
+// which is the default if there are any keys.
+type RepositoryCustomization struct {
+	ID             string   `json:"id" toml:"id"`
+	Name           string   `json:"name,omitempty" toml:"name,omitempty"`
+	BaseURL        string   `json:"baseurl,omitempty" toml:"baseurl,omitempty"`
+	Metalink       string   `json:"metalink,omitempty" toml:"metalink,omitempty"`
+	Mirrorlist     string   `json:"mirrorlist,omitempty" toml:"mirrorlist,omitempty"`
+	GPGKeys        []string `json:"gpgkeys,omitempty" toml:"gpgkeys,omitempty"`
+	GPGCheck       *bool    `json:"gpgcheck,omitempty" toml:"gpgcheck,omitempty"`
+	Enabled        *bool    `json:"enabled,omitempty" toml:"enabled,omitempty"`
+	Priority       *int     `json:"priority,omitempty" toml:"priority,omitempty"`
+	SSLVerify      *bool    `json:"sslverify,omitempty" toml:"sslverify,omitempty"`
+	ModuleHotfixes bool     `json:"module_hotfixes,omitempty" toml:"module_hotfixes,omitempty"`
+	Filename       string   `json:"filename,omitempty" toml:"filename,omitempty"`
+}
+
+// IsEnabled returns whether the repository is enabled, which it is by
+// default.
+func (r *RepositoryCustomization) IsEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+// CheckGPG returns whether the packages of the repository are verified.
+func (r *RepositoryCustomization) CheckGPG() bool {
+	if r.GPGCheck != nil {
+		return *r.GPGCheck
+	}
+	return len(r.GPGKeys) > 0
+}
+
+// VerifySSL returns whether the certificates of the repository's servers are
+// verified, which they are by default.
+func (r *RepositoryCustomization) VerifySSL() bool {
+	return r.SSLVerify == nil || *r.SSLVerify
+}
+
+// RepoFilename returns the name of the file in /etc/yum.repos.d which
+// defines the repository.
+func (r *RepositoryCustomization) RepoFilename() string {
+	if r.Filename != "" {
+		return r.Filename
+	}
+	return r.ID + ".repo"
 }
 
 type CustomizationError struct {
@@ -187,3 +409,75 @@ func (c *Customizations) GetServices() *ServicesCustomization {
 
 	return c.Services
 }
+
+func (c *Customizations) GetFilesystems() []FilesystemCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Filesystem
+}
+
+func (c *Customizations) GetDisk() *DiskCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Disk
+}
+
+func (c *Customizations) GetFiles() []FileCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Files
+}
+
+func (c *Customizations) GetDirectories() []DirectoryCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Directories
+}
+
+func (c *Customizations) GetSubscription() *SubscriptionCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Subscription
+}
+
+func (c *Customizations) GetFDO() *FDOCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.FDO
+}
+
+func (c *Customizations) GetIgnition() *IgnitionCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Ignition
+}
+
+func (c *Customizations) GetRepositories() []RepositoryCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Repositories
+}
+
+func (c *Customizations) GetInstallationDevice() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.InstallationDevice
+}
@@ -267,3 +267,16 @@ func TestNilGetTimezoneSettings(t *testing.T) {
 	assert.Nil(t, retTimezone)
 	assert.Nil(t, retNTPServers)
 }
+
+func TestAuthorizedKeys(t *testing.T) {
+	key := "ssh-rsa AAAA user@host"
+
+	user := UserCustomization{Name: "user", Key: &key, Keys: []string{"ssh-ed25519 AAAA user@laptop"}}
+	assert.Equal(t, "ssh-rsa AAAA user@host\nssh-ed25519 AAAA user@laptop", *user.AuthorizedKeys())
+
+	user = UserCustomization{Name: "user", Keys: []string{"ssh-ed25519 AAAA user@laptop"}}
+	assert.Equal(t, "ssh-ed25519 AAAA user@laptop", *user.AuthorizedKeys())
+
+	user = UserCustomization{Name: "user"}
+	assert.Nil(t, user.AuthorizedKeys())
+}
@@ -99,7 +99,7 @@ func UploadImageToAWS(c *awsCredentials, imagePath string, imageName string) err
 	if err != nil {
 		return fmt.Errorf("cannot upload the image: %v", err)
 	}
-	_, err = uploader.Register(imageName, c.Bucket, imageName, nil, common.CurrentArch())
+	_, err = uploader.Register(imageName, c.Bucket, imageName, nil, common.CurrentArch(), nil)
 	if err != nil {
 		return fmt.Errorf("cannot register the image: %v", err)
 	}
@@ -261,6 +261,84 @@ func (g *GCP) ComputeImageShare(ctx context.Context, imageName string, shareWith
 	return nil
 }
 
+// ComputeProjectMembers returns the accounts to share images with, so that they
+// can be used in the given projects, for example by the service projects of a
+// Shared VPC. For each project, this is the project's Google APIs service
+// agent, which creates instances for managed instance groups, and its Compute
+// Engine default service account. The returned members can be passed to
+// ComputeImageShare().
+//
+// projects - IDs of the projects to share the image with
+//
+// Uses:
+//	- Cloud Resource Manager API (through the Compute Engine API)
+func (g *GCP) ComputeProjectMembers(ctx context.Context, projects []string) ([]string, error) {
+	computeService, err := compute.NewService(ctx, option.WithCredentials(g.creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Compute Engine client: %v", err)
+	}
+
+	var members []string
+	for _, project := range projects {
+		p, err := computeService.Projects.Get(project).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get project '%s': %v", project, err)
+		}
+		members = append(members,
+			fmt.Sprintf("serviceAccount:%d@cloudservices.gserviceaccount.com", p.Id),
+			fmt.Sprintf("serviceAccount:%d-compute@developer.gserviceaccount.com", p.Id),
+		)
+	}
+
+	return members, nil
+}
+
+// ComputeImageCopy creates the image `imageName` from the existing image
+// `sourceImage` in the same project and enables the given guest OS features on
+// it. Guest OS features can only be set when an image is created, which is why
+// an imported image has to be copied to enable them.
+//
+// guestOSFeatures - Features to enable, e.g., UEFI_COMPATIBLE, GVNIC,
+//                   SECURE_BOOT, VIRTIO_SCSI_MULTIQUEUE, or SEV_CAPABLE.
+//                   See: https://cloud.google.com/compute/docs/images/create-delete-deprecate-private-images#guest-os-features
+//
+// Uses:
+//	- Compute Engine API
+func (g *GCP) ComputeImageCopy(ctx context.Context, sourceImage, imageName string, guestOSFeatures []string) error {
+	computeService, err := compute.NewService(ctx, option.WithCredentials(g.creds))
+	if err != nil {
+		return fmt.Errorf("failed to get Compute Engine client: %v", err)
+	}
+
+	image := &compute.Image{
+		Name:        imageName,
+		SourceImage: fmt.Sprintf("projects/%s/global/images/%s", g.creds.ProjectID, sourceImage),
+	}
+	for _, f := range guestOSFeatures {
+		image.GuestOsFeatures = append(image.GuestOsFeatures, &compute.GuestOsFeature{Type: f})
+	}
+
+	op, err := computeService.Images.Insert(g.creds.ProjectID, image).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to create image '%s': %v", imageName, err)
+	}
+
+	// Wait() returns after at most two minutes, even if the operation is
+	// still running
+	for op.Status != "DONE" {
+		op, err = computeService.GlobalOperations.Wait(g.creds.ProjectID, op.Name).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to wait for the creation of image '%s': %v", imageName, err)
+		}
+	}
+
+	if op.Error != nil && len(op.Error.Errors) > 0 {
+		return fmt.Errorf("failed to create image '%s': %s", imageName, op.Error.Errors[0].Message)
+	}
+
+	return nil
+}
+
 // ComputeImageDelete deletes a Compute Engine image with the given name. If the
 // image existed and was successfully deleted, no error is returned.
 //
@@ -0,0 +1,124 @@
+package cloudapi
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/oidc"
+	"github.com/osbuild/osbuild-composer/internal/rbac"
+)
+
+// requestTenant returns the tenant of the client which made `r`, or "" if it
+// isn't known.
+func requestTenant(r *http.Request) string {
+	if claims, ok := r.Context().Value(tokenClaimsKey).(*oidc.Claims); ok {
+		return claims.Tenant
+	}
+	if idHeader, ok := r.Context().Value(identityHeaderKey).(identityHeader); ok {
+		return idHeader.Identity.Internal.OrgId
+	}
+	return ""
+}
+
+// The roles of all clients when there is no policy. They may submit composes
+// and access the ones of their own tenant.
+var defaultRoles = rbac.Roles{rbac.SubmitCompose}
+
+// requestRoles returns the roles the policy grants the client which made `r`.
+func (server *Server) requestRoles(r *http.Request) rbac.Roles {
+	if server.policy == nil {
+		return defaultRoles
+	}
+	if claims, ok := r.Context().Value(tokenClaimsKey).(*oidc.Claims); ok {
+		return server.policy.ClientRoles(claims.Roles, claims.ClientID, claims.Subject)
+	}
+	if idHeader, ok := r.Context().Value(identityHeaderKey).(identityHeader); ok {
+		return server.policy.ClientRoles(nil, idHeader.Identity.AccountNumber)
+	}
+	return server.policy.Default
+}
+
+// authorize rejects requests to the API at `path` which the client isn't
+// allowed to make, including the ones to routes it doesn't know. Submitters
+// may read the composes of their own tenant, reading all composes requires
+// the ReadAnyCompose role. Listing composes only ever returns those of the
+// client's own tenant. Clients without a tenant share the composes without
+// one.
+func (server *Server) authorize(path string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := strings.Trim(strings.TrimPrefix(r.URL.Path, path), "/")
+			if !server.allowed(r, route) {
+				httpError(w, "Not allowed to access this resource", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (server *Server) allowed(r *http.Request, route string) bool {
+	parts := strings.Split(route, "/")
+	roles := server.requestRoles(r)
+
+	switch {
+	case r.Method == http.MethodPost && (route == "compose" || route == "compose/koji"):
+		return roles.Has(rbac.SubmitCompose)
+
+	case r.Method == http.MethodPost && route == "blueprints/validate":
+		return roles.Has(rbac.SubmitCompose)
+
+	case r.Method == http.MethodGet && route == "composes":
+		// only lists the composes of the tenant of the client
+		return roles.Has(rbac.ReadAnyCompose, rbac.SubmitCompose)
+
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "compose" && parts[1] == "koji":
+		return server.mayRead(r, roles, parts[2])
+
+	case r.Method == http.MethodGet && len(parts) >= 2 && (parts[0] == "compose" || parts[0] == "clones"):
+		return server.mayRead(r, roles, parts[1])
+
+	case r.Method == http.MethodDelete && len(parts) == 2 && parts[0] == "compose",
+		r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "compose" && (parts[2] == "retry" || parts[2] == "clone"):
+		// cloning uploads the image of the compose to the client's target
+		return roles.Has(rbac.Admin) || (roles.Has(rbac.SubmitCompose) && server.ownedByTenant(r, parts[1]))
+
+	case r.Method == http.MethodGet && (route == "openapi.json" || route == "version" || route == "signing-key"),
+		r.Method == http.MethodGet && parts[0] == "distros":
+		// describe composer itself, not any compose
+		return true
+	}
+
+	return false
+}
+
+// mayRead returns whether a client with `roles` may read the job `id`.
+func (server *Server) mayRead(r *http.Request, roles rbac.Roles, id string) bool {
+	if roles.Has(rbac.ReadAnyCompose) {
+		return true
+	}
+	return roles.Has(rbac.SubmitCompose) && server.ownedByTenant(r, id)
+}
+
+// ownedByTenant returns whether the job `id` was requested by the tenant of
+// the client which made `r`.
+func (server *Server) ownedByTenant(r *http.Request, id string) bool {
+	tenant := requestTenant(r)
+
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		// let the handler report the invalid id
+		return true
+	}
+	var job struct {
+		Tenant string `json:"tenant"`
+	}
+	if _, _, _, err := server.workers.Job(jobId, &job); err != nil {
+		log.Printf("Error looking up the tenant of job %s: %v", jobId, err)
+		return false
+	}
+	return job.Tenant == tenant
+}
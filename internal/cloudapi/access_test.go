@@ -0,0 +1,87 @@
+package cloudapi
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/rbac"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+func TestAllowed(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "cloudapi-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	workers := newTestWorkers(t, tempdir)
+	defer workers.Close()
+	server := &Server{workers: workers}
+
+	own, err := workers.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{Tenant: "org1"}, worker.PriorityBatch, "org1")
+	require.NoError(t, err)
+	other, err := workers.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{Tenant: "org2"}, worker.PriorityBatch, "org2")
+	require.NoError(t, err)
+
+	request := func(method, account string) *http.Request {
+		var id identityHeader
+		id.Identity.AccountNumber = account
+		id.Identity.Internal.OrgId = "org1"
+		r := httptest.NewRequest(method, "/", nil)
+		return r.WithContext(context.WithValue(r.Context(), identityHeaderKey, id))
+	}
+
+	cases := []struct {
+		method  string
+		route   string
+		account string
+		allowed bool
+	}{
+		{http.MethodPost, "compose", "submitter", true},
+		{http.MethodPost, "compose", "reader", false},
+		{http.MethodGet, "composes", "submitter", true},
+		{http.MethodGet, "openapi.json", "nobody", true},
+		{http.MethodGet, "distros/rhel-90", "nobody", true},
+
+		{http.MethodGet, "compose/" + own.String(), "submitter", true},
+		{http.MethodGet, "compose/" + other.String(), "submitter", false},
+		{http.MethodGet, "compose/" + other.String() + "/logs", "submitter", false},
+		{http.MethodGet, "compose/" + other.String(), "reader", true},
+		{http.MethodGet, "clones/" + other.String(), "submitter", false},
+
+		{http.MethodPost, "compose/" + own.String() + "/clone", "submitter", true},
+		{http.MethodPost, "compose/" + other.String() + "/clone", "submitter", false},
+		{http.MethodPost, "compose/" + other.String() + "/clone", "reader", false},
+		{http.MethodPost, "compose/" + other.String() + "/clone", "admin", true},
+		{http.MethodPost, "compose/" + other.String() + "/retry", "submitter", false},
+		{http.MethodDelete, "compose/" + own.String(), "submitter", true},
+		{http.MethodDelete, "compose/" + other.String(), "submitter", false},
+		{http.MethodDelete, "compose/" + other.String(), "admin", true},
+
+		{http.MethodGet, "unknown", "admin", false},
+		{http.MethodPut, "compose/" + own.String(), "admin", false},
+	}
+
+	server.policy = &rbac.Policy{
+		Clients: map[string]rbac.Roles{
+			"submitter": {rbac.SubmitCompose},
+			"reader":    {rbac.ReadAnyCompose},
+			"admin":     {rbac.Admin},
+		},
+	}
+	for _, c := range cases {
+		require.Equalf(t, c.allowed, server.allowed(request(c.method, c.account), c.route), "%s %s by %s", c.method, c.route, c.account)
+	}
+
+	// without a policy, everyone may submit composes and access those of
+	// their own tenant
+	server.policy = nil
+	require.True(t, server.allowed(request(http.MethodPost, "nobody"), "compose/"+own.String()+"/clone"))
+	require.False(t, server.allowed(request(http.MethodPost, "nobody"), "compose/"+other.String()+"/clone"))
+	require.False(t, server.allowed(request(http.MethodGet, "nobody"), "compose/"+other.String()))
+}
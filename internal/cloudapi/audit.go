@@ -0,0 +1,65 @@
+package cloudapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/osbuild/osbuild-composer/internal/audit"
+	"github.com/osbuild/osbuild-composer/internal/oidc"
+)
+
+// SetAuditLog makes the API record all requests which start or delete
+// composes in `auditLog`. Must be called before Handler().
+func (server *Server) SetAuditLog(auditLog *audit.Log) {
+	server.audit = auditLog
+}
+
+// auditEvent returns a function which returns the audit event of requests to
+// the API at `path`, or nil for ones which don't change anything.
+func auditEvent(path string) func(*http.Request) *audit.Event {
+	return func(r *http.Request) *audit.Event {
+		if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+			return nil
+		}
+
+		route := strings.Trim(strings.TrimPrefix(r.URL.Path, path), "/")
+		// validating doesn't change anything
+		if route == "blueprints/validate" {
+			return nil
+		}
+
+		event := &audit.Event{
+			API:    "cloudapi",
+			Actor:  requestActor(r),
+			Source: r.RemoteAddr,
+		}
+
+		parts := strings.Split(route, "/")
+		if len(parts) == 3 && parts[0] == "compose" && parts[2] == "clone" {
+			event.Action = "compose/clone"
+			event.Object = parts[1]
+		} else if r.Method == http.MethodDelete && len(parts) == 2 && parts[0] == "compose" {
+			event.Action = "compose/delete"
+			event.Object = parts[1]
+		} else {
+			// the compose handlers add the id of the new compose
+			event.Action = route
+		}
+		return event
+	}
+}
+
+// requestActor returns the identity of the client which made `r`, or "" if
+// it isn't known.
+func requestActor(r *http.Request) string {
+	if claims, ok := r.Context().Value(tokenClaimsKey).(*oidc.Claims); ok {
+		if claims.Subject != "" {
+			return claims.Subject
+		}
+		return claims.ClientID
+	}
+	if idHeader, ok := r.Context().Value(identityHeaderKey).(identityHeader); ok {
+		return idHeader.Identity.AccountNumber
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package cloudapi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/cloudapi/v2"
+	"github.com/osbuild/osbuild-composer/internal/logging"
+)
+
+// How often the status of a compose with a callback is checked.
+var callbackPollInterval = 10 * time.Second
+
+// How often sending an event is attempted before it is dropped, and how long
+// to wait between attempts.
+var callbackAttempts = 3
+var callbackRetryInterval = 10 * time.Second
+
+var callbackClient = &http.Client{Timeout: 30 * time.Second}
+
+// checkCallback returns an error if events cannot be sent to `callback`.
+func checkCallback(callback *v2.Callback) error {
+	u, err := url.Parse(callback.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Callback URL must be an http or https URL")
+	}
+	if callback.Secret == "" {
+		return fmt.Errorf("Callback secret must not be empty")
+	}
+	return nil
+}
+
+// watchCompose sends an event to `callback` whenever the status of the
+// compose `id` changes, until the compose has finished. Events are sent in
+// order; the next status is not checked until an event has been delivered
+// or dropped.
+//
+// Callbacks only live in memory: composes which are running when composer
+// restarts don't send any more events.
+func (server *Server) watchCompose(id uuid.UUID, callback v2.Callback) {
+	logger := logging.Default().With(logging.ComposeID, id)
+	var last ImageStatusValue
+	for {
+		status, err := server.currentComposeStatus(id)
+		if err != nil {
+			logger.Errorf("Error getting status of compose for its callback: %v", err)
+		} else if status.ImageStatus.Status != last {
+			last = status.ImageStatus.Status
+			event := ComposeEvent{
+				Id:            id.String(),
+				Status:        last,
+				Reason:        status.ImageStatus.Error,
+				JobError:      status.ImageStatus.JobError,
+				ComposeStatus: *status,
+			}
+			if err := sendEvent(callback, &event); err != nil {
+				logger.Warnf("Dropping %s event of compose: %v", last, err)
+			}
+		}
+
+		if last == ImageStatusValue_success || last == ImageStatusValue_failure {
+			return
+		}
+		time.Sleep(callbackPollInterval)
+	}
+}
+
+func (server *Server) currentComposeStatus(id uuid.UUID) (*ComposeStatus, error) {
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(id, &rawArgs)
+	if err != nil {
+		return nil, err
+	}
+	return server.composeStatus(id, jobType, rawArgs, deps)
+}
+
+// sendEvent POSTs `event` to `callback`, signed with the callback's secret.
+// It retries when the request fails or the receiver responds with an error.
+func sendEvent(callback v2.Callback, event *ComposeEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, []byte(callback.Secret))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	for attempt := 1; ; attempt++ {
+		err = postEvent(callback.Url, body, signature)
+		if err == nil || attempt == callbackAttempts {
+			return err
+		}
+		time.Sleep(callbackRetryInterval)
+	}
+}
+
+func postEvent(url string, body []byte, signature string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Composer-Signature", signature)
+
+	resp, err := callbackClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("callback responded with %s", resp.Status)
+	}
+	return nil
+}
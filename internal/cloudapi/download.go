@@ -0,0 +1,111 @@
+package cloudapi
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// ComposeDownload handles a /compose/{id}/download GET request. It streams
+// the image of a compose which had a local upload request, with support for
+// range requests.
+func (server *Server) ComposeDownload(w http.ResponseWriter, r *http.Request, id string) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(jobId, &rawArgs)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if jobType == "compose" {
+		image, ok := composeImage(w, id, rawArgs, deps, "download")
+		if !ok {
+			return
+		}
+		// the compose of an image which is uploaded to several targets
+		server.ComposeDownload(w, r, image.String())
+		return
+	}
+	if !strings.HasPrefix(jobType, "osbuild:") {
+		httpError(w, fmt.Sprintf("Job %s does not build an image", id), http.StatusBadRequest)
+		return
+	}
+
+	var job worker.OSBuildJob
+	if err := json.Unmarshal(rawArgs, &job); err != nil {
+		httpError(w, fmt.Sprintf("Error reading compose %s: %s", id, err), http.StatusInternalServerError)
+		return
+	}
+	// composer receives the images of all composes, but only keeps those
+	// which were requested for downloading
+	if !job.KeepImage {
+		httpError(w, fmt.Sprintf("Compose %s had no local upload request", id), http.StatusNotFound)
+		return
+	}
+
+	var result worker.OSBuildJobResult
+	status, _, err := server.workers.JobStatus(jobId, &result)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if status.Finished.IsZero() || result.OSBuildOutput == nil || !result.OSBuildOutput.Success {
+		httpError(w, fmt.Sprintf("The image of compose %s has not been built", id), http.StatusNotFound)
+		return
+	}
+
+	reader, _, err := server.workers.JobArtifact(jobId, job.ImageName)
+	if err != nil {
+		httpError(w, fmt.Sprintf("The image of compose %s is not available: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+	image, ok := reader.(io.ReadSeeker)
+	if !ok {
+		httpError(w, fmt.Sprintf("Error reading the image of compose %s", id), http.StatusInternalServerError)
+		return
+	}
+
+	// the digest composer computed when it received the image, or the one
+	// the worker computed for images received by older versions
+	var sha256 string
+	artifacts, err := server.workers.JobArtifacts(jobId)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Error getting artifacts of compose %s: %s", id, err), http.StatusInternalServerError)
+		return
+	}
+	for _, a := range artifacts {
+		if a.Name == job.ImageName {
+			sha256 = a.SHA256
+		}
+	}
+	for _, f := range result.ImageFiles {
+		if sha256 == "" && f.Name == job.ImageName {
+			sha256 = f.SHA256
+		}
+	}
+	if digest, err := hex.DecodeString(sha256); err == nil && len(digest) > 0 {
+		w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(digest))
+		w.Header().Set("ETag", `"`+sha256+`"`)
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s", id, job.ImageName))
+
+	// handles range requests, including If-Range with the ETag
+	http.ServeContent(w, r, job.ImageName, status.Finished, image)
+}
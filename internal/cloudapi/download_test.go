@@ -0,0 +1,49 @@
+package cloudapi
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// Requests for the image of a compose fail cleanly for composes without one
+func TestComposeWithoutImages(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "cloudapi-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	workers := newTestWorkers(t, tempdir)
+	defer workers.Close()
+	server := &Server{workers: workers}
+
+	id, err := workers.EnqueueCompose(context.Background(), &worker.ComposeJob{}, nil)
+	require.NoError(t, err)
+
+	for name, handler := range map[string]func(w http.ResponseWriter, r *http.Request, id string){
+		"download":   server.ComposeDownload,
+		"provenance": server.ComposeProvenance,
+		"metadata":   server.ComposeMetadata,
+		"clone":      server.ComposeClone,
+		"sbom": func(w http.ResponseWriter, r *http.Request, id string) {
+			server.ComposeSbom(w, r, id, ComposeSbomParams{})
+		},
+		"logs": func(w http.ResponseWriter, r *http.Request, id string) {
+			server.ComposeLog(w, r, id, ComposeLogParams{})
+		},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/compose/"+id.String()+"/"+name, strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		handler(resp, req, id.String())
+		require.Equal(t, http.StatusNotFound, resp.Code, name)
+		require.Contains(t, resp.Body.String(), "has no image", name)
+	}
+}
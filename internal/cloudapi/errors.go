@@ -0,0 +1,41 @@
+package cloudapi
+
+import (
+	"net/http"
+
+	"github.com/osbuild/osbuild-composer/internal/cloudapi/v2"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
+)
+
+// httpError replies to the request with an ErrorInfo with `reason` and the
+// code of HTTP status `code`. Like http.Error, it doesn't end the request,
+// and other headers should already have been set. Responses of the
+// deprecated version 1 of the API, which carry the Deprecation header, keep
+// their plain-text errors.
+func httpError(w http.ResponseWriter, reason string, code int) {
+	if w.Header().Get("Deprecation") != "" {
+		http.Error(w, reason, code)
+		return
+	}
+	v2.Error(w, reason, code)
+}
+
+// jobError converts the error of a job to the error of the API, or returns
+// nil if there is none.
+func jobError(err *clienterrors.Error) *ErrorInfo {
+	if err == nil {
+		return nil
+	}
+
+	e := &ErrorInfo{
+		Code:   string(err.Code),
+		Reason: err.Reason,
+	}
+	if err.Details != nil {
+		e.Details = &err.Details
+	}
+	if err.OSBuildError != "" {
+		e.OsbuildError = &err.OSBuildError
+	}
+	return e
+}
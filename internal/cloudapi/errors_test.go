@@ -0,0 +1,165 @@
+package cloudapi
+
+import (
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/cloudapi/v2"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+func TestErrorResponses(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "cloudapi-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	workers := newTestWorkers(t, tempdir)
+	defer workers.Close()
+	server := NewServer(workers, nil, nil, nil)
+	v1Handler := server.Handler("/api/composer/v1", nil, nil, nil)
+	v2Handler := server.HandlerV2("/api/composer/v2", nil, nil, nil)
+
+	id, err := workers.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+
+	cases := []struct {
+		path   string
+		status int
+		code   string
+		reason string
+	}{
+		{"/compose/not-a-uuid", http.StatusBadRequest, "bad-request", "Invalid format for parameter id"},
+		{"/compose/30000000-0000-0000-0000-000000000000", http.StatusForbidden, "forbidden", "Not allowed"},
+		{"/compose/" + id.String() + "/sbom", http.StatusNotFound, "not-found", "has no SBOM"},
+		// rejected by the code which binds the parameters
+		{"/compose/" + id.String() + "/log?offset=first", http.StatusBadRequest, "bad-request", "Invalid format for parameter offset"},
+	}
+
+	for _, c := range cases {
+		resp := httptest.NewRecorder()
+		v2Handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/composer/v2"+c.path, nil))
+		require.Equalf(t, c.status, resp.Code, "path: %s", c.path)
+		require.Equalf(t, "application/json; charset=utf-8", resp.Header().Get("Content-Type"), "path: %s", c.path)
+
+		var body map[string]interface{}
+		require.NoErrorf(t, json.Unmarshal(resp.Body.Bytes(), &body), "path: %s", c.path)
+		require.Lenf(t, body, 2, "path: %s", c.path)
+		require.Equalf(t, c.code, body["code"], "path: %s", c.path)
+		require.Containsf(t, body["reason"], c.reason, "path: %s", c.path)
+
+		// version 1 keeps its plain-text errors
+		resp = httptest.NewRecorder()
+		v1Handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/composer/v1"+c.path, nil))
+		require.Equalf(t, c.status, resp.Code, "path: %s", c.path)
+		require.Equalf(t, "text/plain; charset=utf-8", resp.Header().Get("Content-Type"), "path: %s", c.path)
+		require.Containsf(t, resp.Body.String(), c.reason, "path: %s", c.path)
+		require.Falsef(t, json.Valid(resp.Body.Bytes()), "path: %s", c.path)
+	}
+
+	// statuses without a code of their own get the one of internal errors
+	resp := httptest.NewRecorder()
+	httpError(resp, "I'm a teapot", http.StatusTeapot)
+	require.Equal(t, http.StatusTeapot, resp.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	require.Equal(t, v2.ErrorInternal, body["code"])
+}
+
+// TestErrorCodes checks that every HTTP status which the handlers pass to
+// httpError() or v2.Error() has a code of its own, by looking for those calls
+// in the source of both packages.
+func TestErrorCodes(t *testing.T) {
+	fset := token.NewFileSet()
+	statuses := make(map[string]token.Position)
+	for _, pattern := range []string{"*.go", "v2/*.go"} {
+		names, err := filepath.Glob(pattern)
+		require.NoError(t, err)
+		for _, name := range names {
+			if strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(fset, name, nil, 0)
+			require.NoError(t, err)
+			for _, decl := range f.Decls {
+				// the status is a parameter of httpError() itself
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "httpError" {
+					continue
+				}
+				ast.Inspect(decl, func(n ast.Node) bool {
+					call, ok := n.(*ast.CallExpr)
+					if !ok || !isErrorFunc(call.Fun) {
+						return true
+					}
+					pos := fset.Position(call.Pos())
+					status, ok := call.Args[len(call.Args)-1].(*ast.SelectorExpr)
+					require.Truef(t, ok, "%s: the status must be a constant of net/http", pos)
+					pkg, ok := status.X.(*ast.Ident)
+					require.Truef(t, ok && pkg.Name == "http", "%s: the status must be a constant of net/http", pos)
+					statuses[status.Sel.Name] = pos
+					return true
+				})
+			}
+		}
+	}
+	require.NotEmpty(t, statuses)
+
+	// look up the values of the constants by type-checking a file which
+	// refers to them
+	src := "package statuses\nimport \"net/http\"\n"
+	for name := range statuses {
+		src += "const _ = http." + name + "\n"
+	}
+	f, err := parser.ParseFile(fset, "statuses.go", src, 0)
+	require.NoError(t, err)
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("statuses", fset, []*ast.File{f}, info)
+	require.NoError(t, err)
+	values := make(map[string]int)
+	for expr, tv := range info.Types {
+		if sel, ok := expr.(*ast.SelectorExpr); ok && tv.Value != nil {
+			value, ok := constant.Int64Val(tv.Value)
+			require.True(t, ok)
+			values[sel.Sel.Name] = int(value)
+		}
+	}
+
+	for name, pos := range statuses {
+		status, ok := values[name]
+		require.Truef(t, ok, "%s: http.%s is not a constant", pos, name)
+		if status == http.StatusInternalServerError {
+			continue
+		}
+		resp := httptest.NewRecorder()
+		v2.Error(resp, "", status)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		require.NotEqualf(t, v2.ErrorInternal, body["code"], "%s: http.%s has no error code", pos, name)
+	}
+}
+
+func isErrorFunc(fun ast.Expr) bool {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name == "httpError" || f.Name == "Error"
+	case *ast.SelectorExpr:
+		pkg, ok := f.X.(*ast.Ident)
+		return ok && pkg.Name == "v2" && f.Sel.Name == "Error"
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package cloudapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/logging"
+)
+
+// How often the status of a compose is checked while its events are
+// streamed to a client.
+var eventsPollInterval = 2 * time.Second
+
+// ComposeEvents handles a /compose/{id}/events GET request
+func (server *Server) ComposeEvents(w http.ResponseWriter, r *http.Request, id string) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(jobId, &rawArgs)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpError(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var last []byte
+	for {
+		status, err := server.composeStatus(jobId, jobType, rawArgs, deps)
+		if err != nil {
+			// the response has started, all that's left is ending it
+			logging.Default().With(logging.ComposeID, id).Errorf("Error getting status of compose for its events: %v", err)
+			return
+		}
+
+		data, err := json.Marshal(status)
+		if err != nil {
+			panic("Failed to write response")
+		}
+		if !bytes.Equal(data, last) {
+			_, err = fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+			last = data
+		}
+
+		if s := status.ImageStatus.Status; s == ImageStatusValue_success || s == ImageStatusValue_failure {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(eventsPollInterval):
+		}
+	}
+}
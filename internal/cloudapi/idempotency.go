@@ -0,0 +1,154 @@
+package cloudapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/audit"
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// How long the compose started with an idempotency key is returned for
+// requests with the same key.
+var idempotencyKeyTTL = 24 * time.Hour
+
+// idempotencyKeys is the set of idempotency keys whose requests are being
+// handled by this instance. Keys are scoped to the tenant of the client.
+// Once a request started a compose, its key is stored with the compose in
+// the job queue instead.
+type idempotencyKeys struct {
+	mu      sync.Mutex
+	entries map[string]struct{}
+}
+
+func newIdempotencyKeys() *idempotencyKeys {
+	return &idempotencyKeys{entries: make(map[string]struct{})}
+}
+
+// reserve adds `key` and returns true, unless a request with `key` is already
+// being handled.
+func (k *idempotencyKeys) reserve(key string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.entries[key]; ok {
+		return false
+	}
+	k.entries[key] = struct{}{}
+	return true
+}
+
+// release forgets `key`, because its request has been handled.
+func (k *idempotencyKeys) release(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.entries, key)
+}
+
+// requestIdempotencyKey returns the idempotency key of the compose request
+// `ctx` belongs to, which must be stored with the job that is the compose.
+func requestIdempotencyKey(ctx context.Context) *worker.IdempotencyKey {
+	if key, ok := ctx.Value(idempotencyKeyKey).(*worker.IdempotencyKey); ok {
+		return key
+	}
+	return nil
+}
+
+// idempotent makes compose requests to the API at `path` with an
+// Idempotency-Key header start at most one compose: requests with the key of
+// an earlier request get the id of the compose that request started, as long
+// as their body is the same.
+func (server *Server) idempotent(path string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := strings.Trim(strings.TrimPrefix(r.URL.Path, path), "/")
+			idempotencyKey := r.Header.Get("Idempotency-Key")
+			if r.Method != http.MethodPost || (route != "compose" && route != "compose/koji") || idempotencyKey == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				httpError(w, "Could not read body", http.StatusBadRequest)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			tenant := requestTenant(r)
+			digest := sha256.Sum256(body)
+			key := &worker.IdempotencyKey{
+				Key:    idempotencyKey,
+				Digest: hex.EncodeToString(digest[:]),
+			}
+
+			// in-progress requests are only known to this instance
+			reservation := tenant + "\x00" + idempotencyKey
+			if !server.idempotency.reserve(reservation) {
+				httpError(w, "A request with this idempotency key is in progress", http.StatusConflict)
+				return
+			}
+			// the key must be released even when the handler panics
+			defer server.idempotency.release(reservation)
+
+			id, composeDigest, err := server.idempotentCompose(tenant, key.Key, time.Now())
+			if err != nil {
+				log.Printf("Error looking up idempotency key: %v", err)
+				httpError(w, "Could not look up idempotency key", http.StatusInternalServerError)
+				return
+			}
+			if id != uuid.Nil {
+				if composeDigest != key.Digest {
+					httpError(w, "Idempotency key was already used for a different request", http.StatusUnprocessableEntity)
+					return
+				}
+				audit.SetObject(r.Context(), id.String())
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusCreated)
+				err = json.NewEncoder(w).Encode(ComposeResult{Id: id.String()})
+				if err != nil {
+					panic("Failed to write response")
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), idempotencyKeyKey, key)))
+		})
+	}
+}
+
+// idempotentCompose returns the compose which a request of `tenant` with the
+// idempotency key `key` started within idempotencyKeyTTL before `now`, and
+// the digest of the body of that request. It returns uuid.Nil if there is no
+// such compose.
+func (server *Server) idempotentCompose(tenant, key string, now time.Time) (uuid.UUID, string, error) {
+	ids, _, err := server.workers.ListJobs(jobqueue.JobFilter{
+		Tenant:         &tenant,
+		IdempotencyKey: key,
+		Since:          now.Add(-idempotencyKeyTTL),
+		Limit:          1,
+	})
+	if err != nil || len(ids) == 0 {
+		return uuid.Nil, "", err
+	}
+
+	var args struct {
+		Idempotency worker.IdempotencyKey `json:"idempotency"`
+	}
+	if _, _, _, err := server.workers.Job(ids[0], &args); err != nil {
+		return uuid.Nil, "", err
+	}
+	return ids[0], args.Idempotency.Digest, nil
+}
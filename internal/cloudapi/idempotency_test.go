@@ -0,0 +1,86 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue/fsjobqueue"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// newTestWorkers returns a worker server with a job queue in `tempdir`.
+func newTestWorkers(t *testing.T, tempdir string) *worker.Server {
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	return worker.NewServer(nil, q, worker.Config{})
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "cloudapi-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	workers := newTestWorkers(t, tempdir)
+	defer workers.Close()
+
+	composes := 0
+	panics := true
+	compose := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if panics {
+			panic("compose failed")
+		}
+		composes++
+		id, err := workers.EnqueueCompose(r.Context(), &worker.ComposeJob{
+			Idempotency: requestIdempotencyKey(r.Context()),
+		}, nil)
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusCreated)
+		require.NoError(t, json.NewEncoder(w).Encode(ComposeResult{Id: id.String()}))
+	})
+
+	post := func(server *Server, key, body string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/api/compose", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+		resp := httptest.NewRecorder()
+		server.idempotent("/api")(compose).ServeHTTP(resp, req)
+		var result ComposeResult
+		_ = json.Unmarshal(resp.Body.Bytes(), &result)
+		return resp.Code, result.Id
+	}
+
+	server := &Server{workers: workers, idempotency: newIdempotencyKeys()}
+
+	// a panicking handler releases the key
+	require.Panics(t, func() { post(server, "key", "{}") })
+	panics = false
+	status, id := post(server, "key", "{}")
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, 1, composes)
+
+	status, again := post(server, "key", "{}")
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, id, again)
+	require.Equal(t, 1, composes)
+
+	status, _ = post(server, "key", `{"other": true}`)
+	require.Equal(t, http.StatusUnprocessableEntity, status)
+
+	// keys are kept with the compose, not in the server
+	restarted := &Server{workers: workers, idempotency: newIdempotencyKeys()}
+	status, again = post(restarted, "key", "{}")
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, id, again)
+	require.Equal(t, 1, composes)
+
+	status, other := post(restarted, "other-key", "{}")
+	require.Equal(t, http.StatusCreated, status)
+	require.NotEqual(t, id, other)
+	require.Equal(t, 2, composes)
+}
@@ -0,0 +1,362 @@
+package cloudapi
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/audit"
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/cloudapi/v2"
+	"github.com/osbuild/osbuild-composer/internal/common"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/logging"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
+)
+
+// ComposeKoji handles a new /compose/koji POST request. It enqueues a
+// koji-init job, which creates the build in Koji, one osbuild-koji job for
+// each image, and a koji-finalize job, which imports the images into the
+// build or fails it. The ID of the finalize job identifies the compose.
+func (server *Server) ComposeKoji(w http.ResponseWriter, r *http.Request) {
+	var request KojiComposeRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+	server.composeKoji(w, r, kojiComposeRequestV2(request))
+}
+
+// composeKoji starts the Koji compose `request` of any version of the API,
+// which is passed in the form of version 2.
+func (server *Server) composeKoji(w http.ResponseWriter, r *http.Request, request v2.KojiComposeRequest) {
+	distribution := server.distros.GetDistro(request.Distro)
+	if distribution == nil {
+		httpError(w, fmt.Sprintf("Unsupported distribution: %s", request.Distro), http.StatusBadRequest)
+		return
+	}
+
+	if len(request.ImageRequests) == 0 {
+		httpError(w, "At least one image must be requested", http.StatusBadRequest)
+		return
+	}
+
+	// draft builds have a tag of their own, so that they can't be tagged
+	// into the tag of regular builds by accident
+	draft := request.Koji.Draft != nil && *request.Koji.Draft
+	var tag string
+	if draft {
+		if request.Koji.Tag != nil {
+			httpError(w, "Draft builds are tagged into draft_tag, not tag", http.StatusBadRequest)
+			return
+		}
+		if request.Koji.DraftTag != nil {
+			tag = *request.Koji.DraftTag
+		}
+	} else {
+		if request.Koji.DraftTag != nil {
+			httpError(w, "draft_tag can only be set for draft builds", http.StatusBadRequest)
+			return
+		}
+		if request.Koji.Tag != nil {
+			tag = *request.Koji.Tag
+		}
+	}
+
+	var bp = blueprint.Blueprint{}
+	err := bp.Initialize()
+	if err != nil {
+		httpError(w, "Unable to initialize blueprint", http.StatusInternalServerError)
+		return
+	}
+
+	type imageRequest struct {
+		manifest  distro.Manifest
+		arch      string
+		imageType string
+		filename  string
+		exports   []string
+		size      uint64
+	}
+	imageRequests := make([]imageRequest, len(request.ImageRequests))
+	kojiFilenames := make([]string, len(request.ImageRequests))
+	kojiDirectory := "osbuild-composer-koji-" + uuid.New().String()
+
+	// use the same seed for all images so we get the same IDs
+	bigSeed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		panic("cannot generate a manifest seed: " + err.Error())
+	}
+	manifestSeed := bigSeed.Int64()
+
+	for i, ir := range request.ImageRequests {
+		arch, err := distribution.GetArch(ir.Architecture)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Unsupported architecture '%s' for distribution '%s'", ir.Architecture, request.Distro), http.StatusBadRequest)
+			return
+		}
+		imageType, err := arch.GetImageType(ir.ImageType)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Unsupported image type '%s' for %s/%s", ir.ImageType, ir.Architecture, request.Distro), http.StatusBadRequest)
+			return
+		}
+		if err := server.workers.CheckArch("osbuild-koji", arch.Name()); err != nil {
+			httpError(w, fmt.Sprintf("No worker is available to build images for architecture '%s'", arch.Name()), http.StatusBadRequest)
+			return
+		}
+		repositories, err := repoConfigs(ir.Repositories)
+		if err != nil {
+			httpError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		pkgSpecSets, err := rpmmd.DepsolvePackageSets(server.rpmMetadata, imageType.PackageSets(bp), repositories, distribution.ModulePlatformID(), arch.Name())
+		if err != nil {
+			httpError(w, fmt.Sprintf("Failed to depsolve base packages for %s/%s/%s: %s", ir.ImageType, ir.Architecture, request.Distro, err), http.StatusInternalServerError)
+			return
+		}
+
+		imageOptions := distro.ImageOptions{
+			Size:   imageType.Size(0),
+			OSTree: distro.OSTreeImageOptions{Ref: imageType.OSTreeRef()},
+		}
+		manifest, err := imageType.Manifest(nil, imageOptions, repositories, pkgSpecSets, manifestSeed)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Failed to get manifest for for %s/%s/%s: %s", ir.ImageType, ir.Architecture, request.Distro, err), http.StatusBadRequest)
+			return
+		}
+
+		imageRequests[i] = imageRequest{
+			manifest:  manifest,
+			arch:      arch.Name(),
+			imageType: imageType.Name(),
+			filename:  imageType.Filename(),
+			exports:   imageType.Exports(),
+			size:      imageOptions.Size,
+		}
+		kojiFilenames[i] = fmt.Sprintf(
+			"%s-%s-%s.%s%s",
+			request.Name,
+			request.Version,
+			request.Release,
+			ir.Architecture,
+			common.SplitExtension(imageType.Filename()),
+		)
+	}
+
+	jobs := []worker.DAGJob{
+		{
+			Type: "koji-init",
+			Args: &worker.KojiInitJob{
+				Server:  request.Koji.Server,
+				Name:    request.Name,
+				Version: request.Version,
+				Release: request.Release,
+				Draft:   draft,
+			},
+		},
+	}
+
+	finalizeDeps := []int{0}
+	for i, ir := range imageRequests {
+		finalizeDeps = append(finalizeDeps, len(jobs))
+		jobs = append(jobs, worker.DAGJob{
+			Type: "osbuild-koji",
+			Arch: ir.arch,
+			Args: &worker.OSBuildKojiJob{
+				Manifest:      ir.manifest,
+				ImageName:     ir.filename,
+				ImageType:     ir.imageType,
+				Exports:       ir.exports,
+				KojiServer:    request.Koji.Server,
+				KojiDirectory: kojiDirectory,
+				KojiFilename:  kojiFilenames[i],
+				ImageSize:     ir.size,
+			},
+			Dependencies: []int{0},
+		})
+	}
+
+	jobs = append(jobs, worker.DAGJob{
+		Type: "koji-finalize",
+		Args: &worker.KojiFinalizeJob{
+			Server:        request.Koji.Server,
+			Name:          request.Name,
+			Version:       request.Version,
+			Release:       request.Release,
+			KojiFilenames: kojiFilenames,
+			KojiDirectory: kojiDirectory,
+			TaskID:        uint64(request.Koji.TaskId),
+			StartTime:     uint64(time.Now().Unix()),
+			Draft:         draft,
+			Tag:           tag,
+			Tenant:        requestTenant(r),
+			Idempotency:   requestIdempotencyKey(r.Context()),
+		},
+		Dependencies: finalizeDeps,
+	})
+
+	ids, err := server.workers.EnqueueDAG(r.Context(), jobs)
+	if err != nil {
+		httpError(w, "Failed to enqueue manifests", http.StatusInternalServerError)
+		return
+	}
+
+	var response ComposeResult
+	response.Id = ids[len(ids)-1].String()
+	audit.SetObject(r.Context(), response.Id)
+	logging.Default().With(logging.ComposeID, response.Id, logging.Tenant, requestTenant(r)).Infof("Koji compose enqueued")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
+// KojiComposeStatus handles a /compose/koji/{id} GET request
+func (server *Server) KojiComposeStatus(w http.ResponseWriter, r *http.Request, id string) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	jobType, _, _, err := server.workers.Job(jobId, &json.RawMessage{})
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if jobType != "koji-finalize" {
+		httpError(w, fmt.Sprintf("Job %s is not a Koji compose", id), http.StatusNotFound)
+		return
+	}
+
+	var finalizeResult worker.KojiFinalizeJobResult
+	finalizeStatus, deps, err := server.workers.JobStatus(jobId, &finalizeResult)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+
+	var initResult worker.KojiInitJobResult
+	_, _, err = server.workers.JobStatus(deps[0], &initResult)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Error getting status of Koji build of job %s: %s", id, err), http.StatusInternalServerError)
+		return
+	}
+
+	response := KojiComposeStatus{
+		ImageStatuses: []ImageStatus{},
+	}
+
+	failed := finalizeStatus.Canceled || initResult.KojiError != "" || finalizeResult.KojiError != "" || finalizeResult.TagError != ""
+	for _, dep := range deps[1:] {
+		var buildResult worker.OSBuildKojiJobResult
+		buildStatus, _, err := server.workers.JobStatus(dep, &buildResult)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error getting status of image build of job %s: %s", id, err), http.StatusInternalServerError)
+			return
+		}
+
+		imageStatus := ImageStatus{
+			Status: kojiImageStatusFromJobStatus(buildStatus, &initResult, &buildResult),
+		}
+		if imageStatus.Status == ImageStatusValue_failure {
+			jobErr := kojiImageError(buildStatus, &initResult, &buildResult)
+			imageStatus.Error = &jobErr.Reason
+			imageStatus.JobError = jobError(jobErr)
+			failed = true
+		} else if imageStatus.Status == ImageStatusValue_success {
+			// the images are imported and tagged together, which fails
+			// all of them
+			var jobErr *clienterrors.Error
+			switch {
+			case finalizeResult.KojiError != "":
+				jobErr = clienterrors.New(clienterrors.ErrorKojiImport, finalizeResult.KojiError, nil)
+			case finalizeResult.TagError != "":
+				jobErr = clienterrors.New(clienterrors.ErrorKojiTag, finalizeResult.TagError, nil)
+			}
+			if jobErr != nil {
+				imageStatus.Status = ImageStatusValue_failure
+				imageStatus.Error = &jobErr.Reason
+				imageStatus.JobError = jobError(jobErr)
+				failed = true
+			}
+		}
+		response.ImageStatuses = append(response.ImageStatuses, imageStatus)
+	}
+
+	switch {
+	case failed:
+		response.Status = ImageStatusValue_failure
+	case !finalizeStatus.Finished.IsZero():
+		response.Status = ImageStatusValue_success
+	case !finalizeStatus.Started.IsZero():
+		response.Status = ImageStatusValue_uploading
+	default:
+		response.Status = ImageStatusValue_pending
+		for _, s := range response.ImageStatuses {
+			if s.Status != ImageStatusValue_pending {
+				response.Status = ImageStatusValue_building
+			}
+		}
+	}
+
+	if initResult.BuildID != 0 {
+		buildID := int(initResult.BuildID)
+		response.KojiBuildId = &buildID
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
+func kojiImageStatusFromJobStatus(js *worker.JobStatus, initResult *worker.KojiInitJobResult, result *worker.OSBuildKojiJobResult) ImageStatusValue {
+	if js.Canceled || initResult.KojiError != "" {
+		return ImageStatusValue_failure
+	}
+
+	if js.Started.IsZero() {
+		return ImageStatusValue_pending
+	}
+
+	if js.Finished.IsZero() {
+		return ImageStatusValue_building
+	}
+
+	if result.OSBuildOutput != nil && result.OSBuildOutput.Success && result.KojiError == "" {
+		return ImageStatusValue_success
+	}
+
+	return ImageStatusValue_failure
+}
+
+// kojiImageError returns why the osbuild-koji job with status `js` and
+// result `result` failed.
+func kojiImageError(js *worker.JobStatus, initResult *worker.KojiInitJobResult, result *worker.OSBuildKojiJobResult) *clienterrors.Error {
+	switch {
+	case js.Canceled:
+		return clienterrors.New(clienterrors.ErrorCanceled, "The compose was canceled", nil)
+	case initResult.KojiError != "":
+		return clienterrors.New(clienterrors.ErrorKojiInit, "Could not initialize the Koji build: "+initResult.KojiError, nil)
+	case result.KojiError != "":
+		return clienterrors.New(clienterrors.ErrorKojiUpload, "Uploading the image to Koji failed: "+result.KojiError, nil)
+	case result.JobError != nil:
+		return result.JobError
+	case result.OSBuildOutput != nil && !result.OSBuildOutput.Success:
+		return clienterrors.OSBuildFailed(result.OSBuildOutput, "")
+	default:
+		return clienterrors.New(clienterrors.ErrorWorker, "Building the image failed", nil)
+	}
+}
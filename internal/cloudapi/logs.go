@@ -0,0 +1,88 @@
+package cloudapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// ComposeLog handles a /compose/{id}/log GET request
+func (server *Server) ComposeLog(w http.ResponseWriter, r *http.Request, id string, params ComposeLogParams) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	offset := 0
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+	if offset < 0 {
+		httpError(w, "Offset must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(jobId, &rawArgs)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if jobType == "compose" {
+		image, ok := composeImage(w, id, rawArgs, deps, "request the log of")
+		if !ok {
+			return
+		}
+		// the compose of an image which is uploaded to several targets
+		server.ComposeLog(w, r, image.String(), params)
+		return
+	}
+	if !strings.HasPrefix(jobType, "osbuild:") {
+		httpError(w, fmt.Sprintf("Job %s does not build an image", id), http.StatusBadRequest)
+		return
+	}
+
+	var result worker.OSBuildJobResult
+	status, _, err := server.workers.JobStatus(jobId, &result)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+
+	var response ComposeLog
+	if !status.Finished.IsZero() {
+		var buf bytes.Buffer
+		if result.OSBuildOutput != nil {
+			if err := result.OSBuildOutput.Write(&buf); err != nil {
+				httpError(w, fmt.Sprintf("Error writing log of job %s: %s", id, err), http.StatusInternalServerError)
+				return
+			}
+		}
+		response = ComposeLog{
+			Log:      buf.String(),
+			Offset:   buf.Len(),
+			Finished: true,
+		}
+	} else {
+		// the worker sends the log while osbuild runs; it is empty until
+		// the job has started
+		chunk := server.workers.JobLog(jobId, offset)
+		response = ComposeLog{
+			Log:    string(chunk),
+			Offset: offset + len(chunk),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
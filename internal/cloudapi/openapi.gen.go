@@ -18,12 +18,22 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
+// AWSAmi defines model for AWSAmi.
+type AWSAmi struct {
+	Ami    string `json:"ami"`
+	Region string `json:"region"`
+}
+
 // AWSS3UploadRequestOptions defines model for AWSS3UploadRequestOptions.
 type AWSS3UploadRequestOptions struct {
-	Region string                    `json:"region"`
-	S3     AWSUploadRequestOptionsS3 `json:"s3"`
+	Region string `json:"region"`
+
+	// Credentials for uploading to the bucket. Either access keys or a role
+	// is required.
+	S3 AWSUploadRequestOptionsS3 `json:"s3"`
 }
 
 // AWSS3UploadStatus defines model for AWSS3UploadStatus.
@@ -31,32 +41,95 @@ type AWSS3UploadStatus struct {
 	Url string `json:"url"`
 }
 
+// AWSTag defines model for AWSTag.
+type AWSTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
 // AWSUploadRequestOptions defines model for AWSUploadRequestOptions.
 type AWSUploadRequestOptions struct {
+
+	// Credentials for importing and registering the image. The credentials
+	// of the s3 options are used if these are empty.
 	Ec2    AWSUploadRequestOptionsEc2 `json:"ec2"`
 	Region string                     `json:"region"`
-	S3     AWSUploadRequestOptionsS3  `json:"s3"`
+
+	// Credentials for uploading to the bucket. Either access keys or a role
+	// is required.
+	S3 AWSUploadRequestOptionsS3 `json:"s3"`
 }
 
 // AWSUploadRequestOptionsEc2 defines model for AWSUploadRequestOptionsEc2.
 type AWSUploadRequestOptionsEc2 struct {
-	AccessKeyId       string    `json:"access_key_id"`
-	SecretAccessKey   string    `json:"secret_access_key"`
+	AccessKeyId *string `json:"access_key_id,omitempty"`
+
+	// Regions to copy the AMI to after it has been registered in the
+	// region of the upload. The copies are tagged and shared like the
+	// AMI itself.
+	CopyToRegions *[]string `json:"copy_to_regions,omitempty"`
+
+	// External ID which the trust policy of the role requires.
+	ExternalId *string `json:"external_id,omitempty"`
+
+	// IAM role to assume for the upload, with the access keys if they
+	// are set, or else with the credentials of the worker.
+	RoleArn         *string `json:"role_arn,omitempty"`
+	SecretAccessKey *string `json:"secret_access_key,omitempty"`
+
+	// Session token of temporary access keys.
+	SessionToken      *string   `json:"session_token,omitempty"`
 	ShareWithAccounts *[]string `json:"share_with_accounts,omitempty"`
 	SnapshotName      *string   `json:"snapshot_name,omitempty"`
+
+	// Tags of the AMIs and their snapshots, in addition to their name.
+	Tags *[]AWSTag `json:"tags,omitempty"`
 }
 
 // AWSUploadRequestOptionsS3 defines model for AWSUploadRequestOptionsS3.
 type AWSUploadRequestOptionsS3 struct {
-	AccessKeyId     string `json:"access_key_id"`
-	Bucket          string `json:"bucket"`
-	SecretAccessKey string `json:"secret_access_key"`
+	AccessKeyId *string `json:"access_key_id,omitempty"`
+	Bucket      string  `json:"bucket"`
+
+	// External ID which the trust policy of the role requires.
+	ExternalId *string `json:"external_id,omitempty"`
+
+	// IAM role to assume for the upload, with the access keys if they
+	// are set, or else with the credentials of the worker.
+	RoleArn         *string `json:"role_arn,omitempty"`
+	SecretAccessKey *string `json:"secret_access_key,omitempty"`
+
+	// Session token of temporary access keys.
+	SessionToken *string `json:"session_token,omitempty"`
 }
 
 // AWSUploadStatus defines model for AWSUploadStatus.
 type AWSUploadStatus struct {
-	Ami    string `json:"ami"`
-	Region string `json:"region"`
+	Ami string `json:"ami"`
+
+	// The AMIs of the image in all regions: the one in region,
+	// followed by its copies in the regions of copy_to_regions.
+	Amis   *[]AWSAmi `json:"amis,omitempty"`
+	Region string    `json:"region"`
+}
+
+// ArchitectureImageTypes defines model for ArchitectureImageTypes.
+type ArchitectureImageTypes struct {
+	Architecture string          `json:"architecture"`
+	ImageTypes   []ImageTypeInfo `json:"image_types"`
+}
+
+// Artifact defines model for Artifact.
+type Artifact struct {
+	Name string `json:"name"`
+
+	// Hex-encoded SHA-256 digest of the file, which composer computed
+	// when it received it. Not set for files received by older versions
+	// of composer.
+	Sha256 *string `json:"sha256,omitempty"`
+
+	// Size of the file in bytes
+	Size int64 `json:"size"`
 }
 
 // AzureUploadRequestOptions defines model for AzureUploadRequestOptions.
@@ -89,9 +162,130 @@ type AzureUploadStatus struct {
 	ImageName string `json:"image_name"`
 }
 
+// BlueprintDiagnostic defines model for BlueprintDiagnostic.
+type BlueprintDiagnostic struct {
+	Architecture string `json:"architecture"`
+
+	// The part of the compose request which has the problem
+	Field     *string `json:"field,omitempty"`
+	ImageType string  `json:"image_type"`
+	Message   string  `json:"message"`
+
+	// Errors make the compose fail, while warnings are about images
+	// which might not be what was intended
+	Severity string `json:"severity"`
+}
+
+// BlueprintValidation defines model for BlueprintValidation.
+type BlueprintValidation struct {
+	Diagnostics []BlueprintDiagnostic `json:"diagnostics"`
+
+	// Whether the compose request has no errors
+	Valid bool `json:"valid"`
+}
+
+// BootMode defines model for BootMode.
+type BootMode string
+
+// List of BootMode
+const (
+	BootMode_hybrid BootMode = "hybrid"
+	BootMode_legacy BootMode = "legacy"
+	BootMode_none   BootMode = "none"
+	BootMode_uefi   BootMode = "uefi"
+)
+
+// Callback defines model for Callback.
+type Callback struct {
+	Secret string `json:"secret"`
+	Url    string `json:"url"`
+}
+
+// CloneResult defines model for CloneResult.
+type CloneResult struct {
+	Id string `json:"id"`
+}
+
+// ComposeEvent defines model for ComposeEvent.
+type ComposeEvent struct {
+	ComposeStatus ComposeStatus `json:"compose_status"`
+
+	// ID of the compose whose status changed.
+	Id string `json:"id"`
+
+	// Why an image failed. Clients may rely on the code,
+	// which doesn't change between versions, but not on the reason. The
+	// codes of failed images are:
+	//   - canceled
+	//   - dependency-failed: the details are the error of the job the
+	//     image depended on, for example depsolving its packages
+	//   - invalid-job
+	//   - packages-not-found
+	//   - depsolve-failed
+	//   - repository-unavailable
+	//   - content-verification-failed
+	//   - ostree-ref-resolution-failed
+	//   - manifest-generation-failed
+	//   - osbuild-failed: the details name the stage which failed
+	//   - worker-error
+	//   - upload-failed: the details name the target
+	//   - koji-init-failed, koji-upload-failed, koji-import-failed,
+	//     koji-tag-failed
+	JobError *ErrorInfo `json:"job_error,omitempty"`
+
+	// Why the compose failed, if it has.
+	Reason *string          `json:"reason,omitempty"`
+	Status ImageStatusValue `json:"status"`
+}
+
+// ComposeListItem defines model for ComposeListItem.
+type ComposeListItem struct {
+
+	// When the compose was requested
+	CreatedAt time.Time        `json:"created_at"`
+	Id        string           `json:"id"`
+	Status    ImageStatusValue `json:"status"`
+}
+
+// ComposeLog defines model for ComposeLog.
+type ComposeLog struct {
+
+	// Whether the build has finished, after which its log doesn't
+	// grow anymore
+	Finished bool `json:"finished"`
+
+	// The part of the log starting at the requested offset, or the
+	// complete log once the build has finished
+	Log string `json:"log"`
+
+	// The offset to request the rest of the log from
+	Offset int `json:"offset"`
+}
+
+// ComposeManifests defines model for ComposeManifests.
+type ComposeManifests struct {
+
+	// The manifest of each image, or null for images whose manifest
+	// hasn't been generated yet or couldn't be generated
+	Manifests []map[string]interface{} `json:"manifests"`
+}
+
 // ComposeMetadata defines model for ComposeMetadata.
 type ComposeMetadata struct {
 
+	// The files which the worker sent to composer after building the
+	// image, such as the image itself
+	Artifacts *[]Artifact `json:"artifacts,omitempty"`
+
+	// How the image boots: with the legacy boot loader of BIOS or s390x
+	// machines, with UEFI, with both (hybrid), or not at all
+	BootMode            *BootMode                  `json:"boot_mode,omitempty"`
+	ContentVerification *ContentVerificationResult `json:"content_verification,omitempty"`
+
+	// Minor release of the distribution the image was built from, taken
+	// from the version of its release package
+	MinorRelease *string `json:"minor_release,omitempty"`
+
 	// ID (hash) of the built commit
 	OstreeCommit *string `json:"ostree_commit,omitempty"`
 
@@ -101,9 +295,37 @@ type ComposeMetadata struct {
 
 // ComposeRequest defines model for ComposeRequest.
 type ComposeRequest struct {
-	Customizations *Customizations `json:"customizations,omitempty"`
-	Distribution   string          `json:"distribution"`
-	ImageRequests  []ImageRequest  `json:"image_requests"`
+
+	// A URL to which a ComposeEvent is POSTed whenever the status of the
+	// compose changes, from pending until it succeeded or failed. Events
+	// are signed with an HMAC-SHA256 of their body with the secret as
+	// key, which is sent hex-encoded in the X-Composer-Signature header
+	// as 'sha256=<signature>'.
+	Callback *Callback `json:"callback,omitempty"`
+
+	// How the content of the images is verified. By default, only the
+	// packages of repositories with check_gpg are verified. In strict
+	// mode, all repositories must have a gpg_key, which is used to verify
+	// both the signature of the metadata of the repository when
+	// depsolving and the signatures of all of its packages. A package
+	// whose signature cannot be verified fails the compose.
+	ContentVerification *ContentVerification `json:"content_verification,omitempty"`
+	Customizations      *Customizations      `json:"customizations,omitempty"`
+	Distribution        string               `json:"distribution"`
+
+	// Build the images from the Extended Update Support repositories
+	// of the minor release, which must be set. The content/dist paths
+	// of Red Hat CDN repositories are replaced by content/eus.
+	Eus *bool `json:"eus,omitempty"`
+
+	// Images to build, each with its own upload target. All of them
+	// are built from the same customizations.
+	ImageRequests []ImageRequest `json:"image_requests"`
+
+	// Minor release of a RHEL distribution the images are built from.
+	// It replaces the $releasever variable in the URLs of the
+	// repositories, which defaults to the major release.
+	MinorRelease *string `json:"minor_release,omitempty"`
 }
 
 // ComposeResult defines model for ComposeResult.
@@ -114,12 +336,72 @@ type ComposeResult struct {
 // ComposeStatus defines model for ComposeStatus.
 type ComposeStatus struct {
 	ImageStatus ImageStatus `json:"image_status"`
+
+	// Statuses of the images of a compose with more than one image
+	// request, in the order of the requests. image_status then holds
+	// the status of the whole compose, which failed if any of its
+	// images failed.
+	ImageStatuses *[]ImageStatus `json:"image_statuses,omitempty"`
+}
+
+// ContentVerification defines model for ContentVerification.
+type ContentVerification string
+
+// List of ContentVerification
+const (
+	ContentVerification__default ContentVerification = "default"
+	ContentVerification_strict   ContentVerification = "strict"
+)
+
+// ContentVerificationResult defines model for ContentVerificationResult.
+type ContentVerificationResult struct {
+
+	// How the content of the images is verified. By default, only the
+	// packages of repositories with check_gpg are verified. In strict
+	// mode, all repositories must have a gpg_key, which is used to verify
+	// both the signature of the metadata of the repository when
+	// depsolving and the signatures of all of its packages. A package
+	// whose signature cannot be verified fails the compose.
+	Mode ContentVerification `json:"mode"`
+
+	// Number of packages of the image which were installed without
+	// verifying their signatures
+	UnverifiedPackages int `json:"unverified_packages"`
+
+	// Number of packages of the image whose signatures were verified
+	// when they were installed
+	VerifiedPackages int `json:"verified_packages"`
 }
 
 // Customizations defines model for Customizations.
 type Customizations struct {
 	Packages     *[]string     `json:"packages,omitempty"`
 	Subscription *Subscription `json:"subscription,omitempty"`
+	Users        *[]User       `json:"users,omitempty"`
+}
+
+// Distribution defines model for Distribution.
+type Distribution struct {
+	Architectures []string `json:"architectures"`
+	Name          string   `json:"name"`
+}
+
+// DistributionImageTypes defines model for DistributionImageTypes.
+type DistributionImageTypes struct {
+	Architectures []ArchitectureImageTypes `json:"architectures"`
+	Distribution  string                   `json:"distribution"`
+}
+
+// ErrorInfo defines model for ErrorInfo.
+type ErrorInfo struct {
+	Code string `json:"code"`
+
+	// More information about the error, depending on its code
+	Details *interface{} `json:"details,omitempty"`
+
+	// The end of the output of the osbuild stage which failed
+	OsbuildError *string `json:"osbuild_error,omitempty"`
+	Reason       string  `json:"reason"`
 }
 
 // GCPUploadRequestOptions defines model for GCPUploadRequestOptions.
@@ -128,6 +410,10 @@ type GCPUploadRequestOptions struct {
 	// Name of an existing STANDARD Storage class Bucket.
 	Bucket string `json:"bucket"`
 
+	// List of guest OS features to enable on the imported Compute Engine
+	// image. See https://cloud.google.com/compute/docs/images/create-delete-deprecate-private-images#guest-os-features.
+	GuestOsFeatures *[]string `json:"guest_os_features,omitempty"`
+
 	// The name to use for the imported and shared Compute Engine image.
 	// The image name must be unique within the GCP project, which is used
 	// for the OS image upload and import. If not specified a random
@@ -153,6 +439,12 @@ type GCPUploadRequestOptions struct {
 	// If not specified, the imported Compute Engine image is not shared with any
 	// account.
 	ShareWithAccounts *[]string `json:"share_with_accounts,omitempty"`
+
+	// List of IDs of GCP projects to share the imported Compute Engine image
+	// with, for example the service projects of a Shared VPC. Instances in
+	// these projects, including those created by managed instance groups,
+	// can be created from the image.
+	ShareWithProjects *[]string `json:"share_with_projects,omitempty"`
 }
 
 // GCPUploadStatus defines model for GCPUploadStatus.
@@ -161,19 +453,110 @@ type GCPUploadStatus struct {
 	ProjectId string `json:"project_id"`
 }
 
+// GenericS3UploadRequestOptions defines model for GenericS3UploadRequestOptions.
+type GenericS3UploadRequestOptions struct {
+
+	// PEM-encoded certificates to trust instead of the system's ones
+	// when connecting to the endpoint.
+	CaBundle *string `json:"ca_bundle,omitempty"`
+
+	// URL of an S3-compatible object storage service, such as MinIO or
+	// Ceph RGW. Buckets are addressed in the path of this URL.
+	Endpoint string `json:"endpoint"`
+	Region   string `json:"region"`
+
+	// Credentials for uploading to the bucket. Either access keys or a role
+	// is required.
+	S3 AWSUploadRequestOptionsS3 `json:"s3"`
+
+	// Don't verify the endpoint's certificate. Only use this for testing.
+	SkipSslVerification *bool `json:"skip_ssl_verification,omitempty"`
+}
+
+// GenericS3UploadStatus defines model for GenericS3UploadStatus.
+type GenericS3UploadStatus struct {
+	Url string `json:"url"`
+}
+
+// ImageProgress defines model for ImageProgress.
+type ImageProgress struct {
+	Current  int     `json:"current"`
+	Percent  int     `json:"percent"`
+	Pipeline *string `json:"pipeline,omitempty"`
+	Stage    string  `json:"stage"`
+	Total    int     `json:"total"`
+}
+
 // ImageRequest defines model for ImageRequest.
 type ImageRequest struct {
-	Architecture  string        `json:"architecture"`
-	ImageType     string        `json:"image_type"`
-	Ostree        *OSTree       `json:"ostree,omitempty"`
-	Repositories  []Repository  `json:"repositories"`
-	UploadRequest UploadRequest `json:"upload_request"`
+	Architecture string `json:"architecture"`
+
+	// Packages which must not be installed in the image, neither
+	// explicitly nor as dependencies.
+	ExcludePackages *[]string `json:"exclude_packages,omitempty"`
+	ImageType       string    `json:"image_type"`
+	Ostree          *OSTree   `json:"ostree,omitempty"`
+
+	// Repositories which are only used to install the packages of the
+	// image, in addition to the repositories. Unlike those, they are
+	// not used for the build root of the image.
+	PayloadRepositories *[]Repository  `json:"payload_repositories,omitempty"`
+	Repositories        []Repository   `json:"repositories"`
+	UploadRequest       *UploadRequest `json:"upload_request,omitempty"`
+
+	// Targets to upload the image to in addition to upload_request,
+	// which is optional if this is set. The image is built once and
+	// uploaded to each of the targets.
+	UploadRequests *[]UploadRequest `json:"upload_requests,omitempty"`
 }
 
 // ImageStatus defines model for ImageStatus.
 type ImageStatus struct {
+
+	// Why the image failed, if it has. The status of a compose with
+	// more than one image has the error of the first image that
+	// failed. It describes job_error, which should be used instead
+	// by clients which handle errors.
+	Error *string `json:"error,omitempty"`
+
+	// ID of an image of a compose with more than one image request.
+	// The status and metadata of the image can be requested with it
+	// like those of a compose.
+	Id *string `json:"id,omitempty"`
+
+	// Why an image failed. Clients may rely on the code,
+	// which doesn't change between versions, but not on the reason. The
+	// codes of failed images are:
+	//   - canceled
+	//   - dependency-failed: the details are the error of the job the
+	//     image depended on, for example depsolving its packages
+	//   - invalid-job
+	//   - packages-not-found
+	//   - depsolve-failed
+	//   - repository-unavailable
+	//   - content-verification-failed
+	//   - ostree-ref-resolution-failed
+	//   - manifest-generation-failed
+	//   - osbuild-failed: the details name the stage which failed
+	//   - worker-error
+	//   - upload-failed: the details name the target
+	//   - koji-init-failed, koji-upload-failed, koji-import-failed,
+	//     koji-tag-failed
+	JobError *ErrorInfo `json:"job_error,omitempty"`
+
+	// Progress of an image which is being built: the stage osbuild is
+	// running, its position among all stages of the image, and the
+	// percentage of the stages which have finished. Stages which osbuild
+	// takes from its cache are skipped. The progress of an image whose
+	// build failed names the stage which failed.
+	Progress     *ImageProgress   `json:"progress,omitempty"`
 	Status       ImageStatusValue `json:"status"`
 	UploadStatus *UploadStatus    `json:"upload_status,omitempty"`
+
+	// Statuses of the uploads of an image with more than one upload
+	// request: the one of upload_request, if set, followed by those of
+	// upload_requests.
+	UploadStatuses *[]UploadStatus `json:"upload_statuses,omitempty"`
 }
 
 // ImageStatusValue defines model for ImageStatusValue.
@@ -189,30 +572,159 @@ const (
 	ImageStatusValue_uploading   ImageStatusValue = "uploading"
 )
 
+// ImageTypeInfo defines model for ImageTypeInfo.
+type ImageTypeInfo struct {
+
+	// Size of the image in bytes, unless a compose requests a larger one
+	DefaultSize int64  `json:"default_size"`
+	Name        string `json:"name"`
+}
+
+// KojiComposeRequest defines model for KojiComposeRequest.
+type KojiComposeRequest struct {
+	Distribution  string             `json:"distribution"`
+	ImageRequests []KojiImageRequest `json:"image_requests"`
+	Koji          KojiOptions        `json:"koji"`
+	Name          string             `json:"name"`
+	Release       string             `json:"release"`
+	Version       string             `json:"version"`
+}
+
+// KojiComposeStatus defines model for KojiComposeStatus.
+type KojiComposeStatus struct {
+	ImageStatuses []ImageStatus    `json:"image_statuses"`
+	KojiBuildId   *int             `json:"koji_build_id,omitempty"`
+	Status        ImageStatusValue `json:"status"`
+}
+
+// KojiImageRequest defines model for KojiImageRequest.
+type KojiImageRequest struct {
+	Architecture string       `json:"architecture"`
+	ImageType    string       `json:"image_type"`
+	Repositories []Repository `json:"repositories"`
+}
+
+// KojiOptions defines model for KojiOptions.
+type KojiOptions struct {
+	Server string `json:"server"`
+
+	// ID of the Koji task the build belongs to
+	TaskId int `json:"task_id"`
+}
+
+// OCIUploadRequestOptions defines model for OCIUploadRequestOptions.
+type OCIUploadRequestOptions struct {
+
+	// Name of an existing Object Storage bucket the image is uploaded to
+	// before importing it. The uploaded object is deleted afterwards.
+	Bucket string `json:"bucket"`
+
+	// OCID of the compartment the image will be created in.
+	Compartment string `json:"compartment"`
+
+	// Display name of the imported custom image. If not specified a random
+	// 'composer-api-<uuid>' string is used as the image name.
+	ImageName *string `json:"image_name,omitempty"`
+
+	// Object Storage namespace of the tenancy the bucket belongs to.
+	Namespace string `json:"namespace"`
+
+	// The OCI region where the image will be imported to.
+	Region string `json:"region"`
+}
+
+// OCIUploadStatus defines model for OCIUploadStatus.
+type OCIUploadStatus struct {
+
+	// OCID of the imported custom image.
+	ImageId string `json:"image_id"`
+	Region  string `json:"region"`
+}
+
 // OSTree defines model for OSTree.
 type OSTree struct {
-	Ref *string `json:"ref,omitempty"`
-	Url *string `json:"url,omitempty"`
+
+	// Checksum of the parent commit. Mutually exclusive with url, from
+	// which the parent is resolved otherwise.
+	Parent *string `json:"parent,omitempty"`
+	Ref    *string `json:"ref,omitempty"`
+	Url    *string `json:"url,omitempty"`
+}
+
+// PackageBuild defines model for PackageBuild.
+type PackageBuild struct {
+	Arch      string     `json:"arch"`
+	BuildTime *time.Time `json:"build_time,omitempty"`
+	Epoch     int        `json:"epoch"`
+	Name      string     `json:"name"`
+	Release   string     `json:"release"`
+	Version   string     `json:"version"`
+}
+
+// PackageInfo defines model for PackageInfo.
+type PackageInfo struct {
+	Builds []PackageBuild `json:"builds"`
+
+	// The packages installed with the latest build, including itself
+	Dependencies []PackageBuild `json:"dependencies"`
+	Description  string         `json:"description"`
+	Homepage     string         `json:"homepage"`
+	Name         string         `json:"name"`
+	Summary      string         `json:"summary"`
 }
 
 // PackageMetadata defines model for PackageMetadata.
 type PackageMetadata struct {
-	Arch      string  `json:"arch"`
-	Epoch     *string `json:"epoch,omitempty"`
-	Name      string  `json:"name"`
-	Release   string  `json:"release"`
-	Sigmd5    string  `json:"sigmd5"`
-	Signature *string `json:"signature,omitempty"`
-	Type      string  `json:"type"`
-	Version   string  `json:"version"`
+	Arch string `json:"arch"`
+
+	// Checksum of the package which was depsolved for the image, as
+	// listed in its manifest
+	Checksum *string `json:"checksum,omitempty"`
+	Epoch    *string `json:"epoch,omitempty"`
+	Name     string  `json:"name"`
+	Release  string  `json:"release"`
+
+	// URL of the repository the package was depsolved from, its
+	// base URL, metalink, or mirrorlist
+	Repository *string `json:"repository,omitempty"`
+	Sigmd5     string  `json:"sigmd5"`
+	Signature  *string `json:"signature,omitempty"`
+	Type       string  `json:"type"`
+	Version    string  `json:"version"`
+}
+
+// PackageSearchResult defines model for PackageSearchResult.
+type PackageSearchResult struct {
+	Packages []PackageSummary `json:"packages"`
+
+	// Number of matching packages, including the ones beyond the limit
+	Total int `json:"total"`
+}
+
+// PackageSummary defines model for PackageSummary.
+type PackageSummary struct {
+	Name    string `json:"name"`
+	Summary string `json:"summary"`
+
+	// The available versions, as version-release
+	Versions []string `json:"versions"`
 }
 
 // Repository defines model for Repository.
 type Repository struct {
-	Baseurl    *string `json:"baseurl,omitempty"`
+	Baseurl *string `json:"baseurl,omitempty"`
+
+	// Whether to verify the signatures of the packages of the repository
+	CheckGpg *bool `json:"check_gpg,omitempty"`
+
+	// ASCII-armored GPG key used to verify the packages of the repository
+	GpgKey     *string `json:"gpg_key,omitempty"`
 	Metalink   *string `json:"metalink,omitempty"`
 	Mirrorlist *string `json:"mirrorlist,omitempty"`
-	Rhsm       bool    `json:"rhsm"`
+
+	// Whether packages of the repository are exempt from modular filtering
+	ModuleHotfixes *bool `json:"module_hotfixes,omitempty"`
+	Rhsm           bool  `json:"rhsm"`
 }
 
 // Subscription defines model for Subscription.
@@ -232,9 +744,12 @@ type UploadRequest struct {
 
 // UploadStatus defines model for UploadStatus.
 type UploadStatus struct {
-	Options interface{} `json:"options"`
-	Status  string      `json:"status"`
-	Type    UploadTypes `json:"type"`
+
+	// Result of the upload, which is not set until the upload has
+	// succeeded.
+	Options *interface{} `json:"options,omitempty"`
+	Status  string       `json:"status"`
+	Type    UploadTypes  `json:"type"`
 }
 
 // UploadTypes defines model for UploadTypes.
@@ -242,23 +757,88 @@ type UploadTypes string
 
 // List of UploadTypes
 const (
-	UploadTypes_aws    UploadTypes = "aws"
-	UploadTypes_aws_s3 UploadTypes = "aws.s3"
-	UploadTypes_azure  UploadTypes = "azure"
-	UploadTypes_gcp    UploadTypes = "gcp"
+	UploadTypes_aws        UploadTypes = "aws"
+	UploadTypes_aws_s3     UploadTypes = "aws.s3"
+	UploadTypes_azure      UploadTypes = "azure"
+	UploadTypes_gcp        UploadTypes = "gcp"
+	UploadTypes_generic_s3 UploadTypes = "generic.s3"
+	UploadTypes_oci        UploadTypes = "oci"
 )
 
+// User defines model for User.
+type User struct {
+
+	// Day on which the account is disabled, in days since 1970-01-01.
+	ExpireDate *int `json:"expire-date,omitempty"`
+
+	// Expire the password, so that it has to be changed on the first
+	// login.
+	ForcePasswordReset *bool     `json:"force-password-reset,omitempty"`
+	Gid                *int      `json:"gid,omitempty"`
+	Groups             *[]string `json:"groups,omitempty"`
+
+	// SSH public keys, which are added to the authorized keys of the user.
+	Keys *[]string `json:"keys,omitempty"`
+
+	// Disable logging in with a password.
+	Locked *bool  `json:"locked,omitempty"`
+	Name   string `json:"name"`
+	Uid    *int   `json:"uid,omitempty"`
+}
+
 // Version defines model for Version.
 type Version struct {
 	Version string `json:"version"`
 }
 
+// ValidateBlueprintJSONBody defines parameters for ValidateBlueprint.
+type ValidateBlueprintJSONBody ComposeRequest
+
 // ComposeJSONBody defines parameters for Compose.
 type ComposeJSONBody ComposeRequest
 
+// ComposeKojiJSONBody defines parameters for ComposeKoji.
+type ComposeKojiJSONBody KojiComposeRequest
+
+// ComposeCloneJSONBody defines parameters for ComposeClone.
+type ComposeCloneJSONBody UploadRequest
+
+// ComposeLogParams defines parameters for ComposeLog.
+type ComposeLogParams struct {
+
+	// Byte of the log of a running compose to start at
+	Offset *int `json:"offset,omitempty"`
+}
+
+// ComposeSbomParams defines parameters for ComposeSbom.
+type ComposeSbomParams struct {
+
+	// Format of the SBOM
+	Format *string `json:"format,omitempty"`
+}
+
+// SearchPackagesParams defines parameters for SearchPackages.
+type SearchPackagesParams struct {
+
+	// Comma-separated globs which the names of the packages must match
+	Search string `json:"search"`
+
+	// Maximum number of packages to return
+	Limit *int `json:"limit,omitempty"`
+}
+
+// ValidateBlueprintRequestBody defines body for ValidateBlueprint for application/json ContentType.
+type ValidateBlueprintJSONRequestBody ValidateBlueprintJSONBody
+
 // ComposeRequestBody defines body for Compose for application/json ContentType.
 type ComposeJSONRequestBody ComposeJSONBody
 
+// ComposeKojiRequestBody defines body for ComposeKoji for application/json ContentType.
+type ComposeKojiJSONRequestBody ComposeKojiJSONBody
+
+// ComposeCloneRequestBody defines body for ComposeClone for application/json ContentType.
+type ComposeCloneJSONRequestBody ComposeCloneJSONBody
+
 // RequestEditorFn  is the function signature for the RequestEditor callback function
 type RequestEditorFn func(ctx context.Context, req *http.Request) error
 
@@ -330,26 +910,83 @@ func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
 
 // The interface specification for the client above.
 type ClientInterface interface {
+	// ValidateBlueprint request  with any body
+	ValidateBlueprintWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error)
+
+	ValidateBlueprint(ctx context.Context, body ValidateBlueprintJSONRequestBody) (*http.Response, error)
+
+	// CloneStatus request
+	CloneStatus(ctx context.Context, id string) (*http.Response, error)
+
 	// Compose request  with any body
 	ComposeWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error)
 
 	Compose(ctx context.Context, body ComposeJSONRequestBody) (*http.Response, error)
 
+	// ComposeKoji request  with any body
+	ComposeKojiWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error)
+
+	ComposeKoji(ctx context.Context, body ComposeKojiJSONRequestBody) (*http.Response, error)
+
+	// KojiComposeStatus request
+	KojiComposeStatus(ctx context.Context, id string) (*http.Response, error)
+
+	// DeleteCompose request
+	DeleteCompose(ctx context.Context, id string) (*http.Response, error)
+
 	// ComposeStatus request
 	ComposeStatus(ctx context.Context, id string) (*http.Response, error)
 
+	// ComposeClone request  with any body
+	ComposeCloneWithBody(ctx context.Context, id string, contentType string, body io.Reader) (*http.Response, error)
+
+	ComposeClone(ctx context.Context, id string, body ComposeCloneJSONRequestBody) (*http.Response, error)
+
+	// ComposeEvents request
+	ComposeEvents(ctx context.Context, id string) (*http.Response, error)
+
+	// ComposeLog request
+	ComposeLog(ctx context.Context, id string, params *ComposeLogParams) (*http.Response, error)
+
+	// ComposeManifests request
+	ComposeManifests(ctx context.Context, id string) (*http.Response, error)
+
 	// ComposeMetadata request
 	ComposeMetadata(ctx context.Context, id string) (*http.Response, error)
 
+	// ComposeProvenance request
+	ComposeProvenance(ctx context.Context, id string) (*http.Response, error)
+
+	// ComposeSbom request
+	ComposeSbom(ctx context.Context, id string, params *ComposeSbomParams) (*http.Response, error)
+
+	// ListComposes request
+	ListComposes(ctx context.Context) (*http.Response, error)
+
+	// ListDistros request
+	ListDistros(ctx context.Context) (*http.Response, error)
+
+	// SearchPackages request
+	SearchPackages(ctx context.Context, distro string, arch string, params *SearchPackagesParams) (*http.Response, error)
+
+	// GetPackage request
+	GetPackage(ctx context.Context, distro string, arch string, name string) (*http.Response, error)
+
+	// ListDistroImageTypes request
+	ListDistroImageTypes(ctx context.Context, distro string) (*http.Response, error)
+
 	// GetOpenapiJson request
 	GetOpenapiJson(ctx context.Context) (*http.Response, error)
 
+	// SigningKey request
+	SigningKey(ctx context.Context) (*http.Response, error)
+
 	// GetVersion request
 	GetVersion(ctx context.Context) (*http.Response, error)
 }
 
-func (c *Client) ComposeWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error) {
-	req, err := NewComposeRequestWithBody(c.Server, contentType, body)
+func (c *Client) ValidateBlueprintWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := NewValidateBlueprintRequestWithBody(c.Server, contentType, body)
 	if err != nil {
 		return nil, err
 	}
@@ -363,8 +1000,8 @@ func (c *Client) ComposeWithBody(ctx context.Context, contentType string, body i
 	return c.Client.Do(req)
 }
 
-func (c *Client) Compose(ctx context.Context, body ComposeJSONRequestBody) (*http.Response, error) {
-	req, err := NewComposeRequest(c.Server, body)
+func (c *Client) ValidateBlueprint(ctx context.Context, body ValidateBlueprintJSONRequestBody) (*http.Response, error) {
+	req, err := NewValidateBlueprintRequest(c.Server, body)
 	if err != nil {
 		return nil, err
 	}
@@ -378,8 +1015,8 @@ func (c *Client) Compose(ctx context.Context, body ComposeJSONRequestBody) (*htt
 	return c.Client.Do(req)
 }
 
-func (c *Client) ComposeStatus(ctx context.Context, id string) (*http.Response, error) {
-	req, err := NewComposeStatusRequest(c.Server, id)
+func (c *Client) CloneStatus(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewCloneStatusRequest(c.Server, id)
 	if err != nil {
 		return nil, err
 	}
@@ -393,8 +1030,8 @@ func (c *Client) ComposeStatus(ctx context.Context, id string) (*http.Response,
 	return c.Client.Do(req)
 }
 
-func (c *Client) ComposeMetadata(ctx context.Context, id string) (*http.Response, error) {
-	req, err := NewComposeMetadataRequest(c.Server, id)
+func (c *Client) ComposeWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := NewComposeRequestWithBody(c.Server, contentType, body)
 	if err != nil {
 		return nil, err
 	}
@@ -408,8 +1045,8 @@ func (c *Client) ComposeMetadata(ctx context.Context, id string) (*http.Response
 	return c.Client.Do(req)
 }
 
-func (c *Client) GetOpenapiJson(ctx context.Context) (*http.Response, error) {
-	req, err := NewGetOpenapiJsonRequest(c.Server)
+func (c *Client) Compose(ctx context.Context, body ComposeJSONRequestBody) (*http.Response, error) {
+	req, err := NewComposeRequest(c.Server, body)
 	if err != nil {
 		return nil, err
 	}
@@ -423,8 +1060,8 @@ func (c *Client) GetOpenapiJson(ctx context.Context) (*http.Response, error) {
 	return c.Client.Do(req)
 }
 
-func (c *Client) GetVersion(ctx context.Context) (*http.Response, error) {
-	req, err := NewGetVersionRequest(c.Server)
+func (c *Client) ComposeKojiWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := NewComposeKojiRequestWithBody(c.Server, contentType, body)
 	if err != nil {
 		return nil, err
 	}
@@ -438,115 +1075,319 @@ func (c *Client) GetVersion(ctx context.Context) (*http.Response, error) {
 	return c.Client.Do(req)
 }
 
-// NewComposeRequest calls the generic Compose builder with application/json body
-func NewComposeRequest(server string, body ComposeJSONRequestBody) (*http.Request, error) {
-	var bodyReader io.Reader
-	buf, err := json.Marshal(body)
+func (c *Client) ComposeKoji(ctx context.Context, body ComposeKojiJSONRequestBody) (*http.Response, error) {
+	req, err := NewComposeKojiRequest(c.Server, body)
 	if err != nil {
 		return nil, err
 	}
-	bodyReader = bytes.NewReader(buf)
-	return NewComposeRequestWithBody(server, "application/json", bodyReader)
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
 }
 
-// NewComposeRequestWithBody generates requests for Compose with any type of body
-func NewComposeRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
-	var err error
-
-	queryUrl, err := url.Parse(server)
+func (c *Client) KojiComposeStatus(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewKojiComposeStatusRequest(c.Server, id)
 	if err != nil {
 		return nil, err
 	}
-
-	basePath := fmt.Sprintf("/compose")
-	if basePath[0] == '/' {
-		basePath = basePath[1:]
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
 	}
+	return c.Client.Do(req)
+}
 
-	queryUrl, err = queryUrl.Parse(basePath)
+func (c *Client) DeleteCompose(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewDeleteComposeRequest(c.Server, id)
 	if err != nil {
 		return nil, err
 	}
-
-	req, err := http.NewRequest("POST", queryUrl.String(), body)
-	if err != nil {
-		return nil, err
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
 	}
-
-	req.Header.Add("Content-Type", contentType)
-	return req, nil
+	return c.Client.Do(req)
 }
 
-// NewComposeStatusRequest generates requests for ComposeStatus
-func NewComposeStatusRequest(server string, id string) (*http.Request, error) {
-	var err error
-
-	var pathParam0 string
-
-	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+func (c *Client) ComposeStatus(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeStatusRequest(c.Server, id)
 	if err != nil {
 		return nil, err
 	}
-
-	queryUrl, err := url.Parse(server)
-	if err != nil {
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeCloneWithBody(ctx context.Context, id string, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := NewComposeCloneRequestWithBody(c.Server, id, contentType, body)
+	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
 
-	basePath := fmt.Sprintf("/compose/%s", pathParam0)
-	if basePath[0] == '/' {
-		basePath = basePath[1:]
+func (c *Client) ComposeClone(ctx context.Context, id string, body ComposeCloneJSONRequestBody) (*http.Response, error) {
+	req, err := NewComposeCloneRequest(c.Server, id, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
 	}
+	return c.Client.Do(req)
+}
 
-	queryUrl, err = queryUrl.Parse(basePath)
+func (c *Client) ComposeEvents(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeEventsRequest(c.Server, id)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
 
-	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+func (c *Client) ComposeLog(ctx context.Context, id string, params *ComposeLogParams) (*http.Response, error) {
+	req, err := NewComposeLogRequest(c.Server, id, params)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
 
-	return req, nil
+func (c *Client) ComposeManifests(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeManifestsRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
 }
 
-// NewComposeMetadataRequest generates requests for ComposeMetadata
-func NewComposeMetadataRequest(server string, id string) (*http.Request, error) {
-	var err error
+func (c *Client) ComposeMetadata(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeMetadataRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
 
-	var pathParam0 string
+func (c *Client) ComposeProvenance(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeProvenanceRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
 
-	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+func (c *Client) ComposeSbom(ctx context.Context, id string, params *ComposeSbomParams) (*http.Response, error) {
+	req, err := NewComposeSbomRequest(c.Server, id, params)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
 
-	queryUrl, err := url.Parse(server)
+func (c *Client) ListComposes(ctx context.Context) (*http.Response, error) {
+	req, err := NewListComposesRequest(c.Server)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
 
-	basePath := fmt.Sprintf("/compose/%s/metadata", pathParam0)
-	if basePath[0] == '/' {
-		basePath = basePath[1:]
+func (c *Client) ListDistros(ctx context.Context) (*http.Response, error) {
+	req, err := NewListDistrosRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
 	}
+	return c.Client.Do(req)
+}
 
-	queryUrl, err = queryUrl.Parse(basePath)
+func (c *Client) SearchPackages(ctx context.Context, distro string, arch string, params *SearchPackagesParams) (*http.Response, error) {
+	req, err := NewSearchPackagesRequest(c.Server, distro, arch, params)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
 
-	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+func (c *Client) GetPackage(ctx context.Context, distro string, arch string, name string) (*http.Response, error) {
+	req, err := NewGetPackageRequest(c.Server, distro, arch, name)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
 
-	return req, nil
+func (c *Client) ListDistroImageTypes(ctx context.Context, distro string) (*http.Response, error) {
+	req, err := NewListDistroImageTypesRequest(c.Server, distro)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
 }
 
-// NewGetOpenapiJsonRequest generates requests for GetOpenapiJson
-func NewGetOpenapiJsonRequest(server string) (*http.Request, error) {
+func (c *Client) GetOpenapiJson(ctx context.Context) (*http.Response, error) {
+	req, err := NewGetOpenapiJsonRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SigningKey(ctx context.Context) (*http.Response, error) {
+	req, err := NewSigningKeyRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetVersion(ctx context.Context) (*http.Response, error) {
+	req, err := NewGetVersionRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+// NewValidateBlueprintRequest calls the generic ValidateBlueprint builder with application/json body
+func NewValidateBlueprintRequest(server string, body ValidateBlueprintJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewValidateBlueprintRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewValidateBlueprintRequestWithBody generates requests for ValidateBlueprint with any type of body
+func NewValidateBlueprintRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
 	var err error
 
 	queryUrl, err := url.Parse(server)
@@ -554,7 +1395,7 @@ func NewGetOpenapiJsonRequest(server string) (*http.Request, error) {
 		return nil, err
 	}
 
-	basePath := fmt.Sprintf("/openapi.json")
+	basePath := fmt.Sprintf("/blueprints/validate")
 	if basePath[0] == '/' {
 		basePath = basePath[1:]
 	}
@@ -564,24 +1405,32 @@ func NewGetOpenapiJsonRequest(server string) (*http.Request, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	req, err := http.NewRequest("POST", queryUrl.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
+	req.Header.Add("Content-Type", contentType)
 	return req, nil
 }
 
-// NewGetVersionRequest generates requests for GetVersion
-func NewGetVersionRequest(server string) (*http.Request, error) {
+// NewCloneStatusRequest generates requests for CloneStatus
+func NewCloneStatusRequest(server string, id string) (*http.Request, error) {
 	var err error
 
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
 	queryUrl, err := url.Parse(server)
 	if err != nil {
 		return nil, err
 	}
 
-	basePath := fmt.Sprintf("/version")
+	basePath := fmt.Sprintf("/clones/%s", pathParam0)
 	if basePath[0] == '/' {
 		basePath = basePath[1:]
 	}
@@ -599,403 +1448,2559 @@ func NewGetVersionRequest(server string) (*http.Request, error) {
 	return req, nil
 }
 
-// ClientWithResponses builds on ClientInterface to offer response payloads
-type ClientWithResponses struct {
-	ClientInterface
+// NewComposeRequest calls the generic Compose builder with application/json body
+func NewComposeRequest(server string, body ComposeJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewComposeRequestWithBody(server, "application/json", bodyReader)
 }
 
-// NewClientWithResponses creates a new ClientWithResponses, which wraps
-// Client with return type handling
-func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
-	client, err := NewClient(server, opts...)
+// NewComposeRequestWithBody generates requests for Compose with any type of body
+func NewComposeRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
 	if err != nil {
 		return nil, err
 	}
-	return &ClientWithResponses{client}, nil
-}
 
-// WithBaseURL overrides the baseURL.
-func WithBaseURL(baseURL string) ClientOption {
-	return func(c *Client) error {
-		newBaseURL, err := url.Parse(baseURL)
-		if err != nil {
-			return err
-		}
-		c.Server = newBaseURL.String()
-		return nil
+	basePath := fmt.Sprintf("/compose")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryUrl.String(), body)
+	if err != nil {
+		return nil, err
 	}
+
+	req.Header.Add("Content-Type", contentType)
+	return req, nil
 }
 
-// ClientWithResponsesInterface is the interface specification for the client with responses above.
-type ClientWithResponsesInterface interface {
-	// Compose request  with any body
-	ComposeWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ComposeResponse, error)
+// NewComposeKojiRequest calls the generic ComposeKoji builder with application/json body
+func NewComposeKojiRequest(server string, body ComposeKojiJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewComposeKojiRequestWithBody(server, "application/json", bodyReader)
+}
 
-	ComposeWithResponse(ctx context.Context, body ComposeJSONRequestBody) (*ComposeResponse, error)
+// NewComposeKojiRequestWithBody generates requests for ComposeKoji with any type of body
+func NewComposeKojiRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
 
-	// ComposeStatus request
-	ComposeStatusWithResponse(ctx context.Context, id string) (*ComposeStatusResponse, error)
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
 
-	// ComposeMetadata request
-	ComposeMetadataWithResponse(ctx context.Context, id string) (*ComposeMetadataResponse, error)
+	basePath := fmt.Sprintf("/compose/koji")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
 
-	// GetOpenapiJson request
-	GetOpenapiJsonWithResponse(ctx context.Context) (*GetOpenapiJsonResponse, error)
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
 
-	// GetVersion request
-	GetVersionWithResponse(ctx context.Context) (*GetVersionResponse, error)
-}
+	req, err := http.NewRequest("POST", queryUrl.String(), body)
+	if err != nil {
+		return nil, err
+	}
 
-type ComposeResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON201      *ComposeResult
+	req.Header.Add("Content-Type", contentType)
+	return req, nil
 }
 
-// Status returns HTTPResponse.Status
-func (r ComposeResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+// NewKojiComposeStatusRequest generates requests for KojiComposeStatus
+func NewKojiComposeStatusRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r ComposeResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type ComposeStatusResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *ComposeStatus
-}
+	basePath := fmt.Sprintf("/compose/koji/%s", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
 
-// Status returns HTTPResponse.Status
-func (r ComposeStatusResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r ComposeStatusResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type ComposeMetadataResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *ComposeMetadata
+	return req, nil
 }
 
-// Status returns HTTPResponse.Status
-func (r ComposeMetadataResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+// NewDeleteComposeRequest generates requests for DeleteCompose
+func NewDeleteComposeRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r ComposeMetadataResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type GetOpenapiJsonResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-}
+	basePath := fmt.Sprintf("/compose/%s", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
 
-// Status returns HTTPResponse.Status
-func (r GetOpenapiJsonResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r GetOpenapiJsonResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	req, err := http.NewRequest("DELETE", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type GetVersionResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *Version
+	return req, nil
 }
 
-// Status returns HTTPResponse.Status
-func (r GetVersionResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+// NewComposeStatusRequest generates requests for ComposeStatus
+func NewComposeStatusRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r GetVersionResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-// ComposeWithBodyWithResponse request with arbitrary body returning *ComposeResponse
-func (c *ClientWithResponses) ComposeWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ComposeResponse, error) {
-	rsp, err := c.ComposeWithBody(ctx, contentType, body)
+	basePath := fmt.Sprintf("/compose/%s", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
 	if err != nil {
 		return nil, err
 	}
-	return ParseComposeResponse(rsp)
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
 }
 
-func (c *ClientWithResponses) ComposeWithResponse(ctx context.Context, body ComposeJSONRequestBody) (*ComposeResponse, error) {
-	rsp, err := c.Compose(ctx, body)
+// NewComposeCloneRequest calls the generic ComposeClone builder with application/json body
+func NewComposeCloneRequest(server string, id string, body ComposeCloneJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
-	return ParseComposeResponse(rsp)
+	bodyReader = bytes.NewReader(buf)
+	return NewComposeCloneRequestWithBody(server, id, "application/json", bodyReader)
 }
 
-// ComposeStatusWithResponse request returning *ComposeStatusResponse
-func (c *ClientWithResponses) ComposeStatusWithResponse(ctx context.Context, id string) (*ComposeStatusResponse, error) {
-	rsp, err := c.ComposeStatus(ctx, id)
+// NewComposeCloneRequestWithBody generates requests for ComposeClone with any type of body
+func NewComposeCloneRequestWithBody(server string, id string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
 	if err != nil {
 		return nil, err
 	}
-	return ParseComposeStatusResponse(rsp)
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/clone", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryUrl.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+	return req, nil
 }
 
-// ComposeMetadataWithResponse request returning *ComposeMetadataResponse
-func (c *ClientWithResponses) ComposeMetadataWithResponse(ctx context.Context, id string) (*ComposeMetadataResponse, error) {
-	rsp, err := c.ComposeMetadata(ctx, id)
+// NewComposeEventsRequest generates requests for ComposeEvents
+func NewComposeEventsRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/events", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeLogRequest generates requests for ComposeLog
+func NewComposeLogRequest(server string, id string, params *ComposeLogParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/log", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryUrl.Query()
+
+	if params.Offset != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "offset", *params.Offset); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryUrl.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeManifestsRequest generates requests for ComposeManifests
+func NewComposeManifestsRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/manifests", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeMetadataRequest generates requests for ComposeMetadata
+func NewComposeMetadataRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/metadata", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeProvenanceRequest generates requests for ComposeProvenance
+func NewComposeProvenanceRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/provenance", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeSbomRequest generates requests for ComposeSbom
+func NewComposeSbomRequest(server string, id string, params *ComposeSbomParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/sbom", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryUrl.Query()
+
+	if params.Format != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "format", *params.Format); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryUrl.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListComposesRequest generates requests for ListComposes
+func NewListComposesRequest(server string) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/composes")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListDistrosRequest generates requests for ListDistros
+func NewListDistrosRequest(server string) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/distros")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewSearchPackagesRequest generates requests for SearchPackages
+func NewSearchPackagesRequest(server string, distro string, arch string, params *SearchPackagesParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "distro", distro)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParam("simple", false, "arch", arch)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/distros/%s/architectures/%s/packages", pathParam0, pathParam1)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryUrl.Query()
+
+	if queryFrag, err := runtime.StyleParam("form", true, "search", params.Search); err != nil {
+		return nil, err
+	} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+		return nil, err
+	} else {
+		for k, v := range parsed {
+			for _, v2 := range v {
+				queryValues.Add(k, v2)
+			}
+		}
+	}
+
+	if params.Limit != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "limit", *params.Limit); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryUrl.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetPackageRequest generates requests for GetPackage
+func NewGetPackageRequest(server string, distro string, arch string, name string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "distro", distro)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParam("simple", false, "arch", arch)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam2 string
+
+	pathParam2, err = runtime.StyleParam("simple", false, "name", name)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/distros/%s/architectures/%s/packages/%s", pathParam0, pathParam1, pathParam2)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListDistroImageTypesRequest generates requests for ListDistroImageTypes
+func NewListDistroImageTypesRequest(server string, distro string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "distro", distro)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/distros/%s/image-types", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetOpenapiJsonRequest generates requests for GetOpenapiJson
+func NewGetOpenapiJsonRequest(server string) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/openapi.json")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewSigningKeyRequest generates requests for SigningKey
+func NewSigningKeyRequest(server string) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/signing-key")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetVersionRequest generates requests for GetVersion
+func NewGetVersionRequest(server string) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/version")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// ValidateBlueprint request  with any body
+	ValidateBlueprintWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ValidateBlueprintResponse, error)
+
+	ValidateBlueprintWithResponse(ctx context.Context, body ValidateBlueprintJSONRequestBody) (*ValidateBlueprintResponse, error)
+
+	// CloneStatus request
+	CloneStatusWithResponse(ctx context.Context, id string) (*CloneStatusResponse, error)
+
+	// Compose request  with any body
+	ComposeWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ComposeResponse, error)
+
+	ComposeWithResponse(ctx context.Context, body ComposeJSONRequestBody) (*ComposeResponse, error)
+
+	// ComposeKoji request  with any body
+	ComposeKojiWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ComposeKojiResponse, error)
+
+	ComposeKojiWithResponse(ctx context.Context, body ComposeKojiJSONRequestBody) (*ComposeKojiResponse, error)
+
+	// KojiComposeStatus request
+	KojiComposeStatusWithResponse(ctx context.Context, id string) (*KojiComposeStatusResponse, error)
+
+	// DeleteCompose request
+	DeleteComposeWithResponse(ctx context.Context, id string) (*DeleteComposeResponse, error)
+
+	// ComposeStatus request
+	ComposeStatusWithResponse(ctx context.Context, id string) (*ComposeStatusResponse, error)
+
+	// ComposeClone request  with any body
+	ComposeCloneWithBodyWithResponse(ctx context.Context, id string, contentType string, body io.Reader) (*ComposeCloneResponse, error)
+
+	ComposeCloneWithResponse(ctx context.Context, id string, body ComposeCloneJSONRequestBody) (*ComposeCloneResponse, error)
+
+	// ComposeEvents request
+	ComposeEventsWithResponse(ctx context.Context, id string) (*ComposeEventsResponse, error)
+
+	// ComposeLog request
+	ComposeLogWithResponse(ctx context.Context, id string, params *ComposeLogParams) (*ComposeLogResponse, error)
+
+	// ComposeManifests request
+	ComposeManifestsWithResponse(ctx context.Context, id string) (*ComposeManifestsResponse, error)
+
+	// ComposeMetadata request
+	ComposeMetadataWithResponse(ctx context.Context, id string) (*ComposeMetadataResponse, error)
+
+	// ComposeProvenance request
+	ComposeProvenanceWithResponse(ctx context.Context, id string) (*ComposeProvenanceResponse, error)
+
+	// ComposeSbom request
+	ComposeSbomWithResponse(ctx context.Context, id string, params *ComposeSbomParams) (*ComposeSbomResponse, error)
+
+	// ListComposes request
+	ListComposesWithResponse(ctx context.Context) (*ListComposesResponse, error)
+
+	// ListDistros request
+	ListDistrosWithResponse(ctx context.Context) (*ListDistrosResponse, error)
+
+	// SearchPackages request
+	SearchPackagesWithResponse(ctx context.Context, distro string, arch string, params *SearchPackagesParams) (*SearchPackagesResponse, error)
+
+	// GetPackage request
+	GetPackageWithResponse(ctx context.Context, distro string, arch string, name string) (*GetPackageResponse, error)
+
+	// ListDistroImageTypes request
+	ListDistroImageTypesWithResponse(ctx context.Context, distro string) (*ListDistroImageTypesResponse, error)
+
+	// GetOpenapiJson request
+	GetOpenapiJsonWithResponse(ctx context.Context) (*GetOpenapiJsonResponse, error)
+
+	// SigningKey request
+	SigningKeyWithResponse(ctx context.Context) (*SigningKeyResponse, error)
+
+	// GetVersion request
+	GetVersionWithResponse(ctx context.Context) (*GetVersionResponse, error)
+}
+
+type ValidateBlueprintResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *BlueprintValidation
+}
+
+// Status returns HTTPResponse.Status
+func (r ValidateBlueprintResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ValidateBlueprintResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CloneStatusResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *UploadStatus
+}
+
+// Status returns HTTPResponse.Status
+func (r CloneStatusResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CloneStatusResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *ComposeResult
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeKojiResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *ComposeResult
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeKojiResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeKojiResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type KojiComposeStatusResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *KojiComposeStatus
+}
+
+// Status returns HTTPResponse.Status
+func (r KojiComposeStatusResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r KojiComposeStatusResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteComposeResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteComposeResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteComposeResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeStatusResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ComposeStatus
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeStatusResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeStatusResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeCloneResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *CloneResult
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeCloneResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeCloneResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeEventsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeEventsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeEventsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeLogResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ComposeLog
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeLogResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeLogResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeManifestsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ComposeManifests
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeManifestsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeManifestsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeMetadataResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ComposeMetadata
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeMetadataResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeMetadataResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeProvenanceResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *map[string]interface{}
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeProvenanceResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeProvenanceResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeSbomResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeSbomResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeSbomResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListComposesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]ComposeListItem
+}
+
+// Status returns HTTPResponse.Status
+func (r ListComposesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListComposesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListDistrosResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Distribution
+}
+
+// Status returns HTTPResponse.Status
+func (r ListDistrosResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListDistrosResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SearchPackagesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *PackageSearchResult
+}
+
+// Status returns HTTPResponse.Status
+func (r SearchPackagesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SearchPackagesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetPackageResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *PackageInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r GetPackageResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetPackageResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListDistroImageTypesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *DistributionImageTypes
+}
+
+// Status returns HTTPResponse.Status
+func (r ListDistroImageTypesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListDistroImageTypesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetOpenapiJsonResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r GetOpenapiJsonResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetOpenapiJsonResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SigningKeyResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r SigningKeyResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SigningKeyResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetVersionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Version
+}
+
+// Status returns HTTPResponse.Status
+func (r GetVersionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetVersionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// ValidateBlueprintWithBodyWithResponse request with arbitrary body returning *ValidateBlueprintResponse
+func (c *ClientWithResponses) ValidateBlueprintWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ValidateBlueprintResponse, error) {
+	rsp, err := c.ValidateBlueprintWithBody(ctx, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseValidateBlueprintResponse(rsp)
+}
+
+func (c *ClientWithResponses) ValidateBlueprintWithResponse(ctx context.Context, body ValidateBlueprintJSONRequestBody) (*ValidateBlueprintResponse, error) {
+	rsp, err := c.ValidateBlueprint(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseValidateBlueprintResponse(rsp)
+}
+
+// CloneStatusWithResponse request returning *CloneStatusResponse
+func (c *ClientWithResponses) CloneStatusWithResponse(ctx context.Context, id string) (*CloneStatusResponse, error) {
+	rsp, err := c.CloneStatus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCloneStatusResponse(rsp)
+}
+
+// ComposeWithBodyWithResponse request with arbitrary body returning *ComposeResponse
+func (c *ClientWithResponses) ComposeWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ComposeResponse, error) {
+	rsp, err := c.ComposeWithBody(ctx, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeResponse(rsp)
+}
+
+func (c *ClientWithResponses) ComposeWithResponse(ctx context.Context, body ComposeJSONRequestBody) (*ComposeResponse, error) {
+	rsp, err := c.Compose(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeResponse(rsp)
+}
+
+// ComposeKojiWithBodyWithResponse request with arbitrary body returning *ComposeKojiResponse
+func (c *ClientWithResponses) ComposeKojiWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ComposeKojiResponse, error) {
+	rsp, err := c.ComposeKojiWithBody(ctx, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeKojiResponse(rsp)
+}
+
+func (c *ClientWithResponses) ComposeKojiWithResponse(ctx context.Context, body ComposeKojiJSONRequestBody) (*ComposeKojiResponse, error) {
+	rsp, err := c.ComposeKoji(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeKojiResponse(rsp)
+}
+
+// KojiComposeStatusWithResponse request returning *KojiComposeStatusResponse
+func (c *ClientWithResponses) KojiComposeStatusWithResponse(ctx context.Context, id string) (*KojiComposeStatusResponse, error) {
+	rsp, err := c.KojiComposeStatus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKojiComposeStatusResponse(rsp)
+}
+
+// DeleteComposeWithResponse request returning *DeleteComposeResponse
+func (c *ClientWithResponses) DeleteComposeWithResponse(ctx context.Context, id string) (*DeleteComposeResponse, error) {
+	rsp, err := c.DeleteCompose(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteComposeResponse(rsp)
+}
+
+// ComposeStatusWithResponse request returning *ComposeStatusResponse
+func (c *ClientWithResponses) ComposeStatusWithResponse(ctx context.Context, id string) (*ComposeStatusResponse, error) {
+	rsp, err := c.ComposeStatus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeStatusResponse(rsp)
+}
+
+// ComposeCloneWithBodyWithResponse request with arbitrary body returning *ComposeCloneResponse
+func (c *ClientWithResponses) ComposeCloneWithBodyWithResponse(ctx context.Context, id string, contentType string, body io.Reader) (*ComposeCloneResponse, error) {
+	rsp, err := c.ComposeCloneWithBody(ctx, id, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeCloneResponse(rsp)
+}
+
+func (c *ClientWithResponses) ComposeCloneWithResponse(ctx context.Context, id string, body ComposeCloneJSONRequestBody) (*ComposeCloneResponse, error) {
+	rsp, err := c.ComposeClone(ctx, id, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeCloneResponse(rsp)
+}
+
+// ComposeEventsWithResponse request returning *ComposeEventsResponse
+func (c *ClientWithResponses) ComposeEventsWithResponse(ctx context.Context, id string) (*ComposeEventsResponse, error) {
+	rsp, err := c.ComposeEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeEventsResponse(rsp)
+}
+
+// ComposeLogWithResponse request returning *ComposeLogResponse
+func (c *ClientWithResponses) ComposeLogWithResponse(ctx context.Context, id string, params *ComposeLogParams) (*ComposeLogResponse, error) {
+	rsp, err := c.ComposeLog(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeLogResponse(rsp)
+}
+
+// ComposeManifestsWithResponse request returning *ComposeManifestsResponse
+func (c *ClientWithResponses) ComposeManifestsWithResponse(ctx context.Context, id string) (*ComposeManifestsResponse, error) {
+	rsp, err := c.ComposeManifests(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeManifestsResponse(rsp)
+}
+
+// ComposeMetadataWithResponse request returning *ComposeMetadataResponse
+func (c *ClientWithResponses) ComposeMetadataWithResponse(ctx context.Context, id string) (*ComposeMetadataResponse, error) {
+	rsp, err := c.ComposeMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeMetadataResponse(rsp)
+}
+
+// ComposeProvenanceWithResponse request returning *ComposeProvenanceResponse
+func (c *ClientWithResponses) ComposeProvenanceWithResponse(ctx context.Context, id string) (*ComposeProvenanceResponse, error) {
+	rsp, err := c.ComposeProvenance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeProvenanceResponse(rsp)
+}
+
+// ComposeSbomWithResponse request returning *ComposeSbomResponse
+func (c *ClientWithResponses) ComposeSbomWithResponse(ctx context.Context, id string, params *ComposeSbomParams) (*ComposeSbomResponse, error) {
+	rsp, err := c.ComposeSbom(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeSbomResponse(rsp)
+}
+
+// ListComposesWithResponse request returning *ListComposesResponse
+func (c *ClientWithResponses) ListComposesWithResponse(ctx context.Context) (*ListComposesResponse, error) {
+	rsp, err := c.ListComposes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListComposesResponse(rsp)
+}
+
+// ListDistrosWithResponse request returning *ListDistrosResponse
+func (c *ClientWithResponses) ListDistrosWithResponse(ctx context.Context) (*ListDistrosResponse, error) {
+	rsp, err := c.ListDistros(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListDistrosResponse(rsp)
+}
+
+// SearchPackagesWithResponse request returning *SearchPackagesResponse
+func (c *ClientWithResponses) SearchPackagesWithResponse(ctx context.Context, distro string, arch string, params *SearchPackagesParams) (*SearchPackagesResponse, error) {
+	rsp, err := c.SearchPackages(ctx, distro, arch, params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSearchPackagesResponse(rsp)
+}
+
+// GetPackageWithResponse request returning *GetPackageResponse
+func (c *ClientWithResponses) GetPackageWithResponse(ctx context.Context, distro string, arch string, name string) (*GetPackageResponse, error) {
+	rsp, err := c.GetPackage(ctx, distro, arch, name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetPackageResponse(rsp)
+}
+
+// ListDistroImageTypesWithResponse request returning *ListDistroImageTypesResponse
+func (c *ClientWithResponses) ListDistroImageTypesWithResponse(ctx context.Context, distro string) (*ListDistroImageTypesResponse, error) {
+	rsp, err := c.ListDistroImageTypes(ctx, distro)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListDistroImageTypesResponse(rsp)
+}
+
+// GetOpenapiJsonWithResponse request returning *GetOpenapiJsonResponse
+func (c *ClientWithResponses) GetOpenapiJsonWithResponse(ctx context.Context) (*GetOpenapiJsonResponse, error) {
+	rsp, err := c.GetOpenapiJson(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetOpenapiJsonResponse(rsp)
+}
+
+// SigningKeyWithResponse request returning *SigningKeyResponse
+func (c *ClientWithResponses) SigningKeyWithResponse(ctx context.Context) (*SigningKeyResponse, error) {
+	rsp, err := c.SigningKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSigningKeyResponse(rsp)
+}
+
+// GetVersionWithResponse request returning *GetVersionResponse
+func (c *ClientWithResponses) GetVersionWithResponse(ctx context.Context) (*GetVersionResponse, error) {
+	rsp, err := c.GetVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetVersionResponse(rsp)
+}
+
+// ParseValidateBlueprintResponse parses an HTTP response from a ValidateBlueprintWithResponse call
+func ParseValidateBlueprintResponse(rsp *http.Response) (*ValidateBlueprintResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ValidateBlueprintResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest BlueprintValidation
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseCloneStatusResponse parses an HTTP response from a CloneStatusWithResponse call
+func ParseCloneStatusResponse(rsp *http.Response) (*CloneStatusResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CloneStatusResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest UploadStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeResponse parses an HTTP response from a ComposeWithResponse call
+func ParseComposeResponse(rsp *http.Response) (*ComposeResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest ComposeResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeKojiResponse parses an HTTP response from a ComposeKojiWithResponse call
+func ParseComposeKojiResponse(rsp *http.Response) (*ComposeKojiResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeKojiResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest ComposeResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseKojiComposeStatusResponse parses an HTTP response from a KojiComposeStatusWithResponse call
+func ParseKojiComposeStatusResponse(rsp *http.Response) (*KojiComposeStatusResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &KojiComposeStatusResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest KojiComposeStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteComposeResponse parses an HTTP response from a DeleteComposeWithResponse call
+func ParseDeleteComposeResponse(rsp *http.Response) (*DeleteComposeResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteComposeResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	}
+
+	return response, nil
+}
+
+// ParseComposeStatusResponse parses an HTTP response from a ComposeStatusWithResponse call
+func ParseComposeStatusResponse(rsp *http.Response) (*ComposeStatusResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeStatusResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ComposeStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeCloneResponse parses an HTTP response from a ComposeCloneWithResponse call
+func ParseComposeCloneResponse(rsp *http.Response) (*ComposeCloneResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeCloneResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest CloneResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeEventsResponse parses an HTTP response from a ComposeEventsWithResponse call
+func ParseComposeEventsResponse(rsp *http.Response) (*ComposeEventsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeEventsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	}
+
+	return response, nil
+}
+
+// ParseComposeLogResponse parses an HTTP response from a ComposeLogWithResponse call
+func ParseComposeLogResponse(rsp *http.Response) (*ComposeLogResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeLogResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ComposeLog
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeManifestsResponse parses an HTTP response from a ComposeManifestsWithResponse call
+func ParseComposeManifestsResponse(rsp *http.Response) (*ComposeManifestsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeManifestsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ComposeManifests
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeMetadataResponse parses an HTTP response from a ComposeMetadataWithResponse call
+func ParseComposeMetadataResponse(rsp *http.Response) (*ComposeMetadataResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeMetadataResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ComposeMetadata
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeProvenanceResponse parses an HTTP response from a ComposeProvenanceWithResponse call
+func ParseComposeProvenanceResponse(rsp *http.Response) (*ComposeProvenanceResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeProvenanceResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest map[string]interface{}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeSbomResponse parses an HTTP response from a ComposeSbomWithResponse call
+func ParseComposeSbomResponse(rsp *http.Response) (*ComposeSbomResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeSbomResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	}
+
+	return response, nil
+}
+
+// ParseListComposesResponse parses an HTTP response from a ListComposesWithResponse call
+func ParseListComposesResponse(rsp *http.Response) (*ListComposesResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListComposesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []ComposeListItem
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListDistrosResponse parses an HTTP response from a ListDistrosWithResponse call
+func ParseListDistrosResponse(rsp *http.Response) (*ListDistrosResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListDistrosResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Distribution
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseSearchPackagesResponse parses an HTTP response from a SearchPackagesWithResponse call
+func ParseSearchPackagesResponse(rsp *http.Response) (*SearchPackagesResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SearchPackagesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest PackageSearchResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetPackageResponse parses an HTTP response from a GetPackageWithResponse call
+func ParseGetPackageResponse(rsp *http.Response) (*GetPackageResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetPackageResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest PackageInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListDistroImageTypesResponse parses an HTTP response from a ListDistroImageTypesWithResponse call
+func ParseListDistroImageTypesResponse(rsp *http.Response) (*ListDistroImageTypesResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListDistroImageTypesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest DistributionImageTypes
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetOpenapiJsonResponse parses an HTTP response from a GetOpenapiJsonWithResponse call
+func ParseGetOpenapiJsonResponse(rsp *http.Response) (*GetOpenapiJsonResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetOpenapiJsonResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	}
+
+	return response, nil
+}
+
+// ParseSigningKeyResponse parses an HTTP response from a SigningKeyWithResponse call
+func ParseSigningKeyResponse(rsp *http.Response) (*SigningKeyResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SigningKeyResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	}
+
+	return response, nil
+}
+
+// ParseGetVersionResponse parses an HTTP response from a GetVersionWithResponse call
+func ParseGetVersionResponse(rsp *http.Response) (*GetVersionResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetVersionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Version
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Validate a compose request
+	// (POST /blueprints/validate)
+	ValidateBlueprint(w http.ResponseWriter, r *http.Request)
+	// The status of a clone
+	// (GET /clones/{id})
+	CloneStatus(w http.ResponseWriter, r *http.Request, id string)
+	// Create compose
+	// (POST /compose)
+	Compose(w http.ResponseWriter, r *http.Request)
+	// Create a Koji build
+	// (POST /compose/koji)
+	ComposeKoji(w http.ResponseWriter, r *http.Request)
+	// The status of a Koji build
+	// (GET /compose/koji/{id})
+	KojiComposeStatus(w http.ResponseWriter, r *http.Request, id string)
+	// Delete the images of a compose
+	// (DELETE /compose/{id})
+	DeleteCompose(w http.ResponseWriter, r *http.Request, id string)
+	// The status of a compose
+	// (GET /compose/{id})
+	ComposeStatus(w http.ResponseWriter, r *http.Request, id string)
+	// Upload the image of a compose to another target
+	// (POST /compose/{id}/clone)
+	ComposeClone(w http.ResponseWriter, r *http.Request, id string)
+	// Stream the status of a compose
+	// (GET /compose/{id}/events)
+	ComposeEvents(w http.ResponseWriter, r *http.Request, id string)
+	// Get the build log of a compose
+	// (GET /compose/{id}/log)
+	ComposeLog(w http.ResponseWriter, r *http.Request, id string, params ComposeLogParams)
+	// Get the manifests of a compose
+	// (GET /compose/{id}/manifests)
+	ComposeManifests(w http.ResponseWriter, r *http.Request, id string)
+	// Get the metadata for a compose.
+	// (GET /compose/{id}/metadata)
+	ComposeMetadata(w http.ResponseWriter, r *http.Request, id string)
+	// Get the provenance of a compose
+	// (GET /compose/{id}/provenance)
+	ComposeProvenance(w http.ResponseWriter, r *http.Request, id string)
+	// Get the software bill of materials of a compose
+	// (GET /compose/{id}/sbom)
+	ComposeSbom(w http.ResponseWriter, r *http.Request, id string, params ComposeSbomParams)
+	// List the composes of the organization
+	// (GET /composes)
+	ListComposes(w http.ResponseWriter, r *http.Request)
+	// List the supported distributions
+	// (GET /distros)
+	ListDistros(w http.ResponseWriter, r *http.Request)
+	// Search the packages of a distribution
+	// (GET /distros/{distro}/architectures/{arch}/packages)
+	SearchPackages(w http.ResponseWriter, r *http.Request, distro string, arch string, params SearchPackagesParams)
+	// Get information about a package of a distribution
+	// (GET /distros/{distro}/architectures/{arch}/packages/{name})
+	GetPackage(w http.ResponseWriter, r *http.Request, distro string, arch string, name string)
+	// List the image types of a distribution
+	// (GET /distros/{distro}/image-types)
+	ListDistroImageTypes(w http.ResponseWriter, r *http.Request, distro string)
+	// get the openapi json specification
+	// (GET /openapi.json)
+	GetOpenapiJson(w http.ResponseWriter, r *http.Request)
+	// Get the public key of the signing key
+	// (GET /signing-key)
+	SigningKey(w http.ResponseWriter, r *http.Request)
+	// get the service version
+	// (GET /version)
+	GetVersion(w http.ResponseWriter, r *http.Request)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
+}
+
+// ValidateBlueprint operation middleware
+func (siw *ServerInterfaceWrapper) ValidateBlueprint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.ValidateBlueprint(w, r.WithContext(ctx))
+}
+
+// CloneStatus operation middleware
+func (siw *ServerInterfaceWrapper) CloneStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.CloneStatus(w, r.WithContext(ctx), id)
+}
+
+// Compose operation middleware
+func (siw *ServerInterfaceWrapper) Compose(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.Compose(w, r.WithContext(ctx))
+}
+
+// ComposeKoji operation middleware
+func (siw *ServerInterfaceWrapper) ComposeKoji(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.ComposeKoji(w, r.WithContext(ctx))
+}
+
+// KojiComposeStatus operation middleware
+func (siw *ServerInterfaceWrapper) KojiComposeStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.KojiComposeStatus(w, r.WithContext(ctx), id)
+}
+
+// DeleteCompose operation middleware
+func (siw *ServerInterfaceWrapper) DeleteCompose(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.DeleteCompose(w, r.WithContext(ctx), id)
+}
+
+// ComposeStatus operation middleware
+func (siw *ServerInterfaceWrapper) ComposeStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeStatus(w, r.WithContext(ctx), id)
+}
+
+// ComposeClone operation middleware
+func (siw *ServerInterfaceWrapper) ComposeClone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeClone(w, r.WithContext(ctx), id)
+}
+
+// ComposeEvents operation middleware
+func (siw *ServerInterfaceWrapper) ComposeEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeEvents(w, r.WithContext(ctx), id)
+}
+
+// ComposeLog operation middleware
+func (siw *ServerInterfaceWrapper) ComposeLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ComposeLogParams
+
+	// ------------- Optional query parameter "offset" -------------
+	if paramValue := r.URL.Query().Get("offset"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
 	if err != nil {
-		return nil, err
+		http.Error(w, fmt.Sprintf("Invalid format for parameter offset: %s", err), http.StatusBadRequest)
+		return
 	}
-	return ParseComposeMetadataResponse(rsp)
+
+	siw.Handler.ComposeLog(w, r.WithContext(ctx), id, params)
 }
 
-// GetOpenapiJsonWithResponse request returning *GetOpenapiJsonResponse
-func (c *ClientWithResponses) GetOpenapiJsonWithResponse(ctx context.Context) (*GetOpenapiJsonResponse, error) {
-	rsp, err := c.GetOpenapiJson(ctx)
+// ComposeManifests operation middleware
+func (siw *ServerInterfaceWrapper) ComposeManifests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
 	if err != nil {
-		return nil, err
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
 	}
-	return ParseGetOpenapiJsonResponse(rsp)
+
+	siw.Handler.ComposeManifests(w, r.WithContext(ctx), id)
 }
 
-// GetVersionWithResponse request returning *GetVersionResponse
-func (c *ClientWithResponses) GetVersionWithResponse(ctx context.Context) (*GetVersionResponse, error) {
-	rsp, err := c.GetVersion(ctx)
+// ComposeMetadata operation middleware
+func (siw *ServerInterfaceWrapper) ComposeMetadata(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
 	if err != nil {
-		return nil, err
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
 	}
-	return ParseGetVersionResponse(rsp)
+
+	siw.Handler.ComposeMetadata(w, r.WithContext(ctx), id)
 }
 
-// ParseComposeResponse parses an HTTP response from a ComposeWithResponse call
-func ParseComposeResponse(rsp *http.Response) (*ComposeResponse, error) {
-	bodyBytes, err := ioutil.ReadAll(rsp.Body)
-	defer rsp.Body.Close()
-	if err != nil {
-		return nil, err
-	}
+// ComposeProvenance operation middleware
+func (siw *ServerInterfaceWrapper) ComposeProvenance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	response := &ComposeResponse{
-		Body:         bodyBytes,
-		HTTPResponse: rsp,
-	}
+	var err error
 
-	switch {
-	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
-		var dest ComposeResult
-		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
-			return nil, err
-		}
-		response.JSON201 = &dest
+	// ------------- Path parameter "id" -------------
+	var id string
 
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	return response, nil
+	siw.Handler.ComposeProvenance(w, r.WithContext(ctx), id)
 }
 
-// ParseComposeStatusResponse parses an HTTP response from a ComposeStatusWithResponse call
-func ParseComposeStatusResponse(rsp *http.Response) (*ComposeStatusResponse, error) {
-	bodyBytes, err := ioutil.ReadAll(rsp.Body)
-	defer rsp.Body.Close()
+// ComposeSbom operation middleware
+func (siw *ServerInterfaceWrapper) ComposeSbom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
 	if err != nil {
-		return nil, err
+		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	response := &ComposeStatusResponse{
-		Body:         bodyBytes,
-		HTTPResponse: rsp,
-	}
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ComposeSbomParams
 
-	switch {
-	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
-		var dest ComposeStatus
-		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
-			return nil, err
-		}
-		response.JSON200 = &dest
+	// ------------- Optional query parameter "format" -------------
+	if paramValue := r.URL.Query().Get("format"); paramValue != "" {
 
 	}
 
-	return response, nil
-}
-
-// ParseComposeMetadataResponse parses an HTTP response from a ComposeMetadataWithResponse call
-func ParseComposeMetadataResponse(rsp *http.Response) (*ComposeMetadataResponse, error) {
-	bodyBytes, err := ioutil.ReadAll(rsp.Body)
-	defer rsp.Body.Close()
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
 	if err != nil {
-		return nil, err
+		http.Error(w, fmt.Sprintf("Invalid format for parameter format: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	response := &ComposeMetadataResponse{
-		Body:         bodyBytes,
-		HTTPResponse: rsp,
-	}
+	siw.Handler.ComposeSbom(w, r.WithContext(ctx), id, params)
+}
 
-	switch {
-	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
-		var dest ComposeMetadata
-		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
-			return nil, err
-		}
-		response.JSON200 = &dest
+// ListComposes operation middleware
+func (siw *ServerInterfaceWrapper) ListComposes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	}
+	siw.Handler.ListComposes(w, r.WithContext(ctx))
+}
 
-	return response, nil
+// ListDistros operation middleware
+func (siw *ServerInterfaceWrapper) ListDistros(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.ListDistros(w, r.WithContext(ctx))
 }
 
-// ParseGetOpenapiJsonResponse parses an HTTP response from a GetOpenapiJsonWithResponse call
-func ParseGetOpenapiJsonResponse(rsp *http.Response) (*GetOpenapiJsonResponse, error) {
-	bodyBytes, err := ioutil.ReadAll(rsp.Body)
-	defer rsp.Body.Close()
-	if err != nil {
-		return nil, err
-	}
+// SearchPackages operation middleware
+func (siw *ServerInterfaceWrapper) SearchPackages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	response := &GetOpenapiJsonResponse{
-		Body:         bodyBytes,
-		HTTPResponse: rsp,
-	}
+	var err error
 
-	switch {
+	// ------------- Path parameter "distro" -------------
+	var distro string
+
+	err = runtime.BindStyledParameter("simple", false, "distro", chi.URLParam(r, "distro"), &distro)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter distro: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	return response, nil
-}
+	// ------------- Path parameter "arch" -------------
+	var arch string
 
-// ParseGetVersionResponse parses an HTTP response from a GetVersionWithResponse call
-func ParseGetVersionResponse(rsp *http.Response) (*GetVersionResponse, error) {
-	bodyBytes, err := ioutil.ReadAll(rsp.Body)
-	defer rsp.Body.Close()
+	err = runtime.BindStyledParameter("simple", false, "arch", chi.URLParam(r, "arch"), &arch)
 	if err != nil {
-		return nil, err
+		http.Error(w, fmt.Sprintf("Invalid format for parameter arch: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	response := &GetVersionResponse{
-		Body:         bodyBytes,
-		HTTPResponse: rsp,
-	}
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SearchPackagesParams
 
-	switch {
-	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
-		var dest Version
-		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
-			return nil, err
-		}
-		response.JSON200 = &dest
+	// ------------- Required query parameter "search" -------------
+	if paramValue := r.URL.Query().Get("search"); paramValue != "" {
 
+	} else {
+		http.Error(w, "Query argument search is required, but not found", http.StatusBadRequest)
+		return
 	}
 
-	return response, nil
-}
+	err = runtime.BindQueryParameter("form", true, true, "search", r.URL.Query(), &params.Search)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter search: %s", err), http.StatusBadRequest)
+		return
+	}
 
-// ServerInterface represents all server handlers.
-type ServerInterface interface {
-	// Create compose
-	// (POST /compose)
-	Compose(w http.ResponseWriter, r *http.Request)
-	// The status of a compose
-	// (GET /compose/{id})
-	ComposeStatus(w http.ResponseWriter, r *http.Request, id string)
-	// Get the metadata for a compose.
-	// (GET /compose/{id}/metadata)
-	ComposeMetadata(w http.ResponseWriter, r *http.Request, id string)
-	// get the openapi json specification
-	// (GET /openapi.json)
-	GetOpenapiJson(w http.ResponseWriter, r *http.Request)
-	// get the service version
-	// (GET /version)
-	GetVersion(w http.ResponseWriter, r *http.Request)
-}
+	// ------------- Optional query parameter "limit" -------------
+	if paramValue := r.URL.Query().Get("limit"); paramValue != "" {
 
-// ServerInterfaceWrapper converts contexts to parameters.
-type ServerInterfaceWrapper struct {
-	Handler ServerInterface
-}
+	}
 
-// Compose operation middleware
-func (siw *ServerInterfaceWrapper) Compose(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter limit: %s", err), http.StatusBadRequest)
+		return
+	}
 
-	siw.Handler.Compose(w, r.WithContext(ctx))
+	siw.Handler.SearchPackages(w, r.WithContext(ctx), distro, arch, params)
 }
 
-// ComposeStatus operation middleware
-func (siw *ServerInterfaceWrapper) ComposeStatus(w http.ResponseWriter, r *http.Request) {
+// GetPackage operation middleware
+func (siw *ServerInterfaceWrapper) GetPackage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id string
+	// ------------- Path parameter "distro" -------------
+	var distro string
 
-	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	err = runtime.BindStyledParameter("simple", false, "distro", chi.URLParam(r, "distro"), &distro)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("Invalid format for parameter distro: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	siw.Handler.ComposeStatus(w, r.WithContext(ctx), id)
+	// ------------- Path parameter "arch" -------------
+	var arch string
+
+	err = runtime.BindStyledParameter("simple", false, "arch", chi.URLParam(r, "arch"), &arch)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter arch: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameter("simple", false, "name", chi.URLParam(r, "name"), &name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid format for parameter name: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.GetPackage(w, r.WithContext(ctx), distro, arch, name)
 }
 
-// ComposeMetadata operation middleware
-func (siw *ServerInterfaceWrapper) ComposeMetadata(w http.ResponseWriter, r *http.Request) {
+// ListDistroImageTypes operation middleware
+func (siw *ServerInterfaceWrapper) ListDistroImageTypes(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id string
+	// ------------- Path parameter "distro" -------------
+	var distro string
 
-	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	err = runtime.BindStyledParameter("simple", false, "distro", chi.URLParam(r, "distro"), &distro)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("Invalid format for parameter distro: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	siw.Handler.ComposeMetadata(w, r.WithContext(ctx), id)
+	siw.Handler.ListDistroImageTypes(w, r.WithContext(ctx), distro)
 }
 
 // GetOpenapiJson operation middleware
@@ -1005,6 +4010,13 @@ func (siw *ServerInterfaceWrapper) GetOpenapiJson(w http.ResponseWriter, r *http
 	siw.Handler.GetOpenapiJson(w, r.WithContext(ctx))
 }
 
+// SigningKey operation middleware
+func (siw *ServerInterfaceWrapper) SigningKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.SigningKey(w, r.WithContext(ctx))
+}
+
 // GetVersion operation middleware
 func (siw *ServerInterfaceWrapper) GetVersion(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -1023,18 +4035,69 @@ func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
 		Handler: si,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Post("/blueprints/validate", wrapper.ValidateBlueprint)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/clones/{id}", wrapper.CloneStatus)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post("/compose", wrapper.Compose)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post("/compose/koji", wrapper.ComposeKoji)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/koji/{id}", wrapper.KojiComposeStatus)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete("/compose/{id}", wrapper.DeleteCompose)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get("/compose/{id}", wrapper.ComposeStatus)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post("/compose/{id}/clone", wrapper.ComposeClone)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/events", wrapper.ComposeEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/log", wrapper.ComposeLog)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/manifests", wrapper.ComposeManifests)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get("/compose/{id}/metadata", wrapper.ComposeMetadata)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/provenance", wrapper.ComposeProvenance)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/sbom", wrapper.ComposeSbom)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/composes", wrapper.ListComposes)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/distros", wrapper.ListDistros)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/distros/{distro}/architectures/{arch}/packages", wrapper.SearchPackages)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/distros/{distro}/architectures/{arch}/packages/{name}", wrapper.GetPackage)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/distros/{distro}/image-types", wrapper.ListDistroImageTypes)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get("/openapi.json", wrapper.GetOpenapiJson)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get("/signing-key", wrapper.SigningKey)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get("/version", wrapper.GetVersion)
 	})
@@ -1045,57 +4108,178 @@ func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xa+28bt5P/V4jtAW4B7UqW5JeAonUdN3Db2EHkpC0iw6CWIy2bXXJLci27gf73A1+r",
-	"fcmScynucPj+ZEsk5/GZIefDoT4HMc9yzoApGUw+BzJOIMPm3/Pfp9PR+zzlmLyDvwuQ6iZXlDMzmAue",
-	"g1AUzCcBS8qZ/g8ecZanEEwCKMIVSBUeBr1APeX6K6kEZctg3QvkSE/+LwGLYBJ809/Y0HcG9M9/n3bp",
-	"no6C9boXCPi7oAJIMPnolRuhd6UuPv8LYqV1VfyYKqyKDvsLkeo/DTMbevSkLfL3Qwni4Rd6fRkPA2PN",
-	"/xGYe8aXF4BxaV2v44HjGKS8/wRP95TUvTr/9er86mb6882r6+uTyz/O37z97bLTQYgFqPuNpLqY1S84",
-	"FX+8V+znyzdX/V9P3ry6vH7dn799fLegF386ub9e/hn0ggUXGVbBJMixlCsuSKe6BAu4X1GVaJW8cJum",
-	"VPgxOByOxkfHJ6dng0MDEFWQyY7cKoVjIfCTkc1wLhOu7hnOoO5G9hT60bZVjTDVQe1C6AVhm47+lajN",
-	"i/gTqJaP7uv/7TC/GNDSoWeR3Xb24IzWvcEZDQfx6WhwcjY6OTk6Ojsi43kXKi88Dpp+ZTQoZXRa/k8h",
-	"YL+TjWZ4CWXiEpCxoGZuMAmucQaIL5BKABVGGhBkFkToSqGskArNARWM/l0AosxMXNIHYEiA5IWIAS0F",
-	"L/Joxq4WSCtBVCKeUaWAoIXgmVkirI09hJHAjPAMcQZojiUQxBnC6P37q1eIyhlbAgOBFZBops+zWg4a",
-	"w7rATnmMlYO77uBvbgStEhBgbDFSkEx4kRLjnPcbM4I05FKBABKh24RKlFL2CcFjnmLKZizhK6Q4SqlU",
-	"CKcp8orlZMYSpXI56fcJj2WU0VhwyRcqinnWBxYWsh+ntI913PrufPrhgcLqe/NVGKc0TLECqb7B//gD",
-	"7F4rui+VHDQg0ckEhQ52dwbaAN2bAD0f+3ow9wCrGZ1bXsSYvXNiXhuNXWdFMS9NcCdU3airV9qk6rQv",
-	"MGYMR+R0PoxDPB+Ow/H4cBSeDeKj8PhwOBocw+ngDIZd1ilgmKln7NJG2En7WNVOIIkSvpoxxdGCMoKo",
-	"8lvKbGf0lguF031SyaeRog8QEiogVlw89RcFIzgDpnAqW6Nhwleh4qFWHVovGrgdxSewOJofh4fxaBGO",
-	"CR6E+Hg4DAfzwfFgODojJ+Rk59G1AbEd7lZSVrbujlNu2wldP932OS4a9lYEdJlwoWmZhDegMMEKtw3g",
-	"UgmA+5hnGVWdifNtgmXync+feUFThdz0jiTMcfwJL63suqi3dsSePpTFaUEoW6Lryw/vzoMKm3mOUjoZ",
-	"pTstrrPejoErNG0I4kIqntF/cFmBnjPhoj573QsI1e7PC9WqmCKBNDztgsmGzdUVmwn7+H+ll3lHupyv",
-	"pkbNrpbKu+eQkkXaAVSTkx0OR6AZaQinZ/PwcEhGIR4fHYfj4fHx0dF4PBgMBlVeVBR0NyeiJLjbmPL8",
-	"vpHl6E7QnKDu7ePkGL2tZKgrruZ3hZvnXKqlAPlCXl45YHZ5Ma3O7czz1xdv96NUG47cXVIxQ/BIpdLb",
-	"c3p7fv3q/N0rNFVc6O0bp1hK9JMRETUpjvvwDN1+js7dJmA5mOKokIAWXLgSlXOhHMUx9ySCdH4UCtAl",
-	"W1Lmqlg0Y7dlRTOCGgxQ365cyXp98RblgmvsemiV0DjRzK+QQGbM672ZOlm2Jhr11pYIabrIFZI5xHRB",
-	"tW2OGs7YQWxzV4Q4p+GsGAxGsU598x8cIAuGV4ewrNRhbfVLqOOGp7eh1C7a8Uq5L31a0TTV0JTgKl7F",
-	"V3Nfh+cDTosNlFh/psRI99UvQlMA5Mt+nPKCREvOlymYoi9t6hg+0C/poOPcVRB7xsSsSBUNneV+OopT",
-	"LkEqbaaeZOvwjH3rmJ9PT5uY5bLvNMxxwiUwhAvFM6xojNP0qQkyFC+4lDdIui5ofOFxMX4jP13ba6TU",
-	"M7krfU16RjN2iePEJ4lBPeZMYarvGR4p4cuxU4O05RH6YCyw561EWMBkxhAK0UEhQUw+Q4ZpSsn6YILO",
-	"GTKfECZEgNQpiBUSkAuQ+tjZ6Iq1CNRwK0I/c4Ecej10gFMaw4/us475QeQ0SxAPNIZzu+6FNljVTsQ2",
-	"3dlTyFVidlv+I85zmXMVLd0iv6ZqkuFuL0XD+e9vi9quBgQko0x2YkB4himbfLZ/tUKzPdG0oAqQ/RZ9",
-	"mwuaYfH0XVt5mlqF5porQUgbfazc2iYim613gLhABw2bunfd86lJpV1jDwedqAizpxnz+NZ308fAJFwr",
-	"K0yLo5YP+wYv6AU2bG2Yg17gAK5++YI63KAEzzRcygr79eh8L3BVqNXxwjIGRjBT4VxgSsLRYHR0ONrJ",
-	"nyriertuBzU62e4eiTihCmJViIY7j6fH98fj7eXdft1oPHVNt7ePXdTnZnqrZxlHcy6p4sLjvQ9pfucX",
-	"PXVxMFvbPS/eJatGsNp9rypiNTAaprfU3vlobMusF1PdD7pqVxzcT0AtvZvuVWhyS5GONisyM60w/UvN",
-	"/DFNLRQ5MH3bM/1Mmrp/rWX2f9+50p/uOjLF5UDH89CiQcX1pat/2rc52geyhE6BW99lWrukeens3Cid",
-	"5wzkfMuIPyI6CF0KWHaPSbrMyNG2IYb9Rt1y3nUMPICQjj/uaIvYJDZmb5ZtzO1ZEEobdY5U9l37CoIl",
-	"uAhsDomSQBIWCSAJtj0jTX+Aqb6+z/Z1dE834dVyuOxz2a9dNEXaddpkoHBK2adurRkVggsZLYBwgd0x",
-	"GnGx7Pt1P+g9/L0dD0dDzeeHx9rv78sDcacJRklKpXqxEeXKuhmjLzFDJDKrBH3OeQqYtd/l9LSuwjFt",
-	"XFybzziKPhj6HbbeU7Kn0L5yhPZ5Y6+3MR3lsDNd2tmyh/eUSbpMGu9rShTQawHSC7hYYub6AbUFw8F4",
-	"MBqOyzWUKViCsG9K4gFE2+LqfT/S4FYM31nYa4b0miDXlFYQq3jbFch6PWt3BzctBM7gZhFMPn7Rm2+w",
-	"7u1ct+UHAbtWbut67NS49QlqfVc5MncXy9unHOS2A9MDuB37bQX/y6H31Xt/yPdc0aS/L4DYr9DQbpjI",
-	"foxBFIxtowX/0zA5W3qteJXxsesqxuKVno9XMjK/VljGuf6oXe208MOmxNYDvHft9RPv1mtzeC14uwcx",
-	"dXdkxU2H3vWqmFQ4Te0VTkZBL9AXMmbZhWUgwXmO4wTQMBoEjhOVtWi1WkXYDJsC5NbK/m9XF5fX08tw",
-	"GA2iRGWpiQNV5oS7mf5k1Lv2rUCmGYRwTiu0YRIcmpM1B6YHJsEoGkSHOuZYJQabvmuhGdS47OhVXgjA",
-	"ChBGDFbIze6hnGumQHGaPqGYM+mamHyBJDyAwB4LA4/r6gGOE9dVogIR0Etch8okBAjz6Yporc4sGyCQ",
-	"6idOTIFzHMVUvzxPqe0+9f+SNsA2FXc+LdQfKtb1RNAFyr6L5lzHQUsbDg6/vnbT/DfKG5DbCSjBEkmF",
-	"hQJiclUWWYY1yfNB8cHTgz6S/c+UrLUJy67O82tQtqtntqPpQSO37REXRmAKCogX7R4n7SsSSLRKQCUg",
-	"9FzGFaIKmSMFCJCeiTVOJUeaxyG9fzQ9oJwhPOeF8i/IRaq2Bnzqj4kcC5yBAiHNedz1yupM9L4ojpam",
-	"FU6ZYTkq8Tx6Erg3xWqEe5VoffWXlrtW+gy+dvqUV8dW+tRx0QfAuKVewaPqm7fmuuKmIy3hV8x2X70S",
-	"SqyC8ddS8J59YnzFagpquX/bSN+tm8BcJ/xF8tnd4CdagQvKqEzqewAQPOJY1ZJagCoEA4II6BoqEWfV",
-	"H7H4X8jYlvG2hC8vu/9J+Z0pv3mNbqfNbTWM/l3J/gLJh/H/3U5opa/2G1f81TvCFf/II+42Qj0ZX4O6",
-	"sfN+ka7d0A5l3Tqb/RIpXR8Ij4tM+1s3cOkMdDYgbUP53OHvVwovdcKbpoGmXr2gX2FsnfvWy/UPFps2",
-	"ScutD5UOyr+UnV5FRwhxy8RugNqz1uv/DgAA//8F5oyA1i0AAA==",
+	"H4sIAAAAAAAC/+x9e3PbNrb4V8Fodya7vytKfifxzM5d13ZTbePYGyVp711ltBAJSahJgCVA22om3/03",
+	"By+CJKiHG+d2O90/trGIx8E5BwcH54VPvZhnOWeESdE7/dQT8ZJkWP3z7IfxWUbhX3nBc1JIStTvWP9I",
+	"HnCWp6R3Cj9Ee/GLw73nLw+fPz8+fnmcHM16/Z5c5fBZyIKyRe9zv1eQBeWs3pmU0T0RMtpvd1A9fi5p",
+	"QZLe6b/UvG6Mj641n/1EYgnDn/0wHh++z1OOk7fk55IIeZ1Lyplor2FHSPo9cQiN/1yQee+096dhhbSh",
+	"wdjw7IdxaO7xYWshZnI16IZ1jCWWZQD+skjhP+sRBo06xn+HF+1Bb8mqjhFJcBZCxh1OS1JvSjO8INGs",
+	"pGlCio2khJnsMB0QbkdHEh88ki6X8cEjWPLpGKGv1rIDMi710hMi4oKqn3qnvfOCJIRJilOB5rxANMt5",
+	"ISlbIMwSBPMJSWApSC4JUkQboHdLguKq44TxufosDhHXcyFcEFQKkiCqPgmifiFZLleDCaygISLimAgx",
+	"vSWrKU3qyD37fnQ2uh5/e33x5s3zyx/Prm5eX4bwHPN8NZV8qnEk2kt9qz8gyRG0VRCfXY3gbzyXpEBU",
+	"oiUWaEYIcyuHFTBoOmF6YGTWWioMG1zwnBK9ZokXC5Io5Iklhu4pvSV6AJiMSkHSuUaBW+O/eqWICDYc",
+	"hPNI8FIu1Q+KwlSSTAS2r8MCLgq8gr/JgyQFw6nBYh0Bl+YjGl2g+yWNl2ohsiiFRDlPabyyiyt4SpBh",
+	"PDEISmaekikuWHuW0dmV7g94FaLMiGKsCmd9dE+lnluTHd2SlTCMspowQKMgso94gUgqSNXc4zkL6T0v",
+	"bknRwGcPF+wU34tTirPT0/2Dw6Pjk+cvXu7tH5wCZMMNsqffEyQuiJxWXFlnyft/4LT48b1k315ejYbf",
+	"P7+6uHzzaji7eXg7p+f/Y3j0+8v/6fV7c15kWPZOezkW4p4XSXg6IShnU8lvSQCjY/0Zqc9q4QR2KS5W",
+	"PgIHW88GfDkFpMICeWkOco8bfYztxn+C4VwsuZwynDUEfraK7NcQVBIvAnv2HV44Up9djYTaWHJJaIHs",
+	"YKIPOxQnCZUaSeY7QDDoecBvEMHvsIajtqLP24vX8eFm6ao3gJKmCkw0K+NbIgfoksolKWr7gRcIq400",
+	"YVTYzZg8kfDUcLQIZn4OdPhD0PwhaNbP1lBdDCut1Ve6lNfHXyBwRkNSxUoTQ1tFJiVF0hQZ/eFUfeFM",
+	"/a5/60/YnKcpvycJmq3gKLcnv1YRbFcYtqGNaL7ZVhTBLSogXJ/6NlTESypJLMuCjAAj71Y5CVHDa1cH",
+	"5uHFyfTkKEQHheGptANuhQgHw4jNeVA015bnQ1Wf8KNanKRzHMv2ctonVULF7eDnmN8fdByfB8cnbab6",
+	"jjxEhMU8IQkaf3cWHRyfoIQuiJCWzeY0JX0jENV6BSnUP0pJkgm7XxKGqEQFiQm9A8VTDtAbLpEgUkk2",
+	"6C+qz7MV4iBX0B0pYNtqPdwOrDmuDT39hQQ2Pv2F+FACQ89Wkgh/q1MmfeJSJsmCFC1CKHyaiYJs9ktZ",
+	"kO0ua5qIlkB1kN/gjNR1ccCYvp+MJMrgrJkRVDL6c0nsBl3QO6XbC14WMUGLgpf5YMJGc6UtICoQz6iU",
+	"JEHzgmdmTysY+3AYY5bwTMmEGRYkQZwhjN6/H10gKiZsQRgpsLRndO0gVYCF6JHyGEuzq+sLfG2+oPsl",
+	"KYgnp8SSl2mCZt66/bsaUZcSKlBK2S0iD3mKKZuwJb+HgzKlQioxZycWpxO2lDIXp8NhwmMxyGhccMHn",
+	"chDzbEhYVIphnNIhBroNjcb433eU3P9N/RTFKY1SLImQf8K/WJVyChNN3STPGigBmUVKIHbY7KMJNFUE",
+	"Wk/7OjG3QFaTOu94GWP21gzzSs0Y2jjlzIEQVHpGFwCS3+wRwByR4+TF7CCO8OzgKDo62j+MXu7Fx9HJ",
+	"/sHh3gl5sfeSBIWSJAwzuQYuAEI32gaqNgMJtOT3EyY5mlMGosluKbWd0Q0vJE63YSXLRpLekSihBYkl",
+	"L1bDeckSnBEmcSpaX6Mlv48kj2DqSK+igbfj+DmZH89Oov34cB4dJXgvwicHB9HebO9k7+DwZfI8eb7x",
+	"hKyQ2CZ3iym9rbtBynVpNXXpto24aMDrDRAC4Zu0JHlBmbygeMG4kDT+Mof5nJI0CWtVOS7ccWdOIitB",
+	"zcEHFhb4mhd8lpKsRsUcx7d4QURo0upAb6mCoeYZEQJwGLq0CnJHCipXgYtLUfBCoAzfktoS5pim6uRO",
+	"CbrHBaNsoc09eMZLqfeRmDC9wowulhIxrs6f+yWW6B6DiigJS+CchyWzMgMKEpiv1++ZMT0ydpDcgV6t",
+	"sN+t+qxniw84pYk7fOpskTiW2V5fC/FbQIu9g2nbqP9hSdQVOMQ5wDOMI4UtjzlmnKcEsxaS9Az92iKC",
+	"mOBcXvEkoFx8x+89ATnjXIrT6naYkgWOV+pnpARmASz/zeh6DDdJcfhy72HCMhwvKSPCXELfX347Mv+c",
+	"cblEf1muZgVN/qoun8ArWB3LNe5gnBGQM2q6Xr9Xkjnwuu4Z4JV+7xyn6QzHt+0VnaH3b18jyc0uxOhc",
+	"4/jyjjCJqEA31+N3JIHDgQGPqYUKJbnMfp4wS5Z4idkCVqaUpJwwZdEomaQpHAyijGNCQC0BnRXTFA4U",
+	"NY8wd266YCTRyMAMfXd1dh6NvzsDhVlPRQs048mqwri+JiMsJuyWrKwOTQUSAP3S07zNqfRjZNZXRGO6",
+	"YBi2BloSoNWEYYGeaS3+b5Nyb+8wFraJ+pM8CxlZNAjwr61u2sbfUskpezDGdGB+VIfikvNbMbRK+0aR",
+	"D8Naq0GQp89TzshbIso0cNVpWof2Dw4JWCwi8uLlLNo/SA4jfHR8Eh0dnJwcHx8d7e3t7fk3gLKkmy/6",
+	"wJoAiMdfbUjMgqfCnY3rJIsZyxykn/tmIV16juXT+yX8v2FizbTJoNf/4gjo937is6mW5RtWog4Ye58t",
+	"CBYhzf+H5ap1+pCkD4Yr7aIIGsq2Q6W6VGtEflDetAD53Fj9Jp08wr6mQo4kyQK0LQjcgqZYhpZGWJ1G",
+	"WFgxTxIf1QmWJJI0C14QnoaRnwqHFT58/PGAQ3VOGRVLsuF4VPZEdSja9n3jwTJyUQqU8gVKOBHsmZyw",
+	"RcHvEWarjBdkwgIHKCizi80aHQwqJDb+Qenfj0Haz+fWmuqOi5RI3Y2zmHQAHzZU6NHCMOlvcJqZ2Q0k",
+	"ogYpnE7+bt/fOzjaaL0APLjJ+xVBgrJWU/IKMzonQgZU/Mz/1F6H/QxQEwyUA87SKkGZpsYhC7qlkWW2",
+	"w4QtMZBW+yqd4QGtiITOMVzo9OfqY90CCePjGaBFFiUJLG6tsa1a1zq8EIkTLHHo0qGNcR1o0XauynGg",
+	"Te/6rFfOW2M60zyvGMo4qCfMYFCUoOQI37qrHK87mGENjCEVFjS/aWY0x7UqsdUwlX+aScLkFBT4Oa2s",
+	"PusPPtXng9fFnO1wxaGMF9OCpASLgA57BZ+R+Wz3RUJhh81K7SpzyAEpDHiUatP0kcS3hE2YM4IZCyOM",
+	"AsLFDmrua427+IvBSXBDC1kQMo15llEZPL7/ssRi+VcLqobHNA+M5y6LraFu9Bdt66IsTkvFH28uP7w9",
+	"25b8ZgzHw1t5Bg3jG7Nm4GT01PO1ZLftvhzbqKFKIXlGf8HO3Lp2kHrrz3Cfqpinfv4WS5JGL0JUImWA",
+	"QN+oM8Cxn6isrZcP+paM3uegAKBxmee8kKggORdU8oKSKtwk8zncXgqs4VeAW1UHZyhkDAF6lGO51AO8",
+	"JQn6Dkt0fvGmNrq61hckT3Gsbey2PynFoOPw1DducxYF1jvSq5RcC6u+FvbqdgPbid8zY3pDEhcLAPws",
+	"Tc0+yPSdqdqdaukCZwTV6bmDi0nBY7k0IN92lSwYvf3u8nWHcBGoDj/Y26XFsJbQfzZjwb3zDhcUDiZ7",
+	"k3v/9nV1A/UJZQmekDkuUymsSz3DP1XQDbaRTY3DrcbmLeJ+XLfvfzPXrvU2x53V3IrJddeQ1B2bLzWn",
+	"qtDs4bR9YHnQQpFcYqa9q5k6QZybxZCdF8as4umYYoB8IOATQ0ueJmLCWuYK0JhSd8+wzKKvUnCTwmxl",
+	"jjOjNAjzcdd9VGForcpUw7ymVFtIdxqijBBq4JYKpI8FsLB8s7JboY84S1d6x9hTEnrWxJwiRbwk8e10",
+	"kS/UHq3GGjEEbBbLCQM1p28c4153JWaX+I4gjBb5Ylo3y6jIP8n1iKsJUyYvRSJnjLEy3BywFanNJCtl",
+	"ipqwhOSCp3c2IrE2iOYuLSpBkNrFDtBZpZsYK4CbOMbM2GbtehXhhX8tHdTscAat6k4HSAmb3jpVtfat",
+	"YAvNseMIL5kFetqt/7wps5nePD71PW1PkemeFCBkhcRpaqxxvJQTPcHKKNS08LDtn37u/tTvfRGIajQS",
+	"Gjg7sHGNyyVZNaAOQtS8qwC2Q1CGsRmU7y3FqU5Qf+FeGF3OhVwUeswdQug8z9MmLhn7bYE9BCm2N9i/",
+	"F6RoQxBSbC8aml+3D6mJAwwflRvJ+JN2wkXbOaY1zeONx6Hq2W+A9rGxlG2jXLZHaUcMTWBpm5Tp4131",
+	"lPZSK2Nj0MSImdl91kZ/nlJYDMrwCvSnFeLWVpeQvvVuGaOSsaeiGZH3hDAXg9JHs1I7v0xnbeRUqvhE",
+	"2ejV1rfnsFMQTycMoQiEc0zUxoa/EgLOBcLiVaQ76KCshEglsLFxYyvLqxUoP/GZPvkQQmZ9ZhiI1+gr",
+	"m4rBNfLOFv/40JNTpvxI0U98pn+wnyPGZTTnJaughFGIgVH/WJ1jUcnwHabK2mIWqWV75F/pap31VTkq",
+	"yDwqiOBp2WphDTCRMe+0R1A3jSDaYGtY9447DfzO2t4SKbTqX/TtZP1o+uqi29/yn2hEGZWRtV2rX2rD",
+	"mN90woH9TZNNfZB44ZbU8sbE5ght+44r6oSuowbqwJ2Gq3NF69xwf9GeXcdefcNFwCucmdg/bdgxuK4c",
+	"AG2LFmGJ5U9eyrx0ipzpGyBFOC7GOgyqdV+8+dZugDguCyDPFS5uKVtoZ/Ypuqy+FsbDhzLdpDqOlalR",
+	"H6tq/afoRjvohY5/VAr4KcqoEH6/U8Q4Iw9UAENvlFixPorNMkIn7avzm+1Cw6qA5XBoEGZIgQXAjt+d",
+	"vbk4e3uBxpIXgOc4xUKgb3TsdTNUy/yxJvZ5AZBNuZjOCXbHQyN4i2qrrmqKrsfINkWSI8LUBdddkmED",
+	"kER5ZEtJ0CVbUGZNmQM0JgQ552HKy2Sw4Hxh3IcmelCF2+hoYzHU/oYoISlR/8kLEsMPeUHv4L+62Z8U",
+	"aBEXkQWtlZQCPuvp+fXVzdm70TcqbvzVhzej89oRbnXkrrb93vjy/P3by+k319fvev3e1fvX70bT0c10",
+	"/P6bN5fwy4fR23ej6+n4fDyaqq//fH/5/lJ1/DA9P7s508P9MHpzcf3DOKh7N8/WdXGDsB21zOKoFFXI",
+	"uCODl7pTp4iJLpywd05vVQM1Qg1BkTaX2FfnNygvODB343I0YXbe67EZy1iAYHoNywBBXCKXSOQk1hcV",
+	"G4M4Yc+sFTzCOY20GxvsBMaDjTRy7HR1U7jKjNghRrGKO26jEpaov3txZW5N9zRNATUOuZL7+DXmIBhH",
+	"pfY5VGKkzl41ug2z2rAThN7beifYPsIEd/pI7Ot7Z5lKGhnIbXMUp1wod5I2JemArwn7i/6Hkx9acrhu",
+	"fwU0x3CFYQiXksMJAqbeVRPJpNwhHycsUAxe1LqRbQ7wqlHWCRRHErkcTNglWCANkyisg1KCKUPYYcqp",
+	"VGYaBJAPkAobQvqg9FW3Z3D5OP1EMkxTmnx+dorOGFJ/QXJOQQSwIFaWv4IIpWi6uWJ97teXNUDfVrpa",
+	"Hz3DKY3J373giWcDM7MgxR2NyZnutyMMemozRNfc2Sricql2W/53nOci53KwMJ1sHx8kFSS4KzbM+m1Y",
+	"MsDVQEGSUSaCOEh4hik7/aT/CxOq7YnGJZUE6V/RX/KCZrhY/bU9eZrqCVU8tSCFuaFjafo2MVJtvWeI",
+	"F+hZA6bwrlvPmlToPlo4mPAgyNMx+G0nTZLitMUVvX6vwQ/bEq/X72mytdHc6/cMgv0fd7vXV9vcnAlr",
+	"tvnoQuHfO0B22eQTBtPU7zrQyTK5G1IZZ8ca3x9uzsHwJyRcwUDrU0ZVUbXue740qQw2JrIBvCQZZnih",
+	"4q/0AJqJRX/CYszQrGrrXBj2NK3TFLIENZSRmXcXLG+fc+QUzS8XnavuKDB+KwsPi5iwBDMZzQpMk+hw",
+	"7/B4/3CjuuwN198U7PuKMFLQeNtyBjGezkqWpAEF6ebyykXTxdBDXVG15qpT+IDGBLsbjVgJSbJnAnGm",
+	"g2AJg9OEkVh6mY6EJTmndhe3UGc/t+GBuEWt0I8PI9B6sKRKfVZrR+bct7xduf6vKBtdI7jBnpN8id6+",
+	"+mFgDm4TuqvFcBUzCL5BvSYqwO3UPL2t6pFRRrkfxHf6Ujtstipf4Wd6f9laAf2euKX5VIi05Sy2FuzT",
+	"OU4F6TcwfMHBoKP6rGq0eiZ8Dhiga3ArlIJoFCkNlqgrVtgx2mBnR+L+xoIWDW5+kqIWyjx3U/AFcEFg",
+	"G5gvhvd8yzkVaEaAtZVT89SzpdjrPKQDFSWDmO6+Ni1xodOTccbBk5GmukfdDt63Lo4Jy0kRE6YHnVcz",
+	"CBdFf0dcANUAjf1vBogJgygO41sHGGIcL3UZBuCTnJjiBXl4oVyQCdOrMcY6kD2iw3AUiphV9gZWTyo+",
+	"DDkQzFprDfdPgi1pTlLKGiKZi45YvkWzYbEYGOwMijxYqURyieuRu/sHG10Meqq+W7EdplpaJwN2xoo8",
+	"JjGDPMD5TKYbQ2OEHyxhvGGVK4gynyUZUbnpEwaZQDSmMl0hxgsQsc4+SwPWgzktyD1O02Q3NWnHPA9t",
+	"Kt0kNa/H76CVihpagUSZ+s7MUKkQ31OqUAX7hhv5p+6xBl/m6Kh5tVwYWrswQc2LOkDvmSkPonzUysOF",
+	"IUYTaKImsmYCvRMLzus+4B2c1W5Nq3BucR0fX2BIbdCwkRMbnVH+qdbuHqwNUSyI1ot12wotmj413NeH",
+	"c+4MKkzJGpzqwgBUudVd9JC7megAFhXGisHy73IvJdfhPHzumcF3icZprfxxec69BhE/WhHTdXp22Ktt",
+	"9LnvG/JjzxVaqlCLenAHxAu0ojtczlfNUTOnhZCWXEssJ8z6oUYSaZBmRCAXWm8taF66ojAXDoKTCYNQ",
+	"LePAsmckKLh6UtGhdnYnErijcKsAFmshN9Ysgx44zZsBDrq5uRRVwdMmGGziyYPazE1l9P82cyH31KaN",
+	"ATJOx/oVAfZOHGw3QE1tbHbeJnhJd2joRG3a62YueqmqGcHnTYEDO0iFx/slJCydJ6zeenfxsWUUkhd/",
+	"1EKyZ81XKVxCZf9jmmpJY7xfvX7PRlz3LGL1v71iYUErfb2eQzvlUN9RppsrFLhyHapEQR+VLCVCeLvU",
+	"YhFhlII4LpDOpPNSAZ4fPj/af3FwtLdFgYNQHEJHeYhwFEJtaYD77/lPdFOw8GOibduBqFuxEICzKSYU",
+	"fLLbjGNNDt3RG9rxtMbb4MJOq44Hewf7+3sHx4PgPdsEH9S7vBjsHEJhyGWHq2Axy98qGNSj7TZRmLsW",
+	"ROna6BrEqXZFNwxQRwchpv5CyU4uz6mxKsvnX/6a03VF6CzZ8sWV20frZB380mmnA6sWKcKZpEDvwZwk",
+	"vMDGUjjgxUL9vCxntcNfZY0GSq6J2w0lIwA4BO28S8iMpJwtBJK811/PY01O0YupJg6h4/p89OUjAK7V",
+	"8M5/p7v6Z4lAnj4/YTMy54U1suvwIK33ulYaYOio/eyJzka6x0UiAr7V7mACZdEsZEZCts/r83pWq2no",
+	"R04aD6s1r1NWD2jgMU32B17fAY/3BwNs/te9vcLe8wsq8hSvtOPbHcfGFaHzEVwRnt+G8xraixzHgcU0",
+	"uMK1rNVLiVdeoT6P9+toxg/4geVxwYv7411d6Nfno7YLvdN/Pmh4lKN5gdntvCy2qQHm7K4+1/k46q/z",
+	"mbituf5co8l6Rg7zS4Br9Qfg1/oy13JvR5G0XbDklrG2XJqxKQWCgYvgXj6HWHtRZhYNup1JcBugq1KW",
+	"EDOAlBFP0Dtz4SiLVBdasBYLr6+qC6liDxOkPJz31N4UA3iZN0JzQRkbvhjqc3ZIkgUJqu2dRvYWRoxx",
+	"UWV3hU/6bU94rcWo7G+/4MLanHCScz3BNuq7zMqHrZXPo8E8PjzZWvE8HBzgbW8GGuiwyqkQ9rHCa/ja",
+	"pFC1vVZTo1EoLNkz6XYlhBtbZz13QKddY0mEtFlulbtYJ9/2+l8KxlqAfIsqS56RvKv4z/a8IMoMYiU2",
+	"e5gMLW37OoAeOH1LrAaaPRqvy5mOlwFQ+r3YyJVtJI6aw+Z/aAO+ER9VGJz2QwmwRAmtUQD5vNTzbTZf",
+	"G9/rtlq3wr7q9Ac3koVq66uvTGU0qzyvGRYqobCvrHJQ3kzl2mcUbFspNYsL1GxJ2KAgyRJLE3RZZZQO",
+	"QYq+qMQoTMHFsMMlRRdZchxcsct9WeMk+bRWAK3nUXMbWXPDVQzmYPR4ckzgU1cyk+9r2mV3j81uCexv",
+	"54PrSiLKsIyXfhhyPTRFmeAEmpEVNxljKa0lkXddUPz6YwoGHwuVNNhUP3R3geIo0iFzXQKBl2eBhf0j",
+	"qojoHe9wDEXm6Hp8CE1LtjlAATdva/u0cTBhQbqrID1iRw23uNK6hMY1FVS4H+pQzyUM+PI8IRPM/TbJ",
+	"j+35zsbno1GEi4wXJEGvbl5B8eRGZuTWE1YrtJIrjFctykTALGD7/TcM/zf9PTo8gCvXwQls8L85dWwT",
+	"kit5uTMQrmcdjMNHgcGTMiXTJZdz+kBEN8W7EawfpXggWW5y69WYWJXaNZbsEM2Lpci8rdQV8KKaha4O",
+	"40aKX7Ocu4RAfdjYrYLgKjwuLohUn7as+g07KApuxfZO3ALvlAmocFjP9KtVkfFQxYsFZiZzstbhYO9o",
+	"7zBUkKdv7ERtiP3MyAEg1wN8o8JdA6TfRHJtUg9j3mpDhKy7bluU5JXtijNyPe+d/utR0V29z/2N/caH",
+	"j+rZlXCzccbOKs6benYZ+DZCujbC8fNHT03a7C4zaZlhJcmSrZviXQYQj+DNgBLQnureRS8lhJkq37qG",
+	"YtUEPOcT5goq6qv9jqzkHAZbs9CWPZohtDuwzJY9mganHVnE9vpYc3Zs5+M00Xtr0o0ez2bOY6JrtDqu",
+	"cpnIFkR8D63wvRio954WcQ5//qJh5TGF3/SSB+IwCKpK8W6xKXnIaUGiBMuQiRerxN/K2GTTP8DWTQVo",
+	"oepujxK8EkhQFhO0//L5XrS3H+3tN0yz+xAaG5Lxc15AiLc5tSDNNWTLv1SAGi1JN+0jwXVegnmuSXJl",
+	"/9blHW1SnQoumbCULyjrqtqzaHjJ9jtA1ZHsDePZ/ZKQdLfINng7I+DYHn+H8nKW0lg9rtH3ws1wYuKL",
+	"FBVKueQF/YUkqp0TJYIUg7riL8QyIsnB8fH+S3R2dnZ2fvjmF3y+n/7vxWj/zbvLY/ht9F2cHD0sj67e",
+	"suFPt9nLG/bT6P7Hf2bs51F6kY0+/O/V4T/PFt9f3OUnpZpj/++PTnxIeXwbKml4oZkJavUtlJGImZwP",
+	"R+tBkG7tC5cCMFiFdSsSh648Idn/obps1/fT1rdw2/Dj589KkQql5o9NkoatFqUzAk2woc7dBLykNCZM",
+	"m080QnpnuQrwPVA+cqU8OY38/v5+gNVnpYabvmL4enR++WZ8GR0M9gZLmaWKfFQqpF6PdZmuc/dSBaTc",
+	"IZxTz4Bw2tuHPjwnDD6A8XNvAKRQhbYAuOHMloUWwztdcVoBnXPRZSTX7q5azY16FJat+ogXGBDj4u+q",
+	"Yj62iSm/zEvpFa50MVWoZs80yYeTKl2/etfFCwetBaFUr0MwL/xwwoAN9IDCVDBrr8mc5rnJ3x8lvdOe",
+	"qclNXC3tnmYgIuQ3PFnp1Hd1V4Z/4hwiclXv4U8mM1wfQFtW1HXxHnVGBWVe/SByzkyEwsHe3hebPVSC",
+	"XIEQsDbbLPRwUXlgvqMWZJI8yKF6tqAOU3NztmYc6ZoP7Vl8640jEsLBhpCbyogYfqLJZ5h1ETrZXhn/",
+	"c71mldH6NLPDKEnlHasziqrzPLaKRI4LnBFJCqH0ws7CyKmOgwK8qB1qLYKnPePt8jmg72Huixcv+/iE",
+	"7FXX/tpUVmgwmH8qBlJT0EQPf/Slhn/PbhlUC6yGrzFmKzIXmhme1IzqS15bjdIvyO3KdX/6s41ohUrs",
+	"fxraxsOySD/7ozyhdNLAbC+b2tKDwAimxrR+NQmGCyD2XIVPIIwYua9qxuVc6lfgUlUGUphoEj5HqlYh",
+	"Tl0BmyoiXB9G7hXCRCVIOYE/YW9tbKItfz9KSKYmilfR92RlqtTr4wphiTIupIopNWCdQripLFb6KHOP",
+	"a7hS+TgjytIIJ5Krok8ZOjhCS14WQnUvC2aCKpKmZK1is+3YChR3+6yLId3pN3RK7X/52XXB3QDTGIzB",
+	"LcTgSO/3l6H3F8KUohX1FdWoqreSeyHTRwcHYeZudgU2dzkjDc7AKKHzOVFRA8AUeuCX4YF9U5m5YnHw",
+	"/60sjwj0c0lK/cCDvSjXJZHZT6Z9TQQNbSRphwbo70RTBQ+90lWOuKn2DCiCyLS++dPLv3Cx2FV1KUAH",
+	"lV5hDWiXIcrcG5wwhs7sULUEVeJz5qXXvXOtqFClc3RQUr2YZJUvoapO1ImjU051RoJ5EhjAGtai+4Nb",
+	"63sdePoU2ysQhfzHFvuCWyy0I7BiXM1N7W2xq86I7fbTVd91vf1ET2HGVXzP5/4dybtF1lmuHbq8tWrp",
+	"T/n71zDbiAqpmYYAT6tomkmeTtX0JlirbHbxtWVpHScbMAOp32v1opkJZzMvAbiC/7eE5Ga26vEIp62Z",
+	"oF2bRFkF5tZK8jTS12oPG1GBbkkudQ1DKr1SseYyZt7QCIlrvYxKH9r2Svbb3jJH615oaD63QyqkPzm3",
+	"22dGUOydEEAsyxhfezu02dg3WSk78q+S6Z7NigoT7qKSk7WP27wi5j+/pY1SOBVchTt1VDcs1Nk76FI/",
+	"djkH6gIP3v+HJf/uD4M/DoJgPnD7FNCGsW7V/30zi7sm6R0AqoacPh90snVVu9q9BUONcbiPyGAxqEon",
+	"YQYPf6vccHXB0PHdUC/cjD5hgTRPlyTi2+TUE+CZipacrcxtnyZijSp/bsxvux0NStJ1JST/hnbXl7+f",
+	"NBLjv/LVxHtIr8M2XdUGUNVgbG7Qk29wYAg9WeV/6DgNZ4QwvS30w1ANhWbCvrawCO/xzq0dECLKpic6",
+	"L0ljWRCctc5UOwMWyET6CMKkNhAK51OeMFtCIMYMCXgzW1XvsSVyrC8p52kKZY/QGXqmZ3mmh+rb9wZU",
+	"yj8V1UOb+njou0crC/VKLL7H2lLnv7s5YbU3C51P2B36slG2x1M6FCsailtdVyGEsESYF7Oks4w2NDij",
+	"OmjdQ9djcMWdghJNv+q5s0hT8jhAp/8UXUFtD4XBSC9jx11yZlfP5w3++f1pB+v3Y2B/m5cI12rL6kXB",
+	"uSu35T8BFxArA/SDerm5qszTKOHVd4OqjCqwjttKhf/WTwH+W9WOD0oJXc8BUQlqQI51fWwYr+pqAosL",
+	"ckd5qQzwmr2golrns4h9t2OqRxSrl9GSCfNhLcgCF0lq5IGd2VQjMV23qGJSWz4vlNFIvZZVWY1q2s4a",
+	"0fCaLx4lFxY1Ev82JEK/9YDZShL/qcnata0m5LQTxy7j55IUq2od7pnJCnZXqW9PPcNFszJT/27FrHyF",
+	"G81rHtzylTXc48kFvSNOCgy+igpUf2/UeLI2M/nXFoBWZtVQtk4A1h4NXSsGrfxzPTof3qo/weaqX5ky",
+	"RDYpAZcJVXpiQfKCJ6Uvm7Qy4WbSr9JVKWKtN7sqKAJhG2vkRvWa6q+RHhVG/nO0il+9XyvUdexaHyuh",
+	"XdvvaeezAhCeUNfvlIz93LRmWZ7Ge152jcoFoooeOest3J85m9NFWag3nbTTBBJwQGrekpWu8D40v0Bc",
+	"vuaTNbvx96YtvQrw73ph4SWMrpUVfh2ytlFF727ygGNZsxM6bUAnrIrqVZ+ak1WXhtfaRn2iR6kc6FEa",
+	"h8ud/cNYuVlUWFx1SQpLRJsX/JXP9/+YU72GKOwhqLVT84LfqYIiZO1eBTMfiyTX2qMkqt6LkZXj1+Mz",
+	"VI2D8oIkwAb2ecsJw1IqqbE070aGShjat56h0KJAopzpWvCmsvyE1Zw7Xuk7vQXH351FB8cnKKELHV+r",
+	"4ipUgrgkBcWpUQ1kuFSAi7rwH1PxlvQooTFhj5caNxVZfo3CUVvC70OMNOLfO+NzPdL9oVP8R8o2BZHb",
+	"OjXjsRYVHcKvY98GhJ+Y8WxzXAufy3t1U6H6TVcnUurSyJ/LSb6U2vtPUOp4MgeNVC6TeTnPe5LCey3X",
+	"eyK1s0YGZWh8c/EjOhgc6GcGVsqofvEj2h8coX+Mr98Y8Tb+5vrq6xtgxjOe/SqRZsD+jZpgvlUdLPgA",
+	"a4eRxYwcNLL0RJ48eC/9mj9jTcnkoffx14pVGPG/NsvWfq3THUsGDob/eqRktlz3h0ze0p6kGeU3Kp3b",
+	"zj3N82HBvF6ShgV1t7lJPUrkAVATj9VNUIulevSwi7BSBvM+4mlChNTx5QPrGlNAcRPGUnXWKqnyik2Y",
+	"8X2ZoBbzJoFq4ICqILmjuApKPLsZhaQkLMrO3/uVmtJWdW+sUZUKOZIkC1ReafFCDedm4/kYalC/Taeu",
+	"PqrQSsG3ILlfIdfS3Zj2jPHQmBVVgXZzTZgwvyKq8MNP5ZJkXeS4MEB9DWrUXrTekhQmlQ+sIT5Wuqiw",
+	"rrklwPCT/sfnYQ1jw0/w5+ehX1wp7O1WtZnqao+XQLwpkRFPmA+bDhjzAKlI6goPbUNMDdVNVU1prRLy",
+	"xqsl2ijIHFA8NL62VD7WPKW9DopmPd82FKZY1jYwdFU5bINwzrMMR4IAtoBtFimfCS8Rn6kHeJplilSW",
+	"qaqI1aEACbIDtHc0+3/9cAGrNsBX+AEcVIi1n/eX3CiyHUDZilwBpWx/72v7u0KFzjpkQKD0mNCbfKar",
+	"4j6RacwQ8Wl0jZoM4EWd/xvu/IDAUSpFUhOou4u44SfA3ubkB+9Xl3RdyTldYFFDZEb2H9Vqi8jqjoih",
+	"sq99h0PUSlmGZNwrIg3b/CHftpRvPgi5w11gdvWf7WbvEFVfQVrot1DCUsKu7um3a79xXvOimrx1NWhH",
+	"gLtdsvUmVspfJG2pmvXaY+UcsnuuqTRqXcJfQ0Av8dQQ0XwHROgH7at7WsKJYM+kSwREnJH1Gqd7mOS3",
+	"qao8JS/7arCHhg629skZwsLTs3uXrt2ALMTJpkbJwCLIcG5Lql/rdv8Qpj7qpjzzKpuaCpTwuMx01roP",
+	"pzWpGRgQwODevrYl6SReCPUELpEYKsT0e76JZOPBeHN5hey7rVVBIbPt9IOg9vnGqualvhdMWMAIZBPR",
+	"+bzyRveV0cE4fSrr74Q5V5UYoHE1vDJBYEFOjhxol+cX47N22c0JqzuWOuxMNSFiFpWYl8f+HXMYVv+8",
+	"imYpn6EIUIf0CwMVUtTfBEWRA8M0cX/rFv8OXm40Tb4nq952gaKP4X3lWnHwfuGNde5Z6hiXnrUOtY11",
+	"Xab/isMMpZp9hl5VpCDj2k1hX6W27QOa1gf36ckkoZ0igC/cAjG8u9utPn/+/wMAwA723C+7AAA=",
 }
 
 // GetSwagger returns the Swagger specification corresponding to the generated code
@@ -0,0 +1,198 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/reporegistry"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+// Number of packages a search returns when it doesn't set a limit
+const defaultPackageSearchLimit = 100
+
+// SetRepoRegistry makes the API serve the packages of the repositories in
+// `repos`. Must be called before Handler().
+func (server *Server) SetRepoRegistry(repos *reporegistry.RepoRegistry) {
+	server.repos = repos
+}
+
+// SearchPackages handles a /distros/{distro}/architectures/{arch}/packages
+// GET request
+func (server *Server) SearchPackages(w http.ResponseWriter, r *http.Request, distroName, archName string, params SearchPackagesParams) {
+	limit := defaultPackageSearchLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if limit < 0 {
+		httpError(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	arch, repos, ok := server.archRepositories(w, distroName, archName)
+	if !ok {
+		return
+	}
+	packages, ok := server.availablePackages(w, arch, repos)
+	if !ok {
+		return
+	}
+	found, err := packages.Search(strings.Split(params.Search, ",")...)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid search: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	summaries := []PackageSummary{}
+	indices := make(map[string]int)
+	for _, pkg := range found {
+		i, ok := indices[pkg.Name]
+		if !ok {
+			i = len(summaries)
+			indices[pkg.Name] = i
+			summaries = append(summaries, PackageSummary{
+				Name:     pkg.Name,
+				Summary:  pkg.Summary,
+				Versions: []string{},
+			})
+		}
+		// packages of several architectures have the same versions
+		version := pkg.Version + "-" + pkg.Release
+		if !containsString(summaries[i].Versions, version) {
+			summaries[i].Versions = append(summaries[i].Versions, version)
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+
+	response := PackageSearchResult{
+		Packages: summaries,
+		Total:    len(summaries),
+	}
+	if len(response.Packages) > limit {
+		response.Packages = response.Packages[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
+// GetPackage handles a /distros/{distro}/architectures/{arch}/packages/{name}
+// GET request
+func (server *Server) GetPackage(w http.ResponseWriter, r *http.Request, distroName, archName, name string) {
+	arch, repos, ok := server.archRepositories(w, distroName, archName)
+	if !ok {
+		return
+	}
+	packages, ok := server.availablePackages(w, arch, repos)
+	if !ok {
+		return
+	}
+
+	var response *PackageInfo
+	for _, pkg := range packages {
+		if pkg.Name != name {
+			continue
+		}
+		if response == nil {
+			response = &PackageInfo{
+				Name:         pkg.Name,
+				Summary:      pkg.Summary,
+				Description:  pkg.Description,
+				Homepage:     pkg.URL,
+				Builds:       []PackageBuild{},
+				Dependencies: []PackageBuild{},
+			}
+		}
+		buildTime := pkg.BuildTime
+		response.Builds = append(response.Builds, PackageBuild{
+			Name:      pkg.Name,
+			Epoch:     int(pkg.Epoch),
+			Version:   pkg.Version,
+			Release:   pkg.Release,
+			Arch:      pkg.Arch,
+			BuildTime: &buildTime,
+		})
+	}
+	if response == nil {
+		httpError(w, fmt.Sprintf("Unknown package: %s", name), http.StatusNotFound)
+		return
+	}
+
+	dependencies, _, err := server.rpmMetadata.Depsolve(rpmmd.PackageSet{Include: []string{name}}, repos, arch.Distro().ModulePlatformID(), arch.Name())
+	if err != nil {
+		httpError(w, fmt.Sprintf("Cannot depsolve package %s: %v", name, err), http.StatusInternalServerError)
+		return
+	}
+	for _, dep := range dependencies {
+		response.Dependencies = append(response.Dependencies, PackageBuild{
+			Name:    dep.Name,
+			Epoch:   int(dep.Epoch),
+			Version: dep.Version,
+			Release: dep.Release,
+			Arch:    dep.Arch,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
+// archRepositories returns the architecture `archName` of distribution
+// `distroName` and the repositories which are configured for it, without
+// the ones of specific image types. If there are none, an error is written
+// to `w` and false is returned.
+func (server *Server) archRepositories(w http.ResponseWriter, distroName, archName string) (distro.Arch, []rpmmd.RepoConfig, bool) {
+	d := server.distros.GetDistro(distroName)
+	if d == nil {
+		httpError(w, fmt.Sprintf("Unknown distribution: %s", distroName), http.StatusNotFound)
+		return nil, nil, false
+	}
+	arch, err := d.GetArch(archName)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Unknown architecture '%s' for distribution '%s'", archName, distroName), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	var repos []rpmmd.RepoConfig
+	if server.repos != nil {
+		repos, _ = server.repos.ReposByArch(arch, false)
+	}
+	if len(repos) == 0 {
+		httpError(w, fmt.Sprintf("No repositories are configured for %s/%s", distroName, archName), http.StatusNotFound)
+		return nil, nil, false
+	}
+	return arch, repos, true
+}
+
+// availablePackages returns the packages in `repos`. dnf-json caches their
+// metadata, so that it is only downloaded when it changed. If they can't be
+// listed, an error is written to `w` and false is returned.
+func (server *Server) availablePackages(w http.ResponseWriter, arch distro.Arch, repos []rpmmd.RepoConfig) (rpmmd.PackageList, bool) {
+	packages, _, err := server.rpmMetadata.FetchMetadata(repos, arch.Distro().ModulePlatformID(), arch.Name())
+	if err != nil {
+		httpError(w, fmt.Sprintf("Cannot fetch the packages of %s/%s: %v", arch.Distro().Name(), arch.Name(), err), http.StatusInternalServerError)
+		return nil, false
+	}
+	return packages, true
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/provenance"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// Identifies composer as the builder of images in provenance statements
+const builderID = "https://github.com/osbuild/osbuild-composer"
+
+// Parameters of an image in its provenance statement
+type provenanceParameters struct {
+	Distribution string `json:"distribution"`
+	Architecture string `json:"architecture"`
+	ImageType    string `json:"image_type"`
+}
+
+// ComposeProvenance handles a /compose/{id}/provenance GET request
+func (server *Server) ComposeProvenance(w http.ResponseWriter, r *http.Request, id string) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(jobId, &rawArgs)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if jobType == "compose" {
+		image, ok := composeImage(w, id, rawArgs, deps, "request the provenance of")
+		if !ok {
+			return
+		}
+		// the compose of an image which is uploaded to several targets
+		server.ComposeProvenance(w, r, image.String())
+		return
+	}
+	if !strings.HasPrefix(jobType, "osbuild:") {
+		httpError(w, fmt.Sprintf("Job %s does not build an image", id), http.StatusBadRequest)
+		return
+	}
+
+	var result worker.OSBuildJobResult
+	status, _, err := server.workers.JobStatus(jobId, &result)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if status.Finished.IsZero() || result.OSBuildOutput == nil || !result.OSBuildOutput.Success {
+		httpError(w, fmt.Sprintf("Compose %s has no provenance, its image has not been built", id), http.StatusNotFound)
+		return
+	}
+
+	build := provenance.Build{
+		BuilderID: builderID,
+		Started:   status.Started,
+		Finished:  status.Finished,
+	}
+	for _, f := range result.ImageFiles {
+		build.Files = append(build.Files, provenance.File{Name: f.Name, SHA256: f.SHA256})
+	}
+
+	// composes which were enqueued with their manifest don't have the
+	// jobs which generated it
+	if len(deps) > 0 {
+		var manifestJob worker.ManifestJobByID
+		_, _, manifestDeps, err := server.workers.Job(deps[0], &manifestJob)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error reading manifest job of compose %s: %s", id, err), http.StatusInternalServerError)
+			return
+		}
+		build.Distribution = manifestJob.Distribution
+		build.Parameters = provenanceParameters{
+			Distribution: manifestJob.Distribution,
+			Architecture: manifestJob.Arch,
+			ImageType:    manifestJob.ImageType,
+		}
+
+		if len(manifestDeps) > 0 {
+			var depsolveResult worker.DepsolveJobResult
+			_, _, err = server.workers.JobStatus(manifestDeps[0], &depsolveResult)
+			if err != nil {
+				httpError(w, fmt.Sprintf("Error reading packages of compose %s: %s", id, err), http.StatusInternalServerError)
+				return
+			}
+			// the build root is not part of the image
+			for name, packages := range depsolveResult.PackageSpecs {
+				if name != "build" {
+					build.Packages = append(build.Packages, packages...)
+				}
+			}
+		}
+	}
+
+	body, err := json.Marshal(build.Statement())
+	if err != nil {
+		panic("Failed to write response: " + err.Error())
+	}
+	server.writeSigned(w, "application/json; charset=utf-8", body)
+}
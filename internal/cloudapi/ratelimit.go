@@ -0,0 +1,41 @@
+package cloudapi
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/osbuild/osbuild-composer/internal/ratelimit"
+)
+
+// SetRateLimit makes the API reject requests of clients which exceed the
+// rate limit of `limiter` with 429 Too Many Requests. Must be called before
+// Handler().
+func (server *Server) SetRateLimit(limiter *ratelimit.Limiter) {
+	server.rateLimit = limiter
+}
+
+// limitRate limits the rate of requests of each client. Clients are
+// identified like in the audit log, or by their address if they didn't
+// authenticate.
+func (server *Server) limitRate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, wait := server.rateLimit.Allow(rateLimitKey(r))
+		if !allowed {
+			w.Header().Set("Retry-After", ratelimit.RetryAfter(wait))
+			httpError(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func rateLimitKey(r *http.Request) string {
+	if actor := requestActor(r); actor != "" {
+		return "client:" + actor
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
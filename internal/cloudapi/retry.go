@@ -0,0 +1,173 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/audit"
+	"github.com/osbuild/osbuild-composer/internal/logging"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+)
+
+// ComposeRetry handles a /compose/{id}/retry POST request. It enqueues the
+// osbuild jobs of the images of a failed compose again, with the manifests
+// they were given, followed by their upload jobs and a compose job if the
+// compose had one. The new compose has an id of its own. Composes whose
+// uploads had credentials of the client cannot be retried, because those are
+// gone.
+func (server *Server) ComposeRetry(w http.ResponseWriter, r *http.Request, id string) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(jobId, &rawArgs)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if jobType != "compose" && !strings.HasPrefix(jobType, "osbuild:") {
+		httpError(w, fmt.Sprintf("Compose %s cannot be retried", id), http.StatusBadRequest)
+		return
+	}
+
+	status, err := server.composeStatus(jobId, jobType, rawArgs, deps)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if status.ImageStatus.Status != ImageStatusValue_failure {
+		httpError(w, fmt.Sprintf("Compose %s has not failed, it cannot be retried", id), http.StatusBadRequest)
+		return
+	}
+
+	images := []uuid.UUID{jobId}
+	var composeJob worker.ComposeJob
+	if jobType == "compose" {
+		if err := json.Unmarshal(rawArgs, &composeJob); err != nil {
+			httpError(w, fmt.Sprintf("Error reading compose %s: %s", id, err), http.StatusInternalServerError)
+			return
+		}
+		images = composeImages(&composeJob, deps)
+	}
+
+	// images whose manifest wasn't generated have to be depsolved again,
+	// which only a new compose request does
+	tenants := make([]string, len(images))
+	for i, image := range images {
+		var job worker.OSBuildJob
+		_, _, imageDeps, err := server.workers.Job(image, &job)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error reading image %s of compose %s: %s", image, id, err), http.StatusInternalServerError)
+			return
+		}
+		manifest, err := server.imageManifest(&job, imageDeps)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error getting manifest of image %s of compose %s: %s", image, id, err), http.StatusInternalServerError)
+			return
+		}
+		if manifest == nil {
+			httpError(w, fmt.Sprintf("The manifest of image %s of compose %s was not generated, request the compose again", image, id), http.StatusBadRequest)
+			return
+		}
+		tenants[i] = job.Tenant
+		if job.ClientCredentials {
+			credentialsError(w, id)
+			return
+		}
+	}
+
+	// the upload jobs of each image, in the order of the images
+	var uploads [][]worker.UploadJob
+	if composeJob.Uploads != nil {
+		uploads = make([][]worker.UploadJob, len(images))
+		for i := range images {
+			for _, upload := range composeJob.Uploads[i] {
+				var uploadJob worker.UploadJob
+				_, _, _, err := server.workers.Job(upload, &uploadJob)
+				if err != nil {
+					httpError(w, fmt.Sprintf("Error reading upload %s of compose %s: %s", upload, id, err), http.StatusInternalServerError)
+					return
+				}
+				if uploadJob.ClientCredentials {
+					credentialsError(w, id)
+					return
+				}
+				uploads[i] = append(uploads[i], uploadJob)
+			}
+		}
+	}
+
+	var jobIDs, imageIDs []uuid.UUID
+	var uploadIDs [][]uuid.UUID
+	if composeJob.Uploads != nil {
+		uploadIDs = make([][]uuid.UUID, len(images))
+	}
+	for i, image := range images {
+		// the quota of the tenant which requested the compose applies,
+		// also when an admin retries it
+		imageID, err := server.workers.RetryOSBuild(r.Context(), image, tenants[i])
+		if err != nil {
+			server.cancelJobs(jobIDs)
+			if err == worker.ErrQuotaExceeded {
+				httpError(w, "Too many composes are running for this organization", http.StatusTooManyRequests)
+			} else {
+				httpError(w, "Failed to enqueue image", http.StatusInternalServerError)
+			}
+			return
+		}
+		jobIDs = append(jobIDs, imageID)
+		imageIDs = append(imageIDs, imageID)
+
+		if uploads == nil {
+			continue
+		}
+		for j := range uploads[i] {
+			uploadID, err := server.workers.EnqueueUpload(r.Context(), &uploads[i][j], imageID)
+			if err != nil {
+				server.cancelJobs(jobIDs)
+				httpError(w, "Failed to enqueue upload", http.StatusInternalServerError)
+				return
+			}
+			jobIDs = append(jobIDs, uploadID)
+			uploadIDs[i] = append(uploadIDs[i], uploadID)
+		}
+	}
+
+	retryID := imageIDs[0]
+	if jobType == "compose" {
+		retryID, err = server.workers.EnqueueCompose(r.Context(), &worker.ComposeJob{
+			Uploads: uploadIDs,
+			Tenant:  composeJob.Tenant,
+		}, imageIDs)
+		if err != nil {
+			server.cancelJobs(jobIDs)
+			httpError(w, "Failed to enqueue compose", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var response ComposeResult
+	response.Id = retryID.String()
+	audit.SetObject(r.Context(), response.Id)
+	logging.Default().With(logging.ComposeID, retryID, logging.Tenant, tenants[0]).Infof("Compose %s retried", id)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
+// credentialsError reports that the compose `id` cannot be retried, because it
+// was requested with credentials for its upload targets, which composer
+// doesn't keep.
+func credentialsError(w http.ResponseWriter, id string) {
+	httpError(w, fmt.Sprintf("Compose %s was requested with credentials for its uploads, which are not kept, request the compose again", id), http.StatusBadRequest)
+}
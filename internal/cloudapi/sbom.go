@@ -0,0 +1,80 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/sbom"
+)
+
+// ComposeSbom handles a /compose/{id}/sbom GET request
+func (server *Server) ComposeSbom(w http.ResponseWriter, r *http.Request, id string, params ComposeSbomParams) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var artifact, contentType string
+	format := "spdx"
+	if params.Format != nil {
+		format = *params.Format
+	}
+	switch format {
+	case "spdx":
+		artifact, contentType = sbom.SPDXArtifact, "application/spdx+json"
+	case "cyclonedx":
+		artifact, contentType = sbom.CycloneDXArtifact, "application/vnd.cyclonedx+json"
+	default:
+		httpError(w, fmt.Sprintf("Unknown SBOM format: %s", format), http.StatusBadRequest)
+		return
+	}
+
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(jobId, &rawArgs)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if jobType == "compose" {
+		image, ok := composeImage(w, id, rawArgs, deps, "request the SBOM of")
+		if !ok {
+			return
+		}
+		// the compose of an image which is uploaded to several targets
+		server.ComposeSbom(w, r, image.String(), params)
+		return
+	}
+	if !strings.HasPrefix(jobType, "osbuild:") {
+		httpError(w, fmt.Sprintf("Job %s does not build an image", id), http.StatusBadRequest)
+		return
+	}
+
+	// the SBOMs are artifacts of the manifest job the osbuild job depends on
+	if len(deps) == 0 {
+		httpError(w, fmt.Sprintf("Compose %s has no SBOM", id), http.StatusNotFound)
+		return
+	}
+	reader, _, err := server.workers.JobArtifact(deps[0], artifact)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Compose %s has no SBOM: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	// SBOMs are small enough to be signed in memory
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Error reading SBOM of compose %s: %s", id, err), http.StatusInternalServerError)
+		return
+	}
+	server.writeSigned(w, contentType, body)
+}
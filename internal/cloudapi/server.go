@@ -7,23 +7,40 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"math"
 	"math/big"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
 
+	"github.com/osbuild/osbuild-composer/internal/audit"
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/cloudapi/v2"
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/distroregistry"
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+	"github.com/osbuild/osbuild-composer/internal/logging"
+	"github.com/osbuild/osbuild-composer/internal/oidc"
 	"github.com/osbuild/osbuild-composer/internal/osbuild1"
 	"github.com/osbuild/osbuild-composer/internal/ostree"
+	"github.com/osbuild/osbuild-composer/internal/ratelimit"
+	"github.com/osbuild/osbuild-composer/internal/rbac"
+	"github.com/osbuild/osbuild-composer/internal/reporegistry"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/signing"
 	"github.com/osbuild/osbuild-composer/internal/target"
+	"github.com/osbuild/osbuild-composer/internal/tracing"
+	"github.com/osbuild/osbuild-composer/internal/validation"
 	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
 )
 
 // Server represents the state of the cloud Server
@@ -31,62 +48,149 @@ type Server struct {
 	workers        *worker.Server
 	rpmMetadata    rpmmd.RPMMD
 	distros        *distroregistry.Registry
+	repos          *reporegistry.RepoRegistry
 	identityFilter []string
+	tokenValidator *oidc.Validator
+	policy         *rbac.Policy
+	audit          *audit.Log
+	tracer         *tracing.Tracer
+	rateLimit      *ratelimit.Limiter
+	idempotency    *idempotencyKeys
+
+	// Signs manifests, SBOMs, and provenance statements, if set
+	signer *signing.Signer
 }
 
 type contextKey int
 
-// NewServer creates a new cloud server
-func NewServer(workers *worker.Server, rpmMetadata rpmmd.RPMMD, distros *distroregistry.Registry) *Server {
+const (
+	identityHeaderKey contextKey = iota
+	tokenClaimsKey
+	idempotencyKeyKey
+)
+
+type identityHeader struct {
+	Identity struct {
+		AccountNumber string `json:"account_number"`
+		Internal      struct {
+			OrgId string `json:"org_id"`
+		} `json:"internal"`
+	} `json:"identity"`
+}
+
+// NewServer creates a new cloud server. `signer` may be nil, in which case
+// responses are not signed.
+func NewServer(workers *worker.Server, rpmMetadata rpmmd.RPMMD, distros *distroregistry.Registry, signer *signing.Signer) *Server {
 	server := &Server{
 		workers:     workers,
 		rpmMetadata: rpmMetadata,
 		distros:     distros,
+		signer:      signer,
+		idempotency: newIdempotencyKeys(),
 	}
 	return server
 }
 
-// Create an http.Handler() for this server, that provides the composer API at
-// the given path.
-func (server *Server) Handler(path string, identityFilter []string) http.Handler {
+// SetTracer makes the API record a span for each request, which is the
+// parent of the spans of the jobs it enqueues. Must be called before
+// Handler().
+func (server *Server) SetTracer(tracer *tracing.Tracer) {
+	server.tracer = tracer
+}
+
+// Create an http.Handler() for this server, that provides version 1 of the
+// composer API at the given path. If `validator` is set, clients may
+// authenticate with bearer tokens. Clients without token need an identity
+// header, if there is an identity filter. Clients may only read and change
+// the composes of their own tenant, unless `policy` grants them roles for
+// more. If `policy` is set, clients also need the roles it grants them for
+// composing.
+func (server *Server) Handler(path string, identityFilter []string, validator *oidc.Validator, policy *rbac.Policy) http.Handler {
+	return deprecated(server.handler(path, identityFilter, validator, policy, func(r chi.Router) {
+		HandlerFromMux(server, r)
+	}))
+}
+
+// HandlerV2 is like Handler(), but provides version 2 of the composer API.
+func (server *Server) HandlerV2(path string, identityFilter []string, validator *oidc.Validator, policy *rbac.Policy) http.Handler {
+	return server.handler(path, identityFilter, validator, policy, func(r chi.Router) {
+		v2.HandlerFromMux(v2Handlers{server}, r)
+	})
+}
+
+// handler returns the http.Handler of the API at `path`, whose routes are
+// added by `routes`.
+func (server *Server) handler(path string, identityFilter []string, validator *oidc.Validator, policy *rbac.Policy, routes func(r chi.Router)) http.Handler {
 	r := chi.NewRouter()
+	r.Use(server.tracer.Middleware)
 
+	if validator != nil {
+		server.tokenValidator = validator
+		r.Use(server.VerifyBearerToken)
+	}
 	if len(identityFilter) > 0 {
 		server.identityFilter = identityFilter
 		r.Use(server.VerifyIdentityHeader)
 	}
-	r.Route(path, func(r chi.Router) {
-		HandlerFromMux(server, r)
+	if server.rateLimit != nil {
+		r.Use(server.limitRate)
+	}
+	// record denied requests as well
+	r.Use(func(next http.Handler) http.Handler {
+		return server.audit.Handler(next, auditEvent(path))
 	})
+	if policy != nil {
+		server.policy = policy
+	}
+	r.Use(server.authorize(path))
+	r.Use(server.idempotent(path))
+	r.Route(path, routes)
 
 	return r
 }
 
+func (server *Server) VerifyBearerToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := server.tokenValidator.ValidateRequest(r)
+		if err == oidc.ErrNoToken && len(server.identityFilter) > 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err != nil {
+			log.Printf("Rejecting bearer token: %v", err)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			httpError(w, "Bearer token is missing or not valid", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenClaimsKey, claims)))
+	})
+}
+
 func (server *Server) VerifyIdentityHeader(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		const identityHeaderKey contextKey = iota
-		type identityHeader struct {
-			Identity struct {
-				AccountNumber string `json:"account_number"`
-			} `json:"identity"`
+		// authenticated with a bearer token
+		if r.Context().Value(tokenClaimsKey) != nil {
+			next.ServeHTTP(w, r)
+			return
 		}
 
 		idHeaderB64 := r.Header["X-Rh-Identity"]
 		if len(idHeaderB64) != 1 {
-			http.Error(w, "Auth header is not present", http.StatusNotFound)
+			httpError(w, "Auth header is not present", http.StatusNotFound)
 			return
 		}
 
 		b64Result, err := base64.StdEncoding.DecodeString(idHeaderB64[0])
 		if err != nil {
-			http.Error(w, "Auth header has incorrect format", http.StatusNotFound)
+			httpError(w, "Auth header has incorrect format", http.StatusNotFound)
 			return
 		}
 
 		var idHeader identityHeader
 		err = json.Unmarshal([]byte(strings.TrimSuffix(fmt.Sprintf("%s", b64Result), "\n")), &idHeader)
 		if err != nil {
-			http.Error(w, "Auth header has incorrect format", http.StatusNotFound)
+			httpError(w, "Auth header has incorrect format", http.StatusNotFound)
 			return
 		}
 
@@ -96,52 +200,74 @@ func (server *Server) VerifyIdentityHeader(next http.Handler) http.Handler {
 				return
 			}
 		}
-		http.Error(w, "Account not allowed", http.StatusNotFound)
+		httpError(w, "Account not allowed", http.StatusNotFound)
 	})
 }
 
-// Compose handles a new /compose POST request
+// Compose handles a new /compose POST request of version 1 of the API
 func (server *Server) Compose(w http.ResponseWriter, r *http.Request) {
-	contentType := r.Header["Content-Type"]
-	if len(contentType) != 1 || contentType[0] != "application/json" {
-		http.Error(w, "Only 'application/json' content type is supported", http.StatusUnsupportedMediaType)
-		return
-	}
-
 	var request ComposeRequest
-	err := json.NewDecoder(r.Body).Decode(&request)
-	if err != nil {
-		http.Error(w, "Could not parse JSON body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &request) {
 		return
 	}
+	server.compose(w, r, composeRequestV2(request))
+}
 
-	distribution := server.distros.GetDistro(request.Distribution)
+// compose starts the compose `request` of any version of the API, which is
+// passed in the form of version 2.
+func (server *Server) compose(w http.ResponseWriter, r *http.Request, request v2.ComposeRequest) {
+	distribution := server.distros.GetDistro(request.Distro)
 	if distribution == nil {
-		http.Error(w, fmt.Sprintf("Unsupported distribution: %s", request.Distribution), http.StatusBadRequest)
+		httpError(w, fmt.Sprintf("Unsupported distribution: %s", request.Distro), http.StatusBadRequest)
 		return
 	}
 
-	var bp = blueprint.Blueprint{}
-	err = bp.Initialize()
+	eus := request.Eus != nil && *request.Eus
+	releasever, err := composeReleasever(distribution, request.MinorRelease, eus)
 	if err != nil {
-		http.Error(w, "Unable to initialize blueprint", http.StatusInternalServerError)
+		httpError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if request.Customizations != nil && request.Customizations.Packages != nil {
-		for _, p := range *request.Customizations.Packages {
-			bp.Packages = append(bp.Packages, blueprint.Package{
-				Name: p,
-			})
+
+	strict := false
+	if request.ContentVerification != nil {
+		switch *request.ContentVerification {
+		case v2.ContentVerification__default:
+		case v2.ContentVerification_strict:
+			strict = true
+		default:
+			httpError(w, fmt.Sprintf("Unsupported content verification: %s", *request.ContentVerification), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if request.Callback != nil {
+		if err := checkCallback(request.Callback); err != nil {
+			httpError(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 	}
 
+	bp, err := composeBlueprint(request.Customizations)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	type imageRequest struct {
-		manifest distro.Manifest
-		arch     string
-		exports  []string
+		depsolveJob worker.DepsolveJob
+		manifestJob worker.ManifestJobByID
+		arch        string
+		imageType   string
+		exports     []string
+		filename    string
+		size        uint64
+		keepImage   bool
+		targets     []*target.Target
+		// access keys of the targets, which are not stored with the jobs
+		credentials []*worker.TargetCredentials
 	}
 	imageRequests := make([]imageRequest, len(request.ImageRequests))
-	var targets []*target.Target
 
 	// use the same seed for all images so we get the same IDs
 	bigSeed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
@@ -153,50 +279,48 @@ func (server *Server) Compose(w http.ResponseWriter, r *http.Request) {
 	for i, ir := range request.ImageRequests {
 		arch, err := distribution.GetArch(ir.Architecture)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Unsupported architecture '%s' for distribution '%s'", ir.Architecture, request.Distribution), http.StatusBadRequest)
+			httpError(w, fmt.Sprintf("Unsupported architecture '%s' for distribution '%s'", ir.Architecture, request.Distro), http.StatusBadRequest)
 			return
 		}
 		imageType, err := arch.GetImageType(ir.ImageType)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Unsupported image type '%s' for %s/%s", ir.ImageType, ir.Architecture, request.Distribution), http.StatusBadRequest)
+			httpError(w, fmt.Sprintf("Unsupported image type '%s' for %s/%s", ir.ImageType, ir.Architecture, request.Distro), http.StatusBadRequest)
 			return
 		}
-		repositories := make([]rpmmd.RepoConfig, len(ir.Repositories))
-		for j, repo := range ir.Repositories {
-			repositories[j].RHSM = repo.Rhsm
-
-			if repo.Baseurl != nil {
-				repositories[j].BaseURL = *repo.Baseurl
-			} else if repo.Mirrorlist != nil {
-				repositories[j].MirrorList = *repo.Mirrorlist
-			} else if repo.Metalink != nil {
-				repositories[j].Metalink = *repo.Metalink
-			} else {
-				http.Error(w, "Must specify baseurl, mirrorlist, or metalink", http.StatusBadRequest)
+		if err := server.workers.CheckArch("osbuild", arch.Name()); err != nil {
+			httpError(w, fmt.Sprintf("No worker is available to build images for architecture '%s'", arch.Name()), http.StatusBadRequest)
+			return
+		}
+		repositories, payloadRepositories, err := imageRequestRepositories(ir, releasever, eus)
+		if err != nil {
+			httpError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if strict {
+			// the worker enforces this as well, but check the keys here
+			// to reject the request right away
+			repositories, err = rpmmd.EnforceGPGCheck(repositories)
+			if err == nil {
+				payloadRepositories, err = rpmmd.EnforceGPGCheck(payloadRepositories)
+			}
+			if err != nil {
+				httpError(w, fmt.Sprintf("Strict content verification requires GPG keys: %s", err), http.StatusBadRequest)
 				return
 			}
 		}
 
+		// payload repositories and excludes only apply to the packages
+		// of the image, not to its build root
 		packageSets := imageType.PackageSets(bp)
-		pkgSpecSets := make(map[string][]rpmmd.PackageSpec)
-		for name, packages := range packageSets {
-			pkgs, _, err := server.rpmMetadata.Depsolve(packages, repositories, distribution.ModulePlatformID(), arch.Name())
-			if err != nil {
-				http.Error(w, fmt.Sprintf("Failed to depsolve base packages for %s/%s/%s: %s", ir.ImageType, ir.Architecture, request.Distribution, err), http.StatusInternalServerError)
-				return
-			}
-			pkgSpecSets[name] = pkgs
+		payload := rpmmd.PackageSet{Repositories: payloadRepositories}
+		if ir.ExcludePackages != nil {
+			payload.Exclude = *ir.ExcludePackages
 		}
+		packageSets["packages"] = packageSets["packages"].Append(payload)
 
-		imageOptions := distro.ImageOptions{Size: imageType.Size(0)}
-		if request.Customizations != nil && request.Customizations.Subscription != nil {
-			imageOptions.Subscription = &distro.SubscriptionImageOptions{
-				Organization:  request.Customizations.Subscription.Organization,
-				ActivationKey: request.Customizations.Subscription.ActivationKey,
-				ServerUrl:     request.Customizations.Subscription.ServerUrl,
-				BaseUrl:       request.Customizations.Subscription.BaseUrl,
-				Insights:      request.Customizations.Subscription.Insights,
-			}
+		imageOptions := distro.ImageOptions{
+			Size:         imageType.Size(0),
+			Subscription: subscriptionImageOptions(request.Customizations),
 		}
 
 		// set default ostree ref, if one not provided
@@ -204,187 +328,167 @@ func (server *Server) Compose(w http.ResponseWriter, r *http.Request) {
 		if ostreeOptions == nil || ostreeOptions.Ref == nil {
 			imageOptions.OSTree = distro.OSTreeImageOptions{Ref: imageType.OSTreeRef()}
 		} else if !ostree.VerifyRef(*ostreeOptions.Ref) {
-			http.Error(w, fmt.Sprintf("Invalid OSTree ref: %s", *ostreeOptions.Ref), http.StatusBadRequest)
+			httpError(w, fmt.Sprintf("Invalid OSTree ref: %s", *ostreeOptions.Ref), http.StatusBadRequest)
 			return
 		} else {
 			imageOptions.OSTree = distro.OSTreeImageOptions{Ref: *ostreeOptions.Ref}
 		}
 
-		var parent string
+		// the parent commit is resolved from the URL by the manifest job
 		if ostreeOptions != nil && ostreeOptions.Url != nil {
+			if ostreeOptions.Parent != nil {
+				httpError(w, "Supplying both an OSTree parent commit and URL is not supported", http.StatusBadRequest)
+				return
+			}
 			imageOptions.OSTree.URL = *ostreeOptions.Url
-			parent, err = ostree.ResolveRef(imageOptions.OSTree.URL, imageOptions.OSTree.Ref)
-			if err != nil {
-				http.Error(w, fmt.Sprintf("Error resolving OSTree repo %s: %s", imageOptions.OSTree.URL, err), http.StatusBadRequest)
+		} else if ostreeOptions != nil && ostreeOptions.Parent != nil {
+			if !ostree.VerifyChecksum(*ostreeOptions.Parent) {
+				httpError(w, fmt.Sprintf("Invalid OSTree parent commit: %s", *ostreeOptions.Parent), http.StatusBadRequest)
 				return
 			}
-			imageOptions.OSTree.Parent = parent
+			imageOptions.OSTree.Parent = *ostreeOptions.Parent
 		}
 
-		manifest, err := imageType.Manifest(nil, imageOptions, repositories, pkgSpecSets, manifestSeed)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to get manifest for for %s/%s/%s: %s", ir.ImageType, ir.Architecture, request.Distribution, err), http.StatusBadRequest)
-			return
-		}
+		imageRequests[i].depsolveJob = worker.DepsolveJob{
+			PackageSets:      packageSets,
+			Repos:            repositories,
+			ModulePlatformID: distribution.ModulePlatformID(),
+			Arch:             arch.Name(),
 
-		imageRequests[i].manifest = manifest
+			StrictContentVerification: strict,
+		}
+		imageRequests[i].manifestJob = worker.ManifestJobByID{
+			Distribution: distribution.Name(),
+			Arch:         arch.Name(),
+			ImageType:    imageType.Name(),
+			Blueprint:    bp,
+			Repositories: append(repositories, payloadRepositories...),
+			ImageOptions: imageOptions,
+			Seed:         manifestSeed,
+		}
 		imageRequests[i].arch = arch.Name()
+		imageRequests[i].imageType = imageType.Name()
 		imageRequests[i].exports = imageType.Exports()
+		imageRequests[i].size = imageOptions.Size
 
-		uploadRequest := ir.UploadRequest
-		/* oneOf is not supported by the openapi generator so marshal and unmarshal the uploadrequest based on the type */
-		if uploadRequest.Type == UploadTypes_aws {
-			var awsUploadOptions AWSUploadRequestOptions
-			jsonUploadOptions, err := json.Marshal(uploadRequest.Options)
-			if err != nil {
-				http.Error(w, "Unable to marshal aws upload request", http.StatusInternalServerError)
-				return
-			}
-			err = json.Unmarshal(jsonUploadOptions, &awsUploadOptions)
-			if err != nil {
-				http.Error(w, "Unable to unmarshal aws upload request", http.StatusInternalServerError)
-				return
-			}
-
-			var share []string
-			if awsUploadOptions.Ec2.ShareWithAccounts != nil {
-				share = *awsUploadOptions.Ec2.ShareWithAccounts
-			}
-			key := fmt.Sprintf("composer-api-%s", uuid.New().String())
-			t := target.NewAWSTarget(&target.AWSTargetOptions{
-				Filename:          imageType.Filename(),
-				Region:            awsUploadOptions.Region,
-				AccessKeyID:       awsUploadOptions.S3.AccessKeyId,
-				SecretAccessKey:   awsUploadOptions.S3.SecretAccessKey,
-				Bucket:            awsUploadOptions.S3.Bucket,
-				Key:               key,
-				ShareWithAccounts: share,
-			})
-			if awsUploadOptions.Ec2.SnapshotName != nil {
-				t.ImageName = *awsUploadOptions.Ec2.SnapshotName
-			} else {
-				t.ImageName = key
-			}
-
-			targets = append(targets, t)
-		} else if uploadRequest.Type == UploadTypes_aws_s3 {
-			var awsS3UploadOptions AWSS3UploadRequestOptions
-			jsonUploadOptions, err := json.Marshal(uploadRequest.Options)
-			if err != nil {
-				http.Error(w, "Unable to marshal aws upload request", http.StatusInternalServerError)
-				return
+		if len(ir.UploadRequests) == 0 {
+			httpError(w, fmt.Sprintf("No upload request for image type '%s' of architecture '%s'", ir.ImageType, ir.Architecture), http.StatusBadRequest)
+			return
+		}
+		for _, ur := range ir.UploadRequests {
+			if ur.Type == v2.UploadTypes_local {
+				// the image is kept in composer, which already
+				// receives it from the worker
+				if !server.workers.ArtifactsEnabled() {
+					httpError(w, "Local upload requests are not supported, because composer does not keep artifacts", http.StatusBadRequest)
+					return
+				}
+				imageRequests[i].keepImage = true
+				continue
 			}
-			err = json.Unmarshal(jsonUploadOptions, &awsS3UploadOptions)
+			t, err := targetFromUploadRequest(ur, imageType.Filename())
 			if err != nil {
-				http.Error(w, "Unable to unmarshal aws upload request", http.StatusInternalServerError)
+				httpError(w, err.Error(), http.StatusBadRequest)
 				return
 			}
+			imageRequests[i].targets = append(imageRequests[i].targets, t)
+			imageRequests[i].credentials = append(imageRequests[i].credentials, worker.RedactCredentials(t))
+		}
+		imageRequests[i].filename = imageType.Filename()
+	}
 
-			key := fmt.Sprintf("composer-api-%s", uuid.New().String())
-			t := target.NewAWSS3Target(&target.AWSS3TargetOptions{
-				Filename:        imageType.Filename(),
-				Region:          awsS3UploadOptions.Region,
-				AccessKeyID:     awsS3UploadOptions.S3.AccessKeyId,
-				SecretAccessKey: awsS3UploadOptions.S3.SecretAccessKey,
-				Bucket:          awsS3UploadOptions.S3.Bucket,
-				Key:             key,
-			})
-			t.ImageName = key
-
-			targets = append(targets, t)
-		} else if uploadRequest.Type == UploadTypes_gcp {
-			var gcpUploadOptions GCPUploadRequestOptions
-			jsonUploadOptions, err := json.Marshal(uploadRequest.Options)
-			if err != nil {
-				http.Error(w, "Unable to marshal gcp upload request", http.StatusInternalServerError)
-				return
-			}
-			err = json.Unmarshal(jsonUploadOptions, &gcpUploadOptions)
-			if err != nil {
-				http.Error(w, "Unable to unmarshal gcp upload request", http.StatusInternalServerError)
-				return
-			}
+	if len(imageRequests) == 0 {
+		httpError(w, "At least one image request is required", http.StatusBadRequest)
+		return
+	}
 
-			var share []string
-			if gcpUploadOptions.ShareWithAccounts != nil {
-				share = *gcpUploadOptions.ShareWithAccounts
-			}
-			var region string
-			if gcpUploadOptions.Region != nil {
-				region = *gcpUploadOptions.Region
-			}
-			object := fmt.Sprintf("composer-api-%s", uuid.New().String())
-			t := target.NewGCPTarget(&target.GCPTargetOptions{
-				Filename:          imageType.Filename(),
-				Region:            region,
-				Os:                "", // not exposed in cloudapi for now
-				Bucket:            gcpUploadOptions.Bucket,
-				Object:            object,
-				ShareWithAccounts: share,
-			})
-			// Import will fail if an image with this name already exists
-			if gcpUploadOptions.ImageName != nil {
-				t.ImageName = *gcpUploadOptions.ImageName
+	// quotas are enforced per organization, if the identity is known
+	tenant := requestTenant(r)
+
+	// Each image is built and uploaded to its first target by an osbuild
+	// job, which also uploads the image to composer. Upload jobs download
+	// it from there and upload it to the other targets, or to the targets
+	// of clones of the compose.
+	var jobIDs, imageIDs []uuid.UUID
+	uploadIDs := make([][]uuid.UUID, len(imageRequests))
+	hasUploads := false
+	for i, ir := range imageRequests {
+		job := &worker.OSBuildJob{
+			ImageName: ir.filename,
+			ImageType: ir.imageType,
+			Exports:   ir.exports,
+			ImageSize: ir.size,
+			Tenant:    tenant,
+			KeepImage: ir.keepImage,
+		}
+		// images which are only kept in composer have no target
+		if len(ir.targets) > 0 {
+			job.Targets = ir.targets[:1]
+			job.Credentials = ir.credentials[:1]
+		}
+		// the osbuild job is the compose, see below
+		if len(imageRequests) == 1 && len(ir.targets) <= 1 {
+			job.Idempotency = requestIdempotencyKey(r.Context())
+		}
+		id, err := server.workers.EnqueueOSBuildAsDependency(r.Context(), ir.arch, &ir.depsolveJob, &ir.manifestJob, job, worker.PriorityBatch, tenant)
+		if err != nil {
+			// don't build a part of the compose
+			server.cancelJobs(jobIDs)
+			if err == worker.ErrQuotaExceeded {
+				httpError(w, "Too many composes are running for this organization", http.StatusTooManyRequests)
 			} else {
-				t.ImageName = object
-			}
-
-			targets = append(targets, t)
-		} else if uploadRequest.Type == UploadTypes_azure {
-			var azureUploadOptions AzureUploadRequestOptions
-			jsonUploadOptions, err := json.Marshal(uploadRequest.Options)
-			if err != nil {
-				http.Error(w, "Unable to marshal azure upload request", http.StatusInternalServerError)
-				return
+				httpError(w, "Failed to enqueue manifest", http.StatusInternalServerError)
 			}
-			err = json.Unmarshal(jsonUploadOptions, &azureUploadOptions)
+			return
+		}
+		jobIDs = append(jobIDs, id)
+		imageIDs = append(imageIDs, id)
+
+		for j := 1; j < len(ir.targets); j++ {
+			uploadID, err := server.workers.EnqueueUpload(r.Context(), &worker.UploadJob{
+				Target:      ir.targets[j],
+				ImageName:   ir.filename,
+				Credentials: ir.credentials[j],
+				Tenant:      tenant,
+			}, id)
 			if err != nil {
-				http.Error(w, "Unable to unmarshal azure upload request", http.StatusInternalServerError)
+				server.cancelJobs(jobIDs)
+				httpError(w, "Failed to enqueue upload", http.StatusInternalServerError)
 				return
 			}
-			t := target.NewAzureImageTarget(&target.AzureImageTargetOptions{
-				Filename:       imageType.Filename(),
-				TenantID:       azureUploadOptions.TenantId,
-				Location:       azureUploadOptions.Location,
-				SubscriptionID: azureUploadOptions.SubscriptionId,
-				ResourceGroup:  azureUploadOptions.ResourceGroup,
-			})
-
-			if azureUploadOptions.ImageName != nil {
-				t.ImageName = *azureUploadOptions.ImageName
-			} else {
-				// if ImageName wasn't given, generate a random one
-				t.ImageName = fmt.Sprintf("composer-api-%s", uuid.New().String())
-			}
-
-			targets = append(targets, t)
-		} else {
-			http.Error(w, "Unknown upload request type, only 'aws', 'azure' and 'gcp' are supported", http.StatusBadRequest)
-			return
+			jobIDs = append(jobIDs, uploadID)
+			uploadIDs[i] = append(uploadIDs[i], uploadID)
+			hasUploads = true
 		}
 	}
 
-	var ir imageRequest
-	if len(imageRequests) == 1 {
-		// NOTE: the store currently does not support multi-image composes
-		ir = imageRequests[0]
-	} else {
-		http.Error(w, "Only single-image composes are currently supported", http.StatusBadRequest)
-		return
+	// a single image which is uploaded to a single target is its own
+	// compose
+	id := imageIDs[0]
+	if len(imageIDs) > 1 || hasUploads {
+		composeJob := &worker.ComposeJob{
+			Tenant:      tenant,
+			Idempotency: requestIdempotencyKey(r.Context()),
+		}
+		if hasUploads {
+			composeJob.Uploads = uploadIDs
+		}
+		id, err = server.workers.EnqueueCompose(r.Context(), composeJob, imageIDs)
+		if err != nil {
+			server.cancelJobs(jobIDs)
+			httpError(w, "Failed to enqueue compose", http.StatusInternalServerError)
+			return
+		}
 	}
 
-	id, err := server.workers.EnqueueOSBuild(ir.arch, &worker.OSBuildJob{
-		Manifest: ir.manifest,
-		Targets:  targets,
-		Exports:  ir.exports,
-	})
-	if err != nil {
-		http.Error(w, "Failed to enqueue manifest", http.StatusInternalServerError)
-		return
+	if request.Callback != nil {
+		go server.watchCompose(id, *request.Callback)
 	}
 
 	var response ComposeResult
 	response.Id = id.String()
+	audit.SetObject(r.Context(), response.Id)
+	logging.Default().With(logging.ComposeID, id, logging.Tenant, tenant).Infof("Compose of %d images enqueued", len(imageRequests))
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusCreated)
 	err = json.NewEncoder(w).Encode(response)
@@ -393,78 +497,98 @@ func (server *Server) Compose(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// ComposeStatus handles a /compose/{id} GET request
-func (server *Server) ComposeStatus(w http.ResponseWriter, r *http.Request, id string) {
-	jobId, err := uuid.Parse(id)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+// ValidateBlueprint handles a /blueprints/validate POST request. It reports
+// the problems the compose request would have for each of its image requests,
+// without starting a compose.
+func (server *Server) ValidateBlueprint(w http.ResponseWriter, r *http.Request) {
+	var request ComposeRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+	server.validateBlueprint(w, r, composeRequestV2(request))
+}
+
+// validateBlueprint reports the problems of the compose `request` of any
+// version of the API, which is passed in the form of version 2.
+func (server *Server) validateBlueprint(w http.ResponseWriter, r *http.Request, request v2.ComposeRequest) {
+	distribution := server.distros.GetDistro(request.Distro)
+	if distribution == nil {
+		httpError(w, fmt.Sprintf("Unsupported distribution: %s", request.Distro), http.StatusBadRequest)
 		return
 	}
 
-	var result worker.OSBuildJobResult
-	status, _, err := server.workers.JobStatus(jobId, &result)
+	eus := request.Eus != nil && *request.Eus
+	releasever, err := composeReleasever(distribution, request.MinorRelease, eus)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		httpError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	var us *UploadStatus
-	if result.TargetResults != nil {
-		// Only single upload target is allowed, therefore only a single upload target result is allowed as well
-		if len(result.TargetResults) != 1 {
-			http.Error(w, fmt.Sprintf("Job %s returned more upload target results than allowed", id), http.StatusInternalServerError)
+	if len(request.ImageRequests) == 0 {
+		httpError(w, "At least one image request is required", http.StatusBadRequest)
+		return
+	}
+
+	// invalid customizations are reported by validation.Validate()
+	bp, _ := composeBlueprint(request.Customizations)
+
+	response := BlueprintValidation{Diagnostics: []BlueprintDiagnostic{}}
+	for _, ir := range request.ImageRequests {
+		arch, err := distribution.GetArch(ir.Architecture)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Unsupported architecture '%s' for distribution '%s'", ir.Architecture, request.Distro), http.StatusBadRequest)
+			return
+		}
+		imageType, err := arch.GetImageType(ir.ImageType)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Unsupported image type '%s' for %s/%s", ir.ImageType, ir.Architecture, request.Distro), http.StatusBadRequest)
+			return
+		}
+		repositories, payloadRepositories, err := imageRequestRepositories(ir, releasever, eus)
+		if err != nil {
+			httpError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		tr := *result.TargetResults[0]
 
-		var uploadType UploadTypes
-		var uploadOptions interface{}
+		imageOptions := distro.ImageOptions{
+			Size:         imageType.Size(0),
+			Subscription: subscriptionImageOptions(request.Customizations),
+			OSTree:       distro.OSTreeImageOptions{Ref: imageType.OSTreeRef()},
+		}
+		if ir.Ostree != nil && ir.Ostree.Ref != nil {
+			imageOptions.OSTree.Ref = *ir.Ostree.Ref
+		}
 
-		switch tr.Name {
-		case "org.osbuild.aws":
-			uploadType = UploadTypes_aws
-			awsOptions := tr.Options.(*target.AWSTargetResultOptions)
-			uploadOptions = AWSUploadStatus{
-				Ami:    awsOptions.Ami,
-				Region: awsOptions.Region,
-			}
-		case "org.osbuild.aws.s3":
-			uploadType = UploadTypes_aws_s3
-			awsOptions := tr.Options.(*target.AWSS3TargetResultOptions)
-			uploadOptions = AWSS3UploadStatus{
-				Url: awsOptions.URL,
+		diagnostics := validation.Validate(bp, validation.Target{
+			RPMMD:               server.rpmMetadata,
+			Arch:                arch,
+			ImageType:           imageType,
+			ImageOptions:        imageOptions,
+			Repositories:        repositories,
+			PayloadRepositories: payloadRepositories,
+		})
+		for _, d := range diagnostics {
+			diagnostic := BlueprintDiagnostic{
+				Severity:     string(d.Severity),
+				Message:      d.Message,
+				Architecture: arch.Name(),
+				ImageType:    imageType.Name(),
 			}
-		case "org.osbuild.gcp":
-			uploadType = UploadTypes_gcp
-			gcpOptions := tr.Options.(*target.GCPTargetResultOptions)
-			uploadOptions = GCPUploadStatus{
-				ImageName: gcpOptions.ImageName,
-				ProjectId: gcpOptions.ProjectID,
+			if d.Field != "" {
+				field := d.Field
+				diagnostic.Field = &field
 			}
-		case "org.osbuild.azure.image":
-			uploadType = UploadTypes_azure
-			gcpOptions := tr.Options.(*target.AzureImageTargetResultOptions)
-			uploadOptions = AzureUploadStatus{
-				ImageName: gcpOptions.ImageName,
-			}
-		default:
-			http.Error(w, fmt.Sprintf("Job %s returned unknown upload target results %s", id, tr.Name), http.StatusInternalServerError)
-			return
+			response.Diagnostics = append(response.Diagnostics, diagnostic)
 		}
+	}
 
-		us = &UploadStatus{
-			Status:  result.UploadStatus,
-			Type:    uploadType,
-			Options: uploadOptions,
+	response.Valid = true
+	for _, d := range response.Diagnostics {
+		if d.Severity == string(validation.SeverityError) {
+			response.Valid = false
 		}
 	}
 
-	response := ComposeStatus{
-		ImageStatus: ImageStatus{
-			Status:       composeStatusFromJobStatus(status, &result),
-			UploadStatus: us,
-		},
-	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
@@ -472,47 +596,1078 @@ func (server *Server) ComposeStatus(w http.ResponseWriter, r *http.Request, id s
 	}
 }
 
-func composeStatusFromJobStatus(js *worker.JobStatus, result *worker.OSBuildJobResult) ImageStatusValue {
-	if js.Canceled {
-		return ImageStatusValue_failure
-	}
+// targetFromUploadRequest returns the target to upload the image file
+// `filename` to for upload request `ur`.
+func targetFromUploadRequest(ur v2.UploadRequest, filename string) (*target.Target, error) {
+	/* oneOf is not supported by the openapi generator so marshal and unmarshal the uploadrequest based on the type */
+	if ur.Type == v2.UploadTypes_aws {
+		var awsUploadOptions v2.AWSUploadRequestOptions
+		jsonUploadOptions, err := json.Marshal(ur.Options)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to marshal aws upload request")
+		}
+		err = json.Unmarshal(jsonUploadOptions, &awsUploadOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal aws upload request")
+		}
 
-	if js.Started.IsZero() {
-		return ImageStatusValue_pending
-	}
+		var share []string
+		if awsUploadOptions.Ec2.ShareWithAccounts != nil {
+			share = *awsUploadOptions.Ec2.ShareWithAccounts
+		}
+		var copyToRegions []string
+		if awsUploadOptions.Ec2.CopyToRegions != nil {
+			copyToRegions = *awsUploadOptions.Ec2.CopyToRegions
+		}
+		var tags map[string]string
+		if awsUploadOptions.Ec2.Tags != nil {
+			tags = make(map[string]string)
+			for _, tag := range *awsUploadOptions.Ec2.Tags {
+				tags[tag.Key] = tag.Value
+			}
+		}
+		s3Creds, err := awsS3Credentials(awsUploadOptions.S3)
+		if err != nil {
+			return nil, err
+		}
+		var ec2Creds *target.AWSCredentials
+		ec2 := awsUploadOptions.Ec2
+		if ec2.AccessKeyId != nil || ec2.RoleArn != nil {
+			creds := awsCredentials(ec2.AccessKeyId, ec2.SecretAccessKey, ec2.SessionToken, ec2.RoleArn, ec2.ExternalId)
+			ec2Creds = &creds
+		}
+		key := fmt.Sprintf("composer-api-%s", uuid.New().String())
+		t := target.NewAWSTarget(&target.AWSTargetOptions{
+			Filename:          filename,
+			Region:            awsUploadOptions.Region,
+			AccessKeyID:       s3Creds.AccessKeyID,
+			SecretAccessKey:   s3Creds.SecretAccessKey,
+			SessionToken:      s3Creds.SessionToken,
+			RoleARN:           s3Creds.RoleARN,
+			ExternalID:        s3Creds.ExternalID,
+			Bucket:            awsUploadOptions.S3.Bucket,
+			Key:               key,
+			ShareWithAccounts: share,
+			CopyToRegions:     copyToRegions,
+			Tags:              tags,
+			EC2:               ec2Creds,
+		})
+		if awsUploadOptions.Ec2.SnapshotName != nil {
+			t.ImageName = *awsUploadOptions.Ec2.SnapshotName
+		} else {
+			t.ImageName = key
+		}
 
-	if js.Finished.IsZero() {
-		// TODO: handle also ImageStatusValue_uploading
-		// TODO: handle also ImageStatusValue_registering
-		return ImageStatusValue_building
-	}
+		return t, nil
+	} else if ur.Type == v2.UploadTypes_aws_s3 {
+		var awsS3UploadOptions v2.AWSS3UploadRequestOptions
+		jsonUploadOptions, err := json.Marshal(ur.Options)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to marshal aws upload request")
+		}
+		err = json.Unmarshal(jsonUploadOptions, &awsS3UploadOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal aws upload request")
+		}
 
-	if result.Success {
-		return ImageStatusValue_success
+		creds, err := awsS3Credentials(awsS3UploadOptions.S3)
+		if err != nil {
+			return nil, err
+		}
+		key := fmt.Sprintf("composer-api-%s", uuid.New().String())
+		t := target.NewAWSS3Target(&target.AWSS3TargetOptions{
+			Filename:        filename,
+			Region:          awsS3UploadOptions.Region,
+			AccessKeyID:     creds.AccessKeyID,
+			SecretAccessKey: creds.SecretAccessKey,
+			SessionToken:    creds.SessionToken,
+			RoleARN:         creds.RoleARN,
+			ExternalID:      creds.ExternalID,
+			Bucket:          awsS3UploadOptions.S3.Bucket,
+			Key:             key,
+		})
+		t.ImageName = key
+
+		return t, nil
+	} else if ur.Type == v2.UploadTypes_generic_s3 {
+		var genericS3UploadOptions v2.GenericS3UploadRequestOptions
+		jsonUploadOptions, err := json.Marshal(ur.Options)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to marshal generic.s3 upload request")
+		}
+		err = json.Unmarshal(jsonUploadOptions, &genericS3UploadOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal generic.s3 upload request")
+		}
+
+		creds, err := awsS3Credentials(genericS3UploadOptions.S3)
+		if err != nil {
+			return nil, err
+		}
+		if creds.RoleARN != "" {
+			return nil, fmt.Errorf("Roles are not supported for generic.s3 uploads")
+		}
+
+		var caBundle string
+		if genericS3UploadOptions.CaBundle != nil {
+			caBundle = *genericS3UploadOptions.CaBundle
+		}
+		var skipSSLVerification bool
+		if genericS3UploadOptions.SkipSslVerification != nil {
+			skipSSLVerification = *genericS3UploadOptions.SkipSslVerification
+		}
+
+		key := fmt.Sprintf("composer-api-%s", uuid.New().String())
+		t := target.NewGenericS3Target(&target.GenericS3TargetOptions{
+			AWSS3TargetOptions: target.AWSS3TargetOptions{
+				Filename:        filename,
+				Region:          genericS3UploadOptions.Region,
+				AccessKeyID:     creds.AccessKeyID,
+				SecretAccessKey: creds.SecretAccessKey,
+				SessionToken:    creds.SessionToken,
+				Bucket:          genericS3UploadOptions.S3.Bucket,
+				Key:             key,
+			},
+			Endpoint:            genericS3UploadOptions.Endpoint,
+			CABundle:            caBundle,
+			SkipSSLVerification: skipSSLVerification,
+		})
+		t.ImageName = key
+
+		return t, nil
+	} else if ur.Type == v2.UploadTypes_gcp {
+		var gcpUploadOptions v2.GCPUploadRequestOptions
+		jsonUploadOptions, err := json.Marshal(ur.Options)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to marshal gcp upload request")
+		}
+		err = json.Unmarshal(jsonUploadOptions, &gcpUploadOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal gcp upload request")
+		}
+
+		var share []string
+		if gcpUploadOptions.ShareWithAccounts != nil {
+			share = *gcpUploadOptions.ShareWithAccounts
+		}
+		var shareProjects []string
+		if gcpUploadOptions.ShareWithProjects != nil {
+			shareProjects = *gcpUploadOptions.ShareWithProjects
+		}
+		var guestOSFeatures []string
+		if gcpUploadOptions.GuestOsFeatures != nil {
+			guestOSFeatures = *gcpUploadOptions.GuestOsFeatures
+		}
+		var region string
+		if gcpUploadOptions.Region != nil {
+			region = *gcpUploadOptions.Region
+		}
+		object := fmt.Sprintf("composer-api-%s", uuid.New().String())
+		t := target.NewGCPTarget(&target.GCPTargetOptions{
+			Filename:          filename,
+			Region:            region,
+			Os:                "", // not exposed in cloudapi for now
+			Bucket:            gcpUploadOptions.Bucket,
+			Object:            object,
+			ShareWithAccounts: share,
+			ShareWithProjects: shareProjects,
+			GuestOSFeatures:   guestOSFeatures,
+		})
+		// Import will fail if an image with this name already exists
+		if gcpUploadOptions.ImageName != nil {
+			t.ImageName = *gcpUploadOptions.ImageName
+		} else {
+			t.ImageName = object
+		}
+
+		return t, nil
+	} else if ur.Type == v2.UploadTypes_azure {
+		var azureUploadOptions v2.AzureUploadRequestOptions
+		jsonUploadOptions, err := json.Marshal(ur.Options)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to marshal azure upload request")
+		}
+		err = json.Unmarshal(jsonUploadOptions, &azureUploadOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal azure upload request")
+		}
+		t := target.NewAzureImageTarget(&target.AzureImageTargetOptions{
+			Filename:       filename,
+			TenantID:       azureUploadOptions.TenantId,
+			Location:       azureUploadOptions.Location,
+			SubscriptionID: azureUploadOptions.SubscriptionId,
+			ResourceGroup:  azureUploadOptions.ResourceGroup,
+		})
+
+		if azureUploadOptions.ImageName != nil {
+			t.ImageName = *azureUploadOptions.ImageName
+		} else {
+			// if ImageName wasn't given, generate a random one
+			t.ImageName = fmt.Sprintf("composer-api-%s", uuid.New().String())
+		}
+
+		return t, nil
+	} else if ur.Type == v2.UploadTypes_oci {
+		var ociUploadOptions v2.OCIUploadRequestOptions
+		jsonUploadOptions, err := json.Marshal(ur.Options)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to marshal oci upload request")
+		}
+		err = json.Unmarshal(jsonUploadOptions, &ociUploadOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal oci upload request")
+		}
+
+		object := fmt.Sprintf("composer-api-%s", uuid.New().String())
+		t := target.NewOCITarget(&target.OCITargetOptions{
+			Filename:    filename,
+			Region:      ociUploadOptions.Region,
+			Compartment: ociUploadOptions.Compartment,
+			Namespace:   ociUploadOptions.Namespace,
+			Bucket:      ociUploadOptions.Bucket,
+			Object:      object,
+		})
+		if ociUploadOptions.ImageName != nil {
+			t.ImageName = *ociUploadOptions.ImageName
+		} else {
+			t.ImageName = object
+		}
+
+		return t, nil
+	} else if ur.Type == v2.UploadTypes_local {
+		return nil, fmt.Errorf("Images can only be kept in composer by the compose which builds them")
+	} else {
+		return nil, fmt.Errorf("Unknown upload request type, only 'aws', 'aws.s3', 'generic.s3', 'azure', 'gcp', 'oci' and 'local' are supported")
 	}
+}
 
-	return ImageStatusValue_failure
+// cancelJobs cancels the image builds and uploads of a compose which cannot
+// be enqueued as a whole.
+// awsS3Credentials returns the credentials of the s3 options of an upload
+// request, which must contain either access keys or a role.
+func awsS3Credentials(s3 v2.AWSUploadRequestOptionsS3) (target.AWSCredentials, error) {
+	if s3.AccessKeyId == nil && s3.RoleArn == nil {
+		return target.AWSCredentials{}, fmt.Errorf("Either access keys or a role are required for uploads to S3")
+	}
+	return awsCredentials(s3.AccessKeyId, s3.SecretAccessKey, s3.SessionToken, s3.RoleArn, s3.ExternalId), nil
 }
 
-// GetOpenapiJson handles a /openapi.json GET request
-func (server *Server) GetOpenapiJson(w http.ResponseWriter, r *http.Request) {
-	spec, err := GetSwagger()
-	if err != nil {
-		http.Error(w, "Could not load openapi spec", http.StatusInternalServerError)
-		return
+func awsCredentials(accessKeyID, secretAccessKey, sessionToken, roleARN, externalID *string) target.AWSCredentials {
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
 	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	err = json.NewEncoder(w).Encode(spec)
-	if err != nil {
-		panic("Failed to write response")
+	return target.AWSCredentials{
+		AccessKeyID:     value(accessKeyID),
+		SecretAccessKey: value(secretAccessKey),
+		SessionToken:    value(sessionToken),
+		RoleARN:         value(roleARN),
+		ExternalID:      value(externalID),
 	}
 }
 
-// GetVersion handles a /version GET request
-func (server *Server) GetVersion(w http.ResponseWriter, r *http.Request) {
-	spec, err := GetSwagger()
+func (server *Server) cancelJobs(ids []uuid.UUID) {
+	for _, id := range ids {
+		if err := server.workers.Cancel(id); err != nil {
+			logging.Default().With(logging.JobID, id).Errorf("Error canceling job: %v", err)
+		}
+	}
+}
+
+// Converts the users of a compose request to blueprint customizations.
+func userCustomizations(users []v2.User) []blueprint.UserCustomization {
+	var customizations []blueprint.UserCustomization
+	for _, u := range users {
+		c := blueprint.UserCustomization{
+			Name:       u.Name,
+			UID:        u.Uid,
+			GID:        u.Gid,
+			ExpireDate: u.ExpireDate,
+		}
+		if u.Groups != nil {
+			c.Groups = *u.Groups
+		}
+		if u.Keys != nil {
+			c.Keys = *u.Keys
+		}
+		if u.Locked != nil {
+			c.Locked = *u.Locked
+		}
+		if u.ForcePasswordReset != nil {
+			c.ForcePasswordReset = *u.ForcePasswordReset
+		}
+		customizations = append(customizations, c)
+	}
+	return customizations
+}
+
+// composeBlueprint returns the blueprint which images with `customizations`
+// are built from. The customizations are validated the same way as for
+// blueprints pushed to the weldr API.
+func composeBlueprint(customizations *v2.Customizations) (blueprint.Blueprint, error) {
+	var bp = blueprint.Blueprint{}
+	if customizations != nil && customizations.Packages != nil {
+		for _, p := range *customizations.Packages {
+			bp.Packages = append(bp.Packages, blueprint.Package{
+				Name: p,
+			})
+		}
+	}
+	if customizations != nil && customizations.Users != nil {
+		bp.Customizations = &blueprint.Customizations{
+			User: userCustomizations(*customizations.Users),
+		}
+	}
+	err := bp.Initialize()
+	return bp, err
+}
+
+// subscriptionImageOptions returns the options for registering images with
+// `customizations`, or nil if they shouldn't be registered.
+func subscriptionImageOptions(customizations *v2.Customizations) *distro.SubscriptionImageOptions {
+	if customizations == nil || customizations.Subscription == nil {
+		return nil
+	}
+	return &distro.SubscriptionImageOptions{
+		Organization:  customizations.Subscription.Organization,
+		ActivationKey: customizations.Subscription.ActivationKey,
+		ServerUrl:     customizations.Subscription.ServerUrl,
+		BaseUrl:       customizations.Subscription.BaseUrl,
+		Insights:      customizations.Subscription.Insights,
+	}
+}
+
+// imageRequestRepositories returns the repositories and the payload
+// repositories of `ir`, with $releasever replaced by `releasever` and the
+// Extended Update Support content if `eus` is set.
+func imageRequestRepositories(ir v2.ImageRequest, releasever string, eus bool) ([]rpmmd.RepoConfig, []rpmmd.RepoConfig, error) {
+	repositories, err := repoConfigs(ir.Repositories)
+	if err != nil {
+		return nil, nil, err
+	}
+	var payloadRepositories []rpmmd.RepoConfig
+	if ir.PayloadRepositories != nil {
+		payloadRepositories, err = repoConfigs(*ir.PayloadRepositories)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if releasever != "" {
+		repositories = rpmmd.ExpandReleasever(repositories, releasever)
+		payloadRepositories = rpmmd.ExpandReleasever(payloadRepositories, releasever)
+	}
+	if eus {
+		repositories = eusRepositories(repositories)
+		payloadRepositories = eusRepositories(payloadRepositories)
+	}
+	return repositories, payloadRepositories, nil
+}
+
+var modulePlatformRegexp = regexp.MustCompile(`^platform:(el|f)([0-9]+)$`)
+var minorReleaseRegexp = regexp.MustCompile(`^([0-9]+)\.[0-9]+$`)
+
+// composeReleasever returns the release which replaces $releasever in the
+// repositories of `d`: the minor release `minorRelease` if it is set, or the
+// major release, which is taken from the module platform ID. It returns an
+// empty string for distributions without numbered releases, whose
+// repositories are left alone. Minor releases and Extended Update Support can
+// only be requested for RHEL.
+func composeReleasever(d distro.Distro, minorRelease *string, eus bool) (string, error) {
+	var major string
+	match := modulePlatformRegexp.FindStringSubmatch(d.ModulePlatformID())
+	if match != nil {
+		major = match[2]
+	}
+	isRHEL := strings.HasPrefix(d.Name(), "rhel-") && match != nil && match[1] == "el"
+
+	if minorRelease == nil {
+		if eus {
+			return "", errors.New("Extended Update Support requires a minor release")
+		}
+		return major, nil
+	}
+
+	if !isRHEL {
+		return "", fmt.Errorf("Minor releases cannot be requested for distribution %s", d.Name())
+	}
+	if minor := minorReleaseRegexp.FindStringSubmatch(*minorRelease); minor == nil || minor[1] != major {
+		return "", fmt.Errorf("%s is not a minor release of %s", *minorRelease, d.Name())
+	}
+	return *minorRelease, nil
+}
+
+// eusRepositories returns `repos` with the content/dist paths of Red Hat CDN
+// repositories replaced by content/eus, which serves the Extended Update
+// Support content of minor releases.
+func eusRepositories(repos []rpmmd.RepoConfig) []rpmmd.RepoConfig {
+	result := make([]rpmmd.RepoConfig, len(repos))
+	for i, repo := range repos {
+		if repo.RHSM {
+			repo.BaseURL = strings.Replace(repo.BaseURL, "/content/dist/", "/content/eus/", 1)
+		}
+		result[i] = repo
+	}
+	return result
+}
+
+func repoConfigs(repos []v2.Repository) ([]rpmmd.RepoConfig, error) {
+	repositories := make([]rpmmd.RepoConfig, len(repos))
+	for j, repo := range repos {
+		repositories[j].RHSM = repo.Rhsm
+		if repo.GpgKey != nil {
+			repositories[j].GPGKey = *repo.GpgKey
+		}
+		if repo.CheckGpg != nil {
+			repositories[j].CheckGPG = *repo.CheckGpg
+		}
+		if repo.ModuleHotfixes != nil {
+			repositories[j].ModuleHotfixes = *repo.ModuleHotfixes
+		}
+
+		if repo.Baseurl != nil {
+			repositories[j].BaseURL = *repo.Baseurl
+		} else if repo.Mirrorlist != nil {
+			repositories[j].MirrorList = *repo.Mirrorlist
+		} else if repo.Metalink != nil {
+			repositories[j].Metalink = *repo.Metalink
+		} else {
+			return nil, errors.New("Must specify baseurl, mirrorlist, or metalink")
+		}
+	}
+	return repositories, nil
+}
+
+// ComposeStatus handles a /compose/{id} GET request
+func (server *Server) ComposeStatus(w http.ResponseWriter, r *http.Request, id string) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(jobId, &rawArgs)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+
+	response, err := server.composeStatus(jobId, jobType, rawArgs, deps)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
+// DeleteCompose handles a /compose/{id} DELETE request
+func (server *Server) DeleteCompose(w http.ResponseWriter, r *http.Request, id string) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(jobId, &rawArgs)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+
+	// the images of a compose, and the jobs uploading them to more
+	// targets, might have artifacts
+	jobIDs := []uuid.UUID{jobId}
+	if jobType == "compose" {
+		var composeJob worker.ComposeJob
+		if err := json.Unmarshal(rawArgs, &composeJob); err != nil {
+			httpError(w, fmt.Sprintf("Error reading compose %s: %s", id, err), http.StatusInternalServerError)
+			return
+		}
+		jobIDs = append(jobIDs, composeImages(&composeJob, deps)...)
+		for _, uploads := range composeJob.Uploads {
+			jobIDs = append(jobIDs, uploads...)
+		}
+	}
+
+	// don't delete anything if a part of the compose is still running
+	var finished []uuid.UUID
+	for _, id := range jobIDs {
+		status, _, err := server.workers.JobStatus(id, &json.RawMessage{})
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error getting status of job %s: %s", id, err), http.StatusInternalServerError)
+			return
+		}
+		if status.Canceled {
+			// canceled jobs don't keep artifacts
+			continue
+		}
+		if status.Finished.IsZero() {
+			httpError(w, fmt.Sprintf("Compose %s has not finished", jobId), http.StatusBadRequest)
+			return
+		}
+		finished = append(finished, id)
+	}
+
+	for _, id := range finished {
+		err = server.workers.DeleteArtifacts(id)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error deleting the artifacts of job %s: %s", id, err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListComposes handles a /composes GET request of version 1 of the API, which
+// lists all composes of the tenant
+func (server *Server) ListComposes(w http.ResponseWriter, r *http.Request) {
+	server.listComposes(w, r, v2.ListComposesParams{})
+}
+
+func (server *Server) listComposes(w http.ResponseWriter, r *http.Request, params v2.ListComposesParams) {
+	status := ""
+	if params.Status != nil {
+		status = *params.Status
+		if status != "finished" && status != "unfinished" {
+			httpError(w, fmt.Sprintf("Invalid status %s, must be finished or unfinished", status), http.StatusBadRequest)
+			return
+		}
+	}
+	offset := 0
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+	if offset < 0 {
+		httpError(w, "Offset must not be negative", http.StatusBadRequest)
+		return
+	}
+	limit := 0
+	if params.Limit != nil {
+		limit = *params.Limit
+		if limit < 1 {
+			httpError(w, "Limit must be positive", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// composes are either jobs grouping several images, or the osbuild
+	// job of their only image
+	jobTypes := []string{"compose"}
+	seen := make(map[string]bool)
+	for _, name := range server.distros.List() {
+		for _, arch := range server.distros.GetDistro(name).ListArches() {
+			if !seen[arch] {
+				seen[arch] = true
+				jobTypes = append(jobTypes, "osbuild:"+arch)
+			}
+		}
+	}
+
+	// the images of composes with several images are left out
+	tenant := requestTenant(r)
+	filter := jobqueue.JobFilter{
+		Types:            jobTypes,
+		Tenant:           &tenant,
+		SkipDependencies: true,
+		Offset:           offset,
+		Limit:            limit,
+	}
+	switch status {
+	case "finished":
+		filter.States = []jobqueue.JobState{jobqueue.JobFinished, jobqueue.JobCanceled}
+	case "unfinished":
+		filter.States = []jobqueue.JobState{jobqueue.JobPending, jobqueue.JobRunning}
+	}
+	if params.Since != nil {
+		filter.Since = *params.Since
+	}
+	if params.Until != nil {
+		filter.Until = *params.Until
+	}
+
+	ids, _, err := server.workers.ListJobs(filter)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Error listing composes: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := []ComposeListItem{}
+	for _, id := range ids {
+		var rawArgs json.RawMessage
+		jobType, _, deps, err := server.workers.Job(id, &rawArgs)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error reading compose %s: %s", id, err), http.StatusInternalServerError)
+			return
+		}
+		jobStatus, _, err := server.workers.JobStatus(id, &json.RawMessage{})
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error getting status of compose %s: %s", id, err), http.StatusInternalServerError)
+			return
+		}
+		composeStatus, err := server.composeStatus(id, jobType, rawArgs, deps)
+		if err != nil {
+			httpError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response = append(response, ComposeListItem{
+			Id:        id.String(),
+			Status:    composeStatus.ImageStatus.Status,
+			CreatedAt: jobStatus.Queued,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
+// composeStatus returns the status of the compose `id`, whose job has type
+// `jobType`, arguments `rawArgs`, and dependencies `deps`.
+func (server *Server) composeStatus(id uuid.UUID, jobType string, rawArgs json.RawMessage, deps []uuid.UUID) (*ComposeStatus, error) {
+	if jobType != "compose" {
+		imageStatus, err := server.imageStatus(id, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &ComposeStatus{ImageStatus: *imageStatus}, nil
+	}
+
+	var composeJob worker.ComposeJob
+	if err := json.Unmarshal(rawArgs, &composeJob); err != nil {
+		return nil, fmt.Errorf("Error reading compose %s: %s", id, err)
+	}
+
+	images := composeImages(&composeJob, deps)
+	imageStatuses := make([]ImageStatus, 0, len(images))
+	for i, image := range images {
+		var uploadIDs []uuid.UUID
+		if composeJob.Uploads != nil {
+			uploadIDs = composeJob.Uploads[i]
+		}
+		imageStatus, err := server.imageStatus(image, uploadIDs)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting status of image %s of compose %s: %s", image, id, err)
+		}
+		imageID := image.String()
+		imageStatus.Id = &imageID
+		imageStatuses = append(imageStatuses, *imageStatus)
+	}
+
+	if len(imageStatuses) == 1 {
+		// a compose of a single image which is uploaded to several
+		// targets
+		status := &ComposeStatus{ImageStatus: imageStatuses[0]}
+		status.ImageStatus.Id = nil
+		return status, nil
+	}
+
+	status := &ComposeStatus{
+		ImageStatus:   ImageStatus{Status: composeStatusFromImageStatuses(imageStatuses)},
+		ImageStatuses: &imageStatuses,
+	}
+	if status.ImageStatus.Status == ImageStatusValue_failure {
+		for _, s := range imageStatuses {
+			if s.Error != nil {
+				status.ImageStatus.Error = s.Error
+				status.ImageStatus.JobError = s.JobError
+				break
+			}
+		}
+	}
+	return status, nil
+}
+
+// composeImages returns the osbuild jobs of the images of the compose `job`,
+// which depends on `deps`.
+func composeImages(job *worker.ComposeJob, deps []uuid.UUID) []uuid.UUID {
+	if job.Uploads == nil {
+		return deps
+	}
+	return deps[:len(job.Uploads)]
+}
+
+// composeImage returns the only image of the compose `id`, whose job has
+// the arguments `rawArgs` and depends on `deps`. If the compose doesn't have
+// exactly one image, it replies with an error and returns false. The error
+// for composes of several images tells the client to `action` each image by
+// its id instead, such as "download".
+func composeImage(w http.ResponseWriter, id string, rawArgs json.RawMessage, deps []uuid.UUID, action string) (uuid.UUID, bool) {
+	var composeJob worker.ComposeJob
+	if err := json.Unmarshal(rawArgs, &composeJob); err != nil {
+		httpError(w, fmt.Sprintf("Error reading compose %s: %s", id, err), http.StatusInternalServerError)
+		return uuid.Nil, false
+	}
+
+	images := composeImages(&composeJob, deps)
+	if len(images) == 0 {
+		httpError(w, fmt.Sprintf("Compose %s has no image", id), http.StatusNotFound)
+		return uuid.Nil, false
+	}
+	if len(images) > 1 {
+		httpError(w, fmt.Sprintf("Compose %s has more than one image, %s each image by its id", id, action), http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return images[0], true
+}
+
+// imageStatus returns the status of the image built by the osbuild job `id`,
+// including the status of its upload. An image which is uploaded to more
+// targets by the upload jobs `uploadIDs` has the status of all its uploads.
+func (server *Server) imageStatus(id uuid.UUID, uploadIDs []uuid.UUID) (*ImageStatus, error) {
+	var result worker.OSBuildJobResult
+	status, _, err := server.workers.JobStatus(id, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	var us *UploadStatus
+	if result.TargetResults != nil {
+		// Only single upload target is allowed, therefore only a single upload target result is allowed as well
+		if len(result.TargetResults) != 1 {
+			return nil, fmt.Errorf("job %s returned more upload target results than allowed", id)
+		}
+		us, err = uploadStatusFromTargetResult(result.TargetResults[0], result.UploadStatus)
+		if err != nil {
+			return nil, fmt.Errorf("job %s returned %s", id, err)
+		}
+	}
+
+	imageStatus := &ImageStatus{
+		Status:       composeStatusFromJobStatus(status, &result),
+		UploadStatus: us,
+	}
+	if imageStatus.Status == ImageStatusValue_failure {
+		jobErr := worker.OSBuildJobError(status, &result)
+		imageStatus.Error = &jobErr.Reason
+		imageStatus.JobError = jobError(jobErr)
+	}
+	switch imageStatus.Status {
+	case ImageStatusValue_building:
+		imageStatus.Progress = imageProgress(server.workers.JobProgress(id))
+	case ImageStatusValue_failure:
+		if result.OSBuildOutput != nil && !result.OSBuildOutput.Success {
+			imageStatus.Progress = imageProgress(result.Progress)
+		}
+	}
+
+	var job worker.OSBuildJob
+	_, _, _, err = server.workers.Job(id, &job)
+	if err != nil {
+		return nil, err
+	}
+	if len(uploadIDs) == 0 && !job.KeepImage {
+		return imageStatus, nil
+	}
+
+	// the osbuild job uploads the image to the first target, if there is
+	// one, and to composer, which keeps it if it was requested
+	var uploadStatuses []UploadStatus
+	if len(job.Targets) > 0 {
+		if us == nil {
+			us = &UploadStatus{
+				Status: uploadStatusFromJobStatus(status, result.UploadStatus),
+				Type:   uploadTypes[job.Targets[0].Name],
+			}
+		}
+		uploadStatuses = append(uploadStatuses, *us)
+	}
+	if job.KeepImage {
+		// the image is in composer as soon as it has been built
+		kept := ""
+		if result.OSBuildOutput != nil && result.OSBuildOutput.Success {
+			kept = "success"
+		}
+		uploadStatuses = append(uploadStatuses, UploadStatus{
+			Status: uploadStatusFromJobStatus(status, kept),
+			Type:   UploadTypes(v2.UploadTypes_local),
+		})
+	}
+	if len(uploadStatuses) == 1 && len(uploadIDs) == 0 {
+		// an image which is only kept in composer
+		imageStatus.UploadStatus = &uploadStatuses[0]
+		return imageStatus, nil
+	}
+
+	uploading := false
+	var failed *UploadStatus
+	var failedID uuid.UUID
+	for _, uploadID := range uploadIDs {
+		uploadStatus, err := server.uploadStatus(uploadID)
+		if err != nil {
+			return nil, err
+		}
+		uploadStatuses = append(uploadStatuses, *uploadStatus)
+
+		switch uploadStatus.Status {
+		case "pending", "running":
+			uploading = true
+		case "failure":
+			if failed == nil {
+				failed = uploadStatus
+				failedID = uploadID
+			}
+		}
+	}
+	imageStatus.UploadStatuses = &uploadStatuses
+
+	// the image is uploaded to the other targets once it has been built
+	if imageStatus.Status == ImageStatusValue_success {
+		if failed != nil {
+			imageStatus.Status = ImageStatusValue_failure
+			reason := fmt.Sprintf("Uploading the image to %s failed", failed.Type)
+			imageStatus.Error = &reason
+			jobErr, err := server.uploadJobError(failedID, reason)
+			if err != nil {
+				return nil, err
+			}
+			imageStatus.JobError = jobError(jobErr)
+		} else if uploading {
+			imageStatus.Status = ImageStatusValue_uploading
+		}
+	}
+
+	return imageStatus, nil
+}
+
+// uploadStatus returns the status of the upload job `id`.
+func (server *Server) uploadStatus(id uuid.UUID) (*UploadStatus, error) {
+	var result worker.UploadJobResult
+	status, _, err := server.workers.JobStatus(id, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.TargetResults) == 1 {
+		uploadStatus, err := uploadStatusFromTargetResult(result.TargetResults[0], result.UploadStatus)
+		if err != nil {
+			return nil, fmt.Errorf("job %s returned %s", id, err)
+		}
+		return uploadStatus, nil
+	}
+
+	var job worker.UploadJob
+	_, _, _, err = server.workers.Job(id, &job)
+	if err != nil {
+		return nil, err
+	}
+	return &UploadStatus{
+		Status: uploadStatusFromJobStatus(status, result.UploadStatus),
+		Type:   uploadTypes[job.Target.Name],
+	}, nil
+}
+
+// uploadJobError returns why the upload job `id` failed. Results of older
+// workers, which don't say why, fail with `reason`.
+func (server *Server) uploadJobError(id uuid.UUID, reason string) (*clienterrors.Error, error) {
+	var result worker.UploadJobResult
+	status, _, err := server.workers.JobStatus(id, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case status.Canceled:
+		return clienterrors.New(clienterrors.ErrorCanceled, "The compose was canceled", nil), nil
+	case result.JobError != nil:
+		return result.JobError, nil
+	default:
+		return clienterrors.New(clienterrors.ErrorUploadFailed, reason, nil), nil
+	}
+}
+
+// Upload types of the targets the cloud API uploads to
+var uploadTypes = map[string]UploadTypes{
+	"org.osbuild.aws":         UploadTypes_aws,
+	"org.osbuild.aws.s3":      UploadTypes_aws_s3,
+	"org.osbuild.generic.s3":  UploadTypes_generic_s3,
+	"org.osbuild.gcp":         UploadTypes_gcp,
+	"org.osbuild.azure.image": UploadTypes_azure,
+	"org.osbuild.oci":         UploadTypes_oci,
+}
+
+// uploadStatusFromTargetResult returns the status of an upload which
+// finished with result `tr` and status `status`.
+func uploadStatusFromTargetResult(tr *target.TargetResult, status string) (*UploadStatus, error) {
+	var uploadOptions interface{}
+
+	switch tr.Name {
+	case "org.osbuild.aws":
+		awsOptions := tr.Options.(*target.AWSTargetResultOptions)
+		awsStatus := AWSUploadStatus{
+			Ami:    awsOptions.Ami,
+			Region: awsOptions.Region,
+		}
+		if len(awsOptions.Copies) > 0 {
+			amis := []AWSAmi{{Ami: awsOptions.Ami, Region: awsOptions.Region}}
+			for _, c := range awsOptions.Copies {
+				amis = append(amis, AWSAmi{Ami: c.Ami, Region: c.Region})
+			}
+			awsStatus.Amis = &amis
+		}
+		uploadOptions = awsStatus
+	case "org.osbuild.aws.s3":
+		awsOptions := tr.Options.(*target.AWSS3TargetResultOptions)
+		uploadOptions = AWSS3UploadStatus{
+			Url: awsOptions.URL,
+		}
+	case "org.osbuild.generic.s3":
+		s3Options := tr.Options.(*target.GenericS3TargetResultOptions)
+		uploadOptions = GenericS3UploadStatus{
+			Url: s3Options.URL,
+		}
+	case "org.osbuild.gcp":
+		gcpOptions := tr.Options.(*target.GCPTargetResultOptions)
+		uploadOptions = GCPUploadStatus{
+			ImageName: gcpOptions.ImageName,
+			ProjectId: gcpOptions.ProjectID,
+		}
+	case "org.osbuild.azure.image":
+		gcpOptions := tr.Options.(*target.AzureImageTargetResultOptions)
+		uploadOptions = AzureUploadStatus{
+			ImageName: gcpOptions.ImageName,
+		}
+	case "org.osbuild.oci":
+		ociOptions := tr.Options.(*target.OCITargetResultOptions)
+		uploadOptions = OCIUploadStatus{
+			ImageId: ociOptions.ImageID,
+			Region:  ociOptions.Region,
+		}
+	default:
+		return nil, fmt.Errorf("unknown upload target results %s", tr.Name)
+	}
+
+	return &UploadStatus{
+		Status:  status,
+		Type:    uploadTypes[tr.Name],
+		Options: &uploadOptions,
+	}, nil
+}
+
+// uploadStatusFromJobStatus returns the status of an upload by a job with
+// status `js`, which reported `status` when it finished.
+func uploadStatusFromJobStatus(js *worker.JobStatus, status string) string {
+	switch {
+	case js.Canceled:
+		return "failure"
+	case js.Started.IsZero():
+		return "pending"
+	case js.Finished.IsZero():
+		return "running"
+	case status == "":
+		return "failure"
+	default:
+		return status
+	}
+}
+
+// composeStatusFromImageStatuses returns the status of a compose with several
+// images: it failed as soon as any of its images failed, and succeeded once
+// all of them did. It is pending until any of the images is being built.
+func composeStatusFromImageStatuses(statuses []ImageStatus) ImageStatusValue {
+	pending, success := true, true
+	for _, s := range statuses {
+		if s.Status == ImageStatusValue_failure {
+			return ImageStatusValue_failure
+		}
+		if s.Status != ImageStatusValue_pending {
+			pending = false
+		}
+		if s.Status != ImageStatusValue_success {
+			success = false
+		}
+	}
+
+	switch {
+	case success:
+		return ImageStatusValue_success
+	case pending:
+		return ImageStatusValue_pending
+	default:
+		return ImageStatusValue_building
+	}
+}
+
+// imageProgress returns the progress of an image which osbuild reported as
+// `progress`, or nil if osbuild hasn't reported any.
+func imageProgress(progress *worker.JobProgress) *ImageProgress {
+	if progress == nil {
+		return nil
+	}
+	p := &ImageProgress{
+		Stage:   progress.Stage,
+		Current: progress.Current,
+		Total:   progress.Total,
+		Percent: progress.Percent,
+	}
+	if progress.Pipeline != "" {
+		p.Pipeline = &progress.Pipeline
+	}
+	return p
+}
+
+func composeStatusFromJobStatus(js *worker.JobStatus, result *worker.OSBuildJobResult) ImageStatusValue {
+	if js.Canceled {
+		return ImageStatusValue_failure
+	}
+
+	if js.Started.IsZero() {
+		return ImageStatusValue_pending
+	}
+
+	if js.Finished.IsZero() {
+		// TODO: handle also ImageStatusValue_uploading
+		// TODO: handle also ImageStatusValue_registering
+		return ImageStatusValue_building
+	}
+
+	if result.Success {
+		return ImageStatusValue_success
+	}
+
+	return ImageStatusValue_failure
+}
+
+// GetOpenapiJson handles a /openapi.json GET request
+func (server *Server) GetOpenapiJson(w http.ResponseWriter, r *http.Request) {
+	writeSpec(w, GetSwagger)
+}
+
+// GetVersion handles a /version GET request
+func (server *Server) GetVersion(w http.ResponseWriter, r *http.Request) {
+	writeVersion(w, GetSwagger)
+}
+
+// writeSpec writes the OpenAPI specification returned by `getSwagger`.
+func writeSpec(w http.ResponseWriter, getSwagger func() (*openapi3.Swagger, error)) {
+	spec, err := getSwagger()
+	if err != nil {
+		httpError(w, "Could not load openapi spec", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(spec)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
+// writeVersion writes the version of the OpenAPI specification returned by
+// `getSwagger`.
+func writeVersion(w http.ResponseWriter, getSwagger func() (*openapi3.Swagger, error)) {
+	spec, err := getSwagger()
 	if err != nil {
-		http.Error(w, "Could not load version", http.StatusInternalServerError)
+		httpError(w, "Could not load version", http.StatusInternalServerError)
 		return
 	}
 	version := Version{spec.Info.Version}
@@ -523,24 +1678,95 @@ func (server *Server) GetVersion(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ListDistros handles a /distros GET request
+func (server *Server) ListDistros(w http.ResponseWriter, r *http.Request) {
+	distributions := []Distribution{}
+	for _, name := range server.distros.List() {
+		d := server.distros.GetDistro(name)
+		distributions = append(distributions, Distribution{
+			Name:          d.Name(),
+			Architectures: d.ListArches(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err := json.NewEncoder(w).Encode(distributions)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
+// ListDistroImageTypes handles a /distros/{distro}/image-types GET request
+func (server *Server) ListDistroImageTypes(w http.ResponseWriter, r *http.Request, distroName string) {
+	d := server.distros.GetDistro(distroName)
+	if d == nil {
+		httpError(w, fmt.Sprintf("Unknown distribution: %s", distroName), http.StatusNotFound)
+		return
+	}
+
+	response := DistributionImageTypes{
+		Distribution:  d.Name(),
+		Architectures: []ArchitectureImageTypes{},
+	}
+	for _, archName := range d.ListArches() {
+		arch, err := d.GetArch(archName)
+		if err != nil {
+			panic("distro lists an architecture it doesn't have, this is a programming error")
+		}
+
+		imageTypes := []ImageTypeInfo{}
+		for _, name := range arch.ListImageTypes() {
+			imageType, err := arch.GetImageType(name)
+			if err != nil {
+				panic("architecture lists an image type it doesn't have, this is a programming error")
+			}
+			imageTypes = append(imageTypes, ImageTypeInfo{
+				Name:        imageType.Name(),
+				DefaultSize: int64(imageType.Size(0)),
+			})
+		}
+
+		response.Architectures = append(response.Architectures, ArchitectureImageTypes{
+			Architecture: archName,
+			ImageTypes:   imageTypes,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
 // ComposeMetadata handles a /compose/{id}/metadata GET request
 func (server *Server) ComposeMetadata(w http.ResponseWriter, r *http.Request, id string) {
 	jobId, err := uuid.Parse(id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
 		return
 	}
 
 	var result worker.OSBuildJobResult
 	status, _, err := server.workers.JobStatus(jobId, &result)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
 		return
 	}
 
 	var job worker.OSBuildJob
-	if _, _, _, err = server.workers.Job(jobId, &job); err != nil {
-		http.Error(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+	jobType, rawArgs, deps, err := server.workers.Job(jobId, &job)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+	if jobType == "compose" {
+		image, ok := composeImage(w, id, rawArgs, deps, "request the metadata of")
+		if !ok {
+			return
+		}
+		// the compose of an image which is uploaded to several targets
+		server.ComposeMetadata(w, r, image.String())
 		return
 	}
 
@@ -552,6 +1778,25 @@ func (server *Server) ComposeMetadata(w http.ResponseWriter, r *http.Request, id
 		return
 	}
 
+	job.Manifest, err = server.imageManifest(&job, deps)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Error getting manifest of job %s: %s", id, err), http.StatusInternalServerError)
+		return
+	}
+	if job.Manifest == nil {
+		// the build never ran: empty response
+		if err := json.NewEncoder(w).Encode(new(ComposeMetadata)); err != nil {
+			panic("Failed to write response: " + err.Error())
+		}
+		return
+	}
+
+	depsolved, strict, err := server.depsolvedPackages(deps)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Error getting packages of job %s: %s", id, err), http.StatusInternalServerError)
+		return
+	}
+
 	manifestVer, err := job.Manifest.Version()
 	if err != nil {
 		panic("Failed to parse manifest version: " + err.Error())
@@ -593,6 +1838,7 @@ func (server *Server) ComposeMetadata(w http.ResponseWriter, r *http.Request, id
 	rpms = rpmmd.OSBuildStagesToRPMs(coreStages)
 
 	packages := make([]PackageMetadata, len(rpms))
+	verified := 0
 	for idx, rpm := range rpms {
 		packages[idx] = PackageMetadata{
 			Type:      rpm.Type,
@@ -604,10 +1850,42 @@ func (server *Server) ComposeMetadata(w http.ResponseWriter, r *http.Request, id
 			Sigmd5:    rpm.Sigmd5,
 			Signature: rpm.Signature,
 		}
+		if pkg, ok := depsolved[fmt.Sprintf("%s-%s-%s.%s", rpm.Name, rpm.Version, rpm.Release, rpm.Arch)]; ok {
+			packages[idx].Checksum = &pkg.checksum
+			if pkg.repository != "" {
+				packages[idx].Repository = &pkg.repository
+			}
+			if pkg.checkGPG {
+				verified++
+			}
+		}
 	}
 
 	resp := new(ComposeMetadata)
 	resp.Packages = &packages
+	bootMode, err := manifestBootMode(job.Manifest)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Error reading manifest of job %s: %s", id, err), http.StatusInternalServerError)
+		return
+	}
+	resp.BootMode = &bootMode
+	if depsolved != nil {
+		resp.ContentVerification = &ContentVerificationResult{
+			Mode:               ContentVerification__default,
+			VerifiedPackages:   verified,
+			UnverifiedPackages: len(rpms) - verified,
+		}
+		if strict {
+			resp.ContentVerification.Mode = ContentVerification_strict
+		}
+	}
+	for _, rpm := range rpms {
+		if rpm.Name == "redhat-release" || rpm.Name == "centos-release" {
+			minorRelease := rpm.Version
+			resp.MinorRelease = &minorRelease
+			break
+		}
+	}
 
 	if ostreeCommitResult != nil && ostreeCommitResult.Metadata != nil {
 		commitMetadata, ok := ostreeCommitResult.Metadata.(*osbuild1.OSTreeCommitStageMetadata)
@@ -617,7 +1895,345 @@ func (server *Server) ComposeMetadata(w http.ResponseWriter, r *http.Request, id
 		resp.OstreeCommit = &commitMetadata.Compose.OSTreeCommit
 	}
 
+	if server.workers.ArtifactsEnabled() {
+		artifacts, err := server.workers.JobArtifacts(jobId)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error getting artifacts of job %s: %s", id, err), http.StatusInternalServerError)
+			return
+		}
+		resp.Artifacts = &[]Artifact{}
+		for _, a := range artifacts {
+			artifact := Artifact{Name: a.Name, Size: a.Size}
+			if a.SHA256 != "" {
+				sha256 := a.SHA256
+				artifact.Sha256 = &sha256
+			}
+			*resp.Artifacts = append(*resp.Artifacts, artifact)
+		}
+	}
+
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		panic("Failed to write response: " + err.Error())
 	}
 }
+
+// ComposeManifests handles a /compose/{id}/manifests GET request
+func (server *Server) ComposeManifests(w http.ResponseWriter, r *http.Request, id string) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(jobId, &rawArgs)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+
+	images := []uuid.UUID{jobId}
+	if jobType == "compose" {
+		var composeJob worker.ComposeJob
+		if err := json.Unmarshal(rawArgs, &composeJob); err != nil {
+			httpError(w, fmt.Sprintf("Error reading compose %s: %s", id, err), http.StatusInternalServerError)
+			return
+		}
+		images = composeImages(&composeJob, deps)
+	} else if !strings.HasPrefix(jobType, "osbuild:") {
+		httpError(w, fmt.Sprintf("Job %s does not build an image", id), http.StatusBadRequest)
+		return
+	}
+
+	response := ComposeManifests{
+		Manifests: make([]map[string]interface{}, 0, len(images)),
+	}
+	for _, image := range images {
+		var job worker.OSBuildJob
+		_, _, imageDeps, err := server.workers.Job(image, &job)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error reading image %s of compose %s: %s", image, id, err), http.StatusInternalServerError)
+			return
+		}
+		manifest, err := server.imageManifest(&job, imageDeps)
+		if err != nil {
+			httpError(w, fmt.Sprintf("Error getting manifest of image %s of compose %s: %s", image, id, err), http.StatusInternalServerError)
+			return
+		}
+
+		var m map[string]interface{}
+		if manifest != nil {
+			if err := json.Unmarshal(manifest, &m); err != nil {
+				httpError(w, fmt.Sprintf("Error reading manifest of image %s of compose %s: %s", image, id, err), http.StatusInternalServerError)
+				return
+			}
+		}
+		response.Manifests = append(response.Manifests, m)
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		panic("Failed to write response: " + err.Error())
+	}
+	server.writeSigned(w, "application/json; charset=utf-8", body)
+}
+
+// imageManifest returns the manifest of the osbuild job `job`, which depends
+// on `deps`. Returns nil if the manifest hasn't been generated yet, or
+// generating it failed.
+func (server *Server) imageManifest(job *worker.OSBuildJob, deps []uuid.UUID) (distro.Manifest, error) {
+	if len(job.Manifest) > 0 || len(deps) == 0 {
+		return job.Manifest, nil
+	}
+
+	// the manifest was generated by the job's dependency
+	var manifestResult worker.ManifestJobByIDResult
+	_, _, err := server.workers.JobStatus(deps[0], &manifestResult)
+	if err != nil {
+		return nil, err
+	}
+	if manifestResult.Error != "" || len(manifestResult.Manifest) == 0 {
+		return nil, nil
+	}
+	return manifestResult.Manifest, nil
+}
+
+// A package which was depsolved for an image
+type depsolvedPackage struct {
+	checksum string
+	// URL of the repository the package was resolved from
+	repository string
+	// whether osbuild verifies the signature of the package
+	checkGPG bool
+}
+
+// depsolvedPackages returns the packages which were depsolved for the
+// osbuild job which depends on `deps`, by name-version-release.arch, and
+// whether they were depsolved with strict content verification. Returns nil
+// for jobs which were enqueued with their manifest.
+func (server *Server) depsolvedPackages(deps []uuid.UUID) (map[string]depsolvedPackage, bool, error) {
+	if len(deps) == 0 {
+		return nil, false, nil
+	}
+
+	// the manifest job depends on the depsolve job
+	_, _, manifestDeps, err := server.workers.Job(deps[0], &json.RawMessage{})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(manifestDeps) == 0 {
+		return nil, false, nil
+	}
+
+	var depsolveJob worker.DepsolveJob
+	_, _, _, err = server.workers.Job(manifestDeps[0], &depsolveJob)
+	if err != nil {
+		return nil, false, err
+	}
+	var depsolveResult worker.DepsolveJobResult
+	_, _, err = server.workers.JobStatus(manifestDeps[0], &depsolveResult)
+	if err != nil {
+		return nil, false, err
+	}
+
+	packages := make(map[string]depsolvedPackage)
+	for name, specs := range depsolveResult.PackageSpecs {
+		// each package set is depsolved with the common repositories,
+		// followed by its own
+		repos := append(append([]rpmmd.RepoConfig{}, depsolveJob.Repos...), depsolveJob.PackageSets[name].Repositories...)
+		for _, spec := range specs {
+			pkg := depsolvedPackage{checksum: spec.Checksum, checkGPG: spec.CheckGPG}
+			if i, err := strconv.Atoi(spec.RepoID); err == nil && i >= 0 && i < len(repos) {
+				pkg.repository = repoURL(repos[i])
+			}
+			packages[fmt.Sprintf("%s-%s-%s.%s", spec.Name, spec.Version, spec.Release, spec.Arch)] = pkg
+		}
+	}
+	return packages, depsolveJob.StrictContentVerification, nil
+}
+
+func repoURL(repo rpmmd.RepoConfig) string {
+	switch {
+	case repo.BaseURL != "":
+		return repo.BaseURL
+	case repo.Metalink != "":
+		return repo.Metalink
+	default:
+		return repo.MirrorList
+	}
+}
+
+// manifestBootMode returns how the image built from `manifest` boots,
+// judging by the boot loader stages of the manifest: "legacy" for BIOS
+// (or zipl on s390x), "uefi", "hybrid" for both, or "none".
+func manifestBootMode(manifest distro.Manifest) (BootMode, error) {
+	type stage struct {
+		Type    string `json:"type"`
+		Name    string `json:"name"`
+		Options struct {
+			Legacy string          `json:"legacy"`
+			UEFI   json.RawMessage `json:"uefi"`
+		} `json:"options"`
+	}
+	type pipeline struct {
+		Stages []stage `json:"stages"`
+	}
+	var m struct {
+		Pipelines []pipeline `json:"pipelines"`
+		// version 1 manifests have a single pipeline
+		Pipeline *pipeline `json:"pipeline"`
+	}
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return "", err
+	}
+	if m.Pipeline != nil {
+		m.Pipelines = append(m.Pipelines, *m.Pipeline)
+	}
+
+	legacy, uefi := false, false
+	for _, p := range m.Pipelines {
+		for _, s := range p.Stages {
+			stageType := s.Type
+			if stageType == "" {
+				stageType = s.Name
+			}
+			switch stageType {
+			case "org.osbuild.grub2":
+				legacy = legacy || s.Options.Legacy != ""
+				uefi = uefi || (len(s.Options.UEFI) > 0 && string(s.Options.UEFI) != "null")
+			case "org.osbuild.zipl":
+				legacy = true
+			}
+		}
+	}
+
+	switch {
+	case legacy && uefi:
+		return BootMode_hybrid, nil
+	case legacy:
+		return BootMode_legacy, nil
+	case uefi:
+		return BootMode_uefi, nil
+	default:
+		return BootMode_none, nil
+	}
+}
+
+// ComposeClone handles a /compose/{id}/clone POST request
+func (server *Server) ComposeClone(w http.ResponseWriter, r *http.Request, id string) {
+	contentType := r.Header["Content-Type"]
+	if len(contentType) != 1 || contentType[0] != "application/json" {
+		httpError(w, "Only 'application/json' content type is supported", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var request v2.UploadRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		httpError(w, "Could not parse JSON body", http.StatusBadRequest)
+		return
+	}
+
+	var rawArgs json.RawMessage
+	jobType, _, deps, err := server.workers.Job(jobId, &rawArgs)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", id, err), http.StatusNotFound)
+		return
+	}
+
+	imageID := jobId
+	if jobType == "compose" {
+		image, ok := composeImage(w, id, rawArgs, deps, "clone")
+		if !ok {
+			return
+		}
+		imageID = image
+	}
+
+	var job worker.OSBuildJob
+	jobType, _, _, err = server.workers.Job(imageID, &job)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", imageID, err), http.StatusNotFound)
+		return
+	}
+	if !strings.HasPrefix(jobType, "osbuild:") {
+		httpError(w, fmt.Sprintf("Compose %s cannot be cloned", id), http.StatusBadRequest)
+		return
+	}
+	// older composes didn't keep their image
+	if job.ImageName == "" {
+		httpError(w, fmt.Sprintf("The image of compose %s was not kept, it cannot be cloned", id), http.StatusBadRequest)
+		return
+	}
+
+	var result worker.OSBuildJobResult
+	status, _, err := server.workers.JobStatus(imageID, &result)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Job %s not found: %s", imageID, err), http.StatusNotFound)
+		return
+	}
+	if status.Finished.IsZero() || status.Canceled || result.OSBuildOutput == nil || !result.OSBuildOutput.Success {
+		httpError(w, fmt.Sprintf("Compose %s has not been built successfully, it cannot be cloned", id), http.StatusBadRequest)
+		return
+	}
+
+	t, err := targetFromUploadRequest(request, job.ImageName)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cloneID, err := server.workers.EnqueueUpload(r.Context(), &worker.UploadJob{
+		Target:          t,
+		ImageName:       job.ImageName,
+		StreamOptimized: job.StreamOptimized,
+		Credentials:     worker.RedactCredentials(t),
+		Tenant:          requestTenant(r),
+	}, imageID)
+	if err != nil {
+		httpError(w, "Failed to enqueue clone", http.StatusInternalServerError)
+		return
+	}
+
+	var response CloneResult
+	response.Id = cloneID.String()
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
+
+// CloneStatus handles a /clones/{id} GET request
+func (server *Server) CloneStatus(w http.ResponseWriter, r *http.Request, id string) {
+	jobId, err := uuid.Parse(id)
+	if err != nil {
+		httpError(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	jobType, _, _, err := server.workers.Job(jobId, &json.RawMessage{})
+	if err != nil || jobType != "upload" {
+		httpError(w, fmt.Sprintf("Clone %s not found", id), http.StatusNotFound)
+		return
+	}
+
+	response, err := server.uploadStatus(jobId)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
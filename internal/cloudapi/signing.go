@@ -0,0 +1,43 @@
+package cloudapi
+
+import (
+	"net/http"
+)
+
+// Header which carries the signature of a response, when composer is
+// configured with a signing key
+const signatureHeader = "X-Content-Signature"
+
+// SigningKey handles a /signing-key GET request
+func (server *Server) SigningKey(w http.ResponseWriter, r *http.Request) {
+	if server.signer == nil {
+		httpError(w, "Signing is not configured", http.StatusNotFound)
+		return
+	}
+
+	publicKey, err := server.signer.PublicKey()
+	if err != nil {
+		httpError(w, "Error encoding the public key", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(publicKey)
+}
+
+// writeSigned writes `body` as the response. If composer is configured with
+// a signing key, the signature of `body` is sent in a header, so that
+// clients can verify the response with the public key.
+func (server *Server) writeSigned(w http.ResponseWriter, contentType string, body []byte) {
+	if server.signer != nil {
+		signature, err := server.signer.Sign(body)
+		if err != nil {
+			httpError(w, "Error signing the response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(signatureHeader, signature)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,138 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/osbuild/osbuild-composer/internal/cloudapi/v2"
+)
+
+// Version 1 of the API is deprecated in favor of version 2, which only
+// differs in its requests. Requests of version 1 are converted to the ones of
+// version 2 and handled by the same code.
+
+// deprecated marks all responses as coming from a deprecated API, with the
+// Deprecation header.
+func deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// decodeRequest decodes the JSON body of `r` into `request`. If it cannot,
+// it writes an error response and returns false.
+func decodeRequest(w http.ResponseWriter, r *http.Request, request interface{}) bool {
+	contentType := r.Header["Content-Type"]
+	if len(contentType) != 1 || contentType[0] != "application/json" {
+		httpError(w, "Only 'application/json' content type is supported", http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		httpError(w, "Could not parse JSON body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func composeRequestV2(request ComposeRequest) v2.ComposeRequest {
+	result := v2.ComposeRequest{
+		Callback:       (*v2.Callback)(request.Callback),
+		Customizations: customizationsV2(request.Customizations),
+		Distro:         request.Distribution,
+		Eus:            request.Eus,
+		ImageRequests:  make([]v2.ImageRequest, len(request.ImageRequests)),
+		MinorRelease:   request.MinorRelease,
+	}
+	if request.ContentVerification != nil {
+		cv := v2.ContentVerification(*request.ContentVerification)
+		result.ContentVerification = &cv
+	}
+
+	for i, ir := range request.ImageRequests {
+		result.ImageRequests[i] = v2.ImageRequest{
+			Architecture:    ir.Architecture,
+			ExcludePackages: ir.ExcludePackages,
+			ImageType:       ir.ImageType,
+			Ostree:          (*v2.OSTree)(ir.Ostree),
+			Repositories:    repositoriesV2(ir.Repositories),
+		}
+		if ir.PayloadRepositories != nil {
+			payloadRepositories := repositoriesV2(*ir.PayloadRepositories)
+			result.ImageRequests[i].PayloadRepositories = &payloadRepositories
+		}
+
+		// upload_request is merged into upload_requests, before the
+		// others, so that statuses are reported in the same order
+		if ir.UploadRequest != nil {
+			result.ImageRequests[i].UploadRequests = append(result.ImageRequests[i].UploadRequests, uploadRequestV2(*ir.UploadRequest))
+		}
+		if ir.UploadRequests != nil {
+			for _, ur := range *ir.UploadRequests {
+				result.ImageRequests[i].UploadRequests = append(result.ImageRequests[i].UploadRequests, uploadRequestV2(ur))
+			}
+		}
+	}
+
+	return result
+}
+
+func kojiComposeRequestV2(request KojiComposeRequest) v2.KojiComposeRequest {
+	result := v2.KojiComposeRequest{
+		Distro:        request.Distribution,
+		ImageRequests: make([]v2.KojiImageRequest, len(request.ImageRequests)),
+		Koji: v2.KojiOptions{
+			Server: request.Koji.Server,
+			TaskId: request.Koji.TaskId,
+		},
+		Name:    request.Name,
+		Release: request.Release,
+		Version: request.Version,
+	}
+	for i, ir := range request.ImageRequests {
+		result.ImageRequests[i] = v2.KojiImageRequest{
+			Architecture: ir.Architecture,
+			ImageType:    ir.ImageType,
+			Repositories: repositoriesV2(ir.Repositories),
+		}
+	}
+	return result
+}
+
+func customizationsV2(customizations *Customizations) *v2.Customizations {
+	if customizations == nil {
+		return nil
+	}
+
+	result := &v2.Customizations{
+		Packages:     customizations.Packages,
+		Subscription: (*v2.Subscription)(customizations.Subscription),
+	}
+	if customizations.Users != nil {
+		users := make([]v2.User, len(*customizations.Users))
+		for i, user := range *customizations.Users {
+			users[i] = v2.User(user)
+		}
+		result.Users = &users
+	}
+	return result
+}
+
+func repositoriesV2(repositories []Repository) []v2.Repository {
+	result := make([]v2.Repository, len(repositories))
+	for i, repo := range repositories {
+		result[i] = v2.Repository(repo)
+	}
+	return result
+}
+
+// uploadRequestV2 converts an upload request, whose options are the same in
+// both versions.
+func uploadRequestV2(ur UploadRequest) v2.UploadRequest {
+	return v2.UploadRequest{
+		Options: ur.Options,
+		Type:    v2.UploadTypes(ur.Type),
+	}
+}
@@ -0,0 +1,70 @@
+package cloudapi
+
+import (
+	"net/http"
+
+	"github.com/osbuild/osbuild-composer/internal/cloudapi/v2"
+)
+
+// v2Handlers serves version 2 of the API. Only its requests differ from
+// version 1, so all other routes are served by the handlers of version 1.
+type v2Handlers struct {
+	*Server
+}
+
+// Compose handles a new /compose POST request of version 2 of the API
+func (h v2Handlers) Compose(w http.ResponseWriter, r *http.Request) {
+	var request v2.ComposeRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+	h.compose(w, r, request)
+}
+
+// ValidateBlueprint handles a /blueprints/validate POST request of version 2
+// of the API
+func (h v2Handlers) ValidateBlueprint(w http.ResponseWriter, r *http.Request) {
+	var request v2.ComposeRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+	h.validateBlueprint(w, r, request)
+}
+
+// ComposeKoji handles a new /compose/koji POST request of version 2 of the
+// API
+func (h v2Handlers) ComposeKoji(w http.ResponseWriter, r *http.Request) {
+	var request v2.KojiComposeRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+	h.composeKoji(w, r, request)
+}
+
+// GetOpenapiJson handles a /openapi.json GET request of version 2 of the API
+func (h v2Handlers) GetOpenapiJson(w http.ResponseWriter, r *http.Request) {
+	writeSpec(w, v2.GetSwagger)
+}
+
+// GetVersion handles a /version GET request of version 2 of the API
+func (h v2Handlers) GetVersion(w http.ResponseWriter, r *http.Request) {
+	writeVersion(w, v2.GetSwagger)
+}
+
+func (h v2Handlers) ComposeLog(w http.ResponseWriter, r *http.Request, id string, params v2.ComposeLogParams) {
+	h.Server.ComposeLog(w, r, id, ComposeLogParams(params))
+}
+
+func (h v2Handlers) ComposeSbom(w http.ResponseWriter, r *http.Request, id string, params v2.ComposeSbomParams) {
+	h.Server.ComposeSbom(w, r, id, ComposeSbomParams(params))
+}
+
+func (h v2Handlers) SearchPackages(w http.ResponseWriter, r *http.Request, distroName, archName string, params v2.SearchPackagesParams) {
+	h.Server.SearchPackages(w, r, distroName, archName, SearchPackagesParams(params))
+}
+
+// ListComposes handles a /composes GET request of version 2 of the API, which
+// may filter and paginate the composes
+func (h v2Handlers) ListComposes(w http.ResponseWriter, r *http.Request, params v2.ListComposesParams) {
+	h.listComposes(w, r, params)
+}
@@ -0,0 +1,58 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/osbuild/osbuild-composer/internal/logging"
+)
+
+// The codes of errors of requests. Like the codes of failed images, they are
+// part of the API and must not be changed once they have been released.
+const (
+	ErrorBadRequest           = "bad-request"
+	ErrorUnauthorized         = "unauthorized"
+	ErrorForbidden            = "forbidden"
+	ErrorNotFound             = "not-found"
+	ErrorConflict             = "conflict"
+	ErrorUnsupportedMediaType = "unsupported-media-type"
+	ErrorUnprocessableEntity  = "unprocessable-entity"
+	ErrorTooManyRequests      = "too-many-requests"
+	ErrorInternal             = "internal-error"
+)
+
+// The codes of errors, by their HTTP status
+var errorCodes = map[int]string{
+	http.StatusBadRequest:           ErrorBadRequest,
+	http.StatusUnauthorized:         ErrorUnauthorized,
+	http.StatusForbidden:            ErrorForbidden,
+	http.StatusNotFound:             ErrorNotFound,
+	http.StatusConflict:             ErrorConflict,
+	http.StatusUnsupportedMediaType: ErrorUnsupportedMediaType,
+	http.StatusUnprocessableEntity:  ErrorUnprocessableEntity,
+	http.StatusTooManyRequests:      ErrorTooManyRequests,
+	http.StatusInternalServerError:  ErrorInternal,
+}
+
+// Error replies to the request with an ErrorInfo with `reason` and the code
+// of HTTP status `status`. Like http.Error, it doesn't end the request, and
+// other headers should already have been set. Statuses which have no code,
+// which must be added to the API first, get ErrorInternal.
+func Error(w http.ResponseWriter, reason string, status int) {
+	code, ok := errorCodes[status]
+	if !ok {
+		logging.Default().Errorf("No error code for HTTP status %d, replying with %s", status, ErrorInternal)
+		code = ErrorInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(ErrorInfo{
+		Code:   code,
+		Reason: reason,
+	})
+	if err != nil {
+		panic("Failed to write response")
+	}
+}
@@ -0,0 +1,1753 @@
+// Package v2 provides primitives to interact the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen DO NOT EDIT.
+package v2
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AWSAmi defines model for AWSAmi.
+type AWSAmi struct {
+	Ami    string `json:"ami"`
+	Region string `json:"region"`
+}
+
+// AWSS3UploadRequestOptions defines model for AWSS3UploadRequestOptions.
+type AWSS3UploadRequestOptions struct {
+	Region string `json:"region"`
+
+	// Credentials for uploading to the bucket. Either access keys or a role
+	// is required.
+	S3 AWSUploadRequestOptionsS3 `json:"s3"`
+}
+
+// AWSS3UploadStatus defines model for AWSS3UploadStatus.
+type AWSS3UploadStatus struct {
+	Url string `json:"url"`
+}
+
+// AWSTag defines model for AWSTag.
+type AWSTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// AWSUploadRequestOptions defines model for AWSUploadRequestOptions.
+type AWSUploadRequestOptions struct {
+
+	// Credentials for importing and registering the image. The credentials
+	// of the s3 options are used if these are empty.
+	Ec2    AWSUploadRequestOptionsEc2 `json:"ec2"`
+	Region string                     `json:"region"`
+
+	// Credentials for uploading to the bucket. Either access keys or a role
+	// is required.
+	S3 AWSUploadRequestOptionsS3 `json:"s3"`
+}
+
+// AWSUploadRequestOptionsEc2 defines model for AWSUploadRequestOptionsEc2.
+type AWSUploadRequestOptionsEc2 struct {
+	AccessKeyId *string `json:"access_key_id,omitempty"`
+
+	// Regions to copy the AMI to after it has been registered in the
+	// region of the upload. The copies are tagged and shared like the
+	// AMI itself.
+	CopyToRegions *[]string `json:"copy_to_regions,omitempty"`
+
+	// External ID which the trust policy of the role requires.
+	ExternalId *string `json:"external_id,omitempty"`
+
+	// IAM role to assume for the upload, with the access keys if they
+	// are set, or else with the credentials of the worker.
+	RoleArn         *string `json:"role_arn,omitempty"`
+	SecretAccessKey *string `json:"secret_access_key,omitempty"`
+
+	// Session token of temporary access keys.
+	SessionToken      *string   `json:"session_token,omitempty"`
+	ShareWithAccounts *[]string `json:"share_with_accounts,omitempty"`
+	SnapshotName      *string   `json:"snapshot_name,omitempty"`
+
+	// Tags of the AMIs and their snapshots, in addition to their name.
+	Tags *[]AWSTag `json:"tags,omitempty"`
+}
+
+// AWSUploadRequestOptionsS3 defines model for AWSUploadRequestOptionsS3.
+type AWSUploadRequestOptionsS3 struct {
+	AccessKeyId *string `json:"access_key_id,omitempty"`
+	Bucket      string  `json:"bucket"`
+
+	// External ID which the trust policy of the role requires.
+	ExternalId *string `json:"external_id,omitempty"`
+
+	// IAM role to assume for the upload, with the access keys if they
+	// are set, or else with the credentials of the worker.
+	RoleArn         *string `json:"role_arn,omitempty"`
+	SecretAccessKey *string `json:"secret_access_key,omitempty"`
+
+	// Session token of temporary access keys.
+	SessionToken *string `json:"session_token,omitempty"`
+}
+
+// AWSUploadStatus defines model for AWSUploadStatus.
+type AWSUploadStatus struct {
+	Ami string `json:"ami"`
+
+	// The AMIs of the image in all regions: the one in region,
+	// followed by its copies in the regions of copy_to_regions.
+	Amis   *[]AWSAmi `json:"amis,omitempty"`
+	Region string    `json:"region"`
+}
+
+// ArchitectureImageTypes defines model for ArchitectureImageTypes.
+type ArchitectureImageTypes struct {
+	Architecture string          `json:"architecture"`
+	ImageTypes   []ImageTypeInfo `json:"image_types"`
+}
+
+// Artifact defines model for Artifact.
+type Artifact struct {
+	Name string `json:"name"`
+
+	// Hex-encoded SHA-256 digest of the file, which composer computed
+	// when it received it. Not set for files received by older versions
+	// of composer.
+	Sha256 *string `json:"sha256,omitempty"`
+
+	// Size of the file in bytes
+	Size int64 `json:"size"`
+}
+
+// AzureUploadRequestOptions defines model for AzureUploadRequestOptions.
+type AzureUploadRequestOptions struct {
+
+	// Name of the uploaded image. It must be unique in the given resource group.
+	// If name is omitted from the request, a random one based on a UUID is
+	// generated.
+	ImageName *string `json:"image_name,omitempty"`
+
+	// Location where the image should be uploaded and registered. This link explain
+	// how to list all locations:
+	// https://docs.microsoft.com/en-us/cli/azure/account?view=azure-cli-latest#az_account_list_locations'
+	Location string `json:"location"`
+
+	// Name of the resource group where the image should be uploaded.
+	ResourceGroup string `json:"resource_group"`
+
+	// ID of subscription where the image should be uploaded.
+	SubscriptionId string `json:"subscription_id"`
+
+	// ID of the tenant where the image should be uploaded. This link explains how
+	// to find it in the Azure Portal:
+	// https://docs.microsoft.com/en-us/azure/active-directory/fundamentals/active-directory-how-to-find-tenant
+	TenantId string `json:"tenant_id"`
+}
+
+// AzureUploadStatus defines model for AzureUploadStatus.
+type AzureUploadStatus struct {
+	ImageName string `json:"image_name"`
+}
+
+// BlueprintDiagnostic defines model for BlueprintDiagnostic.
+type BlueprintDiagnostic struct {
+	Architecture string `json:"architecture"`
+
+	// The part of the compose request which has the problem
+	Field     *string `json:"field,omitempty"`
+	ImageType string  `json:"image_type"`
+	Message   string  `json:"message"`
+
+	// Errors make the compose fail, while warnings are about images
+	// which might not be what was intended
+	Severity string `json:"severity"`
+}
+
+// BlueprintValidation defines model for BlueprintValidation.
+type BlueprintValidation struct {
+	Diagnostics []BlueprintDiagnostic `json:"diagnostics"`
+
+	// Whether the compose request has no errors
+	Valid bool `json:"valid"`
+}
+
+// BootMode defines model for BootMode.
+type BootMode string
+
+// List of BootMode
+const (
+	BootMode_hybrid BootMode = "hybrid"
+	BootMode_legacy BootMode = "legacy"
+	BootMode_none   BootMode = "none"
+	BootMode_uefi   BootMode = "uefi"
+)
+
+// Callback defines model for Callback.
+type Callback struct {
+	Secret string `json:"secret"`
+	Url    string `json:"url"`
+}
+
+// CloneResult defines model for CloneResult.
+type CloneResult struct {
+	Id string `json:"id"`
+}
+
+// ComposeEvent defines model for ComposeEvent.
+type ComposeEvent struct {
+	ComposeStatus ComposeStatus `json:"compose_status"`
+
+	// ID of the compose whose status changed.
+	Id string `json:"id"`
+
+	// Why a request or an image failed. Clients may rely on the code,
+	// which doesn't change between versions, but not on the reason. The
+	// codes of failed images are:
+	//   - canceled
+	//   - dependency-failed: the details are the error of the job the
+	//     image depended on, for example depsolving its packages
+	//   - invalid-job
+	//   - packages-not-found
+	//   - depsolve-failed
+	//   - repository-unavailable
+	//   - content-verification-failed
+	//   - ostree-ref-resolution-failed
+	//   - manifest-generation-failed
+	//   - osbuild-failed: the details name the stage which failed
+	//   - worker-error
+	//   - upload-failed: the details name the target
+	//   - koji-init-failed, koji-upload-failed, koji-import-failed,
+	//     koji-tag-failed
+	// The codes of failed requests are bad-request, unauthorized,
+	// forbidden, not-found, conflict, unsupported-media-type,
+	// unprocessable-entity, too-many-requests, and internal-error.
+	JobError *ErrorInfo `json:"job_error,omitempty"`
+
+	// Why the compose failed, if it has.
+	Reason *string          `json:"reason,omitempty"`
+	Status ImageStatusValue `json:"status"`
+}
+
+// ComposeListItem defines model for ComposeListItem.
+type ComposeListItem struct {
+
+	// When the compose was requested
+	CreatedAt time.Time        `json:"created_at"`
+	Id        string           `json:"id"`
+	Status    ImageStatusValue `json:"status"`
+}
+
+// ComposeLog defines model for ComposeLog.
+type ComposeLog struct {
+
+	// Whether the build has finished, after which its log doesn't
+	// grow anymore
+	Finished bool `json:"finished"`
+
+	// The part of the log starting at the requested offset, or the
+	// complete log once the build has finished
+	Log string `json:"log"`
+
+	// The offset to request the rest of the log from
+	Offset int `json:"offset"`
+}
+
+// ComposeManifests defines model for ComposeManifests.
+type ComposeManifests struct {
+
+	// The manifest of each image, or null for images whose manifest
+	// hasn't been generated yet or couldn't be generated
+	Manifests []map[string]interface{} `json:"manifests"`
+}
+
+// ComposeMetadata defines model for ComposeMetadata.
+type ComposeMetadata struct {
+
+	// The files which the worker sent to composer after building the
+	// image, such as the image itself
+	Artifacts *[]Artifact `json:"artifacts,omitempty"`
+
+	// How the image boots: with the legacy boot loader of BIOS or s390x
+	// machines, with UEFI, with both (hybrid), or not at all
+	BootMode            *BootMode                  `json:"boot_mode,omitempty"`
+	ContentVerification *ContentVerificationResult `json:"content_verification,omitempty"`
+
+	// Minor release of the distribution the image was built from, taken
+	// from the version of its release package
+	MinorRelease *string `json:"minor_release,omitempty"`
+
+	// ID (hash) of the built commit
+	OstreeCommit *string `json:"ostree_commit,omitempty"`
+
+	// Package list including NEVRA
+	Packages *[]PackageMetadata `json:"packages,omitempty"`
+}
+
+// ComposeRequest defines model for ComposeRequest.
+type ComposeRequest struct {
+
+	// A URL to which a ComposeEvent is POSTed whenever the status of the
+	// compose changes, from pending until it succeeded or failed. Events
+	// are signed with an HMAC-SHA256 of their body with the secret as
+	// key, which is sent hex-encoded in the X-Composer-Signature header
+	// as 'sha256=<signature>'.
+	Callback *Callback `json:"callback,omitempty"`
+
+	// How the content of the images is verified. By default, only the
+	// packages of repositories with check_gpg are verified. In strict
+	// mode, all repositories must have a gpg_key, which is used to verify
+	// both the signature of the metadata of the repository when
+	// depsolving and the signatures of all of its packages. A package
+	// whose signature cannot be verified fails the compose.
+	ContentVerification *ContentVerification `json:"content_verification,omitempty"`
+	Customizations      *Customizations      `json:"customizations,omitempty"`
+	Distro              string               `json:"distro"`
+
+	// Build the images from the Extended Update Support repositories
+	// of the minor release, which must be set. The content/dist paths
+	// of Red Hat CDN repositories are replaced by content/eus.
+	Eus *bool `json:"eus,omitempty"`
+
+	// Images to build, each with its own upload target. All of them
+	// are built from the same customizations.
+	ImageRequests []ImageRequest `json:"image_requests"`
+
+	// Minor release of a RHEL distribution the images are built from.
+	// It replaces the $releasever variable in the URLs of the
+	// repositories, which defaults to the major release.
+	MinorRelease *string `json:"minor_release,omitempty"`
+}
+
+// ComposeResult defines model for ComposeResult.
+type ComposeResult struct {
+	Id string `json:"id"`
+}
+
+// ComposeStatus defines model for ComposeStatus.
+type ComposeStatus struct {
+	ImageStatus ImageStatus `json:"image_status"`
+
+	// Statuses of the images of a compose with more than one image
+	// request, in the order of the requests. image_status then holds
+	// the status of the whole compose, which failed if any of its
+	// images failed.
+	ImageStatuses *[]ImageStatus `json:"image_statuses,omitempty"`
+}
+
+// ContentVerification defines model for ContentVerification.
+type ContentVerification string
+
+// List of ContentVerification
+const (
+	ContentVerification__default ContentVerification = "default"
+	ContentVerification_strict   ContentVerification = "strict"
+)
+
+// ContentVerificationResult defines model for ContentVerificationResult.
+type ContentVerificationResult struct {
+
+	// How the content of the images is verified. By default, only the
+	// packages of repositories with check_gpg are verified. In strict
+	// mode, all repositories must have a gpg_key, which is used to verify
+	// both the signature of the metadata of the repository when
+	// depsolving and the signatures of all of its packages. A package
+	// whose signature cannot be verified fails the compose.
+	Mode ContentVerification `json:"mode"`
+
+	// Number of packages of the image which were installed without
+	// verifying their signatures
+	UnverifiedPackages int `json:"unverified_packages"`
+
+	// Number of packages of the image whose signatures were verified
+	// when they were installed
+	VerifiedPackages int `json:"verified_packages"`
+}
+
+// Customizations defines model for Customizations.
+type Customizations struct {
+	Packages     *[]string     `json:"packages,omitempty"`
+	Subscription *Subscription `json:"subscription,omitempty"`
+	Users        *[]User       `json:"users,omitempty"`
+}
+
+// Distribution defines model for Distribution.
+type Distribution struct {
+	Architectures []string `json:"architectures"`
+	Name          string   `json:"name"`
+}
+
+// DistributionImageTypes defines model for DistributionImageTypes.
+type DistributionImageTypes struct {
+	Architectures []ArchitectureImageTypes `json:"architectures"`
+	Distribution  string                   `json:"distribution"`
+}
+
+// ErrorInfo defines model for ErrorInfo.
+type ErrorInfo struct {
+	Code string `json:"code"`
+
+	// More information about the error, depending on its code
+	Details *interface{} `json:"details,omitempty"`
+
+	// The end of the output of the osbuild stage which failed
+	OsbuildError *string `json:"osbuild_error,omitempty"`
+	Reason       string  `json:"reason"`
+}
+
+// GCPUploadRequestOptions defines model for GCPUploadRequestOptions.
+type GCPUploadRequestOptions struct {
+
+	// Name of an existing STANDARD Storage class Bucket.
+	Bucket string `json:"bucket"`
+
+	// List of guest OS features to enable on the imported Compute Engine
+	// image. See https://cloud.google.com/compute/docs/images/create-delete-deprecate-private-images#guest-os-features.
+	GuestOsFeatures *[]string `json:"guest_os_features,omitempty"`
+
+	// The name to use for the imported and shared Compute Engine image.
+	// The image name must be unique within the GCP project, which is used
+	// for the OS image upload and import. If not specified a random
+	// 'composer-api-<uuid>' string is used as the image name.
+	ImageName *string `json:"image_name,omitempty"`
+
+	// The GCP region where the OS image will be imported to and shared from.
+	// The value must be a valid GCP location. See https://cloud.google.com/storage/docs/locations.
+	// If not specified, the multi-region location closest to the source
+	// (source Storage Bucket location) is chosen automatically.
+	Region *string `json:"region,omitempty"`
+
+	// List of valid Google accounts to share the imported Compute Engine image with.
+	// Each string must contain a specifier of the account type. Valid formats are:
+	//   - 'user:{emailid}': An email address that represents a specific
+	//     Google account. For example, 'alice@example.com'.
+	//   - 'serviceAccount:{emailid}': An email address that represents a
+	//     service account. For example, 'my-other-app@appspot.gserviceaccount.com'.
+	//   - 'group:{emailid}': An email address that represents a Google group.
+	//     For example, 'admins@example.com'.
+	//   - 'domain:{domain}': The G Suite domain (primary) that represents all
+	//     the users of that domain. For example, 'google.com' or 'example.com'.
+	// If not specified, the imported Compute Engine image is not shared with any
+	// account.
+	ShareWithAccounts *[]string `json:"share_with_accounts,omitempty"`
+
+	// List of IDs of GCP projects to share the imported Compute Engine image
+	// with, for example the service projects of a Shared VPC. Instances in
+	// these projects, including those created by managed instance groups,
+	// can be created from the image.
+	ShareWithProjects *[]string `json:"share_with_projects,omitempty"`
+}
+
+// GCPUploadStatus defines model for GCPUploadStatus.
+type GCPUploadStatus struct {
+	ImageName string `json:"image_name"`
+	ProjectId string `json:"project_id"`
+}
+
+// GenericS3UploadRequestOptions defines model for GenericS3UploadRequestOptions.
+type GenericS3UploadRequestOptions struct {
+
+	// PEM-encoded certificates to trust instead of the system's ones
+	// when connecting to the endpoint.
+	CaBundle *string `json:"ca_bundle,omitempty"`
+
+	// URL of an S3-compatible object storage service, such as MinIO or
+	// Ceph RGW. Buckets are addressed in the path of this URL.
+	Endpoint string `json:"endpoint"`
+	Region   string `json:"region"`
+
+	// Credentials for uploading to the bucket. Either access keys or a role
+	// is required.
+	S3 AWSUploadRequestOptionsS3 `json:"s3"`
+
+	// Don't verify the endpoint's certificate. Only use this for testing.
+	SkipSslVerification *bool `json:"skip_ssl_verification,omitempty"`
+}
+
+// GenericS3UploadStatus defines model for GenericS3UploadStatus.
+type GenericS3UploadStatus struct {
+	Url string `json:"url"`
+}
+
+// ImageProgress defines model for ImageProgress.
+type ImageProgress struct {
+	Current  int     `json:"current"`
+	Percent  int     `json:"percent"`
+	Pipeline *string `json:"pipeline,omitempty"`
+	Stage    string  `json:"stage"`
+	Total    int     `json:"total"`
+}
+
+// ImageRequest defines model for ImageRequest.
+type ImageRequest struct {
+	Architecture string `json:"architecture"`
+
+	// Packages which must not be installed in the image, neither
+	// explicitly nor as dependencies.
+	ExcludePackages *[]string `json:"exclude_packages,omitempty"`
+	ImageType       string    `json:"image_type"`
+	Ostree          *OSTree   `json:"ostree,omitempty"`
+
+	// Repositories which are only used to install the packages of the
+	// image, in addition to the repositories. Unlike those, they are
+	// not used for the build root of the image.
+	PayloadRepositories *[]Repository `json:"payload_repositories,omitempty"`
+	Repositories        []Repository  `json:"repositories"`
+
+	// Targets to upload the image to. The image is built once and
+	// uploaded to each of them. A 'local' upload request keeps the
+	// image in composer, from where it can be downloaded with
+	// /compose/{id}/download.
+	UploadRequests []UploadRequest `json:"upload_requests"`
+}
+
+// ImageStatus defines model for ImageStatus.
+type ImageStatus struct {
+
+	// Why the image failed, if it has. The status of a compose with
+	// more than one image has the error of the first image that
+	// failed. It describes job_error, which should be used instead
+	// by clients which handle errors.
+	Error *string `json:"error,omitempty"`
+
+	// ID of an image of a compose with more than one image request.
+	// The status and metadata of the image can be requested with it
+	// like those of a compose.
+	Id *string `json:"id,omitempty"`
+
+	// Why a request or an image failed. Clients may rely on the code,
+	// which doesn't change between versions, but not on the reason. The
+	// codes of failed images are:
+	//   - canceled
+	//   - dependency-failed: the details are the error of the job the
+	//     image depended on, for example depsolving its packages
+	//   - invalid-job
+	//   - packages-not-found
+	//   - depsolve-failed
+	//   - repository-unavailable
+	//   - content-verification-failed
+	//   - ostree-ref-resolution-failed
+	//   - manifest-generation-failed
+	//   - osbuild-failed: the details name the stage which failed
+	//   - worker-error
+	//   - upload-failed: the details name the target
+	//   - koji-init-failed, koji-upload-failed, koji-import-failed,
+	//     koji-tag-failed
+	// The codes of failed requests are bad-request, unauthorized,
+	// forbidden, not-found, conflict, unsupported-media-type,
+	// unprocessable-entity, too-many-requests, and internal-error.
+	JobError *ErrorInfo `json:"job_error,omitempty"`
+
+	// Progress of an image which is being built: the stage osbuild is
+	// running, its position among all stages of the image, and the
+	// percentage of the stages which have finished. Stages which osbuild
+	// takes from its cache are skipped. The progress of an image whose
+	// build failed names the stage which failed.
+	Progress     *ImageProgress   `json:"progress,omitempty"`
+	Status       ImageStatusValue `json:"status"`
+	UploadStatus *UploadStatus    `json:"upload_status,omitempty"`
+
+	// Statuses of the uploads of an image with more than one upload
+	// request, in the order of upload_requests.
+	UploadStatuses *[]UploadStatus `json:"upload_statuses,omitempty"`
+}
+
+// ImageStatusValue defines model for ImageStatusValue.
+type ImageStatusValue string
+
+// List of ImageStatusValue
+const (
+	ImageStatusValue_building    ImageStatusValue = "building"
+	ImageStatusValue_failure     ImageStatusValue = "failure"
+	ImageStatusValue_pending     ImageStatusValue = "pending"
+	ImageStatusValue_registering ImageStatusValue = "registering"
+	ImageStatusValue_success     ImageStatusValue = "success"
+	ImageStatusValue_uploading   ImageStatusValue = "uploading"
+)
+
+// ImageTypeInfo defines model for ImageTypeInfo.
+type ImageTypeInfo struct {
+
+	// Size of the image in bytes, unless a compose requests a larger one
+	DefaultSize int64  `json:"default_size"`
+	Name        string `json:"name"`
+}
+
+// KojiComposeRequest defines model for KojiComposeRequest.
+type KojiComposeRequest struct {
+	Distro        string             `json:"distro"`
+	ImageRequests []KojiImageRequest `json:"image_requests"`
+	Koji          KojiOptions        `json:"koji"`
+	Name          string             `json:"name"`
+	Release       string             `json:"release"`
+	Version       string             `json:"version"`
+}
+
+// KojiComposeStatus defines model for KojiComposeStatus.
+type KojiComposeStatus struct {
+	ImageStatuses []ImageStatus    `json:"image_statuses"`
+	KojiBuildId   *int             `json:"koji_build_id,omitempty"`
+	Status        ImageStatusValue `json:"status"`
+}
+
+// KojiImageRequest defines model for KojiImageRequest.
+type KojiImageRequest struct {
+	Architecture string       `json:"architecture"`
+	ImageType    string       `json:"image_type"`
+	Repositories []Repository `json:"repositories"`
+}
+
+// KojiOptions defines model for KojiOptions.
+type KojiOptions struct {
+
+	// Import the images as a draft build, which doesn't claim the
+	// NVR of the build and can be promoted to a regular build later.
+	// Draft builds are tagged into draft_tag instead of tag.
+	Draft *bool `json:"draft,omitempty"`
+
+	// Tag to tag the build into once its images have been imported,
+	// if it is a draft build
+	DraftTag *string `json:"draft_tag,omitempty"`
+	Server   string  `json:"server"`
+
+	// Tag to tag the build into once its images have been imported.
+	// The build fails if it can't be tagged, but stays imported.
+	Tag *string `json:"tag,omitempty"`
+
+	// ID of the Koji task the build belongs to
+	TaskId int `json:"task_id"`
+}
+
+// LocalUploadRequestOptions defines model for LocalUploadRequestOptions.
+type LocalUploadRequestOptions map[string]interface{}
+
+// OCIUploadRequestOptions defines model for OCIUploadRequestOptions.
+type OCIUploadRequestOptions struct {
+
+	// Name of an existing Object Storage bucket the image is uploaded to
+	// before importing it. The uploaded object is deleted afterwards.
+	Bucket string `json:"bucket"`
+
+	// OCID of the compartment the image will be created in.
+	Compartment string `json:"compartment"`
+
+	// Display name of the imported custom image. If not specified a random
+	// 'composer-api-<uuid>' string is used as the image name.
+	ImageName *string `json:"image_name,omitempty"`
+
+	// Object Storage namespace of the tenancy the bucket belongs to.
+	Namespace string `json:"namespace"`
+
+	// The OCI region where the image will be imported to.
+	Region string `json:"region"`
+}
+
+// OCIUploadStatus defines model for OCIUploadStatus.
+type OCIUploadStatus struct {
+
+	// OCID of the imported custom image.
+	ImageId string `json:"image_id"`
+	Region  string `json:"region"`
+}
+
+// OSTree defines model for OSTree.
+type OSTree struct {
+
+	// Checksum of the parent commit. Mutually exclusive with url, from
+	// which the parent is resolved otherwise.
+	Parent *string `json:"parent,omitempty"`
+	Ref    *string `json:"ref,omitempty"`
+	Url    *string `json:"url,omitempty"`
+}
+
+// PackageBuild defines model for PackageBuild.
+type PackageBuild struct {
+	Arch      string     `json:"arch"`
+	BuildTime *time.Time `json:"build_time,omitempty"`
+	Epoch     int        `json:"epoch"`
+	Name      string     `json:"name"`
+	Release   string     `json:"release"`
+	Version   string     `json:"version"`
+}
+
+// PackageInfo defines model for PackageInfo.
+type PackageInfo struct {
+	Builds []PackageBuild `json:"builds"`
+
+	// The packages installed with the latest build, including itself
+	Dependencies []PackageBuild `json:"dependencies"`
+	Description  string         `json:"description"`
+	Homepage     string         `json:"homepage"`
+	Name         string         `json:"name"`
+	Summary      string         `json:"summary"`
+}
+
+// PackageMetadata defines model for PackageMetadata.
+type PackageMetadata struct {
+	Arch string `json:"arch"`
+
+	// Checksum of the package which was depsolved for the image, as
+	// listed in its manifest
+	Checksum *string `json:"checksum,omitempty"`
+	Epoch    *string `json:"epoch,omitempty"`
+	Name     string  `json:"name"`
+	Release  string  `json:"release"`
+
+	// URL of the repository the package was depsolved from, its
+	// base URL, metalink, or mirrorlist
+	Repository *string `json:"repository,omitempty"`
+	Sigmd5     string  `json:"sigmd5"`
+	Signature  *string `json:"signature,omitempty"`
+	Type       string  `json:"type"`
+	Version    string  `json:"version"`
+}
+
+// PackageSearchResult defines model for PackageSearchResult.
+type PackageSearchResult struct {
+	Packages []PackageSummary `json:"packages"`
+
+	// Number of matching packages, including the ones beyond the limit
+	Total int `json:"total"`
+}
+
+// PackageSummary defines model for PackageSummary.
+type PackageSummary struct {
+	Name    string `json:"name"`
+	Summary string `json:"summary"`
+
+	// The available versions, as version-release
+	Versions []string `json:"versions"`
+}
+
+// Repository defines model for Repository.
+type Repository struct {
+	Baseurl *string `json:"baseurl,omitempty"`
+
+	// Whether to verify the signatures of the packages of the repository
+	CheckGpg *bool `json:"check_gpg,omitempty"`
+
+	// ASCII-armored GPG key used to verify the packages of the repository
+	GpgKey     *string `json:"gpg_key,omitempty"`
+	Metalink   *string `json:"metalink,omitempty"`
+	Mirrorlist *string `json:"mirrorlist,omitempty"`
+
+	// Whether packages of the repository are exempt from modular filtering
+	ModuleHotfixes *bool `json:"module_hotfixes,omitempty"`
+	Rhsm           bool  `json:"rhsm"`
+}
+
+// Subscription defines model for Subscription.
+type Subscription struct {
+	ActivationKey string `json:"activation_key"`
+	BaseUrl       string `json:"base_url"`
+	Insights      bool   `json:"insights"`
+	Organization  int    `json:"organization"`
+	ServerUrl     string `json:"server_url"`
+}
+
+// UploadRequest defines model for UploadRequest.
+type UploadRequest struct {
+	Options interface{} `json:"options"`
+	Type    UploadTypes `json:"type"`
+}
+
+// UploadStatus defines model for UploadStatus.
+type UploadStatus struct {
+
+	// Result of the upload, which is not set until the upload has
+	// succeeded.
+	Options *interface{} `json:"options,omitempty"`
+	Status  string       `json:"status"`
+	Type    UploadTypes  `json:"type"`
+}
+
+// UploadTypes defines model for UploadTypes.
+type UploadTypes string
+
+// List of UploadTypes
+const (
+	UploadTypes_aws        UploadTypes = "aws"
+	UploadTypes_aws_s3     UploadTypes = "aws.s3"
+	UploadTypes_azure      UploadTypes = "azure"
+	UploadTypes_gcp        UploadTypes = "gcp"
+	UploadTypes_generic_s3 UploadTypes = "generic.s3"
+	UploadTypes_local      UploadTypes = "local"
+	UploadTypes_oci        UploadTypes = "oci"
+)
+
+// User defines model for User.
+type User struct {
+
+	// Day on which the account is disabled, in days since 1970-01-01.
+	ExpireDate *int `json:"expire_date,omitempty"`
+
+	// Expire the password, so that it has to be changed on the first
+	// login.
+	ForcePasswordReset *bool     `json:"force_password_reset,omitempty"`
+	Gid                *int      `json:"gid,omitempty"`
+	Groups             *[]string `json:"groups,omitempty"`
+
+	// SSH public keys, which are added to the authorized keys of the user.
+	Keys *[]string `json:"keys,omitempty"`
+
+	// Disable logging in with a password.
+	Locked *bool  `json:"locked,omitempty"`
+	Name   string `json:"name"`
+	Uid    *int   `json:"uid,omitempty"`
+}
+
+// Version defines model for Version.
+type Version struct {
+	Version string `json:"version"`
+}
+
+// ValidateBlueprintJSONBody defines parameters for ValidateBlueprint.
+type ValidateBlueprintJSONBody ComposeRequest
+
+// ComposeJSONBody defines parameters for Compose.
+type ComposeJSONBody ComposeRequest
+
+// ComposeKojiJSONBody defines parameters for ComposeKoji.
+type ComposeKojiJSONBody KojiComposeRequest
+
+// ComposeCloneJSONBody defines parameters for ComposeClone.
+type ComposeCloneJSONBody UploadRequest
+
+// ComposeLogParams defines parameters for ComposeLog.
+type ComposeLogParams struct {
+
+	// Byte of the log of a running compose to start at
+	Offset *int `json:"offset,omitempty"`
+}
+
+// ComposeSbomParams defines parameters for ComposeSbom.
+type ComposeSbomParams struct {
+
+	// Format of the SBOM
+	Format *string `json:"format,omitempty"`
+}
+
+// ListComposesParams defines parameters for ListComposes.
+type ListComposesParams struct {
+
+	// Only the composes which have finished, successfully or not, or
+	// the ones which are still pending, building or uploading
+	Status *string `json:"status,omitempty"`
+
+	// Only the composes which were requested at or after this time
+	Since *time.Time `json:"since,omitempty"`
+
+	// Only the composes which were requested before this time
+	Until *time.Time `json:"until,omitempty"`
+
+	// Number of composes to skip
+	Offset *int `json:"offset,omitempty"`
+
+	// Maximum number of composes to return, all of them if not given
+	Limit *int `json:"limit,omitempty"`
+}
+
+// SearchPackagesParams defines parameters for SearchPackages.
+type SearchPackagesParams struct {
+
+	// Comma-separated globs which the names of the packages must match
+	Search string `json:"search"`
+
+	// Maximum number of packages to return
+	Limit *int `json:"limit,omitempty"`
+}
+
+// ValidateBlueprintRequestBody defines body for ValidateBlueprint for application/json ContentType.
+type ValidateBlueprintJSONRequestBody ValidateBlueprintJSONBody
+
+// ComposeRequestBody defines body for Compose for application/json ContentType.
+type ComposeJSONRequestBody ComposeJSONBody
+
+// ComposeKojiRequestBody defines body for ComposeKoji for application/json ContentType.
+type ComposeKojiJSONRequestBody ComposeKojiJSONBody
+
+// ComposeCloneRequestBody defines body for ComposeClone for application/json ContentType.
+type ComposeCloneJSONRequestBody ComposeCloneJSONBody
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Validate a compose request
+	// (POST /blueprints/validate)
+	ValidateBlueprint(w http.ResponseWriter, r *http.Request)
+	// The status of a clone
+	// (GET /clones/{id})
+	CloneStatus(w http.ResponseWriter, r *http.Request, id string)
+	// Create compose
+	// (POST /compose)
+	Compose(w http.ResponseWriter, r *http.Request)
+	// Create a Koji build
+	// (POST /compose/koji)
+	ComposeKoji(w http.ResponseWriter, r *http.Request)
+	// The status of a Koji build
+	// (GET /compose/koji/{id})
+	KojiComposeStatus(w http.ResponseWriter, r *http.Request, id string)
+	// Delete the images of a compose
+	// (DELETE /compose/{id})
+	DeleteCompose(w http.ResponseWriter, r *http.Request, id string)
+	// The status of a compose
+	// (GET /compose/{id})
+	ComposeStatus(w http.ResponseWriter, r *http.Request, id string)
+	// Upload the image of a compose to another target
+	// (POST /compose/{id}/clone)
+	ComposeClone(w http.ResponseWriter, r *http.Request, id string)
+	// Download the image of a compose
+	// (GET /compose/{id}/download)
+	ComposeDownload(w http.ResponseWriter, r *http.Request, id string)
+	// Stream the status of a compose
+	// (GET /compose/{id}/events)
+	ComposeEvents(w http.ResponseWriter, r *http.Request, id string)
+	// Get the build log of a compose
+	// (GET /compose/{id}/log)
+	ComposeLog(w http.ResponseWriter, r *http.Request, id string, params ComposeLogParams)
+	// Get the manifests of a compose
+	// (GET /compose/{id}/manifests)
+	ComposeManifests(w http.ResponseWriter, r *http.Request, id string)
+	// Get the metadata for a compose.
+	// (GET /compose/{id}/metadata)
+	ComposeMetadata(w http.ResponseWriter, r *http.Request, id string)
+	// Get the provenance of a compose
+	// (GET /compose/{id}/provenance)
+	ComposeProvenance(w http.ResponseWriter, r *http.Request, id string)
+	// Build a failed compose again
+	// (POST /compose/{id}/retry)
+	ComposeRetry(w http.ResponseWriter, r *http.Request, id string)
+	// Get the software bill of materials of a compose
+	// (GET /compose/{id}/sbom)
+	ComposeSbom(w http.ResponseWriter, r *http.Request, id string, params ComposeSbomParams)
+	// List the composes of the organization
+	// (GET /composes)
+	ListComposes(w http.ResponseWriter, r *http.Request, params ListComposesParams)
+	// List the supported distributions
+	// (GET /distros)
+	ListDistros(w http.ResponseWriter, r *http.Request)
+	// Search the packages of a distribution
+	// (GET /distros/{distro}/architectures/{arch}/packages)
+	SearchPackages(w http.ResponseWriter, r *http.Request, distro string, arch string, params SearchPackagesParams)
+	// Get information about a package of a distribution
+	// (GET /distros/{distro}/architectures/{arch}/packages/{name})
+	GetPackage(w http.ResponseWriter, r *http.Request, distro string, arch string, name string)
+	// List the image types of a distribution
+	// (GET /distros/{distro}/image-types)
+	ListDistroImageTypes(w http.ResponseWriter, r *http.Request, distro string)
+	// get the openapi json specification
+	// (GET /openapi.json)
+	GetOpenapiJson(w http.ResponseWriter, r *http.Request)
+	// Get the public key of the signing key
+	// (GET /signing-key)
+	SigningKey(w http.ResponseWriter, r *http.Request)
+	// get the service version
+	// (GET /version)
+	GetVersion(w http.ResponseWriter, r *http.Request)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
+}
+
+// ValidateBlueprint operation middleware
+func (siw *ServerInterfaceWrapper) ValidateBlueprint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.ValidateBlueprint(w, r.WithContext(ctx))
+}
+
+// CloneStatus operation middleware
+func (siw *ServerInterfaceWrapper) CloneStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.CloneStatus(w, r.WithContext(ctx), id)
+}
+
+// Compose operation middleware
+func (siw *ServerInterfaceWrapper) Compose(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.Compose(w, r.WithContext(ctx))
+}
+
+// ComposeKoji operation middleware
+func (siw *ServerInterfaceWrapper) ComposeKoji(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.ComposeKoji(w, r.WithContext(ctx))
+}
+
+// KojiComposeStatus operation middleware
+func (siw *ServerInterfaceWrapper) KojiComposeStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.KojiComposeStatus(w, r.WithContext(ctx), id)
+}
+
+// DeleteCompose operation middleware
+func (siw *ServerInterfaceWrapper) DeleteCompose(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.DeleteCompose(w, r.WithContext(ctx), id)
+}
+
+// ComposeStatus operation middleware
+func (siw *ServerInterfaceWrapper) ComposeStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeStatus(w, r.WithContext(ctx), id)
+}
+
+// ComposeClone operation middleware
+func (siw *ServerInterfaceWrapper) ComposeClone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeClone(w, r.WithContext(ctx), id)
+}
+
+// ComposeDownload operation middleware
+func (siw *ServerInterfaceWrapper) ComposeDownload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeDownload(w, r.WithContext(ctx), id)
+}
+
+// ComposeEvents operation middleware
+func (siw *ServerInterfaceWrapper) ComposeEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeEvents(w, r.WithContext(ctx), id)
+}
+
+// ComposeLog operation middleware
+func (siw *ServerInterfaceWrapper) ComposeLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ComposeLogParams
+
+	// ------------- Optional query parameter "offset" -------------
+	if paramValue := r.URL.Query().Get("offset"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter offset: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeLog(w, r.WithContext(ctx), id, params)
+}
+
+// ComposeManifests operation middleware
+func (siw *ServerInterfaceWrapper) ComposeManifests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeManifests(w, r.WithContext(ctx), id)
+}
+
+// ComposeMetadata operation middleware
+func (siw *ServerInterfaceWrapper) ComposeMetadata(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeMetadata(w, r.WithContext(ctx), id)
+}
+
+// ComposeProvenance operation middleware
+func (siw *ServerInterfaceWrapper) ComposeProvenance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeProvenance(w, r.WithContext(ctx), id)
+}
+
+// ComposeRetry operation middleware
+func (siw *ServerInterfaceWrapper) ComposeRetry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeRetry(w, r.WithContext(ctx), id)
+}
+
+// ComposeSbom operation middleware
+func (siw *ServerInterfaceWrapper) ComposeSbom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ComposeSbomParams
+
+	// ------------- Optional query parameter "format" -------------
+	if paramValue := r.URL.Query().Get("format"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter format: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ComposeSbom(w, r.WithContext(ctx), id, params)
+}
+
+// ListComposes operation middleware
+func (siw *ServerInterfaceWrapper) ListComposes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListComposesParams
+
+	// ------------- Optional query parameter "status" -------------
+	if paramValue := r.URL.Query().Get("status"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "status", r.URL.Query(), &params.Status)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter status: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "since" -------------
+	if paramValue := r.URL.Query().Get("since"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "since", r.URL.Query(), &params.Since)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter since: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "until" -------------
+	if paramValue := r.URL.Query().Get("until"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "until", r.URL.Query(), &params.Until)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter until: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+	if paramValue := r.URL.Query().Get("offset"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter offset: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+	if paramValue := r.URL.Query().Get("limit"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter limit: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ListComposes(w, r.WithContext(ctx), params)
+}
+
+// ListDistros operation middleware
+func (siw *ServerInterfaceWrapper) ListDistros(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.ListDistros(w, r.WithContext(ctx))
+}
+
+// SearchPackages operation middleware
+func (siw *ServerInterfaceWrapper) SearchPackages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "distro" -------------
+	var distro string
+
+	err = runtime.BindStyledParameter("simple", false, "distro", chi.URLParam(r, "distro"), &distro)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter distro: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "arch" -------------
+	var arch string
+
+	err = runtime.BindStyledParameter("simple", false, "arch", chi.URLParam(r, "arch"), &arch)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter arch: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SearchPackagesParams
+
+	// ------------- Required query parameter "search" -------------
+	if paramValue := r.URL.Query().Get("search"); paramValue != "" {
+
+	} else {
+		Error(w, "Query argument search is required, but not found", http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "search", r.URL.Query(), &params.Search)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter search: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+	if paramValue := r.URL.Query().Get("limit"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter limit: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.SearchPackages(w, r.WithContext(ctx), distro, arch, params)
+}
+
+// GetPackage operation middleware
+func (siw *ServerInterfaceWrapper) GetPackage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "distro" -------------
+	var distro string
+
+	err = runtime.BindStyledParameter("simple", false, "distro", chi.URLParam(r, "distro"), &distro)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter distro: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "arch" -------------
+	var arch string
+
+	err = runtime.BindStyledParameter("simple", false, "arch", chi.URLParam(r, "arch"), &arch)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter arch: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameter("simple", false, "name", chi.URLParam(r, "name"), &name)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter name: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.GetPackage(w, r.WithContext(ctx), distro, arch, name)
+}
+
+// ListDistroImageTypes operation middleware
+func (siw *ServerInterfaceWrapper) ListDistroImageTypes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "distro" -------------
+	var distro string
+
+	err = runtime.BindStyledParameter("simple", false, "distro", chi.URLParam(r, "distro"), &distro)
+	if err != nil {
+		Error(w, fmt.Sprintf("Invalid format for parameter distro: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	siw.Handler.ListDistroImageTypes(w, r.WithContext(ctx), distro)
+}
+
+// GetOpenapiJson operation middleware
+func (siw *ServerInterfaceWrapper) GetOpenapiJson(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.GetOpenapiJson(w, r.WithContext(ctx))
+}
+
+// SigningKey operation middleware
+func (siw *ServerInterfaceWrapper) SigningKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.SigningKey(w, r.WithContext(ctx))
+}
+
+// GetVersion operation middleware
+func (siw *ServerInterfaceWrapper) GetVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	siw.Handler.GetVersion(w, r.WithContext(ctx))
+}
+
+// Handler creates http.Handler with routing matching OpenAPI spec.
+func Handler(si ServerInterface) http.Handler {
+	return HandlerFromMux(si, chi.NewRouter())
+}
+
+// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
+func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Post("/blueprints/validate", wrapper.ValidateBlueprint)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/clones/{id}", wrapper.CloneStatus)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post("/compose", wrapper.Compose)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post("/compose/koji", wrapper.ComposeKoji)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/koji/{id}", wrapper.KojiComposeStatus)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete("/compose/{id}", wrapper.DeleteCompose)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}", wrapper.ComposeStatus)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post("/compose/{id}/clone", wrapper.ComposeClone)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/download", wrapper.ComposeDownload)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/events", wrapper.ComposeEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/log", wrapper.ComposeLog)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/manifests", wrapper.ComposeManifests)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/metadata", wrapper.ComposeMetadata)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/provenance", wrapper.ComposeProvenance)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post("/compose/{id}/retry", wrapper.ComposeRetry)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/compose/{id}/sbom", wrapper.ComposeSbom)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/composes", wrapper.ListComposes)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/distros", wrapper.ListDistros)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/distros/{distro}/architectures/{arch}/packages", wrapper.SearchPackages)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/distros/{distro}/architectures/{arch}/packages/{name}", wrapper.GetPackage)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/distros/{distro}/image-types", wrapper.ListDistroImageTypes)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/openapi.json", wrapper.GetOpenapiJson)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/signing-key", wrapper.SigningKey)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/version", wrapper.GetVersion)
+	})
+
+	return r
+}
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+y9+5PbNpI4/q+gtFfl3e9JmrcfU7V1N5mZOLrEHp9lO7k7uSYQCUnIkABDgDNWUv7f",
+	"v9WNB0ESlDSzduzdT/aHjUckgUaj0e9u/D5IZF5IwYRWg9PfBypZsZziP89+nJ7lHP5VlLJgpeYMf6fm",
+	"R/aB5kXGBqfww2g/eXq0/+TZ0ZMnJyfPTtLj+WA40OsCHitdcrEcfBwOSrbkUjQ/ZtXojik9Ouh+gF/8",
+	"WvGSpYPT/8N5/Rjv/dty/gtLNAx/9uN0evS2yCRNX7NfK6b0VaG5FKq7hntCMhyoI3j530q2GJwO/rJX",
+	"I23PYmzv7MdpbO7pUWchdnIcdMs6pprqKgJ/VWbwn80Ig5d6xn9Dl91Bb9i6iRHNaB5Dxi3NKtZ8led0",
+	"yUbzimcpK7duJczkhumBcLd9ZMnhA/flMjl8AEl+PkIY4lrugYxLs/SUqaTk+NPgdHBespQJzWmmyEKW",
+	"hOeFLDUXS0JFSmA+pRkshegVI7hpY/JmxUhSfzgTcoGP1RGRZi5CS0YqxVLC8ZFi+AvLC70ez2AFLRaR",
+	"JEyp6xu2vuZpE7ln30/OJlfTb68uXr58cvnT2YtXP1zG8JzIYn2t5bXBkeou9bV5QLQk8C5CfPZiAn/T",
+	"hWYl4ZqsqCJzxoRfOaxAwKszYQYmdq0VYtjiQhacmTVrulyyFJGnVhQ+z/gNMwPAZFwrli0MCvwa/29Q",
+	"qRGjloJoMVKy0iv8AXeYa5aryPH1WKBlSdfwN/ugWSloZrHYRMClfUgmF+RuxZMVLkSXldKkkBlP1m5x",
+	"pcwYsYSnxlHOLDN2TUvRnWVy9sJ8D3hVqsoZElaNsyG549rMbbad3LC1soSynglAo2J6SGRJWKZY/XpA",
+	"cw7SO1nesLKFzwEtxSm9U6ec5qenB4dHxyePnzx9tn9weAqQ7W3hPcOBYknJ9HVNlU2SvPsvmpU/vdXi",
+	"28sXk73vn7y4uHz5fG/+6sPrBT//H0uj31/+z2A4WMgyp3pwOiioUneyTOPTKcWluNbyhkUwOjWPCT7G",
+	"hTM4pbRchwgc7zwb0OU1IBUWKCsryANqDDF2P/pTghZqJfW1oHmL4efrkXsag0rTZeTMvqFLv9VnLyYK",
+	"D5ZeMV4SN5gawgmlacq1QZJ9DhCMBwHwW1jwG2rgaKzo4+7sdXq0nbuaA4DcFMEk8yq5YXpMLrlesbJx",
+	"HmRJKB6kmeDKHcb0MzFPA0dnw+zPkQ/+ZDR/MprNs7VUF0tKG/WVPuX14QYEzXmMqzhuYvcWtwm5SJYR",
+	"qz+c4hMp8Hfz23AmFjLL5B1LyXwNotxJfqMiuE9h2JY2YuhmV1YEVlSEuX5ua6hMVlyzRFclmwBG3qwL",
+	"FtuN4L0mMB+ePr5+fBzbB8TwtXYD7oQID8NELGSUNTeWF0LVnPA9Lk7zBU10dzldSZVydTP+NZF3hz3i",
+	"8/DkcZeovmMfRkwkMmUpmX53Njo8eUxSvmRKOzJb8IwNLUPE9SpW4j8qzdKZuFsxQbgmJUsYvwXFU4/J",
+	"S6mJYho5G3yv6sfzNZHAV8gtK+HYGj3cDWworgs9/41FDj7/jYVQAkHP15qp8KhzocPN5UKzJSs7G4H4",
+	"tBNFyey3qmS7GWtmE90GNUF+SXPW1MUBY8Y+mWiSg6yZM1IJ/mvF3AFd8lvU7ZWsyoSRZSmrYjwTkwVq",
+	"C4QrInOuNUvJopS5PdMI4xCEMRWpzJEnzKliKZGCUPL27eSCcDUTSyZYSbWT0Q1BioDF9iOTCdX2VDcX",
+	"+IN9Qu5WrGQBn1IrWWUpmQfrDm01hkYJVyTj4oawD0VGuZiJlbwDQZlxpZHNuYnV6UystC7U6d5eKhM1",
+	"znlSSiUXepzIfI+JUaX2kozvUdi3Pasx/sctZ3d/x59GScZHGdVM6b/Q35xKeQ0TXftJHrVQAjyLVbDZ",
+	"cbeP2aBr3KDNe9/czB2Q1d6dN7JKqHhth3mOM8YOTjX3IESVnskFgBS+9gBgjtlJ+nR+mIzo/PB4dHx8",
+	"cDR6tp+cjB4fHB7tP2ZP95+xKFPSTFChN8AFQJiXdoGqS0CKrOTdTGhJFlwAa3JHCo8zeSVLTbNdSMmR",
+	"kea3bJTykiValuu9RSVSmjOhaaY6T0creTfScgRTj8wqWng7SZ6wxcn88eggOVqMjlO6P6KPDw9H+/P9",
+	"x/uHR8/SJ+mTrRKyRmJ3uztEGRzdLVyuT6tpcrdd2EUL3mCAGAjfZBUrSi70BadLIZXmyacR5gvOsjSu",
+	"VRW09OLOSiLHQa3gAw8LPC1KOc9Y3tjFgiY3dMlUbNJaoHdUwdjrOVMKcBgzWhW7ZSXX64jhUpayVCSn",
+	"N6yxhAXlGUrujJE7WgoulsbdQ+ey0uYcqZkwK8z5cqWJkCh/7lZUkzsKKqJmIgU5D0sWVQ47yGC+wXBg",
+	"xwy2sWfLPej1Cof9qs9msnhHM5564dMki9STzO76WozeIlrsLUzbRf2PK4YmcIxygGaEJIitgDjmUmaM",
+	"ig6SzAzDxiKimJBSv5BpRLn4Tt4FDHIupVantXWYsSVN1vgzQYZZAsl/M7magiWpjp7tf5iJnCYrLpiy",
+	"Rujby28n9p9zqVfkr6v1vOTp39D4BFqhKJYb1CGkYMBncLrBcFCxBdC6+TJCK8PBOc2yOU1uuis6I29f",
+	"/0C0tKeQknOD48tbJjThiry6mr5hKQgHATSGC1XIuex5ngm3LcmKiiWsDJWkggn0aFRC8wwEg6qShDFQ",
+	"S0BnpTwDgYLzKGtz86VgqUEGFeS7F2fno+l3Z6Awm6l4SeYyXdcYN2YyoWombtja6dBcEQXQrwLN20ql",
+	"n0Z2feVoypeCwtEgKwZ7NRNUkUdGi//7rNrfP0qUewX/ZI9iThYDAvxrJ0vbxltqPuUEY8LH9kcUiisp",
+	"b9SeU9q3snwY1nkNojR9nknBXjNVZRFTp+0dOjg8YuCxGLGnz+ajg8P0aESPTx6Pjg8fPz45OT7e39/f",
+	"Dy2AquLbDX0gTQAkoK8uJHbB18rLxk2cxY5lBenHoV1In57j6PRuBf9vidgQbToeDD85AoaDX+T82vDy",
+	"LStBAePs2ZJRFdP8f1ytO9KHpUNwXJkQRdRRthsq0ag2iHyH0bTI9vmxhu19Cjb2B670RLM8srclAyvo",
+	"murY0pho7hFVjs2zNER1SjUbaZ5HDYTPQ8ifC4c1PkL8yUhAdcEFVyu2RTyiPxGFont/aCNYli9qRTK5",
+	"JKlkSjzSM7Es5R2hYp3Lks1ERICCMrvcrtHBoEpTGx/UoX0M3H6xcN5ULy4yps1nUiSsB/i4o8KMFofJ",
+	"PANpZme3kKgGpCCdwtN+sH94vNV7AXjwkw/rDYnyWrOTL6jgC6Z0RMXPw0fddbjHADWjsHNAWUYlqLLM",
+	"BmRBt7S8zH0wEysKW2tild7xQNZMw8cJGHTmcf2w6YGE8ekc0KLLikUWt9HZVq9rE16YpinVNGZ0GGdc",
+	"D1qMn6sOHBjXu5H1GLy1rjND80hQNkA9ExaDqgIlR4XeXQy83sMNa2GMqbCg+V3nVnPcqBI7DRPj00Iz",
+	"oa9BgV/w2uuzWfDhN++CT6xsBxOHC1lelyxjVEV02BfwmNjH7lykHE7YvDKhMo8c4MKAR42HZkg0vWFi",
+	"JrwTzHoYYRRgLm5Qa6+1bPGn48fRA610ydh1IvOc66j4/uuKqtXfHKgGHvt6ZDxvLHaGemWeGF8XF0lW",
+	"IX28vHz3+mzX7bdjeBreKTJoCd+6NSOSMVDPN267e+/TkQ0OVSktc/4b9e7WjYM03/4I9pTSpWxK3nLF",
+	"stHT2P6wKrI13yD394Snaj/r5QdjH5O3BYh+Mq2KQpaalKyQimtZclYnmuQhbTtzwLl8FQRUTVoGomEP",
+	"4CYF1SszwGuWku+oJucXLxujo0FfsiKjifGuu+9ZpcY9YtPY2lYKRdY7MavU0rCpoWHzaNfAQZJ3wjrd",
+	"iKblEgA/yzJ7AnJjLdXnEpeuaM5IcyfvEVxCeBx9RjjbfXkKJa+/u/yhh60o0oQfPO3aYdjw5n+zY4HF",
+	"eUtLDiLJ2XBvX/9Q257hRrkNT9mCVplWLpie019q6Ma7cKWWWLME3tnW95vO+ldjam32M95bta3J23wa",
+	"47RT+6QRSFWGMLyGD8QOmifRKypMRDVHqeFDK3bDZWldKYFeqcYkBAIeCbKSWapmouOiAC0p87aFIxNj",
+	"PoH1RMXaijCrKCj78L4nqMbQRjWpgXmzU13G3Ot8suynhVuuiBEF4FX5Zu0OwZBIka3NWXGSEb5sMDjc",
+	"imTFkpvrZbHE01mPNREEyCzRMwGqzdAGw4PPkcGu6C0jlCyL5XXTFYPZflqaEdczgW4u3CLvgHHc2wrV",
+	"eqvtJGt0P81Eygols1uXhdgYxFCXYZLAQt1ix+Ss1kes5e8nTqiw/li3Xtx4FZqi44bvzaIV7ThAStzd",
+	"1quedS2BHbTFHrFdCQf0db/O87LK5+bwhLsfaHi4TXesBPaqNM0y64GTlZ6ZCdZWieZlgO1Q7nmbaTj4",
+	"JBA19kgZ4NzANhyuV2zdgjoKUds+AWzHoIxjM8rfO8pSc0PDhQepc4VUelmaMe+RNhdEm7ZRyTR8F8hD",
+	"sXJ3J/1bxcouBDFl9iKQ6ZvjRm0cUHiIoSMbQ7oXLroBMaNjnmwVh/jlsAXa+9ZSds1s2R2lPXkzkaWl",
+	"LZTef4mNEWJLrR2MUbci9R4TWRIq7FF0TvrzjMPKSE7XoEatiXTOupQNXXjLepWsQ5XMmb5jTPgklCGZ",
+	"Vyb6ZT82Xk7UyGfopEc+4ISy1xNPZ4KQEXDqhOEph79SBtEFJpL1yHxgsrJSppF7UxvHRter4y6/yLkR",
+	"g4QQuz47DCRsDNGpYhFPAkETyhIzORcYSBr9IufmB/d4JKQeLWQlaihhFGZhND/WQm1UCXpLObpb7CIN",
+	"ox+FNl3jY2Mrj0q2GJVMyazqvOE8MCPr3+mOgAZHFG1wTlx8x4uG8GPjcBkhWs0vxkjZPJqxYMz7N/IX",
+	"PuKC65FzXuMvjWHsb6biwP1mtg0faLr0S3pjqTCkHachGiuDpiOvT1aCVnolS/4bjriQ5ZynKRND4ndu",
+	"CJuwyHiCrytjbLJ0lLOU0xEcwuFMVKIoZcKUgq0bMaG5Xg+JlnKUU7F286khailcmKRXg7ZYCCmxOkA3",
+	"4F1TVMyStpiOmGMSBaMxGsD0MuFofySGlvKBvqWwCYvGG2Xpo45adN1wTKTuTMlKF5XXRO23EfKJJ/O4",
+	"KEe97ouX37pDmyRVCST1gpY3XCxNBP6UXNZPSxuWJLl5pdYn0D9q9AJc/yl5ZbIKlEnaxO05JTlXKvzu",
+	"lAgp2Aeu4BBuZbmJ0SXsMmKqwvPzV7vls9VZ1vF8JioIggXATt+cvbw4e31BplqWgOcko0qRb0zCeDu/",
+	"zP6xIWF7CZBdS3W9YNTLt1bGGTeuaHyVXE2Je5VoSZhA29zb9+a8YBi50oxciiUXzv86JlPGiI94ZrJK",
+	"x0splzbmaVMeMUfIpEirPRMkGaUsY/ifomQJ/FCU/Bb+a177C4I2kmrkQOtU0kCg/fr86sWrszeTbzDZ",
+	"/fm7l5Pzhg7ilPy+d4eD6eX529eX199cXb0ZDAcv3v7wZnI9eXU9ffvNy0v45d3k9ZvJ1fX0fDq5xqf/",
+	"/fby7SV++O76/OzVmRnux8nLi6sfp1Hjoa0cbEp2hONo+Kwklarz3P02BPVGzR2xKZGGg+K/zUCt/Eiw",
+	"BKwV/vz8FSlKCcTdsu6Qk+I7V1M7lnVeIf9DWMYEkimlJqpgibG0XOLkTDxyrvsRLfjIxN7B0WHD7sQg",
+	"x03X9N9jOcc9EivrZOkuKmGJ5nmQDOfXdMezDFDjkatliF/ryYJxsB7Ro5IS1BdwdJcbtuUkKHO2zUlw",
+	"3yibkRoicWgM5yrTfGQhd6+TJJMKY2DGC2ay1Gbir+Yfnn8YzuE/+xugOQEbTBBaaQkSBPzT6zaSWXWP",
+	"IqI4Q7F4wXUT9zrAi6NsYih+S/RqPBOX4Dy1RIJYB0WKckGox5RXA+00BCAfE8x1IkZQhurmI7CeTn9n",
+	"OeUZTz8+OiVnguBfUFFUMgUkSNFpWTKFyrGfKzG6SnNZY/JtrV8OySOa8YT9Z5Dx8WhsZ1asvOUJOzPf",
+	"3RMGM7Udom/ufD2SeoWnrfhPWhSqkHq8tB+5b0KQMLPxvtiw63e51ABXCwVpzoWK4iCVOeXi9HfzX5gQ",
+	"jyeZVlwzYn4lfy1KntNy/bfu5FlmJsQkcMVK62Kg2n7bxkh99B6BAfSoBVP81G0mTa7MN4Y52JwmKC6y",
+	"+O1WerLytEMVg+GgRQ+7bt5gODDb1kXzYDiwCA5/vJ9joj7mViZsOOaTC8R/IEDuc8hnAqZp2mfwkSNy",
+	"PyR6l6cG3+9enYPnUmkwG0HrQ6+wqt8eBgFAjR4nm44BAZ6cCrrEpDEzgCFiNZyJhAoyr9/10RcnTZt7",
+	"CqWNBsqRnfc+WN69UMormp8upRhtFBi/UzpIVcJESoUezUvK09HR/tHJwdFWdTkYbrgtQ/k5E6zkya49",
+	"GBJ6Pa9EmkUUpFeXL3wKYAJfoFltNFdTdwh7zKi3aNRaaZY/UkQKk7nLBEgTwRIdlGcykRaSu1PcQZ17",
+	"3IUHki2NQj89GoHWQzVH9RnXTqzcd7Rd5yu84GJyRcDqPmfFirx+/uPYCm6bb2zYcJ3oCGFNsyauIGLW",
+	"lt5O9ci54DLMPDx9ZiJOO/XcCMvTP22Dg+FA3fDiWqmsE+F2LvjTBc0UG7YwfCHBCYXfrBt79UiFFDAm",
+	"VxAXqRQzKEINlqGJFY/ptsjZb/FwaxeOFjV/lk4c6F98VcolUEHkGNgnlvZC1z9XZM6AtDEeexr4f5w5",
+	"DzVMZSUgEX1o3GFSmZpqmksIxWSZ+aLpyB+6GM1MFKxMmDCDLuoZlE/9v2U+62tMpuEzC8RMQOqJTQsA",
+	"GBKarEzvCKCTgtmOC0V8oVKxmTCrsU4i4D2qx9kV9dGAv0E0K6GPYhEQu9bGiwePo2/ygmVctFiyVD0J",
+	"iMv2i+VybLEzLotoexUtNW2mGx8cbo2RmKmGfsVumHppvQTYm+DykGoS9gHkM7vems+jwjwPG86rY1lc",
+	"hCQpGBbUzwSUL/GE62xNBLi8Ve1T5hHvwYKX7I5mWXo/NemexSnGvbuNa15N38BbmOq0Bo5yHUZjY/1N",
+	"wlAvogrOjbT8D+1Yiy8rOhphOZ871+2m0AgDj8lbYXuaYJAdQ3QUEkthT3Ai5yYwJ7GUshnEvke03a9p",
+	"HS+IbuLjEwxpHBobMnreoJcbFQvzbr0uoqXhTt4yMLkvmPtKIVrgCza1NJlABi05BK8fgWWePXKjWgjI",
+	"DWOFCvYHtse5UWwFhvFhcE2s1prKO2HnAZV6Jlx9wd7vPP245x7fYxsa0tvmCU3MhwcPrcsetPavi/v3",
+	"jt/0idIe57XLnw+DW2H2PO5RnTjSTFWB7IdOroqvWmtEmha8VNpt/YrqmXCBtIkmBqQ5U8QXBzh3WlBw",
+	"qaz1wWg6E5ByZiNwTmCCtmsmVT06aH8phJeLO6XjOIKzri2LHhDt7XQN87qltTr92ya1zQLm0Ji5rZl+",
+	"2dqLItChtqb7eIXrHygR8OS92wANHbL98S6pWOaDloLU3Xvz2oZcrNahvDfT2DFVKkiS6uAu8NhjbZnC",
+	"tgSUZ4al2AjXYDhwqeCel5h/B13Mop74ZqOJbi2ksUOut7dO8AwaeydAYDFjSgWHrw5ZkgzkSElMiV9Q",
+	"o/Dk6MnxwdPD4/0dOi/EkiV6+lbEUyUaSwPcfy9/4duymO+XBtzNkN2JeACQbcmqECXeZRznUOhPLjFh",
+	"pQ2xBJ8PW394uH94cLB/eDKOWtE2HaL5ydPxye45qHaL3EA1FHbhO2WpBvu5S3rofbuz9B1uA+K1CTG3",
+	"HEvHhzFC/kSVV77oqrUqR9uf3nzpU/17+8d8cqX1oQpXH730+t/Ski70ds/MBL28jTRwYHj4tUuDb6US",
+	"ZZTnRsd9+e51WABiQotW1yhKmUsXkCMlW1YZtfU/JKMae95c1LM0GjJyoaUB4VrTZcMbSJd92f3+/Whj",
+	"OgADxqphxUlQ2+daucWj1wPrtJz7ezgTRhnlLby0NCTDVMcnI3wl3s+rvGVlvNAYTuB4wVJZUuuTHcty",
+	"iT+vqnlDsyqz2OCffN1Wtaz9M8oq5Qm1xWpms0wKmdJ0rcJv47hJqEh5SjWLL0HdbGmKAhRP4L1gPXOW",
+	"SbFURMvBcDPjarMfsx/1xLEzBo19sj6PdxPM753xRzq2X0jCdVtBrodESRMCM4idiaZFiBZjj0EIdovt",
+	"tWD7uDZORr2Eq/PJp894ucLhfbzafBouXpHAfoZ1LTD/yXes5bbux79lAIYPTV5JakoG72iZqkguQX/y",
+	"DHrwS52zmK//6rxZem5fDFOdbUaBCydx0UzgkQlPD8bBt2OZHIzH1P6vX+zEs0UuuCoyujaJHl41taE3",
+	"UzrkO2V9Hcka8L4qaBJZTIsq/JuNpkbJOuimGRzfJprpB/pBFEkpy7uT+6aMXJ1Puikjvfki41YGxWhR",
+	"UnGzqMpdGvX5OENIdSGOhptihP5obtb3eLqZkOP0EqFa8wDotbnMjdTb08nwPljyy9jY09D6UCPZ+2X0",
+	"LJ9DcYyqcocG856tQh2TF5WuIEeGoNNa8VtrU1dlZnxxLkk6+Babt2J+cEowon/HnTMkgpdFK5ce5Nze",
+	"0z2jf+6xdMmiJmxvUKmDEetMx0LMuAa8q+ZrtHvNDQvarXEDK6SZYBdTVufVh53NsePxIjl6vLMpdjQ+",
+	"pLtayQbouCmGCHtf4zXuQjD66M7afmOPYnUEQQijr2uD9e03i31MbwSqmfKaeJ0eYSrkB8NPBWOjoqWz",
+	"KyuZs6KvQ9futKCqHHKDtkdU7V6695sABuAM3Wa10Bzs8abGBskqAspwkFi+sgvHwTlcwZYJWFn2Uad9",
+	"mrirAmerMhoFqt51f4hdDl8X35uOWr8hu+7Nf2hV9zXW11wZth3Awsw5VVj7O0THM/QgxIYYOQf3bcbt",
+	"4iKNlVIxLlm6otomGdfF33vARZ/WbBSmkGqvJwTLl3l6El2xL1bbEBT8fSMD2kyj1krf4PlBAvMwBjQ5",
+	"ZfCor/owjK3e53RP7WmJnG8fc+6r+supTlZh2n0zFYth5g2Zs7W0JZ4Zb3R66LOxwiaBCEOIhZobbGvy",
+	"e3+G4nekh+f6Ip+gFooq98eo3sRAvIMYGlnR9fCUsQ5v84ACbl43zmlLMFHF+luVPeBE7e3gWPAVyBva",
+	"HMkwtadZ/BuJXQdMJurIsdXK3fnOpueTyYiWuSxZSp6/eg4dzlulzDtPWK/Qca44Xg0rUxHnjPvuP2D4",
+	"v5vno6NDMLkOH8MB/7tXx7YhueaX9wbCf9kE4+hBYMi0ytj1SuoF/8BU/473I9jcHPOB5YVtg4FjUuyH",
+	"baM6sT0vVyoPjlJfghe+FjMdpq2a3PadCxoKU6A7bKdrP6aDJiXTI3i0Y2t+OEHX0aPYPYk74J0LBW1I",
+	"m6W5jVZPAapkuaTCljo3PjjcP94/inXNcq7HLsRhKfMYkBsAvlXhbgAybCO5MWmAsWC1sY1sJi90dlLW",
+	"visp2NVicPp/D8pmHHwcbv1uevSgL/sKzLbO2NtqfduXfQ6+rZBuzOjd9nW/Z/Tj+0DB2h50thXYcfXK",
+	"bXg/rfS5TmSfn9boXc3Qe1A8JWwTf9MitX4FnK0z4fulGqfAPYnQh+B2Jr4dv2gnm9+D2Hb8ou2quidx",
+	"ua/eN8KHu2UK2DzXDYV5DyczH4M0LZg9VfmmAw5Eegdv0Ts1xuvclkkBf/5mYJUJh9/Mks0LmCAWBRm7",
+	"OnTIlX0oeMmuMTjSdRJTLO+v3VWuYAq85RwrntE7QFIIwyguEkYOnj3ZH+0fjPYPWs7dA0gmj0mJhYSO",
+	"6U7uXZcs2tPxEgG1epZ5tRHGwOwriR5008XVlaFiBtZMZHLJRV8Qb9mKPx/0gGpqP1rut7sVY9n9ckHh",
+	"ipxImsj0O1JU84wneIfOMEjQpKnNCMRd8IXr+J5nKYqV46bpoNRqxNLDk5ODZ+Ts7Ozs/Ojlb/T8IPvf",
+	"i8nByzeXJ/Db5LskPf6wOn7xWuz9cpM/eyV+mdz99N+5+HWSXeSTd//74ui/z5bfX9wWjyuc4+A/H1wq",
+	"lMnkJta59MIQE7TkXKKbSdgqKb/X4+i+dU02BDDabHmnLY4ZTTEZ8K4215vnaWc73r34/uNHVMVi3Tim",
+	"tqxJyyDQ7dJzTQh1PBMzYaEhh/4atFcTkvLFgpU2Td7ORg5Mqq91Bbm0kNOZCJuGmKA4rD0lP+MD+fMw",
+	"bNcWpqjWXR2gYZYgP7eSwX42uf8AMd4JgOTa7E2HYypBb9h14ruyZTxhwniVzC4PzgrM8z/EZBrUKb2h",
+	"cnd3N6b4GK0T+63a+2Fyfvlyejk6HO+PVzrPkCa5Rkq5mppGg+f+lh2ovCW04IFf5XSAF3rKggl4AD7h",
+	"/THQF7YKBOD25q6lvdq7Nd3yEehCqr7YgYkCNlHQyL+0uCN0SWG3fRpw3ZSM1H0lbIOmoOmuz6YkDTev",
+	"rUGe1Z1G6jupgqzwRp5afbONCJKYZwJo2wxo22JE1mRVlcK2Hpmkg9OBvU+A+XsABuZUMKW/kenadMBA",
+	"FwL8kxaQmI9f7/1iG0QY6bpjN3CfGNY8fWDj4A+qkMImNB3u73+y2WPXJyAIESe8a0YRvxADiO/4E0IW",
+	"5Ld24ZmYZjZdGEKXl99CQqMvQgG7YAqzBgCaZUyYP7dB+2ZnPqvwmqMAo6R1SLFJRtjBfup0qIKWNGea",
+	"lQpV4t6W75lJpOQCzWy9cm7U04ENEYb0MQww+slbNL7/jMTXVHy7u4xosJj/MuSFAPDUTH78x0z+VtwI",
+	"6KRaT94g6k62P7xm6dkQecjTXY/e8JoCf4nB7//msuThfoq/7LmX96oy+xiO8hn5ngFmd67X5UsMRrCd",
+	"981dcjBcBLHnmK9CKBHsru6qWUht7sbMsEWusuk7ckGwjyvNvDpRl7wYMefvZk2xAtOLkpl47RKj3aUg",
+	"k5TlOFGyHn3P1vbuDiMICdUkl0pjArsF6xRS2HW5NkLSXznkLxChOUPXLsg6f7cIF+TwmKxkVSr8vCqF",
+	"zWJJ2zy7rvdwYyMo3mhvsjDz0Vck/w4+/eymDXmEaCzGwGizODLc4FnsVpr4TvF693HXODZ0KoIyjOPD",
+	"wzhxtz8FMvdFaS3KoFaRhuMARGEGfhYfOPRNWotUQsB17WhEkV8rVplrb5x/ocmJ7Hmy7zdY0J5LZu/R",
+	"LcOTaPuEkuem9ZssfRomZjMO7Z9BgZmv76hb7gE6uA4698B7uUnlrDMhTekadlvFzgp5UL9b53Jyhb25",
+	"TBZYs91uXYNl29pQzB3lwa0fw2A+rhoJuwAgNvmvU5JglOYWGxvD1ErZ69ZhcXuNuqPoAf3e5NF/jkMa",
+	"KaT486B+woMaO1cUyd9QUvdw3Vdrpe4Qmxs1zF0mqZnCjounRy5CG86Z7h2S61Zi7KzchlP+6+u4XUTF",
+	"FF27AV9S1bUgfCllN5h+o7rbdybccTCp0RG/Hf7eKOMQNoPR3tDiU9FNjTLOVl/q4/VFm6ft6sTrXOxG",
+	"17FWUW7jwjmuyA0rtKkLMGnttv7fmpL2bqMYqzfLqDWyXQ3Kr/u4HW+6Oad9DRqrkf6FT4q7HIokgeyB",
+	"rXRk83Udpe4RCF15GDT4h2RJ4MvjymZHYe8GkxJhb4YMr1R0DlclMTuup/lriTJ/3Kf23Ef+NBkt0ZLA",
+	"kv/lhdCfAujTCaCouYOFR8YT02v0vG3312hIGA8Atuc0cskEK+p7DfzdYNw63IeEjZfjuisdFeTsxQRb",
+	"nqBpZUoJoB2HHX0mIkXzvh4p9GRCQzn8xxD+ZfwcPFUbzI9z67S8n0hCHtrX3uErOpmf3qZqdSH5g82p",
+	"4GLVHn9/3fYFG225SNoXZg5ALs2eMr1SeM6YMIfGXCPYUrNm4utiNHH+0MsWIgzIVT72moUX9oW+SVyf",
+	"lpTQngY+w5mwlbGIZkCxaQs0Jq/xAoVGG3vfiN7nP0DgVbOyrArNUl/Iqer+K6rKnTfGEyBoy8aOVgRq",
+	"4fDO45a6rrGvmr2I0Vmvv8i5ajSL3q13DFeNElPoX9i1iRt8cQNbdDi/N2eELU9jG/bPqK/IRDM9Urpk",
+	"NG8eKD/PnAsayz/exJ0sxpb8lvmTBYqi8a8jXNa9OIIyUtsBsAlAN/nhgi9ZTIJPvzsbwbXeKT5v3tLl",
+	"i1jwMu5RfRv3nCr2+NiWmQ4JX8yEqZVHdmAIZxMwl29ihevfBZeEx8GyAPnSfDPdxslgusP9x3/kNtaO",
+	"3BLZRwh8z+5+JTYfT3fjJl9YyAxbojElQvbxduzU6jlcQ44SL0Y92y9ZLm+9GD1ok41mH/RekVG+5bjt",
+	"RBYu65RqrhZ4rWLbtt0s2SLCEsOGqldUTpHGO4avd5UqYrK3R4oJbWKQKpByrvMZyDW1knemA6lr8+kS",
+	"YQqZZdC6FfrlmVkemaGG7tI37FSGjTUaNtwQfsOZS8gUJ/SOmmAgdP7F6yfR49S4LN5n6XnLXLdajwae",
+	"AdT5rPLknFmIECZSFUQxENq24LL2vXEQmDZyvkFtVEZemr14iIRUkX36ZxGQeEQQg1GWuvWknLnVy0WL",
+	"fv404UPmsPksR3iDvT5+ozsMr4Ff+HbD4b3dEQY0Jj+uuGkB7ztfNloYD/2gGEyE4L1rb/Kzub/9Z7zv",
+	"K8phFjLLkMeATlpQcz8QjFd/agvNSnbLZYX5AYY0oaN07132tfCob74PuhnNRAhryZa0TDPLS9zMVv22",
+	"n+6mfNfLlyVGo/Ci4/ur3j/I5YN4yrKxxV8HNxl27p5ea68heew6v2yDQZocE7eMXytWrut1mG1qqIW+",
+	"H9Y+dkbleZXjvzsZyH+AyxK2sEdHsJ2y5PKr1BONWwtw+8+mMbaYp+N3DXRvYp6ual9tZaGOd/ovem9d",
+	"bt687S9vtF4DV+BKq5SjjlqyopRpFfI1o8T4mcxl5HW7gc6FzTUUkVzXDTznhV/9P8J5aoz8c5r7Dzrr",
+	"Nep6TnyIla12/08jZ/lPwz4H7Xanrcuc3RoxLwOt57pDmcJLFvmyKvFCX5PJAcXcwHFv2NrcjrVnf4Fq",
+	"2W3W/cc/tbQuo2ns8kZGEzQu2chnwpbP3YiL4QzsA010IwDptRDTOEXVN8A2cs/MlVxGy2lO9CBV52FO",
+	"Rt/D5c8o6HY243DVx2XcJrr+NH/6nz7DIQ+RTAPkdk55UcpbbIrHNp5ziB+KkZZG49UMexZaHj39YXpG",
+	"6nFIUbIUFs+sR2QmqNbIccBT0xcbocpFOiZaEVXNzf1d9jawmWhkqwQdys3xbTpp3SW72ORIs5LTzKok",
+	"Ot7uyieyhhdgBkt6EMP5R8Iar+pt+UcUncYS/jVYUKsCs7eYKti6P3WZ/wf98rKsj13E197DOHvOfIRx",
+	"YgFJfzqKKSltG1w23d3bXUu8j1Skwe0wuS18mQnMmbYVtTYVxXiGzThAiglF4wySScSipEqXFbbyngld",
+	"ymqesSDUq1pmXlcbDPNGZsJ50YNr7w3j8vzTJsk0k7yLksuSayDo85KlpvjH3VGC2YUFDe+k65S6lgy2",
+	"aiYwLD1fB/fXKGmuvrO/qCDnAHaD26uNsZe06fkbaJMI7AaO+xr38yHMtrRffpXM9Q8sEXgToKXpfTXI",
+	"/2rTSe2dP4ZjzER9JhK8eseS2NIU0Pg3bU1ceKPNTCQByZuiGdeHWn1tCTGGQ8V5UoTfqbnMtxdEyIW+",
+	"QzbDsQyoVr86oWdap6hZLTHjzkcV1dAC/YxMMObud8Tzs5komte/2XTmnp6YXJDpq4ufyOH40Fyjucbc",
+	"pYufyMH4mPzX9OqlVQWn31y9+OMd7NO5zB/EkZz6Z8H+Sl3s3+IHDnyAtceJbkeOOtEHqkg/DIZ1Jx/z",
+	"Z2J2Mv0weL8Ll9zElGDEf9+uhw4bH92KdOxh+PcHarGO6v7UXz9JvMCQ0T+lJtsVW+a8xJXYzVw4rtT2",
+	"hxPwwu4AgAZrreWfYWnNwldfmoPB1CGRWcqUNqXRY5dygUBJW8NQf2x7z0C2xUzYnApb0WDv68QXPFA1",
+	"JLec1pVwZ68mloeHWqNNhtQlT3Tdvca7LiEJVtR/4aXPVPj3pGjMh/ngBSu5RIrSPLfX3Ho5ADeNF84K",
+	"+Blb1/7s4YkJAMC5Q882CYBXFkf2p3GB7pDYFmeLCirxTbGIyUjyK6qbTCkNhGMboA3r4LtPDsbslR5u",
+	"7TuaBbLEMmcHzWA4qPE7eL+DrOhbZIsIKcanXP4MV8S2lo/CyY13JZJkt6Er/YNB84Vtm6HCrn+fAKq6",
+	"tbIHS0u8FvkzRKo7s7+gH+BtIqJQmHMxtKXitpTc3C2CYq4HQtfyuQbQA3XwGcLnO7W+dnF0rvREszzS",
+	"fLnD4hukYmVxyPi+iJmkbN6bYV5BZN0gvSlnuhIhugyQLqZp2A7Cpdl2zGbSGbeF5dfWc4E3o1qPw0yE",
+	"95epsLoaL8rt4awXFqg/gkAugnXtSh0+r7+Jlb5d2PS624C9380/Pu41MLb3O/z5cS9s+R7P18SO8U3j",
+	"rJYXW/uI0WZfOVOXGABSb6lvh77LZhqoXtU93jcKypfBDUchNHHzyF+tuIuJZC4YO9mJMQdQtG/f60Jh",
+	"W/jvAkPf3StdEM5lntORYoAtIJtlJucqaO4p8Br8dvN0bPKGffr7BCq7B7S3PP//hvG2+ruIEw+VFyc7",
+	"Cw0v1Q72/+isq9j1Cz08IHIhgjKHfG7u6voyYsJs8ZewiBr8Q5bNs9NKSI0wKzR80gYzvj973PsdML+9",
+	"L0jwq++XWPNIe98kQmRH9m0VZyLCXmsvGIX6LHd5tmpczhPjj8+ZtiT3J2/ckTeGIBQed5HZ8T+7zd7D",
+	"5v4ATtN3qMLVfenDPGxpArKsQeu4N7otDPwZ2vmIo1o50q7B9ma9tE5CcieyrY4aLSVcQ0TjCaNk7TvA",
+	"FZaVBJ4oe9Ot78NFpGCbdVl/KfnXqQR9TkoPFewADT1EH25nDAtf+jD06fgtuGN0blsTjx2Elq47EuHK",
+	"vPdfyt4Wta0JZN3qEAt3kyo3LSVDOF3AwcJAAAZ3T2jiLujQdAkUOciZptDtejgIHchbheqryxfE1WTW",
+	"zdHtocTLd7gtugpuADL2yExEXOSuSyT6R23Eb4huVZs+VOcCzIRPelJjMq2HRycrVqB60C7PL6Zn3UuI",
+	"ZiJeR9rywjdYjF2Uiy3+nEgY1vy8Hs0zOScjQB0xpbA1UvBvRkYjD4Z9xf9t3vg5alSZPfmerQe7lVg9",
+	"tAqxhvcPPXbnQZRDSB1EOkg30NGXJlLTn93H9jd7Qf/3KFm7I6Nsa3f3fkSHe+cffTYu6qaI4It2QIyf",
+	"/e5bHz/+/wMAAEycLQDUAAA=",
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file.
+func GetSwagger() (*openapi3.Swagger, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %s", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+
+	swagger, err := openapi3.NewSwaggerLoader().LoadSwaggerFromData(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error loading Swagger: %s", err)
+	}
+	return swagger, nil
+}
@@ -0,0 +1,6 @@
+//go:generate go run github.com/deepmap/oapi-codegen/cmd/oapi-codegen --package=v2 --generate types,spec,chi-server -templates templates -o openapi.gen.go openapi.yml
+
+// The routes of version 2 of the cloud API are served by the cloudapi
+// package, which converts the requests of version 1 to the ones of this one.
+
+package v2
@@ -1,6 +1,9 @@
 package common
 
-import "runtime"
+import (
+	"runtime"
+	"strings"
+)
 
 var RuntimeGOARCH = runtime.GOARCH
 
@@ -23,3 +26,22 @@ func PanicOnError(err error) {
 		panic(err)
 	}
 }
+
+// SplitExtension returns the extension of the given file. If there's
+// a multipart extension (e.g. file.tar.gz), it returns all parts (e.g.
+// .tar.gz). If there's no extension in the input, it returns an empty
+// string. If the filename starts with dot, the part before the second dot
+// is not considered as an extension.
+func SplitExtension(filename string) string {
+	filenameParts := strings.Split(filename, ".")
+
+	if len(filenameParts) > 0 && filenameParts[0] == "" {
+		filenameParts = filenameParts[1:]
+	}
+
+	if len(filenameParts) <= 1 {
+		return ""
+	}
+
+	return "." + strings.Join(filenameParts[1:], ".")
+}
@@ -3,6 +3,7 @@ package common
 import (
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -45,3 +46,21 @@ func TestPanicOnError(t *testing.T) {
 	err := errors.New("Error message")
 	assert.PanicsWithValue(t, err, func() { PanicOnError(err) })
 }
+
+func TestSplitExtension(t *testing.T) {
+	tests := []struct {
+		filename  string
+		extension string
+	}{
+		{filename: "image.qcow2", extension: ".qcow2"},
+		{filename: "image.tar.gz", extension: ".tar.gz"},
+		{filename: "", extension: ""},
+		{filename: ".htaccess", extension: ""},
+		{filename: ".weirdfile.txt", extension: ".txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			require.Equal(t, tt.extension, SplitExtension(tt.filename))
+		})
+	}
+}
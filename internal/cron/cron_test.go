@@ -0,0 +1,55 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"* * * *":       "expected 5 fields in cron schedule, got 4",
+		"60 * * * *":    `invalid value in minute field: "60" (must be between 0 and 59)`,
+		"* * 0 * *":     `invalid value in day of month field: "0" (must be between 1 and 31)`,
+		"* 5-1 * * *":   `invalid range in hour field: "5-1"`,
+		"*/0 * * * *":   `invalid step in minute field: "*/0"`,
+		"* * * jan *":   `invalid value in month field: "jan" (must be between 1 and 12)`,
+		"* * * * 1,,2":  `invalid value in day of week field: "" (must be between 0 and 7)`,
+		"* * * * * * *": "expected 5 fields in cron schedule, got 7",
+	}
+	for spec, msg := range cases {
+		_, err := Parse(spec)
+		require.EqualErrorf(t, err, msg, "spec: %s", spec)
+	}
+}
+
+func TestNext(t *testing.T) {
+	// a Wednesday
+	start := time.Date(2021, time.March, 3, 10, 17, 42, 0, time.UTC)
+
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2021, time.March, 3, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, time.March, 3, 10, 30, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2021, time.March, 4, 2, 0, 0, 0, time.UTC)},
+		{"30 9-17 * * 1-5", time.Date(2021, time.March, 3, 10, 30, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2021, time.March, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2021, time.March, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 6 *", time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2021, time.March, 3, 10, 25, 0, 0, time.UTC)},
+		// day of month and day of week are combined with "or"
+		{"0 0 20 * 5", time.Date(2021, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, c := range cases {
+		s, err := Parse(c.spec)
+		require.NoError(t, err)
+		require.Equalf(t, c.next, s.Next(start), "spec: %s", c.spec)
+	}
+}
@@ -0,0 +1,551 @@
+package disk
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+)
+
+const (
+	// Partition start and size are counted in sectors of this size.
+	sectorSize = 512
+
+	// New partitions are aligned to 1 MiB.
+	alignment = 2048
+
+	// Space at the end of the disk reserved for the backup GPT header.
+	gptBackupSectors = 33
+
+	// dos partition tables without extended partitions hold at most this
+	// many partitions.
+	dosMaxPartitions = 4
+
+	// Sizes of the /boot partition added for encrypted disks, and the
+	// space taken up by LUKS2 headers and LVM metadata, in bytes.
+	bootPartitionSize = 1024 * 1024 * 1024
+	luksHeaderSize    = 16 * 1024 * 1024
+	lvmMetadataSize   = 1024 * 1024
+
+	// Logical volumes are allocated in extents of this size.
+	lvmExtentSize = 4 * 1024 * 1024
+
+	// Name of the volume group holding the filesystems of LVM disks
+	lvmVolumeGroupName = "rootvg"
+
+	linuxFilesystemGUID = "0FC63DAF-8483-4772-8E79-3D69D8477DE4"
+	linuxFilesystemDOS  = "83"
+	linuxLVMGUID        = "E6D6D379-F507-44C2-A23C-238F2A3DF928"
+	linuxLVMDOS         = "8e"
+)
+
+// MountpointAllowList are the mountpoints (and their subdirectories) which
+// can be put on a separate partition by blueprint filesystem customizations.
+// Only the root filesystem itself can be customized, not the directories
+// below it.
+var MountpointAllowList = []string{"/", "/var", "/home", "/opt", "/srv", "/app", "/data", "/tmp"}
+
+// CheckMountpoints returns an error if the filesystem customizations in
+// `mountpoints` are not allowed by `allowList`, or contradict each other.
+func CheckMountpoints(mountpoints []blueprint.FilesystemCustomization, allowList []string) error {
+	seen := make(map[string]bool)
+	growing := ""
+
+	for _, m := range mountpoints {
+		if !path.IsAbs(m.Mountpoint) || path.Clean(m.Mountpoint) != m.Mountpoint {
+			return fmt.Errorf("mountpoint %q must be an absolute, clean path", m.Mountpoint)
+		}
+
+		if !mountpointAllowed(m.Mountpoint, allowList) {
+			return fmt.Errorf("mountpoint %s cannot be customized, allowed are: %s", m.Mountpoint, strings.Join(allowList, ", "))
+		}
+
+		if seen[m.Mountpoint] {
+			return fmt.Errorf("mountpoint %s is customized more than once", m.Mountpoint)
+		}
+		seen[m.Mountpoint] = true
+
+		switch m.Type {
+		case "", "xfs", "ext4":
+		default:
+			return fmt.Errorf("unsupported filesystem type %q for mountpoint %s, supported are xfs and ext4", m.Type, m.Mountpoint)
+		}
+
+		if m.Grow {
+			if growing != "" {
+				return fmt.Errorf("only one filesystem can grow, but both %s and %s do", growing, m.Mountpoint)
+			}
+			growing = m.Mountpoint
+		}
+	}
+
+	return nil
+}
+
+// CheckDiskCustomization returns an error if the disk customization is
+// incomplete or uses an unsupported Clevis pin.
+func CheckDiskCustomization(c *blueprint.DiskCustomization) error {
+	if c == nil || c.Encryption == nil {
+		return nil
+	}
+
+	encryption := c.Encryption
+	if encryption.Passphrase == "" && encryption.Clevis == nil {
+		return fmt.Errorf("disk encryption needs a passphrase or a Clevis pin")
+	}
+
+	if clevis := encryption.Clevis; clevis != nil {
+		switch clevis.Pin {
+		case "tang", "tpm2", "sss":
+		default:
+			return fmt.Errorf("unsupported Clevis pin %q, supported are tang, tpm2 and sss", clevis.Pin)
+		}
+
+		var policy map[string]interface{}
+		if err := json.Unmarshal([]byte(clevis.Policy), &policy); err != nil {
+			return fmt.Errorf("the policy of the Clevis pin must be a JSON object: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func mountpointAllowed(mountpoint string, allowList []string) bool {
+	for _, allowed := range allowList {
+		if mountpoint == allowed {
+			return true
+		}
+		if allowed != "/" && strings.HasPrefix(mountpoint, allowed+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatePartitionTable returns a copy of `basePT` with an additional partition
+// for each filesystem in `mountpoints`. A customization of "/" changes the
+// size and type of the root filesystem instead. New filesystems have the type
+// of the base root filesystem, unless they set their own.
+//
+// The root partition must be the last partition of `basePT`. It is followed
+// by the new partitions in the order of `mountpoints`, except for the growing
+// partition, which is moved to the end of the disk and takes up all remaining
+// space. All other partitions need a size. The disk is enlarged if
+// `imageSize` is too small to hold all partitions.
+//
+// Customizations must have been checked with CheckMountpoints().
+func CreatePartitionTable(mountpoints []blueprint.FilesystemCustomization, imageSize uint64, basePT PartitionTable, rng io.Reader) (PartitionTable, error) {
+	pt := basePT
+	if len(mountpoints) == 0 {
+		return pt, nil
+	}
+
+	n := len(basePT.Partitions)
+	if err := checkRootLast(basePT); err != nil {
+		return PartitionTable{}, err
+	}
+
+	root := basePT.Partitions[n-1]
+	rootFS := *root.Filesystem
+	root.Filesystem = &rootFS
+
+	partitions := []Partition{root}
+	minSizes := []uint64{0}
+	growing := 0
+
+	for _, m := range mountpoints {
+		if m.Mountpoint == "/" {
+			if m.Type != "" {
+				rootFS.Type = m.Type
+				rootFS.FSTabPassNo = fsckPassNo(m.Type, true)
+			}
+			minSizes[0] = sectors(m.Size)
+			continue
+		}
+
+		p, err := newPartition(pt.Type, m, basePT.Partitions[n-1].Filesystem.Type, rng)
+		if err != nil {
+			return PartitionTable{}, err
+		}
+		partitions = append(partitions, p)
+		minSizes = append(minSizes, sectors(m.Size))
+		if m.Grow {
+			growing = len(partitions) - 1
+		}
+	}
+
+	if pt.Type == "dos" && n-1+len(partitions) > dosMaxPartitions {
+		return PartitionTable{}, fmt.Errorf("dos partition tables support at most %d partitions", dosMaxPartitions)
+	}
+
+	pt.Partitions = append([]Partition{}, basePT.Partitions[:n-1]...)
+
+	start := root.Start
+	for i, p := range partitions {
+		if i == growing {
+			continue
+		}
+		if minSizes[i] == 0 {
+			return PartitionTable{}, fmt.Errorf("filesystem %s needs a size, because it doesn't grow", p.Filesystem.Mountpoint)
+		}
+		p.Start = start
+		p.Size = alignUp(minSizes[i])
+		pt.Partitions = append(pt.Partitions, p)
+		start += p.Size
+	}
+
+	last := partitions[growing]
+	last.Start = start
+	last.Size = 0
+	pt.Partitions = append(pt.Partitions, last)
+
+	required := start + minSizes[growing]
+	if pt.Type == "gpt" {
+		required += gptBackupSectors
+	}
+	required = alignUp(required) * sectorSize
+	if imageSize < required {
+		imageSize = required
+	}
+	pt.Size = imageSize
+
+	return pt, nil
+}
+
+// CreateVolumePartitionTable is like CreatePartitionTable, but also applies
+// the disk customization `c`.
+//
+// With LVM, the root partition becomes the physical volume of a volume group,
+// which holds a logical volume for each filesystem instead of a partition.
+// The logical volumes are ordered and sized like the partitions would be.
+//
+// With encryption, the root partition and all partitions for customized
+// filesystems are LUKS2 volumes. A /boot partition is added in front of the
+// root partition if `basePT` doesn't have one already, because the boot
+// loader cannot read encrypted filesystems.
+//
+// The customization must have been checked with CheckDiskCustomization().
+func CreateVolumePartitionTable(mountpoints []blueprint.FilesystemCustomization, c *blueprint.DiskCustomization, imageSize uint64, basePT PartitionTable, rng io.Reader) (PartitionTable, error) {
+	if c == nil || (!c.LVM && c.Encryption == nil) {
+		return CreatePartitionTable(mountpoints, imageSize, basePT, rng)
+	}
+
+	var luks *LUKSContainer
+	if c.Encryption != nil {
+		var err error
+		luks, err = newLUKSContainer(c.Encryption, rng)
+		if err != nil {
+			return PartitionTable{}, err
+		}
+
+		basePT, err = addBootPartition(basePT, rng)
+		if err != nil {
+			return PartitionTable{}, err
+		}
+	}
+
+	if c.LVM {
+		return createLVMPartitionTable(mountpoints, luks, imageSize, basePT, rng)
+	}
+
+	// the LUKS2 header takes up space in each partition
+	sized := make([]blueprint.FilesystemCustomization, len(mountpoints))
+	for i, m := range mountpoints {
+		if m.Size > 0 {
+			m.Size += luksHeaderSize
+		}
+		sized[i] = m
+	}
+
+	pt, err := CreatePartitionTable(sized, imageSize, basePT, rng)
+	if err != nil {
+		return PartitionTable{}, err
+	}
+
+	for i := len(basePT.Partitions) - 1; i < len(pt.Partitions); i++ {
+		p := &pt.Partitions[i]
+
+		volumeUUID, err := newRandomUUIDFromReader(rng)
+		if err != nil {
+			return PartitionTable{}, err
+		}
+
+		container := *luks
+		container.UUID = volumeUUID.String()
+		container.Filesystem = p.Filesystem
+		p.LUKS = &container
+		p.Filesystem = nil
+	}
+
+	return pt, nil
+}
+
+// Returns a copy of `basePT`, whose last partition (the root partition) holds
+// a volume group with a logical volume for each filesystem in `mountpoints`.
+// The volume group is in a copy of `luks`, unless it's nil.
+func createLVMPartitionTable(mountpoints []blueprint.FilesystemCustomization, luks *LUKSContainer, imageSize uint64, basePT PartitionTable, rng io.Reader) (PartitionTable, error) {
+	pt := basePT
+
+	n := len(basePT.Partitions)
+	if err := checkRootLast(basePT); err != nil {
+		return PartitionTable{}, err
+	}
+
+	root := basePT.Partitions[n-1]
+	rootFS := *root.Filesystem
+
+	volumes := []LVMLogicalVolume{{Name: "rootlv", Filesystem: &rootFS}}
+	minSizes := []uint64{0}
+	growing := 0
+
+	for _, m := range mountpoints {
+		if m.Mountpoint == "/" {
+			if m.Type != "" {
+				rootFS.Type = m.Type
+				rootFS.FSTabPassNo = fsckPassNo(m.Type, true)
+			}
+			minSizes[0] = m.Size
+			continue
+		}
+
+		fs, err := newFilesystem(m, basePT.Partitions[n-1].Filesystem.Type, rng)
+		if err != nil {
+			return PartitionTable{}, err
+		}
+		volumes = append(volumes, LVMLogicalVolume{Name: logicalVolumeName(m.Mountpoint), Filesystem: fs})
+		minSizes = append(minSizes, m.Size)
+		if m.Grow {
+			growing = len(volumes) - 1
+		}
+	}
+
+	vg := &LVMVolumeGroup{Name: lvmVolumeGroupName}
+
+	// everything in the physical volume, in bytes
+	size := uint64(lvmMetadataSize)
+	if luks != nil {
+		size += luksHeaderSize
+	}
+
+	for i, lv := range volumes {
+		if i == growing {
+			continue
+		}
+		if minSizes[i] == 0 {
+			return PartitionTable{}, fmt.Errorf("filesystem %s needs a size, because it doesn't grow", lv.Filesystem.Mountpoint)
+		}
+		lv.Size = alignUpTo(minSizes[i], lvmExtentSize)
+		vg.LogicalVolumes = append(vg.LogicalVolumes, lv)
+		size += lv.Size
+	}
+
+	last := volumes[growing]
+	last.Size = 0
+	vg.LogicalVolumes = append(vg.LogicalVolumes, last)
+	size += alignUpTo(minSizes[growing], lvmExtentSize)
+
+	pv := Partition{
+		Start:    root.Start,
+		Bootable: root.Bootable,
+	}
+	if pt.Type == "gpt" {
+		partUUID, err := newRandomUUIDFromReader(rng)
+		if err != nil {
+			return PartitionTable{}, err
+		}
+		pv.Type = linuxLVMGUID
+		pv.UUID = strings.ToUpper(partUUID.String())
+	} else {
+		pv.Type = linuxLVMDOS
+	}
+
+	if luks != nil {
+		volumeUUID, err := newRandomUUIDFromReader(rng)
+		if err != nil {
+			return PartitionTable{}, err
+		}
+		container := *luks
+		container.UUID = volumeUUID.String()
+		container.VolumeGroup = vg
+		pv.LUKS = &container
+	} else {
+		pv.VolumeGroup = vg
+	}
+
+	pt.Partitions = append(append([]Partition{}, basePT.Partitions[:n-1]...), pv)
+
+	required := root.Start + sectors(size)
+	if pt.Type == "gpt" {
+		required += gptBackupSectors
+	}
+	required = alignUp(required) * sectorSize
+	if imageSize < required {
+		imageSize = required
+	}
+	pt.Size = imageSize
+
+	return pt, nil
+}
+
+// Returns a copy of `basePT` with a /boot partition in front of the root
+// partition, unless it has one already. The boot partition inherits the
+// bootable flag and filesystem type of the root partition.
+func addBootPartition(basePT PartitionTable, rng io.Reader) (PartitionTable, error) {
+	for _, p := range basePT.Partitions {
+		if p.Filesystem != nil && p.Filesystem.Mountpoint == "/boot" {
+			return basePT, nil
+		}
+	}
+
+	n := len(basePT.Partitions)
+	if err := checkRootLast(basePT); err != nil {
+		return PartitionTable{}, err
+	}
+
+	root := basePT.Partitions[n-1]
+
+	boot, err := newPartition(basePT.Type, blueprint.FilesystemCustomization{Mountpoint: "/boot"}, root.Filesystem.Type, rng)
+	if err != nil {
+		return PartitionTable{}, err
+	}
+	boot.Start = root.Start
+	boot.Size = bootPartitionSize / sectorSize
+	boot.Bootable = root.Bootable
+
+	root.Start += boot.Size
+	root.Bootable = false
+
+	pt := basePT
+	pt.Partitions = append(append([]Partition{}, basePT.Partitions[:n-1]...), boot, root)
+	return pt, nil
+}
+
+// Returns a LUKS2 volume without an id and contents, which is unlocked as
+// requested by `encryption`. Volumes which are only unlocked by a Clevis pin
+// get a random passphrase, which is removed after binding the pin.
+func newLUKSContainer(encryption *blueprint.EncryptionCustomization, rng io.Reader) (*LUKSContainer, error) {
+	luks := &LUKSContainer{
+		Passphrase: encryption.Passphrase,
+	}
+
+	if encryption.Clevis != nil {
+		luks.Clevis = &ClevisBind{
+			Pin:    encryption.Clevis.Pin,
+			Policy: encryption.Clevis.Policy,
+		}
+
+		if luks.Passphrase == "" {
+			secret := make([]byte, 32)
+			_, err := io.ReadFull(rng, secret)
+			if err != nil {
+				return nil, err
+			}
+			luks.Passphrase = hex.EncodeToString(secret)
+			luks.Clevis.RemovePassphrase = true
+		}
+	}
+
+	return luks, nil
+}
+
+func checkRootLast(pt PartitionTable) error {
+	n := len(pt.Partitions)
+	if n == 0 || pt.Partitions[n-1].Filesystem == nil || pt.Partitions[n-1].Filesystem.Mountpoint != "/" {
+		return fmt.Errorf("the root filesystem must be on the last partition of the base partition table")
+	}
+	return nil
+}
+
+// Returns the name of the logical volume for a mountpoint, e.g. var_loglv for
+// /var/log.
+func logicalVolumeName(mountpoint string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(mountpoint, "/"), "/", "_") + "lv"
+}
+
+func newPartition(ptType string, m blueprint.FilesystemCustomization, defaultFSType string, rng io.Reader) (Partition, error) {
+	fs, err := newFilesystem(m, defaultFSType, rng)
+	if err != nil {
+		return Partition{}, err
+	}
+
+	p := Partition{
+		Filesystem: fs,
+	}
+
+	if ptType == "gpt" {
+		partUUID, err := newRandomUUIDFromReader(rng)
+		if err != nil {
+			return Partition{}, err
+		}
+		p.Type = linuxFilesystemGUID
+		p.UUID = strings.ToUpper(partUUID.String())
+	} else {
+		p.Type = linuxFilesystemDOS
+	}
+
+	return p, nil
+}
+
+func newFilesystem(m blueprint.FilesystemCustomization, defaultFSType string, rng io.Reader) (*Filesystem, error) {
+	fsType := m.Type
+	if fsType == "" {
+		fsType = defaultFSType
+	}
+
+	fsUUID, err := newRandomUUIDFromReader(rng)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Filesystem{
+		Type:         fsType,
+		UUID:         fsUUID.String(),
+		Mountpoint:   m.Mountpoint,
+		FSTabOptions: "defaults",
+		FSTabFreq:    0,
+		FSTabPassNo:  fsckPassNo(fsType, false),
+	}, nil
+}
+
+// Returns the fs_passno for a filesystem: xfs is never checked at boot, other
+// filesystems are checked after the root filesystem.
+func fsckPassNo(fsType string, root bool) uint64 {
+	switch {
+	case fsType == "xfs":
+		return 0
+	case root:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Returns the number of sectors needed to hold `size` bytes.
+func sectors(size uint64) uint64 {
+	return (size + sectorSize - 1) / sectorSize
+}
+
+func alignUp(sectors uint64) uint64 {
+	return alignUpTo(sectors, alignment)
+}
+
+func alignUpTo(n, to uint64) uint64 {
+	return (n + to - 1) / to * to
+}
+
+func newRandomUUIDFromReader(r io.Reader) (uuid.UUID, error) {
+	var id uuid.UUID
+	_, err := io.ReadFull(r, id[:])
+	if err != nil {
+		return uuid.Nil, err
+	}
+	id[6] = (id[6] & 0x0f) | 0x40 // Version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // Variant is 10
+	return id, nil
+}
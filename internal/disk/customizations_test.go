@@ -0,0 +1,310 @@
+package disk_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/disk"
+)
+
+const MiB = 1024 * 1024
+
+func basePartitionTable(ptType string) disk.PartitionTable {
+	return disk.PartitionTable{
+		Size: 10 * 1024 * MiB,
+		Type: ptType,
+		Partitions: []disk.Partition{
+			{
+				Start: 2048,
+				Size:  204800,
+				Filesystem: &disk.Filesystem{
+					Type:       "vfat",
+					Mountpoint: "/boot/efi",
+				},
+			},
+			{
+				Start: 206848,
+				Filesystem: &disk.Filesystem{
+					Type:         "xfs",
+					UUID:         "6264d520-3fb9-423f-8ab8-7a0a8e3d3562",
+					Mountpoint:   "/",
+					FSTabOptions: "defaults",
+				},
+			},
+		},
+	}
+}
+
+func TestCheckMountpoints(t *testing.T) {
+	valid := [][]blueprint.FilesystemCustomization{
+		nil,
+		{{Mountpoint: "/"}},
+		{{Mountpoint: "/var"}, {Mountpoint: "/var/log"}, {Mountpoint: "/home", Type: "ext4", Grow: true}},
+	}
+	for _, mountpoints := range valid {
+		assert.NoError(t, disk.CheckMountpoints(mountpoints, disk.MountpointAllowList), mountpoints)
+	}
+
+	invalid := [][]blueprint.FilesystemCustomization{
+		{{Mountpoint: "var"}},
+		{{Mountpoint: "/var/"}},
+		{{Mountpoint: "/var/../etc"}},
+		{{Mountpoint: "/etc"}},
+		{{Mountpoint: "/usr"}},
+		{{Mountpoint: "/boot"}},
+		{{Mountpoint: "/variable"}},
+		{{Mountpoint: "/var"}, {Mountpoint: "/var"}},
+		{{Mountpoint: "/var", Type: "btrfs"}},
+		{{Mountpoint: "/var", Grow: true}, {Mountpoint: "/home", Grow: true}},
+	}
+	for _, mountpoints := range invalid {
+		assert.Error(t, disk.CheckMountpoints(mountpoints, disk.MountpointAllowList), mountpoints)
+	}
+}
+
+func TestCreatePartitionTableUnchanged(t *testing.T) {
+	base := basePartitionTable("gpt")
+	pt, err := disk.CreatePartitionTable(nil, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+	assert.Equal(t, base, pt)
+}
+
+func TestCreatePartitionTable(t *testing.T) {
+	base := basePartitionTable("gpt")
+	mountpoints := []blueprint.FilesystemCustomization{
+		{Mountpoint: "/", Size: 2048 * MiB, Type: "ext4"},
+		{Mountpoint: "/home", Size: 100 * MiB, Grow: true},
+		{Mountpoint: "/var", Size: 1023*MiB + 1},
+	}
+
+	pt, err := disk.CreatePartitionTable(mountpoints, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+
+	// the base partition table is not modified
+	assert.Equal(t, basePartitionTable("gpt"), base)
+
+	require.Len(t, pt.Partitions, 4)
+	assert.Equal(t, base.Partitions[0], pt.Partitions[0])
+
+	root := pt.Partitions[1]
+	assert.Equal(t, uint64(206848), root.Start)
+	assert.Equal(t, uint64(2048*2048), root.Size)
+	assert.Equal(t, "ext4", root.Filesystem.Type)
+	assert.Equal(t, uint64(1), root.Filesystem.FSTabPassNo)
+	assert.Equal(t, base.Partitions[1].Filesystem.UUID, root.Filesystem.UUID)
+
+	// sizes are rounded up to whole MiB
+	varPart := pt.Partitions[2]
+	assert.Equal(t, "/var", varPart.Filesystem.Mountpoint)
+	assert.Equal(t, root.Start+root.Size, varPart.Start)
+	assert.Equal(t, uint64(1024*2048), varPart.Size)
+	assert.Equal(t, "xfs", varPart.Filesystem.Type)
+	assert.Equal(t, "0FC63DAF-8483-4772-8E79-3D69D8477DE4", varPart.Type)
+	assert.NotEmpty(t, varPart.UUID)
+	assert.NotEmpty(t, varPart.Filesystem.UUID)
+
+	// the growing partition is last and fills the disk
+	home := pt.Partitions[3]
+	assert.Equal(t, "/home", home.Filesystem.Mountpoint)
+	assert.Equal(t, varPart.Start+varPart.Size, home.Start)
+	assert.Equal(t, uint64(0), home.Size)
+	assert.Equal(t, base.Size, pt.Size)
+
+	fstab := pt.FSTabStageOptions()
+	require.Len(t, fstab.FileSystems, 4)
+}
+
+func TestCreatePartitionTableEnlargesDisk(t *testing.T) {
+	base := basePartitionTable("gpt")
+	mountpoints := []blueprint.FilesystemCustomization{
+		{Mountpoint: "/", Size: 8 * 1024 * MiB},
+		{Mountpoint: "/var", Size: 4 * 1024 * MiB},
+	}
+
+	pt, err := disk.CreatePartitionTable(mountpoints, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(206848*512+12*1024*MiB+MiB), pt.Size)
+}
+
+func TestCreatePartitionTableErrors(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+
+	// the root filesystem needs a size when another one grows
+	_, err := disk.CreatePartitionTable([]blueprint.FilesystemCustomization{
+		{Mountpoint: "/var", Size: MiB, Grow: true},
+	}, 0, basePartitionTable("gpt"), rng)
+	assert.Error(t, err)
+
+	// dos partition tables hold at most four partitions
+	_, err = disk.CreatePartitionTable([]blueprint.FilesystemCustomization{
+		{Mountpoint: "/var", Size: MiB},
+		{Mountpoint: "/home", Size: MiB},
+		{Mountpoint: "/opt", Size: MiB},
+	}, 0, basePartitionTable("dos"), rng)
+	assert.Error(t, err)
+
+	// the root filesystem must be last
+	base := basePartitionTable("gpt")
+	base.Partitions[0], base.Partitions[1] = base.Partitions[1], base.Partitions[0]
+	_, err = disk.CreatePartitionTable([]blueprint.FilesystemCustomization{
+		{Mountpoint: "/var", Size: MiB},
+	}, 0, base, rng)
+	assert.Error(t, err)
+}
+
+func TestCheckDiskCustomization(t *testing.T) {
+	valid := []*blueprint.DiskCustomization{
+		nil,
+		{LVM: true},
+		{Encryption: &blueprint.EncryptionCustomization{Passphrase: "secret"}},
+		{Encryption: &blueprint.EncryptionCustomization{Clevis: &blueprint.ClevisCustomization{Pin: "tang", Policy: `{"url": "http://tang.example.com"}`}}},
+	}
+	for _, c := range valid {
+		assert.NoError(t, disk.CheckDiskCustomization(c), c)
+	}
+
+	invalid := []*blueprint.DiskCustomization{
+		{Encryption: &blueprint.EncryptionCustomization{}},
+		{Encryption: &blueprint.EncryptionCustomization{Clevis: &blueprint.ClevisCustomization{Pin: "yubikey", Policy: "{}"}}},
+		{Encryption: &blueprint.EncryptionCustomization{Clevis: &blueprint.ClevisCustomization{Pin: "tang", Policy: "http://tang.example.com"}}},
+	}
+	for _, c := range invalid {
+		assert.Error(t, disk.CheckDiskCustomization(c), c)
+	}
+}
+
+func TestCreateVolumePartitionTableLVM(t *testing.T) {
+	base := basePartitionTable("gpt")
+	mountpoints := []blueprint.FilesystemCustomization{
+		{Mountpoint: "/", Size: 2048 * MiB},
+		{Mountpoint: "/var/log", Size: 1023 * MiB, Type: "ext4"},
+		{Mountpoint: "/home", Size: 100 * MiB, Grow: true},
+	}
+
+	pt, err := disk.CreateVolumePartitionTable(mountpoints, &blueprint.DiskCustomization{LVM: true}, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+	assert.Equal(t, basePartitionTable("gpt"), base)
+
+	require.Len(t, pt.Partitions, 2)
+	assert.Equal(t, base.Partitions[0], pt.Partitions[0])
+
+	pv := pt.Partitions[1]
+	assert.Equal(t, base.Partitions[1].Start, pv.Start)
+	assert.Equal(t, uint64(0), pv.Size)
+	assert.Equal(t, "E6D6D379-F507-44C2-A23C-238F2A3DF928", pv.Type)
+	assert.Nil(t, pv.Filesystem)
+	assert.Nil(t, pv.LUKS)
+	require.NotNil(t, pv.VolumeGroup)
+
+	// sizes are rounded up to whole extents and the growing volume is last
+	vg := pv.VolumeGroup
+	assert.Equal(t, "rootvg", vg.Name)
+	require.Len(t, vg.LogicalVolumes, 3)
+	assert.Equal(t, "rootlv", vg.LogicalVolumes[0].Name)
+	assert.Equal(t, uint64(2048*MiB), vg.LogicalVolumes[0].Size)
+	assert.Equal(t, base.Partitions[1].Filesystem, vg.LogicalVolumes[0].Filesystem)
+	assert.Equal(t, "var_loglv", vg.LogicalVolumes[1].Name)
+	assert.Equal(t, uint64(1024*MiB), vg.LogicalVolumes[1].Size)
+	assert.Equal(t, "ext4", vg.LogicalVolumes[1].Filesystem.Type)
+	assert.Equal(t, "homelv", vg.LogicalVolumes[2].Name)
+	assert.Equal(t, uint64(0), vg.LogicalVolumes[2].Size)
+	assert.Equal(t, "xfs", vg.LogicalVolumes[2].Filesystem.Type)
+
+	assert.Equal(t, base.Size, pt.Size)
+	assert.Equal(t, "/", pt.RootFilesystem().Mountpoint)
+	assert.Len(t, pt.FSTabStageOptions().FileSystems, 4)
+
+	// the disk is enlarged to hold all volumes
+	mountpoints[2].Size = 8 * 1024 * MiB
+	pt, err = disk.CreateVolumePartitionTable(mountpoints, &blueprint.DiskCustomization{LVM: true}, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(206848*512+(11*1024+1)*MiB+MiB), pt.Size)
+}
+
+func TestCreateVolumePartitionTableEncrypted(t *testing.T) {
+	base := basePartitionTable("gpt")
+	mountpoints := []blueprint.FilesystemCustomization{
+		{Mountpoint: "/var", Size: 1024 * MiB},
+	}
+	c := &blueprint.DiskCustomization{
+		Encryption: &blueprint.EncryptionCustomization{Passphrase: "secret"},
+	}
+
+	pt, err := disk.CreateVolumePartitionTable(mountpoints, c, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+	assert.Equal(t, basePartitionTable("gpt"), base)
+
+	// a /boot partition is added in front of the root partition
+	require.Len(t, pt.Partitions, 4)
+	boot := pt.Partitions[1]
+	assert.Equal(t, base.Partitions[1].Start, boot.Start)
+	assert.Equal(t, uint64(1024*2048), boot.Size)
+	assert.Equal(t, "/boot", boot.Filesystem.Mountpoint)
+	assert.Equal(t, "xfs", boot.Filesystem.Type)
+
+	// partitions have room for the LUKS2 header
+	varPart := pt.Partitions[2]
+	assert.Equal(t, boot.Start+boot.Size, varPart.Start)
+	assert.Equal(t, uint64(1040*2048), varPart.Size)
+	assert.Nil(t, varPart.Filesystem)
+	require.NotNil(t, varPart.LUKS)
+	assert.Equal(t, "secret", varPart.LUKS.Passphrase)
+	assert.Equal(t, "/var", varPart.LUKS.Filesystem.Mountpoint)
+
+	root := pt.Partitions[3]
+	require.NotNil(t, root.LUKS)
+	assert.Equal(t, base.Partitions[1].Filesystem, root.LUKS.Filesystem)
+	assert.NotEqual(t, root.LUKS.UUID, varPart.LUKS.UUID)
+
+	assert.Len(t, pt.FSTabStageOptions().FileSystems, 4)
+	assert.Len(t, pt.CrypttabStageOptions().Volumes, 2)
+}
+
+func TestCreateVolumePartitionTableEncryptedLVM(t *testing.T) {
+	base := basePartitionTable("dos")
+	c := &blueprint.DiskCustomization{
+		LVM: true,
+		Encryption: &blueprint.EncryptionCustomization{
+			Clevis: &blueprint.ClevisCustomization{Pin: "tang", Policy: `{"url": "http://tang.example.com"}`},
+		},
+	}
+
+	pt, err := disk.CreateVolumePartitionTable(nil, c, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+
+	require.Len(t, pt.Partitions, 3)
+	assert.Equal(t, "/boot", pt.Partitions[1].Filesystem.Mountpoint)
+
+	pv := pt.Partitions[2]
+	assert.Equal(t, "8e", pv.Type)
+	require.NotNil(t, pv.LUKS)
+	require.NotNil(t, pv.LUKS.VolumeGroup)
+	assert.Len(t, pv.LUKS.VolumeGroup.LogicalVolumes, 1)
+
+	// volumes which are unlocked by a pin get a random passphrase, which is
+	// removed once the pin is bound
+	require.NotNil(t, pv.LUKS.Clevis)
+	assert.Equal(t, "tang", pv.LUKS.Clevis.Pin)
+	assert.True(t, pv.LUKS.Clevis.RemovePassphrase)
+	assert.Len(t, pv.LUKS.Passphrase, 64)
+}
+
+func TestCreateVolumePartitionTableUnchanged(t *testing.T) {
+	base := basePartitionTable("gpt")
+	mountpoints := []blueprint.FilesystemCustomization{
+		{Mountpoint: "/var", Size: 1024 * MiB},
+	}
+
+	expected, err := disk.CreatePartitionTable(mountpoints, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+
+	for _, c := range []*blueprint.DiskCustomization{nil, {}} {
+		pt, err := disk.CreateVolumePartitionTable(mountpoints, c, base.Size, base, rand.New(rand.NewSource(0)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, pt)
+	}
+}
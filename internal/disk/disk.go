@@ -1,7 +1,9 @@
 // Disk package contains abstract data-types to define disk-related entities.
 //
 // PartitionTable, Partition and Filesystem types are currently defined.
-// All of them can be 1:1 converted to osbuild.QEMUAssemblerOptions.
+// All of them can be 1:1 converted to osbuild.QEMUAssemblerOptions, unless
+// partitions contain LUKS2 volumes or LVM volume groups. Those need to be
+// set up with osbuild2 stages instead.
 package disk
 
 import (
@@ -29,6 +31,11 @@ type Partition struct {
 	UUID string
 	// If nil, the partition is raw; It doesn't contain a filesystem.
 	Filesystem *Filesystem
+	// If set, the partition is a LUKS2 volume, which contains the
+	// filesystem or volume group instead of the partition itself.
+	LUKS *LUKSContainer
+	// If set, the partition is the physical volume of an LVM volume group.
+	VolumeGroup *LVMVolumeGroup
 }
 
 type Filesystem struct {
@@ -65,17 +72,12 @@ func (pt PartitionTable) QEMUAssemblerOptions() osbuild.QEMUAssemblerOptions {
 // Generates org.osbuild.fstab stage options from this partition table.
 func (pt PartitionTable) FSTabStageOptions() *osbuild.FSTabStageOptions {
 	var options osbuild.FSTabStageOptions
-	for _, p := range pt.Partitions {
-		fs := p.Filesystem
-		if fs == nil {
-			continue
-		}
-
+	for _, fs := range pt.Filesystems() {
 		options.AddFilesystem(fs.UUID, fs.Type, fs.Mountpoint, fs.FSTabOptions, fs.FSTabFreq, fs.FSTabPassNo)
 	}
 
 	// sort the entries by PassNo to maintain backward compatibility
-	sort.Slice(options.FileSystems, func(i, j int) bool {
+	sort.SliceStable(options.FileSystems, func(i, j int) bool {
 		return options.FileSystems[i].PassNo < options.FileSystems[j].PassNo
 	})
 
@@ -99,6 +101,41 @@ func (pt PartitionTable) RootPartition() *Partition {
 	return nil
 }
 
+// Returns the root filesystem of the partition table, which might be in
+// a LUKS2 volume or on a logical volume. Nil is returned if there's no root
+// filesystem.
+func (pt PartitionTable) RootFilesystem() *Filesystem {
+	for _, fs := range pt.Filesystems() {
+		if fs.Mountpoint == "/" {
+			return fs
+		}
+	}
+
+	return nil
+}
+
+// Returns all filesystems of the partition table, in the order of the
+// partitions and logical volumes they are on.
+func (pt PartitionTable) Filesystems() []*Filesystem {
+	var filesystems []*Filesystem
+	for _, p := range pt.Partitions {
+		filesystems = append(filesystems, p.filesystems()...)
+	}
+	return filesystems
+}
+
+func (p Partition) filesystems() []*Filesystem {
+	switch {
+	case p.Filesystem != nil:
+		return []*Filesystem{p.Filesystem}
+	case p.LUKS != nil:
+		return p.LUKS.filesystems()
+	case p.VolumeGroup != nil:
+		return p.VolumeGroup.filesystems()
+	}
+	return nil
+}
+
 // Converts Partition to osbuild.QEMUPartition that encodes the same partition.
 func (p Partition) QEMUPartition() osbuild.QEMUPartition {
 	var fs *osbuild.QEMUFilesystem
@@ -0,0 +1,244 @@
+package disk
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	osbuild2 "github.com/osbuild/osbuild-composer/internal/osbuild2"
+)
+
+// A filesystem of the partition table with the devices which need to be set
+// up to access it. The filesystem is on the device called `name`.
+type filesystemDevice struct {
+	fs      *Filesystem
+	name    string
+	devices osbuild2.Devices
+}
+
+// ImageStages returns the osbuild2 stages which create the image file
+// `filename` with this partition table, and copy the tree of the pipeline
+// `tree` into its filesystems. The boot loader needs to be installed
+// separately.
+func (pt PartitionTable) ImageStages(filename, tree string) []*osbuild2.Stage {
+	image := osbuild2.Devices{"device": osbuild2.NewLoopbackDevice(&osbuild2.LoopbackDeviceOptions{Filename: filename})}
+
+	stages := []*osbuild2.Stage{
+		osbuild2.NewTruncateStage(&osbuild2.TruncateStageOptions{
+			Filename: filename,
+			Size:     fmt.Sprintf("%d", pt.Size),
+		}),
+		osbuild2.NewSfdiskStage(pt.SfdiskStageOptions(), image),
+	}
+	stages = append(stages, pt.VolumeStages(filename)...)
+
+	fsDevices := pt.filesystemDevices(filename)
+	for _, d := range fsDevices {
+		stages = append(stages, mkfsStage(d))
+	}
+
+	devices := make(osbuild2.Devices)
+	var mounts []osbuild2.Mount
+	for _, d := range fsDevices {
+		for name, device := range d.devices {
+			devices[name] = device
+		}
+		mounts = append(mounts, osbuild2.Mount{
+			Name:   mountName(d.fs.Mountpoint),
+			Type:   mountType(d.fs.Type),
+			Source: d.name,
+			Target: d.fs.Mountpoint,
+		})
+	}
+	// parents need to be mounted before the filesystems below them
+	sort.SliceStable(mounts, func(i, j int) bool {
+		return mountDepth(mounts[i].Target) < mountDepth(mounts[j].Target)
+	})
+
+	inputs := osbuild2.CopyStageTreeInputs{"tree": osbuild2.NewTreeInputRef(tree)}
+	stages = append(stages, osbuild2.NewCopyStageWithMounts(&osbuild2.CopyStageOptions{
+		Paths: []osbuild2.CopyStagePath{{From: "input://tree/", To: "mount://root/"}},
+	}, &inputs, devices, mounts))
+
+	return append(stages, pt.RemoveKeyStages(filename)...)
+}
+
+// SfdiskStageOptions returns org.osbuild.sfdisk stage options, which write
+// the partitions of the partition table.
+func (pt PartitionTable) SfdiskStageOptions() *osbuild2.SfdiskStageOptions {
+	options := osbuild2.SfdiskStageOptions{
+		Label: pt.Type,
+		UUID:  pt.UUID,
+	}
+	for _, p := range pt.Partitions {
+		options.Partitions = append(options.Partitions, osbuild2.SfdiskPartition{
+			Bootable: p.Bootable,
+			Start:    p.Start,
+			Size:     p.Size,
+			Type:     p.Type,
+			UUID:     p.UUID,
+		})
+	}
+	return &options
+}
+
+// FSTabStageOptionsV2 is like FSTabStageOptions(), but returns options of the
+// osbuild2 org.osbuild.fstab stage.
+func (pt PartitionTable) FSTabStageOptionsV2() *osbuild2.FSTabStageOptions {
+	var options osbuild2.FSTabStageOptions
+	for _, fs := range pt.Filesystems() {
+		options.AddFilesystem(fs.UUID, fs.Type, fs.Mountpoint, fs.FSTabOptions, fs.FSTabFreq, fs.FSTabPassNo)
+	}
+
+	// sort the entries by PassNo to maintain backward compatibility
+	sort.SliceStable(options.FileSystems, func(i, j int) bool {
+		return options.FileSystems[i].PassNo < options.FileSystems[j].PassNo
+	})
+
+	return &options
+}
+
+// Grub2InstStageOptions returns org.osbuild.grub2.inst stage options, which
+// install the legacy BIOS boot loader for `platform` (e.g. i386-pc) into the
+// image file `filename`. The core image is written in front of the first
+// partition, which must be the BIOS boot partition on gpt partition tables.
+// It returns nil if /boot is not on a plain partition.
+func (pt PartitionTable) Grub2InstStageOptions(filename, platform string) *osbuild2.Grub2InstStageOptions {
+	if len(pt.Partitions) == 0 {
+		return nil
+	}
+
+	// the prefix is on the /boot partition, or on the root partition if
+	// there's no separate one
+	index, prefix := pt.partitionIndex("/boot"), "/grub2"
+	if index < 0 {
+		index, prefix = pt.partitionIndex("/"), "/boot/grub2"
+	}
+	if index < 0 {
+		return nil
+	}
+
+	return &osbuild2.Grub2InstStageOptions{
+		Filename: filename,
+		Platform: platform,
+		Location: pt.Partitions[0].Start,
+		Core: osbuild2.CoreMkImage{
+			Type:       "mkimage",
+			PartLabel:  pt.Type,
+			Filesystem: pt.Partitions[index].Filesystem.Type,
+		},
+		Prefix: osbuild2.PrefixPartition{
+			Type:      "partition",
+			PartLabel: pt.Type,
+			Number:    uint(index),
+			Path:      prefix,
+		},
+	}
+}
+
+// Returns the index of the plain partition holding the filesystem mounted at
+// `mountpoint`, or -1 if there's no such partition.
+func (pt PartitionTable) partitionIndex(mountpoint string) int {
+	for i, p := range pt.Partitions {
+		if p.Filesystem != nil && p.Filesystem.Mountpoint == mountpoint {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the filesystems of the partition table with the devices they are
+// on. Partitions are loopback devices named after their index, LUKS2 volumes
+// are opened on them, and logical volumes are activated on either.
+func (pt PartitionTable) filesystemDevices(filename string) []filesystemDevice {
+	var result []filesystemDevice
+	for i, p := range pt.Partitions {
+		partName := fmt.Sprintf("part%d", i)
+		devices := osbuild2.Devices{partName: pt.partitionDevice(p, filename)}
+		parent := partName
+
+		if p.Filesystem != nil {
+			result = append(result, filesystemDevice{fs: p.Filesystem, name: partName, devices: devices})
+			continue
+		}
+
+		vg := p.VolumeGroup
+		if luks := p.LUKS; luks != nil {
+			luksName := fmt.Sprintf("luks%d", i)
+			devices[luksName] = osbuild2.NewLUKS2Device(partName, &osbuild2.LUKS2DeviceOptions{Passphrase: luks.Passphrase})
+			parent = luksName
+			if luks.Filesystem != nil {
+				result = append(result, filesystemDevice{fs: luks.Filesystem, name: luksName, devices: devices})
+			}
+			vg = luks.VolumeGroup
+		}
+
+		if vg == nil {
+			continue
+		}
+		for _, lv := range vg.LogicalVolumes {
+			if lv.Filesystem == nil {
+				continue
+			}
+			lvDevices := make(osbuild2.Devices)
+			for name, device := range devices {
+				lvDevices[name] = device
+			}
+			lvDevices[lv.Name] = osbuild2.NewLVM2LVDevice(parent, &osbuild2.LVM2LVDeviceOptions{Volume: lv.Name})
+			result = append(result, filesystemDevice{fs: lv.Filesystem, name: lv.Name, devices: lvDevices})
+		}
+	}
+	return result
+}
+
+// Returns the stage which creates the filesystem of `d`. The mkfs stages
+// expect the filesystem's device to be called "device".
+func mkfsStage(d filesystemDevice) *osbuild2.Stage {
+	devices := make(osbuild2.Devices)
+	for name, device := range d.devices {
+		if name == d.name {
+			name = "device"
+		}
+		devices[name] = device
+	}
+
+	switch d.fs.Type {
+	case "xfs":
+		return osbuild2.NewMkfsXfsStage(&osbuild2.MkfsXfsStageOptions{UUID: d.fs.UUID, Label: d.fs.Label}, devices)
+	case "ext4":
+		return osbuild2.NewMkfsExt4Stage(&osbuild2.MkfsExt4StageOptions{UUID: d.fs.UUID, Label: d.fs.Label}, devices)
+	case "vfat":
+		return osbuild2.NewMkfsFATStage(&osbuild2.MkfsFATStageOptions{
+			VolID: strings.ReplaceAll(d.fs.UUID, "-", ""),
+			Label: d.fs.Label,
+		}, devices)
+	default:
+		panic(fmt.Sprintf("unsupported filesystem type %q, this is a programming error", d.fs.Type))
+	}
+}
+
+// Returns the name of the mount of a filesystem, e.g. boot-efi for
+// /boot/efi.
+func mountName(mountpoint string) string {
+	if mountpoint == "/" {
+		return "root"
+	}
+	return strings.ReplaceAll(strings.TrimPrefix(mountpoint, "/"), "/", "-")
+}
+
+func mountType(fsType string) string {
+	switch fsType {
+	case "vfat":
+		return "org.osbuild.fat"
+	default:
+		return "org.osbuild." + fsType
+	}
+}
+
+func mountDepth(mountpoint string) int {
+	if mountpoint == "/" {
+		return 0
+	}
+	return strings.Count(path.Clean(mountpoint), "/")
+}
@@ -0,0 +1,121 @@
+package disk_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/disk"
+	osbuild2 "github.com/osbuild/osbuild-composer/internal/osbuild2"
+)
+
+func stageTypes(stages []*osbuild2.Stage) []string {
+	var types []string
+	for _, stage := range stages {
+		types = append(types, stage.Type)
+	}
+	return types
+}
+
+func TestImageStages(t *testing.T) {
+	base := basePartitionTable("gpt")
+	base.Partitions[0].Filesystem.UUID = "7B77-95E7"
+	pt, err := disk.CreatePartitionTable([]blueprint.FilesystemCustomization{{Mountpoint: "/var", Size: 100 * MiB}}, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+
+	stages := pt.ImageStages("disk.img", "os")
+	assert.Equal(t, []string{
+		"org.osbuild.truncate",
+		"org.osbuild.sfdisk",
+		"org.osbuild.mkfs.fat",
+		"org.osbuild.mkfs.xfs",
+		"org.osbuild.mkfs.xfs",
+		"org.osbuild.copy",
+	}, stageTypes(stages))
+
+	assert.Equal(t, &osbuild2.TruncateStageOptions{Filename: "disk.img", Size: "10737418240"}, stages[0].Options)
+	sfdisk := stages[1].Options.(*osbuild2.SfdiskStageOptions)
+	assert.Equal(t, "gpt", sfdisk.Label)
+	assert.Len(t, sfdisk.Partitions, len(pt.Partitions))
+	assert.Equal(t, &osbuild2.MkfsFATStageOptions{VolID: "7B7795E7"}, stages[2].Options)
+
+	// the filesystem is always on the "device" of mkfs stages
+	assert.Equal(t, osbuild2.Devices{"device": osbuild2.NewLoopbackDevice(&osbuild2.LoopbackDeviceOptions{
+		Filename: "disk.img",
+		Start:    2048,
+		Size:     204800,
+	})}, stages[2].Devices)
+
+	// parents are mounted first
+	copyStage := stages[5]
+	assert.Equal(t, []osbuild2.Mount{
+		{Name: "root", Type: "org.osbuild.xfs", Source: "part2", Target: "/"},
+		{Name: "var", Type: "org.osbuild.xfs", Source: "part1", Target: "/var"},
+		{Name: "boot-efi", Type: "org.osbuild.fat", Source: "part0", Target: "/boot/efi"},
+	}, copyStage.Mounts)
+	assert.Len(t, copyStage.Devices, 3)
+	assert.Equal(t, &osbuild2.CopyStageTreeInputs{"tree": osbuild2.NewTreeInputRef("os")}, copyStage.Inputs)
+}
+
+func TestImageStagesVolumes(t *testing.T) {
+	base := basePartitionTable("gpt")
+	base.Partitions[0].Filesystem.UUID = "7B77-95E7"
+	c := &blueprint.DiskCustomization{
+		LVM: true,
+		Encryption: &blueprint.EncryptionCustomization{
+			Clevis: &blueprint.ClevisCustomization{Pin: "tang", Policy: `{"url": "http://tang.example.com"}`},
+		},
+	}
+	pt, err := disk.CreateVolumePartitionTable(nil, c, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+
+	stages := pt.ImageStages("disk.img", "os")
+	assert.Equal(t, []string{
+		"org.osbuild.truncate",
+		"org.osbuild.sfdisk",
+		"org.osbuild.luks2.format",
+		"org.osbuild.clevis.luks-bind",
+		"org.osbuild.lvm2.create",
+		"org.osbuild.lvm2.metadata",
+		"org.osbuild.mkfs.fat",
+		"org.osbuild.mkfs.xfs",
+		"org.osbuild.mkfs.xfs",
+		"org.osbuild.copy",
+		"org.osbuild.luks2.remove-key",
+	}, stageTypes(stages))
+
+	// the root logical volume is activated on the opened LUKS2 volume
+	rootfs := stages[8]
+	require.Contains(t, rootfs.Devices, "device")
+	assert.Equal(t, "luks2", rootfs.Devices["device"].Parent)
+	assert.Equal(t, "org.osbuild.lvm2.lv", rootfs.Devices["device"].Type)
+	assert.Equal(t, "org.osbuild.luks2", rootfs.Devices["luks2"].Type)
+	assert.Equal(t, "part2", rootfs.Devices["luks2"].Parent)
+}
+
+func TestGrub2InstStageOptions(t *testing.T) {
+	pt := basePartitionTable("gpt")
+	assert.Equal(t, &osbuild2.Grub2InstStageOptions{
+		Filename: "disk.img",
+		Platform: "i386-pc",
+		Location: 2048,
+		Core:     osbuild2.CoreMkImage{Type: "mkimage", PartLabel: "gpt", Filesystem: "xfs"},
+		Prefix:   osbuild2.PrefixPartition{Type: "partition", PartLabel: "gpt", Number: 1, Path: "/boot/grub2"},
+	}, pt.Grub2InstStageOptions("disk.img", "i386-pc"))
+
+	// the prefix is on the /boot partition, if there is one
+	encrypted := &blueprint.DiskCustomization{Encryption: &blueprint.EncryptionCustomization{Passphrase: "secret"}}
+	volumes, err := disk.CreateVolumePartitionTable(nil, encrypted, pt.Size, pt, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+	options := volumes.Grub2InstStageOptions("disk.img", "i386-pc")
+	require.NotNil(t, options)
+	assert.Equal(t, osbuild2.PrefixPartition{Type: "partition", PartLabel: "gpt", Number: 1, Path: "/grub2"}, options.Prefix)
+
+	// grub cannot load its modules from logical volumes without them
+	lvm, err := disk.CreateVolumePartitionTable(nil, &blueprint.DiskCustomization{LVM: true}, pt.Size, pt, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+	assert.Nil(t, lvm.Grub2InstStageOptions("disk.img", "i386-pc"))
+}
@@ -0,0 +1,184 @@
+package disk
+
+import (
+	"fmt"
+
+	osbuild2 "github.com/osbuild/osbuild-composer/internal/osbuild2"
+)
+
+// LUKSContainer is a LUKS2 volume, which holds either a filesystem or the
+// physical volume of an LVM volume group.
+type LUKSContainer struct {
+	UUID       string
+	Label      string
+	Passphrase string
+	// If set, the volume is bound to a Clevis pin, which unlocks it at boot.
+	Clevis *ClevisBind
+
+	Filesystem  *Filesystem
+	VolumeGroup *LVMVolumeGroup
+}
+
+// ClevisBind is the Clevis pin a LUKS2 volume is bound to.
+type ClevisBind struct {
+	Pin    string
+	Policy string
+	// Remove the passphrase from the volume after binding it, so that it
+	// can only be unlocked by the pin.
+	RemovePassphrase bool
+}
+
+type LVMVolumeGroup struct {
+	Name           string
+	LogicalVolumes []LVMLogicalVolume
+}
+
+type LVMLogicalVolume struct {
+	Name string
+	// Size of the volume in bytes. The last volume of a group may have
+	// a size of 0, which means it takes up all remaining space.
+	Size       uint64
+	Filesystem *Filesystem
+}
+
+func (c *LUKSContainer) filesystems() []*Filesystem {
+	switch {
+	case c.Filesystem != nil:
+		return []*Filesystem{c.Filesystem}
+	case c.VolumeGroup != nil:
+		return c.VolumeGroup.filesystems()
+	}
+	return nil
+}
+
+func (vg *LVMVolumeGroup) filesystems() []*Filesystem {
+	var filesystems []*Filesystem
+	for _, lv := range vg.LogicalVolumes {
+		if lv.Filesystem != nil {
+			filesystems = append(filesystems, lv.Filesystem)
+		}
+	}
+	return filesystems
+}
+
+// VolumeStages returns the osbuild2 stages which set up the LUKS2 volumes and
+// LVM volume groups of the partition table in the image file `filename`. The
+// partition table must have been written to the file before, and the
+// filesystems need to be created afterwards. RemoveKeyStages() removes the
+// passphrases once the image is complete.
+func (pt PartitionTable) VolumeStages(filename string) []*osbuild2.Stage {
+	var stages []*osbuild2.Stage
+	for _, p := range pt.Partitions {
+		if p.LUKS == nil && p.VolumeGroup == nil {
+			continue
+		}
+
+		partition := pt.partitionDevice(p, filename)
+
+		vg := p.VolumeGroup
+		pv := osbuild2.Devices{"device": partition}
+
+		if luks := p.LUKS; luks != nil {
+			devices := osbuild2.Devices{"device": partition}
+			stages = append(stages, osbuild2.NewLUKS2CreateStage(&osbuild2.LUKS2CreateStageOptions{
+				Passphrase: luks.Passphrase,
+				UUID:       luks.UUID,
+				Label:      luks.Label,
+			}, devices))
+
+			if luks.Clevis != nil {
+				stages = append(stages, osbuild2.NewClevisLuksBindStage(&osbuild2.ClevisLuksBindStageOptions{
+					Passphrase: luks.Passphrase,
+					Pin:        luks.Clevis.Pin,
+					Policy:     luks.Clevis.Policy,
+				}, devices))
+			}
+
+			vg = luks.VolumeGroup
+			pv = osbuild2.Devices{
+				"luks":   partition,
+				"device": osbuild2.NewLUKS2Device("luks", &osbuild2.LUKS2DeviceOptions{Passphrase: luks.Passphrase}),
+			}
+		}
+
+		if vg != nil {
+			var volumes []osbuild2.LogicalVolume
+			for _, lv := range vg.LogicalVolumes {
+				volume := osbuild2.LogicalVolume{Name: lv.Name}
+				if lv.Size == 0 {
+					volume.Extents = "100%FREE"
+				} else {
+					volume.Size = fmt.Sprintf("%dB", lv.Size)
+				}
+				volumes = append(volumes, volume)
+			}
+			stages = append(stages,
+				osbuild2.NewLVM2CreateStage(&osbuild2.LVM2CreateStageOptions{Volumes: volumes}, pv),
+				osbuild2.NewLVM2MetadataStage(&osbuild2.LVM2MetadataStageOptions{VGName: vg.Name}, pv),
+			)
+		}
+	}
+
+	return stages
+}
+
+// RemoveKeyStages returns the osbuild2 stages which remove the passphrases
+// from the LUKS2 volumes that are unlocked by their Clevis pin alone. They
+// must run last, because the passphrase is needed to open the volumes while
+// the image is built.
+func (pt PartitionTable) RemoveKeyStages(filename string) []*osbuild2.Stage {
+	var stages []*osbuild2.Stage
+	for _, p := range pt.Partitions {
+		if p.LUKS == nil || p.LUKS.Clevis == nil || !p.LUKS.Clevis.RemovePassphrase {
+			continue
+		}
+		stages = append(stages, osbuild2.NewLUKS2RemoveKeyStage(&osbuild2.LUKS2RemoveKeyStageOptions{
+			Passphrase: p.LUKS.Passphrase,
+		}, osbuild2.Devices{"device": pt.partitionDevice(p, filename)}))
+	}
+	return stages
+}
+
+// CrypttabStageOptions returns org.osbuild.crypttab stage options for the LUKS2
+// volumes of the partition table, or nil if it doesn't contain any.
+func (pt PartitionTable) CrypttabStageOptions() *osbuild2.CrypttabStageOptions {
+	var options osbuild2.CrypttabStageOptions
+	for _, p := range pt.Partitions {
+		if p.LUKS == nil {
+			continue
+		}
+		options.Volumes = append(options.Volumes, osbuild2.CrypttabEntry{
+			Volume:  "luks-" + p.LUKS.UUID,
+			UUID:    p.LUKS.UUID,
+			Options: "luks",
+		})
+	}
+
+	if len(options.Volumes) == 0 {
+		return nil
+	}
+	return &options
+}
+
+// Returns the loopback device of partition `p` in the image file `filename`.
+func (pt PartitionTable) partitionDevice(p Partition, filename string) osbuild2.Device {
+	return osbuild2.NewLoopbackDevice(&osbuild2.LoopbackDeviceOptions{
+		Filename: filename,
+		Start:    p.Start,
+		Size:     pt.partitionSectors(p),
+	})
+}
+
+// Returns the size of a partition in sectors. Partitions with a size of 0 end
+// at the end of the disk, before the backup header of gpt partition tables.
+func (pt PartitionTable) partitionSectors(p Partition) uint64 {
+	if p.Size != 0 {
+		return p.Size
+	}
+
+	end := pt.Size / sectorSize
+	if pt.Type == "gpt" {
+		end -= gptBackupSectors
+	}
+	return end - p.Start
+}
@@ -0,0 +1,77 @@
+package disk_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/disk"
+	osbuild2 "github.com/osbuild/osbuild-composer/internal/osbuild2"
+)
+
+func TestVolumeStages(t *testing.T) {
+	base := basePartitionTable("gpt")
+	c := &blueprint.DiskCustomization{
+		LVM: true,
+		Encryption: &blueprint.EncryptionCustomization{
+			Clevis: &blueprint.ClevisCustomization{Pin: "tang", Policy: `{"url": "http://tang.example.com"}`},
+		},
+	}
+	mountpoints := []blueprint.FilesystemCustomization{
+		{Mountpoint: "/", Size: 2048 * MiB},
+		{Mountpoint: "/home", Size: 100 * MiB, Grow: true},
+	}
+
+	pt, err := disk.CreateVolumePartitionTable(mountpoints, c, base.Size, base, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+	pv := pt.Partitions[2]
+
+	stages := pt.VolumeStages("disk.img")
+	require.Len(t, stages, 4)
+	for i, stageType := range []string{
+		"org.osbuild.luks2.format",
+		"org.osbuild.clevis.luks-bind",
+		"org.osbuild.lvm2.create",
+		"org.osbuild.lvm2.metadata",
+	} {
+		assert.Equal(t, stageType, stages[i].Type)
+	}
+
+	// the last partition ends in front of the backup gpt header
+	partition := osbuild2.NewLoopbackDevice(&osbuild2.LoopbackDeviceOptions{
+		Filename: "disk.img",
+		Start:    pv.Start,
+		Size:     base.Size/512 - 33 - pv.Start,
+	})
+	assert.Equal(t, osbuild2.Devices{"device": partition}, stages[0].Devices)
+	assert.Equal(t, pv.LUKS.UUID, stages[0].Options.(*osbuild2.LUKS2CreateStageOptions).UUID)
+
+	// the volume group is created on the opened LUKS2 volume
+	assert.Equal(t, osbuild2.Devices{
+		"luks":   partition,
+		"device": osbuild2.NewLUKS2Device("luks", &osbuild2.LUKS2DeviceOptions{Passphrase: pv.LUKS.Passphrase}),
+	}, stages[2].Devices)
+	assert.Equal(t, []osbuild2.LogicalVolume{
+		{Name: "rootlv", Size: "2147483648B"},
+		{Name: "homelv", Extents: "100%FREE"},
+	}, stages[2].Options.(*osbuild2.LVM2CreateStageOptions).Volumes)
+	assert.Equal(t, "rootvg", stages[3].Options.(*osbuild2.LVM2MetadataStageOptions).VGName)
+
+	// the passphrase is removed separately, once the image is complete
+	removeKey := pt.RemoveKeyStages("disk.img")
+	require.Len(t, removeKey, 1)
+	assert.Equal(t, "org.osbuild.luks2.remove-key", removeKey[0].Type)
+	assert.Equal(t, osbuild2.Devices{"device": partition}, removeKey[0].Devices)
+
+	crypttab := pt.CrypttabStageOptions()
+	require.NotNil(t, crypttab)
+	assert.Equal(t, []osbuild2.CrypttabEntry{{Volume: "luks-" + pv.LUKS.UUID, UUID: pv.LUKS.UUID, Options: "luks"}}, crypttab.Volumes)
+
+	// plain partitions don't need any stages
+	assert.Empty(t, base.VolumeStages("disk.img"))
+	assert.Empty(t, base.RemoveKeyStages("disk.img"))
+	assert.Nil(t, base.CrypttabStageOptions())
+}
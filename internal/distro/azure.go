@@ -0,0 +1,39 @@
+package distro
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+)
+
+// AzureDracutConfPath is the dracut configuration file which adds the
+// Hyper-V drivers to the initramfs of every kernel installed in the image.
+const AzureDracutConfPath = "/etc/dracut.conf.d/hyperv.conf"
+
+// AzureHyperVDrivers are the drivers of the Hyper-V devices of Azure
+// instances, which the initramfs needs to find the root disk.
+var AzureHyperVDrivers = []string{"hv_vmbus", "hv_netvsc", "hv_storvsc"}
+
+// AzureCustomizations returns a copy of `c` for Azure images, which
+// additionally configures dracut to add the Hyper-V drivers to the
+// initramfs, unless `c` customizes its configuration file.
+func AzureCustomizations(c *blueprint.Customizations) *blueprint.Customizations {
+	var azure blueprint.Customizations
+	if c != nil {
+		azure = *c
+	}
+
+	for _, f := range azure.Files {
+		if f.Path == AzureDracutConfPath {
+			return &azure
+		}
+	}
+	azure.Files = append([]blueprint.FileCustomization{{
+		Path: AzureDracutConfPath,
+		Mode: "0644",
+		Data: fmt.Sprintf("add_drivers+=\" %s \"\n", strings.Join(AzureHyperVDrivers, " ")),
+	}}, azure.Files...)
+
+	return &azure
+}
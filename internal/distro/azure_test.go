@@ -0,0 +1,23 @@
+package distro_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+)
+
+func TestAzureCustomizations(t *testing.T) {
+	azure := distro.AzureCustomizations(nil)
+	require.Len(t, azure.Files, 1)
+	assert.Equal(t, distro.AzureDracutConfPath, azure.Files[0].Path)
+	assert.Equal(t, "add_drivers+=\" hv_vmbus hv_netvsc hv_storvsc \"\n", azure.Files[0].Data)
+
+	c := &blueprint.Customizations{
+		Files: []blueprint.FileCustomization{{Path: distro.AzureDracutConfPath, Data: ""}},
+	}
+	assert.Equal(t, c.Files, distro.AzureCustomizations(c).Files)
+}
@@ -94,6 +94,12 @@ type ImageOptions struct {
 	OSTree       OSTreeImageOptions
 	Size         uint64
 	Subscription *SubscriptionImageOptions
+	// Compression preset of image types with compressed outputs, e.g. 0-9
+	// for xz. The compressor's default is used if it is nil.
+	CompressionLevel *int
+	// Module streams which are enabled in the image, so that later dnf
+	// operations use the same streams as the image build
+	ModuleStreams []blueprint.ModuleStream
 }
 
 // The OSTreeImageOptions specify ostree-specific image options
@@ -114,6 +120,14 @@ type SubscriptionImageOptions struct {
 	Insights      bool
 }
 
+// String formats the options without the activation key, so that it doesn't
+// end up in logs.
+func (o SubscriptionImageOptions) String() string {
+	o.ActivationKey = "<redacted>"
+	type options SubscriptionImageOptions
+	return fmt.Sprintf("%+v", options(o))
+}
+
 // A Manifest is an opaque JSON object, which is a valid input to osbuild
 type Manifest []byte
 
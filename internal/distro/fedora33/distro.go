@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/fsnode"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
 
 	"github.com/google/uuid"
@@ -28,6 +29,17 @@ const f35Name = "fedora-35"
 const f35modulePlatformID = "platform:f35"
 const f35ostreeRef = "fedora/35/%s/iot"
 
+// Rawhide is the development branch of Fedora, which becomes the next
+// release when it branches. Its module platform needs to be bumped then.
+const rawhideName = "fedora-rawhide"
+const rawhideModulePlatformID = "platform:f36"
+const rawhideOstreeRef = "fedora/rawhide/%s/iot"
+
+// ELN rebuilds Rawhide like the next major release of RHEL. It doesn't
+// have an IoT edition.
+const elnName = "fedora-eln"
+const elnModulePlatformID = "platform:eln"
+
 type distribution struct {
 	name             string
 	modulePlatformID string
@@ -225,8 +237,9 @@ func (t *imageType) PackageSets(bp blueprint.Blueprint) map[string]rpmmd.Package
 	includePackages, excludePackages := t.Packages(bp)
 	return map[string]rpmmd.PackageSet{
 		"packages": {
-			Include: includePackages,
-			Exclude: excludePackages,
+			Include:       includePackages,
+			Exclude:       excludePackages,
+			EnableModules: bp.GetEnabledModules(),
 		},
 		"build-packages": {
 			Include: t.BuildPackages(),
@@ -243,6 +256,9 @@ func (t *imageType) Manifest(c *blueprint.Customizations,
 	repos []rpmmd.RepoConfig,
 	packageSpecSets map[string][]rpmmd.PackageSpec,
 	seed int64) (distro.Manifest, error) {
+	if t.name == "wsl" {
+		c = distro.WSLCustomizations(c)
+	}
 	pipeline, err := t.pipeline(c, options, repos, packageSpecSets["packages"], packageSpecSets["build-packages"])
 	if err != nil {
 		return distro.Manifest{}, err
@@ -289,10 +305,34 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		return nil, fmt.Errorf("kernel boot parameter customizations are not supported for ostree types")
 	}
 
+	if len(c.GetFilesystems()) > 0 {
+		return nil, fmt.Errorf("filesystem customizations are not supported for image type %s", t.name)
+	}
+
+	if c.GetDisk() != nil {
+		return nil, fmt.Errorf("disk customizations are not supported for image type %s", t.name)
+	}
+
+	if c.GetFDO() != nil || c.GetIgnition() != nil {
+		return nil, fmt.Errorf("FDO and Ignition customizations are not supported for image type %s", t.name)
+	}
+
+	if c.GetInstallationDevice() != "" {
+		return nil, fmt.Errorf("installation device customizations are not supported for image type %s", t.name)
+	}
+
+	if options.Subscription != nil {
+		return nil, fmt.Errorf("subscriptions are not supported for image type %s", t.name)
+	}
+
+	if err := fsnode.Check(append(fsnode.UnitFiles(c.GetServices()), fsnode.Files(c, options.ModuleStreams)...), c.GetDirectories()); err != nil {
+		return nil, err
+	}
+
 	p := &osbuild.Pipeline{}
 	p.SetBuild(t.buildPipeline(repos, *t.arch, buildPackageSpecs), "org.osbuild.fedora33")
 
-	p.AddStage(osbuild.NewKernelCmdlineStage(t.kernelCmdlineStageOptions()))
+	p.AddStage(osbuild.NewKernelCmdlineStage(t.kernelCmdlineStageOptions(c.GetKernel())))
 	p.AddStage(osbuild.NewRPMStage(t.rpmStageOptions(*t.arch, repos, packageSpecs)))
 
 	// TODO support setting all languages and install corresponding langpack-* package
@@ -344,6 +384,11 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 	}
 	p.AddStage(osbuild.NewFixBLSStage())
 
+	// units need to exist before they can be enabled
+	if stage := fsnode.ScriptStage(fsnode.UnitFiles(c.GetServices()), nil); stage != nil {
+		p.AddStage(stage)
+	}
+
 	if services := c.GetServices(); services != nil || t.enabledServices != nil {
 		p.AddStage(osbuild.NewSystemdStage(t.systemdStageOptions(t.enabledServices, t.disabledServices, services)))
 	}
@@ -352,6 +397,10 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		p.AddStage(osbuild.NewFirewallStage(t.firewallStageOptions(firewall)))
 	}
 
+	if stage := fsnode.ScriptStage(fsnode.Files(c, options.ModuleStreams), c.GetDirectories()); stage != nil {
+		p.AddStage(stage)
+	}
+
 	p.AddStage(osbuild.NewSELinuxStage(t.selinuxStageOptions()))
 
 	if t.rpmOstree {
@@ -383,10 +432,14 @@ func (t *imageType) buildPipeline(repos []rpmmd.RepoConfig, arch architecture, b
 	return p
 }
 
-func (t *imageType) kernelCmdlineStageOptions() *osbuild.KernelCmdlineStageOptions {
+func (t *imageType) kernelCmdlineStageOptions(kernel *blueprint.KernelCustomization) *osbuild.KernelCmdlineStageOptions {
+	kernelOptions := "ro no_timer_check net.ifnames=0 console=tty1 console=ttyS0,115200n8"
+	if kernel != nil && kernel.Append != "" {
+		kernelOptions += " " + kernel.Append
+	}
 	return &osbuild.KernelCmdlineStageOptions{
 		RootFsUUID: "76a22bf4-f153-4541-b6c7-0332c0dfaeac",
-		KernelOpts: "ro no_timer_check net.ifnames=0 console=tty1 console=ttyS0,115200n8",
+		KernelOpts: kernelOptions,
 	}
 }
 
@@ -429,17 +482,31 @@ func (t *imageType) userStageOptions(users []blueprint.UserCustomization) (*osbu
 			c.Password = &cryptedPassword
 		}
 
+		// a "!" in front of the hash disables password logins
+		if c.Locked {
+			lockedPassword := "!"
+			if c.Password != nil {
+				lockedPassword += *c.Password
+			}
+			c.Password = &lockedPassword
+		}
+
 		user := osbuild.UsersStageOptionsUser{
 			Groups:      c.Groups,
 			Description: c.Description,
 			Home:        c.Home,
 			Shell:       c.Shell,
 			Password:    c.Password,
-			Key:         c.Key,
+			Key:         c.AuthorizedKeys(),
+			ExpireDate:  c.ExpireDate,
 		}
 
 		user.UID = c.UID
 		user.GID = c.GID
+		if c.ForcePasswordReset {
+			forcePasswordReset := true
+			user.ForcePasswordReset = &forcePasswordReset
+		}
 
 		options.Users[c.Name] = user
 	}
@@ -474,17 +541,27 @@ func (t *imageType) firewallStageOptions(firewall *blueprint.FirewallCustomizati
 		options.DisabledServices = firewall.Services.Disabled
 	}
 
+	for _, zone := range firewall.Zones {
+		options.Zones = append(options.Zones, osbuild.FirewallZone{
+			Name:    zone.Name,
+			Sources: zone.Sources,
+		})
+	}
+
 	return &options
 }
 
 func (t *imageType) systemdStageOptions(enabledServices, disabledServices []string, s *blueprint.ServicesCustomization) *osbuild.SystemdStageOptions {
+	var maskedServices []string
 	if s != nil {
 		enabledServices = append(enabledServices, s.Enabled...)
 		disabledServices = append(disabledServices, s.Disabled...)
+		maskedServices = s.Masked
 	}
 	return &osbuild.SystemdStageOptions{
 		EnabledServices:  enabledServices,
 		DisabledServices: disabledServices,
+		MaskedServices:   maskedServices,
 	}
 }
 
@@ -586,6 +663,14 @@ func qemuAssembler(format string, filename string, uefi bool, imageOptions distr
 	return osbuild.NewQEMUAssembler(&options)
 }
 
+func tarAssembler(filename, compression string) *osbuild.Assembler {
+	return osbuild.NewTarAssembler(
+		&osbuild.TarAssemblerOptions{
+			Filename:    filename,
+			Compression: compression,
+		})
+}
+
 func ostreeCommitAssembler(options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
 	return osbuild.NewOSTreeCommitAssembler(
 		&osbuild.OSTreeCommitAssemblerOptions{
@@ -611,6 +696,18 @@ func NewF35() distro.Distro {
 	return newDistro(f35Name, f35modulePlatformID, f35ostreeRef)
 }
 
+// NewRawhide returns Fedora Rawhide. Its repositories point to the latest
+// nightly compose, which is resolved when depsolving.
+func NewRawhide() distro.Distro {
+	return newDistro(rawhideName, rawhideModulePlatformID, rawhideOstreeRef)
+}
+
+// NewELN returns Fedora ELN. Like for Rawhide, its repositories point to the
+// latest nightly compose.
+func NewELN() distro.Distro {
+	return newDistro(elnName, elnModulePlatformID, "")
+}
+
 func NewHostDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 	return newDistro(name, modulePlatformID, ostreeRef)
 }
@@ -853,6 +950,26 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 		},
 	}
 
+	wslImgType := imageType{
+		name:     "wsl",
+		filename: "rootfs.tar.gz",
+		mimeType: "application/x-tar",
+		packages: []string{
+			"@core",
+			"langpacks-en",
+			"selinux-policy-targeted",
+		},
+		excludedPackages: []string{
+			"dracut-config-rescue",
+			"plymouth",
+			"zram-generator-defaults",
+		},
+		bootable: false,
+		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return tarAssembler("rootfs.tar.gz", "gzip")
+		},
+	}
+
 	r := distribution{
 		buildPackages: []string{
 			"dnf",
@@ -881,14 +998,18 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 		},
 		legacy: "i386-pc",
 	}
-	x8664.setImageTypes(
-		iotImgType,
+	x8664ImageTypes := []imageType{
 		amiImgType,
 		qcow2ImageType,
 		openstackImgType,
 		vhdImgType,
 		vmdkImgType,
-	)
+		wslImgType,
+	}
+	if ostreeRef != "" {
+		x8664ImageTypes = append(x8664ImageTypes, iotImgType)
+	}
+	x8664.setImageTypes(x8664ImageTypes...)
 
 	aarch64 := architecture{
 		distro: &r,
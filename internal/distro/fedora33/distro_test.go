@@ -52,6 +52,12 @@ func TestFilenameFromType(t *testing.T) {
 			want:  "disk.vmdk",
 			want1: "application/x-vmdk",
 		},
+		{
+			name:  "wsl",
+			args:  args{"wsl"},
+			want:  "rootfs.tar.gz",
+			want1: "application/x-tar",
+		},
 		{
 			name:    "invalid-output-type",
 			args:    args{"foobar"},
@@ -150,6 +156,7 @@ func TestImageType_Name(t *testing.T) {
 				"openstack",
 				"vhd",
 				"vmdk",
+				"wsl",
 			},
 		},
 		{
@@ -379,3 +386,23 @@ func TestFedora33_ModulePlatformID(t *testing.T) {
 func TestFedora33_KernelOption(t *testing.T) {
 	distro_test_common.TestDistro_KernelOption(t, fedora33.New())
 }
+
+func TestFedoraRawhide(t *testing.T) {
+	distro := fedora33.NewRawhide()
+	assert.Equal(t, "fedora-rawhide", distro.Name())
+	assert.Equal(t, "platform:f36", distro.ModulePlatformID())
+
+	arch, err := distro.GetArch("x86_64")
+	assert.NoError(t, err)
+	assert.Contains(t, arch.ListImageTypes(), "fedora-iot-commit")
+}
+
+func TestFedoraELN(t *testing.T) {
+	distro := fedora33.NewELN()
+	assert.Equal(t, "fedora-eln", distro.Name())
+	assert.Equal(t, "platform:eln", distro.ModulePlatformID())
+
+	arch, err := distro.GetArch("x86_64")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ami", "openstack", "qcow2", "vhd", "vmdk", "wsl"}, arch.ListImageTypes())
+}
@@ -0,0 +1,49 @@
+package distro
+
+import "github.com/osbuild/osbuild-composer/internal/blueprint"
+
+const (
+	// GCEDiskFile is the name of the raw disk image in the archives which
+	// Google Compute Engine imports.
+	GCEDiskFile = "disk.raw"
+
+	// GCEMetadataServer is the metadata server of Compute Engine instances,
+	// which is also their NTP server.
+	GCEMetadataServer = "metadata.google.internal"
+
+	// GCEFloppyBlacklistPath keeps the kernel from probing for the floppy
+	// drive, which instances don't have.
+	GCEFloppyBlacklistPath = "/etc/modprobe.d/blacklist-floppy.conf"
+)
+
+// GCECustomizations returns a copy of `c` for Google Compute Engine images,
+// which additionally use UTC and the metadata server for time
+// synchronization, unless `c` customizes the timezone, and blacklist the
+// floppy driver, unless `c` customizes its configuration file.
+func GCECustomizations(c *blueprint.Customizations) *blueprint.Customizations {
+	var gce blueprint.Customizations
+	if c != nil {
+		gce = *c
+	}
+
+	if gce.Timezone == nil {
+		timezone := "UTC"
+		gce.Timezone = &blueprint.TimezoneCustomization{
+			Timezone:   &timezone,
+			NTPServers: []string{GCEMetadataServer},
+		}
+	}
+
+	for _, f := range gce.Files {
+		if f.Path == GCEFloppyBlacklistPath {
+			return &gce
+		}
+	}
+	gce.Files = append([]blueprint.FileCustomization{{
+		Path: GCEFloppyBlacklistPath,
+		Mode: "0644",
+		Data: "blacklist floppy\n",
+	}}, gce.Files...)
+
+	return &gce
+}
@@ -0,0 +1,30 @@
+package distro_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+)
+
+func TestGCECustomizations(t *testing.T) {
+	gce := distro.GCECustomizations(nil)
+	timezone, ntpServers := gce.GetTimezoneSettings()
+	require.NotNil(t, timezone)
+	assert.Equal(t, "UTC", *timezone)
+	assert.Equal(t, []string{distro.GCEMetadataServer}, ntpServers)
+	require.Len(t, gce.Files, 1)
+	assert.Equal(t, distro.GCEFloppyBlacklistPath, gce.Files[0].Path)
+
+	berlin := "Europe/Berlin"
+	c := &blueprint.Customizations{
+		Timezone: &blueprint.TimezoneCustomization{Timezone: &berlin},
+		Files:    []blueprint.FileCustomization{{Path: distro.GCEFloppyBlacklistPath, Data: ""}},
+	}
+	gce = distro.GCECustomizations(c)
+	assert.Equal(t, c.Timezone, gce.Timezone)
+	assert.Equal(t, c.Files, gce.Files)
+}
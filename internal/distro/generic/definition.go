@@ -0,0 +1,214 @@
+package generic
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+// Definition describes a distribution whose image types are built from
+// the generic pipelines of this package. Definitions are read from TOML or
+// YAML files, so that distributions can be added without changing code.
+type Definition struct {
+	// Name of the distribution, e.g. centos-9. The repositories are
+	// looked up by this name.
+	Name             string `toml:"name" yaml:"name"`
+	ModulePlatformID string `toml:"module_platform_id" yaml:"module_platform_id"`
+
+	// osbuild runner of the build pipeline. Defaults to
+	// org.osbuild.linux.
+	Runner string `toml:"runner" yaml:"runner"`
+
+	// Directory of grub2 on the EFI system partition, e.g. centos.
+	// Required if any architecture boots with UEFI.
+	UEFIVendor string `toml:"uefi_vendor" yaml:"uefi_vendor"`
+
+	// Package sets of all architectures: "build" is installed in the
+	// build root, "boot" in the tree of bootable images.
+	PackageSets map[string]PackageSet `toml:"package_sets" yaml:"package_sets"`
+
+	Arches     map[string]ArchDefinition      `toml:"arches" yaml:"arches"`
+	ImageTypes map[string]ImageTypeDefinition `toml:"image_types" yaml:"image_types"`
+}
+
+// ArchDefinition describes how images of an architecture boot.
+type ArchDefinition struct {
+	// grub2 platform of the legacy BIOS boot loader, e.g. i386-pc, or
+	// empty if the architecture doesn't boot from BIOS
+	BIOSPlatform string `toml:"bios_platform" yaml:"bios_platform"`
+	UEFI         bool   `toml:"uefi" yaml:"uefi"`
+
+	// Package sets which are added to the ones of the distribution
+	PackageSets map[string]PackageSet `toml:"package_sets" yaml:"package_sets"`
+}
+
+// ImageTypeDefinition describes an image type, which is built for each of
+// its architectures.
+type ImageTypeDefinition struct {
+	Arches   []string `toml:"arches" yaml:"arches"`
+	Filename string   `toml:"filename" yaml:"filename"`
+	MIMEType string   `toml:"mime_type" yaml:"mime_type"`
+
+	// One of tar, raw, qcow2, vmdk, or vhd. All but tar are bootable disk
+	// images.
+	Format string `toml:"format" yaml:"format"`
+
+	// Size of disk images in bytes, unless a compose requests a larger one
+	DefaultSize uint64 `toml:"default_size" yaml:"default_size"`
+
+	KernelOptions    string   `toml:"kernel_options" yaml:"kernel_options"`
+	EnabledServices  []string `toml:"enabled_services" yaml:"enabled_services"`
+	DisabledServices []string `toml:"disabled_services" yaml:"disabled_services"`
+	DefaultTarget    string   `toml:"default_target" yaml:"default_target"`
+
+	// Packages installed in the tree of the image
+	Packages PackageSet `toml:"packages" yaml:"packages"`
+}
+
+// PackageSet is a set of packages to include and exclude.
+type PackageSet struct {
+	Include []string `toml:"include" yaml:"include"`
+	Exclude []string `toml:"exclude" yaml:"exclude"`
+}
+
+func (s PackageSet) packageSet() rpmmd.PackageSet {
+	return rpmmd.PackageSet{Include: s.Include, Exclude: s.Exclude}
+}
+
+// Image formats and the names of the pipelines which produce them
+var formatExports = map[string]string{
+	"tar":   "root-tar",
+	"raw":   "image",
+	"qcow2": "qcow2",
+	"vmdk":  "vmdk",
+	"vhd":   "vpc",
+}
+
+func (f ImageTypeDefinition) bootable() bool {
+	return f.Format != "tar"
+}
+
+// LoadDefinitions reads the definitions in the *.toml, *.yaml, and *.yml
+// files in `dirs`. A definition in an earlier directory overrides those of
+// the same name in later ones, so that definitions shipped in
+// /usr/share/osbuild-composer/distros can be replaced by files in
+// /etc/osbuild-composer/distros. Directories which don't exist are skipped.
+func LoadDefinitions(dirs ...string) ([]Definition, error) {
+	seen := make(map[string]bool)
+	var definitions []Definition
+
+	for _, dir := range dirs {
+		infos, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		var dirDefinitions []Definition
+		for _, info := range infos {
+			if info.IsDir() {
+				continue
+			}
+			p := filepath.Join(dir, info.Name())
+			var def Definition
+			switch filepath.Ext(p) {
+			case ".toml":
+				var md toml.MetaData
+				md, err = toml.DecodeFile(p, &def)
+				if undecoded := md.Undecoded(); err == nil && len(undecoded) > 0 {
+					err = fmt.Errorf("unknown key %s", undecoded[0])
+				}
+			case ".yaml", ".yml":
+				var data []byte
+				data, err = ioutil.ReadFile(p)
+				if err == nil {
+					err = yaml.UnmarshalStrict(data, &def)
+				}
+			default:
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error reading distro definition %s: %v", p, err)
+			}
+			if err = def.Check(); err != nil {
+				return nil, fmt.Errorf("invalid distro definition %s: %v", p, err)
+			}
+			dirDefinitions = append(dirDefinitions, def)
+		}
+
+		for _, def := range dirDefinitions {
+			if seen[def.Name] {
+				continue
+			}
+			seen[def.Name] = true
+			definitions = append(definitions, def)
+		}
+	}
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+
+	return definitions, nil
+}
+
+// Check returns an error if the definition is incomplete or describes
+// image types which cannot be built.
+func (d Definition) Check() error {
+	if d.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if d.ModulePlatformID == "" {
+		return fmt.Errorf("module_platform_id is required")
+	}
+	if len(d.Arches) == 0 {
+		return fmt.Errorf("at least one architecture is required")
+	}
+
+	for name, t := range d.ImageTypes {
+		if t.Filename == "" || t.MIMEType == "" {
+			return fmt.Errorf("image type %s: filename and mime_type are required", name)
+		}
+		if _, ok := formatExports[t.Format]; !ok {
+			return fmt.Errorf("image type %s: unsupported format %q, supported formats are %s", name, t.Format, strings.Join(formats(), ", "))
+		}
+		if t.bootable() && t.DefaultSize == 0 {
+			return fmt.Errorf("image type %s: default_size is required for %s images", name, t.Format)
+		}
+		if len(t.Arches) == 0 {
+			return fmt.Errorf("image type %s: at least one architecture is required", name)
+		}
+		for _, archName := range t.Arches {
+			arch, ok := d.Arches[archName]
+			if !ok {
+				return fmt.Errorf("image type %s: architecture %s is not defined", name, archName)
+			}
+			if t.bootable() && arch.BIOSPlatform == "" && !arch.UEFI {
+				return fmt.Errorf("image type %s: %s images cannot boot on %s, which has neither bios_platform nor uefi set", name, t.Format, archName)
+			}
+			if t.bootable() && arch.UEFI && d.UEFIVendor == "" {
+				return fmt.Errorf("image type %s: uefi_vendor is required for images which boot with UEFI", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func formats() []string {
+	var names []string
+	for f := range formatExports {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,325 @@
+// Package generic implements distributions which are defined by definition
+// files instead of code. Their image types are built with a common set of
+// pipelines, which install the packages of the image type and configure the
+// tree according to the blueprint.
+package generic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/disk"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/fsnode"
+	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild2"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+const defaultRunner = "org.osbuild.linux"
+
+type distribution struct {
+	name             string
+	modulePlatformID string
+	runner           string
+	uefiVendor       string
+	arches           map[string]distro.Arch
+	packageSets      map[string]rpmmd.PackageSet
+}
+
+// New returns the distribution defined by `def`, which must have been
+// checked with Definition.Check().
+func New(def Definition) distro.Distro {
+	return NewHostDistro(def, def.Name)
+}
+
+// NewHostDistro returns the distribution defined by `def` under the name
+// `name`, which is the name of the host distribution.
+func NewHostDistro(def Definition, name string) distro.Distro {
+	d := &distribution{
+		name:             name,
+		modulePlatformID: def.ModulePlatformID,
+		runner:           def.Runner,
+		uefiVendor:       def.UEFIVendor,
+		arches:           make(map[string]distro.Arch),
+		packageSets:      packageSets(def.PackageSets),
+	}
+	if d.runner == "" {
+		d.runner = defaultRunner
+	}
+
+	for archName, archDef := range def.Arches {
+		a := &architecture{
+			distro:       d,
+			name:         archName,
+			imageTypes:   make(map[string]distro.ImageType),
+			packageSets:  packageSets(archDef.PackageSets),
+			biosPlatform: archDef.BIOSPlatform,
+			uefi:         archDef.UEFI,
+		}
+		d.arches[archName] = a
+	}
+
+	for typeName, typeDef := range def.ImageTypes {
+		for _, archName := range typeDef.Arches {
+			a := d.arches[archName].(*architecture)
+			a.imageTypes[typeName] = &imageType{
+				arch:             a,
+				name:             typeName,
+				filename:         typeDef.Filename,
+				mimeType:         typeDef.MIMEType,
+				format:           typeDef.Format,
+				defaultSize:      typeDef.DefaultSize,
+				kernelOptions:    typeDef.KernelOptions,
+				enabledServices:  typeDef.EnabledServices,
+				disabledServices: typeDef.DisabledServices,
+				defaultTarget:    typeDef.DefaultTarget,
+				packages:         typeDef.Packages.packageSet(),
+			}
+		}
+	}
+
+	return d
+}
+
+func packageSets(sets map[string]PackageSet) map[string]rpmmd.PackageSet {
+	result := make(map[string]rpmmd.PackageSet)
+	for name, set := range sets {
+		result[name] = set.packageSet()
+	}
+	return result
+}
+
+func (d *distribution) Name() string {
+	return d.name
+}
+
+func (d *distribution) ModulePlatformID() string {
+	return d.modulePlatformID
+}
+
+// OSTreeRef returns an empty string, generic distributions don't have ostree
+// image types.
+func (d *distribution) OSTreeRef() string {
+	return ""
+}
+
+func (d *distribution) ListArches() []string {
+	archNames := make([]string, 0, len(d.arches))
+	for name := range d.arches {
+		archNames = append(archNames, name)
+	}
+	sort.Strings(archNames)
+	return archNames
+}
+
+func (d *distribution) GetArch(name string) (distro.Arch, error) {
+	arch, exists := d.arches[name]
+	if !exists {
+		return nil, errors.New("invalid architecture: " + name)
+	}
+	return arch, nil
+}
+
+type architecture struct {
+	distro      *distribution
+	name        string
+	imageTypes  map[string]distro.ImageType
+	packageSets map[string]rpmmd.PackageSet
+	// grub2 platform of the legacy BIOS boot loader, or empty
+	biosPlatform string
+	uefi         bool
+}
+
+func (a *architecture) Name() string {
+	return a.name
+}
+
+func (a *architecture) ListImageTypes() []string {
+	itNames := make([]string, 0, len(a.imageTypes))
+	for name := range a.imageTypes {
+		itNames = append(itNames, name)
+	}
+	sort.Strings(itNames)
+	return itNames
+}
+
+func (a *architecture) GetImageType(name string) (distro.ImageType, error) {
+	t, exists := a.imageTypes[name]
+	if !exists {
+		return nil, errors.New("invalid image type: " + name)
+	}
+	return t, nil
+}
+
+func (a *architecture) Distro() distro.Distro {
+	return a.distro
+}
+
+type imageType struct {
+	arch             *architecture
+	name             string
+	filename         string
+	mimeType         string
+	format           string
+	defaultSize      uint64
+	kernelOptions    string
+	enabledServices  []string
+	disabledServices []string
+	defaultTarget    string
+	packages         rpmmd.PackageSet
+}
+
+func (t *imageType) Name() string {
+	return t.name
+}
+
+func (t *imageType) Arch() distro.Arch {
+	return t.arch
+}
+
+func (t *imageType) Filename() string {
+	return t.filename
+}
+
+func (t *imageType) MIMEType() string {
+	return t.mimeType
+}
+
+func (t *imageType) OSTreeRef() string {
+	return ""
+}
+
+func (t *imageType) Size(size uint64) uint64 {
+	if size == 0 {
+		size = t.defaultSize
+	}
+	return size
+}
+
+func (t *imageType) bootable() bool {
+	return t.format != "tar"
+}
+
+func (t *imageType) PackageSets(bp blueprint.Blueprint) map[string]rpmmd.PackageSet {
+	distroSets := t.arch.distro.packageSets
+	archSets := t.arch.packageSets
+
+	packages := t.packages
+	if t.bootable() {
+		packages = packages.Append(archSets["boot"]).Append(distroSets["boot"])
+	}
+
+	bpPackages := bp.GetPackages()
+	timezone, _ := bp.Customizations.GetTimezoneSettings()
+	if timezone != nil {
+		bpPackages = append(bpPackages, "chrony")
+	}
+
+	return map[string]rpmmd.PackageSet{
+		"build":    archSets["build"].Append(distroSets["build"]),
+		"packages": packages.Append(rpmmd.PackageSet{Include: bpPackages, EnableModules: bp.GetEnabledModules()}),
+	}
+}
+
+func (t *imageType) Exports() []string {
+	return []string{formatExports[t.format]}
+}
+
+func (t *imageType) Manifest(customizations *blueprint.Customizations,
+	options distro.ImageOptions,
+	repos []rpmmd.RepoConfig,
+	packageSpecSets map[string][]rpmmd.PackageSpec,
+	seed int64) (distro.Manifest, error) {
+
+	if err := t.checkOptions(customizations, options); err != nil {
+		return distro.Manifest{}, err
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	pipelines, err := t.pipelines(customizations, options, repos, packageSpecSets, rng)
+	if err != nil {
+		return distro.Manifest{}, err
+	}
+
+	var packages []rpmmd.PackageSpec
+	for _, specs := range packageSpecSets {
+		packages = append(packages, specs...)
+	}
+	files := append(fsnode.UnitFiles(customizations.GetServices()), fsnode.Files(customizations, options.ModuleStreams)...)
+
+	return json.Marshal(
+		osbuild.Manifest{
+			Version:   "2",
+			Pipelines: pipelines,
+			Sources:   sources(packages, files),
+		},
+	)
+}
+
+func sources(packages []rpmmd.PackageSpec, files []blueprint.FileCustomization) osbuild.Sources {
+	sources := osbuild.Sources{}
+	curl := &osbuild.CurlSource{
+		Items: make(map[string]osbuild.CurlSourceItem),
+	}
+	for _, pkg := range packages {
+		item := new(osbuild.URLWithSecrets)
+		item.URL = pkg.RemoteLocation
+		if pkg.Secrets == "org.osbuild.rhsm" {
+			item.Secrets = &osbuild.URLSecrets{
+				Name: "org.osbuild.rhsm",
+			}
+		}
+		curl.Items[pkg.Checksum] = item
+	}
+	if len(curl.Items) > 0 {
+		sources["org.osbuild.curl"] = curl
+	}
+
+	if inline := fsnode.InlineSource(files); inline != nil {
+		sources["org.osbuild.inline"] = inline
+	}
+	return sources
+}
+
+// checkOptions returns an error for the customizations and options which the
+// generic pipelines don't support.
+func (t *imageType) checkOptions(customizations *blueprint.Customizations, options distro.ImageOptions) error {
+	if customizations.GetFDO() != nil || customizations.GetIgnition() != nil {
+		return fmt.Errorf("FDO and Ignition customizations are not supported for image type %q", t.name)
+	}
+
+	if customizations.GetInstallationDevice() != "" {
+		return fmt.Errorf("installation device customizations are not supported for image type %q", t.name)
+	}
+
+	if customizations.GetDisk() != nil {
+		return fmt.Errorf("disk customizations are not supported for image type %q", t.name)
+	}
+
+	if mountpoints := customizations.GetFilesystems(); len(mountpoints) > 0 {
+		if !t.bootable() {
+			return fmt.Errorf("filesystem customizations are not supported for image type %q", t.name)
+		}
+		if err := disk.CheckMountpoints(mountpoints, disk.MountpointAllowList); err != nil {
+			return err
+		}
+	}
+
+	if options.Subscription != nil {
+		return fmt.Errorf("image type %q cannot be registered with a subscription", t.name)
+	}
+
+	if options.CompressionLevel != nil {
+		return fmt.Errorf("image type %q is not compressed, its compression level cannot be set", t.name)
+	}
+
+	if err := fsnode.Check(append(fsnode.UnitFiles(customizations.GetServices()), fsnode.Files(customizations, options.ModuleStreams)...), customizations.GetDirectories()); err != nil {
+		return err
+	}
+
+	return nil
+}
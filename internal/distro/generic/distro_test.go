@@ -0,0 +1,333 @@
+package generic
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+const testDefinitionTOML = `
+name = "toucan-1"
+module_platform_id = "platform:tc1"
+uefi_vendor = "toucan"
+
+[package_sets.build]
+include = ["dnf", "rpm", "selinux-policy-targeted"]
+
+[package_sets.boot]
+include = ["kernel", "grub2-pc"]
+
+[arches.x86_64]
+bios_platform = "i386-pc"
+uefi = true
+
+[arches.x86_64.package_sets.boot]
+include = ["grub2-efi-x64", "shim-x64"]
+
+[image_types.qcow2]
+arches = ["x86_64"]
+filename = "disk.qcow2"
+mime_type = "application/x-qemu-disk"
+format = "qcow2"
+default_size = 4294967296
+kernel_options = "console=ttyS0"
+enabled_services = ["sshd"]
+
+[image_types.qcow2.packages]
+include = ["@core"]
+exclude = ["dracut-config-rescue"]
+
+[image_types.tar]
+arches = ["x86_64"]
+filename = "root.tar.xz"
+mime_type = "application/x-tar"
+format = "tar"
+
+[image_types.tar.packages]
+include = ["bash"]
+`
+
+const testDefinitionYAML = `
+name: heron-2
+module_platform_id: platform:hr2
+arches:
+  aarch64: {}
+image_types:
+  tar:
+    arches: [aarch64]
+    filename: root.tar.xz
+    mime_type: application/x-tar
+    format: tar
+    packages:
+      include: [bash]
+`
+
+func writeDefinition(t *testing.T, dir, name, content string) {
+	err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	require.NoError(t, err)
+}
+
+func testDistro(t *testing.T) distro.Distro {
+	dir, err := ioutil.TempDir("", "generic-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeDefinition(t, dir, "toucan-1.toml", testDefinitionTOML)
+	definitions, err := LoadDefinitions(dir)
+	require.NoError(t, err)
+	require.Len(t, definitions, 1)
+
+	return New(definitions[0])
+}
+
+func TestLoadDefinitions(t *testing.T) {
+	etc, err := ioutil.TempDir("", "generic-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(etc)
+	usr, err := ioutil.TempDir("", "generic-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(usr)
+
+	writeDefinition(t, usr, "toucan-1.toml", testDefinitionTOML)
+	writeDefinition(t, usr, "heron-2.yaml", testDefinitionYAML)
+	writeDefinition(t, usr, "README", "not a definition")
+	// overrides the definition of the same name in usr
+	writeDefinition(t, etc, "heron.yml", `
+name: heron-2
+module_platform_id: platform:hr2-custom
+arches:
+  aarch64: {}
+`)
+
+	definitions, err := LoadDefinitions(etc, usr, filepath.Join(usr, "missing"))
+	require.NoError(t, err)
+	require.Len(t, definitions, 2)
+
+	assert.Equal(t, "heron-2", definitions[0].Name)
+	assert.Equal(t, "platform:hr2-custom", definitions[0].ModulePlatformID)
+	assert.Empty(t, definitions[0].ImageTypes)
+
+	def := definitions[1]
+	assert.Equal(t, "toucan-1", def.Name)
+	assert.Equal(t, "i386-pc", def.Arches["x86_64"].BIOSPlatform)
+	assert.Equal(t, []string{"grub2-efi-x64", "shim-x64"}, def.Arches["x86_64"].PackageSets["boot"].Include)
+	assert.Equal(t, uint64(4294967296), def.ImageTypes["qcow2"].DefaultSize)
+	assert.Equal(t, []string{"dracut-config-rescue"}, def.ImageTypes["qcow2"].Packages.Exclude)
+}
+
+func TestLoadDefinitions_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		err     string
+	}{
+		{"syntax", "a.toml", `name = `, "error reading distro definition"},
+		{"unknown toml key", "a.toml", "name = \"a\"\nmodule_platform_id = \"p\"\nfoo = 1\n[arches.x86_64]\n", "unknown key foo"},
+		{"unknown yaml key", "a.yaml", "name: a\nmodule_platform_id: p\nfoo: 1\narches:\n  x86_64: {}\n", "field foo not found"},
+		{"no name", "a.yaml", "module_platform_id: p\narches:\n  x86_64: {}\n", "name is required"},
+		{"no arches", "a.yaml", "name: a\nmodule_platform_id: p\n", "at least one architecture is required"},
+		{"bad format", "a.yaml", `
+name: a
+module_platform_id: p
+arches:
+  x86_64: {}
+image_types:
+  iso:
+    arches: [x86_64]
+    filename: a.iso
+    mime_type: application/x-iso9660-image
+    format: iso
+`, `unsupported format "iso"`},
+		{"no size", "a.yaml", `
+name: a
+module_platform_id: p
+arches:
+  x86_64: {bios_platform: i386-pc}
+image_types:
+  raw:
+    arches: [x86_64]
+    filename: disk.raw
+    mime_type: application/octet-stream
+    format: raw
+`, "default_size is required"},
+		{"undefined arch", "a.yaml", `
+name: a
+module_platform_id: p
+arches:
+  x86_64: {}
+image_types:
+  tar:
+    arches: [s390x]
+    filename: root.tar
+    mime_type: application/x-tar
+    format: tar
+`, "architecture s390x is not defined"},
+		{"not bootable", "a.yaml", `
+name: a
+module_platform_id: p
+arches:
+  x86_64: {}
+image_types:
+  raw:
+    arches: [x86_64]
+    filename: disk.raw
+    mime_type: application/octet-stream
+    format: raw
+    default_size: 1024
+`, "neither bios_platform nor uefi set"},
+		{"no uefi vendor", "a.yaml", `
+name: a
+module_platform_id: p
+arches:
+  x86_64: {uefi: true}
+image_types:
+  raw:
+    arches: [x86_64]
+    filename: disk.raw
+    mime_type: application/octet-stream
+    format: raw
+    default_size: 1024
+`, "uefi_vendor is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "generic-test-")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			writeDefinition(t, dir, tt.file, tt.content)
+			_, err = LoadDefinitions(dir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestDistro_Lists(t *testing.T) {
+	d := testDistro(t)
+	assert.Equal(t, "toucan-1", d.Name())
+	assert.Equal(t, "platform:tc1", d.ModulePlatformID())
+	assert.Equal(t, []string{"x86_64"}, d.ListArches())
+
+	_, err := d.GetArch("aarch64")
+	assert.EqualError(t, err, "invalid architecture: aarch64")
+
+	arch, err := d.GetArch("x86_64")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"qcow2", "tar"}, arch.ListImageTypes())
+
+	host := NewHostDistro(Definition{Name: "toucan-1", ModulePlatformID: "platform:tc1"}, "toucan-1-beta")
+	assert.Equal(t, "toucan-1-beta", host.Name())
+}
+
+func TestImageType_PackageSets(t *testing.T) {
+	arch, err := testDistro(t).GetArch("x86_64")
+	require.NoError(t, err)
+
+	qcow2, err := arch.GetImageType("qcow2")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4294967296), qcow2.Size(0))
+	assert.Equal(t, []string{"qcow2"}, qcow2.Exports())
+
+	bp := blueprint.Blueprint{
+		Packages: []blueprint.Package{{Name: "vim"}},
+		Customizations: &blueprint.Customizations{
+			Timezone: &blueprint.TimezoneCustomization{Timezone: &[]string{"Europe/Berlin"}[0]},
+		},
+	}
+	sets := qcow2.PackageSets(bp)
+	assert.ElementsMatch(t, []string{"dnf", "rpm", "selinux-policy-targeted"}, sets["build"].Include)
+	assert.ElementsMatch(t, []string{"@core", "grub2-efi-x64", "shim-x64", "kernel", "grub2-pc", "vim", "kernel", "chrony"}, sets["packages"].Include)
+	assert.Equal(t, []string{"dracut-config-rescue"}, sets["packages"].Exclude)
+
+	tar, err := arch.GetImageType("tar")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root-tar"}, tar.Exports())
+	assert.ElementsMatch(t, []string{"bash", "kernel"}, tar.PackageSets(blueprint.Blueprint{})["packages"].Include)
+}
+
+func testManifest(t *testing.T, imageType string, c *blueprint.Customizations) (map[string]interface{}, error) {
+	arch, err := testDistro(t).GetArch("x86_64")
+	require.NoError(t, err)
+	it, err := arch.GetImageType(imageType)
+	require.NoError(t, err)
+
+	packages := []rpmmd.PackageSpec{{Name: "kernel", Version: "5.14", Release: "1", Arch: "x86_64", Checksum: "sha256:aaaa", RemoteLocation: "https://example.com/kernel.rpm"}}
+	m, err := it.Manifest(c, distro.ImageOptions{}, nil, map[string][]rpmmd.PackageSpec{"build": packages, "packages": packages}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest map[string]interface{}
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	return manifest, nil
+}
+
+// Returns the names of the pipelines of a manifest and the types of the
+// stages of the pipeline `name`.
+func pipelineStages(manifest map[string]interface{}, name string) ([]string, []string) {
+	var pipelines, stages []string
+	for _, p := range manifest["pipelines"].([]interface{}) {
+		pipeline := p.(map[string]interface{})
+		pipelines = append(pipelines, pipeline["name"].(string))
+		if pipeline["name"] != name {
+			continue
+		}
+		for _, s := range pipeline["stages"].([]interface{}) {
+			stages = append(stages, s.(map[string]interface{})["type"].(string))
+		}
+	}
+	return pipelines, stages
+}
+
+func TestImageType_Manifest(t *testing.T) {
+	manifest, err := testManifest(t, "qcow2", &blueprint.Customizations{
+		Hostname: &[]string{"toucan"}[0],
+	})
+	require.NoError(t, err)
+
+	pipelines, stages := pipelineStages(manifest, "os")
+	assert.Equal(t, []string{"build", "os", "image", "qcow2"}, pipelines)
+	assert.Equal(t, []string{
+		"org.osbuild.rpm",
+		"org.osbuild.fix-bls",
+		"org.osbuild.locale",
+		"org.osbuild.hostname",
+		"org.osbuild.timezone",
+		"org.osbuild.systemd",
+		"org.osbuild.fstab",
+		"org.osbuild.grub2",
+		"org.osbuild.selinux",
+	}, stages)
+
+	_, stages = pipelineStages(manifest, "image")
+	assert.Contains(t, stages, "org.osbuild.grub2.inst")
+	assert.Equal(t, "org.osbuild.linux", manifest["pipelines"].([]interface{})[0].(map[string]interface{})["runner"])
+
+	manifest, err = testManifest(t, "tar", nil)
+	require.NoError(t, err)
+	pipelines, stages = pipelineStages(manifest, "os")
+	assert.Equal(t, []string{"build", "os", "root-tar"}, pipelines)
+	assert.NotContains(t, stages, "org.osbuild.grub2")
+}
+
+func TestImageType_ManifestError(t *testing.T) {
+	_, err := testManifest(t, "tar", &blueprint.Customizations{
+		Filesystem: []blueprint.FilesystemCustomization{{Size: 1024, Mountpoint: "/var"}},
+	})
+	assert.EqualError(t, err, `filesystem customizations are not supported for image type "tar"`)
+
+	_, err = testManifest(t, "qcow2", &blueprint.Customizations{InstallationDevice: "/dev/sda"})
+	assert.EqualError(t, err, `installation device customizations are not supported for image type "qcow2"`)
+}
@@ -0,0 +1,258 @@
+package generic
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/disk"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/fsnode"
+	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild2"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+// Name of the raw disk image, which is converted to the other disk formats
+const diskfile = "disk.img"
+
+// pipelines returns the pipelines of the image type: the build pipeline, the
+// os pipeline with the tree, and the pipelines which produce the image in the
+// format of the image type from the tree.
+func (t *imageType) pipelines(customizations *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSetSpecs map[string][]rpmmd.PackageSpec, rng *rand.Rand) ([]osbuild.Pipeline, error) {
+	pipelines := make([]osbuild.Pipeline, 0)
+	pipelines = append(pipelines, *t.buildPipeline(repos, packageSetSpecs["build"]))
+
+	if !t.bootable() {
+		treePipeline, err := t.osPipeline(repos, packageSetSpecs["packages"], customizations, options, nil)
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, *treePipeline)
+
+		tarPipeline := osbuild.Pipeline{
+			Name:  formatExports[t.format],
+			Build: "name:build",
+		}
+		tarPipeline.AddStage(tarStage("os", t.Filename()))
+		pipelines = append(pipelines, tarPipeline)
+		return pipelines, nil
+	}
+
+	pt, err := disk.CreatePartitionTable(customizations.GetFilesystems(), options.Size, t.partitionTable(options, rng), rng)
+	if err != nil {
+		return nil, err
+	}
+
+	bootStages := []*osbuild.Stage{
+		osbuild.NewFSTabStage(pt.FSTabStageOptionsV2()),
+		osbuild.NewGRUB2Stage(t.grub2StageOptions(&pt, customizations.GetKernel(), packageSetSpecs["packages"])),
+	}
+	treePipeline, err := t.osPipeline(repos, packageSetSpecs["packages"], customizations, options, bootStages)
+	if err != nil {
+		return nil, err
+	}
+	pipelines = append(pipelines, *treePipeline)
+
+	if t.format == "raw" {
+		pipelines = append(pipelines, *t.diskImagePipeline(&pt, t.Filename()))
+		return pipelines, nil
+	}
+
+	pipelines = append(pipelines, *t.diskImagePipeline(&pt, diskfile))
+
+	format := osbuild.QEMUFormat{Type: formatExports[t.format]}
+	switch t.format {
+	case "qcow2":
+		format.Compat = "1.1"
+	case "vmdk":
+		format.Subformat = "streamOptimized"
+	case "vhd":
+		forceSize := true
+		format.Subformat = "fixed"
+		format.ForceSize = &forceSize
+	}
+	qemuPipeline := osbuild.Pipeline{
+		Name:  formatExports[t.format],
+		Build: "name:build",
+	}
+	qemuPipeline.AddStage(osbuild.NewQEMUStage(&osbuild.QEMUStageOptions{
+		Filename: t.Filename(),
+		Format:   format,
+	}, osbuild.NewQEMUStageInputs("image", diskfile)))
+	pipelines = append(pipelines, qemuPipeline)
+
+	return pipelines, nil
+}
+
+func (t *imageType) buildPipeline(repos []rpmmd.RepoConfig, buildPackageSpecs []rpmmd.PackageSpec) *osbuild.Pipeline {
+	p := new(osbuild.Pipeline)
+	p.Name = "build"
+	p.Runner = t.arch.distro.runner
+	p.AddStage(osbuild.NewRPMStage(rpmStageOptions(repos), rpmStageInputs(buildPackageSpecs)))
+	p.AddStage(osbuild.NewSELinuxStage(selinuxStageOptions()))
+	return p
+}
+
+// osPipeline returns the pipeline which installs and configures the tree.
+// `imageStages` configure the boot loader of bootable images and run before
+// the tree is labelled for SELinux.
+func (t *imageType) osPipeline(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, c *blueprint.Customizations, options distro.ImageOptions, imageStages []*osbuild.Stage) (*osbuild.Pipeline, error) {
+	p := new(osbuild.Pipeline)
+	p.Name = "os"
+	p.Build = "name:build"
+
+	p.AddStage(osbuild.NewRPMStage(rpmStageOptions(repos), rpmStageInputs(packages)))
+	if t.bootable() {
+		p.AddStage(osbuild.NewFixBLSStage())
+	}
+
+	language, keyboard := c.GetPrimaryLocale()
+	if language != nil {
+		p.AddStage(osbuild.NewLocaleStage(&osbuild.LocaleStageOptions{Language: *language}))
+	} else {
+		p.AddStage(osbuild.NewLocaleStage(&osbuild.LocaleStageOptions{Language: "en_US.UTF-8"}))
+	}
+	if keyboard != nil {
+		p.AddStage(osbuild.NewKeymapStage(&osbuild.KeymapStageOptions{Keymap: *keyboard}))
+	}
+	if hostname := c.GetHostname(); hostname != nil {
+		p.AddStage(osbuild.NewHostnameStage(&osbuild.HostnameStageOptions{Hostname: *hostname}))
+	}
+
+	timezone, ntpServers := c.GetTimezoneSettings()
+	if timezone != nil {
+		p.AddStage(osbuild.NewTimezoneStage(&osbuild.TimezoneStageOptions{Zone: *timezone}))
+	} else {
+		p.AddStage(osbuild.NewTimezoneStage(&osbuild.TimezoneStageOptions{Zone: "UTC"}))
+	}
+	if len(ntpServers) > 0 {
+		p.AddStage(osbuild.NewChronyStage(&osbuild.ChronyStageOptions{Timeservers: ntpServers}))
+	}
+
+	if groups := c.GetGroups(); len(groups) > 0 {
+		p.AddStage(osbuild.NewGroupsStage(groupStageOptions(groups)))
+	}
+
+	if users := c.GetUsers(); len(users) > 0 {
+		options, err := userStageOptions(users)
+		if err != nil {
+			return nil, err
+		}
+		p.AddStage(osbuild.NewUsersStage(options))
+	}
+
+	// units need to exist before they can be enabled
+	p.Stages = append(p.Stages, fsnode.Stages(fsnode.UnitFiles(c.GetServices()), nil)...)
+
+	if services := c.GetServices(); services != nil || t.enabledServices != nil || t.disabledServices != nil || t.defaultTarget != "" {
+		p.AddStage(osbuild.NewSystemdStage(systemdStageOptions(t.enabledServices, t.disabledServices, services, t.defaultTarget)))
+	}
+
+	if firewall := c.GetFirewall(); firewall != nil {
+		p.AddStage(osbuild.NewFirewallStage(firewallStageOptions(firewall)))
+	}
+
+	p.Stages = append(p.Stages, fsnode.Stages(fsnode.Files(c, options.ModuleStreams), c.GetDirectories())...)
+	p.Stages = append(p.Stages, imageStages...)
+	p.AddStage(osbuild.NewSELinuxStage(selinuxStageOptions()))
+
+	return p, nil
+}
+
+func tarStage(source, filename string) *osbuild.Stage {
+	tree := new(osbuild.TarStageInput)
+	tree.Type = "org.osbuild.tree"
+	tree.Origin = "org.osbuild.pipeline"
+	tree.References = []string{fmt.Sprintf("name:%s", source)}
+	return osbuild.NewTarStage(&osbuild.TarStageOptions{Filename: filename}, &osbuild.TarStageInputs{Tree: tree})
+}
+
+// diskImagePipeline returns the pipeline which creates the raw image
+// `filename` with the partition table `pt` from the tree of the os pipeline,
+// and installs the legacy boot loader if the architecture boots from BIOS.
+func (t *imageType) diskImagePipeline(pt *disk.PartitionTable, filename string) *osbuild.Pipeline {
+	p := new(osbuild.Pipeline)
+	p.Name = "image"
+	p.Build = "name:build"
+	p.Stages = pt.ImageStages(filename, "os")
+	if legacy := t.arch.biosPlatform; legacy != "" {
+		if options := pt.Grub2InstStageOptions(filename, legacy); options != nil {
+			p.AddStage(osbuild.NewGrub2InstStage(options))
+		}
+	}
+	return p
+}
+
+// partitionTable returns the gpt partition table of bootable images, which
+// has a BIOS boot partition if the architecture boots from BIOS, an EFI
+// system partition if it boots with UEFI, and an xfs root filesystem in the
+// remaining space.
+func (t *imageType) partitionTable(options distro.ImageOptions, rng *rand.Rand) disk.PartitionTable {
+	var partitions []disk.Partition
+	start := uint64(2048)
+
+	if t.arch.biosPlatform != "" {
+		partitions = append(partitions, disk.Partition{
+			Bootable: true,
+			Start:    start,
+			Size:     2048,
+			Type:     "21686148-6449-6E6F-744E-656564454649",
+			UUID:     "FAC7F1FB-3E8D-4137-A512-961DE09A5549",
+		})
+		start += 2048
+	}
+
+	if t.arch.uefi {
+		partitions = append(partitions, disk.Partition{
+			Start: start,
+			Size:  204800,
+			Type:  "C12A7328-F81F-11D2-BA4B-00A0C93EC93B",
+			UUID:  "68B2905B-DF3E-4FB3-80FA-49D1E773AA33",
+			Filesystem: &disk.Filesystem{
+				Type:         "vfat",
+				UUID:         "7B77-95E7",
+				Mountpoint:   "/boot/efi",
+				FSTabOptions: "defaults,uid=0,gid=0,umask=077,shortname=winnt",
+				FSTabFreq:    0,
+				FSTabPassNo:  2,
+			},
+		})
+		start += 204800
+	}
+
+	partitions = append(partitions, disk.Partition{
+		Start: start,
+		Type:  "0FC63DAF-8483-4772-8E79-3D69D8477DE4",
+		UUID:  "6264D520-3FB9-423F-8AB8-7A0A8E3D3562",
+		Filesystem: &disk.Filesystem{
+			Type:         "xfs",
+			UUID:         uuid.Must(newRandomUUIDFromReader(rng)).String(),
+			Label:        "root",
+			Mountpoint:   "/",
+			FSTabOptions: "defaults",
+			FSTabFreq:    0,
+			FSTabPassNo:  0,
+		},
+	})
+
+	return disk.PartitionTable{
+		Size:       options.Size,
+		UUID:       "D209C89E-EA5E-4FBD-B161-B461CCE297E0",
+		Type:       "gpt",
+		Partitions: partitions,
+	}
+}
+
+func newRandomUUIDFromReader(r io.Reader) (uuid.UUID, error) {
+	var id uuid.UUID
+	_, err := io.ReadFull(r, id[:])
+	if err != nil {
+		return uuid.Nil, err
+	}
+	id[6] = (id[6] & 0x0f) | 0x40 // Version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // Variant is 10
+	return id, nil
+}
@@ -0,0 +1,174 @@
+package generic
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/crypt"
+	"github.com/osbuild/osbuild-composer/internal/disk"
+	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild2"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+func rpmStageOptions(repos []rpmmd.RepoConfig) *osbuild.RPMStageOptions {
+	var gpgKeys []string
+	for _, repo := range repos {
+		if repo.GPGKey == "" {
+			continue
+		}
+		gpgKeys = append(gpgKeys, repo.GPGKey)
+	}
+
+	return &osbuild.RPMStageOptions{
+		GPGKeys: gpgKeys,
+		Exclude: &osbuild.Exclude{
+			// NOTE: Make configurable?
+			Docs: true,
+		},
+	}
+}
+
+func selinuxStageOptions() *osbuild.SELinuxStageOptions {
+	return &osbuild.SELinuxStageOptions{
+		FileContexts: "etc/selinux/targeted/contexts/files/file_contexts",
+	}
+}
+
+func userStageOptions(users []blueprint.UserCustomization) (*osbuild.UsersStageOptions, error) {
+	options := osbuild.UsersStageOptions{
+		Users: make(map[string]osbuild.UsersStageOptionsUser),
+	}
+
+	for _, c := range users {
+		if c.Password != nil && !crypt.PasswordIsCrypted(*c.Password) {
+			cryptedPassword, err := crypt.CryptSHA512(*c.Password)
+			if err != nil {
+				return nil, err
+			}
+
+			c.Password = &cryptedPassword
+		}
+
+		// a "!" in front of the hash disables password logins
+		if c.Locked {
+			lockedPassword := "!"
+			if c.Password != nil {
+				lockedPassword += *c.Password
+			}
+			c.Password = &lockedPassword
+		}
+
+		user := osbuild.UsersStageOptionsUser{
+			Groups:      c.Groups,
+			Description: c.Description,
+			Home:        c.Home,
+			Shell:       c.Shell,
+			Password:    c.Password,
+			Key:         c.AuthorizedKeys(),
+			ExpireDate:  c.ExpireDate,
+		}
+
+		user.UID = c.UID
+		user.GID = c.GID
+		if c.ForcePasswordReset {
+			forcePasswordReset := true
+			user.ForcePasswordReset = &forcePasswordReset
+		}
+
+		options.Users[c.Name] = user
+	}
+
+	return &options, nil
+}
+
+func groupStageOptions(groups []blueprint.GroupCustomization) *osbuild.GroupsStageOptions {
+	options := osbuild.GroupsStageOptions{
+		Groups: map[string]osbuild.GroupsStageOptionsGroup{},
+	}
+
+	for _, group := range groups {
+		groupData := osbuild.GroupsStageOptionsGroup{
+			Name: group.Name,
+		}
+		groupData.GID = group.GID
+
+		options.Groups[group.Name] = groupData
+	}
+
+	return &options
+}
+
+func firewallStageOptions(firewall *blueprint.FirewallCustomization) *osbuild.FirewallStageOptions {
+	options := osbuild.FirewallStageOptions{
+		Ports: firewall.Ports,
+	}
+
+	if firewall.Services != nil {
+		options.EnabledServices = firewall.Services.Enabled
+		options.DisabledServices = firewall.Services.Disabled
+	}
+
+	for _, zone := range firewall.Zones {
+		options.Zones = append(options.Zones, osbuild.FirewallZone{
+			Name:    zone.Name,
+			Sources: zone.Sources,
+		})
+	}
+
+	return &options
+}
+
+func systemdStageOptions(enabledServices, disabledServices []string, s *blueprint.ServicesCustomization, target string) *osbuild.SystemdStageOptions {
+	var maskedServices []string
+	if s != nil {
+		enabledServices = append(enabledServices, s.Enabled...)
+		disabledServices = append(disabledServices, s.Disabled...)
+		maskedServices = s.Masked
+	}
+	return &osbuild.SystemdStageOptions{
+		EnabledServices:  enabledServices,
+		DisabledServices: disabledServices,
+		MaskedServices:   maskedServices,
+		DefaultTarget:    target,
+	}
+}
+
+func (t *imageType) grub2StageOptions(pt *disk.PartitionTable, kernel *blueprint.KernelCustomization, packages []rpmmd.PackageSpec) *osbuild.GRUB2StageOptions {
+	rootFs := pt.RootFilesystem()
+	if rootFs == nil {
+		panic("root filesystem must be defined for grub2 stage, this is a programming error")
+	}
+
+	stageOptions := osbuild.GRUB2StageOptions{
+		RootFilesystemUUID: uuid.MustParse(rootFs.UUID),
+		KernelOptions:      t.kernelOptions,
+		Legacy:             t.arch.biosPlatform,
+	}
+
+	for _, fs := range pt.Filesystems() {
+		if fs.Mountpoint == "/boot" {
+			bootFsUUID := uuid.MustParse(fs.UUID)
+			stageOptions.BootFilesystemUUID = &bootFsUUID
+		}
+	}
+
+	if t.arch.uefi {
+		stageOptions.UEFI = &osbuild.GRUB2UEFI{
+			Vendor: t.arch.distro.uefiVendor,
+		}
+	}
+
+	if kernel != nil {
+		if kernel.Append != "" {
+			stageOptions.KernelOptions += " " + kernel.Append
+		}
+		for _, pkg := range packages {
+			if pkg.Name == kernel.Name {
+				stageOptions.SavedEntry = "ffffffffffffffffffffffffffffffff-" + pkg.Version + "-" + pkg.Release + "." + pkg.Arch
+				break
+			}
+		}
+	}
+
+	return &stageOptions
+}
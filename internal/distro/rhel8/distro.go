@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/fsnode"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
 
 	"github.com/google/uuid"
@@ -219,8 +220,9 @@ func (t *imageType) PackageSets(bp blueprint.Blueprint) map[string]rpmmd.Package
 	includePackages, excludePackages := t.Packages(bp)
 	return map[string]rpmmd.PackageSet{
 		"packages": {
-			Include: includePackages,
-			Exclude: excludePackages,
+			Include:       includePackages,
+			Exclude:       excludePackages,
+			EnableModules: bp.GetEnabledModules(),
 		},
 		"build-packages": {
 			Include: t.BuildPackages(),
@@ -288,13 +290,33 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		return nil, fmt.Errorf("kernel boot parameter customizations are not supported for ostree types")
 	}
 
+	if len(c.GetFilesystems()) > 0 {
+		return nil, fmt.Errorf("filesystem customizations are not supported for image type %s", t.name)
+	}
+
+	if c.GetDisk() != nil {
+		return nil, fmt.Errorf("disk customizations are not supported for image type %s", t.name)
+	}
+
+	if c.GetFDO() != nil || c.GetIgnition() != nil {
+		return nil, fmt.Errorf("FDO and Ignition customizations are not supported for image type %s", t.name)
+	}
+
+	if c.GetInstallationDevice() != "" {
+		return nil, fmt.Errorf("installation device customizations are not supported for image type %s", t.name)
+	}
+
+	if err := fsnode.Check(append(fsnode.UnitFiles(c.GetServices()), fsnode.Files(c, options.ModuleStreams)...), c.GetDirectories()); err != nil {
+		return nil, err
+	}
+
 	p := &osbuild.Pipeline{}
 	p.SetBuild(t.buildPipeline(repos, *t.arch, buildPackageSpecs), "org.osbuild.rhel82")
 
 	if t.arch.Name() == "s390x" {
 		p.AddStage(osbuild.NewKernelCmdlineStage(&osbuild.KernelCmdlineStageOptions{
 			RootFsUUID: "0bd700f8-090f-4556-b797-b340297ea1bd",
-			KernelOpts: "net.ifnames=0 crashkernel=auto",
+			KernelOpts: kernelCmdline("net.ifnames=0 crashkernel=auto", c.GetKernel()),
 		}))
 	}
 
@@ -347,6 +369,11 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		p.AddStage(osbuild.NewUsersStage(options))
 	}
 
+	// units need to exist before they can be enabled
+	if stage := fsnode.ScriptStage(fsnode.UnitFiles(c.GetServices()), nil); stage != nil {
+		p.AddStage(stage)
+	}
+
 	if services := c.GetServices(); services != nil || t.enabledServices != nil || t.disabledServices != nil || t.defaultTarget != "" {
 		p.AddStage(osbuild.NewSystemdStage(t.systemdStageOptions(t.enabledServices, t.disabledServices, services, t.defaultTarget)))
 	}
@@ -355,6 +382,10 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		p.AddStage(osbuild.NewFirewallStage(t.firewallStageOptions(firewall)))
 	}
 
+	if stage := fsnode.ScriptStage(fsnode.Files(c, options.ModuleStreams), c.GetDirectories()); stage != nil {
+		p.AddStage(stage)
+	}
+
 	if t.arch.Name() == "s390x" {
 		p.AddStage(osbuild.NewZiplStage(&osbuild.ZiplStageOptions{}))
 	}
@@ -450,17 +481,31 @@ func (t *imageType) userStageOptions(users []blueprint.UserCustomization) (*osbu
 			c.Password = &cryptedPassword
 		}
 
+		// a "!" in front of the hash disables password logins
+		if c.Locked {
+			lockedPassword := "!"
+			if c.Password != nil {
+				lockedPassword += *c.Password
+			}
+			c.Password = &lockedPassword
+		}
+
 		user := osbuild.UsersStageOptionsUser{
 			Groups:      c.Groups,
 			Description: c.Description,
 			Home:        c.Home,
 			Shell:       c.Shell,
 			Password:    c.Password,
-			Key:         c.Key,
+			Key:         c.AuthorizedKeys(),
+			ExpireDate:  c.ExpireDate,
 		}
 
 		user.UID = c.UID
 		user.GID = c.GID
+		if c.ForcePasswordReset {
+			forcePasswordReset := true
+			user.ForcePasswordReset = &forcePasswordReset
+		}
 
 		options.Users[c.Name] = user
 	}
@@ -495,17 +540,27 @@ func (t *imageType) firewallStageOptions(firewall *blueprint.FirewallCustomizati
 		options.DisabledServices = firewall.Services.Disabled
 	}
 
+	for _, zone := range firewall.Zones {
+		options.Zones = append(options.Zones, osbuild.FirewallZone{
+			Name:    zone.Name,
+			Sources: zone.Sources,
+		})
+	}
+
 	return &options
 }
 
 func (t *imageType) systemdStageOptions(enabledServices, disabledServices []string, s *blueprint.ServicesCustomization, target string) *osbuild.SystemdStageOptions {
+	var maskedServices []string
 	if s != nil {
 		enabledServices = append(enabledServices, s.Enabled...)
 		disabledServices = append(disabledServices, s.Disabled...)
+		maskedServices = s.Masked
 	}
 	return &osbuild.SystemdStageOptions{
 		EnabledServices:  enabledServices,
 		DisabledServices: disabledServices,
+		MaskedServices:   maskedServices,
 		DefaultTarget:    target,
 	}
 }
@@ -519,6 +574,15 @@ func (t *imageType) fsTabStageOptions(uefi bool) *osbuild.FSTabStageOptions {
 	return &options
 }
 
+// Returns the kernel command line of an image: `kernelOptions` followed by
+// the arguments of the kernel customization.
+func kernelCmdline(kernelOptions string, kernel *blueprint.KernelCustomization) string {
+	if kernel != nil && kernel.Append != "" {
+		kernelOptions += " " + kernel.Append
+	}
+	return kernelOptions
+}
+
 func (t *imageType) grub2StageOptions(kernelOptions string, kernel *blueprint.KernelCustomization, uefi bool) *osbuild.GRUB2StageOptions {
 	id := uuid.MustParse("0bd700f8-090f-4556-b797-b340297ea1bd")
 
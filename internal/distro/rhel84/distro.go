@@ -10,6 +10,7 @@ import (
 
 	"github.com/osbuild/osbuild-composer/internal/disk"
 	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/fsnode"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
 
 	"github.com/google/uuid"
@@ -246,8 +247,9 @@ func (t *imageType) PackageSets(bp blueprint.Blueprint) map[string]rpmmd.Package
 	includePackages, excludePackages := t.Packages(bp)
 	return map[string]rpmmd.PackageSet{
 		"packages": {
-			Include: includePackages,
-			Exclude: excludePackages,
+			Include:       includePackages,
+			Exclude:       excludePackages,
+			EnableModules: bp.GetEnabledModules(),
 		},
 		"build-packages": {
 			Include: t.BuildPackages(),
@@ -264,6 +266,9 @@ func (t *imageType) Manifest(c *blueprint.Customizations,
 	repos []rpmmd.RepoConfig,
 	packageSpecSets map[string][]rpmmd.PackageSpec,
 	seed int64) (distro.Manifest, error) {
+	if t.name == "wsl" {
+		c = distro.WSLCustomizations(c)
+	}
 	source := rand.NewSource(seed)
 	rng := rand.New(source)
 	pipeline, err := t.pipeline(c, options, repos, packageSpecSets["packages"], packageSpecSets["build-packages"], rng)
@@ -317,9 +322,39 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		return nil, fmt.Errorf("kernel boot parameter customizations are not supported for ostree types")
 	}
 
+	mountpoints := c.GetFilesystems()
+	if len(mountpoints) > 0 {
+		if t.partitionTableGenerator == nil || t.rpmOstree {
+			return nil, fmt.Errorf("filesystem customizations are not supported for image type %s", t.name)
+		}
+		if err := disk.CheckMountpoints(mountpoints, disk.MountpointAllowList); err != nil {
+			return nil, err
+		}
+	}
+
+	// the qemu assembler can neither create LVM volume groups nor LUKS2 volumes
+	if c.GetDisk() != nil {
+		return nil, fmt.Errorf("disk customizations are not supported for image type %s", t.name)
+	}
+
+	if c.GetFDO() != nil || c.GetIgnition() != nil {
+		return nil, fmt.Errorf("FDO and Ignition customizations are not supported for image type %s", t.name)
+	}
+
+	if c.GetInstallationDevice() != "" {
+		return nil, fmt.Errorf("installation device customizations are not supported for image type %s", t.name)
+	}
+
+	if err := fsnode.Check(append(fsnode.UnitFiles(c.GetServices()), fsnode.Files(c, options.ModuleStreams)...), c.GetDirectories()); err != nil {
+		return nil, err
+	}
+
 	var pt *disk.PartitionTable
 	if t.partitionTableGenerator != nil {
-		table := t.partitionTableGenerator(options, t.arch, rng)
+		table, err := disk.CreatePartitionTable(mountpoints, options.Size, t.partitionTableGenerator(options, t.arch, rng), rng)
+		if err != nil {
+			return nil, err
+		}
 		pt = &table
 	}
 
@@ -342,7 +377,7 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 
 		p.AddStage(osbuild.NewKernelCmdlineStage(&osbuild.KernelCmdlineStageOptions{
 			RootFsUUID: rootPartition.Filesystem.UUID,
-			KernelOpts: t.kernelOptions,
+			KernelOpts: kernelCmdline(t.kernelOptions, c.GetKernel()),
 		}))
 	}
 
@@ -400,6 +435,11 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		p.AddStage(osbuild.NewUsersStage(options))
 	}
 
+	// units need to exist before they can be enabled
+	if stage := fsnode.ScriptStage(fsnode.UnitFiles(c.GetServices()), nil); stage != nil {
+		p.AddStage(stage)
+	}
+
 	if services := c.GetServices(); services != nil || t.enabledServices != nil || t.disabledServices != nil || t.defaultTarget != "" {
 		p.AddStage(osbuild.NewSystemdStage(t.systemdStageOptions(t.enabledServices, t.disabledServices, services, t.defaultTarget)))
 	}
@@ -408,6 +448,10 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		p.AddStage(osbuild.NewFirewallStage(t.firewallStageOptions(firewall)))
 	}
 
+	if stage := fsnode.ScriptStage(fsnode.Files(c, options.ModuleStreams), c.GetDirectories()); stage != nil {
+		p.AddStage(stage)
+	}
+
 	if t.arch.Name() == "s390x" {
 		p.AddStage(osbuild.NewZiplStage(&osbuild.ZiplStageOptions{}))
 	}
@@ -418,7 +462,7 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 	p.AddStage(osbuild.NewSysconfigStage(&osbuild.SysconfigStageOptions{
 		Kernel: osbuild.SysconfigKernelOptions{
 			UpdateDefault: true,
-			DefaultKernel: "kernel",
+			DefaultKernel: c.GetKernel().Name,
 		},
 		Network: osbuild.SysconfigNetworkOptions{
 			Networking: true,
@@ -515,17 +559,31 @@ func (t *imageType) userStageOptions(users []blueprint.UserCustomization) (*osbu
 			c.Password = &cryptedPassword
 		}
 
+		// a "!" in front of the hash disables password logins
+		if c.Locked {
+			lockedPassword := "!"
+			if c.Password != nil {
+				lockedPassword += *c.Password
+			}
+			c.Password = &lockedPassword
+		}
+
 		user := osbuild.UsersStageOptionsUser{
 			Groups:      c.Groups,
 			Description: c.Description,
 			Home:        c.Home,
 			Shell:       c.Shell,
 			Password:    c.Password,
-			Key:         c.Key,
+			Key:         c.AuthorizedKeys(),
+			ExpireDate:  c.ExpireDate,
 		}
 
 		user.UID = c.UID
 		user.GID = c.GID
+		if c.ForcePasswordReset {
+			forcePasswordReset := true
+			user.ForcePasswordReset = &forcePasswordReset
+		}
 
 		options.Users[c.Name] = user
 	}
@@ -560,21 +618,40 @@ func (t *imageType) firewallStageOptions(firewall *blueprint.FirewallCustomizati
 		options.DisabledServices = firewall.Services.Disabled
 	}
 
+	for _, zone := range firewall.Zones {
+		options.Zones = append(options.Zones, osbuild.FirewallZone{
+			Name:    zone.Name,
+			Sources: zone.Sources,
+		})
+	}
+
 	return &options
 }
 
 func (t *imageType) systemdStageOptions(enabledServices, disabledServices []string, s *blueprint.ServicesCustomization, target string) *osbuild.SystemdStageOptions {
+	var maskedServices []string
 	if s != nil {
 		enabledServices = append(enabledServices, s.Enabled...)
 		disabledServices = append(disabledServices, s.Disabled...)
+		maskedServices = s.Masked
 	}
 	return &osbuild.SystemdStageOptions{
 		EnabledServices:  enabledServices,
 		DisabledServices: disabledServices,
+		MaskedServices:   maskedServices,
 		DefaultTarget:    target,
 	}
 }
 
+// Returns the kernel command line of an image: `kernelOptions` followed by
+// the arguments of the kernel customization.
+func kernelCmdline(kernelOptions string, kernel *blueprint.KernelCustomization) string {
+	if kernel != nil && kernel.Append != "" {
+		kernelOptions += " " + kernel.Append
+	}
+	return kernelOptions
+}
+
 func (t *imageType) grub2StageOptions(pt *disk.PartitionTable, kernelOptions string, kernel *blueprint.KernelCustomization, packages []rpmmd.PackageSpec, uefi bool, legacy string) *osbuild.GRUB2StageOptions {
 	if pt == nil {
 		panic("partition table must be defined for grub2 stage, this is a programming error")
@@ -1162,6 +1239,25 @@ func newDistro(name, modulePlatformID, ostreeRef string, isCentos bool) distro.D
 		},
 	}
 
+	wslImgType := imageType{
+		name:     "wsl",
+		filename: "rootfs.tar.gz",
+		mimeType: "application/x-tar",
+		packages: []string{
+			"@core",
+			"policycoreutils",
+			"selinux-policy-targeted",
+		},
+		excludedPackages: []string{
+			"dracut-config-rescue",
+			"rng-tools",
+		},
+		bootable: false,
+		assembler: func(pt *disk.PartitionTable, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return tarAssembler("rootfs.tar.gz", "gzip")
+		},
+	}
+
 	vhdImgType := imageType{
 		name:     "vhd",
 		filename: "disk.vhd",
@@ -1529,6 +1625,7 @@ func newDistro(name, modulePlatformID, ostreeRef string, isCentos bool) distro.D
 		tarImgType,
 		vhdImgType,
 		vmdkImgType,
+		wslImgType,
 	)
 
 	if !isCentos {
@@ -1,6 +1,8 @@
 package rhel84_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -76,6 +78,12 @@ func TestFilenameFromType(t *testing.T) {
 			want:  "disk.vmdk",
 			want1: "application/x-vmdk",
 		},
+		{
+			name:  "wsl",
+			args:  args{"wsl"},
+			want:  "rootfs.tar.gz",
+			want1: "application/x-tar",
+		},
 		{
 			name:    "invalid-output-type",
 			args:    args{"foobar"},
@@ -452,6 +460,242 @@ func TestDistro_ManifestError(t *testing.T) {
 	}
 }
 
+// Check that filesystem customizations are applied to image types with a
+// partition table and rejected for all others.
+func TestDistro_FilesystemCustomizations(t *testing.T) {
+	r8distro := rhel84.New()
+	bp := blueprint.Blueprint{
+		Customizations: &blueprint.Customizations{
+			Filesystem: []blueprint.FilesystemCustomization{
+				{Mountpoint: "/", Size: 2 * 1024 * 1024 * 1024},
+				{Mountpoint: "/var/log", Size: 1024 * 1024 * 1024, Type: "ext4"},
+				{Mountpoint: "/home", Size: 1024 * 1024 * 1024, Grow: true},
+			},
+		},
+	}
+
+	for _, archName := range r8distro.ListArches() {
+		arch, _ := r8distro.GetArch(archName)
+		for _, imgTypeName := range arch.ListImageTypes() {
+			if archName == "s390x" && imgTypeName == "tar" {
+				continue
+			}
+			imgType, _ := arch.GetImageType(imgTypeName)
+			imgOpts := distro.ImageOptions{
+				Size: imgType.Size(0),
+			}
+			manifest, err := imgType.Manifest(bp.Customizations, imgOpts, nil, nil, 0)
+			switch imgTypeName {
+			case "rhel-edge-commit", "rhel-edge-container", "rhel-edge-installer", "tar", "wsl":
+				assert.Error(t, err, "%s/%s", archName, imgTypeName)
+			default:
+				require.NoError(t, err, "%s/%s", archName, imgTypeName)
+				assert.Contains(t, string(manifest), `"mountpoint":"/var/log"`)
+				assert.Contains(t, string(manifest), `"mountpoint":"/home"`)
+			}
+		}
+	}
+
+	qcow2, err := r8distro.GetArch("x86_64")
+	require.NoError(t, err)
+	imgType, err := qcow2.GetImageType("qcow2")
+	require.NoError(t, err)
+	_, err = imgType.Manifest(&blueprint.Customizations{
+		Filesystem: []blueprint.FilesystemCustomization{{Mountpoint: "/etc", Size: 1024}},
+	}, distro.ImageOptions{Size: imgType.Size(0)}, nil, nil, 0)
+	assert.Error(t, err)
+}
+
+func TestDistro_FirewallCustomizations(t *testing.T) {
+	r8distro := rhel84.New()
+	c := &blueprint.Customizations{
+		Firewall: &blueprint.FirewallCustomization{
+			Ports: []string{"8080:tcp"},
+			Zones: []blueprint.FirewallZoneCustomization{{Name: "trusted", Sources: []string{"10.0.0.0/8"}}},
+		},
+	}
+
+	for _, archName := range r8distro.ListArches() {
+		arch, _ := r8distro.GetArch(archName)
+		for _, imgTypeName := range arch.ListImageTypes() {
+			// the installer doesn't support customizations and s390x has no tar images
+			if imgTypeName == "rhel-edge-installer" || (archName == "s390x" && imgTypeName == "tar") {
+				continue
+			}
+			imgType, _ := arch.GetImageType(imgTypeName)
+			manifest, err := imgType.Manifest(c, distro.ImageOptions{Size: imgType.Size(0)}, nil, nil, 0)
+			require.NoError(t, err, "%s/%s", archName, imgTypeName)
+			assert.Contains(t, string(manifest), `"zones":[{"name":"trusted","sources":["10.0.0.0/8"]}]`, "%s/%s", archName, imgTypeName)
+		}
+	}
+}
+
+func TestDistro_FileCustomizations(t *testing.T) {
+	r8distro := rhel84.New()
+	c := &blueprint.Customizations{
+		Files:       []blueprint.FileCustomization{{Path: "/etc/foo/foo.conf", Data: "foo=1\n", Mode: "0600"}},
+		Directories: []blueprint.DirectoryCustomization{{Path: "/etc/foo"}},
+	}
+
+	arch, err := r8distro.GetArch("x86_64")
+	require.NoError(t, err)
+
+	// osbuild1 manifests create them with a script, osbuild2 ones embed them
+	qcow2, err := arch.GetImageType("qcow2")
+	require.NoError(t, err)
+	manifest, err := qcow2.Manifest(c, distro.ImageOptions{Size: qcow2.Size(0)}, nil, nil, 0)
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), `"name":"org.osbuild.script"`)
+	assert.Contains(t, string(manifest), `Zm9vPTEK | base64 -d \u003e '/etc/foo/foo.conf'`)
+
+	container, err := arch.GetImageType("rhel-edge-container")
+	require.NoError(t, err)
+	manifest, err = container.Manifest(c, distro.ImageOptions{}, nil, nil, 0)
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), `"type":"org.osbuild.mkdir"`)
+	assert.Contains(t, string(manifest), `"type":"org.osbuild.copy"`)
+	assert.Contains(t, string(manifest), `"org.osbuild.inline":{"items":{"sha256:`)
+
+	c.Files[0].Path = "/usr/bin/foo"
+	for _, imgType := range []distro.ImageType{qcow2, container} {
+		_, err = imgType.Manifest(c, distro.ImageOptions{Size: imgType.Size(0)}, nil, nil, 0)
+		assert.Error(t, err, imgType.Name())
+	}
+}
+
+func TestDistro_ServicesCustomizations(t *testing.T) {
+	r8distro := rhel84.New()
+	c := &blueprint.Customizations{
+		Services: &blueprint.ServicesCustomization{
+			Enabled: []string{"firstboot.timer"},
+			Masked:  []string{"kdump.service"},
+			Units:   []blueprint.SystemdUnitCustomization{{Name: "firstboot.timer", Data: "[Timer]\nOnBootSec=1min\n"}},
+		},
+	}
+
+	arch, err := r8distro.GetArch("x86_64")
+	require.NoError(t, err)
+
+	for _, name := range []string{"qcow2", "rhel-edge-container"} {
+		imgType, err := arch.GetImageType(name)
+		require.NoError(t, err)
+		manifest, err := imgType.Manifest(c, distro.ImageOptions{Size: imgType.Size(0)}, nil, nil, 0)
+		require.NoError(t, err)
+
+		// the unit is installed before it is enabled
+		m := string(manifest)
+		assert.Contains(t, m, `"masked_services":["kdump.service"]`, name)
+		assert.Contains(t, m, "/etc/systemd/system/firstboot.timer", name)
+		assert.Less(t, strings.Index(m, "/etc/systemd/system/firstboot.timer"), strings.Index(m, `"org.osbuild.systemd"`), name)
+	}
+}
+
+func TestDistro_KernelCustomizations(t *testing.T) {
+	r8distro := rhel84.New()
+	c := &blueprint.Customizations{
+		Kernel: &blueprint.KernelCustomization{Name: "kernel-rt", Append: "nohz_full=1-3"},
+	}
+
+	for _, archName := range []string{"x86_64", "s390x"} {
+		arch, err := r8distro.GetArch(archName)
+		require.NoError(t, err)
+		imgType, err := arch.GetImageType("qcow2")
+		require.NoError(t, err)
+		manifest, err := imgType.Manifest(c, distro.ImageOptions{Size: imgType.Size(0)}, nil, nil, 0)
+		require.NoError(t, err)
+
+		// grub2 and zipl images both get the arguments
+		m := string(manifest)
+		assert.Contains(t, m, `"default_kernel":"kernel-rt"`, archName)
+		assert.Regexp(t, `"kernel_opts":"[^"]* nohz_full=1-3"`, m, archName)
+		if archName == "s390x" {
+			assert.Contains(t, m, `"name":"org.osbuild.kernel-cmdline"`, archName)
+			assert.NotContains(t, m, `"name":"org.osbuild.grub2"`, archName)
+		}
+	}
+}
+
+func TestDistro_UserCustomizations(t *testing.T) {
+	r8distro := rhel84.New()
+	key := "ssh-rsa AAAA user@host"
+	expireDate := 19000
+	c := &blueprint.Customizations{
+		User: []blueprint.UserCustomization{
+			{
+				Name:       "locked",
+				Key:        &key,
+				Keys:       []string{"ssh-ed25519 AAAA user@laptop"},
+				Groups:     []string{"wheel"},
+				ExpireDate: &expireDate,
+				Locked:     true,
+			},
+			{
+				Name:               "reset",
+				ForcePasswordReset: true,
+			},
+		},
+	}
+
+	arch, err := r8distro.GetArch("x86_64")
+	require.NoError(t, err)
+	imgType, err := arch.GetImageType("qcow2")
+	require.NoError(t, err)
+	manifest, err := imgType.Manifest(c, distro.ImageOptions{Size: imgType.Size(0)}, nil, nil, 0)
+	require.NoError(t, err)
+
+	m := string(manifest)
+	assert.Contains(t, m, `"locked":{"groups":["wheel"],"password":"!","key":"ssh-rsa AAAA user@host\nssh-ed25519 AAAA user@laptop","expiredate":19000}`)
+	assert.Contains(t, m, `"reset":{"force_password_reset":true}`)
+}
+
+func TestDistro_Subscription(t *testing.T) {
+	r8distro := rhel84.New()
+	options := distro.ImageOptions{
+		Subscription: &distro.SubscriptionImageOptions{
+			Organization:  2040324,
+			ActivationKey: "my-secret-key",
+			ServerUrl:     "subscription.rhsm.redhat.com",
+			BaseUrl:       "http://cdn.redhat.com/",
+			Insights:      true,
+		},
+	}
+
+	arch, err := r8distro.GetArch("x86_64")
+	require.NoError(t, err)
+
+	for _, name := range []string{"qcow2", "rhel-edge-container"} {
+		imgType, err := arch.GetImageType(name)
+		require.NoError(t, err)
+		options.Size = imgType.Size(0)
+		manifest, err := imgType.Manifest(nil, options, nil, nil, 0)
+		require.NoError(t, err)
+		assert.Contains(t, string(manifest), "/usr/sbin/subscription-manager register --org=2040324 --activationkey=my-secret-key", name)
+		assert.Contains(t, string(manifest), "/usr/bin/insights-client --register", name)
+	}
+
+	assert.NotContains(t, fmt.Sprintf("%v", options.Subscription), "my-secret-key")
+}
+
+// LVM and disk encryption cannot be set up by any image type yet
+func TestDistro_DiskCustomizations(t *testing.T) {
+	r8distro := rhel84.New()
+	c := &blueprint.Customizations{
+		Disk: &blueprint.DiskCustomization{
+			LVM:        true,
+			Encryption: &blueprint.EncryptionCustomization{Passphrase: "secret"},
+		},
+	}
+
+	for _, archName := range r8distro.ListArches() {
+		arch, _ := r8distro.GetArch(archName)
+		for _, imgTypeName := range arch.ListImageTypes() {
+			imgType, _ := arch.GetImageType(imgTypeName)
+			_, err := imgType.Manifest(c, distro.ImageOptions{Size: imgType.Size(0)}, nil, nil, 0)
+			assert.Error(t, err, "%s/%s", archName, imgTypeName)
+		}
+	}
+}
+
 func TestArchitecture_ListImageTypes(t *testing.T) {
 	imgMap := []struct {
 		arch                     string
@@ -467,6 +711,7 @@ func TestArchitecture_ListImageTypes(t *testing.T) {
 				"tar",
 				"vhd",
 				"vmdk",
+				"wsl",
 			},
 			rhelAdditionalImageTypes: []string{"rhel-edge-commit", "rhel-edge-container", "rhel-edge-installer"},
 		},
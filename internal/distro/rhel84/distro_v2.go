@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"math/rand"
 	"path/filepath"
+	"strings"
 
 	"github.com/osbuild/osbuild-composer/internal/crypt"
 	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/fsnode"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild2"
 
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
@@ -108,6 +110,7 @@ func (t *imageTypeS2) PackageSets(bp blueprint.Blueprint) map[string]rpmmd.Packa
 			// treat base packages separately to combine with blueprint
 			packages := new(rpmmd.PackageSet)
 			packages.Include, packages.Exclude = t.Packages(bp)
+			packages.EnableModules = bp.GetEnabledModules()
 			sets[name] = *packages
 			continue
 		}
@@ -147,7 +150,7 @@ func (t *imageTypeS2) Manifest(c *blueprint.Customizations,
 		osbuild.Manifest{
 			Version:   "2",
 			Pipelines: pipelines,
-			Sources:   t.sources(allPackageSpecs, commits),
+			Sources:   t.sources(allPackageSpecs, commits, append(fsnode.UnitFiles(c.GetServices()), fsnode.Files(c, options.ModuleStreams)...)),
 		},
 	)
 }
@@ -158,7 +161,7 @@ type ostreeCommit struct {
 	URL      string
 }
 
-func (t *imageTypeS2) sources(packages []rpmmd.PackageSpec, ostreeCommits []ostreeCommit) osbuild.Sources {
+func (t *imageTypeS2) sources(packages []rpmmd.PackageSpec, ostreeCommits []ostreeCommit, files []blueprint.FileCustomization) osbuild.Sources {
 	sources := osbuild.Sources{}
 	curl := &osbuild.CurlSource{
 		Items: make(map[string]osbuild.CurlSourceItem),
@@ -188,6 +191,10 @@ func (t *imageTypeS2) sources(packages []rpmmd.PackageSpec, ostreeCommits []ostr
 	if len(ostree.Items) > 0 {
 		sources["org.osbuild.ostree"] = ostree
 	}
+
+	if inline := fsnode.InlineSource(files); inline != nil {
+		sources["org.osbuild.inline"] = inline
+	}
 	return sources
 }
 
@@ -206,6 +213,26 @@ func (t *imageTypeS2) pipelines(customizations *blueprint.Customizations, option
 		return nil, fmt.Errorf("kernel boot parameter customizations are not supported for ostree types")
 	}
 
+	if len(customizations.GetFilesystems()) > 0 {
+		return nil, fmt.Errorf("filesystem customizations are not supported for image type %s", t.name)
+	}
+
+	if customizations.GetDisk() != nil {
+		return nil, fmt.Errorf("disk customizations are not supported for image type %s", t.name)
+	}
+
+	if customizations.GetFDO() != nil || customizations.GetIgnition() != nil {
+		return nil, fmt.Errorf("FDO and Ignition customizations are not supported for image type %s", t.name)
+	}
+
+	if customizations.GetInstallationDevice() != "" {
+		return nil, fmt.Errorf("installation device customizations are not supported for image type %s", t.name)
+	}
+
+	if err := fsnode.Check(append(fsnode.UnitFiles(customizations.GetServices()), fsnode.Files(customizations, options.ModuleStreams)...), customizations.GetDirectories()); err != nil {
+		return nil, err
+	}
+
 	pipelines := make([]osbuild.Pipeline, 0)
 
 	pipelines = append(pipelines, *t.buildPipeline(repos, packageSetSpecs["build-packages"]))
@@ -224,7 +251,7 @@ func (t *imageTypeS2) pipelines(customizations *blueprint.Customizations, option
 		pipelines = append(pipelines, *t.bootISOTreePipeline(kernelVer))
 		pipelines = append(pipelines, *t.bootISOPipeline())
 	} else {
-		treePipeline, err := t.ostreeTreePipeline(repos, packageSetSpecs["packages"], customizations)
+		treePipeline, err := t.ostreeTreePipeline(repos, packageSetSpecs["packages"], customizations, options)
 		if err != nil {
 			return nil, err
 		}
@@ -246,7 +273,7 @@ func (t *imageTypeS2) buildPipeline(repos []rpmmd.RepoConfig, buildPackageSpecs
 	return p
 }
 
-func (t *imageTypeS2) ostreeTreePipeline(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, c *blueprint.Customizations) (*osbuild.Pipeline, error) {
+func (t *imageTypeS2) ostreeTreePipeline(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, c *blueprint.Customizations, options distro.ImageOptions) (*osbuild.Pipeline, error) {
 	p := new(osbuild.Pipeline)
 	p.Name = "ostree-tree"
 	p.Build = "name:build"
@@ -288,6 +315,11 @@ func (t *imageTypeS2) ostreeTreePipeline(repos []rpmmd.RepoConfig, packages []rp
 		p.AddStage(osbuild.NewFirstBootStage(t.usersFirstBootOptions(options)))
 	}
 
+	// units need to exist before they can be enabled
+	for _, stage := range fsnode.Stages(fsnode.UnitFiles(c.GetServices()), nil) {
+		p.AddStage(stage)
+	}
+
 	if services := c.GetServices(); services != nil || t.enabledServices != nil || t.disabledServices != nil || t.defaultTarget != "" {
 		p.AddStage(osbuild.NewSystemdStage(t.systemdStageOptions(t.enabledServices, t.disabledServices, services, t.defaultTarget)))
 	}
@@ -296,6 +328,10 @@ func (t *imageTypeS2) ostreeTreePipeline(repos []rpmmd.RepoConfig, packages []rp
 		p.AddStage(osbuild.NewFirewallStage(t.firewallStageOptions(firewall)))
 	}
 
+	for _, stage := range fsnode.Stages(fsnode.Files(c, options.ModuleStreams), c.GetDirectories()) {
+		p.AddStage(stage)
+	}
+
 	if !t.bootISO {
 		p.AddStage(osbuild.NewSELinuxStage(t.selinuxStageOptions()))
 	}
@@ -304,7 +340,7 @@ func (t *imageTypeS2) ostreeTreePipeline(repos []rpmmd.RepoConfig, packages []rp
 	p.AddStage(osbuild.NewSysconfigStage(&osbuild.SysconfigStageOptions{
 		Kernel: osbuild.SysconfigKernelOptions{
 			UpdateDefault: true,
-			DefaultKernel: "kernel",
+			DefaultKernel: c.GetKernel().Name,
 		},
 		Network: osbuild.SysconfigNetworkOptions{
 			Networking: true,
@@ -312,6 +348,21 @@ func (t *imageTypeS2) ostreeTreePipeline(repos []rpmmd.RepoConfig, packages []rp
 		},
 	}))
 
+	if options.Subscription != nil {
+		commands := []string{
+			fmt.Sprintf("/usr/sbin/subscription-manager register --org=%d --activationkey=%s --serverurl %s --baseurl %s", options.Subscription.Organization, options.Subscription.ActivationKey, options.Subscription.ServerUrl, options.Subscription.BaseUrl),
+		}
+		if options.Subscription.Insights {
+			commands = append(commands, "/usr/bin/insights-client --register")
+		}
+
+		p.AddStage(osbuild.NewFirstBootStage(&osbuild.FirstBootStageOptions{
+			Commands:       commands,
+			WaitForNetwork: true,
+		},
+		))
+	}
+
 	p.AddStage(osbuild.NewOSTreePrepTreeStage(&osbuild.OSTreePrepTreeStageOptions{
 		EtcGroupMembers: []string{
 			// NOTE: We may want to make this configurable.
@@ -528,17 +579,31 @@ func (t *imageTypeS2) userStageOptions(users []blueprint.UserCustomization) (*os
 			c.Password = &cryptedPassword
 		}
 
+		// a "!" in front of the hash disables password logins
+		if c.Locked {
+			lockedPassword := "!"
+			if c.Password != nil {
+				lockedPassword += *c.Password
+			}
+			c.Password = &lockedPassword
+		}
+
 		user := osbuild.UsersStageOptionsUser{
 			Groups:      c.Groups,
 			Description: c.Description,
 			Home:        c.Home,
 			Shell:       c.Shell,
 			Password:    c.Password,
-			Key:         c.Key,
+			Key:         c.AuthorizedKeys(),
+			ExpireDate:  c.ExpireDate,
 		}
 
 		user.UID = c.UID
 		user.GID = c.GID
+		if c.ForcePasswordReset {
+			forcePasswordReset := true
+			user.ForcePasswordReset = &forcePasswordReset
+		}
 
 		options.Users[c.Name] = user
 	}
@@ -554,7 +619,9 @@ func (t *imageTypeS2) usersFirstBootOptions(usersStageOptions *osbuild.UsersStag
 		if user.Key != nil {
 			sshdir := filepath.Join(varhome, name, ".ssh")
 			cmds = append(cmds, fmt.Sprintf("mkdir -p %s", sshdir))
-			cmds = append(cmds, fmt.Sprintf("sh -c 'echo %q >> %q'", *user.Key, filepath.Join(sshdir, "authorized_keys")))
+			for _, key := range strings.Split(*user.Key, "\n") {
+				cmds = append(cmds, fmt.Sprintf("sh -c 'echo %q >> %q'", key, filepath.Join(sshdir, "authorized_keys")))
+			}
 			cmds = append(cmds, fmt.Sprintf("chown %s:%s -Rc %s", name, name, sshdir))
 		}
 	}
@@ -594,17 +661,27 @@ func (t *imageTypeS2) firewallStageOptions(firewall *blueprint.FirewallCustomiza
 		options.DisabledServices = firewall.Services.Disabled
 	}
 
+	for _, zone := range firewall.Zones {
+		options.Zones = append(options.Zones, osbuild.FirewallZone{
+			Name:    zone.Name,
+			Sources: zone.Sources,
+		})
+	}
+
 	return &options
 }
 
 func (t *imageTypeS2) systemdStageOptions(enabledServices, disabledServices []string, s *blueprint.ServicesCustomization, target string) *osbuild.SystemdStageOptions {
+	var maskedServices []string
 	if s != nil {
 		enabledServices = append(enabledServices, s.Enabled...)
 		disabledServices = append(disabledServices, s.Disabled...)
+		maskedServices = s.Masked
 	}
 	return &osbuild.SystemdStageOptions{
 		EnabledServices:  enabledServices,
 		DisabledServices: disabledServices,
+		MaskedServices:   maskedServices,
 		DefaultTarget:    target,
 	}
 }
@@ -4,11 +4,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
+	"reflect"
 	"sort"
+	"strings"
+
+	"github.com/google/uuid"
 
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/disk"
 	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/fsnode"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild2"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 )
@@ -65,6 +72,9 @@ func (d *distribution) addArches(arches ...architecture) {
 			distro:     d,
 			name:       a.name,
 			imageTypes: a.imageTypes,
+			legacy:     a.legacy,
+			uefi:       a.uefi,
+			extlinux:   a.extlinux,
 		}
 	}
 }
@@ -74,6 +84,11 @@ type architecture struct {
 	name        string
 	imageTypes  map[string]distro.ImageType
 	packageSets map[string]rpmmd.PackageSet
+	// legacy BIOS boot loader platform of bootable images, e.g. i386-pc
+	legacy string
+	uefi   bool
+	// bootable images are also configured for u-boot with extlinux.conf
+	extlinux bool
 }
 
 func (a *architecture) Name() string {
@@ -115,17 +130,19 @@ func (a *architecture) Distro() distro.Distro {
 type pipelinesFunc func(t *imageType, customizations *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSetSpecs map[string][]rpmmd.PackageSpec, rng *rand.Rand) ([]osbuild.Pipeline, error)
 
 type imageType struct {
-	arch             *architecture
-	name             string
-	filename         string
-	mimeType         string
-	packageSets      map[string]rpmmd.PackageSet
-	enabledServices  []string
-	disabledServices []string
-	defaultTarget    string
-	defaultSize      uint64
-	exports          []string
-	pipelines        pipelinesFunc
+	arch                    *architecture
+	name                    string
+	filename                string
+	mimeType                string
+	packageSets             map[string]rpmmd.PackageSet
+	enabledServices         []string
+	disabledServices        []string
+	defaultTarget           string
+	kernelOptions           string
+	defaultSize             uint64
+	exports                 []string
+	pipelines               pipelinesFunc
+	partitionTableGenerator func(imageOptions distro.ImageOptions, arch distro.Arch, rng *rand.Rand) disk.PartitionTable
 
 	// bootISO: installable ISO
 	bootISO bool
@@ -133,6 +150,14 @@ type imageType struct {
 	rpmOstree bool
 	// bootable image
 	bootable bool
+	// compression of the image file, e.g. xz, or empty if it's not
+	// compressed
+	compression string
+	// the image gets its content from the cloud provider's RHUI instead of
+	// a subscription
+	rhui bool
+	// the image is tuned for SAP HANA
+	sap bool
 }
 
 func (t *imageType) Name() string {
@@ -161,7 +186,7 @@ func (t *imageType) OSTreeRef() string {
 func (t *imageType) Size(size uint64) uint64 {
 	const MegaByte = 1024 * 1024
 	// Microsoft Azure requires vhd images to be rounded up to the nearest MB
-	if t.name == "vhd" && size%MegaByte != 0 {
+	if (t.name == "vhd" || strings.HasPrefix(t.name, "azure-")) && size%MegaByte != 0 {
 		size = (size/MegaByte + 1) * MegaByte
 	}
 	if size == 0 {
@@ -208,7 +233,7 @@ func (t *imageType) PackageSets(bp blueprint.Blueprint) map[string]rpmmd.Package
 	if timezone != nil {
 		bpPackages = append(bpPackages, "chrony")
 	}
-	mergedSets["packages"] = mergedSets["packages"].Append(rpmmd.PackageSet{Include: bpPackages})
+	mergedSets["packages"] = mergedSets["packages"].Append(rpmmd.PackageSet{Include: bpPackages, EnableModules: bp.GetEnabledModules()})
 	return mergedSets
 
 }
@@ -232,6 +257,22 @@ func (t *imageType) Manifest(customizations *blueprint.Customizations,
 	packageSpecSets map[string][]rpmmd.PackageSpec,
 	seed int64) (distro.Manifest, error) {
 
+	if t.name == "wsl" {
+		customizations = distro.WSLCustomizations(customizations)
+	}
+	if strings.HasPrefix(t.name, "vagrant-") {
+		customizations = distro.VagrantCustomizations(customizations)
+	}
+	if t.name == "gce" || t.name == "gce-rhui" {
+		customizations = distro.GCECustomizations(customizations)
+	}
+	if t.name == "azure-rhui" || t.name == "azure-sap" {
+		customizations = distro.AzureCustomizations(customizations)
+	}
+	if t.sap {
+		customizations = distro.SAPCustomizations(customizations)
+	}
+
 	if err := t.checkOptions(customizations, options); err != nil {
 		return distro.Manifest{}, err
 	}
@@ -258,12 +299,29 @@ func (t *imageType) Manifest(customizations *blueprint.Customizations,
 		osbuild.Manifest{
 			Version:   "2",
 			Pipelines: pipelines,
-			Sources:   t.sources(allPackageSpecs, commits),
+			Sources:   t.sources(allPackageSpecs, commits, t.files(customizations, options, packageSpecSets)),
 		},
 	)
 }
 
-func (t *imageType) sources(packages []rpmmd.PackageSpec, ostreeCommits []ostreeCommit) osbuild.Sources {
+// Returns the files which the customizations and image type embed in the
+// image, whose contents are in the sources of the manifest.
+func (t *imageType) files(c *blueprint.Customizations, options distro.ImageOptions, packageSpecSets map[string][]rpmmd.PackageSpec) []blueprint.FileCustomization {
+	files := append(fsnode.UnitFiles(c.GetServices()), fsnode.Files(c, options.ModuleStreams)...)
+	files = append(files, fdoFiles(c.GetFDO())...)
+	files = append(files, ignitionFiles(c.GetIgnition())...)
+	if strings.HasPrefix(t.name, "vagrant-") {
+		files = append(files, distro.VagrantBoxFiles(strings.TrimPrefix(t.name, "vagrant-"), options.Size)...)
+	}
+	if t.bootable && t.arch.extlinux {
+		// the pipelines failed already if the kernel is missing
+		extlinux, _ := t.extlinuxFiles(c, packageSpecSets["packages"])
+		files = append(files, extlinux...)
+	}
+	return files
+}
+
+func (t *imageType) sources(packages []rpmmd.PackageSpec, ostreeCommits []ostreeCommit, files []blueprint.FileCustomization) osbuild.Sources {
 	sources := osbuild.Sources{}
 	curl := &osbuild.CurlSource{
 		Items: make(map[string]osbuild.CurlSourceItem),
@@ -293,6 +351,10 @@ func (t *imageType) sources(packages []rpmmd.PackageSpec, ostreeCommits []ostree
 	if len(ostree.Items) > 0 {
 		sources["org.osbuild.ostree"] = ostree
 	}
+
+	if inline := fsnode.InlineSource(files); inline != nil {
+		sources["org.osbuild.inline"] = inline
+	}
 	return sources
 }
 
@@ -302,8 +364,38 @@ func (t *imageType) checkOptions(customizations *blueprint.Customizations, optio
 		if options.OSTree.Parent == "" {
 			return fmt.Errorf("boot ISO image type %q requires specifying a URL from which to retrieve the OSTree commit", t.name)
 		}
-		if customizations != nil {
-			return fmt.Errorf("boot ISO image type %q does not support blueprint customizations", t.name)
+		// only the customizations of the installer itself are supported
+		if t.name == "edge-simplified-installer" {
+			// the URL is also the remote of the deployment
+			if options.OSTree.URL == "" {
+				return fmt.Errorf("boot ISO image type %q requires specifying a URL from which to retrieve the OSTree commit", t.name)
+			}
+			if customizations.GetInstallationDevice() == "" {
+				return fmt.Errorf("boot ISO image type %q requires specifying an installation device to install to", t.name)
+			}
+			c := *customizations
+			c.FDO, c.Ignition, c.InstallationDevice = nil, nil, ""
+			if !reflect.DeepEqual(c, blueprint.Customizations{}) {
+				return fmt.Errorf("boot ISO image type %q only supports FDO, Ignition and installation device customizations", t.name)
+			}
+		} else if customizations != nil {
+			c := *customizations
+			c.FDO, c.Ignition = nil, nil
+			if !reflect.DeepEqual(c, blueprint.Customizations{}) {
+				return fmt.Errorf("boot ISO image type %q only supports FDO and Ignition customizations", t.name)
+			}
+		}
+	} else if customizations.GetFDO() != nil || customizations.GetIgnition() != nil {
+		return fmt.Errorf("FDO and Ignition customizations are not supported for image type %q", t.name)
+	}
+
+	if t.name != "edge-simplified-installer" && customizations.GetInstallationDevice() != "" {
+		return fmt.Errorf("installation device customizations are not supported for image type %q", t.name)
+	}
+
+	if t.bootISO && !t.rpmOstree {
+		if err := checkInstallerUsers(customizations); err != nil {
+			return fmt.Errorf("image type %q: %v", t.name, err)
 		}
 	}
 
@@ -311,6 +403,67 @@ func (t *imageType) checkOptions(customizations *blueprint.Customizations, optio
 		return fmt.Errorf("kernel boot parameter customizations are not supported for ostree types")
 	}
 
+	if mountpoints := customizations.GetFilesystems(); len(mountpoints) > 0 {
+		if t.partitionTableGenerator == nil {
+			return fmt.Errorf("filesystem customizations are not supported for image type %q", t.name)
+		}
+		if err := disk.CheckMountpoints(mountpoints, disk.MountpointAllowList); err != nil {
+			return err
+		}
+	}
+
+	if customizations.GetDisk() != nil {
+		return fmt.Errorf("disk customizations are not supported for image type %q", t.name)
+	}
+
+	if t.rhui && options.Subscription != nil {
+		return fmt.Errorf("image type %q gets its content from RHUI and cannot be registered with a subscription", t.name)
+	}
+
+	if options.CompressionLevel != nil {
+		if t.compression == "" {
+			return fmt.Errorf("image type %q is not compressed, its compression level cannot be set", t.name)
+		}
+		if level := *options.CompressionLevel; level < 0 || level > 9 {
+			return fmt.Errorf("invalid compression level %d, %s supports levels 0 to 9", level, t.compression)
+		}
+	}
+
+	if err := fsnode.Check(append(fsnode.UnitFiles(customizations.GetServices()), fsnode.Files(customizations, options.ModuleStreams)...), customizations.GetDirectories()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Returns an error if the users and groups of `c` cannot be created by the
+// installer, which creates them from its kickstart file after the tree with
+// the files and directories of `c` has been built.
+func checkInstallerUsers(c *blueprint.Customizations) error {
+	names := make(map[string]bool)
+	for _, user := range c.GetUsers() {
+		if user.ExpireDate != nil || user.ForcePasswordReset {
+			return fmt.Errorf("expiredate and force_password_reset of user %s are not supported by the installer", user.Name)
+		}
+		names[user.Name] = true
+	}
+	for _, group := range c.GetGroups() {
+		names[group.Name] = true
+	}
+
+	owners := make(map[string]string)
+	for _, f := range c.GetFiles() {
+		owners[f.User], owners[f.Group] = f.Path, f.Path
+	}
+	for _, d := range c.GetDirectories() {
+		owners[d.User], owners[d.Group] = d.Path, d.Path
+	}
+	for owner, p := range owners {
+		if names[owner] {
+			return fmt.Errorf("%s cannot be owned by %s, which is created by the installer", p, owner)
+		}
+	}
+
 	return nil
 }
 
@@ -324,6 +477,8 @@ func NewHostDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 }
 
 func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
+	const GigaByte = 1024 * 1024 * 1024
+
 	rd := &distribution{
 		name:             name,
 		modulePlatformID: modulePlatformID,
@@ -434,6 +589,24 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 		},
 		Exclude: nil,
 	}
+	edgeSimplifiedInstallerPkgSet := rpmmd.PackageSet{
+		Include: []string{
+			"attr", "basesystem", "binutils", "bsdtar", "clevis-dracut",
+			"clevis-luks", "cloud-utils-growpart", "coreos-installer",
+			"coreos-installer-dracut", "coreutils", "device-mapper-multipath",
+			"dnsmasq", "dosfstools", "dracut-live", "e2fsprogs", "fcoe-utils",
+			"fdo-init", "gzip", "ima-evm-utils", "iproute", "iptables",
+			"iputils", "iscsi-initiator-utils", "keyutils", "kernel", "lldpad",
+			"lvm2", "passwd", "policycoreutils", "policycoreutils-python-utils",
+			"procps-ng", "rootfiles", "setools-console", "sudo", "traceroute",
+			"util-linux",
+			// boot loader of the ISO
+			"grub2-efi-ia32-cdboot", "grub2-efi-x64-cdboot", "grub2-tools",
+			"grub2-tools-efi", "grub2-tools-extra", "grub2-tools-minimal",
+			"shim-ia32", "shim-x64", "syslinux", "syslinux-nonlinux",
+		},
+		Exclude: nil,
+	}
 	edgeCommitX86PkgSet := rpmmd.PackageSet{
 		Include: append(edgeCommitCommonPkgSet.Include,
 			// x86 specific
@@ -502,6 +675,23 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 		pipelines:       edgeInstallerPipelines,
 		exports:         []string{"bootiso"},
 	}
+	edgeSimplifiedInstallerImgTypeX86_64 := imageType{
+		name:     "edge-simplified-installer",
+		filename: "simplified-installer.iso",
+		mimeType: "application/x-iso9660-image",
+		packageSets: map[string]rpmmd.PackageSet{
+			"build":     edgeBuildPkgSet,
+			"installer": edgeSimplifiedInstallerPkgSet,
+		},
+		kernelOptions:           "modprobe.blacklist=vc4 rw",
+		rpmOstree:               true,
+		bootISO:                 true,
+		compression:             "xz",
+		defaultSize:             10 * GigaByte,
+		pipelines:               edgeSimplifiedInstallerPipelines,
+		exports:                 []string{"bootiso"},
+		partitionTableGenerator: defaultPartitionTable,
+	}
 
 	x86_64 := architecture{
 		name:   "x86_64",
@@ -509,6 +699,8 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 		packageSets: map[string]rpmmd.PackageSet{
 			"boot": x8664BootPackageSet(),
 		},
+		legacy: "i386-pc",
+		uefi:   true,
 	}
 
 	tarImgType := imageType{
@@ -524,8 +716,21 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 		pipelines: tarPipelines,
 		exports:   []string{"root-tar"},
 	}
-	tarInstallerImgTypeX86_64 := imageType{
-		name:     "tar-installer",
+	wslImgType := imageType{
+		name:     "wsl",
+		filename: "rootfs.tar.gz",
+		mimeType: "application/x-tar",
+		packageSets: map[string]rpmmd.PackageSet{
+			"packages": {
+				Include: []string{"@core", "policycoreutils", "selinux-policy-targeted"},
+				Exclude: []string{"dracut-config-rescue", "rng-tools"},
+			},
+		},
+		pipelines: tarPipelines,
+		exports:   []string{"root-tar"},
+	}
+	imageInstallerImgTypeX86_64 := imageType{
+		name:     "image-installer",
 		filename: "installer.iso",
 		mimeType: "application/x-iso9660-image",
 		packageSets: map[string]rpmmd.PackageSet{
@@ -538,10 +743,135 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 		},
 		rpmOstree: false,
 		bootISO:   true,
-		pipelines: tarInstallerPipelines,
+		pipelines: imageInstallerPipelines,
 		exports:   []string{"bootiso"},
 	}
 
+	liveISOImgTypeX86_64 := imageType{
+		name:     "live-iso",
+		filename: "live.iso",
+		mimeType: "application/x-iso9660-image",
+		packageSets: map[string]rpmmd.PackageSet{
+			"build":    installerBuildPackageSet(),
+			"packages": liveISOPackageSet(),
+		},
+		pipelines: liveISOPipelines,
+		exports:   []string{"bootiso"},
+	}
+
+	vagrantLibvirtImgTypeX86_64 := imageType{
+		name:     "vagrant-libvirt",
+		filename: "vagrant-libvirt.box",
+		mimeType: "application/x-tar",
+		packageSets: map[string]rpmmd.PackageSet{
+			"build":    x8664BuildPackageSet(),
+			"packages": vagrantPackageSet(),
+		},
+		enabledServices:         []string{"sshd"},
+		kernelOptions:           "ro no_timer_check console=tty0 console=ttyS0,115200n8 net.ifnames=0",
+		bootable:                true,
+		defaultSize:             10 * GigaByte,
+		pipelines:               vagrantPipelines,
+		exports:                 []string{"archive"},
+		partitionTableGenerator: defaultPartitionTable,
+	}
+	rawXzImgTypeX86_64 := imageType{
+		name:     "raw-xz",
+		filename: "disk.raw.xz",
+		mimeType: "application/xz",
+		packageSets: map[string]rpmmd.PackageSet{
+			"build":    x8664BuildPackageSet(),
+			"packages": rawPackageSet(),
+		},
+		kernelOptions:           "ro net.ifnames=0",
+		bootable:                true,
+		compression:             "xz",
+		defaultSize:             4 * GigaByte,
+		pipelines:               rawXzPipelines,
+		exports:                 []string{"xz"},
+		partitionTableGenerator: defaultPartitionTable,
+	}
+	minimalRawImgTypeX86_64 := rawXzImgTypeX86_64
+	minimalRawImgTypeX86_64.name = "minimal-raw"
+	minimalRawImgTypeX86_64.packageSets = map[string]rpmmd.PackageSet{
+		"build":    x8664BuildPackageSet(),
+		"packages": minimalRawPackageSet(),
+	}
+	minimalRawImgTypeX86_64.enabledServices = []string{"NetworkManager.service", "initial-setup.service"}
+	gceImgTypeX86_64 := imageType{
+		name:     "gce",
+		filename: "image.tar.gz",
+		mimeType: "application/gzip",
+		packageSets: map[string]rpmmd.PackageSet{
+			"build":    x8664BuildPackageSet(),
+			"packages": gcePackageSet(),
+		},
+		enabledServices:         []string{"sshd", "rngd", "dnf-automatic.timer"},
+		kernelOptions:           "net.ifnames=0 biosdevname=0 scsi_mod.use_blk_mq=Y crashkernel=auto console=ttyS0,38400n8d",
+		bootable:                true,
+		defaultSize:             20 * GigaByte,
+		pipelines:               gcePipelines,
+		exports:                 []string{"archive"},
+		partitionTableGenerator: defaultPartitionTable,
+	}
+	gceRHUIImgTypeX86_64 := gceImgTypeX86_64
+	gceRHUIImgTypeX86_64.name = "gce-rhui"
+	gceRHUIImgTypeX86_64.rhui = true
+	gceRHUIImgTypeX86_64.packageSets = map[string]rpmmd.PackageSet{
+		"build":    x8664BuildPackageSet(),
+		"packages": gceRHUIPackageSet(),
+	}
+
+	azureRHUIImgTypeX86_64 := imageType{
+		name:     "azure-rhui",
+		filename: "disk.vhd.xz",
+		mimeType: "application/xz",
+		packageSets: map[string]rpmmd.PackageSet{
+			"build":    x8664BuildPackageSet(),
+			"packages": azureRHUIPackageSet(),
+		},
+		enabledServices: []string{
+			"sshd", "waagent", "cloud-init", "cloud-init-local",
+			"cloud-config", "cloud-final",
+		},
+		defaultTarget:           "multi-user.target",
+		kernelOptions:           "ro crashkernel=auto console=tty1 console=ttyS0 earlyprintk=ttyS0 rootdelay=300",
+		bootable:                true,
+		compression:             "xz",
+		rhui:                    true,
+		defaultSize:             64 * GigaByte,
+		pipelines:               azureRHUIPipelines,
+		exports:                 []string{"xz"},
+		partitionTableGenerator: defaultPartitionTable,
+	}
+
+	azureSAPImgTypeX86_64 := azureRHUIImgTypeX86_64
+	azureSAPImgTypeX86_64.name = "azure-sap"
+	azureSAPImgTypeX86_64.packageSets = map[string]rpmmd.PackageSet{
+		"build":    x8664BuildPackageSet(),
+		"packages": azureRHUIPackageSet().Append(sapPackageSet()),
+	}
+	azureSAPImgTypeX86_64.enabledServices = append(azureRHUIImgTypeX86_64.enabledServices, "uuidd.socket")
+	azureSAPImgTypeX86_64.kernelOptions = azureRHUIImgTypeX86_64.kernelOptions + " " + distro.SAPKernelOptions
+	azureSAPImgTypeX86_64.sap = true
+
+	vagrantVirtualBoxImgTypeX86_64 := vagrantLibvirtImgTypeX86_64
+	vagrantVirtualBoxImgTypeX86_64.name = "vagrant-virtualbox"
+	vagrantVirtualBoxImgTypeX86_64.filename = "vagrant-virtualbox.box"
+
+	// aarch64 has no grub2-pc, so it uses the default build root
+	rawXzImgTypeAarch64 := rawXzImgTypeX86_64
+	rawXzImgTypeAarch64.packageSets = map[string]rpmmd.PackageSet{
+		"packages": rawPackageSet(),
+	}
+	rawXzImgTypeAarch64.kernelOptions = "ro console=ttyAMA0,115200n8 console=tty0"
+	minimalRawImgTypeAarch64 := rawXzImgTypeAarch64
+	minimalRawImgTypeAarch64.name = "minimal-raw"
+	minimalRawImgTypeAarch64.packageSets = map[string]rpmmd.PackageSet{
+		"packages": minimalRawPackageSet(),
+	}
+	minimalRawImgTypeAarch64.enabledServices = minimalRawImgTypeX86_64.enabledServices
+
 	edgeCommitImgTypeAarch64 := imageType{
 		name:     "edge-commit",
 		filename: "commit.tar",
@@ -584,15 +914,17 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 		pipelines:       edgeInstallerPipelines,
 		exports:         []string{"bootiso"},
 	}
-	x86_64.addImageTypes(tarImgType, tarInstallerImgTypeX86_64, edgeCommitImgTypeX86_64, edgeInstallerImgTypeX86_64, edgeOCIImgTypeX86_64)
+	x86_64.addImageTypes(tarImgType, wslImgType, imageInstallerImgTypeX86_64, liveISOImgTypeX86_64, rawXzImgTypeX86_64, minimalRawImgTypeX86_64, gceImgTypeX86_64, gceRHUIImgTypeX86_64, azureRHUIImgTypeX86_64, azureSAPImgTypeX86_64, vagrantLibvirtImgTypeX86_64, vagrantVirtualBoxImgTypeX86_64, edgeCommitImgTypeX86_64, edgeInstallerImgTypeX86_64, edgeSimplifiedInstallerImgTypeX86_64, edgeOCIImgTypeX86_64)
 	aarch64 := architecture{
 		name:   "aarch64",
 		distro: rd,
 		packageSets: map[string]rpmmd.PackageSet{
 			"boot": aarch64BootPackageSet(),
 		},
+		uefi:     true,
+		extlinux: true,
 	}
-	aarch64.addImageTypes(tarImgType, edgeCommitImgTypeAarch64, edgeOCIImgTypeAarch64, edgeInstallerImgTypeAarch64)
+	aarch64.addImageTypes(tarImgType, rawXzImgTypeAarch64, minimalRawImgTypeAarch64, edgeCommitImgTypeAarch64, edgeOCIImgTypeAarch64, edgeInstallerImgTypeAarch64)
 
 	ppc64le := architecture{
 		distro: rd,
@@ -613,3 +945,120 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 	rd.addArches(x86_64, aarch64, ppc64le, s390x)
 	return rd
 }
+
+func defaultPartitionTable(imageOptions distro.ImageOptions, arch distro.Arch, rng *rand.Rand) disk.PartitionTable {
+	if arch.Name() == "aarch64" {
+		return aarch64PartitionTable(imageOptions, rng)
+	}
+	if arch.Name() != "x86_64" {
+		panic(fmt.Sprintf("unsupported architecture %s for the default partition table, this is a programming error", arch.Name()))
+	}
+
+	return disk.PartitionTable{
+		Size: imageOptions.Size,
+		UUID: "D209C89E-EA5E-4FBD-B161-B461CCE297E0",
+		Type: "gpt",
+		Partitions: []disk.Partition{
+			{
+				Bootable: true,
+				Size:     2048,
+				Start:    2048,
+				Type:     "21686148-6449-6E6F-744E-656564454649",
+				UUID:     "FAC7F1FB-3E8D-4137-A512-961DE09A5549",
+			},
+			{
+				Start: 4096,
+				Size:  204800,
+				Type:  "C12A7328-F81F-11D2-BA4B-00A0C93EC93B",
+				UUID:  "68B2905B-DF3E-4FB3-80FA-49D1E773AA33",
+				Filesystem: &disk.Filesystem{
+					Type:         "vfat",
+					UUID:         "7B77-95E7",
+					Mountpoint:   "/boot/efi",
+					FSTabOptions: "defaults,uid=0,gid=0,umask=077,shortname=winnt",
+					FSTabFreq:    0,
+					FSTabPassNo:  2,
+				},
+			},
+			{
+				Start: 208896,
+				Type:  "0FC63DAF-8483-4772-8E79-3D69D8477DE4",
+				UUID:  "6264D520-3FB9-423F-8AB8-7A0A8E3D3562",
+				Filesystem: &disk.Filesystem{
+					Type:         "xfs",
+					UUID:         uuid.Must(newRandomUUIDFromReader(rng)).String(),
+					Label:        "root",
+					Mountpoint:   "/",
+					FSTabOptions: "defaults",
+					FSTabFreq:    0,
+					FSTabPassNo:  0,
+				},
+			},
+		},
+	}
+}
+
+// aarch64PartitionTable is a dos partition table, which the firmware of most
+// single board computers can read. u-boot cannot read xfs, so the kernels are
+// on an ext4 /boot partition.
+func aarch64PartitionTable(imageOptions distro.ImageOptions, rng *rand.Rand) disk.PartitionTable {
+	return disk.PartitionTable{
+		Size: imageOptions.Size,
+		UUID: "0x14fc63d2",
+		Type: "dos",
+		Partitions: []disk.Partition{
+			{
+				Start: 2048,
+				Size:  204800,
+				Type:  "06",
+				Filesystem: &disk.Filesystem{
+					Type:         "vfat",
+					UUID:         "7B77-95E7",
+					Mountpoint:   "/boot/efi",
+					FSTabOptions: "defaults,uid=0,gid=0,umask=077,shortname=winnt",
+					FSTabFreq:    0,
+					FSTabPassNo:  2,
+				},
+			},
+			{
+				Bootable: true,
+				Start:    206848,
+				Size:     2097152,
+				Type:     "83",
+				Filesystem: &disk.Filesystem{
+					Type:         "ext4",
+					UUID:         uuid.Must(newRandomUUIDFromReader(rng)).String(),
+					Label:        "boot",
+					Mountpoint:   "/boot",
+					FSTabOptions: "defaults",
+					FSTabFreq:    0,
+					FSTabPassNo:  2,
+				},
+			},
+			{
+				Start: 2304000,
+				Type:  "83",
+				Filesystem: &disk.Filesystem{
+					Type:         "xfs",
+					UUID:         uuid.Must(newRandomUUIDFromReader(rng)).String(),
+					Label:        "root",
+					Mountpoint:   "/",
+					FSTabOptions: "defaults",
+					FSTabFreq:    0,
+					FSTabPassNo:  0,
+				},
+			},
+		},
+	}
+}
+
+func newRandomUUIDFromReader(r io.Reader) (uuid.UUID, error) {
+	var id uuid.UUID
+	_, err := io.ReadFull(r, id[:])
+	if err != nil {
+		return uuid.Nil, err
+	}
+	id[6] = (id[6] & 0x0f) | 0x40 // Version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // Variant is 10
+	return id, nil
+}
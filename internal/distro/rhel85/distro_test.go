@@ -1,6 +1,9 @@
 package rhel85_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,6 +13,7 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/distro/distro_test_common"
 	"github.com/osbuild/osbuild-composer/internal/distro/rhel85"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 )
 
 type rhelFamilyDistro struct {
@@ -47,6 +51,66 @@ func TestFilenameFromType(t *testing.T) {
 			want:  "installer.iso",
 			want1: "application/x-iso9660-image",
 		},
+		{
+			name:  "edge-simplified-installer",
+			args:  args{"edge-simplified-installer"},
+			want:  "simplified-installer.iso",
+			want1: "application/x-iso9660-image",
+		},
+		{
+			name:  "wsl",
+			args:  args{"wsl"},
+			want:  "rootfs.tar.gz",
+			want1: "application/x-tar",
+		},
+		{
+			name:  "raw-xz",
+			args:  args{"raw-xz"},
+			want:  "disk.raw.xz",
+			want1: "application/xz",
+		},
+		{
+			name:  "minimal-raw",
+			args:  args{"minimal-raw"},
+			want:  "disk.raw.xz",
+			want1: "application/xz",
+		},
+		{
+			name:  "gce",
+			args:  args{"gce"},
+			want:  "image.tar.gz",
+			want1: "application/gzip",
+		},
+		{
+			name:  "gce-rhui",
+			args:  args{"gce-rhui"},
+			want:  "image.tar.gz",
+			want1: "application/gzip",
+		},
+		{
+			name:  "azure-rhui",
+			args:  args{"azure-rhui"},
+			want:  "disk.vhd.xz",
+			want1: "application/xz",
+		},
+		{
+			name:  "azure-sap",
+			args:  args{"azure-sap"},
+			want:  "disk.vhd.xz",
+			want1: "application/xz",
+		},
+		{
+			name:  "vagrant-libvirt",
+			args:  args{"vagrant-libvirt"},
+			want:  "vagrant-libvirt.box",
+			want1: "application/x-tar",
+		},
+		{
+			name:  "vagrant-virtualbox",
+			args:  args{"vagrant-virtualbox"},
+			want:  "vagrant-virtualbox.box",
+			want1: "application/x-tar",
+		},
 		{
 			name:    "invalid-output-type",
 			args:    args{"foobar"},
@@ -206,8 +270,11 @@ func TestDistro_ManifestError(t *testing.T) {
 			_, err := imgType.Manifest(bp.Customizations, imgOpts, nil, nil, 0)
 			if imgTypeName == "edge-commit" || imgTypeName == "edge-container" {
 				assert.EqualError(t, err, "kernel boot parameter customizations are not supported for ostree types")
-			} else if imgTypeName == "edge-installer" {
-				assert.EqualError(t, err, "boot ISO image type \"edge-installer\" requires specifying a URL from which to retrieve the OSTree commit")
+			} else if imgTypeName == "edge-installer" || imgTypeName == "edge-simplified-installer" {
+				assert.EqualError(t, err, fmt.Sprintf("boot ISO image type %q requires specifying a URL from which to retrieve the OSTree commit", imgTypeName))
+			} else if imgTypeName == "live-iso" || strings.HasPrefix(imgTypeName, "azure-") || (archName == "aarch64" && (imgTypeName == "raw-xz" || imgTypeName == "minimal-raw")) {
+				// the kernel version is taken from the (missing) package specs
+				assert.EqualError(t, err, "kernel package not found in package set")
 			} else {
 				assert.NoError(t, err)
 			}
@@ -227,8 +294,19 @@ func TestArchitecture_ListImageTypes(t *testing.T) {
 				"edge-commit",
 				"edge-container",
 				"edge-installer",
+				"edge-simplified-installer",
 				"tar",
-				"tar-installer",
+				"wsl",
+				"image-installer",
+				"live-iso",
+				"raw-xz",
+				"minimal-raw",
+				"gce",
+				"gce-rhui",
+				"azure-rhui",
+				"azure-sap",
+				"vagrant-libvirt",
+				"vagrant-virtualbox",
 			},
 		},
 		{
@@ -238,6 +316,8 @@ func TestArchitecture_ListImageTypes(t *testing.T) {
 				"edge-container",
 				"edge-installer",
 				"tar",
+				"raw-xz",
+				"minimal-raw",
 			},
 		},
 		{
@@ -324,6 +404,536 @@ func TestRhel85_ModulePlatformID(t *testing.T) {
 	assert.Equal(t, "platform:el8", distro.ModulePlatformID())
 }
 
+func TestDistro_FDOAndIgnitionCustomizations(t *testing.T) {
+	arch, err := rhel85.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	fdo := &blueprint.FDOCustomization{
+		ManufacturingServerURL: "http://10.0.0.2:8080",
+		DiunPubKeyRootCerts:    "-----BEGIN CERTIFICATE-----\n",
+	}
+	ignition := &blueprint.IgnitionCustomization{
+		FirstBoot: &blueprint.FirstBootIgnitionCustomization{ProvisioningURL: "https://example.com/config.ign"},
+	}
+
+	imgType, err := arch.GetImageType("edge-installer")
+	require.NoError(t, err)
+	options := distro.ImageOptions{
+		OSTree: distro.OSTreeImageOptions{
+			Parent: "02604b2da6e954bd34b8b82a835e5a77d2b60ffa",
+			URL:    "https://example.com/repo",
+		},
+	}
+	packages := map[string][]rpmmd.PackageSpec{
+		"installer": {{Name: "kernel", Version: "4.18.0", Release: "305.el8", Arch: "x86_64"}},
+	}
+	manifest, err := imgType.Manifest(&blueprint.Customizations{FDO: fdo, Ignition: ignition}, options, nil, packages, 0)
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"fdo.manufacturing_server_url=http://10.0.0.2:8080",
+		"fdo.diun_pub_key_root_certs=/fdo_diun_pub_key_root_certs.pem",
+		"ignition.config.url=https://example.com/config.ign",
+		"tree:///fdo_diun_pub_key_root_certs.pem",
+		"org.osbuild.inline",
+	} {
+		assert.True(t, strings.Contains(string(manifest), expected), "manifest does not contain %q", expected)
+	}
+
+	hostname := "edge"
+	_, err = imgType.Manifest(&blueprint.Customizations{FDO: fdo, Hostname: &hostname}, options, nil, packages, 0)
+	assert.EqualError(t, err, "boot ISO image type \"edge-installer\" only supports FDO and Ignition customizations")
+
+	imgType, err = arch.GetImageType("tar")
+	require.NoError(t, err)
+	_, err = imgType.Manifest(&blueprint.Customizations{Ignition: ignition}, distro.ImageOptions{Size: imgType.Size(0)}, nil, nil, 0)
+	assert.EqualError(t, err, "FDO and Ignition customizations are not supported for image type \"tar\"")
+}
+
+func TestDistro_EdgeSimplifiedInstaller(t *testing.T) {
+	arch, err := rhel85.New().GetArch("x86_64")
+	require.NoError(t, err)
+	imgType, err := arch.GetImageType("edge-simplified-installer")
+	require.NoError(t, err)
+
+	options := distro.ImageOptions{
+		Size: imgType.Size(0),
+		OSTree: distro.OSTreeImageOptions{
+			Ref:    "rhel/8/x86_64/edge",
+			Parent: "02604b2da6e954bd34b8b82a835e5a77d2b60ffa",
+			URL:    "https://example.com/repo",
+		},
+	}
+	packages := map[string][]rpmmd.PackageSpec{
+		"installer": {{Name: "kernel", Version: "4.18.0", Release: "305.el8", Arch: "x86_64"}},
+	}
+	customizations := &blueprint.Customizations{
+		InstallationDevice: "/dev/vda",
+		FDO: &blueprint.FDOCustomization{
+			ManufacturingServerURL: "http://10.0.0.2:8080",
+			DiunPubKeyInsecure:     true,
+		},
+		Ignition: &blueprint.IgnitionCustomization{
+			FirstBoot: &blueprint.FirstBootIgnitionCustomization{ProvisioningURL: "https://example.com/config.ign"},
+		},
+	}
+	manifest, err := imgType.Manifest(customizations, options, nil, packages, 0)
+	require.NoError(t, err)
+
+	var parsed struct {
+		Pipelines []struct {
+			Name   string
+			Stages []struct {
+				Type    string
+				Options map[string]interface{}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(manifest, &parsed))
+
+	var stages []string
+	for _, pipeline := range parsed.Pipelines {
+		for _, stage := range pipeline.Stages {
+			stages = append(stages, pipeline.Name+":"+stage.Type)
+			switch stage.Type {
+			case "org.osbuild.ostree.deploy":
+				assert.Equal(t, "redhat", stage.Options["osname"])
+				assert.Equal(t, "rhel/8/x86_64/edge", stage.Options["ref"])
+				assert.Contains(t, stage.Options["kernel_opts"], "ignition.config.url=https://example.com/config.ign")
+			case "org.osbuild.dracut":
+				assert.Equal(t, []interface{}{"coreos-installer", "fdo"}, stage.Options["add_modules"])
+			case "org.osbuild.xz":
+				assert.Equal(t, "image.raw.xz", stage.Options["filename"])
+			}
+		}
+	}
+	assert.Subset(t, stages, []string{
+		"image-tree:org.osbuild.ostree.init-fs",
+		"image-tree:org.osbuild.ostree.pull",
+		"image-tree:org.osbuild.ostree.deploy",
+		"image-tree:org.osbuild.ignition",
+		"image-tree:org.osbuild.grub2",
+		"image:org.osbuild.grub2.inst",
+		"xz:org.osbuild.xz",
+		"coi-tree:org.osbuild.dracut",
+		"bootiso-tree:org.osbuild.copy",
+		"bootiso:org.osbuild.xorrisofs",
+	})
+	for _, expected := range []string{
+		"coreos.inst.install_dev=/dev/vda",
+		"coreos.inst.image_file=/run/media/iso/image.raw.xz",
+		"fdo.manufacturing_server_url=http://10.0.0.2:8080",
+	} {
+		assert.True(t, strings.Contains(string(manifest), expected), "manifest does not contain %q", expected)
+	}
+
+	_, err = imgType.Manifest(&blueprint.Customizations{FDO: customizations.FDO}, options, nil, packages, 0)
+	assert.EqualError(t, err, `boot ISO image type "edge-simplified-installer" requires specifying an installation device to install to`)
+
+	hostname := "edge"
+	_, err = imgType.Manifest(&blueprint.Customizations{InstallationDevice: "/dev/vda", Hostname: &hostname}, options, nil, packages, 0)
+	assert.EqualError(t, err, `boot ISO image type "edge-simplified-installer" only supports FDO, Ignition and installation device customizations`)
+
+	imgType, err = arch.GetImageType("edge-installer")
+	require.NoError(t, err)
+	_, err = imgType.Manifest(&blueprint.Customizations{InstallationDevice: "/dev/vda"}, options, nil, packages, 0)
+	assert.EqualError(t, err, `boot ISO image type "edge-installer" only supports FDO and Ignition customizations`)
+
+	imgType, err = arch.GetImageType("minimal-raw")
+	require.NoError(t, err)
+	_, err = imgType.Manifest(&blueprint.Customizations{InstallationDevice: "/dev/vda"}, distro.ImageOptions{Size: imgType.Size(0)}, nil, nil, 0)
+	assert.EqualError(t, err, `installation device customizations are not supported for image type "minimal-raw"`)
+}
+
+func TestDistro_ImageInstallerUsers(t *testing.T) {
+	arch, err := rhel85.New().GetArch("x86_64")
+	require.NoError(t, err)
+	imgType, err := arch.GetImageType("image-installer")
+	require.NoError(t, err)
+
+	key := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIASBH0vbbEmHBz0Z6R4oXDHM7iZE2E6lWl3FE9Bsnhxh user@example.com"
+	customizations := &blueprint.Customizations{
+		User:  []blueprint.UserCustomization{{Name: "admin", Groups: []string{"wheel", "admins"}, Keys: []string{key}}},
+		Group: []blueprint.GroupCustomization{{Name: "admins"}},
+	}
+	packages := map[string][]rpmmd.PackageSpec{
+		"installer": {{Name: "kernel", Version: "4.18.0", Release: "305.el8", Arch: "x86_64"}},
+	}
+	manifest, err := imgType.Manifest(customizations, distro.ImageOptions{}, nil, packages, 0)
+	require.NoError(t, err)
+
+	var parsed struct {
+		Pipelines []struct {
+			Name   string
+			Stages []struct {
+				Type    string
+				Options map[string]interface{}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(manifest, &parsed))
+	var kickstart map[string]interface{}
+	for _, pipeline := range parsed.Pipelines {
+		for _, stage := range pipeline.Stages {
+			if pipeline.Name == "os" {
+				// the installer creates the users and groups
+				assert.NotContains(t, []string{"org.osbuild.users", "org.osbuild.groups"}, stage.Type)
+			}
+			if stage.Type == "org.osbuild.kickstart" {
+				kickstart = stage.Options
+			}
+		}
+	}
+	require.NotNil(t, kickstart)
+	assert.Equal(t, map[string]interface{}{"groups": []interface{}{"wheel", "admins"}, "key": key}, kickstart["users"].(map[string]interface{})["admin"])
+	assert.Equal(t, map[string]interface{}{"name": "admins"}, kickstart["groups"].(map[string]interface{})["admins"])
+
+	customizations.Files = []blueprint.FileCustomization{{Path: "/etc/admin.conf", User: "admin"}}
+	_, err = imgType.Manifest(customizations, distro.ImageOptions{}, nil, packages, 0)
+	assert.EqualError(t, err, "image type \"image-installer\": /etc/admin.conf cannot be owned by admin, which is created by the installer")
+}
+
+func TestDistro_LiveISO(t *testing.T) {
+	arch, err := rhel85.New().GetArch("x86_64")
+	require.NoError(t, err)
+	imgType, err := arch.GetImageType("live-iso")
+	require.NoError(t, err)
+
+	customizations := &blueprint.Customizations{
+		Kernel: &blueprint.KernelCustomization{Append: "nomodeset"},
+	}
+	packages := map[string][]rpmmd.PackageSpec{
+		"packages": {{Name: "kernel", Version: "4.18.0", Release: "305.el8", Arch: "x86_64"}},
+	}
+	manifest, err := imgType.Manifest(customizations, distro.ImageOptions{}, nil, packages, 0)
+	require.NoError(t, err)
+
+	var parsed struct {
+		Pipelines []struct {
+			Name   string
+			Stages []struct {
+				Type    string
+				Inputs  map[string]interface{}
+				Options map[string]interface{}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(manifest, &parsed))
+	var stages []string
+	for _, pipeline := range parsed.Pipelines {
+		for _, stage := range pipeline.Stages {
+			stages = append(stages, pipeline.Name+":"+stage.Type)
+			if stage.Type == "org.osbuild.bootiso.mono" {
+				rootfs := stage.Inputs["rootfs"].(map[string]interface{})
+				assert.Equal(t, []interface{}{"name:os"}, rootfs["references"])
+				assert.Equal(t, "root=live:CDLABEL=RHEL-8-5-0-BaseOS-x86_64 rd.live.image nomodeset", stage.Options["kernel_opts"])
+			}
+		}
+	}
+	assert.Contains(t, stages, "os:org.osbuild.dracut")
+	assert.Contains(t, stages, "bootiso-tree:org.osbuild.bootiso.mono")
+	assert.NotContains(t, stages, "bootiso-tree:org.osbuild.kickstart")
+}
+
+func TestDistro_Vagrant(t *testing.T) {
+	arch, err := rhel85.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	customizations := &blueprint.Customizations{
+		Filesystem: []blueprint.FilesystemCustomization{{Mountpoint: "/var", Size: 1024 * 1024 * 1024}},
+	}
+	packages := map[string][]rpmmd.PackageSpec{
+		"packages": {{Name: "kernel", Version: "4.18.0", Release: "305.el8", Arch: "x86_64"}},
+	}
+
+	for provider, disk := range map[string]string{"libvirt": "box.img", "virtualbox": "box-disk1.vmdk"} {
+		imgType, err := arch.GetImageType("vagrant-" + provider)
+		require.NoError(t, err)
+		options := distro.ImageOptions{Size: imgType.Size(0)}
+		manifest, err := imgType.Manifest(customizations, options, nil, packages, 0)
+		require.NoError(t, err)
+
+		var parsed struct {
+			Pipelines []struct {
+				Name   string
+				Stages []struct {
+					Type    string
+					Options map[string]interface{}
+				}
+			}
+			Sources map[string]map[string]map[string]interface{}
+		}
+		require.NoError(t, json.Unmarshal(manifest, &parsed))
+
+		var stages []string
+		for _, pipeline := range parsed.Pipelines {
+			for _, stage := range pipeline.Stages {
+				stages = append(stages, pipeline.Name+":"+stage.Type)
+				switch stage.Type {
+				case "org.osbuild.users":
+					assert.Contains(t, stage.Options["users"], "vagrant")
+				case "org.osbuild.qemu":
+					assert.Equal(t, disk, stage.Options["filename"])
+				case "org.osbuild.tar":
+					assert.Equal(t, "vagrant-"+provider+".box", stage.Options["filename"])
+				}
+			}
+		}
+		assert.Subset(t, stages, []string{
+			"os:org.osbuild.fstab",
+			"os:org.osbuild.grub2",
+			"image:org.osbuild.sfdisk",
+			"image:org.osbuild.copy",
+			"image:org.osbuild.grub2.inst",
+			"vagrant:org.osbuild.qemu",
+			"archive:org.osbuild.tar",
+		}, provider)
+
+		// the box metadata is embedded in the manifest
+		assert.Len(t, parsed.Sources["org.osbuild.inline"]["items"], len(distro.VagrantBoxFiles(provider, options.Size))+1)
+	}
+}
+
+func TestDistro_RawXz(t *testing.T) {
+	packages := map[string][]rpmmd.PackageSpec{
+		"packages": {{Name: "kernel", Version: "4.18.0", Release: "305.el8", Arch: "aarch64"}},
+	}
+
+	for _, archName := range []string{"x86_64", "aarch64"} {
+		arch, err := rhel85.New().GetArch(archName)
+		require.NoError(t, err)
+		imgType, err := arch.GetImageType("raw-xz")
+		require.NoError(t, err)
+
+		level := 6
+		options := distro.ImageOptions{Size: imgType.Size(0), CompressionLevel: &level}
+		manifest, err := imgType.Manifest(nil, options, nil, packages, 0)
+		require.NoError(t, err)
+
+		var parsed struct {
+			Pipelines []struct {
+				Name   string
+				Stages []struct {
+					Type    string
+					Options map[string]interface{}
+				}
+			}
+		}
+		require.NoError(t, json.Unmarshal(manifest, &parsed))
+
+		var stages []string
+		for _, pipeline := range parsed.Pipelines {
+			for _, stage := range pipeline.Stages {
+				stages = append(stages, pipeline.Name+":"+stage.Type)
+				switch stage.Type {
+				case "org.osbuild.xz":
+					assert.Equal(t, "disk.raw.xz", stage.Options["filename"])
+					assert.EqualValues(t, level, stage.Options["level"])
+				case "org.osbuild.sfdisk":
+					if archName == "aarch64" {
+						assert.Equal(t, "dos", stage.Options["label"])
+					} else {
+						assert.Equal(t, "gpt", stage.Options["label"])
+					}
+				}
+			}
+		}
+		assert.Subset(t, stages, []string{"os:org.osbuild.grub2", "image:org.osbuild.sfdisk", "xz:org.osbuild.xz"}, archName)
+		if archName == "aarch64" {
+			// u-boot boots the kernel with extlinux.conf
+			assert.Contains(t, stages, "os:org.osbuild.mkdir")
+			assert.NotContains(t, stages, "image:org.osbuild.grub2.inst")
+		} else {
+			assert.Contains(t, stages, "image:org.osbuild.grub2.inst")
+		}
+
+		for _, level := range []int{-1, 10} {
+			options.CompressionLevel = &level
+			_, err = imgType.Manifest(nil, options, nil, packages, 0)
+			assert.EqualError(t, err, fmt.Sprintf("invalid compression level %d, xz supports levels 0 to 9", level))
+		}
+	}
+
+	arch, err := rhel85.New().GetArch("x86_64")
+	require.NoError(t, err)
+	imgType, err := arch.GetImageType("tar")
+	require.NoError(t, err)
+	level := 9
+	_, err = imgType.Manifest(nil, distro.ImageOptions{CompressionLevel: &level}, nil, nil, 0)
+	assert.EqualError(t, err, `image type "tar" is not compressed, its compression level cannot be set`)
+}
+
+func TestDistro_GCE(t *testing.T) {
+	arch, err := rhel85.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	for _, name := range []string{"gce", "gce-rhui"} {
+		imgType, err := arch.GetImageType(name)
+		require.NoError(t, err)
+		options := distro.ImageOptions{Size: imgType.Size(0)}
+		manifest, err := imgType.Manifest(nil, options, nil, nil, 0)
+		require.NoError(t, err)
+
+		var parsed struct {
+			Pipelines []struct {
+				Name   string
+				Stages []struct {
+					Type    string
+					Options map[string]interface{}
+				}
+			}
+		}
+		require.NoError(t, json.Unmarshal(manifest, &parsed))
+
+		var stages []string
+		for _, pipeline := range parsed.Pipelines {
+			for _, stage := range pipeline.Stages {
+				stages = append(stages, pipeline.Name+":"+stage.Type)
+				switch stage.Type {
+				case "org.osbuild.timezone":
+					assert.Equal(t, "UTC", stage.Options["zone"])
+				case "org.osbuild.truncate":
+					assert.Equal(t, "disk.raw", stage.Options["filename"])
+				case "org.osbuild.tar":
+					assert.Equal(t, "image.tar.gz", stage.Options["filename"])
+					assert.Equal(t, "oldgnu", stage.Options["format"])
+					assert.Equal(t, "omit", stage.Options["root-node"])
+				}
+			}
+		}
+		assert.Subset(t, stages, []string{
+			"os:org.osbuild.chrony",
+			"os:org.osbuild.grub2",
+			"image:org.osbuild.grub2.inst",
+			"archive:org.osbuild.tar",
+		}, name)
+		if name == "gce-rhui" {
+			assert.Contains(t, stages, "os:org.osbuild.rhsm")
+		} else {
+			assert.NotContains(t, stages, "os:org.osbuild.rhsm")
+		}
+	}
+
+	imgType, err := arch.GetImageType("gce-rhui")
+	require.NoError(t, err)
+	options := distro.ImageOptions{
+		Size:         imgType.Size(0),
+		Subscription: &distro.SubscriptionImageOptions{Organization: 42, ActivationKey: "key"},
+	}
+	_, err = imgType.Manifest(nil, options, nil, nil, 0)
+	assert.EqualError(t, err, `image type "gce-rhui" gets its content from RHUI and cannot be registered with a subscription`)
+}
+
+func TestDistro_AzureRHUI(t *testing.T) {
+	arch, err := rhel85.New().GetArch("x86_64")
+	require.NoError(t, err)
+	imgType, err := arch.GetImageType("azure-rhui")
+	require.NoError(t, err)
+
+	// Azure requires the size of vhd images to be a multiple of 1 MiB
+	assert.Equal(t, uint64(2*1024*1024), imgType.Size(1024*1024+1))
+
+	packages := map[string][]rpmmd.PackageSpec{
+		"packages": {{Name: "kernel", Version: "4.18.0", Release: "305.el8", Arch: "x86_64"}},
+	}
+	options := distro.ImageOptions{Size: imgType.Size(0)}
+	manifest, err := imgType.Manifest(nil, options, nil, packages, 0)
+	require.NoError(t, err)
+
+	var parsed struct {
+		Pipelines []struct {
+			Name   string
+			Stages []struct {
+				Type    string
+				Options map[string]interface{}
+			}
+		}
+		Sources map[string]map[string]map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal(manifest, &parsed))
+
+	var stages []string
+	for _, pipeline := range parsed.Pipelines {
+		for _, stage := range pipeline.Stages {
+			stages = append(stages, pipeline.Name+":"+stage.Type)
+			switch stage.Type {
+			case "org.osbuild.dracut":
+				assert.Equal(t, []interface{}{"4.18.0-305.el8.x86_64"}, stage.Options["kernel"])
+				assert.Equal(t, []interface{}{"hv_vmbus", "hv_netvsc", "hv_storvsc"}, stage.Options["add_drivers"])
+			case "org.osbuild.qemu":
+				assert.Equal(t, map[string]interface{}{"type": "vpc", "subformat": "fixed", "force_size": true}, stage.Options["format"])
+			case "org.osbuild.xz":
+				assert.Equal(t, "disk.vhd.xz", stage.Options["filename"])
+			}
+		}
+	}
+	assert.Subset(t, stages, []string{
+		"os:org.osbuild.waagent.conf",
+		"os:org.osbuild.dracut",
+		"os:org.osbuild.rhsm",
+		"image:org.osbuild.grub2.inst",
+		"vpc:org.osbuild.qemu",
+		"xz:org.osbuild.xz",
+	})
+
+	// the dracut configuration is embedded in the manifest
+	assert.Len(t, parsed.Sources["org.osbuild.inline"]["items"], 1)
+
+	options.Subscription = &distro.SubscriptionImageOptions{Organization: 42, ActivationKey: "key"}
+	_, err = imgType.Manifest(nil, options, nil, packages, 0)
+	assert.EqualError(t, err, `image type "azure-rhui" gets its content from RHUI and cannot be registered with a subscription`)
+}
+
+func TestDistro_AzureSAP(t *testing.T) {
+	arch, err := rhel85.New().GetArch("x86_64")
+	require.NoError(t, err)
+	imgType, err := arch.GetImageType("azure-sap")
+	require.NoError(t, err)
+
+	packages := map[string][]rpmmd.PackageSpec{
+		"packages": {{Name: "kernel", Version: "4.18.0", Release: "305.el8", Arch: "x86_64"}},
+	}
+	manifest, err := imgType.Manifest(nil, distro.ImageOptions{Size: imgType.Size(0)}, nil, packages, 0)
+	require.NoError(t, err)
+
+	var parsed struct {
+		Pipelines []struct {
+			Name   string
+			Stages []struct {
+				Type    string
+				Options map[string]interface{}
+			}
+		}
+		Sources map[string]map[string]map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal(manifest, &parsed))
+
+	var stages []string
+	for _, pipeline := range parsed.Pipelines {
+		for _, stage := range pipeline.Stages {
+			stages = append(stages, pipeline.Name+":"+stage.Type)
+			switch stage.Type {
+			case "org.osbuild.tuned":
+				assert.Equal(t, []interface{}{"sap-hana"}, stage.Options["profiles"])
+			case "org.osbuild.grub2":
+				assert.Contains(t, stage.Options["kernel_opts"], distro.SAPKernelOptions)
+			case "org.osbuild.systemd":
+				assert.Contains(t, stage.Options["enabled_services"], "uuidd.socket")
+			}
+		}
+	}
+	assert.Subset(t, stages, []string{
+		"os:org.osbuild.waagent.conf",
+		"os:org.osbuild.tuned",
+		"xz:org.osbuild.xz",
+	})
+
+	// the dracut and SAP configuration files are embedded in the manifest
+	assert.Len(t, parsed.Sources["org.osbuild.inline"]["items"], 4)
+
+	pkgs := imgType.PackageSets(blueprint.Blueprint{})["packages"]
+	assert.Subset(t, pkgs.Include, []string{"uuidd", "tuned-profiles-sap-hana", "rhui-azure-rhel8"})
+}
+
 func TestRhel85_KernelOption(t *testing.T) {
 	distro_test_common.TestDistro_KernelOption(t, rhel85.New())
 }
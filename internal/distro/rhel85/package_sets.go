@@ -61,6 +61,130 @@ func installerBuildPackageSet() rpmmd.PackageSet {
 	}
 }
 
+func liveISOPackageSet() rpmmd.PackageSet {
+	return rpmmd.PackageSet{
+		Include: []string{
+			"@core", "kernel", "dracut-config-generic", "dracut-live",
+			"grub2-efi-ia32-cdboot", "grub2-efi-x64-cdboot", "grub2-tools",
+			"policycoreutils", "selinux-policy-targeted", "shim-ia32",
+			"shim-x64", "syslinux", "syslinux-nonlinux",
+		},
+		Exclude: []string{"rng-tools"},
+	}
+}
+
+func rawPackageSet() rpmmd.PackageSet {
+	return rpmmd.PackageSet{
+		Include: []string{
+			"@core", "kernel", "chrony", "policycoreutils",
+			"selinux-policy-targeted",
+		},
+		Exclude: []string{"dracut-config-rescue", "rng-tools"},
+	}
+}
+
+// minimal image for single board computers, which initial-setup configures
+// on the first boot
+func minimalRawPackageSet() rpmmd.PackageSet {
+	return rpmmd.PackageSet{
+		Include: []string{
+			"@core", "kernel", "dracut-config-generic", "initial-setup",
+			"NetworkManager-wifi", "iwl7260-firmware",
+			"iwl3160-firmware", "policycoreutils", "selinux-policy-targeted",
+		},
+		Exclude: []string{"dracut-config-rescue", "rng-tools"},
+	}
+}
+
+func gcePackageSet() rpmmd.PackageSet {
+	return rpmmd.PackageSet{
+		Include: []string{
+			"@core", "langpacks-en", "acpid", "dhcp-client", "dnf-automatic",
+			"net-tools", "python3", "rng-tools", "tar", "vim", "timedatex",
+			"tuned", "google-compute-engine", "google-osconfig-agent",
+			"gce-disk-expand", "chrony", "kernel", "selinux-policy-targeted",
+		},
+		Exclude: []string{
+			"alsa-utils", "b43-fwcutter", "dmraid", "eject", "gpm",
+			"irqbalance", "microcode_ctl", "smartmontools", "aic94xx-firmware",
+			"atmel-firmware", "b43-openfwwf", "bfa-firmware",
+			"ipw2100-firmware", "ipw2200-firmware", "ivtv-firmware",
+			"iwl100-firmware", "iwl1000-firmware", "iwl3945-firmware",
+			"iwl4965-firmware", "iwl5000-firmware", "iwl5150-firmware",
+			"iwl6000-firmware", "iwl6050-firmware", "kernel-firmware",
+			"libertas-usb8388-firmware", "ql2100-firmware", "ql2200-firmware",
+			"ql23xx-firmware", "ql2400-firmware", "ql2500-firmware",
+			"rt61pci-firmware", "rt73usb-firmware", "xorg-x11-drv-ati-firmware",
+			"zd1211-firmware", "dracut-config-rescue", "firewalld",
+		},
+	}
+}
+
+func gceRHUIPackageSet() rpmmd.PackageSet {
+	return gcePackageSet().Append(rpmmd.PackageSet{
+		Include: []string{"google-rhui-client-rhel8"},
+	})
+}
+
+func azureRHUIPackageSet() rpmmd.PackageSet {
+	return rpmmd.PackageSet{
+		Include: []string{
+			"@Server", "NetworkManager", "NetworkManager-cloud-setup",
+			"WALinuxAgent", "bzip2", "chrony", "cloud-init",
+			"cloud-utils-growpart", "cryptsetup-reencrypt", "dracut-config-generic",
+			"dracut-norescue", "efibootmgr", "gdisk", "hyperv-daemons", "kernel",
+			"kernel-core", "kernel-modules", "kexec-tools", "lvm2", "nfs-utils",
+			"patch", "rhui-azure-rhel8", "rpm-plugin-systemd-inhibit",
+			"selinux-policy-targeted", "uuid", "yum-utils",
+		},
+		Exclude: []string{
+			"NetworkManager-config-server", "aic94xx-firmware", "alsa-firmware",
+			"alsa-lib", "alsa-sof-firmware", "alsa-tools-firmware", "biosdevname",
+			"bolt", "buildah", "cockpit-podman", "containernetworking-plugins",
+			"dnf-plugin-spacewalk", "dracut-config-rescue", "glibc-all-langpacks",
+			"iprutils", "ivtv-firmware", "iwl100-firmware", "iwl1000-firmware",
+			"iwl105-firmware", "iwl135-firmware", "iwl2000-firmware",
+			"iwl2030-firmware", "iwl3160-firmware", "iwl3945-firmware",
+			"iwl4965-firmware", "iwl5000-firmware", "iwl5150-firmware",
+			"iwl6000-firmware", "iwl6000g2a-firmware", "iwl6000g2b-firmware",
+			"iwl6050-firmware", "iwl7260-firmware", "libertas-sd8686-firmware",
+			"libertas-sd8787-firmware", "libertas-usb8388-firmware", "plymouth",
+			"podman", "python3-dnf-plugin-spacewalk", "python3-hwdata",
+			"python3-rhnlib", "rhn-check", "rhn-client-tools", "rhn-setup",
+			"rhnlib", "rhnsd", "rng-tools", "usb_modeswitch",
+		},
+	}
+}
+
+// packages which SAP HANA and the SAP NetWeaver applications need, as listed
+// in SAP notes 2772999 and 2777782
+func sapPackageSet() rpmmd.PackageSet {
+	return rpmmd.PackageSet{
+		Include: []string{
+			// SAP System Roles
+			"ansible", "rhel-system-roles-sap",
+			// SAP note 2772999
+			"compat-sap-c++-9", "libatomic", "libtool-ltdl", "tuned-profiles-sap-hana",
+			"uuidd",
+			// SAP note 2777782
+			"bind-utils", "cairo", "expect", "graphviz", "gtk2", "iptraf-ng",
+			"krb5-workstation", "libaio", "libicu", "lm_sensors", "net-tools",
+			"nfs-utils", "numactl", "PackageKit-gtk3-module", "tcsh", "tuned",
+			"xorg-x11-xauth",
+		},
+	}
+}
+
+func vagrantPackageSet() rpmmd.PackageSet {
+	return rpmmd.PackageSet{
+		Include: []string{
+			"@core", "kernel", "chrony", "openssh-server", "policycoreutils",
+			"rsync", "selinux-policy-targeted", "sudo",
+		},
+		Exclude: []string{"dracut-config-rescue", "rng-tools"},
+	}
+}
+
 // BOOT PACKAGE SETS
 
 func x8664BootPackageSet() rpmmd.PackageSet {
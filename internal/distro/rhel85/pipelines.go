@@ -3,9 +3,12 @@ package rhel85
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/disk"
 	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/fsnode"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild2"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 )
@@ -14,7 +17,7 @@ func tarPipelines(t *imageType, customizations *blueprint.Customizations, option
 	pipelines := make([]osbuild.Pipeline, 0)
 	pipelines = append(pipelines, *buildPipeline(repos, packageSetSpecs["build"]))
 
-	treePipeline, err := osPipeline(repos, packageSetSpecs["packages"], customizations, options, t.enabledServices, t.disabledServices, t.defaultTarget)
+	treePipeline, err := osPipeline(repos, packageSetSpecs["packages"], customizations, options, t.enabledServices, t.disabledServices, t.defaultTarget, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -23,7 +26,7 @@ func tarPipelines(t *imageType, customizations *blueprint.Customizations, option
 		Name:  "root-tar",
 		Build: "name:build",
 	}
-	tarPipeline.AddStage(tarStage("os", "root.tar.xz"))
+	tarPipeline.AddStage(tarStage("os", t.Filename()))
 	pipelines = append(pipelines, tarPipeline)
 	return pipelines, nil
 }
@@ -44,17 +47,70 @@ func edgeInstallerPipelines(t *imageType, customizations *blueprint.Customizatio
 	}
 	kernelVer := fmt.Sprintf("%s-%s.%s", kernelPkg.Version, kernelPkg.Release, kernelPkg.Arch)
 	ostreeRepoPath := "/ostree/repo"
-	pipelines = append(pipelines, *anacondaTreePipeline(repos, installerPackages, kernelVer, t.Arch().Name(), ostreePayloadStages(options, ostreeRepoPath)))
-	pipelines = append(pipelines, *bootISOTreePipeline(kernelVer, t.Arch().Name(), ostreeKickstartStageOptions(fmt.Sprintf("file://%s", ostreeRepoPath), options.OSTree.Ref)))
+	payloadStages := append(ostreePayloadStages(options, ostreeRepoPath), fsnode.Stages(fdoFiles(customizations.GetFDO()), nil)...)
+	pipelines = append(pipelines, *anacondaTreePipeline(repos, installerPackages, kernelVer, t.Arch().Name(), payloadStages))
+	isoStages := fsnode.Stages(ignitionFiles(customizations.GetIgnition()), nil)
+	kernelOpts := append([]string{kickstartKernelOpt(t.Arch().Name())}, installerKernelOpts(customizations.GetFDO(), customizations.GetIgnition())...)
+	pipelines = append(pipelines, *bootISOTreePipeline(kernelVer, t.Arch().Name(), "anaconda-tree", kernelOpts, ostreeKickstartStageOptions(fmt.Sprintf("file://%s", ostreeRepoPath), options.OSTree.Ref), isoStages))
 	pipelines = append(pipelines, *bootISOPipeline(t.Filename(), t.Arch().Name()))
 	return pipelines, nil
 }
 
-func tarInstallerPipelines(t *imageType, customizations *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSetSpecs map[string][]rpmmd.PackageSpec, rng *rand.Rand) ([]osbuild.Pipeline, error) {
+// The simplified installer writes the compressed raw image of an ostree
+// deployment of the commit to the installation device with coreos-installer,
+// which runs in its initramfs.
+func edgeSimplifiedInstallerPipelines(t *imageType, customizations *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSetSpecs map[string][]rpmmd.PackageSpec, rng *rand.Rand) ([]osbuild.Pipeline, error) {
 	pipelines := make([]osbuild.Pipeline, 0)
 	pipelines = append(pipelines, *buildPipeline(repos, packageSetSpecs["build"]))
 
-	treePipeline, err := osPipeline(repos, packageSetSpecs["packages"], customizations, options, t.enabledServices, t.disabledServices, t.defaultTarget)
+	installerPackages := packageSetSpecs["installer"]
+	kernelVer, err := kernelVersion(&blueprint.KernelCustomization{Name: "kernel"}, installerPackages)
+	if err != nil {
+		return nil, fmt.Errorf("kernel package not found in installer package set")
+	}
+
+	pt, err := disk.CreatePartitionTable(nil, options.Size, t.partitionTableGenerator(options, t.arch, rng), rng)
+	if err != nil {
+		return nil, err
+	}
+	pipelines = append(pipelines, *ostreeDeploymentPipeline(t, &pt, customizations.GetIgnition(), options))
+
+	rawfile := "image.raw"
+	pipelines = append(pipelines, *diskImagePipeline(&pt, "image-tree", rawfile, t.arch.legacy))
+
+	xzfile := rawfile + ".xz"
+	xzPipeline := osbuild.Pipeline{
+		Name:  "xz",
+		Build: "name:build",
+	}
+	xzPipeline.AddStage(osbuild.NewXzStage(&osbuild.XzStageOptions{
+		Filename: xzfile,
+		Level:    options.CompressionLevel,
+	}, osbuild.NewXzStageInputs("image", rawfile)))
+	pipelines = append(pipelines, xzPipeline)
+
+	pipelines = append(pipelines, *coiTreePipeline(repos, installerPackages, kernelVer, customizations.GetFDO()))
+
+	isoStages := []*osbuild.Stage{osbuild.NewCopyStageWithMounts(&osbuild.CopyStageOptions{
+		Paths: []osbuild.CopyStagePath{{From: "input://xz/" + xzfile, To: "tree:///" + xzfile}},
+	}, &osbuild.CopyStageTreeInputs{"xz": osbuild.NewTreeInputRef("xz")}, nil, nil)}
+	kernelOpts := append([]string{
+		"coreos.inst.install_dev=" + customizations.GetInstallationDevice(),
+		"coreos.inst.image_file=/run/media/iso/" + xzfile,
+		"coreos.inst.insecure",
+	}, installerKernelOpts(customizations.GetFDO(), nil)...)
+	pipelines = append(pipelines, *bootISOTreePipeline(kernelVer, t.Arch().Name(), "coi-tree", kernelOpts, nil, isoStages))
+	pipelines = append(pipelines, *bootISOPipeline(t.Filename(), t.Arch().Name()))
+	return pipelines, nil
+}
+
+func imageInstallerPipelines(t *imageType, customizations *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSetSpecs map[string][]rpmmd.PackageSpec, rng *rand.Rand) ([]osbuild.Pipeline, error) {
+	pipelines := make([]osbuild.Pipeline, 0)
+	pipelines = append(pipelines, *buildPipeline(repos, packageSetSpecs["build"]))
+
+	// the installer creates the users and groups from the kickstart file
+	treeCustomizations := withoutUsers(customizations)
+	treePipeline, err := osPipeline(repos, packageSetSpecs["packages"], treeCustomizations, options, t.enabledServices, t.disabledServices, t.defaultTarget, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +132,11 @@ func tarInstallerPipelines(t *imageType, customizations *blueprint.Customization
 	tarPath := "/liveimg.tar"
 	tarPayloadStages := []*osbuild.Stage{tarStage("os", tarPath)}
 	pipelines = append(pipelines, *anacondaTreePipeline(repos, installerPackages, kernelVer, t.Arch().Name(), tarPayloadStages))
-	pipelines = append(pipelines, *bootISOTreePipeline(kernelVer, t.Arch().Name(), tarKickstartStageOptions(fmt.Sprintf("file://%s", tarPath))))
+	ksOptions, err := tarKickstartStageOptions(fmt.Sprintf("file://%s", tarPath), customizations.GetUsers(), customizations.GetGroups())
+	if err != nil {
+		return nil, err
+	}
+	pipelines = append(pipelines, *bootISOTreePipeline(kernelVer, t.Arch().Name(), "anaconda-tree", []string{kickstartKernelOpt(t.Arch().Name())}, ksOptions, nil))
 	pipelines = append(pipelines, *bootISOPipeline(t.Filename(), t.Arch().Name()))
 	return pipelines, nil
 }
@@ -120,6 +180,217 @@ func edgeContainerPipelines(t *imageType, customizations *blueprint.Customizatio
 	return pipelines, nil
 }
 
+func liveISOPipelines(t *imageType, customizations *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSetSpecs map[string][]rpmmd.PackageSpec, rng *rand.Rand) ([]osbuild.Pipeline, error) {
+	pipelines := make([]osbuild.Pipeline, 0)
+	pipelines = append(pipelines, *buildPipeline(repos, packageSetSpecs["build"]))
+
+	var kernelPkg *rpmmd.PackageSpec
+	for _, pkg := range packageSetSpecs["packages"] {
+		if pkg.Name == "kernel" {
+			kernelPkg = &pkg
+			break
+		}
+	}
+	if kernelPkg == nil {
+		return nil, fmt.Errorf("kernel package not found in package set")
+	}
+	kernelVer := fmt.Sprintf("%s-%s.%s", kernelPkg.Version, kernelPkg.Release, kernelPkg.Arch)
+
+	treePipeline, err := osPipeline(repos, packageSetSpecs["packages"], customizations, options, t.enabledServices, t.disabledServices, t.defaultTarget, nil)
+	if err != nil {
+		return nil, err
+	}
+	treePipeline.Build = "name:build"
+	treePipeline.AddStage(osbuild.NewDracutStage(liveDracutStageOptions(kernelVer)))
+	pipelines = append(pipelines, *treePipeline)
+
+	kernelOpts := liveKernelOpts(t.Arch().Name())
+	if bpKernel := customizations.GetKernel(); bpKernel.Append != "" {
+		kernelOpts = append(kernelOpts, bpKernel.Append)
+	}
+	pipelines = append(pipelines, *bootISOTreePipeline(kernelVer, t.Arch().Name(), "os", kernelOpts, nil, nil))
+	pipelines = append(pipelines, *bootISOPipeline(t.Filename(), t.Arch().Name()))
+	return pipelines, nil
+}
+
+func vagrantPipelines(t *imageType, customizations *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSetSpecs map[string][]rpmmd.PackageSpec, rng *rand.Rand) ([]osbuild.Pipeline, error) {
+	pipelines := make([]osbuild.Pipeline, 0)
+	pipelines = append(pipelines, *buildPipeline(repos, packageSetSpecs["build"]))
+
+	pt, err := disk.CreatePartitionTable(customizations.GetFilesystems(), options.Size, t.partitionTableGenerator(options, t.arch, rng), rng)
+	if err != nil {
+		return nil, err
+	}
+
+	bootStages, err := bootloaderStages(t, &pt, customizations, packageSetSpecs["packages"])
+	if err != nil {
+		return nil, err
+	}
+	treePipeline, err := osPipeline(repos, packageSetSpecs["packages"], customizations, options, t.enabledServices, t.disabledServices, t.defaultTarget, bootStages)
+	if err != nil {
+		return nil, err
+	}
+	pipelines = append(pipelines, *treePipeline)
+
+	diskfile := "disk.img"
+	pipelines = append(pipelines, *diskImagePipeline(&pt, "os", diskfile, t.arch.legacy))
+
+	provider := strings.TrimPrefix(t.name, "vagrant-")
+	boxPipeline := osbuild.Pipeline{
+		Name:  "vagrant",
+		Build: "name:build",
+	}
+	boxPipeline.AddStage(qemuStage(provider, diskfile))
+	boxPipeline.Stages = append(boxPipeline.Stages, fsnode.Stages(distro.VagrantBoxFiles(provider, options.Size), nil)...)
+	pipelines = append(pipelines, boxPipeline)
+
+	archivePipeline := osbuild.Pipeline{
+		Name:  "archive",
+		Build: "name:build",
+	}
+	archivePipeline.AddStage(tarStage("vagrant", t.Filename()))
+	pipelines = append(pipelines, archivePipeline)
+	return pipelines, nil
+}
+
+func rawXzPipelines(t *imageType, customizations *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSetSpecs map[string][]rpmmd.PackageSpec, rng *rand.Rand) ([]osbuild.Pipeline, error) {
+	pipelines := make([]osbuild.Pipeline, 0)
+	pipelines = append(pipelines, *buildPipeline(repos, packageSetSpecs["build"]))
+
+	pt, err := disk.CreatePartitionTable(customizations.GetFilesystems(), options.Size, t.partitionTableGenerator(options, t.arch, rng), rng)
+	if err != nil {
+		return nil, err
+	}
+
+	bootStages, err := bootloaderStages(t, &pt, customizations, packageSetSpecs["packages"])
+	if err != nil {
+		return nil, err
+	}
+	treePipeline, err := osPipeline(repos, packageSetSpecs["packages"], customizations, options, t.enabledServices, t.disabledServices, t.defaultTarget, bootStages)
+	if err != nil {
+		return nil, err
+	}
+	pipelines = append(pipelines, *treePipeline)
+
+	diskfile := "disk.img"
+	pipelines = append(pipelines, *diskImagePipeline(&pt, "os", diskfile, t.arch.legacy))
+
+	xzPipeline := osbuild.Pipeline{
+		Name:  "xz",
+		Build: "name:build",
+	}
+	xzPipeline.AddStage(osbuild.NewXzStage(&osbuild.XzStageOptions{
+		Filename: t.Filename(),
+		Level:    options.CompressionLevel,
+	}, osbuild.NewXzStageInputs("image", diskfile)))
+	pipelines = append(pipelines, xzPipeline)
+	return pipelines, nil
+}
+
+func gcePipelines(t *imageType, customizations *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSetSpecs map[string][]rpmmd.PackageSpec, rng *rand.Rand) ([]osbuild.Pipeline, error) {
+	pipelines := make([]osbuild.Pipeline, 0)
+	pipelines = append(pipelines, *buildPipeline(repos, packageSetSpecs["build"]))
+
+	pt, err := disk.CreatePartitionTable(customizations.GetFilesystems(), options.Size, t.partitionTableGenerator(options, t.arch, rng), rng)
+	if err != nil {
+		return nil, err
+	}
+
+	imageStages, err := bootloaderStages(t, &pt, customizations, packageSetSpecs["packages"])
+	if err != nil {
+		return nil, err
+	}
+	if t.rhui {
+		imageStages = append(imageStages, osbuild.NewRHSMStage(rhuiRHSMStageOptions()))
+	}
+	treePipeline, err := osPipeline(repos, packageSetSpecs["packages"], customizations, options, t.enabledServices, t.disabledServices, t.defaultTarget, imageStages)
+	if err != nil {
+		return nil, err
+	}
+	pipelines = append(pipelines, *treePipeline)
+
+	pipelines = append(pipelines, *diskImagePipeline(&pt, "os", distro.GCEDiskFile, t.arch.legacy))
+
+	// Compute Engine only imports gzip compressed tarballs in the oldgnu
+	// format, which contain nothing but the disk image
+	archive := tarStage("image", t.Filename())
+	archiveOptions := archive.Options.(*osbuild.TarStageOptions)
+	archiveOptions.Format = "oldgnu"
+	archiveOptions.RootNode = "omit"
+	archivePipeline := osbuild.Pipeline{
+		Name:  "archive",
+		Build: "name:build",
+	}
+	archivePipeline.AddStage(archive)
+	pipelines = append(pipelines, archivePipeline)
+	return pipelines, nil
+}
+
+func azureRHUIPipelines(t *imageType, customizations *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSetSpecs map[string][]rpmmd.PackageSpec, rng *rand.Rand) ([]osbuild.Pipeline, error) {
+	pipelines := make([]osbuild.Pipeline, 0)
+	pipelines = append(pipelines, *buildPipeline(repos, packageSetSpecs["build"]))
+
+	pt, err := disk.CreatePartitionTable(customizations.GetFilesystems(), options.Size, t.partitionTableGenerator(options, t.arch, rng), rng)
+	if err != nil {
+		return nil, err
+	}
+
+	imageStages, err := bootloaderStages(t, &pt, customizations, packageSetSpecs["packages"])
+	if err != nil {
+		return nil, err
+	}
+	kernelVer, err := kernelVersion(customizations.GetKernel(), packageSetSpecs["packages"])
+	if err != nil {
+		return nil, err
+	}
+	imageStages = append(imageStages,
+		osbuild.NewWAAgentConfStage(waagentConfStageOptions()),
+		// the kernel's initramfs was created before dracut was configured
+		// to include the Hyper-V drivers
+		osbuild.NewDracutStage(&osbuild.DracutStageOptions{
+			Kernel:     []string{kernelVer},
+			AddDrivers: distro.AzureHyperVDrivers,
+		}),
+		osbuild.NewRHSMStage(rhuiRHSMStageOptions()),
+	)
+	if t.sap {
+		imageStages = append(imageStages, osbuild.NewTunedStage(&osbuild.TunedStageOptions{
+			Profiles: []string{distro.SAPTunedProfile},
+		}))
+	}
+	treePipeline, err := osPipeline(repos, packageSetSpecs["packages"], customizations, options, t.enabledServices, t.disabledServices, t.defaultTarget, imageStages)
+	if err != nil {
+		return nil, err
+	}
+	pipelines = append(pipelines, *treePipeline)
+
+	diskfile := "disk.img"
+	pipelines = append(pipelines, *diskImagePipeline(&pt, "os", diskfile, t.arch.legacy))
+
+	vhdfile := "disk.vhd"
+	forceSize := true
+	vpcPipeline := osbuild.Pipeline{
+		Name:  "vpc",
+		Build: "name:build",
+	}
+	vpcPipeline.AddStage(osbuild.NewQEMUStage(&osbuild.QEMUStageOptions{
+		Filename: vhdfile,
+		Format:   osbuild.QEMUFormat{Type: "vpc", Subformat: "fixed", ForceSize: &forceSize},
+	}, osbuild.NewQEMUStageInputs("image", diskfile)))
+	pipelines = append(pipelines, vpcPipeline)
+
+	xzPipeline := osbuild.Pipeline{
+		Name:  "xz",
+		Build: "name:build",
+	}
+	xzPipeline.AddStage(osbuild.NewXzStage(&osbuild.XzStageOptions{
+		Filename: t.Filename(),
+		Level:    options.CompressionLevel,
+	}, osbuild.NewXzStageInputs("vpc", vhdfile)))
+	pipelines = append(pipelines, xzPipeline)
+	return pipelines, nil
+}
+
 func buildPipeline(repos []rpmmd.RepoConfig, buildPackageSpecs []rpmmd.PackageSpec) *osbuild.Pipeline {
 	p := new(osbuild.Pipeline)
 	p.Name = "build"
@@ -129,7 +400,11 @@ func buildPipeline(repos []rpmmd.RepoConfig, buildPackageSpecs []rpmmd.PackageSp
 	return p
 }
 
-func coreStages(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, c *blueprint.Customizations, options distro.ImageOptions, enabledServices, disabledServices []string, defaultTarget string) ([]*osbuild.Stage, error) {
+// coreStages returns the stages which install and configure the tree.
+// `imageStages` are specific to the image type, like the boot loader
+// configuration of bootable images, and run before the tree is labelled for
+// SELinux.
+func coreStages(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, c *blueprint.Customizations, options distro.ImageOptions, enabledServices, disabledServices []string, defaultTarget string, imageStages []*osbuild.Stage) ([]*osbuild.Stage, error) {
 	stages := make([]*osbuild.Stage, 0)
 	stages = append(stages, osbuild.NewRPMStage(rpmStageOptions(repos), rpmStageInputs(packages)))
 	stages = append(stages, osbuild.NewFixBLSStage())
@@ -170,6 +445,9 @@ func coreStages(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, c *bluep
 		stages = append(stages, osbuild.NewFirstBootStage(usersFirstBootOptions(options)))
 	}
 
+	// units need to exist before they can be enabled
+	stages = append(stages, fsnode.Stages(fsnode.UnitFiles(c.GetServices()), nil)...)
+
 	if services := c.GetServices(); services != nil || enabledServices != nil || disabledServices != nil || defaultTarget != "" {
 		stages = append(stages, osbuild.NewSystemdStage(systemdStageOptions(enabledServices, disabledServices, services, defaultTarget)))
 	}
@@ -177,13 +455,16 @@ func coreStages(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, c *bluep
 	if firewall := c.GetFirewall(); firewall != nil {
 		stages = append(stages, osbuild.NewFirewallStage(firewallStageOptions(firewall)))
 	}
+
+	stages = append(stages, fsnode.Stages(fsnode.Files(c, options.ModuleStreams), c.GetDirectories())...)
+	stages = append(stages, imageStages...)
 	stages = append(stages, osbuild.NewSELinuxStage(selinuxStageOptions(false)))
 
 	// These are the current defaults for the sysconfig stage. This can be changed to be image type exclusive if different configs are needed.
 	stages = append(stages, osbuild.NewSysconfigStage(&osbuild.SysconfigStageOptions{
 		Kernel: osbuild.SysconfigKernelOptions{
 			UpdateDefault: true,
-			DefaultKernel: "kernel",
+			DefaultKernel: c.GetKernel().Name,
 		},
 		Network: osbuild.SysconfigNetworkOptions{
 			Networking: true,
@@ -209,10 +490,10 @@ func coreStages(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, c *bluep
 	return stages, nil
 }
 
-func osPipeline(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, c *blueprint.Customizations, options distro.ImageOptions, enabledServices, disabledServices []string, defaultTarget string) (*osbuild.Pipeline, error) {
+func osPipeline(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, c *blueprint.Customizations, options distro.ImageOptions, enabledServices, disabledServices []string, defaultTarget string, imageStages []*osbuild.Stage) (*osbuild.Pipeline, error) {
 	p := new(osbuild.Pipeline)
 	p.Name = "os"
-	stages, err := coreStages(repos, packages, c, options, enabledServices, disabledServices, defaultTarget)
+	stages, err := coreStages(repos, packages, c, options, enabledServices, disabledServices, defaultTarget, imageStages)
 	if err != nil {
 		return nil, err
 	}
@@ -226,7 +507,7 @@ func ostreeTreePipeline(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec,
 	p.Name = "ostree-tree"
 	p.Build = "name:build"
 
-	stages, err := coreStages(repos, packages, c, options, enabledServices, disabledServices, defaultTarget)
+	stages, err := coreStages(repos, packages, c, options, enabledServices, disabledServices, defaultTarget, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -270,6 +551,58 @@ func tarStage(source, filename string) *osbuild.Stage {
 	return osbuild.NewTarStage(&osbuild.TarStageOptions{Filename: filename}, &osbuild.TarStageInputs{Tree: tree})
 }
 
+// bootloaderStages returns the stages which configure the boot loaders of
+// bootable image types for the partition table `pt`, which run in the os
+// pipeline.
+func bootloaderStages(t *imageType, pt *disk.PartitionTable, c *blueprint.Customizations, packages []rpmmd.PackageSpec) ([]*osbuild.Stage, error) {
+	stages := []*osbuild.Stage{
+		osbuild.NewFSTabStage(pt.FSTabStageOptionsV2()),
+		osbuild.NewGRUB2Stage(grub2StageOptions(pt, t.kernelOptions, c.GetKernel(), packages, t.arch.uefi, t.arch.legacy)),
+	}
+
+	if t.arch.extlinux {
+		files, err := t.extlinuxFiles(c, packages)
+		if err != nil {
+			return nil, err
+		}
+		stages = append(stages, fsnode.Stages(files, extlinuxDirectories)...)
+	}
+
+	return stages, nil
+}
+
+// diskImagePipeline returns the pipeline which creates the raw image
+// `filename` with the partition table `pt` from the tree of the pipeline
+// `tree`, and installs the legacy boot loader for `legacy`, unless it's empty.
+func diskImagePipeline(pt *disk.PartitionTable, tree, filename, legacy string) *osbuild.Pipeline {
+	p := new(osbuild.Pipeline)
+	p.Name = "image"
+	p.Build = "name:build"
+	p.Stages = pt.ImageStages(filename, tree)
+	if legacy != "" {
+		if options := pt.Grub2InstStageOptions(filename, legacy); options != nil {
+			p.AddStage(osbuild.NewGrub2InstStage(options))
+		}
+	}
+	return p
+}
+
+// qemuStage converts the raw image `filename` of the image pipeline to the
+// disk of a Vagrant box for `provider`.
+func qemuStage(provider, filename string) *osbuild.Stage {
+	options := &osbuild.QEMUStageOptions{
+		Filename: distro.VagrantLibvirtDisk,
+		Format:   osbuild.QEMUFormat{Type: "qcow2", Compat: "1.1"},
+	}
+	if provider == "virtualbox" {
+		options = &osbuild.QEMUStageOptions{
+			Filename: distro.VagrantVirtualBoxDisk,
+			Format:   osbuild.QEMUFormat{Type: "vmdk", Subformat: "streamOptimized"},
+		}
+	}
+	return osbuild.NewQEMUStage(options, osbuild.NewQEMUStageInputs("image", filename))
+}
+
 func containerTreePipeline(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, options distro.ImageOptions, c *blueprint.Customizations) *osbuild.Pipeline {
 	p := new(osbuild.Pipeline)
 	p.Name = "container-tree"
@@ -311,6 +644,117 @@ func containerPipeline(t *imageType) *osbuild.Pipeline {
 	return p
 }
 
+// Name of the operating system of ostree deployments and the remote from
+// which they are updated
+const (
+	ostreeOSName = "redhat"
+	ostreeRemote = "rhel-edge"
+)
+
+// ostreeDeploymentPipeline returns the pipeline of the physical root of a
+// system with an ostree deployment of the commit of `options`, which is
+// written to an image with the partition table `pt`. Ignition provisions the
+// system on its first boot if `ignition` is set.
+func ostreeDeploymentPipeline(t *imageType, pt *disk.PartitionTable, ignition *blueprint.IgnitionCustomization, options distro.ImageOptions) *osbuild.Pipeline {
+	p := new(osbuild.Pipeline)
+	p.Name = "image-tree"
+	p.Build = "name:build"
+
+	repoPath := "/ostree/repo"
+	deployment := osbuild.OSTreeDeployment{OSName: ostreeOSName, Ref: options.OSTree.Ref}
+	readOnly := true
+	kernelOpts := strings.Fields(t.kernelOptions)
+	if ignition != nil {
+		kernelOpts = append(kernelOpts, "ignition.platform.id=metal", "$ignition_firstboot")
+		if ignition.FirstBoot != nil {
+			kernelOpts = append(kernelOpts, "ignition.config.url="+ignition.FirstBoot.ProvisioningURL)
+		}
+	}
+
+	p.AddStage(osbuild.NewOSTreeInitFsStage())
+	p.AddStage(osbuild.NewOSTreePullStage(
+		&osbuild.OSTreePullStageOptions{Repo: repoPath},
+		ostreePullStageInputs("org.osbuild.source", options.OSTree.Parent, options.OSTree.Ref),
+	))
+	p.AddStage(osbuild.NewOSTreeOsInitStage(&osbuild.OSTreeOsInitStageOptions{OSName: ostreeOSName}))
+	p.AddStage(osbuild.NewOSTreeConfigStage(&osbuild.OSTreeConfigStageOptions{
+		Repo: repoPath,
+		Config: &osbuild.OSTreeConfig{
+			Sysroot: &osbuild.SysrootOptions{ReadOnly: &readOnly, Bootloader: "none"},
+		},
+	}))
+	p.AddStage(osbuild.NewMkdirStage(&osbuild.MkdirStageOptions{
+		Paths: []osbuild.MkdirStagePath{{Path: "/boot/efi", Mode: 0700}},
+	}))
+	p.AddStage(osbuild.NewOSTreeDeployStage(&osbuild.OSTreeDeployStageOptions{
+		OSName:     ostreeOSName,
+		Ref:        options.OSTree.Ref,
+		Remote:     ostreeRemote,
+		Mounts:     []string{"/boot/efi"},
+		Rootfs:     &osbuild.OSTreeRootfs{UUID: pt.RootFilesystem().UUID},
+		KernelOpts: kernelOpts,
+	}))
+	p.AddStage(osbuild.NewOSTreeRemotesStage(&osbuild.OSTreeRemotesStageOptions{
+		Repo:    repoPath,
+		Remotes: []osbuild.OSTreeRemotesStageRemote{{Name: ostreeRemote, URL: options.OSTree.URL}},
+	}))
+	p.AddStage(osbuild.NewOSTreeFillvarStage(&osbuild.OSTreeFillvarStageOptions{Deployment: deployment}))
+
+	fstabOptions := pt.FSTabStageOptionsV2()
+	fstabOptions.OSTree = &osbuild.FSTabOSTreeOptions{Deployment: deployment}
+	p.AddStage(osbuild.NewFSTabStage(fstabOptions))
+	p.AddStage(osbuild.NewOSTreeSelinuxStage(&osbuild.OSTreeSelinuxStageOptions{Deployment: deployment}))
+
+	if ignition != nil {
+		p.AddStage(osbuild.NewIgnitionStage(&osbuild.IgnitionStageOptions{}))
+		if ignition.Embedded != nil {
+			// ignition-edge copies the config from /boot into the
+			// initramfs on the first boot
+			p.Stages = append(p.Stages, fsnode.Stages(
+				[]blueprint.FileCustomization{{Path: "/boot/ignition/config.ign", Mode: "0600", Data: ignition.Embedded.Config}},
+				[]blueprint.DirectoryCustomization{{Path: "/boot/ignition", Mode: "0700"}},
+			)...)
+		}
+	}
+
+	// the boot loader reads the BLS entries of the deployments
+	grub2Options := grub2StageOptions(pt, "", nil, nil, t.arch.uefi, t.arch.legacy)
+	if grub2Options.UEFI != nil {
+		grub2Options.UEFI.Install = true
+	}
+	writeDefaults := false
+	grub2Options.WriteDefaults = &writeDefaults
+	grub2Options.Greenboot = true
+	grub2Options.Ignition = ignition != nil
+	p.AddStage(osbuild.NewGRUB2Stage(grub2Options))
+
+	return p
+}
+
+// coiTreePipeline returns the pipeline of the tree of the simplified
+// installer, whose initramfs contains coreos-installer and, if `fdo` is set,
+// the FDO client, which onboards the device once it is installed.
+func coiTreePipeline(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec, kernelVer string, fdo *blueprint.FDOCustomization) *osbuild.Pipeline {
+	p := new(osbuild.Pipeline)
+	p.Name = "coi-tree"
+	p.Build = "name:build"
+	p.AddStage(osbuild.NewRPMStage(rpmStageOptions(repos), rpmStageInputs(packages)))
+
+	dracutOptions := &osbuild.DracutStageOptions{
+		Kernel:     []string{kernelVer},
+		AddModules: []string{"coreos-installer"},
+	}
+	if fdo != nil {
+		dracutOptions.AddModules = append(dracutOptions.AddModules, "fdo")
+		if files := fdoFiles(fdo); len(files) > 0 {
+			p.Stages = append(p.Stages, fsnode.Stages(files, nil)...)
+			dracutOptions.Include = map[string]string{fdoRootCertsPath: fdoRootCertsPath}
+		}
+	}
+	p.AddStage(osbuild.NewDracutStage(dracutOptions))
+	return p
+}
+
 func ostreePayloadStages(options distro.ImageOptions, ostreeRepoPath string) []*osbuild.Stage {
 	stages := make([]*osbuild.Stage, 0)
 
@@ -367,14 +811,22 @@ func anacondaTreePipeline(repos []rpmmd.RepoConfig, packages []rpmmd.PackageSpec
 	return p
 }
 
-func bootISOTreePipeline(kernelVer string, arch string, ksOptions *osbuild.KickstartStageOptions) *osbuild.Pipeline {
+// Returns the pipeline of the tree of a boot ISO, whose root filesystem is
+// the tree of the pipeline `rootfs`. Live ISOs don't have a kickstart file,
+// so `ksOptions` may be nil.
+func bootISOTreePipeline(kernelVer string, arch string, rootfs string, kernelOpts []string, ksOptions *osbuild.KickstartStageOptions, isoStages []*osbuild.Stage) *osbuild.Pipeline {
 	p := new(osbuild.Pipeline)
 	p.Name = "bootiso-tree"
 	p.Build = "name:build"
 
-	p.AddStage(osbuild.NewBootISOMonoStage(bootISOMonoStageOptions(kernelVer, arch), bootISOMonoStageInputs()))
-	p.AddStage(osbuild.NewKickstartStage(ksOptions))
+	p.AddStage(osbuild.NewBootISOMonoStage(bootISOMonoStageOptions(kernelVer, arch, kernelOpts), bootISOMonoStageInputs(rootfs)))
+	if ksOptions != nil {
+		p.AddStage(osbuild.NewKickstartStage(ksOptions))
+	}
 	p.AddStage(osbuild.NewDiscinfoStage(discinfoStageOptions(arch)))
+	for _, stage := range isoStages {
+		p.AddStage(stage)
+	}
 
 	return p
 }
@@ -388,3 +840,100 @@ func bootISOPipeline(filename string, arch string) *osbuild.Pipeline {
 
 	return p
 }
+
+// Returns a copy of the customizations without users and groups.
+func withoutUsers(c *blueprint.Customizations) *blueprint.Customizations {
+	if c == nil {
+		return nil
+	}
+	tree := *c
+	tree.SSHKey, tree.User, tree.Group = nil, nil, nil
+	return &tree
+}
+
+// Paths of the files which FDO and Ignition customizations embed in edge
+// installers: the DIUN root certificates are put in the root filesystem of
+// the installer, the Ignition config on the ISO.
+const (
+	fdoRootCertsPath   = "/fdo_diun_pub_key_root_certs.pem"
+	ignitionConfigPath = "/ignition_config"
+)
+
+func fdoFiles(fdo *blueprint.FDOCustomization) []blueprint.FileCustomization {
+	if fdo == nil || fdo.DiunPubKeyRootCerts == "" {
+		return nil
+	}
+	return []blueprint.FileCustomization{{Path: fdoRootCertsPath, Mode: "0644", Data: fdo.DiunPubKeyRootCerts}}
+}
+
+func ignitionFiles(ignition *blueprint.IgnitionCustomization) []blueprint.FileCustomization {
+	if ignition == nil || ignition.Embedded == nil {
+		return nil
+	}
+	return []blueprint.FileCustomization{{Path: ignitionConfigPath, Mode: "0644", Data: ignition.Embedded.Config}}
+}
+
+// Returns the arguments which pass the FDO and Ignition customizations to
+// the installer's kernel.
+func installerKernelOpts(fdo *blueprint.FDOCustomization, ignition *blueprint.IgnitionCustomization) []string {
+	var opts []string
+	if fdo != nil {
+		opts = append(opts, "fdo.manufacturing_server_url="+fdo.ManufacturingServerURL)
+		switch {
+		case fdo.DiunPubKeyInsecure:
+			opts = append(opts, "fdo.diun_pub_key_insecure=true")
+		case fdo.DiunPubKeyHash != "":
+			opts = append(opts, "fdo.diun_pub_key_hash="+fdo.DiunPubKeyHash)
+		case fdo.DiunPubKeyRootCerts != "":
+			opts = append(opts, "fdo.diun_pub_key_root_certs="+fdoRootCertsPath)
+		}
+	}
+	if ignition != nil && ignition.FirstBoot != nil {
+		opts = append(opts, "ignition.firstboot", "ignition.platform.id=metal", "ignition.config.url="+ignition.FirstBoot.ProvisioningURL)
+	}
+	return opts
+}
+
+// The directory of the extlinux.conf which u-boot reads
+var extlinuxDirectories = []blueprint.DirectoryCustomization{{Path: "/boot/extlinux", Mode: "0755"}}
+
+// extlinuxFiles returns the extlinux.conf for the kernel of the image, which
+// u-boot uses to boot it on single board computers without UEFI firmware.
+// u-boot reads the configuration from the /boot partition, so the paths are
+// relative to it and the root filesystem is identified by its label.
+func (t *imageType) extlinuxFiles(c *blueprint.Customizations, packages []rpmmd.PackageSpec) ([]blueprint.FileCustomization, error) {
+	kernel := c.GetKernel()
+	kernelVer, err := kernelVersion(kernel, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	kernelOpts := t.kernelOptions
+	if kernel.Append != "" {
+		kernelOpts += " " + kernel.Append
+	}
+
+	conf := fmt.Sprintf(`default rhel
+timeout 30
+menu title Red Hat Enterprise Linux
+
+label rhel
+	kernel /vmlinuz-%[1]s
+	initrd /initramfs-%[1]s.img
+	fdtdir /dtb-%[1]s/
+	append root=LABEL=root %[2]s
+`, kernelVer, kernelOpts)
+
+	return []blueprint.FileCustomization{{Path: "/boot/extlinux/extlinux.conf", Mode: "0644", Data: conf}}, nil
+}
+
+// kernelVersion returns the version of the `kernel` package in `packages`,
+// as in the names of its files in /boot.
+func kernelVersion(kernel *blueprint.KernelCustomization, packages []rpmmd.PackageSpec) (string, error) {
+	for _, pkg := range packages {
+		if pkg.Name == kernel.Name {
+			return fmt.Sprintf("%s-%s.%s", pkg.Version, pkg.Release, pkg.Arch), nil
+		}
+	}
+	return "", fmt.Errorf("kernel package not found in package set")
+}
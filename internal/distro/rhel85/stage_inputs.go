@@ -5,11 +5,11 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 )
 
-func bootISOMonoStageInputs() *osbuild.BootISOMonoStageInputs {
+func bootISOMonoStageInputs(rootfs string) *osbuild.BootISOMonoStageInputs {
 	rootfsInput := new(osbuild.BootISOMonoStageInput)
 	rootfsInput.Type = "org.osbuild.tree"
 	rootfsInput.Origin = "org.osbuild.pipeline"
-	rootfsInput.References = osbuild.BootISOMonoStageReferences{"name:anaconda-tree"}
+	rootfsInput.References = osbuild.BootISOMonoStageReferences{"name:" + rootfs}
 	return &osbuild.BootISOMonoStageInputs{
 		RootFS: rootfsInput,
 	}
@@ -3,9 +3,13 @@ package rhel85
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
 
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
 	"github.com/osbuild/osbuild-composer/internal/crypt"
+	"github.com/osbuild/osbuild-composer/internal/disk"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild2"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 )
@@ -61,17 +65,31 @@ func userStageOptions(users []blueprint.UserCustomization) (*osbuild.UsersStageO
 			c.Password = &cryptedPassword
 		}
 
+		// a "!" in front of the hash disables password logins
+		if c.Locked {
+			lockedPassword := "!"
+			if c.Password != nil {
+				lockedPassword += *c.Password
+			}
+			c.Password = &lockedPassword
+		}
+
 		user := osbuild.UsersStageOptionsUser{
 			Groups:      c.Groups,
 			Description: c.Description,
 			Home:        c.Home,
 			Shell:       c.Shell,
 			Password:    c.Password,
-			Key:         c.Key,
+			Key:         c.AuthorizedKeys(),
+			ExpireDate:  c.ExpireDate,
 		}
 
 		user.UID = c.UID
 		user.GID = c.GID
+		if c.ForcePasswordReset {
+			forcePasswordReset := true
+			user.ForcePasswordReset = &forcePasswordReset
+		}
 
 		options.Users[c.Name] = user
 	}
@@ -87,7 +105,9 @@ func usersFirstBootOptions(usersStageOptions *osbuild.UsersStageOptions) *osbuil
 		if user.Key != nil {
 			sshdir := filepath.Join(varhome, name, ".ssh")
 			cmds = append(cmds, fmt.Sprintf("mkdir -p %s", sshdir))
-			cmds = append(cmds, fmt.Sprintf("sh -c 'echo %q >> %q'", *user.Key, filepath.Join(sshdir, "authorized_keys")))
+			for _, key := range strings.Split(*user.Key, "\n") {
+				cmds = append(cmds, fmt.Sprintf("sh -c 'echo %q >> %q'", key, filepath.Join(sshdir, "authorized_keys")))
+			}
 			cmds = append(cmds, fmt.Sprintf("chown %s:%s -Rc %s", name, name, sshdir))
 		}
 	}
@@ -127,21 +147,74 @@ func firewallStageOptions(firewall *blueprint.FirewallCustomization) *osbuild.Fi
 		options.DisabledServices = firewall.Services.Disabled
 	}
 
+	for _, zone := range firewall.Zones {
+		options.Zones = append(options.Zones, osbuild.FirewallZone{
+			Name:    zone.Name,
+			Sources: zone.Sources,
+		})
+	}
+
 	return &options
 }
 
 func systemdStageOptions(enabledServices, disabledServices []string, s *blueprint.ServicesCustomization, target string) *osbuild.SystemdStageOptions {
+	var maskedServices []string
 	if s != nil {
 		enabledServices = append(enabledServices, s.Enabled...)
 		disabledServices = append(disabledServices, s.Disabled...)
+		maskedServices = s.Masked
 	}
 	return &osbuild.SystemdStageOptions{
 		EnabledServices:  enabledServices,
 		DisabledServices: disabledServices,
+		MaskedServices:   maskedServices,
 		DefaultTarget:    target,
 	}
 }
 
+func grub2StageOptions(pt *disk.PartitionTable, kernelOptions string, kernel *blueprint.KernelCustomization, packages []rpmmd.PackageSpec, uefi bool, legacy string) *osbuild.GRUB2StageOptions {
+	if pt == nil {
+		panic("partition table must be defined for grub2 stage, this is a programming error")
+	}
+	rootFs := pt.RootFilesystem()
+	if rootFs == nil {
+		panic("root filesystem must be defined for grub2 stage, this is a programming error")
+	}
+
+	stageOptions := osbuild.GRUB2StageOptions{
+		RootFilesystemUUID: uuid.MustParse(rootFs.UUID),
+		KernelOptions:      kernelOptions,
+		Legacy:             legacy,
+	}
+
+	for _, fs := range pt.Filesystems() {
+		if fs.Mountpoint == "/boot" {
+			bootFsUUID := uuid.MustParse(fs.UUID)
+			stageOptions.BootFilesystemUUID = &bootFsUUID
+		}
+	}
+
+	if uefi {
+		stageOptions.UEFI = &osbuild.GRUB2UEFI{
+			Vendor: "redhat",
+		}
+	}
+
+	if kernel != nil {
+		if kernel.Append != "" {
+			stageOptions.KernelOptions += " " + kernel.Append
+		}
+		for _, pkg := range packages {
+			if pkg.Name == kernel.Name {
+				stageOptions.SavedEntry = "ffffffffffffffffffffffffffffffff-" + pkg.Version + "-" + pkg.Release + "." + pkg.Arch
+				break
+			}
+		}
+	}
+
+	return &stageOptions
+}
+
 func buildStampStageOptions(arch string) *osbuild.BuildstampStageOptions {
 	return &osbuild.BuildstampStageOptions{
 		Arch:    arch,
@@ -169,6 +242,15 @@ func loraxScriptStageOptions(arch string) *osbuild.LoraxScriptStageOptions {
 	}
 }
 
+// Returns the options of the dracut stage which creates the initramfs of a
+// live ISO, which boots the squashfs root filesystem of the ISO.
+func liveDracutStageOptions(kernelVer string) *osbuild.DracutStageOptions {
+	return &osbuild.DracutStageOptions{
+		Kernel:     []string{kernelVer},
+		AddModules: []string{"dmsquash-live", "livenet", "pollcdrom"},
+	}
+}
+
 func dracutStageOptions(kernelVer string) *osbuild.DracutStageOptions {
 	kernel := []string{kernelVer}
 	modules := []string{
@@ -229,13 +311,24 @@ func dracutStageOptions(kernelVer string) *osbuild.DracutStageOptions {
 	}
 }
 
-func tarKickstartStageOptions(tarURL string) *osbuild.KickstartStageOptions {
-	return &osbuild.KickstartStageOptions{
+func tarKickstartStageOptions(tarURL string, users []blueprint.UserCustomization, groups []blueprint.GroupCustomization) (*osbuild.KickstartStageOptions, error) {
+	options := &osbuild.KickstartStageOptions{
 		Path: kspath,
 		LiveIMG: &osbuild.LiveIMG{
 			URL: tarURL,
 		},
 	}
+	if len(users) > 0 {
+		usersOptions, err := userStageOptions(users)
+		if err != nil {
+			return nil, err
+		}
+		options.Users = usersOptions.Users
+	}
+	if len(groups) > 0 {
+		options.Groups = groupStageOptions(groups).Groups
+	}
+	return options, nil
 }
 
 func ostreeKickstartStageOptions(ostreeURL, ostreeRef string) *osbuild.KickstartStageOptions {
@@ -250,20 +343,35 @@ func ostreeKickstartStageOptions(ostreeURL, ostreeRef string) *osbuild.Kickstart
 	}
 }
 
-func bootISOMonoStageOptions(kernelVer string, arch string) *osbuild.BootISOMonoStageOptions {
+func isoLabel(arch string) string {
+	return fmt.Sprintf("RHEL-8-5-0-BaseOS-%s", arch)
+}
+
+// Returns the kernel option which makes Anaconda install from the kickstart
+// file on the ISO.
+func kickstartKernelOpt(arch string) string {
+	return fmt.Sprintf("inst.ks=hd:LABEL=%s:%s", isoLabel(arch), kspath)
+}
+
+// Returns the kernel options which boot the squashfs root filesystem of a
+// live ISO.
+func liveKernelOpts(arch string) []string {
+	return []string{fmt.Sprintf("root=live:CDLABEL=%s", isoLabel(arch)), "rd.live.image"}
+}
+
+func bootISOMonoStageOptions(kernelVer string, arch string, kernelOpts []string) *osbuild.BootISOMonoStageOptions {
 	comprOptions := new(osbuild.FSCompressionOptions)
 	if bcj := osbuild.BCJOption(arch); bcj != "" {
 		comprOptions.BCJ = bcj
 	}
-	isolabel := fmt.Sprintf("RHEL-8-5-0-BaseOS-%s", arch)
 	return &osbuild.BootISOMonoStageOptions{
 		Product: osbuild.Product{
 			Name:    "Red Hat Enterprise Linux",
 			Version: osVersion,
 		},
-		ISOLabel:   isolabel,
+		ISOLabel:   isoLabel(arch),
 		Kernel:     kernelVer,
-		KernelOpts: fmt.Sprintf("inst.ks=hd:LABEL=%s:%s", isolabel, kspath),
+		KernelOpts: strings.Join(kernelOpts, " "),
 		EFI: osbuild.EFI{
 			Architectures: []string{
 				"IA32",
@@ -296,7 +404,7 @@ func discinfoStageOptions(arch string) *osbuild.DiscinfoStageOptions {
 func xorrisofsStageOptions(filename string, arch string) *osbuild.XorrisofsStageOptions {
 	return &osbuild.XorrisofsStageOptions{
 		Filename: filename,
-		VolID:    fmt.Sprintf("RHEL-8-5-0-BaseOS-%s", arch),
+		VolID:    isoLabel(arch),
 		SysID:    "LINUX",
 		Boot: osbuild.XorrisofsBoot{
 			Image:   "isolinux/isolinux.bin",
@@ -306,3 +414,29 @@ func xorrisofsStageOptions(filename string, arch string) *osbuild.XorrisofsStage
 		IsohybridMBR: "/usr/share/syslinux/isohdpfx.bin",
 	}
 }
+
+// cloud-init provisions Azure instances and sets up the resource disk, so the
+// agent only reports their status
+func waagentConfStageOptions() *osbuild.WAAgentConfStageOptions {
+	yes, no := true, false
+	return &osbuild.WAAgentConfStageOptions{
+		Config: osbuild.WAAgentConfig{
+			Provisioning:             &no,
+			ProvisioningUseCloudInit: &yes,
+			ResourceDiskFormat:       &no,
+			ResourceDiskEnableSwap:   &no,
+		},
+	}
+}
+
+// RHUI images get their content from the cloud provider's RHUI, which is paid
+// for with the instance's license, so the subscription-manager dnf plugins
+// are disabled
+func rhuiRHSMStageOptions() *osbuild.RHSMStageOptions {
+	return &osbuild.RHSMStageOptions{
+		DnfPlugins: &osbuild.RHSMStageOptionsDnfPlugins{
+			ProductID:           &osbuild.RHSMStageOptionsDnfPlugin{Enabled: false},
+			SubscriptionManager: &osbuild.RHSMStageOptionsDnfPlugin{Enabled: false},
+		},
+	}
+}
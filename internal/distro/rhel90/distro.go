@@ -7,9 +7,11 @@ import (
 	"io"
 	"math/rand"
 	"sort"
+	"strings"
 
 	"github.com/osbuild/osbuild-composer/internal/disk"
 	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/fsnode"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
 
 	"github.com/google/uuid"
@@ -23,10 +25,16 @@ const defaultName = "rhel-90"
 const modulePlatformID = "platform:el9"
 const ostreeRef = "rhel/9/%s/edge"
 
+const defaultCentosName = "centos-9"
+const defaultCentos10Name = "centos-10"
+const centos10ModulePlatformID = "platform:el10"
+
 type distribution struct {
 	name             string
 	modulePlatformID string
 	ostreeRef        string
+	isCentos         bool
+	runner           string
 	arches           map[string]architecture
 	buildPackages    []string
 }
@@ -131,8 +139,9 @@ func (t *imageType) PackageSets(bp blueprint.Blueprint) map[string]rpmmd.Package
 	includePackages, excludePackages := t.Packages(bp)
 	return map[string]rpmmd.PackageSet{
 		"packages": {
-			Include: includePackages,
-			Exclude: excludePackages,
+			Include:       includePackages,
+			Exclude:       excludePackages,
+			EnableModules: bp.GetEnabledModules(),
 		},
 		"build-packages": {
 			Include: t.BuildPackages(),
@@ -210,6 +219,14 @@ func (t *imageType) Packages(bp blueprint.Blueprint) ([]string, []string) {
 	}
 	if t.bootable {
 		packages = append(packages, t.arch.bootloaderPackages...)
+	} else {
+		// the kernel, which blueprints include by default, is of no use
+		// in images which don't boot
+		packages = removePackage(packages, bp.Customizations.GetKernel().Name)
+	}
+
+	if t.arch.distro.isCentos {
+		packages = centosPackages(packages)
 	}
 
 	// copy the list of excluded packages from the image type
@@ -226,6 +243,29 @@ func (t *imageType) Packages(bp blueprint.Blueprint) ([]string, []string) {
 	return packages, excludedPackages
 }
 
+// CentOS Stream has its own release package and doesn't ship the packages
+// which integrate with Red Hat's subscription services. An empty
+// replacement drops the package.
+var centosReplacements = map[string]string{
+	"redhat-release":               "centos-stream-release",
+	"redhat-release-eula":          "",
+	"insights-client":              "",
+	"subscription-manager-cockpit": "",
+}
+
+func centosPackages(packages []string) []string {
+	result := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		replacement, replaced := centosReplacements[pkg]
+		if !replaced {
+			result = append(result, pkg)
+		} else if replacement != "" {
+			result = append(result, replacement)
+		}
+	}
+	return result
+}
+
 func (t *imageType) BuildPackages() []string {
 	packages := append(t.arch.distro.buildPackages, t.arch.buildPackages...)
 	return packages
@@ -288,28 +328,60 @@ func sources(packages []rpmmd.PackageSpec) *osbuild.Sources {
 }
 
 func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSpecs, buildPackageSpecs []rpmmd.PackageSpec, rng *rand.Rand) (*osbuild.Pipeline, error) {
+	mountpoints := c.GetFilesystems()
+	if len(mountpoints) > 0 {
+		if t.partitionTableGenerator == nil {
+			return nil, fmt.Errorf("filesystem customizations are not supported for image type %s", t.name)
+		}
+		if err := disk.CheckMountpoints(mountpoints, disk.MountpointAllowList); err != nil {
+			return nil, err
+		}
+	}
+
+	// the qemu assembler can neither create LVM volume groups nor LUKS2 volumes
+	if c.GetDisk() != nil {
+		return nil, fmt.Errorf("disk customizations are not supported for image type %s", t.name)
+	}
+
+	if c.GetFDO() != nil || c.GetIgnition() != nil {
+		return nil, fmt.Errorf("FDO and Ignition customizations are not supported for image type %s", t.name)
+	}
+
+	if c.GetInstallationDevice() != "" {
+		return nil, fmt.Errorf("installation device customizations are not supported for image type %s", t.name)
+	}
+
+	if err := fsnode.Check(append(fsnode.UnitFiles(c.GetServices()), fsnode.Files(c, options.ModuleStreams)...), c.GetDirectories()); err != nil {
+		return nil, err
+	}
+
 	var pt *disk.PartitionTable
 	if t.partitionTableGenerator != nil {
-		table := t.partitionTableGenerator(options, t.arch, rng)
+		table, err := disk.CreatePartitionTable(mountpoints, options.Size, t.partitionTableGenerator(options, t.arch, rng), rng)
+		if err != nil {
+			return nil, err
+		}
 		pt = &table
 	}
 
 	p := &osbuild.Pipeline{}
-	p.SetBuild(t.buildPipeline(repos, *t.arch, buildPackageSpecs), "org.osbuild.rhel90")
+	p.SetBuild(t.buildPipeline(repos, *t.arch, buildPackageSpecs), t.arch.distro.runner)
 
-	if pt == nil {
-		panic("Image must have a partition table, this is a programming error")
-	}
+	if t.bootable {
+		if pt == nil {
+			panic("Image must have a partition table, this is a programming error")
+		}
 
-	rootPartition := pt.RootPartition()
-	if rootPartition == nil {
-		panic("Image must have a root partition, this is a programming error")
-	}
+		rootPartition := pt.RootPartition()
+		if rootPartition == nil {
+			panic("Image must have a root partition, this is a programming error")
+		}
 
-	p.AddStage(osbuild.NewKernelCmdlineStage(&osbuild.KernelCmdlineStageOptions{
-		RootFsUUID: rootPartition.Filesystem.UUID,
-		KernelOpts: t.kernelOptions,
-	}))
+		p.AddStage(osbuild.NewKernelCmdlineStage(&osbuild.KernelCmdlineStageOptions{
+			RootFsUUID: rootPartition.Filesystem.UUID,
+			KernelOpts: kernelCmdline(t.kernelOptions, c.GetKernel()),
+		}))
+	}
 
 	p.AddStage(osbuild.NewRPMStage(t.rpmStageOptions(*t.arch, repos, packageSpecs)))
 	p.AddStage(osbuild.NewFixBLSStage())
@@ -369,6 +441,11 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		p.AddStage(osbuild.NewUsersStage(options))
 	}
 
+	// units need to exist before they can be enabled
+	if stage := fsnode.ScriptStage(fsnode.UnitFiles(c.GetServices()), nil); stage != nil {
+		p.AddStage(stage)
+	}
+
 	if services := c.GetServices(); services != nil || t.enabledServices != nil || t.disabledServices != nil || t.defaultTarget != "" {
 		p.AddStage(osbuild.NewSystemdStage(t.systemdStageOptions(t.enabledServices, t.disabledServices, services, t.defaultTarget)))
 	}
@@ -377,7 +454,11 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		p.AddStage(osbuild.NewFirewallStage(t.firewallStageOptions(firewall)))
 	}
 
-	if t.arch.Name() == "s390x" {
+	if stage := fsnode.ScriptStage(fsnode.Files(c, options.ModuleStreams), c.GetDirectories()); stage != nil {
+		p.AddStage(stage)
+	}
+
+	if t.bootable && t.arch.Name() == "s390x" {
 		p.AddStage(osbuild.NewZiplStage(&osbuild.ZiplStageOptions{}))
 	}
 
@@ -385,7 +466,7 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 	p.AddStage(osbuild.NewSysconfigStage(&osbuild.SysconfigStageOptions{
 		Kernel: osbuild.SysconfigKernelOptions{
 			UpdateDefault: true,
-			DefaultKernel: "kernel",
+			DefaultKernel: c.GetKernel().Name,
 		},
 		Network: osbuild.SysconfigNetworkOptions{
 			Networking: true,
@@ -408,7 +489,7 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		))
 	} else {
 		// RHSM DNF plugins should be by default disabled on RHEL Guest KVM images
-		if t.Name() == "qcow2" {
+		if t.Name() == "qcow2" && !t.arch.distro.isCentos {
 			p.AddStage(osbuild.NewRHSMStage(&osbuild.RHSMStageOptions{
 				DnfPlugins: &osbuild.RHSMStageOptionsDnfPlugins{
 					ProductID: &osbuild.RHSMStageOptionsDnfPlugin{
@@ -476,17 +557,31 @@ func (t *imageType) userStageOptions(users []blueprint.UserCustomization) (*osbu
 			c.Password = &cryptedPassword
 		}
 
+		// a "!" in front of the hash disables password logins
+		if c.Locked {
+			lockedPassword := "!"
+			if c.Password != nil {
+				lockedPassword += *c.Password
+			}
+			c.Password = &lockedPassword
+		}
+
 		user := osbuild.UsersStageOptionsUser{
 			Groups:      c.Groups,
 			Description: c.Description,
 			Home:        c.Home,
 			Shell:       c.Shell,
 			Password:    c.Password,
-			Key:         c.Key,
+			Key:         c.AuthorizedKeys(),
+			ExpireDate:  c.ExpireDate,
 		}
 
 		user.UID = c.UID
 		user.GID = c.GID
+		if c.ForcePasswordReset {
+			forcePasswordReset := true
+			user.ForcePasswordReset = &forcePasswordReset
+		}
 
 		options.Users[c.Name] = user
 	}
@@ -525,21 +620,40 @@ func (t *imageType) firewallStageOptions(firewall *blueprint.FirewallCustomizati
 		options.DisabledServices = firewall.Services.Disabled
 	}
 
+	for _, zone := range firewall.Zones {
+		options.Zones = append(options.Zones, osbuild.FirewallZone{
+			Name:    zone.Name,
+			Sources: zone.Sources,
+		})
+	}
+
 	return &options
 }
 
 func (t *imageType) systemdStageOptions(enabledServices, disabledServices []string, s *blueprint.ServicesCustomization, target string) *osbuild.SystemdStageOptions {
+	var maskedServices []string
 	if s != nil {
 		enabledServices = append(enabledServices, s.Enabled...)
 		disabledServices = append(disabledServices, s.Disabled...)
+		maskedServices = s.Masked
 	}
 	return &osbuild.SystemdStageOptions{
 		EnabledServices:  enabledServices,
 		DisabledServices: disabledServices,
+		MaskedServices:   maskedServices,
 		DefaultTarget:    target,
 	}
 }
 
+// Returns the kernel command line of an image: `kernelOptions` followed by
+// the arguments of the kernel customization.
+func kernelCmdline(kernelOptions string, kernel *blueprint.KernelCustomization) string {
+	if kernel != nil && kernel.Append != "" {
+		kernelOptions += " " + kernel.Append
+	}
+	return kernelOptions
+}
+
 func (t *imageType) grub2StageOptions(pt *disk.PartitionTable, kernelOptions string, kernel *blueprint.KernelCustomization, packages []rpmmd.PackageSpec, uefi bool, legacy string) *osbuild.GRUB2StageOptions {
 	if pt == nil {
 		panic("partition table must be defined for grub2 stage, this is a programming error")
@@ -557,6 +671,9 @@ func (t *imageType) grub2StageOptions(pt *disk.PartitionTable, kernelOptions str
 
 	if uefi {
 		vendor := "redhat"
+		if t.arch.distro.isCentos {
+			vendor = "centos"
+		}
 		stageOptions.UEFI = &osbuild.GRUB2UEFI{
 			Vendor: vendor,
 		}
@@ -739,6 +856,14 @@ func qemuAssembler(pt *disk.PartitionTable, format string, filename string, imag
 	return osbuild.NewQEMUAssembler(&options)
 }
 
+func tarAssembler(filename, compression string) *osbuild.Assembler {
+	return osbuild.NewTarAssembler(
+		&osbuild.TarAssemblerOptions{
+			Filename:    filename,
+			Compression: compression,
+		})
+}
+
 func newRandomUUIDFromReader(r io.Reader) (uuid.UUID, error) {
 	var id uuid.UUID
 	_, err := io.ReadFull(r, id[:])
@@ -751,14 +876,30 @@ func newRandomUUIDFromReader(r io.Reader) (uuid.UUID, error) {
 }
 
 func New() distro.Distro {
-	return newDistro(defaultName, modulePlatformID, ostreeRef)
+	return newDistro(defaultName, modulePlatformID, ostreeRef, false)
 }
 
 func NewHostDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
-	return newDistro(name, modulePlatformID, ostreeRef)
+	return newDistro(name, modulePlatformID, ostreeRef, false)
+}
+
+// NewCentos returns CentOS Stream 9, which is built like RHEL 9 from the
+// CentOS repositories.
+func NewCentos() distro.Distro {
+	return newDistro(defaultCentosName, modulePlatformID, "", true)
 }
 
-func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
+// NewCentos10 returns CentOS Stream 10, the upstream of the next RHEL major
+// release, which is built like CentOS Stream 9 until it diverges.
+func NewCentos10() distro.Distro {
+	return newDistro(defaultCentos10Name, centos10ModulePlatformID, "", true)
+}
+
+func NewCentosHostDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
+	return newDistro(name, modulePlatformID, ostreeRef, true)
+}
+
+func newDistro(name, modulePlatformID, ostreeRef string, isCentos bool) distro.Distro {
 	const GigaByte = 1024 * 1024 * 1024
 
 	qcow2ImageType := imageType{
@@ -783,11 +924,81 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 		},
 	}
 
+	openstackImgType := imageType{
+		name:                    "openstack",
+		filename:                "disk.qcow2",
+		mimeType:                "application/x-qemu-disk",
+		packages:                packages.Openstack.Include,
+		excludedPackages:        packages.Openstack.Exclude,
+		kernelOptions:           "ro net.ifnames=0",
+		bootable:                true,
+		defaultSize:             4 * GigaByte,
+		partitionTableGenerator: defaultPartitionTable,
+		assembler: func(pt *disk.PartitionTable, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return qemuAssembler(pt, "qcow2", "disk.qcow2", options, arch)
+		},
+	}
+
+	amiImgType := imageType{
+		name:                    "ami",
+		filename:                "image.raw",
+		mimeType:                "application/octet-stream",
+		packages:                packages.Ami.Include,
+		excludedPackages:        packages.Ami.Exclude,
+		defaultTarget:           "multi-user.target",
+		kernelOptions:           "console=ttyS0,115200n8 console=tty0 net.ifnames=0 rd.blacklist=nouveau nvme_core.io_timeout=4294967295",
+		bootable:                true,
+		defaultSize:             6 * GigaByte,
+		partitionTableGenerator: defaultPartitionTable,
+		assembler: func(pt *disk.PartitionTable, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return qemuAssembler(pt, "raw", "image.raw", options, arch)
+		},
+	}
+
+	vhdImgType := imageType{
+		name:             "vhd",
+		filename:         "disk.vhd",
+		mimeType:         "application/x-vhd",
+		packages:         packages.Vhd.Include,
+		excludedPackages: packages.Vhd.Exclude,
+		enabledServices: []string{
+			"sshd",
+			"waagent",
+		},
+		defaultTarget:           "multi-user.target",
+		kernelOptions:           "ro biosdevname=0 rootdelay=300 console=ttyS0 earlyprintk=ttyS0 net.ifnames=0",
+		bootable:                true,
+		defaultSize:             4 * GigaByte,
+		partitionTableGenerator: defaultPartitionTable,
+		assembler: func(pt *disk.PartitionTable, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return qemuAssembler(pt, "vpc", "disk.vhd", options, arch)
+		},
+	}
+
+	containerImgType := imageType{
+		name:             "container",
+		filename:         "container.tar",
+		mimeType:         "application/x-tar",
+		packages:         packages.Container.Include,
+		excludedPackages: packages.Container.Exclude,
+		bootable:         false,
+		assembler: func(pt *disk.PartitionTable, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return tarAssembler("container.tar", "")
+		},
+	}
+
 	r := distribution{
 		buildPackages:    packages.GenericBuild,
 		name:             name,
 		modulePlatformID: modulePlatformID,
 		ostreeRef:        ostreeRef,
+		isCentos:         isCentos,
+		runner:           "org.osbuild.rhel90",
+	}
+	if isCentos {
+		// the runners are named after the major release, like the
+		// module platform: org.osbuild.centos9, org.osbuild.centos10
+		r.runner = "org.osbuild.centos" + strings.TrimPrefix(modulePlatformID, "platform:el")
 	}
 	x8664 := architecture{
 		distro:             &r,
@@ -800,6 +1011,14 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 	x8664.addImageTypes(
 		qcow2ImageType,
 	)
+	if isCentos {
+		x8664.addImageTypes(
+			amiImgType,
+			containerImgType,
+			openstackImgType,
+			vhdImgType,
+		)
+	}
 
 	aarch64 := architecture{
 		distro:             &r,
@@ -811,6 +1030,13 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 	aarch64.addImageTypes(
 		qcow2ImageType,
 	)
+	if isCentos {
+		aarch64.addImageTypes(
+			amiImgType,
+			containerImgType,
+			openstackImgType,
+		)
+	}
 
 	ppc64le := architecture{
 		distro:             &r,
@@ -823,6 +1049,9 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 	ppc64le.addImageTypes(
 		qcow2ImageType,
 	)
+	if isCentos {
+		ppc64le.addImageTypes(containerImgType)
+	}
 
 	s390x := architecture{
 		distro:             &r,
@@ -834,6 +1063,9 @@ func newDistro(name, modulePlatformID, ostreeRef string) distro.Distro {
 	s390x.addImageTypes(
 		qcow2ImageType,
 	)
+	if isCentos {
+		s390x.addImageTypes(containerImgType)
+	}
 
 	r.addArches(x8664, aarch64, ppc64le, s390x)
 
@@ -378,3 +378,61 @@ func TestRhel84_ModulePlatformID(t *testing.T) {
 func TestRhel90_KernelOption(t *testing.T) {
 	distro_test_common.TestDistro_KernelOption(t, rhel90.New())
 }
+
+func TestCentos_ListImageTypes(t *testing.T) {
+	imgMap := map[string][]string{
+		"x86_64":  {"ami", "container", "openstack", "qcow2", "vhd"},
+		"aarch64": {"ami", "container", "openstack", "qcow2"},
+		"ppc64le": {"container", "qcow2"},
+		"s390x":   {"container", "qcow2"},
+	}
+
+	for _, centos := range []distro.Distro{rhel90.NewCentos(), rhel90.NewCentos10()} {
+		for archName, imgNames := range imgMap {
+			arch, err := centos.GetArch(archName)
+			require.NoError(t, err)
+			assert.Equalf(t, imgNames, arch.ListImageTypes(), "%s %s", centos.Name(), archName)
+		}
+	}
+}
+
+func TestCentos_NameAndModulePlatformID(t *testing.T) {
+	assert.Equal(t, "centos-9", rhel90.NewCentos().Name())
+	assert.Equal(t, "platform:el9", rhel90.NewCentos().ModulePlatformID())
+	assert.Equal(t, "centos-10", rhel90.NewCentos10().Name())
+	assert.Equal(t, "platform:el10", rhel90.NewCentos10().ModulePlatformID())
+}
+
+func TestCentos_Packages(t *testing.T) {
+	arch, err := rhel90.NewCentos().GetArch("x86_64")
+	require.NoError(t, err)
+
+	qcow2, err := arch.GetImageType("qcow2")
+	require.NoError(t, err)
+	packages := qcow2.PackageSets(blueprint.Blueprint{})["packages"].Include
+	assert.Contains(t, packages, "centos-stream-release")
+	assert.NotContains(t, packages, "redhat-release")
+	assert.NotContains(t, packages, "subscription-manager-cockpit")
+
+	container, err := arch.GetImageType("container")
+	require.NoError(t, err)
+	packages = container.PackageSets(blueprint.Blueprint{})["packages"].Include
+	assert.NotContains(t, packages, "kernel")
+	assert.NotContains(t, packages, "grub2-pc")
+}
+
+func TestCentos_Runner(t *testing.T) {
+	for runner, d := range map[string]distro.Distro{
+		"org.osbuild.rhel90":   rhel90.New(),
+		"org.osbuild.centos9":  rhel90.NewCentos(),
+		"org.osbuild.centos10": rhel90.NewCentos10(),
+	} {
+		arch, err := d.GetArch("x86_64")
+		require.NoError(t, err)
+		qcow2, err := arch.GetImageType("qcow2")
+		require.NoError(t, err)
+		manifest, err := qcow2.Manifest(nil, distro.ImageOptions{}, nil, nil, 0)
+		require.NoError(t, err)
+		assert.Containsf(t, string(manifest), `"runner":"`+runner+`"`, "distro: %s", d.Name())
+	}
+}
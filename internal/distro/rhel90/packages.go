@@ -14,6 +14,10 @@ var packages = struct {
 	Bootloader   archPackages
 	Build        archPackages
 	Qcow2        rpmmd.PackageSet
+	Openstack    rpmmd.PackageSet
+	Vhd          rpmmd.PackageSet
+	Ami          rpmmd.PackageSet
+	Container    rpmmd.PackageSet
 }{
 	GenericBuild: []string{
 		"dnf",
@@ -136,4 +140,106 @@ var packages = struct {
 			"udisks2",
 		},
 	},
+	Openstack: rpmmd.PackageSet{
+		Include: []string{
+			"@Core",
+			"langpacks-en",
+			"selinux-policy-targeted",
+			"cloud-init",
+			"qemu-guest-agent",
+			"spice-vdagent",
+		},
+		Exclude: []string{
+			"dracut-config-rescue",
+			"rng-tools",
+		},
+	},
+	Vhd: rpmmd.PackageSet{
+		Include: []string{
+			"@Core",
+			"langpacks-en",
+			"selinux-policy-targeted",
+			"chrony",
+			"WALinuxAgent",
+			"python3",
+			"cloud-init",
+			"cloud-utils-growpart",
+			"gdisk",
+		},
+		Exclude: []string{
+			"dracut-config-rescue",
+			"rng-tools",
+		},
+	},
+	Ami: rpmmd.PackageSet{
+		Include: []string{
+			"@Core",
+			"checkpolicy",
+			"chrony",
+			"cloud-init",
+			"cloud-utils-growpart",
+			"dhcp-client",
+			"gdisk",
+			"langpacks-en",
+			"NetworkManager",
+			"redhat-release",
+			"rsync",
+			"selinux-policy-targeted",
+			"tar",
+			"yum-utils",
+		},
+		Exclude: []string{
+			"aic94xx-firmware",
+			"alsa-firmware",
+			"alsa-lib",
+			"alsa-tools-firmware",
+			"biosdevname",
+			"dracut-config-rescue",
+			"firewalld",
+			"iprutils",
+			"ivtv-firmware",
+			"iwl100-firmware",
+			"iwl1000-firmware",
+			"iwl105-firmware",
+			"iwl135-firmware",
+			"iwl2000-firmware",
+			"iwl2030-firmware",
+			"iwl3160-firmware",
+			"iwl3945-firmware",
+			"iwl4965-firmware",
+			"iwl5000-firmware",
+			"iwl5150-firmware",
+			"iwl6000-firmware",
+			"iwl6000g2a-firmware",
+			"iwl6000g2b-firmware",
+			"iwl6050-firmware",
+			"iwl7260-firmware",
+			"libertas-sd8686-firmware",
+			"libertas-sd8787-firmware",
+			"libertas-usb8388-firmware",
+			"plymouth",
+			"rng-tools",
+		},
+	},
+	// Base image for containers, which don't need a kernel or boot loader
+	Container: rpmmd.PackageSet{
+		Include: []string{
+			"bash",
+			"coreutils-single",
+			"dnf",
+			"glibc-minimal-langpack",
+			"redhat-release",
+			"rootfiles",
+			"tar",
+			"vim-minimal",
+		},
+		Exclude: []string{
+			"dracut",
+			"firewalld",
+			"grub2-common",
+			"langpacks-en",
+			"plymouth",
+			"rng-tools",
+		},
+	},
 }
@@ -0,0 +1,67 @@
+package distro
+
+import "github.com/osbuild/osbuild-composer/internal/blueprint"
+
+const (
+	// SAPTunedProfile is the tuned profile which applies the system settings
+	// SAP HANA requires, e.g. it disables transparent huge pages.
+	SAPTunedProfile = "sap-hana"
+
+	// SAPKernelOptions keep the processors out of deep C-states, which
+	// increase the latency of SAP HANA.
+	SAPKernelOptions = "processor.max_cstate=1 intel_idle.max_cstate=1"
+
+	// Configuration files of the resource limits, kernel parameters and
+	// temporary files of SAP systems
+	SAPLimitsPath   = "/etc/security/limits.d/99-sap.conf"
+	SAPSysctlPath   = "/etc/sysctl.d/sap.conf"
+	SAPTmpfilesPath = "/etc/tmpfiles.d/sap.conf"
+)
+
+// The users of the sapsys group run the SAP systems, which open many files
+// and start many processes.
+const sapLimits = `@sapsys    hard    nofile    1048576
+@sapsys    soft    nofile    1048576
+@sapsys    hard    nproc     unlimited
+@sapsys    soft    nproc     unlimited
+`
+
+const sapSysctl = `kernel.pid_max = 4194304
+vm.max_map_count = 2147483647
+`
+
+// systemd must not clean up the lock files of running SAP systems
+const sapTmpfiles = `x /tmp/.sap*
+x /tmp/.hdb*lock
+x /tmp/.trex*lock
+`
+
+// SAPCustomizations returns a copy of `c` for images tuned for SAP HANA,
+// which additionally configure the resource limits, kernel parameters and
+// temporary files SAP systems need. Configuration files which `c` customizes
+// are not replaced.
+func SAPCustomizations(c *blueprint.Customizations) *blueprint.Customizations {
+	var sap blueprint.Customizations
+	if c != nil {
+		sap = *c
+	}
+
+	customized := make(map[string]bool)
+	for _, f := range sap.Files {
+		customized[f.Path] = true
+	}
+
+	var files []blueprint.FileCustomization
+	for _, f := range []blueprint.FileCustomization{
+		{Path: SAPLimitsPath, Mode: "0644", Data: sapLimits},
+		{Path: SAPSysctlPath, Mode: "0644", Data: sapSysctl},
+		{Path: SAPTmpfilesPath, Mode: "0644", Data: sapTmpfiles},
+	} {
+		if !customized[f.Path] {
+			files = append(files, f)
+		}
+	}
+	sap.Files = append(files, sap.Files...)
+
+	return &sap
+}
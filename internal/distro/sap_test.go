@@ -0,0 +1,31 @@
+package distro_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+)
+
+func TestSAPCustomizations(t *testing.T) {
+	sap := distro.SAPCustomizations(nil)
+	require.Len(t, sap.Files, 3)
+	assert.Equal(t, distro.SAPLimitsPath, sap.Files[0].Path)
+	assert.Contains(t, sap.Files[0].Data, "@sapsys")
+	assert.Equal(t, distro.SAPSysctlPath, sap.Files[1].Path)
+	assert.Equal(t, distro.SAPTmpfilesPath, sap.Files[2].Path)
+
+	c := &blueprint.Customizations{
+		Files: []blueprint.FileCustomization{{Path: distro.SAPSysctlPath, Data: "kernel.pid_max = 65536\n"}},
+	}
+	sap = distro.SAPCustomizations(c)
+	require.Len(t, sap.Files, 3)
+	assert.Equal(t, distro.SAPLimitsPath, sap.Files[0].Path)
+	assert.Equal(t, distro.SAPTmpfilesPath, sap.Files[1].Path)
+	assert.Equal(t, c.Files[0], sap.Files[2])
+	// the blueprint's customizations are not modified
+	assert.Len(t, c.Files, 1)
+}
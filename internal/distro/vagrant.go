@@ -0,0 +1,185 @@
+package distro
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+)
+
+const (
+	// VagrantUser is the user which Vagrant logs in as.
+	VagrantUser = "vagrant"
+
+	// VagrantInsecureKey is the public key of the key pair which Vagrant
+	// uses to log into new machines, before it replaces it with a new one.
+	VagrantInsecureKey = "ssh-rsa AAAAB3NzaC1yc2EAAAABIwAAAQEA6NF8iallvQVp22WDkTkyrtvp9eWW6A8YVr+kz4TjGYe7gHzIw+niNltGEFHzD8+v1I2YJ6oXevct1YeS0o9HZyN1Q9qgCgzUFtdOKLv6IedplqoPkcmF0aYet2PkEDo3MlTBckFXPITAMzF8dJSIFo9D8HfdOV0IAdx4O7PtixWKn5y2hMNG0zQPyUecp4pzC6kivAIhyfHilFR61RGL+GPXQ2MWZWFYbAGjyiYJnAmCP3NOTd0jMZEnDkbUvxhMmBYSdETk1rRgm+R4LOzFUGaHqHDLKLX+FIPKcF96hrucXzcWyLbIbEgE98OHlnVYCzRdK8jlqm8tehUc9c9WhQ== vagrant insecure public key"
+
+	// VagrantSudoersPath lets the vagrant user run all commands as root
+	// without a password, as Vagrant expects.
+	VagrantSudoersPath = "/etc/sudoers.d/vagrant"
+
+	// The disk images of the boxes, as expected by the providers
+	VagrantLibvirtDisk    = "box.img"
+	VagrantVirtualBoxDisk = "box-disk1.vmdk"
+)
+
+// VagrantCustomizations returns a copy of `c` for Vagrant boxes, which
+// additionally creates the vagrant user with the insecure key and allows it
+// to use sudo without a password. A vagrant user defined by `c` is used
+// instead of the default one.
+func VagrantCustomizations(c *blueprint.Customizations) *blueprint.Customizations {
+	var vagrant blueprint.Customizations
+	if c != nil {
+		vagrant = *c
+	}
+
+	hasUser := false
+	for _, u := range vagrant.User {
+		if u.Name == VagrantUser {
+			hasUser = true
+			break
+		}
+	}
+	if !hasUser {
+		key := VagrantInsecureKey
+		vagrant.User = append([]blueprint.UserCustomization{{
+			Name:   VagrantUser,
+			Key:    &key,
+			Groups: []string{"wheel"},
+		}}, vagrant.User...)
+	}
+
+	for _, f := range vagrant.Files {
+		if f.Path == VagrantSudoersPath {
+			return &vagrant
+		}
+	}
+	vagrant.Files = append([]blueprint.FileCustomization{{
+		Path: VagrantSudoersPath,
+		Mode: "0440",
+		Data: VagrantUser + " ALL=(ALL) NOPASSWD: ALL\n",
+	}}, vagrant.Files...)
+
+	return &vagrant
+}
+
+// VagrantBoxFiles returns the files which need to be in a Vagrant box for
+// `provider` (libvirt or virtualbox) next to its disk image: metadata.json,
+// the Vagrantfile with the box's defaults and, for virtualbox, the
+// box.ovf describing the machine. The paths are relative to the root of
+// the box. `size` is the virtual size of the disk in bytes.
+func VagrantBoxFiles(provider string, size uint64) []blueprint.FileCustomization {
+	const GigaByte = 1024 * 1024 * 1024
+
+	metadata := map[string]interface{}{"provider": provider}
+	if provider == "libvirt" {
+		metadata["format"] = "qcow2"
+		metadata["virtual_size"] = (size + GigaByte - 1) / GigaByte
+	}
+	// a map of strings and numbers cannot fail to marshal
+	data, _ := json.Marshal(metadata)
+
+	files := []blueprint.FileCustomization{
+		{Path: "/metadata.json", Mode: "0644", Data: string(data) + "\n"},
+		{Path: "/Vagrantfile", Mode: "0644", Data: fmt.Sprintf(vagrantfile, provider)},
+	}
+	if provider == "virtualbox" {
+		files = append(files, blueprint.FileCustomization{
+			Path: "/box.ovf",
+			Mode: "0644",
+			Data: fmt.Sprintf(vagrantOVF, VagrantVirtualBoxDisk, size),
+		})
+	}
+	return files
+}
+
+// The images have no guest additions, so the project directory is synced
+// with rsync.
+const vagrantfile = `Vagrant.configure("2") do |config|
+  config.ssh.username = "vagrant"
+  config.vm.synced_folder ".", "/vagrant", type: "rsync"
+
+  config.vm.provider :%s do |provider|
+    provider.memory = 2048
+    provider.cpus = 2
+  end
+end
+`
+
+// A machine with the disk on a SATA controller and a NAT network adapter,
+// which VirtualBox imports when the box is added.
+const vagrantOVF = `<?xml version="1.0"?>
+<Envelope ovf:version="1.0" xml:lang="en-US" xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1" xmlns:rasd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData" xmlns:vssd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_VirtualSystemSettingData" xmlns:vbox="http://www.virtualbox.org/ovf/machine">
+  <References>
+    <File ovf:id="file1" ovf:href="%s"/>
+  </References>
+  <DiskSection>
+    <Info>List of the virtual disks used in the package</Info>
+    <Disk ovf:capacity="%d" ovf:diskId="vmdisk1" ovf:fileRef="file1" ovf:format="http://www.vmware.com/interfaces/specifications/vmdk.html#streamOptimized"/>
+  </DiskSection>
+  <NetworkSection>
+    <Info>Logical networks used in the package</Info>
+    <Network ovf:name="NAT">
+      <Description>Logical network used by this appliance.</Description>
+    </Network>
+  </NetworkSection>
+  <VirtualSystem ovf:id="vagrant">
+    <Info>A virtual machine</Info>
+    <OperatingSystemSection ovf:id="80">
+      <Info>The kind of installed guest operating system</Info>
+      <vbox:OSType ovf:required="false">RedHat_64</vbox:OSType>
+    </OperatingSystemSection>
+    <VirtualHardwareSection>
+      <Info>Virtual hardware requirements for a virtual machine</Info>
+      <System>
+        <vssd:ElementName>Virtual Hardware Family</vssd:ElementName>
+        <vssd:InstanceID>0</vssd:InstanceID>
+        <vssd:VirtualSystemIdentifier>vagrant</vssd:VirtualSystemIdentifier>
+        <vssd:VirtualSystemType>virtualbox-2.2</vssd:VirtualSystemType>
+      </System>
+      <Item>
+        <rasd:Caption>2 virtual CPU</rasd:Caption>
+        <rasd:ElementName>2 virtual CPU</rasd:ElementName>
+        <rasd:InstanceID>1</rasd:InstanceID>
+        <rasd:ResourceType>3</rasd:ResourceType>
+        <rasd:VirtualQuantity>2</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:AllocationUnits>MegaBytes</rasd:AllocationUnits>
+        <rasd:Caption>2048 MB of memory</rasd:Caption>
+        <rasd:ElementName>2048 MB of memory</rasd:ElementName>
+        <rasd:InstanceID>2</rasd:InstanceID>
+        <rasd:ResourceType>4</rasd:ResourceType>
+        <rasd:VirtualQuantity>2048</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:Address>0</rasd:Address>
+        <rasd:Caption>sataController0</rasd:Caption>
+        <rasd:ElementName>sataController0</rasd:ElementName>
+        <rasd:InstanceID>3</rasd:InstanceID>
+        <rasd:ResourceSubType>AHCI</rasd:ResourceSubType>
+        <rasd:ResourceType>20</rasd:ResourceType>
+      </Item>
+      <Item>
+        <rasd:AutomaticAllocation>true</rasd:AutomaticAllocation>
+        <rasd:Caption>Ethernet adapter on 'NAT'</rasd:Caption>
+        <rasd:Connection>NAT</rasd:Connection>
+        <rasd:ElementName>Ethernet adapter on 'NAT'</rasd:ElementName>
+        <rasd:InstanceID>4</rasd:InstanceID>
+        <rasd:ResourceSubType>E1000</rasd:ResourceSubType>
+        <rasd:ResourceType>10</rasd:ResourceType>
+      </Item>
+      <Item>
+        <rasd:AddressOnParent>0</rasd:AddressOnParent>
+        <rasd:Caption>disk1</rasd:Caption>
+        <rasd:ElementName>disk1</rasd:ElementName>
+        <rasd:HostResource>/disk/vmdisk1</rasd:HostResource>
+        <rasd:InstanceID>5</rasd:InstanceID>
+        <rasd:Parent>3</rasd:Parent>
+        <rasd:ResourceType>17</rasd:ResourceType>
+      </Item>
+    </VirtualHardwareSection>
+  </VirtualSystem>
+</Envelope>
+`
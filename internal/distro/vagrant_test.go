@@ -0,0 +1,55 @@
+package distro_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+)
+
+func TestVagrantCustomizations(t *testing.T) {
+	c := &blueprint.Customizations{
+		User: []blueprint.UserCustomization{{Name: "admin"}},
+	}
+
+	vagrant := distro.VagrantCustomizations(c)
+	require.Len(t, vagrant.User, 2)
+	assert.Equal(t, distro.VagrantUser, vagrant.User[0].Name)
+	assert.Equal(t, distro.VagrantInsecureKey, *vagrant.User[0].Key)
+	require.Len(t, vagrant.Files, 1)
+	assert.Equal(t, distro.VagrantSudoersPath, vagrant.Files[0].Path)
+	assert.Equal(t, "0440", vagrant.Files[0].Mode)
+	// the blueprint's customizations are not modified
+	assert.Len(t, c.User, 1)
+	assert.Empty(t, c.Files)
+
+	// a vagrant user of the blueprint replaces the default one
+	key := "ssh-ed25519 AAAA..."
+	c.User = []blueprint.UserCustomization{{Name: "vagrant", Key: &key}}
+	vagrant = distro.VagrantCustomizations(c)
+	assert.Equal(t, c.User, vagrant.User)
+
+	assert.Len(t, distro.VagrantCustomizations(nil).User, 1)
+}
+
+func TestVagrantBoxFiles(t *testing.T) {
+	files := distro.VagrantBoxFiles("libvirt", 10*1024*1024*1024+1)
+	require.Len(t, files, 2)
+	assert.Equal(t, "/metadata.json", files[0].Path)
+	var metadata map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(files[0].Data), &metadata))
+	assert.Equal(t, map[string]interface{}{"provider": "libvirt", "format": "qcow2", "virtual_size": float64(11)}, metadata)
+	assert.Equal(t, "/Vagrantfile", files[1].Path)
+	assert.Contains(t, files[1].Data, "config.vm.provider :libvirt")
+
+	files = distro.VagrantBoxFiles("virtualbox", 1024)
+	require.Len(t, files, 3)
+	assert.Equal(t, "{\"provider\":\"virtualbox\"}\n", files[0].Data)
+	assert.Equal(t, "/box.ovf", files[2].Path)
+	assert.Contains(t, files[2].Data, `ovf:href="box-disk1.vmdk"`)
+	assert.Contains(t, files[2].Data, `ovf:capacity="1024"`)
+}
@@ -0,0 +1,34 @@
+package distro
+
+import "github.com/osbuild/osbuild-composer/internal/blueprint"
+
+// WSLConfPath is the configuration file which the Windows Subsystem for
+// Linux reads from the root filesystems it imports.
+const WSLConfPath = "/etc/wsl.conf"
+
+// wslConf boots the image with systemd and mounts the Windows drives with
+// Linux permissions.
+const wslConf = `[boot]
+systemd=true
+
+[automount]
+options="metadata"
+`
+
+// WSLCustomizations returns a copy of `c` for wsl image types, which
+// additionally creates the default /etc/wsl.conf, unless `c` customizes it.
+func WSLCustomizations(c *blueprint.Customizations) *blueprint.Customizations {
+	var wsl blueprint.Customizations
+	if c != nil {
+		wsl = *c
+	}
+
+	for _, f := range wsl.Files {
+		if f.Path == WSLConfPath {
+			return &wsl
+		}
+	}
+	wsl.Files = append([]blueprint.FileCustomization{{Path: WSLConfPath, Mode: "0644", Data: wslConf}}, wsl.Files...)
+
+	return &wsl
+}
@@ -0,0 +1,33 @@
+package distro_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+)
+
+func TestWSLCustomizations(t *testing.T) {
+	hostname := "wsl"
+	c := &blueprint.Customizations{
+		Hostname: &hostname,
+		Files:    []blueprint.FileCustomization{{Path: "/etc/motd", Data: "hello"}},
+	}
+
+	wsl := distro.WSLCustomizations(c)
+	require.Len(t, wsl.Files, 2)
+	assert.Equal(t, distro.WSLConfPath, wsl.Files[0].Path)
+	assert.Contains(t, wsl.Files[0].Data, "systemd=true")
+	assert.Equal(t, "/etc/motd", wsl.Files[1].Path)
+	assert.Equal(t, &hostname, wsl.Hostname)
+	// the blueprint's customizations are not modified
+	assert.Len(t, c.Files, 1)
+
+	c.Files = []blueprint.FileCustomization{{Path: distro.WSLConfPath, Data: "[boot]\nsystemd=false\n"}}
+	assert.Equal(t, c.Files, distro.WSLCustomizations(c).Files)
+
+	assert.Len(t, distro.WSLCustomizations(nil).Files, 1)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/distro/fedora33"
+	"github.com/osbuild/osbuild-composer/internal/distro/generic"
 	"github.com/osbuild/osbuild-composer/internal/distro/rhel8"
 	"github.com/osbuild/osbuild-composer/internal/distro/rhel84"
 	"github.com/osbuild/osbuild-composer/internal/distro/rhel85"
@@ -19,11 +20,15 @@ var supportedDistros = []supportedDistro{
 	{fedora33.New, fedora33.NewHostDistro},
 	{fedora33.NewF34, fedora33.NewHostDistro},
 	{fedora33.NewF35, fedora33.NewHostDistro},
+	{fedora33.NewRawhide, fedora33.NewHostDistro},
+	{fedora33.NewELN, fedora33.NewHostDistro},
 	{rhel8.New, rhel8.NewHostDistro},
 	{rhel84.New, rhel84.NewHostDistro},
 	{rhel84.NewCentos, rhel84.NewCentosHostDistro},
 	{rhel85.New, rhel85.NewHostDistro},
 	{rhel90.New, rhel90.NewHostDistro},
+	{rhel90.NewCentos, rhel90.NewCentosHostDistro},
+	{rhel90.NewCentos10, rhel90.NewCentosHostDistro},
 }
 
 type supportedDistro struct {
@@ -55,6 +60,37 @@ func New(hostDistro distro.Distro, distros ...distro.Distro) (*Registry, error)
 // osbuild-composer. If you need to add a distribution here, see the
 // supportedDistros variable.
 func NewDefault() *Registry {
+	registry, err := newDefault(supportedDistros)
+	if err != nil {
+		panic(fmt.Sprintf("two supported distros have the same name, this is a programming error: %v", err))
+	}
+
+	return registry
+}
+
+// NewDefaultWithDefinitions creates a Registry with all distributions
+// supported by osbuild-composer and the generic distributions defined by the
+// files in `dirs`, see generic.LoadDefinitions(). It returns an error if a
+// definition cannot be loaded, or if it has the name of another distribution.
+func NewDefaultWithDefinitions(dirs ...string) (*Registry, error) {
+	definitions, err := generic.LoadDefinitions(dirs...)
+	if err != nil {
+		return nil, err
+	}
+
+	distros := append([]supportedDistro{}, supportedDistros...)
+	for _, def := range definitions {
+		def := def
+		distros = append(distros, supportedDistro{
+			func() distro.Distro { return generic.New(def) },
+			func(name, _, _ string) distro.Distro { return generic.NewHostDistro(def, name) },
+		})
+	}
+
+	return newDefault(distros)
+}
+
+func newDefault(supportedDistros []supportedDistro) (*Registry, error) {
 	var distros []distro.Distro
 	var hostDistro distro.Distro
 
@@ -78,12 +114,7 @@ func NewDefault() *Registry {
 		distros = append(distros, distro)
 	}
 
-	registry, err := New(hostDistro, distros...)
-	if err != nil {
-		panic(fmt.Sprintf("two supported distros have the same name, this is a programming error: %v", err))
-	}
-
-	return registry
+	return New(hostDistro, distros...)
 }
 
 func (r *Registry) GetDistro(name string) distro.Distro {
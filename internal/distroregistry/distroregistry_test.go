@@ -1,6 +1,9 @@
 package distroregistry
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -23,6 +26,31 @@ func TestRegistry_List(t *testing.T) {
 	require.ElementsMatch(t, expected, distros.List(), "unexpected list of distros")
 }
 
+func TestRegistry_NewDefaultWithDefinitions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "distroregistry-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	definition := func(name string) []byte {
+		return []byte("name: " + name + "\nmodule_platform_id: platform:test\narches:\n  x86_64: {}\n")
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "toucan.yaml"), definition("toucan-1"), 0644)
+	require.NoError(t, err)
+
+	distros, err := NewDefaultWithDefinitions(dir)
+	require.NoError(t, err)
+	require.Len(t, distros.List(), len(supportedDistros)+1)
+	require.Equal(t, "platform:test", distros.GetDistro("toucan-1").ModulePlatformID())
+
+	// definitions cannot replace the distros supported by osbuild-composer
+	err = ioutil.WriteFile(filepath.Join(dir, "rhel.yaml"), definition(rhel8.New().Name()), 0644)
+	require.NoError(t, err)
+
+	_, err = NewDefaultWithDefinitions(dir)
+	require.Error(t, err)
+}
+
 func TestRegistry_GetDistro(t *testing.T) {
 	distros := NewDefault()
 
@@ -0,0 +1,438 @@
+// Package dnfjson manages the metadata cache shared by all dnf-json
+// processes running on a host.
+package dnfjson
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultMaxSize is the size above which the least recently used
+	// repositories are evicted from a cache.
+	DefaultMaxSize = 5 * 1024 * 1024 * 1024
+
+	// DefaultTTL is the time after which metadata of repositories which
+	// don't set their own metadata expiration is downloaded again.
+	DefaultTTL = 48 * time.Hour
+)
+
+// Cache is a dnf cache directory which is shared between concurrent dnf-json
+// processes. dnf stores the metadata of a repository in a directory named
+// after the repository's id and a hash of its URL, and puts solv files with
+// the same prefix next to it. Cache treats all entries with that prefix as one
+// unit, which it locks while a dnf-json process uses it and evicts once it
+// expired or the cache grew too large.
+//
+// Processes share the lock of a repository whose metadata is in the cache
+// already. Only a process which needs to download it holds the lock
+// exclusively, so that the metadata is downloaded once.
+//
+// Locks are taken with flock(2) on files in the cache directory, so that they
+// are respected by all processes on the host.
+type Cache struct {
+	root    string
+	maxSize int64
+	ttl     time.Duration
+}
+
+// CacheRepo identifies a repository passed to dnf-json.
+type CacheRepo struct {
+	// ID of the repository in the dnf-json call
+	ID string
+
+	// The metalink, mirrorlist or baseurl of the repository, whichever
+	// dnf uses to identify it (in this order).
+	URL string
+
+	// The repository's metadata_expire setting. The cache's TTL is used
+	// when it is empty.
+	MetadataExpire string
+}
+
+// NewCache returns a cache in `root`, which holds at most `maxSize` bytes
+// (unless a single dnf-json call needs more) and expires metadata after
+// `ttl`.
+func NewCache(root string, maxSize int64, ttl time.Duration) *Cache {
+	return &Cache{
+		root:    root,
+		maxSize: maxSize,
+		ttl:     ttl,
+	}
+}
+
+// Root returns the directory which must be passed to dnf-json as its cachedir.
+func (c *Cache) Root() string {
+	return c.root
+}
+
+// Lock prepares the cache for a dnf-json call using `repos`. It waits until
+// no other call downloads metadata of any of the repositories, removes their
+// metadata if it expired, and evicts the least recently used other
+// repositories if the cache is too large. The returned function must be
+// called once dnf-json exited.
+func (c *Cache) Lock(repos []CacheRepo) (func(), error) {
+	err := os.MkdirAll(c.root, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	global, err := lockFile(filepath.Join(c.root, ".lock"), syscall.LOCK_SH)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(repos))
+	ttls := make(map[string]time.Duration)
+	for _, repo := range repos {
+		key := cacheKey(repo)
+		if _, ok := ttls[key]; ok {
+			continue
+		}
+		ttl, err := c.repoTTL(repo)
+		if err != nil {
+			unlockFile(global)
+			return nil, err
+		}
+		keys = append(keys, key)
+		ttls[key] = ttl
+	}
+	// always lock in the same order to avoid deadlocks
+	sort.Strings(keys)
+
+	var locks []*os.File
+	unlock := func() {
+		now := time.Now()
+		for _, key := range keys {
+			// the directory's mtime is the time it was last used
+			_ = os.Chtimes(filepath.Join(c.root, key), now, now)
+		}
+		for i := len(locks) - 1; i >= 0; i-- {
+			unlockFile(locks[i])
+		}
+		unlockFile(global)
+	}
+
+	for _, key := range keys {
+		l, err := c.lockRepo(key, ttls[key])
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		locks = append(locks, l)
+	}
+
+	err = c.shrink(global, keys)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	return unlock, nil
+}
+
+// Locks the repository identified by `key`. The lock is shared if its metadata
+// is in the cache and didn't expire. Otherwise, the expired metadata is
+// removed and the lock is held exclusively, until dnf-json downloaded it.
+func (c *Cache) lockRepo(key string, ttl time.Duration) (*os.File, error) {
+	l, err := lockFile(filepath.Join(c.root, "."+key+".lock"), syscall.LOCK_SH)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.downloaded(key) && !c.expired(key, ttl) {
+		return l, nil
+	}
+
+	// Converting the lock isn't atomic, so the metadata needs to be checked
+	// again: another process might have downloaded it in between.
+	err = syscall.Flock(int(l.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		unlockFile(l)
+		return nil, err
+	}
+
+	if c.expired(key, ttl) {
+		err = c.remove(key)
+		if err != nil {
+			unlockFile(l)
+			return nil, err
+		}
+	}
+
+	if c.downloaded(key) {
+		err = syscall.Flock(int(l.Fd()), syscall.LOCK_SH)
+		if err != nil {
+			unlockFile(l)
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// Evicts the least recently used repositories until the cache is smaller than
+// its maximum size, except for those in `keep`. Other processes might be
+// using any of the other repositories, so this only happens when the shared
+// lock on `global` can be upgraded to an exclusive one without waiting.
+func (c *Cache) shrink(global *os.File, keep []string) (err error) {
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	for _, e := range entries {
+		size += e.size
+	}
+	if size <= c.maxSize {
+		return nil
+	}
+
+	// Converting a lock isn't atomic: the shared lock is released even if
+	// the exclusive one cannot be taken, so it needs to be taken again in
+	// any case.
+	defer func() {
+		lockErr := syscall.Flock(int(global.Fd()), syscall.LOCK_SH)
+		if err == nil {
+			err = lockErr
+		}
+	}()
+
+	err = syscall.Flock(int(global.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	kept := make(map[string]bool)
+	for _, key := range keep {
+		kept[key] = true
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.Before(entries[j].lastUsed)
+	})
+	for _, e := range entries {
+		if size <= c.maxSize {
+			break
+		}
+		if kept[e.key] {
+			continue
+		}
+		err = c.remove(e.key)
+		if err != nil {
+			return err
+		}
+		size -= e.size
+	}
+
+	return nil
+}
+
+type cacheEntry struct {
+	key      string
+	size     int64
+	lastUsed time.Time
+}
+
+// Returns all repositories in the cache. Files which don't belong to a
+// repository are ignored.
+func (c *Cache) entries() ([]cacheEntry, error) {
+	infos, err := ioutil.ReadDir(c.root)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*cacheEntry)
+	for _, info := range infos {
+		key := keyOf(info.Name())
+		if key == "" {
+			continue
+		}
+
+		e, ok := byKey[key]
+		if !ok {
+			e = &cacheEntry{key: key}
+			byKey[key] = e
+		}
+
+		size, err := diskUsage(filepath.Join(c.root, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		e.size += size
+		if info.IsDir() || e.lastUsed.IsZero() {
+			e.lastUsed = info.ModTime()
+		}
+	}
+
+	entries := make([]cacheEntry, 0, len(byKey))
+	for _, e := range byKey {
+		entries = append(entries, *e)
+	}
+	return entries, nil
+}
+
+// Returns whether the metadata of the repository is in the cache.
+func (c *Cache) downloaded(key string) bool {
+	_, err := os.Stat(filepath.Join(c.root, key, "repodata", "repomd.xml"))
+	return err == nil
+}
+
+// Returns whether the metadata of the repository was downloaded longer than
+// `ttl` ago. A negative `ttl` means it never expires.
+func (c *Cache) expired(key string, ttl time.Duration) bool {
+	if ttl < 0 {
+		return false
+	}
+
+	info, err := os.Stat(filepath.Join(c.root, key, "repodata", "repomd.xml"))
+	if err != nil {
+		return false
+	}
+
+	return time.Since(info.ModTime()) > ttl
+}
+
+// Removes all files belonging to the repository identified by `key`.
+func (c *Cache) remove(key string) error {
+	infos, err := ioutil.ReadDir(c.root)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		if keyOf(info.Name()) != key {
+			continue
+		}
+		err = os.RemoveAll(filepath.Join(c.root, info.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Cache) repoTTL(repo CacheRepo) (time.Duration, error) {
+	if repo.MetadataExpire == "" {
+		return c.ttl, nil
+	}
+	return parseMetadataExpire(repo.MetadataExpire)
+}
+
+// Parses a dnf metadata_expire value: a number of seconds with an optional
+// unit suffix (s, m, h, d), or "-1" and "never" for metadata which never
+// expires.
+func parseMetadataExpire(s string) (time.Duration, error) {
+	if s == "never" || s == "-1" {
+		return -1, nil
+	}
+
+	unit := time.Second
+	switch s[len(s)-1] {
+	case 's':
+		s = s[:len(s)-1]
+	case 'm':
+		unit = time.Minute
+		s = s[:len(s)-1]
+	case 'h':
+		unit = time.Hour
+		s = s[:len(s)-1]
+	case 'd':
+		unit = 24 * time.Hour
+		s = s[:len(s)-1]
+	}
+
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid metadata_expire value: %v", err)
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+// Returns the prefix of the cache entries of `repo`. It uses the same
+// algorithm as libdnf (Repo::Impl::getHash() in libdnf/repo/Repo.cpp).
+func cacheKey(repo CacheRepo) string {
+	digest := sha256.Sum256([]byte(repo.URL))
+	return repo.ID + "-" + hex.EncodeToString(digest[:])[:16]
+}
+
+// Returns the key of the repository a file in the cache belongs to, or an
+// empty string if it doesn't belong to one. Lock files are never removed,
+// because another process might be waiting for them.
+func keyOf(name string) string {
+	if strings.HasPrefix(name, ".") {
+		return ""
+	}
+
+	// the key ends with the first dash which is followed by 16 hex digits
+	for i := strings.IndexByte(name, '-'); i >= 0; {
+		end := i + 1 + 16
+		if end <= len(name) && isHex(name[i+1:end]) && (end == len(name) || name[end] == '.' || name[end] == '-') {
+			return name[:end]
+		}
+		next := strings.IndexByte(name[i+1:], '-')
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+
+	return ""
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func diskUsage(p string) (int64, error) {
+	var size int64
+	err := filepath.Walk(p, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+func lockFile(p string, how int) (*os.File, error) {
+	f, err := os.OpenFile(p, os.O_RDONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syscall.Flock(int(f.Fd()), how)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error locking %s: %v", p, err)
+	}
+
+	return f, nil
+}
+
+func unlockFile(f *os.File) {
+	_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	f.Close()
+}
@@ -0,0 +1,183 @@
+package dnfjson
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Creates the cache entries dnf would create for `repo`, with `size` bytes of
+// metadata downloaded at `downloaded`, and last used at `used`.
+func addRepo(t *testing.T, root string, repo CacheRepo, size int, downloaded, used time.Time) string {
+	key := cacheKey(repo)
+
+	repodata := filepath.Join(root, key, "repodata")
+	require.NoError(t, os.MkdirAll(repodata, 0755))
+	repomd := filepath.Join(repodata, "repomd.xml")
+	require.NoError(t, ioutil.WriteFile(repomd, make([]byte, size), 0644))
+	require.NoError(t, os.Chtimes(repomd, downloaded, downloaded))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, key+".solv"), nil, 0644))
+	require.NoError(t, os.Chtimes(filepath.Join(root, key), used, used))
+
+	return key
+}
+
+func exists(root, name string) bool {
+	_, err := os.Stat(filepath.Join(root, name))
+	return err == nil
+}
+
+func TestCacheKey(t *testing.T) {
+	key := cacheKey(CacheRepo{ID: "fedora-updates", URL: "https://example.com/repo"})
+	require.Regexp(t, "^fedora-updates-[0-9a-f]{16}$", key)
+
+	require.Equal(t, key, keyOf(key))
+	require.Equal(t, key, keyOf(key+".solv"))
+	require.Equal(t, key, keyOf(key+"-filenames.solvx"))
+	require.Equal(t, "", keyOf("."+key+".lock"))
+	require.Equal(t, "", keyOf(".lock"))
+	require.Equal(t, "", keyOf("expired_repos.json"))
+}
+
+func TestParseMetadataExpire(t *testing.T) {
+	for s, d := range map[string]time.Duration{
+		"never": -1,
+		"-1":    -1,
+		"90":    90 * time.Second,
+		"90s":   90 * time.Second,
+		"15m":   15 * time.Minute,
+		"6h":    6 * time.Hour,
+		"2d":    48 * time.Hour,
+	} {
+		parsed, err := parseMetadataExpire(s)
+		require.NoError(t, err)
+		require.Equal(t, d, parsed, s)
+	}
+
+	for _, s := range []string{"h", "-5m", "soon"} {
+		_, err := parseMetadataExpire(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	root, err := ioutil.TempDir("", "dnfjson-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	old := time.Now().Add(-2 * time.Hour)
+	stale := CacheRepo{ID: "0", URL: "https://example.com/stale"}
+	fresh := CacheRepo{ID: "1", URL: "https://example.com/fresh", MetadataExpire: "3h"}
+	pinned := CacheRepo{ID: "2", URL: "https://example.com/pinned", MetadataExpire: "never"}
+	unused := CacheRepo{ID: "3", URL: "https://example.com/unused"}
+	staleKey := addRepo(t, root, stale, 10, old, old)
+	freshKey := addRepo(t, root, fresh, 10, old, old)
+	pinnedKey := addRepo(t, root, pinned, 10, old, old)
+	unusedKey := addRepo(t, root, unused, 10, old, old)
+
+	cache := NewCache(root, DefaultMaxSize, time.Hour)
+	unlock, err := cache.Lock([]CacheRepo{stale, fresh, pinned})
+	require.NoError(t, err)
+	unlock()
+
+	require.False(t, exists(root, staleKey))
+	require.False(t, exists(root, staleKey+".solv"))
+	require.True(t, exists(root, freshKey))
+	require.True(t, exists(root, pinnedKey))
+
+	// only repositories which are used expire
+	require.True(t, exists(root, unusedKey))
+
+	info, err := os.Stat(filepath.Join(root, freshKey))
+	require.NoError(t, err)
+	require.True(t, info.ModTime().After(old))
+
+	_, err = cache.Lock([]CacheRepo{{ID: "4", URL: "https://example.com", MetadataExpire: "soon"}})
+	require.Error(t, err)
+}
+
+func TestCacheEviction(t *testing.T) {
+	root, err := ioutil.TempDir("", "dnfjson-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	now := time.Now()
+	var keys []string
+	var repos []CacheRepo
+	for i, id := range []string{"0", "1", "2", "3"} {
+		repo := CacheRepo{ID: id, URL: "https://example.com/" + id}
+		repos = append(repos, repo)
+		used := now.Add(time.Duration(i-10) * time.Minute)
+		keys = append(keys, addRepo(t, root, repo, 100, now, used))
+	}
+
+	// the least recently used repository is in use and must be kept
+	cache := NewCache(root, 250, time.Hour)
+	unlock, err := cache.Lock(repos[:1])
+	require.NoError(t, err)
+	unlock()
+
+	require.True(t, exists(root, keys[0]))
+	require.False(t, exists(root, keys[1]))
+	require.False(t, exists(root, keys[2]))
+	require.True(t, exists(root, keys[3]))
+}
+
+func TestCacheLocking(t *testing.T) {
+	root, err := ioutil.TempDir("", "dnfjson-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	cache := NewCache(root, DefaultMaxSize, DefaultTTL)
+	a := CacheRepo{ID: "0", URL: "https://example.com/a"}
+	b := CacheRepo{ID: "0", URL: "https://example.com/b"}
+
+	// the metadata of `a` isn't downloaded yet, so it is locked exclusively
+	unlock, err := cache.Lock([]CacheRepo{a})
+	require.NoError(t, err)
+
+	// other repositories can be used concurrently
+	unlockB, err := cache.Lock([]CacheRepo{b})
+	require.NoError(t, err)
+	unlockB()
+
+	locked := make(chan struct{})
+	go func() {
+		unlockA, err := cache.Lock([]CacheRepo{b, a})
+		if err == nil {
+			unlockA()
+		}
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		require.Fail(t, "repository was locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	<-locked
+}
+
+func TestCacheSharedLocking(t *testing.T) {
+	root, err := ioutil.TempDir("", "dnfjson-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	// repositories whose metadata is downloaded already can be used concurrently
+	repo := CacheRepo{ID: "0", URL: "https://example.com/repo"}
+	addRepo(t, root, repo, 10, time.Now(), time.Now())
+
+	cache := NewCache(root, DefaultMaxSize, DefaultTTL)
+	unlock1, err := cache.Lock([]CacheRepo{repo})
+	require.NoError(t, err)
+	unlock2, err := cache.Lock([]CacheRepo{repo})
+	require.NoError(t, err)
+	unlock2()
+	unlock1()
+}
@@ -0,0 +1,446 @@
+// Package fsnode checks the files and directories which blueprints embed in
+// images, and generates the osbuild stages which create them.
+package fsnode
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	osbuild1 "github.com/osbuild/osbuild-composer/internal/osbuild1"
+	osbuild2 "github.com/osbuild/osbuild-composer/internal/osbuild2"
+)
+
+// MaxFileSize is the maximum size of the content of a file in bytes.
+// Customizations are meant for small configuration files, which are stored as
+// part of the blueprint and the manifest.
+const MaxFileSize = 512 * 1024
+
+// PathAllowList are the directories below which files and directories can be
+// created.
+var PathAllowList = []string{"/etc", "/root", "/usr/local", "/opt", "/srv", "/var"}
+
+// PathDenyList are the paths below PathAllowList, which cannot be created
+// (or replaced), because other customizations or the image build manage them.
+// Paths below them cannot be created either.
+var PathDenyList = []string{
+	"/etc/fstab",
+	"/etc/crypttab",
+	"/etc/passwd",
+	"/etc/shadow",
+	"/etc/group",
+	"/etc/gshadow",
+	"/etc/hostname",
+	"/etc/machine-id",
+	"/etc/selinux",
+	"/var/lock",
+	"/var/run",
+}
+
+// Directory in which the units defined by blueprints are installed
+const unitDir = "/etc/systemd/system"
+
+// Directories of the repositories and GPG keys defined by blueprints
+const repoDir = "/etc/yum.repos.d"
+const gpgKeyDir = "/etc/pki/rpm-gpg"
+
+// Directory in which dnf keeps the state of modules
+const moduleStateDir = "/etc/dnf/modules.d"
+
+// Name of the copy stage input which holds the files
+const inputName = "inlinefile"
+
+var (
+	// user and group names as accepted by shadow-utils, or numeric ids
+	ownerRegex = regexp.MustCompile(`^([a-zA-Z0-9_.][a-zA-Z0-9_.-]{0,30}[a-zA-Z0-9_.$-]?|[0-9]+)$`)
+)
+
+// Check returns an error if any of the files or directories cannot be created
+// in an image.
+func Check(files []blueprint.FileCustomization, directories []blueprint.DirectoryCustomization) error {
+	seen := make(map[string]bool)
+	var filePaths []string
+
+	checkNode := func(kind, p, user, group, mode string) error {
+		if err := checkPath(p); err != nil {
+			return fmt.Errorf("invalid %s customization: %v", kind, err)
+		}
+		if seen[p] {
+			return fmt.Errorf("invalid %s customization: %s is customized more than once", kind, p)
+		}
+		seen[p] = true
+
+		for _, owner := range []string{user, group} {
+			if owner != "" && !ownerRegex.MatchString(owner) {
+				return fmt.Errorf("invalid %s customization: %q is not a valid user or group for %s", kind, owner, p)
+			}
+		}
+
+		if _, err := parseMode(mode); err != nil {
+			return fmt.Errorf("invalid %s customization: %v", kind, err)
+		}
+
+		return nil
+	}
+
+	for _, d := range directories {
+		if err := checkNode("directory", d.Path, d.User, d.Group, d.Mode); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range files {
+		if err := checkNode("file", f.Path, f.User, f.Group, f.Mode); err != nil {
+			return err
+		}
+		data, err := decode(f)
+		if err != nil {
+			return fmt.Errorf("invalid file customization: %v", err)
+		}
+		if len(data) > MaxFileSize {
+			return fmt.Errorf("invalid file customization: %s is larger than %d bytes", f.Path, MaxFileSize)
+		}
+		filePaths = append(filePaths, f.Path)
+	}
+
+	// files cannot be parents of other files or directories
+	for _, f := range filePaths {
+		for p := range seen {
+			if strings.HasPrefix(p, f+"/") {
+				return fmt.Errorf("invalid file customization: %s is a file, but %s is customized", f, p)
+			}
+		}
+	}
+
+	return nil
+}
+
+// UnitFiles returns the files of the systemd units defined by `services`, or
+// nil if it doesn't define any. They must be created before the units are
+// enabled.
+func UnitFiles(services *blueprint.ServicesCustomization) []blueprint.FileCustomization {
+	if services == nil {
+		return nil
+	}
+
+	var files []blueprint.FileCustomization
+	for _, unit := range services.Units {
+		files = append(files, blueprint.FileCustomization{
+			Path: path.Join(unitDir, unit.Name),
+			Mode: "0644",
+			Data: unit.Data,
+		})
+	}
+	return files
+}
+
+// Files returns the files customized by `c` and `modules`: the files of its
+// file customizations, the definitions and GPG keys of its repositories, and
+// the state of the enabled module streams.
+func Files(c *blueprint.Customizations, modules []blueprint.ModuleStream) []blueprint.FileCustomization {
+	files := append(RepositoryFiles(c.GetRepositories()), ModuleFiles(modules)...)
+	return append(files, c.GetFiles()...)
+}
+
+// ModuleFiles returns the files in /etc/dnf/modules.d which mark `modules` as
+// enabled, or nil if there are none. Packages are installed with rpm, so dnf
+// in the image would otherwise not know which streams they belong to.
+func ModuleFiles(modules []blueprint.ModuleStream) []blueprint.FileCustomization {
+	var files []blueprint.FileCustomization
+	for _, m := range modules {
+		lines := []string{
+			"[" + m.Name + "]",
+			"name=" + m.Name,
+			"stream=" + m.Stream,
+			"profiles=" + strings.Join(m.Profiles, ","),
+			"state=enabled",
+		}
+		files = append(files, blueprint.FileCustomization{
+			Path: path.Join(moduleStateDir, m.Name+".module"),
+			Mode: "0644",
+			Data: strings.Join(lines, "\n") + "\n",
+		})
+	}
+	return files
+}
+
+// RepositoryFiles returns the .repo files in /etc/yum.repos.d which define
+// `repos`, and the files in /etc/pki/rpm-gpg with their GPG keys, or nil if
+// there are no repositories.
+func RepositoryFiles(repos []blueprint.RepositoryCustomization) []blueprint.FileCustomization {
+	var files []blueprint.FileCustomization
+	var filenames []string
+	sections := make(map[string][]string)
+
+	for _, repo := range repos {
+		lines := []string{"[" + repo.ID + "]"}
+		if repo.Name != "" {
+			lines = append(lines, "name="+repo.Name)
+		}
+		switch {
+		case repo.BaseURL != "":
+			lines = append(lines, "baseurl="+repo.BaseURL)
+		case repo.Metalink != "":
+			lines = append(lines, "metalink="+repo.Metalink)
+		case repo.Mirrorlist != "":
+			lines = append(lines, "mirrorlist="+repo.Mirrorlist)
+		}
+		lines = append(lines, "enabled="+boolOption(repo.IsEnabled()))
+		lines = append(lines, "gpgcheck="+boolOption(repo.CheckGPG()))
+
+		if len(repo.GPGKeys) > 0 {
+			keyPath := path.Join(gpgKeyDir, "RPM-GPG-KEY-"+repo.ID)
+			lines = append(lines, "gpgkey=file://"+keyPath)
+			var keys []string
+			for _, key := range repo.GPGKeys {
+				keys = append(keys, strings.TrimSpace(key)+"\n")
+			}
+			files = append(files, blueprint.FileCustomization{
+				Path: keyPath,
+				Mode: "0644",
+				Data: strings.Join(keys, ""),
+			})
+		}
+
+		if repo.Priority != nil {
+			lines = append(lines, "priority="+strconv.Itoa(*repo.Priority))
+		}
+		if !repo.VerifySSL() {
+			lines = append(lines, "sslverify=0")
+		}
+		if repo.ModuleHotfixes {
+			lines = append(lines, "module_hotfixes=1")
+		}
+
+		filename := repo.RepoFilename()
+		if _, ok := sections[filename]; !ok {
+			filenames = append(filenames, filename)
+		}
+		sections[filename] = append(sections[filename], strings.Join(lines, "\n")+"\n")
+	}
+
+	for _, filename := range filenames {
+		files = append(files, blueprint.FileCustomization{
+			Path: path.Join(repoDir, filename),
+			Mode: "0644",
+			Data: strings.Join(sections[filename], "\n"),
+		})
+	}
+	return files
+}
+
+func boolOption(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func checkPath(p string) error {
+	if !path.IsAbs(p) || path.Clean(p) != p {
+		return fmt.Errorf("path %q must be an absolute, clean path", p)
+	}
+
+	if !below(p, PathAllowList) {
+		return fmt.Errorf("path %s is not allowed, only paths below %s are", p, strings.Join(PathAllowList, ", "))
+	}
+
+	for _, denied := range PathDenyList {
+		if p == denied || strings.HasPrefix(p, denied+"/") {
+			return fmt.Errorf("path %s is not allowed, because %s is managed by the image build", p, denied)
+		}
+	}
+
+	return nil
+}
+
+// Returns whether `p` is strictly below any of `dirs`.
+func below(p string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses an octal mode. An empty string is the default mode (0).
+func parseMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return 0, nil
+	}
+
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || m > 07777 {
+		return 0, fmt.Errorf("mode %q must be an octal number no larger than 07777", mode)
+	}
+
+	return os.FileMode(m), nil
+}
+
+func decode(f blueprint.FileCustomization) ([]byte, error) {
+	switch f.Encoding {
+	case "":
+		return []byte(f.Data), nil
+	case "base64":
+		data, err := base64.StdEncoding.DecodeString(f.Data)
+		if err != nil {
+			return nil, fmt.Errorf("content of %s is not valid base64: %v", f.Path, err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q of %s, only base64 is supported", f.Encoding, f.Path)
+	}
+}
+
+// Returns the checksum of the content of a file, as used for sources in
+// osbuild manifests.
+func checksum(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+// InlineSource returns the osbuild2 source holding the contents of `files`,
+// or nil if there are no files. The files must have been checked with
+// Check().
+func InlineSource(files []blueprint.FileCustomization) *osbuild2.InlineSource {
+	if len(files) == 0 {
+		return nil
+	}
+
+	source := &osbuild2.InlineSource{
+		Items: make(map[string]osbuild2.InlineSourceItem),
+	}
+	for _, f := range files {
+		data, _ := decode(f)
+		source.Items[checksum(data)] = osbuild2.InlineSourceItem{
+			Encoding: "base64",
+			Data:     base64.StdEncoding.EncodeToString(data),
+		}
+	}
+
+	return source
+}
+
+// Stages returns the osbuild2 stages which create `directories` and `files`,
+// in this order, and set their owners and modes. The contents of the files
+// are taken from the source returned by InlineSource(). The stages must run
+// after the users and groups have been created. The files and directories
+// must have been checked with Check().
+func Stages(files []blueprint.FileCustomization, directories []blueprint.DirectoryCustomization) []*osbuild2.Stage {
+	var stages []*osbuild2.Stage
+
+	chmod := make(map[string]osbuild2.ChmodStagePathOptions)
+	chown := make(map[string]osbuild2.ChownStagePathOptions)
+	setAttrs := func(p, user, group, mode string) {
+		if mode != "" {
+			chmod[p] = osbuild2.ChmodStagePathOptions{Mode: mode}
+		}
+		if user != "" || group != "" {
+			chown[p] = osbuild2.ChownStagePathOptions{User: user, Group: group}
+		}
+	}
+
+	if len(directories) > 0 {
+		var paths []osbuild2.MkdirStagePath
+		for _, d := range directories {
+			paths = append(paths, osbuild2.MkdirStagePath{
+				Path:    d.Path,
+				Parents: d.EnsureParents,
+				ExistOk: true,
+			})
+			setAttrs(d.Path, d.User, d.Group, d.Mode)
+		}
+		stages = append(stages, osbuild2.NewMkdirStage(&osbuild2.MkdirStageOptions{Paths: paths}))
+	}
+
+	if len(files) > 0 {
+		var checksums []string
+		var paths []osbuild2.CopyStagePath
+		for _, f := range files {
+			data, _ := decode(f)
+			sum := checksum(data)
+			checksums = append(checksums, sum)
+			paths = append(paths, osbuild2.CopyStagePath{
+				From: fmt.Sprintf("input://%s/%s", inputName, sum),
+				To:   "tree://" + f.Path,
+			})
+			setAttrs(f.Path, f.User, f.Group, f.Mode)
+		}
+		inputs := osbuild2.CopyStageFilesInputs{inputName: osbuild2.NewFilesInputRef(dedup(checksums))}
+		stages = append(stages, osbuild2.NewCopyStage(&osbuild2.CopyStageOptions{Paths: paths}, &inputs))
+	}
+
+	if len(chmod) > 0 {
+		stages = append(stages, osbuild2.NewChmodStage(&osbuild2.ChmodStageOptions{Items: chmod}))
+	}
+	if len(chown) > 0 {
+		stages = append(stages, osbuild2.NewChownStage(&osbuild2.ChownStageOptions{Items: chown}))
+	}
+
+	return stages
+}
+
+// ScriptStage returns an osbuild1 script stage which creates `directories`
+// and `files`, for manifests which cannot embed files in sources. It must run
+// after the users and groups have been created. The files and directories
+// must have been checked with Check().
+func ScriptStage(files []blueprint.FileCustomization, directories []blueprint.DirectoryCustomization) *osbuild1.Stage {
+	if len(files) == 0 && len(directories) == 0 {
+		return nil
+	}
+
+	lines := []string{"#!/bin/sh", "set -eu"}
+	setAttrs := func(p, user, group, mode string) {
+		if mode != "" {
+			lines = append(lines, fmt.Sprintf("chmod %s %s", mode, quote(p)))
+		}
+		if user != "" {
+			lines = append(lines, fmt.Sprintf("chown %s %s", quote(user), quote(p)))
+		}
+		if group != "" {
+			lines = append(lines, fmt.Sprintf("chgrp %s %s", quote(group), quote(p)))
+		}
+	}
+
+	for _, d := range directories {
+		mkdir := "mkdir -p"
+		if !d.EnsureParents {
+			mkdir = fmt.Sprintf("test -d %s || mkdir", quote(d.Path))
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", mkdir, quote(d.Path)))
+		setAttrs(d.Path, d.User, d.Group, d.Mode)
+	}
+
+	for _, f := range files {
+		data, _ := decode(f)
+		lines = append(lines, fmt.Sprintf("echo %s | base64 -d > %s", base64.StdEncoding.EncodeToString(data), quote(f.Path)))
+		setAttrs(f.Path, f.User, f.Group, f.Mode)
+	}
+
+	return osbuild1.NewScriptStage(osbuild1.NewScriptStageOptions(strings.Join(lines, "\n") + "\n"))
+}
+
+// Quotes `s` for the shell.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func dedup(s []string) []string {
+	set := make(map[string]bool)
+	var result []string
+	for _, e := range s {
+		if !set[e] {
+			set[e] = true
+			result = append(result, e)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
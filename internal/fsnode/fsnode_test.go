@@ -0,0 +1,230 @@
+package fsnode
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	osbuild1 "github.com/osbuild/osbuild-composer/internal/osbuild1"
+	osbuild2 "github.com/osbuild/osbuild-composer/internal/osbuild2"
+)
+
+func TestCheck(t *testing.T) {
+	valid := []struct {
+		files       []blueprint.FileCustomization
+		directories []blueprint.DirectoryCustomization
+	}{
+		{nil, nil},
+		{
+			[]blueprint.FileCustomization{
+				{Path: "/etc/foo/foo.conf", Data: "foo=1\n", User: "root", Group: "1000", Mode: "0640"},
+				{Path: "/root/.bashrc", Data: base64.StdEncoding.EncodeToString([]byte("bar")), Encoding: "base64"},
+			},
+			[]blueprint.DirectoryCustomization{
+				{Path: "/etc/foo", Mode: "0755"},
+				{Path: "/opt/foo/bar/baz", EnsureParents: true, User: "foo$"},
+			},
+		},
+	}
+	for _, c := range valid {
+		assert.NoError(t, Check(c.files, c.directories))
+	}
+
+	invalidFiles := []blueprint.FileCustomization{
+		{Path: "etc/foo"},
+		{Path: "/etc/../usr/bin/foo"},
+		{Path: "/etc"},
+		{Path: "/usr/bin/foo"},
+		{Path: "/etcetera/foo"},
+		{Path: "/etc/passwd"},
+		{Path: "/etc/selinux/config"},
+		{Path: "/etc/foo", Mode: "0999"},
+		{Path: "/etc/foo", Mode: "17777"},
+		{Path: "/etc/foo", User: "-root"},
+		{Path: "/etc/foo", Group: "root group"},
+		{Path: "/etc/foo", Encoding: "gzip"},
+		{Path: "/etc/foo", Encoding: "base64", Data: "not base64!"},
+		{Path: "/etc/foo", Data: strings.Repeat("x", MaxFileSize+1)},
+	}
+	for _, f := range invalidFiles {
+		assert.Error(t, Check([]blueprint.FileCustomization{f}, nil), f.Path)
+	}
+
+	// paths are customized only once
+	err := Check([]blueprint.FileCustomization{{Path: "/etc/foo"}}, []blueprint.DirectoryCustomization{{Path: "/etc/foo"}})
+	assert.Error(t, err)
+
+	// files don't contain other files
+	err = Check([]blueprint.FileCustomization{{Path: "/etc/foo"}, {Path: "/etc/foo/bar"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestStages(t *testing.T) {
+	files := []blueprint.FileCustomization{
+		{Path: "/etc/foo/foo.conf", Data: "foo=1\n", User: "root", Mode: "0640"},
+		{Path: "/etc/foo/copy.conf", Data: base64.StdEncoding.EncodeToString([]byte("foo=1\n")), Encoding: "base64"},
+	}
+	directories := []blueprint.DirectoryCustomization{
+		{Path: "/etc/foo", Group: "wheel", EnsureParents: true},
+	}
+	require.NoError(t, Check(files, directories))
+
+	// both files have the same content
+	sum := checksum([]byte("foo=1\n"))
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", sum)
+	assert.Equal(t, &osbuild2.InlineSource{
+		Items: map[string]osbuild2.InlineSourceItem{
+			sum: {Encoding: "base64", Data: base64.StdEncoding.EncodeToString([]byte("foo=1\n"))},
+		},
+	}, InlineSource(files))
+
+	inputs := osbuild2.CopyStageFilesInputs{"inlinefile": osbuild2.NewFilesInputRef([]string{sum})}
+	assert.Equal(t, []*osbuild2.Stage{
+		osbuild2.NewMkdirStage(&osbuild2.MkdirStageOptions{
+			Paths: []osbuild2.MkdirStagePath{{Path: "/etc/foo", Parents: true, ExistOk: true}},
+		}),
+		osbuild2.NewCopyStage(&osbuild2.CopyStageOptions{
+			Paths: []osbuild2.CopyStagePath{
+				{From: "input://inlinefile/" + sum, To: "tree:///etc/foo/foo.conf"},
+				{From: "input://inlinefile/" + sum, To: "tree:///etc/foo/copy.conf"},
+			},
+		}, &inputs),
+		osbuild2.NewChmodStage(&osbuild2.ChmodStageOptions{
+			Items: map[string]osbuild2.ChmodStagePathOptions{"/etc/foo/foo.conf": {Mode: "0640"}},
+		}),
+		osbuild2.NewChownStage(&osbuild2.ChownStageOptions{
+			Items: map[string]osbuild2.ChownStagePathOptions{
+				"/etc/foo":          {Group: "wheel"},
+				"/etc/foo/foo.conf": {User: "root"},
+			},
+		}),
+	}, Stages(files, directories))
+
+	assert.Nil(t, InlineSource(nil))
+	assert.Empty(t, Stages(nil, nil))
+}
+
+func TestScriptStage(t *testing.T) {
+	files := []blueprint.FileCustomization{
+		{Path: "/etc/it's.conf", Data: "foo=1\n", User: "root", Group: "wheel", Mode: "0640"},
+	}
+	directories := []blueprint.DirectoryCustomization{
+		{Path: "/etc/foo/bar", EnsureParents: true},
+		{Path: "/etc/baz"},
+	}
+	require.NoError(t, Check(files, directories))
+
+	stage := ScriptStage(files, directories)
+	require.NotNil(t, stage)
+	assert.Equal(t, "org.osbuild.script", stage.Name)
+	assert.Equal(t, `#!/bin/sh
+set -eu
+mkdir -p '/etc/foo/bar'
+test -d '/etc/baz' || mkdir '/etc/baz'
+echo Zm9vPTEK | base64 -d > '/etc/it'\''s.conf'
+chmod 0640 '/etc/it'\''s.conf'
+chown 'root' '/etc/it'\''s.conf'
+chgrp 'wheel' '/etc/it'\''s.conf'
+`, stage.Options.(*osbuild1.ScriptStageOptions).Script)
+
+	assert.Nil(t, ScriptStage(nil, nil))
+}
+
+func TestUnitFiles(t *testing.T) {
+	services := &blueprint.ServicesCustomization{
+		Enabled: []string{"firstboot.timer"},
+		Units: []blueprint.SystemdUnitCustomization{
+			{Name: "firstboot.service", Data: "[Service]\nExecStart=/usr/local/bin/firstboot\n"},
+			{Name: "firstboot.timer", Data: "[Timer]\nOnBootSec=1min\n"},
+		},
+	}
+
+	files := UnitFiles(services)
+	assert.Equal(t, []blueprint.FileCustomization{
+		{Path: "/etc/systemd/system/firstboot.service", Mode: "0644", Data: "[Service]\nExecStart=/usr/local/bin/firstboot\n"},
+		{Path: "/etc/systemd/system/firstboot.timer", Mode: "0644", Data: "[Timer]\nOnBootSec=1min\n"},
+	}, files)
+	assert.NoError(t, Check(files, nil))
+
+	// units cannot be defined twice
+	assert.Error(t, Check(append(files, blueprint.FileCustomization{Path: "/etc/systemd/system/firstboot.timer"}), nil))
+
+	assert.Nil(t, UnitFiles(nil))
+	assert.Nil(t, UnitFiles(&blueprint.ServicesCustomization{Enabled: []string{"sshd"}}))
+}
+
+func TestRepositoryFiles(t *testing.T) {
+	priority := 10
+	disabled := false
+	key := "-----BEGIN PGP PUBLIC KEY BLOCK-----\n\nmQINBGAcScoBEADLf8YHke\n-----END PGP PUBLIC KEY BLOCK-----"
+	repos := []blueprint.RepositoryCustomization{
+		{
+			ID:       "example",
+			Name:     "Example",
+			BaseURL:  "https://example.com/repo/$basearch/",
+			GPGKeys:  []string{key, key + "\n"},
+			Priority: &priority,
+		},
+		{
+			ID:             "example-modules",
+			Metalink:       "https://example.com/metalink?repo=modules",
+			Enabled:        &disabled,
+			SSLVerify:      &disabled,
+			ModuleHotfixes: true,
+			Filename:       "example.repo",
+		},
+		{
+			ID:         "other",
+			Mirrorlist: "https://example.com/mirrorlist",
+		},
+	}
+
+	files := RepositoryFiles(repos)
+	assert.Equal(t, []blueprint.FileCustomization{
+		{Path: "/etc/pki/rpm-gpg/RPM-GPG-KEY-example", Mode: "0644", Data: key + "\n" + key + "\n"},
+		{Path: "/etc/yum.repos.d/example.repo", Mode: "0644", Data: `[example]
+name=Example
+baseurl=https://example.com/repo/$basearch/
+enabled=1
+gpgcheck=1
+gpgkey=file:///etc/pki/rpm-gpg/RPM-GPG-KEY-example
+priority=10
+
+[example-modules]
+metalink=https://example.com/metalink?repo=modules
+enabled=0
+gpgcheck=0
+sslverify=0
+module_hotfixes=1
+`},
+		{Path: "/etc/yum.repos.d/other.repo", Mode: "0644", Data: "[other]\nmirrorlist=https://example.com/mirrorlist\nenabled=1\ngpgcheck=0\n"},
+	}, files)
+	assert.NoError(t, Check(files, nil))
+
+	assert.Nil(t, RepositoryFiles(nil))
+	assert.Equal(t, files, Files(&blueprint.Customizations{Repositories: repos}, nil))
+}
+
+func TestModuleFiles(t *testing.T) {
+	modules := []blueprint.ModuleStream{
+		{Name: "nodejs", Stream: "18"},
+		{Name: "postgresql", Stream: "15", Profiles: []string{"server", "client"}},
+	}
+
+	files := ModuleFiles(modules)
+	assert.Equal(t, []blueprint.FileCustomization{
+		{Path: "/etc/dnf/modules.d/nodejs.module", Mode: "0644", Data: "[nodejs]\nname=nodejs\nstream=18\nprofiles=\nstate=enabled\n"},
+		{Path: "/etc/dnf/modules.d/postgresql.module", Mode: "0644", Data: "[postgresql]\nname=postgresql\nstream=15\nprofiles=server,client\nstate=enabled\n"},
+	}, files)
+	assert.NoError(t, Check(files, nil))
+
+	// the state cannot be customized as well
+	c := &blueprint.Customizations{Files: []blueprint.FileCustomization{{Path: "/etc/dnf/modules.d/nodejs.module"}}}
+	assert.Error(t, Check(Files(c, modules), nil))
+
+	assert.Nil(t, ModuleFiles(nil))
+}
@@ -0,0 +1,768 @@
+// Package dbjobqueue implements the interfaces in package jobqueue backed by a
+// PostgreSQL database.
+//
+// In contrast to fsjobqueue, this package does not keep any state in memory.
+// Multiple `dbJobQueue` objects, possibly in different composer instances,
+// can safely share the same database. Jobs are handed out with `SELECT ...
+// FOR UPDATE SKIP LOCKED`, which ensures that a job is never dequeued twice
+// and that concurrent calls to Dequeue() don't block each other. Goroutines
+// waiting in Dequeue() are woken up via LISTEN/NOTIFY on the `jobs` channel.
+//
+// The database schema can be found in the `schemas` directory.
+package dbjobqueue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+)
+
+const (
+	sqlNotify = `NOTIFY jobs`
+
+	sqlEnqueue = `
+		INSERT INTO jobs(id, type, args, dependencies, priority, queued_at)
+		VALUES ($1, $2, $3, $4, $5, now())`
+	sqlCountJobs = `
+		SELECT count(*)
+		FROM jobs
+		WHERE id = ANY($1)`
+	// With $3, jobs of the same priority are ordered by when a job of
+	// their tenant was last started, which interleaves the tenants
+	sqlDequeue = `
+		UPDATE jobs
+		SET started_at = now(), owner = $2
+		WHERE id = (
+		  SELECT id
+		  FROM ready_jobs
+		  WHERE type = ANY($1)
+		  ORDER BY priority DESC,
+		           CASE WHEN $3 THEN (
+		             SELECT max(started_at)
+		             FROM jobs AS served
+		             WHERE coalesce(served.args->>'tenant', '') = coalesce(ready_jobs.args->>'tenant', '')
+		           ) END ASC NULLS FIRST,
+		           queued_at ASC
+		  LIMIT 1
+		  FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, type, args, dependencies`
+	sqlLockJob = `
+		SELECT started_at, finished_at, canceled
+		FROM jobs
+		WHERE id = $1
+		FOR UPDATE`
+	sqlFinishJob = `
+		UPDATE jobs
+		SET finished_at = now(), result = $2
+		WHERE id = $1`
+	sqlRequeueJob = `
+		UPDATE jobs
+		SET started_at = NULL,
+		    owner = NULL,
+		    failures = failures + 1,
+		    dead_lettered = ($2 > 0 AND failures + 1 >= $2)
+		WHERE id = $1
+		RETURNING dead_lettered`
+	sqlRetryJob = `
+		UPDATE jobs
+		SET started_at = NULL, owner = NULL, retries = retries + 1, not_before = $2
+		WHERE id = $1`
+	sqlQueryJobRetries = `
+		SELECT retries
+		FROM jobs
+		WHERE id = $1`
+	sqlQueryDeadLetterJobs = `
+		SELECT id
+		FROM jobs
+		WHERE dead_lettered = TRUE AND canceled = FALSE
+		ORDER BY queued_at`
+	sqlQueryUnfinishedJobs = `
+		SELECT id
+		FROM jobs
+		WHERE finished_at IS NULL AND canceled = FALSE AND dead_lettered = FALSE
+		ORDER BY queued_at`
+	// The conditions of ListJobs(), which are shared by the query for the
+	// page and the one for the total number of jobs
+	sqlJobFilter = `
+		WHERE (NOT $1 OR id = ANY($2))
+		  AND (cardinality($3::varchar[]) = 0 OR type = ANY($3))
+		  AND (cardinality($4::varchar[]) = 0 OR
+		       CASE
+		         WHEN canceled THEN 'canceled'
+		         WHEN finished_at IS NOT NULL THEN 'finished'
+		         WHEN started_at IS NOT NULL THEN 'running'
+		         ELSE 'pending'
+		       END = ANY($4))
+		  AND ($5::timestamptz IS NULL OR queued_at >= $5)
+		  AND ($6::timestamptz IS NULL OR queued_at < $6)
+		  AND (NOT $7 OR coalesce(args->>'tenant', '') = $8)
+		  AND (NOT $9 OR NOT EXISTS (
+		    SELECT 1
+		    FROM jobs AS dependant
+		    WHERE jobs.id = ANY(dependant.dependencies)
+		      AND (cardinality($3::varchar[]) = 0 OR dependant.type = ANY($3))
+		      AND (NOT $7 OR coalesce(dependant.args->>'tenant', '') = $8)
+		  ))
+		  AND ($10 = '' OR args->'idempotency'->>'key' = $10)
+		  AND ($11::varchar IS NULL OR
+		       (started_at IS NOT NULL AND finished_at IS NULL AND canceled = FALSE AND owner = $11))`
+	sqlQueryJobs = `
+		SELECT id
+		FROM jobs` + sqlJobFilter + `
+		ORDER BY queued_at
+		OFFSET $12
+		LIMIT $13`
+	sqlCountFilteredJobs = `
+		SELECT count(*)
+		FROM jobs` + sqlJobFilter
+	sqlLockDeadLetterJob = `
+		SELECT canceled, dead_lettered
+		FROM jobs
+		WHERE id = $1
+		FOR UPDATE`
+	sqlRequeueDeadLetterJob = `
+		UPDATE jobs
+		SET failures = 0, dead_lettered = FALSE
+		WHERE id = $1`
+	sqlCancelJob = `
+		UPDATE jobs
+		SET canceled = TRUE
+		WHERE id = $1 AND finished_at IS NULL`
+	sqlQueryJobStatus = `
+		SELECT result, queued_at, started_at, finished_at, canceled, dependencies
+		FROM jobs
+		WHERE id = $1`
+	sqlQueryJob = `
+		SELECT type, args, dependencies
+		FROM jobs
+		WHERE id = $1`
+)
+
+// Time after which Dequeue() looks for new jobs even if it didn't receive a
+// notification. Notifications can get lost when the connection of the
+// listener breaks, and retried jobs become due without one.
+const pollInterval = time.Minute
+
+type dbJobQueue struct {
+	db       *sql.DB
+	listener *pq.Listener
+
+	// Set of goroutines waiting for notifications on the jobs channel.
+	listenersMutex sync.Mutex
+	listeners      map[chan struct{}]struct{}
+}
+
+// Create a new dbJobQueue object for the PostgreSQL database at `url`. The
+// database must already contain the schema from the `schemas` directory. If
+// `url` is empty, the connection parameters are taken from the standard libpq
+// environment variables (PGHOST, PGDATABASE, PGUSER, PGPASSWORD, ...).
+func New(url string) (*dbJobQueue, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %v", err)
+	}
+
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to database: %v", err)
+	}
+
+	listener := pq.NewListener(url, 10*time.Second, time.Minute, nil)
+	err = listener.Listen("jobs")
+	if err != nil {
+		db.Close()
+		listener.Close()
+		return nil, fmt.Errorf("error listening on jobs channel: %v", err)
+	}
+
+	q := &dbJobQueue{
+		db:        db,
+		listener:  listener,
+		listeners: make(map[chan struct{}]struct{}),
+	}
+
+	go q.forwardNotifications()
+
+	return q, nil
+}
+
+// Close closes all connections to the database.
+func (q *dbJobQueue) Close() {
+	q.listener.Close()
+	q.db.Close()
+}
+
+func (q *dbJobQueue) Enqueue(jobType string, args interface{}, dependencies []uuid.UUID, priority int) (uuid.UUID, error) {
+	rawArgs, err := json.Marshal(args)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("error marshaling job arguments: %v", err)
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("error starting database transaction: %v", err)
+	}
+	defer rollback(tx)
+
+	// Verify dependencies early, so that the job doesn't get written when
+	// one of them doesn't exist.
+	deps := uuidArray(dependencies)
+	if len(dependencies) > 0 {
+		var n int
+		err = tx.QueryRow(sqlCountJobs, deps).Scan(&n)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("error querying dependencies: %v", err)
+		}
+		if n != len(uniqueUUIDs(dependencies)) {
+			return uuid.Nil, jobqueue.ErrNotExist
+		}
+	}
+
+	id := uuid.New()
+	_, err = tx.Exec(sqlEnqueue, id, jobType, string(rawArgs), deps, priority)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("error enqueuing job: %v", err)
+	}
+
+	_, err = tx.Exec(sqlNotify)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("error notifying jobs channel: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("error committing database transaction: %v", err)
+	}
+
+	return id, nil
+}
+
+func (q *dbJobQueue) EnqueueJobs(specs []jobqueue.JobSpec) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, len(specs))
+	rawArgs := make([]string, len(specs))
+	for i, spec := range specs {
+		args, err := json.Marshal(spec.Args)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling job arguments: %v", err)
+		}
+		rawArgs[i] = string(args)
+
+		for _, d := range spec.Dependencies {
+			if d < 0 || d >= i {
+				return nil, jobqueue.ErrInvalidDependency
+			}
+		}
+
+		ids[i] = uuid.New()
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting database transaction: %v", err)
+	}
+	defer rollback(tx)
+
+	for i, spec := range specs {
+		deps := make([]uuid.UUID, len(spec.Dependencies))
+		for j, d := range spec.Dependencies {
+			deps[j] = ids[d]
+		}
+
+		_, err = tx.Exec(sqlEnqueue, ids[i], spec.Type, rawArgs[i], uuidArray(deps), spec.Priority)
+		if err != nil {
+			return nil, fmt.Errorf("error enqueuing job: %v", err)
+		}
+	}
+
+	_, err = tx.Exec(sqlNotify)
+	if err != nil {
+		return nil, fmt.Errorf("error notifying jobs channel: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing database transaction: %v", err)
+	}
+
+	return ids, nil
+}
+
+func (q *dbJobQueue) Dequeue(ctx context.Context, jobTypes []string, options jobqueue.DequeueOptions) (uuid.UUID, []uuid.UUID, string, json.RawMessage, error) {
+	// Return early if the context is already canceled.
+	if err := ctx.Err(); err != nil {
+		return uuid.Nil, nil, "", nil, err
+	}
+
+	for {
+		// Start listening before looking for a job, so that no
+		// notification is missed in between.
+		c := q.listen()
+
+		var id uuid.UUID
+		var jobType string
+		var args json.RawMessage
+		var deps pq.StringArray
+		err := q.db.QueryRowContext(ctx, sqlDequeue, pq.Array(jobTypes), options.Owner, options.Fair).Scan(&id, &jobType, &args, &deps)
+		if err == nil {
+			q.unlisten(c)
+
+			dependencies, err := parseUUIDs(deps)
+			if err != nil {
+				return uuid.Nil, nil, "", nil, err
+			}
+
+			return id, dependencies, jobType, args, nil
+		}
+		if err != sql.ErrNoRows {
+			q.unlisten(c)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return uuid.Nil, nil, "", nil, ctxErr
+			}
+			return uuid.Nil, nil, "", nil, fmt.Errorf("error dequeuing job: %v", err)
+		}
+
+		select {
+		case <-c:
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+		}
+		q.unlisten(c)
+
+		if err := ctx.Err(); err != nil {
+			return uuid.Nil, nil, "", nil, err
+		}
+	}
+}
+
+func (q *dbJobQueue) FinishJob(id uuid.UUID, result interface{}) error {
+	rawResult, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("error marshaling result: %v", err)
+	}
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting database transaction: %v", err)
+	}
+	defer rollback(tx)
+
+	err = lockRunningJob(tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(sqlFinishJob, id, string(rawResult))
+	if err != nil {
+		return fmt.Errorf("error finishing job %s: %v", id, err)
+	}
+
+	// Dependants of this job might be ready now.
+	_, err = tx.Exec(sqlNotify)
+	if err != nil {
+		return fmt.Errorf("error notifying jobs channel: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing database transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (q *dbJobQueue) CancelJob(id uuid.UUID) error {
+	res, err := q.db.Exec(sqlCancelJob, id)
+	if err != nil {
+		return fmt.Errorf("error canceling job %s: %v", id, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error canceling job %s: %v", id, err)
+	}
+
+	// Either the job has already finished, which is not an error, or it
+	// doesn't exist.
+	if n == 0 {
+		_, _, _, err := q.Job(id)
+		return err
+	}
+
+	return nil
+}
+
+func (q *dbJobQueue) RequeueJob(id uuid.UUID, maxFailures int) (bool, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("error starting database transaction: %v", err)
+	}
+	defer rollback(tx)
+
+	err = lockRunningJob(tx, id)
+	if err != nil {
+		return false, err
+	}
+
+	var deadLettered bool
+	err = tx.QueryRow(sqlRequeueJob, id, maxFailures).Scan(&deadLettered)
+	if err != nil {
+		return false, fmt.Errorf("error requeueing job %s: %v", id, err)
+	}
+
+	if !deadLettered {
+		_, err = tx.Exec(sqlNotify)
+		if err != nil {
+			return false, fmt.Errorf("error notifying jobs channel: %v", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, fmt.Errorf("error committing database transaction: %v", err)
+	}
+
+	return deadLettered, nil
+}
+
+func (q *dbJobQueue) RetryJob(id uuid.UUID, notBefore time.Time) error {
+	tx, err := q.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting database transaction: %v", err)
+	}
+	defer rollback(tx)
+
+	err = lockRunningJob(tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(sqlRetryJob, id, notBefore)
+	if err != nil {
+		return fmt.Errorf("error retrying job %s: %v", id, err)
+	}
+
+	// Workers only notice the job when they poll for jobs after it became
+	// due, unless it is due already.
+	_, err = tx.Exec(sqlNotify)
+	if err != nil {
+		return fmt.Errorf("error notifying jobs channel: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing database transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (q *dbJobQueue) JobRetries(id uuid.UUID) (int, error) {
+	var retries int
+	err := q.db.QueryRow(sqlQueryJobRetries, id).Scan(&retries)
+	if err == sql.ErrNoRows {
+		return 0, jobqueue.ErrNotExist
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error querying retries of job %s: %v", id, err)
+	}
+	return retries, nil
+}
+
+func (q *dbJobQueue) DeadLetterJobs() ([]uuid.UUID, error) {
+	rows, err := q.db.Query(sqlQueryDeadLetterJobs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying dead-letter jobs: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("error querying dead-letter jobs: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error querying dead-letter jobs: %v", err)
+	}
+
+	return ids, nil
+}
+
+func (q *dbJobQueue) RequeueDeadLetterJob(id uuid.UUID) error {
+	tx, err := q.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting database transaction: %v", err)
+	}
+	defer rollback(tx)
+
+	var canceled, deadLettered bool
+	err = tx.QueryRow(sqlLockDeadLetterJob, id).Scan(&canceled, &deadLettered)
+	if err == sql.ErrNoRows {
+		return jobqueue.ErrNotExist
+	}
+	if err != nil {
+		return fmt.Errorf("error querying job %s: %v", id, err)
+	}
+
+	if canceled {
+		return jobqueue.ErrCanceled
+	}
+
+	if !deadLettered {
+		return jobqueue.ErrNotDeadLettered
+	}
+
+	_, err = tx.Exec(sqlRequeueDeadLetterJob, id)
+	if err != nil {
+		return fmt.Errorf("error requeueing job %s: %v", id, err)
+	}
+
+	_, err = tx.Exec(sqlNotify)
+	if err != nil {
+		return fmt.Errorf("error notifying jobs channel: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing database transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (q *dbJobQueue) UnfinishedJobs() ([]uuid.UUID, error) {
+	rows, err := q.db.Query(sqlQueryUnfinishedJobs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying unfinished jobs: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("error querying unfinished jobs: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error querying unfinished jobs: %v", err)
+	}
+
+	return ids, nil
+}
+
+func (q *dbJobQueue) ListJobs(filter jobqueue.JobFilter) ([]uuid.UUID, int, error) {
+	states := make([]string, len(filter.States))
+	for i, state := range filter.States {
+		states[i] = string(state)
+	}
+	var since, until sql.NullTime
+	if !filter.Since.IsZero() {
+		since = sql.NullTime{Time: filter.Since, Valid: true}
+	}
+	if !filter.Until.IsZero() {
+		until = sql.NullTime{Time: filter.Until, Valid: true}
+	}
+	tenant := ""
+	if filter.Tenant != nil {
+		tenant = *filter.Tenant
+	}
+	var owner sql.NullString
+	if filter.Owner != nil {
+		owner = sql.NullString{String: *filter.Owner, Valid: true}
+	}
+	args := []interface{}{filter.IDs != nil, uuidArray(filter.IDs), pq.Array(filter.Types), pq.Array(states), since, until, filter.Tenant != nil, tenant, filter.SkipDependencies, filter.IdempotencyKey, owner}
+
+	// NULL means no limit
+	var limit sql.NullInt64
+	if filter.Limit > 0 {
+		limit = sql.NullInt64{Int64: int64(filter.Limit), Valid: true}
+	}
+
+	rows, err := q.db.Query(sqlQueryJobs, append(args, filter.Offset, limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying jobs: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error querying jobs: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying jobs: %v", err)
+	}
+
+	var total int
+	err = q.db.QueryRow(sqlCountFilteredJobs, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting jobs: %v", err)
+	}
+
+	return ids, total, nil
+}
+
+func (q *dbJobQueue) JobStatus(id uuid.UUID) (result json.RawMessage, queued, started, finished time.Time, canceled bool, deps []uuid.UUID, err error) {
+	var rawResult []byte
+	var startedAt, finishedAt sql.NullTime
+	var rawDeps pq.StringArray
+	err = q.db.QueryRow(sqlQueryJobStatus, id).Scan(&rawResult, &queued, &startedAt, &finishedAt, &canceled, &rawDeps)
+	if err == sql.ErrNoRows {
+		err = jobqueue.ErrNotExist
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("error querying job %s: %v", id, err)
+		return
+	}
+
+	if rawResult != nil {
+		result = rawResult
+	}
+	started = startedAt.Time
+	finished = finishedAt.Time
+	deps, err = parseUUIDs(rawDeps)
+
+	return
+}
+
+func (q *dbJobQueue) Job(id uuid.UUID) (jobType string, args json.RawMessage, dependencies []uuid.UUID, err error) {
+	var rawDeps pq.StringArray
+	err = q.db.QueryRow(sqlQueryJob, id).Scan(&jobType, &args, &rawDeps)
+	if err == sql.ErrNoRows {
+		err = jobqueue.ErrNotExist
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("error querying job %s: %v", id, err)
+		return
+	}
+
+	dependencies, err = parseUUIDs(rawDeps)
+
+	return
+}
+
+// Locks the row of job `id` for the rest of the transaction `tx`. Returns an
+// error if the job doesn't exist or isn't running.
+func lockRunningJob(tx *sql.Tx, id uuid.UUID) error {
+	var startedAt, finishedAt sql.NullTime
+	var canceled bool
+	err := tx.QueryRow(sqlLockJob, id).Scan(&startedAt, &finishedAt, &canceled)
+	if err == sql.ErrNoRows {
+		return jobqueue.ErrNotExist
+	}
+	if err != nil {
+		return fmt.Errorf("error querying job %s: %v", id, err)
+	}
+
+	if canceled {
+		return jobqueue.ErrCanceled
+	}
+
+	if !startedAt.Valid || finishedAt.Valid {
+		return jobqueue.ErrNotRunning
+	}
+
+	return nil
+}
+
+// Forwards notifications on the jobs channel to all goroutines waiting in
+// Dequeue(). The listener sends a nil notification after reconnecting, which
+// is forwarded as well, because notifications might have been lost.
+func (q *dbJobQueue) forwardNotifications() {
+	for range q.listener.Notify {
+		q.listenersMutex.Lock()
+		for c := range q.listeners {
+			select {
+			case c <- struct{}{}:
+			default:
+			}
+		}
+		q.listenersMutex.Unlock()
+	}
+}
+
+func (q *dbJobQueue) listen() chan struct{} {
+	q.listenersMutex.Lock()
+	defer q.listenersMutex.Unlock()
+
+	c := make(chan struct{}, 1)
+	q.listeners[c] = struct{}{}
+	return c
+}
+
+func (q *dbJobQueue) unlisten(c chan struct{}) {
+	q.listenersMutex.Lock()
+	defer q.listenersMutex.Unlock()
+
+	delete(q.listeners, c)
+}
+
+func rollback(tx *sql.Tx) {
+	err := tx.Rollback()
+	if err != nil && err != sql.ErrTxDone {
+		log.Printf("error rolling back database transaction: %v", err)
+	}
+}
+
+func uuidArray(ids []uuid.UUID) interface{} {
+	a := make([]string, len(ids))
+	for i, id := range ids {
+		a[i] = id.String()
+	}
+	return pq.Array(a)
+}
+
+func uniqueUUIDs(ids []uuid.UUID) map[uuid.UUID]struct{} {
+	set := make(map[uuid.UUID]struct{})
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
+
+// Parses an array of uuids returned from the database. Returns nil for an
+// empty array, to match jobs which were enqueued without dependencies.
+func parseUUIDs(a []string) ([]uuid.UUID, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, len(a))
+	for i, s := range a {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uuid '%s' in database: %v", s, err)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
@@ -0,0 +1,32 @@
+-- Schema for the PostgreSQL-backed job queue (internal/jobqueue/dbjobqueue).
+
+CREATE TABLE jobs(
+	id uuid PRIMARY KEY,
+	type varchar NOT NULL,
+	args jsonb,
+	dependencies uuid[] NOT NULL DEFAULT '{}',
+	priority integer NOT NULL DEFAULT 0,
+	result jsonb,
+	queued_at timestamptz NOT NULL,
+	started_at timestamptz,
+	finished_at timestamptz,
+	canceled boolean NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX jobs_pending_idx ON jobs(type, priority DESC, queued_at)
+	WHERE started_at IS NULL AND canceled = FALSE;
+
+-- Jobs which can be handed out to workers: neither started nor canceled, and
+-- all their dependencies have finished.
+CREATE VIEW ready_jobs AS
+SELECT *
+FROM jobs
+WHERE started_at IS NULL
+  AND canceled = FALSE
+  AND NOT EXISTS (
+    SELECT 1
+    FROM jobs AS dependency
+    WHERE dependency.id = ANY(jobs.dependencies)
+      AND dependency.finished_at IS NULL
+  )
+ORDER BY priority DESC, queued_at ASC;
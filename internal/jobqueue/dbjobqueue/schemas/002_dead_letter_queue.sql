@@ -0,0 +1,24 @@
+-- Dead-letter queue: jobs which failed to finish `failures` times are marked
+-- as `dead_lettered` and are not handed out to workers anymore.
+
+ALTER TABLE jobs
+	ADD COLUMN failures integer NOT NULL DEFAULT 0,
+	ADD COLUMN dead_lettered boolean NOT NULL DEFAULT FALSE;
+
+DROP INDEX jobs_pending_idx;
+CREATE INDEX jobs_pending_idx ON jobs(type, priority DESC, queued_at)
+	WHERE started_at IS NULL AND canceled = FALSE AND dead_lettered = FALSE;
+
+CREATE OR REPLACE VIEW ready_jobs AS
+SELECT *
+FROM jobs
+WHERE started_at IS NULL
+  AND canceled = FALSE
+  AND dead_lettered = FALSE
+  AND NOT EXISTS (
+    SELECT 1
+    FROM jobs AS dependency
+    WHERE dependency.id = ANY(jobs.dependencies)
+      AND dependency.finished_at IS NULL
+  )
+ORDER BY priority DESC, queued_at ASC;
@@ -0,0 +1,3 @@
+-- Jobs are listed by their type, for example to find the composes of a tenant.
+
+CREATE INDEX jobs_type_idx ON jobs(type, queued_at);
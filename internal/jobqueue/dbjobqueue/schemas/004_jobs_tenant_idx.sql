@@ -0,0 +1,4 @@
+-- Jobs are listed by the tenant in their arguments, for example to find the
+-- composes of a tenant. Jobs without a tenant are listed under ''.
+
+CREATE INDEX jobs_tenant_idx ON jobs((coalesce(args->>'tenant', '')), queued_at);
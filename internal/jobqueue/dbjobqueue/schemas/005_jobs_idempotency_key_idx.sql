@@ -0,0 +1,4 @@
+-- Composes of the Cloud API are looked up by the idempotency key of the
+-- request which started them.
+
+CREATE INDEX jobs_idempotency_key_idx ON jobs((args->'idempotency'->>'key'));
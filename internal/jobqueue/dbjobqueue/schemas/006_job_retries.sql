@@ -0,0 +1,22 @@
+-- Jobs which failed transiently are put back into the queue, but are not
+-- handed out to workers before `not_before`. `retries` counts how often that
+-- happened.
+
+ALTER TABLE jobs
+	ADD COLUMN retries integer NOT NULL DEFAULT 0,
+	ADD COLUMN not_before timestamptz;
+
+CREATE OR REPLACE VIEW ready_jobs AS
+SELECT *
+FROM jobs
+WHERE started_at IS NULL
+  AND canceled = FALSE
+  AND dead_lettered = FALSE
+  AND (not_before IS NULL OR not_before <= now())
+  AND NOT EXISTS (
+    SELECT 1
+    FROM jobs AS dependency
+    WHERE dependency.id = ANY(jobs.dependencies)
+      AND dependency.finished_at IS NULL
+  )
+ORDER BY priority DESC, queued_at ASC;
@@ -0,0 +1,5 @@
+-- Running jobs record the composer instance which handed them out, so that
+-- an instance only takes over its own jobs after a restart.
+
+ALTER TABLE jobs
+	ADD COLUMN owner varchar;
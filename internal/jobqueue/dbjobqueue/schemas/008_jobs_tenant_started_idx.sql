@@ -0,0 +1,3 @@
+-- Fair dequeueing looks up when a job of each tenant was last started.
+
+CREATE INDEX jobs_tenant_started_idx ON jobs((coalesce(args->>'tenant', '')), started_at);
@@ -12,11 +12,11 @@
 package fsjobqueue
 
 import (
+	"container/list"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -29,17 +29,37 @@ type fsJobQueue struct {
 	// Protects all fields of this struct. In particular, it ensures
 	// transactions on `db` are atomic. All public functions except
 	// JobStatus hold it while they're running. Dequeue() releases it
-	// briefly while waiting on pending channels.
+	// briefly while waiting for new pending jobs.
 	mu sync.Mutex
 
 	db *jsondb.JSONDatabase
 
-	// Maps job types to channels of job ids for that type.
-	pending map[string]chan uuid.UUID
+	// List of pending jobs, ordered by priority (highest first) and by
+	// the time they became pending within the same priority.
+	pending *list.List
+
+	// Set of goroutines waiting for new pending jobs. Each of them is
+	// notified (non-blockingly) when a job is added to `pending`.
+	listeners map[chan struct{}]struct{}
 
 	// Maps job ids to the jobs that depend on it, if any of those
 	// dependants have not yet finished.
 	dependants map[uuid.UUID][]uuid.UUID
+
+	// Set of jobs in the dead-letter queue.
+	deadLetter map[uuid.UUID]struct{}
+
+	// Maps tenants to the ids of their jobs, so that listing the jobs of
+	// a tenant doesn't require reading every job from disk. Jobs without
+	// a tenant are kept under "".
+	tenants map[string][]uuid.UUID
+
+	// Maps idempotency keys to the jobs which have them.
+	idempotencyKeys map[string][]uuid.UUID
+
+	// Maps tenants to the time their last job was dequeued, for fair
+	// dequeueing.
+	served map[string]time.Time
 }
 
 // On-disk job struct. Contains all necessary (but non-redundant) information
@@ -51,17 +71,37 @@ type job struct {
 	Args         json.RawMessage `json:"args,omitempty"`
 	Dependencies []uuid.UUID     `json:"dependencies"`
 	Result       json.RawMessage `json:"result,omitempty"`
+	Priority     int             `json:"priority,omitempty"`
 
 	QueuedAt   time.Time `json:"queued_at,omitempty"`
 	StartedAt  time.Time `json:"started_at,omitempty"`
 	FinishedAt time.Time `json:"finished_at,omitempty"`
 
+	// The instance which dequeued the job while it is running
+	Owner string `json:"owner,omitempty"`
+
 	Canceled bool `json:"canceled,omitempty"`
+
+	// Number of times the job was requeued because it failed to finish,
+	// and whether it was moved to the dead-letter queue because of that.
+	Failures     int  `json:"failures,omitempty"`
+	DeadLettered bool `json:"dead_lettered,omitempty"`
+
+	// Number of times the job was retried, and the time before which it
+	// must not be dequeued again
+	Retries   int       `json:"retries,omitempty"`
+	NotBefore time.Time `json:"not_before,omitempty"`
 }
 
-// The size of channels used in fsJobQueue for queueing jobs.
-// Note that each job type has its own queue.
-const channelSize = 100
+// In-memory representation of a pending job, so that selecting the next
+// job does not require reading every pending job from disk.
+type pendingJob struct {
+	Id        uuid.UUID
+	Type      string
+	Tenant    string
+	Priority  int
+	NotBefore time.Time
+}
 
 // Create a new fsJobQueue object for `dir`. This object must have exclusive
 // access to `dir`. If `dir` contains jobs created from previous runs, they are
@@ -69,8 +109,14 @@ const channelSize = 100
 func New(dir string) (*fsJobQueue, error) {
 	q := &fsJobQueue{
 		db:         jsondb.New(dir, 0600),
-		pending:    make(map[string]chan uuid.UUID),
+		pending:    list.New(),
+		listeners:  make(map[chan struct{}]struct{}),
 		dependants: make(map[uuid.UUID][]uuid.UUID),
+		deadLetter: make(map[uuid.UUID]struct{}),
+		tenants:    make(map[string][]uuid.UUID),
+
+		idempotencyKeys: make(map[string][]uuid.UUID),
+		served:          make(map[string]time.Time),
 	}
 
 	// Look for jobs that are still pending and build the dependant map.
@@ -87,6 +133,13 @@ func New(dir string) (*fsJobQueue, error) {
 		if err != nil {
 			return nil, err
 		}
+		if j.DeadLettered && !j.Canceled {
+			q.deadLetter[j.Id] = struct{}{}
+		}
+		if tenant := j.tenant(); j.StartedAt.After(q.served[tenant]) {
+			q.served[tenant] = j.StartedAt
+		}
+		q.indexJob(j)
 		err = q.maybeEnqueue(j, true)
 		if err != nil {
 			return nil, err
@@ -96,7 +149,7 @@ func New(dir string) (*fsJobQueue, error) {
 	return q, nil
 }
 
-func (q *fsJobQueue) Enqueue(jobType string, args interface{}, dependencies []uuid.UUID) (uuid.UUID, error) {
+func (q *fsJobQueue) Enqueue(jobType string, args interface{}, dependencies []uuid.UUID, priority int) (uuid.UUID, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -104,6 +157,7 @@ func (q *fsJobQueue) Enqueue(jobType string, args interface{}, dependencies []uu
 		Id:           uuid.New(),
 		Type:         jobType,
 		Dependencies: dependencies,
+		Priority:     priority,
 		QueuedAt:     time.Now(),
 	}
 
@@ -132,6 +186,7 @@ func (q *fsJobQueue) Enqueue(jobType string, args interface{}, dependencies []uu
 		return uuid.Nil, fmt.Errorf("cannot write job: %v:", err)
 	}
 
+	q.indexJob(&j)
 	err = q.maybeEnqueue(&j, true)
 	if err != nil {
 		return uuid.Nil, err
@@ -140,60 +195,116 @@ func (q *fsJobQueue) Enqueue(jobType string, args interface{}, dependencies []uu
 	return j.Id, nil
 }
 
-func (q *fsJobQueue) Dequeue(ctx context.Context, jobTypes []string) (uuid.UUID, []uuid.UUID, string, json.RawMessage, error) {
+func (q *fsJobQueue) EnqueueJobs(specs []jobqueue.JobSpec) ([]uuid.UUID, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	// Return early if the context is already canceled.
-	if err := ctx.Err(); err != nil {
-		return uuid.Nil, nil, "", nil, err
-	}
+	now := time.Now()
+	jobs := make([]*job, len(specs))
+	for i, spec := range specs {
+		j := &job{
+			Id:       uuid.New(),
+			Type:     spec.Type,
+			Priority: spec.Priority,
+			QueuedAt: now,
+		}
 
-	// Filter q.pending by the `jobTypes`. Ignore those job types that this
-	// queue doesn't accept.
-	chans := []chan uuid.UUID{}
-	for _, jt := range jobTypes {
-		c, exists := q.pending[jt]
-		if !exists {
-			c = make(chan uuid.UUID, channelSize)
-			q.pending[jt] = c
+		var err error
+		j.Args, err = json.Marshal(spec.Args)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling job arguments: %v", err)
 		}
-		chans = append(chans, c)
-	}
 
-	// Loop until finding a non-canceled job.
-	var j *job
-	for {
-		// Unlock the mutex while polling channels, so that multiple goroutines
-		// can wait at the same time.
-		q.mu.Unlock()
-		id, err := selectUUIDChannel(ctx, chans)
-		q.mu.Lock()
+		for _, d := range spec.Dependencies {
+			if d < 0 || d >= i {
+				return nil, jobqueue.ErrInvalidDependency
+			}
+			j.Dependencies = append(j.Dependencies, jobs[d].Id)
+		}
+
+		jobs[i] = j
+	}
 
-		// Delete empty channels
-		for _, jt := range jobTypes {
-			c, exists := q.pending[jt]
-			if exists && len(c) == 0 {
-				close(c)
-				delete(q.pending, jt)
+	// Write all jobs before updating in-memory state. Remove the ones
+	// that were already written when writing fails, so that no partial
+	// graph is picked up after a restart.
+	for i, j := range jobs {
+		err := q.db.Write(j.Id.String(), j)
+		if err != nil {
+			for _, written := range jobs[:i] {
+				_ = q.db.Delete(written.Id.String())
 			}
+			return nil, fmt.Errorf("cannot write job: %v", err)
 		}
+	}
 
+	ids := make([]uuid.UUID, len(jobs))
+	for i, j := range jobs {
+		q.indexJob(j)
+		err := q.maybeEnqueue(j, true)
 		if err != nil {
-			return uuid.Nil, nil, "", nil, err
+			return nil, err
 		}
+		ids[i] = j.Id
+	}
 
-		j, err = q.readJob(id)
+	return ids, nil
+}
+
+func (q *fsJobQueue) Dequeue(ctx context.Context, jobTypes []string, options jobqueue.DequeueOptions) (uuid.UUID, []uuid.UUID, string, json.RawMessage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Return early if the context is already canceled.
+	if err := ctx.Err(); err != nil {
+		return uuid.Nil, nil, "", nil, err
+	}
+
+	// Loop until finding a suitable job.
+	var j *job
+	for {
+		var err error
+		var notBefore time.Time
+		j, notBefore, err = q.dequeueSuitableJob(jobTypes, options.Fair, time.Now())
 		if err != nil {
 			return uuid.Nil, nil, "", nil, err
 		}
-
-		if !j.Canceled {
+		if j != nil {
 			break
 		}
+
+		// Wake up when the first job which is put off becomes due.
+		var timer *time.Timer
+		var due <-chan time.Time
+		if !notBefore.IsZero() {
+			timer = time.NewTimer(time.Until(notBefore))
+			due = timer.C
+		}
+
+		// Unlock the mutex while waiting for new jobs, so that multiple
+		// goroutines can wait at the same time.
+		c := make(chan struct{}, 1)
+		q.listeners[c] = struct{}{}
+		q.mu.Unlock()
+		select {
+		case <-c:
+		case <-due:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		q.mu.Lock()
+		delete(q.listeners, c)
+
+		if err := ctx.Err(); err != nil {
+			return uuid.Nil, nil, "", nil, err
+		}
 	}
 
 	j.StartedAt = time.Now()
+	j.Owner = options.Owner
+	q.served[j.tenant()] = j.StartedAt
 
 	err := q.db.Write(j.Id.String(), j)
 	if err != nil {
@@ -268,9 +379,256 @@ func (q *fsJobQueue) CancelJob(id uuid.UUID) error {
 		return fmt.Errorf("error writing job %s: %v", id, err)
 	}
 
+	delete(q.deadLetter, id)
+
+	return nil
+}
+
+func (q *fsJobQueue) RequeueJob(id uuid.UUID, maxFailures int) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.readJob(id)
+	if err != nil {
+		return false, err
+	}
+
+	if j.Canceled {
+		return false, jobqueue.ErrCanceled
+	}
+
+	if j.StartedAt.IsZero() || !j.FinishedAt.IsZero() {
+		return false, jobqueue.ErrNotRunning
+	}
+
+	j.StartedAt = time.Time{}
+	j.Owner = ""
+	j.Failures += 1
+	j.DeadLettered = maxFailures > 0 && j.Failures >= maxFailures
+
+	err = q.db.Write(id.String(), j)
+	if err != nil {
+		return false, fmt.Errorf("error writing job %s: %v", id, err)
+	}
+
+	if j.DeadLettered {
+		q.deadLetter[id] = struct{}{}
+	} else {
+		q.pushPending(j)
+	}
+
+	return j.DeadLettered, nil
+}
+
+func (q *fsJobQueue) RetryJob(id uuid.UUID, notBefore time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.readJob(id)
+	if err != nil {
+		return err
+	}
+
+	if j.Canceled {
+		return jobqueue.ErrCanceled
+	}
+
+	if j.StartedAt.IsZero() || !j.FinishedAt.IsZero() {
+		return jobqueue.ErrNotRunning
+	}
+
+	j.StartedAt = time.Time{}
+	j.Owner = ""
+	j.Retries += 1
+	j.NotBefore = notBefore
+
+	err = q.db.Write(id.String(), j)
+	if err != nil {
+		return fmt.Errorf("error writing job %s: %v", id, err)
+	}
+
+	q.pushPending(j)
+	return nil
+}
+
+func (q *fsJobQueue) JobRetries(id uuid.UUID) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.readJob(id)
+	if err != nil {
+		return 0, err
+	}
+	return j.Retries, nil
+}
+
+func (q *fsJobQueue) DeadLetterJobs() ([]uuid.UUID, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var jobs []*job
+	for id := range q.deadLetter {
+		j, err := q.readJob(id)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].QueuedAt.Before(jobs[b].QueuedAt)
+	})
+
+	ids := make([]uuid.UUID, len(jobs))
+	for i, j := range jobs {
+		ids[i] = j.Id
+	}
+	return ids, nil
+}
+
+func (q *fsJobQueue) RequeueDeadLetterJob(id uuid.UUID) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.readJob(id)
+	if err != nil {
+		return err
+	}
+
+	if j.Canceled {
+		return jobqueue.ErrCanceled
+	}
+
+	if !j.DeadLettered {
+		return jobqueue.ErrNotDeadLettered
+	}
+
+	j.Failures = 0
+	j.DeadLettered = false
+
+	err = q.db.Write(id.String(), j)
+	if err != nil {
+		return fmt.Errorf("error writing job %s: %v", id, err)
+	}
+
+	delete(q.deadLetter, id)
+	q.pushPending(j)
+
 	return nil
 }
 
+// UnfinishedJobs reads all jobs, because only the pending ones which are
+// ready to run are kept in memory. It is meant for occasional use by
+// administrators.
+func (q *fsJobQueue) UnfinishedJobs() ([]uuid.UUID, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ids, err := q.db.List()
+	if err != nil {
+		return nil, fmt.Errorf("error listing jobs: %v", err)
+	}
+
+	var jobs []*job
+	for _, id := range ids {
+		jobId, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid job '%s' in db: %v", id, err)
+		}
+		j, err := q.readJob(jobId)
+		if err != nil {
+			return nil, err
+		}
+		if j.FinishedAt.IsZero() && !j.Canceled && !j.DeadLettered {
+			jobs = append(jobs, j)
+		}
+	}
+
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].QueuedAt.Before(jobs[b].QueuedAt)
+	})
+
+	unfinished := make([]uuid.UUID, len(jobs))
+	for i, j := range jobs {
+		unfinished[i] = j.Id
+	}
+	return unfinished, nil
+}
+
+// ListJobs reads all jobs, like UnfinishedJobs, unless the filter selects
+// the jobs of a tenant. Then it only reads the jobs of that tenant.
+func (q *fsJobQueue) ListJobs(filter jobqueue.JobFilter) ([]uuid.UUID, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var ids []uuid.UUID
+	if filter.IdempotencyKey != "" {
+		ids = q.idempotencyKeys[filter.IdempotencyKey]
+	} else if filter.Tenant != nil {
+		ids = q.tenants[*filter.Tenant]
+	} else {
+		names, err := q.db.List()
+		if err != nil {
+			return nil, 0, fmt.Errorf("error listing jobs: %v", err)
+		}
+		for _, name := range names {
+			id, err := uuid.Parse(name)
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid job '%s' in db: %v", name, err)
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	var jobs []*job
+	dependencies := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		j, err := q.readJob(id)
+		if err != nil {
+			return nil, 0, err
+		}
+		// only the jobs of the tenant were read, if there is one and no
+		// idempotency key
+		if filter.SkipDependencies && (len(filter.Types) == 0 || jobTypeMatches(j.Type, filter.Types)) {
+			for _, d := range j.Dependencies {
+				dependencies[d] = true
+			}
+		}
+		if j.matches(&filter) {
+			jobs = append(jobs, j)
+		}
+	}
+	if filter.SkipDependencies {
+		selected := jobs[:0]
+		for _, j := range jobs {
+			if !dependencies[j.Id] {
+				selected = append(selected, j)
+			}
+		}
+		jobs = selected
+	}
+
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].QueuedAt.Before(jobs[b].QueuedAt)
+	})
+
+	total := len(jobs)
+	if filter.Offset < len(jobs) {
+		jobs = jobs[filter.Offset:]
+	} else {
+		jobs = nil
+	}
+	if filter.Limit > 0 && filter.Limit < len(jobs) {
+		jobs = jobs[:filter.Limit]
+	}
+
+	result := make([]uuid.UUID, len(jobs))
+	for i, j := range jobs {
+		result[i] = j.Id
+	}
+	return result, total, nil
+}
+
 func (q *fsJobQueue) JobStatus(id uuid.UUID) (result json.RawMessage, queued, started, finished time.Time, canceled bool, deps []uuid.UUID, err error) {
 	j, err := q.readJob(id)
 	if err != nil {
@@ -321,7 +679,7 @@ func (q *fsJobQueue) readJob(id uuid.UUID) (*job, error) {
 // `q.mu` must be locked when this method is called. The only exception is
 // `New()` because no concurrent calls are possible there.
 func (q *fsJobQueue) maybeEnqueue(j *job, updateDependants bool) error {
-	if !j.StartedAt.IsZero() {
+	if !j.StartedAt.IsZero() || j.DeadLettered {
 		return nil
 	}
 
@@ -338,12 +696,7 @@ func (q *fsJobQueue) maybeEnqueue(j *job, updateDependants bool) error {
 	}
 
 	if depsFinished {
-		c, exists := q.pending[j.Type]
-		if !exists {
-			c = make(chan uuid.UUID, channelSize)
-			q.pending[j.Type] = c
-		}
-		c <- j.Id
+		q.pushPending(j)
 	} else if updateDependants {
 		for _, id := range j.Dependencies {
 			q.dependants[id] = append(q.dependants[id], j.Id)
@@ -353,33 +706,205 @@ func (q *fsJobQueue) maybeEnqueue(j *job, updateDependants bool) error {
 	return nil
 }
 
-// Select on a list of `chan uuid.UUID`s. Returns an error if one of the
-// channels is closed.
-//
-// Uses reflect.Select(), because the `select` statement cannot operate on an
-// unknown amount of channels.
-func selectUUIDChannel(ctx context.Context, chans []chan uuid.UUID) (uuid.UUID, error) {
-	cases := []reflect.SelectCase{
-		{
-			Dir:  reflect.SelectRecv,
-			Chan: reflect.ValueOf(ctx.Done()),
-		},
+// Insert `j` into the list of pending jobs, after all jobs with the same or a
+// higher priority, and wake up all goroutines waiting for new jobs.
+// `q.mu` must be locked when this method is called.
+func (q *fsJobQueue) pushPending(j *job) {
+	p := pendingJob{
+		Id:        j.Id,
+		Type:      j.Type,
+		Tenant:    j.tenant(),
+		Priority:  j.Priority,
+		NotBefore: j.NotBefore,
+	}
+
+	e := q.pending.Front()
+	for e != nil && e.Value.(pendingJob).Priority >= p.Priority {
+		e = e.Next()
 	}
-	for _, c := range chans {
-		cases = append(cases, reflect.SelectCase{
-			Dir:  reflect.SelectRecv,
-			Chan: reflect.ValueOf(c),
-		})
+	if e == nil {
+		q.pending.PushBack(p)
+	} else {
+		q.pending.InsertBefore(p, e)
 	}
 
-	chosen, value, recvOK := reflect.Select(cases)
-	if !recvOK {
-		if chosen == 0 {
-			return uuid.Nil, ctx.Err()
-		} else {
-			return uuid.Nil, errors.New("channel was closed unexpectedly")
+	for c := range q.listeners {
+		select {
+		case c <- struct{}{}:
+		default:
 		}
 	}
+}
 
-	return value.Interface().(uuid.UUID), nil
+// Remove the pending job with a type in `jobTypes`, which may be dequeued at
+// `now` and comes next, from the list of pending jobs and return it. Canceled
+// jobs are dropped from the list. Returns nil if there is no such job, and
+// the earliest time at which a job that is put off until later may be
+// dequeued, if there is one.
+// `q.mu` must be locked when this method is called.
+func (q *fsJobQueue) dequeueSuitableJob(jobTypes []string, fair bool, now time.Time) (*job, time.Time, error) {
+	for {
+		e, notBefore := q.nextPending(jobTypes, fair, now)
+		if e == nil {
+			return nil, notBefore, nil
+		}
+
+		p := q.pending.Remove(e).(pendingJob)
+		j, err := q.readJob(p.Id)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if !j.Canceled {
+			return j, time.Time{}, nil
+		}
+	}
+}
+
+// Returns the element of the pending job with a type in `jobTypes`, which may
+// be dequeued at `now` and comes next: the first one of the highest priority,
+// or, if `fair` is true, the first one of the tenant among those which was
+// served longest ago. Returns nil if there is no such job, and the earliest
+// time at which a job that is put off until later may be dequeued, if there
+// is one.
+// `q.mu` must be locked when this method is called.
+func (q *fsJobQueue) nextPending(jobTypes []string, fair bool, now time.Time) (*list.Element, time.Time) {
+	var next *list.Element
+	var notBefore time.Time
+	for e := q.pending.Front(); e != nil; e = e.Next() {
+		p := e.Value.(pendingJob)
+
+		if !jobTypeMatches(p.Type, jobTypes) {
+			continue
+		}
+
+		if p.NotBefore.After(now) {
+			if notBefore.IsZero() || p.NotBefore.Before(notBefore) {
+				notBefore = p.NotBefore
+			}
+			continue
+		}
+
+		if next == nil {
+			next = e
+			if !fair {
+				break
+			}
+			continue
+		}
+
+		// pending jobs are ordered by priority
+		n := next.Value.(pendingJob)
+		if p.Priority < n.Priority {
+			break
+		}
+		if q.served[p.Tenant].Before(q.served[n.Tenant]) {
+			next = e
+		}
+	}
+
+	if next != nil {
+		return next, time.Time{}
+	}
+	return nil, notBefore
+}
+
+func jobTypeMatches(jobType string, jobTypes []string) bool {
+	for _, t := range jobTypes {
+		if t == jobType {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *job) state() jobqueue.JobState {
+	switch {
+	case j.Canceled:
+		return jobqueue.JobCanceled
+	case !j.FinishedAt.IsZero():
+		return jobqueue.JobFinished
+	case !j.StartedAt.IsZero():
+		return jobqueue.JobRunning
+	default:
+		return jobqueue.JobPending
+	}
+}
+
+// Returns the "tenant" field of the job's arguments, if any.
+func (j *job) tenant() string {
+	var args struct {
+		Tenant string `json:"tenant"`
+	}
+	_ = json.Unmarshal(j.Args, &args)
+	return args.Tenant
+}
+
+// Returns the "key" of the "idempotency" object in the job's arguments, if
+// any.
+func (j *job) idempotencyKey() string {
+	var args struct {
+		Idempotency struct {
+			Key string `json:"key"`
+		} `json:"idempotency"`
+	}
+	_ = json.Unmarshal(j.Args, &args)
+	return args.Idempotency.Key
+}
+
+// Adds `j` to the jobs of its tenant and of its idempotency key.
+func (q *fsJobQueue) indexJob(j *job) {
+	tenant := j.tenant()
+	q.tenants[tenant] = append(q.tenants[tenant], j.Id)
+	if key := j.idempotencyKey(); key != "" {
+		q.idempotencyKeys[key] = append(q.idempotencyKeys[key], j.Id)
+	}
+}
+
+// Returns whether `filter` selects the job, ignoring its offset and limit
+// and whether it is a dependency of another job.
+func (j *job) matches(filter *jobqueue.JobFilter) bool {
+	if filter.IDs != nil {
+		found := false
+		for _, id := range filter.IDs {
+			if id == j.Id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(filter.Types) > 0 && !jobTypeMatches(j.Type, filter.Types) {
+		return false
+	}
+	if filter.Tenant != nil && j.tenant() != *filter.Tenant {
+		return false
+	}
+	if filter.IdempotencyKey != "" && j.idempotencyKey() != filter.IdempotencyKey {
+		return false
+	}
+	if filter.Owner != nil && (j.state() != jobqueue.JobRunning || j.Owner != *filter.Owner) {
+		return false
+	}
+	if len(filter.States) > 0 {
+		state := j.state()
+		found := false
+		for _, s := range filter.States {
+			if s == state {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !filter.Since.IsZero() && j.QueuedAt.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && !j.QueuedAt.Before(filter.Until) {
+		return false
+	}
+	return true
 }
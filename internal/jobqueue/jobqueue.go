@@ -9,7 +9,15 @@
 // that are determined by its type.
 //
 // A job can have dependencies. It is not run until all its dependencies have
-// finished.
+// finished. Whole graphs of jobs which depend on each other can be enqueued
+// atomically with EnqueueJobs().
+//
+// Each job has a priority. Pending jobs with a higher priority are dequeued
+// before those with a lower priority. Jobs of the same priority are dequeued
+// in the order in which they became ready to run.
+//
+// Jobs which repeatedly fail to finish can be moved to a dead-letter queue,
+// where they stay until they are explicitly requeued.
 package jobqueue
 
 import (
@@ -31,17 +39,29 @@ type JobQueue interface {
 	// All dependencies must already exist, but the job isn't run until all of them
 	// have finished.
 	//
+	// Jobs with a higher `priority` are dequeued first. The default
+	// priority is 0.
+	//
 	// Returns the id of the new job, or an error.
-	Enqueue(jobType string, args interface{}, dependencies []uuid.UUID) (uuid.UUID, error)
+	Enqueue(jobType string, args interface{}, dependencies []uuid.UUID, priority int) (uuid.UUID, error)
+
+	// Enqueues a graph of jobs which depend on each other, such as an
+	// initialization job, several jobs depending on it, and a final job
+	// collecting all their results.
+	//
+	// Either all jobs are enqueued or, when an error is returned, none of
+	// them. Returns the ids of the new jobs, in the same order as `jobs`.
+	EnqueueJobs(jobs []JobSpec) ([]uuid.UUID, error)
 
 	// Dequeues a job, blocking until one is available.
 	//
 	// Waits until a job with a type of any of `jobTypes` is available, or `ctx` is
-	// canceled.
+	// canceled. Jobs with a higher priority are dequeued first, see
+	// DequeueOptions for the order of the ones with the same priority.
 	//
 	// Returns the job's id, dependencies, type, and arguments, or an error. Arguments
 	// can be unmarshaled to the type given in Enqueue().
-	Dequeue(ctx context.Context, jobTypes []string) (uuid.UUID, []uuid.UUID, string, json.RawMessage, error)
+	Dequeue(ctx context.Context, jobTypes []string, options DequeueOptions) (uuid.UUID, []uuid.UUID, string, json.RawMessage, error)
 
 	// Mark the job with `id` as finished. `result` must fit the associated
 	// job type and must be serializable to JSON.
@@ -50,6 +70,43 @@ type JobQueue interface {
 	// Cancel a job. Does nothing if the job has already finished.
 	CancelJob(id uuid.UUID) error
 
+	// Put a running job, which failed to finish, back into the queue, so
+	// that it is handed out to a worker again. The job's start time is
+	// reset and its failure count is incremented.
+	//
+	// When the job has failed `maxFailures` times, it is moved to the
+	// dead-letter queue instead. A `maxFailures` of zero means that jobs
+	// are never dead-lettered. Returns whether the job was dead-lettered.
+	RequeueJob(id uuid.UUID, maxFailures int) (bool, error)
+
+	// Put a running job, which failed in a way that might go away when it
+	// is run again, back into the queue. It isn't handed out to a worker
+	// before `notBefore`. The job's start time is reset and its retry
+	// count is incremented. Its failure count stays the same.
+	RetryJob(id uuid.UUID, notBefore time.Time) error
+
+	// Returns how often the job was put back into the queue with
+	// RetryJob().
+	JobRetries(id uuid.UUID) (int, error)
+
+	// Returns the ids of all jobs in the dead-letter queue, in the order
+	// they were queued. Canceled jobs are not included.
+	DeadLetterJobs() ([]uuid.UUID, error)
+
+	// Move a job from the dead-letter queue back into the queue. Its
+	// failure count is reset.
+	RequeueDeadLetterJob(id uuid.UUID) error
+
+	// Returns the ids of all jobs which are pending, including the ones
+	// waiting for their dependencies, or running, in the order they were
+	// queued. Canceled and dead-lettered jobs are not included.
+	UnfinishedJobs() ([]uuid.UUID, error)
+
+	// Returns the ids of the jobs selected by `filter`, in the order they
+	// were queued, and the number of jobs it selects when its offset and
+	// limit are ignored.
+	ListJobs(filter JobFilter) ([]uuid.UUID, int, error)
+
 	// If the job has finished, returns the result as raw JSON.
 	//
 	// Returns the current status of the job, in the form of three times:
@@ -64,8 +121,88 @@ type JobQueue interface {
 	Job(id uuid.UUID) (jobType string, args json.RawMessage, dependencies []uuid.UUID, err error)
 }
 
+// JobSpec describes a job passed to EnqueueJobs(). `Type`, `Args`, and
+// `Priority` have the same meaning as the arguments to Enqueue().
+type JobSpec struct {
+	Type     string
+	Args     interface{}
+	Priority int
+
+	// Indices of the jobs this job depends on. They must refer to jobs
+	// which come before this one in the same call to EnqueueJobs().
+	Dependencies []int
+}
+
+// DequeueOptions are passed to Dequeue().
+type DequeueOptions struct {
+	// Identifies the instance which hands out the job. The job is
+	// recorded as running for it until it is put back into the queue.
+	Owner string
+
+	// If true, jobs of the same priority are dequeued round-robin between
+	// the tenants in their arguments: the next job is the oldest one of
+	// the tenant whose last job was dequeued longest ago. Jobs without a
+	// tenant are treated as the jobs of one tenant. Otherwise, they are
+	// dequeued in the order they were queued.
+	Fair bool
+}
+
+// JobState is the state of a job, as it follows from the times and the
+// cancellation returned by JobStatus().
+type JobState string
+
+const (
+	// Not started, including jobs which wait for their dependencies and
+	// the ones in the dead-letter queue
+	JobPending JobState = "pending"
+
+	// Started, but not finished
+	JobRunning JobState = "running"
+
+	JobFinished JobState = "finished"
+	JobCanceled JobState = "canceled"
+)
+
+// JobFilter selects the jobs which ListJobs() returns. Fields with their zero
+// value select all jobs.
+type JobFilter struct {
+	// If not nil, only jobs with one of these ids
+	IDs []uuid.UUID
+
+	// If not empty, only jobs with one of these types or states
+	Types  []string
+	States []JobState
+
+	// If not nil, only jobs whose arguments have a "tenant" field with
+	// this value. An empty tenant selects the jobs without one.
+	Tenant *string
+
+	// If not empty, only jobs whose arguments have an "idempotency" object
+	// with this "key"
+	IdempotencyKey string
+
+	// If not nil, only running jobs which were dequeued by this owner
+	Owner *string
+
+	// If true, leave out the jobs which another job with one of Types and
+	// of Tenant depends on, such as the images of a compose
+	SkipDependencies bool
+
+	// Jobs which were queued at or after Since and before Until
+	Since time.Time
+	Until time.Time
+
+	// Skip the first Offset of the selected jobs and return at most Limit
+	// of the remaining ones. A Limit of zero returns all of them.
+	Offset int
+	Limit  int
+}
+
 var (
 	ErrNotExist   = errors.New("job does not exist")
 	ErrNotRunning = errors.New("job is not running")
 	ErrCanceled   = errors.New("job ws canceled")
+
+	ErrNotDeadLettered   = errors.New("job is not in the dead-letter queue")
+	ErrInvalidDependency = errors.New("job depends on a job which is not enqueued before it")
 )
@@ -0,0 +1,739 @@
+// Package jobqueuetest provides test functions to verify a JobQueue
+// implementation satisfies the interface in package jobqueue.
+package jobqueuetest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+)
+
+// MakeJobQueue returns a new, empty job queue and a function to clean it up
+// after a test has finished.
+type MakeJobQueue func() (q jobqueue.JobQueue, stop func(), err error)
+
+type testResult struct {
+}
+
+// TestJobQueue runs the test suite against new queues returned by
+// `makeJobQueue`. Each test gets its own queue.
+func TestJobQueue(t *testing.T, makeJobQueue MakeJobQueue) {
+	wrap := func(f func(t *testing.T, q jobqueue.JobQueue)) func(*testing.T) {
+		return func(t *testing.T) {
+			q, stop, err := makeJobQueue()
+			require.NoError(t, err)
+			defer stop()
+			f(t, q)
+		}
+	}
+
+	t.Run("errors", wrap(testErrors))
+	t.Run("args", wrap(testArgs))
+	t.Run("job-types", wrap(testJobTypes))
+	t.Run("dependencies", wrap(testDependencies))
+	t.Run("enqueue-jobs", wrap(testEnqueueJobs))
+	t.Run("multiple-workers", wrap(testMultipleWorkers))
+	t.Run("cancel", wrap(testCancel))
+	t.Run("priorities", wrap(testPriorities))
+	t.Run("fair", wrap(testFair))
+	t.Run("requeue", wrap(testRequeue))
+	t.Run("retry", wrap(testRetry))
+	t.Run("dead-letter", wrap(testDeadLetter))
+	t.Run("unfinished", wrap(testUnfinished))
+	t.Run("list-jobs", wrap(testListJobs))
+	t.Run("list-tenant-jobs", wrap(testListTenantJobs))
+	t.Run("list-idempotent-jobs", wrap(testListIdempotentJobs))
+	t.Run("list-owned-jobs", wrap(testListOwnedJobs))
+}
+
+func pushTestJob(t *testing.T, q jobqueue.JobQueue, jobType string, args interface{}, dependencies []uuid.UUID) uuid.UUID {
+	t.Helper()
+	id, err := q.Enqueue(jobType, args, dependencies, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	return id
+}
+
+func finishNextTestJob(t *testing.T, q jobqueue.JobQueue, jobType string, result interface{}, deps []uuid.UUID) uuid.UUID {
+	id, d, typ, args, err := q.Dequeue(context.Background(), []string{jobType}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	require.ElementsMatch(t, deps, d)
+	require.Equal(t, jobType, typ)
+	require.NotNil(t, args)
+
+	err = q.FinishJob(id, result)
+	require.NoError(t, err)
+
+	return id
+}
+
+func testErrors(t *testing.T, q jobqueue.JobQueue) {
+	// not serializable to JSON
+	id, err := q.Enqueue("test", make(chan string), nil, 0)
+	require.Error(t, err)
+	require.Equal(t, uuid.Nil, id)
+
+	// invalid dependency
+	id, err = q.Enqueue("test", "arg0", []uuid.UUID{uuid.New()}, 0)
+	require.Error(t, err)
+	require.Equal(t, uuid.Nil, id)
+}
+
+func testArgs(t *testing.T, q jobqueue.JobQueue) {
+	type argument struct {
+		I int
+		S string
+	}
+
+	oneargs := argument{7, "🐠"}
+	one := pushTestJob(t, q, "fish", oneargs, nil)
+
+	twoargs := argument{42, "🐙"}
+	two := pushTestJob(t, q, "octopus", twoargs, nil)
+
+	var parsedArgs argument
+
+	id, deps, typ, args, err := q.Dequeue(context.Background(), []string{"octopus"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, two, id)
+	require.Empty(t, deps)
+	require.Equal(t, "octopus", typ)
+	err = json.Unmarshal(args, &parsedArgs)
+	require.NoError(t, err)
+	require.Equal(t, twoargs, parsedArgs)
+
+	// Read job params after Dequeue
+	jtype, jargs, jdeps, err := q.Job(id)
+	require.NoError(t, err)
+	require.Equal(t, args, jargs)
+	require.Equal(t, deps, jdeps)
+	require.Equal(t, typ, jtype)
+
+	id, deps, typ, args, err = q.Dequeue(context.Background(), []string{"fish"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, one, id)
+	require.Empty(t, deps)
+	require.Equal(t, "fish", typ)
+	err = json.Unmarshal(args, &parsedArgs)
+	require.NoError(t, err)
+	require.Equal(t, oneargs, parsedArgs)
+
+	jtype, jargs, jdeps, err = q.Job(id)
+	require.NoError(t, err)
+	require.Equal(t, args, jargs)
+	require.Equal(t, deps, jdeps)
+	require.Equal(t, typ, jtype)
+
+	_, _, _, err = q.Job(uuid.New())
+	require.Error(t, err)
+}
+
+func testJobTypes(t *testing.T, q jobqueue.JobQueue) {
+	one := pushTestJob(t, q, "octopus", nil, nil)
+	two := pushTestJob(t, q, "clownfish", nil, nil)
+
+	require.Equal(t, two, finishNextTestJob(t, q, "clownfish", testResult{}, nil))
+	require.Equal(t, one, finishNextTestJob(t, q, "octopus", testResult{}, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	id, deps, typ, args, err := q.Dequeue(ctx, []string{"zebra"}, jobqueue.DequeueOptions{})
+	require.Equal(t, err, context.Canceled)
+	require.Equal(t, uuid.Nil, id)
+	require.Empty(t, deps)
+	require.Equal(t, "", typ)
+	require.Nil(t, args)
+}
+
+func testDependencies(t *testing.T, q jobqueue.JobQueue) {
+	t.Run("done-before-pushing-dependant", func(t *testing.T) {
+		one := pushTestJob(t, q, "test", nil, nil)
+		two := pushTestJob(t, q, "test", nil, nil)
+
+		r := []uuid.UUID{}
+		r = append(r, finishNextTestJob(t, q, "test", testResult{}, nil))
+		r = append(r, finishNextTestJob(t, q, "test", testResult{}, nil))
+		require.ElementsMatch(t, []uuid.UUID{one, two}, r)
+
+		j := pushTestJob(t, q, "test", nil, []uuid.UUID{one, two})
+		_, queued, started, finished, canceled, deps, err := q.JobStatus(j)
+		require.NoError(t, err)
+		require.True(t, !queued.IsZero())
+		require.True(t, started.IsZero())
+		require.True(t, finished.IsZero())
+		require.False(t, canceled)
+		require.ElementsMatch(t, deps, []uuid.UUID{one, two})
+
+		require.Equal(t, j, finishNextTestJob(t, q, "test", testResult{}, []uuid.UUID{one, two}))
+
+		result, queued, started, finished, canceled, deps, err := q.JobStatus(j)
+		require.NoError(t, err)
+		require.True(t, !queued.IsZero())
+		require.True(t, !started.IsZero())
+		require.True(t, !finished.IsZero())
+		require.False(t, canceled)
+		require.ElementsMatch(t, deps, []uuid.UUID{one, two})
+
+		err = json.Unmarshal(result, &testResult{})
+		require.NoError(t, err)
+	})
+
+	t.Run("done-after-pushing-dependant", func(t *testing.T) {
+		one := pushTestJob(t, q, "test", nil, nil)
+		two := pushTestJob(t, q, "test", nil, nil)
+
+		j := pushTestJob(t, q, "test", nil, []uuid.UUID{one, two})
+		_, queued, started, finished, canceled, deps, err := q.JobStatus(j)
+		require.NoError(t, err)
+		require.True(t, !queued.IsZero())
+		require.True(t, started.IsZero())
+		require.True(t, finished.IsZero())
+		require.False(t, canceled)
+		require.ElementsMatch(t, deps, []uuid.UUID{one, two})
+
+		r := []uuid.UUID{}
+		r = append(r, finishNextTestJob(t, q, "test", testResult{}, nil))
+		r = append(r, finishNextTestJob(t, q, "test", testResult{}, nil))
+		require.ElementsMatch(t, []uuid.UUID{one, two}, r)
+
+		require.Equal(t, j, finishNextTestJob(t, q, "test", testResult{}, []uuid.UUID{one, two}))
+
+		result, queued, started, finished, canceled, deps, err := q.JobStatus(j)
+		require.NoError(t, err)
+		require.True(t, !queued.IsZero())
+		require.True(t, !started.IsZero())
+		require.True(t, !finished.IsZero())
+		require.False(t, canceled)
+		require.ElementsMatch(t, deps, []uuid.UUID{one, two})
+
+		err = json.Unmarshal(result, &testResult{})
+		require.NoError(t, err)
+	})
+}
+
+func testEnqueueJobs(t *testing.T, q jobqueue.JobQueue) {
+	t.Run("fan-in", func(t *testing.T) {
+		ids, err := q.EnqueueJobs([]jobqueue.JobSpec{
+			{Type: "init"},
+			{Type: "build", Args: "one", Dependencies: []int{0}},
+			{Type: "build", Args: "two", Dependencies: []int{0}},
+			{Type: "finalize", Dependencies: []int{0, 1, 2}},
+		})
+		require.NoError(t, err)
+		require.Len(t, ids, 4)
+		initID, buildIDs, finalizeID := ids[0], ids[1:3], ids[3]
+
+		jobType, args, deps, err := q.Job(buildIDs[1])
+		require.NoError(t, err)
+		require.Equal(t, "build", jobType)
+		require.JSONEq(t, `"two"`, string(args))
+		require.Equal(t, []uuid.UUID{initID}, deps)
+
+		// nothing but the init job is ready
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, _, _, _, err = q.Dequeue(ctx, []string{"build", "finalize"}, jobqueue.DequeueOptions{})
+		require.Equal(t, context.DeadlineExceeded, err)
+
+		require.Equal(t, initID, finishNextTestJob(t, q, "init", testResult{}, nil))
+
+		r := []uuid.UUID{}
+		r = append(r, finishNextTestJob(t, q, "build", testResult{}, []uuid.UUID{initID}))
+		r = append(r, finishNextTestJob(t, q, "build", testResult{}, []uuid.UUID{initID}))
+		require.ElementsMatch(t, buildIDs, r)
+
+		finalizeDeps := []uuid.UUID{initID, buildIDs[0], buildIDs[1]}
+		require.Equal(t, finalizeID, finishNextTestJob(t, q, "finalize", testResult{}, finalizeDeps))
+
+		_, _, deps, err = q.Job(finalizeID)
+		require.NoError(t, err)
+		require.Equal(t, finalizeDeps, deps, "dependencies must keep their order")
+	})
+
+	t.Run("invalid-dependency", func(t *testing.T) {
+		for _, deps := range [][]int{{1}, {2}, {-1}} {
+			ids, err := q.EnqueueJobs([]jobqueue.JobSpec{
+				{Type: "invalid"},
+				{Type: "invalid", Dependencies: deps},
+			})
+			require.Equal(t, jobqueue.ErrInvalidDependency, err)
+			require.Nil(t, ids)
+		}
+
+		// none of the jobs were enqueued
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, _, _, _, err := q.Dequeue(ctx, []string{"invalid"}, jobqueue.DequeueOptions{})
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+}
+
+// Test that a job queue allows parallel access to multiple workers, mainly to
+// verify the quirky unlocking in Dequeue().
+
+func testMultipleWorkers(t *testing.T, q jobqueue.JobQueue) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		id, deps, typ, args, err := q.Dequeue(ctx, []string{"octopus"}, jobqueue.DequeueOptions{})
+		require.NoError(t, err)
+		require.NotEmpty(t, id)
+		require.Empty(t, deps)
+		require.Equal(t, "octopus", typ)
+		require.Equal(t, json.RawMessage("null"), args)
+	}()
+
+	// Increase the likelihood that the above goroutine was scheduled and
+	// is waiting in Dequeue().
+	time.Sleep(10 * time.Millisecond)
+
+	// This call to Dequeue() should not block on the one in the goroutine.
+	id := pushTestJob(t, q, "clownfish", nil, nil)
+	r, deps, typ, args, err := q.Dequeue(context.Background(), []string{"clownfish"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, id, r)
+	require.Empty(t, deps)
+	require.Equal(t, "clownfish", typ)
+	require.Equal(t, json.RawMessage("null"), args)
+
+	// Now wake up the Dequeue() in the goroutine and wait for it to finish.
+	_ = pushTestJob(t, q, "octopus", nil, nil)
+	<-done
+}
+
+func testCancel(t *testing.T, q jobqueue.JobQueue) {
+	// Cancel a non-existing job
+	err := q.CancelJob(uuid.New())
+	require.Error(t, err)
+
+	// Cancel a pending job
+	id := pushTestJob(t, q, "clownfish", nil, nil)
+	require.NotEmpty(t, id)
+	err = q.CancelJob(id)
+	require.NoError(t, err)
+	result, _, _, _, canceled, _, err := q.JobStatus(id)
+	require.NoError(t, err)
+	require.True(t, canceled)
+	require.Nil(t, result)
+	err = q.FinishJob(id, &testResult{})
+	require.Error(t, err)
+
+	// Cancel a running job, which should not dequeue the canceled job from above
+	id = pushTestJob(t, q, "clownfish", nil, nil)
+	require.NotEmpty(t, id)
+	r, deps, typ, args, err := q.Dequeue(context.Background(), []string{"clownfish"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, id, r)
+	require.Empty(t, deps)
+	require.Equal(t, "clownfish", typ)
+	require.Equal(t, json.RawMessage("null"), args)
+	err = q.CancelJob(id)
+	require.NoError(t, err)
+	result, _, _, _, canceled, _, err = q.JobStatus(id)
+	require.NoError(t, err)
+	require.True(t, canceled)
+	require.Nil(t, result)
+	err = q.FinishJob(id, &testResult{})
+	require.Error(t, err)
+
+	// Cancel a finished job, which is a no-op
+	id = pushTestJob(t, q, "clownfish", nil, nil)
+	require.NotEmpty(t, id)
+	r, deps, typ, args, err = q.Dequeue(context.Background(), []string{"clownfish"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, id, r)
+	require.Empty(t, deps)
+	require.Equal(t, "clownfish", typ)
+	require.Equal(t, json.RawMessage("null"), args)
+	err = q.FinishJob(id, &testResult{})
+	require.NoError(t, err)
+	err = q.CancelJob(id)
+	require.NoError(t, err)
+	result, _, _, _, canceled, _, err = q.JobStatus(id)
+	require.NoError(t, err)
+	require.False(t, canceled)
+	err = json.Unmarshal(result, &testResult{})
+	require.NoError(t, err)
+}
+
+func testPriorities(t *testing.T, q jobqueue.JobQueue) {
+	low, err := q.Enqueue("octopus", nil, nil, 0)
+	require.NoError(t, err)
+	high, err := q.Enqueue("octopus", nil, nil, 10)
+	require.NoError(t, err)
+	medium, err := q.Enqueue("octopus", nil, nil, 5)
+	require.NoError(t, err)
+	high2, err := q.Enqueue("octopus", nil, nil, 10)
+	require.NoError(t, err)
+
+	// jobs of a different type don't interfere with the order
+	_ = pushTestJob(t, q, "clownfish", nil, nil)
+
+	require.Equal(t, high, finishNextTestJob(t, q, "octopus", testResult{}, nil))
+	require.Equal(t, high2, finishNextTestJob(t, q, "octopus", testResult{}, nil))
+	require.Equal(t, medium, finishNextTestJob(t, q, "octopus", testResult{}, nil))
+	require.Equal(t, low, finishNextTestJob(t, q, "octopus", testResult{}, nil))
+}
+
+func testFair(t *testing.T, q jobqueue.JobQueue) {
+	type tenantArgs struct {
+		Tenant string `json:"tenant,omitempty"`
+	}
+	enqueue := func(tenant string, priority int) uuid.UUID {
+		id, err := q.Enqueue("octopus", tenantArgs{tenant}, nil, priority)
+		require.NoError(t, err)
+		return id
+	}
+
+	a1 := enqueue("a", 0)
+	a2 := enqueue("a", 0)
+	a3 := enqueue("a", 0)
+	b1 := enqueue("b", 0)
+	b2 := enqueue("b", 0)
+	none := enqueue("", 0)
+	a4 := enqueue("a", 1)
+
+	// higher priorities still come first, and jobs of the same tenant
+	// keep their order, but are interleaved with the ones of other tenants
+	for _, expected := range []uuid.UUID{a4, b1, none, a1, b2, a2, a3} {
+		r, _, _, _, err := q.Dequeue(context.Background(), []string{"octopus"}, jobqueue.DequeueOptions{Fair: true})
+		require.NoError(t, err)
+		require.Equal(t, expected, r)
+		// make sure the start times of the jobs differ
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func testRequeue(t *testing.T, q jobqueue.JobQueue) {
+	// Requeue a non-existing job
+	_, err := q.RequeueJob(uuid.New(), 0)
+	require.Equal(t, jobqueue.ErrNotExist, err)
+
+	// Requeue a pending job
+	id := pushTestJob(t, q, "clownfish", nil, nil)
+	_, err = q.RequeueJob(id, 0)
+	require.Equal(t, jobqueue.ErrNotRunning, err)
+
+	r, _, _, _, err := q.Dequeue(context.Background(), []string{"clownfish"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, id, r)
+
+	// Requeue a running job, which resets its start time
+	deadLettered, err := q.RequeueJob(id, 0)
+	require.NoError(t, err)
+	require.False(t, deadLettered)
+	_, _, started, _, _, _, err := q.JobStatus(id)
+	require.NoError(t, err)
+	require.True(t, started.IsZero())
+
+	require.Equal(t, id, finishNextTestJob(t, q, "clownfish", testResult{}, nil))
+
+	// Requeueing a finished job is not possible
+	_, err = q.RequeueJob(id, 0)
+	require.Equal(t, jobqueue.ErrNotRunning, err)
+}
+
+func testRetry(t *testing.T, q jobqueue.JobQueue) {
+	err := q.RetryJob(uuid.New(), time.Now())
+	require.Equal(t, jobqueue.ErrNotExist, err)
+
+	id := pushTestJob(t, q, "clownfish", nil, nil)
+	err = q.RetryJob(id, time.Now())
+	require.Equal(t, jobqueue.ErrNotRunning, err)
+
+	r, _, _, _, err := q.Dequeue(context.Background(), []string{"clownfish"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, id, r)
+
+	// the job isn't handed out again before it is due
+	notBefore := time.Now().Add(500 * time.Millisecond)
+	require.NoError(t, q.RetryJob(id, notBefore))
+	retries, err := q.JobRetries(id)
+	require.NoError(t, err)
+	require.Equal(t, 1, retries)
+	_, _, started, _, _, _, err := q.JobStatus(id)
+	require.NoError(t, err)
+	require.True(t, started.IsZero())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _, _, _, err = q.Dequeue(ctx, []string{"clownfish"}, jobqueue.DequeueOptions{})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	time.Sleep(time.Until(notBefore))
+	require.Equal(t, id, finishNextTestJob(t, q, "clownfish", testResult{}, nil))
+
+	err = q.RetryJob(id, time.Now())
+	require.Equal(t, jobqueue.ErrNotRunning, err)
+	retries, err = q.JobRetries(id)
+	require.NoError(t, err)
+	require.Equal(t, 1, retries)
+}
+
+func testDeadLetter(t *testing.T, q jobqueue.JobQueue) {
+	ids, err := q.DeadLetterJobs()
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	err = q.RequeueDeadLetterJob(uuid.New())
+	require.Equal(t, jobqueue.ErrNotExist, err)
+
+	id := pushTestJob(t, q, "octopus", nil, nil)
+	err = q.RequeueDeadLetterJob(id)
+	require.Equal(t, jobqueue.ErrNotDeadLettered, err)
+
+	// the job is dead-lettered when it fails for the second time
+	for i := 0; i < 2; i++ {
+		r, _, _, _, err := q.Dequeue(context.Background(), []string{"octopus"}, jobqueue.DequeueOptions{})
+		require.NoError(t, err)
+		require.Equal(t, id, r)
+
+		deadLettered, err := q.RequeueJob(id, 2)
+		require.NoError(t, err)
+		require.Equal(t, i == 1, deadLettered)
+	}
+
+	ids, err = q.DeadLetterJobs()
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{id}, ids)
+
+	// dead-lettered jobs are not handed out to workers
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, _, _, err = q.Dequeue(ctx, []string{"octopus"}, jobqueue.DequeueOptions{})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// requeueing resets the failure count
+	require.NoError(t, q.RequeueDeadLetterJob(id))
+	ids, err = q.DeadLetterJobs()
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	r, _, _, _, err := q.Dequeue(context.Background(), []string{"octopus"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, id, r)
+	deadLettered, err := q.RequeueJob(id, 2)
+	require.NoError(t, err)
+	require.False(t, deadLettered)
+
+	// canceled jobs are removed from the dead-letter queue
+	r, _, _, _, err = q.Dequeue(context.Background(), []string{"octopus"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, id, r)
+	deadLettered, err = q.RequeueJob(id, 2)
+	require.NoError(t, err)
+	require.True(t, deadLettered)
+
+	require.NoError(t, q.CancelJob(id))
+	ids, err = q.DeadLetterJobs()
+	require.NoError(t, err)
+	require.Empty(t, ids)
+	err = q.RequeueDeadLetterJob(id)
+	require.Equal(t, jobqueue.ErrCanceled, err)
+}
+
+func testUnfinished(t *testing.T, q jobqueue.JobQueue) {
+	ids, err := q.UnfinishedJobs()
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	one := pushTestJob(t, q, "octopus", nil, nil)
+	two := pushTestJob(t, q, "octopus", nil, []uuid.UUID{one})
+	three := pushTestJob(t, q, "clownfish", nil, nil)
+
+	// pending, waiting, and running jobs are unfinished
+	r, _, _, _, err := q.Dequeue(context.Background(), []string{"clownfish"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, three, r)
+	ids, err = q.UnfinishedJobs()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{one, two, three}, ids)
+
+	// finished, canceled, and dead-lettered jobs are not
+	require.NoError(t, q.FinishJob(three, nil))
+	require.NoError(t, q.CancelJob(two))
+	r, _, _, _, err = q.Dequeue(context.Background(), []string{"octopus"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, one, r)
+	deadLettered, err := q.RequeueJob(one, 1)
+	require.NoError(t, err)
+	require.True(t, deadLettered)
+
+	ids, err = q.UnfinishedJobs()
+	require.NoError(t, err)
+	require.Empty(t, ids)
+}
+
+func testListJobs(t *testing.T, q jobqueue.JobQueue) {
+	list := func(filter jobqueue.JobFilter) ([]uuid.UUID, int) {
+		ids, total, err := q.ListJobs(filter)
+		require.NoError(t, err)
+		return ids, total
+	}
+
+	ids, total := list(jobqueue.JobFilter{})
+	require.Empty(t, ids)
+	require.Equal(t, 0, total)
+
+	one := pushTestJob(t, q, "octopus", nil, nil)
+	two := pushTestJob(t, q, "clownfish", nil, nil)
+	three := pushTestJob(t, q, "octopus", nil, nil)
+	four := pushTestJob(t, q, "octopus", nil, []uuid.UUID{three})
+
+	finishNextTestJob(t, q, "clownfish", testResult{}, nil)
+	r, _, _, _, err := q.Dequeue(context.Background(), []string{"octopus"}, jobqueue.DequeueOptions{})
+	require.NoError(t, err)
+	require.Equal(t, one, r)
+	require.NoError(t, q.CancelJob(three))
+
+	ids, total = list(jobqueue.JobFilter{})
+	require.Equal(t, []uuid.UUID{one, two, three, four}, ids)
+	require.Equal(t, 4, total)
+
+	ids, _ = list(jobqueue.JobFilter{Types: []string{"octopus"}})
+	require.Equal(t, []uuid.UUID{one, three, four}, ids)
+
+	ids, _ = list(jobqueue.JobFilter{IDs: []uuid.UUID{four, two}})
+	require.Equal(t, []uuid.UUID{two, four}, ids)
+
+	ids, _ = list(jobqueue.JobFilter{IDs: []uuid.UUID{}})
+	require.Empty(t, ids)
+
+	for state, expected := range map[jobqueue.JobState][]uuid.UUID{
+		jobqueue.JobPending:  {four},
+		jobqueue.JobRunning:  {one},
+		jobqueue.JobFinished: {two},
+		jobqueue.JobCanceled: {three},
+	} {
+		ids, _ = list(jobqueue.JobFilter{States: []jobqueue.JobState{state}})
+		require.Equalf(t, expected, ids, "state %s", state)
+	}
+
+	_, queued, _, _, _, _, err := q.JobStatus(three)
+	require.NoError(t, err)
+	ids, _ = list(jobqueue.JobFilter{Since: queued})
+	require.Equal(t, []uuid.UUID{three, four}, ids)
+	ids, _ = list(jobqueue.JobFilter{Until: queued})
+	require.Equal(t, []uuid.UUID{one, two}, ids)
+
+	// the total ignores the page
+	ids, total = list(jobqueue.JobFilter{Offset: 1, Limit: 2})
+	require.Equal(t, []uuid.UUID{two, three}, ids)
+	require.Equal(t, 4, total)
+	ids, total = list(jobqueue.JobFilter{Offset: 5})
+	require.Empty(t, ids)
+	require.Equal(t, 4, total)
+}
+
+func testListTenantJobs(t *testing.T, q jobqueue.JobQueue) {
+	type tenantArgs struct {
+		Tenant string `json:"tenant"`
+	}
+	one, two, none := "one", "two", ""
+	list := func(filter jobqueue.JobFilter) ([]uuid.UUID, int) {
+		ids, total, err := q.ListJobs(filter)
+		require.NoError(t, err)
+		return ids, total
+	}
+
+	ids, _ := list(jobqueue.JobFilter{Tenant: &one})
+	require.Empty(t, ids)
+
+	image1 := pushTestJob(t, q, "octopus", tenantArgs{"one"}, nil)
+	image2 := pushTestJob(t, q, "octopus", tenantArgs{"one"}, nil)
+	compose := pushTestJob(t, q, "zebra", tenantArgs{"one"}, []uuid.UUID{image1, image2})
+	other := pushTestJob(t, q, "octopus", tenantArgs{"two"}, nil)
+	single := pushTestJob(t, q, "octopus", tenantArgs{"one"}, nil)
+	untenanted := pushTestJob(t, q, "octopus", nil, nil)
+
+	ids, total := list(jobqueue.JobFilter{Tenant: &one})
+	require.Equal(t, []uuid.UUID{image1, image2, compose, single}, ids)
+	require.Equal(t, 4, total)
+
+	ids, _ = list(jobqueue.JobFilter{Tenant: &two})
+	require.Equal(t, []uuid.UUID{other}, ids)
+
+	ids, _ = list(jobqueue.JobFilter{Tenant: &none})
+	require.Equal(t, []uuid.UUID{untenanted}, ids)
+
+	// images of a compose are left out, but only if the job depending
+	// on them is selected
+	ids, total = list(jobqueue.JobFilter{Tenant: &one, Types: []string{"octopus", "zebra"}, SkipDependencies: true})
+	require.Equal(t, []uuid.UUID{compose, single}, ids)
+	require.Equal(t, 2, total)
+	ids, _ = list(jobqueue.JobFilter{Tenant: &one, Types: []string{"octopus"}, SkipDependencies: true})
+	require.Equal(t, []uuid.UUID{image1, image2, single}, ids)
+
+	ids, total = list(jobqueue.JobFilter{Tenant: &one, SkipDependencies: true, Offset: 1, Limit: 1})
+	require.Equal(t, []uuid.UUID{single}, ids)
+	require.Equal(t, 2, total)
+}
+
+func testListIdempotentJobs(t *testing.T, q jobqueue.JobQueue) {
+	type idempotency struct {
+		Key string `json:"key"`
+	}
+	type keyArgs struct {
+		Tenant      string       `json:"tenant"`
+		Idempotency *idempotency `json:"idempotency,omitempty"`
+	}
+	list := func(filter jobqueue.JobFilter) []uuid.UUID {
+		ids, _, err := q.ListJobs(filter)
+		require.NoError(t, err)
+		return ids
+	}
+
+	require.Empty(t, list(jobqueue.JobFilter{IdempotencyKey: "k1"}))
+
+	one, two := "one", "two"
+	first := pushTestJob(t, q, "octopus", keyArgs{"one", &idempotency{"k1"}}, nil)
+	_ = pushTestJob(t, q, "octopus", keyArgs{"one", nil}, nil)
+	other := pushTestJob(t, q, "octopus", keyArgs{"two", &idempotency{"k1"}}, nil)
+
+	require.Equal(t, []uuid.UUID{first, other}, list(jobqueue.JobFilter{IdempotencyKey: "k1"}))
+	require.Equal(t, []uuid.UUID{first}, list(jobqueue.JobFilter{IdempotencyKey: "k1", Tenant: &one}))
+	require.Equal(t, []uuid.UUID{other}, list(jobqueue.JobFilter{IdempotencyKey: "k1", Tenant: &two}))
+	require.Empty(t, list(jobqueue.JobFilter{IdempotencyKey: "k2"}))
+}
+
+func testListOwnedJobs(t *testing.T, q jobqueue.JobQueue) {
+	list := func(owner string) []uuid.UUID {
+		ids, _, err := q.ListJobs(jobqueue.JobFilter{Owner: &owner})
+		require.NoError(t, err)
+		return ids
+	}
+
+	first := pushTestJob(t, q, "octopus", nil, nil)
+	second := pushTestJob(t, q, "octopus", nil, nil)
+	_ = pushTestJob(t, q, "octopus", nil, nil)
+
+	// pending jobs have no owner
+	require.Empty(t, list(""))
+
+	r, _, _, _, err := q.Dequeue(context.Background(), []string{"octopus"}, jobqueue.DequeueOptions{Owner: "one"})
+	require.NoError(t, err)
+	require.Equal(t, first, r)
+	r, _, _, _, err = q.Dequeue(context.Background(), []string{"octopus"}, jobqueue.DequeueOptions{Owner: "two"})
+	require.NoError(t, err)
+	require.Equal(t, second, r)
+
+	require.Equal(t, []uuid.UUID{first}, list("one"))
+	require.Equal(t, []uuid.UUID{second}, list("two"))
+	require.Empty(t, list(""))
+
+	// finished and requeued jobs aren't owned anymore
+	require.NoError(t, q.FinishJob(first, &testResult{}))
+	require.Empty(t, list("one"))
+	_, err = q.RequeueJob(second, 0)
+	require.NoError(t, err)
+	require.Empty(t, list("two"))
+}
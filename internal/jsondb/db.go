@@ -1,7 +1,7 @@
 // Package jsondb implements a simple database of JSON documents, backed by the
 // file system.
 //
-// It supports two operations: Read() and Write(). Their signatures mirror
+// It supports two main operations: Read() and Write(). Their signatures mirror
 // those of json.Unmarshal() and json.Marshal():
 //
 //     err := db.Write("my-string", "octopus")
@@ -9,6 +9,8 @@
 //     var v string
 //     exists, err := db.Read("my-string", &v)
 //
+// Documents can be removed again with Delete().
+//
 // The JSON documents are stored in a directory, in the form name.json (name as
 // passed to Read() and Write()). Thus, names may only contain characters that
 // may appear in filenames.
@@ -88,6 +90,15 @@ func (db *JSONDatabase) Write(name string, document interface{}) error {
 	})
 }
 
+// Deletes the document at `name`. Does nothing if it does not exist.
+func (db *JSONDatabase) Delete(name string) error {
+	err := os.Remove(path.Join(db.dir, name+".json"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting db file %s: %v", name, err)
+	}
+	return nil
+}
+
 // writeFileAtomically writes data to `filename` in `directory` atomically, by
 // first creating a temporary file in `directory` and only moving it when
 // writing succeeded. `writer` gets passed the open file handle to write to and
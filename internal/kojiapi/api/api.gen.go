@@ -67,7 +67,10 @@ type Repository struct {
 
 // Status defines model for Status.
 type Status struct {
-	Status string `json:"status"`
+
+	// Architectures which registered workers build images for
+	Arches []string `json:"arches"`
+	Status string   `json:"status"`
 }
 
 // PostComposeJSONBody defines parameters for PostCompose.
@@ -15,7 +15,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	"github.com/osbuild/osbuild-composer/internal/audit"
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/common"
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/distroregistry"
 	"github.com/osbuild/osbuild-composer/internal/kojiapi/api"
@@ -29,6 +31,7 @@ type Server struct {
 	workers     *worker.Server
 	rpmMetadata rpmmd.RPMMD
 	distros     *distroregistry.Registry
+	audit       *audit.Log
 }
 
 // NewServer creates a new koji server
@@ -43,6 +46,24 @@ func NewServer(logger *log.Logger, workers *worker.Server, rpmMetadata rpmmd.RPM
 	return s
 }
 
+// SetAuditLog makes the API record all requests which start composes in
+// `auditLog`. Must be called before Handler().
+func (s *Server) SetAuditLog(auditLog *audit.Log) {
+	s.audit = auditLog
+}
+
+func auditEvent(ctx echo.Context) *audit.Event {
+	if ctx.Request().Method != http.MethodPost {
+		return nil
+	}
+	// the handler adds the id of the new compose
+	return &audit.Event{
+		API:    "koji",
+		Source: ctx.Request().RemoteAddr,
+		Action: "compose",
+	}
+}
+
 // Create an http.Handler() for this server, that provides the koji API at the
 // given path.
 func (s *Server) Handler(path string) http.Handler {
@@ -56,7 +77,7 @@ func (s *Server) Handler(path string) http.Handler {
 		e.DefaultHTTPErrorHandler(err, c)
 	}
 
-	api.RegisterHandlers(e.Group(path), &apiHandlers{s})
+	api.RegisterHandlers(e.Group(path, s.audit.Middleware(auditEvent)), &apiHandlers{s})
 
 	return e
 }
@@ -82,10 +103,12 @@ func (h *apiHandlers) PostCompose(ctx echo.Context) error {
 	}
 
 	type imageRequest struct {
-		manifest distro.Manifest
-		arch     string
-		filename string
-		exports  []string
+		manifest  distro.Manifest
+		arch      string
+		imageType string
+		filename  string
+		exports   []string
+		size      uint64
 	}
 
 	imageRequests := make([]imageRequest, len(request.ImageRequests))
@@ -108,6 +131,9 @@ func (h *apiHandlers) PostCompose(ctx echo.Context) error {
 		if err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported image type '%s' for %s/%s", ir.ImageType, ir.Architecture, request.Distribution))
 		}
+		if err := h.server.workers.CheckArch("osbuild-koji", arch.Name()); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("No worker is available to build images for architecture '%s'", arch.Name()))
+		}
 		repositories := make([]rpmmd.RepoConfig, len(ir.Repositories))
 		for j, repo := range ir.Repositories {
 			repositories[j].BaseURL = repo.Baseurl
@@ -121,25 +147,23 @@ func (h *apiHandlers) PostCompose(ctx echo.Context) error {
 			panic("Could not initialize empty blueprint.")
 		}
 
-		packageSets := imageType.PackageSets(*bp)
-		packageSpecSets := make(map[string][]rpmmd.PackageSpec)
-		for name, packages := range packageSets {
-			packageSpecs, _, err := h.server.rpmMetadata.Depsolve(packages, repositories, d.ModulePlatformID(), arch.Name())
-			if err != nil {
-				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Failed to depsolve base base packages for %s/%s/%s: %s", ir.ImageType, ir.Architecture, request.Distribution, err))
-			}
-			packageSpecSets[name] = packageSpecs
+		packageSpecSets, err := rpmmd.DepsolvePackageSets(h.server.rpmMetadata, imageType.PackageSets(*bp), repositories, d.ModulePlatformID(), arch.Name())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Failed to depsolve base base packages for %s/%s/%s: %s", ir.ImageType, ir.Architecture, request.Distribution, err))
 		}
 
-		manifest, err := imageType.Manifest(nil, distro.ImageOptions{Size: imageType.Size(0)}, repositories, packageSpecSets, manifestSeed)
+		size := imageType.Size(0)
+		manifest, err := imageType.Manifest(nil, distro.ImageOptions{Size: size}, repositories, packageSpecSets, manifestSeed)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusBadGateway, fmt.Sprintf("Failed to get manifest for for %s/%s/%s: %s", ir.ImageType, ir.Architecture, request.Distribution, err))
 		}
 
 		imageRequests[i].manifest = manifest
 		imageRequests[i].arch = arch.Name()
+		imageRequests[i].imageType = imageType.Name()
 		imageRequests[i].filename = imageType.Filename()
 		imageRequests[i].exports = imageType.Exports()
+		imageRequests[i].size = size
 
 		kojiFilenames[i] = fmt.Sprintf(
 			"%s-%s-%s.%s%s",
@@ -147,52 +171,65 @@ func (h *apiHandlers) PostCompose(ctx echo.Context) error {
 			request.Version,
 			request.Release,
 			ir.Architecture,
-			splitExtension(imageType.Filename()),
+			common.SplitExtension(imageType.Filename()),
 		)
 	}
 
-	initID, err := h.server.workers.EnqueueKojiInit(&worker.KojiInitJob{
-		Server:  request.Koji.Server,
-		Name:    request.Name,
-		Version: request.Version,
-		Release: request.Release,
-	})
-	if err != nil {
-		// This is a programming error.
-		panic(err)
+	// The init job comes first, followed by one build job for each image,
+	// and the finalize job, which collects the results of all of them.
+	jobs := []worker.DAGJob{
+		{
+			Type: "koji-init",
+			Args: &worker.KojiInitJob{
+				Server:  request.Koji.Server,
+				Name:    request.Name,
+				Version: request.Version,
+				Release: request.Release,
+			},
+		},
 	}
 
-	var buildIDs []uuid.UUID
+	finalizeDeps := []int{0}
 	for i, ir := range imageRequests {
-		id, err := h.server.workers.EnqueueOSBuildKoji(ir.arch, &worker.OSBuildKojiJob{
-			Manifest:      ir.manifest,
-			ImageName:     ir.filename,
-			Exports:       ir.exports,
-			KojiServer:    request.Koji.Server,
+		finalizeDeps = append(finalizeDeps, len(jobs))
+		jobs = append(jobs, worker.DAGJob{
+			Type: "osbuild-koji",
+			Arch: ir.arch,
+			Args: &worker.OSBuildKojiJob{
+				Manifest:      ir.manifest,
+				ImageName:     ir.filename,
+				ImageType:     ir.imageType,
+				Exports:       ir.exports,
+				KojiServer:    request.Koji.Server,
+				KojiDirectory: kojiDirectory,
+				KojiFilename:  kojiFilenames[i],
+				ImageSize:     ir.size,
+			},
+			Dependencies: []int{0},
+		})
+	}
+
+	jobs = append(jobs, worker.DAGJob{
+		Type: "koji-finalize",
+		Args: &worker.KojiFinalizeJob{
+			Server:        request.Koji.Server,
+			Name:          request.Name,
+			Version:       request.Version,
+			Release:       request.Release,
+			KojiFilenames: kojiFilenames,
 			KojiDirectory: kojiDirectory,
-			KojiFilename:  kojiFilenames[i],
-		}, initID)
-		if err != nil {
-			// This is a programming error.
-			panic(err)
-		}
-		buildIDs = append(buildIDs, id)
-	}
-
-	id, err := h.server.workers.EnqueueKojiFinalize(&worker.KojiFinalizeJob{
-		Server:        request.Koji.Server,
-		Name:          request.Name,
-		Version:       request.Version,
-		Release:       request.Release,
-		KojiFilenames: kojiFilenames,
-		KojiDirectory: kojiDirectory,
-		TaskID:        uint64(request.Koji.TaskId),
-		StartTime:     uint64(time.Now().Unix()),
-	}, initID, buildIDs)
+			TaskID:        uint64(request.Koji.TaskId),
+			StartTime:     uint64(time.Now().Unix()),
+		},
+		Dependencies: finalizeDeps,
+	})
+
+	ids, err := h.server.workers.EnqueueDAG(ctx.Request().Context(), jobs)
 	if err != nil {
 		// This is a programming error.
 		panic(err)
 	}
+	initID, id := ids[0], ids[len(ids)-1]
 
 	// TODO: remove
 	// For backwards compatibility we must only return once the
@@ -214,31 +251,13 @@ func (h *apiHandlers) PostCompose(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Could not initialize build with koji: %v", initResult.KojiError))
 	}
 
+	audit.SetObject(ctx.Request().Context(), id.String())
 	return ctx.JSON(http.StatusCreated, &api.ComposeResponse{
 		Id:          id.String(),
 		KojiBuildId: int(initResult.BuildID),
 	})
 }
 
-// splitExtension returns the extension of the given file. If there's
-// a multipart extension (e.g. file.tar.gz), it returns all parts (e.g.
-// .tar.gz). If there's no extension in the input, it returns an empty
-// string. If the filename starts with dot, the part before the second dot
-// is not considered as an extension.
-func splitExtension(filename string) string {
-	filenameParts := strings.Split(filename, ".")
-
-	if len(filenameParts) > 0 && filenameParts[0] == "" {
-		filenameParts = filenameParts[1:]
-	}
-
-	if len(filenameParts) <= 1 {
-		return ""
-	}
-
-	return "." + strings.Join(filenameParts[1:], ".")
-}
-
 func composeStatusFromJobStatus(js *worker.JobStatus, initResult *worker.KojiInitJobResult, buildResults []worker.OSBuildKojiJobResult, result *worker.KojiFinalizeJobResult) string {
 	if js.Canceled {
 		return "failure"
@@ -355,6 +374,7 @@ func (h *apiHandlers) GetComposeId(ctx echo.Context, idstr string) error {
 func (h *apiHandlers) GetStatus(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, &api.Status{
 		Status: "OK",
+		Arches: h.server.workers.Arches(),
 	})
 }
 
@@ -49,7 +49,45 @@ func TestStatus(t *testing.T) {
 
 	kojiServer, _ := newTestKojiServer(t, dir)
 	handler := kojiServer.Handler("/api/composer-koji/v1")
-	test.TestRoute(t, handler, false, "GET", "/api/composer-koji/v1/status", ``, http.StatusOK, `{"status":"OK"}`, "message")
+	test.TestRoute(t, handler, false, "GET", "/api/composer-koji/v1/status", ``, http.StatusOK, `{"status":"OK","arches":[]}`, "message")
+}
+
+func TestComposeUnavailableArch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osbuild-composer-test-kojiapi-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kojiServer, workerServer := newTestKojiServer(t, dir)
+	handler := kojiServer.Handler("/api/composer-koji/v1")
+
+	// only a worker for another architecture is registered
+	workerServer.RegisterWorker(worker.WorkerInfo{Arch: "s390x", JobTypes: []string{"osbuild-koji"}})
+	test.TestRoute(t, handler, false, "GET", "/api/composer-koji/v1/status", ``, http.StatusOK, `{"status":"OK","arches":["s390x"]}`)
+
+	test.TestRoute(t, handler, false, "POST", "/api/composer-koji/v1/compose", fmt.Sprintf(`
+	{
+		"name":"foo",
+		"version":"1",
+		"release":"2",
+		"distribution":"%[1]s",
+		"image_requests": [
+			{
+				"architecture": "%[2]s",
+				"image_type": "%[3]s",
+				"repositories": [
+					{
+						"baseurl": "https://repo.example.com/"
+					}
+				]
+			}
+		],
+		"koji": {
+			"server": "koji.example.com"
+		}
+	}`, test_distro.TestDistroName, test_distro.TestArchName, test_distro.TestImageTypeName),
+		http.StatusBadRequest, fmt.Sprintf(`{"message":"No worker is available to build images for architecture '%s'"}`, test_distro.TestArchName))
 }
 
 type jobResult struct {
@@ -404,7 +442,7 @@ func TestJobTypeValidation(t *testing.T) {
 		Version: "42",
 		Release: "1",
 	}
-	initID, err := workers.EnqueueKojiInit(&initJob)
+	initID, err := workers.EnqueueKojiInit(context.Background(), &initJob)
 	require.NoError(t, err)
 
 	buildJobs := make([]worker.OSBuildKojiJob, nImages)
@@ -418,7 +456,7 @@ func TestJobTypeValidation(t *testing.T) {
 			KojiDirectory: "koji-server-test-dir",
 			KojiFilename:  fname,
 		}
-		buildID, err := workers.EnqueueOSBuildKoji(fmt.Sprintf("fake-arch-%d", idx), &buildJob, initID)
+		buildID, err := workers.EnqueueOSBuildKoji(context.Background(), fmt.Sprintf("fake-arch-%d", idx), &buildJob, initID)
 		require.NoError(t, err)
 
 		buildJobs[idx] = buildJob
@@ -436,7 +474,7 @@ func TestJobTypeValidation(t *testing.T) {
 		TaskID:        0,
 		StartTime:     uint64(time.Now().Unix()),
 	}
-	finalizeID, err := workers.EnqueueKojiFinalize(&finalizeJob, initID, buildJobIDs)
+	finalizeID, err := workers.EnqueueKojiFinalize(context.Background(), &finalizeJob, initID, buildJobIDs)
 	require.NoError(t, err)
 
 	// ----- Jobs queued - Test API endpoints (status, manifests, logs) ----- //
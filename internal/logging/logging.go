@@ -0,0 +1,254 @@
+// Package logging writes log entries as text or JSON, each carrying the
+// fields of what it is about, such as the id and type of a job, the compose it
+// belongs to, and its tenant.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the fields which correlate entries about the same compose or job
+const (
+	ComposeID  = "compose_id"
+	JobID      = "job_id"
+	JobType    = "job_type"
+	Tenant     = "tenant"
+	ScheduleID = "schedule_id"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return strconv.Itoa(int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level named `s`, or LevelInfo if it is empty.
+func ParseLevel(s string) (Level, error) {
+	if s == "" {
+		return LevelInfo, nil
+	}
+	for i, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level '%s'", s)
+}
+
+// The output shared by a logger and the loggers derived from it
+type sink struct {
+	mu    sync.Mutex
+	w     io.Writer
+	json  bool
+	level Level
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+// Logger writes entries of at least its level. Loggers are safe for
+// concurrent use.
+type Logger struct {
+	sink   *sink
+	fields []field
+}
+
+// New returns a logger which writes entries of `level` and above to `w`, in
+// `format`, which is either "text" (the default) or "json".
+func New(w io.Writer, format string, level Level) (*Logger, error) {
+	s := &sink{w: w, level: level}
+	switch format {
+	case "", "text":
+	case "json":
+		s.json = true
+	default:
+		return nil, fmt.Errorf("unknown log format '%s'", format)
+	}
+	return &Logger{sink: s}, nil
+}
+
+// With returns a logger which adds the fields `keyvals`, pairs of names and
+// values, to all entries. Values which are empty strings are skipped.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	fields := append([]field(nil), l.fields...)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if s, ok := keyvals[i+1].(string); ok && s == "" {
+			continue
+		}
+		fields = append(fields, field{key, keyvals[i+1]})
+	}
+	return &Logger{sink: l.sink, fields: fields}
+}
+
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.sink.level
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.log(LevelDebug, format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log(LevelInfo, format, args...)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.log(LevelWarn, format, args...)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log(LevelError, format, args...)
+}
+
+func (l *Logger) log(level Level, format string, args ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	l.write(time.Now(), level, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) write(t time.Time, level Level, msg string) {
+	var buf bytes.Buffer
+	if l.sink.json {
+		entry := map[string]interface{}{}
+		for _, f := range l.fields {
+			entry[f.key] = jsonValue(f.value)
+		}
+		entry["time"] = t.Format(time.RFC3339Nano)
+		entry["level"] = level.String()
+		entry["msg"] = msg
+		// keys of maps are encoded in sorted order
+		if err := json.NewEncoder(&buf).Encode(entry); err != nil {
+			fmt.Fprintf(&buf, "{\"level\":\"error\",\"msg\":%q}\n", "cannot encode log entry: "+err.Error())
+		}
+	} else {
+		fmt.Fprintf(&buf, "time=%s level=%s msg=%s", t.Format(time.RFC3339), level, quote(msg))
+		fields := append([]field(nil), l.fields...)
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+		for _, f := range fields {
+			fmt.Fprintf(&buf, " %s=%s", f.key, quote(fmt.Sprint(f.value)))
+		}
+		buf.WriteByte('\n')
+	}
+
+	l.sink.mu.Lock()
+	defer l.sink.mu.Unlock()
+	_, _ = l.sink.w.Write(buf.Bytes())
+}
+
+// Errors and stringers, such as uuids, are encoded as strings
+func jsonValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case error:
+		return value.Error()
+	case fmt.Stringer:
+		return value.String()
+	}
+	return v
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// Writer returns a writer which logs each line written to it as an entry.
+// Lines starting with "Error" are logged as errors, all others as info. It
+// serves as output of the standard library's logger, for code which doesn't
+// log structured entries.
+func (l *Logger) Writer() io.Writer {
+	return &lineWriter{logger: l}
+}
+
+type lineWriter struct {
+	mu     sync.Mutex
+	logger *Logger
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.buf[:i])
+		w.buf = w.buf[i+1:]
+
+		level := LevelInfo
+		if strings.HasPrefix(line, "Error") {
+			level = LevelError
+		}
+		if w.logger.Enabled(level) {
+			w.logger.write(time.Now(), level, line)
+		}
+	}
+	return len(p), nil
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger = &Logger{sink: &sink{w: os.Stderr, level: LevelInfo}}
+)
+
+// Default returns the logger set with SetDefault(), which writes text
+// entries to stderr unless set otherwise.
+func Default() *Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+func SetDefault(l *Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = l
+}
+
+type contextKey int
+
+const loggerKey contextKey = iota
+
+// NewContext returns a context carrying `l`.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger stored in `ctx`, or the default logger.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey).(*Logger); ok {
+		return l
+	}
+	return Default()
+}
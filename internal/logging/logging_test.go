@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("")
+	require.NoError(t, err)
+	require.Equal(t, LevelInfo, level)
+
+	level, err = ParseLevel("WARN")
+	require.NoError(t, err)
+	require.Equal(t, LevelWarn, level)
+
+	_, err = ParseLevel("verbose")
+	require.Error(t, err)
+}
+
+func TestText(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "text", LevelInfo)
+	require.NoError(t, err)
+
+	id := uuid.MustParse("d6b9d3a0-c1a4-4b8a-8a51-ea7a9e4f0e4b")
+	l.With(JobType, "osbuild:x86_64", JobID, id, Tenant, "").Infof("Job %s", "finished")
+	l.Debugf("not logged")
+
+	entry := buf.String()
+	require.True(t, strings.HasPrefix(entry, "time="))
+	require.True(t, strings.HasSuffix(entry, ` level=info msg="Job finished" job_id=d6b9d3a0-c1a4-4b8a-8a51-ea7a9e4f0e4b job_type=osbuild:x86_64`+"\n"), entry)
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "json", LevelDebug)
+	require.NoError(t, err)
+
+	l = l.With(ComposeID, uuid.Nil)
+	l.Debugf("first")
+	l.With("error", errors.New("failed")).Errorf("second")
+
+	decoder := json.NewDecoder(&buf)
+	var entries []map[string]interface{}
+	for decoder.More() {
+		var entry map[string]interface{}
+		require.NoError(t, decoder.Decode(&entry))
+		require.Contains(t, entry, "time")
+		delete(entry, "time")
+		entries = append(entries, entry)
+	}
+	require.Equal(t, []map[string]interface{}{
+		{"level": "debug", "msg": "first", "compose_id": uuid.Nil.String()},
+		{"level": "error", "msg": "second", "compose_id": uuid.Nil.String(), "error": "failed"},
+	}, entries)
+
+	_, err = New(&buf, "xml", LevelDebug)
+	require.Error(t, err)
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "json", LevelError)
+	require.NoError(t, err)
+
+	stdlog := log.New(l.Writer(), "", 0)
+	stdlog.Printf("Not an error")
+	stdlog.Printf("Error reading something")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "error", entry["level"])
+	require.Equal(t, "Error reading something", entry["msg"])
+}
@@ -20,7 +20,7 @@ type depsolve struct {
 type Fixture struct {
 	fetchPackageList
 	depsolve
-	*store.Store
+	store.Store
 	Workers *worker.Server
 }
 
@@ -0,0 +1,233 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// How long keys are used before they are fetched again
+	keyRefreshInterval = time.Hour
+
+	// Minimum time between fetching keys because a token was signed with
+	// an unknown key
+	keyRetryInterval = time.Minute
+)
+
+// keySet fetches the signing keys of an issuer from its JSON Web Key Set
+// and caches them.
+type keySet struct {
+	issuer  string
+	jwksURL string
+	client  *http.Client
+
+	// Protects the fields below. It is not held while keys are fetched.
+	mu        sync.Mutex
+	keys      map[string]interface{}
+	fetched   time.Time
+	attempted time.Time
+	fetchErr  error
+	fetching  chan struct{}
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func newKeySet(issuer, jwksURL string) *keySet {
+	return &keySet{
+		issuer:  issuer,
+		jwksURL: jwksURL,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// key returns the public key with id `kid`, or the only key if `kid` is
+// empty.
+//
+// Keys are fetched at most once every keyRetryInterval, whether fetching
+// succeeds or not, and by one caller at a time. Callers which already have a
+// key keep using it while keys are fetched, the others wait for the fetch.
+func (s *keySet) key(kid string, now time.Time) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.lookup(kid)
+	if ok && now.Sub(s.fetched) <= keyRefreshInterval {
+		return key, nil
+	}
+
+	if s.fetching == nil && now.Sub(s.attempted) >= keyRetryInterval {
+		s.attempted = now
+		s.fetching = make(chan struct{})
+		jwksURL := s.jwksURL
+
+		s.mu.Unlock()
+		keys, jwksURL, err := s.fetch(jwksURL)
+		s.mu.Lock()
+
+		if err == nil {
+			s.keys = keys
+			s.jwksURL = jwksURL
+			s.fetched = now
+		}
+		s.fetchErr = err
+		close(s.fetching)
+		s.fetching = nil
+
+		key, ok = s.lookup(kid)
+	} else if s.fetching != nil && !ok {
+		fetching := s.fetching
+
+		s.mu.Unlock()
+		<-fetching
+		s.mu.Lock()
+
+		key, ok = s.lookup(kid)
+	}
+
+	if ok {
+		// keep using the keys we have if the issuer is unavailable
+		return key, nil
+	}
+	if s.fetchErr != nil {
+		return nil, fmt.Errorf("cannot fetch signing keys: %v", s.fetchErr)
+	}
+	return nil, fmt.Errorf("unknown signing key '%s'", kid)
+}
+
+func (s *keySet) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// fetch returns the keys of the issuer and the URL of its key set, which is
+// discovered if `jwksURL` is empty. It must be called without holding s.mu.
+func (s *keySet) fetch(jwksURL string) (map[string]interface{}, string, error) {
+	if jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		err := s.get(strings.TrimSuffix(s.issuer, "/")+"/.well-known/openid-configuration", &discovery)
+		if err != nil {
+			return nil, "", err
+		}
+		if discovery.JWKSURI == "" {
+			return nil, "", errors.New("issuer doesn't have a jwks_uri")
+		}
+		jwksURL = discovery.JWKSURI
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.get(jwksURL, &jwks); err != nil {
+		return nil, "", err
+	}
+
+	keys := make(map[string]interface{})
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid key '%s': %v", jwk.Kid, err)
+		}
+		// keys of unsupported types are skipped
+		if key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, jwksURL, nil
+}
+
+func (s *keySet) get(url string, v interface{}) error {
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (jwk *jsonWebKey) publicKey() (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", jwk.Crv)
+		}
+		x, err := decodeInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
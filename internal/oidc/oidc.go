@@ -0,0 +1,194 @@
+// Package oidc validates bearer tokens which an OpenID Connect provider
+// issued to clients of composer's APIs.
+package oidc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// DefaultTenantClaim is the claim which contains the tenant of a client,
+// unless configured otherwise.
+const DefaultTenantClaim = "org_id"
+
+// Config describes which tokens a Validator accepts.
+type Config struct {
+	// Issuer of the tokens, which must match their "iss" claim
+	Issuer string
+
+	// URL of the JSON Web Key Set of the issuer. If empty, it is
+	// discovered from the issuer's OpenID configuration.
+	JWKSURL string
+
+	// If set, the "aud" claim must contain it
+	Audience string
+
+	// Clients whose tokens are accepted, matched against the "azp" or
+	// "client_id" claim
+	AllowedClientIDs []string
+
+	// Claim which contains the tenant of the client, DefaultTenantClaim
+	// if empty
+	TenantClaim string
+
+	// If not empty, only tokens of these tenants are accepted
+	AllowedTenants []string
+
+	// Claim which contains the roles of the client, see rbac.Role. Dots
+	// separate the names of nested claims, as in "realm_access.roles".
+	RolesClaim string
+}
+
+// Claims are the parts of a token composer is interested in.
+type Claims struct {
+	Subject  string
+	ClientID string
+	// Jobs are accounted to the tenant, see worker.TenantQuota
+	Tenant string
+	Roles  []string
+}
+
+// Validator validates bearer tokens.
+type Validator struct {
+	config Config
+	keys   *keySet
+}
+
+var ErrNoToken = errors.New("no bearer token")
+
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+func NewValidator(config Config) *Validator {
+	if config.TenantClaim == "" {
+		config.TenantClaim = DefaultTenantClaim
+	}
+	return &Validator{
+		config: config,
+		keys:   newKeySet(config.Issuer, config.JWKSURL),
+	}
+}
+
+// ValidateRequest validates the bearer token in the Authorization header of
+// `r`. Returns ErrNoToken if there is none.
+func (v *Validator) ValidateRequest(r *http.Request) (*Claims, error) {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+		return nil, ErrNoToken
+	}
+	return v.Validate(strings.TrimSpace(header[7:]))
+}
+
+// Validate verifies the signature and claims of `token` and returns the
+// claims composer uses.
+func (v *Validator) Validate(token string) (*Claims, error) {
+	parser := jwt.Parser{ValidMethods: validMethods}
+	mapClaims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(token, mapClaims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.key(kid, jwt.TimeFunc())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// MapClaims.Valid() accepts tokens without expiration time
+	if !mapClaims.VerifyExpiresAt(jwt.TimeFunc().Unix(), true) {
+		return nil, errors.New("token doesn't expire")
+	}
+	if iss, _ := mapClaims["iss"].(string); iss != v.config.Issuer {
+		return nil, fmt.Errorf("token was issued by '%s'", iss)
+	}
+	if v.config.Audience != "" && !hasAudience(mapClaims["aud"], v.config.Audience) {
+		return nil, errors.New("token is not meant for this audience")
+	}
+
+	claims := &Claims{
+		Subject:  stringClaim(mapClaims["sub"]),
+		ClientID: stringClaim(mapClaims["azp"]),
+		Tenant:   stringClaim(mapClaims[v.config.TenantClaim]),
+	}
+	if claims.ClientID == "" {
+		claims.ClientID = stringClaim(mapClaims["client_id"])
+	}
+	if v.config.RolesClaim != "" {
+		claims.Roles = listClaim(nestedClaim(mapClaims, v.config.RolesClaim))
+	}
+
+	if !contains(v.config.AllowedClientIDs, claims.ClientID) {
+		return nil, fmt.Errorf("client '%s' is not allowed", claims.ClientID)
+	}
+	if len(v.config.AllowedTenants) > 0 && !contains(v.config.AllowedTenants, claims.Tenant) {
+		return nil, fmt.Errorf("tenant '%s' is not allowed", claims.Tenant)
+	}
+
+	return claims, nil
+}
+
+// The "aud" claim is either a string or an array of strings
+func hasAudience(aud interface{}, audience string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == audience
+	case []interface{}:
+		for _, s := range a {
+			if s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Identifiers such as organization ids may be numbers
+func stringClaim(claim interface{}) string {
+	switch c := claim.(type) {
+	case string:
+		return c
+	case float64:
+		return strconv.FormatFloat(c, 'f', -1, 64)
+	}
+	return ""
+}
+
+func nestedClaim(claims map[string]interface{}, path string) interface{} {
+	var claim interface{} = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := claim.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		claim = object[name]
+	}
+	return claim
+}
+
+// Lists may also be strings separated by spaces, like the "scope" claim
+func listClaim(claim interface{}) []string {
+	switch c := claim.(type) {
+	case string:
+		return strings.Fields(c)
+	case []interface{}:
+		var list []string
+		for _, item := range c {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
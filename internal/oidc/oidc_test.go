@@ -0,0 +1,245 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+type testIssuer struct {
+	*httptest.Server
+	rsaKey   *rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	requests int
+
+	// the keys can't be fetched while set
+	unavailable bool
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	issuer := &testIssuer{rsaKey: rsaKey, ecKey: ecKey}
+	issuer.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			response = map[string]string{
+				"issuer":   issuer.URL,
+				"jwks_uri": issuer.URL + "/certs",
+			}
+		case "/certs":
+			issuer.requests++
+			if issuer.unavailable {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			encode := func(i *big.Int) string {
+				return base64.RawURLEncoding.EncodeToString(i.Bytes())
+			}
+			response = map[string]interface{}{
+				"keys": []map[string]string{
+					{"kid": "rsa", "kty": "RSA", "use": "sig", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+					{"kid": "ec", "kty": "EC", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+					{"kid": "enc", "kty": "RSA", "use": "enc", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+				},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	return issuer
+}
+
+// token returns a token signed with the key `kid`, with the claims of a
+// client of tenant 1234, overridden by `claims`.
+func (issuer *testIssuer) token(t *testing.T, kid string, claims jwt.MapClaims) string {
+	all := jwt.MapClaims{
+		"iss":    issuer.URL,
+		"sub":    "f:1234:worker",
+		"aud":    []string{"account", "composer"},
+		"azp":    "image-builder",
+		"org_id": 1234,
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"iat":    time.Now().Unix(),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(all, name)
+		} else {
+			all[name] = value
+		}
+	}
+
+	var token *jwt.Token
+	var key interface{}
+	if kid == "ec" {
+		token = jwt.NewWithClaims(jwt.SigningMethodES256, all)
+		key = issuer.ecKey
+	} else {
+		token = jwt.NewWithClaims(jwt.SigningMethodRS256, all)
+		key = issuer.rsaKey
+	}
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestValidate(t *testing.T) {
+	issuer := newTestIssuer(t)
+	defer issuer.Close()
+
+	v := NewValidator(Config{
+		Issuer:           issuer.URL,
+		Audience:         "composer",
+		AllowedClientIDs: []string{"image-builder"},
+		AllowedTenants:   []string{"1234"},
+	})
+
+	claims, err := v.Validate(issuer.token(t, "rsa", nil))
+	require.NoError(t, err)
+	require.Equal(t, &Claims{Subject: "f:1234:worker", ClientID: "image-builder", Tenant: "1234"}, claims)
+
+	claims, err = v.Validate(issuer.token(t, "ec", jwt.MapClaims{"azp": nil, "client_id": "image-builder"}))
+	require.NoError(t, err)
+	require.Equal(t, "image-builder", claims.ClientID)
+
+	// the keys were fetched once
+	require.Equal(t, 1, issuer.requests)
+
+	tests := []struct {
+		name   string
+		kid    string
+		claims jwt.MapClaims
+	}{
+		{"expired", "rsa", jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}},
+		{"no expiration", "rsa", jwt.MapClaims{"exp": nil}},
+		{"other issuer", "rsa", jwt.MapClaims{"iss": "https://example.com"}},
+		{"other audience", "rsa", jwt.MapClaims{"aud": "account"}},
+		{"other client", "rsa", jwt.MapClaims{"azp": "someone"}},
+		{"other tenant", "rsa", jwt.MapClaims{"org_id": "4321"}},
+		{"encryption key", "enc", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := v.Validate(issuer.token(t, tc.kid, tc.claims))
+			require.Error(t, err)
+		})
+	}
+
+	// symmetric signatures are never accepted
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": issuer.URL})
+	signed, err := hmac.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = v.Validate(signed)
+	require.Error(t, err)
+}
+
+func TestValidateRequest(t *testing.T) {
+	issuer := newTestIssuer(t)
+	defer issuer.Close()
+
+	v := NewValidator(Config{
+		Issuer:           issuer.URL,
+		JWKSURL:          issuer.URL + "/certs",
+		TenantClaim:      "tenant",
+		AllowedClientIDs: []string{"image-builder"},
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	_, err := v.ValidateRequest(r)
+	require.Equal(t, ErrNoToken, err)
+
+	r.Header.Set("Authorization", "Bearer "+issuer.token(t, "rsa", jwt.MapClaims{"tenant": "acme"}))
+	claims, err := v.ValidateRequest(r)
+	require.NoError(t, err)
+	require.Equal(t, "acme", claims.Tenant)
+	require.Nil(t, claims.Roles)
+}
+
+func TestRolesClaim(t *testing.T) {
+	issuer := newTestIssuer(t)
+	defer issuer.Close()
+
+	v := NewValidator(Config{
+		Issuer:           issuer.URL,
+		AllowedClientIDs: []string{"image-builder"},
+		RolesClaim:       "realm_access.roles",
+	})
+
+	claims, err := v.Validate(issuer.token(t, "rsa", jwt.MapClaims{
+		"realm_access": map[string]interface{}{"roles": []string{"submit-compose", "offline_access"}},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"submit-compose", "offline_access"}, claims.Roles)
+
+	claims, err = v.Validate(issuer.token(t, "rsa", jwt.MapClaims{"realm_access": "admin"}))
+	require.NoError(t, err)
+	require.Nil(t, claims.Roles)
+}
+
+func TestKeyRotation(t *testing.T) {
+	issuer := newTestIssuer(t)
+	defer issuer.Close()
+
+	keys := newKeySet(issuer.URL, "")
+	now := time.Now()
+
+	_, err := keys.key("rsa", now)
+	require.NoError(t, err)
+
+	// unknown keys are only fetched again after a while
+	_, err = keys.key("new", now.Add(time.Second))
+	require.EqualError(t, err, "unknown signing key 'new'")
+	require.Equal(t, 1, issuer.requests)
+
+	_, err = keys.key("new", now.Add(2*keyRetryInterval))
+	require.Error(t, err)
+	require.Equal(t, 2, issuer.requests)
+
+	// known keys are used while the issuer is unavailable
+	issuer.Close()
+	_, err = keys.key("rsa", now.Add(2*keyRefreshInterval))
+	require.NoError(t, err)
+}
+
+func TestKeyFetchFailure(t *testing.T) {
+	issuer := newTestIssuer(t)
+	defer issuer.Close()
+
+	keys := newKeySet(issuer.URL, "")
+	now := time.Now()
+
+	issuer.unavailable = true
+	_, err := keys.key("rsa", now)
+	require.Error(t, err)
+	require.Equal(t, 1, issuer.requests)
+
+	// failed fetches are only retried after a while, too
+	_, err = keys.key("rsa", now.Add(time.Second))
+	require.Error(t, err)
+	require.Equal(t, 1, issuer.requests)
+
+	issuer.unavailable = false
+	_, err = keys.key("rsa", now.Add(2*keyRetryInterval))
+	require.NoError(t, err)
+	require.Equal(t, 2, issuer.requests)
+}
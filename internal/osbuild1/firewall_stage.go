@@ -4,6 +4,14 @@ type FirewallStageOptions struct {
 	Ports            []string `json:"ports,omitempty"`
 	EnabledServices  []string `json:"enabled_services,omitempty"`
 	DisabledServices []string `json:"disabled_services,omitempty"`
+	// Sources which are assigned to zones
+	Zones []FirewallZone `json:"zones,omitempty"`
+}
+
+// FirewallZone assigns sources (addresses or networks) to the zone Name.
+type FirewallZone struct {
+	Name    string   `json:"name"`
+	Sources []string `json:"sources,omitempty"`
 }
 
 func (FirewallStageOptions) isStageOptions() {}
@@ -3,6 +3,7 @@ package osbuild1
 type SystemdStageOptions struct {
 	EnabledServices  []string `json:"enabled_services,omitempty"`
 	DisabledServices []string `json:"disabled_services,omitempty"`
+	MaskedServices   []string `json:"masked_services,omitempty"`
 	DefaultTarget    string   `json:"default_target,omitempty"`
 }
 
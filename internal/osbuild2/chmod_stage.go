@@ -0,0 +1,22 @@
+package osbuild2
+
+// The ChmodStageOptions describe the modes to set on paths in the tree.
+type ChmodStageOptions struct {
+	Items map[string]ChmodStagePathOptions `json:"items"`
+}
+
+func (ChmodStageOptions) isStageOptions() {}
+
+type ChmodStagePathOptions struct {
+	// Symbolic or octal mode, as accepted by chmod(1)
+	Mode      string `json:"mode"`
+	Recursive bool   `json:"recursive,omitempty"`
+}
+
+// NewChmodStage creates a new org.osbuild.chmod stage object.
+func NewChmodStage(options *ChmodStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.chmod",
+		Options: options,
+	}
+}
@@ -0,0 +1,24 @@
+package osbuild2
+
+// The ChownStageOptions describe the owners to set on paths in the tree.
+type ChownStageOptions struct {
+	Items map[string]ChownStagePathOptions `json:"items"`
+}
+
+func (ChownStageOptions) isStageOptions() {}
+
+// ChownStagePathOptions set the user and group owning a path. Both are
+// names or numeric ids, and at least one of them must be set.
+type ChownStagePathOptions struct {
+	User      string `json:"user,omitempty"`
+	Group     string `json:"group,omitempty"`
+	Recursive bool   `json:"recursive,omitempty"`
+}
+
+// NewChownStage creates a new org.osbuild.chown stage object.
+func NewChownStage(options *ChownStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.chown",
+		Options: options,
+	}
+}
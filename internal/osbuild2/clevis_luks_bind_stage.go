@@ -0,0 +1,24 @@
+package osbuild2
+
+// ClevisLuksBindStageOptions bind the LUKS2 volume on the "device" of the
+// stage to a Clevis pin, so that it is unlocked automatically at boot.
+// Passphrase must unlock an existing key slot of the volume.
+type ClevisLuksBindStageOptions struct {
+	Passphrase string `json:"passphrase"`
+	// The Clevis pin, e.g. "tang" or "tpm2"
+	Pin string `json:"pin"`
+	// The JSON configuration of the pin, e.g. {"url": "http://tang.example.com"}
+	Policy string `json:"policy"`
+}
+
+func (ClevisLuksBindStageOptions) isStageOptions() {}
+
+// NewClevisLuksBindStage creates a new org.osbuild.clevis.luks-bind stage
+// object.
+func NewClevisLuksBindStage(options *ClevisLuksBindStageOptions, devices Devices) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.clevis.luks-bind",
+		Options: options,
+		Devices: devices,
+	}
+}
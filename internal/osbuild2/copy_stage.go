@@ -0,0 +1,110 @@
+package osbuild2
+
+import "encoding/json"
+
+// The CopyStageOptions describe which files to copy into the tree. From is
+// a URL pointing into one of the inputs of the stage, e.g.
+// input://inlinefile/sha256:..., and To one pointing into the tree, e.g.
+// tree:///etc/motd.
+type CopyStageOptions struct {
+	Paths []CopyStagePath `json:"paths"`
+}
+
+func (CopyStageOptions) isStageOptions() {}
+
+type CopyStagePath struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// CopyStageFilesInputs are the files inputs of a copy stage, by name
+type CopyStageFilesInputs map[string]*FilesInputRef
+
+func (CopyStageFilesInputs) isStageInputs() {}
+
+// FilesInputRef is a files input which references files of a source by
+// their checksums.
+type FilesInputRef struct {
+	inputCommon
+	References FilesInputReferences `json:"references"`
+}
+
+func (FilesInputRef) isStageInput() {}
+
+type FilesInputReferences []string
+
+func (FilesInputReferences) isReferences() {}
+
+// NewFilesInputRef creates a new files input referencing the files with
+// the given checksums of a source.
+func NewFilesInputRef(checksums []string) *FilesInputRef {
+	input := new(FilesInputRef)
+	input.Type = "org.osbuild.files"
+	input.Origin = "org.osbuild.source"
+	input.References = checksums
+	return input
+}
+
+// NewCopyStage creates a new org.osbuild.copy stage object.
+func NewCopyStage(options *CopyStageOptions, inputs *CopyStageFilesInputs) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.copy",
+		Options: options,
+		Inputs:  inputs,
+	}
+}
+
+// CopyStageTreeInputs are the tree inputs of a copy stage, by name
+type CopyStageTreeInputs map[string]*TreeInputRef
+
+func (CopyStageTreeInputs) isStageInputs() {}
+
+// TreeInputRef is a tree input which references the tree of a pipeline by
+// its name, i.e. name:<pipeline>.
+type TreeInputRef struct {
+	inputCommon
+	References TreeInputReferences `json:"references"`
+}
+
+func (TreeInputRef) isStageInput() {}
+
+type TreeInputReferences []string
+
+func (TreeInputReferences) isReferences() {}
+
+// NewTreeInputRef creates a new tree input referencing the tree of
+// `pipeline`.
+func NewTreeInputRef(pipeline string) *TreeInputRef {
+	input := new(TreeInputRef)
+	input.Type = "org.osbuild.tree"
+	input.Origin = "org.osbuild.pipeline"
+	input.References = TreeInputReferences{"name:" + pipeline}
+	return input
+}
+
+// NewCopyStageWithMounts creates a new org.osbuild.copy stage object, which
+// copies into the filesystems `mounts` on `devices`. Its paths point into
+// the mounts with mount://<name>/ URLs.
+func NewCopyStageWithMounts(options *CopyStageOptions, inputs Inputs, devices Devices, mounts []Mount) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.copy",
+		Options: options,
+		Inputs:  inputs,
+		Devices: devices,
+		Mounts:  mounts,
+	}
+}
+
+// Returns the type of the inputs of a copy stage, which are either all files
+// or all tree inputs.
+func newCopyStageInputs(data json.RawMessage) Inputs {
+	var inputs map[string]inputCommon
+	if err := json.Unmarshal(data, &inputs); err == nil {
+		for _, input := range inputs {
+			if input.Type == "org.osbuild.tree" {
+				return new(CopyStageTreeInputs)
+			}
+		}
+	}
+	return new(CopyStageFilesInputs)
+}
@@ -0,0 +1,26 @@
+package osbuild2
+
+// The CrypttabStageOptions describe the content of the /etc/crypttab file,
+// which lists the encrypted volumes to unlock at boot.
+type CrypttabStageOptions struct {
+	Volumes []CrypttabEntry `json:"volumes"`
+}
+
+func (CrypttabStageOptions) isStageOptions() {}
+
+// A CrypttabEntry represents one line in /etc/crypttab. The encrypted device
+// is identified by its UUID.
+type CrypttabEntry struct {
+	Volume  string `json:"volume"`
+	UUID    string `json:"uuid"`
+	Keyfile string `json:"keyfile,omitempty"`
+	Options string `json:"options,omitempty"`
+}
+
+// NewCrypttabStage creates a new org.osbuild.crypttab stage object.
+func NewCrypttabStage(options *CrypttabStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.crypttab",
+		Options: options,
+	}
+}
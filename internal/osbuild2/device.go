@@ -0,0 +1,128 @@
+package osbuild2
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Devices which a stage needs, by name. Devices can be stacked, e.g. a LUKS2
+// device on a loopback device, by setting the name of the underlying device
+// as Parent.
+type Devices map[string]Device
+
+// Device is a block device set up by osbuild for a stage, like a partition of
+// an image file or the decrypted view of a LUKS2 volume.
+type Device struct {
+	// Well-known name in reverse domain-name notation, uniquely identifying
+	// the device type.
+	Type string `json:"type"`
+	// Name of the device which this device is created on
+	Parent  string        `json:"parent,omitempty"`
+	Options DeviceOptions `json:"options,omitempty"`
+}
+
+// DeviceOptions specify the operations of a given device-type.
+type DeviceOptions interface {
+	isDeviceOptions()
+}
+
+type rawDevice struct {
+	Type    string          `json:"type"`
+	Parent  string          `json:"parent"`
+	Options json.RawMessage `json:"options"`
+}
+
+// UnmarshalJSON unmarshals JSON into a Device object. The options are
+// unmarshalled according to the device type.
+func (device *Device) UnmarshalJSON(data []byte) error {
+	var rawDevice rawDevice
+	if err := json.Unmarshal(data, &rawDevice); err != nil {
+		return err
+	}
+	var options DeviceOptions
+	switch rawDevice.Type {
+	case "org.osbuild.loopback":
+		options = new(LoopbackDeviceOptions)
+	case "org.osbuild.luks2":
+		options = new(LUKS2DeviceOptions)
+	case "org.osbuild.lvm2.lv":
+		options = new(LVM2LVDeviceOptions)
+	default:
+		return fmt.Errorf("unexpected device type: %s", rawDevice.Type)
+	}
+	if rawDevice.Options != nil {
+		if err := json.Unmarshal(rawDevice.Options, options); err != nil {
+			return err
+		}
+	}
+
+	device.Type = rawDevice.Type
+	device.Parent = rawDevice.Parent
+	device.Options = options
+
+	return nil
+}
+
+// LoopbackDeviceOptions expose a range of an image file as a block device.
+// Start and Size are counted in sectors; a Size of 0 means up to the end of
+// the file.
+type LoopbackDeviceOptions struct {
+	Filename string `json:"filename"`
+	Start    uint64 `json:"start,omitempty"`
+	Size     uint64 `json:"size,omitempty"`
+}
+
+func (LoopbackDeviceOptions) isDeviceOptions() {}
+
+// NewLoopbackDevice creates a new loopback Device object.
+func NewLoopbackDevice(options *LoopbackDeviceOptions) Device {
+	return Device{
+		Type:    "org.osbuild.loopback",
+		Options: options,
+	}
+}
+
+// LUKS2DeviceOptions open the LUKS2 volume on the parent device.
+type LUKS2DeviceOptions struct {
+	Passphrase string `json:"passphrase"`
+}
+
+func (LUKS2DeviceOptions) isDeviceOptions() {}
+
+// NewLUKS2Device creates a new Device object for the LUKS2 volume on the
+// device named `parent`.
+func NewLUKS2Device(parent string, options *LUKS2DeviceOptions) Device {
+	return Device{
+		Type:    "org.osbuild.luks2",
+		Parent:  parent,
+		Options: options,
+	}
+}
+
+// LVM2LVDeviceOptions activate a logical volume of the volume group on the
+// parent device.
+type LVM2LVDeviceOptions struct {
+	Volume string `json:"volume"`
+}
+
+func (LVM2LVDeviceOptions) isDeviceOptions() {}
+
+// NewLVM2LVDevice creates a new Device object for the logical volume
+// `volume` of the volume group on the device named `parent`.
+func NewLVM2LVDevice(parent string, options *LVM2LVDeviceOptions) Device {
+	return Device{
+		Type:    "org.osbuild.lvm2.lv",
+		Parent:  parent,
+		Options: options,
+	}
+}
+
+// Mount is a filesystem on one of the devices of a stage, which osbuild mounts
+// at Target (relative to the stage's mount root) while the stage runs.
+type Mount struct {
+	Name string `json:"name"`
+	// Well-known name of the filesystem type, e.g. org.osbuild.xfs
+	Type   string `json:"type"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
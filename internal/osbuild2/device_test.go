@@ -0,0 +1,53 @@
+package osbuild2
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageDevices_UnmarshalJSON(t *testing.T) {
+	stages := []*Stage{
+		NewLUKS2CreateStage(
+			&LUKS2CreateStageOptions{
+				Passphrase: "secret",
+				UUID:       "fb180daf-48a7-4ee0-b10d-394651850fd4",
+				PBKDF:      &LUKS2PBKDF{Method: "argon2i", Iterations: 4, Memory: 32, Parallelism: 1},
+			},
+			Devices{"device": NewLoopbackDevice(&LoopbackDeviceOptions{Filename: "disk.img", Start: 2048, Size: 204800})},
+		),
+		NewLVM2CreateStage(
+			&LVM2CreateStageOptions{
+				Volumes: []LogicalVolume{{Name: "rootlv", Size: "2147483648"}, {Name: "homelv", Extents: "100%FREE"}},
+			},
+			Devices{
+				"luks":   NewLoopbackDevice(&LoopbackDeviceOptions{Filename: "disk.img", Start: 2048}),
+				"device": NewLUKS2Device("luks", &LUKS2DeviceOptions{Passphrase: "secret"}),
+			},
+		),
+		{
+			Type:    "org.osbuild.fstab",
+			Options: &FSTabStageOptions{},
+			Devices: Devices{
+				"disk": NewLoopbackDevice(&LoopbackDeviceOptions{Filename: "disk.img"}),
+				"root": NewLVM2LVDevice("disk", &LVM2LVDeviceOptions{Volume: "rootlv"}),
+			},
+			Mounts: []Mount{{Name: "root", Type: "org.osbuild.xfs", Source: "root", Target: "/"}},
+		},
+	}
+
+	for _, stage := range stages {
+		data, err := json.Marshal(stage)
+		require.NoError(t, err)
+
+		var got Stage
+		require.NoError(t, json.Unmarshal(data, &got))
+		assert.Equal(t, stage, &got)
+	}
+
+	var device Device
+	err := json.Unmarshal([]byte(`{"type":"org.osbuild.nbd","options":{}}`), &device)
+	assert.Error(t, err)
+}
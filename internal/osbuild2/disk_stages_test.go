@@ -0,0 +1,75 @@
+package osbuild2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDiskStages(t *testing.T) {
+	devices := Devices{"device": NewLoopbackDevice(&LoopbackDeviceOptions{Filename: "disk.img"})}
+
+	assert.Equal(t, &Stage{
+		Type:    "org.osbuild.luks2.remove-key",
+		Options: &LUKS2RemoveKeyStageOptions{Passphrase: "secret"},
+		Devices: devices,
+	}, NewLUKS2RemoveKeyStage(&LUKS2RemoveKeyStageOptions{Passphrase: "secret"}, devices))
+
+	bind := &ClevisLuksBindStageOptions{Passphrase: "secret", Pin: "tang", Policy: `{"url":"http://tang.example.com"}`}
+	assert.Equal(t, &Stage{
+		Type:    "org.osbuild.clevis.luks-bind",
+		Options: bind,
+		Devices: devices,
+	}, NewClevisLuksBindStage(bind, devices))
+
+	assert.Equal(t, &Stage{
+		Type:    "org.osbuild.lvm2.metadata",
+		Options: &LVM2MetadataStageOptions{VGName: "rootvg"},
+		Devices: devices,
+	}, NewLVM2MetadataStage(&LVM2MetadataStageOptions{VGName: "rootvg"}, devices))
+
+	crypttab := &CrypttabStageOptions{Volumes: []CrypttabEntry{{Volume: "luks-0", UUID: "fb180daf-48a7-4ee0-b10d-394651850fd4", Options: "luks"}}}
+	assert.Equal(t, &Stage{
+		Type:    "org.osbuild.crypttab",
+		Options: crypttab,
+	}, NewCrypttabStage(crypttab))
+}
+
+func TestNewImageStages(t *testing.T) {
+	devices := Devices{"device": NewLoopbackDevice(&LoopbackDeviceOptions{Filename: "disk.img"})}
+
+	assert.Equal(t, &Stage{
+		Type:    "org.osbuild.truncate",
+		Options: &TruncateStageOptions{Filename: "disk.img", Size: "10737418240"},
+	}, NewTruncateStage(&TruncateStageOptions{Filename: "disk.img", Size: "10737418240"}))
+
+	sfdisk := &SfdiskStageOptions{Label: "gpt", UUID: "D209C89E-EA5E-4FBD-B161-B461CCE297E0", Partitions: []SfdiskPartition{{Start: 2048, Size: 2048, Bootable: true}}}
+	assert.Equal(t, &Stage{
+		Type:    "org.osbuild.sfdisk",
+		Options: sfdisk,
+		Devices: devices,
+	}, NewSfdiskStage(sfdisk, devices))
+
+	assert.Equal(t, &Stage{
+		Type:    "org.osbuild.mkfs.xfs",
+		Options: &MkfsXfsStageOptions{UUID: "6e4ff95f-f662-45ee-a82a-bdf44a2d0b75", Label: "root"},
+		Devices: devices,
+	}, NewMkfsXfsStage(&MkfsXfsStageOptions{UUID: "6e4ff95f-f662-45ee-a82a-bdf44a2d0b75", Label: "root"}, devices))
+
+	assert.Equal(t, &Stage{
+		Type:    "org.osbuild.mkfs.fat",
+		Options: &MkfsFATStageOptions{VolID: "7B7795E7"},
+		Devices: devices,
+	}, NewMkfsFATStage(&MkfsFATStageOptions{VolID: "7B7795E7"}, devices))
+
+	mounts := []Mount{{Name: "root", Type: "org.osbuild.xfs", Source: "device", Target: "/"}}
+	copyOptions := &CopyStageOptions{Paths: []CopyStagePath{{From: "input://tree/", To: "mount://root/"}}}
+	inputs := &CopyStageTreeInputs{"tree": NewTreeInputRef("os")}
+	assert.Equal(t, &Stage{
+		Type:    "org.osbuild.copy",
+		Options: copyOptions,
+		Inputs:  inputs,
+		Devices: devices,
+		Mounts:  mounts,
+	}, NewCopyStageWithMounts(copyOptions, inputs, devices, mounts))
+}
@@ -16,3 +16,32 @@ func NewFilesInput() *FilesInput {
 	input.Origin = "org.osbuild.source"
 	return input
 }
+
+// PipelineFilesInput is a files input which takes a file from the tree of
+// a pipeline, e.g. the image file which a later stage converts.
+type PipelineFilesInput struct {
+	inputCommon
+	References PipelineFilesReferences `json:"references"`
+}
+
+func (PipelineFilesInput) isStageInput() {}
+
+// PipelineFilesReferences map the name of a pipeline (name:<pipeline>) to
+// the file in its tree.
+type PipelineFilesReferences map[string]PipelineFile
+
+func (PipelineFilesReferences) isReferences() {}
+
+type PipelineFile struct {
+	File string `json:"file"`
+}
+
+// NewPipelineFilesInput creates a new files input referencing `filename` in
+// the tree of `pipeline`.
+func NewPipelineFilesInput(pipeline, filename string) *PipelineFilesInput {
+	input := new(PipelineFilesInput)
+	input.Type = "org.osbuild.files"
+	input.Origin = "org.osbuild.pipeline"
+	input.References = PipelineFilesReferences{"name:" + pipeline: {File: filename}}
+	return input
+}
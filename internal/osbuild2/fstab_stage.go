@@ -7,6 +7,13 @@ package osbuild2
 // are set to their defaults (if possible).
 type FSTabStageOptions struct {
 	FileSystems []*FSTabEntry `json:"filesystems"`
+	// The fstab of an ostree deployment is written into the deployment
+	// instead of the tree
+	OSTree *FSTabOSTreeOptions `json:"ostree,omitempty"`
+}
+
+type FSTabOSTreeOptions struct {
+	Deployment OSTreeDeployment `json:"deployment"`
 }
 
 func (FSTabStageOptions) isStageOptions() {}
@@ -0,0 +1,41 @@
+package osbuild2
+
+// Grub2InstStageOptions describe how to install the legacy BIOS boot loader
+// into the image file Filename. The core image is written to Location (in
+// sectors), which is the BIOS boot partition on gpt partition tables, and
+// loads the remaining modules from Prefix.
+type Grub2InstStageOptions struct {
+	Filename string `json:"filename"`
+	Platform string `json:"platform"`
+	Location uint64 `json:"location"`
+
+	Core   CoreMkImage     `json:"core"`
+	Prefix PrefixPartition `json:"prefix"`
+}
+
+func (Grub2InstStageOptions) isStageOptions() {}
+
+// CoreMkImage configures the core image, which needs the modules to read the
+// partition table and the filesystem holding the prefix.
+type CoreMkImage struct {
+	Type       string `json:"type"`
+	PartLabel  string `json:"partlabel"`
+	Filesystem string `json:"filesystem"`
+}
+
+// PrefixPartition is the directory Path on the partition with the (0-based)
+// index Number, which holds the grub modules and configuration.
+type PrefixPartition struct {
+	Type      string `json:"type"`
+	PartLabel string `json:"partlabel"`
+	Number    uint   `json:"number"`
+	Path      string `json:"path"`
+}
+
+// NewGrub2InstStage creates a new org.osbuild.grub2.inst stage object.
+func NewGrub2InstStage(options *Grub2InstStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.grub2.inst",
+		Options: options,
+	}
+}
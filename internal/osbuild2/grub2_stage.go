@@ -17,10 +17,19 @@ type GRUB2StageOptions struct {
 	Legacy             string     `json:"legacy,omitempty"`
 	UEFI               *GRUB2UEFI `json:"uefi,omitempty"`
 	SavedEntry         string     `json:"saved_entry,omitempty"`
+	// Enable Ignition on the first boot, if /boot/ignition.firstboot exists
+	Ignition bool `json:"ignition,omitempty"`
+	// Count the boot attempts of greenboot, which rolls back failed updates
+	Greenboot bool `json:"greenboot,omitempty"`
+	// Write /etc/default/grub, which isn't used by ostree deployments
+	WriteDefaults *bool `json:"write_defaults,omitempty"`
 }
 
 type GRUB2UEFI struct {
 	Vendor string `json:"vendor"`
+	// Copy the EFI binaries of the tree into the ESP, for trees which don't
+	// have them in /boot/efi, e.g. ostree deployments
+	Install bool `json:"install,omitempty"`
 }
 
 func (GRUB2StageOptions) isStageOptions() {}
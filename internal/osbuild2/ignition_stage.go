@@ -0,0 +1,21 @@
+package osbuild2
+
+// IgnitionStageOptions configure the first boot of a system provisioned with
+// Ignition.
+type IgnitionStageOptions struct {
+	// Kernel command line arguments which configure the network of the
+	// first boot, e.g. to fetch the Ignition config
+	Network []string `json:"network,omitempty"`
+}
+
+func (IgnitionStageOptions) isStageOptions() {}
+
+// NewIgnitionStage creates a new org.osbuild.ignition stage, which creates
+// /boot/ignition.firstboot, so that the boot loader enables Ignition on the
+// first boot.
+func NewIgnitionStage(options *IgnitionStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.ignition",
+		Options: options,
+	}
+}
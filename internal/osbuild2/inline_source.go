@@ -0,0 +1,14 @@
+package osbuild2
+
+// The files to embed in the manifest, indexed by their checksum
+type InlineSource struct {
+	Items map[string]InlineSourceItem `json:"items"`
+}
+
+func (InlineSource) isSource() {}
+
+type InlineSourceItem struct {
+	// Encoding of Data, only "base64" is supported
+	Encoding string `json:"encoding"`
+	Data     string `json:"data"`
+}
@@ -7,6 +7,10 @@ type KickstartStageOptions struct {
 	OSTree *OSTreeOptions `json:"ostree,omitempty"`
 
 	LiveIMG *LiveIMG `json:"liveimg,omitempty"`
+
+	// Users and groups which the installer creates
+	Users  map[string]UsersStageOptionsUser   `json:"users,omitempty"`
+	Groups map[string]GroupsStageOptionsGroup `json:"groups,omitempty"`
 }
 
 type LiveIMG struct {
@@ -0,0 +1,34 @@
+package osbuild2
+
+// LUKS2CreateStageOptions describe how to format the "device" of the stage as
+// a LUKS2 volume.
+type LUKS2CreateStageOptions struct {
+	Passphrase string `json:"passphrase"`
+	UUID       string `json:"uuid"`
+	Label      string `json:"label,omitempty"`
+	Subsystem  string `json:"subsystem,omitempty"`
+	Cipher     string `json:"cipher,omitempty"`
+
+	// The key derivation function used for the passphrase
+	PBKDF *LUKS2PBKDF `json:"pbkdf,omitempty"`
+}
+
+func (LUKS2CreateStageOptions) isStageOptions() {}
+
+// LUKS2PBKDF configures the key derivation function of a LUKS2 volume.
+// Memory is in kilobytes and only used by the argon2 methods.
+type LUKS2PBKDF struct {
+	Method      string `json:"method"`
+	Iterations  uint   `json:"iterations,omitempty"`
+	Memory      uint   `json:"memory,omitempty"`
+	Parallelism uint   `json:"parallelism,omitempty"`
+}
+
+// NewLUKS2CreateStage creates a new org.osbuild.luks2.format stage object.
+func NewLUKS2CreateStage(options *LUKS2CreateStageOptions, devices Devices) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.luks2.format",
+		Options: options,
+		Devices: devices,
+	}
+}
@@ -0,0 +1,19 @@
+package osbuild2
+
+// LUKS2RemoveKeyStageOptions remove the key slot unlocked by Passphrase from
+// the LUKS2 volume on the "device" of the stage.
+type LUKS2RemoveKeyStageOptions struct {
+	Passphrase string `json:"passphrase"`
+}
+
+func (LUKS2RemoveKeyStageOptions) isStageOptions() {}
+
+// NewLUKS2RemoveKeyStage creates a new org.osbuild.luks2.remove-key stage
+// object.
+func NewLUKS2RemoveKeyStage(options *LUKS2RemoveKeyStageOptions, devices Devices) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.luks2.remove-key",
+		Options: options,
+		Devices: devices,
+	}
+}
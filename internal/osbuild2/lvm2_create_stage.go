@@ -0,0 +1,26 @@
+package osbuild2
+
+// LVM2CreateStageOptions describe the logical volumes to create in a new
+// volume group on the "device" of the stage.
+type LVM2CreateStageOptions struct {
+	Volumes []LogicalVolume `json:"volumes"`
+}
+
+func (LVM2CreateStageOptions) isStageOptions() {}
+
+// LogicalVolume is created either with a fixed Size (in bytes, or with an
+// lvcreate unit suffix) or with a number of Extents, e.g. "100%FREE".
+type LogicalVolume struct {
+	Name    string `json:"name"`
+	Size    string `json:"size,omitempty"`
+	Extents string `json:"extents,omitempty"`
+}
+
+// NewLVM2CreateStage creates a new org.osbuild.lvm2.create stage object.
+func NewLVM2CreateStage(options *LVM2CreateStageOptions, devices Devices) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.lvm2.create",
+		Options: options,
+		Devices: devices,
+	}
+}
@@ -0,0 +1,22 @@
+package osbuild2
+
+// LVM2MetadataStageOptions set the metadata of the volume group on the
+// "device" of the stage. The volume group is created with a random name,
+// which is replaced by VGName.
+type LVM2MetadataStageOptions struct {
+	VGName       string `json:"vg_name"`
+	CreationHost string `json:"creation_host,omitempty"`
+	CreationTime string `json:"creation_time,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+func (LVM2MetadataStageOptions) isStageOptions() {}
+
+// NewLVM2MetadataStage creates a new org.osbuild.lvm2.metadata stage object.
+func NewLVM2MetadataStage(options *LVM2MetadataStageOptions, devices Devices) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.lvm2.metadata",
+		Options: options,
+		Devices: devices,
+	}
+}
@@ -0,0 +1,27 @@
+package osbuild2
+
+import "os"
+
+// The MkdirStageOptions describe the directories to create in the tree.
+type MkdirStageOptions struct {
+	Paths []MkdirStagePath `json:"paths"`
+}
+
+func (MkdirStageOptions) isStageOptions() {}
+
+type MkdirStagePath struct {
+	Path string      `json:"path"`
+	Mode os.FileMode `json:"mode,omitempty"`
+	// Create missing parent directories
+	Parents bool `json:"parents,omitempty"`
+	// Don't fail if the directory exists already
+	ExistOk bool `json:"exist_ok,omitempty"`
+}
+
+// NewMkdirStage creates a new org.osbuild.mkdir stage object.
+func NewMkdirStage(options *MkdirStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.mkdir",
+		Options: options,
+	}
+}
@@ -0,0 +1,57 @@
+package osbuild2
+
+// MkfsXfsStageOptions describe the xfs filesystem created on the "device" of
+// the stage.
+type MkfsXfsStageOptions struct {
+	UUID  string `json:"uuid"`
+	Label string `json:"label,omitempty"`
+}
+
+func (MkfsXfsStageOptions) isStageOptions() {}
+
+// NewMkfsXfsStage creates a new org.osbuild.mkfs.xfs stage object.
+func NewMkfsXfsStage(options *MkfsXfsStageOptions, devices Devices) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.mkfs.xfs",
+		Options: options,
+		Devices: devices,
+	}
+}
+
+// MkfsExt4StageOptions describe the ext4 filesystem created on the "device"
+// of the stage.
+type MkfsExt4StageOptions struct {
+	UUID  string `json:"uuid"`
+	Label string `json:"label,omitempty"`
+}
+
+func (MkfsExt4StageOptions) isStageOptions() {}
+
+// NewMkfsExt4Stage creates a new org.osbuild.mkfs.ext4 stage object.
+func NewMkfsExt4Stage(options *MkfsExt4StageOptions, devices Devices) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.mkfs.ext4",
+		Options: options,
+		Devices: devices,
+	}
+}
+
+// MkfsFATStageOptions describe the FAT filesystem created on the "device" of
+// the stage. VolID is the volume id as 8 hexadecimal digits, without the
+// dash of its usual notation.
+type MkfsFATStageOptions struct {
+	VolID   string `json:"volid"`
+	Label   string `json:"label,omitempty"`
+	FATSize *int   `json:"fat-size,omitempty"`
+}
+
+func (MkfsFATStageOptions) isStageOptions() {}
+
+// NewMkfsFATStage creates a new org.osbuild.mkfs.fat stage object.
+func NewMkfsFATStage(options *MkfsFATStageOptions, devices Devices) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.mkfs.fat",
+		Options: options,
+		Devices: devices,
+	}
+}
@@ -0,0 +1,34 @@
+package osbuild2
+
+// Options for the org.osbuild.ostree.config stage.
+type OSTreeConfigStageOptions struct {
+	// Location of the ostree repo
+	Repo string `json:"repo"`
+
+	Config *OSTreeConfig `json:"config,omitempty"`
+}
+
+func (OSTreeConfigStageOptions) isStageOptions() {}
+
+// OSTreeConfig are the settings of the repo's config file.
+type OSTreeConfig struct {
+	Sysroot *SysrootOptions `json:"sysroot,omitempty"`
+}
+
+// SysrootOptions configure how ostree manages the system it is deployed on.
+type SysrootOptions struct {
+	// Mount /sysroot read-only
+	ReadOnly *bool `json:"readonly,omitempty"`
+	// Boot loader configuration which ostree writes, e.g. none, when the
+	// boot loader reads the BLS entries itself
+	Bootloader string `json:"bootloader,omitempty"`
+}
+
+// A new org.osbuild.ostree.config stage to change the config of an ostree
+// repo
+func NewOSTreeConfigStage(options *OSTreeConfigStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.ostree.config",
+		Options: options,
+	}
+}
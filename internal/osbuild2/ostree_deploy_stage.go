@@ -0,0 +1,42 @@
+package osbuild2
+
+// Options for the org.osbuild.ostree.deploy stage.
+type OSTreeDeployStageOptions struct {
+	// Name of the operating system (stateroot) to deploy to
+	OSName string `json:"osname"`
+	// Ref of the commit in the repo of the tree which is deployed
+	Ref string `json:"ref"`
+	// Remote which the deployment is updated from
+	Remote string `json:"remote,omitempty"`
+	// Mountpoints of the deployment which are mounted during the deployment,
+	// e.g. /boot/efi
+	Mounts []string `json:"mounts,omitempty"`
+	// Root filesystem of the deployment, passed to the kernel
+	Rootfs *OSTreeRootfs `json:"rootfs,omitempty"`
+	// Kernel command line arguments of the deployment
+	KernelOpts []string `json:"kernel_opts,omitempty"`
+}
+
+func (OSTreeDeployStageOptions) isStageOptions() {}
+
+// OSTreeRootfs identifies the root filesystem of a deployment by its label
+// or UUID.
+type OSTreeRootfs struct {
+	Label string `json:"label,omitempty"`
+	UUID  string `json:"uuid,omitempty"`
+}
+
+// OSTreeDeployment identifies a deployment for stages which change it.
+type OSTreeDeployment struct {
+	OSName string `json:"osname"`
+	Ref    string `json:"ref"`
+}
+
+// A new org.osbuild.ostree.deploy stage to deploy a commit of the repo in
+// the tree
+func NewOSTreeDeployStage(options *OSTreeDeployStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.ostree.deploy",
+		Options: options,
+	}
+}
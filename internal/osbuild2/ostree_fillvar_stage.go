@@ -0,0 +1,17 @@
+package osbuild2
+
+// Options for the org.osbuild.ostree.fillvar stage.
+type OSTreeFillvarStageOptions struct {
+	Deployment OSTreeDeployment `json:"deployment"`
+}
+
+func (OSTreeFillvarStageOptions) isStageOptions() {}
+
+// A new org.osbuild.ostree.fillvar stage to create the content of /var of a
+// deployment, which ostree doesn't deploy
+func NewOSTreeFillvarStage(options *OSTreeFillvarStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.ostree.fillvar",
+		Options: options,
+	}
+}
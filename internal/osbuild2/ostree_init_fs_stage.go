@@ -0,0 +1,19 @@
+package osbuild2
+
+// An OSTreeInitFsStageOptions struct is empty, as the stage takes no options.
+//
+// The org.osbuild.ostree.init-fs stage creates the basic filesystem layout
+// of an ostree based system in the tree, which becomes the physical root of
+// the system.
+type OSTreeInitFsStageOptions struct {
+}
+
+func (OSTreeInitFsStageOptions) isStageOptions() {}
+
+// NewOSTreeInitFsStage creates a new org.osbuild.ostree.init-fs stage.
+func NewOSTreeInitFsStage() *Stage {
+	return &Stage{
+		Type:    "org.osbuild.ostree.init-fs",
+		Options: &OSTreeInitFsStageOptions{},
+	}
+}
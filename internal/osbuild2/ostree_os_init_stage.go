@@ -0,0 +1,18 @@
+package osbuild2
+
+// Options for the org.osbuild.ostree.os-init stage.
+type OSTreeOsInitStageOptions struct {
+	// Name of the operating system (stateroot) to set up
+	OSName string `json:"osname"`
+}
+
+func (OSTreeOsInitStageOptions) isStageOptions() {}
+
+// A new org.osbuild.ostree.os-init stage to set up the stateroot of an
+// operating system, into which commits are deployed
+func NewOSTreeOsInitStage(options *OSTreeOsInitStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.ostree.os-init",
+		Options: options,
+	}
+}
@@ -0,0 +1,29 @@
+package osbuild2
+
+// Options for the org.osbuild.ostree.remotes stage.
+type OSTreeRemotesStageOptions struct {
+	// Location of the ostree repo
+	Repo    string                     `json:"repo"`
+	Remotes []OSTreeRemotesStageRemote `json:"remotes"`
+}
+
+func (OSTreeRemotesStageOptions) isStageOptions() {}
+
+// OSTreeRemotesStageRemote is a remote from which updates are pulled.
+type OSTreeRemotesStageRemote struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Branches which are pulled from the remote, all if empty
+	Branches []string `json:"branches,omitempty"`
+	// GPG keys to verify the commits of the remote with
+	GPGKeys []string `json:"gpgkeys,omitempty"`
+}
+
+// A new org.osbuild.ostree.remotes stage to configure the remotes of an
+// ostree repo
+func NewOSTreeRemotesStage(options *OSTreeRemotesStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.ostree.remotes",
+		Options: options,
+	}
+}
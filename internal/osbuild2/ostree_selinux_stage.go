@@ -0,0 +1,17 @@
+package osbuild2
+
+// Options for the org.osbuild.ostree.selinux stage.
+type OSTreeSelinuxStageOptions struct {
+	Deployment OSTreeDeployment `json:"deployment"`
+}
+
+func (OSTreeSelinuxStageOptions) isStageOptions() {}
+
+// A new org.osbuild.ostree.selinux stage to label the files of a deployment
+// which are created when it is deployed, e.g. in /etc and /var
+func NewOSTreeSelinuxStage(options *OSTreeSelinuxStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.ostree.selinux",
+		Options: options,
+	}
+}
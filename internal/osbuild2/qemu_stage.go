@@ -0,0 +1,44 @@
+package osbuild2
+
+// QEMUStageOptions describe the format the image of the stage input is
+// converted to, and the file in the tree it is written to.
+type QEMUStageOptions struct {
+	Filename string     `json:"filename"`
+	Format   QEMUFormat `json:"format"`
+}
+
+func (QEMUStageOptions) isStageOptions() {}
+
+// QEMUFormat is an image format of qemu-img, e.g. qcow2 or vmdk, with its
+// format specific options.
+type QEMUFormat struct {
+	Type string `json:"type"`
+	// qcow2 only: the version of the format, e.g. 0.10 or 1.1
+	Compat string `json:"compat,omitempty"`
+	// vmdk: e.g. streamOptimized, vpc: fixed or dynamic
+	Subformat string `json:"subformat,omitempty"`
+	// vpc only: keep the exact virtual size instead of rounding it to the
+	// disk geometry, as Azure requires
+	ForceSize *bool `json:"force_size,omitempty"`
+}
+
+type QEMUStageInputs struct {
+	Image *PipelineFilesInput `json:"image"`
+}
+
+func (QEMUStageInputs) isStageInputs() {}
+
+// NewQEMUStageInputs creates the inputs of a qemu stage for the image file
+// `filename` in the tree of `pipeline`.
+func NewQEMUStageInputs(pipeline, filename string) *QEMUStageInputs {
+	return &QEMUStageInputs{Image: NewPipelineFilesInput(pipeline, filename)}
+}
+
+// NewQEMUStage creates a new org.osbuild.qemu stage object.
+func NewQEMUStage(options *QEMUStageOptions, inputs *QEMUStageInputs) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.qemu",
+		Options: options,
+		Inputs:  inputs,
+	}
+}
@@ -0,0 +1,31 @@
+package osbuild2
+
+// SfdiskStageOptions describe the partition table which is written to the
+// "device" of the stage. Start and Size of the partitions are counted in
+// sectors; a Size of 0 means up to the end of the device.
+type SfdiskStageOptions struct {
+	// Partition table type, e.g. dos, gpt
+	Label      string            `json:"label"`
+	UUID       string            `json:"uuid"`
+	Partitions []SfdiskPartition `json:"partitions"`
+}
+
+func (SfdiskStageOptions) isStageOptions() {}
+
+type SfdiskPartition struct {
+	Bootable bool   `json:"bootable,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Size     uint64 `json:"size,omitempty"`
+	Start    uint64 `json:"start,omitempty"`
+	Type     string `json:"type,omitempty"`
+	UUID     string `json:"uuid,omitempty"`
+}
+
+// NewSfdiskStage creates a new org.osbuild.sfdisk stage object.
+func NewSfdiskStage(options *SfdiskStageOptions, devices Devices) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.sfdisk",
+		Options: options,
+		Devices: devices,
+	}
+}
@@ -87,6 +87,19 @@ func TestSource_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"org.osbuild.curl":{"items":{"checksum1":"url1","checksum2":"url2"}}}`),
 			},
 		},
+		{
+			name: "inline",
+			fields: fields{
+				Type: "org.osbuild.inline",
+				Source: &InlineSource{
+					Items: map[string]InlineSourceItem{
+						"sha256:checksum1": {Encoding: "base64", Data: "aGVsbG8K"},
+					}},
+			},
+			args: args{
+				data: []byte(`{"org.osbuild.inline":{"items":{"sha256:checksum1":{"encoding":"base64","data":"aGVsbG8K"}}}}`),
+			},
+		},
 	}
 	for idx, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -14,6 +14,9 @@ type Stage struct {
 
 	Inputs  Inputs       `json:"inputs,omitempty"`
 	Options StageOptions `json:"options,omitempty"`
+	// Block devices and filesystems on them which the stage operates on
+	Devices Devices `json:"devices,omitempty"`
+	Mounts  []Mount `json:"mounts,omitempty"`
 }
 
 // Collection of Inputs for a Stage
@@ -55,6 +58,8 @@ type rawStage struct {
 	Type    string          `json:"type"`
 	Options json.RawMessage `json:"options"`
 	Inputs  json.RawMessage `json:"inputs"`
+	Devices Devices         `json:"devices"`
+	Mounts  []Mount         `json:"mounts"`
 }
 
 // UnmarshalJSON unmarshals JSON into a Stage object. Each type of stage has
@@ -117,6 +122,67 @@ func (stage *Stage) UnmarshalJSON(data []byte) error {
 		options = new(OSTreeInitStageOptions)
 	case "org.osbuild.ostree.preptree":
 		options = new(OSTreePrepTreeStageOptions)
+	case "org.osbuild.ostree.init-fs":
+		options = new(OSTreeInitFsStageOptions)
+	case "org.osbuild.ostree.os-init":
+		options = new(OSTreeOsInitStageOptions)
+	case "org.osbuild.ostree.config":
+		options = new(OSTreeConfigStageOptions)
+	case "org.osbuild.ostree.remotes":
+		options = new(OSTreeRemotesStageOptions)
+	case "org.osbuild.ostree.deploy":
+		options = new(OSTreeDeployStageOptions)
+	case "org.osbuild.ostree.fillvar":
+		options = new(OSTreeFillvarStageOptions)
+	case "org.osbuild.ostree.selinux":
+		options = new(OSTreeSelinuxStageOptions)
+	case "org.osbuild.ignition":
+		options = new(IgnitionStageOptions)
+	case "org.osbuild.luks2.format":
+		options = new(LUKS2CreateStageOptions)
+	case "org.osbuild.luks2.remove-key":
+		options = new(LUKS2RemoveKeyStageOptions)
+	case "org.osbuild.clevis.luks-bind":
+		options = new(ClevisLuksBindStageOptions)
+	case "org.osbuild.lvm2.create":
+		options = new(LVM2CreateStageOptions)
+	case "org.osbuild.lvm2.metadata":
+		options = new(LVM2MetadataStageOptions)
+	case "org.osbuild.crypttab":
+		options = new(CrypttabStageOptions)
+	case "org.osbuild.copy":
+		options = new(CopyStageOptions)
+		inputs = newCopyStageInputs(rawStage.Inputs)
+	case "org.osbuild.mkdir":
+		options = new(MkdirStageOptions)
+	case "org.osbuild.chmod":
+		options = new(ChmodStageOptions)
+	case "org.osbuild.chown":
+		options = new(ChownStageOptions)
+	case "org.osbuild.truncate":
+		options = new(TruncateStageOptions)
+	case "org.osbuild.sfdisk":
+		options = new(SfdiskStageOptions)
+	case "org.osbuild.mkfs.xfs":
+		options = new(MkfsXfsStageOptions)
+	case "org.osbuild.mkfs.ext4":
+		options = new(MkfsExt4StageOptions)
+	case "org.osbuild.mkfs.fat":
+		options = new(MkfsFATStageOptions)
+	case "org.osbuild.grub2.inst":
+		options = new(Grub2InstStageOptions)
+	case "org.osbuild.qemu":
+		options = new(QEMUStageOptions)
+		inputs = new(QEMUStageInputs)
+	case "org.osbuild.xz":
+		options = new(XzStageOptions)
+		inputs = new(XzStageInputs)
+	case "org.osbuild.dracut":
+		options = new(DracutStageOptions)
+	case "org.osbuild.waagent.conf":
+		options = new(WAAgentConfStageOptions)
+	case "org.osbuild.tuned":
+		options = new(TunedStageOptions)
 	default:
 		return fmt.Errorf("unexpected stage type: %s", rawStage.Type)
 	}
@@ -132,6 +198,8 @@ func (stage *Stage) UnmarshalJSON(data []byte) error {
 	stage.Type = rawStage.Type
 	stage.Options = options
 	stage.Inputs = inputs
+	stage.Devices = rawStage.Devices
+	stage.Mounts = rawStage.Mounts
 
 	return nil
 }
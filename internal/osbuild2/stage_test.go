@@ -72,6 +72,55 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"type":"org.osbuild.firewall","options":{}}`),
 			},
 		},
+		{
+			name: "firewall-zones",
+			fields: fields{
+				Type: "org.osbuild.firewall",
+				Options: &FirewallStageOptions{
+					Ports: []string{"8080:tcp"},
+					Zones: []FirewallZone{{Name: "trusted", Sources: []string{"10.0.0.0/8", "192.168.0.1"}}},
+				},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.firewall","options":{"ports":["8080:tcp"],"zones":[{"name":"trusted","sources":["10.0.0.0/8","192.168.0.1"]}]}}`),
+			},
+		},
+		{
+			name: "chmod",
+			fields: fields{
+				Type: "org.osbuild.chmod",
+				Options: &ChmodStageOptions{
+					Items: map[string]ChmodStagePathOptions{"/etc/motd": {Mode: "0640"}},
+				},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.chmod","options":{"items":{"/etc/motd":{"mode":"0640"}}}}`),
+			},
+		},
+		{
+			name: "chown",
+			fields: fields{
+				Type: "org.osbuild.chown",
+				Options: &ChownStageOptions{
+					Items: map[string]ChownStagePathOptions{"/etc/motd": {User: "root", Group: "1000"}},
+				},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.chown","options":{"items":{"/etc/motd":{"user":"root","group":"1000"}}}}`),
+			},
+		},
+		{
+			name: "mkdir",
+			fields: fields{
+				Type: "org.osbuild.mkdir",
+				Options: &MkdirStageOptions{
+					Paths: []MkdirStagePath{{Path: "/etc/foo/bar", Mode: 0750, Parents: true, ExistOk: true}},
+				},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.mkdir","options":{"paths":[{"path":"/etc/foo/bar","mode":488,"parents":true,"exist_ok":true}]}}`),
+			},
+		},
 		{
 			name: "fix-bls",
 			fields: fields{
@@ -293,6 +342,8 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 
 // Test new stages that have Inputs (osbuild v2 schema)
 func TestStageV2_UnmarshalJSON(t *testing.T) {
+	level := 9
+	yes, no := true, false
 	type fields struct {
 		Type    string
 		Options StageOptions
@@ -338,6 +389,137 @@ func TestStageV2_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"type":"org.osbuild.rpm","inputs":{"packages":{"type":"","origin":"","references":["checksum1","checksum2"]}},"options":{"gpgkeys":["key1","key2"]}}`),
 			},
 		},
+		{
+			name: "copy",
+			fields: fields{
+				Type: "org.osbuild.copy",
+				Inputs: &CopyStageFilesInputs{
+					"inlinefile": NewFilesInputRef([]string{"sha256:checksum1"}),
+				},
+				Options: &CopyStageOptions{
+					Paths: []CopyStagePath{{From: "input://inlinefile/sha256:checksum1", To: "tree:///etc/motd"}},
+				},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.copy","inputs":{"inlinefile":{"type":"org.osbuild.files","origin":"org.osbuild.source","references":["sha256:checksum1"]}},"options":{"paths":[{"from":"input://inlinefile/sha256:checksum1","to":"tree:///etc/motd"}]}}`),
+			},
+		},
+		{
+			name: "copy-tree",
+			fields: fields{
+				Type: "org.osbuild.copy",
+				Inputs: &CopyStageTreeInputs{
+					"tree": NewTreeInputRef("os"),
+				},
+				Options: &CopyStageOptions{
+					Paths: []CopyStagePath{{From: "input://tree/", To: "mount://root/"}},
+				},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.copy","inputs":{"tree":{"type":"org.osbuild.tree","origin":"org.osbuild.pipeline","references":["name:os"]}},"options":{"paths":[{"from":"input://tree/","to":"mount://root/"}]}}`),
+			},
+		},
+		{
+			name: "qemu",
+			fields: fields{
+				Type:    "org.osbuild.qemu",
+				Inputs:  NewQEMUStageInputs("image", "disk.img"),
+				Options: &QEMUStageOptions{Filename: "box.img", Format: QEMUFormat{Type: "qcow2"}},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.qemu","inputs":{"image":{"type":"org.osbuild.files","origin":"org.osbuild.pipeline","references":{"name:image":{"file":"disk.img"}}}},"options":{"filename":"box.img","format":{"type":"qcow2"}}}`),
+			},
+		},
+		{
+			name: "xz",
+			fields: fields{
+				Type:    "org.osbuild.xz",
+				Inputs:  NewXzStageInputs("image", "disk.img"),
+				Options: &XzStageOptions{Filename: "disk.raw.xz", Level: &level},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.xz","inputs":{"file":{"type":"org.osbuild.files","origin":"org.osbuild.pipeline","references":{"name:image":{"file":"disk.img"}}}},"options":{"filename":"disk.raw.xz","level":9}}`),
+			},
+		},
+		{
+			name: "qemu-vpc",
+			fields: fields{
+				Type:    "org.osbuild.qemu",
+				Inputs:  NewQEMUStageInputs("image", "disk.img"),
+				Options: &QEMUStageOptions{Filename: "disk.vhd", Format: QEMUFormat{Type: "vpc", Subformat: "fixed", ForceSize: &yes}},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.qemu","inputs":{"image":{"type":"org.osbuild.files","origin":"org.osbuild.pipeline","references":{"name:image":{"file":"disk.img"}}}},"options":{"filename":"disk.vhd","format":{"type":"vpc","subformat":"fixed","force_size":true}}}`),
+			},
+		},
+		{
+			name: "waagent-conf",
+			fields: fields{
+				Type: "org.osbuild.waagent.conf",
+				Options: &WAAgentConfStageOptions{
+					Config: WAAgentConfig{ProvisioningUseCloudInit: &yes, ResourceDiskFormat: &no},
+				},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.waagent.conf","options":{"config":{"Provisioning.UseCloudInit":true,"ResourceDisk.Format":false}}}`),
+			},
+		},
+		{
+			name: "dracut",
+			fields: fields{
+				Type:    "org.osbuild.dracut",
+				Options: &DracutStageOptions{Kernel: []string{"4.18.0-305.el8.x86_64"}, AddDrivers: []string{"hv_vmbus"}},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.dracut","options":{"kernel":["4.18.0-305.el8.x86_64"],"add_drivers":["hv_vmbus"]}}`),
+			},
+		},
+		{
+			name: "ostree-init-fs",
+			fields: fields{
+				Type:    "org.osbuild.ostree.init-fs",
+				Options: &OSTreeInitFsStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.ostree.init-fs","options":{}}`),
+			},
+		},
+		{
+			name: "ostree-deploy",
+			fields: fields{
+				Type: "org.osbuild.ostree.deploy",
+				Options: &OSTreeDeployStageOptions{
+					OSName:     "redhat",
+					Ref:        "rhel/8/x86_64/edge",
+					Mounts:     []string{"/boot/efi"},
+					Rootfs:     &OSTreeRootfs{Label: "root"},
+					KernelOpts: []string{"console=ttyS0"},
+				},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.ostree.deploy","options":{"osname":"redhat","ref":"rhel/8/x86_64/edge","mounts":["/boot/efi"],"rootfs":{"label":"root"},"kernel_opts":["console=ttyS0"]}}`),
+			},
+		},
+		{
+			name: "ostree-fillvar",
+			fields: fields{
+				Type:    "org.osbuild.ostree.fillvar",
+				Options: &OSTreeFillvarStageOptions{Deployment: OSTreeDeployment{OSName: "redhat", Ref: "rhel/8/x86_64/edge"}},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.ostree.fillvar","options":{"deployment":{"osname":"redhat","ref":"rhel/8/x86_64/edge"}}}`),
+			},
+		},
+		{
+			name: "ignition",
+			fields: fields{
+				Type:    "org.osbuild.ignition",
+				Options: &IgnitionStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"type":"org.osbuild.ignition","options":{}}`),
+			},
+		},
 		{
 			name: "ostree-preptree",
 			fields: fields{
@@ -12,6 +12,13 @@ type TarStageOptions struct {
 
 	// Enable support for extended attributes
 	Xattrs bool `json:"xattrs,omitempty"`
+
+	// Archive format, e.g. oldgnu or posix
+	Format string `json:"format,omitempty"`
+
+	// Whether the root directory of the tree is included in the archive
+	// ("include") or only its contents ("omit")
+	RootNode string `json:"root-node,omitempty"`
 }
 
 func (TarStageOptions) isStageOptions() {}
@@ -0,0 +1,19 @@
+package osbuild2
+
+// TruncateStageOptions create the image file Filename in the tree, or
+// resize it, to Size. Size is a number of bytes with an optional unit
+// suffix, as understood by truncate(1).
+type TruncateStageOptions struct {
+	Filename string `json:"filename"`
+	Size     string `json:"size"`
+}
+
+func (TruncateStageOptions) isStageOptions() {}
+
+// NewTruncateStage creates a new org.osbuild.truncate stage object.
+func NewTruncateStage(options *TruncateStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.truncate",
+		Options: options,
+	}
+}
@@ -0,0 +1,17 @@
+package osbuild2
+
+// TunedStageOptions select the tuned profiles which are applied when the
+// image boots.
+type TunedStageOptions struct {
+	Profiles []string `json:"profiles"`
+}
+
+func (TunedStageOptions) isStageOptions() {}
+
+// NewTunedStage creates a new org.osbuild.tuned stage object.
+func NewTunedStage(options *TunedStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.tuned",
+		Options: options,
+	}
+}
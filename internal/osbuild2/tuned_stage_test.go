@@ -0,0 +1,16 @@
+package osbuild2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTunedStage(t *testing.T) {
+	expectedStage := &Stage{
+		Type:    "org.osbuild.tuned",
+		Options: &TunedStageOptions{Profiles: []string{"sap-hana"}},
+	}
+	actualStage := NewTunedStage(&TunedStageOptions{Profiles: []string{"sap-hana"}})
+	assert.Equal(t, expectedStage, actualStage)
+}
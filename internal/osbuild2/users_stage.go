@@ -7,14 +7,16 @@ type UsersStageOptions struct {
 func (UsersStageOptions) isStageOptions() {}
 
 type UsersStageOptionsUser struct {
-	UID         *int     `json:"uid,omitempty"`
-	GID         *int     `json:"gid,omitempty"`
-	Groups      []string `json:"groups,omitempty"`
-	Description *string  `json:"description,omitempty"`
-	Home        *string  `json:"home,omitempty"`
-	Shell       *string  `json:"shell,omitempty"`
-	Password    *string  `json:"password,omitempty"`
-	Key         *string  `json:"key,omitempty"`
+	UID                *int     `json:"uid,omitempty"`
+	GID                *int     `json:"gid,omitempty"`
+	Groups             []string `json:"groups,omitempty"`
+	Description        *string  `json:"description,omitempty"`
+	Home               *string  `json:"home,omitempty"`
+	Shell              *string  `json:"shell,omitempty"`
+	Password           *string  `json:"password,omitempty"`
+	Key                *string  `json:"key,omitempty"`
+	ExpireDate         *int     `json:"expiredate,omitempty"`
+	ForcePasswordReset *bool    `json:"force_password_reset,omitempty"`
 }
 
 func NewUsersStage(options *UsersStageOptions) *Stage {
@@ -0,0 +1,29 @@
+package osbuild2
+
+// WAAgentConfStageOptions set options of the Azure Linux Agent in
+// /etc/waagent.conf. Options which are not set keep the value of the
+// configuration file shipped with WALinuxAgent.
+type WAAgentConfStageOptions struct {
+	Config WAAgentConfig `json:"config"`
+}
+
+func (WAAgentConfStageOptions) isStageOptions() {}
+
+type WAAgentConfig struct {
+	// Whether the agent provisions the instance itself
+	Provisioning *bool `json:"Provisioning.Enabled,omitempty"`
+	// Whether cloud-init provisions the instance instead of the agent
+	ProvisioningUseCloudInit *bool `json:"Provisioning.UseCloudInit,omitempty"`
+	// Whether the agent formats and mounts the resource disk
+	ResourceDiskFormat *bool `json:"ResourceDisk.Format,omitempty"`
+	// Whether the agent creates a swap file on the resource disk
+	ResourceDiskEnableSwap *bool `json:"ResourceDisk.EnableSwap,omitempty"`
+}
+
+// NewWAAgentConfStage creates a new org.osbuild.waagent.conf stage object.
+func NewWAAgentConfStage(options *WAAgentConfStageOptions) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.waagent.conf",
+		Options: options,
+	}
+}
@@ -0,0 +1,32 @@
+package osbuild2
+
+// XzStageOptions describe the file in the tree to which the file of the
+// stage input is compressed. Level is the compression preset (0-9) of xz;
+// its default is used if it is nil.
+type XzStageOptions struct {
+	Filename string `json:"filename"`
+	Level    *int   `json:"level,omitempty"`
+}
+
+func (XzStageOptions) isStageOptions() {}
+
+type XzStageInputs struct {
+	File *PipelineFilesInput `json:"file"`
+}
+
+func (XzStageInputs) isStageInputs() {}
+
+// NewXzStageInputs creates the inputs of an xz stage for the file
+// `filename` in the tree of `pipeline`.
+func NewXzStageInputs(pipeline, filename string) *XzStageInputs {
+	return &XzStageInputs{File: NewPipelineFilesInput(pipeline, filename)}
+}
+
+// NewXzStage creates a new org.osbuild.xz stage object.
+func NewXzStage(options *XzStageOptions, inputs *XzStageInputs) *Stage {
+	return &Stage{
+		Type:    "org.osbuild.xz",
+		Options: options,
+		Inputs:  inputs,
+	}
+}
@@ -26,6 +26,13 @@ func VerifyRef(ref string) bool {
 	return false
 }
 
+// VerifyChecksum returns whether `checksum` is a valid OSTree commit checksum,
+// i.e., a hex-encoded SHA-256 hash.
+func VerifyChecksum(checksum string) bool {
+	b, err := hex.DecodeString(checksum)
+	return err == nil && len(b) == 32
+}
+
 func ResolveRef(location, ref string) (string, error) {
 	u, err := url.Parse(location)
 	if err != nil {
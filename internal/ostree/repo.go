@@ -0,0 +1,150 @@
+package ostree
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Repo is an archive OSTree repository which collects the commits of all
+// successful commit composes, so that installers and subsequent composes can
+// pull them from a single, stable location.
+//
+// It lives in `dir`: the repository itself in `dir/repo` and a marker for
+// each imported compose in `dir/imported`.
+type Repo struct {
+	dir string
+
+	mu sync.Mutex
+}
+
+// InitRepo opens the repository in `dir`, creating it if it doesn't exist.
+func InitRepo(dir string) (*Repo, error) {
+	r := &Repo{dir: dir}
+
+	err := os.MkdirAll(path.Join(dir, "imported"), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = os.Stat(path.Join(r.path(), "config"))
+	if os.IsNotExist(err) {
+		err = ostree("init", "--mode=archive", "--repo="+r.path())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error initializing OSTree repository: %v", err)
+	}
+
+	return r, nil
+}
+
+func (r *Repo) path() string {
+	return path.Join(r.dir, "repo")
+}
+
+// Imported returns whether the commit of compose `id` has been imported.
+func (r *Repo) Imported(id uuid.UUID) bool {
+	_, err := os.Stat(path.Join(r.dir, "imported", id.String()))
+	return err == nil
+}
+
+// Import pulls all refs of the repository in the commit archive of compose
+// `id` into the repository and regenerates its summary. Refs which already
+// exist are moved to the new commits.
+func (r *Repo) Import(id uuid.UUID, archive io.Reader) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Imported(id) {
+		return nil
+	}
+
+	tmpDir, err := ioutil.TempDir(r.dir, "import-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = extractRepo(archive, tmpDir)
+	if err != nil {
+		return err
+	}
+
+	refs, err := listRefs(tmpDir)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		return fmt.Errorf("commit archive of compose %s does not contain any refs", id)
+	}
+
+	err = ostree(append([]string{"pull-local", "--repo=" + r.path(), tmpDir}, refs...)...)
+	if err != nil {
+		return fmt.Errorf("error importing commit of compose %s: %v", id, err)
+	}
+
+	err = ostree("summary", "--update", "--repo="+r.path())
+	if err != nil {
+		return fmt.Errorf("error updating repository summary: %v", err)
+	}
+
+	return ioutil.WriteFile(path.Join(r.dir, "imported", id.String()), nil, 0644)
+}
+
+// Handler serves the repository read-only over HTTP.
+func (r *Repo) Handler() http.Handler {
+	files := http.FileServer(http.Dir(r.path()))
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if readOnlyRequest(w, req) {
+			files.ServeHTTP(w, req)
+		}
+	})
+}
+
+// Returns the refs in `refs/heads` of the repository in `repo`.
+func listRefs(repo string) ([]string, error) {
+	heads := filepath.Join(repo, "refs", "heads")
+
+	var refs []string
+	err := filepath.Walk(heads, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			ref, err := filepath.Rel(heads, p)
+			if err != nil {
+				return err
+			}
+			refs = append(refs, filepath.ToSlash(ref))
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return refs, nil
+}
+
+func ostree(args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("ostree", args...)
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("ostree %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
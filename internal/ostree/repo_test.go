@@ -0,0 +1,66 @@
+package ostree
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoImport(t *testing.T) {
+	if _, err := exec.LookPath("ostree"); err != nil {
+		t.Skip("ostree is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "ostree-repo-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Create a commit archive from a real commit
+	src, err := InitRepo(dir + "/src")
+	require.NoError(t, err)
+	tree := dir + "/tree"
+	require.NoError(t, os.MkdirAll(tree+"/usr", 0755))
+	require.NoError(t, ioutil.WriteFile(tree+"/usr/hello", []byte("hello"), 0644))
+	require.NoError(t, ostree("commit", "--repo="+src.path(), "--branch=test/edge", tree))
+
+	var archive bytes.Buffer
+	cmd := exec.Command("tar", "-cf", "-", "repo")
+	cmd.Dir = dir + "/src"
+	cmd.Stdout = &archive
+	require.NoError(t, cmd.Run())
+
+	repo, err := InitRepo(dir + "/composer")
+	require.NoError(t, err)
+
+	id := uuid.New()
+	require.False(t, repo.Imported(id))
+	require.NoError(t, repo.Import(id, bytes.NewReader(archive.Bytes())))
+	require.True(t, repo.Imported(id))
+
+	// Reopening an existing repository keeps its imports
+	repo, err = InitRepo(dir + "/composer")
+	require.NoError(t, err)
+	require.True(t, repo.Imported(id))
+
+	srv := httptest.NewServer(repo.Handler())
+	defer srv.Close()
+
+	checksum, err := ResolveRef(srv.URL, "test/edge")
+	require.NoError(t, err)
+	require.True(t, VerifyChecksum(checksum))
+
+	response, err := http.Get(srv.URL + "/summary")
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	err = repo.Import(uuid.New(), bytes.NewReader(commitArchive(t, map[string]string{"disk.img": "not a commit"})))
+	require.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package ostree
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// CommitOpener opens the tar archive of the finished OSTree commit compose
+// `id`, which contains the commit's repository in `repo/`.
+type CommitOpener func(id uuid.UUID) (io.Reader, error)
+
+// RepoServer serves the OSTree repositories of finished commit composes
+// read-only over HTTP, so that they can be used as the parent of subsequent
+// builds. The repository of compose `id` is available at `/{id}/`, which can
+// be passed as the URL of an OSTree request.
+//
+// Repositories are extracted from the compose's commit archive into a cache
+// directory the first time they are requested.
+type RepoServer struct {
+	open     CommitOpener
+	cacheDir string
+
+	mu sync.Mutex
+}
+
+func NewRepoServer(open CommitOpener, cacheDir string) *RepoServer {
+	return &RepoServer{
+		open:     open,
+		cacheDir: cacheDir,
+	}
+}
+
+func (s *RepoServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !readOnlyRequest(w, r) {
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	id, err := uuid.Parse(parts[0])
+	if err != nil || len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	repo, err := s.repo(id)
+	if err != nil {
+		log.Printf("Error serving OSTree repository of compose %s: %v", id, err)
+		http.Error(w, fmt.Sprintf("no OSTree repository for compose %s", id), http.StatusNotFound)
+		return
+	}
+
+	r.URL.Path = "/" + parts[1]
+	http.FileServer(http.Dir(repo)).ServeHTTP(w, r)
+}
+
+// Returns the directory containing the repository of compose `id`,
+// extracting it first if needed.
+func (s *RepoServer) repo(id uuid.UUID) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := path.Join(s.cacheDir, id.String())
+	_, err := os.Stat(dir)
+	if err == nil {
+		return dir, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	archive, err := s.open(id)
+	if err != nil {
+		return "", err
+	}
+	if closer, ok := archive.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	// Extract into a temporary directory first, so that a failed
+	// extraction is not mistaken for a complete repository later.
+	tmpDir := dir + ".tmp"
+	err = os.RemoveAll(tmpDir)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = extractRepo(archive, tmpDir)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Rename(tmpDir, dir)
+	if err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// Extracts the directories and regular files below `repo/` in the tar archive
+// read from `r` into `dir`. Everything else is skipped.
+func extractRepo(r io.Reader, dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("error reading commit archive: %v", err)
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if name == "repo" {
+			found = true
+			continue
+		}
+		if !strings.HasPrefix(name, "repo/") {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(name, "repo/")))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = extractFile(archive, target)
+		}
+		if err != nil {
+			return err
+		}
+		found = true
+	}
+
+	if !found {
+		return errors.New("commit archive does not contain an OSTree repository")
+	}
+
+	return nil
+}
+
+func extractFile(r io.Reader, target string) error {
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// Returns whether `r` only reads. Otherwise, responds with 405 Method Not
+// Allowed.
+func readOnlyRequest(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package ostree
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func commitArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	archive := tar.NewWriter(&buf)
+	for name, content := range files {
+		err := archive.WriteHeader(&tar.Header{
+			Name:     name,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(content)),
+		})
+		require.NoError(t, err)
+		_, err = archive.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestRepoServer(t *testing.T) {
+	checksum := "5330bb1b8820944567f519de66ad6354c729b6b490dea1c5a7ba320c9f147c58"
+
+	commitID := uuid.New()
+	otherID := uuid.New()
+	archives := map[uuid.UUID][]byte{
+		commitID: commitArchive(t, map[string]string{
+			"compose.json":                     "{}",
+			"./repo/config":                    "[core]\nmode=archive-z2\n",
+			"./repo/refs/heads/rhel/8/edge":    checksum + "\n",
+			"./repo/objects/53/30bb1b.commit":  "commit",
+			"./repo/../../escaped":             "nope",
+			"./repo/objects/../../../escaped2": "nope",
+		}),
+		otherID: commitArchive(t, map[string]string{
+			"disk.img": "not a commit",
+		}),
+	}
+
+	opened := 0
+	open := func(id uuid.UUID) (io.Reader, error) {
+		opened++
+		archive, ok := archives[id]
+		if !ok {
+			return nil, errors.New("no such compose")
+		}
+		return bytes.NewReader(archive), nil
+	}
+
+	cacheDir, err := ioutil.TempDir("", "ostree-repos-")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+
+	reposDir := path.Join(cacheDir, "repos")
+	require.NoError(t, os.Mkdir(reposDir, 0700))
+
+	srv := httptest.NewServer(NewRepoServer(open, reposDir))
+	defer srv.Close()
+
+	parent, err := ResolveRef(srv.URL+"/"+commitID.String(), "rhel/8/edge")
+	require.NoError(t, err)
+	require.Equal(t, checksum, parent)
+
+	response, err := http.Get(srv.URL + "/" + commitID.String() + "/objects/53/30bb1b.commit")
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "commit", string(body))
+
+	// the repository is only extracted once
+	require.Equal(t, 1, opened)
+	require.NoFileExists(t, path.Join(cacheDir, "escaped"))
+	require.NoFileExists(t, path.Join(reposDir, "escaped2"))
+
+	for _, p := range []string{
+		"/" + commitID.String() + "/compose.json",
+		"/" + otherID.String() + "/config",
+		"/" + uuid.New().String() + "/config",
+		"/not-a-uuid/config",
+	} {
+		response, err = http.Get(srv.URL + p)
+		require.NoError(t, err)
+		response.Body.Close()
+		require.Equal(t, http.StatusNotFound, response.StatusCode, p)
+	}
+
+	response, err = http.Post(srv.URL+"/"+commitID.String()+"/config", "text/plain", nil)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+}
@@ -0,0 +1,148 @@
+// Package provenance describes how an image was built as an in-toto
+// statement with a SLSA provenance predicate.
+package provenance
+
+import (
+	"strings"
+	"time"
+
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/sbom"
+)
+
+const (
+	StatementType = "https://in-toto.io/Statement/v0.1"
+	PredicateType = "https://slsa.dev/provenance/v0.2"
+
+	// Identifies how composer builds images: it depsolves the packages of
+	// the image, generates an osbuild manifest from them and builds it
+	BuildType = "https://osbuild.org/osbuild-composer/image@v1"
+)
+
+// Build describes the build of an image.
+type Build struct {
+	// Identifies the composer instance which built the image
+	BuilderID string
+
+	// The files of the image, by their name
+	Files []File
+
+	// Parameters of the compose request the image was built for
+	Parameters interface{}
+
+	Started  time.Time
+	Finished time.Time
+
+	// The packages which were installed into the image, which belong to
+	// `Distribution`
+	Packages     []rpmmd.PackageSpec
+	Distribution string
+}
+
+type File struct {
+	Name   string
+	SHA256 string
+}
+
+type Statement struct {
+	Type          string     `json:"_type"`
+	Subject       []Subject  `json:"subject"`
+	PredicateType string     `json:"predicateType"`
+	Predicate     Provenance `json:"predicate"`
+}
+
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type Provenance struct {
+	Builder    Builder    `json:"builder"`
+	BuildType  string     `json:"buildType"`
+	Invocation Invocation `json:"invocation"`
+	Metadata   Metadata   `json:"metadata"`
+	Materials  []Material `json:"materials"`
+}
+
+type Builder struct {
+	ID string `json:"id"`
+}
+
+type Invocation struct {
+	Parameters interface{} `json:"parameters,omitempty"`
+}
+
+type Metadata struct {
+	BuildStartedOn  string       `json:"buildStartedOn,omitempty"`
+	BuildFinishedOn string       `json:"buildFinishedOn,omitempty"`
+	Completeness    Completeness `json:"completeness"`
+	Reproducible    bool         `json:"reproducible"`
+}
+
+type Completeness struct {
+	Parameters  bool `json:"parameters"`
+	Environment bool `json:"environment"`
+	Materials   bool `json:"materials"`
+}
+
+type Material struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// Statement returns the provenance statement of the build, whose subjects
+// are the files of the image and whose materials are its packages.
+func (b *Build) Statement() *Statement {
+	statement := &Statement{
+		Type:          StatementType,
+		Subject:       []Subject{},
+		PredicateType: PredicateType,
+		Predicate: Provenance{
+			Builder:   Builder{ID: b.BuilderID},
+			BuildType: BuildType,
+			Invocation: Invocation{
+				Parameters: b.Parameters,
+			},
+			Metadata: Metadata{
+				BuildStartedOn:  timestamp(b.Started),
+				BuildFinishedOn: timestamp(b.Finished),
+				Completeness: Completeness{
+					Parameters: true,
+					Materials:  len(b.Packages) > 0,
+				},
+			},
+			Materials: []Material{},
+		},
+	}
+
+	for _, f := range b.Files {
+		statement.Subject = append(statement.Subject, Subject{
+			Name:   f.Name,
+			Digest: map[string]string{"sha256": f.SHA256},
+		})
+	}
+
+	seen := make(map[string]bool)
+	for _, pkg := range b.Packages {
+		purl := sbom.PackageURL(b.Distribution, pkg)
+		if seen[purl] {
+			continue
+		}
+		seen[purl] = true
+
+		material := Material{URI: purl}
+		if parts := strings.SplitN(pkg.Checksum, ":", 2); len(parts) == 2 {
+			material.Digest = map[string]string{parts[0]: parts[1]}
+		}
+		statement.Predicate.Materials = append(statement.Predicate.Materials, material)
+	}
+
+	return statement
+}
+
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
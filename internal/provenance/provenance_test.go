@@ -0,0 +1,55 @@
+package provenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+func TestStatement(t *testing.T) {
+	build := Build{
+		BuilderID: "https://composer.example.com",
+		Files: []File{
+			{Name: "disk.qcow2", SHA256: "aaaa"},
+		},
+		Parameters: map[string]string{"image_type": "qcow2"},
+		Started:    time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC),
+		Finished:   time.Date(2021, 10, 1, 12, 30, 0, 0, time.UTC),
+		Packages: []rpmmd.PackageSpec{
+			{Name: "bash", Version: "4.4.20", Release: "2.el8", Arch: "x86_64", Checksum: "sha256:bbbb"},
+			{Name: "bash", Version: "4.4.20", Release: "2.el8", Arch: "x86_64", Checksum: "sha256:bbbb"},
+			{Name: "zlib", Version: "1.2.11", Release: "17.el8", Arch: "x86_64"},
+		},
+		Distribution: "rhel-85",
+	}
+
+	require.Equal(t, &Statement{
+		Type: StatementType,
+		Subject: []Subject{
+			{Name: "disk.qcow2", Digest: map[string]string{"sha256": "aaaa"}},
+		},
+		PredicateType: PredicateType,
+		Predicate: Provenance{
+			Builder:   Builder{ID: "https://composer.example.com"},
+			BuildType: BuildType,
+			Invocation: Invocation{
+				Parameters: map[string]string{"image_type": "qcow2"},
+			},
+			Metadata: Metadata{
+				BuildStartedOn:  "2021-10-01T12:00:00Z",
+				BuildFinishedOn: "2021-10-01T12:30:00Z",
+				Completeness: Completeness{
+					Parameters: true,
+					Materials:  true,
+				},
+			},
+			Materials: []Material{
+				{URI: "pkg:rpm/redhat/bash@4.4.20-2.el8?arch=x86_64&distro=rhel-85", Digest: map[string]string{"sha256": "bbbb"}},
+				{URI: "pkg:rpm/redhat/zlib@1.2.11-17.el8?arch=x86_64&distro=rhel-85"},
+			},
+		},
+	}, build.Statement())
+}
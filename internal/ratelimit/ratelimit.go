@@ -0,0 +1,128 @@
+// Package ratelimit limits the rate of requests of each client of an API with
+// a token bucket per client.
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Buckets which have been refilled completely are forgotten at most this
+// often, so that clients which stopped sending requests don't take up memory.
+const sweepInterval = time.Minute
+
+// Config configures a Limiter. A Rate of zero disables rate limiting.
+type Config struct {
+	// Number of requests per second each client may make on average
+	Rate float64
+	// Number of requests each client may make at once, at least one
+	Burst int
+}
+
+// A Limiter gives each client, identified by a key, a bucket of `Burst`
+// tokens, which is refilled with `Rate` tokens per second. Each request takes
+// a token, and is rejected when the bucket of its client is empty. It is safe
+// for concurrent use.
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	rejected prometheus.Counter
+
+	// returns the current time, replaced in tests
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	// time at which `tokens` was last updated
+	updated time.Time
+}
+
+// New returns a Limiter for `config`, or nil if rate limiting is disabled.
+// Rejected requests are counted in `rejected`, if it is not nil.
+func New(config Config, rejected prometheus.Counter) *Limiter {
+	if config.Rate <= 0 {
+		return nil
+	}
+
+	burst := config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		rate:     config.Rate,
+		burst:    float64(burst),
+		rejected: rejected,
+		now:      time.Now,
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of the client `key`. It returns
+// whether the request of the client may proceed and, if it may not, how long
+// the client has to wait until it may make the next request.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = l.refilled(b, now)
+	b.updated = now
+
+	if b.tokens < 1 {
+		if l.rejected != nil {
+			l.rejected.Inc()
+		}
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// refilled returns the number of tokens in `b` at `now`.
+func (l *Limiter) refilled(b *bucket, now time.Time) float64 {
+	tokens := b.tokens + now.Sub(b.updated).Seconds()*l.rate
+	return math.Min(tokens, l.burst)
+}
+
+// sweep forgets the buckets which are full at `now`, because they are the
+// same as the bucket of a new client.
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if l.refilled(b, now) >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RetryAfter formats `wait` for a Retry-After header, which is in whole
+// seconds. It is rounded up, so that clients don't retry too early.
+func RetryAfter(wait time.Duration) string {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	rejected := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "rejected_total",
+		Help: "Rejected requests.",
+	})
+
+	l := New(Config{Rate: 2, Burst: 3}, rejected)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	// a new client can make `Burst` requests at once
+	for i := 0; i < 3; i++ {
+		allowed, _ := l.Allow("a")
+		require.True(t, allowed)
+	}
+	allowed, wait := l.Allow("a")
+	require.False(t, allowed)
+	require.Equal(t, 500*time.Millisecond, wait)
+
+	// other clients have their own bucket
+	allowed, _ = l.Allow("b")
+	require.True(t, allowed)
+
+	// the bucket is refilled with `Rate` tokens per second
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		allowed, _ = l.Allow("a")
+		require.True(t, allowed)
+	}
+	allowed, _ = l.Allow("a")
+	require.False(t, allowed)
+
+	// but never beyond `Burst`
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		allowed, _ = l.Allow("a")
+		require.True(t, allowed)
+	}
+	allowed, _ = l.Allow("a")
+	require.False(t, allowed)
+
+	require.Equal(t, 3.0, testutil.ToFloat64(rejected))
+}
+
+func TestLimiterSweep(t *testing.T) {
+	l := New(Config{Rate: 0.02, Burst: 2}, nil)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	l.Allow("idle")
+	l.Allow("busy")
+	l.Allow("busy")
+	require.Len(t, l.buckets, 2)
+
+	// after a minute, the bucket of "idle" is full again, but the one of
+	// "busy" is not
+	now = now.Add(sweepInterval + time.Second)
+	allowed, _ := l.Allow("other")
+	require.True(t, allowed)
+	require.Len(t, l.buckets, 2)
+	require.Contains(t, l.buckets, "busy")
+	require.Contains(t, l.buckets, "other")
+}
+
+func TestNewDisabled(t *testing.T) {
+	require.Nil(t, New(Config{}, nil))
+	require.Equal(t, 1.0, New(Config{Rate: 1}, nil).burst)
+}
+
+func TestRetryAfter(t *testing.T) {
+	require.Equal(t, "1", RetryAfter(0))
+	require.Equal(t, "1", RetryAfter(200*time.Millisecond))
+	require.Equal(t, "2", RetryAfter(1001*time.Millisecond))
+	require.Equal(t, "60", RetryAfter(time.Minute))
+}
@@ -0,0 +1,143 @@
+// Package rbac decides which operations of composer's APIs a client may
+// perform, based on the roles a Policy grants it.
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+type Role string
+
+const (
+	// Start composes and manage the blueprints they are built from, and
+	// read the composes of the own tenant
+	SubmitCompose Role = "submit-compose"
+
+	// Read the status, logs, and results of all composes
+	ReadAnyCompose Role = "read-any-compose"
+
+	// Cancel and delete composes, manage sources and upload providers,
+	// and look after workers and the job queue
+	Admin Role = "admin"
+
+	// Request and run jobs
+	Worker Role = "worker"
+)
+
+var allRoles = []Role{SubmitCompose, ReadAnyCompose, Admin, Worker}
+
+// Roles are the roles of a client.
+type Roles []Role
+
+// Has returns whether `r` contains any of `roles`.
+func (r Roles) Has(roles ...Role) bool {
+	for _, have := range r {
+		for _, role := range roles {
+			if have == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r Roles) add(roles ...Role) Roles {
+	for _, role := range roles {
+		if !r.Has(role) {
+			r = append(r, role)
+		}
+	}
+	return r
+}
+
+// ParseRoles returns the roles named `names`, or an error if any of them
+// doesn't exist.
+func ParseRoles(names []string) (Roles, error) {
+	var roles Roles
+	for _, name := range names {
+		role := Role(name)
+		if !Roles(allRoles).Has(role) {
+			return nil, fmt.Errorf("unknown role '%s'", name)
+		}
+		roles = roles.add(role)
+	}
+	return roles, nil
+}
+
+// Policy grants roles to local users of the weldr API and to clients of the
+// HTTP APIs.
+type Policy struct {
+	// Roles of local users, by user name and by group name. root has
+	// all roles except Worker.
+	Users  map[string]Roles
+	Groups map[string]Roles
+
+	// Roles of clients of the HTTP APIs, by OIDC client id or subject,
+	// account number of the identity header, or identity of the client
+	// certificate of a worker
+	Clients map[string]Roles
+
+	// Roles of everyone, including clients which aren't identified, such
+	// as the local worker
+	Default Roles
+}
+
+// PeerRoles returns the roles of the local user `uid` whose primary group is
+// `gid`.
+func (p *Policy) PeerRoles(uid, gid uint32) Roles {
+	roles := append(Roles(nil), p.Default...)
+	if uid == 0 {
+		return roles.add(SubmitCompose, ReadAnyCompose, Admin)
+	}
+
+	gids := []string{strconv.FormatUint(uint64(gid), 10)}
+	if u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10)); err == nil {
+		roles = roles.add(p.Users[u.Username]...)
+		if groupIDs, err := u.GroupIds(); err == nil {
+			gids = append(gids, groupIDs...)
+		}
+	}
+	for _, id := range gids {
+		if g, err := user.LookupGroupId(id); err == nil {
+			roles = roles.add(p.Groups[g.Name]...)
+		}
+	}
+
+	return roles
+}
+
+// ClientRoles returns the roles of the HTTP API client which is known by
+// any of `names`, in addition to the ones it claims in its token.
+func (p *Policy) ClientRoles(claimed []string, names ...string) Roles {
+	roles := append(Roles(nil), p.Default...)
+	for _, name := range names {
+		if name != "" {
+			roles = roles.add(p.Clients[name]...)
+		}
+	}
+	for _, name := range claimed {
+		role := Role(name)
+		if Roles(allRoles).Has(role) {
+			roles = roles.add(role)
+		}
+	}
+	return roles
+}
+
+type contextKey int
+
+const rolesKey contextKey = iota
+
+// NewContext returns a context carrying `roles`.
+func NewContext(ctx context.Context, roles Roles) context.Context {
+	return context.WithValue(ctx, rolesKey, roles)
+}
+
+// FromContext returns the roles stored in `ctx`.
+func FromContext(ctx context.Context) (Roles, bool) {
+	roles, ok := ctx.Value(rolesKey).(Roles)
+	return roles, ok
+}
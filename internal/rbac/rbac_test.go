@@ -0,0 +1,50 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoles(t *testing.T) {
+	roles, err := ParseRoles([]string{"admin", "worker", "admin"})
+	require.NoError(t, err)
+	require.Equal(t, Roles{Admin, Worker}, roles)
+
+	_, err = ParseRoles([]string{"root"})
+	require.EqualError(t, err, "unknown role 'root'")
+}
+
+func TestClientRoles(t *testing.T) {
+	policy := Policy{
+		Clients: map[string]Roles{
+			"image-builder": {SubmitCompose},
+			"worker-1":      {Worker},
+		},
+		Default: Roles{ReadAnyCompose},
+	}
+
+	roles := policy.ClientRoles([]string{"admin", "offline_access"}, "image-builder", "")
+	require.Equal(t, Roles{ReadAnyCompose, SubmitCompose, Admin}, roles)
+	require.True(t, roles.Has(Worker, Admin))
+	require.False(t, roles.Has(Worker))
+
+	require.Equal(t, Roles{ReadAnyCompose}, policy.ClientRoles(nil))
+	// the policy isn't modified
+	require.Equal(t, Roles{ReadAnyCompose}, policy.Default)
+}
+
+func TestPeerRoles(t *testing.T) {
+	policy := Policy{Default: Roles{Worker}}
+	require.Equal(t, Roles{Worker, SubmitCompose, ReadAnyCompose, Admin}, policy.PeerRoles(0, 0))
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+
+	roles, ok := FromContext(NewContext(context.Background(), Roles{Admin}))
+	require.True(t, ok)
+	require.Equal(t, Roles{Admin}, roles)
+}
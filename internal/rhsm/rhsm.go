@@ -0,0 +1,142 @@
+// Package rhsm provides the entitlement certificates with which hosts that
+// are registered with Red Hat Subscription Management access the repositories
+// on cdn.redhat.com.
+//
+// subscription-manager (or rhsmcertd) replaces the certificates whenever it
+// refreshes the entitlements of the host. The certificates are therefore
+// looked up again whenever the entitlement directory changed, instead of
+// being read once at startup.
+package rhsm
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultEntitlementDir = "/etc/pki/entitlement"
+	DefaultCACert         = "/etc/rhsm/ca/redhat-uep.pem"
+)
+
+// Secrets are the paths of the files a client needs to access the CDN.
+type Secrets struct {
+	SSLCACert     string `json:"sslcacert,omitempty"`
+	SSLClientKey  string `json:"sslclientkey,omitempty"`
+	SSLClientCert string `json:"sslclientcert,omitempty"`
+}
+
+// Entitlements finds the entitlement certificates in a directory, in which
+// each certificate `<serial>.pem` comes with its key `<serial>-key.pem`.
+type Entitlements struct {
+	dir    string
+	caCert string
+
+	mu      sync.Mutex
+	secrets *Secrets
+	// modification time of `dir` when `secrets` were looked up
+	modTime time.Time
+}
+
+// NewEntitlements returns the entitlements in `dir`, which are verified with
+// the CA certificate `caCert`. Empty values select the default paths of
+// subscription-manager.
+func NewEntitlements(dir, caCert string) *Entitlements {
+	if dir == "" {
+		dir = DefaultEntitlementDir
+	}
+	if caCert == "" {
+		caCert = DefaultCACert
+	}
+	return &Entitlements{dir: dir, caCert: caCert}
+}
+
+// Secrets returns the entitlement certificate which is valid the longest,
+// skipping expired ones. It is looked up again when the entitlement
+// directory or the previously returned files changed.
+func (e *Entitlements) Secrets() (*Secrets, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	info, err := os.Stat(e.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.secrets != nil && info.ModTime().Equal(e.modTime) && exists(e.secrets.SSLClientCert) && exists(e.secrets.SSLClientKey) {
+		return e.secrets, nil
+	}
+
+	secrets, err := e.find(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	e.secrets = secrets
+	e.modTime = info.ModTime()
+	return secrets, nil
+}
+
+func (e *Entitlements) find(now time.Time) (*Secrets, error) {
+	keys, err := filepath.Glob(filepath.Join(e.dir, "*-key.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets *Secrets
+	var notAfter time.Time
+	for _, key := range keys {
+		cert := strings.TrimSuffix(key, "-key.pem") + ".pem"
+		expires, err := certificateExpiry(cert)
+		if err != nil || expires.Before(now) {
+			continue
+		}
+		if secrets == nil || expires.After(notAfter) {
+			secrets = &Secrets{
+				SSLCACert:     e.caCert,
+				SSLClientKey:  key,
+				SSLClientCert: cert,
+			}
+			notAfter = expires
+		}
+	}
+
+	if secrets == nil {
+		return nil, fmt.Errorf("no valid entitlement certificate in %s", e.dir)
+	}
+	return secrets, nil
+}
+
+// certificateExpiry returns the end of the validity of the first
+// certificate in the PEM file at `path`.
+func certificateExpiry(path string) (time.Time, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return time.Time{}, fmt.Errorf("%s contains no certificate", path)
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return cert.NotAfter, nil
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
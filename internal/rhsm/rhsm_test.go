@@ -0,0 +1,74 @@
+package rhsm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeEntitlement writes a self-signed entitlement certificate `serial`
+// which expires at `notAfter` and its key to `dir`
+func writeEntitlement(t *testing.T, dir, serial string, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: serial},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	// entitlement certificates carry additional blocks after the
+	// certificate
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	cert = append(cert, pem.EncodeToMemory(&pem.Block{Type: "ENTITLEMENT DATA", Bytes: []byte("data")})...)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, serial+".pem"), cert, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, serial+"-key.pem"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+}
+
+func TestEntitlements(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rhsm-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	entitlements := NewEntitlements(dir, "")
+
+	_, err = entitlements.Secrets()
+	require.Error(t, err)
+
+	writeEntitlement(t, dir, "expired", time.Now().Add(-time.Hour))
+	writeEntitlement(t, dir, "1", time.Now().Add(time.Hour))
+	writeEntitlement(t, dir, "2", time.Now().Add(2*time.Hour))
+
+	secrets, err := entitlements.Secrets()
+	require.NoError(t, err)
+	require.Equal(t, &Secrets{
+		SSLCACert:     DefaultCACert,
+		SSLClientKey:  filepath.Join(dir, "2-key.pem"),
+		SSLClientCert: filepath.Join(dir, "2.pem"),
+	}, secrets)
+
+	// subscription-manager replaced the certificates
+	require.NoError(t, os.Remove(filepath.Join(dir, "2.pem")))
+	require.NoError(t, os.Remove(filepath.Join(dir, "2-key.pem")))
+
+	secrets, err = entitlements.Secrets()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "1.pem"), secrets.SSLClientCert)
+}
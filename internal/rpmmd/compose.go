@@ -0,0 +1,100 @@
+package rpmmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Prefix of the path segment of a compose URL which is a symlink to the most
+// recent compose, e.g. latest-Fedora-Rawhide
+const latestComposePrefix = "latest-"
+
+var composeClient = &http.Client{Timeout: 30 * time.Second}
+
+// ResolveLatestComposes returns a copy of `repos` in which the base URLs of
+// repositories with LatestCompose set point to the compose their latest-*
+// symlink currently points to, instead of the symlink. The symlink of nightly
+// composes moves when a new compose finishes, so resolving it once ensures
+// that all packages of an image come from the same compose and can still be
+// downloaded when the image is built.
+func ResolveLatestComposes(repos []RepoConfig) ([]RepoConfig, error) {
+	resolved := make([]RepoConfig, len(repos))
+	composeIDs := make(map[string]string)
+	for i, repo := range repos {
+		resolved[i] = repo
+		if !repo.LatestCompose {
+			continue
+		}
+
+		baseURL, err := resolveLatestCompose(repo.BaseURL, composeIDs)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve the latest compose of repository %s: %v", repo.Name, err)
+		}
+		resolved[i].BaseURL = baseURL
+		resolved[i].LatestCompose = false
+	}
+	return resolved, nil
+}
+
+// resolveLatestCompose replaces the latest-* segment of `baseURL` by the ID
+// of the compose, which is read from the COMPOSE_ID file at the top of the
+// compose. The IDs of composes which were resolved before are looked up in
+// `composeIDs`, so that repositories of the same compose are resolved to the
+// same one.
+func resolveLatestCompose(baseURL string, composeIDs map[string]string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(u.Path, "/")
+	i := 0
+	for ; i < len(segments); i++ {
+		if strings.HasPrefix(segments[i], latestComposePrefix) {
+			break
+		}
+	}
+	if i == len(segments) {
+		return "", fmt.Errorf("base URL %s doesn't contain a %s* path segment", baseURL, latestComposePrefix)
+	}
+
+	composeURL := *u
+	composeURL.Path = strings.Join(segments[:i+1], "/")
+	id, ok := composeIDs[composeURL.String()]
+	if !ok {
+		id, err = fetchComposeID(composeURL.String() + "/COMPOSE_ID")
+		if err != nil {
+			return "", err
+		}
+		composeIDs[composeURL.String()] = id
+	}
+
+	segments[i] = id
+	u.Path = strings.Join(segments, "/")
+	return u.String(), nil
+}
+
+func fetchComposeID(idURL string) (string, error) {
+	resp, err := composeClient.Get(idURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching %s failed: %s", idURL, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	id := strings.TrimSpace(string(body))
+	if id == "" || strings.ContainsAny(id, "/ \n") {
+		return "", fmt.Errorf("%s contains an invalid compose ID: %q", idURL, id)
+	}
+	return id, nil
+}
@@ -0,0 +1,50 @@
+package rpmmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveLatestComposes(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/compose/rawhide/latest-Fedora-Rawhide/COMPOSE_ID":
+			requests++
+			_, _ = w.Write([]byte("Fedora-Rawhide-20211015.n.0\n"))
+		case "/compose/eln/latest-Fedora-ELN/COMPOSE_ID":
+			_, _ = w.Write([]byte("not a/compose id"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	repos := []RepoConfig{
+		{Name: "everything", BaseURL: server.URL + "/compose/rawhide/latest-Fedora-Rawhide/compose/Everything/x86_64/os/", LatestCompose: true},
+		{Name: "modular", BaseURL: server.URL + "/compose/rawhide/latest-Fedora-Rawhide/compose/Modular/x86_64/os/", LatestCompose: true},
+		{Name: "fixed", BaseURL: server.URL + "/compose/rawhide/latest-Fedora-Rawhide/compose/Everything/x86_64/os/"},
+	}
+	resolved, err := ResolveLatestComposes(repos)
+	require.NoError(t, err)
+	require.Equal(t, server.URL+"/compose/rawhide/Fedora-Rawhide-20211015.n.0/compose/Everything/x86_64/os/", resolved[0].BaseURL)
+	require.Equal(t, server.URL+"/compose/rawhide/Fedora-Rawhide-20211015.n.0/compose/Modular/x86_64/os/", resolved[1].BaseURL)
+	require.False(t, resolved[0].LatestCompose)
+	require.Equal(t, repos[2], resolved[2])
+	require.Equal(t, 1, requests)
+
+	// the passed repositories are left alone
+	require.True(t, repos[0].LatestCompose)
+
+	_, err = ResolveLatestComposes([]RepoConfig{{Name: "eln", BaseURL: server.URL + "/compose/eln/latest-Fedora-ELN/compose/BaseOS/x86_64/os/", LatestCompose: true}})
+	require.EqualError(t, err, `cannot resolve the latest compose of repository eln: `+server.URL+`/compose/eln/latest-Fedora-ELN/COMPOSE_ID contains an invalid compose ID: "not a/compose id"`)
+
+	_, err = ResolveLatestComposes([]RepoConfig{{Name: "missing", BaseURL: server.URL + "/compose/f35/latest-Fedora-35/compose/Everything/x86_64/os/", LatestCompose: true}})
+	require.EqualError(t, err, `cannot resolve the latest compose of repository missing: fetching `+server.URL+`/compose/f35/latest-Fedora-35/COMPOSE_ID failed: 404 Not Found`)
+
+	_, err = ResolveLatestComposes([]RepoConfig{{Name: "nolatest", BaseURL: server.URL + "/compose/Everything/x86_64/os/", LatestCompose: true}})
+	require.EqualError(t, err, `cannot resolve the latest compose of repository nolatest: base URL `+server.URL+`/compose/Everything/x86_64/os/ doesn't contain a latest-* path segment`)
+}
@@ -13,6 +13,9 @@ import (
 	"time"
 
 	"github.com/gobwas/glob"
+
+	"github.com/osbuild/osbuild-composer/internal/dnfjson"
+	"github.com/osbuild/osbuild-composer/internal/rhsm"
 )
 
 type repository struct {
@@ -22,9 +25,12 @@ type repository struct {
 	MirrorList     string   `json:"mirrorlist,omitempty"`
 	GPGKey         string   `json:"gpgkey,omitempty"`
 	CheckGPG       bool     `json:"check_gpg,omitempty"`
+	RepoGPGCheck   bool     `json:"repo_gpgcheck,omitempty"`
+	ModuleHotfixes bool     `json:"module_hotfixes,omitempty"`
 	RHSM           bool     `json:"rhsm,omitempty"`
 	MetadataExpire string   `json:"metadata_expire,omitempty"`
 	ImageTypeTags  []string `json:"image_type_tags,omitempty"`
+	LatestCompose  bool     `json:"latest_compose,omitempty"`
 }
 
 type dnfRepoConfig struct {
@@ -33,11 +39,14 @@ type dnfRepoConfig struct {
 	Metalink       string `json:"metalink,omitempty"`
 	MirrorList     string `json:"mirrorlist,omitempty"`
 	GPGKey         string `json:"gpgkey,omitempty"`
+	RepoGPGCheck   bool   `json:"repo_gpgcheck,omitempty"`
 	IgnoreSSL      bool   `json:"ignoressl"`
 	SSLCACert      string `json:"sslcacert,omitempty"`
 	SSLClientKey   string `json:"sslclientkey,omitempty"`
 	SSLClientCert  string `json:"sslclientcert,omitempty"`
 	MetadataExpire string `json:"metadata_expire,omitempty"`
+	ModuleHotfixes bool   `json:"module_hotfixes,omitempty"`
+	Priority       int    `json:"priority,omitempty"`
 }
 
 type RepoConfig struct {
@@ -49,12 +58,64 @@ type RepoConfig struct {
 	CheckGPG       bool
 	IgnoreSSL      bool
 	MetadataExpire string
+	ModuleHotfixes bool
 	RHSM           bool
 	ImageTypeTags  []string
+	// Verify the signature of the metadata of the repository with GPGKey
+	// when depsolving
+	RepoGPGCheck bool
+	// dnf priority of the repository, where lower values take precedence.
+	// 0 means the default priority of dnf.
+	Priority int
+	// The base URL contains a latest-* symlink to the most recent compose,
+	// which is resolved when depsolving, see ResolveLatestComposes()
+	LatestCompose bool
 }
 
 type DistrosRepoConfigs map[string]map[string][]RepoConfig
 
+// ExpandReleasever returns a copy of `repos` in which the $releasever
+// variable in the repository URLs is replaced by `releasever`. dnf would
+// replace it by the release of the host otherwise, which is not necessarily
+// the release an image is built for.
+func ExpandReleasever(repos []RepoConfig, releasever string) []RepoConfig {
+	replacer := strings.NewReplacer("${releasever}", releasever, "$releasever", releasever)
+	expanded := make([]RepoConfig, len(repos))
+	for i, repo := range repos {
+		repo.BaseURL = replacer.Replace(repo.BaseURL)
+		repo.Metalink = replacer.Replace(repo.Metalink)
+		repo.MirrorList = replacer.Replace(repo.MirrorList)
+		expanded[i] = repo
+	}
+	return expanded
+}
+
+// EnforceGPGCheck returns a copy of `repos` in which the signatures of both
+// the packages and the metadata of each repository are verified. It returns
+// an error if any repository has no GPG key to verify them with.
+func EnforceGPGCheck(repos []RepoConfig) ([]RepoConfig, error) {
+	enforced := make([]RepoConfig, len(repos))
+	for i, repo := range repos {
+		if repo.GPGKey == "" {
+			name := repo.Name
+			switch {
+			case name != "":
+			case repo.BaseURL != "":
+				name = repo.BaseURL
+			case repo.Metalink != "":
+				name = repo.Metalink
+			default:
+				name = repo.MirrorList
+			}
+			return nil, fmt.Errorf("repository %s has no GPG key", name)
+		}
+		repo.CheckGPG = true
+		repo.RepoGPGCheck = true
+		enforced[i] = repo
+	}
+	return enforced, nil
+}
+
 type PackageList []Package
 
 type Package struct {
@@ -105,13 +166,21 @@ func (pkg Package) ToPackageInfo() PackageInfo {
 type PackageSet struct {
 	Include []string
 	Exclude []string
+	// Repositories which are only used to depsolve this package set, in
+	// addition to the ones passed to DepsolvePackageSets()
+	Repositories []RepoConfig `json:",omitempty"`
+	// Module streams which are enabled before the packages are depsolved,
+	// given as name:stream
+	EnableModules []string `json:",omitempty"`
 }
 
-// Append the Include and Exclude package list from another PackageSet and
-// return the result.
+// Append the Include and Exclude package list, the repositories, and the
+// enabled modules from another PackageSet and return the result.
 func (ps PackageSet) Append(other PackageSet) PackageSet {
 	ps.Include = append(ps.Include, other.Include...)
 	ps.Exclude = append(ps.Exclude, other.Exclude...)
+	ps.Repositories = append(ps.Repositories, other.Repositories...)
+	ps.EnableModules = append(ps.EnableModules, other.EnableModules...)
 	return ps
 }
 
@@ -126,6 +195,9 @@ type PackageSpec struct {
 	Checksum       string `json:"checksum,omitempty"`
 	Secrets        string `json:"secrets,omitempty"`
 	CheckGPG       bool   `json:"check_gpg,omitempty"`
+	// index of the repository the package was resolved from in the
+	// list of repositories passed to Depsolve()
+	RepoID string `json:"repo_id,omitempty"`
 }
 
 type dnfPackageSpec struct {
@@ -198,30 +270,6 @@ func (re *RepositoryError) Error() string {
 	return re.msg
 }
 
-type RHSMSecrets struct {
-	SSLCACert     string `json:"sslcacert,omitempty"`
-	SSLClientKey  string `json:"sslclientkey,omitempty"`
-	SSLClientCert string `json:"sslclientcert,omitempty"`
-}
-
-func getRHSMSecrets() *RHSMSecrets {
-	keys, err := filepath.Glob("/etc/pki/entitlement/*-key.pem")
-	if err != nil {
-		return nil
-	}
-	for _, key := range keys {
-		cert := strings.TrimSuffix(key, "-key.pem") + ".pem"
-		if _, err := os.Stat(cert); err == nil {
-			return &RHSMSecrets{
-				SSLCACert:     "/etc/rhsm/ca/redhat-uep.pem",
-				SSLClientKey:  key,
-				SSLClientCert: cert,
-			}
-		}
-	}
-	return nil
-}
-
 func loadRepositoriesFromFile(filename string) (map[string][]RepoConfig, error) {
 	f, err := os.Open(filename)
 	if err != nil {
@@ -246,9 +294,12 @@ func loadRepositoriesFromFile(filename string) (map[string][]RepoConfig, error)
 				MirrorList:     repo.MirrorList,
 				GPGKey:         repo.GPGKey,
 				CheckGPG:       repo.CheckGPG,
+				RepoGPGCheck:   repo.RepoGPGCheck,
+				ModuleHotfixes: repo.ModuleHotfixes,
 				RHSM:           repo.RHSM,
 				MetadataExpire: repo.MetadataExpire,
 				ImageTypeTags:  repo.ImageTypeTags,
+				LatestCompose:  repo.LatestCompose,
 			}
 
 			repoConfigs[arch] = append(repoConfigs[arch], config)
@@ -393,19 +444,49 @@ func runDNF(dnfJsonPath string, command string, arguments interface{}, result in
 }
 
 type rpmmdImpl struct {
-	CacheDir    string
-	RHSM        *RHSMSecrets
-	dnfJsonPath string
+	Cache        *dnfjson.Cache
+	Entitlements *rhsm.Entitlements
+	dnfJsonPath  string
 }
 
 func NewRPMMD(cacheDir, dnfJsonPath string) RPMMD {
+	return NewRPMMDWithEntitlements(cacheDir, dnfJsonPath, rhsm.NewEntitlements("", ""))
+}
+
+// NewRPMMDWithEntitlements returns an RPMMD which accesses repositories
+// with `RHSM` set with the certificates of `entitlements`.
+func NewRPMMDWithEntitlements(cacheDir, dnfJsonPath string, entitlements *rhsm.Entitlements) RPMMD {
 	return &rpmmdImpl{
-		CacheDir:    cacheDir,
-		RHSM:        getRHSMSecrets(),
-		dnfJsonPath: dnfJsonPath,
+		Cache:        dnfjson.NewCache(cacheDir, dnfjson.DefaultMaxSize, dnfjson.DefaultTTL),
+		Entitlements: entitlements,
+		dnfJsonPath:  dnfJsonPath,
 	}
 }
 
+// Locks the metadata of `repos` in the cache for a dnf-json call. Returns a
+// function which releases them again.
+func (r *rpmmdImpl) lockCache(repos []dnfRepoConfig) (func(), error) {
+	cacheRepos := make([]dnfjson.CacheRepo, len(repos))
+	for i, repo := range repos {
+		cacheRepos[i].ID = repo.ID
+		switch {
+		case repo.Metalink != "":
+			cacheRepos[i].URL = repo.Metalink
+		case repo.MirrorList != "":
+			cacheRepos[i].URL = repo.MirrorList
+		default:
+			cacheRepos[i].URL = repo.BaseURL
+		}
+		cacheRepos[i].MetadataExpire = repo.MetadataExpire
+	}
+
+	unlock, err := r.Cache.Lock(cacheRepos)
+	if err != nil {
+		return nil, fmt.Errorf("error locking the metadata cache: %v", err)
+	}
+	return unlock, nil
+}
+
 func (repo RepoConfig) toDNFRepoConfig(rpmmd *rpmmdImpl, i int) (dnfRepoConfig, error) {
 	id := strconv.Itoa(i)
 	dnfRepo := dnfRepoConfig{
@@ -414,21 +495,30 @@ func (repo RepoConfig) toDNFRepoConfig(rpmmd *rpmmdImpl, i int) (dnfRepoConfig,
 		Metalink:       repo.Metalink,
 		MirrorList:     repo.MirrorList,
 		GPGKey:         repo.GPGKey,
+		RepoGPGCheck:   repo.RepoGPGCheck,
 		IgnoreSSL:      repo.IgnoreSSL,
 		MetadataExpire: repo.MetadataExpire,
+		ModuleHotfixes: repo.ModuleHotfixes,
+		Priority:       repo.Priority,
 	}
 	if repo.RHSM {
-		if rpmmd.RHSM == nil {
-			return dnfRepoConfig{}, fmt.Errorf("RHSM secrets not found on host")
+		secrets, err := rpmmd.Entitlements.Secrets()
+		if err != nil {
+			return dnfRepoConfig{}, fmt.Errorf("RHSM secrets not found on host: %v", err)
 		}
-		dnfRepo.SSLCACert = rpmmd.RHSM.SSLCACert
-		dnfRepo.SSLClientKey = rpmmd.RHSM.SSLClientKey
-		dnfRepo.SSLClientCert = rpmmd.RHSM.SSLClientCert
+		dnfRepo.SSLCACert = secrets.SSLCACert
+		dnfRepo.SSLClientKey = secrets.SSLClientKey
+		dnfRepo.SSLClientCert = secrets.SSLClientCert
 	}
 	return dnfRepo, nil
 }
 
 func (r *rpmmdImpl) FetchMetadata(repos []RepoConfig, modulePlatformID string, arch string) (PackageList, map[string]string, error) {
+	repos, err := ResolveLatestComposes(repos)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var dnfRepoConfigs []dnfRepoConfig
 	for i, repo := range repos {
 		dnfRepo, err := repo.toDNFRepoConfig(r, i)
@@ -443,13 +533,18 @@ func (r *rpmmdImpl) FetchMetadata(repos []RepoConfig, modulePlatformID string, a
 		CacheDir         string          `json:"cachedir"`
 		ModulePlatformID string          `json:"module_platform_id"`
 		Arch             string          `json:"arch"`
-	}{dnfRepoConfigs, r.CacheDir, modulePlatformID, arch}
+	}{dnfRepoConfigs, r.Cache.Root(), modulePlatformID, arch}
 	var reply struct {
 		Checksums map[string]string `json:"checksums"`
 		Packages  PackageList       `json:"packages"`
 	}
 
-	err := runDNF(r.dnfJsonPath, "dump", arguments, &reply)
+	unlock, err := r.lockCache(dnfRepoConfigs)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = runDNF(r.dnfJsonPath, "dump", arguments, &reply)
+	unlock()
 
 	sort.Slice(reply.Packages, func(i, j int) bool {
 		return reply.Packages[i].Name < reply.Packages[j].Name
@@ -462,6 +557,11 @@ func (r *rpmmdImpl) FetchMetadata(repos []RepoConfig, modulePlatformID string, a
 }
 
 func (r *rpmmdImpl) Depsolve(packageSet PackageSet, repos []RepoConfig, modulePlatformID, arch string) ([]PackageSpec, map[string]string, error) {
+	repos, err := ResolveLatestComposes(repos)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var dnfRepoConfigs []dnfRepoConfig
 
 	for i, repo := range repos {
@@ -473,18 +573,24 @@ func (r *rpmmdImpl) Depsolve(packageSet PackageSet, repos []RepoConfig, modulePl
 	}
 
 	var arguments = struct {
-		PackageSpecs     []string        `json:"package-specs"`
-		ExcludSpecs      []string        `json:"exclude-specs"`
-		Repos            []dnfRepoConfig `json:"repos"`
-		CacheDir         string          `json:"cachedir"`
-		ModulePlatformID string          `json:"module_platform_id"`
-		Arch             string          `json:"arch"`
-	}{packageSet.Include, packageSet.Exclude, dnfRepoConfigs, r.CacheDir, modulePlatformID, arch}
+		PackageSpecs      []string        `json:"package-specs"`
+		ExcludSpecs       []string        `json:"exclude-specs"`
+		ModuleEnableSpecs []string        `json:"module-enable-specs,omitempty"`
+		Repos             []dnfRepoConfig `json:"repos"`
+		CacheDir          string          `json:"cachedir"`
+		ModulePlatformID  string          `json:"module_platform_id"`
+		Arch              string          `json:"arch"`
+	}{packageSet.Include, packageSet.Exclude, packageSet.EnableModules, dnfRepoConfigs, r.Cache.Root(), modulePlatformID, arch}
 	var reply struct {
 		Checksums    map[string]string `json:"checksums"`
 		Dependencies []dnfPackageSpec  `json:"dependencies"`
 	}
-	err := runDNF(r.dnfJsonPath, "depsolve", arguments, &reply)
+	unlock, err := r.lockCache(dnfRepoConfigs)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = runDNF(r.dnfJsonPath, "depsolve", arguments, &reply)
+	unlock()
 
 	dependencies := make([]PackageSpec, len(reply.Dependencies))
 	for i, pack := range reply.Dependencies {
@@ -502,6 +608,7 @@ func (r *rpmmdImpl) Depsolve(packageSet PackageSet, repos []RepoConfig, modulePl
 		dependencies[i].RemoteLocation = dep.RemoteLocation
 		dependencies[i].Checksum = dep.Checksum
 		dependencies[i].CheckGPG = repo.CheckGPG
+		dependencies[i].RepoID = pack.RepoID
 		if repo.RHSM {
 			dependencies[i].Secrets = "org.osbuild.rhsm"
 		}
@@ -562,6 +669,72 @@ func (packages PackageList) ToPackageInfos() []PackageInfo {
 	return results
 }
 
+// MaxParallelDepsolves is the number of dnf-json processes
+// DepsolvePackageSets() runs at the same time.
+const MaxParallelDepsolves = 4
+
+// DepsolvePackageSets depsolves all `packageSets` of an image concurrently
+// and returns the package specs of each set under the same name. When
+// depsolving any of the sets fails, the error of the first failing set (in
+// order of their names) is returned. Latest composes are resolved before any
+// set is depsolved, so that all sets come from the same compose. Each set is
+// depsolved with `repos` and its own additional repositories.
+func DepsolvePackageSets(rpmmd RPMMD, packageSets map[string]PackageSet, repos []RepoConfig, modulePlatformID, arch string) (map[string][]PackageSpec, error) {
+	repos, err := ResolveLatestComposes(repos)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		name         string
+		packageSpecs []PackageSpec
+		err          error
+	}
+
+	names := make(chan string, len(packageSets))
+	for name := range packageSets {
+		names <- name
+	}
+	close(names)
+	results := make(chan result)
+
+	workers := MaxParallelDepsolves
+	if len(packageSets) < workers {
+		workers = len(packageSets)
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for name := range names {
+				setRepos := append(append([]RepoConfig{}, repos...), packageSets[name].Repositories...)
+				packageSpecs, _, err := rpmmd.Depsolve(packageSets[name], setRepos, modulePlatformID, arch)
+				results <- result{name, packageSpecs, err}
+			}
+		}()
+	}
+
+	packageSpecSets := make(map[string][]PackageSpec)
+	errs := make(map[string]error)
+	for range packageSets {
+		r := <-results
+		if r.err != nil {
+			errs[r.name] = r.err
+			continue
+		}
+		packageSpecSets[r.name] = r.packageSpecs
+	}
+
+	if len(errs) > 0 {
+		failed := make([]string, 0, len(errs))
+		for name := range errs {
+			failed = append(failed, name)
+		}
+		sort.Strings(failed)
+		return nil, errs[failed[0]]
+	}
+
+	return packageSpecSets, nil
+}
+
 func (pkg *PackageInfo) FillDependencies(rpmmd RPMMD, repos []RepoConfig, modulePlatformID string, arch string) (err error) {
 	pkg.Dependencies, _, err = rpmmd.Depsolve(PackageSet{Include: []string{pkg.Name}}, repos, modulePlatformID, arch)
 	return
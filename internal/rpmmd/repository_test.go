@@ -0,0 +1,147 @@
+package rpmmd
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slowRPMMD resolves each package set to a single package named after its
+// first included package from the last repository, and records how many
+// depsolves run concurrently.
+type slowRPMMD struct {
+	mu       sync.Mutex
+	running  int
+	parallel int
+}
+
+func (r *slowRPMMD) FetchMetadata(repos []RepoConfig, modulePlatformID string, arch string) (PackageList, map[string]string, error) {
+	return nil, nil, nil
+}
+
+func (r *slowRPMMD) Depsolve(packageSet PackageSet, repos []RepoConfig, modulePlatformID, arch string) ([]PackageSpec, map[string]string, error) {
+	r.mu.Lock()
+	r.running++
+	if r.running > r.parallel {
+		r.parallel = r.running
+	}
+	r.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	r.mu.Lock()
+	r.running--
+	r.mu.Unlock()
+
+	if len(packageSet.Exclude) > 0 {
+		return nil, nil, errors.New(packageSet.Exclude[0])
+	}
+	spec := PackageSpec{Name: packageSet.Include[0], Arch: arch}
+	if len(repos) > 0 {
+		spec.RepoID = strconv.Itoa(len(repos) - 1)
+	}
+	return []PackageSpec{spec}, nil, nil
+}
+
+func TestDepsolvePackageSets(t *testing.T) {
+	rpm := &slowRPMMD{}
+	packageSets := map[string]PackageSet{
+		"build":     {Include: []string{"rpm"}},
+		"packages":  {Include: []string{"kernel"}},
+		"installer": {Include: []string{"anaconda"}},
+		"ostree":    {Include: []string{"rpm-ostree"}},
+		"extra":     {Include: []string{"vim"}},
+		"more":      {Include: []string{"emacs"}},
+	}
+
+	packageSpecSets, err := DepsolvePackageSets(rpm, packageSets, nil, "platform:el8", "x86_64")
+	require.NoError(t, err)
+	require.Len(t, packageSpecSets, len(packageSets))
+	for name, set := range packageSets {
+		require.Equal(t, []PackageSpec{{Name: set.Include[0], Arch: "x86_64"}}, packageSpecSets[name])
+	}
+	require.Greater(t, rpm.parallel, 1)
+	require.LessOrEqual(t, rpm.parallel, MaxParallelDepsolves)
+
+	packageSets["build"] = PackageSet{Include: []string{"rpm"}, Exclude: []string{"build failed"}}
+	packageSets["packages"] = PackageSet{Include: []string{"kernel"}, Exclude: []string{"packages failed"}}
+	_, err = DepsolvePackageSets(rpm, packageSets, nil, "platform:el8", "x86_64")
+	require.EqualError(t, err, "build failed")
+
+	packageSpecSets, err = DepsolvePackageSets(rpm, map[string]PackageSet{}, nil, "platform:el8", "x86_64")
+	require.NoError(t, err)
+	require.Empty(t, packageSpecSets)
+}
+
+func TestDepsolvePackageSetsRepositories(t *testing.T) {
+	repos := make([]RepoConfig, 2, 4)
+	repos[0] = RepoConfig{Name: "baseos", BaseURL: "https://example.com/baseos"}
+	repos[1] = RepoConfig{Name: "appstream", BaseURL: "https://example.com/appstream"}
+	packageSets := map[string]PackageSet{
+		"build": {Include: []string{"rpm"}},
+		"packages": {
+			Include:      []string{"kernel"},
+			Repositories: []RepoConfig{{Name: "payload", BaseURL: "https://example.com/payload"}},
+		},
+	}
+
+	packageSpecSets, err := DepsolvePackageSets(&slowRPMMD{}, packageSets, repos, "platform:el8", "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, "1", packageSpecSets["build"][0].RepoID)
+	require.Equal(t, "2", packageSpecSets["packages"][0].RepoID)
+	require.Len(t, repos, 2)
+}
+
+func TestPackageSetAppend(t *testing.T) {
+	ps := PackageSet{Include: []string{"kernel"}, EnableModules: []string{"nodejs:18"}}
+	ps = ps.Append(PackageSet{
+		Include:       []string{"@postgresql:15/server"},
+		Exclude:       []string{"rng-tools"},
+		EnableModules: []string{"postgresql:15"},
+	})
+	require.Equal(t, PackageSet{
+		Include:       []string{"kernel", "@postgresql:15/server"},
+		Exclude:       []string{"rng-tools"},
+		EnableModules: []string{"nodejs:18", "postgresql:15"},
+	}, ps)
+}
+
+func TestExpandReleasever(t *testing.T) {
+	repos := []RepoConfig{
+		{Name: "baseos", BaseURL: "https://cdn.redhat.com/content/dist/rhel8/$releasever/x86_64/baseos/os", RHSM: true},
+		{Name: "fedora", Metalink: "https://mirrors.fedoraproject.org/metalink?repo=fedora-${releasever}&arch=x86_64"},
+		{Name: "fixed", MirrorList: "http://mirrorlist.centos.org/?release=8-stream&arch=x86_64&repo=BaseOS"},
+	}
+
+	expanded := ExpandReleasever(repos, "8.6")
+	require.Equal(t, "https://cdn.redhat.com/content/dist/rhel8/8.6/x86_64/baseos/os", expanded[0].BaseURL)
+	require.True(t, expanded[0].RHSM)
+	require.Equal(t, "https://mirrors.fedoraproject.org/metalink?repo=fedora-8.6&arch=x86_64", expanded[1].Metalink)
+	require.Equal(t, repos[2], expanded[2])
+
+	// the passed repositories are left alone
+	require.Equal(t, "https://cdn.redhat.com/content/dist/rhel8/$releasever/x86_64/baseos/os", repos[0].BaseURL)
+}
+
+func TestEnforceGPGCheck(t *testing.T) {
+	repos := []RepoConfig{
+		{Name: "baseos", BaseURL: "https://example.com/baseos", GPGKey: "key"},
+		{BaseURL: "https://example.com/appstream", GPGKey: "key", CheckGPG: true},
+	}
+
+	enforced, err := EnforceGPGCheck(repos)
+	require.NoError(t, err)
+	for _, repo := range enforced {
+		require.True(t, repo.CheckGPG)
+		require.True(t, repo.RepoGPGCheck)
+	}
+	require.False(t, repos[0].CheckGPG)
+
+	repos = append(repos, RepoConfig{Metalink: "https://example.com/metalink"})
+	_, err = EnforceGPGCheck(repos)
+	require.EqualError(t, err, "repository https://example.com/metalink has no GPG key")
+}
@@ -0,0 +1,91 @@
+package sbom
+
+import (
+	"time"
+)
+
+// CycloneDXDocument is an SBOM in the CycloneDX 1.4 JSON format.
+type CycloneDXDocument struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     CycloneDXMetadata    `json:"metadata"`
+	Components   []CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     []CycloneDXTool    `json:"tools"`
+	Component CycloneDXComponent `json:"component"`
+}
+
+type CycloneDXTool struct {
+	Vendor string `json:"vendor"`
+	Name   string `json:"name"`
+}
+
+type CycloneDXComponent struct {
+	Type    string          `json:"type"`
+	BOMRef  string          `json:"bom-ref,omitempty"`
+	Name    string          `json:"name"`
+	Version string          `json:"version,omitempty"`
+	PURL    string          `json:"purl,omitempty"`
+	Hashes  []CycloneDXHash `json:"hashes,omitempty"`
+}
+
+type CycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+// Checksum algorithms of packages, as CycloneDX names them
+var cycloneDXAlgorithms = map[string]string{
+	"md5":    "MD5",
+	"sha1":   "SHA-1",
+	"sha256": "SHA-256",
+	"sha384": "SHA-384",
+	"sha512": "SHA-512",
+}
+
+// CycloneDX returns the SBOM of the image in the CycloneDX format.
+func (img *Image) CycloneDX() *CycloneDXDocument {
+	doc := &CycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: "urn:uuid:" + img.ID.String(),
+		Version:      1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: img.Created.UTC().Format(time.RFC3339),
+			Tools: []CycloneDXTool{{
+				Vendor: "osbuild",
+				Name:   "osbuild-composer",
+			}},
+			Component: CycloneDXComponent{
+				Type: "operating-system",
+				Name: img.Name,
+			},
+		},
+		Components: []CycloneDXComponent{},
+	}
+
+	for _, pkg := range img.packages() {
+		purl := PackageURL(img.Distribution, pkg)
+		c := CycloneDXComponent{
+			Type:    "library",
+			BOMRef:  purl,
+			Name:    pkg.Name,
+			Version: version(pkg),
+			PURL:    purl,
+		}
+		if algorithm, value, ok := splitChecksum(pkg.Checksum); ok && cycloneDXAlgorithms[algorithm] != "" {
+			c.Hashes = []CycloneDXHash{{
+				Algorithm: cycloneDXAlgorithms[algorithm],
+				Content:   value,
+			}}
+		}
+		doc.Components = append(doc.Components, c)
+	}
+
+	return doc
+}
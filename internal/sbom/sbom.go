@@ -0,0 +1,103 @@
+// Package sbom renders the packages which were depsolved for an image into
+// software bills of materials, in the SPDX and CycloneDX JSON formats.
+package sbom
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+// Names of the SBOM artifacts of an image
+const (
+	SPDXArtifact      = "sbom.spdx.json"
+	CycloneDXArtifact = "sbom.cdx.json"
+)
+
+// Image describes the image an SBOM is generated for.
+type Image struct {
+	// Identifies the SBOM, which is generated once for each image
+	ID   uuid.UUID
+	Name string
+
+	// Name of the distribution the image is built from, e.g. "rhel-85"
+	Distribution string
+
+	Created time.Time
+
+	// The packages which are installed in the image
+	Packages []rpmmd.PackageSpec
+}
+
+// Vendor returns the vendor of the packages of `distribution`, as it
+// appears in package URLs.
+func Vendor(distribution string) string {
+	name := strings.SplitN(distribution, "-", 2)[0]
+	if name == "rhel" {
+		return "redhat"
+	}
+	return name
+}
+
+// PackageURL returns the package URL (purl) of `pkg`, which belongs to
+// `distribution`.
+func PackageURL(distribution string, pkg rpmmd.PackageSpec) string {
+	qualifiers := url.Values{}
+	qualifiers.Set("arch", pkg.Arch)
+	if pkg.Epoch != 0 {
+		qualifiers.Set("epoch", fmt.Sprint(pkg.Epoch))
+	}
+	qualifiers.Set("distro", distribution)
+
+	return fmt.Sprintf("pkg:rpm/%s/%s@%s?%s",
+		url.PathEscape(Vendor(distribution)),
+		url.PathEscape(pkg.Name),
+		url.PathEscape(pkg.Version+"-"+pkg.Release),
+		qualifiers.Encode())
+}
+
+// packages returns the packages of the image, sorted by name and without
+// duplicates.
+func (img *Image) packages() []rpmmd.PackageSpec {
+	seen := make(map[string]bool)
+	packages := make([]rpmmd.PackageSpec, 0, len(img.Packages))
+	for _, pkg := range img.Packages {
+		nevra := fmt.Sprintf("%s-%d:%s-%s.%s", pkg.Name, pkg.Epoch, pkg.Version, pkg.Release, pkg.Arch)
+		if seen[nevra] {
+			continue
+		}
+		seen[nevra] = true
+		packages = append(packages, pkg)
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Arch < packages[j].Arch
+	})
+	return packages
+}
+
+// version returns the [epoch:]version-release of `pkg`.
+func version(pkg rpmmd.PackageSpec) string {
+	if pkg.Epoch != 0 {
+		return fmt.Sprintf("%d:%s-%s", pkg.Epoch, pkg.Version, pkg.Release)
+	}
+	return pkg.Version + "-" + pkg.Release
+}
+
+// splitChecksum splits a checksum of the form "<algorithm>:<value>".
+func splitChecksum(checksum string) (string, string, bool) {
+	parts := strings.SplitN(checksum, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,113 @@
+package sbom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+var testImage = Image{
+	ID:           uuid.MustParse("b7a2a7a1-6ee8-4c66-9d4b-7a5b2b9e1cd4"),
+	Name:         "rhel-85-qcow2-x86_64",
+	Distribution: "rhel-85",
+	Created:      time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC),
+	Packages: []rpmmd.PackageSpec{
+		{
+			Name:           "zlib",
+			Version:        "1.2.11",
+			Release:        "17.el8",
+			Arch:           "x86_64",
+			RemoteLocation: "https://example.com/repo/zlib-1.2.11-17.el8.x86_64.rpm",
+			Checksum:       "sha256:1111",
+		},
+		{
+			Name:     "bash",
+			Epoch:    0,
+			Version:  "4.4.20",
+			Release:  "2.el8",
+			Arch:     "x86_64",
+			Checksum: "sha256:2222",
+		},
+		{
+			Name:     "openssl-libs",
+			Epoch:    1,
+			Version:  "1.1.1k",
+			Release:  "4.el8",
+			Arch:     "x86_64",
+			Checksum: "sha256:3333",
+		},
+		// depsolved for another package set of the image
+		{
+			Name:     "bash",
+			Version:  "4.4.20",
+			Release:  "2.el8",
+			Arch:     "x86_64",
+			Checksum: "sha256:2222",
+		},
+	},
+}
+
+func TestVendor(t *testing.T) {
+	require.Equal(t, "redhat", Vendor("rhel-85"))
+	require.Equal(t, "fedora", Vendor("fedora-33"))
+	require.Equal(t, "centos", Vendor("centos-8"))
+}
+
+func TestPackageURL(t *testing.T) {
+	require.Equal(t, "pkg:rpm/redhat/openssl-libs@1.1.1k-4.el8?arch=x86_64&distro=rhel-85&epoch=1", PackageURL("rhel-85", testImage.Packages[2]))
+	require.Equal(t, "pkg:rpm/redhat/bash@4.4.20-2.el8?arch=x86_64&distro=rhel-85", PackageURL("rhel-85", testImage.Packages[1]))
+}
+
+func TestSPDX(t *testing.T) {
+	doc := testImage.SPDX()
+
+	require.Equal(t, "SPDX-2.2", doc.SPDXVersion)
+	require.Equal(t, "https://osbuild.org/spdxdocs/rhel-85-qcow2-x86_64-b7a2a7a1-6ee8-4c66-9d4b-7a5b2b9e1cd4", doc.DocumentNamespace)
+	require.Equal(t, "2021-10-01T12:00:00Z", doc.CreationInfo.Created)
+
+	require.Len(t, doc.Packages, 3)
+	require.Len(t, doc.Relationships, 3)
+	require.Equal(t, SPDXPackage{
+		SPDXID:           "SPDXRef-Package-1",
+		Name:             "openssl-libs",
+		VersionInfo:      "1:1.1.1k-4.el8",
+		DownloadLocation: "NOASSERTION",
+		LicenseConcluded: "NOASSERTION",
+		LicenseDeclared:  "NOASSERTION",
+		CopyrightText:    "NOASSERTION",
+		Checksums:        []SPDXChecksum{{Algorithm: "SHA256", ChecksumValue: "3333"}},
+		ExternalRefs: []SPDXExternalRef{{
+			ReferenceCategory: "PACKAGE_MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  "pkg:rpm/redhat/openssl-libs@1.1.1k-4.el8?arch=x86_64&distro=rhel-85&epoch=1",
+		}},
+	}, doc.Packages[1])
+	require.Equal(t, "https://example.com/repo/zlib-1.2.11-17.el8.x86_64.rpm", doc.Packages[2].DownloadLocation)
+	require.Equal(t, SPDXRelationship{
+		SPDXElementID:      "SPDXRef-DOCUMENT",
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: "SPDXRef-Package-0",
+	}, doc.Relationships[0])
+}
+
+func TestCycloneDX(t *testing.T) {
+	doc := testImage.CycloneDX()
+
+	require.Equal(t, "CycloneDX", doc.BOMFormat)
+	require.Equal(t, "urn:uuid:b7a2a7a1-6ee8-4c66-9d4b-7a5b2b9e1cd4", doc.SerialNumber)
+	require.Equal(t, "rhel-85-qcow2-x86_64", doc.Metadata.Component.Name)
+
+	require.Len(t, doc.Components, 3)
+	require.Equal(t, CycloneDXComponent{
+		Type:    "library",
+		BOMRef:  "pkg:rpm/redhat/bash@4.4.20-2.el8?arch=x86_64&distro=rhel-85",
+		Name:    "bash",
+		Version: "4.4.20-2.el8",
+		PURL:    "pkg:rpm/redhat/bash@4.4.20-2.el8?arch=x86_64&distro=rhel-85",
+		Hashes:  []CycloneDXHash{{Algorithm: "SHA-256", Content: "2222"}},
+	}, doc.Components[0])
+}
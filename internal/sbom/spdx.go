@@ -0,0 +1,114 @@
+package sbom
+
+import (
+	"fmt"
+	"time"
+)
+
+// SPDXDocument is an SBOM in the SPDX 2.2 JSON format.
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type SPDXPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Checksums        []SPDXChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs"`
+}
+
+type SPDXChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// Checksum algorithms of packages, as SPDX names them
+var spdxAlgorithms = map[string]string{
+	"md5":    "MD5",
+	"sha1":   "SHA1",
+	"sha256": "SHA256",
+	"sha384": "SHA384",
+	"sha512": "SHA512",
+}
+
+// SPDX returns the SBOM of the image in the SPDX format.
+func (img *Image) SPDX() *SPDXDocument {
+	doc := &SPDXDocument{
+		SPDXVersion:       "SPDX-2.2",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              img.Name,
+		DocumentNamespace: fmt.Sprintf("https://osbuild.org/spdxdocs/%s-%s", img.Name, img.ID),
+		CreationInfo: SPDXCreationInfo{
+			Created:  img.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: osbuild-composer"},
+		},
+		Packages:      []SPDXPackage{},
+		Relationships: []SPDXRelationship{},
+	}
+
+	for i, pkg := range img.packages() {
+		p := SPDXPackage{
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d", i),
+			Name:             pkg.Name,
+			VersionInfo:      version(pkg),
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+			CopyrightText:    "NOASSERTION",
+			ExternalRefs: []SPDXExternalRef{{
+				ReferenceCategory: "PACKAGE_MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  PackageURL(img.Distribution, pkg),
+			}},
+		}
+		if pkg.RemoteLocation != "" {
+			p.DownloadLocation = pkg.RemoteLocation
+		}
+		if algorithm, value, ok := splitChecksum(pkg.Checksum); ok && spdxAlgorithms[algorithm] != "" {
+			p.Checksums = []SPDXChecksum{{
+				Algorithm:     spdxAlgorithms[algorithm],
+				ChecksumValue: value,
+			}}
+		}
+
+		doc.Packages = append(doc.Packages, p)
+		doc.Relationships = append(doc.Relationships, SPDXRelationship{
+			SPDXElementID:      doc.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: p.SPDXID,
+		})
+	}
+
+	return doc
+}
@@ -0,0 +1,124 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// How often Run() checks whether leases need to be renewed
+const renewInterval = 30 * time.Second
+
+// Manager reads named secrets from the Vault paths configured for them when
+// they are first needed, and keeps them until their lease expires. Run()
+// renews the leases of renewable secrets, so that credentials which were
+// handed out remain valid.
+type Manager struct {
+	client *VaultClient
+	paths  map[string]string
+
+	mu     sync.Mutex
+	leases map[string]*lease
+}
+
+type lease struct {
+	secret *VaultSecret
+	// zero for secrets which are not leased
+	expires time.Time
+}
+
+// NewManager returns a manager which reads the secret with name `name` from
+// `paths[name]`.
+func NewManager(client *VaultClient, paths map[string]string) *Manager {
+	return &Manager{
+		client: client,
+		paths:  paths,
+		leases: make(map[string]*lease),
+	}
+}
+
+// Secret returns the string values of the secret `name`, or nil if no path
+// is configured for it.
+func (m *Manager) Secret(name string) (map[string]string, error) {
+	path, ok := m.paths[name]
+	if !ok {
+		return nil, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.leases[name]
+	if !ok || l.expiring(time.Now()) {
+		secret, err := m.client.Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read secret %s from vault: %v", name, err)
+		}
+		l = newLease(secret, time.Now())
+		m.leases[name] = l
+	}
+
+	values := make(map[string]string)
+	for key, value := range l.secret.Data {
+		if s, ok := value.(string); ok {
+			values[key] = s
+		}
+	}
+	return values, nil
+}
+
+// Run renews the leases of secrets until `ctx` is done. Secrets whose lease
+// cannot be renewed are read again when they are needed next.
+func (m *Manager) Run(ctx context.Context) {
+	for {
+		select {
+		case <-time.After(renewInterval):
+			m.renew(time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Manager) renew(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, l := range m.leases {
+		if !l.expiring(now) {
+			continue
+		}
+		if !l.secret.Renewable {
+			delete(m.leases, name)
+			continue
+		}
+
+		renewed, err := m.client.RenewLease(l.secret.LeaseID, l.secret.LeaseDuration)
+		if err != nil {
+			log.Printf("Error renewing the lease of secret %s: %v", name, err)
+			delete(m.leases, name)
+			continue
+		}
+		l.secret.LeaseDuration = renewed.LeaseDuration
+		l.secret.Renewable = renewed.Renewable
+		l.expires = now.Add(renewed.LeaseDuration)
+	}
+}
+
+func newLease(secret *VaultSecret, now time.Time) *lease {
+	l := &lease{secret: secret}
+	if secret.LeaseDuration > 0 {
+		l.expires = now.Add(secret.LeaseDuration)
+	}
+	return l
+}
+
+// Leases are renewed when a third of their duration is left.
+func (l *lease) expiring(now time.Time) bool {
+	if l.expires.IsZero() {
+		return false
+	}
+	return now.After(l.expires.Add(-l.secret.LeaseDuration / 3))
+}
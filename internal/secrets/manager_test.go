@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVault(t *testing.T) (*httptest.Server, *int) {
+	renewals := 0
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		var response interface{}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/aws/creds/composer":
+			response = map[string]interface{}{
+				"lease_id":       "aws/creds/composer/1",
+				"lease_duration": 3600,
+				"renewable":      true,
+				"data": map[string]interface{}{
+					"access_key":     "AKIA",
+					"secret_key":     "secret",
+					"security_token": nil,
+				},
+			}
+		case "GET /v1/secret/data/azure":
+			response = map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]interface{}{"client_id": "id", "client_secret": "secret"},
+					"metadata": map[string]interface{}{"version": 1},
+				},
+			}
+		case "PUT /v1/sys/leases/renew":
+			var body struct {
+				LeaseID   string `json:"lease_id"`
+				Increment int    `json:"increment"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "aws/creds/composer/1", body.LeaseID)
+			require.Equal(t, 3600, body.Increment)
+			renewals++
+			response = map[string]interface{}{
+				"lease_id":       body.LeaseID,
+				"lease_duration": 1800,
+				"renewable":      true,
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	return vault, &renewals
+}
+
+func TestManager(t *testing.T) {
+	vault, renewals := newTestVault(t)
+	defer vault.Close()
+
+	manager := NewManager(NewVaultClient(vault.URL, "token"), map[string]string{
+		"aws":     "aws/creds/composer",
+		"azure":   "secret/data/azure",
+		"missing": "secret/data/missing",
+	})
+
+	aws, err := manager.Secret("aws")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"access_key": "AKIA", "secret_key": "secret"}, aws)
+
+	azure, err := manager.Secret("azure")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"client_id": "id", "client_secret": "secret"}, azure)
+
+	gcp, err := manager.Secret("gcp")
+	require.NoError(t, err)
+	require.Nil(t, gcp)
+
+	_, err = manager.Secret("missing")
+	require.EqualError(t, err, "cannot read secret missing from vault: vault returned 404 Not Found")
+
+	// nothing to renew yet
+	manager.renew(time.Now())
+	require.Equal(t, 0, *renewals)
+
+	now := time.Now().Add(50 * time.Minute)
+	manager.renew(now)
+	require.Equal(t, 1, *renewals)
+	require.Equal(t, now.Add(30*time.Minute), manager.leases["aws"].expires)
+
+	// secrets which are not leased are kept
+	require.Contains(t, manager.leases, "azure")
+}
+
+func TestVaultClientPermissionDenied(t *testing.T) {
+	vault, _ := newTestVault(t)
+	defer vault.Close()
+
+	_, err := NewVaultClient(vault.URL, "wrong").Read("aws/creds/composer")
+	require.EqualError(t, err, "vault returned 403 Forbidden: permission denied")
+}
@@ -0,0 +1,133 @@
+// Package secrets fetches credentials from HashiCorp Vault, so that they
+// don't need to be stored in configuration files.
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultClient is a minimal client of Vault's HTTP API, authenticated with a
+// token.
+type VaultClient struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// VaultSecret is a secret read from Vault. Dynamic secrets, such as the
+// credentials of the AWS, Azure, and GCP secrets engines, are leased and
+// must be renewed before the lease expires.
+type VaultSecret struct {
+	Data          map[string]interface{}
+	LeaseID       string
+	LeaseDuration time.Duration
+	Renewable     bool
+}
+
+type vaultResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Errors        []string               `json:"errors"`
+}
+
+func NewVaultClient(address, token string) *VaultClient {
+	return &VaultClient{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Read reads the secret at `path`, for example "aws/creds/composer". The
+// data of key/value version 2 secrets is unwrapped.
+func (c *VaultClient) Read(path string) (*VaultSecret, error) {
+	resp, err := c.request(http.MethodGet, "/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := resp.secret()
+	// key/value version 2 secrets nest the data with its metadata
+	if data, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			secret.Data = data
+		}
+	}
+	return secret, nil
+}
+
+// RenewLease extends the lease `leaseID` by `increment`. Vault may grant a
+// shorter lease, which the returned secret contains.
+func (c *VaultClient) RenewLease(leaseID string, increment time.Duration) (*VaultSecret, error) {
+	body := map[string]interface{}{
+		"lease_id":  leaseID,
+		"increment": int(increment.Seconds()),
+	}
+	resp, err := c.request(http.MethodPut, "/v1/sys/leases/renew", body)
+	if err != nil {
+		return nil, err
+	}
+	return resp.secret(), nil
+}
+
+func (c *VaultClient) request(method, path string, body interface{}) (*vaultResponse, error) {
+	reader := bytes.NewReader(nil)
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.address+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result vaultResponse
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("cannot parse response of vault: %v", err)
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(result.Errors, ", "))
+		}
+		return nil, fmt.Errorf("vault returned %s", resp.Status)
+	}
+	return &result, nil
+}
+
+func (r *vaultResponse) secret() *VaultSecret {
+	return &VaultSecret{
+		Data:          r.Data,
+		LeaseID:       r.LeaseID,
+		LeaseDuration: time.Duration(r.LeaseDuration) * time.Second,
+		Renewable:     r.Renewable,
+	}
+}
@@ -0,0 +1,109 @@
+// Package sentry reports panics and failures to Sentry, or to a service which
+// implements its API, such as GlitchTip, with the Sentry SDK.
+package sentry
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	sentrygo "github.com/getsentry/sentry-go"
+)
+
+// Panics are sent right away, because the process might not survive them,
+// but give up after this long
+const panicFlushTimeout = 5 * time.Second
+
+// Client sends events to a Sentry project. A nil *Client drops all events,
+// so that callers don't need to check whether error reporting is enabled.
+type Client struct {
+	hub *sentrygo.Hub
+}
+
+// NewClient returns a client which sends events to the project of `dsn`,
+// tagged with `tags`, which usually include the name of the service.
+func NewClient(dsn string, tags map[string]string) (*Client, error) {
+	client, err := sentrygo.NewClient(sentrygo.ClientOptions{
+		Dsn: dsn,
+		// panics with a string or another value which isn't an error
+		// have a stack trace only with this
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %v", err)
+	}
+
+	scope := sentrygo.NewScope()
+	scope.SetTags(tags)
+	return &Client{hub: sentrygo.NewHub(client, scope)}, nil
+}
+
+// CaptureError sends `err`, with `tags` and `extra` context, in the
+// background.
+func (c *Client) CaptureError(err error, tags map[string]string, extra map[string]interface{}) {
+	if c == nil || err == nil {
+		return
+	}
+
+	// the scope of a clone can be changed without affecting concurrent
+	// callers
+	hub := c.hub.Clone()
+	hub.Scope().SetTags(tags)
+	hub.Scope().SetExtras(extra)
+	hub.CaptureException(err)
+}
+
+// Recover reports a panic with `tags` and panics again. It must be deferred
+// directly:
+//
+//	defer client.Recover(tags)
+func (c *Client) Recover(tags map[string]string) {
+	if c == nil {
+		return
+	}
+	if r := recover(); r != nil {
+		c.reportPanic(r, tags)
+		panic(r)
+	}
+}
+
+// Middleware reports panics of `next` and panics again, which lets the HTTP
+// server log them as usual.
+func (c *Client) Middleware(next http.Handler) http.Handler {
+	if c == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if p := recover(); p != nil {
+				if p != http.ErrAbortHandler {
+					c.reportPanic(p, map[string]string{
+						"http.method": r.Method,
+						"http.path":   r.URL.Path,
+					})
+				}
+				panic(p)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *Client) reportPanic(value interface{}, tags map[string]string) {
+	hub := c.hub.Clone()
+	hub.Scope().SetTags(tags)
+	hub.Recover(value)
+	if !hub.Flush(panicFlushTimeout) {
+		log.Printf("Error reporting panic '%v': timed out", value)
+	}
+}
+
+// Flush waits until all events have been sent, for `timeout` at most.
+// Returns false if some events haven't been sent in time.
+func (c *Client) Flush(timeout time.Duration) bool {
+	if c == nil {
+		return true
+	}
+	return c.hub.Flush(timeout)
+}
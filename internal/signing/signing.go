@@ -0,0 +1,122 @@
+// Package signing creates detached signatures of the documents composer
+// serves, such as manifests, SBOMs, and provenance attestations.
+//
+// Signatures are ECDSA signatures of the SHA-256 digest of a document,
+// ASN.1 DER encoded and then base64 encoded. This is the format of cosign's
+// blob signatures, so that they can be verified with
+//
+//	cosign verify-blob --key <public key> --signature <signature> <document>
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+)
+
+type Signer struct {
+	key *ecdsa.PrivateKey
+}
+
+func NewSigner(key *ecdsa.PrivateKey) *Signer {
+	return &Signer{key: key}
+}
+
+// LoadSigner reads a PEM encoded ECDSA private key, either in SEC 1 ("EC
+// PRIVATE KEY") or PKCS #8 ("PRIVATE KEY") form, from the file at `path`.
+func LoadSigner(path string) (*Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM encoded key", path)
+	}
+
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewSigner(key), nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		ecKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s does not contain an ECDSA key", path)
+		}
+		return NewSigner(ecKey), nil
+	default:
+		return nil, fmt.Errorf("%s contains a %s instead of a private key", path, block.Type)
+	}
+}
+
+// Sign returns the signature of `data`.
+func (s *Signer) Sign(data []byte) (string, error) {
+	digest := sha256.Sum256(data)
+	signature, err := s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// PublicKey returns the PEM encoded public key which verifies the
+// signatures of the signer.
+func (s *Signer) PublicKey() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(&s.key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// Verify returns an error if `signature` is not a signature of `data` by the
+// key whose PEM encoded public key is `publicKey`.
+func Verify(publicKey, data []byte, signature string) error {
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return errors.New("public key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("public key is not an ECDSA key")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("signature is not base64 encoded: %v", err)
+	}
+
+	var rs struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(sig, &rs); err != nil {
+		return fmt.Errorf("signature is not ASN.1 encoded: %v", err)
+	}
+
+	digest := sha256.Sum256(data)
+	if !ecdsa.Verify(ecKey, digest[:], rs.R, rs.S) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer := NewSigner(key)
+
+	publicKey, err := signer.PublicKey()
+	require.NoError(t, err)
+
+	signature, err := signer.Sign([]byte("manifest"))
+	require.NoError(t, err)
+	require.NoError(t, Verify(publicKey, []byte("manifest"), signature))
+	require.Error(t, Verify(publicKey, []byte("another manifest"), signature))
+	require.Error(t, Verify(publicKey, []byte("manifest"), "not a signature"))
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherPublicKey, err := NewSigner(otherKey).PublicKey()
+	require.NoError(t, err)
+	require.Error(t, Verify(otherPublicKey, []byte("manifest"), signature))
+}
+
+func TestLoadSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signing-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	sec1, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	for blockType, der := range map[string][]byte{"EC PRIVATE KEY": sec1, "PRIVATE KEY": pkcs8} {
+		p := path.Join(dir, "key.pem")
+		err = ioutil.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+		require.NoError(t, err)
+
+		signer, err := LoadSigner(p)
+		require.NoError(t, err, blockType)
+		require.Equal(t, key.D, signer.key.D, blockType)
+	}
+
+	p := path.Join(dir, "public.pem")
+	publicKey, err := NewSigner(key).PublicKey()
+	require.NoError(t, err)
+	err = ioutil.WriteFile(p, publicKey, 0600)
+	require.NoError(t, err)
+	_, err = LoadSigner(p)
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -94,3 +95,29 @@ func (c *Compose) DeepCopy() Compose {
 		Packages:   pkgs,
 	}
 }
+
+// ComposeFilter selects the composes which ListComposes() returns. Fields with
+// their zero value select all composes.
+type ComposeFilter struct {
+	// Case-insensitive part of the name of the blueprint of the composes
+	BlueprintSearch string
+	// Composes which were created at or after Since and before Until
+	Since time.Time
+	Until time.Time
+}
+
+// Matches returns whether `compose` is selected by the filter
+func (f *ComposeFilter) Matches(compose *Compose) bool {
+	if f.BlueprintSearch != "" {
+		if compose.Blueprint == nil || !strings.Contains(strings.ToLower(compose.Blueprint.Name), strings.ToLower(f.BlueprintSearch)) {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && compose.ImageBuild.JobCreated.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !compose.ImageBuild.JobCreated.Before(f.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,1379 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	// register the "postgres" database driver
+	_ "github.com/lib/pq"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/target"
+)
+
+// Schema migrations of the PostgreSQL-backed store. Each of them is applied
+// exactly once, in order, and recorded in `store_schema_migrations`. Append
+// new migrations to the end, never change existing ones.
+var dbStoreMigrations = []string{
+	`
+	CREATE TABLE blueprints(
+		name varchar PRIMARY KEY,
+		blueprint jsonb NOT NULL
+	);
+
+	CREATE TABLE blueprint_workspace(
+		name varchar PRIMARY KEY,
+		blueprint jsonb NOT NULL
+	);
+
+	-- Kept when the blueprint is deleted. The order of the changes of a
+	-- blueprint is the order of their seq.
+	CREATE TABLE blueprint_changes(
+		name varchar NOT NULL,
+		commit varchar NOT NULL,
+		seq bigserial NOT NULL,
+		message varchar NOT NULL,
+		revision integer,
+		timestamp varchar NOT NULL,
+		-- NULL for changes saved by older versions
+		blueprint jsonb,
+		PRIMARY KEY(name, commit)
+	);
+
+	CREATE INDEX blueprint_changes_seq_idx ON blueprint_changes(name, seq);
+
+	CREATE TABLE blueprint_tags(
+		name varchar NOT NULL,
+		tag varchar NOT NULL,
+		commit varchar NOT NULL,
+		PRIMARY KEY(name, tag)
+	);
+
+	-- Composes in the same format as in the state file of FileStore
+	CREATE TABLE composes(
+		id uuid PRIMARY KEY,
+		compose jsonb NOT NULL
+	);
+
+	CREATE TABLE sources(
+		id varchar PRIMARY KEY,
+		name varchar NOT NULL,
+		type varchar NOT NULL,
+		url varchar NOT NULL,
+		check_gpg boolean NOT NULL,
+		check_ssl boolean NOT NULL,
+		system boolean NOT NULL
+	);
+
+	-- Compose schedules in the same format as in the state file of
+	-- FileStore
+	CREATE TABLE compose_schedules(
+		id uuid PRIMARY KEY,
+		schedule jsonb NOT NULL
+	);
+	`,
+	`
+	-- Copies of the name of the blueprint and the creation time of the
+	-- compose, so that composes can be filtered by them
+	ALTER TABLE composes
+		ADD COLUMN blueprint_name varchar,
+		ADD COLUMN created_at timestamptz;
+
+	UPDATE composes
+	SET blueprint_name = compose->'blueprint'->>'name',
+	    created_at = (compose->'image_builds'->0->>'job_created')::timestamptz;
+
+	CREATE INDEX composes_created_at_idx ON composes(created_at);
+	`,
+}
+
+const (
+	// Serializes migrations of composer instances which start at the same
+	// time. The key is arbitrary, but must not be used for other locks.
+	sqlLockMigrations   = `SELECT pg_advisory_xact_lock(7310455841)`
+	sqlCreateMigrations = `
+		CREATE TABLE IF NOT EXISTS store_schema_migrations(
+			version integer PRIMARY KEY,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`
+	sqlQuerySchemaVersion = `
+		SELECT coalesce(max(version), 0)
+		FROM store_schema_migrations`
+	sqlInsertSchemaVersion = `
+		INSERT INTO store_schema_migrations(version)
+		VALUES ($1)`
+
+	sqlQueryIsEmpty = `
+		SELECT NOT EXISTS (SELECT 1 FROM blueprints)
+		   AND NOT EXISTS (SELECT 1 FROM blueprint_workspace)
+		   AND NOT EXISTS (SELECT 1 FROM blueprint_changes)
+		   AND NOT EXISTS (SELECT 1 FROM composes)
+		   AND NOT EXISTS (SELECT 1 FROM sources)
+		   AND NOT EXISTS (SELECT 1 FROM compose_schedules)`
+
+	sqlListBlueprints = `
+		SELECT name
+		FROM blueprints
+		ORDER BY name COLLATE "C"`
+	sqlQueryBlueprint = `
+		SELECT blueprint, TRUE
+		FROM blueprint_workspace
+		WHERE name = $1
+		UNION ALL
+		SELECT blueprint, FALSE
+		FROM blueprints
+		WHERE name = $1
+		ORDER BY 2 DESC
+		LIMIT 1`
+	sqlQueryAllBlueprints = `
+		SELECT name, blueprint
+		FROM blueprints
+		ORDER BY name COLLATE "C"`
+	sqlQueryBlueprintCommitted = `
+		SELECT blueprint
+		FROM blueprints
+		WHERE name = $1`
+	sqlLockBlueprintCommitted = `
+		SELECT blueprint
+		FROM blueprints
+		WHERE name = $1
+		FOR UPDATE`
+	sqlUpsertBlueprint = `
+		INSERT INTO blueprints(name, blueprint)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET blueprint = excluded.blueprint`
+	sqlDeleteBlueprint = `
+		DELETE FROM blueprints
+		WHERE name = $1`
+	sqlUpsertWorkspace = `
+		INSERT INTO blueprint_workspace(name, blueprint)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET blueprint = excluded.blueprint`
+	sqlDeleteWorkspace = `
+		DELETE FROM blueprint_workspace
+		WHERE name = $1`
+
+	sqlQueryChange = `
+		SELECT commit, message, revision, timestamp, blueprint
+		FROM blueprint_changes
+		WHERE name = $1 AND commit = coalesce((
+		  SELECT commit
+		  FROM blueprint_tags
+		  WHERE name = $1 AND tag = $2
+		), $2)`
+	sqlQueryChanges = `
+		SELECT commit, message, revision, timestamp, blueprint
+		FROM blueprint_changes
+		WHERE name = $1
+		ORDER BY seq`
+	sqlQueryAllChanges = `
+		SELECT name, commit, message, revision, timestamp, blueprint
+		FROM blueprint_changes
+		ORDER BY name, seq`
+	sqlQueryHasChanges = `
+		SELECT EXISTS (SELECT 1 FROM blueprint_changes WHERE name = $1),
+		       EXISTS (SELECT 1 FROM blueprint_changes WHERE name = $1 AND commit = $2)`
+	sqlQueryLatestChange = `
+		SELECT commit, revision
+		FROM blueprint_changes
+		WHERE name = $1
+		ORDER BY seq DESC
+		LIMIT 1`
+	sqlQueryLatestRevision = `
+		SELECT revision
+		FROM blueprint_changes
+		WHERE name = $1 AND revision > 0
+		ORDER BY seq DESC
+		LIMIT 1`
+	sqlSetRevision = `
+		UPDATE blueprint_changes
+		SET revision = $3
+		WHERE name = $1 AND commit = $2`
+	sqlInsertChange = `
+		INSERT INTO blueprint_changes(name, commit, message, revision, timestamp, blueprint)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	sqlDeleteChanges = `
+		DELETE FROM blueprint_changes
+		WHERE name = $1`
+
+	sqlQueryTags = `
+		SELECT tag, commit
+		FROM blueprint_tags
+		WHERE name = $1`
+	sqlQueryAllTags = `
+		SELECT name, tag, commit
+		FROM blueprint_tags`
+	sqlUpsertTag = `
+		INSERT INTO blueprint_tags(name, tag, commit)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name, tag) DO UPDATE SET commit = excluded.commit`
+	sqlDeleteTags = `
+		DELETE FROM blueprint_tags
+		WHERE name = $1`
+
+	sqlQueryCompose = `
+		SELECT compose
+		FROM composes
+		WHERE id = $1`
+	sqlQueryAllComposes = `
+		SELECT id, compose
+		FROM composes`
+	sqlQueryComposes = `
+		SELECT id, compose
+		FROM composes
+		WHERE ($1::varchar = '' OR strpos(lower(blueprint_name), lower($1::varchar)) > 0)
+		  AND ($2::timestamptz IS NULL OR created_at >= $2)
+		  AND ($3::timestamptz IS NULL OR created_at < $3)`
+	// The columns which are copied from the compose are set from it, so
+	// that they can't get out of sync
+	sqlInsertCompose = `
+		INSERT INTO composes(id, compose, blueprint_name, created_at)
+		VALUES ($1, $2, $2::jsonb->'blueprint'->>'name',
+		        ($2::jsonb->'image_builds'->0->>'job_created')::timestamptz)
+		ON CONFLICT (id) DO NOTHING`
+	sqlUpsertCompose = `
+		INSERT INTO composes(id, compose, blueprint_name, created_at)
+		VALUES ($1, $2, $2::jsonb->'blueprint'->>'name',
+		        ($2::jsonb->'image_builds'->0->>'job_created')::timestamptz)
+		ON CONFLICT (id) DO UPDATE
+		SET compose = excluded.compose, blueprint_name = excluded.blueprint_name,
+		    created_at = excluded.created_at`
+	sqlDeleteCompose = `
+		DELETE FROM composes
+		WHERE id = $1`
+
+	sqlUpsertSource = `
+		INSERT INTO sources(id, name, type, url, check_gpg, check_ssl, system)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE
+		SET name = excluded.name, type = excluded.type, url = excluded.url,
+		    check_gpg = excluded.check_gpg, check_ssl = excluded.check_ssl,
+		    system = excluded.system`
+	sqlDeleteSourceByName = `
+		DELETE FROM sources
+		WHERE id = (
+		  SELECT id
+		  FROM sources
+		  WHERE name = $1
+		  LIMIT 1
+		)`
+	sqlDeleteSourceByID = `
+		DELETE FROM sources
+		WHERE id = $1`
+	sqlListSourceNames = `
+		SELECT name
+		FROM sources
+		ORDER BY name COLLATE "C"`
+	sqlListSourceIDs = `
+		SELECT id
+		FROM sources
+		ORDER BY id COLLATE "C"`
+	sqlQuerySource = `
+		SELECT id, name, type, url, check_gpg, check_ssl, system
+		FROM sources
+		WHERE id = $1`
+	sqlQueryAllSources = `
+		SELECT id, name, type, url, check_gpg, check_ssl, system
+		FROM sources`
+
+	sqlQueryAllSchedules = `
+		SELECT id, schedule
+		FROM compose_schedules`
+	sqlLockSchedule = `
+		SELECT schedule
+		FROM compose_schedules
+		WHERE id = $1
+		FOR UPDATE`
+	sqlUpsertSchedule = `
+		INSERT INTO compose_schedules(id, schedule)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET schedule = excluded.schedule`
+	sqlDeleteSchedule = `
+		DELETE FROM compose_schedules
+		WHERE id = $1`
+)
+
+// A DBStore keeps the state in a PostgreSQL database. It doesn't keep any
+// state in memory, so that several composer instances can share the same
+// database.
+//
+// Methods which can't return an error panic when the database can't be
+// accessed, like FileStore does when it can't write the state file.
+type DBStore struct {
+	db   *sql.DB
+	arch distro.Arch
+}
+
+// NewDB returns a DBStore for the PostgreSQL database at `url`, and migrates
+// its schema to the current version. If `url` is empty, the connection
+// parameters are taken from the standard libpq environment variables
+// (PGHOST, PGDATABASE, PGUSER, PGPASSWORD, ...). Image types of composes are
+// looked up in `arch`.
+func NewDB(url string, arch distro.Arch) (*DBStore, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %v", err)
+	}
+
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to database: %v", err)
+	}
+
+	err = migrateDBStore(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DBStore{db, arch}, nil
+}
+
+// Close closes all connections to the database.
+func (s *DBStore) Close() {
+	s.db.Close()
+}
+
+func migrateDBStore(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting database transaction: %v", err)
+	}
+	defer rollback(tx)
+
+	_, err = tx.Exec(sqlLockMigrations)
+	if err != nil {
+		return fmt.Errorf("error locking schema migrations: %v", err)
+	}
+
+	_, err = tx.Exec(sqlCreateMigrations)
+	if err != nil {
+		return fmt.Errorf("error creating schema migrations table: %v", err)
+	}
+
+	var version int
+	err = tx.QueryRow(sqlQuerySchemaVersion).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("error querying schema version: %v", err)
+	}
+	if version > len(dbStoreMigrations) {
+		return fmt.Errorf("database schema version %d is newer than the supported version %d", version, len(dbStoreMigrations))
+	}
+
+	for ; version < len(dbStoreMigrations); version++ {
+		_, err = tx.Exec(dbStoreMigrations[version])
+		if err != nil {
+			return fmt.Errorf("error migrating schema to version %d: %v", version+1, err)
+		}
+		_, err = tx.Exec(sqlInsertSchemaVersion, version+1)
+		if err != nil {
+			return fmt.Errorf("error recording schema version %d: %v", version+1, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing database transaction: %v", err)
+	}
+
+	return nil
+}
+
+// ImportFileStore copies all state of `src` into the database, which must
+// not contain any state yet. Composes which `src` couldn't load are copied as
+// they are.
+func (s *DBStore) ImportFileStore(src *FileStore) error {
+	src.mu.RLock()
+	defer src.mu.RUnlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting database transaction: %v", err)
+	}
+	defer rollback(tx)
+
+	var empty bool
+	err = tx.QueryRow(sqlQueryIsEmpty).Scan(&empty)
+	if err != nil {
+		return fmt.Errorf("error querying store: %v", err)
+	}
+	if !empty {
+		return errors.New("the database already contains a store")
+	}
+
+	for name, bp := range src.blueprints {
+		err = execJSON(tx, sqlUpsertBlueprint, name, bp)
+		if err != nil {
+			return fmt.Errorf("error importing blueprint %s: %v", name, err)
+		}
+	}
+
+	for name, bp := range src.workspace {
+		err = execJSON(tx, sqlUpsertWorkspace, name, bp)
+		if err != nil {
+			return fmt.Errorf("error importing workspace blueprint %s: %v", name, err)
+		}
+	}
+
+	for name, changes := range src.blueprintsChanges {
+		for _, commit := range orderedCommits(changes, src.blueprintsCommits[name]) {
+			err = insertChange(tx, name, changes[commit])
+			if err != nil {
+				return fmt.Errorf("error importing change %s of blueprint %s: %v", commit, name, err)
+			}
+		}
+	}
+
+	for name, tags := range src.blueprintsTags {
+		for tag, commit := range tags {
+			_, err = tx.Exec(sqlUpsertTag, name, tag, commit)
+			if err != nil {
+				return fmt.Errorf("error importing tag %s of blueprint %s: %v", tag, name, err)
+			}
+		}
+	}
+
+	for id, compose := range src.composes {
+		err = execJSON(tx, sqlUpsertCompose, id, newComposeV0(compose))
+		if err != nil {
+			return fmt.Errorf("error importing compose %s: %v", id, err)
+		}
+	}
+
+	for id, composeStruct := range src.unloadedComposes {
+		err = execJSON(tx, sqlUpsertCompose, id, composeStruct)
+		if err != nil {
+			return fmt.Errorf("error importing compose %s: %v", id, err)
+		}
+	}
+
+	for id, source := range src.sources {
+		err = upsertSource(tx, id, source)
+		if err != nil {
+			return fmt.Errorf("error importing source %s: %v", id, err)
+		}
+	}
+
+	for id, schedule := range src.schedules {
+		err = execJSON(tx, sqlUpsertSchedule, id, scheduleV0(schedule))
+		if err != nil {
+			return fmt.Errorf("error importing schedule %s: %v", id, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing database transaction: %v", err)
+	}
+
+	return nil
+}
+
+// orderedCommits returns the commits of `changes` in the order in which they
+// are inserted into the database. Changes which are missing in `commits` are
+// put first, ordered by their timestamp, so that the latest change stays the
+// latest one.
+func orderedCommits(changes map[string]blueprint.Change, commits []string) []string {
+	listed := make(map[string]bool, len(commits))
+	for _, commit := range commits {
+		listed[commit] = true
+	}
+
+	var ordered []string
+	for commit := range changes {
+		if !listed[commit] {
+			ordered = append(ordered, commit)
+		}
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return changes[ordered[i]].Timestamp < changes[ordered[j]].Timestamp
+	})
+
+	for _, commit := range commits {
+		if _, ok := changes[commit]; ok {
+			ordered = append(ordered, commit)
+		}
+	}
+	return ordered
+}
+
+func (s *DBStore) ListBlueprints() []string {
+	return s.queryStrings("blueprints", sqlListBlueprints)
+}
+
+func (s *DBStore) GetBlueprint(name string) (*blueprint.Blueprint, bool) {
+	var rawBlueprint []byte
+	var inWorkspace bool
+	err := s.db.QueryRow(sqlQueryBlueprint, name).Scan(&rawBlueprint, &inWorkspace)
+	if err == sql.ErrNoRows {
+		return nil, false
+	}
+	if err != nil {
+		panic(fmt.Errorf("error querying blueprint %s: %v", name, err))
+	}
+
+	return mustUnmarshalBlueprint(rawBlueprint), inWorkspace
+}
+
+func (s *DBStore) GetBlueprintCommitted(name string) *blueprint.Blueprint {
+	var rawBlueprint []byte
+	err := s.db.QueryRow(sqlQueryBlueprintCommitted, name).Scan(&rawBlueprint)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		panic(fmt.Errorf("error querying blueprint %s: %v", name, err))
+	}
+
+	return mustUnmarshalBlueprint(rawBlueprint)
+}
+
+// GetBlueprintChange returns a specific change to a blueprint, by its commit
+// or the name of a tag pointing to it
+// If the blueprint or change do not exist then an error is returned
+func (s *DBStore) GetBlueprintChange(name string, commit string) (*blueprint.Change, error) {
+	change, err := scanChange(s.db.QueryRow(sqlQueryChange, name, commit))
+	if err == sql.ErrNoRows {
+		var blueprintExists, commitExists bool
+		err = s.db.QueryRow(sqlQueryHasChanges, name, commit).Scan(&blueprintExists, &commitExists)
+		if err != nil {
+			return nil, fmt.Errorf("error querying changes of blueprint %s: %v", name, err)
+		}
+		if !blueprintExists {
+			return nil, errors.New("Unknown blueprint")
+		}
+		return nil, errors.New("Unknown commit")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying change %s of blueprint %s: %v", commit, name, err)
+	}
+	return &change, nil
+}
+
+// GetBlueprintRevision returns the blueprint as it was saved in a commit,
+// which is given by its id or the name of a tag pointing to it
+func (s *DBStore) GetBlueprintRevision(name string, commit string) (*blueprint.Blueprint, error) {
+	change, err := s.GetBlueprintChange(name, commit)
+	if err != nil {
+		return nil, err
+	}
+	return changeBlueprint(change)
+}
+
+// GetBlueprintTags returns the tags of a blueprint and the commits they
+// point to
+func (s *DBStore) GetBlueprintTags(name string) map[string]string {
+	rows, err := s.db.Query(sqlQueryTags, name)
+	if err != nil {
+		panic(fmt.Errorf("error querying tags of blueprint %s: %v", name, err))
+	}
+	defer rows.Close()
+
+	tags := make(map[string]string)
+	for rows.Next() {
+		var tag, commit string
+		err = rows.Scan(&tag, &commit)
+		if err != nil {
+			panic(fmt.Errorf("error querying tags of blueprint %s: %v", name, err))
+		}
+		tags[tag] = commit
+	}
+	if err = rows.Err(); err != nil {
+		panic(fmt.Errorf("error querying tags of blueprint %s: %v", name, err))
+	}
+	return tags
+}
+
+// SetBlueprintTag points the tag of a blueprint to a commit, moving it if it
+// already exists
+// It will return an error if the blueprint or commit don't exist
+func (s *DBStore) SetBlueprintTag(name string, tag string, commit string) error {
+	return s.transaction(func(tx *sql.Tx) error {
+		var blueprintExists, commitExists bool
+		err := tx.QueryRow(sqlQueryHasChanges, name, commit).Scan(&blueprintExists, &commitExists)
+		if err != nil {
+			return fmt.Errorf("error querying changes of blueprint %s: %v", name, err)
+		}
+		if !blueprintExists {
+			return errors.New("Unknown blueprint")
+		}
+		if !commitExists {
+			return errors.New("Unknown commit")
+		}
+
+		_, err = tx.Exec(sqlUpsertTag, name, tag, commit)
+		if err != nil {
+			return fmt.Errorf("error setting tag %s of blueprint %s: %v", tag, name, err)
+		}
+		return nil
+	})
+}
+
+// GetBlueprintChanges returns the list of changes, oldest first
+func (s *DBStore) GetBlueprintChanges(name string) []blueprint.Change {
+	rows, err := s.db.Query(sqlQueryChanges, name)
+	if err != nil {
+		panic(fmt.Errorf("error querying changes of blueprint %s: %v", name, err))
+	}
+	defer rows.Close()
+
+	var changes []blueprint.Change
+	for rows.Next() {
+		change, err := scanChange(rows)
+		if err != nil {
+			panic(fmt.Errorf("error querying changes of blueprint %s: %v", name, err))
+		}
+		changes = append(changes, change)
+	}
+	if err = rows.Err(); err != nil {
+		panic(fmt.Errorf("error querying changes of blueprint %s: %v", name, err))
+	}
+
+	return changes
+}
+
+func (s *DBStore) PushBlueprint(bp blueprint.Blueprint, commitMsg string) error {
+	commit, err := randomSHA1String()
+	if err != nil {
+		return err
+	}
+
+	// Make sure the blueprint has default values and that the version is valid
+	err = bp.Initialize()
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Format("2006-01-02T15:04:05Z")
+	change := blueprint.Change{
+		Commit:    commit,
+		Message:   commitMsg,
+		Timestamp: timestamp,
+		Blueprint: bp,
+	}
+
+	return s.transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(sqlDeleteWorkspace, bp.Name)
+		if err != nil {
+			return fmt.Errorf("error deleting workspace blueprint %s: %v", bp.Name, err)
+		}
+
+		err = insertChange(tx, bp.Name, change)
+		if err != nil {
+			return fmt.Errorf("error inserting change of blueprint %s: %v", bp.Name, err)
+		}
+
+		var rawOld []byte
+		err = tx.QueryRow(sqlLockBlueprintCommitted, bp.Name).Scan(&rawOld)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("error querying blueprint %s: %v", bp.Name, err)
+		}
+		if err == nil {
+			old := mustUnmarshalBlueprint(rawOld)
+			if bp.Version == "" || bp.Version == old.Version {
+				bp.BumpVersion(old.Version)
+			}
+		}
+
+		err = execJSON(tx, sqlUpsertBlueprint, bp.Name, bp)
+		if err != nil {
+			return fmt.Errorf("error saving blueprint %s: %v", bp.Name, err)
+		}
+		return nil
+	})
+}
+
+func (s *DBStore) PushBlueprintToWorkspace(bp blueprint.Blueprint) error {
+	// Make sure the blueprint has default values and that the version is valid
+	err := bp.Initialize()
+	if err != nil {
+		return err
+	}
+
+	err = execJSON(s.db, sqlUpsertWorkspace, bp.Name, bp)
+	if err != nil {
+		return fmt.Errorf("error saving workspace blueprint %s: %v", bp.Name, err)
+	}
+	return nil
+}
+
+// DeleteBlueprint will remove the named blueprint from the store
+// if the blueprint does not exist it will return an error
+// The workspace copy is deleted unconditionally, it will not return an error if it does not exist.
+func (s *DBStore) DeleteBlueprint(name string) error {
+	_, err := s.db.Exec(sqlDeleteWorkspace, name)
+	if err != nil {
+		return fmt.Errorf("error deleting workspace blueprint %s: %v", name, err)
+	}
+
+	deleted, err := s.execCount(sqlDeleteBlueprint, name)
+	if err != nil {
+		return fmt.Errorf("error deleting blueprint %s: %v", name, err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("Unknown blueprint: %s", name)
+	}
+	return nil
+}
+
+// DeleteBlueprintFromWorkspace deletes the workspace copy of a blueprint
+// if the blueprint doesn't exist in the workspace it returns an error
+func (s *DBStore) DeleteBlueprintFromWorkspace(name string) error {
+	deleted, err := s.execCount(sqlDeleteWorkspace, name)
+	if err != nil {
+		return fmt.Errorf("error deleting workspace blueprint %s: %v", name, err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("Unknown blueprint: %s", name)
+	}
+	return nil
+}
+
+// TagBlueprint will tag the most recent commit
+// It will return an error if the blueprint doesn't exist
+func (s *DBStore) TagBlueprint(name string) error {
+	return s.transaction(func(tx *sql.Tx) error {
+		var rawBlueprint []byte
+		err := tx.QueryRow(sqlLockBlueprintCommitted, name).Scan(&rawBlueprint)
+		if err == sql.ErrNoRows {
+			return errors.New("Unknown blueprint")
+		}
+		if err != nil {
+			return fmt.Errorf("error querying blueprint %s: %v", name, err)
+		}
+
+		var latest string
+		var latestRevision sql.NullInt64
+		err = tx.QueryRow(sqlQueryLatestChange, name).Scan(&latest, &latestRevision)
+		if err == sql.ErrNoRows {
+			return errors.New("No commits for blueprint")
+		}
+		if err != nil {
+			return fmt.Errorf("error querying changes of blueprint %s: %v", name, err)
+		}
+
+		// If the most recent commit already has a revision, don't bump it
+		if latestRevision.Valid {
+			return nil
+		}
+
+		// Get the latest revision for this blueprint
+		var revision int
+		err = tx.QueryRow(sqlQueryLatestRevision, name).Scan(&revision)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("error querying revisions of blueprint %s: %v", name, err)
+		}
+
+		// Bump the revision (if there was none it will start at 1), and
+		// leave the rest of the commit as it is
+		_, err = tx.Exec(sqlSetRevision, name, latest, revision+1)
+		if err != nil {
+			return fmt.Errorf("error tagging blueprint %s: %v", name, err)
+		}
+		return nil
+	})
+}
+
+// ExportBlueprints returns all committed blueprints with their changes,
+// sorted by name. Changes in the workspace are not exported.
+func (s *DBStore) ExportBlueprints() []BlueprintHistory {
+	tx, err := s.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		panic(fmt.Errorf("error starting database transaction: %v", err))
+	}
+	defer rollback(tx)
+
+	histories := []BlueprintHistory{}
+	index := make(map[string]int)
+	rows, err := tx.Query(sqlQueryAllBlueprints)
+	if err != nil {
+		panic(fmt.Errorf("error querying blueprints: %v", err))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var rawBlueprint []byte
+		err = rows.Scan(&name, &rawBlueprint)
+		if err != nil {
+			panic(fmt.Errorf("error querying blueprints: %v", err))
+		}
+		index[name] = len(histories)
+		histories = append(histories, BlueprintHistory{
+			Blueprint: *mustUnmarshalBlueprint(rawBlueprint),
+			Changes:   []BlueprintChange{},
+		})
+	}
+	if err = rows.Err(); err != nil {
+		panic(fmt.Errorf("error querying blueprints: %v", err))
+	}
+
+	rows, err = tx.Query(sqlQueryAllChanges)
+	if err != nil {
+		panic(fmt.Errorf("error querying changes: %v", err))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var revision sql.NullInt64
+		var rawBlueprint []byte
+		var change BlueprintChange
+		err = rows.Scan(&name, &change.Commit, &change.Message, &revision, &change.Timestamp, &rawBlueprint)
+		if err != nil {
+			panic(fmt.Errorf("error querying changes: %v", err))
+		}
+		i, ok := index[name]
+		if !ok {
+			continue
+		}
+		if revision.Valid {
+			r := int(revision.Int64)
+			change.Revision = &r
+		}
+		if rawBlueprint != nil {
+			change.Blueprint = mustUnmarshalBlueprint(rawBlueprint)
+		}
+		histories[i].Changes = append(histories[i].Changes, change)
+	}
+	if err = rows.Err(); err != nil {
+		panic(fmt.Errorf("error querying changes: %v", err))
+	}
+
+	rows, err = tx.Query(sqlQueryAllTags)
+	if err != nil {
+		panic(fmt.Errorf("error querying tags: %v", err))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, tag, commit string
+		err = rows.Scan(&name, &tag, &commit)
+		if err != nil {
+			panic(fmt.Errorf("error querying tags: %v", err))
+		}
+		i, ok := index[name]
+		if !ok {
+			continue
+		}
+		if histories[i].Tags == nil {
+			histories[i].Tags = make(map[string]string)
+		}
+		histories[i].Tags[tag] = commit
+	}
+	if err = rows.Err(); err != nil {
+		panic(fmt.Errorf("error querying tags: %v", err))
+	}
+
+	return histories
+}
+
+// ImportBlueprints replaces the blueprints of `histories`, including their
+// changes, with the imported ones, and drops their workspace copies. Other
+// blueprints are not touched. Nothing is imported if any of the blueprints is
+// invalid.
+func (s *DBStore) ImportBlueprints(histories []BlueprintHistory) error {
+	err := validateBlueprintHistories(histories)
+	if err != nil {
+		return err
+	}
+
+	return s.transaction(func(tx *sql.Tx) error {
+		for _, history := range histories {
+			name := history.Blueprint.Name
+
+			for _, query := range []string{sqlDeleteWorkspace, sqlDeleteChanges, sqlDeleteTags} {
+				_, err := tx.Exec(query, name)
+				if err != nil {
+					return fmt.Errorf("error replacing blueprint %s: %v", name, err)
+				}
+			}
+
+			err := execJSON(tx, sqlUpsertBlueprint, name, history.Blueprint)
+			if err != nil {
+				return fmt.Errorf("error saving blueprint %s: %v", name, err)
+			}
+
+			for _, change := range history.Changes {
+				imported := blueprint.Change{
+					Commit:    change.Commit,
+					Message:   change.Message,
+					Revision:  change.Revision,
+					Timestamp: change.Timestamp,
+				}
+				if change.Blueprint != nil {
+					imported.Blueprint = *change.Blueprint
+				}
+				err = insertChange(tx, name, imported)
+				if err != nil {
+					return fmt.Errorf("error saving change %s of blueprint %s: %v", change.Commit, name, err)
+				}
+			}
+
+			for tag, commit := range history.Tags {
+				_, err = tx.Exec(sqlUpsertTag, name, tag, commit)
+				if err != nil {
+					return fmt.Errorf("error saving tag %s of blueprint %s: %v", tag, name, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// GetCompose returns the compose with `id`. Composes with an image type
+// which isn't supported by the arch of the store are treated as if they
+// didn't exist, like FileStore ignores them when loading its state.
+func (s *DBStore) GetCompose(id uuid.UUID) (Compose, bool) {
+	var rawCompose []byte
+	err := s.db.QueryRow(sqlQueryCompose, id).Scan(&rawCompose)
+	if err == sql.ErrNoRows {
+		return Compose{}, false
+	}
+	if err != nil {
+		panic(fmt.Errorf("error querying compose %s: %v", id, err))
+	}
+
+	compose, err := s.unmarshalCompose(rawCompose)
+	if err != nil {
+		return Compose{}, false
+	}
+	return compose, true
+}
+
+// GetAllComposes returns all composes present in this store as a dictionary
+// with compose UUIDs as keys
+func (s *DBStore) GetAllComposes() map[uuid.UUID]Compose {
+	return s.queryComposes(sqlQueryAllComposes)
+}
+
+// ListComposes returns the composes selected by `filter`
+func (s *DBStore) ListComposes(filter ComposeFilter) map[uuid.UUID]Compose {
+	var since, until sql.NullTime
+	if !filter.Since.IsZero() {
+		since = sql.NullTime{Time: filter.Since, Valid: true}
+	}
+	if !filter.Until.IsZero() {
+		until = sql.NullTime{Time: filter.Until, Valid: true}
+	}
+	return s.queryComposes(sqlQueryComposes, filter.BlueprintSearch, since, until)
+}
+
+// queryComposes returns the composes which `query` selects as (id, compose)
+// rows, skipping those with an unsupported image type
+func (s *DBStore) queryComposes(query string, args ...interface{}) map[uuid.UUID]Compose {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		panic(fmt.Errorf("error querying composes: %v", err))
+	}
+	defer rows.Close()
+
+	composes := make(map[uuid.UUID]Compose)
+	for rows.Next() {
+		var id uuid.UUID
+		var rawCompose []byte
+		err = rows.Scan(&id, &rawCompose)
+		if err != nil {
+			panic(fmt.Errorf("error querying composes: %v", err))
+		}
+		compose, err := s.unmarshalCompose(rawCompose)
+		if err != nil {
+			continue
+		}
+		composes[id] = compose
+	}
+	if err = rows.Err(); err != nil {
+		panic(fmt.Errorf("error querying composes: %v", err))
+	}
+
+	return composes
+}
+
+func (s *DBStore) PushCompose(composeID uuid.UUID,
+	manifest distro.Manifest,
+	imageType distro.ImageType,
+	bp *blueprint.Blueprint,
+	size uint64,
+	targets []*target.Target,
+	jobId uuid.UUID,
+	packages []rpmmd.PackageSpec) error {
+
+	compose := newCompose(manifest, imageType, bp, size, targets, packages)
+	compose.ImageBuild.JobID = jobId
+
+	inserted, err := s.execJSONCount(sqlInsertCompose, composeID, newComposeV0(compose))
+	if err != nil {
+		return fmt.Errorf("error saving compose %s: %v", composeID, err)
+	}
+	if inserted == 0 {
+		panic("a compose with this id already exists")
+	}
+	return nil
+}
+
+// PushTestCompose is used for testing
+// Set testSuccess to create a fake successful compose, otherwise it will create a failed compose
+// It does not actually run a compose job
+func (s *DBStore) PushTestCompose(composeID uuid.UUID,
+	manifest distro.Manifest,
+	imageType distro.ImageType,
+	bp *blueprint.Blueprint,
+	size uint64,
+	targets []*target.Target,
+	testSuccess bool,
+	packages []rpmmd.PackageSpec) error {
+
+	compose := newTestCompose(manifest, imageType, bp, size, targets, testSuccess, packages)
+
+	err := execJSON(s.db, sqlUpsertCompose, composeID, newComposeV0(compose))
+	if err != nil {
+		return fmt.Errorf("error saving compose %s: %v", composeID, err)
+	}
+	return nil
+}
+
+// DeleteCompose deletes the compose from the database
+func (s *DBStore) DeleteCompose(id uuid.UUID) error {
+	deleted, err := s.execCount(sqlDeleteCompose, id)
+	if err != nil {
+		return fmt.Errorf("error deleting compose %s: %v", id, err)
+	}
+	if deleted == 0 {
+		return &NotFoundError{}
+	}
+	return nil
+}
+
+// PushSource stores a SourceConfig under the ID `key`
+func (s *DBStore) PushSource(key string, source SourceConfig) {
+	err := upsertSource(s.db, key, source)
+	if err != nil {
+		panic(fmt.Errorf("error saving source %s: %v", key, err))
+	}
+}
+
+// DeleteSourceByName removes a SourceConfig using the .Name field
+func (s *DBStore) DeleteSourceByName(name string) {
+	_, err := s.db.Exec(sqlDeleteSourceByName, name)
+	if err != nil {
+		panic(fmt.Errorf("error deleting source %s: %v", name, err))
+	}
+}
+
+// DeleteSourceByID removes a SourceConfig using the ID
+func (s *DBStore) DeleteSourceByID(key string) {
+	_, err := s.db.Exec(sqlDeleteSourceByID, key)
+	if err != nil {
+		panic(fmt.Errorf("error deleting source %s: %v", key, err))
+	}
+}
+
+// ListSourcesByName returns the repo source names
+// Name is different than Id, it can be a full description of the repo
+func (s *DBStore) ListSourcesByName() []string {
+	return s.queryStrings("sources", sqlListSourceNames)
+}
+
+// ListSourcesById returns the repo source id
+// Id is a short identifier for the repo, not a full name description
+func (s *DBStore) ListSourcesById() []string {
+	return s.queryStrings("sources", sqlListSourceIDs)
+}
+
+func (s *DBStore) GetSource(name string) *SourceConfig {
+	_, source, err := scanSource(s.db.QueryRow(sqlQuerySource, name))
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		panic(fmt.Errorf("error querying source %s: %v", name, err))
+	}
+	return &source
+}
+
+// GetAllSourcesByName returns the sources using the repo name as the key
+func (s *DBStore) GetAllSourcesByName() map[string]SourceConfig {
+	sources := make(map[string]SourceConfig)
+	for _, source := range s.querySources() {
+		sources[source.Name] = source
+	}
+	return sources
+}
+
+// GetAllSourcesByID returns the sources using the repo id as the key
+func (s *DBStore) GetAllSourcesByID() map[string]SourceConfig {
+	return s.querySources()
+}
+
+func (s *DBStore) querySources() map[string]SourceConfig {
+	rows, err := s.db.Query(sqlQueryAllSources)
+	if err != nil {
+		panic(fmt.Errorf("error querying sources: %v", err))
+	}
+	defer rows.Close()
+
+	sources := make(map[string]SourceConfig)
+	for rows.Next() {
+		id, source, err := scanSource(rows)
+		if err != nil {
+			panic(fmt.Errorf("error querying sources: %v", err))
+		}
+		sources[id] = source
+	}
+	if err = rows.Err(); err != nil {
+		panic(fmt.Errorf("error querying sources: %v", err))
+	}
+	return sources
+}
+
+// PushSchedule stores a new compose schedule under `id`.
+func (s *DBStore) PushSchedule(id uuid.UUID, schedule ComposeSchedule) error {
+	err := execJSON(s.db, sqlUpsertSchedule, id, scheduleV0(schedule))
+	if err != nil {
+		return fmt.Errorf("error saving schedule %s: %v", id, err)
+	}
+	return nil
+}
+
+// GetAllSchedules returns all compose schedules.
+func (s *DBStore) GetAllSchedules() map[uuid.UUID]ComposeSchedule {
+	rows, err := s.db.Query(sqlQueryAllSchedules)
+	if err != nil {
+		panic(fmt.Errorf("error querying schedules: %v", err))
+	}
+	defer rows.Close()
+
+	schedules := make(map[uuid.UUID]ComposeSchedule)
+	for rows.Next() {
+		var id uuid.UUID
+		var rawSchedule []byte
+		err = rows.Scan(&id, &rawSchedule)
+		if err != nil {
+			panic(fmt.Errorf("error querying schedules: %v", err))
+		}
+		var schedule scheduleV0
+		err = json.Unmarshal(rawSchedule, &schedule)
+		if err != nil {
+			panic(fmt.Errorf("error decoding schedule %s: %v", id, err))
+		}
+		schedules[id] = ComposeSchedule(schedule)
+	}
+	if err = rows.Err(); err != nil {
+		panic(fmt.Errorf("error querying schedules: %v", err))
+	}
+
+	return schedules
+}
+
+// UpdateSchedule changes the compose schedule `id` with `f`. The schedule is
+// locked meanwhile, so that composer instances which share the database
+// don't overwrite each other's changes. It returns a NotFoundError if the
+// schedule was deleted in the meantime.
+func (s *DBStore) UpdateSchedule(id uuid.UUID, f func(*ComposeSchedule)) error {
+	return s.transaction(func(tx *sql.Tx) error {
+		var rawSchedule []byte
+		err := tx.QueryRow(sqlLockSchedule, id).Scan(&rawSchedule)
+		if err == sql.ErrNoRows {
+			return &NotFoundError{"schedule does not exist"}
+		}
+		if err != nil {
+			return fmt.Errorf("error querying schedule %s: %v", id, err)
+		}
+
+		var scheduleStruct scheduleV0
+		err = json.Unmarshal(rawSchedule, &scheduleStruct)
+		if err != nil {
+			return fmt.Errorf("error decoding schedule %s: %v", id, err)
+		}
+		schedule := ComposeSchedule(scheduleStruct)
+		f(&schedule)
+
+		err = execJSON(tx, sqlUpsertSchedule, id, scheduleV0(schedule))
+		if err != nil {
+			return fmt.Errorf("error saving schedule %s: %v", id, err)
+		}
+		return nil
+	})
+}
+
+// DeleteSchedule deletes the compose schedule `id`.
+func (s *DBStore) DeleteSchedule(id uuid.UUID) error {
+	deleted, err := s.execCount(sqlDeleteSchedule, id)
+	if err != nil {
+		return fmt.Errorf("error deleting schedule %s: %v", id, err)
+	}
+	if deleted == 0 {
+		return &NotFoundError{"schedule does not exist"}
+	}
+	return nil
+}
+
+// transaction runs `f` in a database transaction, which is committed if `f`
+// doesn't return an error.
+func (s *DBStore) transaction(f func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting database transaction: %v", err)
+	}
+	defer rollback(tx)
+
+	err = f(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing database transaction: %v", err)
+	}
+	return nil
+}
+
+// execCount executes `query` and returns the number of affected rows
+func (s *DBStore) execCount(query string, args ...interface{}) (int64, error) {
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// execJSONCount executes `query` with `key` and the JSON encoding of `value`,
+// and returns the number of affected rows
+func (s *DBStore) execJSONCount(query string, key interface{}, value interface{}) (int64, error) {
+	rawValue, err := json.Marshal(value)
+	if err != nil {
+		return 0, err
+	}
+	return s.execCount(query, key, string(rawValue))
+}
+
+func (s *DBStore) queryStrings(what string, query string) []string {
+	return queryStrings(s.db, what, query)
+}
+
+func (s *DBStore) unmarshalCompose(rawCompose []byte) (Compose, error) {
+	var composeStruct composeV0
+	err := json.Unmarshal(rawCompose, &composeStruct)
+	if err != nil {
+		return Compose{}, err
+	}
+	return newComposeFromV0(composeStruct, s.arch)
+}
+
+// Common interface of *sql.DB and *sql.Tx
+type dbExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Common interface of *sql.Row and *sql.Rows
+type dbScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// execJSON executes `query` with `key` and the JSON encoding of `value`
+func execJSON(db dbExecutor, query string, key interface{}, value interface{}) error {
+	rawValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(query, key, string(rawValue))
+	return err
+}
+
+func queryStrings(db dbExecutor, what string, query string) []string {
+	rows, err := db.Query(query)
+	if err != nil {
+		panic(fmt.Errorf("error querying %s: %v", what, err))
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			panic(fmt.Errorf("error querying %s: %v", what, err))
+		}
+		values = append(values, value)
+	}
+	if err = rows.Err(); err != nil {
+		panic(fmt.Errorf("error querying %s: %v", what, err))
+	}
+	return values
+}
+
+func insertChange(db dbExecutor, name string, change blueprint.Change) error {
+	var rawBlueprint interface{}
+	// older versions didn't save the blueprints of commits
+	if change.Blueprint.Name != "" {
+		data, err := json.Marshal(change.Blueprint)
+		if err != nil {
+			return err
+		}
+		rawBlueprint = string(data)
+	}
+
+	var revision interface{}
+	if change.Revision != nil {
+		revision = *change.Revision
+	}
+
+	_, err := db.Exec(sqlInsertChange, name, change.Commit, change.Message, revision, change.Timestamp, rawBlueprint)
+	return err
+}
+
+func scanChange(row dbScanner) (blueprint.Change, error) {
+	var change blueprint.Change
+	var revision sql.NullInt64
+	var rawBlueprint []byte
+	err := row.Scan(&change.Commit, &change.Message, &revision, &change.Timestamp, &rawBlueprint)
+	if err != nil {
+		return blueprint.Change{}, err
+	}
+	if revision.Valid {
+		r := int(revision.Int64)
+		change.Revision = &r
+	}
+	if rawBlueprint != nil {
+		change.Blueprint = *mustUnmarshalBlueprint(rawBlueprint)
+	}
+	return change, nil
+}
+
+func upsertSource(db dbExecutor, id string, source SourceConfig) error {
+	_, err := db.Exec(sqlUpsertSource, id, source.Name, source.Type, source.URL, source.CheckGPG, source.CheckSSL, source.System)
+	return err
+}
+
+func scanSource(row dbScanner) (string, SourceConfig, error) {
+	var id string
+	var source SourceConfig
+	err := row.Scan(&id, &source.Name, &source.Type, &source.URL, &source.CheckGPG, &source.CheckSSL, &source.System)
+	return id, source, err
+}
+
+// Blueprints are only written by the store, so that they can always be
+// unmarshaled
+func mustUnmarshalBlueprint(rawBlueprint []byte) *blueprint.Blueprint {
+	var bp blueprint.Blueprint
+	err := json.Unmarshal(rawBlueprint, &bp)
+	if err != nil {
+		panic(fmt.Errorf("error unmarshaling blueprint: %v", err))
+	}
+	return &bp
+}
+
+func rollback(tx *sql.Tx) {
+	// Rollback() fails when the transaction was already committed, which
+	// is fine
+	_ = tx.Rollback()
+}
@@ -13,7 +13,7 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/target"
 )
 
-func FixtureBase() *Store {
+func FixtureBase() *FileStore {
 	var bName = "test"
 	var b = blueprint.Blueprint{
 		Name:           bName,
@@ -63,7 +63,10 @@ func FixtureBase() *Store {
 	if err != nil {
 		panic(fmt.Sprintf("failed to create a manifest: %v", err))
 	}
-	s := New(nil, arch, nil)
+	s, err := New(nil, arch, nil)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create a store: %v", err))
+	}
 
 	pkgs := []rpmmd.PackageSpec{
 		{
@@ -148,7 +151,7 @@ func FixtureBase() *Store {
 
 	return s
 }
-func FixtureFinished() *Store {
+func FixtureFinished() *FileStore {
 	var bName = "test"
 	var b = blueprint.Blueprint{
 		Name:           bName,
@@ -198,7 +201,10 @@ func FixtureFinished() *Store {
 	if err != nil {
 		panic(fmt.Sprintf("failed to create a manifest: %v", err))
 	}
-	s := New(nil, arch, nil)
+	s, err := New(nil, arch, nil)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create a store: %v", err))
+	}
 
 	pkgs := []rpmmd.PackageSpec{
 		{
@@ -270,7 +276,7 @@ func FixtureFinished() *Store {
 	return s
 }
 
-func FixtureEmpty() *Store {
+func FixtureEmpty() *FileStore {
 	var bName = "test"
 	var b = blueprint.Blueprint{
 		Name:           bName,
@@ -287,7 +293,10 @@ func FixtureEmpty() *Store {
 		panic(fmt.Sprintf("failed to get architecture %s for a test distro: %v", test_distro.TestArchName, err))
 	}
 
-	s := New(nil, arch, nil)
+	s, err := New(nil, arch, nil)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create a store: %v", err))
+	}
 
 	s.blueprints[bName] = b
 
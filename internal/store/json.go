@@ -1,6 +1,7 @@
 package store
 
 import (
+	"encoding/json"
 	"errors"
 	"log"
 	"sort"
@@ -15,12 +16,16 @@ import (
 )
 
 type storeV0 struct {
+	// missing in stores saved by older versions, which are version 0
+	Version    int          `json:"version"`
 	Blueprints blueprintsV0 `json:"blueprints"`
 	Workspace  workspaceV0  `json:"workspace"`
 	Composes   composesV0   `json:"composes"`
 	Sources    sourcesV0    `json:"sources"`
 	Changes    changesV0    `json:"changes"`
 	Commits    commitsV0    `json:"commits"`
+	Schedules  schedulesV0  `json:"schedules,omitempty"`
+	Tags       tagsV0       `json:"tags,omitempty"`
 }
 
 type blueprintsV0 map[string]blueprint.Blueprint
@@ -71,13 +76,27 @@ type changeV0 struct {
 	Message   string `json:"message"`
 	Revision  *int   `json:"revision"`
 	Timestamp string `json:"timestamp"`
-	// BUG: We are currently not (un)marshalling the Blueprint field.
+	// missing in changes saved by older versions
+	Blueprint *blueprint.Blueprint `json:"blueprint,omitempty"`
 }
 
 type changesV0 map[string]map[string]changeV0
 
 type commitsV0 map[string][]string
 
+type scheduleV0 struct {
+	Request       json.RawMessage `json:"request"`
+	Cron          string          `json:"cron,omitempty"`
+	NextRun       time.Time       `json:"next_run"`
+	Created       time.Time       `json:"created"`
+	LastRun       time.Time       `json:"last_run"`
+	LastComposeID uuid.UUID       `json:"last_compose_id"`
+	LastError     string          `json:"last_error,omitempty"`
+}
+
+type schedulesV0 map[uuid.UUID]scheduleV0
+type tagsV0 map[string]map[string]string
+
 func newBlueprintsFromV0(blueprintsStruct blueprintsV0) map[string]blueprint.Blueprint {
 	blueprints := make(map[string]blueprint.Blueprint)
 	for name, blueprint := range blueprintsStruct {
@@ -177,12 +196,16 @@ func newChangesFromV0(changesStruct changesV0) map[string]map[string]blueprint.C
 	for name, commitsStruct := range changesStruct {
 		commits := make(map[string]blueprint.Change)
 		for commitID, change := range commitsStruct {
-			commits[commitID] = blueprint.Change{
+			c := blueprint.Change{
 				Commit:    change.Commit,
 				Message:   change.Message,
 				Revision:  change.Revision,
 				Timestamp: change.Timestamp,
 			}
+			if change.Blueprint != nil {
+				c.Blueprint = *change.Blueprint
+			}
+			commits[commitID] = c
 		}
 		changes[name] = commits
 	}
@@ -232,15 +255,50 @@ func newCommitsFromV0(commitsMapStruct commitsV0, changesMapStruct changesV0) ma
 	return commitsMap
 }
 
-func newStoreFromV0(storeStruct storeV0, arch distro.Arch, log *log.Logger) *Store {
-	return &Store{
+func newSchedulesFromV0(schedulesStruct schedulesV0) map[uuid.UUID]ComposeSchedule {
+	schedules := make(map[uuid.UUID]ComposeSchedule)
+	for id, schedule := range schedulesStruct {
+		schedules[id] = ComposeSchedule(schedule)
+	}
+	return schedules
+}
+
+func newStoreFromV0(storeStruct storeV0, arch distro.Arch, log *log.Logger) *FileStore {
+	composes := newComposesFromV0(storeStruct.Composes, arch, log)
+	return &FileStore{
 		blueprints:        newBlueprintsFromV0(storeStruct.Blueprints),
 		workspace:         newWorkspaceFromV0(storeStruct.Workspace),
-		composes:          newComposesFromV0(storeStruct.Composes, arch, log),
+		composes:          composes,
+		unloadedComposes:  newUnloadedComposesV0(storeStruct.Composes, composes),
 		sources:           newSourceConfigsFromV0(storeStruct.Sources),
 		blueprintsChanges: newChangesFromV0(storeStruct.Changes),
 		blueprintsCommits: newCommitsFromV0(storeStruct.Commits, storeStruct.Changes),
+		schedules:         newSchedulesFromV0(storeStruct.Schedules),
+		blueprintsTags:    newTagsFromV0(storeStruct.Tags),
+	}
+}
+
+// newUnloadedComposesV0 returns the composes of `composesStruct` which
+// couldn't be loaded into `composes`
+func newUnloadedComposesV0(composesStruct composesV0, composes map[uuid.UUID]Compose) composesV0 {
+	unloaded := make(composesV0)
+	for composeID, composeStruct := range composesStruct {
+		if _, loaded := composes[composeID]; !loaded {
+			unloaded[composeID] = composeStruct
+		}
+	}
+	return unloaded
+}
+
+func newTagsFromV0(tagsStruct tagsV0) map[string]map[string]string {
+	tags := make(map[string]map[string]string)
+	for name, bpTags := range tagsStruct {
+		tags[name] = make(map[string]string)
+		for tag, commit := range bpTags {
+			tags[name][tag] = commit
+		}
 	}
+	return tags
 }
 
 func newBlueprintsV0(blueprints map[string]blueprint.Blueprint) blueprintsV0 {
@@ -306,12 +364,17 @@ func newChangesV0(changes map[string]map[string]blueprint.Change) changesV0 {
 	for name, commits := range changes {
 		commitsStruct := make(map[string]changeV0)
 		for commitID, change := range commits {
-			commitsStruct[commitID] = changeV0{
+			c := changeV0{
 				Commit:    change.Commit,
 				Message:   change.Message,
 				Revision:  change.Revision,
 				Timestamp: change.Timestamp,
 			}
+			if change.Blueprint.Name != "" {
+				bp := change.Blueprint.DeepCopy()
+				c.Blueprint = &bp
+			}
+			commitsStruct[commitID] = c
 		}
 		changesStruct[name] = commitsStruct
 	}
@@ -326,43 +389,88 @@ func newCommitsV0(commits map[string][]string) commitsV0 {
 	return commitsStruct
 }
 
-func (store *Store) toStoreV0() *storeV0 {
+func newSchedulesV0(schedules map[uuid.UUID]ComposeSchedule) schedulesV0 {
+	schedulesStruct := make(schedulesV0)
+	for id, schedule := range schedules {
+		schedulesStruct[id] = scheduleV0(schedule)
+	}
+	return schedulesStruct
+}
+
+func (store *FileStore) toStoreV0() *storeV0 {
+	composes := newComposesV0(store.composes)
+	for composeID, composeStruct := range store.unloadedComposes {
+		composes[composeID] = composeStruct
+	}
+
 	return &storeV0{
+		Version:    storeVersion,
 		Blueprints: newBlueprintsV0(store.blueprints),
 		Workspace:  newWorkspaceV0(store.workspace),
-		Composes:   newComposesV0(store.composes),
+		Composes:   composes,
 		Sources:    newSourcesV0(store.sources),
 		Changes:    newChangesV0(store.blueprintsChanges),
 		Commits:    newCommitsV0(store.blueprintsCommits),
+		Schedules:  newSchedulesV0(store.schedules),
+		Tags:       newTagsV0(store.blueprintsTags),
+	}
+}
+
+func newTagsV0(tags map[string]map[string]string) tagsV0 {
+	tagsStruct := make(tagsV0)
+	for name, bpTags := range tags {
+		if len(bpTags) == 0 {
+			continue
+		}
+		tagsStruct[name] = make(map[string]string)
+		for tag, commit := range bpTags {
+			tagsStruct[name][tag] = commit
+		}
 	}
+	return tagsStruct
 }
 
 var imageTypeCompatMapping = map[string]string{
-	"vhd":                 "Azure",
-	"ami":                 "AWS",
-	"liveiso":             "LiveISO",
-	"openstack":           "OpenStack",
-	"qcow2":               "qcow2",
-	"vmdk":                "VMWare",
-	"ext4-filesystem":     "Raw-filesystem",
-	"partitioned-disk":    "Partitioned-disk",
-	"tar":                 "Tar",
-	"fedora-iot-commit":   "fedora-iot-commit",
-	"rhel-edge-commit":    "rhel-edge-commit",
-	"rhel-edge-container": "rhel-edge-container",
-	"rhel-edge-installer": "rhel-edge-installer",
-	"edge-commit":         "edge-commit",
-	"edge-container":      "edge-container",
-	"edge-installer":      "edge-installer",
-	"tar-installer":       "tar-installer",
-	"test_type":           "test_type",         // used only in json_test.go
-	"test_type_invalid":   "test_type_invalid", // used only in json_test.go
+	"vhd":                       "Azure",
+	"ami":                       "AWS",
+	"liveiso":                   "LiveISO",
+	"openstack":                 "OpenStack",
+	"qcow2":                     "qcow2",
+	"vmdk":                      "VMWare",
+	"ext4-filesystem":           "Raw-filesystem",
+	"partitioned-disk":          "Partitioned-disk",
+	"tar":                       "Tar",
+	"fedora-iot-commit":         "fedora-iot-commit",
+	"rhel-edge-commit":          "rhel-edge-commit",
+	"rhel-edge-container":       "rhel-edge-container",
+	"rhel-edge-installer":       "rhel-edge-installer",
+	"edge-commit":               "edge-commit",
+	"edge-container":            "edge-container",
+	"edge-installer":            "edge-installer",
+	"edge-simplified-installer": "edge-simplified-installer",
+	"image-installer":           "image-installer",
+	"live-iso":                  "live-iso",
+	"raw-xz":                    "raw-xz",
+	"minimal-raw":               "minimal-raw",
+	"gce":                       "gce",
+	"gce-rhui":                  "gce-rhui",
+	"azure-rhui":                "azure-rhui",
+	"azure-sap":                 "azure-sap",
+	"vagrant-libvirt":           "vagrant-libvirt",
+	"vagrant-virtualbox":        "vagrant-virtualbox",
+	"wsl":                       "wsl",
+	"container":                 "container",
+	"test_type":                 "test_type",         // used only in json_test.go
+	"test_type_invalid":         "test_type_invalid", // used only in json_test.go
 }
 
+// imageTypeToCompatString returns the name under which composes of `imgType`
+// are stored. Image types of distributions which are loaded from definition
+// files have no mapping, they are stored under their own names.
 func imageTypeToCompatString(imgType distro.ImageType) string {
 	imgTypeString, exists := imageTypeCompatMapping[imgType.Name()]
 	if !exists {
-		panic("No mapping exists for " + imgType.Name())
+		return imgType.Name()
 	}
 	return imgTypeString
 }
@@ -377,5 +485,13 @@ func imageTypeFromCompatString(input string, arch distro.Arch) distro.ImageType
 			return imgType
 		}
 	}
-	return nil
+
+	if _, mapped := imageTypeCompatMapping[input]; mapped {
+		return nil
+	}
+	imgType, err := arch.GetImageType(input)
+	if err != nil {
+		return nil
+	}
+	return imgType
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/common"
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/distro/fedora33"
+	"github.com/osbuild/osbuild-composer/internal/distro/generic"
 	"github.com/osbuild/osbuild-composer/internal/distro/test_distro"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 	"github.com/osbuild/osbuild-composer/internal/target"
@@ -112,6 +113,26 @@ func Test_imageTypeFromCompatString(t *testing.T) {
 	}
 }
 
+// Image types of distributions loaded from definition files are stored under
+// their own names.
+func Test_imageTypeCompatStringGeneric(t *testing.T) {
+	d := generic.New(generic.Definition{
+		Name:             "toucan-1",
+		ModulePlatformID: "platform:tc1",
+		Arches:           map[string]generic.ArchDefinition{"x86_64": {}},
+		ImageTypes: map[string]generic.ImageTypeDefinition{
+			"toucan-tar": {Arches: []string{"x86_64"}, Filename: "root.tar", MIMEType: "application/x-tar", Format: "tar"},
+		},
+	})
+	arch, err := d.GetArch("x86_64")
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType("toucan-tar")
+	require.NoError(t, err)
+
+	require.Equal(t, "toucan-tar", imageTypeToCompatString(imageType))
+	require.Equal(t, imageType, imageTypeFromCompatString("toucan-tar", arch))
+}
+
 func TestMarshalEmpty(t *testing.T) {
 	d := test_distro.New()
 	arch, err := d.GetArch(test_distro.TestArchName)
@@ -158,18 +179,21 @@ func TestStore_toStoreV0(t *testing.T) {
 			name:   "empty",
 			fields: fields{},
 			want: &storeV0{
+				Version:    storeVersion,
 				Blueprints: make(blueprintsV0),
 				Workspace:  make(workspaceV0),
 				Composes:   make(composesV0),
 				Sources:    make(sourcesV0),
 				Changes:    make(changesV0),
 				Commits:    make(commitsV0),
+				Schedules:  make(schedulesV0),
+				Tags:       make(tagsV0),
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			store := &Store{
+			store := &FileStore{
 				blueprints:        tt.fields.blueprints,
 				workspace:         tt.fields.workspace,
 				composes:          tt.fields.composes,
@@ -192,11 +216,13 @@ func Test_newStoreFromV0(t *testing.T) {
 
 	testDistro := test_distro.New()
 	testArch, _ := testDistro.GetArch(test_distro.TestArchName)
+	emptyStore, err := New(nil, testArch, nil)
+	require.NoError(t, err)
 
 	tests := []struct {
 		name string
 		args args
-		want *Store
+		want *FileStore
 	}{
 		{
 			name: "empty",
@@ -204,7 +230,7 @@ func Test_newStoreFromV0(t *testing.T) {
 				storeStruct: storeV0{},
 				arch:        testArch,
 			},
-			want: New(nil, testArch, nil),
+			want: emptyStore,
 		},
 	}
 	for _, tt := range tests {
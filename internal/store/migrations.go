@@ -0,0 +1,79 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/osbuild/osbuild-composer/internal/jsondb"
+)
+
+// Version of the format in which the state is saved. Increase it when the
+// format changes in a way which older versions of composer can't read, and
+// append a migration from the previous version to storeMigrations.
+const storeVersion = 1
+
+// storeMigrations[i] converts the saved state from version i to version i+1.
+// The state is passed by its top-level keys, so that migrations don't depend
+// on the types of the current version.
+var storeMigrations = []func(state map[string]json.RawMessage) error{
+	// version 1 only added the version number to the state
+	func(state map[string]json.RawMessage) error { return nil },
+}
+
+// readState reads the state `name` from `db` into `storeStruct`, migrating
+// it to the current version first. Before it is migrated, the state is
+// backed up to `<name>-v<version>-backup`. It is not read if it was saved by
+// a newer version of composer, because its changes would be lost the next
+// time the state is saved.
+func readState(db *jsondb.JSONDatabase, name string, storeStruct *storeV0) error {
+	var raw json.RawMessage
+	exists, err := db.Read(name, &raw)
+	if err != nil || !exists {
+		return err
+	}
+
+	var state map[string]json.RawMessage
+	err = json.Unmarshal(raw, &state)
+	if err != nil {
+		return fmt.Errorf("cannot parse state: %v", err)
+	}
+
+	var version int
+	if rawVersion, ok := state["version"]; ok {
+		err = json.Unmarshal(rawVersion, &version)
+		if err != nil {
+			return fmt.Errorf("cannot parse state version: %v", err)
+		}
+	}
+	if version > storeVersion {
+		return fmt.Errorf("state has version %d, which is newer than the supported version %d", version, storeVersion)
+	}
+	if version == storeVersion {
+		return json.Unmarshal(raw, storeStruct)
+	}
+
+	err = db.Write(fmt.Sprintf("%s-v%d-backup", name, version), raw)
+	if err != nil {
+		return fmt.Errorf("cannot back up state before migrating it: %v", err)
+	}
+
+	for ; version < storeVersion; version++ {
+		err = storeMigrations[version](state)
+		if err != nil {
+			return fmt.Errorf("cannot migrate state from version %d to %d: %v", version, version+1, err)
+		}
+	}
+	state["version"] = json.RawMessage(strconv.Itoa(storeVersion))
+
+	raw, err = json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(raw, storeStruct)
+	if err != nil {
+		return fmt.Errorf("cannot parse migrated state: %v", err)
+	}
+
+	return db.Write(name, raw)
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro/fedora33"
+	"github.com/osbuild/osbuild-composer/internal/distro/test_distro"
+)
+
+func TestReadStateMigrates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osbuild-composer-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	original, err := ioutil.ReadFile("test/state-v13.json")
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "state.json"), original, 0600))
+
+	arch, err := fedora33.New().GetArch("x86_64")
+	require.NoError(t, err)
+	s, err := New(&dir, arch, nil)
+	require.NoError(t, err)
+	require.Len(t, s.blueprints, 1)
+	require.Len(t, s.workspace, 1)
+
+	// the state of the old version is kept as it was
+	backup, err := ioutil.ReadFile(filepath.Join(dir, "state-v0-backup.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, string(original), string(backup))
+
+	var migrated map[string]json.RawMessage
+	state, err := ioutil.ReadFile(filepath.Join(dir, "state.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(state, &migrated))
+	require.Equal(t, strconv.Itoa(storeVersion), string(migrated["version"]))
+}
+
+func TestReadStateNewerVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osbuild-composer-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	newer := []byte(`{"version": ` + strconv.Itoa(storeVersion+1) + `, "blueprints": {}}`)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "state.json"), newer, 0600))
+
+	arch, err := test_distro.New().GetArch(test_distro.TestArchName)
+	require.NoError(t, err)
+	_, err = New(&dir, arch, nil)
+	require.Error(t, err)
+
+	state, err := ioutil.ReadFile(filepath.Join(dir, "state.json"))
+	require.NoError(t, err)
+	require.Equal(t, newer, state)
+}
+
+func TestUnloadedComposesAreKept(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osbuild-composer-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	testArch, err := test_distro.New().GetArch(test_distro.TestArchName)
+	require.NoError(t, err)
+	imageType, err := testArch.GetImageType(test_distro.TestImageTypeName)
+	require.NoError(t, err)
+
+	s, err := New(&dir, testArch, nil)
+	require.NoError(t, err)
+	id := uuid.New()
+	require.NoError(t, s.PushTestCompose(id, nil, imageType, &blueprint.Blueprint{Name: "test"}, 0, nil, true, nil))
+
+	// fedora33 doesn't have the image type of the compose
+	otherArch, err := fedora33.New().GetArch("x86_64")
+	require.NoError(t, err)
+	s, err = New(&dir, otherArch, nil)
+	require.NoError(t, err)
+	require.Empty(t, s.GetAllComposes())
+	require.NoError(t, s.PushBlueprint(blueprint.Blueprint{Name: "test"}, "message"))
+
+	s, err = New(&dir, testArch, nil)
+	require.NoError(t, err)
+	compose, exists := s.GetCompose(id)
+	require.True(t, exists)
+	require.Equal(t, "test", compose.Blueprint.Name)
+	require.Equal(t, []string{"test"}, s.ListBlueprints())
+}
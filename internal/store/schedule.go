@@ -0,0 +1,24 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A ComposeSchedule starts a compose at a later time, either once or
+// repeatedly.
+type ComposeSchedule struct {
+	// The compose request, as the weldr API received it
+	Request json.RawMessage
+	// The cron schedule of a repeating compose, empty for one-shot ones
+	Cron string
+	// When the compose is due next, zero if never again
+	NextRun time.Time
+
+	Created       time.Time
+	LastRun       time.Time
+	LastComposeID uuid.UUID
+	LastError     string
+}
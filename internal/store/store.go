@@ -27,15 +27,64 @@ import (
 // StoreDBName is the name under which to save the store to the underlying jsondb
 const StoreDBName = "state"
 
-// A Store contains all the persistent state of osbuild-composer, and is serialized
-// on every change, and deserialized on start.
-type Store struct {
+// A Store contains all the persistent state of osbuild-composer: blueprints
+// with their history, composes, sources, and compose schedules.
+type Store interface {
+	ListBlueprints() []string
+	GetBlueprint(name string) (*blueprint.Blueprint, bool)
+	GetBlueprintCommitted(name string) *blueprint.Blueprint
+	GetBlueprintChange(name string, commit string) (*blueprint.Change, error)
+	GetBlueprintRevision(name string, commit string) (*blueprint.Blueprint, error)
+	GetBlueprintTags(name string) map[string]string
+	SetBlueprintTag(name string, tag string, commit string) error
+	GetBlueprintChanges(name string) []blueprint.Change
+	PushBlueprint(bp blueprint.Blueprint, commitMsg string) error
+	PushBlueprintToWorkspace(bp blueprint.Blueprint) error
+	DeleteBlueprint(name string) error
+	DeleteBlueprintFromWorkspace(name string) error
+	TagBlueprint(name string) error
+	ExportBlueprints() []BlueprintHistory
+	ImportBlueprints(histories []BlueprintHistory) error
+
+	GetCompose(id uuid.UUID) (Compose, bool)
+	GetAllComposes() map[uuid.UUID]Compose
+	ListComposes(filter ComposeFilter) map[uuid.UUID]Compose
+	PushCompose(composeID uuid.UUID, manifest distro.Manifest, imageType distro.ImageType, bp *blueprint.Blueprint, size uint64, targets []*target.Target, jobId uuid.UUID, packages []rpmmd.PackageSpec) error
+	PushTestCompose(composeID uuid.UUID, manifest distro.Manifest, imageType distro.ImageType, bp *blueprint.Blueprint, size uint64, targets []*target.Target, testSuccess bool, packages []rpmmd.PackageSpec) error
+	DeleteCompose(id uuid.UUID) error
+
+	PushSource(key string, source SourceConfig)
+	DeleteSourceByName(name string)
+	DeleteSourceByID(key string)
+	ListSourcesByName() []string
+	ListSourcesById() []string
+	GetSource(name string) *SourceConfig
+	GetAllSourcesByName() map[string]SourceConfig
+	GetAllSourcesByID() map[string]SourceConfig
+
+	PushSchedule(id uuid.UUID, schedule ComposeSchedule) error
+	GetAllSchedules() map[uuid.UUID]ComposeSchedule
+	UpdateSchedule(id uuid.UUID, f func(*ComposeSchedule)) error
+	DeleteSchedule(id uuid.UUID) error
+}
+
+// A FileStore keeps the state in memory, and serializes it to a file in the
+// state directory on every change. It is deserialized on start.
+type FileStore struct {
 	blueprints        map[string]blueprint.Blueprint
 	workspace         map[string]blueprint.Blueprint
 	composes          map[uuid.UUID]Compose
 	sources           map[string]SourceConfig
 	blueprintsChanges map[string]map[string]blueprint.Change
 	blueprintsCommits map[string][]string
+	// names of the tags of each blueprint and the commits they point to
+	blueprintsTags map[string]map[string]string
+	schedules      map[uuid.UUID]ComposeSchedule
+
+	// composes which are saved in the state, but couldn't be loaded, e.g.
+	// because their image type isn't supported anymore. They are written
+	// back unchanged, so that they aren't lost.
+	unloadedComposes composesV0
 
 	mu       sync.RWMutex // protects all fields
 	stateDir *string
@@ -67,15 +116,19 @@ func (e *NoLocalTargetError) Error() string {
 	return e.message
 }
 
-func New(stateDir *string, arch distro.Arch, log *log.Logger) *Store {
+// New returns a FileStore with the state saved in `stateDir`, or one which
+// keeps the state only in memory if `stateDir` is nil. It returns an error if
+// the saved state can't be read, instead of starting with an empty state
+// which would overwrite it.
+func New(stateDir *string, arch distro.Arch, log *log.Logger) (*FileStore, error) {
 	var storeStruct storeV0
 	var db *jsondb.JSONDatabase
 
 	if stateDir != nil {
 		db = jsondb.New(*stateDir, 0600)
-		_, err := db.Read(StoreDBName, &storeStruct)
-		if err != nil && log != nil {
-			log.Fatalf("cannot read state: %v", err)
+		err := readState(db, StoreDBName, &storeStruct)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read state: %v", err)
 		}
 	}
 
@@ -84,7 +137,7 @@ func New(stateDir *string, arch distro.Arch, log *log.Logger) *Store {
 	store.stateDir = stateDir
 	store.db = db
 
-	return store
+	return store, nil
 }
 
 func randomSHA1String() (string, error) {
@@ -103,7 +156,7 @@ func randomSHA1String() (string, error) {
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
-func (s *Store) change(f func() error) error {
+func (s *FileStore) change(f func() error) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -119,7 +172,7 @@ func (s *Store) change(f func() error) error {
 	return result
 }
 
-func (s *Store) ListBlueprints() []string {
+func (s *FileStore) ListBlueprints() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -132,7 +185,7 @@ func (s *Store) ListBlueprints() []string {
 	return names
 }
 
-func (s *Store) GetBlueprint(name string) (*blueprint.Blueprint, bool) {
+func (s *FileStore) GetBlueprint(name string) (*blueprint.Blueprint, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -148,7 +201,7 @@ func (s *Store) GetBlueprint(name string) (*blueprint.Blueprint, bool) {
 	return &bp, inWorkspace
 }
 
-func (s *Store) GetBlueprintCommitted(name string) *blueprint.Blueprint {
+func (s *FileStore) GetBlueprintCommitted(name string) *blueprint.Blueprint {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -160,15 +213,19 @@ func (s *Store) GetBlueprintCommitted(name string) *blueprint.Blueprint {
 	return &bp
 }
 
-// GetBlueprintChange returns a specific change to a blueprint
+// GetBlueprintChange returns a specific change to a blueprint, by its commit
+// or the name of a tag pointing to it
 // If the blueprint or change do not exist then an error is returned
-func (s *Store) GetBlueprintChange(name string, commit string) (*blueprint.Change, error) {
+func (s *FileStore) GetBlueprintChange(name string, commit string) (*blueprint.Change, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	if _, ok := s.blueprintsChanges[name]; !ok {
 		return nil, errors.New("Unknown blueprint")
 	}
+	if tagged, ok := s.blueprintsTags[name][commit]; ok {
+		commit = tagged
+	}
 	change, ok := s.blueprintsChanges[name][commit]
 	if !ok {
 		return nil, errors.New("Unknown commit")
@@ -176,8 +233,60 @@ func (s *Store) GetBlueprintChange(name string, commit string) (*blueprint.Chang
 	return &change, nil
 }
 
+// GetBlueprintRevision returns the blueprint as it was saved in a commit,
+// which is given by its id or the name of a tag pointing to it
+func (s *FileStore) GetBlueprintRevision(name string, commit string) (*blueprint.Blueprint, error) {
+	change, err := s.GetBlueprintChange(name, commit)
+	if err != nil {
+		return nil, err
+	}
+	return changeBlueprint(change)
+}
+
+// changeBlueprint returns a copy of the blueprint saved in `change`
+func changeBlueprint(change *blueprint.Change) (*blueprint.Blueprint, error) {
+	// older versions didn't save the blueprints of commits
+	if change.Blueprint.Name == "" {
+		return nil, fmt.Errorf("Blueprint of commit %s was not saved", change.Commit)
+	}
+	bp := change.Blueprint.DeepCopy()
+	return &bp, nil
+}
+
+// GetBlueprintTags returns the tags of a blueprint and the commits they
+// point to
+func (s *FileStore) GetBlueprintTags(name string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tags := make(map[string]string, len(s.blueprintsTags[name]))
+	for tag, commit := range s.blueprintsTags[name] {
+		tags[tag] = commit
+	}
+	return tags
+}
+
+// SetBlueprintTag points the tag of a blueprint to a commit, moving it if it
+// already exists
+// It will return an error if the blueprint or commit don't exist
+func (s *FileStore) SetBlueprintTag(name string, tag string, commit string) error {
+	return s.change(func() error {
+		if _, ok := s.blueprintsChanges[name]; !ok {
+			return errors.New("Unknown blueprint")
+		}
+		if _, ok := s.blueprintsChanges[name][commit]; !ok {
+			return errors.New("Unknown commit")
+		}
+		if s.blueprintsTags[name] == nil {
+			s.blueprintsTags[name] = make(map[string]string)
+		}
+		s.blueprintsTags[name][tag] = commit
+		return nil
+	})
+}
+
 // GetBlueprintChanges returns the list of changes, oldest first
-func (s *Store) GetBlueprintChanges(name string) []blueprint.Change {
+func (s *FileStore) GetBlueprintChanges(name string) []blueprint.Change {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -190,7 +299,7 @@ func (s *Store) GetBlueprintChanges(name string) []blueprint.Change {
 	return changes
 }
 
-func (s *Store) PushBlueprint(bp blueprint.Blueprint, commitMsg string) error {
+func (s *FileStore) PushBlueprint(bp blueprint.Blueprint, commitMsg string) error {
 	return s.change(func() error {
 		commit, err := randomSHA1String()
 		if err != nil {
@@ -229,7 +338,7 @@ func (s *Store) PushBlueprint(bp blueprint.Blueprint, commitMsg string) error {
 	})
 }
 
-func (s *Store) PushBlueprintToWorkspace(bp blueprint.Blueprint) error {
+func (s *FileStore) PushBlueprintToWorkspace(bp blueprint.Blueprint) error {
 	return s.change(func() error {
 		// Make sure the blueprint has default values and that the version is valid
 		err := bp.Initialize()
@@ -245,7 +354,7 @@ func (s *Store) PushBlueprintToWorkspace(bp blueprint.Blueprint) error {
 // DeleteBlueprint will remove the named blueprint from the store
 // if the blueprint does not exist it will return an error
 // The workspace copy is deleted unconditionally, it will not return an error if it does not exist.
-func (s *Store) DeleteBlueprint(name string) error {
+func (s *FileStore) DeleteBlueprint(name string) error {
 	return s.change(func() error {
 		delete(s.workspace, name)
 		if _, ok := s.blueprints[name]; !ok {
@@ -258,7 +367,7 @@ func (s *Store) DeleteBlueprint(name string) error {
 
 // DeleteBlueprintFromWorkspace deletes the workspace copy of a blueprint
 // if the blueprint doesn't exist in the workspace it returns an error
-func (s *Store) DeleteBlueprintFromWorkspace(name string) error {
+func (s *FileStore) DeleteBlueprintFromWorkspace(name string) error {
 	return s.change(func() error {
 		if _, ok := s.workspace[name]; !ok {
 			return fmt.Errorf("Unknown blueprint: %s", name)
@@ -270,7 +379,7 @@ func (s *Store) DeleteBlueprintFromWorkspace(name string) error {
 
 // TagBlueprint will tag the most recent commit
 // It will return an error if the blueprint doesn't exist
-func (s *Store) TagBlueprint(name string) error {
+func (s *FileStore) TagBlueprint(name string) error {
 	return s.change(func() error {
 		_, ok := s.blueprints[name]
 		if !ok {
@@ -289,25 +398,171 @@ func (s *Store) TagBlueprint(name string) error {
 
 		// Get the latest revision for this blueprint
 		var revision int
-		var change blueprint.Change
 		for i := len(s.blueprintsCommits[name]) - 1; i >= 0; i-- {
 			commit := s.blueprintsCommits[name][i]
-			change = s.blueprintsChanges[name][commit]
+			change := s.blueprintsChanges[name][commit]
 			if change.Revision != nil && *change.Revision > revision {
 				revision = *change.Revision
 				break
 			}
 		}
 
-		// Bump the revision (if there was none it will start at 1)
+		// Bump the revision (if there was none it will start at 1), and
+		// leave the rest of the commit as it is
 		revision++
+		change := s.blueprintsChanges[name][latest]
 		change.Revision = &revision
 		s.blueprintsChanges[name][latest] = change
 		return nil
 	})
 }
 
-func (s *Store) GetCompose(id uuid.UUID) (Compose, bool) {
+// BlueprintHistory is a committed blueprint with all of its changes, in the
+// format in which blueprints are exported and imported.
+type BlueprintHistory struct {
+	Blueprint blueprint.Blueprint `json:"blueprint"`
+	// oldest first
+	Changes []BlueprintChange `json:"changes"`
+	// names of tags and the commits they point to
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// BlueprintChange is a change of an exported blueprint, which includes the
+// blueprint as it was committed, unless that wasn't saved.
+type BlueprintChange struct {
+	Commit    string               `json:"commit"`
+	Message   string               `json:"message"`
+	Revision  *int                 `json:"revision,omitempty"`
+	Timestamp string               `json:"timestamp"`
+	Blueprint *blueprint.Blueprint `json:"blueprint,omitempty"`
+}
+
+// ExportBlueprints returns all committed blueprints with their changes,
+// sorted by name. Changes in the workspace are not exported.
+func (s *FileStore) ExportBlueprints() []BlueprintHistory {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.blueprints))
+	for name := range s.blueprints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	histories := make([]BlueprintHistory, 0, len(names))
+	for _, name := range names {
+		history := BlueprintHistory{
+			Blueprint: s.blueprints[name],
+			Changes:   []BlueprintChange{},
+		}
+		for _, commit := range s.blueprintsCommits[name] {
+			change := s.blueprintsChanges[name][commit]
+			exported := BlueprintChange{
+				Commit:    change.Commit,
+				Message:   change.Message,
+				Revision:  change.Revision,
+				Timestamp: change.Timestamp,
+			}
+			if change.Blueprint.Name != "" {
+				bp := change.Blueprint
+				exported.Blueprint = &bp
+			}
+			history.Changes = append(history.Changes, exported)
+		}
+		if len(s.blueprintsTags[name]) > 0 {
+			history.Tags = make(map[string]string)
+			for tag, commit := range s.blueprintsTags[name] {
+				history.Tags[tag] = commit
+			}
+		}
+		histories = append(histories, history)
+	}
+
+	return histories
+}
+
+// ImportBlueprints replaces the blueprints of `histories`, including their
+// changes, with the imported ones, and drops their workspace copies. Other
+// blueprints are not touched. Nothing is imported if any of the blueprints is
+// invalid.
+func (s *FileStore) ImportBlueprints(histories []BlueprintHistory) error {
+	err := validateBlueprintHistories(histories)
+	if err != nil {
+		return err
+	}
+
+	return s.change(func() error {
+		for _, history := range histories {
+			name := history.Blueprint.Name
+			changes := make(map[string]blueprint.Change)
+			commits := make([]string, 0, len(history.Changes))
+			for _, change := range history.Changes {
+				imported := blueprint.Change{
+					Commit:    change.Commit,
+					Message:   change.Message,
+					Revision:  change.Revision,
+					Timestamp: change.Timestamp,
+				}
+				if change.Blueprint != nil {
+					imported.Blueprint = *change.Blueprint
+				}
+				changes[change.Commit] = imported
+				commits = append(commits, change.Commit)
+			}
+
+			delete(s.workspace, name)
+			s.blueprints[name] = history.Blueprint
+			s.blueprintsChanges[name] = changes
+			s.blueprintsCommits[name] = commits
+			s.blueprintsTags[name] = history.Tags
+		}
+		return nil
+	})
+}
+
+// validateBlueprintHistories checks that `histories` can be imported.
+// Blueprints are initialized with their default values, and changes without
+// a commit are given a new one.
+func validateBlueprintHistories(histories []BlueprintHistory) error {
+	for i := range histories {
+		history := &histories[i]
+		if history.Blueprint.Name == "" {
+			return errors.New("Blueprint without a name")
+		}
+		err := history.Blueprint.Initialize()
+		if err != nil {
+			return fmt.Errorf("%s: %v", history.Blueprint.Name, err)
+		}
+		commits := make(map[string]bool)
+		for j := range history.Changes {
+			change := &history.Changes[j]
+			if change.Blueprint != nil {
+				if change.Blueprint.Name != history.Blueprint.Name {
+					return fmt.Errorf("%s: change %s is of blueprint %q", history.Blueprint.Name, change.Commit, change.Blueprint.Name)
+				}
+				err := change.Blueprint.Initialize()
+				if err != nil {
+					return fmt.Errorf("%s: change %s: %v", history.Blueprint.Name, change.Commit, err)
+				}
+			}
+			if change.Commit == "" {
+				change.Commit, err = randomSHA1String()
+				if err != nil {
+					return err
+				}
+			}
+			commits[change.Commit] = true
+		}
+		for tag, commit := range history.Tags {
+			if !commits[commit] {
+				return fmt.Errorf("%s: tag %s points to unknown commit %s", history.Blueprint.Name, tag, commit)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) GetCompose(id uuid.UUID) (Compose, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -317,7 +572,7 @@ func (s *Store) GetCompose(id uuid.UUID) (Compose, bool) {
 
 // GetAllComposes creates a deep copy of all composes present in this store
 // and returns them as a dictionary with compose UUIDs as keys
-func (s *Store) GetAllComposes() map[uuid.UUID]Compose {
+func (s *FileStore) GetAllComposes() map[uuid.UUID]Compose {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -331,7 +586,22 @@ func (s *Store) GetAllComposes() map[uuid.UUID]Compose {
 	return composes
 }
 
-func (s *Store) PushCompose(composeID uuid.UUID,
+// ListComposes returns a deep copy of the composes selected by `filter`
+func (s *FileStore) ListComposes(filter ComposeFilter) map[uuid.UUID]Compose {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	composes := make(map[uuid.UUID]Compose)
+	for id, compose := range s.composes {
+		if filter.Matches(&compose) {
+			composes[id] = compose.DeepCopy()
+		}
+	}
+
+	return composes
+}
+
+func (s *FileStore) PushCompose(composeID uuid.UUID,
 	manifest distro.Manifest,
 	imageType distro.ImageType,
 	bp *blueprint.Blueprint,
@@ -344,24 +614,12 @@ func (s *Store) PushCompose(composeID uuid.UUID,
 		panic("a compose with this id already exists")
 	}
 
-	if targets == nil {
-		targets = []*target.Target{}
-	}
+	compose := newCompose(manifest, imageType, bp, size, targets, packages)
+	compose.ImageBuild.JobID = jobId
 
 	// FIXME: handle or comment this possible error
 	_ = s.change(func() error {
-		s.composes[composeID] = Compose{
-			Blueprint: bp,
-			ImageBuild: ImageBuild{
-				Manifest:   manifest,
-				ImageType:  imageType,
-				Targets:    targets,
-				JobCreated: time.Now(),
-				Size:       size,
-				JobID:      jobId,
-			},
-			Packages: packages,
-		}
+		s.composes[composeID] = compose
 		return nil
 	})
 	return nil
@@ -370,7 +628,7 @@ func (s *Store) PushCompose(composeID uuid.UUID,
 // PushTestCompose is used for testing
 // Set testSuccess to create a fake successful compose, otherwise it will create a failed compose
 // It does not actually run a compose job
-func (s *Store) PushTestCompose(composeID uuid.UUID,
+func (s *FileStore) PushTestCompose(composeID uuid.UUID,
 	manifest distro.Manifest,
 	imageType distro.ImageType,
 	bp *blueprint.Blueprint,
@@ -379,41 +637,59 @@ func (s *Store) PushTestCompose(composeID uuid.UUID,
 	testSuccess bool,
 	packages []rpmmd.PackageSpec) error {
 
-	if targets == nil {
-		targets = []*target.Target{}
-	}
-
-	var status common.ImageBuildState
-	if testSuccess {
-		status = common.IBFinished
-	} else {
-		status = common.IBFailed
-	}
+	compose := newTestCompose(manifest, imageType, bp, size, targets, testSuccess, packages)
 
 	// FIXME: handle or comment this possible error
 	_ = s.change(func() error {
-		s.composes[composeID] = Compose{
-			Blueprint: bp,
-			ImageBuild: ImageBuild{
-				QueueStatus: status,
-				Manifest:    manifest,
-				ImageType:   imageType,
-				Targets:     targets,
-				JobCreated:  time.Now(),
-				JobStarted:  time.Now(),
-				Size:        size,
-			},
-			Packages: packages,
-		}
+		s.composes[composeID] = compose
 		return nil
 	})
 
 	return nil
 }
 
+// newCompose returns a compose which was just created, with the secrets
+// removed from its blueprint
+func newCompose(manifest distro.Manifest, imageType distro.ImageType, bp *blueprint.Blueprint, size uint64, targets []*target.Target, packages []rpmmd.PackageSpec) Compose {
+	if targets == nil {
+		targets = []*target.Target{}
+	}
+
+	// secrets are only needed to create the manifest
+	if bp != nil {
+		redacted := bp.Redacted()
+		bp = &redacted
+	}
+
+	return Compose{
+		Blueprint: bp,
+		ImageBuild: ImageBuild{
+			Manifest:   manifest,
+			ImageType:  imageType,
+			Targets:    targets,
+			JobCreated: time.Now(),
+			Size:       size,
+		},
+		Packages: packages,
+	}
+}
+
+// newTestCompose returns a compose for PushTestCompose(), which is finished
+// or failed depending on `testSuccess`
+func newTestCompose(manifest distro.Manifest, imageType distro.ImageType, bp *blueprint.Blueprint, size uint64, targets []*target.Target, testSuccess bool, packages []rpmmd.PackageSpec) Compose {
+	compose := newCompose(manifest, imageType, bp, size, targets, packages)
+	compose.ImageBuild.JobStarted = compose.ImageBuild.JobCreated
+	if testSuccess {
+		compose.ImageBuild.QueueStatus = common.IBFinished
+	} else {
+		compose.ImageBuild.QueueStatus = common.IBFailed
+	}
+	return compose
+}
+
 // DeleteCompose deletes the compose from the state file and also removes all files on disk that are
 // associated with this compose
-func (s *Store) DeleteCompose(id uuid.UUID) error {
+func (s *FileStore) DeleteCompose(id uuid.UUID) error {
 	return s.change(func() error {
 		if _, exists := s.composes[id]; !exists {
 			return &NotFoundError{}
@@ -425,8 +701,53 @@ func (s *Store) DeleteCompose(id uuid.UUID) error {
 	})
 }
 
+// PushSchedule stores a new compose schedule under `id`.
+func (s *FileStore) PushSchedule(id uuid.UUID, schedule ComposeSchedule) error {
+	return s.change(func() error {
+		s.schedules[id] = schedule
+		return nil
+	})
+}
+
+// GetAllSchedules returns a copy of all compose schedules.
+func (s *FileStore) GetAllSchedules() map[uuid.UUID]ComposeSchedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	schedules := make(map[uuid.UUID]ComposeSchedule)
+	for id, schedule := range s.schedules {
+		schedules[id] = schedule
+	}
+	return schedules
+}
+
+// UpdateSchedule changes the compose schedule `id` with `f`. It returns a
+// NotFoundError if the schedule was deleted in the meantime.
+func (s *FileStore) UpdateSchedule(id uuid.UUID, f func(*ComposeSchedule)) error {
+	return s.change(func() error {
+		schedule, exists := s.schedules[id]
+		if !exists {
+			return &NotFoundError{"schedule does not exist"}
+		}
+		f(&schedule)
+		s.schedules[id] = schedule
+		return nil
+	})
+}
+
+// DeleteSchedule deletes the compose schedule `id`.
+func (s *FileStore) DeleteSchedule(id uuid.UUID) error {
+	return s.change(func() error {
+		if _, exists := s.schedules[id]; !exists {
+			return &NotFoundError{"schedule does not exist"}
+		}
+		delete(s.schedules, id)
+		return nil
+	})
+}
+
 // PushSource stores a SourceConfig in store.Sources
-func (s *Store) PushSource(key string, source SourceConfig) {
+func (s *FileStore) PushSource(key string, source SourceConfig) {
 	// FIXME: handle or comment this possible error
 	_ = s.change(func() error {
 		s.sources[key] = source
@@ -435,7 +756,7 @@ func (s *Store) PushSource(key string, source SourceConfig) {
 }
 
 // DeleteSourceByName removes a SourceConfig from store.Sources using the .Name field
-func (s *Store) DeleteSourceByName(name string) {
+func (s *FileStore) DeleteSourceByName(name string) {
 	// FIXME: handle or comment this possible error
 	_ = s.change(func() error {
 		for key := range s.sources {
@@ -449,7 +770,7 @@ func (s *Store) DeleteSourceByName(name string) {
 }
 
 // DeleteSourceByID removes a SourceConfig from store.Sources using the ID
-func (s *Store) DeleteSourceByID(key string) {
+func (s *FileStore) DeleteSourceByID(key string) {
 	// FIXME: handle or comment this possible error
 	_ = s.change(func() error {
 		delete(s.sources, key)
@@ -459,7 +780,7 @@ func (s *Store) DeleteSourceByID(key string) {
 
 // ListSourcesByName returns the repo source names
 // Name is different than Id, it can be a full description of the repo
-func (s *Store) ListSourcesByName() []string {
+func (s *FileStore) ListSourcesByName() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	names := make([]string, 0, len(s.sources))
@@ -473,7 +794,7 @@ func (s *Store) ListSourcesByName() []string {
 
 // ListSourcesById returns the repo source id
 // Id is a short identifier for the repo, not a full name description
-func (s *Store) ListSourcesById() []string {
+func (s *FileStore) ListSourcesById() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	names := make([]string, 0, len(s.sources))
@@ -485,7 +806,7 @@ func (s *Store) ListSourcesById() []string {
 	return names
 }
 
-func (s *Store) GetSource(name string) *SourceConfig {
+func (s *FileStore) GetSource(name string) *SourceConfig {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -497,7 +818,7 @@ func (s *Store) GetSource(name string) *SourceConfig {
 }
 
 // GetAllSourcesByName returns the sources using the repo name as the key
-func (s *Store) GetAllSourcesByName() map[string]SourceConfig {
+func (s *FileStore) GetAllSourcesByName() map[string]SourceConfig {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -511,7 +832,7 @@ func (s *Store) GetAllSourcesByName() map[string]SourceConfig {
 }
 
 // GetAllSourcesByID returns the sources using the repo id as the key
-func (s *Store) GetAllSourcesByID() map[string]SourceConfig {
+func (s *FileStore) GetAllSourcesByID() map[string]SourceConfig {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -22,7 +22,7 @@ import (
 type storeTest struct {
 	suite.Suite
 	dir              string
-	myStore          *Store
+	myStore          *FileStore
 	myCustomizations blueprint.Customizations
 	myBP             blueprint.Blueprint
 	CommitHash       string
@@ -118,7 +118,8 @@ func (suite *storeTest) SetupTest() {
 	arch, err := distro.GetArch(test_distro.TestArchName)
 	suite.NoError(err)
 	suite.dir = tmpDir
-	suite.myStore = New(&suite.dir, arch, nil)
+	suite.myStore, err = New(&suite.dir, arch, nil)
+	suite.NoError(err)
 }
 
 //teardown after each test
@@ -258,6 +259,86 @@ func (suite *storeTest) TestDeleteBlueprintFromWorkspace() {
 	suite.EqualError(suite.myStore.DeleteBlueprintFromWorkspace("WIPtestBP"), "Unknown blueprint: WIPtestBP")
 }
 
+func (suite *storeTest) TestExportImportBlueprints() {
+	suite.NoError(suite.myStore.PushBlueprint(suite.myBP, "first commit"))
+	suite.NoError(suite.myStore.TagBlueprint("testBP"))
+	suite.NoError(suite.myStore.PushBlueprint(suite.myBP, "second commit"))
+	first := suite.myStore.blueprintsCommits["testBP"][0]
+	suite.NoError(suite.myStore.SetBlueprintTag("testBP", "production", first))
+
+	exported := suite.myStore.ExportBlueprints()
+	suite.Len(exported, 1)
+	suite.Equal(map[string]string{"production": first}, exported[0].Tags)
+	suite.Equal("0.0.2", exported[0].Blueprint.Version)
+	suite.Len(exported[0].Changes, 2)
+	suite.Equal("first commit", exported[0].Changes[0].Message)
+	suite.Equal("second commit", exported[0].Changes[1].Message)
+
+	distro := test_distro.New()
+	arch, err := distro.GetArch(test_distro.TestArchName)
+	suite.NoError(err)
+	other, err := New(nil, arch, nil)
+	suite.NoError(err)
+	suite.NoError(other.PushBlueprintToWorkspace(suite.myBP))
+	suite.NoError(other.ImportBlueprints(exported))
+
+	suite.Equal(exported, other.ExportBlueprints())
+	bp, inWorkspace := other.GetBlueprint("testBP")
+	suite.False(inWorkspace)
+	suite.Equal("0.0.2", bp.Version)
+	change, err := other.GetBlueprintChange("testBP", exported[0].Changes[0].Commit)
+	suite.NoError(err)
+	suite.Equal(1, *change.Revision)
+
+	// invalid blueprints are not imported
+	invalid := []BlueprintHistory{{Blueprint: blueprint.Blueprint{Name: "invalid", Version: "one"}}}
+	suite.Error(other.ImportBlueprints(invalid))
+	suite.Equal([]string{"testBP"}, other.ListBlueprints())
+}
+
+func (suite *storeTest) TestBlueprintRevisions() {
+	suite.NoError(suite.myStore.PushBlueprint(suite.myBP, "first commit"))
+	changed := suite.myBP.DeepCopy()
+	changed.Version = "0.1.0"
+	changed.Packages = []blueprint.Package{{Name: "tmux", Version: "*"}}
+	suite.NoError(suite.myStore.PushBlueprint(changed, "second commit"))
+	commits := suite.myStore.blueprintsCommits["testBP"]
+
+	// tagging keeps the blueprint of the commit
+	suite.NoError(suite.myStore.TagBlueprint("testBP"))
+	bp, err := suite.myStore.GetBlueprintRevision("testBP", commits[1])
+	suite.NoError(err)
+	suite.Equal("tmux", bp.Packages[0].Name)
+
+	suite.NoError(suite.myStore.SetBlueprintTag("testBP", "production", commits[0]))
+	suite.Equal(map[string]string{"production": commits[0]}, suite.myStore.GetBlueprintTags("testBP"))
+	bp, err = suite.myStore.GetBlueprintRevision("testBP", "production")
+	suite.NoError(err)
+	suite.Equal("test1", bp.Packages[0].Name)
+	suite.EqualError(suite.myStore.SetBlueprintTag("testBP", "production", "unknown"), "Unknown commit")
+	suite.EqualError(suite.myStore.SetBlueprintTag("unknown", "production", commits[0]), "Unknown blueprint")
+
+	// the blueprints of commits and tags are kept across restarts
+	distro := test_distro.New()
+	arch, err := distro.GetArch(test_distro.TestArchName)
+	suite.NoError(err)
+	restarted, err := New(&suite.dir, arch, nil)
+	suite.NoError(err)
+	bp, err = restarted.GetBlueprintRevision("testBP", "production")
+	suite.NoError(err)
+	suite.Equal("test1", bp.Packages[0].Name)
+	bp, err = restarted.GetBlueprintRevision("testBP", commits[1])
+	suite.NoError(err)
+	suite.Equal("0.1.0", bp.Version)
+
+	// changes saved by older versions have no blueprint
+	change := restarted.blueprintsChanges["testBP"][commits[0]]
+	change.Blueprint = blueprint.Blueprint{}
+	restarted.blueprintsChanges["testBP"][commits[0]] = change
+	_, err = restarted.GetBlueprintRevision("testBP", commits[0])
+	suite.Error(err)
+}
+
 func (suite *storeTest) TestPushCompose() {
 	testID := uuid.New()
 	err := suite.myStore.PushCompose(testID, suite.myManifest, suite.myImageType, &suite.myBP, 123, nil, uuid.New(), []rpmmd.PackageSpec{})
@@ -271,6 +352,17 @@ func (suite *storeTest) TestPushCompose() {
 	testID = uuid.New()
 	err = suite.myStore.PushCompose(testID, suite.myManifest, suite.myImageType, &suite.myBP, 123, nil, uuid.New(), suite.myPackages)
 	suite.NoError(err)
+
+	// Test that secrets are not stored
+	testID = uuid.New()
+	bp := suite.myBP.DeepCopy()
+	bp.Customizations = &blueprint.Customizations{
+		Subscription: &blueprint.SubscriptionCustomization{Organization: 1, ActivationKey: "secret"},
+	}
+	err = suite.myStore.PushCompose(testID, suite.myManifest, suite.myImageType, &bp, 123, nil, uuid.New(), suite.myPackages)
+	suite.NoError(err)
+	suite.Equal(blueprint.RedactedActivationKey, suite.myStore.composes[testID].Blueprint.Customizations.Subscription.ActivationKey)
+	suite.Equal("secret", bp.Customizations.Subscription.ActivationKey)
 }
 
 func (suite *storeTest) TestPushTestCompose() {
@@ -301,6 +393,20 @@ func (suite *storeTest) TestGetAllComposes() {
 	suite.Equal(suite.myStore.composes, compose)
 }
 
+func (suite *storeTest) TestListComposes() {
+	ID := uuid.New()
+	err := suite.myStore.PushTestCompose(ID, suite.myManifest, suite.myImageType, &suite.myBP, 123, nil, true, []rpmmd.PackageSpec{})
+	suite.NoError(err)
+	created := suite.myStore.composes[ID].ImageBuild.JobCreated
+
+	suite.Contains(suite.myStore.ListComposes(ComposeFilter{}), ID)
+	suite.Contains(suite.myStore.ListComposes(ComposeFilter{BlueprintSearch: "tbp"}), ID)
+	suite.Empty(suite.myStore.ListComposes(ComposeFilter{BlueprintSearch: "other"}))
+	suite.Contains(suite.myStore.ListComposes(ComposeFilter{Since: created, Until: created.Add(time.Second)}), ID)
+	suite.Empty(suite.myStore.ListComposes(ComposeFilter{Since: created.Add(time.Second)}))
+	suite.Empty(suite.myStore.ListComposes(ComposeFilter{Until: created}))
+}
+
 func (suite *storeTest) TestDeleteCompose() {
 	ID := uuid.New()
 	suite.myStore.composes = make(map[uuid.UUID]Compose)
@@ -430,3 +536,37 @@ func (suite *storeTest) TestRepoConfigMirrorlist() {
 func TestStore(t *testing.T) {
 	suite.Run(t, new(storeTest))
 }
+
+func (suite *storeTest) TestSchedules() {
+	id := uuid.New()
+	next := time.Date(2021, time.March, 3, 2, 0, 0, 0, time.UTC)
+	err := suite.myStore.PushSchedule(id, ComposeSchedule{
+		Request: []byte(`{"blueprint_name":"test"}`),
+		Cron:    "0 2 * * *",
+		NextRun: next,
+	})
+	suite.NoError(err)
+
+	composeID := uuid.New()
+	err = suite.myStore.UpdateSchedule(id, func(schedule *ComposeSchedule) {
+		schedule.LastComposeID = composeID
+	})
+	suite.NoError(err)
+
+	// schedules survive a restart
+	arch, err := suite.myDistro.GetArch(test_distro.TestArchName)
+	suite.NoError(err)
+	restarted, err := New(&suite.dir, arch, nil)
+	suite.NoError(err)
+	schedules := restarted.GetAllSchedules()
+	suite.Len(schedules, 1)
+	suite.JSONEq(`{"blueprint_name":"test"}`, string(schedules[id].Request))
+	suite.Equal("0 2 * * *", schedules[id].Cron)
+	suite.True(next.Equal(schedules[id].NextRun))
+	suite.Equal(composeID, schedules[id].LastComposeID)
+
+	suite.NoError(suite.myStore.DeleteSchedule(id))
+	suite.Empty(suite.myStore.GetAllSchedules())
+	suite.IsType(&NotFoundError{}, suite.myStore.DeleteSchedule(id))
+	suite.IsType(&NotFoundError{}, suite.myStore.UpdateSchedule(id, func(*ComposeSchedule) {}))
+}
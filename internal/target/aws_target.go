@@ -1,17 +1,60 @@
 package target
 
+// AWSCredentials are access keys, or an IAM role which is assumed with the
+// access keys, or with the worker's own credentials if they are empty.
+type AWSCredentials struct {
+	AccessKeyID     string `json:"accessKeyID"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken,omitempty"`
+	RoleARN         string `json:"roleARN,omitempty"`
+	ExternalID      string `json:"externalID,omitempty"`
+}
+
 type AWSTargetOptions struct {
 	Filename          string   `json:"filename"`
 	Region            string   `json:"region"`
 	AccessKeyID       string   `json:"accessKeyID"`
 	SecretAccessKey   string   `json:"secretAccessKey"`
+	SessionToken      string   `json:"sessionToken,omitempty"`
+	RoleARN           string   `json:"roleARN,omitempty"`
+	ExternalID        string   `json:"externalID,omitempty"`
 	Bucket            string   `json:"bucket"`
 	Key               string   `json:"key"`
 	ShareWithAccounts []string `json:"shareWithAccounts"`
+
+	// Credentials for importing and registering the image, if they differ
+	// from the ones above, which are used for uploading it to S3
+	EC2 *AWSCredentials `json:"ec2,omitempty"`
+
+	// Regions to copy the AMI to, in addition to Region
+	CopyToRegions []string `json:"copyToRegions,omitempty"`
+
+	// Tags of the AMIs and their snapshots, in addition to the name
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 func (AWSTargetOptions) isTargetOptions() {}
 
+// S3Credentials returns the credentials for uploading the image to S3.
+func (o *AWSTargetOptions) S3Credentials() AWSCredentials {
+	return AWSCredentials{
+		AccessKeyID:     o.AccessKeyID,
+		SecretAccessKey: o.SecretAccessKey,
+		SessionToken:    o.SessionToken,
+		RoleARN:         o.RoleARN,
+		ExternalID:      o.ExternalID,
+	}
+}
+
+// EC2Credentials returns the credentials for importing and registering the
+// image.
+func (o *AWSTargetOptions) EC2Credentials() AWSCredentials {
+	if o.EC2 != nil {
+		return *o.EC2
+	}
+	return o.S3Credentials()
+}
+
 func NewAWSTarget(options *AWSTargetOptions) *Target {
 	return newTarget("org.osbuild.aws", options)
 }
@@ -19,6 +62,9 @@ func NewAWSTarget(options *AWSTargetOptions) *Target {
 type AWSTargetResultOptions struct {
 	Ami    string `json:"ami"`
 	Region string `json:"region"`
+
+	// Copies of the AMI in the regions it was copied to
+	Copies []AWSTargetResultOptions `json:"copies,omitempty"`
 }
 
 func (AWSTargetResultOptions) isTargetResultOptions() {}
@@ -32,12 +78,26 @@ type AWSS3TargetOptions struct {
 	Region          string `json:"region"`
 	AccessKeyID     string `json:"accessKeyID"`
 	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken,omitempty"`
+	RoleARN         string `json:"roleARN,omitempty"`
+	ExternalID      string `json:"externalID,omitempty"`
 	Bucket          string `json:"bucket"`
 	Key             string `json:"key"`
 }
 
 func (AWSS3TargetOptions) isTargetOptions() {}
 
+// Credentials returns the credentials for uploading to the bucket.
+func (o *AWSS3TargetOptions) Credentials() AWSCredentials {
+	return AWSCredentials{
+		AccessKeyID:     o.AccessKeyID,
+		SecretAccessKey: o.SecretAccessKey,
+		SessionToken:    o.SessionToken,
+		RoleARN:         o.RoleARN,
+		ExternalID:      o.ExternalID,
+	}
+}
+
 func NewAWSS3Target(options *AWSS3TargetOptions) *Target {
 	return newTarget("org.osbuild.aws.s3", options)
 }
@@ -51,3 +111,30 @@ func (AWSS3TargetResultOptions) isTargetResultOptions() {}
 func NewAWSS3TargetResult(options *AWSS3TargetResultOptions) *TargetResult {
 	return newTargetResult("org.osbuild.aws.s3", options)
 }
+
+// GenericS3TargetOptions describe an upload to an S3-compatible service
+// other than AWS, such as MinIO or Ceph RGW.
+type GenericS3TargetOptions struct {
+	AWSS3TargetOptions
+
+	// URL of the service, e.g., https://minio.example.com:9000
+	Endpoint string `json:"endpoint"`
+
+	// PEM-encoded certificates to trust instead of the system's ones.
+	CABundle            string `json:"caBundle,omitempty"`
+	SkipSSLVerification bool   `json:"skipSSLVerification,omitempty"`
+}
+
+func (GenericS3TargetOptions) isTargetOptions() {}
+
+func NewGenericS3Target(options *GenericS3TargetOptions) *Target {
+	return newTarget("org.osbuild.generic.s3", options)
+}
+
+type GenericS3TargetResultOptions AWSS3TargetResultOptions
+
+func (GenericS3TargetResultOptions) isTargetResultOptions() {}
+
+func NewGenericS3TargetResult(options *GenericS3TargetResultOptions) *TargetResult {
+	return newTargetResult("org.osbuild.generic.s3", options)
+}
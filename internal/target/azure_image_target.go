@@ -6,6 +6,12 @@ type AzureImageTargetOptions struct {
 	Location       string `json:"location"`
 	SubscriptionID string `json:"subscription_id"`
 	ResourceGroup  string `json:"resource_group"`
+
+	// Credentials of the service principal, which composer fills in when
+	// it hands out the job. The worker's own credentials are used if they
+	// are empty.
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
 }
 
 func (AzureImageTargetOptions) isTargetOptions() {}
@@ -18,9 +24,9 @@ func (AzureImageTargetOptions) isTargetOptions() {}
 // The target uses Azure OAuth credentials. In most cases you want to create
 // a service principal for this purpose, see:
 // https://docs.microsoft.com/en-us/azure/active-directory/develop/app-objects-and-service-principals
-// The credentials are not passed in the target options, instead they are
-// defined in the worker. If the worker doesn't have Azure credentials
-// and gets a job with this target, the job will fail.
+// The credentials are not stored in the target options, instead they are
+// defined in the worker, or filled in by composer when it hands out the job.
+// If neither has Azure credentials, the job will fail.
 //
 // The Tenant ID for the authorization process is specified in the target
 // options. This means that this target can be used for multi-tenant
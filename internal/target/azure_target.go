@@ -1,24 +1,61 @@
 package target
 
 type AzureTargetOptions struct {
-	Filename         string `json:"filename"`
-	StorageAccount   string `json:"storageAccount"`
+	Filename       string `json:"filename"`
+	StorageAccount string `json:"storageAccount"`
+	Container      string `json:"container"`
+
+	// Credentials for uploading the blob. If neither a storage account key
+	// nor a SAS token is set, the worker's service principal is used to
+	// retrieve a key for the storage account, which must then be in
+	// `ResourceGroup` of `SubscriptionID`.
 	StorageAccessKey string `json:"storageAccessKey"`
-	Container        string `json:"container"`
+	SASToken         string `json:"sasToken,omitempty"`
+
+	// Where to find the storage account and register the image when
+	// authenticating with the worker's service principal.
+	TenantID       string `json:"tenantId,omitempty"`
+	SubscriptionID string `json:"subscriptionId,omitempty"`
+	ResourceGroup  string `json:"resourceGroup,omitempty"`
+
+	// If set, a managed image with this name is registered in
+	// `ResourceGroup` at `Location` from the uploaded blob. This always
+	// requires the worker's service principal.
+	ImageName string `json:"imageName,omitempty"`
+	Location  string `json:"location,omitempty"`
 }
 
 func (AzureTargetOptions) isTargetOptions() {}
 
 // NewAzureTarget creates org.osbuild.azure target
 //
-// This target uploads a Page Blob to Azure Storage.
+// This target uploads a Page Blob to Azure Storage and optionally registers
+// a managed image from it.
 //
-// The target uses Azure Storage keys for authentication, see:
+// The target authenticates with Azure Storage keys, see:
 // https://docs.microsoft.com/en-us/azure/storage/common/storage-account-keys-manage
-// The credentials are defined inside the target options.
+// or with a shared access signature (SAS) token. Both are defined inside the
+// target options. If neither is set, the worker's Azure credentials are used
+// to retrieve a storage account key.
 //
-// If you need to upload an Azure Image instead, see the
-// org.osbuild.azure.image target.
+// If you need to upload an Azure Image into a storage account managed by
+// the worker, see the org.osbuild.azure.image target.
 func NewAzureTarget(options *AzureTargetOptions) *Target {
 	return newTarget("org.osbuild.azure", options)
 }
+
+type AzureTargetResultOptions struct {
+	BlobURL   string `json:"blob_url"`
+	ImageName string `json:"image_name,omitempty"`
+
+	// Number of bytes that were uploaded and the size of the image.
+	// They differ only when the upload failed.
+	UploadedBytes int64 `json:"uploaded_bytes"`
+	TotalBytes    int64 `json:"total_bytes"`
+}
+
+func (AzureTargetResultOptions) isTargetResultOptions() {}
+
+func NewAzureTargetResult(options *AzureTargetResultOptions) *TargetResult {
+	return newTargetResult("org.osbuild.azure", options)
+}
@@ -7,6 +7,18 @@ type GCPTargetOptions struct {
 	Bucket            string   `json:"bucket"`
 	Object            string   `json:"object"`
 	ShareWithAccounts []string `json:"shareWithAccounts"`
+
+	// IDs of projects to share the image with, e.g., the service projects
+	// of a Shared VPC.
+	ShareWithProjects []string `json:"shareWithProjects,omitempty"`
+
+	// Guest OS features to enable on the image, such as UEFI_COMPATIBLE or
+	// GVNIC.
+	GuestOSFeatures []string `json:"guestOsFeatures,omitempty"`
+
+	// Service account key, which composer fills in when it hands out the
+	// job. The worker's own credentials are used if it is empty.
+	Credentials []byte `json:"credentials,omitempty"`
 }
 
 func (GCPTargetOptions) isTargetOptions() {}
@@ -0,0 +1,38 @@
+package target
+
+type OCITargetOptions struct {
+	Filename string `json:"filename"`
+	Region   string `json:"region"`
+
+	// OCID of the compartment the image is created in.
+	Compartment string `json:"compartment"`
+
+	// Object Storage namespace and bucket the QCOW2 image is uploaded to
+	// before importing it. The object is deleted after the import.
+	Namespace string `json:"namespace"`
+	Bucket    string `json:"bucket"`
+	Object    string `json:"object"`
+}
+
+func (OCITargetOptions) isTargetOptions() {}
+
+// NewOCITarget creates org.osbuild.oci target
+//
+// This target uploads a QCOW2 image to Oracle Cloud Infrastructure Object
+// Storage and imports it as a custom image. The target authenticates with the
+// worker's OCI credentials.
+func NewOCITarget(options *OCITargetOptions) *Target {
+	return newTarget("org.osbuild.oci", options)
+}
+
+type OCITargetResultOptions struct {
+	// OCID of the imported image.
+	ImageID string `json:"image_id"`
+	Region  string `json:"region"`
+}
+
+func (OCITargetResultOptions) isTargetResultOptions() {}
+
+func NewOCITargetResult(options *OCITargetResultOptions) *TargetResult {
+	return newTargetResult("org.osbuild.oci", options)
+}
@@ -71,6 +71,8 @@ func UnmarshalTargetOptions(targetName string, rawOptions json.RawMessage) (Targ
 		options = new(AWSTargetOptions)
 	case "org.osbuild.aws.s3":
 		options = new(AWSS3TargetOptions)
+	case "org.osbuild.generic.s3":
+		options = new(GenericS3TargetOptions)
 	case "org.osbuild.gcp":
 		options = new(GCPTargetOptions)
 	case "org.osbuild.azure.image":
@@ -81,6 +83,8 @@ func UnmarshalTargetOptions(targetName string, rawOptions json.RawMessage) (Targ
 		options = new(KojiTargetOptions)
 	case "org.osbuild.vmware":
 		options = new(VMWareTargetOptions)
+	case "org.osbuild.oci":
+		options = new(OCITargetOptions)
 	default:
 		return nil, errors.New("unexpected target name")
 	}
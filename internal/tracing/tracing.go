@@ -0,0 +1,176 @@
+// Package tracing records spans of work done for composes with OpenTelemetry,
+// exports them to a collector via OTLP/HTTP, and propagates their context
+// with W3C Trace Context "traceparent" headers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlphttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var propagator = propagation.TraceContext{}
+
+// Tracer records spans and exports them. All its methods may be called on a
+// nil *Tracer, which doesn't record anything.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// NewTracer returns a tracer which exports spans to the OTLP/HTTP endpoint
+// `endpoint` of a collector, such as "http://localhost:4318", with the
+// service name `service`.
+func NewTracer(service, endpoint string) (*Tracer, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid collector endpoint '%s'", endpoint)
+	}
+
+	options := []otlphttp.Option{
+		otlphttp.WithEndpoint(u.Host),
+		otlphttp.WithTracesURLPath(strings.TrimSuffix(u.Path, "/") + "/v1/traces"),
+	}
+	if u.Scheme == "http" {
+		options = append(options, otlphttp.WithInsecure())
+	}
+
+	exporter, err := otlp.NewExporter(context.Background(), otlphttp.NewDriver(options...))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create exporter: %v", err)
+	}
+
+	return newTracer(sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.ServiceNameKey.String(service))),
+	)), nil
+}
+
+func newTracer(provider *sdktrace.TracerProvider) *Tracer {
+	return &Tracer{
+		provider: provider,
+		tracer:   provider.Tracer("github.com/osbuild/osbuild-composer/internal/tracing"),
+	}
+}
+
+// Start starts a span of `kind` as a child of the span or remote span context
+// in `ctx`, or as the root of a new trace. Returns a context carrying the new
+// span.
+func (t *Tracer) Start(ctx context.Context, name string, kind trace.SpanKind, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.StartAt(ctx, name, kind, time.Now(), attributes...)
+}
+
+// StartAt is like Start, for spans which started at `start`.
+func (t *Tracer) StartAt(ctx context.Context, name string, kind trace.SpanKind, start time.Time, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if t == nil {
+		// a span which isn't recorded
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return t.tracer.Start(ctx, name,
+		trace.WithSpanKind(kind),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attributes...),
+	)
+}
+
+// Shutdown exports all ended spans and stops recording new ones, waiting
+// until `ctx` is done at most.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	return t.provider.Shutdown(ctx)
+}
+
+// Middleware returns a handler which records a server span for each request
+// to `next`, as a child of the span in its traceparent header.
+func (t *Tracer) Middleware(next http.Handler) http.Handler {
+	if t == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		ctx, span := t.Start(ctx, r.Method+" "+r.URL.Path, trace.SpanKindServer,
+			semconv.HTTPMethodKey.String(r.Method),
+			semconv.HTTPTargetKey.String(r.URL.Path),
+		)
+		defer span.End()
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(sw.status))
+		if sw.status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(sw.status))
+		}
+	})
+}
+
+// Start starts a span as a child of the span in `ctx`, which is recorded by
+// the same tracer. If there is none, the span is not recorded.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return trace.SpanFromContext(ctx).Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// RecordError marks `span` as failed with `err`, unless it is nil.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Inject sets the traceparent header of `header` to the span context in
+// `ctx`, if there is one.
+func Inject(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Traceparent returns the context of the span in `ctx` in the format of the
+// traceparent header, or an empty string if there is none.
+func Traceparent(ctx context.Context) string {
+	header := http.Header{}
+	Inject(ctx, header)
+	return header.Get("traceparent")
+}
+
+// ContextWithTraceparent returns a context whose spans are children of the
+// span `traceparent` of another process.
+func ContextWithTraceparent(ctx context.Context, traceparent string) (context.Context, error) {
+	header := http.Header{}
+	header.Set("traceparent", traceparent)
+	sc := trace.RemoteSpanContextFromContext(propagator.Extract(context.Background(), propagation.HeaderCarrier(header)))
+	if !sc.IsValid() {
+		return ctx, fmt.Errorf("invalid traceparent '%s'", traceparent)
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc), nil
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,166 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// testExporter keeps the spans it is handed in memory.
+type testExporter struct {
+	mu    sync.Mutex
+	spans []*exporttrace.SpanSnapshot
+}
+
+func (e *testExporter) ExportSpans(ctx context.Context, spans []*exporttrace.SpanSnapshot) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *testExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (e *testExporter) span(name string) *exporttrace.SpanSnapshot {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, span := range e.spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func newTestTracer() (*Tracer, *testExporter) {
+	exporter := &testExporter{}
+	return newTracer(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))), exporter
+}
+
+func TestTraceparent(t *testing.T) {
+	tracer, _ := newTestTracer()
+
+	ctx, err := ContextWithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	ctx, span := tracer.Start(ctx, "job", trace.SpanKindConsumer)
+	defer span.End()
+	require.Regexp(t, "^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$", Traceparent(ctx))
+	require.NotContains(t, Traceparent(ctx), "00f067aa0ba902b7")
+
+	require.Empty(t, Traceparent(context.Background()))
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+	} {
+		_, err := ContextWithTraceparent(context.Background(), invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	tracer, exporter := newTestTracer()
+
+	handler := tracer.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := Start(r.Context(), "depsolve", attribute.Int64("packages", 42))
+		RecordError(span, errors.New("no such package"))
+		span.End()
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest("POST", "/compose", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	server := exporter.span("POST /compose")
+	require.NotNil(t, server)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID.String())
+	require.Equal(t, "00f067aa0ba902b7", server.ParentSpanID.String())
+	require.Equal(t, trace.SpanKindServer, server.SpanKind)
+	require.Contains(t, server.Attributes, semconv.HTTPStatusCodeKey.Int(201))
+	require.Equal(t, codes.Unset, server.StatusCode)
+
+	child := exporter.span("depsolve")
+	require.NotNil(t, child)
+	require.Equal(t, server.SpanContext.TraceID, child.SpanContext.TraceID)
+	require.Equal(t, server.SpanContext.SpanID, child.ParentSpanID)
+	require.Equal(t, codes.Error, child.StatusCode)
+	require.Equal(t, "no such package", child.StatusMessage)
+	require.Equal(t, []attribute.KeyValue{attribute.Int64("packages", 42)}, child.Attributes)
+}
+
+func TestUnsampled(t *testing.T) {
+	tracer, exporter := newTestTracer()
+
+	ctx, err := ContextWithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.NoError(t, err)
+	ctx, span := tracer.Start(ctx, "job", trace.SpanKindConsumer)
+	span.End()
+
+	// the context is still propagated
+	header := http.Header{}
+	Inject(ctx, header)
+	require.Regexp(t, "^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-00$", header.Get("traceparent"))
+	require.Nil(t, exporter.span("job"))
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	ctx, span := tracer.Start(context.Background(), "job", trace.SpanKindInternal)
+	require.False(t, span.IsRecording())
+	span.SetAttributes(attribute.String("job.id", "1"))
+	RecordError(span, errors.New("failed"))
+	span.End()
+
+	_, child := Start(ctx, "build")
+	require.False(t, child.IsRecording())
+
+	next := http.NotFoundHandler()
+	require.NotNil(t, tracer.Middleware(next))
+	require.NoError(t, tracer.Shutdown(context.Background()))
+}
+
+func TestExport(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests <- r
+	}))
+	defer collector.Close()
+
+	tracer, err := NewTracer("test", collector.URL+"/otlp/")
+	require.NoError(t, err)
+
+	_, span := tracer.Start(context.Background(), "job", trace.SpanKindConsumer)
+	span.End()
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	r := <-requests
+	require.Equal(t, "POST", r.Method)
+	require.Equal(t, "/otlp/v1/traces", r.URL.Path)
+
+	for _, invalid := range []string{"", "localhost:4318", "ftp://localhost:4318", "http://"} {
+		_, err := NewTracer("test", invalid)
+		require.Error(t, err, invalid)
+	}
+}
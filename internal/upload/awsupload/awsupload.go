@@ -1,13 +1,17 @@
 package awsupload
 
 import (
+	"crypto/tls"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -21,24 +25,124 @@ type AWS struct {
 	s3       *s3.S3
 }
 
+// Credentials are access keys, or an IAM role which is assumed with them.
+// A role without access keys is assumed with the default credentials of the
+// environment, such as the ones of the instance profile.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	RoleARN         string
+	ExternalID      string
+}
+
 func New(region, accessKeyID, accessKey, sessionToken string) (*AWS, error) {
-	// Session credentials
-	creds := credentials.NewStaticCredentials(accessKeyID, accessKey, sessionToken)
+	creds := Credentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: accessKey,
+		SessionToken:    sessionToken,
+	}
+	return NewWithCredentials(region, creds, creds)
+}
+
+// NewWithCredentials creates a client which uses `s3Creds` to upload
+// images to S3 and `ec2Creds` to import and register them.
+func NewWithCredentials(region string, s3Creds, ec2Creds Credentials) (*AWS, error) {
+	s3Sess, err := newSession(region, s3Creds)
+	if err != nil {
+		return nil, err
+	}
+
+	ec2Sess := s3Sess
+	if ec2Creds != s3Creds {
+		ec2Sess, err = newSession(region, ec2Creds)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// Create a Session with a custom region
-	sess, err := session.NewSession(&aws.Config{
-		Credentials: creds,
-		Region:      aws.String(region),
+	return &AWS{
+		uploader: s3manager.NewUploader(s3Sess),
+		ec2:      ec2.New(ec2Sess),
+		s3:       s3.New(s3Sess),
+	}, nil
+}
+
+// newSession creates a session for `region` with the credentials `creds`.
+func newSession(region string, creds Credentials) (*session.Session, error) {
+	config := &aws.Config{
+		Region: aws.String(region),
+	}
+	if creds.AccessKeyID != "" || creds.RoleARN == "" {
+		config.Credentials = credentials.NewStaticCredentials(creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken)
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	if creds.RoleARN == "" {
+		return sess, nil
+	}
+
+	// The temporary credentials of the role expire after 15 minutes and
+	// are renewed by the next request after that, which matters for
+	// snapshot imports that take longer.
+	return session.NewSession(&aws.Config{
+		Region: aws.String(region),
+		Credentials: stscreds.NewCredentials(sess, creds.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+			if creds.ExternalID != "" {
+				p.ExternalID = aws.String(creds.ExternalID)
+			}
+		}),
 	})
+}
+
+// NewForEndpoint creates a client for an S3-compatible service other than
+// AWS, such as MinIO or Ceph RGW, which listens at `endpoint`. Buckets are
+// addressed in the path instead of the host name, because these services
+// usually don't have DNS entries for buckets.
+//
+// `caBundle` may contain PEM-encoded certificates which are trusted instead
+// of the system's ones, for services with self-signed certificates.
+// Alternatively, `skipSSLVerification` disables verifying the service's
+// certificate altogether.
+func NewForEndpoint(endpoint, region, accessKeyID, accessKey, sessionToken, caBundle string, skipSSLVerification bool) (*AWS, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: skipSSLVerification,
+	}
+
+	options := session.Options{
+		Config: aws.Config{
+			Credentials:      credentials.NewStaticCredentials(accessKeyID, accessKey, sessionToken),
+			Region:           aws.String(region),
+			Endpoint:         aws.String(endpoint),
+			S3ForcePathStyle: aws.Bool(true),
+			HTTPClient:       &http.Client{Transport: transport},
+		},
+	}
+
+	// The session loads the bundle into the transport. This takes
+	// precedence over the AWS_CA_BUNDLE environment variable.
+	if caBundle != "" {
+		options.CustomCABundle = strings.NewReader(caBundle)
+	}
+
+	sess, err := session.NewSessionWithOptions(options)
 	if err != nil {
 		return nil, err
 	}
 
+	return newForSession(sess), nil
+}
+
+func newForSession(sess *session.Session) *AWS {
 	return &AWS{
 		uploader: s3manager.NewUploader(sess),
 		ec2:      ec2.New(sess),
 		s3:       s3.New(sess),
-	}, nil
+	}
 }
 
 func (a *AWS) Upload(filename, bucket, key string) (*s3manager.UploadOutput, error) {
@@ -116,8 +220,9 @@ func WaitUntilImportSnapshotTaskCompletedWithContext(c *ec2.EC2, ctx aws.Context
 
 // Register is a function that imports a snapshot, waits for the snapshot to
 // fully import, tags the snapshot, cleans up the image in S3, and registers
-// an AMI in AWS.
-func (a *AWS) Register(name, bucket, key string, shareWith []string, rpmArch string) (*string, error) {
+// an AMI in AWS. The snapshot and the AMI are tagged with `name` and `tags`,
+// and shared with the accounts `shareWith`.
+func (a *AWS) Register(name, bucket, key string, shareWith []string, rpmArch string, tags map[string]string) (*string, error) {
 	rpmArchToEC2Arch := map[string]string{
 		"x86_64":  "x86_64",
 		"aarch64": "arm64",
@@ -182,39 +287,13 @@ func (a *AWS) Register(name, bucket, key string, shareWith []string, rpmArch str
 
 	snapshotID := importOutput.ImportSnapshotTasks[0].SnapshotTaskDetail.SnapshotId
 
-	if len(shareWith) > 0 {
-		log.Printf("[AWS] 🎥 Sharing ec2 snapshot")
-		var userIds []*string
-		for _, v := range shareWith {
-			userIds = append(userIds, &v)
-		}
-		_, err := a.ec2.ModifySnapshotAttribute(
-			&ec2.ModifySnapshotAttributeInput{
-				Attribute:     aws.String("createVolumePermission"),
-				OperationType: aws.String("add"),
-				SnapshotId:    snapshotID,
-				UserIds:       userIds,
-			},
-		)
-		if err != nil {
-			return nil, err
-		}
-		log.Println("[AWS] 📨 Shared ec2 snapshot")
+	err = a.shareSnapshots([]*string{snapshotID}, shareWith)
+	if err != nil {
+		return nil, err
 	}
 
 	// Tag the snapshot with the image name.
-	req, _ := a.ec2.CreateTagsRequest(
-		&ec2.CreateTagsInput{
-			Resources: []*string{snapshotID},
-			Tags: []*ec2.Tag{
-				{
-					Key:   aws.String("Name"),
-					Value: aws.String(name),
-				},
-			},
-		},
-	)
-	err = req.Send()
+	err = a.tag([]*string{snapshotID}, name, tags)
 	if err != nil {
 		return nil, err
 	}
@@ -244,45 +323,166 @@ func (a *AWS) Register(name, bucket, key string, shareWith []string, rpmArch str
 	log.Printf("[AWS] 🎉 AMI registered: %s", *registerOutput.ImageId)
 
 	// Tag the image with the image name.
-	req, _ = a.ec2.CreateTagsRequest(
-		&ec2.CreateTagsInput{
-			Resources: []*string{registerOutput.ImageId},
-			Tags: []*ec2.Tag{
-				{
-					Key:   aws.String("Name"),
-					Value: aws.String(name),
-				},
-			},
+	err = a.tag([]*string{registerOutput.ImageId}, name, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.shareImage(registerOutput.ImageId, shareWith)
+	if err != nil {
+		return nil, err
+	}
+
+	return registerOutput.ImageId, nil
+}
+
+// CopyImage copies the AMI `ami` from `sourceRegion` to the region of the
+// client and waits until the copy is available. The copy and its snapshots
+// are tagged and shared like those of an AMI created with Register(). Returns
+// the id of the copy.
+func (a *AWS) CopyImage(name, ami, sourceRegion string, shareWith []string, tags map[string]string) (string, error) {
+	log.Printf("[AWS] 📋 Copying AMI %s from %s", ami, sourceRegion)
+	copyOutput, err := a.ec2.CopyImage(
+		&ec2.CopyImageInput{
+			Name:          aws.String(name),
+			SourceImageId: aws.String(ami),
+			SourceRegion:  aws.String(sourceRegion),
 		},
 	)
-	err = req.Send()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	if len(shareWith) > 0 {
-		log.Println("[AWS] 💿 Sharing ec2 AMI")
-		var launchPerms []*ec2.LaunchPermission
-		for _, id := range shareWith {
-			launchPerms = append(launchPerms, &ec2.LaunchPermission{
-				UserId: &id,
-			})
+	log.Printf("[AWS] 🚚 Waiting for AMI copy to become available: %s", *copyOutput.ImageId)
+	describeInput := &ec2.DescribeImagesInput{
+		ImageIds: []*string{copyOutput.ImageId},
+	}
+	err = a.ec2.WaitUntilImageAvailableWithContext(
+		aws.BackgroundContext(),
+		describeInput,
+		request.WithWaiterDelay(request.ConstantWaiterDelay(15*time.Second)),
+		request.WithWaiterMaxAttempts(240),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// The copy has its own snapshots
+	describeOutput, err := a.ec2.DescribeImages(describeInput)
+	if err != nil {
+		return "", err
+	}
+	if len(describeOutput.Images) != 1 {
+		return "", fmt.Errorf("AMI copy %s not found", *copyOutput.ImageId)
+	}
+	var snapshotIDs []*string
+	for _, mapping := range describeOutput.Images[0].BlockDeviceMappings {
+		if mapping.Ebs != nil && mapping.Ebs.SnapshotId != nil {
+			snapshotIDs = append(snapshotIDs, mapping.Ebs.SnapshotId)
 		}
-		_, err := a.ec2.ModifyImageAttribute(
-			&ec2.ModifyImageAttributeInput{
-				ImageId: registerOutput.ImageId,
-				LaunchPermission: &ec2.LaunchPermissionModifications{
-					Add: launchPerms,
-				},
+	}
+
+	err = a.tag(append(snapshotIDs, copyOutput.ImageId), name, tags)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.shareSnapshots(snapshotIDs, shareWith)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.shareImage(copyOutput.ImageId, shareWith)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("[AWS] 🎉 AMI copied: %s", *copyOutput.ImageId)
+	return *copyOutput.ImageId, nil
+}
+
+// Tags `resources` with the name `name` and the additional `tags`.
+func (a *AWS) tag(resources []*string, name string, tags map[string]string) error {
+	ec2Tags := []*ec2.Tag{
+		{
+			Key:   aws.String("Name"),
+			Value: aws.String(name),
+		},
+	}
+	for key, value := range tags {
+		ec2Tags = append(ec2Tags, &ec2.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(value),
+		})
+	}
+
+	req, _ := a.ec2.CreateTagsRequest(
+		&ec2.CreateTagsInput{
+			Resources: resources,
+			Tags:      ec2Tags,
+		},
+	)
+	return req.Send()
+}
+
+// Allows the accounts `shareWith` to create volumes from the snapshots
+// `snapshotIDs`.
+func (a *AWS) shareSnapshots(snapshotIDs []*string, shareWith []string) error {
+	if len(shareWith) == 0 {
+		return nil
+	}
+
+	var userIds []*string
+	for _, id := range shareWith {
+		userIds = append(userIds, aws.String(id))
+	}
+
+	for _, snapshotID := range snapshotIDs {
+		log.Printf("[AWS] 🎥 Sharing ec2 snapshot %s", *snapshotID)
+		_, err := a.ec2.ModifySnapshotAttribute(
+			&ec2.ModifySnapshotAttributeInput{
+				Attribute:     aws.String("createVolumePermission"),
+				OperationType: aws.String("add"),
+				SnapshotId:    snapshotID,
+				UserIds:       userIds,
 			},
 		)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		log.Println("[AWS] 💿 Shared AMI")
 	}
+	log.Println("[AWS] 📨 Shared ec2 snapshot")
 
-	return registerOutput.ImageId, nil
+	return nil
+}
+
+// Allows the accounts `shareWith` to launch the AMI `imageID`.
+func (a *AWS) shareImage(imageID *string, shareWith []string) error {
+	if len(shareWith) == 0 {
+		return nil
+	}
+
+	log.Println("[AWS] 💿 Sharing ec2 AMI")
+	var launchPerms []*ec2.LaunchPermission
+	for _, id := range shareWith {
+		launchPerms = append(launchPerms, &ec2.LaunchPermission{
+			UserId: aws.String(id),
+		})
+	}
+	_, err := a.ec2.ModifyImageAttribute(
+		&ec2.ModifyImageAttributeInput{
+			ImageId: imageID,
+			LaunchPermission: &ec2.LaunchPermissionModifications{
+				Add: launchPerms,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	log.Println("[AWS] 💿 Shared AMI")
+
+	return nil
 }
 
 func (a *AWS) S3ObjectPresignedURL(bucket, objectKey string) (string, error) {
@@ -0,0 +1,66 @@
+package awsupload_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/upload/awsupload"
+)
+
+func TestNewForEndpoint(t *testing.T) {
+	var uploaded []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "PUT", r.Method)
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "image data", string(body))
+		uploaded = append(uploaded, r.URL.Path)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "awsupload-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := path.Join(dir, "image.qcow2")
+	err = ioutil.WriteFile(filename, []byte("image data"), 0600)
+	require.NoError(t, err)
+
+	caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		a, err := awsupload.NewForEndpoint(server.URL, "us-east-1", "key-id", "key", "", "", false)
+		require.NoError(t, err)
+		_, err = a.Upload(filename, "bucket", "untrusted")
+		require.Error(t, err)
+	})
+
+	t.Run("ca bundle", func(t *testing.T) {
+		a, err := awsupload.NewForEndpoint(server.URL, "us-east-1", "key-id", "key", "", caBundle, false)
+		require.NoError(t, err)
+		_, err = a.Upload(filename, "bucket", "ca-bundle")
+		require.NoError(t, err)
+	})
+
+	t.Run("skip verification", func(t *testing.T) {
+		a, err := awsupload.NewForEndpoint(server.URL, "us-east-1", "key-id", "key", "", "", true)
+		require.NoError(t, err)
+		_, err = a.Upload(filename, "bucket", "skip-verification")
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid ca bundle", func(t *testing.T) {
+		_, err := awsupload.NewForEndpoint(server.URL, "us-east-1", "key-id", "key", "", "not a certificate", false)
+		require.Error(t, err)
+	})
+
+	// buckets are addressed in the path
+	require.Equal(t, []string{"/bucket/ca-bundle", "/bucket/skip-verification"}, uploaded)
+}
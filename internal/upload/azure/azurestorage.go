@@ -22,6 +22,10 @@ import (
 // see the docs: https://docs.microsoft.com/en-us/rest/api/storageservices/
 type StorageClient struct {
 	pipeline pipeline.Pipeline
+
+	// Shared access signature appended to all URLs, when the client
+	// authenticates with a SAS token instead of a storage account key.
+	sasToken string
 }
 
 // NewStorageClient creates a new client for Azure Storage API.
@@ -40,6 +44,33 @@ func NewStorageClient(storageAccount, storageAccessKey string) (*StorageClient,
 	}, nil
 }
 
+// NewStorageClientWithSASToken creates a new client for Azure Storage API,
+// which authenticates with a shared access signature (SAS) token instead of a
+// storage account key. To upload page blobs, the token must grant read,
+// create, and write permissions. See:
+// https://docs.microsoft.com/en-us/azure/storage/common/storage-sas-overview
+func NewStorageClientWithSASToken(sasToken string) *StorageClient {
+	p := azblob.NewPipeline(azblob.NewAnonymousCredential(), azblob.PipelineOptions{})
+	return &StorageClient{
+		pipeline: p,
+		sasToken: strings.TrimPrefix(sasToken, "?"),
+	}
+}
+
+// Returns the URL of the container `name` in `storageAccount`, including the
+// client's SAS token if it has one.
+func (c StorageClient) containerURL(storageAccount, name string) azblob.ContainerURL {
+	URL, _ := url.Parse(fmt.Sprintf("https://%s.blob.core.windows.net/%s", storageAccount, name))
+	URL.RawQuery = c.sasToken
+	return azblob.NewContainerURL(*URL, c.pipeline)
+}
+
+// BlobURL returns the URL of the blob described by `metadata`, without any
+// credentials.
+func BlobURL(metadata BlobMetadata) string {
+	return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", metadata.StorageAccount, metadata.ContainerName, metadata.BlobName)
+}
+
 // BlobMetadata contains information needed to store the image in a proper place.
 // In case of Azure cloud storage this includes container name and blob name.
 type BlobMetadata struct {
@@ -55,17 +86,21 @@ const DefaultUploadThreads = 16
 // It can speed up the upload by using goroutines. The number of parallel goroutines is bounded by
 // the `threads` argument.
 func (c StorageClient) UploadPageBlob(metadata BlobMetadata, fileName string, threads int) error {
+	return c.UploadPageBlobWithProgress(metadata, fileName, threads, nil)
+}
+
+// UploadPageBlobWithProgress works like UploadPageBlob, but calls `progress`
+// with the number of bytes uploaded so far and the total size of the image
+// after each uploaded page. Calls to `progress` are serialized. It may be nil.
+func (c StorageClient) UploadPageBlobWithProgress(metadata BlobMetadata, fileName string, threads int, progress func(uploaded, total int64)) error {
 	// Azure cannot create an image from a storage blob without .vhd extension
 	if !strings.HasSuffix(metadata.BlobName, ".vhd") {
 		metadata.BlobName = metadata.BlobName + ".vhd"
 	}
 
-	// get storage account blob service URL endpoint.
-	URL, _ := url.Parse(fmt.Sprintf("https://%s.blob.core.windows.net/%s", metadata.StorageAccount, metadata.ContainerName))
-
 	// Create a ContainerURL object that wraps the container URL and a request
 	// pipeline to make requests.
-	containerURL := azblob.NewContainerURL(*URL, c.pipeline)
+	containerURL := c.containerURL(metadata.StorageAccount, metadata.ContainerName)
 
 	// Create the container, use a never-expiring context
 	ctx := context.Background()
@@ -115,6 +150,8 @@ func (c StorageClient) UploadPageBlob(metadata BlobMetadata, fileName string, th
 	// Forward error from goroutine to the caller
 	var errorInGoroutine = make(chan error, 1)
 	var counter int64 = 0
+	var uploaded int64 = 0
+	var progressMutex sync.Mutex
 
 	// Create buffered reader to speed up the upload
 	reader := bufio.NewReader(imageFile)
@@ -138,7 +175,7 @@ func (c StorageClient) UploadPageBlob(metadata BlobMetadata, fileName string, th
 		semaphore <- 1
 		go func(counter int64, buffer []byte, n int) {
 			defer wg.Done()
-			_, err = blobURL.UploadPages(ctx, counter*azblob.PageBlobMaxUploadPagesBytes, bytes.NewReader(buffer[:n]), azblob.PageBlobAccessConditions{}, nil, azblob.ClientProvidedKeyOptions{})
+			_, err := blobURL.UploadPages(ctx, counter*azblob.PageBlobMaxUploadPagesBytes, bytes.NewReader(buffer[:n]), azblob.PageBlobAccessConditions{}, nil, azblob.ClientProvidedKeyOptions{})
 			if err != nil {
 				err = fmt.Errorf("uploading a page failed: %v", err)
 				// Send the error to the error channel in a non-blocking way. If there is already an error, just discard this one
@@ -146,6 +183,11 @@ func (c StorageClient) UploadPageBlob(metadata BlobMetadata, fileName string, th
 				case errorInGoroutine <- err:
 				default:
 				}
+			} else if progress != nil {
+				progressMutex.Lock()
+				uploaded += int64(n)
+				progress(uploaded, stat.Size())
+				progressMutex.Unlock()
 			}
 			<-semaphore
 		}(counter, buffer, n)
@@ -178,8 +220,7 @@ func (c StorageClient) UploadPageBlob(metadata BlobMetadata, fileName string, th
 // a storage account. If a container with the same name already exists,
 // this method is no-op.
 func (c StorageClient) CreateStorageContainerIfNotExist(ctx context.Context, storageAccount, name string) error {
-	URL, _ := url.Parse(fmt.Sprintf("https://%s.blob.core.windows.net/%s", storageAccount, name))
-	containerURL := azblob.NewContainerURL(*URL, c.pipeline)
+	containerURL := c.containerURL(storageAccount, name)
 
 	_, err := containerURL.Create(ctx, azblob.Metadata{}, azblob.PublicAccessNone)
 	if err != nil {
@@ -15,3 +15,20 @@ func TestRandomStorageAccountName(t *testing.T) {
 	r := regexp.MustCompile(`^[\d\w]{24}$`)
 	assert.True(t, r.MatchString(randomName), "the returned name should be 24 characters long and contain only alphanumerical characters")
 }
+
+func TestContainerURLWithSASToken(t *testing.T) {
+	c := NewStorageClientWithSASToken("?sv=2020-02-10&sig=secret")
+
+	containerURL := c.containerURL("account", "container")
+	assert.Equal(t, "https://account.blob.core.windows.net/container?sv=2020-02-10&sig=secret", containerURL.String())
+
+	blobURL := containerURL.NewPageBlobURL("image.vhd")
+	assert.Equal(t, "https://account.blob.core.windows.net/container/image.vhd?sv=2020-02-10&sig=secret", blobURL.String())
+
+	// the public URL never contains the token
+	assert.Equal(t, "https://account.blob.core.windows.net/container/image.vhd", BlobURL(BlobMetadata{
+		StorageAccount: "account",
+		ContainerName:  "container",
+		BlobName:       "image.vhd",
+	}))
+}
@@ -11,6 +11,13 @@ type Credentials struct {
 	clientSecret string
 }
 
+func NewCredentials(clientID, clientSecret string) *Credentials {
+	return &Credentials{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+	}
+}
+
 // ParseAzureCredentialsFile parses a credentials file for azure.
 // The file is in toml format and contains two keys: client_id and
 // client_secret
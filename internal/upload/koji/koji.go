@@ -2,6 +2,7 @@ package koji
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/json"
 	"errors"
@@ -12,6 +13,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 
 	"github.com/kolo/xmlrpc"
 	"github.com/ubccr/kerby/khttp"
@@ -43,6 +45,9 @@ type ImageBuild struct {
 	StartTime int64           `json:"start_time"`
 	EndTime   int64           `json:"end_time"`
 	Extra     ImageBuildExtra `json:"extra"`
+
+	// Whether the build was initialized as a draft build
+	Draft bool `json:"draft,omitempty"`
 }
 
 type Host struct {
@@ -213,15 +218,28 @@ func (k *Koji) Logout() error {
 
 // CGInitBuild reserves a build ID and initializes a build
 func (k *Koji) CGInitBuild(name, version, release string) (*CGInitBuildResult, error) {
+	return k.cgInitBuild(name, version, release, false)
+}
+
+// CGInitDraftBuild reserves a build ID and initializes a draft build, which
+// doesn't claim the NVR of the build and has to be promoted to become a
+// regular build
+func (k *Koji) CGInitDraftBuild(name, version, release string) (*CGInitBuildResult, error) {
+	return k.cgInitBuild(name, version, release, true)
+}
+
+func (k *Koji) cgInitBuild(name, version, release string, draft bool) (*CGInitBuildResult, error) {
 	var buildInfo struct {
 		Name    string `xmlrpc:"name"`
 		Version string `xmlrpc:"version"`
 		Release string `xmlrpc:"release"`
+		Draft   bool   `xmlrpc:"draft,omitempty"`
 	}
 
 	buildInfo.Name = name
 	buildInfo.Version = version
 	buildInfo.Release = release
+	buildInfo.Draft = draft
 
 	var result CGInitBuildResult
 	err := k.xmlrpc.Call("CGInitBuild", []interface{}{"osbuild", buildInfo}, &result)
@@ -281,6 +299,71 @@ func (k *Koji) CGImport(build ImageBuild, buildRoots []BuildRoot, images []Image
 	return &result, nil
 }
 
+// TagBuild starts a task which tags the build into the tag, and returns the
+// ID of the task
+func (k *Koji) TagBuild(tag string, buildID int) (int, error) {
+	var taskID int
+	err := k.xmlrpc.Call("tagBuild", []interface{}{tag, buildID}, &taskID)
+	if err != nil {
+		return 0, err
+	}
+
+	return taskID, nil
+}
+
+/* from `koji/__init__.py`
+TASK_STATES = Enum((
+    'FREE',
+    'OPEN',
+    'CLOSED',
+    'CANCELED',
+    'ASSIGNED',
+    'FAILED',
+))
+*/
+const (
+	_ = iota /* FREE */
+	_        /* OPEN */
+	taskStateClosed
+	taskStateCanceled
+	_ /* ASSIGNED */
+	taskStateFailed
+)
+
+// WaitForTask polls the state of the task every `interval` until it has
+// finished, and returns an error if it didn't succeed
+func (k *Koji) WaitForTask(ctx context.Context, taskID int, interval time.Duration) error {
+	for {
+		var info struct {
+			State int `xmlrpc:"state"`
+		}
+		err := k.xmlrpc.Call("getTaskInfo", []interface{}{taskID}, &info)
+		if err != nil {
+			return err
+		}
+
+		switch info.State {
+		case taskStateClosed:
+			return nil
+		case taskStateCanceled:
+			return fmt.Errorf("task %d was canceled", taskID)
+		case taskStateFailed:
+			var result interface{}
+			err = k.xmlrpc.Call("getTaskResult", []interface{}{taskID}, &result)
+			if err != nil {
+				return fmt.Errorf("task %d failed: %v", taskID, err)
+			}
+			return fmt.Errorf("task %d failed", taskID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
 // uploadChunk uploads a byte slice to a given filepath/filname at a given offset
 func (k *Koji) uploadChunk(chunk []byte, filepath, filename string, offset uint64) error {
 	// We have to open-code a bastardized version of XML-RPC: We send an octet-stream, as
@@ -0,0 +1,91 @@
+package oci
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Credentials of an OCI user, who authenticates by signing requests with an
+// API signing key. See:
+// https://docs.oracle.com/en-us/iaas/Content/API/Concepts/apisigningkey.htm
+type Credentials struct {
+	User        string
+	Tenancy     string
+	Fingerprint string
+	PrivateKey  *rsa.PrivateKey
+}
+
+func (c *Credentials) keyID() string {
+	return c.Tenancy + "/" + c.User + "/" + c.Fingerprint
+}
+
+// ParseOCICredentialsFile parses a credentials file for OCI. The file is in
+// toml format and contains the OCIDs of the user and its tenancy, the
+// fingerprint of the API signing key, and the path to the PEM-encoded private
+// key.
+//
+// Example of the file:
+// user        = "ocid1.user.oc1..aaaa"
+// tenancy     = "ocid1.tenancy.oc1..aaaa"
+// fingerprint = "12:34:56:78:9a:bc:de:f0:12:34:56:78:9a:bc:de:f0"
+// key_file    = "/etc/osbuild-worker/oci-key.pem"
+func ParseOCICredentialsFile(filename string) (*Credentials, error) {
+	var creds struct {
+		User        string `toml:"user"`
+		Tenancy     string `toml:"tenancy"`
+		Fingerprint string `toml:"fingerprint"`
+		KeyFile     string `toml:"key_file"`
+	}
+	_, err := toml.DecodeFile(filename, &creds)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse oci credentials: %v", err)
+	}
+
+	keyPEM, err := ioutil.ReadFile(creds.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read oci private key: %v", err)
+	}
+
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse oci private key: %v", err)
+	}
+
+	return &Credentials{
+		User:        creds.User,
+		Tenancy:     creds.Tenancy,
+		Fingerprint: creds.Fingerprint,
+		PrivateKey:  key,
+	}, nil
+}
+
+// Parses an RSA private key in PKCS #1 or PKCS #8 form, which is what
+// `oci setup keys` and `openssl genrsa` generate.
+func parsePrivateKey(keyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
@@ -0,0 +1,267 @@
+// Package oci implements the few calls to the Oracle Cloud Infrastructure API
+// which are needed to upload and import images: putting objects into Object
+// Storage and creating custom images from them.
+//
+// Requests are signed as described in
+// https://docs.oracle.com/en-us/iaas/Content/API/Concepts/signingrequests.htm
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client is a client for the OCI Object Storage and Core Services APIs of
+// one region.
+type Client struct {
+	creds      Credentials
+	httpClient *http.Client
+
+	objectStorageEndpoint string
+	coreEndpoint          string
+
+	// How often WaitForImage() checks the state of an image.
+	pollInterval time.Duration
+}
+
+// NewClient creates a client for the OCI region `region`, e.g.,
+// "eu-frankfurt-1".
+func NewClient(creds Credentials, region string) *Client {
+	return &Client{
+		creds:                 creds,
+		httpClient:            &http.Client{},
+		objectStorageEndpoint: fmt.Sprintf("https://objectstorage.%s.oraclecloud.com", region),
+		coreEndpoint:          fmt.Sprintf("https://iaas.%s.oraclecloud.com", region),
+		pollInterval:          30 * time.Second,
+	}
+}
+
+// UploadObject uploads `size` bytes from `reader` into `object` in `bucket`.
+// `namespace` is the Object Storage namespace of the tenancy the bucket
+// belongs to.
+func (c *Client) UploadObject(ctx context.Context, namespace, bucket, object string, reader io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.objectURL(namespace, bucket, object), reader)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	// Object Storage doesn't require signing the body of PutObject
+	// requests, which allows streaming it.
+	err = c.sign(req, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error uploading object: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errorFromResponse(response, "error uploading object")
+	}
+
+	return nil
+}
+
+// DeleteObject deletes `object` from `bucket`.
+func (c *Client) DeleteObject(ctx context.Context, namespace, bucket, object string) error {
+	_, err := c.do(ctx, "DELETE", c.objectURL(namespace, bucket, object), nil, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting object: %v", err)
+	}
+	return nil
+}
+
+type imageSourceDetails struct {
+	SourceType      string `json:"sourceType"`
+	NamespaceName   string `json:"namespaceName"`
+	BucketName      string `json:"bucketName"`
+	ObjectName      string `json:"objectName"`
+	SourceImageType string `json:"sourceImageType"`
+}
+
+type createImageRequest struct {
+	CompartmentID      string             `json:"compartmentId"`
+	DisplayName        string             `json:"displayName"`
+	LaunchMode         string             `json:"launchMode"`
+	ImageSourceDetails imageSourceDetails `json:"imageSourceDetails"`
+}
+
+type image struct {
+	ID             string `json:"id"`
+	LifecycleState string `json:"lifecycleState"`
+}
+
+// ImportImage creates a custom image named `displayName` in `compartment` from
+// the QCOW2 image in `object`. Returns the OCID of the new image. Importing
+// happens in the background, use WaitForImage() to wait until it finished.
+func (c *Client) ImportImage(ctx context.Context, compartment, namespace, bucket, object, displayName string) (string, error) {
+	request := createImageRequest{
+		CompartmentID: compartment,
+		DisplayName:   displayName,
+		LaunchMode:    "PARAVIRTUALIZED",
+		ImageSourceDetails: imageSourceDetails{
+			SourceType:      "objectStorageTuple",
+			NamespaceName:   namespace,
+			BucketName:      bucket,
+			ObjectName:      object,
+			SourceImageType: "QCOW2",
+		},
+	}
+
+	var img image
+	_, err := c.do(ctx, "POST", c.coreEndpoint+"/20160918/images", request, &img)
+	if err != nil {
+		return "", fmt.Errorf("error importing image: %v", err)
+	}
+
+	return img.ID, nil
+}
+
+// WaitForImage waits until the image with the OCID `id` finished importing.
+// Returns an error if the import failed.
+func (c *Client) WaitForImage(ctx context.Context, id string) error {
+	for {
+		var img image
+		_, err := c.do(ctx, "GET", c.coreEndpoint+"/20160918/images/"+url.PathEscape(id), nil, &img)
+		if err != nil {
+			return fmt.Errorf("error getting image state: %v", err)
+		}
+
+		switch img.LifecycleState {
+		case "AVAILABLE":
+			return nil
+		case "PROVISIONING", "IMPORTING":
+		default:
+			return fmt.Errorf("image import failed, image is in state %s", img.LifecycleState)
+		}
+
+		select {
+		case <-time.After(c.pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Client) objectURL(namespace, bucket, object string) string {
+	return fmt.Sprintf("%s/n/%s/b/%s/o/%s", c.objectStorageEndpoint, url.PathEscape(namespace), url.PathEscape(bucket), url.PathEscape(object))
+}
+
+// Sends a signed request with `body` encoded as JSON, if it isn't nil, and
+// decodes the response into `result`, if it isn't nil.
+func (c *Client) do(ctx context.Context, method, url string, body, result interface{}) (*http.Response, error) {
+	var rawBody []byte
+	if body != nil {
+		var err error
+		rawBody, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(rawBody))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	err = c.sign(req, rawBody)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, errorFromResponse(response, "request failed")
+	}
+
+	if result != nil {
+		err = json.NewDecoder(response.Body).Decode(result)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing response: %v", err)
+		}
+	}
+
+	return response, nil
+}
+
+// Signs `req` with the client's API signing key. `body` is the request's body
+// for POST and PUT requests which need it signed, or nil.
+func (c *Client) sign(req *http.Request, body []byte) error {
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+	headers := []string{"(request-target)", "date", "host"}
+	if body != nil && (req.Method == "POST" || req.Method == "PUT") {
+		hash := sha256.Sum256(body)
+		req.Header.Set("X-Content-Sha256", base64.StdEncoding.EncodeToString(hash[:]))
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		headers = append(headers, "x-content-sha256", "content-type", "content-length")
+	}
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.creds.PrivateKey, crypto.SHA256, signingHash(req, headers))
+	if err != nil {
+		return fmt.Errorf("error signing request: %v", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf(`Signature version="1",keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		c.creds.keyID(), strings.Join(headers, " "), base64.StdEncoding.EncodeToString(signature)))
+
+	return nil
+}
+
+// Returns the SHA-256 hash of the signing string of `req`, which consists of
+// the given headers.
+func signingHash(req *http.Request, headers []string) []byte {
+	var lines []string
+	for _, h := range headers {
+		switch h {
+		case "(request-target)":
+			lines = append(lines, fmt.Sprintf("(request-target): %s %s", strings.ToLower(req.Method), req.URL.RequestURI()))
+		case "host":
+			lines = append(lines, "host: "+req.URL.Host)
+		default:
+			lines = append(lines, h+": "+req.Header.Get(h))
+		}
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hash[:]
+}
+
+func errorFromResponse(response *http.Response, message string) error {
+	var e struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	if json.Unmarshal(body, &e) != nil || e.Message == "" {
+		return fmt.Errorf("%s: %s", message, response.Status)
+	}
+	return fmt.Errorf("%s: %s (%s): %s", message, response.Status, e.Code, e.Message)
+}
@@ -0,0 +1,111 @@
+package oci
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Verifies the signature in the Authorization header of `r` against `key`.
+func verifySignature(t *testing.T, r *http.Request, key *rsa.PublicKey) {
+	auth := r.Header.Get("Authorization")
+	match := regexp.MustCompile(`^Signature version="1",keyId="tenancy/user/fingerprint",algorithm="rsa-sha256",headers="([^"]*)",signature="([^"]*)"$`).FindStringSubmatch(auth)
+	require.NotNil(t, match, "unexpected Authorization header: %s", auth)
+
+	signature, err := base64.StdEncoding.DecodeString(match[2])
+	require.NoError(t, err)
+
+	// the server side request has no scheme and host in URL
+	r.URL.Host = r.Host
+	err = rsa.VerifyPKCS1v15(key, crypto.SHA256, signingHash(r, strings.Split(match[1], " ")), signature)
+	require.NoError(t, err)
+}
+
+func TestImportImage(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var uploaded []byte
+	states := []string{"IMPORTING", "IMPORTING", "AVAILABLE"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifySignature(t, r, &key.PublicKey)
+
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/n/ns/b/bucket/o/image.qcow2":
+			var err error
+			uploaded, err = ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+		case r.Method == "POST" && r.URL.Path == "/20160918/images":
+			require.NotEmpty(t, r.Header.Get("X-Content-Sha256"))
+			var req createImageRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Equal(t, "compartment", req.CompartmentID)
+			require.Equal(t, "my-image", req.DisplayName)
+			require.Equal(t, imageSourceDetails{
+				SourceType:      "objectStorageTuple",
+				NamespaceName:   "ns",
+				BucketName:      "bucket",
+				ObjectName:      "image.qcow2",
+				SourceImageType: "QCOW2",
+			}, req.ImageSourceDetails)
+			_, _ = w.Write([]byte(`{"id": "ocid1.image.oc1..test", "lifecycleState": "PROVISIONING"}`))
+
+		case r.Method == "GET" && r.URL.Path == "/20160918/images/ocid1.image.oc1..test":
+			require.NotEmpty(t, states)
+			state := states[0]
+			states = states[1:]
+			_, _ = w.Write([]byte(`{"id": "ocid1.image.oc1..test", "lifecycleState": "` + state + `"}`))
+
+		case r.Method == "DELETE" && r.URL.Path == "/n/ns/b/bucket/o/image.qcow2":
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code": "NotFound", "message": "no such thing"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{
+		User:        "user",
+		Tenancy:     "tenancy",
+		Fingerprint: "fingerprint",
+		PrivateKey:  key,
+	}, "test-region-1")
+	client.objectStorageEndpoint = server.URL
+	client.coreEndpoint = server.URL
+	client.pollInterval = time.Millisecond
+
+	ctx := context.Background()
+
+	err = client.UploadObject(ctx, "ns", "bucket", "image.qcow2", strings.NewReader("qcow2 data"), 10)
+	require.NoError(t, err)
+	require.Equal(t, "qcow2 data", string(uploaded))
+
+	id, err := client.ImportImage(ctx, "compartment", "ns", "bucket", "image.qcow2", "my-image")
+	require.NoError(t, err)
+	require.Equal(t, "ocid1.image.oc1..test", id)
+
+	err = client.WaitForImage(ctx, id)
+	require.NoError(t, err)
+	require.Empty(t, states)
+
+	err = client.DeleteObject(ctx, "ns", "bucket", "image.qcow2")
+	require.NoError(t, err)
+
+	err = client.DeleteObject(ctx, "ns", "bucket", "other.qcow2")
+	require.EqualError(t, err, "error deleting object: request failed: 404 Not Found (NotFound): no such thing")
+}
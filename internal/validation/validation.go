@@ -0,0 +1,156 @@
+// Package validation checks blueprints against the repositories and image
+// types they are built with, so that problems are reported before a compose
+// is started.
+package validation
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+type Severity string
+
+const (
+	// The compose would fail
+	SeverityError Severity = "error"
+	// The compose would succeed, but the image might not be what was
+	// intended
+	SeverityWarning Severity = "warning"
+)
+
+// A Diagnostic is a problem of a blueprint.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	// The part of the blueprint which has the problem, such as "packages"
+	// or "customizations"
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Target is what a blueprint is validated for.
+type Target struct {
+	RPMMD rpmmd.RPMMD
+	Arch  distro.Arch
+	// Customizations and the package sets of the image type are only
+	// checked if it is set
+	ImageType    distro.ImageType
+	ImageOptions distro.ImageOptions
+	Repositories []rpmmd.RepoConfig
+	// Repositories which are only used for the packages of the image, not
+	// for its build root
+	PayloadRepositories []rpmmd.RepoConfig
+}
+
+// Validate returns the problems of `bp` when it is built for `target`. The
+// packages of the blueprint are checked to exist first. If they do, the
+// package sets of the image type are depsolved and its manifest is created,
+// which checks whether the image type supports the customizations.
+func Validate(bp blueprint.Blueprint, target Target) []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	err := bp.Initialize()
+	if err != nil {
+		return append(diagnostics, Diagnostic{SeverityError, "", err.Error()})
+	}
+
+	diagnostics = append(diagnostics, duplicatePackages("packages", bp.Packages)...)
+	diagnostics = append(diagnostics, duplicatePackages("modules", bp.Modules)...)
+
+	repos := append(append([]rpmmd.RepoConfig{}, target.Repositories...), target.PayloadRepositories...)
+	available, _, err := target.RPMMD.FetchMetadata(repos, target.Arch.Distro().ModulePlatformID(), target.Arch.Name())
+	if err != nil {
+		return append(diagnostics, Diagnostic{SeverityWarning, "packages", fmt.Sprintf("The packages could not be checked: %v", err)})
+	}
+	diagnostics = append(diagnostics, missingPackages("packages", bp.Packages, available)...)
+	diagnostics = append(diagnostics, missingPackages("modules", bp.Modules, available)...)
+
+	if target.ImageType == nil || HasErrors(diagnostics) {
+		return diagnostics
+	}
+
+	packageSets := target.ImageType.PackageSets(bp)
+	if len(target.PayloadRepositories) > 0 {
+		packageSets["packages"] = packageSets["packages"].Append(rpmmd.PackageSet{Repositories: target.PayloadRepositories})
+	}
+	specs, err := rpmmd.DepsolvePackageSets(target.RPMMD, packageSets, target.Repositories, target.Arch.Distro().ModulePlatformID(), target.Arch.Name())
+	if err != nil {
+		return append(diagnostics, Diagnostic{SeverityError, "packages", fmt.Sprintf("The packages of image type %s cannot be installed: %v", target.ImageType.Name(), err)})
+	}
+
+	_, err = target.ImageType.Manifest(bp.Customizations, target.ImageOptions, repos, specs, 0)
+	if err != nil {
+		return append(diagnostics, Diagnostic{SeverityError, "customizations", err.Error()})
+	}
+
+	return diagnostics
+}
+
+// HasErrors returns whether any of `diagnostics` is an error.
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicatePackages warns about packages which are listed more than once,
+// of which only one version is installed.
+func duplicatePackages(field string, packages []blueprint.Package) []Diagnostic {
+	var diagnostics []Diagnostic
+	seen := make(map[string]bool)
+	for _, p := range packages {
+		if seen[p.Name] {
+			diagnostics = append(diagnostics, Diagnostic{SeverityWarning, field, fmt.Sprintf("Package %s is listed more than once", p.Name)})
+		}
+		seen[p.Name] = true
+	}
+	return diagnostics
+}
+
+// missingPackages returns an error for each of `packages` which isn't one of
+// the `available` ones, or of which no available version matches.
+func missingPackages(field string, packages []blueprint.Package, available rpmmd.PackageList) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, p := range packages {
+		// files and other provides are resolved by dnf
+		if strings.ContainsAny(p.Name, "/()") {
+			continue
+		}
+		// module streams are enabled by dnf, which reports unknown ones
+		if _, ok := p.ModuleStream(); ok {
+			continue
+		}
+
+		var found, versionFound bool
+		for _, a := range available {
+			if !globMatch(p.Name, a.Name) {
+				continue
+			}
+			found = true
+			if p.Version == "" || globMatch(p.Version, a.Version) || globMatch(p.Version, a.Version+"-"+a.Release) {
+				versionFound = true
+				break
+			}
+		}
+
+		if !found {
+			diagnostics = append(diagnostics, Diagnostic{SeverityError, field, fmt.Sprintf("Package %s is not available in the repositories", p.Name)})
+		} else if !versionFound {
+			diagnostics = append(diagnostics, Diagnostic{SeverityError, field, fmt.Sprintf("No version of package %s matching %s is available in the repositories", p.Name, p.Version)})
+		}
+	}
+	return diagnostics
+}
+
+// globMatch returns whether `name` matches the dnf glob `pattern`
+func globMatch(pattern, name string) bool {
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
@@ -0,0 +1,117 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro/test_distro"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+)
+
+type rpmmdFake struct {
+	packages rpmmd.PackageList
+	err      error
+	repos    []rpmmd.RepoConfig
+}
+
+func (r *rpmmdFake) FetchMetadata(repos []rpmmd.RepoConfig, modulePlatformID, arch string) (rpmmd.PackageList, map[string]string, error) {
+	r.repos = repos
+	return r.packages, nil, r.err
+}
+
+func (r *rpmmdFake) Depsolve(packageSet rpmmd.PackageSet, repos []rpmmd.RepoConfig, modulePlatformID, arch string) ([]rpmmd.PackageSpec, map[string]string, error) {
+	return nil, nil, r.err
+}
+
+func TestValidate(t *testing.T) {
+	arch, err := test_distro.New().GetArch(test_distro.TestArchName)
+	require.NoError(t, err)
+
+	available := rpmmd.PackageList{
+		{Name: "tmux", Version: "3.2a", Release: "4.fc36"},
+		{Name: "vim-enhanced", Version: "8.2.5172", Release: "1.fc36"},
+		{Name: "vim-minimal", Version: "8.2.5172", Release: "1.fc36"},
+	}
+
+	tests := []struct {
+		name     string
+		bp       blueprint.Blueprint
+		expected []Diagnostic
+	}{
+		{
+			name: "valid",
+			bp: blueprint.Blueprint{
+				Name: "valid",
+				Packages: []blueprint.Package{
+					{Name: "tmux"},
+					{Name: "vim-*", Version: "8.2.*"},
+					{Name: "/usr/bin/zsh"},
+				},
+				Modules: []blueprint.Package{{Name: "vim-minimal", Version: "8.2.5172-1.fc36"}, {Name: "nodejs:18/common"}, {Name: "ruby:3.1"}},
+			},
+			expected: []Diagnostic{},
+		},
+		{
+			name: "duplicates",
+			bp: blueprint.Blueprint{
+				Name:     "duplicates",
+				Packages: []blueprint.Package{{Name: "tmux"}, {Name: "tmux", Version: "3.2a"}},
+			},
+			expected: []Diagnostic{
+				{SeverityWarning, "packages", "Package tmux is listed more than once"},
+			},
+		},
+		{
+			name: "missing",
+			bp: blueprint.Blueprint{
+				Name:     "missing",
+				Packages: []blueprint.Package{{Name: "emacs"}, {Name: "tmux", Version: "3.3"}},
+				Modules:  []blueprint.Package{{Name: "nodejs*"}},
+			},
+			expected: []Diagnostic{
+				{SeverityError, "packages", "Package emacs is not available in the repositories"},
+				{SeverityError, "packages", "No version of package tmux matching 3.3 is available in the repositories"},
+				{SeverityError, "modules", "Package nodejs* is not available in the repositories"},
+			},
+		},
+		{
+			name: "invalid",
+			bp:   blueprint.Blueprint{Name: "invalid", Version: "one"},
+			expected: []Diagnostic{
+				{SeverityError, "", "Invalid 'version', must use Semantic Versioning: one is not in dotted-tri format"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := Validate(tt.bp, Target{
+				RPMMD: &rpmmdFake{packages: available},
+				Arch:  arch,
+			})
+			require.Equal(t, tt.expected, diagnostics)
+		})
+	}
+}
+
+func TestValidateFetchError(t *testing.T) {
+	arch, err := test_distro.New().GetArch(test_distro.TestArchName)
+	require.NoError(t, err)
+
+	rpm := &rpmmdFake{err: errors.New("repository unreachable")}
+	diagnostics := Validate(blueprint.Blueprint{Name: "fetch"}, Target{
+		RPMMD:               rpm,
+		Arch:                arch,
+		Repositories:        []rpmmd.RepoConfig{{Name: "base"}},
+		PayloadRepositories: []rpmmd.RepoConfig{{Name: "payload"}},
+	})
+	require.Equal(t, []Diagnostic{
+		{SeverityWarning, "packages", "The packages could not be checked: repository unreachable"},
+	}, diagnostics)
+	require.False(t, HasErrors(diagnostics))
+	// packages may come from the payload repositories as well
+	require.Len(t, rpm.repos, 2)
+}
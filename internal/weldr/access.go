@@ -0,0 +1,114 @@
+package weldr
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/osbuild/osbuild-composer/internal/rbac"
+)
+
+// Roles which may read composes, blueprints, and sources. Composes of the
+// weldr API have no owner, so everyone who may submit them may also read all
+// of them.
+var readRoles = []rbac.Role{rbac.SubmitCompose, rbac.ReadAnyCompose, rbac.Admin}
+
+// SetAccessPolicy makes the API check the roles of the local users who
+// connect to it. Without a policy, everyone who may open the socket may do
+// everything.
+func (api *API) SetAccessPolicy(policy *rbac.Policy) {
+	api.policy = policy
+}
+
+type contextKey int
+
+const peerKey contextKey = iota
+
+// peerContext stores the credentials of the user on the other end of `conn`,
+// as the kernel reports them, and the roles of that user in `ctx`.
+func (api *API) peerContext(ctx context.Context, conn net.Conn) context.Context {
+	if api.policy == nil && api.audit == nil {
+		return ctx
+	}
+
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return ctx
+	}
+	rawConn, err := unixConn.SyscallConn()
+	if err != nil {
+		log.Printf("Error getting the credentials of a weldr client: %v", err)
+		return ctx
+	}
+
+	var cred *unix.Ucred
+	err = rawConn.Control(func(fd uintptr) {
+		cred, err = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil {
+		log.Printf("Error getting the credentials of a weldr client: %v", err)
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, peerKey, cred)
+	if api.policy != nil {
+		ctx = rbac.NewContext(ctx, api.policy.PeerRoles(cred.Uid, cred.Gid))
+	}
+	return ctx
+}
+
+// allowed returns whether the user who made `request` has one of the roles
+// it requires. Users whose credentials are unknown have the default roles.
+func (api *API) allowed(request *http.Request) bool {
+	required := requiredRoles(request.Method, request.URL.Path)
+	if required == nil {
+		return true
+	}
+
+	roles, ok := rbac.FromContext(request.Context())
+	if !ok {
+		roles = api.policy.Default
+	}
+	return roles.Has(required...)
+}
+
+// requiredRoles returns the roles of which a user needs one to make a request
+// with `method` to `path`, or nil if everyone may make it.
+func requiredRoles(method, path string) []rbac.Role {
+	if path == "/api/status" {
+		return nil
+	}
+
+	// strip "/api/v{version}/"
+	route := strings.TrimPrefix(path, "/api/v")
+	if i := strings.Index(route, "/"); i >= 0 {
+		route = route[i+1:]
+	}
+
+	switch {
+	// upload providers contain credentials
+	case strings.HasPrefix(route, "upload/providers"):
+		return []rbac.Role{rbac.Admin}
+
+	case method == http.MethodGet:
+		return readRoles
+
+	// imports replace the history of blueprints
+	case route == "blueprints/import":
+		return []rbac.Role{rbac.Admin}
+
+	case method == http.MethodPost && route == "compose",
+		method == http.MethodPost && strings.HasPrefix(route, "compose/uploads/schedule/"),
+		method == http.MethodPost && route == "compose/schedule",
+		strings.HasPrefix(route, "blueprints/"):
+		return []rbac.Role{rbac.SubmitCompose, rbac.Admin}
+	}
+
+	// canceling and deleting composes, uploads, and compose schedules, and
+	// managing sources
+	return []rbac.Role{rbac.Admin}
+}
@@ -0,0 +1,66 @@
+package weldr
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	rpmmd_mock "github.com/osbuild/osbuild-composer/internal/mocks/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/rbac"
+)
+
+func TestRequiredRoles(t *testing.T) {
+	submit := []rbac.Role{rbac.SubmitCompose, rbac.Admin}
+	admin := []rbac.Role{rbac.Admin}
+
+	cases := []struct {
+		method, path string
+		roles        []rbac.Role
+	}{
+		{"GET", "/api/status", nil},
+		{"GET", "/api/v0/compose/queue", readRoles},
+		{"GET", "/api/v1/upload/providers", admin},
+		{"POST", "/api/v0/compose", submit},
+		{"POST", "/api/v1/compose/uploads/schedule/6ba7b810-9dad-11d1-80b4-00c04fd430c8", submit},
+		{"POST", "/api/v0/compose/schedule", submit},
+		{"GET", "/api/v0/compose/schedule", readRoles},
+		{"POST", "/api/v0/blueprints/new", submit},
+		{"DELETE", "/api/v0/blueprints/delete/test", submit},
+		{"POST", "/api/v0/blueprints/import", admin},
+		{"DELETE", "/api/v0/compose/delete/6ba7b810-9dad-11d1-80b4-00c04fd430c8", admin},
+		{"POST", "/api/v0/compose/cancel/6ba7b810-9dad-11d1-80b4-00c04fd430c8", admin},
+		{"DELETE", "/api/v0/compose/schedule/6ba7b810-9dad-11d1-80b4-00c04fd430c8", admin},
+		{"POST", "/api/v0/projects/source/new", admin},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.roles, requiredRoles(c.method, c.path), "%s %s", c.method, c.path)
+	}
+}
+
+func TestAccessPolicy(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "weldr-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	api, _ := createWeldrAPI(tempdir, rpmmd_mock.BaseFixture)
+	api.SetAccessPolicy(&rbac.Policy{})
+
+	request := func(path string, roles rbac.Roles) int {
+		req := httptest.NewRequest("GET", path, nil)
+		if roles != nil {
+			req = req.WithContext(rbac.NewContext(req.Context(), roles))
+		}
+		recorder := httptest.NewRecorder()
+		api.ServeHTTP(recorder, req)
+		return recorder.Result().StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, request("/api/status", nil))
+	require.Equal(t, http.StatusForbidden, request("/api/v0/blueprints/list", nil))
+	require.Equal(t, http.StatusForbidden, request("/api/v0/blueprints/list", rbac.Roles{rbac.Worker}))
+	require.Equal(t, http.StatusOK, request("/api/v0/blueprints/list", rbac.Roles{rbac.ReadAnyCompose}))
+}
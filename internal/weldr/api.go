@@ -3,6 +3,7 @@ package weldr
 import (
 	"archive/tar"
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/json"
 	errors_package "errors"
@@ -27,21 +28,27 @@ import (
 	"github.com/google/uuid"
 	"github.com/julienschmidt/httprouter"
 
+	"github.com/osbuild/osbuild-composer/internal/audit"
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
 	"github.com/osbuild/osbuild-composer/internal/common"
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+	"github.com/osbuild/osbuild-composer/internal/logging"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
 	"github.com/osbuild/osbuild-composer/internal/ostree"
+	"github.com/osbuild/osbuild-composer/internal/rbac"
 	"github.com/osbuild/osbuild-composer/internal/reporegistry"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/sentry"
 	"github.com/osbuild/osbuild-composer/internal/store"
 	"github.com/osbuild/osbuild-composer/internal/target"
+	"github.com/osbuild/osbuild-composer/internal/validation"
 	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
 )
 
 type API struct {
-	store   *store.Store
+	store   store.Store
 	workers *worker.Server
 
 	rpmmd        rpmmd.RPMMD
@@ -53,6 +60,15 @@ type API struct {
 	router *httprouter.Router
 
 	compatOutputDir string
+
+	// Grants roles to users, see SetAccessPolicy()
+	policy *rbac.Policy
+
+	// Records changes, see SetAuditLog()
+	audit *audit.Log
+
+	// Reports panics, see SetErrorReporter()
+	reporter *sentry.Client
 }
 
 type ComposeState int
@@ -94,7 +110,7 @@ func (api *API) systemRepoNames() (names []string) {
 
 var ValidBlueprintName = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
 
-func New(rpmmd rpmmd.RPMMD, arch distro.Arch, distro distro.Distro, repoRegistry *reporegistry.RepoRegistry, logger *log.Logger, store *store.Store, workers *worker.Server, compatOutputDir string) *API {
+func New(rpmmd rpmmd.RPMMD, arch distro.Arch, distro distro.Distro, repoRegistry *reporegistry.RepoRegistry, logger *log.Logger, store store.Store, workers *worker.Server, compatOutputDir string) *API {
 	api := &API{
 		store:           store,
 		workers:         workers,
@@ -143,6 +159,11 @@ func New(rpmmd rpmmd.RPMMD, arch distro.Arch, distro distro.Distro, repoRegistry
 	api.router.POST("/api/v:version/blueprints/workspace", api.blueprintsWorkspaceHandler)
 	api.router.POST("/api/v:version/blueprints/undo/:blueprint/:commit", api.blueprintUndoHandler)
 	api.router.POST("/api/v:version/blueprints/tag/:blueprint", api.blueprintsTagHandler)
+	api.router.POST("/api/v:version/blueprints/tag/:blueprint/:tag/:commit", api.blueprintsSetTagHandler)
+	api.router.GET("/api/v:version/blueprints/revisions/:blueprint", api.blueprintsRevisionsHandler)
+	api.router.GET("/api/v:version/blueprints/export", api.blueprintsExportHandler)
+	api.router.POST("/api/v:version/blueprints/import", api.blueprintsImportHandler)
+	api.router.POST("/api/v:version/blueprints/validate", api.blueprintsValidateHandler)
 	api.router.DELETE("/api/v:version/blueprints/delete/:blueprint", api.blueprintDeleteHandler)
 	api.router.DELETE("/api/v:version/blueprints/workspace/:blueprint", api.blueprintDeleteWorkspaceHandler)
 
@@ -161,6 +182,9 @@ func New(rpmmd rpmmd.RPMMD, arch distro.Arch, distro distro.Distro, repoRegistry
 	api.router.GET("/api/v:version/compose/log/:uuid", api.composeLogHandler)
 	api.router.POST("/api/v:version/compose/uploads/schedule/:uuid", api.uploadsScheduleHandler)
 	api.router.DELETE("/api/v:version/compose/cancel/:uuid", api.composeCancelHandler)
+	api.router.POST("/api/v:version/compose/schedule", api.composeScheduleHandler)
+	api.router.GET("/api/v:version/compose/schedule", api.composeScheduleListHandler)
+	api.router.DELETE("/api/v:version/compose/schedule/:id", api.composeScheduleDeleteHandler)
 
 	api.router.DELETE("/api/v:version/upload/delete/:uuid", api.uploadsDeleteHandler)
 	api.router.GET("/api/v:version/upload/info/:uuid", api.uploadsInfoHandler)
@@ -175,8 +199,16 @@ func New(rpmmd rpmmd.RPMMD, arch distro.Arch, distro distro.Distro, repoRegistry
 	return api
 }
 
+// SetErrorReporter makes the API report panics of its handlers to `reporter`.
+func (api *API) SetErrorReporter(reporter *sentry.Client) {
+	api.reporter = reporter
+}
+
 func (api *API) Serve(listener net.Listener) error {
-	server := http.Server{Handler: api}
+	server := http.Server{
+		Handler:     api,
+		ConnContext: api.peerContext,
+	}
 
 	err := server.Serve(listener)
 	if err != nil && err != http.ErrServerClosed {
@@ -192,6 +224,20 @@ func (api *API) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	}
 
 	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	api.reporter.Middleware(api.audit.Handler(http.HandlerFunc(api.serve), auditEvent)).ServeHTTP(writer, request)
+}
+
+func (api *API) serve(writer http.ResponseWriter, request *http.Request) {
+	if api.policy != nil && !api.allowed(request) {
+		statusResponseError(writer, http.StatusForbidden, responseError{
+			Code: http.StatusForbidden,
+			ID:   "Forbidden",
+			Msg:  "You are not allowed to do this",
+		})
+		return
+	}
+
 	api.router.ServeHTTP(writer, request)
 }
 
@@ -201,6 +247,9 @@ type composeStatus struct {
 	Started  time.Time
 	Finished time.Time
 	Result   *osbuild.Result
+
+	// Why the compose failed, if it has and the reason is known
+	JobError *clienterrors.Error
 }
 
 func composeStateFromJobStatus(js *worker.JobStatus, result *worker.OSBuildJobResult) ComposeState {
@@ -267,6 +316,7 @@ func (api *API) getComposeStatus(compose store.Compose) *composeStatus {
 		Started:  jobStatus.Started,
 		Finished: jobStatus.Finished,
 		Result:   result.OSBuildOutput,
+		JobError: worker.OSBuildJobError(jobStatus, &result),
 	}
 }
 
@@ -300,6 +350,42 @@ func (api *API) openImageFile(composeId uuid.UUID, compose store.Compose) (io.Re
 	return reader, size, nil
 }
 
+// OSTreeCommits returns the IDs of all finished composes which produced an
+// OSTree commit archive.
+func (api *API) OSTreeCommits() []uuid.UUID {
+	var ids []uuid.UUID
+	for id, compose := range api.store.GetAllComposes() {
+		// All commit image types produce a commit.tar
+		if compose.ImageBuild.ImageType.OSTreeRef() == "" || compose.ImageBuild.ImageType.Filename() != "commit.tar" {
+			continue
+		}
+		if api.getComposeStatus(compose).State == ComposeFinished {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// OpenOSTreeCommit opens the image file of the finished OSTree compose `id`.
+// It is used as the ostree.CommitOpener of the repository server.
+func (api *API) OpenOSTreeCommit(id uuid.UUID) (io.Reader, error) {
+	compose, exists := api.store.GetCompose(id)
+	if !exists {
+		return nil, fmt.Errorf("compose %s doesn't exist", id)
+	}
+
+	if compose.ImageBuild.ImageType.OSTreeRef() == "" {
+		return nil, fmt.Errorf("compose %s is not an OSTree compose", id)
+	}
+
+	if state := api.getComposeStatus(compose).State; state != ComposeFinished {
+		return nil, fmt.Errorf("compose %s is in wrong state: %s", id, state.ToString())
+	}
+
+	reader, _, err := api.openImageFile(id, compose)
+	return reader, err
+}
+
 func verifyRequestVersion(writer http.ResponseWriter, params httprouter.Params, minVersion uint) bool {
 	versionString := params.ByName("version")
 
@@ -742,6 +828,7 @@ func (api *API) sourceNewHandler(writer http.ResponseWriter, request *http.Reque
 		}
 	}
 
+	audit.SetObject(request.Context(), source.GetKey())
 	api.store.PushSource(source.GetKey(), source.SourceConfig())
 
 	statusResponseOK(writer)
@@ -1294,6 +1381,10 @@ func (api *API) blueprintsDepsolveHandler(writer http.ResponseWriter, request *h
 // It will return an error if it cannot find a package in the dependencies
 func setPkgEVRA(dependencies []rpmmd.PackageSpec, packages []blueprint.Package) error {
 	for pkgIndex, pkg := range packages {
+		// module streams are frozen by their stream already
+		if _, ok := pkg.ModuleStream(); ok {
+			continue
+		}
 		i := sort.Search(len(dependencies), func(i int) bool {
 			return dependencies[i].Name >= pkg.Name
 		})
@@ -1468,32 +1559,33 @@ func (api *API) blueprintsDiffHandler(writer http.ResponseWriter, request *http.
 		statusResponseError(writer, http.StatusNotFound, errors)
 		return
 	}
-	if fromCommit != "NEWEST" {
+	// Deleted blueprints keep their commits, which can still be compared
+	if api.store.GetBlueprintCommitted(name) == nil && len(api.store.GetBlueprintChanges(name)) == 0 {
 		errors := responseError{
-			ID:  "UnknownCommit",
-			Msg: fmt.Sprintf("ggit-error: revspec '%s' not found (-3)", fromCommit),
+			ID:  "UnknownBlueprint",
+			Msg: fmt.Sprintf("Unknown blueprint name: %s", name),
 		}
-		statusResponseError(writer, http.StatusBadRequest, errors)
+		statusResponseError(writer, http.StatusNotFound, errors)
 		return
 	}
-	if toCommit != "WORKSPACE" {
+
+	// Fetch old and new blueprint details from store and return error if not found
+	oldBlueprint, err := api.blueprintRevision(name, fromCommit)
+	if err != nil {
 		errors := responseError{
 			ID:  "UnknownCommit",
-			Msg: fmt.Sprintf("ggit-error: revspec '%s' not found (-3)", toCommit),
+			Msg: fmt.Sprintf("%s: %v", fromCommit, err),
 		}
 		statusResponseError(writer, http.StatusBadRequest, errors)
 		return
 	}
-
-	// Fetch old and new blueprint details from store and return error if not found
-	oldBlueprint := api.store.GetBlueprintCommitted(name)
-	newBlueprint, _ := api.store.GetBlueprint(name)
-	if oldBlueprint == nil || newBlueprint == nil {
+	newBlueprint, err := api.blueprintRevision(name, toCommit)
+	if err != nil {
 		errors := responseError{
-			ID:  "UnknownBlueprint",
-			Msg: fmt.Sprintf("Unknown blueprint name: %s", name),
+			ID:  "UnknownCommit",
+			Msg: fmt.Sprintf("%s: %v", toCommit, err),
 		}
-		statusResponseError(writer, http.StatusNotFound, errors)
+		statusResponseError(writer, http.StatusBadRequest, errors)
 		return
 	}
 
@@ -1525,10 +1617,32 @@ func (api *API) blueprintsDiffHandler(writer http.ResponseWriter, request *http.
 		diffs = append(diffs, diff{Old: &pack{oldPackage}, New: nil})
 	}
 
-	err := json.NewEncoder(writer).Encode(reply{diffs})
+	err = json.NewEncoder(writer).Encode(reply{diffs})
 	common.PanicOnError(err)
 }
 
+// blueprintRevision returns a blueprint at `revision`, which is "NEWEST" for
+// the committed blueprint, "WORKSPACE" for its workspace copy (or the
+// committed one if there is none), or a commit or tag.
+func (api *API) blueprintRevision(name, revision string) (*blueprint.Blueprint, error) {
+	switch revision {
+	case "NEWEST":
+		bp := api.store.GetBlueprintCommitted(name)
+		if bp == nil {
+			return nil, errors_package.New("Unknown blueprint")
+		}
+		return bp, nil
+	case "WORKSPACE":
+		bp, _ := api.store.GetBlueprint(name)
+		if bp == nil {
+			return nil, errors_package.New("Unknown blueprint")
+		}
+		return bp, nil
+	default:
+		return api.store.GetBlueprintRevision(name, revision)
+	}
+}
+
 func (api *API) blueprintsChangesHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	if !verifyRequestVersion(writer, params, 0) {
 		return
@@ -1652,6 +1766,7 @@ func (api *API) blueprintsNewHandler(writer http.ResponseWriter, request *http.R
 		return
 	}
 
+	audit.SetObject(request.Context(), blueprint.Name)
 	if !verifyStringsWithRegex(writer, []string{blueprint.Name}, ValidBlueprintName) {
 		return
 	}
@@ -1713,6 +1828,7 @@ func (api *API) blueprintsWorkspaceHandler(writer http.ResponseWriter, request *
 		return
 	}
 
+	audit.SetObject(request.Context(), blueprint.Name)
 	if !verifyStringsWithRegex(writer, []string{blueprint.Name}, ValidBlueprintName) {
 		return
 	}
@@ -1745,7 +1861,8 @@ func (api *API) blueprintUndoHandler(writer http.ResponseWriter, request *http.R
 		return
 	}
 
-	bpChange, err := api.store.GetBlueprintChange(name, commit)
+	// commit may also be a tag, to roll back to a tagged revision
+	bp, err := api.store.GetBlueprintRevision(name, commit)
 	if err != nil {
 		errors := responseError{
 			ID:  "UnknownCommit",
@@ -1755,9 +1872,8 @@ func (api *API) blueprintUndoHandler(writer http.ResponseWriter, request *http.R
 		return
 	}
 
-	bp := bpChange.Blueprint
 	commitMsg := name + ".toml reverted to commit " + commit
-	err = api.store.PushBlueprint(bp, commitMsg)
+	err = api.store.PushBlueprint(*bp, commitMsg)
 	if err != nil {
 		errors := responseError{
 			ID:  "BlueprintsError",
@@ -1834,23 +1950,335 @@ func (api *API) blueprintsTagHandler(writer http.ResponseWriter, request *http.R
 	statusResponseOK(writer)
 }
 
-func (api *API) depsolveBlueprintForImageType(bp *blueprint.Blueprint, imageType distro.ImageType) (map[string][]rpmmd.PackageSpec, error) {
-	packageSets := imageType.PackageSets(*bp)
-	packageSpecSets := make(map[string][]rpmmd.PackageSpec)
+// blueprintsSetTagHandler points a named tag, such as "production", to a
+// commit of a blueprint, or to its latest commit for "NEWEST"
+func (api *API) blueprintsSetTagHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if !verifyRequestVersion(writer, params, 0) {
+		return
+	}
 
-	imageTypeRepos, err := api.allRepositoriesByImageType(imageType)
+	name := params.ByName("blueprint")
+	tag := params.ByName("tag")
+	commit := params.ByName("commit")
+	if !verifyStringsWithRegex(writer, []string{name, tag, commit}, ValidBlueprintName) {
+		return
+	}
+
+	if tag == "NEWEST" || tag == "WORKSPACE" {
+		errors := responseError{
+			ID:  "BlueprintsError",
+			Msg: fmt.Sprintf("%s is not a valid tag name", tag),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+
+	if commit == "NEWEST" {
+		changes := api.store.GetBlueprintChanges(name)
+		if len(changes) > 0 {
+			commit = changes[len(changes)-1].Commit
+		}
+	}
+
+	err := api.store.SetBlueprintTag(name, tag, commit)
 	if err != nil {
-		return nil, err
+		errors := responseError{
+			ID:  "BlueprintsError",
+			Msg: err.Error(),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+	statusResponseOK(writer)
+}
+
+// blueprintsRevisionsHandler lists the commits of a blueprint, newest first,
+// with their tags
+func (api *API) blueprintsRevisionsHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if !verifyRequestVersion(writer, params, 0) {
+		return
+	}
+
+	type revision struct {
+		Commit    string   `json:"commit"`
+		Message   string   `json:"message"`
+		Revision  *int     `json:"revision"`
+		Timestamp string   `json:"timestamp"`
+		Tags      []string `json:"tags"`
+	}
+
+	type reply struct {
+		Name      string     `json:"name"`
+		Revisions []revision `json:"revisions"`
+		Total     int        `json:"total"`
+	}
+
+	name := params.ByName("blueprint")
+	if !verifyStringsWithRegex(writer, []string{name}, ValidBlueprintName) {
+		return
+	}
+
+	changes := api.store.GetBlueprintChanges(name)
+	if changes == nil {
+		errors := responseError{
+			ID:  "UnknownBlueprint",
+			Msg: name,
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+
+	tagsByCommit := make(map[string][]string)
+	for tag, commit := range api.store.GetBlueprintTags(name) {
+		tagsByCommit[commit] = append(tagsByCommit[commit], tag)
+	}
+
+	revisions := make([]revision, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		tags := tagsByCommit[changes[i].Commit]
+		if tags == nil {
+			tags = []string{}
+		}
+		sort.Strings(tags)
+		revisions = append(revisions, revision{
+			Commit:    changes[i].Commit,
+			Message:   changes[i].Message,
+			Revision:  changes[i].Revision,
+			Timestamp: changes[i].Timestamp,
+			Tags:      tags,
+		})
+	}
+
+	err := json.NewEncoder(writer).Encode(reply{
+		Name:      name,
+		Revisions: revisions,
+		Total:     len(revisions),
+	})
+	common.PanicOnError(err)
+}
+
+// blueprintsExportHandler returns a tarball of all committed blueprints with
+// their changes, one JSON file per blueprint, which can be imported again.
+func (api *API) blueprintsExportHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if !verifyRequestVersion(writer, params, 0) {
+		return
+	}
+
+	writer.Header().Set("Content-Disposition", "attachment; filename=blueprints.tar")
+	writer.Header().Set("Content-Type", "application/x-tar")
+
+	tw := tar.NewWriter(writer)
+	modTime := time.Now().Truncate(time.Second)
+	for _, history := range api.store.ExportBlueprints() {
+		contents, err := json.MarshalIndent(history, "", "  ")
+		common.PanicOnError(err)
+
+		header := &tar.Header{
+			Name:    "blueprints/" + history.Blueprint.Name + ".json",
+			Mode:    0644,
+			Size:    int64(len(contents)),
+			ModTime: modTime,
+		}
+		err = tw.WriteHeader(header)
+		common.PanicOnError(err)
+
+		_, err = tw.Write(contents)
+		common.PanicOnError(err)
+	}
+
+	err := tw.Close()
+	common.PanicOnError(err)
+}
+
+// blueprintsImportHandler imports the blueprints of a tarball returned by
+// blueprintsExportHandler. They replace existing blueprints of the same name,
+// including their changes.
+func (api *API) blueprintsImportHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if !verifyRequestVersion(writer, params, 0) {
+		return
 	}
 
-	for name, packageSet := range packageSets {
-		packageSpecs, _, err := api.rpmmd.Depsolve(packageSet, imageTypeRepos, api.distro.ModulePlatformID(), api.arch.Name())
+	var histories []store.BlueprintHistory
+	var names []string
+	tr := tar.NewReader(request.Body)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			return nil, err
+			errors := responseError{
+				ID:  "BlueprintsError",
+				Msg: "Invalid blueprints tarball: " + err.Error(),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+		if header.Typeflag != tar.TypeReg || path.Ext(header.Name) != ".json" {
+			continue
 		}
-		packageSpecSets[name] = packageSpecs
+
+		var history store.BlueprintHistory
+		err = json.NewDecoder(tr).Decode(&history)
+		if err != nil {
+			errors := responseError{
+				ID:  "BlueprintsError",
+				Msg: fmt.Sprintf("Invalid blueprint %s: %v", header.Name, err),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+		histories = append(histories, history)
+		names = append(names, history.Blueprint.Name)
+	}
+
+	audit.SetObject(request.Context(), strings.Join(names, ","))
+	if !verifyStringsWithRegex(writer, names, ValidBlueprintName) {
+		return
+	}
+
+	err := api.store.ImportBlueprints(histories)
+	if err != nil {
+		errors := responseError{
+			ID:  "BlueprintsError",
+			Msg: err.Error(),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
 	}
-	return packageSpecSets, nil
+	statusResponseOK(writer)
+}
+
+// blueprintsValidateHandler checks the blueprint in the body of the request
+// without saving it. The packages must be available in the repositories. If
+// the compose_type query parameter is set, the packages of the image type
+// must be installable and it must support the customizations.
+func (api *API) blueprintsValidateHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if !verifyRequestVersion(writer, params, 0) {
+		return
+	}
+
+	contentType := request.Header["Content-Type"]
+	if len(contentType) == 0 {
+		errors := responseError{
+			ID:  "BlueprintsError",
+			Msg: "missing Content-Type header",
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+
+	if request.ContentLength == 0 {
+		errors := responseError{
+			ID:  "BlueprintsError",
+			Msg: "Missing blueprint",
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+
+	var bp blueprint.Blueprint
+	var err error
+	if contentType[0] == "application/json" {
+		err = json.NewDecoder(request.Body).Decode(&bp)
+	} else if contentType[0] == "text/x-toml" {
+		_, err = toml.DecodeReader(request.Body, &bp)
+	} else {
+		err = errors_package.New("blueprint must be in json or toml format")
+	}
+	if err != nil {
+		errors := responseError{
+			ID:  "BlueprintsError",
+			Msg: "400 Bad Request: The browser (or proxy) sent a request that this server could not understand: " + err.Error(),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+
+	target := validation.Target{
+		RPMMD: api.rpmmd,
+		Arch:  api.arch,
+	}
+	if composeType := request.URL.Query().Get("compose_type"); composeType != "" {
+		target.ImageType, err = api.arch.GetImageType(composeType)
+		if err != nil {
+			errors := responseError{
+				ID:  "UnknownComposeType",
+				Msg: fmt.Sprintf("Unknown compose type for architecture: %s", composeType),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+		target.ImageOptions = distro.ImageOptions{
+			Size:          target.ImageType.Size(0),
+			OSTree:        distro.OSTreeImageOptions{Ref: target.ImageType.OSTreeRef()},
+			ModuleStreams: bp.GetModuleStreams(),
+		}
+		if s := bp.Customizations.GetSubscription(); s != nil {
+			target.ImageOptions.Subscription = &distro.SubscriptionImageOptions{
+				Organization:  s.Organization,
+				ActivationKey: s.ActivationKey,
+				ServerUrl:     s.ServerURL,
+				BaseUrl:       s.BaseURL,
+				Insights:      s.Insights,
+			}
+		}
+		target.Repositories, err = api.allRepositoriesByImageType(target.ImageType)
+	} else {
+		target.Repositories, err = api.allRepositories()
+	}
+	if err != nil {
+		errors := responseError{
+			ID:  "InternalError",
+			Msg: err.Error(),
+		}
+		statusResponseError(writer, http.StatusInternalServerError, errors)
+		return
+	}
+	target.Repositories = append(target.Repositories, blueprintRepositories(&bp)...)
+
+	diagnostics := validation.Validate(bp, target)
+
+	type reply struct {
+		Valid       bool                    `json:"valid"`
+		Diagnostics []validation.Diagnostic `json:"diagnostics"`
+	}
+	err = json.NewEncoder(writer).Encode(reply{
+		Valid:       !validation.HasErrors(diagnostics),
+		Diagnostics: diagnostics,
+	})
+	common.PanicOnError(err)
+}
+
+func (api *API) depsolveBlueprintForImageType(bp *blueprint.Blueprint, imageType distro.ImageType) (map[string][]rpmmd.PackageSpec, error) {
+	imageTypeRepos, err := api.allRepositoriesByImageType(imageType)
+	if err != nil {
+		return nil, err
+	}
+	imageTypeRepos = append(imageTypeRepos, blueprintRepositories(bp)...)
+
+	return rpmmd.DepsolvePackageSets(api.rpmmd, imageType.PackageSets(*bp), imageTypeRepos, api.distro.ModulePlatformID(), api.arch.Name())
+}
+
+// https://weldr.io/lorax/pylorax.api.html#pylorax.api.v0.v0_compose_start
+type composeRequest struct {
+	BlueprintName string               `json:"blueprint_name"`
+	ComposeType   string               `json:"compose_type"`
+	Size          uint64               `json:"size"`
+	OSTree        ostree.OSTreeRequest `json:"ostree"`
+	Branch        string               `json:"branch"`
+	Upload        *uploadRequest       `json:"upload"`
+
+	// Commit or tag of the blueprint to build instead of the
+	// committed blueprint
+	BlueprintRevision string `json:"blueprint_revision,omitempty"`
+
+	// Compression preset of image types with compressed outputs
+	CompressionLevel *int `json:"compression_level,omitempty"`
+
+	// Reply with the manifest and the packages of the image instead
+	// of building it
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
 // Schedule new compose by first translating the appropriate blueprint into a pipeline and then
@@ -1860,19 +2288,15 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
-	// https://weldr.io/lorax/pylorax.api.html#pylorax.api.v0.v0_compose_start
-	type ComposeRequest struct {
-		BlueprintName string               `json:"blueprint_name"`
-		ComposeType   string               `json:"compose_type"`
-		Size          uint64               `json:"size"`
-		OSTree        ostree.OSTreeRequest `json:"ostree"`
-		Branch        string               `json:"branch"`
-		Upload        *uploadRequest       `json:"upload"`
-	}
 	type ComposeReply struct {
 		BuildID uuid.UUID `json:"build_id"`
 		Status  bool      `json:"status"`
 	}
+	type DryRunReply struct {
+		Status   bool                `json:"status"`
+		Manifest distro.Manifest     `json:"manifest"`
+		Packages []rpmmd.PackageSpec `json:"packages"`
+	}
 
 	contentType := request.Header["Content-Type"]
 	if len(contentType) != 1 || contentType[0] != "application/json" {
@@ -1884,7 +2308,7 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
-	var cr ComposeRequest
+	var cr composeRequest
 	err := json.NewDecoder(request.Body).Decode(&cr)
 	if err != nil {
 		errors := responseError{
@@ -1895,62 +2319,139 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 		statusResponseError(writer, http.StatusNotFound, errors)
 		return
 	}
+	if !isRequestVersionAtLeast(params, 1) {
+		cr.Upload = nil
+	}
 
-	imageType, err := api.arch.GetImageType(cr.ComposeType)
+	// Check for test parameter
+	q, err := url.ParseQuery(request.URL.RawQuery)
 	if err != nil {
 		errors := responseError{
-			ID:  "UnknownComposeType",
-			Msg: fmt.Sprintf("Unknown compose type for architecture: %s", cr.ComposeType),
+			ID:  "InvalidChars",
+			Msg: fmt.Sprintf("invalid query string: %v", err),
 		}
 		statusResponseError(writer, http.StatusBadRequest, errors)
 		return
 	}
+	testMode := q.Get("test")
+
+	if cr.DryRun {
+		prepared, status, errors := api.prepareCompose(cr, testMode)
+		if errors != nil {
+			statusResponseError(writer, status, *errors)
+			return
+		}
+
+		packages := prepared.packageSets["packages"]
+		if packages == nil {
+			packages = []rpmmd.PackageSpec{}
+		}
+		err = json.NewEncoder(writer).Encode(DryRunReply{
+			Status:   true,
+			Manifest: prepared.manifest,
+			Packages: packages,
+		})
+		common.PanicOnError(err)
+		return
+	}
+
+	composeID := uuid.New()
+	audit.SetObject(request.Context(), composeID.String())
+
+	status, errors := api.startCompose(request.Context(), composeID, cr, testMode)
+	if errors != nil {
+		statusResponseError(writer, status, *errors)
+		return
+	}
+
+	err = json.NewEncoder(writer).Encode(ComposeReply{
+		BuildID: composeID,
+		Status:  true,
+	})
+	common.PanicOnError(err)
+}
+
+// validateComposeRequest checks that `cr` names a known image type and
+// blueprint, and fills in the default ostree ref of the image type.
+func (api *API) validateComposeRequest(cr *composeRequest) (distro.ImageType, *blueprint.Blueprint, *responseError) {
+	imageType, err := api.arch.GetImageType(cr.ComposeType)
+	if err != nil {
+		return nil, nil, &responseError{
+			ID:  "UnknownComposeType",
+			Msg: fmt.Sprintf("Unknown compose type for architecture: %s", cr.ComposeType),
+		}
+	}
 
 	// set default ostree ref, if one not provided
 	if cr.OSTree.Ref == "" {
 		cr.OSTree.Ref = imageType.OSTreeRef()
 	} else if !ostree.VerifyRef(cr.OSTree.Ref) {
-		errors := responseError{
+		return nil, nil, &responseError{
 			ID:  "InvalidChars",
 			Msg: "Invalid ostree ref",
 		}
-		statusResponseError(writer, http.StatusBadRequest, errors)
-		return
 	}
 
-	if !verifyStringsWithRegex(writer, []string{cr.BlueprintName}, ValidBlueprintName) {
-		return
+	if cr.BlueprintName == "" || !ValidBlueprintName.MatchString(cr.BlueprintName) {
+		return nil, nil, &responseError{
+			ID:  "InvalidChars",
+			Msg: "Invalid characters in API path",
+		}
 	}
 
-	composeID := uuid.New()
-
-	var targets []*target.Target
-	if isRequestVersionAtLeast(params, 1) && cr.Upload != nil {
-		t := uploadRequestToTarget(*cr.Upload, imageType)
-		targets = append(targets, t)
+	if cr.BlueprintRevision != "" {
+		if !ValidBlueprintName.MatchString(cr.BlueprintRevision) {
+			return nil, nil, &responseError{
+				ID:  "InvalidChars",
+				Msg: "Invalid characters in API path",
+			}
+		}
+		bp, err := api.store.GetBlueprintRevision(cr.BlueprintName, cr.BlueprintRevision)
+		if err != nil {
+			return nil, nil, &responseError{
+				ID:  "UnknownBlueprint",
+				Msg: fmt.Sprintf("Unknown revision %s of blueprint %s: %v", cr.BlueprintRevision, cr.BlueprintName, err),
+			}
+		}
+		return imageType, bp, nil
 	}
 
 	bp := api.store.GetBlueprintCommitted(cr.BlueprintName)
 	if bp == nil {
-		errors := responseError{
+		return nil, nil, &responseError{
 			ID:  "UnknownBlueprint",
 			Msg: fmt.Sprintf("Unknown blueprint name: %s", cr.BlueprintName),
 		}
-		statusResponseError(writer, http.StatusBadRequest, errors)
-		return
 	}
 
-	// Check for test parameter
-	q, err := url.ParseQuery(request.URL.RawQuery)
-	if err != nil {
-		errors := responseError{
-			ID:  "InvalidChars",
-			Msg: fmt.Sprintf("invalid query string: %v", err),
-		}
-		statusResponseError(writer, http.StatusBadRequest, errors)
-		return
+	return imageType, bp, nil
+}
+
+// A preparedCompose has everything needed to build the image of a compose
+// request.
+type preparedCompose struct {
+	imageType   distro.ImageType
+	bp          *blueprint.Blueprint
+	size        uint64
+	targets     []*target.Target
+	manifest    distro.Manifest
+	packageSets map[string][]rpmmd.PackageSpec
+}
+
+// prepareCompose depsolves the blueprint of `cr` and creates the manifest
+// of its image. It returns an error and the HTTP status that goes with it if
+// that isn't possible.
+func (api *API) prepareCompose(cr composeRequest, testMode string) (*preparedCompose, int, *responseError) {
+	imageType, bp, errors := api.validateComposeRequest(&cr)
+	if errors != nil {
+		return nil, http.StatusBadRequest, errors
+	}
+
+	var targets []*target.Target
+	if cr.Upload != nil {
+		t := uploadRequestToTarget(*cr.Upload, imageType)
+		targets = append(targets, t)
 	}
-	testMode := q.Get("test")
 
 	// Fetch parent ostree commit from ref + url if commit is not
 	// provided. The parameter name "parent" is perhaps slightly misleading
@@ -1958,12 +2459,10 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 	// strictly speaking just the parent commit.
 	if cr.OSTree.Ref != "" && cr.OSTree.URL != "" {
 		if cr.OSTree.Parent != "" {
-			errors := responseError{
+			return nil, http.StatusBadRequest, &responseError{
 				ID:  "OSTreeOptionsError",
 				Msg: "Supply at most one of Parent and URL",
 			}
-			statusResponseError(writer, http.StatusBadRequest, errors)
-			return
 		}
 		var parent string
 		if testMode == "1" || testMode == "2" {
@@ -1971,14 +2470,13 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 			parent = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa"
 		} else {
 			// Resolve the URL and get the parent commit
+			var err error
 			parent, err = ostree.ResolveRef(cr.OSTree.URL, cr.OSTree.Ref)
 			if err != nil {
-				errors := responseError{
+				return nil, http.StatusBadRequest, &responseError{
 					ID:  "OSTreeCommitError",
 					Msg: err.Error(),
 				}
-				statusResponseError(writer, http.StatusBadRequest, errors)
-				return
 			}
 		}
 		cr.OSTree.Parent = parent
@@ -1986,12 +2484,10 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 
 	packageSets, err := api.depsolveBlueprintForImageType(bp, imageType)
 	if err != nil {
-		errors := responseError{
+		return nil, http.StatusInternalServerError, &responseError{
 			ID:  "DepsolveError",
 			Msg: err.Error(),
 		}
-		statusResponseError(writer, http.StatusInternalServerError, errors)
-		return
 	}
 
 	size := imageType.Size(cr.Size)
@@ -2005,12 +2501,22 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 	imageRepos, err := api.allRepositoriesByImageType(imageType)
 	// this shoudl not happen if the api.depsolveBlueprintForImageType() call above worked
 	if err != nil {
-		errors := responseError{
+		return nil, http.StatusInternalServerError, &responseError{
 			ID:  "InternalError",
 			Msg: err.Error(),
 		}
-		statusResponseError(writer, http.StatusInternalServerError, errors)
-		return
+	}
+	imageRepos = append(imageRepos, blueprintRepositories(bp)...)
+
+	var subscription *distro.SubscriptionImageOptions
+	if s := bp.Customizations.GetSubscription(); s != nil {
+		subscription = &distro.SubscriptionImageOptions{
+			Organization:  s.Organization,
+			ActivationKey: s.ActivationKey,
+			ServerUrl:     s.ServerURL,
+			BaseUrl:       s.BaseURL,
+			Insights:      s.Insights,
+		}
 	}
 
 	manifest, err := imageType.Manifest(bp.Customizations,
@@ -2021,57 +2527,85 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 				Parent: cr.OSTree.Parent,
 				URL:    cr.OSTree.URL,
 			},
+			Subscription:     subscription,
+			CompressionLevel: cr.CompressionLevel,
+			ModuleStreams:    bp.GetModuleStreams(),
 		},
 		imageRepos,
 		packageSets,
 		seed)
 	if err != nil {
-		errors := responseError{
+		return nil, http.StatusBadRequest, &responseError{
 			ID:  "ManifestCreationFailed",
 			Msg: fmt.Sprintf("failed to create osbuild manifest: %v", err),
 		}
-		statusResponseError(writer, http.StatusBadRequest, errors)
-		return
 	}
 
+	return &preparedCompose{
+		imageType:   imageType,
+		bp:          bp,
+		size:        size,
+		targets:     targets,
+		manifest:    manifest,
+		packageSets: packageSets,
+	}, http.StatusOK, nil
+}
+
+// startCompose builds the manifest for `cr` and enqueues it as compose
+// `composeID`. It returns an error and the HTTP status that goes with it if
+// the compose could not be started.
+func (api *API) startCompose(ctx context.Context, composeID uuid.UUID, cr composeRequest, testMode string) (int, *responseError) {
+	prepared, status, errors := api.prepareCompose(cr, testMode)
+	if errors != nil {
+		return status, errors
+	}
+	imageType := prepared.imageType
+
+	var err error
 	if testMode == "1" {
 		// Create a failed compose
-		err = api.store.PushTestCompose(composeID, manifest, imageType, bp, size, targets, false, packageSets["packages"])
+		err = api.store.PushTestCompose(composeID, prepared.manifest, imageType, prepared.bp, prepared.size, prepared.targets, false, prepared.packageSets["packages"])
 	} else if testMode == "2" {
 		// Create a successful compose
-		err = api.store.PushTestCompose(composeID, manifest, imageType, bp, size, targets, true, packageSets["packages"])
+		err = api.store.PushTestCompose(composeID, prepared.manifest, imageType, prepared.bp, prepared.size, prepared.targets, true, prepared.packageSets["packages"])
 	} else {
 		var jobId uuid.UUID
 
-		jobId, err = api.workers.EnqueueOSBuild(api.arch.Name(), &worker.OSBuildJob{
-			Manifest:        manifest,
-			Targets:         targets,
+		jobId, err = api.workers.EnqueueOSBuild(ctx, api.arch.Name(), &worker.OSBuildJob{
+			Manifest:        prepared.manifest,
+			Targets:         prepared.targets,
 			ImageName:       imageType.Filename(),
+			ImageType:       imageType.Name(),
 			StreamOptimized: imageType.Name() == "vmdk", // https://github.com/osbuild/osbuild/issues/528
 			Exports:         imageType.Exports(),
-		})
+			ImageSize:       prepared.size,
+		}, worker.PriorityInteractive, composeTenant(prepared.bp.Name))
 		if err == nil {
-			err = api.store.PushCompose(composeID, manifest, imageType, bp, size, targets, jobId, packageSets["packages"])
+			err = api.store.PushCompose(composeID, prepared.manifest, imageType, prepared.bp, prepared.size, prepared.targets, jobId, prepared.packageSets["packages"])
+		}
+		if err == nil {
+			logging.Default().With(logging.ComposeID, composeID, logging.JobID, jobId, logging.JobType, "osbuild:"+api.arch.Name()).Infof("Compose enqueued")
 		}
 	}
 
 	// TODO: we should probably do some kind of blueprint validation in future
 	// for now, let's just 500 and bail out
 	if err != nil {
-		log.Println("error when pushing new compose: ", err.Error())
-		errors := responseError{
+		logging.Default().With(logging.ComposeID, composeID).Errorf("Error when pushing new compose: %v", err)
+		return http.StatusInternalServerError, &responseError{
 			ID:  "ComposePushErrored",
 			Msg: err.Error(),
 		}
-		statusResponseError(writer, http.StatusInternalServerError, errors)
-		return
 	}
 
-	err = json.NewEncoder(writer).Encode(ComposeReply{
-		BuildID: composeID,
-		Status:  true,
-	})
-	common.PanicOnError(err)
+	return http.StatusOK, nil
+}
+
+// composeTenant returns the tenant which composes of the blueprint `name`
+// are enqueued for, so that blueprints are scheduled fairly and are subject
+// to the tenant quota of the worker server separately.
+func composeTenant(name string) string {
+	return "blueprint:" + name
 }
 
 func (api *API) composeDeleteHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
@@ -2228,26 +2762,208 @@ func (api *API) composeQueueHandler(writer http.ResponseWriter, request *http.Re
 		return
 	}
 
+	query := request.URL.Query()
+
+	states := []ComposeState{ComposeWaiting, ComposeRunning}
+	if status := query.Get("status"); status != "" {
+		switch status {
+		case ComposeWaiting.ToString():
+			states = []ComposeState{ComposeWaiting}
+		case ComposeRunning.ToString():
+			states = []ComposeState{ComposeRunning}
+		default:
+			errors := responseError{
+				ID:  "InvalidFilter",
+				Msg: fmt.Sprintf("BadRequest: invalid value for 'status': %s is not WAITING or RUNNING", status),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+	}
+
+	filter, err := parseComposeFilter(query)
+	if err != nil {
+		errors := responseError{
+			ID:  "InvalidFilter",
+			Msg: fmt.Sprintf("BadRequest: %s", err.Error()),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+
+	var page *composePage
+	if query.Get("offset") != "" || query.Get("limit") != "" {
+		offset, limit, err := parseOffsetAndLimit(query)
+		if err != nil {
+			errors := responseError{
+				ID:  "BadLimitOrOffset",
+				Msg: fmt.Sprintf("BadRequest: %s", err.Error()),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+		page = &composePage{Offset: offset, Limit: limit}
+	}
+
+	entries, err := api.queuedComposeEntries(filter, states, page, isRequestVersionAtLeast(params, 1))
+	if err != nil {
+		errors := responseError{
+			ID:  "InternalServerError",
+			Msg: err.Error(),
+		}
+		statusResponseError(writer, http.StatusInternalServerError, errors)
+		return
+	}
+
 	reply := struct {
 		New []*ComposeEntry `json:"new"`
 		Run []*ComposeEntry `json:"run"`
-	}{[]*ComposeEntry{}, []*ComposeEntry{}}
+		*composePage
+	}{[]*ComposeEntry{}, []*ComposeEntry{}, page}
 
-	includeUploads := isRequestVersionAtLeast(params, 1)
+	for _, entry := range entries {
+		if entry.QueueStatus == common.IBWaiting {
+			reply.New = append(reply.New, entry)
+		} else {
+			reply.Run = append(reply.Run, entry)
+		}
+	}
 
-	composes := api.store.GetAllComposes()
+	err = json.NewEncoder(writer).Encode(reply)
+	common.PanicOnError(err)
+}
+
+// queuedComposeEntries returns the entries of the composes which are selected
+// by `filter` and are in one of `states`, in the order they were queued. The
+// store applies `filter` and the job queue selects the states and the page.
+// If `page` is not nil, only the entries on it are returned, and its total
+// and limit are set to the number of selected composes and the number of
+// returned entries, respectively.
+//
+// Composes from before jobs were kept in the job queue have their state in
+// the store. They come first, because they were queued before all others.
+func (api *API) queuedComposeEntries(filter store.ComposeFilter, states []ComposeState, page *composePage, includeUploads bool) ([]*ComposeEntry, error) {
+	composes := api.store.ListComposes(filter)
+
+	var legacy []*ComposeEntry
+	jobIDs := []uuid.UUID{}
+	composeIDs := make(map[uuid.UUID]uuid.UUID)
 	for id, compose := range composes {
-		composeStatus := api.getComposeStatus(compose)
-		switch composeStatus.State {
+		jobID := compose.ImageBuild.JobID
+		if jobID != uuid.Nil {
+			jobIDs = append(jobIDs, jobID)
+			composeIDs[jobID] = id
+			continue
+		}
+		status := api.getComposeStatus(compose)
+		for _, state := range states {
+			if status.State == state {
+				legacy = append(legacy, composeToComposeEntry(id, compose, status, includeUploads))
+				break
+			}
+		}
+	}
+	sort.Slice(legacy, func(i, j int) bool {
+		if legacy[i].JobCreated == legacy[j].JobCreated {
+			return legacy[i].ID.String() < legacy[j].ID.String()
+		}
+		return legacy[i].JobCreated < legacy[j].JobCreated
+	})
+
+	jobFilter := jobqueue.JobFilter{IDs: jobIDs}
+	for _, state := range states {
+		switch state {
 		case ComposeWaiting:
-			reply.New = append(reply.New, composeToComposeEntry(id, compose, composeStatus, includeUploads))
+			jobFilter.States = append(jobFilter.States, jobqueue.JobPending)
 		case ComposeRunning:
-			reply.Run = append(reply.Run, composeToComposeEntry(id, compose, composeStatus, includeUploads))
+			jobFilter.States = append(jobFilter.States, jobqueue.JobRunning)
 		}
 	}
 
-	err := json.NewEncoder(writer).Encode(reply)
-	common.PanicOnError(err)
+	entries := legacy
+	if page != nil {
+		nLegacy := uint(len(legacy))
+		entries = append([]*ComposeEntry{}, legacy[min(page.Offset, nLegacy):min(page.Offset+page.Limit, nLegacy)]...)
+		if page.Offset > nLegacy {
+			jobFilter.Offset = int(page.Offset - nLegacy)
+		}
+		jobFilter.Limit = int(page.Limit) - len(entries)
+		if jobFilter.Limit == 0 {
+			// a limit of zero would return all jobs, but only their
+			// total is needed
+			jobFilter.Offset = len(jobIDs)
+			jobFilter.Limit = 1
+		}
+	}
+
+	ids, total, err := api.workers.ListJobs(jobFilter)
+	if err != nil {
+		return nil, fmt.Errorf("error listing the jobs of the composes: %v", err)
+	}
+
+	for _, jobID := range ids {
+		id := composeIDs[jobID]
+		compose := composes[id]
+		status := api.getComposeStatus(compose)
+		// the job might have finished in the meantime
+		if status.State != ComposeWaiting && status.State != ComposeRunning {
+			continue
+		}
+		entries = append(entries, composeToComposeEntry(id, compose, status, includeUploads))
+	}
+
+	if page != nil {
+		page.Total = uint(len(legacy) + total)
+		page.Offset = min(page.Offset, page.Total)
+		page.Limit = uint(len(entries))
+	}
+
+	return entries, nil
+}
+
+// listComposeEntries returns the entries of the composes in one of `states`
+// which are selected by the filter and page in the query of `request`, sorted
+// by their ID. Whether a compose finished successfully is only known from the
+// result of its job, so the states are checked and the page is selected here
+// rather than in the job queue. It writes an error response and returns false
+// if the query is invalid.
+func (api *API) listComposeEntries(writer http.ResponseWriter, request *http.Request, params httprouter.Params, states ...ComposeState) ([]*ComposeEntry, *composePage, bool) {
+	query := request.URL.Query()
+
+	filter, err := parseComposeFilter(query)
+	if err != nil {
+		errors := responseError{
+			ID:  "InvalidFilter",
+			Msg: fmt.Sprintf("BadRequest: %s", err.Error()),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return nil, nil, false
+	}
+
+	includeUploads := isRequestVersionAtLeast(params, 1)
+	entries := []*ComposeEntry{}
+	for id, compose := range api.store.ListComposes(filter) {
+		composeStatus := api.getComposeStatus(compose)
+		for _, state := range states {
+			if composeStatus.State == state {
+				entries = append(entries, composeToComposeEntry(id, compose, composeStatus, includeUploads))
+				break
+			}
+		}
+	}
+	sortComposeEntries(entries)
+
+	entries, page, err := paginateComposeEntries(entries, query)
+	if err != nil {
+		errors := responseError{
+			ID:  "BadLimitOrOffset",
+			Msg: fmt.Sprintf("BadRequest: %s", err.Error()),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return nil, nil, false
+	}
+
+	return entries, page, true
 }
 
 func (api *API) composeStatusHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
@@ -2368,10 +3084,11 @@ func (api *API) composeInfoHandler(writer http.ResponseWriter, request *http.Req
 		Deps      struct {
 			Packages []rpmmd.PackageSpec `json:"packages"`
 		} `json:"deps"`
-		ComposeType string           `json:"compose_type"`
-		QueueStatus string           `json:"queue_status"`
-		ImageSize   uint64           `json:"image_size"`
-		Uploads     []uploadResponse `json:"uploads,omitempty"`
+		ComposeType string              `json:"compose_type"`
+		QueueStatus string              `json:"queue_status"`
+		ImageSize   uint64              `json:"image_size"`
+		Uploads     []uploadResponse    `json:"uploads,omitempty"`
+		JobError    *clienterrors.Error `json:"job_error,omitempty"`
 	}
 
 	reply.ID = id
@@ -2382,6 +3099,7 @@ func (api *API) composeInfoHandler(writer http.ResponseWriter, request *http.Req
 	reply.ComposeType = compose.ImageBuild.ImageType.Name()
 	reply.QueueStatus = composeStatus.State.ToString()
 	reply.ImageSize = compose.ImageBuild.Size
+	reply.JobError = composeStatus.JobError
 
 	if isRequestVersionAtLeast(params, 1) {
 		reply.Uploads = targetsToUploadResponses(compose.ImageBuild.Targets, composeStatus.State)
@@ -2672,12 +3390,28 @@ func (api *API) composeLogsHandler(writer http.ResponseWriter, request *http.Req
 	common.PanicOnError(err)
 }
 
+// composeLogHandler returns the end of the log of a compose, up to `size`
+// kilobytes (1 MiB by default). The log of a running compose is the part
+// its worker has sent so far.
 func (api *API) composeLogHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	// TODO: implement size param
 	if !verifyRequestVersion(writer, params, 0) {
 		return
 	}
 
+	size := uint64(1024)
+	if sizeString := request.URL.Query().Get("size"); sizeString != "" {
+		var err error
+		size, err = strconv.ParseUint(sizeString, 10, 0)
+		if err != nil {
+			errors := responseError{
+				ID:  "ComposeError",
+				Msg: fmt.Sprintf("invalid size: %s", sizeString),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+	}
+
 	uuidString := params.ByName("uuid")
 	id, err := uuid.Parse(uuidString)
 	if err != nil {
@@ -2709,12 +3443,23 @@ func (api *API) composeLogHandler(writer http.ResponseWriter, request *http.Requ
 		return
 	}
 
+	var buf bytes.Buffer
 	if composeStatus.State == ComposeRunning {
-		fmt.Fprintf(writer, "Build %s is still running.\n", uuidString)
-		return
+		buf.Write(api.workers.JobLog(compose.ImageBuild.JobID, 0))
+		if buf.Len() == 0 {
+			fmt.Fprintf(writer, "Build %s is still running.\n", uuidString)
+			return
+		}
+	} else {
+		err = composeStatus.Result.Write(&buf)
+		common.PanicOnError(err)
 	}
 
-	err = composeStatus.Result.Write(writer)
+	logBytes := buf.Bytes()
+	if limit := size * 1024; uint64(len(logBytes)) > limit {
+		logBytes = logBytes[uint64(len(logBytes))-limit:]
+	}
+	_, err = writer.Write(logBytes)
 	common.PanicOnError(err)
 }
 
@@ -2723,19 +3468,15 @@ func (api *API) composeFinishedHandler(writer http.ResponseWriter, request *http
 		return
 	}
 
+	entries, page, ok := api.listComposeEntries(writer, request, params, ComposeFinished)
+	if !ok {
+		return
+	}
+
 	reply := struct {
 		Finished []*ComposeEntry `json:"finished"`
-	}{[]*ComposeEntry{}}
-
-	includeUploads := isRequestVersionAtLeast(params, 1)
-	for id, compose := range api.store.GetAllComposes() {
-		composeStatus := api.getComposeStatus(compose)
-		if composeStatus.State != ComposeFinished {
-			continue
-		}
-		reply.Finished = append(reply.Finished, composeToComposeEntry(id, compose, composeStatus, includeUploads))
-	}
-	sortComposeEntries(reply.Finished)
+		*composePage
+	}{entries, page}
 
 	err := json.NewEncoder(writer).Encode(reply)
 	common.PanicOnError(err)
@@ -2746,19 +3487,15 @@ func (api *API) composeFailedHandler(writer http.ResponseWriter, request *http.R
 		return
 	}
 
+	entries, page, ok := api.listComposeEntries(writer, request, params, ComposeFailed)
+	if !ok {
+		return
+	}
+
 	reply := struct {
 		Failed []*ComposeEntry `json:"failed"`
-	}{[]*ComposeEntry{}}
-
-	includeUploads := isRequestVersionAtLeast(params, 1)
-	for id, compose := range api.store.GetAllComposes() {
-		composeStatus := api.getComposeStatus(compose)
-		if composeStatus.State != ComposeFailed {
-			continue
-		}
-		reply.Failed = append(reply.Failed, composeToComposeEntry(id, compose, composeStatus, includeUploads))
-	}
-	sortComposeEntries(reply.Failed)
+		*composePage
+	}{entries, page}
 
 	err := json.NewEncoder(writer).Encode(reply)
 	common.PanicOnError(err)
@@ -2807,13 +3544,41 @@ func (api *API) allRepositories() ([]rpmmd.RepoConfig, error) {
 	return repos, nil
 }
 
+// Returns the enabled repositories of the blueprint's customizations, which
+// are used in addition to the configured ones when depsolving the blueprint.
+func blueprintRepositories(bp *blueprint.Blueprint) []rpmmd.RepoConfig {
+	var repos []rpmmd.RepoConfig
+	for _, r := range bp.Customizations.GetRepositories() {
+		if !r.IsEnabled() {
+			continue
+		}
+		repo := rpmmd.RepoConfig{
+			Name:           r.ID,
+			BaseURL:        r.BaseURL,
+			Metalink:       r.Metalink,
+			MirrorList:     r.Mirrorlist,
+			GPGKey:         strings.Join(r.GPGKeys, "\n"),
+			CheckGPG:       r.CheckGPG(),
+			IgnoreSSL:      !r.VerifySSL(),
+			ModuleHotfixes: r.ModuleHotfixes,
+		}
+		if r.Priority != nil {
+			repo.Priority = *r.Priority
+		}
+		repos = append(repos, repo)
+	}
+	return repos
+}
+
 func (api *API) depsolveBlueprint(bp *blueprint.Blueprint) ([]rpmmd.PackageSpec, error) {
 	repos, err := api.allRepositories()
 	if err != nil {
 		return nil, err
 	}
+	repos = append(repos, blueprintRepositories(bp)...)
 
-	packages, _, err := api.rpmmd.Depsolve(rpmmd.PackageSet{Include: bp.GetPackages()}, repos, api.distro.ModulePlatformID(), api.arch.Name())
+	packageSet := rpmmd.PackageSet{Include: bp.GetPackages(), EnableModules: bp.GetEnabledModules()}
+	packages, _, err := api.rpmmd.Depsolve(packageSet, repos, api.distro.ModulePlatformID(), api.arch.Name())
 	if err != nil {
 		return nil, err
 	}
@@ -3,6 +3,7 @@ package weldr
 import (
 	"archive/tar"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,18 +21,22 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/distro/test_distro"
 	rpmmd_mock "github.com/osbuild/osbuild-composer/internal/mocks/rpmmd"
+	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
 	"github.com/osbuild/osbuild-composer/internal/reporegistry"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 	"github.com/osbuild/osbuild-composer/internal/store"
 	"github.com/osbuild/osbuild-composer/internal/target"
 	"github.com/osbuild/osbuild-composer/internal/test"
+	"github.com/osbuild/osbuild-composer/internal/worker"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
 
 	"github.com/BurntSushi/toml"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
-func createWeldrAPI(tempdir string, fixtureGenerator rpmmd_mock.FixtureGenerator) (*API, *store.Store) {
+func createWeldrAPI(tempdir string, fixtureGenerator rpmmd_mock.FixtureGenerator) (*API, store.Store) {
 	fixture := fixtureGenerator(tempdir)
 	rpm := rpmmd_mock.NewRPMMDMock(fixture)
 
@@ -583,6 +588,110 @@ func TestBlueprintsUndo(t *testing.T) {
 	test.SendHTTP(api, true, "DELETE", "/api/v0/blueprints/delete/"+id, ``)
 }
 
+func TestBlueprintsRevisions(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "weldr-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	api, s := createWeldrAPI(tempdir, rpmmd_mock.NoComposesFixture)
+	test.SendHTTP(api, false, "POST", "/api/v0/blueprints/new", `{"name":"revisions","description":"Test","packages":[{"name":"httpd","version":"2.4.*"}],"version":"0.0.1"}`)
+	test.SendHTTP(api, false, "POST", "/api/v0/blueprints/new", `{"name":"revisions","description":"Test","packages":[{"name":"tmux","version":"*"}],"version":"0.1.0"}`)
+	changes := s.GetBlueprintChanges("revisions")
+	require.Len(t, changes, 2)
+	first := changes[0].Commit
+
+	test.TestRoute(t, api, false, "POST", "/api/v0/blueprints/tag/revisions/production/"+first, ``, http.StatusOK, `{"status":true}`)
+	test.TestRoute(t, api, false, "POST", "/api/v0/blueprints/tag/revisions/latest/NEWEST", ``, http.StatusOK, `{"status":true}`)
+	test.TestRoute(t, api, false, "POST", "/api/v0/blueprints/tag/revisions/production/d7e5fa641aad45300242a0f273827576e32bfc03", ``, http.StatusBadRequest, `{"status":false,"errors":[{"id":"BlueprintsError","msg":"Unknown commit"}]}`)
+	test.TestRoute(t, api, false, "POST", "/api/v0/blueprints/tag/revisions/WORKSPACE/"+first, ``, http.StatusBadRequest, `{"status":false,"errors":[{"id":"BlueprintsError","msg":"WORKSPACE is not a valid tag name"}]}`)
+
+	test.TestRoute(t, api, false, "GET", "/api/v0/blueprints/revisions/revisions", ``, http.StatusOK, `{"name":"revisions","revisions":[{"commit":"","message":"Recipe revisions, version 0.1.0 saved.","revision":null,"timestamp":"","tags":["latest"]},{"commit":"","message":"Recipe revisions, version 0.0.1 saved.","revision":null,"timestamp":"","tags":["production"]}],"total":2}`, "commit", "timestamp")
+	test.TestRoute(t, api, false, "GET", "/api/v0/blueprints/revisions/unknown", ``, http.StatusBadRequest, `{"status":false,"errors":[{"id":"UnknownBlueprint","msg":"unknown"}]}`)
+
+	test.TestRoute(t, api, false, "GET", "/api/v0/blueprints/diff/revisions/production/NEWEST", ``, http.StatusOK, `{"diff":[{"new":{"Package":{"name":"tmux","version":"*"}},"old":null},{"new":null,"old":{"Package":{"name":"httpd","version":"2.4.*"}}}]}`)
+	test.TestRoute(t, api, false, "GET", "/api/v0/blueprints/diff/revisions/"+first+"/production", ``, http.StatusOK, `{"diff":[]}`)
+	test.TestRoute(t, api, false, "GET", "/api/v0/blueprints/diff/revisions/staging/NEWEST", ``, http.StatusBadRequest, `{"status":false,"errors":[{"id":"UnknownCommit","msg":"staging: Unknown commit"}]}`)
+	test.TestRoute(t, api, false, "GET", "/api/v0/blueprints/diff/unknown/NEWEST/WORKSPACE", ``, http.StatusNotFound, `{"status":false,"errors":[{"id":"UnknownBlueprint","msg":"Unknown blueprint name: unknown"}]}`)
+
+	// compose the tagged revision instead of the committed blueprint
+	test.TestRoute(t, api, false, "POST", "/api/v0/compose", fmt.Sprintf(`{"blueprint_name":"revisions","blueprint_revision":"production","compose_type":"%s","branch":"master"}`, test_distro.TestImageTypeName), http.StatusOK, `{"status":true}`, "build_id")
+	composes := s.GetAllComposes()
+	require.Len(t, composes, 1)
+	for _, compose := range composes {
+		require.Equal(t, "0.0.1", compose.Blueprint.Version)
+		require.Equal(t, "httpd", compose.Blueprint.Packages[0].Name)
+	}
+	test.TestRoute(t, api, false, "POST", "/api/v0/compose", fmt.Sprintf(`{"blueprint_name":"revisions","blueprint_revision":"staging","compose_type":"%s","branch":"master"}`, test_distro.TestImageTypeName), http.StatusBadRequest, `{"status":false,"errors":[{"id":"UnknownBlueprint","msg":"Unknown revision staging of blueprint revisions: Unknown commit"}]}`)
+
+	// roll back to the tagged revision
+	test.TestRoute(t, api, false, "POST", "/api/v0/blueprints/undo/revisions/production", ``, http.StatusOK, `{"status":true}`)
+	bp := s.GetBlueprintCommitted("revisions")
+	require.Equal(t, "httpd", bp.Packages[0].Name)
+}
+
+func TestBlueprintsValidate(t *testing.T) {
+	var cases = []struct {
+		Fixture        rpmmd_mock.FixtureGenerator
+		Path           string
+		Body           string
+		ExpectedStatus int
+		ExpectedJSON   string
+	}{
+		{rpmmd_mock.BaseFixture, "/api/v0/blueprints/validate", `{"name":"validate","version":"0.0.1","packages":[{"name":"package1","version":"1.*"},{"name":"package2*"}]}`, http.StatusOK, `{"valid":true,"diagnostics":[]}`},
+		{rpmmd_mock.BaseFixture, "/api/v0/blueprints/validate", `{"name":"validate","version":"0.0.1","packages":[{"name":"package1"},{"name":"package1"},{"name":"/usr/bin/tmux"}]}`, http.StatusOK, `{"valid":true,"diagnostics":[{"severity":"warning","field":"packages","message":"Package package1 is listed more than once"}]}`},
+		{rpmmd_mock.BaseFixture, "/api/v0/blueprints/validate", `{"name":"validate","version":"0.0.1","packages":[{"name":"missing"},{"name":"package1","version":"2.0"}],"modules":[{"name":"package3","version":"3.0-3.fc30"}]}`, http.StatusOK, `{"valid":false,"diagnostics":[{"severity":"error","field":"packages","message":"Package missing is not available in the repositories"},{"severity":"error","field":"packages","message":"No version of package package1 matching 2.0 is available in the repositories"}]}`},
+		{rpmmd_mock.BaseFixture, "/api/v0/blueprints/validate", `{"name":"validate","version":"foo"}`, http.StatusOK, `{"valid":false,"diagnostics":[{"severity":"error","message":"Invalid 'version', must use Semantic Versioning: foo is not in dotted-tri format"}]}`},
+		{rpmmd_mock.BaseFixture, "/api/v0/blueprints/validate?compose_type=" + test_distro.TestImageTypeName, `{"name":"validate","version":"0.0.1","packages":[{"name":"package1"}]}`, http.StatusOK, `{"valid":true,"diagnostics":[]}`},
+		{rpmmd_mock.BadFetch, "/api/v0/blueprints/validate", `{"name":"validate","version":"0.0.1","packages":[{"name":"package1"}]}`, http.StatusOK, `{"valid":true,"diagnostics":[{"severity":"warning","field":"packages","message":"The packages could not be checked: DNF error occured: FetchError: There was a problem when fetching packages."}]}`},
+		{rpmmd_mock.BaseFixture, "/api/v0/blueprints/validate?compose_type=unknown", `{"name":"validate","version":"0.0.1"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"UnknownComposeType","msg":"Unknown compose type for architecture: unknown"}]}`},
+	}
+
+	tempdir, err := ioutil.TempDir("", "weldr-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	for _, c := range cases {
+		api, s := createWeldrAPI(tempdir, c.Fixture)
+		test.TestRoute(t, api, false, "POST", c.Path, c.Body, c.ExpectedStatus, c.ExpectedJSON)
+		// validating doesn't save the blueprint
+		require.Nil(t, s.GetBlueprintCommitted("validate"))
+	}
+}
+
+func TestBlueprintsExportImport(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "weldr-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	api, _ := createWeldrAPI(tempdir, rpmmd_mock.BaseFixture)
+	test.SendHTTP(api, true, "POST", "/api/v0/blueprints/new", `{"name":"exported","description":"Test","packages":[{"name":"httpd","version":"2.4.*"}],"version":"0.0.1"}`)
+	test.SendHTTP(api, true, "POST", "/api/v0/blueprints/new", `{"name":"exported","description":"Test","packages":[{"name":"tmux","version":"*"}],"version":"0.1.0"}`)
+
+	response := test.SendHTTP(api, true, "GET", "/api/v0/blueprints/export", ``)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "application/x-tar", response.Header.Get("content-type"))
+	tarball, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(tarball))
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, h.Name)
+	}
+	require.Contains(t, names, "blueprints/exported.json")
+
+	other, _ := createWeldrAPI(tempdir, rpmmd_mock.BaseFixture)
+	test.TestRoute(t, other, true, "POST", "/api/v0/blueprints/import", string(tarball), http.StatusOK, `{"status":true}`)
+	test.TestRoute(t, other, true, "GET", "/api/v0/blueprints/changes/exported", ``, http.StatusOK, `{"blueprints":[{"changes":[{"commit":"","message":"Recipe exported, version 0.1.0 saved.","revision":null,"timestamp":""},{"commit":"","message":"Recipe exported, version 0.0.1 saved.","revision":null,"timestamp":""}],"name":"exported","total":2}],"errors":[],"limit":20,"offset":0}`, "commit", "timestamp")
+
+	test.TestRoute(t, other, true, "POST", "/api/v0/blueprints/import", "not a tarball", http.StatusBadRequest, `{"status":false,"errors":[{"id":"BlueprintsError","msg":"Invalid blueprints tarball: unexpected EOF"}]}`)
+}
+
 func TestCompose(t *testing.T) {
 	arch, err := test_distro.New().GetArch(test_distro.TestArchName)
 	require.NoError(t, err)
@@ -721,6 +830,93 @@ func TestCompose(t *testing.T) {
 	}
 }
 
+func TestComposeSchedule(t *testing.T) {
+	if len(os.Getenv("OSBUILD_COMPOSER_TEST_EXTERNAL")) > 0 {
+		t.Skip("This test is for internal testing only")
+	}
+
+	tempdir, err := ioutil.TempDir("", "weldr-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	api, s := createWeldrAPI(tempdir, rpmmd_mock.NoComposesFixture)
+
+	at := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	compose := fmt.Sprintf(`"blueprint_name":"test","compose_type":"%s","branch":"master"`, test_distro.TestImageTypeName)
+
+	var cases = []struct {
+		Body           string
+		ExpectedStatus int
+		ExpectedJSON   string
+	}{
+		{`{` + compose + `}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"ScheduleError","msg":"exactly one of 'at' and 'cron' is required"}]}`},
+		{`{` + compose + `,"cron":"0 2 * * *","at":"` + at + `"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"ScheduleError","msg":"exactly one of 'at' and 'cron' is required"}]}`},
+		{`{` + compose + `,"cron":"0 2 * * *","dry_run":true}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"ScheduleError","msg":"dry runs cannot be scheduled"}]}`},
+		{`{` + compose + `,"at":"2000-01-01T00:00:00Z"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"ScheduleError","msg":"'at' must be in the future"}]}`},
+		{`{` + compose + `,"cron":"0 25 * * *"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"ScheduleError","msg":"invalid value in hour field: \"25\" (must be between 0 and 23)"}]}`},
+		{`{"blueprint_name":"unknown","compose_type":"` + test_distro.TestImageTypeName + `","cron":"0 2 * * *"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"UnknownBlueprint","msg":"Unknown blueprint name: unknown"}]}`},
+		{`{` + compose + `,"cron":"0 2 * * *"}`, http.StatusOK, `{"status":true}`},
+		{`{` + compose + `,"at":"` + at + `"}`, http.StatusOK, `{"status":true}`},
+	}
+	for _, c := range cases {
+		test.TestRoute(t, api, false, "POST", "/api/v0/compose/schedule", c.Body, c.ExpectedStatus, c.ExpectedJSON, "schedule_id", "next_run")
+	}
+
+	schedules := s.GetAllSchedules()
+	require.Len(t, schedules, 2)
+	var cronID, onceID uuid.UUID
+	for id, schedule := range schedules {
+		if schedule.Cron != "" {
+			cronID = id
+		} else {
+			onceID = id
+		}
+	}
+
+	test.TestRoute(t, api, false, "GET", "/api/v0/compose/schedule", "", http.StatusOK,
+		fmt.Sprintf(`{"schedules":[{"id":"%s","blueprint":"test","compose_type":"%s","cron":"0 2 * * *"},{"id":"%s","blueprint":"test","compose_type":"%s"}]}`,
+			cronID, test_distro.TestImageTypeName, onceID, test_distro.TestImageTypeName),
+		"next_run", "created")
+	if schedules[cronID].Created.After(schedules[onceID].Created) {
+		t.Fatal("schedules are not listed in the order they were created")
+	}
+
+	// nothing is due yet
+	api.runDueSchedules(context.Background(), time.Now())
+	require.Empty(t, s.GetAllComposes())
+
+	// both are due in two days
+	now := time.Now().Add(48 * time.Hour)
+	api.runDueSchedules(context.Background(), now)
+	composes := s.GetAllComposes()
+	require.Len(t, composes, 2)
+
+	schedules = s.GetAllSchedules()
+	for _, id := range []uuid.UUID{cronID, onceID} {
+		require.Contains(t, composes, schedules[id].LastComposeID)
+		require.Empty(t, schedules[id].LastError)
+		require.True(t, now.Equal(schedules[id].LastRun))
+	}
+	require.True(t, schedules[cronID].NextRun.After(now))
+	require.True(t, schedules[onceID].NextRun.IsZero())
+
+	test.TestRoute(t, api, false, "DELETE", "/api/v0/compose/schedule/"+onceID.String(), "", http.StatusOK, fmt.Sprintf(`{"schedule_id":"%s","status":true}`, onceID))
+	test.TestRoute(t, api, false, "DELETE", "/api/v0/compose/schedule/"+onceID.String(), "", http.StatusBadRequest, fmt.Sprintf(`{"status":false,"errors":[{"id":"UnknownUUID","msg":"schedule %s doesn't exist"}]}`, onceID))
+	require.Len(t, s.GetAllSchedules(), 1)
+}
+
+func TestComposeDryRun(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "weldr-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	api, s := createWeldrAPI(tempdir, rpmmd_mock.NoComposesFixture)
+	test.TestRoute(t, api, false, "POST", "/api/v0/compose", fmt.Sprintf(`{"blueprint_name":"test","compose_type":"%s","branch":"master","dry_run":true}`, test_distro.TestImageTypeName), http.StatusOK, `{"status":true,"manifest":{"sources":{},"pipeline":{}},"packages":[]}`)
+	require.Empty(t, s.GetAllComposes())
+
+	test.TestRoute(t, api, false, "POST", "/api/v0/compose", fmt.Sprintf(`{"blueprint_name":"unknown","compose_type":"%s","branch":"master","dry_run":true}`, test_distro.TestImageTypeName), http.StatusBadRequest, `{"status":false,"errors":[{"id":"UnknownBlueprint","msg":"Unknown blueprint name: unknown"}]}`)
+}
+
 func TestComposeDelete(t *testing.T) {
 	if len(os.Getenv("OSBUILD_COMPOSER_TEST_EXTERNAL")) > 0 {
 		t.Skip("This test is for internal testing only")
@@ -897,6 +1093,8 @@ func TestComposeLog(t *testing.T) {
 		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/log/30000000-0000-0000-0000-000000000001", http.StatusOK, `Build 30000000-0000-0000-0000-000000000001 is still running.` + "\n"},
 		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/log/30000000-0000-0000-0000-000000000002", http.StatusOK, `The compose result is empty.` + "\n"},
 		{rpmmd_mock.BaseFixture, "GET", "/api/v1/compose/log/30000000-0000-0000-0000-000000000002", http.StatusOK, `The compose result is empty.` + "\n"},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v1/compose/log/30000000-0000-0000-0000-000000000002?size=0", http.StatusOK, ``},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v1/compose/log/30000000-0000-0000-0000-000000000002?size=foo", http.StatusBadRequest, `{"status":false,"errors":[{"id":"ComposeError","msg":"invalid size: foo"}]}` + "\n"},
 		{rpmmd_mock.BaseFixture, "GET", "/api/v1/compose/log/30000000-0000-0000-0000", http.StatusBadRequest, `{"status":false,"errors":[{"id":"UnknownUUID","msg":"30000000-0000-0000-0000 is not a valid build uuid"}]}` + "\n"},
 		{rpmmd_mock.BaseFixture, "GET", "/api/v1/compose/log/42000000-0000-0000-0000-000000000000", http.StatusBadRequest, `{"status":false,"errors":[{"id":"UnknownUUID","msg":"Compose 42000000-0000-0000-0000-000000000000 doesn't exist"}]}` + "\n"},
 	}
@@ -927,6 +1125,9 @@ func TestComposeQueue(t *testing.T) {
 		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/queue", ``, http.StatusOK, fmt.Sprintf(`{"new":[{"blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"WAITING"}],"run":[{"blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"RUNNING"}]}`, test_distro.TestImageTypeName)},
 		{rpmmd_mock.BaseFixture, "GET", "/api/v1/compose/queue", ``, http.StatusOK, fmt.Sprintf(`{"new":[{"blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"WAITING","uploads":[{"uuid":"10000000-0000-0000-0000-000000000000","status":"WAITING","provider_name":"aws","image_name":"awsimage","creation_time":1574857140,"settings":{"region":"frankfurt","bucket":"clay","key":"imagekey"}}]}],"run":[{"blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"RUNNING"}]}`, test_distro.TestImageTypeName)},
 		{rpmmd_mock.NoComposesFixture, "GET", "/api/v0/compose/queue", ``, http.StatusOK, `{"new":[],"run":[]}`},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/queue?status=RUNNING", ``, http.StatusOK, fmt.Sprintf(`{"new":[],"run":[{"blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"RUNNING"}]}`, test_distro.TestImageTypeName)},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/queue?limit=1", ``, http.StatusOK, fmt.Sprintf(`{"new":[{"blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"WAITING"}],"run":[],"total":2,"offset":0,"limit":1}`, test_distro.TestImageTypeName)},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/queue?status=FINISHED", ``, http.StatusBadRequest, `{"status":false,"errors":[{"id":"InvalidFilter","msg":"BadRequest: invalid value for 'status': FINISHED is not WAITING or RUNNING"}]}`},
 	}
 
 	if len(os.Getenv("OSBUILD_COMPOSER_TEST_EXTERNAL")) > 0 {
@@ -943,6 +1144,49 @@ func TestComposeQueue(t *testing.T) {
 	}
 }
 
+func TestComposeQueueOrder(t *testing.T) {
+	if len(os.Getenv("OSBUILD_COMPOSER_TEST_EXTERNAL")) > 0 {
+		t.Skip("This test is for internal testing only")
+	}
+
+	tempdir, err := ioutil.TempDir("", "weldr-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	api, _ := createWeldrAPI(tempdir, rpmmd_mock.NoComposesFixture)
+
+	// queued in a different order than their ids
+	ids := []uuid.UUID{
+		uuid.MustParse("30000000-0000-0000-0000-000000000002"),
+		uuid.MustParse("30000000-0000-0000-0000-000000000000"),
+		uuid.MustParse("30000000-0000-0000-0000-000000000001"),
+	}
+	for _, id := range ids {
+		_, errors := api.startCompose(context.Background(), id, composeRequest{
+			BlueprintName: "test",
+			ComposeType:   test_distro.TestImageTypeName,
+			Branch:        "master",
+		}, "")
+		require.Nil(t, errors)
+	}
+	_, _, _, _, _, err = api.workers.RequestJob(context.Background(), test_distro.TestArchName, []string{"osbuild"})
+	require.NoError(t, err)
+
+	entry := func(id uuid.UUID, status string) string {
+		return fmt.Sprintf(`{"id":"%s","blueprint":"test","version":"0.0.0","compose_type":"%s","image_size":0,"queue_status":"%s"}`, id, test_distro.TestImageTypeName, status)
+	}
+
+	test.TestRoute(t, api, false, "GET", "/api/v0/compose/queue", ``, http.StatusOK,
+		fmt.Sprintf(`{"new":[%s,%s],"run":[%s]}`, entry(ids[1], "WAITING"), entry(ids[2], "WAITING"), entry(ids[0], "RUNNING")),
+		"job_created", "job_started")
+	test.TestRoute(t, api, false, "GET", "/api/v0/compose/queue?offset=1&limit=1", ``, http.StatusOK,
+		fmt.Sprintf(`{"new":[%s],"run":[],"total":3,"offset":1,"limit":1}`, entry(ids[1], "WAITING")),
+		"job_created", "job_started")
+	test.TestRoute(t, api, false, "GET", "/api/v0/compose/queue?status=WAITING&offset=1", ``, http.StatusOK,
+		fmt.Sprintf(`{"new":[%s],"run":[],"total":2,"offset":1,"limit":1}`, entry(ids[2], "WAITING")),
+		"job_created", "job_started")
+}
+
 func TestComposeFinished(t *testing.T) {
 	var cases = []struct {
 		Fixture        rpmmd_mock.FixtureGenerator
@@ -955,6 +1199,14 @@ func TestComposeFinished(t *testing.T) {
 		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/finished", ``, http.StatusOK, fmt.Sprintf(`{"finished":[{"id":"30000000-0000-0000-0000-000000000002","blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"FINISHED","job_created":1574857140,"job_started":1574857140,"job_finished":1574857140},{"id":"30000000-0000-0000-0000-000000000004","blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"FINISHED","job_created":1574857140,"job_started":1574857140,"job_finished":1574857140}]}`, test_distro.TestImageTypeName)},
 		{rpmmd_mock.BaseFixture, "GET", "/api/v1/compose/finished", ``, http.StatusOK, fmt.Sprintf(`{"finished":[{"id":"30000000-0000-0000-0000-000000000002","blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"FINISHED","job_created":1574857140,"job_started":1574857140,"job_finished":1574857140,"uploads":[{"uuid":"10000000-0000-0000-0000-000000000000","status":"FINISHED","provider_name":"aws","image_name":"awsimage","creation_time":1574857140,"settings":{"region":"frankfurt","bucket":"clay","key":"imagekey"}}]},{"id":"30000000-0000-0000-0000-000000000004","blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"FINISHED","job_created":1574857140,"job_started":1574857140,"job_finished":1574857140,"uploads":[{"uuid":"10000000-0000-0000-0000-000000000000","status":"FINISHED","provider_name":"aws","image_name":"awsimage","creation_time":1574857140,"settings":{"region":"frankfurt","bucket":"clay","key":"imagekey"}}]}]}`, test_distro.TestImageTypeName)},
 		{rpmmd_mock.NoComposesFixture, "GET", "/api/v0/compose/finished", ``, http.StatusOK, `{"finished":[]}`},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/finished?limit=1", ``, http.StatusOK, fmt.Sprintf(`{"finished":[{"id":"30000000-0000-0000-0000-000000000002","blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"FINISHED","job_created":1574857140,"job_started":1574857140,"job_finished":1574857140}],"total":2,"offset":0,"limit":1}`, test_distro.TestImageTypeName)},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/finished?offset=1&limit=5", ``, http.StatusOK, fmt.Sprintf(`{"finished":[{"id":"30000000-0000-0000-0000-000000000004","blueprint":"test","version":"0.0.0","compose_type":"%[1]s","image_size":0,"queue_status":"FINISHED","job_created":1574857140,"job_started":1574857140,"job_finished":1574857140}],"total":2,"offset":1,"limit":1}`, test_distro.TestImageTypeName)},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/finished?search=TE&until=2019-11-27&limit=0", ``, http.StatusOK, `{"finished":[],"total":2,"offset":0,"limit":0}`},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/finished?search=other", ``, http.StatusOK, `{"finished":[]}`},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/finished?since=2019-11-28", ``, http.StatusOK, `{"finished":[]}`},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/finished?until=2019-11-27T00:00:00Z", ``, http.StatusOK, `{"finished":[]}`},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/finished?since=yesterday", ``, http.StatusBadRequest, `{"status":false,"errors":[{"id":"InvalidFilter","msg":"BadRequest: invalid value for 'since': yesterday is neither an RFC 3339 timestamp nor a date"}]}`},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/finished?limit=-1", ``, http.StatusBadRequest, `{"status":false,"errors":[{"id":"BadLimitOrOffset","msg":"BadRequest: invalid value for 'limit': strconv.ParseUint: parsing \"-1\": invalid syntax"}]}`},
 	}
 
 	if len(os.Getenv("OSBUILD_COMPOSER_TEST_EXTERNAL")) > 0 {
@@ -983,6 +1235,7 @@ func TestComposeFailed(t *testing.T) {
 		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/failed", ``, http.StatusOK, fmt.Sprintf(`{"failed":[{"id":"30000000-0000-0000-0000-000000000003","blueprint":"test","version":"0.0.0","compose_type":"%s","image_size":0,"queue_status":"FAILED","job_created":1574857140,"job_started":1574857140,"job_finished":1574857140}]}`, test_distro.TestImageTypeName)},
 		{rpmmd_mock.BaseFixture, "GET", "/api/v1/compose/failed", ``, http.StatusOK, fmt.Sprintf(`{"failed":[{"id":"30000000-0000-0000-0000-000000000003","blueprint":"test","version":"0.0.0","compose_type":"%s","image_size":0,"queue_status":"FAILED","job_created":1574857140,"job_started":1574857140,"job_finished":1574857140,"uploads":[{"uuid":"10000000-0000-0000-0000-000000000000","status":"FAILED","provider_name":"aws","image_name":"awsimage","creation_time":1574857140,"settings":{"region":"frankfurt","bucket":"clay","key":"imagekey"}}]}]}`, test_distro.TestImageTypeName)},
 		{rpmmd_mock.NoComposesFixture, "GET", "/api/v0/compose/failed", ``, http.StatusOK, `{"failed":[]}`},
+		{rpmmd_mock.BaseFixture, "GET", "/api/v0/compose/failed?search=es&since=2019-11-27T12:19:00Z", ``, http.StatusOK, fmt.Sprintf(`{"failed":[{"id":"30000000-0000-0000-0000-000000000003","blueprint":"test","version":"0.0.0","compose_type":"%s","image_size":0,"queue_status":"FAILED","job_created":1574857140,"job_started":1574857140,"job_finished":1574857140}]}`, test_distro.TestImageTypeName)},
 	}
 
 	if len(os.Getenv("OSBUILD_COMPOSER_TEST_EXTERNAL")) > 0 {
@@ -999,6 +1252,43 @@ func TestComposeFailed(t *testing.T) {
 	}
 }
 
+func TestComposeJobError(t *testing.T) {
+	if len(os.Getenv("OSBUILD_COMPOSER_TEST_EXTERNAL")) > 0 {
+		t.Skip("This test is for internal testing only")
+	}
+
+	tempdir, err := ioutil.TempDir("", "weldr-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	api, _ := createWeldrAPI(tempdir, rpmmd_mock.NoComposesFixture)
+
+	id := uuid.MustParse("30000000-0000-0000-0000-000000000000")
+	_, errors := api.startCompose(context.Background(), id, composeRequest{
+		BlueprintName: "test",
+		ComposeType:   test_distro.TestImageTypeName,
+		Branch:        "master",
+	}, "")
+	require.Nil(t, errors)
+
+	token, _, _, _, _, err := api.workers.RequestJob(context.Background(), test_distro.TestArchName, []string{"osbuild"})
+	require.NoError(t, err)
+	result, err := json.Marshal(worker.OSBuildJobResult{
+		OSBuildOutput: &osbuild.Result{Success: false},
+		JobError:      clienterrors.New(clienterrors.ErrorPackagesNotFound, "Package nonexistent not found", []string{"nonexistent"}),
+	})
+	require.NoError(t, err)
+	require.NoError(t, api.workers.FinishJob(token, result))
+
+	entry := fmt.Sprintf(`{"id":"%s","blueprint":"test","version":"0.0.0","compose_type":"%s","image_size":0,"queue_status":"FAILED","job_error":{"code":"packages-not-found","reason":"Package nonexistent not found","details":["nonexistent"]}}`, id, test_distro.TestImageTypeName)
+	test.TestRoute(t, api, false, "GET", "/api/v0/compose/failed", ``, http.StatusOK,
+		fmt.Sprintf(`{"failed":[%s]}`, entry),
+		"job_created", "job_started", "job_finished")
+	test.TestRoute(t, api, false, "GET", "/api/v0/compose/status/"+id.String(), ``, http.StatusOK,
+		fmt.Sprintf(`{"uuids":[%s]}`, entry),
+		"job_created", "job_started", "job_finished")
+}
+
 func TestSourcesNew(t *testing.T) {
 	var cases = []struct {
 		Method         string
@@ -1356,3 +1646,23 @@ func TestModulesList(t *testing.T) {
 		test.TestRoute(t, api, true, "GET", c.Path, ``, c.ExpectedStatus, c.ExpectedJSON)
 	}
 }
+
+func TestBlueprintRepositories(t *testing.T) {
+	priority := 10
+	disabled := false
+	bp := blueprint.Blueprint{
+		Customizations: &blueprint.Customizations{
+			Repositories: []blueprint.RepositoryCustomization{
+				{ID: "example", BaseURL: "https://example.com/repo/", GPGKeys: []string{"key1", "key2"}, Priority: &priority, ModuleHotfixes: true},
+				{ID: "insecure", Mirrorlist: "https://example.com/mirrorlist", SSLVerify: &disabled},
+				{ID: "disabled", Metalink: "https://example.com/metalink", Enabled: &disabled},
+			},
+		},
+	}
+
+	require.Equal(t, []rpmmd.RepoConfig{
+		{Name: "example", BaseURL: "https://example.com/repo/", GPGKey: "key1\nkey2", CheckGPG: true, Priority: 10, ModuleHotfixes: true},
+		{Name: "insecure", MirrorList: "https://example.com/mirrorlist", IgnoreSSL: true},
+	}, blueprintRepositories(&bp))
+	require.Nil(t, blueprintRepositories(&blueprint.Blueprint{}))
+}
@@ -0,0 +1,93 @@
+package weldr
+
+import (
+	"net/http"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/osbuild/osbuild-composer/internal/audit"
+)
+
+// Routes of requests which change something, without the "/api/v{version}/"
+// prefix. Their parameters are the object of the audit event.
+var auditedRoutes = []string{
+	"blueprints/new",
+	"blueprints/workspace",
+	"blueprints/undo",
+	"blueprints/tag",
+	"blueprints/import",
+	"blueprints/delete",
+	"projects/source/new",
+	"projects/source/delete",
+	"compose",
+	"compose/delete",
+	"compose/cancel",
+	"compose/uploads/schedule",
+	"compose/schedule",
+	"upload/delete",
+	"upload/reset",
+	"upload/cancel",
+	"upload/providers/save",
+	"upload/providers/delete",
+}
+
+// SetAuditLog makes the API record all requests which change blueprints,
+// sources, composes, uploads, or upload providers in `auditLog`.
+func (api *API) SetAuditLog(auditLog *audit.Log) {
+	api.audit = auditLog
+}
+
+// auditEvent returns the audit event for `request`, or nil if it doesn't
+// change anything.
+func auditEvent(request *http.Request) *audit.Event {
+	if request.Method == http.MethodGet || request.Method == http.MethodHead {
+		return nil
+	}
+
+	// strip "/api/v{version}/"
+	route := strings.TrimPrefix(request.URL.Path, "/api/v")
+	if i := strings.Index(route, "/"); i >= 0 {
+		route = route[i+1:]
+	}
+	// validating a blueprint doesn't save it
+	if route == "blueprints/validate" {
+		return nil
+	}
+
+	event := &audit.Event{
+		API:    "weldr",
+		Actor:  peerName(request),
+		Source: "local",
+		Action: route,
+	}
+	// the longest matching route, so that "compose/cancel/{uuid}" isn't
+	// taken for "compose"
+	match := ""
+	for _, r := range auditedRoutes {
+		if (route == r || strings.HasPrefix(route, r+"/")) && len(r) > len(match) {
+			match = r
+		}
+	}
+	if match != "" {
+		event.Action = match
+		event.Object = strings.TrimPrefix(strings.TrimPrefix(route, match), "/")
+	}
+	return event
+}
+
+// peerName returns the name of the local user who made `request`, its uid if
+// it has no name, or "" if it isn't known.
+func peerName(request *http.Request) string {
+	cred, ok := request.Context().Value(peerKey).(*unix.Ucred)
+	if !ok {
+		return ""
+	}
+	uid := strconv.FormatUint(uint64(cred.Uid), 10)
+	if u, err := user.LookupId(uid); err == nil {
+		return u.Username
+	}
+	return uid
+}
@@ -0,0 +1,62 @@
+package weldr
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/audit"
+	rpmmd_mock "github.com/osbuild/osbuild-composer/internal/mocks/rpmmd"
+)
+
+func TestAuditEvent(t *testing.T) {
+	cases := []struct {
+		method, path   string
+		action, object string
+	}{
+		{"POST", "/api/v1/compose", "compose", ""},
+		{"DELETE", "/api/v0/compose/cancel/6ba7b810-9dad-11d1-80b4-00c04fd430c8", "compose/cancel", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"DELETE", "/api/v0/compose/schedule/6ba7b810-9dad-11d1-80b4-00c04fd430c8", "compose/schedule", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"DELETE", "/api/v0/blueprints/workspace/test", "blueprints/workspace", "test"},
+		{"DELETE", "/api/v1/projects/source/delete/fish", "projects/source/delete", "fish"},
+		{"POST", "/api/v0/blueprints/undo/test/abcdef", "blueprints/undo", "test/abcdef"},
+	}
+	for _, c := range cases {
+		event := auditEvent(httptest.NewRequest(c.method, c.path, nil))
+		require.NotNil(t, event)
+		require.Equal(t, "weldr", event.API)
+		require.Equal(t, c.action, event.Action, c.path)
+		require.Equal(t, c.object, event.Object, c.path)
+	}
+
+	require.Nil(t, auditEvent(httptest.NewRequest("GET", "/api/v0/compose/queue", nil)))
+	require.Nil(t, auditEvent(httptest.NewRequest("POST", "/api/v0/blueprints/validate", nil)))
+}
+
+func TestAuditLog(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "weldr-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	var buf bytes.Buffer
+	api, _ := createWeldrAPI(tempdir, rpmmd_mock.BaseFixture)
+	api.SetAuditLog(audit.NewLog(&buf))
+
+	req := httptest.NewRequest("POST", "/api/v0/blueprints/new", strings.NewReader(`{"name":"test","version":"0.0.1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	api.ServeHTTP(httptest.NewRecorder(), req)
+
+	var event audit.Event
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+	require.Equal(t, "blueprints/new", event.Action)
+	require.Equal(t, "test", event.Object)
+	require.Equal(t, "local", event.Source)
+	require.Equal(t, http.StatusOK, event.Status)
+}
@@ -1,12 +1,17 @@
 package weldr
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"sort"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/osbuild/osbuild-composer/internal/common"
 	"github.com/osbuild/osbuild-composer/internal/store"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
 )
 
 type ComposeEntry struct {
@@ -20,6 +25,7 @@ type ComposeEntry struct {
 	JobStarted  float64                `json:"job_started,omitempty"`
 	JobFinished float64                `json:"job_finished,omitempty"`
 	Uploads     []uploadResponse       `json:"uploads,omitempty"`
+	JobError    *clienterrors.Error    `json:"job_error,omitempty"`
 }
 
 func composeToComposeEntry(id uuid.UUID, compose store.Compose, status *composeStatus, includeUploads bool) *ComposeEntry {
@@ -56,6 +62,7 @@ func composeToComposeEntry(id uuid.UUID, compose store.Compose, status *composeS
 		composeEntry.JobCreated = float64(status.Queued.UnixNano()) / 1000000000
 		composeEntry.JobStarted = float64(status.Started.UnixNano()) / 1000000000
 		composeEntry.JobFinished = float64(status.Finished.UnixNano()) / 1000000000
+		composeEntry.JobError = status.JobError
 	default:
 		panic("invalid compose state")
 	}
@@ -68,3 +75,72 @@ func sortComposeEntries(entries []*ComposeEntry) {
 		return entries[i].ID.String() < entries[j].ID.String()
 	})
 }
+
+// parseComposeFilter returns the filter for listing composes which is given by
+// the `search`, `since` and `until` query parameters. `since` and `until` are
+// either RFC 3339 timestamps or dates, in which case `until` includes that
+// day.
+func parseComposeFilter(query url.Values) (store.ComposeFilter, error) {
+	filter := store.ComposeFilter{
+		BlueprintSearch: query.Get("search"),
+	}
+
+	var err error
+	if v := query.Get("since"); v != "" {
+		filter.Since, err = parseComposeTime(v, false)
+		if err != nil {
+			return store.ComposeFilter{}, errors.New("invalid value for 'since': " + err.Error())
+		}
+	}
+	if v := query.Get("until"); v != "" {
+		filter.Until, err = parseComposeTime(v, true)
+		if err != nil {
+			return store.ComposeFilter{}, errors.New("invalid value for 'until': " + err.Error())
+		}
+	}
+
+	return filter, nil
+}
+
+func parseComposeTime(value string, endOfDay bool) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+	t, err = time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is neither an RFC 3339 timestamp nor a date", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// composePage describes which part of a list of composes is returned. It is
+// only included in replies to requests which ask for a page.
+type composePage struct {
+	Total  uint `json:"total"`
+	Offset uint `json:"offset"`
+	Limit  uint `json:"limit"`
+}
+
+// paginateComposeEntries returns the page of the sorted `entries` which is
+// selected by the `offset` and `limit` query parameters. When neither of them
+// is given, all entries are returned and the page is nil.
+func paginateComposeEntries(entries []*ComposeEntry, query url.Values) ([]*ComposeEntry, *composePage, error) {
+	if query.Get("offset") == "" && query.Get("limit") == "" {
+		return entries, nil, nil
+	}
+
+	offset, limit, err := parseOffsetAndLimit(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total := uint(len(entries))
+	offset = min(offset, total)
+	limit = min(limit, total-offset)
+
+	return entries[offset : offset+limit], &composePage{total, offset, limit}, nil
+}
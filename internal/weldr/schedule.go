@@ -0,0 +1,304 @@
+package weldr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/osbuild/osbuild-composer/internal/audit"
+	"github.com/osbuild/osbuild-composer/internal/common"
+	"github.com/osbuild/osbuild-composer/internal/cron"
+	"github.com/osbuild/osbuild-composer/internal/logging"
+	"github.com/osbuild/osbuild-composer/internal/store"
+)
+
+// How often RunSchedules looks for composes which are due. Cron schedules
+// have a resolution of one minute.
+const scheduleInterval = time.Minute
+
+type scheduleEntry struct {
+	ID            uuid.UUID  `json:"id"`
+	Blueprint     string     `json:"blueprint"`
+	ComposeType   string     `json:"compose_type"`
+	Cron          string     `json:"cron,omitempty"`
+	NextRun       *time.Time `json:"next_run,omitempty"`
+	Created       time.Time  `json:"created"`
+	LastRun       *time.Time `json:"last_run,omitempty"`
+	LastComposeID *uuid.UUID `json:"last_compose_id,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// composeScheduleHandler schedules a compose either once, at the time in
+// "at", or repeatedly, following the cron schedule in "cron". The other
+// fields are those of a compose request.
+func (api *API) composeScheduleHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if !verifyRequestVersion(writer, params, 0) {
+		return
+	}
+
+	type ScheduleRequest struct {
+		composeRequest
+		At   *time.Time `json:"at"`
+		Cron string     `json:"cron"`
+	}
+	type ScheduleReply struct {
+		ScheduleID uuid.UUID `json:"schedule_id"`
+		NextRun    time.Time `json:"next_run"`
+		Status     bool      `json:"status"`
+	}
+
+	contentType := request.Header["Content-Type"]
+	if len(contentType) != 1 || contentType[0] != "application/json" {
+		errors := responseError{
+			ID:  "MissingPost",
+			Msg: "schedule must be json",
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+
+	var sr ScheduleRequest
+	err := json.NewDecoder(request.Body).Decode(&sr)
+	if err != nil {
+		errors := responseError{
+			ID:  "ScheduleError",
+			Msg: fmt.Sprintf("invalid schedule request: %v", err),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+	cr := sr.composeRequest
+	if !isRequestVersionAtLeast(params, 1) {
+		cr.Upload = nil
+	}
+
+	now := time.Now()
+	var nextRun time.Time
+	switch {
+	case cr.DryRun:
+		err = fmt.Errorf("dry runs cannot be scheduled")
+	case (sr.At == nil) == (sr.Cron == ""):
+		err = fmt.Errorf("exactly one of 'at' and 'cron' is required")
+	case sr.At != nil:
+		nextRun = *sr.At
+		if !nextRun.After(now) {
+			err = fmt.Errorf("'at' must be in the future")
+		}
+	default:
+		var schedule *cron.Schedule
+		schedule, err = cron.Parse(sr.Cron)
+		if err == nil {
+			nextRun = schedule.Next(now)
+			if nextRun.IsZero() {
+				err = fmt.Errorf("cron schedule '%s' never fires", sr.Cron)
+			}
+		}
+	}
+	if err != nil {
+		errors := responseError{
+			ID:  "ScheduleError",
+			Msg: err.Error(),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+
+	// only validate a copy, the defaults are filled in when the compose
+	// is started
+	validated := cr
+	if _, _, errors := api.validateComposeRequest(&validated); errors != nil {
+		statusResponseError(writer, http.StatusBadRequest, *errors)
+		return
+	}
+
+	rawRequest, err := json.Marshal(cr)
+	common.PanicOnError(err)
+
+	scheduleID := uuid.New()
+	audit.SetObject(request.Context(), scheduleID.String())
+
+	err = api.store.PushSchedule(scheduleID, store.ComposeSchedule{
+		Request: rawRequest,
+		Cron:    sr.Cron,
+		NextRun: nextRun,
+		Created: now,
+	})
+	if err != nil {
+		errors := responseError{
+			ID:  "InternalServerError",
+			Msg: err.Error(),
+		}
+		statusResponseError(writer, http.StatusInternalServerError, errors)
+		return
+	}
+
+	err = json.NewEncoder(writer).Encode(ScheduleReply{
+		ScheduleID: scheduleID,
+		NextRun:    nextRun,
+		Status:     true,
+	})
+	common.PanicOnError(err)
+}
+
+func (api *API) composeScheduleListHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if !verifyRequestVersion(writer, params, 0) {
+		return
+	}
+
+	type ScheduleListReply struct {
+		Schedules []scheduleEntry `json:"schedules"`
+	}
+
+	reply := ScheduleListReply{
+		Schedules: []scheduleEntry{},
+	}
+	for id, schedule := range api.store.GetAllSchedules() {
+		var cr composeRequest
+		// the request was marshaled by composeScheduleHandler
+		err := json.Unmarshal(schedule.Request, &cr)
+		common.PanicOnError(err)
+
+		entry := scheduleEntry{
+			ID:          id,
+			Blueprint:   cr.BlueprintName,
+			ComposeType: cr.ComposeType,
+			Cron:        schedule.Cron,
+			NextRun:     timeOrNil(schedule.NextRun),
+			Created:     schedule.Created,
+			LastRun:     timeOrNil(schedule.LastRun),
+			LastError:   schedule.LastError,
+		}
+		if schedule.LastComposeID != uuid.Nil {
+			composeID := schedule.LastComposeID
+			entry.LastComposeID = &composeID
+		}
+		reply.Schedules = append(reply.Schedules, entry)
+	}
+	sort.Slice(reply.Schedules, func(i, j int) bool {
+		if reply.Schedules[i].Created.Equal(reply.Schedules[j].Created) {
+			return reply.Schedules[i].ID.String() < reply.Schedules[j].ID.String()
+		}
+		return reply.Schedules[i].Created.Before(reply.Schedules[j].Created)
+	})
+
+	err := json.NewEncoder(writer).Encode(reply)
+	common.PanicOnError(err)
+}
+
+func (api *API) composeScheduleDeleteHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if !verifyRequestVersion(writer, params, 0) {
+		return
+	}
+
+	type ScheduleDeleteReply struct {
+		ScheduleID uuid.UUID `json:"schedule_id"`
+		Status     bool      `json:"status"`
+	}
+
+	idstr := params.ByName("id")
+	id, err := uuid.Parse(idstr)
+	if err != nil {
+		errors := responseError{
+			ID:  "UnknownUUID",
+			Msg: fmt.Sprintf("%s is not a valid uuid", idstr),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+
+	err = api.store.DeleteSchedule(id)
+	if err != nil {
+		errors := responseError{
+			ID:  "UnknownUUID",
+			Msg: fmt.Sprintf("schedule %s doesn't exist", id),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+
+	err = json.NewEncoder(writer).Encode(ScheduleDeleteReply{
+		ScheduleID: id,
+		Status:     true,
+	})
+	common.PanicOnError(err)
+}
+
+// RunSchedules starts the scheduled composes when they are due, until `ctx`
+// is done. Schedules which became due while composer wasn't running are
+// started right away.
+func (api *API) RunSchedules(ctx context.Context) {
+	api.runDueSchedules(ctx, time.Now())
+
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			api.runDueSchedules(ctx, now)
+		}
+	}
+}
+
+func (api *API) runDueSchedules(ctx context.Context, now time.Time) {
+	for id, schedule := range api.store.GetAllSchedules() {
+		if schedule.NextRun.IsZero() || schedule.NextRun.After(now) {
+			continue
+		}
+
+		logger := logging.Default().With(logging.ScheduleID, id)
+
+		// Move the schedule on before starting the compose, so that a
+		// restart in between doesn't start it twice.
+		var next time.Time
+		if schedule.Cron != "" {
+			if c, err := cron.Parse(schedule.Cron); err == nil {
+				next = c.Next(now)
+			}
+		}
+		err := api.store.UpdateSchedule(id, func(s *store.ComposeSchedule) {
+			s.NextRun = next
+			s.LastRun = now
+		})
+		if err != nil {
+			// deleted in the meantime
+			continue
+		}
+
+		var cr composeRequest
+		// the request was marshaled by composeScheduleHandler
+		err = json.Unmarshal(schedule.Request, &cr)
+		common.PanicOnError(err)
+
+		composeID := uuid.New()
+		lastError := ""
+		if _, errors := api.startCompose(ctx, composeID, cr, ""); errors != nil {
+			logger.Errorf("Error starting scheduled compose: %s", errors.Msg)
+			composeID = uuid.Nil
+			lastError = errors.Msg
+		} else {
+			logger.With(logging.ComposeID, composeID).Infof("Started scheduled compose")
+		}
+
+		// ignore a schedule which was deleted in the meantime
+		_ = api.store.UpdateSchedule(id, func(s *store.ComposeSchedule) {
+			s.LastComposeID = composeID
+			s.LastError = lastError
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package weldr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/logging"
+)
+
+// SyncBlueprints commits the blueprints of the TOML and JSON files in `dir`
+// which differ from the committed blueprints of the same name. Blueprints
+// which are not in `dir` are kept, and files which cannot be read are skipped.
+func (api *API) SyncBlueprints(dir string) error {
+	logger := logging.Default()
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error reading blueprints directory: %v", err)
+	}
+
+	var synced int
+	for _, info := range infos {
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		filename := path.Join(dir, info.Name())
+
+		var bp blueprint.Blueprint
+		switch path.Ext(filename) {
+		case ".toml":
+			_, err = toml.DecodeFile(filename, &bp)
+		case ".json":
+			var data []byte
+			data, err = ioutil.ReadFile(filename)
+			if err == nil {
+				err = json.Unmarshal(data, &bp)
+			}
+		default:
+			continue
+		}
+		if err == nil && !ValidBlueprintName.MatchString(bp.Name) {
+			err = fmt.Errorf("invalid blueprint name %q", bp.Name)
+		}
+		if err != nil {
+			logger.Errorf("Skipping blueprint %s: %v", filename, err)
+			continue
+		}
+
+		if !api.blueprintChanged(bp) {
+			continue
+		}
+
+		commitMsg := "Recipe " + bp.Name + " synced from " + info.Name()
+		err = api.store.PushBlueprint(bp, commitMsg)
+		if err != nil {
+			logger.Errorf("Skipping blueprint %s: %v", filename, err)
+			continue
+		}
+		synced++
+	}
+
+	if synced > 0 {
+		logger.Infof("Synced %d blueprints from %s", synced, dir)
+	}
+	return nil
+}
+
+// blueprintChanged returns whether `bp` differs from the committed blueprint
+// of the same name. Blueprints without a version have the version of the
+// committed one, so that they are not bumped on every sync.
+func (api *API) blueprintChanged(bp blueprint.Blueprint) bool {
+	committed := api.store.GetBlueprintCommitted(bp.Name)
+	if committed == nil {
+		return true
+	}
+
+	bp = bp.DeepCopy()
+	if bp.Version == "" {
+		bp.Version = committed.Version
+	}
+	if err := bp.Initialize(); err != nil {
+		// let PushBlueprint report the error
+		return true
+	}
+
+	// compare the JSON representations, which don't distinguish between
+	// blueprints read from TOML and the ones read from the state file
+	a, err := json.Marshal(bp.DeepCopy())
+	if err != nil {
+		return true
+	}
+	b, err := json.Marshal(committed.DeepCopy())
+	if err != nil {
+		return true
+	}
+	return !bytes.Equal(a, b)
+}
+
+// FetchBlueprints checks out `ref` of the git repository at `url` into `dir`,
+// replacing what was checked out there before. `ref` is the default branch of
+// the repository when it is empty.
+func FetchBlueprints(url, ref, dir string) error {
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	if _, err := os.Stat(path.Join(dir, ".git")); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if err := git(dir, "init", "--quiet"); err != nil {
+			return err
+		}
+	}
+	if err := git(dir, "fetch", "--quiet", "--depth=1", url, ref); err != nil {
+		return err
+	}
+	return git(dir, "checkout", "--quiet", "--force", "FETCH_HEAD")
+}
+
+// git runs the git command `args` in the repository at `dir`.
+func git(dir string, args ...string) error {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package weldr
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	rpmmd_mock "github.com/osbuild/osbuild-composer/internal/mocks/rpmmd"
+)
+
+func TestSyncBlueprints(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "weldr-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	api, store := createWeldrAPI(tempdir, rpmmd_mock.BaseFixture)
+
+	dir := path.Join(tempdir, "blueprints")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	write := func(name, contents string) {
+		require.NoError(t, ioutil.WriteFile(path.Join(dir, name), []byte(contents), 0644))
+	}
+	write("web.toml", "name = \"web\"\ndescription = \"Web server\"\n\n[[packages]]\nname = \"httpd\"\n")
+	write("other.json", `{"name":"other","description":"Other","version":"1.0.0"}`)
+	write("invalid.toml", "name = \"invalid name\"\n")
+	write("README.md", "Blueprints")
+
+	require.NoError(t, api.SyncBlueprints(dir))
+	bp := store.GetBlueprintCommitted("web")
+	require.NotNil(t, bp)
+	require.Equal(t, "httpd", bp.Packages[0].Name)
+	require.NotNil(t, store.GetBlueprintCommitted("other"))
+	require.Nil(t, store.GetBlueprintCommitted("invalid name"))
+	require.Len(t, store.GetBlueprintChanges("web"), 1)
+
+	// unchanged blueprints are not committed again
+	require.NoError(t, api.SyncBlueprints(dir))
+	require.Len(t, store.GetBlueprintChanges("web"), 1)
+	require.Len(t, store.GetBlueprintChanges("other"), 1)
+
+	write("web.toml", "name = \"web\"\ndescription = \"Web server\"\n\n[[packages]]\nname = \"tmux\"\n")
+	require.NoError(t, api.SyncBlueprints(dir))
+	changes := store.GetBlueprintChanges("web")
+	require.Len(t, changes, 2)
+	require.Equal(t, "Recipe web synced from web.toml", changes[1].Message)
+	bp = store.GetBlueprintCommitted("web")
+	require.Equal(t, "tmux", bp.Packages[0].Name)
+	require.Equal(t, "0.0.1", bp.Version)
+
+	require.Error(t, api.SyncBlueprints(path.Join(tempdir, "missing")))
+}
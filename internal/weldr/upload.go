@@ -38,7 +38,15 @@ func (awsUploadSettings) isUploadSettings() {}
 type azureUploadSettings struct {
 	StorageAccount   string `json:"storageAccount,omitempty"`
 	StorageAccessKey string `json:"storageAccessKey,omitempty"`
+	SASToken         string `json:"sasToken,omitempty"`
 	Container        string `json:"container"`
+
+	// Used with the worker's service principal
+	TenantID       string `json:"tenantId,omitempty"`
+	SubscriptionID string `json:"subscriptionId,omitempty"`
+	ResourceGroup  string `json:"resourceGroup,omitempty"`
+	ImageName      string `json:"imageName,omitempty"`
+	Location       string `json:"location,omitempty"`
 }
 
 func (azureUploadSettings) isUploadSettings() {}
@@ -137,8 +145,13 @@ func targetsToUploadResponses(targets []*target.Target, state ComposeState) []up
 		case *target.AzureTargetOptions:
 			upload.ProviderName = "azure"
 			upload.Settings = &azureUploadSettings{
-				Container: options.Container,
-				// StorageAccount and StorageAccessKey are intentionally not included.
+				Container:      options.Container,
+				TenantID:       options.TenantID,
+				SubscriptionID: options.SubscriptionID,
+				ResourceGroup:  options.ResourceGroup,
+				ImageName:      options.ImageName,
+				Location:       options.Location,
+				// StorageAccount, StorageAccessKey, and SASToken are intentionally not included.
 			}
 			uploads = append(uploads, upload)
 		case *target.VMWareTargetOptions:
@@ -182,7 +195,13 @@ func uploadRequestToTarget(u uploadRequest, imageType distro.ImageType) *target.
 			Filename:         imageType.Filename(),
 			StorageAccount:   options.StorageAccount,
 			StorageAccessKey: options.StorageAccessKey,
+			SASToken:         options.SASToken,
 			Container:        options.Container,
+			TenantID:         options.TenantID,
+			SubscriptionID:   options.SubscriptionID,
+			ResourceGroup:    options.ResourceGroup,
+			ImageName:        options.ImageName,
+			Location:         options.Location,
 		}
 	case *vmwareUploadSettings:
 		t.Name = "org.osbuild.vmware"
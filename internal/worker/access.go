@@ -0,0 +1,131 @@
+package worker
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/osbuild/osbuild-composer/internal/audit"
+	"github.com/osbuild/osbuild-composer/internal/oidc"
+	"github.com/osbuild/osbuild-composer/internal/rbac"
+	"github.com/osbuild/osbuild-composer/internal/worker/api"
+)
+
+// Authorize rejects requests which the access policy doesn't allow the client
+// to make. Workers are identified by their token, client certificate, or
+// identity header; unidentified ones, such as the local worker, have the
+// default roles.
+func (s *Server) Authorize(nextHandler echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		required := requiredRoles(ctx.Request().Method, ctx.Path())
+		if required == nil || s.requestRoles(ctx).Has(required...) {
+			return nextHandler(ctx)
+		}
+		s.metrics.authFailures.Inc()
+		return echo.NewHTTPError(http.StatusForbidden, "Not allowed to access this resource")
+	}
+}
+
+func (s *Server) requestRoles(ctx echo.Context) rbac.Roles {
+	policy := s.config.AccessPolicy
+
+	var claimed, names []string
+	if claims, ok := ctx.Get("TokenClaims").(*oidc.Claims); ok {
+		claimed = claims.Roles
+		names = append(names, claims.ClientID)
+	}
+	if identity, ok := ctx.Get("WorkerIdentity").(string); ok {
+		names = append(names, identity)
+	}
+	if idHeader, ok := ctx.Get("IdentityHeader").(identityHeader); ok {
+		names = append(names, idHeader.Identity.AccountNumber)
+	}
+	return policy.ClientRoles(claimed, names...)
+}
+
+// requiredRoles returns the roles of which a client needs one to make a
+// request with `method` to the route `path`, or nil if everyone may make it.
+func requiredRoles(method, path string) []rbac.Role {
+	switch {
+	case strings.HasSuffix(path, "/status"):
+		return nil
+	case strings.Contains(path, "/admin/"),
+		strings.Contains(path, "/dead-letter-jobs"),
+		method == http.MethodGet && strings.HasSuffix(path, "/workers"):
+		return []rbac.Role{rbac.Admin}
+	}
+	return []rbac.Role{rbac.Worker}
+}
+
+// withoutAdminRoutes registers all routes except the ones which require the
+// admin role.
+type withoutAdminRoutes struct {
+	api.EchoRouter
+}
+
+func (r withoutAdminRoutes) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	if isAdminRoute(http.MethodGet, path) {
+		return nil
+	}
+	return r.EchoRouter.GET(path, h, m...)
+}
+
+func (r withoutAdminRoutes) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	if isAdminRoute(http.MethodPost, path) {
+		return nil
+	}
+	return r.EchoRouter.POST(path, h, m...)
+}
+
+func (r withoutAdminRoutes) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	if isAdminRoute(http.MethodDelete, path) {
+		return nil
+	}
+	return r.EchoRouter.DELETE(path, h, m...)
+}
+
+func isAdminRoute(method, path string) bool {
+	return rbac.Roles(requiredRoles(method, path)).Has(rbac.Admin)
+}
+
+// Actions of administrators which are audited, by method and route suffix
+var auditedRoutes = []struct {
+	method string
+	suffix string
+	action string
+}{
+	{http.MethodPost, "/dead-letter-jobs/:id/requeue", "dead-letter-jobs/requeue"},
+	{http.MethodPost, "/admin/jobs/:id/requeue", "jobs/requeue"},
+	{http.MethodPost, "/admin/jobs/:id/cancel", "jobs/cancel"},
+	{http.MethodPost, "/admin/workers/:id/drain", "workers/drain"},
+	{http.MethodDelete, "/admin/workers/:id/drain", "workers/undrain"},
+}
+
+// auditEvent returns the audit event of requests which change the job queue
+// or workers on behalf of an administrator, or nil for all other requests.
+func auditEvent(ctx echo.Context) *audit.Event {
+	action := ""
+	for _, r := range auditedRoutes {
+		if ctx.Request().Method == r.method && strings.HasSuffix(ctx.Path(), r.suffix) {
+			action = r.action
+			break
+		}
+	}
+	if action == "" {
+		return nil
+	}
+
+	event := &audit.Event{
+		API:    "worker",
+		Source: ctx.Request().RemoteAddr,
+		Action: action,
+		Object: ctx.Param("id"),
+	}
+	if identity, ok := ctx.Get("WorkerIdentity").(string); ok {
+		event.Actor = identity
+	} else if idHeader, ok := ctx.Get("IdentityHeader").(identityHeader); ok {
+		event.Actor = idHeader.Identity.AccountNumber
+	}
+	return event
+}
@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+)
+
+// QueuedJob is a job which is pending or running.
+type QueuedJob struct {
+	Id     uuid.UUID
+	Type   string
+	Tenant string
+
+	QueuedAt time.Time
+	// Zero while the job is pending
+	StartedAt time.Time
+}
+
+// QueueStats summarizes the job queue and the registered workers.
+type QueueStats struct {
+	Pending      int
+	Running      int
+	DeadLettered int
+
+	// Pending and running jobs by type
+	PendingByType map[string]int
+	RunningByType map[string]int
+
+	// Zero if no job is pending
+	OldestPending time.Time
+
+	Workers         int
+	DrainingWorkers int
+}
+
+// QueuedJobs returns the jobs which are pending, including the ones waiting
+// for their dependencies, or running, in the order they were queued.
+func (s *Server) QueuedJobs() ([]QueuedJob, error) {
+	ids, err := s.jobs.UnfinishedJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []QueuedJob{}
+	for _, id := range ids {
+		jobType, args, _, err := s.jobs.Job(id)
+		if err == jobqueue.ErrNotExist {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		_, queued, started, finished, canceled, _, err := s.jobs.JobStatus(id)
+		if err != nil {
+			return nil, err
+		}
+		// the job might have finished in the meantime
+		if !finished.IsZero() || canceled {
+			continue
+		}
+
+		jobs = append(jobs, QueuedJob{
+			Id:        id,
+			Type:      jobType,
+			Tenant:    argsTenant(args),
+			QueuedAt:  queued,
+			StartedAt: started,
+		})
+	}
+
+	return jobs, nil
+}
+
+// QueueStats returns statistics of the job queue and the registered workers.
+func (s *Server) QueueStats() (*QueueStats, error) {
+	jobs, err := s.QueuedJobs()
+	if err != nil {
+		return nil, err
+	}
+	deadLettered, err := s.jobs.DeadLetterJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &QueueStats{
+		DeadLettered:  len(deadLettered),
+		PendingByType: make(map[string]int),
+		RunningByType: make(map[string]int),
+	}
+	for _, j := range jobs {
+		if j.StartedAt.IsZero() {
+			stats.Pending++
+			stats.PendingByType[j.Type]++
+			if stats.OldestPending.IsZero() || j.QueuedAt.Before(stats.OldestPending) {
+				stats.OldestPending = j.QueuedAt
+			}
+		} else {
+			stats.Running++
+			stats.RunningByType[j.Type]++
+		}
+	}
+	for _, w := range s.registry.list() {
+		stats.Workers++
+		if w.Draining {
+			stats.DrainingWorkers++
+		}
+	}
+
+	return stats, nil
+}
+
+// jobTypes returns the types of jobs in `stats`, sorted.
+func (stats *QueueStats) jobTypes() []string {
+	var types []string
+	for t := range stats.PendingByType {
+		types = append(types, t)
+	}
+	for t := range stats.RunningByType {
+		if _, ok := stats.PendingByType[t]; !ok {
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// RequeueRunningJob puts the running job `id` back into the queue, so that it
+// is handed to another worker. The worker running it is told that the job
+// was canceled and cannot report its result anymore. Requeueing counts as a
+// failure of the job, but never moves it to the dead-letter queue.
+func (s *Server) RequeueRunningJob(id uuid.UUID) error {
+	_, err := s.jobs.RequeueJob(id, 0)
+	if err != nil {
+		return err
+	}
+
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+	for token, jobId := range s.running {
+		if jobId != id {
+			continue
+		}
+		delete(s.running, token)
+		delete(s.heartbeats, token)
+		delete(s.deadlines, token)
+		delete(s.progress, token)
+		delete(s.logs, token)
+		delete(s.digests, token)
+		s.revoked[token] = struct{}{}
+		s.notifyCancellation(token)
+
+		if s.config.ArtifactsDir != "" {
+			err := os.RemoveAll(path.Join(s.config.ArtifactsDir, "tmp", token.String()))
+			if err != nil {
+				s.jobLoggerByID(id).Errorf("Error removing artifacts of requeued job: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// wasRevoked returns whether the job with `token` was requeued by an
+// administrator or timed out while it was running.
+func (s *Server) wasRevoked(token uuid.UUID) bool {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+	_, ok := s.revoked[token]
+	return ok
+}
+
+// DrainWorker stops handing new jobs to the registered worker `id`, or starts
+// again if `drain` is false.
+func (s *Server) DrainWorker(id uuid.UUID, drain bool) error {
+	return s.registry.setDraining(id, drain)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/deepmap/oapi-codegen/pkg/runtime"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"time"
 )
 
 // Error defines model for Error.
@@ -15,16 +16,107 @@ type Error struct {
 	Message string `json:"message"`
 }
 
+// JobProgress defines model for JobProgress.
+type JobProgress struct {
+
+	// Position of the stage among all stages of the manifest, starting at 1.
+	Current int `json:"current"`
+
+	// Percentage of the stages of the manifest which have finished.
+	Percent *int `json:"percent,omitempty"`
+
+	// Name of the pipeline osbuild is running.
+	Pipeline *string `json:"pipeline,omitempty"`
+
+	// Name of the stage osbuild is running.
+	Stage string `json:"stage"`
+
+	// Number of stages of the manifest.
+	Total int `json:"total"`
+}
+
+// QueueStats defines model for QueueStats.
+type QueueStats struct {
+	DeadLettered    int `json:"dead_lettered"`
+	DrainingWorkers int `json:"draining_workers"`
+
+	// Number of pending and running jobs by type.
+	JobTypes []struct {
+		Pending int    `json:"pending"`
+		Running int    `json:"running"`
+		Type    string `json:"type"`
+	} `json:"job_types"`
+
+	// Seconds since the oldest pending job was queued.
+	OldestPendingAge *float32 `json:"oldest_pending_age,omitempty"`
+
+	// Number of pending jobs, including the ones waiting for their dependencies.
+	Pending int `json:"pending"`
+	Running int `json:"running"`
+
+	// Number of registered workers.
+	Workers int `json:"workers"`
+}
+
+// QueuedJob defines model for QueuedJob.
+type QueuedJob struct {
+
+	// Seconds since the job was queued.
+	Age       float32    `json:"age"`
+	Id        string     `json:"id"`
+	QueuedAt  time.Time  `json:"queued_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+
+	// Either "pending" or "running".
+	Status string  `json:"status"`
+	Tenant *string `json:"tenant,omitempty"`
+	Type   string  `json:"type"`
+}
+
+// Worker defines model for Worker.
+type Worker struct {
+	Arch string `json:"arch"`
+
+	// Whether the worker is not handed new jobs.
+	Draining *bool `json:"draining,omitempty"`
+
+	// Free space in bytes in the directory the worker builds images in.
+	FreeDisk *int64  `json:"free_disk,omitempty"`
+	Hostname *string `json:"hostname,omitempty"`
+	Id       string  `json:"id"`
+
+	// Identity from the client certificate of the worker, if client certificates are verified.
+	Identity       *string   `json:"identity,omitempty"`
+	JobTypes       []string  `json:"job_types"`
+	LastSeen       time.Time `json:"last_seen"`
+	OsbuildVersion *string   `json:"osbuild_version,omitempty"`
+	RegisteredAt   time.Time `json:"registered_at"`
+}
+
 // RequestJobJSONBody defines parameters for RequestJob.
 type RequestJobJSONBody struct {
 	Arch  string   `json:"arch"`
 	Types []string `json:"types"`
+
+	// Id returned when registering the worker. Registered workers are only handed jobs of the types they advertised.
+	WorkerId *string `json:"worker_id,omitempty"`
 }
 
 // UpdateJobJSONBody defines parameters for UpdateJob.
 type UpdateJobJSONBody struct {
-	Result interface{} `json:"result"`
-	Status string      `json:"status"`
+	Progress *JobProgress `json:"progress,omitempty"`
+	Reason   *string      `json:"reason,omitempty"`
+	Result   *interface{} `json:"result,omitempty"`
+	Status   string       `json:"status"`
+}
+
+// RegisterWorkerJSONBody defines parameters for RegisterWorker.
+type RegisterWorkerJSONBody struct {
+	Arch           string   `json:"arch"`
+	FreeDisk       *int64   `json:"free_disk,omitempty"`
+	Hostname       *string  `json:"hostname,omitempty"`
+	JobTypes       []string `json:"job_types"`
+	OsbuildVersion *string  `json:"osbuild_version,omitempty"`
 }
 
 // RequestJobRequestBody defines body for RequestJob for application/json ContentType.
@@ -33,8 +125,35 @@ type RequestJobJSONRequestBody RequestJobJSONBody
 // UpdateJobRequestBody defines body for UpdateJob for application/json ContentType.
 type UpdateJobJSONRequestBody UpdateJobJSONBody
 
+// RegisterWorkerRequestBody defines body for RegisterWorker for application/json ContentType.
+type RegisterWorkerJSONRequestBody RegisterWorkerJSONBody
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// List unfinished jobs
+	// (GET /admin/jobs)
+	GetQueuedJobs(ctx echo.Context) error
+	// Cancel a job
+	// (POST /admin/jobs/{id}/cancel)
+	AdminCancelJob(ctx echo.Context, id string) error
+	// Requeue a running job
+	// (POST /admin/jobs/{id}/requeue)
+	AdminRequeueJob(ctx echo.Context, id string) error
+	// Get statistics of the job queue
+	// (GET /admin/stats)
+	GetQueueStats(ctx echo.Context) error
+	// Stop draining a worker
+	// (DELETE /admin/workers/{id}/drain)
+	UndrainWorker(ctx echo.Context, id string) error
+	// Drain a worker
+	// (POST /admin/workers/{id}/drain)
+	DrainWorker(ctx echo.Context, id string) error
+	// List jobs in the dead-letter queue
+	// (GET /dead-letter-jobs)
+	GetDeadLetterJobs(ctx echo.Context) error
+	// Requeue a job from the dead-letter queue
+	// (POST /dead-letter-jobs/{id}/requeue)
+	RequeueDeadLetterJob(ctx echo.Context, id string) error
 	// Request a job
 	// (POST /jobs)
 	RequestJob(ctx echo.Context) error
@@ -47,9 +166,27 @@ type ServerInterface interface {
 	// Upload an artifact
 	// (PUT /jobs/{token}/artifacts/{name})
 	UploadJobArtifact(ctx echo.Context, token string, name string) error
+	// Wait for a running job to be canceled
+	// (GET /jobs/{token}/cancellation)
+	WaitForCancellation(ctx echo.Context, token string) error
+	// Download an artifact of a dependency
+	// (GET /jobs/{token}/dependencies/{index}/artifacts/{name})
+	GetDependencyArtifact(ctx echo.Context, token string, index int, name string) error
+	// Send a heartbeat for a running job
+	// (POST /jobs/{token}/heartbeat)
+	PostHeartbeat(ctx echo.Context, token string) error
+	// Append to the log of a running job
+	// (POST /jobs/{token}/log)
+	AppendJobLog(ctx echo.Context, token string) error
 	// status
 	// (GET /status)
 	GetStatus(ctx echo.Context) error
+	// List registered workers
+	// (GET /workers)
+	GetWorkers(ctx echo.Context) error
+	// Register a worker
+	// (POST /workers)
+	RegisterWorker(ctx echo.Context) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -57,6 +194,113 @@ type ServerInterfaceWrapper struct {
 	Handler ServerInterface
 }
 
+// GetQueuedJobs converts echo context to params.
+func (w *ServerInterfaceWrapper) GetQueuedJobs(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetQueuedJobs(ctx)
+	return err
+}
+
+// AdminCancelJob converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminCancelJob(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.AdminCancelJob(ctx, id)
+	return err
+}
+
+// AdminRequeueJob converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminRequeueJob(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.AdminRequeueJob(ctx, id)
+	return err
+}
+
+// GetQueueStats converts echo context to params.
+func (w *ServerInterfaceWrapper) GetQueueStats(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetQueueStats(ctx)
+	return err
+}
+
+// UndrainWorker converts echo context to params.
+func (w *ServerInterfaceWrapper) UndrainWorker(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.UndrainWorker(ctx, id)
+	return err
+}
+
+// DrainWorker converts echo context to params.
+func (w *ServerInterfaceWrapper) DrainWorker(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DrainWorker(ctx, id)
+	return err
+}
+
+// GetDeadLetterJobs converts echo context to params.
+func (w *ServerInterfaceWrapper) GetDeadLetterJobs(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetDeadLetterJobs(ctx)
+	return err
+}
+
+// RequeueDeadLetterJob converts echo context to params.
+func (w *ServerInterfaceWrapper) RequeueDeadLetterJob(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.RequeueDeadLetterJob(ctx, id)
+	return err
+}
+
 // RequestJob converts echo context to params.
 func (w *ServerInterfaceWrapper) RequestJob(ctx echo.Context) error {
 	var err error
@@ -122,6 +366,86 @@ func (w *ServerInterfaceWrapper) UploadJobArtifact(ctx echo.Context) error {
 	return err
 }
 
+// WaitForCancellation converts echo context to params.
+func (w *ServerInterfaceWrapper) WaitForCancellation(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "token" -------------
+	var token string
+
+	err = runtime.BindStyledParameter("simple", false, "token", ctx.Param("token"), &token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.WaitForCancellation(ctx, token)
+	return err
+}
+
+// GetDependencyArtifact converts echo context to params.
+func (w *ServerInterfaceWrapper) GetDependencyArtifact(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "token" -------------
+	var token string
+
+	err = runtime.BindStyledParameter("simple", false, "token", ctx.Param("token"), &token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// ------------- Path parameter "index" -------------
+	var index int
+
+	err = runtime.BindStyledParameter("simple", false, "index", ctx.Param("index"), &index)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter index: %s", err))
+	}
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameter("simple", false, "name", ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetDependencyArtifact(ctx, token, index, name)
+	return err
+}
+
+// PostHeartbeat converts echo context to params.
+func (w *ServerInterfaceWrapper) PostHeartbeat(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "token" -------------
+	var token string
+
+	err = runtime.BindStyledParameter("simple", false, "token", ctx.Param("token"), &token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PostHeartbeat(ctx, token)
+	return err
+}
+
+// AppendJobLog converts echo context to params.
+func (w *ServerInterfaceWrapper) AppendJobLog(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "token" -------------
+	var token string
+
+	err = runtime.BindStyledParameter("simple", false, "token", ctx.Param("token"), &token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.AppendJobLog(ctx, token)
+	return err
+}
+
 // GetStatus converts echo context to params.
 func (w *ServerInterfaceWrapper) GetStatus(ctx echo.Context) error {
 	var err error
@@ -131,6 +455,24 @@ func (w *ServerInterfaceWrapper) GetStatus(ctx echo.Context) error {
 	return err
 }
 
+// GetWorkers converts echo context to params.
+func (w *ServerInterfaceWrapper) GetWorkers(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetWorkers(ctx)
+	return err
+}
+
+// RegisterWorker converts echo context to params.
+func (w *ServerInterfaceWrapper) RegisterWorker(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.RegisterWorker(ctx)
+	return err
+}
+
 // This is a simple interface which specifies echo.Route addition functions which
 // are present on both echo.Echo and echo.Group, since we want to allow using
 // either of them for path registration
@@ -153,10 +495,24 @@ func RegisterHandlers(router EchoRouter, si ServerInterface) {
 		Handler: si,
 	}
 
+	router.GET("/admin/jobs", wrapper.GetQueuedJobs)
+	router.POST("/admin/jobs/:id/cancel", wrapper.AdminCancelJob)
+	router.POST("/admin/jobs/:id/requeue", wrapper.AdminRequeueJob)
+	router.GET("/admin/stats", wrapper.GetQueueStats)
+	router.DELETE("/admin/workers/:id/drain", wrapper.UndrainWorker)
+	router.POST("/admin/workers/:id/drain", wrapper.DrainWorker)
+	router.GET("/dead-letter-jobs", wrapper.GetDeadLetterJobs)
+	router.POST("/dead-letter-jobs/:id/requeue", wrapper.RequeueDeadLetterJob)
 	router.POST("/jobs", wrapper.RequestJob)
 	router.GET("/jobs/:token", wrapper.GetJob)
 	router.PATCH("/jobs/:token", wrapper.UpdateJob)
 	router.PUT("/jobs/:token/artifacts/:name", wrapper.UploadJobArtifact)
+	router.GET("/jobs/:token/cancellation", wrapper.WaitForCancellation)
+	router.GET("/jobs/:token/dependencies/:index/artifacts/:name", wrapper.GetDependencyArtifact)
+	router.POST("/jobs/:token/heartbeat", wrapper.PostHeartbeat)
+	router.POST("/jobs/:token/log", wrapper.AppendJobLog)
 	router.GET("/status", wrapper.GetStatus)
+	router.GET("/workers", wrapper.GetWorkers)
+	router.POST("/workers", wrapper.RegisterWorker)
 
 }
@@ -0,0 +1,214 @@
+package worker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/logging"
+)
+
+// Artifacts of jobs of a tenant are kept in a directory of that tenant,
+// `$STATE_DIRECTORY/artifacts/tenants/$TENANT/$JOB_ID`, so that the files of
+// different tenants are never mixed. Artifacts of jobs without a tenant are
+// kept in `$STATE_DIRECTORY/artifacts/$JOB_ID`.
+const tenantsArtifactsDir = "tenants"
+
+// The SHA-256 digest of each artifact is kept next to it, in a file with this
+// suffix, in the format of sha256sum.
+const artifactDigestSuffix = ".sha256"
+
+// ArtifactInfo describes an artifact of a finished job.
+type ArtifactInfo struct {
+	Name string
+	Size int64
+
+	// Hex-encoded SHA-256 digest of the artifact, which is empty for
+	// artifacts which were uploaded before composer computed digests
+	SHA256 string
+}
+
+// artifactDigest is the digest of the part of an artifact which has been
+// uploaded so far, for artifacts which are uploaded in chunks.
+type artifactDigest struct {
+	hash   hash.Hash
+	offset int64
+}
+
+// tenantArtifactsDir returns the directory which contains the artifacts of
+// the jobs of `tenant`. The tenant is escaped, so that it is always a single
+// path element.
+func (s *Server) tenantArtifactsDir(tenant string) string {
+	if tenant == "" {
+		return s.config.ArtifactsDir
+	}
+	name := strings.ReplaceAll(url.PathEscape(tenant), ".", "%2E")
+	return path.Join(s.config.ArtifactsDir, tenantsArtifactsDir, name)
+}
+
+// jobArtifactsDir returns the directory which contains the artifacts of the
+// finished job `id`.
+func (s *Server) jobArtifactsDir(id uuid.UUID) (string, error) {
+	_, args, _, err := s.jobs.Job(id)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(s.tenantArtifactsDir(argsTenant(args)), id.String()), nil
+}
+
+// validArtifactName returns whether `name` can be used as the name of an
+// artifact, i.e., whether it names a file in the artifacts directory of a
+// job.
+func validArtifactName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.Contains(name, "/")
+}
+
+// chunkDigest returns the digest of the artifact `name` of the job with
+// `token` for the chunk starting at `start`. It returns nil if the chunk
+// doesn't continue the part of the artifact whose digest is known, because an
+// earlier chunk is sent again.
+func (s *Server) chunkDigest(token uuid.UUID, name string, start int64) *artifactDigest {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+
+	if _, ok := s.running[token]; !ok {
+		return nil
+	}
+
+	digest := s.digests[token][name]
+	if start == 0 {
+		digest = &artifactDigest{hash: sha256.New()}
+		if s.digests[token] == nil {
+			s.digests[token] = make(map[string]*artifactDigest)
+		}
+		s.digests[token][name] = digest
+	}
+	if digest == nil || digest.offset != start {
+		delete(s.digests[token], name)
+		return nil
+	}
+	return digest
+}
+
+// forgetChunkDigest forgets the digest of the artifact `name` of the job with
+// `token`, after the artifact was uploaded or a chunk of it failed.
+func (s *Server) forgetChunkDigest(token uuid.UUID, name string) {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+	delete(s.digests[token], name)
+}
+
+// writeArtifactDigest writes the digest `sum` of the artifact at `p` into the
+// file next to it.
+func writeArtifactDigest(p string, sum []byte) error {
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum), path.Base(p))
+	err := ioutil.WriteFile(p+artifactDigestSuffix, []byte(line), 0600)
+	if err != nil {
+		return fmt.Errorf("cannot write digest of artifact: %v", err)
+	}
+	return nil
+}
+
+// readArtifactDigest returns the hex-encoded digest of the artifact at `p`,
+// or an empty string if it has none.
+func readArtifactDigest(p string) string {
+	line, err := ioutil.ReadFile(p + artifactDigestSuffix)
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// fileDigest returns the SHA-256 digest of the file at `p`.
+func fileDigest(p string) ([]byte, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// JobArtifacts returns the artifacts of the finished job `id`, with their
+// sizes and digests, sorted by name.
+func (s *Server) JobArtifacts(id uuid.UUID) ([]ArtifactInfo, error) {
+	if s.config.ArtifactsDir == "" {
+		return nil, errors.New("Artifacts not enabled")
+	}
+
+	dir, err := s.jobArtifactsDir(id)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []ArtifactInfo{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	artifacts := []ArtifactInfo{}
+	for _, info := range infos {
+		if !info.Mode().IsRegular() || strings.HasSuffix(info.Name(), artifactDigestSuffix) {
+			continue
+		}
+		artifacts = append(artifacts, ArtifactInfo{
+			Name:   info.Name(),
+			Size:   info.Size(),
+			SHA256: readArtifactDigest(path.Join(dir, info.Name())),
+		})
+	}
+	return artifacts, nil
+}
+
+// moveArtifactsToTenants moves the artifacts of jobs of a tenant, which were
+// kept in the artifacts directory itself by older versions, to the directory
+// of their tenant.
+func (s *Server) moveArtifactsToTenants() {
+	infos, err := ioutil.ReadDir(s.config.ArtifactsDir)
+	if err != nil {
+		return
+	}
+
+	for _, info := range infos {
+		jobId, err := uuid.Parse(info.Name())
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		dir, err := s.jobArtifactsDir(jobId)
+		if err != nil || dir == path.Join(s.config.ArtifactsDir, info.Name()) {
+			continue
+		}
+		err = os.MkdirAll(path.Dir(dir), 0700)
+		if err == nil {
+			err = os.Rename(path.Join(s.config.ArtifactsDir, info.Name()), dir)
+		}
+		if err == nil {
+			// artifacts are retained from the time the job finished
+			err = os.Chtimes(dir, info.ModTime(), info.ModTime())
+		}
+		if err != nil {
+			logging.Default().Errorf("Error moving the artifacts of job %s to the directory of its tenant: %v", jobId, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobBroker distributes jobs to workers through a message broker, as an
+// alternative to workers polling the API for jobs. Workers subscribe to the
+// job types they support and only use the API to report results and upload
+// artifacts.
+//
+// Jobs are published as the JSON response to a job request, with locations
+// relative to the API's base path.
+type JobBroker interface {
+	// Publish makes `job` available to the workers subscribed to
+	// `jobType`, e.g., "osbuild:x86_64".
+	Publish(jobType string, job []byte) error
+
+	// Unclaimed returns the number of published jobs of `jobType` which
+	// have not been delivered to a worker yet.
+	Unclaimed(jobType string) (int, error)
+}
+
+// How often dispatchers check whether workers claimed a published job.
+var brokerPollInterval = 5 * time.Second
+
+// ArchJobType returns the type under which jobs of type `jobType` for
+// architecture `arch` are queued. Only osbuild jobs are bound to an
+// architecture.
+func ArchJobType(jobType, arch string) string {
+	if jobType == "osbuild" || jobType == "osbuild-koji" {
+		return jobType + ":" + arch
+	}
+	return jobType
+}
+
+// Hands jobs of `jobType` to workers through the broker. A job is only
+// dequeued after the previous one was claimed by a worker, so that jobs
+// waiting in the broker don't pile up as running in the queue. Returns when
+// the server is closed.
+func (s *Server) dispatchJobs(jobType string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		token, jobId, _, args, dynamicArgs, err := s.RequestJob(ctx, "", []string{jobType})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Error dequeuing %s job for the broker: %v", jobType, err)
+			if !s.sleep(brokerPollInterval) {
+				return
+			}
+			continue
+		}
+
+		err = s.publishJob(token, jobId, jobType, args, dynamicArgs)
+		if err != nil {
+			jobLogger(jobId, jobType, args).Errorf("Error publishing job to the broker, requeuing it: %v", err)
+			s.requeueUnclaimedJob(token, jobId)
+			if !s.sleep(brokerPollInterval) {
+				return
+			}
+			continue
+		}
+
+		if !s.waitUntilClaimed(token, jobType) {
+			return
+		}
+	}
+}
+
+// Sleeps for `d`. Returns false if the server was closed in the meantime.
+func (s *Server) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-s.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (s *Server) publishJob(token, jobId uuid.UUID, jobType string, args json.RawMessage, dynamicArgs []json.RawMessage) error {
+	response, err := s.jobResponse("", token, jobId, baseJobType(jobType), args, dynamicArgs)
+	if err != nil {
+		return err
+	}
+
+	message, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	return s.config.Broker.Publish(jobType, message)
+}
+
+// Blocks until the job with `token` was delivered to a worker. Until then,
+// the dispatcher sends heartbeats in the worker's stead. Returns false if
+// the server was closed before. The job then stays in the broker, where a
+// worker might still claim it.
+func (s *Server) waitUntilClaimed(token uuid.UUID, jobType string) bool {
+	for {
+		unclaimed, err := s.config.Broker.Unclaimed(jobType)
+		if err != nil {
+			log.Printf("Error getting unclaimed %s jobs from the broker: %v", jobType, err)
+		} else if unclaimed == 0 {
+			return true
+		}
+
+		// The job stopped running in the meantime
+		if s.Heartbeat(token) == ErrTokenNotExist {
+			return true
+		}
+
+		if !s.sleep(brokerPollInterval) {
+			return false
+		}
+	}
+}
+
+// Puts a job which could not be handed to the broker back into the queue.
+func (s *Server) requeueUnclaimedJob(token, jobId uuid.UUID) {
+	s.runningMutex.Lock()
+	delete(s.running, token)
+	delete(s.heartbeats, token)
+	delete(s.deadlines, token)
+	delete(s.progress, token)
+	s.notifyCancellation(token)
+	s.runningMutex.Unlock()
+
+	if s.config.ArtifactsDir != "" {
+		err := os.RemoveAll(path.Join(s.config.ArtifactsDir, "tmp", token.String()))
+		if err != nil {
+			s.jobLoggerByID(jobId).Errorf("Error removing artifacts: %v", err)
+		}
+	}
+
+	_, err := s.jobs.RequeueJob(jobId, 0)
+	if err != nil {
+		s.jobLoggerByID(jobId).Errorf("Error requeuing job: %v", err)
+		return
+	}
+}
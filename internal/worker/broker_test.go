@@ -0,0 +1,146 @@
+package worker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue/fsjobqueue"
+)
+
+func init() {
+	// set before any dispatcher runs, they keep reading it until closed
+	brokerPollInterval = 10 * time.Millisecond
+}
+
+// An in-memory JobBroker, from which tests claim jobs.
+type testBroker struct {
+	mu   sync.Mutex
+	jobs map[string][][]byte
+}
+
+func (b *testBroker) Publish(jobType string, job []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.jobs[jobType] = append(b.jobs[jobType], job)
+	return nil
+}
+
+func (b *testBroker) Unclaimed(jobType string) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.jobs[jobType]), nil
+}
+
+func (b *testBroker) claim(t *testing.T, jobType string) []byte {
+	for i := 0; i < 500; i++ {
+		b.mu.Lock()
+		if len(b.jobs[jobType]) > 0 {
+			job := b.jobs[jobType][0]
+			b.jobs[jobType] = b.jobs[jobType][1:]
+			b.mu.Unlock()
+			return job
+		}
+		b.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("no %s job was published", jobType)
+	return nil
+}
+
+func TestBroker(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+
+	broker := &testBroker{jobs: make(map[string][][]byte)}
+	server := NewServer(nil, q, Config{
+		Broker:         broker,
+		BrokerJobTypes: []string{ArchJobType("osbuild", "x86_64"), ArchJobType("koji-init", "x86_64")},
+	})
+	defer server.Close()
+	srv := httptest.NewServer(server.Handler())
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+
+	firstID, err := server.EnqueueOSBuild(context.Background(), "x86_64", &OSBuildJob{}, PriorityBatch, "")
+	require.NoError(t, err)
+	secondID, err := server.EnqueueOSBuild(context.Background(), "x86_64", &OSBuildJob{}, PriorityBatch, "")
+	require.NoError(t, err)
+	initID, err := server.EnqueueKojiInit(context.Background(), &KojiInitJob{})
+	require.NoError(t, err)
+
+	// the second job is only dequeued when the first was claimed
+	time.Sleep(5 * brokerPollInterval)
+	unclaimed, err := broker.Unclaimed("osbuild:x86_64")
+	require.NoError(t, err)
+	require.Equal(t, 1, unclaimed)
+	status, _, err := server.JobStatus(secondID, &OSBuildJobResult{})
+	require.NoError(t, err)
+	require.True(t, status.Started.IsZero())
+
+	job, err := client.JobFromMessage(broker.claim(t, "osbuild:x86_64"))
+	require.NoError(t, err)
+	require.Equal(t, firstID, job.Id())
+	require.Equal(t, "osbuild", job.Type())
+	require.NoError(t, job.Update(&OSBuildJobResult{Success: true}))
+
+	var result OSBuildJobResult
+	status, _, err = server.JobStatus(firstID, &result)
+	require.NoError(t, err)
+	require.False(t, status.Finished.IsZero())
+	require.True(t, result.Success)
+
+	job, err = client.JobFromMessage(broker.claim(t, "osbuild:x86_64"))
+	require.NoError(t, err)
+	require.Equal(t, secondID, job.Id())
+
+	job, err = client.JobFromMessage(broker.claim(t, "koji-init"))
+	require.NoError(t, err)
+	require.Equal(t, initID, job.Id())
+	require.Equal(t, "koji-init", job.Type())
+	canceled, err := job.Canceled()
+	require.NoError(t, err)
+	require.False(t, canceled)
+}
+
+func TestBrokerClose(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+
+	broker := &testBroker{jobs: make(map[string][][]byte)}
+	server := NewServer(nil, q, Config{
+		Broker:         broker,
+		BrokerJobTypes: []string{ArchJobType("osbuild", "x86_64")},
+	})
+	server.Close()
+
+	// give the dispatcher time to stop
+	time.Sleep(5 * brokerPollInterval)
+
+	id, err := server.EnqueueOSBuild(context.Background(), "x86_64", &OSBuildJob{}, PriorityBatch, "")
+	require.NoError(t, err)
+
+	time.Sleep(5 * brokerPollInterval)
+	unclaimed, err := broker.Unclaimed("osbuild:x86_64")
+	require.NoError(t, err)
+	require.Equal(t, 0, unclaimed)
+	status, _, err := server.JobStatus(id, &OSBuildJobResult{})
+	require.NoError(t, err)
+	require.True(t, status.Started.IsZero())
+}
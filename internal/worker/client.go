@@ -5,11 +5,13 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
@@ -32,17 +34,32 @@ type Client struct {
 	bearerToken      *bearerToken
 
 	tokenMu *sync.Mutex
+
+	// Set after the worker registered with Register().
+	registration *WorkerInfo
 }
 
+// ErrNoDynamicArgsTypes is returned by DynamicArgsOfType() when talking to a
+// server which doesn't send the types of a job's dependencies.
+var ErrNoDynamicArgsTypes = errors.New("server did not send the types of the job's dependencies")
+
 type Job interface {
 	Id() uuid.UUID
 	Type() string
+	Timeout() time.Duration
 	Args(args interface{}) error
 	DynamicArgs(i int, args interface{}) error
 	NDynamicArgs() int
+	DynamicArgsOfType(jobType string, args interface{}) error
 	Update(result interface{}) error
 	Canceled() (bool, error)
+	WaitForCancellation(ctx context.Context) (bool, error)
+	Heartbeat() error
+	UpdateProgress(progress *JobProgress) error
+	Yield(reason string) error
+	AppendLog(chunk []byte) error
 	UploadArtifact(name string, reader io.Reader) error
+	DownloadDependencyArtifact(i int, name string, writer io.Writer) error
 }
 
 type job struct {
@@ -53,6 +70,8 @@ type job struct {
 	jobType          string
 	args             json.RawMessage
 	dynamicArgs      []json.RawMessage
+	dynamicArgsTypes []string
+	timeout          time.Duration
 }
 
 func NewClient(baseURL string, conf *tls.Config, offlineToken, oAuthURL *string) (*Client, error) {
@@ -81,7 +100,7 @@ func NewClient(baseURL string, conf *tls.Config, offlineToken, oAuthURL *string)
 		}
 	}
 
-	return &Client{server, requester, offlineToken, oAuthURL, nil, nil, &sync.Mutex{}}, nil
+	return &Client{server, requester, offlineToken, oAuthURL, nil, nil, &sync.Mutex{}, nil}, nil
 }
 
 func NewClientUnix(path string) *Client {
@@ -103,7 +122,7 @@ func NewClientUnix(path string) *Client {
 		},
 	}
 
-	return &Client{server, requester, nil, nil, nil, nil, nil}
+	return &Client{server, requester, nil, nil, nil, nil, nil, nil}
 }
 
 // Note: Only call this function with Client.tokenMu locked!
@@ -161,18 +180,102 @@ func (c *Client) NewRequest(method, url string, body io.Reader) (*http.Request,
 	return req, nil
 }
 
+// Register announces the worker and its capabilities to the server. The
+// server only hands out jobs of the types in `info.JobTypes` to it
+// afterwards. The id, registration time, and last-seen time in `info` are
+// ignored.
+func (c *Client) Register(info WorkerInfo) error {
+	url, err := c.server.Parse("workers")
+	if err != nil {
+		// This only happens when "workers" cannot be parsed.
+		panic(err)
+	}
+
+	body := api.RegisterWorkerJSONRequestBody{
+		Arch:     info.Arch,
+		JobTypes: info.JobTypes,
+	}
+	if info.Hostname != "" {
+		body.Hostname = &info.Hostname
+	}
+	if info.FreeDisk > 0 {
+		freeDisk := int64(info.FreeDisk)
+		body.FreeDisk = &freeDisk
+	}
+	if info.OSBuildVersion != "" {
+		body.OsbuildVersion = &info.OSBuildVersion
+	}
+
+	var buf bytes.Buffer
+	err = json.NewEncoder(&buf).Encode(body)
+	if err != nil {
+		panic(err)
+	}
+
+	req, err := c.NewRequest("POST", url.String(), &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	response, err := c.requester.Do(req)
+	if err != nil {
+		return fmt.Errorf("error registering worker: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		return errorFromResponse(response, "error registering worker")
+	}
+
+	var rr registerWorkerResponse
+	err = json.NewDecoder(response.Body).Decode(&rr)
+	if err != nil {
+		return fmt.Errorf("error parsing response: %v", err)
+	}
+
+	info.Id = rr.Id
+	c.registration = &info
+
+	return nil
+}
+
 func (c *Client) RequestJob(types []string, arch string) (Job, error) {
+	job, err := c.requestJob(types, arch)
+
+	// The server forgets about registered workers when it restarts.
+	// Register again and retry once.
+	if err == errWorkerNotRegistered {
+		err = c.Register(*c.registration)
+		if err != nil {
+			return nil, err
+		}
+		job, err = c.requestJob(types, arch)
+	}
+
+	return job, err
+}
+
+var errWorkerNotRegistered = errors.New("worker is not registered")
+
+func (c *Client) requestJob(types []string, arch string) (Job, error) {
 	url, err := c.server.Parse("jobs")
 	if err != nil {
 		// This only happens when "jobs" cannot be parsed.
 		panic(err)
 	}
 
-	var buf bytes.Buffer
-	err = json.NewEncoder(&buf).Encode(api.RequestJobJSONRequestBody{
+	body := api.RequestJobJSONRequestBody{
 		Types: types,
 		Arch:  arch,
-	})
+	}
+	if c.registration != nil {
+		workerID := c.registration.Id.String()
+		body.WorkerId = &workerID
+	}
+
+	var buf bytes.Buffer
+	err = json.NewEncoder(&buf).Encode(body)
 	if err != nil {
 		panic(err)
 	}
@@ -189,6 +292,10 @@ func (c *Client) RequestJob(types []string, arch string) (Job, error) {
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusNotFound && c.registration != nil {
+		return nil, errWorkerNotRegistered
+	}
+
 	if response.StatusCode != http.StatusCreated {
 		return nil, errorFromResponse(response, "error requesting job")
 	}
@@ -199,6 +306,22 @@ func (c *Client) RequestJob(types []string, arch string) (Job, error) {
 		return nil, fmt.Errorf("error parsing response: %v", err)
 	}
 
+	return c.newJob(&jr)
+}
+
+// JobFromMessage returns the job in `message`, which was received from a
+// message broker the server publishes jobs to. See JobBroker.
+func (c *Client) JobFromMessage(message []byte) (Job, error) {
+	var jr requestJobResponse
+	err := json.Unmarshal(message, &jr)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing job message: %v", err)
+	}
+
+	return c.newJob(&jr)
+}
+
+func (c *Client) newJob(jr *requestJobResponse) (Job, error) {
 	location, err := c.server.Parse(jr.Location)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing location url in response: %v", err)
@@ -215,6 +338,8 @@ func (c *Client) RequestJob(types []string, arch string) (Job, error) {
 		jobType:          jr.Type,
 		args:             jr.Args,
 		dynamicArgs:      jr.DynamicArgs,
+		dynamicArgsTypes: jr.DynamicArgsTypes,
+		timeout:          time.Duration(jr.Timeout) * time.Second,
 		location:         location.String(),
 		artifactLocation: artifactLocation.String(),
 	}, nil
@@ -228,6 +353,12 @@ func (j *job) Type() string {
 	return j.jobType
 }
 
+// Timeout returns how long the job may run before composer fails it, or
+// zero if it may run forever.
+func (j *job) Timeout() time.Duration {
+	return j.timeout
+}
+
 func (j *job) Args(args interface{}) error {
 	err := json.Unmarshal(j.args, args)
 	if err != nil {
@@ -248,10 +379,36 @@ func (j *job) DynamicArgs(i int, args interface{}) error {
 	return nil
 }
 
+// DynamicArgsOfType unmarshals the results of all dependencies of type
+// `jobType` into `args`, which must be a pointer to a slice. Returns
+// ErrNoDynamicArgsTypes if the server didn't send the types of the job's
+// dependencies.
+func (j *job) DynamicArgsOfType(jobType string, args interface{}) error {
+	if len(j.dynamicArgsTypes) != len(j.dynamicArgs) {
+		return ErrNoDynamicArgsTypes
+	}
+
+	results := []json.RawMessage{}
+	for i, t := range j.dynamicArgsTypes {
+		if t == jobType {
+			results = append(results, j.dynamicArgs[i])
+		}
+	}
+
+	// marshaling a slice of raw messages cannot fail
+	buf, _ := json.Marshal(results)
+	err := json.Unmarshal(buf, args)
+	if err != nil {
+		return fmt.Errorf("error parsing job arguments: %v", err)
+	}
+	return nil
+}
+
 func (j *job) Update(result interface{}) error {
 	var buf bytes.Buffer
 	err := json.NewEncoder(&buf).Encode(api.UpdateJobJSONRequestBody{
-		Result: result,
+		Status: "FINISHED",
+		Result: &result,
 	})
 	if err != nil {
 		panic(err)
@@ -302,6 +459,144 @@ func (j *job) Canceled() (bool, error) {
 	return jr.Canceled, nil
 }
 
+// WaitForCancellation blocks until the job is canceled or the server's
+// timeout elapses. Returns whether the job was canceled. Call it in a loop to
+// be notified as soon as the job is canceled.
+func (j *job) WaitForCancellation(ctx context.Context) (bool, error) {
+	req, err := j.client.NewRequest("GET", j.location+"/cancellation", nil)
+	if err != nil {
+		return false, err
+	}
+
+	response, err := j.client.requester.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("error waiting for cancellation: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return false, errorFromResponse(response, "error waiting for cancellation")
+	}
+
+	var wr waitForCancellationResponse
+	err = json.NewDecoder(response.Body).Decode(&wr)
+	if err != nil {
+		return false, fmt.Errorf("error parsing reponse: %v", err)
+	}
+
+	return wr.Canceled, nil
+}
+
+func (j *job) Heartbeat() error {
+	req, err := j.client.NewRequest("POST", j.location+"/heartbeat", nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := j.client.requester.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending heartbeat: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errorFromResponse(response, "error sending heartbeat")
+	}
+
+	return nil
+}
+
+// UpdateProgress reports the progress of the running job to composer.
+func (j *job) UpdateProgress(progress *JobProgress) error {
+	var buf bytes.Buffer
+	err := json.NewEncoder(&buf).Encode(updateJobRequest{
+		Status:   "RUNNING",
+		Progress: progress,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	req, err := j.client.NewRequest("PATCH", j.location, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	response, err := j.client.requester.Do(req)
+	if err != nil {
+		return fmt.Errorf("error reporting progress: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errorFromResponse(response, "error reporting progress")
+	}
+
+	return nil
+}
+
+// Yield gives the job back to composer without running it, because of
+// `reason`. Composer hands it to another worker.
+func (j *job) Yield(reason string) error {
+	var buf bytes.Buffer
+	err := json.NewEncoder(&buf).Encode(updateJobRequest{
+		Status: "YIELDED",
+		Reason: reason,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	req, err := j.client.NewRequest("PATCH", j.location, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	response, err := j.client.requester.Do(req)
+	if err != nil {
+		return fmt.Errorf("error yielding job: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errorFromResponse(response, "error yielding job")
+	}
+
+	return nil
+}
+
+// AppendLog sends the next part of the log of the running job to composer.
+func (j *job) AppendLog(chunk []byte) error {
+	req, err := j.client.NewRequest("POST", j.location+"/log", bytes.NewReader(chunk))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "text/plain")
+
+	response, err := j.client.requester.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending log: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errorFromResponse(response, "error sending log")
+	}
+
+	return nil
+}
+
+// Artifacts are uploaded in chunks of this size. When the upload of a chunk
+// fails, only that chunk is sent again, instead of the whole artifact.
+const artifactChunkSize = 32 * 1024 * 1024
+
+// How often the upload of a single chunk is attempted before giving up, and
+// how long to wait before the first retry. The delay grows with each attempt.
+const artifactChunkAttempts = 5
+const artifactChunkRetryDelay = time.Second
+
 func (j *job) UploadArtifact(name string, reader io.Reader) error {
 	if j.artifactLocation == "" {
 		return fmt.Errorf("server does not accept artifacts for this job")
@@ -317,20 +612,103 @@ func (j *job) UploadArtifact(name string, reader io.Reader) error {
 		panic(err)
 	}
 
-	req, err := j.client.NewRequest("PUT", loc.String(), reader)
+	chunk := make([]byte, artifactChunkSize)
+	var offset int64
+	for {
+		n, err := io.ReadFull(reader, chunk)
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return fmt.Errorf("error reading artifact: %v", err)
+		}
+
+		// Artifacts which fit into a single chunk are uploaded without
+		// Content-Range, which is also what older servers expect.
+		var contentRange string
+		if offset > 0 || !last {
+			if n == 0 {
+				break
+			}
+			total := "*"
+			if last {
+				total = strconv.FormatInt(offset+int64(n), 10)
+			}
+			contentRange = fmt.Sprintf("bytes %d-%d/%s", offset, offset+int64(n)-1, total)
+		}
+
+		err = j.uploadArtifactChunk(loc.String(), chunk[:n], contentRange)
+		if err != nil {
+			return err
+		}
+
+		if last {
+			break
+		}
+		offset += int64(n)
+	}
+
+	return nil
+}
+
+// Uploads a single chunk of an artifact, retrying on network and server
+// errors.
+func (j *job) uploadArtifactChunk(location string, chunk []byte, contentRange string) error {
+	for attempt := 1; ; attempt++ {
+		retry, err := j.putArtifactChunk(location, chunk, contentRange)
+		if err == nil || !retry || attempt == artifactChunkAttempts {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * artifactChunkRetryDelay)
+	}
+}
+
+// Sends a single chunk of an artifact to the server. Returns whether the
+// request may be retried in case of an error.
+func (j *job) putArtifactChunk(location string, chunk []byte, contentRange string) (bool, error) {
+	req, err := j.client.NewRequest("PUT", location, bytes.NewReader(chunk))
 	if err != nil {
-		return fmt.Errorf("cannot create request: %v", err)
+		return true, fmt.Errorf("cannot create request: %v", err)
 	}
 
 	req.Header.Add("Content-Type", "application/octet-stream")
+	if contentRange != "" {
+		req.Header.Add("Content-Range", contentRange)
+	}
 
 	response, err := j.client.requester.Do(req)
 	if err != nil {
-		return fmt.Errorf("error uploading artifact: %v", err)
+		return true, fmt.Errorf("error uploading artifact: %v", err)
 	}
+	defer response.Body.Close()
 
-	if response.StatusCode != 200 {
-		return errorFromResponse(response, "error uploading artifact")
+	if response.StatusCode != http.StatusOK {
+		return response.StatusCode >= 500, errorFromResponse(response, "error uploading artifact")
+	}
+
+	return false, nil
+}
+
+// DownloadDependencyArtifact writes the artifact `name` of the job's `i`th
+// dependency to `writer`. The index is the same as for DynamicArgs().
+func (j *job) DownloadDependencyArtifact(i int, name string, writer io.Writer) error {
+	loc := fmt.Sprintf("%s/dependencies/%d/artifacts/%s", j.location, i, url.PathEscape(name))
+	req, err := j.client.NewRequest("GET", loc, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := j.client.requester.Do(req)
+	if err != nil {
+		return fmt.Errorf("error downloading artifact: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errorFromResponse(response, "error downloading artifact")
+	}
+
+	_, err = io.Copy(writer, response.Body)
+	if err != nil {
+		return fmt.Errorf("error downloading artifact: %v", err)
 	}
 
 	return nil
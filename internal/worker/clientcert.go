@@ -0,0 +1,215 @@
+package worker
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientCertConfig configures how the server authenticates remote workers by
+// the client certificates they present.
+type ClientCertConfig struct {
+	// CAs which issue the certificates of workers. If nil, the CAs
+	// trusted by the host are used.
+	CAs *x509.CertPool
+
+	// If not empty, the certificate must be valid for one of these
+	// domains.
+	AllowedDomains []string
+
+	// Files containing PEM or DER encoded certificate revocation lists.
+	// They are read again when they change.
+	CRLFiles []string
+
+	// Ask the OCSP responders named in certificates whether they were
+	// revoked.
+	OCSP bool
+}
+
+// clientCertVerifier verifies client certificates according to a
+// ClientCertConfig. Only the certificates of workers are checked for
+// revocation, not the intermediate CAs which issued them.
+type clientCertVerifier struct {
+	config *ClientCertConfig
+	crls   *crlSet
+	ocsp   *ocspChecker
+}
+
+func newClientCertVerifier(config *ClientCertConfig) *clientCertVerifier {
+	v := &clientCertVerifier{
+		config: config,
+		crls:   newCRLSet(config.CRLFiles),
+	}
+	if config.OCSP {
+		v.ocsp = newOCSPChecker()
+	}
+	return v
+}
+
+// verify verifies the certificate chain a worker presented and returns the
+// identity of the worker.
+func (v *clientCertVerifier) verify(certs []*x509.Certificate, now time.Time) (string, error) {
+	if len(certs) == 0 {
+		return "", errors.New("no client certificate")
+	}
+	leaf := certs[0]
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	chains, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         v.config.CAs,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(v.config.AllowedDomains) > 0 && !v.allowed(leaf) {
+		return "", errors.New("domain not in allowlist")
+	}
+
+	// a certificate in the pool of CAs is its own issuer
+	issuer := leaf
+	if len(chains[0]) > 1 {
+		issuer = chains[0][1]
+	}
+
+	if err := v.crls.check(leaf, issuer, now); err != nil {
+		return "", err
+	}
+	if v.ocsp != nil {
+		if err := v.ocsp.check(leaf, issuer, now); err != nil {
+			return "", err
+		}
+	}
+
+	return certIdentity(leaf), nil
+}
+
+func (v *clientCertVerifier) allowed(cert *x509.Certificate) bool {
+	for _, domain := range v.config.AllowedDomains {
+		if cert.VerifyHostname(domain) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// certIdentity returns the identity of the worker which owns `cert`: its
+// first DNS or URI subject alternative name, or its common name if it has
+// none.
+func certIdentity(cert *x509.Certificate) string {
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	return cert.Subject.CommonName
+}
+
+// crlSet keeps the certificate revocation lists read from a set of files.
+type crlSet struct {
+	files []string
+
+	mu     sync.Mutex
+	loaded map[string]*crlFile
+}
+
+type crlFile struct {
+	modTime time.Time
+	lists   []*pkix.CertificateList
+}
+
+func newCRLSet(files []string) *crlSet {
+	return &crlSet{
+		files:  files,
+		loaded: make(map[string]*crlFile),
+	}
+}
+
+// check returns an error if `cert` was revoked by `issuer`, or if the
+// revocation list of `issuer` has expired. Certificates of issuers without a
+// revocation list are not checked.
+func (c *crlSet) check(cert, issuer *x509.Certificate, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range c.files {
+		f, err := c.load(name)
+		if err != nil {
+			return err
+		}
+		for _, list := range f.lists {
+			if issuer.CheckCRLSignature(list) != nil {
+				continue
+			}
+			if list.HasExpired(now) {
+				return fmt.Errorf("revocation list %s has expired", name)
+			}
+			for _, revoked := range list.TBSCertList.RevokedCertificates {
+				if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+					return fmt.Errorf("certificate %s was revoked at %s", cert.SerialNumber, revoked.RevocationTime)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// load returns the revocation lists in `name`, reading them again if the file
+// changed since it was read last.
+func (c *crlSet) load(name string) (*crlFile, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read revocation list: %v", err)
+	}
+	if f, ok := c.loaded[name]; ok && f.modTime.Equal(info.ModTime()) {
+		return f, nil
+	}
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read revocation list: %v", err)
+	}
+
+	f := &crlFile{modTime: info.ModTime()}
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "X509 CRL" {
+			continue
+		}
+		list, err := x509.ParseDERCRL(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse revocation list %s: %v", name, err)
+		}
+		f.lists = append(f.lists, list)
+	}
+	// not PEM encoded
+	if len(f.lists) == 0 {
+		list, err := x509.ParseDERCRL(data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse revocation list %s: %v", name, err)
+		}
+		f.lists = append(f.lists, list)
+	}
+
+	c.loaded[name] = f
+	return f, nil
+}
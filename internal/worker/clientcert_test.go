@@ -0,0 +1,300 @@
+package worker
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue/fsjobqueue"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Worker CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert, key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue returns a client certificate for `template`, which only needs to
+// contain the serial number and names.
+func (ca *testCA) issue(t *testing.T, template *x509.Certificate) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func (ca *testCA) writeCRL(t *testing.T, name string, modTime time.Time, revoked ...*x509.Certificate) {
+	var list []pkix.RevokedCertificate
+	for _, cert := range revoked {
+		list = append(list, pkix.RevokedCertificate{
+			SerialNumber:   cert.SerialNumber,
+			RevocationTime: time.Now().Add(-time.Minute),
+		})
+	}
+	der, err := ca.cert.CreateCRL(rand.Reader, ca.key, list, time.Now(), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), 0600))
+	require.NoError(t, os.Chtimes(name, modTime, modTime))
+}
+
+// ocspResponse returns a response of the CA for `cert`, which says that it
+// is good or was revoked.
+func (ca *testCA) ocspResponse(t *testing.T, cert *x509.Certificate, revoked bool) []byte {
+	id, err := newOCSPCertID(cert, ca.cert)
+	require.NoError(t, err)
+
+	single := ocspSingleResponse{
+		CertID:     *id,
+		ThisUpdate: time.Now().Add(-time.Minute).UTC(),
+		NextUpdate: time.Now().Add(time.Hour).UTC(),
+	}
+	if revoked {
+		single.Revoked.RevocationTime = time.Now().Add(-time.Minute).UTC()
+	} else {
+		single.Good = true
+	}
+
+	keyHash, err := asn1.Marshal(id.IssuerKeyHash)
+	require.NoError(t, err)
+	data := ocspResponseData{
+		ResponderID: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, IsCompound: true, Bytes: keyHash},
+		ProducedAt:  time.Now().UTC(),
+		Responses:   []ocspSingleResponse{single},
+	}
+	data.Raw, err = asn1.Marshal(data)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256(data.Raw)
+	signature, err := ca.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+
+	basic, err := asn1.Marshal(ocspBasicResponse{
+		TBSResponseData:    data,
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+		Signature:          asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)},
+	})
+	require.NoError(t, err)
+
+	var resp ocspResponse
+	resp.Response.ResponseType = oidOCSPBasic
+	resp.Response.Response = basic
+	der, err := asn1.Marshal(resp)
+	require.NoError(t, err)
+	return der
+}
+
+func TestClientCertIdentity(t *testing.T) {
+	ca := newTestCA(t)
+	v := newClientCertVerifier(&ClientCertConfig{
+		CAs:            ca.pool(),
+		AllowedDomains: []string{"worker.osbuild.org"},
+	})
+
+	identity, err := v.verify([]*x509.Certificate{ca.issue(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "worker-1"},
+		DNSNames:     []string{"worker.osbuild.org"},
+	})}, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "worker.osbuild.org", identity)
+
+	_, err = v.verify([]*x509.Certificate{ca.issue(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		DNSNames:     []string{"worker.example.com"},
+	})}, time.Now())
+	require.EqualError(t, err, "domain not in allowlist")
+
+	_, err = v.verify([]*x509.Certificate{newTestCA(t).issue(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		DNSNames:     []string{"worker.osbuild.org"},
+	})}, time.Now())
+	require.Error(t, err)
+
+	_, err = v.verify(nil, time.Now())
+	require.EqualError(t, err, "no client certificate")
+
+	require.Equal(t, "worker-1", certIdentity(&x509.Certificate{Subject: pkix.Name{CommonName: "worker-1"}}))
+}
+
+func TestClientCertCRL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "worker-crl-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCA(t)
+	cert := ca.issue(t, &x509.Certificate{SerialNumber: big.NewInt(2), DNSNames: []string{"worker"}})
+
+	// revocation lists of other CAs are ignored
+	other := newTestCA(t)
+	other.writeCRL(t, filepath.Join(dir, "other.pem"), time.Now(), cert)
+
+	crl := filepath.Join(dir, "crl.pem")
+	ca.writeCRL(t, crl, time.Now().Add(-time.Hour))
+
+	v := newClientCertVerifier(&ClientCertConfig{
+		CAs:      ca.pool(),
+		CRLFiles: []string{filepath.Join(dir, "other.pem"), crl},
+	})
+	_, err = v.verify([]*x509.Certificate{cert}, time.Now())
+	require.NoError(t, err)
+
+	// the list is read again when it changes
+	ca.writeCRL(t, crl, time.Now(), cert)
+	_, err = v.verify([]*x509.Certificate{cert}, time.Now())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "certificate 2 was revoked")
+
+	_, err = v.verify([]*x509.Certificate{cert}, time.Now().Add(2*time.Hour))
+	require.Error(t, err)
+
+	require.NoError(t, os.Remove(crl))
+	_, err = v.verify([]*x509.Certificate{cert}, time.Now())
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "cannot read revocation list"))
+}
+
+func TestClientCertOCSP(t *testing.T) {
+	ca := newTestCA(t)
+
+	requests := 0
+	var responses map[string][]byte
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "application/ocsp-request", r.Header.Get("Content-Type"))
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var request ocspRequest
+		_, err = asn1.Unmarshal(body, &request)
+		require.NoError(t, err)
+		_, _ = w.Write(responses[request.TBSRequest.RequestList[0].Cert.SerialNumber.String()])
+	}))
+	defer responder.Close()
+
+	good := ca.issue(t, &x509.Certificate{SerialNumber: big.NewInt(2), DNSNames: []string{"good"}, OCSPServer: []string{responder.URL}})
+	bad := ca.issue(t, &x509.Certificate{SerialNumber: big.NewInt(3), DNSNames: []string{"bad"}, OCSPServer: []string{responder.URL}})
+	unknown := ca.issue(t, &x509.Certificate{SerialNumber: big.NewInt(4), DNSNames: []string{"unknown"}, OCSPServer: []string{responder.URL}})
+	responses = map[string][]byte{
+		"2": ca.ocspResponse(t, good, false),
+		"3": ca.ocspResponse(t, bad, true),
+		// signed by a CA which isn't the issuer
+		"4": newTestCA(t).ocspResponse(t, unknown, false),
+	}
+
+	v := newClientCertVerifier(&ClientCertConfig{CAs: ca.pool(), OCSP: true})
+
+	identity, err := v.verify([]*x509.Certificate{good}, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "good", identity)
+
+	// the answer is cached
+	_, err = v.verify([]*x509.Certificate{good}, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	_, err = v.verify([]*x509.Certificate{bad}, time.Now())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "certificate 3 was revoked")
+
+	_, err = v.verify([]*x509.Certificate{unknown}, time.Now())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid signature")
+}
+
+func TestVerifyClientCertificate(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	dir, err := ioutil.TempDir("", "worker-crl-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCA(t)
+	cert := ca.issue(t, &x509.Certificate{SerialNumber: big.NewInt(2), DNSNames: []string{"worker.osbuild.org"}})
+	revoked := ca.issue(t, &x509.Certificate{SerialNumber: big.NewInt(3), DNSNames: []string{"worker.osbuild.org"}})
+	crl := filepath.Join(dir, "crl.pem")
+	ca.writeCRL(t, crl, time.Now(), revoked)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := NewServer(nil, q, Config{
+		ClientCerts: &ClientCertConfig{
+			CAs:      ca.pool(),
+			CRLFiles: []string{crl},
+		},
+	})
+	handler := server.Handler()
+
+	register := func(certs ...*x509.Certificate) int {
+		request := httptest.NewRequest("POST", "/api/worker/v1/workers", strings.NewReader(`{"arch":"x86_64","job_types":["osbuild"]}`))
+		request.Header.Set("Content-Type", "application/json")
+		if certs != nil {
+			request.TLS = &tls.ConnectionState{PeerCertificates: certs}
+		}
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response.Code
+	}
+
+	require.Equal(t, http.StatusForbidden, register(revoked))
+	require.Equal(t, http.StatusCreated, register(cert))
+	// the local worker doesn't connect via TLS
+	require.Equal(t, http.StatusCreated, register())
+
+	workers := server.Workers()
+	require.Len(t, workers, 2)
+	require.Equal(t, "worker.osbuild.org", workers[0].Identity)
+	require.Empty(t, workers[1].Identity)
+}
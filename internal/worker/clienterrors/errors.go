@@ -0,0 +1,175 @@
+// Package clienterrors defines the errors composer reports to its clients
+// when a job fails. Each error has a stable code, which frontends can map to
+// guidance for their users, and a human-readable reason, which may change
+// between versions.
+package clienterrors
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
+)
+
+// Code identifies the kind of an error. Codes are part of the APIs of
+// composer and must not be changed once they have been released.
+type Code string
+
+const (
+	// The job was canceled
+	ErrorCanceled Code = "canceled"
+
+	// A job the failed job depends on failed. The details are the error
+	// of that job, if known.
+	ErrorDependencyFailed Code = "dependency-failed"
+
+	// The arguments of the job are invalid, which usually means that the
+	// versions of composer and the worker are incompatible
+	ErrorInvalidJob Code = "invalid-job"
+
+	// Packages which were requested don't exist in the repositories
+	ErrorPackagesNotFound Code = "packages-not-found"
+
+	// The requested packages conflict with each other or have
+	// dependencies which can't be satisfied
+	ErrorDepsolveFailed Code = "depsolve-failed"
+
+	// The metadata of a repository couldn't be loaded
+	ErrorRepositoryUnavailable Code = "repository-unavailable"
+
+	// A repository has no GPG key, but the signatures of its content
+	// must be verified
+	ErrorContentVerification Code = "content-verification-failed"
+
+	// The ref of the parent commit of an OSTree image couldn't be resolved
+	ErrorOSTreeRefResolution Code = "ostree-ref-resolution-failed"
+
+	// The manifest of the image couldn't be generated from the request
+	ErrorManifestGeneration Code = "manifest-generation-failed"
+
+	// A stage of osbuild failed. The details name the stage, and the end
+	// of its output is attached.
+	ErrorOSBuildFailed Code = "osbuild-failed"
+
+	// The worker failed while it was preparing or handling the build, for
+	// example when running osbuild or uploading the image to composer
+	ErrorWorker Code = "worker-error"
+
+	// Uploading the image to its target failed. The details name the
+	// target.
+	ErrorUploadFailed Code = "upload-failed"
+
+	// Initializing, importing, or tagging a Koji build failed
+	ErrorKojiInit   Code = "koji-init-failed"
+	ErrorKojiUpload Code = "koji-upload-failed"
+	ErrorKojiImport Code = "koji-import-failed"
+	ErrorKojiTag    Code = "koji-tag-failed"
+)
+
+// At most this much of the output of a failed osbuild stage is attached to
+// errors, from its end, where the error usually is
+const maxOSBuildError = 4 * 1024
+
+// Error is an error of a job as it is reported to clients.
+type Error struct {
+	Code   Code   `json:"code"`
+	Reason string `json:"reason"`
+
+	// More information about the error, depending on its code
+	Details interface{} `json:"details,omitempty"`
+
+	// The end of the output of the osbuild stage which failed
+	OSBuildError string `json:"osbuild_error,omitempty"`
+
+	// The error which caused this one on the worker, which is not
+	// serialized
+	err error
+}
+
+// New returns an error with `code`, `reason`, and `details`, which may be
+// nil.
+func New(code Code, reason string, details interface{}) *Error {
+	return &Error{
+		Code:    code,
+		Reason:  reason,
+		Details: details,
+	}
+}
+
+// Wrap returns an error with `code` whose reason is `err`. It unwraps to
+// `err`, so that the error can still be inspected on the worker.
+func Wrap(code Code, err error) *Error {
+	return &Error{
+		Code:   code,
+		Reason: err.Error(),
+		err:    err,
+	}
+}
+
+// Errorf returns an error with `code` whose reason is formatted like
+// fmt.Errorf formats errors. It unwraps to the error wrapped with %w, if
+// there is one.
+func Errorf(code Code, format string, args ...interface{}) *Error {
+	return Wrap(code, fmt.Errorf(format, args...))
+}
+
+func (e *Error) Error() string {
+	return e.Reason
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// OSBuildFailed returns the error of an osbuild run with `result`, which
+// failed. `stage` is the stage osbuild ran last, for results which don't
+// contain the stages.
+func OSBuildFailed(result *osbuild.Result, stage string) *Error {
+	var output string
+	if failed := FailedStage(result); failed != nil {
+		stage = failed.Name
+		output = failed.Output
+	}
+	if len(output) > maxOSBuildError {
+		// cut at the start of a character
+		start := len(output) - maxOSBuildError
+		for start < len(output) && !utf8.RuneStart(output[start]) {
+			start++
+		}
+		output = output[start:]
+	}
+
+	if stage == "" {
+		return New(ErrorOSBuildFailed, "Building the image failed", nil)
+	}
+	return &Error{
+		Code:         ErrorOSBuildFailed,
+		Reason:       fmt.Sprintf("Building the image failed in stage %s", stage),
+		Details:      map[string]string{"stage": stage},
+		OSBuildError: output,
+	}
+}
+
+// FailedStage returns the first stage of `result` which didn't succeed, or
+// nil if there is none.
+func FailedStage(result *osbuild.Result) *osbuild.StageResult {
+	if result == nil {
+		return nil
+	}
+
+	var stages []osbuild.StageResult
+	if result.Build != nil {
+		stages = append(stages, result.Build.Stages...)
+	}
+	stages = append(stages, result.Stages...)
+	if result.Assembler != nil {
+		stages = append(stages, *result.Assembler)
+	}
+
+	for i := range stages {
+		if !stages[i].Success {
+			return &stages[i]
+		}
+	}
+	return nil
+}
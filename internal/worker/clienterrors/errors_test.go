@@ -0,0 +1,63 @@
+package clienterrors_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+
+	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
+)
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := clienterrors.Errorf(clienterrors.ErrorRepositoryUnavailable, "loading repository: %w", cause)
+	require.Equal(t, "loading repository: connection refused", err.Error())
+	require.True(t, errors.Is(err, cause))
+
+	var clientErr *clienterrors.Error
+	require.True(t, errors.As(fmt.Errorf("depsolving: %w", err), &clientErr))
+	require.Equal(t, clienterrors.ErrorRepositoryUnavailable, clientErr.Code)
+
+	// the cause stays on the worker
+	data, err2 := json.Marshal(err)
+	require.NoError(t, err2)
+	require.JSONEq(t, `{"code":"repository-unavailable","reason":"loading repository: connection refused"}`, string(data))
+}
+
+func TestOSBuildFailed(t *testing.T) {
+	output := strings.Repeat("x", 5000) + "setfiles: invalid context"
+	result := &osbuild.Result{
+		Success: false,
+		Stages: []osbuild.StageResult{
+			{Name: "org.osbuild.kernel-cmdline", Success: true, Output: "ok"},
+			{Name: "org.osbuild.selinux", Success: false, Output: output},
+		},
+	}
+
+	err := clienterrors.OSBuildFailed(result, "org.osbuild.rpm")
+	require.Equal(t, clienterrors.ErrorOSBuildFailed, err.Code)
+	require.Equal(t, "Building the image failed in stage org.osbuild.selinux", err.Reason)
+	require.Equal(t, map[string]string{"stage": "org.osbuild.selinux"}, err.Details)
+	require.Len(t, err.OSBuildError, 4*1024)
+	require.True(t, strings.HasSuffix(err.OSBuildError, "setfiles: invalid context"))
+
+	// the output is cut between characters
+	result.Stages[1].Output = strings.Repeat("é", 3000) + "!"
+	err = clienterrors.OSBuildFailed(result, "")
+	require.True(t, utf8.ValidString(err.OSBuildError))
+	require.Equal(t, strings.Repeat("é", 2047)+"!", err.OSBuildError)
+
+	// remote workers might not report the stages
+	err = clienterrors.OSBuildFailed(&osbuild.Result{}, "org.osbuild.rpm")
+	require.Equal(t, "Building the image failed in stage org.osbuild.rpm", err.Reason)
+	require.Empty(t, err.OSBuildError)
+
+	err = clienterrors.OSBuildFailed(nil, "")
+	require.Equal(t, clienterrors.New(clienterrors.ErrorOSBuildFailed, "Building the image failed", nil), err)
+}
@@ -0,0 +1,329 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/target"
+)
+
+// TargetCredentials are the access keys of an upload target which were
+// passed in a compose request. They are only kept in memory, see
+// RedactCredentials().
+type TargetCredentials struct {
+	// for uploading to S3
+	S3 target.AWSCredentials
+	// for importing and registering an AMI, if they differ from S3
+	EC2 *target.AWSCredentials
+}
+
+// Never print the keys, the access key id is enough to identify them
+func (c TargetCredentials) String() string {
+	return fmt.Sprintf("{S3:%s EC2:%s}", redacted(&c.S3), redacted(c.EC2))
+}
+
+func (c TargetCredentials) GoString() string {
+	return c.String()
+}
+
+func redacted(creds *target.AWSCredentials) string {
+	if creds == nil {
+		return "<nil>"
+	}
+	return creds.AccessKeyID + ":<redacted>"
+}
+
+// RedactCredentials removes the access keys from the options of `t` and
+// returns them, or nil if `t` has none. Jobs keep them in their
+// `Credentials`, which are never stored in the job queue.
+func RedactCredentials(t *target.Target) *TargetCredentials {
+	var creds TargetCredentials
+	switch options := t.Options.(type) {
+	case *target.AWSTargetOptions:
+		creds.S3 = takeKeys(&options.AccessKeyID, &options.SecretAccessKey, &options.SessionToken)
+		// the role is not secret and stays with the target
+		if options.EC2 != nil && options.EC2.AccessKeyID != "" {
+			ec2 := takeKeys(&options.EC2.AccessKeyID, &options.EC2.SecretAccessKey, &options.EC2.SessionToken)
+			creds.EC2 = &ec2
+		}
+	case *target.AWSS3TargetOptions:
+		creds.S3 = takeKeys(&options.AccessKeyID, &options.SecretAccessKey, &options.SessionToken)
+	case *target.GenericS3TargetOptions:
+		creds.S3 = takeKeys(&options.AccessKeyID, &options.SecretAccessKey, &options.SessionToken)
+	default:
+		return nil
+	}
+
+	if creds.S3.AccessKeyID == "" && creds.EC2 == nil {
+		return nil
+	}
+	return &creds
+}
+
+// takeKeys returns the access keys and clears them.
+func takeKeys(accessKeyID, secretAccessKey, sessionToken *string) target.AWSCredentials {
+	keys := target.AWSCredentials{
+		AccessKeyID:     *accessKeyID,
+		SecretAccessKey: *secretAccessKey,
+		SessionToken:    *sessionToken,
+	}
+	*accessKeyID, *secretAccessKey, *sessionToken = "", "", ""
+	return keys
+}
+
+// putKeys sets the access keys to `keys`, unless they are empty.
+func putKeys(keys *target.AWSCredentials, accessKeyID, secretAccessKey, sessionToken *string) {
+	if keys.AccessKeyID == "" {
+		return
+	}
+	*accessKeyID, *secretAccessKey, *sessionToken = keys.AccessKeyID, keys.SecretAccessKey, keys.SessionToken
+}
+
+// apply fills the access keys of `c` into the options of `t`.
+func (c *TargetCredentials) apply(t *target.Target) {
+	if c == nil || t == nil {
+		return
+	}
+	switch options := t.Options.(type) {
+	case *target.AWSTargetOptions:
+		putKeys(&c.S3, &options.AccessKeyID, &options.SecretAccessKey, &options.SessionToken)
+		if c.EC2 != nil && options.EC2 != nil {
+			putKeys(c.EC2, &options.EC2.AccessKeyID, &options.EC2.SecretAccessKey, &options.EC2.SessionToken)
+		}
+	case *target.AWSS3TargetOptions:
+		putKeys(&c.S3, &options.AccessKeyID, &options.SecretAccessKey, &options.SessionToken)
+	case *target.GenericS3TargetOptions:
+		putKeys(&c.S3, &options.AccessKeyID, &options.SecretAccessKey, &options.SessionToken)
+	}
+}
+
+// secrets returns the values which must never show up in the results or
+// logs of a job.
+func (c *TargetCredentials) secrets() []string {
+	var values []string
+	for _, keys := range []*target.AWSCredentials{&c.S3, c.EC2} {
+		if keys == nil {
+			continue
+		}
+		for _, value := range []string{keys.SecretAccessKey, keys.SessionToken} {
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// jobCredentials returns the credentials of the targets of `job`, or nil if
+// it has none.
+func jobCredentials(job interface{}) []*TargetCredentials {
+	var creds []*TargetCredentials
+	switch j := job.(type) {
+	case *OSBuildJob:
+		creds = j.Credentials
+	case *UploadJob:
+		creds = []*TargetCredentials{j.Credentials}
+	}
+	for _, c := range creds {
+		if c != nil {
+			return creds
+		}
+	}
+	return nil
+}
+
+// markClientCredentials records in `job`, which has credentials, that they
+// came from the client. Unlike the credentials, that is stored in the job
+// queue, so that the job isn't retried without them.
+func markClientCredentials(job interface{}) {
+	switch j := job.(type) {
+	case *OSBuildJob:
+		j.ClientCredentials = true
+	case *UploadJob:
+		j.ClientCredentials = true
+	}
+}
+
+func (s *Server) forgetCredentials(id uuid.UUID) {
+	s.credentialsMutex.Lock()
+	defer s.credentialsMutex.Unlock()
+	delete(s.credentials, id)
+}
+
+// scrub replaces the secrets of the credentials of job `id` in `data`.
+func (s *Server) scrub(id uuid.UUID, data []byte) []byte {
+	s.credentialsMutex.Lock()
+	defer s.credentialsMutex.Unlock()
+
+	for _, creds := range s.credentials[id] {
+		if creds == nil {
+			continue
+		}
+		for _, secret := range creds.secrets() {
+			data = bytes.ReplaceAll(data, []byte(secret), []byte("<redacted>"))
+		}
+	}
+	return data
+}
+
+// SecretsProvider provides the credentials of cloud providers by name:
+// "aws", "azure", and "gcp". Secret returns nil for credentials it doesn't
+// have.
+type SecretsProvider interface {
+	Secret(name string) (map[string]string, error)
+}
+
+// withCredentials returns `args` of the job `id` of type `jobType` with the
+// credentials of its upload targets filled in: the ones passed in the compose
+// request, otherwise the ones of the secrets provider for targets without
+// credentials. The credentials are only handed to the worker, they are never
+// stored with the job. Returns ErrCredentialsLost if the compose request
+// passed credentials which composer doesn't have in memory anymore, instead
+// of using the ones of the secrets provider for the client's targets.
+func (s *Server) withCredentials(id uuid.UUID, jobType string, args json.RawMessage) (json.RawMessage, error) {
+	s.credentialsMutex.Lock()
+	creds := s.credentials[id]
+	s.credentialsMutex.Unlock()
+
+	var targets []*target.Target
+	var job interface{}
+	switch jobType {
+	case "osbuild":
+		var osbuildJob OSBuildJob
+		if err := json.Unmarshal(args, &osbuildJob); err != nil {
+			return nil, err
+		}
+		if osbuildJob.ClientCredentials && len(creds) == 0 {
+			return nil, ErrCredentialsLost
+		}
+		targets = osbuildJob.Targets
+		job = &osbuildJob
+	case "upload":
+		var uploadJob UploadJob
+		if err := json.Unmarshal(args, &uploadJob); err != nil {
+			return nil, err
+		}
+		if uploadJob.ClientCredentials && len(creds) == 0 {
+			return nil, ErrCredentialsLost
+		}
+		targets = []*target.Target{uploadJob.Target}
+		job = &uploadJob
+	default:
+		return args, nil
+	}
+
+	if len(creds) == 0 && s.config.Secrets == nil {
+		return args, nil
+	}
+
+	changed := len(creds) > 0
+	for i, t := range targets {
+		if i < len(creds) {
+			creds[i].apply(t)
+		}
+		if s.config.Secrets != nil {
+			filled, err := s.fillCredentials(t)
+			if err != nil {
+				return nil, err
+			}
+			changed = changed || filled
+		}
+	}
+	if !changed {
+		return args, nil
+	}
+	return json.Marshal(job)
+}
+
+// fillCredentials fills in the credentials of `t` if they are empty and the
+// secrets provider has them. Returns whether it did.
+func (s *Server) fillCredentials(t *target.Target) (bool, error) {
+	if t == nil {
+		return false, nil
+	}
+
+	switch options := t.Options.(type) {
+	case *target.AWSTargetOptions:
+		if options.AccessKeyID != "" {
+			return false, nil
+		}
+		creds, err := s.awsCredentials()
+		if err != nil || creds == nil {
+			return false, err
+		}
+		options.AccessKeyID = creds.AccessKeyID
+		options.SecretAccessKey = creds.SecretAccessKey
+		options.SessionToken = creds.SessionToken
+		if options.EC2 != nil && options.EC2.AccessKeyID == "" {
+			options.EC2.AccessKeyID = creds.AccessKeyID
+			options.EC2.SecretAccessKey = creds.SecretAccessKey
+			options.EC2.SessionToken = creds.SessionToken
+		}
+		return true, nil
+
+	case *target.AWSS3TargetOptions:
+		if options.AccessKeyID != "" {
+			return false, nil
+		}
+		creds, err := s.awsCredentials()
+		if err != nil || creds == nil {
+			return false, err
+		}
+		options.AccessKeyID = creds.AccessKeyID
+		options.SecretAccessKey = creds.SecretAccessKey
+		options.SessionToken = creds.SessionToken
+		return true, nil
+
+	case *target.AzureImageTargetOptions:
+		if options.ClientID != "" {
+			return false, nil
+		}
+		secret, err := s.config.Secrets.Secret("azure")
+		if err != nil || secret == nil {
+			return false, err
+		}
+		options.ClientID = secret["client_id"]
+		options.ClientSecret = secret["client_secret"]
+		return true, nil
+
+	case *target.GCPTargetOptions:
+		if len(options.Credentials) > 0 {
+			return false, nil
+		}
+		secret, err := s.config.Secrets.Secret("gcp")
+		if err != nil || secret == nil {
+			return false, err
+		}
+		// the gcp secrets engine returns the key encoded in base64,
+		// key/value secrets may contain it as is
+		if data, ok := secret["private_key_data"]; ok {
+			options.Credentials, err = base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				return false, fmt.Errorf("cannot decode gcp credentials: %v", err)
+			}
+		} else {
+			options.Credentials = []byte(secret["credentials"])
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// awsCredentials returns the credentials of the AWS secrets engine, whose
+// names key/value secrets use as well.
+func (s *Server) awsCredentials() (*target.AWSCredentials, error) {
+	secret, err := s.config.Secrets.Secret("aws")
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	return &target.AWSCredentials{
+		AccessKeyID:     secret["access_key"],
+		SecretAccessKey: secret["secret_key"],
+		SessionToken:    secret["security_token"],
+	}, nil
+}
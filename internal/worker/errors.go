@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"strings"
+
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
+)
+
+// OSBuildJobError returns why the osbuild job with `status` and `result`
+// failed, or nil if it hasn't failed. Results of older workers, which don't
+// have a JobError, are described by their other fields.
+func OSBuildJobError(status *JobStatus, result *OSBuildJobResult) *clienterrors.Error {
+	switch {
+	case status.Canceled:
+		return clienterrors.New(clienterrors.ErrorCanceled, "The compose was canceled", nil)
+	case status.Finished.IsZero() || result.Success:
+		return nil
+	case result.JobError != nil:
+		return result.JobError
+	case len(result.TargetErrors) > 0:
+		return clienterrors.New(clienterrors.ErrorUploadFailed, "Uploading the image failed: "+strings.Join(result.TargetErrors, "; "), nil)
+	case result.OSBuildOutput != nil && !result.OSBuildOutput.Success:
+		stage := ""
+		if result.Progress != nil {
+			stage = result.Progress.Stage
+		}
+		return clienterrors.OSBuildFailed(result.OSBuildOutput, stage)
+	default:
+		return clienterrors.New(clienterrors.ErrorWorker, "Building the image failed", nil)
+	}
+}
@@ -2,23 +2,67 @@ package worker
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 	"github.com/osbuild/osbuild-composer/internal/target"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
 )
 
 //
 // JSON-serializable types for the jobqueue
 //
 
+// Kinds of failures workers report in the `failure` field of the results of
+// depsolve, manifest, osbuild and upload jobs.
+const (
+	// The job may succeed when it runs again, for example because it
+	// timed out reaching a repository or upload target, or got a server
+	// error from it. Composer requeues jobs which failed transiently, if
+	// it is configured to.
+	FailureTransient = "transient"
+
+	// The job fails every time it runs, for example because a package is
+	// missing or the manifest is invalid.
+	FailurePermanent = "permanent"
+)
+
 type OSBuildJob struct {
 	Manifest        distro.Manifest  `json:"manifest"`
 	Targets         []*target.Target `json:"targets,omitempty"`
 	ImageName       string           `json:"image_name,omitempty"`
+	ImageType       string           `json:"image_type,omitempty"`
 	StreamOptimized bool             `json:"stream_optimized,omitempty"`
 	Exports         []string         `json:"export_stages,omitempty"`
+
+	// Size of the image in bytes, which workers compare with their free
+	// resources before building it. Zero if unknown.
+	ImageSize uint64 `json:"image_size,omitempty"`
+
+	// Access keys of the targets, by their index, which are kept in
+	// memory only and never stored in the job queue
+	Credentials []*TargetCredentials `json:"-"`
+
+	// Whether the job had Credentials when it was enqueued
+	ClientCredentials bool `json:"client_credentials,omitempty"`
+
+	// Priority class the job was enqueued with
+	PriorityClass string `json:"priority_class,omitempty"`
+
+	// Tenant the job was enqueued for, such as the organization which
+	// requested it via the Cloud API, if known
+	Tenant string `json:"tenant,omitempty"`
+
+	// Idempotency key of the compose request, if the job is the compose
+	Idempotency *IdempotencyKey `json:"idempotency,omitempty"`
+
+	// Whether the image may be downloaded from composer, because it was
+	// requested with a local upload request of the Cloud API
+	KeepImage bool `json:"keep_image,omitempty"`
 }
 
 type OSBuildJobResult struct {
@@ -27,6 +71,152 @@ type OSBuildJobResult struct {
 	TargetResults []*target.TargetResult `json:"target_results,omitempty"`
 	TargetErrors  []string               `json:"target_errors,omitempty"`
 	UploadStatus  string                 `json:"upload_status"`
+
+	// The stage osbuild ran last, which is the one that failed if the
+	// build failed
+	Progress *JobProgress `json:"progress,omitempty"`
+
+	// The files osbuild exported, if the build succeeded
+	ImageFiles []ImageFile `json:"image_files,omitempty"`
+
+	// How much of the build the worker had cached
+	Cache *CacheStats `json:"cache,omitempty"`
+
+	// FailureTransient or FailurePermanent, if the job failed
+	Failure string `json:"failure,omitempty"`
+
+	// Why the job failed, if it has. The string fields which describe
+	// errors are kept for clients which don't know this field.
+	JobError *clienterrors.Error `json:"job_error,omitempty"`
+}
+
+// CacheStats tells how many of the files an osbuild job downloads were
+// already in the store of its worker, and how large the store was.
+type CacheStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+	// Size of the store in bytes before the build
+	StoreSize uint64 `json:"store_size"`
+}
+
+// ImageFile is a file of a built image, with its path relative to the
+// export directory.
+type ImageFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// UploadJob uploads the image built by the osbuild job it depends on to one
+// more target, so that an image can be built once and delivered to several
+// targets. The osbuild job must upload the image as artifact `ImageName`.
+type UploadJob struct {
+	Target          *target.Target `json:"target"`
+	ImageName       string         `json:"image_name"`
+	StreamOptimized bool           `json:"stream_optimized,omitempty"`
+
+	// Access keys of the target, which are kept in memory only
+	Credentials *TargetCredentials `json:"-"`
+
+	// Whether the job had Credentials when it was enqueued
+	ClientCredentials bool `json:"client_credentials,omitempty"`
+
+	// Tenant which requested the job via the Cloud API, if known
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// UploadJobResult has the same fields as the upload part of an
+// OSBuildJobResult.
+type UploadJobResult struct {
+	Success       bool                   `json:"success"`
+	TargetResults []*target.TargetResult `json:"target_results,omitempty"`
+	TargetErrors  []string               `json:"target_errors,omitempty"`
+	UploadStatus  string                 `json:"upload_status"`
+
+	// FailureTransient or FailurePermanent, if the job failed
+	Failure string `json:"failure,omitempty"`
+
+	// Why the job failed, if it has, as in OSBuildJobResult
+	JobError *clienterrors.Error `json:"job_error,omitempty"`
+}
+
+// DepsolveJob resolves the package sets of an image on a worker, so that
+// composer doesn't need to run dnf itself.
+type DepsolveJob struct {
+	PackageSets      map[string]rpmmd.PackageSet `json:"package_sets"`
+	Repos            []rpmmd.RepoConfig          `json:"repos"`
+	ModulePlatformID string                      `json:"module_platform_id"`
+	Arch             string                      `json:"arch"`
+
+	// Verify the signatures of the metadata and packages of all
+	// repositories, which must all have a GPG key
+	StrictContentVerification bool `json:"strict_content_verification,omitempty"`
+}
+
+type DepsolveJobResult struct {
+	PackageSpecs map[string][]rpmmd.PackageSpec `json:"package_specs"`
+	Error        string                         `json:"error,omitempty"`
+
+	// FailureTransient or FailurePermanent, if the job failed
+	Failure string `json:"failure,omitempty"`
+
+	// Why the job failed, if it has, as in OSBuildJobResult
+	JobError *clienterrors.Error `json:"job_error,omitempty"`
+}
+
+// ManifestJobByID generates the manifest of an image on a worker, which
+// includes resolving its OSTree parent commit. It takes the packages of the
+// image from the depsolve job it depends on. The osbuild job depending on it
+// takes the manifest from its result.
+type ManifestJobByID struct {
+	Distribution string              `json:"distribution"`
+	Arch         string              `json:"arch"`
+	ImageType    string              `json:"image_type"`
+	Blueprint    blueprint.Blueprint `json:"blueprint"`
+	Repositories []rpmmd.RepoConfig  `json:"repositories"`
+	ImageOptions distro.ImageOptions `json:"image_options"`
+	Seed         int64               `json:"seed"`
+}
+
+type ManifestJobByIDResult struct {
+	Manifest distro.Manifest `json:"manifest,omitempty"`
+	Error    string          `json:"error,omitempty"`
+
+	// FailureTransient or FailurePermanent, if the job failed
+	Failure string `json:"failure,omitempty"`
+
+	// Why the job failed, if it has, as in OSBuildJobResult
+	JobError *clienterrors.Error `json:"job_error,omitempty"`
+}
+
+// ComposeJob groups the jobs of a compose with more than one image, or with
+// images which are uploaded to more than one target. It depends on the
+// osbuild jobs of the images, followed by their upload jobs, and finishes
+// after all of them.
+type ComposeJob struct {
+	// The upload jobs of each image, in the order of the images
+	Uploads [][]uuid.UUID `json:"uploads,omitempty"`
+
+	// Tenant which requested the job via the Cloud API, if known
+	Tenant string `json:"tenant,omitempty"`
+
+	// Idempotency key of the compose request, if it had one
+	Idempotency *IdempotencyKey `json:"idempotency,omitempty"`
+}
+
+// IdempotencyKey is the Idempotency-Key header of a compose request of the
+// Cloud API, stored with the job which is the compose, so that requests with
+// the same key find the compose after restarts and on other instances.
+type IdempotencyKey struct {
+	Key string `json:"key"`
+
+	// Hex-encoded SHA-256 digest of the body of the request
+	Digest string `json:"digest"`
+}
+
+type ComposeJobResult struct {
+	// Whether all images of the compose were built and uploaded to all of
+	// their targets
+	Success bool `json:"success"`
 }
 
 type KojiInitJob struct {
@@ -34,21 +224,31 @@ type KojiInitJob struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
 	Release string `json:"release"`
+
+	// Whether to initialize a draft build
+	Draft bool `json:"draft,omitempty"`
 }
 
 type KojiInitJobResult struct {
 	BuildID   uint64 `json:"build_id"`
 	Token     string `json:"token"`
 	KojiError string `json:"koji_error"`
+
+	// Why the job failed, if it has, as in OSBuildJobResult
+	JobError *clienterrors.Error `json:"job_error,omitempty"`
 }
 
 type OSBuildKojiJob struct {
 	Manifest      distro.Manifest `json:"manifest"`
 	ImageName     string          `json:"image_name"`
+	ImageType     string          `json:"image_type,omitempty"`
 	Exports       []string        `json:"exports"`
 	KojiServer    string          `json:"koji_server"`
 	KojiDirectory string          `json:"koji_directory"`
 	KojiFilename  string          `json:"koji_filename"`
+
+	// Size of the image in bytes, as in OSBuildJob
+	ImageSize uint64 `json:"image_size,omitempty"`
 }
 
 type OSBuildKojiJobResult struct {
@@ -58,6 +258,10 @@ type OSBuildKojiJobResult struct {
 	ImageHash     string          `json:"image_hash"`
 	ImageSize     uint64          `json:"image_size"`
 	KojiError     string          `json:"koji_error"`
+	Cache         *CacheStats     `json:"cache,omitempty"`
+
+	// Why the job failed, if it has, as in OSBuildJobResult
+	JobError *clienterrors.Error `json:"job_error,omitempty"`
 }
 
 type KojiFinalizeJob struct {
@@ -69,10 +273,27 @@ type KojiFinalizeJob struct {
 	KojiDirectory string   `json:"koji_directory"`
 	TaskID        uint64   `json:"task_id"` /* https://pagure.io/koji/issue/215 */
 	StartTime     uint64   `json:"start_time"`
+
+	// Whether the build was initialized as a draft build, and the tag to
+	// tag the build into after it has been imported, if any
+	Draft bool   `json:"draft,omitempty"`
+	Tag   string `json:"tag,omitempty"`
+
+	// Tenant which requested the job via the Cloud API, if known
+	Tenant string `json:"tenant,omitempty"`
+
+	// Idempotency key of the compose request, if it had one
+	Idempotency *IdempotencyKey `json:"idempotency,omitempty"`
 }
 
 type KojiFinalizeJobResult struct {
 	KojiError string `json:"koji_error"`
+
+	// Why tagging the imported build failed, if it has
+	TagError string `json:"tag_error,omitempty"`
+
+	// Why the job failed, if it has, as in OSBuildJobResult
+	JobError *clienterrors.Error `json:"job_error,omitempty"`
 }
 
 //
@@ -81,6 +302,8 @@ type KojiFinalizeJobResult struct {
 
 type statusResponse struct {
 	Status string `json:"status"`
+	// Architectures registered workers build images for
+	Arches []string `json:"arches"`
 }
 
 type requestJobResponse struct {
@@ -90,15 +313,113 @@ type requestJobResponse struct {
 	Type             string            `json:"type"`
 	Args             json.RawMessage   `json:"args,omitempty"`
 	DynamicArgs      []json.RawMessage `json:"dynamic_args,omitempty"`
+	DynamicArgsTypes []string          `json:"dynamic_args_types,omitempty"`
+	// Seconds after which the job times out, rounded up, or zero if it
+	// doesn't
+	Timeout int `json:"timeout,omitempty"`
 }
 
 type getJobResponse struct {
 	Canceled bool `json:"canceled"`
 }
 
+type waitForCancellationResponse struct {
+	Canceled bool `json:"canceled"`
+}
+
 type updateJobRequest struct {
-	Result json.RawMessage `json:"result"`
+	Status   string          `json:"status"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Progress *JobProgress    `json:"progress,omitempty"`
+	Reason   string          `json:"reason,omitempty"`
 }
 
 type updateJobResponse struct {
 }
+
+type heartbeatResponse struct {
+}
+
+// JobProgress is the progress of a running osbuild job, which its worker
+// reports whenever osbuild starts a stage. `Current` is the position of the
+// stage among all `Total` stages of the manifest, starting at 1, and
+// `Percent` the share of the stages which have finished.
+type JobProgress struct {
+	Pipeline string `json:"pipeline,omitempty"`
+	Stage    string `json:"stage"`
+	Current  int    `json:"current"`
+	Total    int    `json:"total"`
+	Percent  int    `json:"percent"`
+}
+
+type deadLetterJob struct {
+	Id   uuid.UUID `json:"id"`
+	Type string    `json:"type"`
+}
+
+type deadLetterJobsResponse struct {
+	Jobs []deadLetterJob `json:"jobs"`
+}
+
+type requeueDeadLetterJobResponse struct {
+}
+
+type registerWorkerResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type workerResponse struct {
+	Id             uuid.UUID `json:"id"`
+	Hostname       string    `json:"hostname,omitempty"`
+	Arch           string    `json:"arch"`
+	JobTypes       []string  `json:"job_types"`
+	FreeDisk       uint64    `json:"free_disk,omitempty"`
+	OSBuildVersion string    `json:"osbuild_version,omitempty"`
+	Identity       string    `json:"identity,omitempty"`
+	RegisteredAt   time.Time `json:"registered_at"`
+	LastSeen       time.Time `json:"last_seen"`
+	Draining       bool      `json:"draining,omitempty"`
+}
+
+type getWorkersResponse struct {
+	Workers []workerResponse `json:"workers"`
+}
+
+type queuedJob struct {
+	Id        uuid.UUID  `json:"id"`
+	Type      string     `json:"type"`
+	Tenant    string     `json:"tenant,omitempty"`
+	Status    string     `json:"status"`
+	QueuedAt  time.Time  `json:"queued_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Age       float64    `json:"age"`
+}
+
+type queuedJobsResponse struct {
+	Jobs []queuedJob `json:"jobs"`
+}
+
+type jobTypeStats struct {
+	Type    string `json:"type"`
+	Pending int    `json:"pending"`
+	Running int    `json:"running"`
+}
+
+type queueStatsResponse struct {
+	Pending          int            `json:"pending"`
+	Running          int            `json:"running"`
+	DeadLettered     int            `json:"dead_lettered"`
+	OldestPendingAge *float64       `json:"oldest_pending_age,omitempty"`
+	JobTypes         []jobTypeStats `json:"job_types"`
+	Workers          int            `json:"workers"`
+	DrainingWorkers  int            `json:"draining_workers"`
+}
+
+type requeueJobResponse struct {
+}
+
+type cancelJobResponse struct {
+}
+
+type drainWorkerResponse struct {
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/logging"
+)
+
+// jobLogger returns a logger whose entries carry the id, type, and tenant of
+// the job `id` with arguments `args`.
+func jobLogger(id uuid.UUID, jobType string, args interface{}) *logging.Logger {
+	raw, ok := args.(json.RawMessage)
+	if !ok {
+		raw, _ = json.Marshal(args)
+	}
+
+	return logging.Default().With(
+		logging.JobID, id,
+		logging.JobType, baseJobType(jobType),
+		logging.Tenant, argsTenant(raw),
+	)
+}
+
+// argsTenant returns the tenant in the arguments of a job, if any.
+func argsTenant(args json.RawMessage) string {
+	var fields struct {
+		Tenant string `json:"tenant"`
+	}
+	_ = json.Unmarshal(args, &fields)
+	return fields.Tenant
+}
+
+// jobLoggerByID is like jobLogger, for jobs whose type and arguments aren't
+// at hand.
+func (s *Server) jobLoggerByID(id uuid.UUID) *logging.Logger {
+	jobType, args, _, err := s.jobs.Job(id)
+	if err != nil {
+		return logging.Default().With(logging.JobID, id)
+	}
+	return jobLogger(id, jobType, args)
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics exposed on the worker API's /metrics endpoint.
+type metrics struct {
+	registry *prometheus.Registry
+
+	jobsEnqueued        *prometheus.CounterVec
+	jobWaitDuration     *prometheus.HistogramVec
+	buildDuration       *prometheus.HistogramVec
+	artifactUploadBytes prometheus.Counter
+	authFailures        prometheus.Counter
+	rateLimited         *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	// builds take anywhere between a few minutes and a few hours, and can
+	// wait in the queue for just as long
+	durationBuckets := prometheus.ExponentialBuckets(1, 2, 16)
+
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		jobsEnqueued: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "composer_jobs_enqueued_total",
+			Help: "Number of jobs that have been added to the job queue.",
+		}, []string{"type"}),
+		jobWaitDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "composer_job_wait_duration_seconds",
+			Help:    "Time jobs spent in the queue before being picked up by a worker.",
+			Buckets: durationBuckets,
+		}, []string{"type"}),
+		buildDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "composer_build_duration_seconds",
+			Help:    "Time workers spent building images.",
+			Buckets: durationBuckets,
+		}, []string{"image_type"}),
+		artifactUploadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "composer_artifact_upload_bytes_total",
+			Help: "Number of bytes of artifacts uploaded by workers.",
+		}),
+		authFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "composer_auth_failures_total",
+			Help: "Number of requests rejected because of a missing or invalid identity.",
+		}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "composer_rate_limited_requests_total",
+			Help: "Number of requests rejected because their client exceeded the rate limit.",
+		}, []string{"api"}),
+	}
+
+	m.registry.MustRegister(
+		m.jobsEnqueued,
+		m.jobWaitDuration,
+		m.buildDuration,
+		m.artifactUploadBytes,
+		m.authFailures,
+		m.rateLimited,
+	)
+
+	return m
+}
@@ -0,0 +1,289 @@
+package worker
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// How long the status of a certificate is cached when the OCSP response
+// doesn't say when the next update is available.
+const ocspCacheTime = 5 * time.Minute
+
+// Responses larger than this are rejected.
+const ocspMaxResponseSize = 1024 * 1024
+
+var (
+	oidSHA1           = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidOCSPBasic      = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1}
+	oidSignatureAlgos = map[string]x509.SignatureAlgorithm{
+		"1.2.840.113549.1.1.5":  x509.SHA1WithRSA,
+		"1.2.840.113549.1.1.11": x509.SHA256WithRSA,
+		"1.2.840.113549.1.1.12": x509.SHA384WithRSA,
+		"1.2.840.113549.1.1.13": x509.SHA512WithRSA,
+		"1.2.840.10045.4.1":     x509.ECDSAWithSHA1,
+		"1.2.840.10045.4.3.2":   x509.ECDSAWithSHA256,
+		"1.2.840.10045.4.3.3":   x509.ECDSAWithSHA384,
+		"1.2.840.10045.4.3.4":   x509.ECDSAWithSHA512,
+		"1.3.101.112":           x509.PureEd25519,
+	}
+)
+
+// ASN.1 structures of OCSP requests and responses, see RFC 6960
+
+type ocspCertID struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	NameHash      []byte
+	IssuerKeyHash []byte
+	SerialNumber  *big.Int
+}
+
+type ocspRequest struct {
+	TBSRequest struct {
+		Version     int `asn1:"explicit,tag:0,default:0,optional"`
+		RequestList []struct {
+			Cert ocspCertID
+		}
+	}
+}
+
+type ocspResponse struct {
+	Status   asn1.Enumerated
+	Response struct {
+		ResponseType asn1.ObjectIdentifier
+		Response     []byte
+	} `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspBasicResponse struct {
+	TBSResponseData    ocspResponseData
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+	Certificates       []asn1.RawValue `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseData struct {
+	Raw         asn1.RawContent
+	Version     int `asn1:"optional,default:0,explicit,tag:0"`
+	ResponderID asn1.RawValue
+	ProducedAt  time.Time `asn1:"generalized"`
+	Responses   []ocspSingleResponse
+}
+
+type ocspSingleResponse struct {
+	CertID     ocspCertID
+	Good       asn1.Flag        `asn1:"tag:0,optional"`
+	Revoked    ocspRevokedInfo  `asn1:"tag:1,optional"`
+	Unknown    asn1.Flag        `asn1:"tag:2,optional"`
+	ThisUpdate time.Time        `asn1:"generalized"`
+	NextUpdate time.Time        `asn1:"generalized,explicit,tag:0,optional"`
+	Extensions []pkix.Extension `asn1:"explicit,tag:1,optional"`
+}
+
+type ocspRevokedInfo struct {
+	RevocationTime time.Time       `asn1:"generalized"`
+	Reason         asn1.Enumerated `asn1:"explicit,tag:0,optional"`
+}
+
+// ocspChecker asks OCSP responders whether certificates were revoked and
+// caches their answers.
+type ocspChecker struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]*ocspStatus
+}
+
+type ocspStatus struct {
+	err     error
+	expires time.Time
+}
+
+func newOCSPChecker() *ocspChecker {
+	return &ocspChecker{
+		client: &http.Client{Timeout: 10 * time.Second},
+		cache:  make(map[string]*ocspStatus),
+	}
+}
+
+// check returns an error if the responder of `cert` says that it was revoked
+// or doesn't know it, or if the responder can't be asked. Certificates which
+// don't name a responder are not checked.
+func (o *ocspChecker) check(cert, issuer *x509.Certificate, now time.Time) error {
+	if len(cert.OCSPServer) == 0 {
+		return nil
+	}
+
+	id, err := newOCSPCertID(cert, issuer)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%x:%s", id.IssuerKeyHash, id.SerialNumber)
+
+	o.mu.Lock()
+	status, ok := o.cache[key]
+	o.mu.Unlock()
+	if ok && now.Before(status.expires) {
+		return status.err
+	}
+
+	status, err = o.query(cert.OCSPServer[0], id, issuer, now)
+	if err != nil {
+		return fmt.Errorf("cannot check revocation status of certificate %s: %v", cert.SerialNumber, err)
+	}
+
+	o.mu.Lock()
+	o.cache[key] = status
+	o.mu.Unlock()
+
+	return status.err
+}
+
+func (o *ocspChecker) query(url string, id *ocspCertID, issuer *x509.Certificate, now time.Time) (*ocspStatus, error) {
+	var request ocspRequest
+	request.TBSRequest.RequestList = []struct{ Cert ocspCertID }{{*id}}
+	body, err := asn1.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.client.Post(url, "application/ocsp-request", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("responder returned %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, ocspMaxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	single, err := parseOCSPResponse(data, id, issuer)
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(single.ThisUpdate) {
+		return nil, errors.New("response is not valid yet")
+	}
+	if !single.NextUpdate.IsZero() && now.After(single.NextUpdate) {
+		return nil, errors.New("response has expired")
+	}
+
+	status := &ocspStatus{expires: single.NextUpdate}
+	if status.expires.IsZero() || status.expires.After(now.Add(ocspCacheTime)) {
+		status.expires = now.Add(ocspCacheTime)
+	}
+	switch {
+	case bool(single.Good):
+	case bool(single.Unknown):
+		status.err = fmt.Errorf("certificate %s is unknown to its OCSP responder", id.SerialNumber)
+	default:
+		status.err = fmt.Errorf("certificate %s was revoked at %s", id.SerialNumber, single.Revoked.RevocationTime)
+	}
+	return status, nil
+}
+
+func newOCSPCertID(cert, issuer *x509.Certificate) (*ocspCertID, error) {
+	var publicKeyInfo struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(issuer.RawSubjectPublicKeyInfo, &publicKeyInfo); err != nil {
+		return nil, err
+	}
+
+	// responders are required to support SHA-1
+	nameHash := sha1.Sum(issuer.RawSubject)
+	keyHash := sha1.Sum(publicKeyInfo.PublicKey.RightAlign())
+
+	return &ocspCertID{
+		HashAlgorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidSHA1,
+			Parameters: asn1.RawValue{Tag: asn1.TagNull},
+		},
+		NameHash:      nameHash[:],
+		IssuerKeyHash: keyHash[:],
+		SerialNumber:  cert.SerialNumber,
+	}, nil
+}
+
+// parseOCSPResponse verifies that `data` is a response signed by `issuer` or
+// by a responder it delegated to, and returns the status of the certificate
+// `id`.
+func parseOCSPResponse(data []byte, id *ocspCertID, issuer *x509.Certificate) (*ocspSingleResponse, error) {
+	var resp ocspResponse
+	if rest, err := asn1.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("cannot parse response: %v", err)
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after response")
+	}
+	if resp.Status != 0 {
+		return nil, fmt.Errorf("responder returned status %d", resp.Status)
+	}
+	if !resp.Response.ResponseType.Equal(oidOCSPBasic) {
+		return nil, fmt.Errorf("unsupported response type %s", resp.Response.ResponseType)
+	}
+
+	var basic ocspBasicResponse
+	if _, err := asn1.Unmarshal(resp.Response.Response, &basic); err != nil {
+		return nil, fmt.Errorf("cannot parse response: %v", err)
+	}
+
+	signer := issuer
+	if len(basic.Certificates) > 0 {
+		delegate, err := x509.ParseCertificate(basic.Certificates[0].FullBytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse certificate of responder: %v", err)
+		}
+		if !delegate.Equal(issuer) {
+			if err := delegate.CheckSignatureFrom(issuer); err != nil {
+				return nil, fmt.Errorf("responder is not authorized by the issuer: %v", err)
+			}
+			if !hasExtKeyUsage(delegate, x509.ExtKeyUsageOCSPSigning) {
+				return nil, errors.New("responder is not authorized to sign OCSP responses")
+			}
+		}
+		signer = delegate
+	}
+
+	algorithm, ok := oidSignatureAlgos[basic.SignatureAlgorithm.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signature algorithm %s", basic.SignatureAlgorithm.Algorithm)
+	}
+	if err := signer.CheckSignature(algorithm, basic.TBSResponseData.Raw, basic.Signature.RightAlign()); err != nil {
+		return nil, fmt.Errorf("invalid signature: %v", err)
+	}
+
+	for i, single := range basic.TBSResponseData.Responses {
+		if single.CertID.SerialNumber.Cmp(id.SerialNumber) != 0 {
+			continue
+		}
+		if !single.CertID.HashAlgorithm.Algorithm.Equal(oidSHA1) ||
+			!bytes.Equal(single.CertID.NameHash, id.NameHash) ||
+			!bytes.Equal(single.CertID.IssuerKeyHash, id.IssuerKeyHash) {
+			continue
+		}
+		return &basic.TBSResponseData.Responses[i], nil
+	}
+	return nil, errors.New("response doesn't contain the certificate")
+}
+
+func hasExtKeyUsage(cert *x509.Certificate, usage x509.ExtKeyUsage) bool {
+	for _, u := range cert.ExtKeyUsage {
+		if u == usage {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+)
+
+// TenantQuota limits the number of jobs a single tenant can have in the job
+// queue. Zero values mean that there's no limit.
+type TenantQuota struct {
+	// Maximum number of jobs of a tenant which are queued or running at
+	// the same time.
+	MaxConcurrent int
+
+	// Maximum number of jobs of a tenant which are waiting for a worker.
+	MaxQueued int
+}
+
+var ErrQuotaExceeded = errors.New("tenant exceeded its job quota")
+
+// Enforces the quotas of tenants on their osbuild jobs.
+//
+// The jobs of a tenant are counted in the job queue, so that jobs enqueued
+// before composer was restarted, or by other composer instances sharing the
+// same job queue, count as well. Enqueueing is serialized per tenant, but
+// instances sharing the queue might still both enqueue the last job a
+// tenant's quota allows.
+type tenantQuotas struct {
+	quota TenantQuota
+	jobs  jobqueue.JobQueue
+
+	// Protects `tenants` only, it is not held while accessing the queue
+	mu      sync.Mutex
+	tenants map[string]*tenantLock
+}
+
+// Serializes enqueueing the jobs of a tenant. It is removed from
+// tenantQuotas.tenants when nobody uses it anymore.
+type tenantLock struct {
+	sync.Mutex
+	users int
+}
+
+func newTenantQuotas(quota TenantQuota, jobs jobqueue.JobQueue) *tenantQuotas {
+	return &tenantQuotas{
+		quota:   quota,
+		jobs:    jobs,
+		tenants: make(map[string]*tenantLock),
+	}
+}
+
+// Calls `enqueue` if `tenant` has not exhausted its quota yet. Jobs without a
+// tenant are not subject to quotas.
+func (q *tenantQuotas) enqueue(tenant string, enqueue func() (uuid.UUID, error)) (uuid.UUID, error) {
+	if tenant == "" || (q.quota.MaxQueued <= 0 && q.quota.MaxConcurrent <= 0) {
+		return enqueue()
+	}
+
+	l := q.lock(tenant)
+	defer q.unlock(tenant, l)
+
+	queued, err := q.count(tenant, jobqueue.JobPending)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if q.quota.MaxQueued > 0 && queued >= q.quota.MaxQueued {
+		return uuid.Nil, ErrQuotaExceeded
+	}
+
+	if q.quota.MaxConcurrent > 0 {
+		running, err := q.count(tenant, jobqueue.JobRunning)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if queued+running >= q.quota.MaxConcurrent {
+			return uuid.Nil, ErrQuotaExceeded
+		}
+	}
+
+	return enqueue()
+}
+
+// Returns the number of osbuild jobs of `tenant` in `state`. Jobs waiting for
+// their dependencies, or in the dead-letter queue, are pending as well.
+func (q *tenantQuotas) count(tenant string, state jobqueue.JobState) (int, error) {
+	ids, _, err := q.jobs.ListJobs(jobqueue.JobFilter{
+		Tenant: &tenant,
+		States: []jobqueue.JobState{state},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error listing the jobs of tenant %s: %v", tenant, err)
+	}
+
+	n := 0
+	for _, id := range ids {
+		jobType, _, _, err := q.jobs.Job(id)
+		if err != nil {
+			return 0, fmt.Errorf("error reading job %s: %v", id, err)
+		}
+		if strings.HasPrefix(jobType, "osbuild:") {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (q *tenantQuotas) lock(tenant string) *tenantLock {
+	q.mu.Lock()
+	l, ok := q.tenants[tenant]
+	if !ok {
+		l = &tenantLock{}
+		q.tenants[tenant] = l
+	}
+	l.users++
+	q.mu.Unlock()
+
+	l.Lock()
+	return l
+}
+
+func (q *tenantQuotas) unlock(tenant string, l *tenantLock) {
+	l.Unlock()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	l.users--
+	if l.users == 0 {
+		delete(q.tenants, tenant)
+	}
+}
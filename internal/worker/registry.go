@@ -0,0 +1,204 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkerInfo describes a registered worker and the capabilities it
+// advertised.
+type WorkerInfo struct {
+	Id             uuid.UUID
+	Hostname       string
+	Arch           string
+	JobTypes       []string
+	OSBuildVersion string
+
+	// Free space in bytes in the directory the worker builds images in,
+	// at the time it registered.
+	FreeDisk uint64
+
+	// Identity from the client certificate the worker registered with,
+	// if client certificates are verified.
+	Identity string
+
+	RegisteredAt time.Time
+	LastSeen     time.Time
+
+	// Draining workers are not handed new jobs, so that they can be
+	// stopped once they have finished the ones they are running.
+	Draining bool
+}
+
+var ErrWorkerNotExist = errors.New("worker is not registered")
+var ErrIncompatibleWorker = errors.New("worker does not support any of the requested job types")
+var ErrNoWorkerForArch = errors.New("no registered worker can build images for this architecture")
+
+// Keeps track of registered workers.
+//
+// This is only kept in memory. Workers need to register again when composer
+// was restarted.
+type workerRegistry struct {
+	mu      sync.Mutex
+	workers map[uuid.UUID]*WorkerInfo
+
+	// Closed when draining the worker stops
+	undrained map[uuid.UUID]chan struct{}
+}
+
+func newWorkerRegistry() *workerRegistry {
+	return &workerRegistry{
+		workers:   make(map[uuid.UUID]*WorkerInfo),
+		undrained: make(map[uuid.UUID]chan struct{}),
+	}
+}
+
+// Registers a new worker with the capabilities in `info` and returns its id.
+func (r *workerRegistry) register(info WorkerInfo) uuid.UUID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	info.Id = uuid.New()
+	info.JobTypes = append([]string(nil), info.JobTypes...)
+	info.RegisteredAt = now
+	info.LastSeen = now
+	r.workers[info.Id] = &info
+
+	return info.Id
+}
+
+// Returns all registered workers, in the order they registered.
+func (r *workerRegistry) list() []WorkerInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workers := make([]WorkerInfo, 0, len(r.workers))
+	for _, w := range r.workers {
+		workers = append(workers, *w)
+	}
+
+	sort.Slice(workers, func(a, b int) bool {
+		return workers[a].RegisteredAt.Before(workers[b].RegisteredAt)
+	})
+
+	return workers
+}
+
+// Returns the architectures of the registered workers which support any of
+// `jobTypes`, sorted and without duplicates.
+func (r *workerRegistry) arches(jobTypes []string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	arches := []string{}
+	for _, w := range r.workers {
+		if seen[w.Arch] {
+			continue
+		}
+		for _, t := range jobTypes {
+			if jobTypeMatches(t, w.JobTypes) {
+				seen[w.Arch] = true
+				arches = append(arches, w.Arch)
+				break
+			}
+		}
+	}
+	sort.Strings(arches)
+
+	return arches
+}
+
+// Returns whether no worker has registered (yet).
+func (r *workerRegistry) empty() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.workers) == 0
+}
+
+// Marks the worker with `id` as seen and returns the job types out of
+// `jobTypes` it supports. Returns ErrIncompatibleWorker if the worker
+// registered for a different architecture or doesn't support any of them.
+func (r *workerRegistry) compatibleJobTypes(id uuid.UUID, arch string, jobTypes []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.workers[id]
+	if !ok {
+		return nil, ErrWorkerNotExist
+	}
+
+	w.LastSeen = time.Now()
+
+	if arch != w.Arch {
+		return nil, ErrIncompatibleWorker
+	}
+
+	var compatible []string
+	for _, t := range jobTypes {
+		if jobTypeMatches(t, w.JobTypes) {
+			compatible = append(compatible, t)
+		}
+	}
+	if len(compatible) == 0 {
+		return nil, ErrIncompatibleWorker
+	}
+
+	return compatible, nil
+}
+
+// Starts or stops draining the worker with `id`.
+func (r *workerRegistry) setDraining(id uuid.UUID, draining bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.workers[id]
+	if !ok {
+		return ErrWorkerNotExist
+	}
+	w.Draining = draining
+
+	undrained, ok := r.undrained[id]
+	if draining && !ok {
+		r.undrained[id] = make(chan struct{})
+	} else if !draining && ok {
+		close(undrained)
+		delete(r.undrained, id)
+	}
+
+	return nil
+}
+
+// Blocks while the worker with `id` is draining, until `ctx` is done.
+func (r *workerRegistry) waitWhileDraining(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	undrained, ok := r.undrained[id]
+	r.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	select {
+	case <-undrained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func jobTypeMatches(jobType string, jobTypes []string) bool {
+	for _, t := range jobTypes {
+		if t == jobType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/logging"
+	osbuild "github.com/osbuild/osbuild-composer/internal/osbuild1"
+	"github.com/osbuild/osbuild-composer/internal/worker/clienterrors"
+)
+
+// At most this much of the output of a failed osbuild stage is attached to
+// reports, from its end, where the error usually is
+const maxReportedOutput = 8 * 1024
+
+// The fields which tell whether a job failed, shared by the results of most
+// job types
+type failureResult struct {
+	Success       *bool               `json:"success"`
+	OSBuildOutput *osbuild.Result     `json:"osbuild_output"`
+	TargetErrors  []string            `json:"target_errors"`
+	Progress      *JobProgress        `json:"progress"`
+	Error         string              `json:"error"`
+	KojiError     string              `json:"koji_error"`
+	JobError      *clienterrors.Error `json:"job_error"`
+}
+
+// reportFailure reports the job `id` to the error reporter, if `result` says
+// that it failed. Failures of osbuild carry the name and output of the stage
+// which failed.
+func (s *Server) reportFailure(id uuid.UUID, result json.RawMessage) {
+	if s.config.ErrorReporter == nil {
+		return
+	}
+
+	jobType, args, _, err := s.jobs.Job(id)
+	if err != nil {
+		return
+	}
+	// compose jobs fail when their osbuild or upload jobs fail, which are
+	// reported themselves
+	if baseJobType(jobType) == "compose" {
+		return
+	}
+
+	var r failureResult
+	if err := json.Unmarshal(result, &r); err != nil {
+		return
+	}
+
+	var message string
+	extra := map[string]interface{}{}
+	switch {
+	case r.Error != "":
+		message = r.Error
+	case r.KojiError != "":
+		message = r.KojiError
+	case r.OSBuildOutput != nil && !r.OSBuildOutput.Success:
+		message = "osbuild failed"
+		if stage := clienterrors.FailedStage(r.OSBuildOutput); stage != nil {
+			message = fmt.Sprintf("osbuild stage %s failed", stage.Name)
+			extra["osbuild.stage"] = stage.Name
+			extra["osbuild.output"] = tail(stage.Output, maxReportedOutput)
+		} else if r.Progress != nil {
+			// remote workers might not report the stages
+			message = fmt.Sprintf("osbuild stage %s failed", r.Progress.Stage)
+			extra["osbuild.stage"] = r.Progress.Stage
+		}
+	case len(r.TargetErrors) > 0:
+		message = strings.Join(r.TargetErrors, "; ")
+		extra["target_errors"] = r.TargetErrors
+	case r.Success != nil && !*r.Success:
+		message = "job failed"
+	default:
+		return
+	}
+
+	if r.JobError != nil {
+		extra["error.code"] = r.JobError.Code
+	}
+
+	tags := map[string]string{
+		logging.JobID:   id.String(),
+		logging.JobType: baseJobType(jobType),
+	}
+	if tenant := argsTenant(args); tenant != "" {
+		tags[logging.Tenant] = tenant
+	}
+
+	s.config.ErrorReporter.CaptureError(jobError(message), tags, extra)
+}
+
+// jobError is the type under which job failures are grouped in reports
+type jobError string
+
+func (e jobError) Error() string {
+	return string(e)
+}
+
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
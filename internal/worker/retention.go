@@ -0,0 +1,162 @@
+package worker
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/logging"
+)
+
+// How often the artifacts directory is cleaned up
+const artifactCleanupInterval = 10 * time.Minute
+
+// Regularly delete the artifacts which are older than `ArtifactMaxAge` or
+// don't fit into `ArtifactMaxSize`, until the server is closed.
+func (s *Server) watchArtifacts() {
+	ticker := time.NewTicker(artifactCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		s.cleanupArtifacts()
+
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// artifactsEntry is the directory with the artifacts of a finished job.
+type artifactsEntry struct {
+	jobId    uuid.UUID
+	dir      string
+	size     int64
+	finished time.Time
+}
+
+// cleanupArtifacts deletes the artifacts of finished jobs which are older than
+// `ArtifactMaxAge`, and then the oldest ones until all of them are no larger
+// than `ArtifactMaxSize`. Leftovers of jobs which are not running anymore,
+// for example because composer was restarted, are deleted as well.
+func (s *Server) cleanupArtifacts() {
+	logger := logging.Default()
+
+	entries, err := readArtifactsEntries(s.config.ArtifactsDir)
+	if err != nil {
+		logger.Errorf("Error reading the artifacts directory: %v", err)
+		return
+	}
+
+	// the limits apply to the artifacts of all tenants together
+	tenants, _ := ioutil.ReadDir(path.Join(s.config.ArtifactsDir, tenantsArtifactsDir))
+	for _, tenant := range tenants {
+		if !tenant.IsDir() {
+			continue
+		}
+		tenantEntries, err := readArtifactsEntries(path.Join(s.config.ArtifactsDir, tenantsArtifactsDir, tenant.Name()))
+		if err != nil {
+			logger.Errorf("Error reading the artifacts directory of a tenant: %v", err)
+			continue
+		}
+		entries = append(entries, tenantEntries...)
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.size
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].finished.Before(entries[j].finished)
+	})
+
+	var deleted int
+	for _, entry := range entries {
+		tooOld := s.config.ArtifactMaxAge > 0 && time.Since(entry.finished) > s.config.ArtifactMaxAge
+		tooLarge := s.config.ArtifactMaxSize > 0 && total > s.config.ArtifactMaxSize
+		if !tooOld && !tooLarge {
+			// the remaining entries are newer
+			break
+		}
+		err := os.RemoveAll(entry.dir)
+		if err != nil {
+			s.jobLoggerByID(entry.jobId).Errorf("Error deleting artifacts: %v", err)
+			continue
+		}
+		deleted++
+		total -= entry.size
+	}
+	if deleted > 0 {
+		logger.Infof("Deleted the artifacts of %d jobs, %d bytes left", deleted, total)
+	}
+
+	s.cleanupTemporaryArtifacts()
+}
+
+// readArtifactsEntries returns the directories with the artifacts of
+// finished jobs in `dir`.
+func readArtifactsEntries(dir string) ([]artifactsEntry, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []artifactsEntry
+	for _, info := range infos {
+		jobId, err := uuid.Parse(info.Name())
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		jobDir := path.Join(dir, info.Name())
+		entries = append(entries, artifactsEntry{jobId, jobDir, directorySize(jobDir), info.ModTime()})
+	}
+	return entries, nil
+}
+
+// cleanupTemporaryArtifacts deletes the artifacts uploaded for tokens which
+// are not running anymore.
+func (s *Server) cleanupTemporaryArtifacts() {
+	infos, err := ioutil.ReadDir(path.Join(s.config.ArtifactsDir, "tmp"))
+	if err != nil {
+		return
+	}
+
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+	for _, info := range infos {
+		token, err := uuid.Parse(info.Name())
+		if err != nil {
+			continue
+		}
+		if _, ok := s.running[token]; ok {
+			continue
+		}
+		// the job might just have been handed to a worker, which
+		// creates the directory before the token is running
+		if time.Since(info.ModTime()) < artifactCleanupInterval {
+			continue
+		}
+		err = os.RemoveAll(path.Join(s.config.ArtifactsDir, "tmp", info.Name()))
+		if err != nil {
+			logging.Default().Errorf("Error deleting leftover artifacts: %v", err)
+		}
+	}
+}
+
+// directorySize returns the size of the files under `dir`.
+func directorySize(dir string) int64 {
+	var size int64
+	_ = filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
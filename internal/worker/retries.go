@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+)
+
+// The backoff before the first retry of a job which failed transiently, if
+// TransientRetryBackoff is not set, and the longest backoff between retries
+const (
+	defaultTransientRetryBackoff = time.Minute
+	maxTransientRetryBackoff     = time.Hour
+)
+
+// retryTransientFailure puts the job `id`, which its worker finished with
+// `result` while it was running with `token`, back into the queue if the
+// result says that it failed transiently and it hasn't been retried
+// `TransientRetries` times yet. The job isn't handed out again before its
+// backoff has passed. Both the retry count and the time are kept in the job
+// queue, so that they survive restarts. The credentials of its targets are
+// kept for the next run. Returns whether the job is retried, otherwise the
+// result is the job's final result. Must be called with `runningMutex` held.
+func (s *Server) retryTransientFailure(token, id uuid.UUID, result json.RawMessage) bool {
+	if s.config.TransientRetries <= 0 {
+		return false
+	}
+
+	var failure struct {
+		Failure string `json:"failure"`
+	}
+	if json.Unmarshal(result, &failure) != nil || failure.Failure != FailureTransient {
+		return false
+	}
+
+	logger := s.jobLoggerByID(id)
+	retries, err := s.jobs.JobRetries(id)
+	if err != nil {
+		logger.Errorf("Error reading retries of job which failed transiently: %v", err)
+		return false
+	}
+	if retries >= s.config.TransientRetries {
+		return false
+	}
+
+	backoff := s.config.TransientRetryBackoff
+	if backoff <= 0 {
+		backoff = defaultTransientRetryBackoff
+	}
+	for i := 0; i < retries && backoff < maxTransientRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxTransientRetryBackoff {
+		backoff = maxTransientRetryBackoff
+	}
+
+	// retries are limited by TransientRetries, not by MaxJobFailures
+	err = s.jobs.RetryJob(id, time.Now().Add(backoff))
+	if err != nil && err != jobqueue.ErrCanceled {
+		logger.Errorf("Error requeueing job which failed transiently: %v", err)
+		return false
+	}
+
+	if s.config.ArtifactsDir != "" {
+		err := os.RemoveAll(path.Join(s.config.ArtifactsDir, "tmp", token.String()))
+		if err != nil {
+			logger.Errorf("Error removing artifacts of failed job: %v", err)
+		}
+	}
+
+	if err == jobqueue.ErrCanceled {
+		// the job was canceled while it was running and stays canceled
+		return true
+	}
+
+	logger.Warnf("Job failed transiently, requeued it to run in %s (retry %d of %d)", backoff, retries+1, s.config.TransientRetries)
+	return true
+}
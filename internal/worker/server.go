@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -9,25 +10,37 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/osbuild/osbuild-composer/internal/audit"
 	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+	"github.com/osbuild/osbuild-composer/internal/logging"
+	"github.com/osbuild/osbuild-composer/internal/oidc"
+	"github.com/osbuild/osbuild-composer/internal/ratelimit"
+	"github.com/osbuild/osbuild-composer/internal/rbac"
+	"github.com/osbuild/osbuild-composer/internal/sentry"
+	"github.com/osbuild/osbuild-composer/internal/tracing"
 	"github.com/osbuild/osbuild-composer/internal/worker/api"
 )
 
 type Server struct {
-	jobs           jobqueue.JobQueue
-	logger         *log.Logger
-	artifactsDir   string
-	identityFilter []string
+	jobs   jobqueue.JobQueue
+	logger *log.Logger
+	config Config
 
 	// Currently running jobs. Workers are not handed job ids, but
 	// independent tokens which serve as an indirection. This enables
@@ -40,6 +53,54 @@ type Server struct {
 	// reported as done.
 	running      map[uuid.UUID]uuid.UUID
 	runningMutex sync.Mutex
+
+	// Maps tokens of running jobs to the time the worker last signaled
+	// that it is still alive. Protected by `runningMutex`.
+	heartbeats map[uuid.UUID]time.Time
+
+	// Maps tokens of running jobs to the time at which they time out, for
+	// job types which have a timeout. Protected by `runningMutex`.
+	deadlines map[uuid.UUID]time.Time
+
+	// Maps tokens of running jobs to channels which are closed when the
+	// job is canceled or stops running. Protected by `runningMutex`.
+	cancellations map[uuid.UUID]chan struct{}
+
+	// Maps tokens of running jobs to the progress their worker reported
+	// last. Protected by `runningMutex`.
+	progress map[uuid.UUID]JobProgress
+
+	// Maps tokens of running jobs to the part of their log which their
+	// worker has sent so far. Protected by `runningMutex`.
+	logs map[uuid.UUID][]byte
+
+	// Maps tokens of running jobs to the digests of the artifacts which
+	// their worker is uploading in chunks, by name. Protected by
+	// `runningMutex`.
+	digests map[uuid.UUID]map[string]*artifactDigest
+
+	// Tokens of jobs an administrator requeued or which timed out while
+	// they were running. Their workers are told that the jobs were
+	// canceled. Protected by `runningMutex`.
+	revoked map[uuid.UUID]struct{}
+
+	// Maps ids of jobs to the credentials of their upload targets, which
+	// compose requests passed and which are kept in memory only, in the
+	// order of the job's targets.
+	credentials      map[uuid.UUID][]*TargetCredentials
+	credentialsMutex sync.Mutex
+
+	quotas      *tenantQuotas
+	builds      *buildSlots
+	registry    *workerRegistry
+	metrics     *metrics
+	clientCerts *clientCertVerifier
+	rateLimit   *ratelimit.Limiter
+
+	// Closed by Close() to stop the goroutines which watch running jobs
+	// and artifacts
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 type JobStatus struct {
@@ -49,17 +110,250 @@ type JobStatus struct {
 	Canceled bool
 }
 
+// Config contains the settings of a worker server.
+type Config struct {
+	// Directory in which artifacts uploaded by workers are stored. If
+	// empty, uploaded artifacts are discarded.
+	ArtifactsDir string
+
+	// The artifacts of finished jobs are deleted when they are older than
+	// `ArtifactMaxAge`, and the oldest ones when all of them are larger
+	// than `ArtifactMaxSize` bytes. Zero disables either limit.
+	ArtifactMaxAge  time.Duration
+	ArtifactMaxSize int64
+
+	// Account numbers which are allowed to access the API. If empty, the
+	// identity header is not verified.
+	IdentityFilter []string
+
+	// If set, workers may authenticate with bearer tokens. Workers
+	// without token need an identity header, if there is an identity
+	// filter.
+	TokenValidator *oidc.Validator
+
+	// If set, workers which connect via TLS must present a client
+	// certificate, which the server verifies. Requests which don't arrive
+	// via TLS, such as the ones of the local worker, are not affected.
+	ClientCerts *ClientCertConfig
+
+	// If set, clients need the roles it grants them: rbac.Worker for
+	// handling jobs and rbac.Admin for managing workers and the dead-letter
+	// queue.
+	AccessPolicy *rbac.Policy
+
+	// If set, requeueing dead-lettered jobs is recorded in it
+	AuditLog *audit.Log
+
+	// If set, enqueueing jobs and the time they wait in the queue are
+	// traced, and jobs carry the context of the span which enqueued them
+	// to the workers
+	Tracer *tracing.Tracer
+
+	// If set, failed jobs are reported to it
+	ErrorReporter *sentry.Client
+
+	// Maps priority class names to job queue priorities. Jobs with a
+	// higher priority are handed to workers first. Entries override or
+	// extend DefaultPriorityClasses.
+	PriorityClasses map[string]int
+
+	// Time after which a running job whose worker has not sent a
+	// heartbeat is considered stale. Zero disables checking for stale
+	// jobs.
+	HeartbeatTimeout time.Duration
+
+	// Identifies this instance in the job queue, which records the
+	// instance that handed out each running job. After a restart, only
+	// the running jobs of this instance are adopted, so that instances
+	// sharing a queue don't take over each other's jobs. It must stay the
+	// same across restarts.
+	InstanceID string
+
+	// Stale jobs are requeued, unless this is set. Then they are finished
+	// with a failed result instead.
+	FailStaleJobs bool
+
+	// Stale and yielded jobs which have been requeued this many times are
+	// moved to the dead-letter queue instead of being requeued again. Zero
+	// means that they are requeued forever.
+	MaxJobFailures int
+
+	// Jobs whose workers report that they failed transiently are
+	// requeued up to this many times, after `TransientRetryBackoff`,
+	// which doubles with each retry. Zero disables retrying. Their last
+	// failure is their result.
+	TransientRetries      int
+	TransientRetryBackoff time.Duration
+
+	// Jobs which run longer than the timeout of their type, such as
+	// "osbuild" or "depsolve", are failed. Workers are told the timeout
+	// and stop the job when it passes. Job types without a timeout may
+	// run forever.
+	JobTimeouts map[string]time.Duration
+
+	// Limits the number of osbuild jobs each tenant can enqueue.
+	TenantQuota TenantQuota
+
+	// Limits the rate of requests of each worker, which is identified by
+	// its token, client certificate or identity header, or else by its
+	// address. Requests over the limit are rejected with 429 Too Many
+	// Requests.
+	RateLimit ratelimit.Config
+
+	// How osbuild jobs of the same priority class are handed out to
+	// workers: SchedulingFIFO (the default) or SchedulingFair.
+	Scheduling string
+
+	// Maximum number of osbuild and osbuild-koji jobs which workers run at
+	// the same time. Workers which request other job types as well are
+	// still handed those. Zero means that there's no limit.
+	MaxConcurrentBuilds int
+
+	// If set, jobs of `BrokerJobTypes` are published to this broker
+	// instead of waiting for workers to request them. Job types contain
+	// the architecture for osbuild jobs, see ArchJobType().
+	Broker         JobBroker
+	BrokerJobTypes []string
+
+	// If set, empty credentials of upload targets are filled in from
+	// these secrets when a job is handed to a worker.
+	Secrets SecretsProvider
+}
+
+// Priority classes used by the composer APIs when enqueueing jobs.
+const (
+	// Composes requested interactively by a user, e.g. via the weldr API.
+	PriorityInteractive = "interactive"
+
+	// Composes requested in bulk, e.g. via the cloud or koji API.
+	PriorityBatch = "batch"
+)
+
+// Scheduling policies of osbuild jobs.
+const (
+	// Jobs are handed out in the order they were enqueued.
+	SchedulingFIFO = "fifo"
+
+	// Jobs of the same priority are handed out round-robin between
+	// tenants, so that a tenant which enqueues many jobs at once doesn't
+	// hold up the others. The next job is the oldest one of the tenant
+	// whose last job was handed out longest ago.
+	SchedulingFair = "fair"
+)
+
+// DefaultPriorityClasses makes interactive composes jump ahead of batch ones.
+var DefaultPriorityClasses = map[string]int{
+	PriorityInteractive: 10,
+	PriorityBatch:       0,
+}
+
 var ErrTokenNotExist = errors.New("worker token does not exist")
+var ErrInvalidPriorityClass = errors.New("priority class does not exist")
+var ErrCredentialsLost = errors.New("the credentials of the job's targets are no longer known")
+var ErrInvalidJobType = errors.New("job type does not exist")
+var ErrJobLogTooLarge = errors.New("job log exceeds its maximum size")
+
+// The log of a running job is kept in memory, up to this size.
+const maxJobLogSize = 16 * 1024 * 1024
+
+// DAGJob is a job passed to EnqueueDAG().
+type DAGJob struct {
+	// One of "osbuild", "osbuild-koji", "koji-init", or "koji-finalize".
+	Type string
+
+	// Architecture the job must run on. Only used for "osbuild" and
+	// "osbuild-koji" jobs.
+	Arch string
+
+	// Arguments of the job, e.g., an *OSBuildKojiJob.
+	Args interface{}
+
+	// Indices of the jobs in the same graph this job depends on.
+	Dependencies []int
+}
+
+func (j *DAGJob) jobType() (string, error) {
+	switch j.Type {
+	case "osbuild", "osbuild-koji":
+		return j.Type + ":" + j.Arch, nil
+	case "koji-init", "koji-finalize":
+		return j.Type, nil
+	default:
+		return "", ErrInvalidJobType
+	}
+}
+
+// Strips the architecture from job types such as "osbuild:x86_64".
+func baseJobType(jobType string) string {
+	return strings.SplitN(jobType, ":", 2)[0]
+}
+
+func NewServer(logger *log.Logger, jobs jobqueue.JobQueue, config Config) *Server {
+	priorityClasses := make(map[string]int)
+	for class, priority := range DefaultPriorityClasses {
+		priorityClasses[class] = priority
+	}
+	for class, priority := range config.PriorityClasses {
+		priorityClasses[class] = priority
+	}
+	config.PriorityClasses = priorityClasses
+
+	s := &Server{
+		jobs:          jobs,
+		logger:        logger,
+		config:        config,
+		running:       make(map[uuid.UUID]uuid.UUID),
+		heartbeats:    make(map[uuid.UUID]time.Time),
+		deadlines:     make(map[uuid.UUID]time.Time),
+		cancellations: make(map[uuid.UUID]chan struct{}),
+		progress:      make(map[uuid.UUID]JobProgress),
+		logs:          make(map[uuid.UUID][]byte),
+		digests:       make(map[uuid.UUID]map[string]*artifactDigest),
+		revoked:       make(map[uuid.UUID]struct{}),
+		credentials:   make(map[uuid.UUID][]*TargetCredentials),
+		quotas:        newTenantQuotas(config.TenantQuota, jobs),
+		builds:        newBuildSlots(config.MaxConcurrentBuilds),
+		registry:      newWorkerRegistry(),
+		metrics:       newMetrics(),
+		stop:          make(chan struct{}),
+	}
+	s.rateLimit = s.NewRateLimiter(config.RateLimit, "worker")
+
+	if config.ClientCerts != nil {
+		s.clientCerts = newClientCertVerifier(config.ClientCerts)
+	}
+
+	if config.HeartbeatTimeout > 0 {
+		s.adoptRunningJobs()
+		go s.watchHeartbeats()
+	}
+
+	if len(config.JobTimeouts) > 0 {
+		go s.watchDeadlines()
+	}
 
-func NewServer(logger *log.Logger, jobs jobqueue.JobQueue, artifactsDir string, identityFilter []string) *Server {
+	if config.ArtifactsDir != "" {
+		s.moveArtifactsToTenants()
+	}
+
+	if config.ArtifactsDir != "" && (config.ArtifactMaxAge > 0 || config.ArtifactMaxSize > 0) {
+		go s.watchArtifacts()
+	}
 
-	return &Server{
-		jobs:           jobs,
-		logger:         logger,
-		artifactsDir:   artifactsDir,
-		identityFilter: identityFilter,
-		running:        make(map[uuid.UUID]uuid.UUID),
+	if config.Broker != nil {
+		for _, jobType := range config.BrokerJobTypes {
+			go s.dispatchJobs(jobType)
+		}
 	}
+
+	return s
+}
+
+// Close stops the goroutines which watch running jobs and artifacts.
+func (s *Server) Close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
 }
 
 func (s *Server) Handler() http.Handler {
@@ -74,70 +368,390 @@ func (s *Server) Handler() http.Handler {
 	}
 
 	var mws []echo.MiddlewareFunc
-	if len(s.identityFilter) > 0 {
+	if s.config.TokenValidator != nil {
+		mws = append(mws, s.VerifyBearerToken)
+	}
+	if len(s.config.IdentityFilter) > 0 {
 		mws = append(mws, s.VerifyIdentityHeader)
 	}
+	if s.clientCerts != nil {
+		mws = append(mws, s.VerifyClientCertificate)
+	}
+	if s.rateLimit != nil {
+		mws = append(mws, s.LimitRate)
+	}
+	// record denied requests as well
+	mws = append(mws, s.config.AuditLog.Middleware(auditEvent))
+	if s.config.AccessPolicy != nil {
+		mws = append(mws, s.Authorize)
+	}
 
 	handler := apiHandlers{
 		server: s,
 	}
-	api.RegisterHandlers(e.Group(api.BasePath, mws...), &handler)
-	api.RegisterHandlers(e.Group(api.CloudBasePath, mws...), &handler)
+	// Without an access policy, the routes for administrators would be
+	// open to every client which can reach the API
+	var router api.EchoRouter = e.Group(api.BasePath, mws...)
+	var cloudRouter api.EchoRouter = e.Group(api.CloudBasePath, mws...)
+	if s.config.AccessPolicy == nil {
+		router, cloudRouter = withoutAdminRoutes{router}, withoutAdminRoutes{cloudRouter}
+	}
+	api.RegisterHandlers(router, &handler)
+	api.RegisterHandlers(cloudRouter, &handler)
+
+	// Prometheus scrapes metrics directly, without going through the
+	// identity-checking proxy.
+	e.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{})))
 
 	return e
 }
 
+// VerifyBearerToken validates the bearer token of requests and stores its
+// claims in the context, as "TokenClaims". The identity of the worker is the
+// subject of the token, or its client id.
+func (s *Server) VerifyBearerToken(nextHandler echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		claims, err := s.config.TokenValidator.ValidateRequest(ctx.Request())
+		if err == oidc.ErrNoToken && len(s.config.IdentityFilter) > 0 {
+			return nextHandler(ctx)
+		}
+		if err != nil {
+			s.metrics.authFailures.Inc()
+			ctx.Response().Header().Set("WWW-Authenticate", "Bearer")
+			return echo.NewHTTPError(http.StatusUnauthorized, "Bearer token is missing or not valid").SetInternal(err)
+		}
+
+		ctx.Set("TokenClaims", claims)
+		if claims.Subject != "" {
+			ctx.Set("WorkerIdentity", claims.Subject)
+		} else {
+			ctx.Set("WorkerIdentity", claims.ClientID)
+		}
+		return nextHandler(ctx)
+	}
+}
+
+type identityHeader struct {
+	Identity struct {
+		AccountNumber string `json:"account_number"`
+	} `json:"identity"`
+}
+
 func (s *Server) VerifyIdentityHeader(nextHandler echo.HandlerFunc) echo.HandlerFunc {
 	return func(ctx echo.Context) error {
-		type identityHeader struct {
-			Identity struct {
-				AccountNumber string `json:"account_number"`
-			} `json:"identity"`
+		// authenticated with a bearer token
+		if ctx.Get("TokenClaims") != nil {
+			return nextHandler(ctx)
 		}
 
 		request := ctx.Request()
 
 		idHeaderB64 := request.Header["X-Rh-Identity"]
 		if len(idHeaderB64) != 1 {
+			s.metrics.authFailures.Inc()
 			return echo.NewHTTPError(http.StatusNotFound, "Auth header is not present")
 		}
 
 		b64Result, err := base64.StdEncoding.DecodeString(idHeaderB64[0])
 		if err != nil {
+			s.metrics.authFailures.Inc()
 			return echo.NewHTTPError(http.StatusNotFound, "Auth header has incorrect format")
 		}
 
 		var idHeader identityHeader
 		err = json.Unmarshal([]byte(strings.TrimSuffix(fmt.Sprintf("%s", b64Result), "\n")), &idHeader)
 		if err != nil {
+			s.metrics.authFailures.Inc()
 			return echo.NewHTTPError(http.StatusNotFound, "Auth header has incorrect format")
 		}
 
-		for _, i := range s.identityFilter {
+		for _, i := range s.config.IdentityFilter {
 			if idHeader.Identity.AccountNumber == i {
 				ctx.Set("IdentityHeader", idHeader)
 				return nextHandler(ctx)
 
 			}
 		}
+		s.metrics.authFailures.Inc()
 		return echo.NewHTTPError(http.StatusNotFound, "Account not allowed")
 	}
 }
 
-func (s *Server) EnqueueOSBuild(arch string, job *OSBuildJob) (uuid.UUID, error) {
-	return s.jobs.Enqueue("osbuild:"+arch, job, nil)
+// VerifyClientCertificate verifies the client certificate of requests which
+// arrived via TLS and stores the identity of the worker in the context, as
+// "WorkerIdentity".
+func (s *Server) VerifyClientCertificate(nextHandler echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		state := ctx.Request().TLS
+		if state == nil {
+			return nextHandler(ctx)
+		}
+
+		identity, err := s.clientCerts.verify(state.PeerCertificates, time.Now())
+		if err != nil {
+			s.metrics.authFailures.Inc()
+			return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Client certificate is not accepted: %v", err))
+		}
+
+		ctx.Set("WorkerIdentity", identity)
+		return nextHandler(ctx)
+	}
+}
+
+// LimitRate rejects requests of workers which exceed the rate limit with 429
+// Too Many Requests. Workers are identified like in the audit log, or by
+// their address if they didn't authenticate.
+func (s *Server) LimitRate(nextHandler echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		allowed, wait := s.rateLimit.Allow(rateLimitKey(ctx))
+		if !allowed {
+			ctx.Response().Header().Set("Retry-After", ratelimit.RetryAfter(wait))
+			return echo.NewHTTPError(http.StatusTooManyRequests, "Too many requests")
+		}
+		return nextHandler(ctx)
+	}
+}
+
+func rateLimitKey(ctx echo.Context) string {
+	if identity, ok := ctx.Get("WorkerIdentity").(string); ok && identity != "" {
+		return "worker:" + identity
+	}
+	if idHeader, ok := ctx.Get("IdentityHeader").(identityHeader); ok {
+		return "account:" + idHeader.Identity.AccountNumber
+	}
+	host, _, err := net.SplitHostPort(ctx.Request().RemoteAddr)
+	if err != nil {
+		host = ctx.Request().RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// NewRateLimiter returns a limiter for `config`, or nil if it doesn't limit
+// anything. The requests it rejects are counted in the metrics of the worker
+// API, labeled with `api`, so that the other APIs of composer can use it as
+// well.
+func (s *Server) NewRateLimiter(config ratelimit.Config, api string) *ratelimit.Limiter {
+	return ratelimit.New(config, s.metrics.rateLimited.WithLabelValues(api))
+}
+
+// EnqueueOSBuild enqueues an osbuild job for `arch`. Jobs of a priority class
+// with a higher priority are handed out to workers first. Returns
+// ErrQuotaExceeded when `tenant` already has too many jobs. An empty tenant
+// is not subject to any quota, and its jobs are scheduled like the ones of a
+// single tenant.
+func (s *Server) EnqueueOSBuild(ctx context.Context, arch string, job *OSBuildJob, priorityClass, tenant string) (uuid.UUID, error) {
+	priority, ok := s.config.PriorityClasses[priorityClass]
+	if !ok {
+		return uuid.Nil, ErrInvalidPriorityClass
+	}
+	job.PriorityClass = priorityClass
+	job.Tenant = tenant
+
+	return s.quotas.enqueue(tenant, func() (uuid.UUID, error) {
+		return s.enqueue(ctx, "osbuild:"+arch, job, nil, priority)
+	})
+}
+
+// EnqueueOSBuildAsDependency enqueues a DepsolveJob, a ManifestJobByID which
+// generates the manifest from the packages it resolves, and an osbuild job for
+// `arch` which builds that manifest. The manifest in `job` is ignored. Returns
+// the id of the osbuild job, which is subject to the same priority and quota
+// as jobs enqueued with EnqueueOSBuild().
+func (s *Server) EnqueueOSBuildAsDependency(ctx context.Context, arch string, depsolveJob *DepsolveJob, manifestJob *ManifestJobByID, job *OSBuildJob, priorityClass, tenant string) (uuid.UUID, error) {
+	priority, ok := s.config.PriorityClasses[priorityClass]
+	if !ok {
+		return uuid.Nil, ErrInvalidPriorityClass
+	}
+	job.PriorityClass = priorityClass
+	job.Tenant = tenant
+
+	return s.quotas.enqueue(tenant, func() (uuid.UUID, error) {
+		ids, err := s.enqueueJobs(ctx, []jobqueue.JobSpec{
+			{
+				Type:     "depsolve",
+				Args:     depsolveJob,
+				Priority: priority,
+			},
+			{
+				Type:         "manifest-id-only",
+				Args:         manifestJob,
+				Priority:     priority,
+				Dependencies: []int{0},
+			},
+			{
+				Type:         "osbuild:" + arch,
+				Args:         job,
+				Priority:     priority,
+				Dependencies: []int{1},
+			},
+		})
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return ids[2], nil
+	})
+}
+
+// RetryOSBuild enqueues a new osbuild job with the arguments and
+// dependencies of the finished osbuild job `id`, so that it builds the same
+// manifest again without depsolving its packages again. The new job has the
+// priority class of `id` and is subject to the same quota as jobs enqueued
+// with EnqueueOSBuild(). Returns ErrCredentialsLost if the targets of `id`
+// had credentials of the client, because those are not kept after `id` has
+// finished.
+func (s *Server) RetryOSBuild(ctx context.Context, id uuid.UUID, tenant string) (uuid.UUID, error) {
+	jobType, rawArgs, deps, err := s.jobs.Job(id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !strings.HasPrefix(jobType, "osbuild:") {
+		return uuid.Nil, fmt.Errorf("job %s is not an osbuild job", id)
+	}
+	var job OSBuildJob
+	if err := json.Unmarshal(rawArgs, &job); err != nil {
+		return uuid.Nil, fmt.Errorf("error reading job %s: %v", id, err)
+	}
+	if job.ClientCredentials {
+		return uuid.Nil, ErrCredentialsLost
+	}
+
+	// jobs queued before their class was recorded came from the Cloud
+	// API, which only enqueues batch jobs
+	if job.PriorityClass == "" {
+		job.PriorityClass = PriorityBatch
+	}
+	priority, ok := s.config.PriorityClasses[job.PriorityClass]
+	if !ok {
+		return uuid.Nil, ErrInvalidPriorityClass
+	}
+	// requests with the idempotency key of the original compose still get
+	// the original
+	job.Idempotency = nil
+
+	return s.quotas.enqueue(tenant, func() (uuid.UUID, error) {
+		return s.enqueue(ctx, jobType, &job, deps, priority)
+	})
+}
+
+// EnqueueCompose enqueues a job which groups the osbuild jobs `imageIDs` of
+// the images of one compose and the upload jobs in `job.Uploads`, so that the
+// compose can be referred to by the id of that job. The dependencies of the
+// job are the images in the same order, followed by the upload jobs.
+func (s *Server) EnqueueCompose(ctx context.Context, job *ComposeJob, imageIDs []uuid.UUID) (uuid.UUID, error) {
+	deps := append([]uuid.UUID{}, imageIDs...)
+	for _, uploadIDs := range job.Uploads {
+		deps = append(deps, uploadIDs...)
+	}
+	return s.enqueue(ctx, "compose", job, deps, 0)
+}
+
+// EnqueueUpload enqueues a job which uploads the image built by the osbuild
+// job `osbuildID` to `job.Target`. That job must upload the image as artifact
+// `job.ImageName`.
+func (s *Server) EnqueueUpload(ctx context.Context, job *UploadJob, osbuildID uuid.UUID) (uuid.UUID, error) {
+	return s.enqueue(ctx, "upload", job, []uuid.UUID{osbuildID}, 0)
+}
+
+func (s *Server) EnqueueOSBuildKoji(ctx context.Context, arch string, job *OSBuildKojiJob, initID uuid.UUID) (uuid.UUID, error) {
+	return s.enqueue(ctx, "osbuild-koji:"+arch, job, []uuid.UUID{initID}, 0)
+}
+
+func (s *Server) EnqueueKojiInit(ctx context.Context, job *KojiInitJob) (uuid.UUID, error) {
+	return s.enqueue(ctx, "koji-init", job, nil, 0)
+}
+
+func (s *Server) EnqueueKojiFinalize(ctx context.Context, job *KojiFinalizeJob, initID uuid.UUID, buildIDs []uuid.UUID) (uuid.UUID, error) {
+	return s.enqueue(ctx, "koji-finalize", job, append([]uuid.UUID{initID}, buildIDs...), 0)
 }
 
-func (s *Server) EnqueueOSBuildKoji(arch string, job *OSBuildKojiJob, initID uuid.UUID) (uuid.UUID, error) {
-	return s.jobs.Enqueue("osbuild-koji:"+arch, job, []uuid.UUID{initID})
+// EnqueueDAG enqueues a graph of jobs which depend on each other atomically:
+// either all of them are enqueued or none. Each job's dependencies are
+// indices of jobs earlier in `jobs`. Returns the ids of the new jobs, in the
+// same order as `jobs`.
+//
+// Workers receive the results of a job's dependencies as dynamic arguments,
+// in the order of its dependencies.
+func (s *Server) EnqueueDAG(ctx context.Context, jobs []DAGJob) ([]uuid.UUID, error) {
+	specs := make([]jobqueue.JobSpec, len(jobs))
+	for i, j := range jobs {
+		jobType, err := j.jobType()
+		if err != nil {
+			return nil, err
+		}
+		specs[i] = jobqueue.JobSpec{
+			Type:         jobType,
+			Args:         j.Args,
+			Dependencies: j.Dependencies,
+		}
+	}
+
+	return s.enqueueJobs(ctx, specs)
 }
 
-func (s *Server) EnqueueKojiInit(job *KojiInitJob) (uuid.UUID, error) {
-	return s.jobs.Enqueue("koji-init", job, nil)
+// The credentials of jobs are recorded while holding `credentialsMutex`, so
+// that withCredentials() waits for them when a job is dequeued right away.
+func (s *Server) enqueueJobs(ctx context.Context, specs []jobqueue.JobSpec) ([]uuid.UUID, error) {
+	creds := make([][]*TargetCredentials, len(specs))
+	locked := false
+	for i, spec := range specs {
+		creds[i] = jobCredentials(spec.Args)
+		if creds[i] != nil {
+			markClientCredentials(spec.Args)
+		}
+		if creds[i] != nil && !locked {
+			s.credentialsMutex.Lock()
+			defer s.credentialsMutex.Unlock()
+			locked = true
+		}
+	}
+
+	ctx, span := s.config.Tracer.Start(ctx, "enqueue", trace.SpanKindProducer)
+	defer span.End()
+	for i := range specs {
+		specs[i].Args = withTraceparent(ctx, specs[i].Args)
+	}
+
+	ids, err := s.jobs.EnqueueJobs(specs)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("job.id", ids[len(ids)-1].String()))
+
+	for i, spec := range specs {
+		if creds[i] != nil {
+			s.credentials[ids[i]] = creds[i]
+		}
+		s.metrics.jobsEnqueued.WithLabelValues(baseJobType(spec.Type)).Inc()
+		jobLogger(ids[i], spec.Type, spec.Args).Infof("Job enqueued")
+	}
+	return ids, nil
 }
 
-func (s *Server) EnqueueKojiFinalize(job *KojiFinalizeJob, initID uuid.UUID, buildIDs []uuid.UUID) (uuid.UUID, error) {
-	return s.jobs.Enqueue("koji-finalize", job, append([]uuid.UUID{initID}, buildIDs...))
+func (s *Server) enqueue(ctx context.Context, jobType string, job interface{}, dependencies []uuid.UUID, priority int) (uuid.UUID, error) {
+	creds := jobCredentials(job)
+	if creds != nil {
+		markClientCredentials(job)
+		s.credentialsMutex.Lock()
+		defer s.credentialsMutex.Unlock()
+	}
+
+	ctx, span := s.config.Tracer.Start(ctx, "enqueue", trace.SpanKindProducer, attribute.String("job.type", baseJobType(jobType)))
+	defer span.End()
+
+	id, err := s.jobs.Enqueue(jobType, withTraceparent(ctx, job), dependencies, priority)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return uuid.Nil, err
+	}
+	span.SetAttributes(attribute.String("job.id", id.String()))
+
+	if creds != nil {
+		s.credentials[id] = creds
+	}
+	s.metrics.jobsEnqueued.WithLabelValues(baseJobType(jobType)).Inc()
+	jobLogger(id, jobType, job).Infof("Job enqueued")
+	return id, nil
 }
 
 func (s *Server) JobStatus(id uuid.UUID, result interface{}) (*JobStatus, []uuid.UUID, error) {
@@ -184,13 +798,109 @@ func (s *Server) Job(id uuid.UUID, job interface{}) (string, json.RawMessage, []
 }
 
 func (s *Server) Cancel(id uuid.UUID) error {
-	return s.jobs.CancelJob(id)
+	err := s.jobs.CancelJob(id)
+	if err != nil {
+		return err
+	}
+
+	s.forgetCredentials(id)
+
+	// wake up workers waiting for the job to be canceled
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+	for token, jobId := range s.running {
+		if jobId == id {
+			s.notifyCancellation(token)
+		}
+	}
+
+	return nil
+}
+
+// How long WaitForCancellation requests from workers block at most, and how
+// often the job queue is checked for cancellations which happened through
+// other instances of composer sharing the same queue.
+const cancellationPollTimeout = 30 * time.Second
+const cancellationCheckInterval = 10 * time.Second
+
+// WaitForCancellation blocks until the job with `token` is canceled, stops
+// running, or `ctx` is done. Returns whether the job was canceled.
+func (s *Server) WaitForCancellation(ctx context.Context, token uuid.UUID) (bool, error) {
+	s.runningMutex.Lock()
+	jobId, ok := s.running[token]
+	cancellation := s.cancellations[token]
+	_, revoked := s.revoked[token]
+	s.runningMutex.Unlock()
+
+	if revoked {
+		return true, nil
+	}
+	if !ok {
+		return false, ErrTokenNotExist
+	}
+
+	ticker := time.NewTicker(cancellationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		_, _, _, _, canceled, _, err := s.jobs.JobStatus(jobId)
+		if err != nil {
+			return false, err
+		}
+		if canceled || cancellation == nil {
+			return canceled, nil
+		}
+
+		select {
+		case <-cancellation:
+			cancellation = nil
+			if s.wasRevoked(token) {
+				return true, nil
+			}
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false, nil
+		}
+	}
+}
+
+// Note: Only call this function with runningMutex locked!
+func (s *Server) notifyCancellation(token uuid.UUID) {
+	if cancellation, ok := s.cancellations[token]; ok {
+		close(cancellation)
+		delete(s.cancellations, token)
+	}
+	// the job is canceled or stopped running, which ends its build
+	s.builds.release(token)
+}
+
+// DeadLetterJobs returns the ids of the jobs which were moved to the
+// dead-letter queue because they went stale too often.
+func (s *Server) DeadLetterJobs() ([]uuid.UUID, error) {
+	return s.jobs.DeadLetterJobs()
+}
+
+// RequeueDeadLetterJob moves the job `id` from the dead-letter queue back into
+// the job queue.
+func (s *Server) RequeueDeadLetterJob(id uuid.UUID) error {
+	return s.jobs.RequeueDeadLetterJob(id)
+}
+
+// ListJobs returns the ids of the jobs selected by `filter`, in the order they
+// were queued, and how many jobs it selects without its offset and limit.
+func (s *Server) ListJobs(filter jobqueue.JobFilter) ([]uuid.UUID, int, error) {
+	return s.jobs.ListJobs(filter)
+}
+
+// ArtifactsEnabled returns whether the server keeps the artifacts of jobs.
+func (s *Server) ArtifactsEnabled() bool {
+	return s.config.ArtifactsDir != ""
 }
 
 // Provides access to artifacts of a job. Returns an io.Reader for the artifact
 // and the artifact's size.
 func (s *Server) JobArtifact(id uuid.UUID, name string) (io.Reader, int64, error) {
-	if s.artifactsDir == "" {
+	if s.config.ArtifactsDir == "" {
 		return nil, 0, errors.New("Artifacts not enabled")
 	}
 
@@ -203,8 +913,15 @@ func (s *Server) JobArtifact(id uuid.UUID, name string) (io.Reader, int64, error
 		return nil, 0, fmt.Errorf("Cannot access artifacts before job is finished: %s", id)
 	}
 
-	p := path.Join(s.artifactsDir, id.String(), name)
-	f, err := os.Open(p)
+	if !validArtifactName(name) {
+		return nil, 0, fmt.Errorf("Invalid artifact name: %s", name)
+	}
+
+	dir, err := s.jobArtifactsDir(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	f, err := os.Open(path.Join(dir, name))
 	if err != nil {
 		return nil, 0, fmt.Errorf("Error accessing artifact %s for job %s: %v", name, id, err)
 	}
@@ -219,7 +936,7 @@ func (s *Server) JobArtifact(id uuid.UUID, name string) (io.Reader, int64, error
 
 // Deletes all artifacts for job `id`.
 func (s *Server) DeleteArtifacts(id uuid.UUID) error {
-	if s.artifactsDir == "" {
+	if s.config.ArtifactsDir == "" {
 		return errors.New("Artifacts not enabled")
 	}
 
@@ -232,7 +949,11 @@ func (s *Server) DeleteArtifacts(id uuid.UUID) error {
 		return fmt.Errorf("Cannot delete artifacts before job is finished: %s", id)
 	}
 
-	return os.RemoveAll(path.Join(s.artifactsDir, id.String()))
+	dir, err := s.jobArtifactsDir(id)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
 }
 
 func (s *Server) RequestJob(ctx context.Context, arch string, jobTypes []string) (uuid.UUID, uuid.UUID, string, json.RawMessage, []json.RawMessage, error) {
@@ -243,26 +964,41 @@ func (s *Server) RequestJob(ctx context.Context, arch string, jobTypes []string)
 	// restriction: arch for osbuild jobs.
 	jts := []string{}
 	for _, t := range jobTypes {
-		if t == "osbuild" || t == "osbuild-koji" {
-			t = t + ":" + arch
-		}
-		jts = append(jts, t)
+		jts = append(jts, ArchJobType(t, arch))
 	}
 
-	jobId, depIDs, jobType, args, err := s.jobs.Dequeue(ctx, jts)
+	jobId, depIDs, jobType, args, err := s.dequeue(ctx, token, jts)
 	if err != nil {
 		return uuid.Nil, uuid.Nil, "", nil, nil, err
 	}
 
+	// without the credentials, the worker falls back to its own ones
+	withCreds, err := s.withCredentials(jobId, baseJobType(jobType), args)
+	if err == ErrCredentialsLost {
+		s.failLostCredentials(token, jobId)
+		return s.RequestJob(ctx, arch, jobTypes)
+	} else if err != nil {
+		jobLogger(jobId, jobType, args).Errorf("Error filling in the credentials: %v", err)
+		withCreds = args
+	}
+
+	_, queued, started, _, _, _, err := s.jobs.JobStatus(jobId)
+	if err == nil {
+		s.metrics.jobWaitDuration.WithLabelValues(baseJobType(jobType)).Observe(started.Sub(queued).Seconds())
+		s.traceQueueWait(baseJobType(jobType), args, queued, started)
+		jobLogger(jobId, jobType, args).Infof("Job dequeued after waiting %s", started.Sub(queued))
+	}
+
 	var dynamicArgs []json.RawMessage
 	for _, depID := range depIDs {
 		result, _, _, _, _, _, _ := s.jobs.JobStatus(depID)
 		dynamicArgs = append(dynamicArgs, result)
 	}
 
-	if s.artifactsDir != "" {
-		err := os.MkdirAll(path.Join(s.artifactsDir, "tmp", token.String()), 0700)
+	if s.config.ArtifactsDir != "" {
+		err := os.MkdirAll(path.Join(s.config.ArtifactsDir, "tmp", token.String()), 0700)
 		if err != nil {
+			s.builds.release(token)
 			return uuid.Nil, uuid.Nil, "", nil, nil, fmt.Errorf("cannot create artifact directory: %v", err)
 		}
 	}
@@ -270,6 +1006,11 @@ func (s *Server) RequestJob(ctx context.Context, arch string, jobTypes []string)
 	s.runningMutex.Lock()
 	defer s.runningMutex.Unlock()
 	s.running[token] = jobId
+	s.heartbeats[token] = time.Now()
+	s.cancellations[token] = make(chan struct{})
+	if timeout := s.config.JobTimeouts[baseJobType(jobType)]; timeout > 0 {
+		s.deadlines[token] = time.Now().Add(timeout)
+	}
 
 	if jobType == "osbuild:"+arch {
 		jobType = "osbuild"
@@ -277,7 +1018,168 @@ func (s *Server) RequestJob(ctx context.Context, arch string, jobTypes []string)
 		jobType = "osbuild-koji"
 	}
 
-	return token, jobId, jobType, args, dynamicArgs, nil
+	return token, jobId, jobType, withCreds, dynamicArgs, nil
+}
+
+// Fails the job `id`, which was just dequeued for `token`, because the
+// credentials the client passed for its targets are no longer known. They
+// are only kept in memory and are gone after a restart, or on other
+// instances of composer. Handing out the job would make the worker upload
+// to the client's target with the credentials of composer.
+func (s *Server) failLostCredentials(token, id uuid.UUID) {
+	s.builds.release(token)
+
+	logger := s.jobLoggerByID(id)
+	logger.Warnf("Credentials of the job's targets were lost, failing it")
+	err := s.failJob(id, "the credentials of the upload target were lost, the compose must be submitted again")
+	if err != nil && err != jobqueue.ErrCanceled {
+		logger.Errorf("Error failing job: %v", err)
+	}
+}
+
+// Dequeues a job of one of `jobTypes` for `token`. Builds are only dequeued
+// after reserving one of the MaxConcurrentBuilds build slots for `token`.
+// Until one is free, jobs of the other types are dequeued, or, if there are
+// none, this blocks until a build stops running.
+func (s *Server) dequeue(ctx context.Context, token uuid.UUID, jobTypes []string) (uuid.UUID, []uuid.UUID, string, json.RawMessage, error) {
+	options := jobqueue.DequeueOptions{
+		Owner: s.config.InstanceID,
+		Fair:  s.config.Scheduling == SchedulingFair,
+	}
+
+	for {
+		types := jobTypes
+		reserved := false
+		var released <-chan struct{}
+		if containsBuildJobType(jobTypes) {
+			reserved, released = s.builds.reserve(token)
+			if !reserved {
+				types = nil
+				for _, t := range jobTypes {
+					if !isBuildJobType(t) {
+						types = append(types, t)
+					}
+				}
+			}
+		}
+
+		if len(types) == 0 {
+			select {
+			case <-released:
+				continue
+			case <-ctx.Done():
+				return uuid.Nil, nil, "", nil, ctx.Err()
+			}
+		}
+
+		// stop waiting for the other job types when a slot is released
+		dequeueCtx, cancel := context.WithCancel(ctx)
+		if released != nil {
+			go func() {
+				select {
+				case <-released:
+					cancel()
+				case <-dequeueCtx.Done():
+				}
+			}()
+		}
+		jobId, depIDs, jobType, args, err := s.jobs.Dequeue(dequeueCtx, types, options)
+		cancel()
+
+		// the slot is only kept for a build
+		if reserved && (err != nil || !isBuildJobType(jobType)) {
+			s.builds.release(token)
+		}
+
+		if err != nil && released != nil && ctx.Err() == nil && dequeueCtx.Err() != nil {
+			continue
+		}
+		return jobId, depIDs, jobType, args, err
+	}
+}
+
+func containsBuildJobType(jobTypes []string) bool {
+	for _, t := range jobTypes {
+		if isBuildJobType(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Assembles the response to a job request. Locations are prefixed with
+// `basePath`, which is empty for locations relative to the API's base path.
+func (s *Server) jobResponse(basePath string, token, jobId uuid.UUID, jobType string, args json.RawMessage, dynamicArgs []json.RawMessage) (*requestJobResponse, error) {
+	dynamicArgsTypes, err := s.dependencyTypes(jobId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &requestJobResponse{
+		Id:               jobId,
+		Location:         fmt.Sprintf("%sjobs/%v", basePath, token),
+		ArtifactLocation: fmt.Sprintf("%sjobs/%v/artifacts/", basePath, token),
+		Type:             jobType,
+		Args:             args,
+		DynamicArgs:      dynamicArgs,
+		DynamicArgsTypes: dynamicArgsTypes,
+		Timeout:          int(math.Ceil(s.config.JobTimeouts[baseJobType(jobType)].Seconds())),
+	}, nil
+}
+
+// Returns the types of the dependencies of job `id`, in the same order as
+// their results are passed to workers as dynamic arguments.
+func (s *Server) dependencyTypes(id uuid.UUID) ([]string, error) {
+	_, _, depIDs, err := s.jobs.Job(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []string
+	for _, depID := range depIDs {
+		depType, _, _, err := s.jobs.Job(depID)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, baseJobType(depType))
+	}
+
+	return types, nil
+}
+
+// RegisterWorker registers a worker with the capabilities in `info` and
+// returns its id. Ids, registration times, and last-seen times in `info` are
+// ignored.
+func (s *Server) RegisterWorker(info WorkerInfo) uuid.UUID {
+	return s.registry.register(info)
+}
+
+// Workers returns all registered workers, in the order they registered.
+func (s *Server) Workers() []WorkerInfo {
+	return s.registry.list()
+}
+
+// Arches returns the architectures for which registered workers build images,
+// i.e., which accept osbuild or osbuild-koji jobs.
+func (s *Server) Arches() []string {
+	return s.registry.arches([]string{"osbuild", "osbuild-koji"})
+}
+
+// CheckArch returns ErrNoWorkerForArch if jobs of `jobType` for `arch` would
+// never be picked up, because none of the registered workers runs them. Jobs
+// are accepted for all architectures as long as no worker has registered,
+// because workers only register again after composer was restarted once
+// they request their next job, and older workers don't register at all.
+func (s *Server) CheckArch(jobType, arch string) error {
+	if s.registry.empty() {
+		return nil
+	}
+	for _, a := range s.registry.arches([]string{jobType}) {
+		if a == arch {
+			return nil
+		}
+	}
+	return ErrNoWorkerForArch
 }
 
 func (s *Server) RunningJob(token uuid.UUID) (uuid.UUID, error) {
@@ -298,41 +1200,372 @@ func (s *Server) FinishJob(token uuid.UUID, result json.RawMessage) error {
 
 	jobId, ok := s.running[token]
 	if !ok {
+		// the worker noticed that its job was requeued or timed out
+		delete(s.revoked, token)
 		return ErrTokenNotExist
 	}
 
 	// Always delete the running job, even if there are errors finishing
 	// the job, because callers won't call this a second time on error.
 	delete(s.running, token)
+	delete(s.heartbeats, token)
+	delete(s.deadlines, token)
+	delete(s.progress, token)
+	delete(s.logs, token)
+	delete(s.digests, token)
+	s.notifyCancellation(token)
+
+	if s.retryTransientFailure(token, jobId, result) {
+		return nil
+	}
 
+	result = s.scrub(jobId, result)
 	err := s.jobs.FinishJob(jobId, result)
+	s.forgetCredentials(jobId)
 	if err != nil {
 		return fmt.Errorf("error finishing job: %v", err)
 	}
 
+	s.observeBuildDuration(jobId)
+	s.jobLoggerByID(jobId).Infof("Job finished")
+	s.reportFailure(jobId, result)
+
 	// Move artifacts from the temporary location to the final job
 	// location. Log any errors, but do not treat them as fatal. The job is
 	// already finished.
-	if s.artifactsDir != "" {
-		err := os.Rename(path.Join(s.artifactsDir, "tmp", token.String()), path.Join(s.artifactsDir, jobId.String()))
+	if s.config.ArtifactsDir != "" {
+		dir, err := s.jobArtifactsDir(jobId)
+		if err == nil {
+			err = os.MkdirAll(path.Dir(dir), 0700)
+		}
+		if err == nil {
+			err = os.Rename(path.Join(s.config.ArtifactsDir, "tmp", token.String()), dir)
+		}
 		if err != nil {
-			log.Printf("Error moving artifacts for job%s: %v", jobId, err)
+			s.jobLoggerByID(jobId).Errorf("Error moving artifacts: %v", err)
+		} else {
+			// artifacts are retained from the time the job finished
+			now := time.Now()
+			_ = os.Chtimes(dir, now, now)
 		}
 	}
 
 	return nil
 }
 
-// apiHandlers implements api.ServerInterface - the http api route handlers
-// generated from api/openapi.yml. This is a separate object, because these
-// handlers should not be exposed on the `Server` object.
-type apiHandlers struct {
-	server *Server
-}
-
-func (h *apiHandlers) GetStatus(ctx echo.Context) error {
+// YieldJob puts the running job with `token` back into the queue, because its
+// worker cannot run it for `reason`, for example because it lacks the
+// resources to build the image. Yielding counts as a failure of the job, so
+// that a job which no worker can run ends up in the dead-letter queue.
+func (s *Server) YieldJob(token uuid.UUID, reason string) error {
+	s.runningMutex.Lock()
+	jobId, ok := s.running[token]
+	if !ok {
+		delete(s.revoked, token)
+		s.runningMutex.Unlock()
+		return ErrTokenNotExist
+	}
+	delete(s.running, token)
+	delete(s.heartbeats, token)
+	delete(s.deadlines, token)
+	delete(s.progress, token)
+	delete(s.logs, token)
+	delete(s.digests, token)
+	s.notifyCancellation(token)
+	s.runningMutex.Unlock()
+
+	logger := s.jobLoggerByID(jobId)
+
+	if s.config.ArtifactsDir != "" {
+		err := os.RemoveAll(path.Join(s.config.ArtifactsDir, "tmp", token.String()))
+		if err != nil {
+			logger.Errorf("Error removing artifacts of yielded job: %v", err)
+		}
+	}
+
+	deadLettered, err := s.jobs.RequeueJob(jobId, s.config.MaxJobFailures)
+	if err == jobqueue.ErrCanceled {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error requeueing job: %v", err)
+	}
+
+	if deadLettered {
+		logger.Warnf("Worker yielded the job (%s) and it failed too often, moved it to the dead-letter queue", reason)
+	} else {
+		logger.Warnf("Worker yielded the job (%s), requeued it", reason)
+	}
+
+	return nil
+}
+
+// Records how long the build took, if `id` refers to an osbuild job.
+func (s *Server) observeBuildDuration(id uuid.UUID) {
+	jobType, rawArgs, _, err := s.jobs.Job(id)
+	if err != nil || (baseJobType(jobType) != "osbuild" && baseJobType(jobType) != "osbuild-koji") {
+		return
+	}
+
+	// OSBuildJob and OSBuildKojiJob share the image_type field
+	var args struct {
+		ImageType string `json:"image_type"`
+	}
+	err = json.Unmarshal(rawArgs, &args)
+	if err != nil {
+		jobLogger(id, jobType, rawArgs).Errorf("Error parsing arguments: %v", err)
+		return
+	}
+
+	_, _, started, finished, _, _, err := s.jobs.JobStatus(id)
+	if err != nil {
+		jobLogger(id, jobType, rawArgs).Errorf("Error getting status: %v", err)
+		return
+	}
+
+	s.metrics.buildDuration.WithLabelValues(args.ImageType).Observe(finished.Sub(started).Seconds())
+}
+
+// Heartbeat records that the worker running the job with `token` is still
+// alive.
+func (s *Server) Heartbeat(token uuid.UUID) error {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+
+	if _, ok := s.running[token]; !ok {
+		return ErrTokenNotExist
+	}
+
+	s.heartbeats[token] = time.Now()
+
+	return nil
+}
+
+// UpdateJobProgress stores the progress of the running job with `token`.
+func (s *Server) UpdateJobProgress(token uuid.UUID, progress *JobProgress) error {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+
+	if _, ok := s.running[token]; !ok {
+		return ErrTokenNotExist
+	}
+
+	s.progress[token] = *progress
+
+	return nil
+}
+
+// JobProgress returns the progress the worker running job `id` reported
+// last, or nil if the job is not running or hasn't reported any progress.
+func (s *Server) JobProgress(id uuid.UUID) *JobProgress {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+
+	for token, jobId := range s.running {
+		if jobId != id {
+			continue
+		}
+		if progress, ok := s.progress[token]; ok {
+			return &progress
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// AppendJobLog appends `chunk` to the log of the running job with `token`.
+func (s *Server) AppendJobLog(token uuid.UUID, chunk []byte) error {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+
+	jobId, ok := s.running[token]
+	if !ok {
+		return ErrTokenNotExist
+	}
+
+	if len(s.logs[token])+len(chunk) > maxJobLogSize {
+		return ErrJobLogTooLarge
+	}
+	// scrub the whole log, in case a secret spans chunks
+	s.logs[token] = s.scrub(jobId, append(s.logs[token], chunk...))
+
+	return nil
+}
+
+// JobLog returns the log the worker running job `id` has sent so far, from
+// byte `offset` on. The log is only kept while the job is running.
+func (s *Server) JobLog(id uuid.UUID, offset int) []byte {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+
+	for token, jobId := range s.running {
+		if jobId != id {
+			continue
+		}
+		jobLog := s.logs[token]
+		if offset < 0 || offset >= len(jobLog) {
+			return nil
+		}
+		return append([]byte(nil), jobLog[offset:]...)
+	}
+
+	return nil
+}
+
+// Regularly look for running jobs whose worker didn't send a heartbeat within
+// `HeartbeatTimeout` and requeue or fail them, until the server is closed.
+func (s *Server) watchHeartbeats() {
+	ticker := time.NewTicker(s.config.HeartbeatTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			for token, jobId := range s.removeStaleJobs() {
+				err := s.handleStaleJob(token, jobId)
+				if err != nil {
+					s.jobLoggerByID(jobId).Errorf("Error handling stale job: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// Adds the jobs which the queue considers running for this instance to the
+// set of running jobs. They were dequeued before composer was restarted, and
+// their workers' tokens are unknown. The running jobs of other instances
+// sharing the queue are left to those. Giving them new tokens, which no worker has, makes them go
+// stale after `HeartbeatTimeout`, so that they are requeued or failed like
+// the jobs of workers which stopped sending heartbeats.
+func (s *Server) adoptRunningJobs() {
+	ids, _, err := s.jobs.ListJobs(jobqueue.JobFilter{
+		States: []jobqueue.JobState{jobqueue.JobRunning},
+		Owner:  &s.config.InstanceID,
+	})
+	if err != nil {
+		logging.Default().Errorf("Error looking for jobs which were running before a restart: %v", err)
+		return
+	}
+
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+
+	for _, id := range ids {
+		token := uuid.New()
+		s.running[token] = id
+		s.heartbeats[token] = time.Now()
+		s.cancellations[token] = make(chan struct{})
+		s.jobLoggerByID(id).Infof("Job was running before a restart, waiting for its heartbeat timeout")
+	}
+}
+
+// Removes all stale jobs from the set of running jobs and returns them. Their
+// tokens become invalid, so that workers which are merely slow cannot report
+// results for them anymore.
+func (s *Server) removeStaleJobs() map[uuid.UUID]uuid.UUID {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+
+	stale := make(map[uuid.UUID]uuid.UUID)
+	for token, heartbeat := range s.heartbeats {
+		if time.Since(heartbeat) > s.config.HeartbeatTimeout {
+			stale[token] = s.running[token]
+			delete(s.running, token)
+			delete(s.heartbeats, token)
+			delete(s.deadlines, token)
+			delete(s.progress, token)
+			delete(s.logs, token)
+			delete(s.digests, token)
+			s.notifyCancellation(token)
+		}
+	}
+
+	return stale
+}
+
+func (s *Server) handleStaleJob(token, jobId uuid.UUID) error {
+	logger := s.jobLoggerByID(jobId)
+
+	if s.config.ArtifactsDir != "" {
+		err := os.RemoveAll(path.Join(s.config.ArtifactsDir, "tmp", token.String()))
+		if err != nil {
+			logger.Errorf("Error removing artifacts of stale job: %v", err)
+		}
+	}
+
+	var err error
+	if s.config.FailStaleJobs {
+		logger.Warnf("Job is stale, failing it")
+		err = s.failJob(jobId, "worker stopped sending heartbeats")
+	} else {
+		var deadLettered bool
+		deadLettered, err = s.jobs.RequeueJob(jobId, s.config.MaxJobFailures)
+		if err == nil && deadLettered {
+			logger.Warnf("Job is stale and failed too often, moved it to the dead-letter queue")
+		} else if err == nil {
+			logger.Warnf("Job is stale, requeued it")
+		}
+	}
+
+	// The job might have been canceled in the meantime
+	if err == jobqueue.ErrCanceled {
+		return nil
+	}
+	return err
+}
+
+// Finish the job with `id` with a failed result fitting its job type.
+func (s *Server) failJob(id uuid.UUID, reason string) error {
+	jobType, _, _, err := s.jobs.Job(id)
+	if err != nil {
+		return err
+	}
+
+	var result interface{}
+	switch strings.SplitN(jobType, ":", 2)[0] {
+	case "osbuild":
+		result = &OSBuildJobResult{
+			Success:      false,
+			TargetErrors: []string{reason},
+			UploadStatus: "failure",
+		}
+	case "osbuild-koji":
+		result = &OSBuildKojiJobResult{KojiError: reason}
+	case "koji-init":
+		result = &KojiInitJobResult{KojiError: reason}
+	case "koji-finalize":
+		result = &KojiFinalizeJobResult{KojiError: reason}
+	case "depsolve":
+		result = &DepsolveJobResult{Error: reason}
+	case "manifest-id-only":
+		result = &ManifestJobByIDResult{Error: reason}
+	case "upload":
+		result = &UploadJobResult{
+			TargetErrors: []string{reason},
+			UploadStatus: "failure",
+		}
+	case "compose":
+		result = &ComposeJobResult{}
+	default:
+		return fmt.Errorf("cannot fail job of unknown type %s", jobType)
+	}
+
+	return s.jobs.FinishJob(id, result)
+}
+
+// apiHandlers implements api.ServerInterface - the http api route handlers
+// generated from api/openapi.yml. This is a separate object, because these
+// handlers should not be exposed on the `Server` object.
+type apiHandlers struct {
+	server *Server
+}
+
+func (h *apiHandlers) GetStatus(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, &statusResponse{
 		Status: "OK",
+		Arches: h.server.Arches(),
 	})
 }
 
@@ -343,7 +1576,42 @@ func (h *apiHandlers) RequestJob(ctx echo.Context) error {
 		return err
 	}
 
-	token, jobId, jobType, jobArgs, dynamicJobArgs, err := h.server.RequestJob(ctx.Request().Context(), body.Arch, body.Types)
+	jobTypes := body.Types
+	if body.Arch == "" {
+		// osbuild jobs would be dequeued for an empty architecture,
+		// which no job is ever enqueued for
+		for _, t := range jobTypes {
+			if ArchJobType(t, "") != t {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("arch is required to request %s jobs", t))
+			}
+		}
+	}
+	if body.WorkerId != nil {
+		workerID, err := uuid.Parse(*body.WorkerId)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "cannot parse worker id")
+		}
+
+		// draining workers wait until they are handed jobs again, so
+		// that they don't exit and register anew
+		err = h.server.registry.waitWhileDraining(ctx.Request().Context(), workerID)
+		if err != nil {
+			return err
+		}
+
+		jobTypes, err = h.server.registry.compatibleJobTypes(workerID, body.Arch, body.Types)
+		switch err {
+		case nil:
+		case ErrWorkerNotExist:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case ErrIncompatibleWorker:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return err
+		}
+	}
+
+	token, jobId, jobType, jobArgs, dynamicJobArgs, err := h.server.RequestJob(ctx.Request().Context(), body.Arch, jobTypes)
 	if err != nil {
 		return err
 	}
@@ -353,14 +1621,12 @@ func (h *apiHandlers) RequestJob(ctx echo.Context) error {
 		basePath = api.CloudBasePath
 	}
 
-	return ctx.JSON(http.StatusCreated, requestJobResponse{
-		Id:               jobId,
-		Location:         fmt.Sprintf("%s/jobs/%v", basePath, token),
-		ArtifactLocation: fmt.Sprintf("%s/jobs/%v/artifacts/", basePath, token),
-		Type:             jobType,
-		Args:             jobArgs,
-		DynamicArgs:      dynamicJobArgs,
-	})
+	response, err := h.server.jobResponse(basePath+"/", token, jobId, jobType, jobArgs, dynamicJobArgs)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, response)
 }
 
 func (h *apiHandlers) GetJob(ctx echo.Context, tokenstr string) error {
@@ -369,6 +1635,10 @@ func (h *apiHandlers) GetJob(ctx echo.Context, tokenstr string) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "cannot parse job token")
 	}
 
+	if h.server.wasRevoked(token) {
+		return ctx.JSON(http.StatusOK, getJobResponse{Canceled: true})
+	}
+
 	jobId, err := h.server.RunningJob(token)
 	if err != nil {
 		switch err {
@@ -393,6 +1663,30 @@ func (h *apiHandlers) GetJob(ctx echo.Context, tokenstr string) error {
 	})
 }
 
+func (h *apiHandlers) WaitForCancellation(ctx echo.Context, tokenstr string) error {
+	token, err := uuid.Parse(tokenstr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot parse job token")
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx.Request().Context(), cancellationPollTimeout)
+	defer cancel()
+
+	canceled, err := h.server.WaitForCancellation(waitCtx, token)
+	if err != nil {
+		switch err {
+		case ErrTokenNotExist:
+			return echo.NewHTTPError(http.StatusNotFound, "not found")
+		default:
+			return err
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, waitForCancellationResponse{
+		Canceled: canceled,
+	})
+}
+
 func (h *apiHandlers) UpdateJob(ctx echo.Context, idstr string) error {
 	token, err := uuid.Parse(idstr)
 	if err != nil {
@@ -405,7 +1699,16 @@ func (h *apiHandlers) UpdateJob(ctx echo.Context, idstr string) error {
 		return err
 	}
 
-	err = h.server.FinishJob(token, body.Result)
+	if body.Status == "RUNNING" {
+		if body.Progress == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "progress is required for running jobs")
+		}
+		err = h.server.UpdateJobProgress(token, body.Progress)
+	} else if body.Status == "YIELDED" {
+		err = h.server.YieldJob(token, body.Reason)
+	} else {
+		err = h.server.FinishJob(token, body.Result)
+	}
 	if err != nil {
 		switch err {
 		case ErrTokenNotExist:
@@ -418,6 +1721,273 @@ func (h *apiHandlers) UpdateJob(ctx echo.Context, idstr string) error {
 	return ctx.JSON(http.StatusOK, updateJobResponse{})
 }
 
+func (h *apiHandlers) PostHeartbeat(ctx echo.Context, tokenstr string) error {
+	token, err := uuid.Parse(tokenstr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot parse job token")
+	}
+
+	err = h.server.Heartbeat(token)
+	if err != nil {
+		switch err {
+		case ErrTokenNotExist:
+			return echo.NewHTTPError(http.StatusNotFound, "not found")
+		default:
+			return err
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, heartbeatResponse{})
+}
+
+func (h *apiHandlers) AppendJobLog(ctx echo.Context, tokenstr string) error {
+	token, err := uuid.Parse(tokenstr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot parse job token")
+	}
+
+	chunk, err := ioutil.ReadAll(io.LimitReader(ctx.Request().Body, maxJobLogSize+1))
+	if err != nil {
+		return fmt.Errorf("error reading log: %v", err)
+	}
+
+	err = h.server.AppendJobLog(token, chunk)
+	if err != nil {
+		switch err {
+		case ErrTokenNotExist:
+			return echo.NewHTTPError(http.StatusNotFound, "not found")
+		case ErrJobLogTooLarge:
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err.Error())
+		default:
+			return err
+		}
+	}
+
+	return ctx.NoContent(http.StatusOK)
+}
+
+func (h *apiHandlers) RegisterWorker(ctx echo.Context) error {
+	var body api.RegisterWorkerJSONRequestBody
+	err := ctx.Bind(&body)
+	if err != nil {
+		return err
+	}
+
+	if body.Arch == "" || len(body.JobTypes) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "arch and job_types are required")
+	}
+
+	info := WorkerInfo{
+		Arch:     body.Arch,
+		JobTypes: body.JobTypes,
+	}
+	if body.Hostname != nil {
+		info.Hostname = *body.Hostname
+	}
+	if body.FreeDisk != nil {
+		if *body.FreeDisk < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "free_disk must not be negative")
+		}
+		info.FreeDisk = uint64(*body.FreeDisk)
+	}
+	if body.OsbuildVersion != nil {
+		info.OSBuildVersion = *body.OsbuildVersion
+	}
+	if identity, ok := ctx.Get("WorkerIdentity").(string); ok {
+		info.Identity = identity
+	}
+
+	return ctx.JSON(http.StatusCreated, registerWorkerResponse{
+		Id: h.server.RegisterWorker(info),
+	})
+}
+
+func (h *apiHandlers) GetWorkers(ctx echo.Context) error {
+	response := getWorkersResponse{
+		Workers: []workerResponse{},
+	}
+	for _, w := range h.server.Workers() {
+		response.Workers = append(response.Workers, workerResponse{
+			Id:             w.Id,
+			Hostname:       w.Hostname,
+			Arch:           w.Arch,
+			JobTypes:       w.JobTypes,
+			FreeDisk:       w.FreeDisk,
+			OSBuildVersion: w.OSBuildVersion,
+			Identity:       w.Identity,
+			RegisteredAt:   w.RegisteredAt,
+			LastSeen:       w.LastSeen,
+			Draining:       w.Draining,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *apiHandlers) GetDeadLetterJobs(ctx echo.Context) error {
+	ids, err := h.server.DeadLetterJobs()
+	if err != nil {
+		return err
+	}
+
+	response := deadLetterJobsResponse{
+		Jobs: []deadLetterJob{},
+	}
+	for _, id := range ids {
+		jobType, _, _, err := h.server.jobs.Job(id)
+		if err != nil {
+			return err
+		}
+		response.Jobs = append(response.Jobs, deadLetterJob{Id: id, Type: jobType})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *apiHandlers) RequeueDeadLetterJob(ctx echo.Context, idstr string) error {
+	id, err := uuid.Parse(idstr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot parse job id")
+	}
+
+	err = h.server.RequeueDeadLetterJob(id)
+	if err != nil {
+		switch err {
+		case jobqueue.ErrNotExist:
+			return echo.NewHTTPError(http.StatusNotFound, "not found")
+		case jobqueue.ErrNotDeadLettered, jobqueue.ErrCanceled:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return err
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, requeueDeadLetterJobResponse{})
+}
+
+func (h *apiHandlers) GetQueuedJobs(ctx echo.Context) error {
+	jobs, err := h.server.QueuedJobs()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	response := queuedJobsResponse{
+		Jobs: []queuedJob{},
+	}
+	for _, j := range jobs {
+		qj := queuedJob{
+			Id:       j.Id,
+			Type:     j.Type,
+			Tenant:   j.Tenant,
+			Status:   "pending",
+			QueuedAt: j.QueuedAt,
+			Age:      now.Sub(j.QueuedAt).Seconds(),
+		}
+		if !j.StartedAt.IsZero() {
+			started := j.StartedAt
+			qj.Status = "running"
+			qj.StartedAt = &started
+		}
+		response.Jobs = append(response.Jobs, qj)
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *apiHandlers) AdminRequeueJob(ctx echo.Context, idstr string) error {
+	id, err := uuid.Parse(idstr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot parse job id")
+	}
+
+	err = h.server.RequeueRunningJob(id)
+	if err != nil {
+		switch err {
+		case jobqueue.ErrNotExist:
+			return echo.NewHTTPError(http.StatusNotFound, "not found")
+		case jobqueue.ErrNotRunning, jobqueue.ErrCanceled:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return err
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, requeueJobResponse{})
+}
+
+func (h *apiHandlers) AdminCancelJob(ctx echo.Context, idstr string) error {
+	id, err := uuid.Parse(idstr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot parse job id")
+	}
+
+	err = h.server.Cancel(id)
+	if err != nil {
+		switch err {
+		case jobqueue.ErrNotExist:
+			return echo.NewHTTPError(http.StatusNotFound, "not found")
+		default:
+			return err
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, cancelJobResponse{})
+}
+
+func (h *apiHandlers) GetQueueStats(ctx echo.Context) error {
+	stats, err := h.server.QueueStats()
+	if err != nil {
+		return err
+	}
+
+	response := queueStatsResponse{
+		Pending:         stats.Pending,
+		Running:         stats.Running,
+		DeadLettered:    stats.DeadLettered,
+		JobTypes:        []jobTypeStats{},
+		Workers:         stats.Workers,
+		DrainingWorkers: stats.DrainingWorkers,
+	}
+	if !stats.OldestPending.IsZero() {
+		age := time.Since(stats.OldestPending).Seconds()
+		response.OldestPendingAge = &age
+	}
+	for _, t := range stats.jobTypes() {
+		response.JobTypes = append(response.JobTypes, jobTypeStats{
+			Type:    t,
+			Pending: stats.PendingByType[t],
+			Running: stats.RunningByType[t],
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *apiHandlers) DrainWorker(ctx echo.Context, idstr string) error {
+	return h.drainWorker(ctx, idstr, true)
+}
+
+func (h *apiHandlers) UndrainWorker(ctx echo.Context, idstr string) error {
+	return h.drainWorker(ctx, idstr, false)
+}
+
+func (h *apiHandlers) drainWorker(ctx echo.Context, idstr string, drain bool) error {
+	id, err := uuid.Parse(idstr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot parse worker id")
+	}
+
+	err = h.server.DrainWorker(id, drain)
+	if err == ErrWorkerNotExist {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, drainWorkerResponse{})
+}
+
 func (h *apiHandlers) UploadJobArtifact(ctx echo.Context, tokenstr string, name string) error {
 	token, err := uuid.Parse(tokenstr)
 	if err != nil {
@@ -426,27 +1996,186 @@ func (h *apiHandlers) UploadJobArtifact(ctx echo.Context, tokenstr string, name
 
 	request := ctx.Request()
 
-	if h.server.artifactsDir == "" {
-		_, err := io.Copy(ioutil.Discard, request.Body)
+	if h.server.config.ArtifactsDir == "" {
+		n, err := io.Copy(ioutil.Discard, request.Body)
+		h.server.metrics.artifactUploadBytes.Add(float64(n))
 		if err != nil {
 			return fmt.Errorf("error discarding artifact: %v", err)
 		}
 		return ctx.NoContent(http.StatusOK)
 	}
 
-	f, err := os.Create(path.Join(h.server.artifactsDir, "tmp", token.String(), name))
+	if !validArtifactName(name) || strings.HasSuffix(name, artifactDigestSuffix) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid artifact name")
+	}
+
+	p := path.Join(h.server.config.ArtifactsDir, "tmp", token.String(), name)
+
+	contentRange := request.Header.Get("Content-Range")
+	if contentRange == "" {
+		f, err := os.Create(p)
+		if err != nil {
+			return fmt.Errorf("cannot create artifact file: %v", err)
+		}
+		defer f.Close()
+
+		digest := sha256.New()
+		n, err := io.Copy(io.MultiWriter(f, digest), request.Body)
+		h.server.metrics.artifactUploadBytes.Add(float64(n))
+		if err != nil {
+			return fmt.Errorf("error writing artifact file: %v", err)
+		}
+
+		err = writeArtifactDigest(p, digest.Sum(nil))
+		if err != nil {
+			return err
+		}
+
+		return ctx.NoContent(http.StatusOK)
+	}
+
+	// The artifact is uploaded in chunks. Each chunk must either continue
+	// the already uploaded part of the artifact or overwrite a part of it,
+	// which happens when a worker retries a chunk that failed half-way.
+	start, end, size, err := parseContentRange(contentRange)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open artifact file: %v", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("cannot stat artifact file: %v", err)
+	}
+
+	if start > info.Size() {
+		return echo.NewHTTPError(http.StatusRequestedRangeNotSatisfiable, fmt.Sprintf("chunk starts at %d, but only %d bytes have been uploaded", start, info.Size()))
+	}
+
+	err = f.Truncate(start)
+	if err != nil {
+		return fmt.Errorf("error truncating artifact file: %v", err)
+	}
+
+	_, err = f.Seek(start, io.SeekStart)
 	if err != nil {
-		return fmt.Errorf("cannot create artifact file: %v", err)
+		return fmt.Errorf("error seeking in artifact file: %v", err)
+	}
+
+	// The digest of the artifact is computed while its chunks arrive in
+	// order. It is computed from the file once the last chunk arrived if
+	// a chunk was overwritten.
+	digest := h.server.chunkDigest(token, name, start)
+	w := io.Writer(f)
+	if digest != nil {
+		w = io.MultiWriter(f, digest.hash)
 	}
 
-	_, err = io.Copy(f, request.Body)
+	n, err := io.Copy(w, io.LimitReader(request.Body, end-start+1))
+	h.server.metrics.artifactUploadBytes.Add(float64(n))
 	if err != nil {
+		h.server.forgetChunkDigest(token, name)
 		return fmt.Errorf("error writing artifact file: %v", err)
 	}
+	if n != end-start+1 {
+		h.server.forgetChunkDigest(token, name)
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("chunk is %d bytes long, but Content-Range specifies %d", n, end-start+1))
+	}
+	if digest != nil {
+		digest.offset += n
+	}
 
+	if size == end+1 {
+		var sum []byte
+		if digest != nil && digest.offset == size {
+			sum = digest.hash.Sum(nil)
+		} else {
+			sum, err = fileDigest(p)
+			if err != nil {
+				return fmt.Errorf("error computing digest of artifact file: %v", err)
+			}
+		}
+		h.server.forgetChunkDigest(token, name)
+		err = writeArtifactDigest(p, sum)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx.Response().Header().Set("Range", fmt.Sprintf("bytes=0-%d", end))
 	return ctx.NoContent(http.StatusOK)
 }
 
+func (h *apiHandlers) GetDependencyArtifact(ctx echo.Context, tokenstr string, index int, name string) error {
+	token, err := uuid.Parse(tokenstr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot parse job token")
+	}
+
+	jobId, err := h.server.RunningJob(token)
+	if err != nil {
+		switch err {
+		case ErrTokenNotExist:
+			return echo.NewHTTPError(http.StatusNotFound, "not found")
+		default:
+			return err
+		}
+	}
+	if jobId == uuid.Nil {
+		return echo.NewHTTPError(http.StatusNotFound, "not found")
+	}
+
+	_, _, deps, err := h.server.Job(jobId, &json.RawMessage{})
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(deps) {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("job has no dependency %d", index))
+	}
+
+	reader, size, err := h.server.JobArtifact(deps[index], name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	ctx.Response().Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	return ctx.Stream(http.StatusOK, "application/octet-stream", reader)
+}
+
+// Parses a Content-Range header of the form "bytes <start>-<end>/<total>",
+// where total may be "*" when the size of the whole artifact is not yet known.
+// Returns the start, end, and total, which is -1 if it is not known.
+func parseContentRange(header string) (int64, int64, int64, error) {
+	var start, end int64
+	var total string
+	_, err := fmt.Sscanf(header, "bytes %d-%d/%s", &start, &end, &total)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("cannot parse Content-Range header: %s", header)
+	}
+
+	if start < 0 || end < start {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range header: %s", header)
+	}
+
+	size := int64(-1)
+	if total != "*" {
+		size, err = strconv.ParseInt(total, 10, 64)
+		if err != nil || end >= size {
+			return 0, 0, 0, fmt.Errorf("invalid Content-Range header: %s", header)
+		}
+	}
+
+	return start, end, size, nil
+}
+
 // A simple echo.Binder(), which only accepts application/json, but is more
 // strict than echo's DefaultBinder. It does not handle binding query
 // parameters either.
@@ -2,23 +2,40 @@ package worker_test
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/distro/test_distro"
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
 	"github.com/osbuild/osbuild-composer/internal/jobqueue/fsjobqueue"
+	"github.com/osbuild/osbuild-composer/internal/oidc"
+	"github.com/osbuild/osbuild-composer/internal/ratelimit"
+	"github.com/osbuild/osbuild-composer/internal/rbac"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/sentry"
+	"github.com/osbuild/osbuild-composer/internal/target"
 	"github.com/osbuild/osbuild-composer/internal/test"
+	"github.com/osbuild/osbuild-composer/internal/tracing"
 	"github.com/osbuild/osbuild-composer/internal/worker"
 )
 
@@ -27,9 +44,13 @@ func newTestServer(t *testing.T, tempdir string, identities []string) *worker.Se
 	if err != nil {
 		t.Fatalf("error creating fsjobqueue: %v", err)
 	}
-	return worker.NewServer(nil, q, "", identities)
+	return worker.NewServer(nil, q, worker.Config{IdentityFilter: identities})
 }
 
+// Policy which grants all roles to everyone, because the routes for
+// administrators only exist when there is a policy
+var adminPolicy = &rbac.Policy{Default: rbac.Roles{rbac.Worker, rbac.Admin}}
+
 // Ensure that the status request returns OK.
 func TestStatus(t *testing.T) {
 	tempdir, err := ioutil.TempDir("", "worker-tests-")
@@ -38,7 +59,7 @@ func TestStatus(t *testing.T) {
 
 	server := newTestServer(t, tempdir, []string{})
 	handler := server.Handler()
-	test.TestRoute(t, handler, false, "GET", "/api/worker/v1/status", ``, http.StatusOK, `{"status":"OK"}`, "message")
+	test.TestRoute(t, handler, false, "GET", "/api/worker/v1/status", ``, http.StatusOK, `{"status":"OK","arches":[]}`, "message")
 }
 
 func TestErrors(t *testing.T) {
@@ -94,12 +115,12 @@ func TestCreate(t *testing.T) {
 	server := newTestServer(t, tempdir, []string{})
 	handler := server.Handler()
 
-	_, err = server.EnqueueOSBuild(arch.Name(), &worker.OSBuildJob{Manifest: manifest})
+	_, err = server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
 	require.NoError(t, err)
 
 	test.TestRoute(t, handler, false, "POST", "/api/worker/v1/jobs",
 		fmt.Sprintf(`{"types":["osbuild"],"arch":"%s"}`, test_distro.TestArchName), http.StatusCreated,
-		`{"type":"osbuild","args":{"manifest":{"pipeline":{},"sources":{}}}}`, "id", "location", "artifact_location")
+		`{"type":"osbuild","args":{"manifest":{"pipeline":{},"sources":{}},"priority_class":"batch"}}`, "id", "location", "artifact_location")
 }
 
 func TestCancel(t *testing.T) {
@@ -123,7 +144,7 @@ func TestCancel(t *testing.T) {
 	server := newTestServer(t, tempdir, []string{})
 	handler := server.Handler()
 
-	jobId, err := server.EnqueueOSBuild(arch.Name(), &worker.OSBuildJob{Manifest: manifest})
+	jobId, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
 	require.NoError(t, err)
 
 	token, j, typ, args, dynamicArgs, err := server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
@@ -143,6 +164,53 @@ func TestCancel(t *testing.T) {
 		`{"canceled":true}`)
 }
 
+func TestWaitForCancellation(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	server := newTestServer(t, tempdir, []string{})
+	handler := server.Handler()
+
+	jobId, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+
+	token, _, _, _, _, err := server.RequestJob(context.Background(), "", []string{"koji-init"})
+	require.NoError(t, err)
+
+	_, err = server.WaitForCancellation(context.Background(), uuid.New())
+	require.Equal(t, worker.ErrTokenNotExist, err)
+
+	// returns when the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	canceled, err := server.WaitForCancellation(ctx, token)
+	require.NoError(t, err)
+	require.False(t, canceled)
+
+	// returns as soon as the job is canceled
+	result := make(chan bool)
+	go func() {
+		canceled, err := server.WaitForCancellation(context.Background(), token)
+		require.NoError(t, err)
+		result <- canceled
+	}()
+
+	require.NoError(t, server.Cancel(jobId))
+
+	select {
+	case canceled := <-result:
+		require.True(t, canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForCancellation did not return after the job was canceled")
+	}
+
+	test.TestRoute(t, handler, false, "GET", fmt.Sprintf("/api/worker/v1/jobs/%s/cancellation", token), ``, http.StatusOK,
+		`{"canceled":true}`)
+	test.TestRoute(t, handler, false, "GET", fmt.Sprintf("/api/worker/v1/jobs/%s/cancellation", uuid.New()), ``, http.StatusNotFound,
+		`{"message":"not found"}`)
+}
+
 func TestUpdate(t *testing.T) {
 	tempdir, err := ioutil.TempDir("", "worker-tests-")
 	require.NoError(t, err)
@@ -164,7 +232,7 @@ func TestUpdate(t *testing.T) {
 	server := newTestServer(t, tempdir, []string{})
 	handler := server.Handler()
 
-	jobId, err := server.EnqueueOSBuild(arch.Name(), &worker.OSBuildJob{Manifest: manifest})
+	jobId, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
 	require.NoError(t, err)
 
 	token, j, typ, args, dynamicArgs, err := server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
@@ -196,7 +264,7 @@ func TestArgs(t *testing.T) {
 		Manifest:  manifest,
 		ImageName: "test-image",
 	}
-	jobId, err := server.EnqueueOSBuild(arch.Name(), &job)
+	jobId, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &job, worker.PriorityBatch, "")
 	require.NoError(t, err)
 
 	_, _, _, args, _, err := server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
@@ -212,6 +280,266 @@ func TestArgs(t *testing.T) {
 	require.Equal(t, []uuid.UUID(nil), deps)
 }
 
+func TestPriorityClasses(t *testing.T) {
+	distroStruct := test_distro.New()
+	arch, err := distroStruct.GetArch(test_distro.TestArchName)
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType(test_distro.TestImageTypeName)
+	require.NoError(t, err)
+	manifest, err := imageType.Manifest(nil, distro.ImageOptions{Size: imageType.Size(0)}, nil, nil, 0)
+	require.NoError(t, err)
+
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+	server := newTestServer(t, tempdir, []string{})
+
+	_, err = server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, "nonexistent", "")
+	require.Equal(t, worker.ErrInvalidPriorityClass, err)
+
+	batchID, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	interactiveID, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityInteractive, "")
+	require.NoError(t, err)
+
+	_, j, _, _, _, err := server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
+	require.NoError(t, err)
+	require.Equal(t, interactiveID, j)
+
+	_, j, _, _, _, err = server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
+	require.NoError(t, err)
+	require.Equal(t, batchID, j)
+}
+
+func TestTenantQuota(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{
+		TenantQuota: worker.TenantQuota{MaxConcurrent: 3, MaxQueued: 2},
+	})
+
+	enqueue := func(tenant string) (uuid.UUID, error) {
+		return server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, tenant)
+	}
+
+	first, err := enqueue("000000")
+	require.NoError(t, err)
+	second, err := enqueue("000000")
+	require.NoError(t, err)
+	_, err = enqueue("000000")
+	require.Equal(t, worker.ErrQuotaExceeded, err)
+
+	// running jobs don't count against the queued quota...
+	token, j, _, _, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+	require.NoError(t, err)
+	require.Equal(t, first, j)
+	_, err = enqueue("000000")
+	require.NoError(t, err)
+
+	// ...but against the concurrent one
+	_, err = enqueue("000000")
+	require.Equal(t, worker.ErrQuotaExceeded, err)
+
+	// canceled jobs don't count
+	require.NoError(t, server.Cancel(second))
+	_, err = enqueue("000000")
+	require.NoError(t, err)
+
+	// finishing the running job frees a concurrent slot, but two jobs
+	// are still queued
+	require.NoError(t, server.FinishJob(token, json.RawMessage(`{}`)))
+	_, err = enqueue("000000")
+	require.Equal(t, worker.ErrQuotaExceeded, err)
+
+	_, _, _, _, _, err = server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+	require.NoError(t, err)
+	_, err = enqueue("000000")
+	require.NoError(t, err)
+	_, err = enqueue("000000")
+	require.Equal(t, worker.ErrQuotaExceeded, err)
+
+	// other tenants, and jobs without a tenant, are not affected
+	_, err = enqueue("000001")
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err = enqueue("")
+		require.NoError(t, err)
+	}
+
+	// the jobs are counted in the queue, so that they still count after
+	// a restart
+	server.Close()
+	q, err = fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server = worker.NewServer(nil, q, worker.Config{
+		TenantQuota: worker.TenantQuota{MaxConcurrent: 3, MaxQueued: 2},
+	})
+	defer server.Close()
+	_, err = enqueue("000000")
+	require.Equal(t, worker.ErrQuotaExceeded, err)
+	_, err = enqueue("000001")
+	require.NoError(t, err)
+}
+
+func TestFairScheduling(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{Scheduling: worker.SchedulingFair})
+
+	enqueue := func(tenant string) uuid.UUID {
+		id, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityInteractive, tenant)
+		require.NoError(t, err)
+		return id
+	}
+
+	a1 := enqueue("blueprint:a")
+	a2 := enqueue("blueprint:a")
+	a3 := enqueue("blueprint:a")
+	b1 := enqueue("blueprint:b")
+	b2 := enqueue("blueprint:b")
+	c1 := enqueue("blueprint:c")
+
+	// jobs of the same tenant keep their order, but are interleaved
+	// with the ones of other tenants
+	for _, expected := range []uuid.UUID{a1, b1, c1, a2, b2, a3} {
+		_, j, _, _, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+		require.NoError(t, err)
+		require.Equal(t, expected, j)
+	}
+}
+
+func TestFairSchedulingPriorityClasses(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{Scheduling: worker.SchedulingFair})
+
+	var interactive []uuid.UUID
+	for i := 0; i < 15; i++ {
+		id, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityInteractive, "blueprint:a")
+		require.NoError(t, err)
+		interactive = append(interactive, id)
+	}
+	batch, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "blueprint:b")
+	require.NoError(t, err)
+
+	// a tenant with many queued jobs never falls behind a lower priority
+	// class
+	for _, expected := range append(interactive, batch) {
+		_, j, _, _, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+		require.NoError(t, err)
+		require.Equal(t, expected, j)
+	}
+}
+
+func TestMaxConcurrentBuilds(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{MaxConcurrentBuilds: 1})
+
+	first, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	second, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	initID, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+
+	token, j, _, _, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+	require.NoError(t, err)
+	require.Equal(t, first, j)
+
+	// no other build is handed out while the first one is running...
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, _, _, _, err = server.RequestJob(ctx, "x86_64", []string{"osbuild"})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// ...but jobs of other types are
+	_, j, _, _, _, err = server.RequestJob(context.Background(), "x86_64", []string{"osbuild", "koji-init"})
+	require.NoError(t, err)
+	require.Equal(t, initID, j)
+
+	// a waiting worker receives the next build once the first one finished
+	next := make(chan uuid.UUID)
+	go func() {
+		_, j, _, _, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild", "koji-init"})
+		assert.NoError(t, err)
+		next <- j
+	}()
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, server.FinishJob(token, json.RawMessage(`{}`)))
+	select {
+	case j = <-next:
+		require.Equal(t, second, j)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second build was not handed out")
+	}
+}
+
+func TestMaxConcurrentBuildsRace(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{MaxConcurrentBuilds: 1})
+
+	var ids []uuid.UUID
+	for i := 0; i < 2; i++ {
+		id, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	// of two concurrent requests, only one gets a build, and the other
+	// one doesn't take the second build out of the queue while waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, _, _, _, _, err := server.RequestJob(ctx, "x86_64", []string{"osbuild"})
+			results <- err
+		}()
+	}
+	select {
+	case err := <-results:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no build was handed out")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	var pending int
+	for _, id := range ids {
+		_, _, started, _, _, _, err := q.JobStatus(id)
+		require.NoError(t, err)
+		if started.IsZero() {
+			pending++
+		}
+	}
+	require.Equal(t, 1, pending)
+
+	cancel()
+	require.Equal(t, context.Canceled, <-results)
+}
+
 func TestUpload(t *testing.T) {
 	tempdir, err := ioutil.TempDir("", "worker-tests-")
 	require.NoError(t, err)
@@ -233,7 +561,7 @@ func TestUpload(t *testing.T) {
 	server := newTestServer(t, tempdir, []string{})
 	handler := server.Handler()
 
-	jobID, err := server.EnqueueOSBuild(arch.Name(), &worker.OSBuildJob{Manifest: manifest})
+	jobID, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
 	require.NoError(t, err)
 
 	token, j, typ, args, dynamicArgs, err := server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
@@ -246,6 +574,203 @@ func TestUpload(t *testing.T) {
 	test.TestRoute(t, handler, false, "PUT", fmt.Sprintf("/api/worker/v1/jobs/%s/artifacts/foobar", token), `this is my artifact`, http.StatusOK, `?`)
 }
 
+func TestChunkedUpload(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{ArtifactsDir: tempdir})
+	handler := server.Handler()
+
+	jobId, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+
+	token, _, _, _, _, err := server.RequestJob(context.Background(), "", []string{"koji-init"})
+	require.NoError(t, err)
+
+	path := fmt.Sprintf("/api/worker/v1/jobs/%s/artifacts/foobar", token)
+	upload := func(body, contentRange string) *http.Response {
+		return test.SendHTTPWithHeader(handler, "PUT", path, body, map[string]string{
+			"Content-Type":  "application/octet-stream",
+			"Content-Range": contentRange,
+		})
+	}
+
+	response := upload("this is ", "bytes 0-7/*")
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "bytes=0-7", response.Header.Get("Range"))
+
+	// chunks must not leave a gap
+	response = upload("artifact", "bytes 11-18/19")
+	require.Equal(t, http.StatusRequestedRangeNotSatisfiable, response.StatusCode)
+
+	// a chunk which is shorter than announced is rejected...
+	response = upload("my", "bytes 8-10/*")
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// ...but can be retried
+	response = upload("my ", "bytes 8-10/*")
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	response = upload("artifact", "bytes 11-18/19")
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "bytes=0-18", response.Header.Get("Range"))
+
+	response = upload("artifact", "bytes 11-18/10")
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	require.NoError(t, server.FinishJob(token, json.RawMessage(`{}`)))
+
+	reader, size, err := server.JobArtifact(jobId, "foobar")
+	require.NoError(t, err)
+	require.Equal(t, int64(19), size)
+	contents, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "this is my artifact", string(contents))
+
+	// the digest is computed from the file, because a chunk was retried
+	digest := sha256.Sum256([]byte("this is my artifact"))
+	artifacts, err := server.JobArtifacts(jobId)
+	require.NoError(t, err)
+	require.Equal(t, []worker.ArtifactInfo{{Name: "foobar", Size: 19, SHA256: hex.EncodeToString(digest[:])}}, artifacts)
+}
+
+func TestArtifactDigests(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{ArtifactsDir: tempdir})
+	handler := server.Handler()
+
+	jobId, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+	token, _, _, _, _, err := server.RequestJob(context.Background(), "", []string{"koji-init"})
+	require.NoError(t, err)
+
+	upload := func(name, body, contentRange string) {
+		headers := map[string]string{"Content-Type": "application/octet-stream"}
+		if contentRange != "" {
+			headers["Content-Range"] = contentRange
+		}
+		path := fmt.Sprintf("/api/worker/v1/jobs/%s/artifacts/%s", token, name)
+		response := test.SendHTTPWithHeader(handler, "PUT", path, body, headers)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	}
+	upload("chunked", "first ", "bytes 0-5/*")
+	upload("chunked", "second", "bytes 6-11/12")
+	upload("whole", "contents", "")
+
+	// digests are reserved for the server
+	response := test.SendHTTPWithHeader(handler, "PUT", fmt.Sprintf("/api/worker/v1/jobs/%s/artifacts/whole.sha256", token), "", map[string]string{"Content-Type": "application/octet-stream"})
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	require.NoError(t, server.FinishJob(token, json.RawMessage(`{}`)))
+
+	chunked := sha256.Sum256([]byte("first second"))
+	whole := sha256.Sum256([]byte("contents"))
+	artifacts, err := server.JobArtifacts(jobId)
+	require.NoError(t, err)
+	require.Equal(t, []worker.ArtifactInfo{
+		{Name: "chunked", Size: 12, SHA256: hex.EncodeToString(chunked[:])},
+		{Name: "whole", Size: 8, SHA256: hex.EncodeToString(whole[:])},
+	}, artifacts)
+
+	// the digests are kept next to the artifacts, for sha256sum
+	sidecar, err := ioutil.ReadFile(path.Join(tempdir, jobId.String(), "whole.sha256"))
+	require.NoError(t, err)
+	require.Equal(t, hex.EncodeToString(whole[:])+"  whole\n", string(sidecar))
+}
+
+func TestUploadRetry(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{ArtifactsDir: tempdir})
+	handler := server.Handler()
+
+	// fail the first upload attempt with a server error
+	failed := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" && !failed {
+			failed = true
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, err := w.Write([]byte(`{"message":"try again"}`))
+			require.NoError(t, err)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	jobId, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+
+	client, err := worker.NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+	job, err := client.RequestJob([]string{"koji-init"}, "")
+	require.NoError(t, err)
+
+	require.NoError(t, job.UploadArtifact("some-artifact", strings.NewReader("artifact contents")))
+	require.True(t, failed)
+	require.NoError(t, job.Update(&worker.KojiInitJobResult{}))
+
+	reader, _, err := server.JobArtifact(jobId, "some-artifact")
+	require.NoError(t, err)
+	contents, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "artifact contents", string(contents))
+}
+
+func TestMetrics(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	server := newTestServer(t, tempdir, []string{"000000"})
+	handler := server.Handler()
+
+	distroStruct := test_distro.New()
+	arch, err := distroStruct.GetArch(test_distro.TestArchName)
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType(test_distro.TestImageTypeName)
+	require.NoError(t, err)
+	manifest, err := imageType.Manifest(nil, distro.ImageOptions{Size: imageType.Size(0)}, nil, nil, 0)
+	require.NoError(t, err)
+
+	_, err = server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest, ImageType: imageType.Name()}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+
+	token, _, _, _, _, err := server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
+	require.NoError(t, err)
+	require.NoError(t, server.FinishJob(token, json.RawMessage(`{}`)))
+
+	// rejected by the identity filter
+	response := test.SendHTTP(handler, false, "GET", "/api/worker/v1/status", ``)
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	// /metrics is not subject to the identity filter
+	response = test.SendHTTP(handler, false, "GET", "/metrics", ``)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	body, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	metrics := string(body)
+	require.Contains(t, metrics, `composer_jobs_enqueued_total{type="osbuild"} 1`)
+	require.Contains(t, metrics, `composer_job_wait_duration_seconds_count{type="osbuild"} 1`)
+	require.Contains(t, metrics, fmt.Sprintf(`composer_build_duration_seconds_count{image_type="%s"} 1`, imageType.Name()))
+	require.Contains(t, metrics, `composer_artifact_upload_bytes_total 0`)
+	require.Contains(t, metrics, `composer_auth_failures_total 1`)
+}
+
 func TestIdentities(t *testing.T) {
 	tempdir, err := ioutil.TempDir("", "worker-tests-")
 	require.NoError(t, err)
@@ -262,7 +787,7 @@ func TestIdentities(t *testing.T) {
 	server := newTestServer(t, tempdir, []string{"000000"})
 	handler := server.Handler()
 
-	// _, err := server.EnqueueOSBuild(arch.Name(), &worker.OSBuildJob{Manifest: manifest})
+	// _, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
 	// require.NoError(t, err)
 
 	test.TestRoute(t, handler, false, "GET", "/api/worker/v1/status", ``, http.StatusNotFound, `{"message":"Auth header is not present"}`, "message")
@@ -281,28 +806,65 @@ func TestIdentities(t *testing.T) {
 	assert.Equal(t, 200, response.StatusCode, "status mismatch")
 }
 
-func TestOAuth(t *testing.T) {
+func TestRateLimit(t *testing.T) {
 	tempdir, err := ioutil.TempDir("", "worker-tests-")
 	require.NoError(t, err)
 	defer os.RemoveAll(tempdir)
 
 	q, err := fsjobqueue.New(tempdir)
 	require.NoError(t, err)
-	workerServer := worker.NewServer(nil, q, tempdir, []string{"000000"})
-	handler := workerServer.Handler()
+	server := worker.NewServer(nil, q, worker.Config{
+		IdentityFilter: []string{"000000"},
+		RateLimit:      ratelimit.Config{Rate: 0.01, Burst: 1},
+	})
+	handler := server.Handler()
 
-	workSrv := httptest.NewServer(handler)
-	defer workSrv.Close()
+	header := map[string]string{
+		"x-rh-identity": "eyJlbnRpdGxlbWVudHMiOnsiaW5zaWdodHMiOnsiaXNfZW50aXRsZWQiOnRydWV9LCJzbWFydF9tYW5hZ2VtZW50Ijp7ImlzX2VudGl0bGVkIjp0cnVlfSwib3BlbnNoaWZ0Ijp7ImlzX2VudGl0bGVkIjp0cnVlfSwiaHlicmlkIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwibWlncmF0aW9ucyI6eyJpc19lbnRpdGxlZCI6dHJ1ZX0sImFuc2libGUiOnsiaXNfZW50aXRsZWQiOnRydWV9fSwiaWRlbnRpdHkiOnsiYWNjb3VudF9udW1iZXIiOiIwMDAwMDAiLCJ0eXBlIjoiVXNlciIsInVzZXIiOnsidXNlcm5hbWUiOiJ1c2VyIiwiZW1haWwiOiJ1c2VyQHVzZXIudXNlciIsImZpcnN0X25hbWUiOiJ1c2VyIiwibGFzdF9uYW1lIjoidXNlciIsImlzX2FjdGl2ZSI6dHJ1ZSwiaXNfb3JnX2FkbWluIjp0cnVlLCJpc19pbnRlcm5hbCI6dHJ1ZSwibG9jYWxlIjoiZW4tVVMifSwiaW50ZXJuYWwiOnsib3JnX2lkIjoiMDAwMDAwIn19fQ==",
+	}
+	response := test.SendHTTPWithHeader(handler, "GET", "/api/worker/v1/status", ``, header)
+	require.Equal(t, http.StatusOK, response.StatusCode)
 
-	/* Start a server which will act as a proxy, adding a valid identity header  */
-	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		require.Equal(t, "Bearer accessToken!", r.Header.Get("Authorization"))
-		r.Header.Set("x-rh-identity", "eyJlbnRpdGxlbWVudHMiOnsiaW5zaWdodHMiOnsiaXNfZW50aXRsZWQiOnRydWV9LCJzbWFydF9tYW5hZ2VtZW50Ijp7ImlzX2VudGl0bGVkIjp0cnVlfSwib3BlbnNoaWZ0Ijp7ImlzX2VudGl0bGVkIjp0cnVlfSwiaHlicmlkIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwibWlncmF0aW9ucyI6eyJpc19lbnRpdGxlZCI6dHJ1ZX0sImFuc2libGUiOnsiaXNfZW50aXRsZWQiOnRydWV9fSwiaWRlbnRpdHkiOnsiYWNjb3VudF9udW1iZXIiOiIwMDAwMDAiLCJ0eXBlIjoiVXNlciIsInVzZXIiOnsidXNlcm5hbWUiOiJ1c2VyIiwiZW1haWwiOiJ1c2VyQHVzZXIudXNlciIsImZpcnN0X25hbWUiOiJ1c2VyIiwibGFzdF9uYW1lIjoidXNlciIsImlzX2FjdGl2ZSI6dHJ1ZSwiaXNfb3JnX2FkbWluIjp0cnVlLCJpc19pbnRlcm5hbCI6dHJ1ZSwibG9jYWxlIjoiZW4tVVMifSwiaW50ZXJuYWwiOnsib3JnX2lkIjoiMDAwMDAwIn19fQ==")
-		handler.ServeHTTP(w, r)
-	}))
-	defer proxySrv.Close()
+	response = test.SendHTTPWithHeader(handler, "GET", "/api/worker/v1/status", ``, header)
+	require.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	require.Equal(t, "100", response.Header.Get("Retry-After"))
 
-	offlineToken := "someOfflineToken"
+	// requests without identity are rejected before they count
+	response = test.SendHTTP(handler, false, "GET", "/api/worker/v1/status", ``)
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	// /metrics is not rate limited
+	for i := 0; i < 2; i++ {
+		response = test.SendHTTP(handler, false, "GET", "/metrics", ``)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `composer_rate_limited_requests_total{api="worker"} 1`)
+}
+
+func TestOAuth(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	workerServer := worker.NewServer(nil, q, worker.Config{ArtifactsDir: tempdir, IdentityFilter: []string{"000000"}})
+	handler := workerServer.Handler()
+
+	workSrv := httptest.NewServer(handler)
+	defer workSrv.Close()
+
+	/* Start a server which will act as a proxy, adding a valid identity header  */
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer accessToken!", r.Header.Get("Authorization"))
+		r.Header.Set("x-rh-identity", "eyJlbnRpdGxlbWVudHMiOnsiaW5zaWdodHMiOnsiaXNfZW50aXRsZWQiOnRydWV9LCJzbWFydF9tYW5hZ2VtZW50Ijp7ImlzX2VudGl0bGVkIjp0cnVlfSwib3BlbnNoaWZ0Ijp7ImlzX2VudGl0bGVkIjp0cnVlfSwiaHlicmlkIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwibWlncmF0aW9ucyI6eyJpc19lbnRpdGxlZCI6dHJ1ZX0sImFuc2libGUiOnsiaXNfZW50aXRsZWQiOnRydWV9fSwiaWRlbnRpdHkiOnsiYWNjb3VudF9udW1iZXIiOiIwMDAwMDAiLCJ0eXBlIjoiVXNlciIsInVzZXIiOnsidXNlcm5hbWUiOiJ1c2VyIiwiZW1haWwiOiJ1c2VyQHVzZXIudXNlciIsImZpcnN0X25hbWUiOiJ1c2VyIiwibGFzdF9uYW1lIjoidXNlciIsImlzX2FjdGl2ZSI6dHJ1ZSwiaXNfb3JnX2FkbWluIjp0cnVlLCJpc19pbnRlcm5hbCI6dHJ1ZSwibG9jYWxlIjoiZW4tVVMifSwiaW50ZXJuYWwiOnsib3JnX2lkIjoiMDAwMDAwIn19fQ==")
+		handler.ServeHTTP(w, r)
+	}))
+	defer proxySrv.Close()
+
+	offlineToken := "someOfflineToken"
 	/* Start oauth srv supplying the bearer token */
 	oauthSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		require.Equal(t, "POST", r.Method)
@@ -342,7 +904,7 @@ func TestOAuth(t *testing.T) {
 		t.Fatalf("error creating osbuild manifest: %v", err)
 	}
 
-	_, err = workerServer.EnqueueOSBuild(arch.Name(), &worker.OSBuildJob{Manifest: manifest})
+	_, err = workerServer.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
 	require.NoError(t, err)
 
 	client, err := worker.NewClient(proxySrv.URL, nil, &offlineToken, &oauthSrv.URL)
@@ -354,3 +916,1299 @@ func TestOAuth(t *testing.T) {
 	c, err := job.Canceled()
 	require.False(t, c)
 }
+
+func TestHeartbeat(t *testing.T) {
+	distroStruct := test_distro.New()
+	arch, err := distroStruct.GetArch(test_distro.TestArchName)
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType(test_distro.TestImageTypeName)
+	require.NoError(t, err)
+	manifest, err := imageType.Manifest(nil, distro.ImageOptions{Size: imageType.Size(0)}, nil, nil, 0)
+	require.NoError(t, err)
+
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{HeartbeatTimeout: 100 * time.Millisecond})
+	handler := server.Handler()
+
+	jobId, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+
+	token, j, _, _, _, err := server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
+	require.NoError(t, err)
+	require.Equal(t, jobId, j)
+
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/jobs/%s/heartbeat", token), ``, http.StatusOK, `{}`)
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/jobs/%s/heartbeat", uuid.New()), ``, http.StatusNotFound, `*`)
+
+	// Without heartbeats, the job is requeued and the old token becomes invalid
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	newToken, j, _, _, _, err := server.RequestJob(ctx, arch.Name(), []string{"osbuild"})
+	require.NoError(t, err)
+	require.Equal(t, jobId, j)
+	require.NotEqual(t, token, newToken)
+
+	_, err = server.RunningJob(token)
+	require.Equal(t, worker.ErrTokenNotExist, err)
+}
+
+func TestHeartbeatAfterRestart(t *testing.T) {
+	distroStruct := test_distro.New()
+	arch, err := distroStruct.GetArch(test_distro.TestArchName)
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType(test_distro.TestImageTypeName)
+	require.NoError(t, err)
+	manifest, err := imageType.Manifest(nil, distro.ImageOptions{Size: imageType.Size(0)}, nil, nil, 0)
+	require.NoError(t, err)
+
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{InstanceID: "one", HeartbeatTimeout: 100 * time.Millisecond})
+
+	jobId, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+
+	token, j, _, _, _, err := server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
+	require.NoError(t, err)
+	require.Equal(t, jobId, j)
+	server.Close()
+
+	// Another instance sharing the queue leaves the job to the one which
+	// handed it out
+	q, err = fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server = worker.NewServer(nil, q, worker.Config{InstanceID: "two", HeartbeatTimeout: 100 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	_, _, _, _, _, err = server.RequestJob(ctx, arch.Name(), []string{"osbuild"})
+	cancel()
+	require.Equal(t, context.DeadlineExceeded, err)
+	server.Close()
+
+	// A restarted instance doesn't know the old token, but still requeues
+	// its job once its heartbeat timeout has passed
+	q, err = fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server = worker.NewServer(nil, q, worker.Config{InstanceID: "one", HeartbeatTimeout: 100 * time.Millisecond})
+	defer server.Close()
+
+	_, err = server.RunningJob(token)
+	require.Equal(t, worker.ErrTokenNotExist, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, j, _, _, _, err = server.RequestJob(ctx, arch.Name(), []string{"osbuild"})
+	require.NoError(t, err)
+	require.Equal(t, jobId, j)
+}
+
+func TestJobProgress(t *testing.T) {
+	distroStruct := test_distro.New()
+	arch, err := distroStruct.GetArch(test_distro.TestArchName)
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType(test_distro.TestImageTypeName)
+	require.NoError(t, err)
+	manifest, err := imageType.Manifest(nil, distro.ImageOptions{Size: imageType.Size(0)}, nil, nil, 0)
+	require.NoError(t, err)
+
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	server := newTestServer(t, tempdir, []string{})
+	handler := server.Handler()
+
+	jobId, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	require.Nil(t, server.JobProgress(jobId))
+
+	token, _, _, _, _, err := server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
+	require.NoError(t, err)
+	require.Nil(t, server.JobProgress(jobId))
+
+	test.TestRoute(t, handler, false, "PATCH", fmt.Sprintf("/api/worker/v1/jobs/%s", token), `{"status":"RUNNING","progress":{"pipeline":"os","stage":"org.osbuild.rpm","current":3,"total":10,"percent":20}}`, http.StatusOK, `{}`)
+	test.TestRoute(t, handler, false, "PATCH", fmt.Sprintf("/api/worker/v1/jobs/%s", token), `{"status":"RUNNING"}`, http.StatusBadRequest, `*`)
+	test.TestRoute(t, handler, false, "PATCH", fmt.Sprintf("/api/worker/v1/jobs/%s", uuid.New()), `{"status":"RUNNING","progress":{"stage":"org.osbuild.rpm","current":1,"total":1}}`, http.StatusNotFound, `*`)
+	require.Equal(t, &worker.JobProgress{Pipeline: "os", Stage: "org.osbuild.rpm", Current: 3, Total: 10, Percent: 20}, server.JobProgress(jobId))
+
+	// the progress is forgotten once the job has finished
+	test.TestRoute(t, handler, false, "PATCH", fmt.Sprintf("/api/worker/v1/jobs/%s", token), `{}`, http.StatusOK, `{}`)
+	require.Nil(t, server.JobProgress(jobId))
+}
+
+func TestJobLog(t *testing.T) {
+	distroStruct := test_distro.New()
+	arch, err := distroStruct.GetArch(test_distro.TestArchName)
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType(test_distro.TestImageTypeName)
+	require.NoError(t, err)
+	manifest, err := imageType.Manifest(nil, distro.ImageOptions{Size: imageType.Size(0)}, nil, nil, 0)
+	require.NoError(t, err)
+
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	server := newTestServer(t, tempdir, []string{})
+	handler := server.Handler()
+
+	jobId, err := server.EnqueueOSBuild(context.Background(), arch.Name(), &worker.OSBuildJob{Manifest: manifest}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+
+	token, _, _, _, _, err := server.RequestJob(context.Background(), arch.Name(), []string{"osbuild"})
+	require.NoError(t, err)
+	require.Nil(t, server.JobLog(jobId, 0))
+
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/jobs/%s/log", token), "Pipeline build\n", http.StatusOK, `?`)
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/jobs/%s/log", token), "org.osbuild.rpm\n", http.StatusOK, `?`)
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/jobs/%s/log", uuid.New()), "foo", http.StatusNotFound, `*`)
+	require.Equal(t, []byte("Pipeline build\norg.osbuild.rpm\n"), server.JobLog(jobId, 0))
+	require.Equal(t, []byte("org.osbuild.rpm\n"), server.JobLog(jobId, 15))
+	require.Nil(t, server.JobLog(jobId, 31))
+
+	// the log is forgotten once the job has finished
+	test.TestRoute(t, handler, false, "PATCH", fmt.Sprintf("/api/worker/v1/jobs/%s", token), `{}`, http.StatusOK, `{}`)
+	require.Nil(t, server.JobLog(jobId, 0))
+}
+
+func TestFailStaleJobs(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{HeartbeatTimeout: 100 * time.Millisecond, FailStaleJobs: true})
+
+	jobId, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+
+	_, j, _, _, _, err := server.RequestJob(context.Background(), "", []string{"koji-init"})
+	require.NoError(t, err)
+	require.Equal(t, jobId, j)
+
+	require.Eventually(t, func() bool {
+		var result worker.KojiInitJobResult
+		status, _, err := server.JobStatus(jobId, &result)
+		require.NoError(t, err)
+		return !status.Finished.IsZero() && result.KojiError != ""
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestDeadLetterJobs(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{HeartbeatTimeout: 100 * time.Millisecond, MaxJobFailures: 2, AccessPolicy: adminPolicy})
+	handler := server.Handler()
+
+	jobId, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+
+	test.TestRoute(t, handler, false, "GET", "/api/worker/v1/dead-letter-jobs", ``, http.StatusOK, `{"jobs":[]}`)
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/dead-letter-jobs/%s/requeue", jobId), ``, http.StatusConflict, `*`)
+
+	// The job goes stale twice, after which it is dead-lettered
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, j, _, _, _, err := server.RequestJob(ctx, "", []string{"koji-init"})
+		cancel()
+		require.NoError(t, err)
+		require.Equal(t, jobId, j)
+	}
+
+	require.Eventually(t, func() bool {
+		ids, err := server.DeadLetterJobs()
+		require.NoError(t, err)
+		return len(ids) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	test.TestRoute(t, handler, false, "GET", "/api/worker/v1/dead-letter-jobs", ``, http.StatusOK,
+		fmt.Sprintf(`{"jobs":[{"id":"%s","type":"koji-init"}]}`, jobId))
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/dead-letter-jobs/%s/requeue", uuid.New()), ``, http.StatusNotFound, `*`)
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/dead-letter-jobs/%s/requeue", jobId), ``, http.StatusOK, `{}`)
+	test.TestRoute(t, handler, false, "GET", "/api/worker/v1/dead-letter-jobs", ``, http.StatusOK, `{"jobs":[]}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, j, _, _, _, err := server.RequestJob(ctx, "", []string{"koji-init"})
+	require.NoError(t, err)
+	require.Equal(t, jobId, j)
+}
+
+func TestTransientRetries(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{TransientRetries: 2, TransientRetryBackoff: 10 * time.Millisecond})
+
+	jobId, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+
+	transient, err := json.Marshal(&worker.OSBuildJobResult{Failure: worker.FailureTransient})
+	require.NoError(t, err)
+
+	// the job is requeued twice, after which its transient failure is its
+	// result
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		token, j, _, _, _, err := server.RequestJob(ctx, "x86_64", []string{"osbuild"})
+		cancel()
+		require.NoError(t, err)
+		require.Equal(t, jobId, j)
+		require.NoError(t, server.FinishJob(token, transient))
+
+		var result worker.OSBuildJobResult
+		status, _, err := server.JobStatus(jobId, &result)
+		require.NoError(t, err)
+		require.Equal(t, i == 2, !status.Finished.IsZero())
+	}
+
+	// permanent failures are never retried
+	jobId, err = server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	token, _, _, _, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+	require.NoError(t, err)
+	permanent, err := json.Marshal(&worker.OSBuildJobResult{Failure: worker.FailurePermanent})
+	require.NoError(t, err)
+	require.NoError(t, server.FinishJob(token, permanent))
+	var result worker.OSBuildJobResult
+	status, _, err := server.JobStatus(jobId, &result)
+	require.NoError(t, err)
+	require.False(t, status.Finished.IsZero())
+	require.Equal(t, worker.FailurePermanent, result.Failure)
+
+	// jobs which are canceled while waiting for their retry stay canceled
+	jobId, err = server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	token, _, _, _, _, err = server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+	require.NoError(t, err)
+	require.NoError(t, server.FinishJob(token, transient))
+	require.NoError(t, server.Cancel(jobId))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _, _, _, _, err = server.RequestJob(ctx, "x86_64", []string{"osbuild"})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// retries are kept in the job queue, so that they survive restarts
+	jobId, err = server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	token, _, _, _, _, err = server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+	require.NoError(t, err)
+	require.NoError(t, server.FinishJob(token, transient))
+	server.Close()
+
+	q, err = fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server = worker.NewServer(nil, q, worker.Config{TransientRetries: 2, TransientRetryBackoff: 10 * time.Millisecond})
+	defer server.Close()
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		token, j, _, _, _, err := server.RequestJob(ctx, "x86_64", []string{"osbuild"})
+		cancel()
+		require.NoError(t, err)
+		require.Equal(t, jobId, j)
+		require.NoError(t, server.FinishJob(token, transient))
+	}
+	status, _, err = server.JobStatus(jobId, &result)
+	require.NoError(t, err)
+	require.False(t, status.Finished.IsZero())
+	require.Equal(t, worker.FailureTransient, result.Failure)
+}
+
+func TestEnqueueDAG(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	server := newTestServer(t, tempdir, []string{})
+	srv := httptest.NewServer(server.Handler())
+	defer srv.Close()
+
+	_, err = server.EnqueueDAG(context.Background(), []worker.DAGJob{{Type: "bogus"}})
+	require.Equal(t, worker.ErrInvalidJobType, err)
+
+	ids, err := server.EnqueueDAG(context.Background(), []worker.DAGJob{
+		{Type: "koji-init", Args: &worker.KojiInitJob{}},
+		{Type: "osbuild-koji", Arch: "x86_64", Args: &worker.OSBuildKojiJob{ImageName: "one"}, Dependencies: []int{0}},
+		{Type: "osbuild-koji", Arch: "aarch64", Args: &worker.OSBuildKojiJob{ImageName: "two"}, Dependencies: []int{0}},
+		{Type: "koji-finalize", Args: &worker.KojiFinalizeJob{}, Dependencies: []int{0, 1, 2}},
+	})
+	require.NoError(t, err)
+	require.Len(t, ids, 4)
+
+	token, _, _, _, _, err := server.RequestJob(context.Background(), "", []string{"koji-init"})
+	require.NoError(t, err)
+	require.NoError(t, server.FinishJob(token, json.RawMessage(`{"build_id":42}`)))
+
+	for _, arch := range []string{"x86_64", "aarch64"} {
+		token, _, _, _, _, err := server.RequestJob(context.Background(), arch, []string{"osbuild-koji"})
+		require.NoError(t, err)
+		require.NoError(t, server.FinishJob(token, json.RawMessage(`{"image_hash":"`+arch+`"}`)))
+	}
+
+	client, err := worker.NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+	job, err := client.RequestJob([]string{"koji-finalize"}, "")
+	require.NoError(t, err)
+	require.Equal(t, ids[3], job.Id())
+	require.Equal(t, 3, job.NDynamicArgs())
+
+	var initResults []worker.KojiInitJobResult
+	require.NoError(t, job.DynamicArgsOfType("koji-init", &initResults))
+	require.Len(t, initResults, 1)
+	require.Equal(t, uint64(42), initResults[0].BuildID)
+
+	var buildResults []worker.OSBuildKojiJobResult
+	require.NoError(t, job.DynamicArgsOfType("osbuild-koji", &buildResults))
+	require.Len(t, buildResults, 2)
+	require.Equal(t, "x86_64", buildResults[0].ImageHash)
+	require.Equal(t, "aarch64", buildResults[1].ImageHash)
+}
+
+func TestEnqueueOSBuildAsDependency(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	server := newTestServer(t, tempdir, []string{})
+	srv := httptest.NewServer(server.Handler())
+	defer srv.Close()
+
+	_, err = server.EnqueueOSBuildAsDependency(context.Background(), "x86_64", &worker.DepsolveJob{}, &worker.ManifestJobByID{}, &worker.OSBuildJob{}, "bogus", "")
+	require.Equal(t, worker.ErrInvalidPriorityClass, err)
+
+	id, err := server.EnqueueOSBuildAsDependency(context.Background(), "x86_64", &worker.DepsolveJob{
+		PackageSets: map[string]rpmmd.PackageSet{
+			"packages": {Include: []string{"kernel"}},
+		},
+		Repos: []rpmmd.RepoConfig{{
+			BaseURL:        "https://example.com/repo",
+			GPGKey:         "key",
+			CheckGPG:       true,
+			ModuleHotfixes: true,
+		}},
+		ModulePlatformID: "platform:el8",
+		Arch:             "x86_64",
+	}, &worker.ManifestJobByID{
+		Distribution: "rhel-85",
+		Arch:         "x86_64",
+		ImageType:    "qcow2",
+	}, &worker.OSBuildJob{ImageName: "disk.qcow2"}, worker.PriorityInteractive, "")
+	require.NoError(t, err)
+
+	// the manifest and the build must wait for the packages
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, _, _, _, err = server.RequestJob(ctx, "x86_64", []string{"manifest-id-only", "osbuild"})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	client, err := worker.NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+
+	job, err := client.RequestJob([]string{"depsolve"}, "x86_64")
+	require.NoError(t, err)
+	var depsolveJob worker.DepsolveJob
+	require.NoError(t, job.Args(&depsolveJob))
+	require.Equal(t, []string{"kernel"}, depsolveJob.PackageSets["packages"].Include)
+	require.Len(t, depsolveJob.Repos, 1)
+	require.Equal(t, "key", depsolveJob.Repos[0].GPGKey)
+	require.True(t, depsolveJob.Repos[0].ModuleHotfixes)
+	require.NoError(t, job.Update(&worker.DepsolveJobResult{
+		PackageSpecs: map[string][]rpmmd.PackageSpec{
+			"packages": {{Name: "kernel", Version: "4.18.0", Checksum: "sha256:abc", RepoID: "0"}},
+		},
+	}))
+
+	job, err = client.RequestJob([]string{"manifest-id-only"}, "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, 1, job.NDynamicArgs())
+	var depsolveResult worker.DepsolveJobResult
+	require.NoError(t, job.DynamicArgs(0, &depsolveResult))
+	require.Equal(t, "0", depsolveResult.PackageSpecs["packages"][0].RepoID)
+	var manifestJob worker.ManifestJobByID
+	require.NoError(t, job.Args(&manifestJob))
+	require.Equal(t, "qcow2", manifestJob.ImageType)
+	require.NoError(t, job.Update(&worker.ManifestJobByIDResult{Manifest: distro.Manifest(`{"version":"2"}`)}))
+
+	job, err = client.RequestJob([]string{"osbuild"}, "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, id, job.Id())
+	require.Equal(t, 1, job.NDynamicArgs())
+
+	var manifestResult worker.ManifestJobByIDResult
+	require.NoError(t, job.DynamicArgs(0, &manifestResult))
+	require.JSONEq(t, `{"version":"2"}`, string(manifestResult.Manifest))
+	require.NoError(t, job.Update(&worker.OSBuildJobResult{}))
+
+	// a retry builds the same manifest without depsolving again, with the
+	// same priority
+	retryID, err := server.RetryOSBuild(context.Background(), id, "")
+	require.NoError(t, err)
+	require.NotEqual(t, id, retryID)
+
+	job, err = client.RequestJob([]string{"depsolve", "manifest-id-only", "osbuild"}, "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, retryID, job.Id())
+	var osbuildJob worker.OSBuildJob
+	require.NoError(t, job.Args(&osbuildJob))
+	require.Equal(t, "disk.qcow2", osbuildJob.ImageName)
+	require.Equal(t, worker.PriorityInteractive, osbuildJob.PriorityClass)
+	require.NoError(t, job.DynamicArgs(0, &manifestResult))
+	require.JSONEq(t, `{"version":"2"}`, string(manifestResult.Manifest))
+
+	// the credentials of the client are gone after the job
+	withCredentials, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{
+		Credentials: []*worker.TargetCredentials{{S3: target.AWSCredentials{AccessKeyID: "id", SecretAccessKey: "secret"}}},
+	}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	_, err = server.RetryOSBuild(context.Background(), withCredentials, "")
+	require.Equal(t, worker.ErrCredentialsLost, err)
+}
+
+func TestEnqueueCompose(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	server := newTestServer(t, tempdir, []string{})
+	srv := httptest.NewServer(server.Handler())
+	defer srv.Close()
+
+	var imageIDs []uuid.UUID
+	for _, arch := range []string{"x86_64", "aarch64"} {
+		id, err := server.EnqueueOSBuild(context.Background(), arch, &worker.OSBuildJob{ImageName: arch}, worker.PriorityBatch, "")
+		require.NoError(t, err)
+		imageIDs = append(imageIDs, id)
+	}
+	id, err := server.EnqueueCompose(context.Background(), &worker.ComposeJob{}, imageIDs)
+	require.NoError(t, err)
+
+	var result worker.ComposeJobResult
+	status, deps, err := server.JobStatus(id, &result)
+	require.NoError(t, err)
+	require.True(t, status.Started.IsZero())
+	require.Equal(t, imageIDs, deps)
+
+	// the compose waits for all of its images
+	token, _, _, _, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+	require.NoError(t, err)
+	require.NoError(t, server.FinishJob(token, json.RawMessage(`{"success":true}`)))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, _, _, _, err = server.RequestJob(ctx, "x86_64", []string{"compose"})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	token, _, _, _, _, err = server.RequestJob(context.Background(), "aarch64", []string{"osbuild"})
+	require.NoError(t, err)
+	require.NoError(t, server.FinishJob(token, json.RawMessage(`{"success":false}`)))
+
+	client, err := worker.NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+	job, err := client.RequestJob([]string{"compose"}, "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, id, job.Id())
+	require.Equal(t, 2, job.NDynamicArgs())
+
+	var imageResults []worker.OSBuildJobResult
+	require.NoError(t, job.DynamicArgsOfType("osbuild", &imageResults))
+	require.Len(t, imageResults, 2)
+	require.True(t, imageResults[0].Success)
+	require.False(t, imageResults[1].Success)
+	require.NoError(t, job.Update(&worker.ComposeJobResult{Success: false}))
+
+	_, _, err = server.JobStatus(id, &result)
+	require.NoError(t, err)
+	require.False(t, result.Success)
+}
+
+func TestEnqueueUpload(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{ArtifactsDir: tempdir})
+	srv := httptest.NewServer(server.Handler())
+	defer srv.Close()
+
+	osbuildID, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{ImageName: "disk.qcow2"}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	uploadID, err := server.EnqueueUpload(context.Background(), &worker.UploadJob{ImageName: "disk.qcow2"}, osbuildID)
+	require.NoError(t, err)
+	composeID, err := server.EnqueueCompose(context.Background(), &worker.ComposeJob{Uploads: [][]uuid.UUID{{uploadID}}}, []uuid.UUID{osbuildID})
+	require.NoError(t, err)
+
+	_, deps, err := server.JobStatus(composeID, &worker.ComposeJobResult{})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{osbuildID, uploadID}, deps)
+
+	client, err := worker.NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+	job, err := client.RequestJob([]string{"osbuild"}, "x86_64")
+	require.NoError(t, err)
+	require.NoError(t, job.UploadArtifact("disk.qcow2", strings.NewReader("image contents")))
+	require.NoError(t, job.Update(&worker.OSBuildJobResult{Success: true}))
+
+	job, err = client.RequestJob([]string{"upload"}, "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, uploadID, job.Id())
+
+	var contents strings.Builder
+	require.NoError(t, job.DownloadDependencyArtifact(0, "disk.qcow2", &contents))
+	require.Equal(t, "image contents", contents.String())
+	require.Error(t, job.DownloadDependencyArtifact(0, "missing", &contents))
+	require.Error(t, job.DownloadDependencyArtifact(1, "disk.qcow2", &contents))
+
+	require.NoError(t, job.Update(&worker.UploadJobResult{Success: true, UploadStatus: "success"}))
+	var result worker.UploadJobResult
+	_, _, err = server.JobStatus(uploadID, &result)
+	require.NoError(t, err)
+	require.True(t, result.Success)
+}
+
+func TestWorkerRegistration(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{AccessPolicy: adminPolicy})
+	handler := server.Handler()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	client, err := worker.NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+	err = client.Register(worker.WorkerInfo{
+		Hostname:       "worker.example.com",
+		Arch:           "x86_64",
+		JobTypes:       []string{"koji-init"},
+		FreeDisk:       1 << 30,
+		OSBuildVersion: "osbuild 24",
+	})
+	require.NoError(t, err)
+
+	workers := server.Workers()
+	require.Len(t, workers, 1)
+	require.NotEqual(t, uuid.Nil, workers[0].Id)
+	require.Equal(t, "worker.example.com", workers[0].Hostname)
+	require.Equal(t, []string{"koji-init"}, workers[0].JobTypes)
+	require.Equal(t, uint64(1<<30), workers[0].FreeDisk)
+	require.Equal(t, "osbuild 24", workers[0].OSBuildVersion)
+
+	test.TestRoute(t, handler, false, "GET", "/api/worker/v1/workers", ``, http.StatusOK,
+		fmt.Sprintf(`{"workers":[{"id":"%s","hostname":"worker.example.com","arch":"x86_64","job_types":["koji-init"],"free_disk":1073741824,"osbuild_version":"osbuild 24"}]}`, workers[0].Id),
+		"registered_at", "last_seen")
+
+	test.TestRoute(t, handler, false, "POST", "/api/worker/v1/workers", `{"arch":"x86_64","job_types":[]}`, http.StatusBadRequest, `{"message":"arch and job_types are required"}`, "message")
+
+	// the worker only gets jobs it advertised, even though it asks for more
+	_, err = server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	initID, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+
+	job, err := client.RequestJob([]string{"osbuild", "koji-init"}, "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, initID, job.Id())
+	require.Equal(t, "koji-init", job.Type())
+	require.NoError(t, job.Update(&worker.KojiInitJobResult{}))
+
+	_, err = client.RequestJob([]string{"osbuild"}, "x86_64")
+	require.Error(t, err)
+	_, err = client.RequestJob([]string{"koji-init"}, "aarch64")
+	require.Error(t, err)
+
+	// a composer serves workers of several architectures, but rejects
+	// jobs which none of them can run
+	require.Equal(t, []string{}, server.Arches())
+	require.Equal(t, worker.ErrNoWorkerForArch, server.CheckArch("osbuild", "aarch64"))
+	var archClient *worker.Client
+	for _, arch := range []string{"s390x", "aarch64", "aarch64"} {
+		archClient, err = worker.NewClient(srv.URL, nil, nil, nil)
+		require.NoError(t, err)
+		err = archClient.Register(worker.WorkerInfo{Arch: arch, JobTypes: []string{"osbuild"}})
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"aarch64", "s390x"}, server.Arches())
+	test.TestRoute(t, handler, false, "GET", "/api/worker/v1/status", ``, http.StatusOK, `{"status":"OK","arches":["aarch64","s390x"]}`)
+	require.NoError(t, server.CheckArch("osbuild", "aarch64"))
+	require.Equal(t, worker.ErrNoWorkerForArch, server.CheckArch("osbuild", "x86_64"))
+	require.Equal(t, worker.ErrNoWorkerForArch, server.CheckArch("osbuild-koji", "aarch64"))
+
+	aarch64ID, err := server.EnqueueOSBuild(context.Background(), "aarch64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	job, err = archClient.RequestJob([]string{"osbuild"}, "aarch64")
+	require.NoError(t, err)
+	require.Equal(t, aarch64ID, job.Id())
+	require.Equal(t, "osbuild", job.Type())
+
+	test.TestRoute(t, handler, false, "POST", "/api/worker/v1/jobs", `{"types":["osbuild"],"arch":""}`, http.StatusBadRequest,
+		`{"message":"arch is required to request osbuild jobs"}`)
+
+	// a restarted server has forgotten the worker, which registers again
+	server = newTestServer(t, tempdir, []string{})
+	handler = server.Handler()
+	require.NoError(t, server.CheckArch("osbuild", "x86_64"))
+	secondInitID, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+	job, err = client.RequestJob([]string{"koji-init"}, "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, secondInitID, job.Id())
+}
+
+type testSecrets map[string]map[string]string
+
+func (s testSecrets) Secret(name string) (map[string]string, error) {
+	return s[name], nil
+}
+
+func TestRequestJobCredentials(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{
+		Secrets: testSecrets{
+			"aws":   {"access_key": "AKIA", "secret_key": "secret", "security_token": "token"},
+			"azure": {"client_id": "id", "client_secret": "secret"},
+		},
+	})
+
+	jobID, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{
+		Targets: []*target.Target{
+			target.NewAWSTarget(&target.AWSTargetOptions{Bucket: "bucket"}),
+			target.NewAzureImageTarget(&target.AzureImageTargetOptions{TenantID: "tenant"}),
+			// credentials of the request are kept
+			target.NewAWSS3Target(&target.AWSS3TargetOptions{AccessKeyID: "own", SecretAccessKey: "own"}),
+			// no credentials in the secrets
+			target.NewGCPTarget(&target.GCPTargetOptions{Bucket: "bucket"}),
+		},
+	}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+
+	_, _, _, args, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+	require.NoError(t, err)
+	var job worker.OSBuildJob
+	require.NoError(t, json.Unmarshal(args, &job))
+
+	aws := job.Targets[0].Options.(*target.AWSTargetOptions)
+	require.Equal(t, "AKIA", aws.AccessKeyID)
+	require.Equal(t, "secret", aws.SecretAccessKey)
+	require.Equal(t, "token", aws.SessionToken)
+	azure := job.Targets[1].Options.(*target.AzureImageTargetOptions)
+	require.Equal(t, "id", azure.ClientID)
+	require.Equal(t, "secret", azure.ClientSecret)
+	require.Equal(t, "own", job.Targets[2].Options.(*target.AWSS3TargetOptions).AccessKeyID)
+	require.Empty(t, job.Targets[3].Options.(*target.GCPTargetOptions).Credentials)
+
+	// the credentials are not stored with the job
+	var stored worker.OSBuildJob
+	_, _, _, err = server.Job(jobID, &stored)
+	require.NoError(t, err)
+	require.Empty(t, stored.Targets[0].Options.(*target.AWSTargetOptions).AccessKeyID)
+	require.Empty(t, stored.Targets[1].Options.(*target.AzureImageTargetOptions).ClientID)
+}
+
+func TestRequestJobLostCredentials(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	config := worker.Config{
+		Secrets: testSecrets{
+			"aws": {"access_key": "AKIA", "secret_key": "secret"},
+		},
+	}
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, config)
+
+	s3 := target.NewAWSS3Target(&target.AWSS3TargetOptions{Bucket: "client", AccessKeyID: "own", SecretAccessKey: "own"})
+	lostID, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{
+		Targets:     []*target.Target{s3},
+		Credentials: []*worker.TargetCredentials{worker.RedactCredentials(s3)},
+	}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	server.Close()
+
+	// the credentials of the client are gone after a restart, composer's
+	// own ones must not be used for the client's bucket
+	q, err = fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server = worker.NewServer(nil, q, config)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _, _, _, _, err = server.RequestJob(ctx, "x86_64", []string{"osbuild"})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	var result worker.OSBuildJobResult
+	status, _, err := server.JobStatus(lostID, &result)
+	require.NoError(t, err)
+	require.False(t, status.Finished.IsZero())
+	require.False(t, result.Success)
+	require.Contains(t, result.TargetErrors[0], "credentials")
+}
+
+func TestRequestCredentials(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	server := newTestServer(t, tempdir, []string{})
+
+	osbuildID, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	token, _, _, _, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+	require.NoError(t, err)
+	require.NoError(t, server.FinishJob(token, json.RawMessage(`{}`)))
+
+	s3 := target.NewAWSS3Target(&target.AWSS3TargetOptions{
+		Bucket:          "bucket",
+		AccessKeyID:     "ASIA",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+	})
+	creds := worker.RedactCredentials(s3)
+	require.Equal(t, &worker.TargetCredentials{
+		S3: target.AWSCredentials{AccessKeyID: "ASIA", SecretAccessKey: "secret", SessionToken: "session"},
+	}, creds)
+	require.Equal(t, "{S3:ASIA:<redacted> EC2:<nil>}", fmt.Sprintf("%v", creds))
+
+	uploadID, err := server.EnqueueUpload(context.Background(), &worker.UploadJob{Target: s3, Credentials: creds}, osbuildID)
+	require.NoError(t, err)
+
+	// the queue doesn't have the keys
+	var stored worker.UploadJob
+	_, _, _, err = server.Job(uploadID, &stored)
+	require.NoError(t, err)
+	require.Empty(t, stored.Target.Options.(*target.AWSS3TargetOptions).AccessKeyID)
+	require.Empty(t, stored.Target.Options.(*target.AWSS3TargetOptions).SecretAccessKey)
+
+	// the worker does
+	token, _, _, args, _, err := server.RequestJob(context.Background(), "", []string{"upload"})
+	require.NoError(t, err)
+	var job worker.UploadJob
+	require.NoError(t, json.Unmarshal(args, &job))
+	options := job.Target.Options.(*target.AWSS3TargetOptions)
+	require.Equal(t, "ASIA", options.AccessKeyID)
+	require.Equal(t, "secret", options.SecretAccessKey)
+	require.Equal(t, "session", options.SessionToken)
+
+	require.NoError(t, server.AppendJobLog(token, []byte("signing with secret\n")))
+	require.Equal(t, "signing with <redacted>\n", string(server.JobLog(uploadID, 0)))
+
+	require.NoError(t, server.FinishJob(token, json.RawMessage(`{"success":false,"target_errors":["token session expired"]}`)))
+	var result worker.UploadJobResult
+	_, _, err = server.JobStatus(uploadID, &result)
+	require.NoError(t, err)
+	require.Equal(t, []string{"token <redacted> expired"}, result.TargetErrors)
+}
+
+func TestBearerToken(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"keys":[{"kty":"RSA","n":"%s","e":"AQAB"}]}`, base64.RawURLEncoding.EncodeToString(key.N.Bytes()))
+	}))
+	defer issuer.Close()
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{
+		IdentityFilter: []string{"000000"},
+		TokenValidator: oidc.NewValidator(oidc.Config{
+			Issuer:           issuer.URL,
+			JWKSURL:          issuer.URL,
+			AllowedClientIDs: []string{"worker"},
+		}),
+	})
+	handler := server.Handler()
+
+	token := func(clientID string) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss": issuer.URL,
+			"sub": "worker-1",
+			"azp": clientID,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString(key)
+		require.NoError(t, err)
+		return "Bearer " + signed
+	}
+	register := func(header map[string]string) int {
+		header["Content-Type"] = "application/json"
+		resp := test.SendHTTPWithHeader(handler, "POST", "/api/worker/v1/workers", `{"arch":"x86_64","job_types":["osbuild"]}`, header)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusCreated, register(map[string]string{"Authorization": token("worker")}))
+	require.Equal(t, http.StatusUnauthorized, register(map[string]string{"Authorization": token("someone")}))
+	// the identity header is still accepted
+	identity := base64.StdEncoding.EncodeToString([]byte(`{"identity":{"account_number":"000000"}}`))
+	require.Equal(t, http.StatusCreated, register(map[string]string{"X-Rh-Identity": identity}))
+	require.Equal(t, http.StatusNotFound, register(map[string]string{}))
+
+	workers := server.Workers()
+	require.Len(t, workers, 2)
+	require.Equal(t, "worker-1", workers[0].Identity)
+	require.Empty(t, workers[1].Identity)
+}
+
+func TestAccessPolicy(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{
+		IdentityFilter: []string{"000000", "111111"},
+		AccessPolicy: &rbac.Policy{
+			Clients: map[string]rbac.Roles{
+				"000000": {rbac.Worker},
+				"111111": {rbac.Admin},
+			},
+		},
+	})
+	handler := server.Handler()
+
+	request := func(method, path, body, account string) int {
+		identity := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf(`{"identity":{"account_number":"%s"}}`, account)))
+		resp := test.SendHTTPWithHeader(handler, method, path, body, map[string]string{
+			"Content-Type":  "application/json",
+			"X-Rh-Identity": identity,
+		})
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	register := `{"arch":"x86_64","job_types":["osbuild"]}`
+	require.Equal(t, http.StatusCreated, request("POST", "/api/worker/v1/workers", register, "000000"))
+	require.Equal(t, http.StatusForbidden, request("POST", "/api/worker/v1/workers", register, "111111"))
+
+	require.Equal(t, http.StatusOK, request("GET", "/api/worker/v1/workers", "", "111111"))
+	require.Equal(t, http.StatusForbidden, request("GET", "/api/worker/v1/workers", "", "000000"))
+	require.Equal(t, http.StatusForbidden, request("GET", "/api/worker/v1/dead-letter-jobs", "", "000000"))
+	require.Equal(t, http.StatusForbidden, request("GET", "/api/worker/v1/admin/stats", "", "000000"))
+	require.Equal(t, http.StatusOK, request("GET", "/api/worker/v1/admin/stats", "", "111111"))
+
+	require.Equal(t, http.StatusOK, request("GET", "/api/worker/v1/status", "", "111111"))
+}
+
+func TestTraceContext(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer collector.Close()
+	tracer, err := tracing.NewTracer("test", collector.URL)
+	require.NoError(t, err)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{Tracer: tracer})
+
+	ctx, span := tracer.Start(context.Background(), "POST /compose", trace.SpanKindServer)
+	_, err = server.EnqueueOSBuild(ctx, "x86_64", &worker.OSBuildJob{ImageName: "disk.img"}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	span.End()
+
+	_, _, _, args, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+	require.NoError(t, err)
+
+	// the job's arguments are unchanged, apart from the trace context
+	var job worker.OSBuildJob
+	require.NoError(t, json.Unmarshal(args, &job))
+	require.Equal(t, "disk.img", job.ImageName)
+
+	var fields struct {
+		Traceparent string `json:"traceparent"`
+	}
+	require.NoError(t, json.Unmarshal(args, &fields))
+	jobCtx, err := tracing.ContextWithTraceparent(context.Background(), fields.Traceparent)
+	require.NoError(t, err)
+	sc := trace.RemoteSpanContextFromContext(jobCtx)
+	require.Equal(t, span.SpanContext().TraceID, sc.TraceID)
+	require.NotEqual(t, span.SpanContext().SpanID, sc.SpanID)
+}
+
+func TestReportFailure(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	events := make(chan map[string]interface{}, 2)
+	project := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events <- event
+	}))
+	defer project.Close()
+	reporter, err := sentry.NewClient(strings.Replace(project.URL, "://", "://public@", 1)+"/1", nil)
+	require.NoError(t, err)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{ErrorReporter: reporter})
+
+	for _, result := range []string{
+		`{"success":true,"osbuild_output":{"success":true}}`,
+		`{"success":false,"osbuild_output":{"success":false,"stages":[{"name":"org.osbuild.kernel-cmdline","success":true},{"name":"org.osbuild.selinux","success":false,"output":"setfiles: invalid context"}]}}`,
+	} {
+		_, err = server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{Tenant: "acme"}, worker.PriorityBatch, "acme")
+		require.NoError(t, err)
+		token, _, _, _, _, err := server.RequestJob(context.Background(), "x86_64", []string{"osbuild"})
+		require.NoError(t, err)
+		require.NoError(t, server.FinishJob(token, json.RawMessage(result)))
+	}
+	require.True(t, reporter.Flush(10*time.Second))
+
+	// only the failed job is reported
+	require.Len(t, events, 1)
+	event := <-events
+	tags := event["tags"].(map[string]interface{})
+	require.Equal(t, "osbuild", tags["job_type"])
+	require.Equal(t, "acme", tags["tenant"])
+	require.Equal(t, map[string]interface{}{
+		"osbuild.stage":  "org.osbuild.selinux",
+		"osbuild.output": "setfiles: invalid context",
+	}, event["extra"])
+}
+
+func TestAdminRoutesWithoutPolicy(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	server := newTestServer(t, tempdir, []string{})
+	handler := server.Handler()
+
+	jobId, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+
+	// nobody could be authorized to use them
+	for _, basePath := range []string{"/api/worker/v1", "/api/composer-worker/v1"} {
+		test.TestRoute(t, handler, false, "GET", basePath+"/admin/jobs", ``, http.StatusNotFound, `*`)
+		test.TestRoute(t, handler, false, "GET", basePath+"/admin/stats", ``, http.StatusNotFound, `*`)
+		test.TestRoute(t, handler, false, "POST", fmt.Sprintf("%s/admin/jobs/%s/cancel", basePath, jobId), ``, http.StatusNotFound, `*`)
+		test.TestRoute(t, handler, false, "GET", basePath+"/dead-letter-jobs", ``, http.StatusNotFound, `*`)
+		test.TestRoute(t, handler, false, "GET", basePath+"/workers", ``, http.StatusMethodNotAllowed, `*`)
+		test.TestRoute(t, handler, false, "GET", basePath+"/status", ``, http.StatusOK, `*`)
+	}
+
+	status, _, err := server.JobStatus(jobId, &worker.KojiInitJobResult{})
+	require.NoError(t, err)
+	require.False(t, status.Canceled)
+}
+
+func TestAdmin(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{AccessPolicy: adminPolicy})
+	handler := server.Handler()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	test.TestRoute(t, handler, false, "GET", "/api/worker/v1/admin/jobs", ``, http.StatusOK, `{"jobs":[]}`)
+
+	initID, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+	osbuildID, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{Tenant: "acme"}, worker.PriorityBatch, "acme")
+	require.NoError(t, err)
+
+	client, err := worker.NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Register(worker.WorkerInfo{Arch: "x86_64", JobTypes: []string{"koji-init"}}))
+	job, err := client.RequestJob([]string{"koji-init"}, "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, initID, job.Id())
+
+	jobs, err := server.QueuedJobs()
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	require.Equal(t, initID, jobs[0].Id)
+	require.False(t, jobs[0].StartedAt.IsZero())
+	require.Equal(t, osbuildID, jobs[1].Id)
+	require.Equal(t, "osbuild:x86_64", jobs[1].Type)
+	require.Equal(t, "acme", jobs[1].Tenant)
+	require.True(t, jobs[1].StartedAt.IsZero())
+
+	test.TestRoute(t, handler, false, "GET", "/api/worker/v1/admin/stats", ``, http.StatusOK,
+		`{"pending":1,"running":1,"dead_lettered":0,"job_types":[{"type":"koji-init","pending":0,"running":1},{"type":"osbuild:x86_64","pending":1,"running":0}],"workers":1,"draining_workers":0}`,
+		"oldest_pending_age")
+
+	// pending jobs cannot be requeued, but canceled
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/admin/jobs/%s/requeue", osbuildID), ``, http.StatusConflict, `*`)
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/admin/jobs/%s/cancel", osbuildID), ``, http.StatusOK, `{}`)
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/admin/jobs/%s/cancel", uuid.New()), ``, http.StatusNotFound, `*`)
+
+	// the worker of a requeued job is told that it was canceled
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/admin/jobs/%s/requeue", initID), ``, http.StatusOK, `{}`)
+	canceled, err := job.Canceled()
+	require.NoError(t, err)
+	require.True(t, canceled)
+	canceled, err = job.WaitForCancellation(context.Background())
+	require.NoError(t, err)
+	require.True(t, canceled)
+	require.Error(t, job.Update(&worker.KojiInitJobResult{}))
+
+	// draining workers are not handed jobs until draining stops
+	workers := server.Workers()
+	require.Len(t, workers, 1)
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/admin/workers/%s/drain", workers[0].Id), ``, http.StatusOK, `{}`)
+	require.True(t, server.Workers()[0].Draining)
+
+	requested := make(chan worker.Job)
+	go func() {
+		job, err := client.RequestJob([]string{"koji-init"}, "x86_64")
+		require.NoError(t, err)
+		requested <- job
+	}()
+	select {
+	case <-requested:
+		t.Fatal("draining worker was handed a job")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	test.TestRoute(t, handler, false, "DELETE", fmt.Sprintf("/api/worker/v1/admin/workers/%s/drain", workers[0].Id), ``, http.StatusOK, `{}`)
+	job = <-requested
+	require.Equal(t, initID, job.Id())
+
+	test.TestRoute(t, handler, false, "POST", fmt.Sprintf("/api/worker/v1/admin/workers/%s/drain", uuid.New()), ``, http.StatusNotFound, `*`)
+}
+
+func TestYieldJob(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{MaxJobFailures: 2})
+	handler := server.Handler()
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	jobId, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{ImageSize: 42}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+
+	client, err := worker.NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+
+	// a yielded job goes to the next worker, until it failed too often
+	for i := 0; i < 2; i++ {
+		job, err := client.RequestJob([]string{"osbuild"}, "x86_64")
+		require.NoError(t, err)
+		require.Equal(t, jobId, job.Id())
+
+		var args worker.OSBuildJob
+		require.NoError(t, job.Args(&args))
+		require.Equal(t, uint64(42), args.ImageSize)
+
+		require.NoError(t, job.Yield("insufficient resources"))
+		require.Error(t, job.Update(&worker.OSBuildJobResult{Success: true}))
+	}
+
+	ids, err := server.DeadLetterJobs()
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{jobId}, ids)
+}
+
+func TestJobTimeouts(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	q, err := fsjobqueue.New(tempdir)
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{JobTimeouts: map[string]time.Duration{"osbuild": 100 * time.Millisecond}})
+	srv := httptest.NewServer(server.Handler())
+	defer srv.Close()
+
+	osbuildID, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{}, worker.PriorityBatch, "")
+	require.NoError(t, err)
+	initID, err := server.EnqueueKojiInit(context.Background(), &worker.KojiInitJob{})
+	require.NoError(t, err)
+
+	client, err := worker.NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+
+	// the worker is told the timeout, in seconds, and that the job was
+	// canceled when it passes, after which the job failed
+	job, err := client.RequestJob([]string{"osbuild"}, "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, osbuildID, job.Id())
+	require.Equal(t, time.Second, job.Timeout())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	canceled, err := job.WaitForCancellation(ctx)
+	require.NoError(t, err)
+	require.True(t, canceled)
+
+	var result worker.OSBuildJobResult
+	status, _, err := server.JobStatus(osbuildID, &result)
+	require.NoError(t, err)
+	require.False(t, status.Finished.IsZero())
+	require.False(t, result.Success)
+	require.Equal(t, []string{"job timed out after 100ms"}, result.TargetErrors)
+	require.Error(t, job.Update(&worker.OSBuildJobResult{Success: true}))
+
+	// job types without a timeout run until they finish
+	job, err = client.RequestJob([]string{"koji-init"}, "x86_64")
+	require.NoError(t, err)
+	require.Equal(t, initID, job.Id())
+	require.Zero(t, job.Timeout())
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, job.Update(&worker.KojiInitJobResult{}))
+}
+
+func TestArtifactRetention(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	artifactsDir := path.Join(tempdir, "artifacts")
+	old, recent, leftover := uuid.New(), uuid.New(), uuid.New()
+	oldOfTenant := path.Join("tenants", "acme", uuid.New().String())
+	for _, dir := range []string{old.String(), recent.String(), path.Join("tmp", leftover.String()), oldOfTenant} {
+		require.NoError(t, os.MkdirAll(path.Join(artifactsDir, dir), 0700))
+		require.NoError(t, ioutil.WriteFile(path.Join(artifactsDir, dir, "image.raw"), []byte("image"), 0600))
+	}
+	lastWeek := time.Now().Add(-7 * 24 * time.Hour)
+	require.NoError(t, os.Chtimes(path.Join(artifactsDir, old.String()), lastWeek, lastWeek))
+	require.NoError(t, os.Chtimes(path.Join(artifactsDir, oldOfTenant), lastWeek, lastWeek))
+	require.NoError(t, os.Chtimes(path.Join(artifactsDir, "tmp", leftover.String()), lastWeek, lastWeek))
+
+	require.NoError(t, os.Mkdir(path.Join(tempdir, "jobs"), 0700))
+	q, err := fsjobqueue.New(path.Join(tempdir, "jobs"))
+	require.NoError(t, err)
+	_ = worker.NewServer(nil, q, worker.Config{ArtifactsDir: artifactsDir, ArtifactMaxAge: 24 * time.Hour})
+
+	require.Eventually(t, func() bool {
+		_, errOld := os.Stat(path.Join(artifactsDir, old.String()))
+		_, errLeftover := os.Stat(path.Join(artifactsDir, "tmp", leftover.String()))
+		_, errOfTenant := os.Stat(path.Join(artifactsDir, oldOfTenant))
+		return os.IsNotExist(errOld) && os.IsNotExist(errLeftover) && os.IsNotExist(errOfTenant)
+	}, 5*time.Second, 50*time.Millisecond)
+	require.DirExists(t, path.Join(artifactsDir, recent.String()))
+}
+
+func TestTenantArtifacts(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	artifactsDir := path.Join(tempdir, "artifacts")
+	require.NoError(t, os.Mkdir(artifactsDir, 0700))
+	require.NoError(t, os.Mkdir(path.Join(tempdir, "jobs"), 0700))
+	q, err := fsjobqueue.New(path.Join(tempdir, "jobs"))
+	require.NoError(t, err)
+	server := worker.NewServer(nil, q, worker.Config{ArtifactsDir: artifactsDir})
+	srv := httptest.NewServer(server.Handler())
+	defer srv.Close()
+	client, err := worker.NewClient(srv.URL, nil, nil, nil)
+	require.NoError(t, err)
+
+	ids := make(map[string]uuid.UUID)
+	for _, tenant := range []string{"acme", "../evil"} {
+		id, err := server.EnqueueOSBuild(context.Background(), "x86_64", &worker.OSBuildJob{Tenant: tenant}, worker.PriorityBatch, tenant)
+		require.NoError(t, err)
+		ids[tenant] = id
+
+		job, err := client.RequestJob([]string{"osbuild"}, "x86_64")
+		require.NoError(t, err)
+		require.NoError(t, job.UploadArtifact("disk.img", strings.NewReader(tenant)))
+		require.NoError(t, job.Update(&worker.OSBuildJobResult{Success: true}))
+	}
+
+	// tenants are escaped, so that their directories are always in
+	// the directory of all tenants
+	require.FileExists(t, path.Join(artifactsDir, "tenants", "acme", ids["acme"].String(), "disk.img"))
+	require.FileExists(t, path.Join(artifactsDir, "tenants", "%2E%2E%2Fevil", ids["../evil"].String(), "disk.img"))
+
+	reader, _, err := server.JobArtifact(ids["acme"], "disk.img")
+	require.NoError(t, err)
+	contents, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "acme", string(contents))
+
+	_, _, err = server.JobArtifact(ids["acme"], "..")
+	require.Error(t, err)
+	_, _, err = server.JobArtifact(ids["acme"], "../../%2E%2E%2Fevil/"+ids["../evil"].String()+"/disk.img")
+	require.Error(t, err)
+
+	acme, other := "acme", "other"
+	jobs, _, err := server.ListJobs(jobqueue.JobFilter{Tenant: &acme, Types: []string{"osbuild:x86_64"}})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{ids["acme"]}, jobs)
+	jobs, _, err = server.ListJobs(jobqueue.JobFilter{Tenant: &other, Types: []string{"osbuild:x86_64"}})
+	require.NoError(t, err)
+	require.Empty(t, jobs)
+
+	require.NoError(t, server.DeleteArtifacts(ids["acme"]))
+	require.NoDirExists(t, path.Join(artifactsDir, "tenants", "acme", ids["acme"].String()))
+	require.DirExists(t, path.Join(artifactsDir, "tenants", "%2E%2E%2Fevil", ids["../evil"].String()))
+}
+
+func TestMoveArtifactsToTenants(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "worker-tests-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	require.NoError(t, os.Mkdir(path.Join(tempdir, "jobs"), 0700))
+	q, err := fsjobqueue.New(path.Join(tempdir, "jobs"))
+	require.NoError(t, err)
+	withTenant, err := q.Enqueue("osbuild:x86_64", &worker.OSBuildJob{Tenant: "acme"}, nil, 0)
+	require.NoError(t, err)
+	withoutTenant, err := q.Enqueue("osbuild:x86_64", &worker.OSBuildJob{}, nil, 0)
+	require.NoError(t, err)
+
+	// artifacts kept by older versions
+	artifactsDir := path.Join(tempdir, "artifacts")
+	for _, id := range []uuid.UUID{withTenant, withoutTenant} {
+		require.NoError(t, os.MkdirAll(path.Join(artifactsDir, id.String()), 0700))
+		require.NoError(t, ioutil.WriteFile(path.Join(artifactsDir, id.String(), "image.raw"), []byte("image"), 0600))
+	}
+
+	_ = worker.NewServer(nil, q, worker.Config{ArtifactsDir: artifactsDir})
+	require.FileExists(t, path.Join(artifactsDir, "tenants", "acme", withTenant.String(), "image.raw"))
+	require.NoDirExists(t, path.Join(artifactsDir, withTenant.String()))
+	require.FileExists(t, path.Join(artifactsDir, withoutTenant.String(), "image.raw"))
+}
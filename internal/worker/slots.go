@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Limits the number of images which are built at the same time.
+//
+// Requests for builds reserve a slot before dequeueing one, so that concurrent
+// requests never dequeue more builds than there are free slots. The
+// reservation is released right away when the request gets a job of another
+// type, or none, and otherwise when the build stops running.
+type buildSlots struct {
+	max int
+
+	mu       sync.Mutex
+	reserved map[uuid.UUID]struct{}
+
+	// Closed and replaced whenever a slot is released
+	released chan struct{}
+}
+
+func newBuildSlots(max int) *buildSlots {
+	return &buildSlots{
+		max:      max,
+		reserved: make(map[uuid.UUID]struct{}),
+		released: make(chan struct{}),
+	}
+}
+
+// Reserves a slot for the job which is handed out with `token`. Returns false
+// if all slots are taken, and a channel which is closed when one of them is
+// released.
+func (b *buildSlots) reserve(token uuid.UUID) (bool, <-chan struct{}) {
+	if b.max <= 0 {
+		return true, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.reserved) >= b.max {
+		return false, b.released
+	}
+	b.reserved[token] = struct{}{}
+	return true, nil
+}
+
+// Releases the slot of `token`, if it has one.
+func (b *buildSlots) release(token uuid.UUID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.reserved[token]; !ok {
+		return
+	}
+	delete(b.reserved, token)
+	close(b.released)
+	b.released = make(chan struct{})
+}
+
+// Returns whether `jobType` builds an image, and is therefore subject to the
+// limit.
+func isBuildJobType(jobType string) bool {
+	switch baseJobType(jobType) {
+	case "osbuild", "osbuild-koji":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/osbuild/osbuild-composer/internal/jobqueue"
+)
+
+// Running jobs are checked for having timed out at least this often, and
+// twice within the shortest timeout
+const deadlineCheckInterval = 10 * time.Second
+
+// Regularly look for running jobs which have passed their deadline and fail
+// them, until the server is closed.
+func (s *Server) watchDeadlines() {
+	interval := deadlineCheckInterval
+	for _, timeout := range s.config.JobTimeouts {
+		if timeout > 0 && timeout/2 < interval {
+			interval = timeout / 2
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			for token, jobId := range s.removeTimedOutJobs() {
+				err := s.handleTimedOutJob(token, jobId)
+				if err != nil {
+					s.jobLoggerByID(jobId).Errorf("Error handling timed out job: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// Removes all jobs which have passed their deadline from the set of running
+// jobs and returns them. Their workers are told that the jobs were canceled,
+// so that they stop them.
+func (s *Server) removeTimedOutJobs() map[uuid.UUID]uuid.UUID {
+	s.runningMutex.Lock()
+	defer s.runningMutex.Unlock()
+
+	timedOut := make(map[uuid.UUID]uuid.UUID)
+	for token, deadline := range s.deadlines {
+		if time.Now().After(deadline) {
+			timedOut[token] = s.running[token]
+			delete(s.running, token)
+			delete(s.heartbeats, token)
+			delete(s.deadlines, token)
+			delete(s.progress, token)
+			delete(s.logs, token)
+			delete(s.digests, token)
+			s.revoked[token] = struct{}{}
+			s.notifyCancellation(token)
+		}
+	}
+
+	return timedOut
+}
+
+func (s *Server) handleTimedOutJob(token, jobId uuid.UUID) error {
+	logger := s.jobLoggerByID(jobId)
+
+	if s.config.ArtifactsDir != "" {
+		err := os.RemoveAll(path.Join(s.config.ArtifactsDir, "tmp", token.String()))
+		if err != nil {
+			logger.Errorf("Error removing artifacts of timed out job: %v", err)
+		}
+	}
+
+	jobType, _, _, err := s.jobs.Job(jobId)
+	if err != nil {
+		return err
+	}
+	timeout := s.config.JobTimeouts[baseJobType(jobType)]
+
+	logger.Warnf("Job timed out after %s, failing it", timeout)
+	err = s.failJob(jobId, fmt.Sprintf("job timed out after %s", timeout))
+	// The job might have been canceled in the meantime
+	if err == jobqueue.ErrCanceled {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	s.observeBuildDuration(jobId)
+	return nil
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/osbuild/osbuild-composer/internal/tracing"
+)
+
+// The arguments of jobs carry the context of the span which enqueued them in
+// this field, so that spans recorded while running them are part of the same
+// trace. Job types don't need to declare it.
+const traceparentField = "traceparent"
+
+// withTraceparent returns `args` with the context of the span in `ctx`, or
+// `args` unchanged if there is no span or they are not a JSON object.
+func withTraceparent(ctx context.Context, args interface{}) interface{} {
+	traceparent := tracing.Traceparent(ctx)
+	if traceparent == "" {
+		return args
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return args
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return args
+	}
+	fields[traceparentField], err = json.Marshal(traceparent)
+	if err != nil {
+		return args
+	}
+
+	withTrace, err := json.Marshal(fields)
+	if err != nil {
+		return args
+	}
+	return json.RawMessage(withTrace)
+}
+
+// JobContext returns a context carrying the span context which `job` was
+// enqueued with, if any. Spans started from it are part of the trace of the
+// request which enqueued the job.
+func JobContext(ctx context.Context, job Job) context.Context {
+	var args struct {
+		Traceparent string `json:"traceparent"`
+	}
+	if err := job.Args(&args); err != nil || args.Traceparent == "" {
+		return ctx
+	}
+	return contextWithTraceparent(ctx, args.Traceparent)
+}
+
+func contextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	traceCtx, err := tracing.ContextWithTraceparent(ctx, traceparent)
+	if err != nil {
+		log.Printf("Ignoring trace context of job: %v", err)
+	}
+	return traceCtx
+}
+
+// traceQueueWait records the time the job with `args` waited in the queue
+// between `queued` and `started`.
+func (s *Server) traceQueueWait(jobType string, args json.RawMessage, queued, started time.Time) {
+	if s.config.Tracer == nil {
+		return
+	}
+
+	var fields struct {
+		Traceparent string `json:"traceparent"`
+	}
+	if err := json.Unmarshal(args, &fields); err != nil || fields.Traceparent == "" {
+		return
+	}
+
+	ctx := contextWithTraceparent(context.Background(), fields.Traceparent)
+	_, span := s.config.Tracer.StartAt(ctx, "queue wait", trace.SpanKindConsumer, queued, attribute.String("job.type", jobType))
+	span.End(trace.WithTimestamp(started))
+}
@@ -49,14 +49,22 @@ BuildRequires:  golang(github.com/go-chi/chi)
 BuildRequires:  golang(github.com/google/uuid)
 BuildRequires:  golang(github.com/julienschmidt/httprouter)
 BuildRequires:  golang(github.com/getkin/kin-openapi/openapi3)
+BuildRequires:  golang(github.com/getsentry/sentry-go)
 BuildRequires:  golang(github.com/kolo/xmlrpc)
 BuildRequires:  golang(github.com/labstack/echo/v4)
+BuildRequires:  golang(github.com/lib/pq)
+BuildRequires:  golang(github.com/prometheus/client_golang/prometheus)
 BuildRequires:  golang(github.com/gobwas/glob)
+BuildRequires:  golang(github.com/golang-jwt/jwt)
 BuildRequires:  golang(github.com/google/go-cmp/cmp)
 BuildRequires:  golang(github.com/gophercloud/gophercloud)
+BuildRequires:  golang(github.com/streadway/amqp)
 BuildRequires:  golang(github.com/stretchr/testify/assert)
 BuildRequires:  golang(github.com/ubccr/kerby)
 BuildRequires:  golang(github.com/vmware/govmomi)
+BuildRequires:  golang(go.opentelemetry.io/otel)
+BuildRequires:  golang(go.opentelemetry.io/otel/exporters/otlp)
+BuildRequires:  golang(go.opentelemetry.io/otel/sdk)
 BuildRequires:  golang(cloud.google.com/go)
 %endif
 
@@ -117,6 +125,8 @@ export GOFLAGS=-mod=vendor
 
 %gobuild -o _bin/osbuild-composer %{goipath}/cmd/osbuild-composer
 %gobuild -o _bin/osbuild-worker %{goipath}/cmd/osbuild-worker
+%gobuild -o _bin/osbuild-store-migrate %{goipath}/cmd/osbuild-store-migrate
+%gobuild -o _bin/osbuild-composer-cloud %{goipath}/cmd/osbuild-composer-cloud
 
 make man
 
@@ -149,7 +159,10 @@ go build -tags=integration -ldflags="${TEST_LDFLAGS}" -o _bin/cloud-cleaner %{go
 install -m 0755 -vd                                             %{buildroot}%{_libexecdir}/osbuild-composer
 install -m 0755 -vp _bin/osbuild-composer                       %{buildroot}%{_libexecdir}/osbuild-composer/
 install -m 0755 -vp _bin/osbuild-worker                         %{buildroot}%{_libexecdir}/osbuild-composer/
+install -m 0755 -vp _bin/osbuild-store-migrate                  %{buildroot}%{_libexecdir}/osbuild-composer/
 install -m 0755 -vp dnf-json                                    %{buildroot}%{_libexecdir}/osbuild-composer/
+install -m 0755 -vd                                             %{buildroot}%{_bindir}
+install -m 0755 -vp _bin/osbuild-composer-cloud                 %{buildroot}%{_bindir}/
 
 install -m 0755 -vd                                             %{buildroot}%{_datadir}/osbuild-composer/repositories
 install -m 0644 -vp repositories/*                              %{buildroot}%{_datadir}/osbuild-composer/repositories/
@@ -233,13 +246,13 @@ cd $PWD/_build/src/%{goipath}
 %endif
 
 %post
-%systemd_post osbuild-composer.service osbuild-composer.socket osbuild-composer-api.socket osbuild-remote-worker.socket
+%systemd_post osbuild-composer.service osbuild-composer.socket osbuild-composer-api.socket osbuild-composer-ostree.socket osbuild-remote-worker.socket
 
 %preun
-%systemd_preun osbuild-composer.service osbuild-composer.socket osbuild-composer-api.socket osbuild-remote-worker.socket
+%systemd_preun osbuild-composer.service osbuild-composer.socket osbuild-composer-api.socket osbuild-composer-ostree.socket osbuild-remote-worker.socket
 
 %postun
-%systemd_postun_with_restart osbuild-composer.service osbuild-composer.socket osbuild-composer-api.socket osbuild-remote-worker.socket
+%systemd_postun_with_restart osbuild-composer.service osbuild-composer.socket osbuild-composer-api.socket osbuild-composer-ostree.socket osbuild-remote-worker.socket
 
 %files
 %license LICENSE
@@ -248,24 +261,49 @@ cd $PWD/_build/src/%{goipath}
 %{_unitdir}/osbuild-composer.service
 %{_unitdir}/osbuild-composer.socket
 %{_unitdir}/osbuild-composer-api.socket
+%{_unitdir}/osbuild-composer-ostree.socket
 %{_unitdir}/osbuild-local-worker.socket
 %{_unitdir}/osbuild-remote-worker.socket
 %{_sysusersdir}/osbuild-composer.conf
 
 %package core
 Summary:    The core osbuild-composer binary
+Requires:   ostree
+Requires:   %{name}-dnf-json = %{version}-%{release}
 
 %description core
 The core osbuild-composer binary. This is suitable both for spawning in containers and by systemd.
 
 %files core
 %{_libexecdir}/osbuild-composer/osbuild-composer
-%{_libexecdir}/osbuild-composer/dnf-json
+%{_libexecdir}/osbuild-composer/osbuild-store-migrate
 %{_datadir}/osbuild-composer/
 
+%package cloud-cli
+Summary:    Command line client of the cloud API of osbuild-composer
+
+%description cloud-cli
+A command line client for administering a remote osbuild-composer through its
+cloud API, over TLS with client certificates or OAuth.
+
+%files cloud-cli
+%{_bindir}/osbuild-composer-cloud
+
+%package dnf-json
+Summary:    The dnf-json binary used by osbuild-composer and the workers
+Requires:   python3-dnf
+
+%description dnf-json
+The dnf-json binary, which is used by osbuild-composer and the workers to
+depsolve packages.
+
+%files dnf-json
+%{_libexecdir}/osbuild-composer/dnf-json
+
 %package worker
 Summary:    The worker for osbuild-composer
 Requires:   systemd
+Requires:   %{name}-dnf-json = %{version}-%{release}
 Requires:   qemu-img
 Requires:   osbuild >= 28
 Requires:   osbuild-ostree >= 28
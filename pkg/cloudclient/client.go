@@ -0,0 +1,238 @@
+package cloudclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Composer is a client of version 2 of the cloud API of osbuild-composer. On
+// top of the generated client, it has helpers for starting composes safely and
+// for waiting until they finished.
+type Composer struct {
+	*ClientWithResponses
+
+	// How often WaitForCompose() checks the status of a compose
+	PollInterval time.Duration
+
+	// How often StartCompose() retries a request which failed because of
+	// the network or the server
+	Retries int
+}
+
+// Error is returned for responses of composer which report an error.
+type Error struct {
+	StatusCode int
+	Message    string
+
+	// The code of the error, which is empty for errors of older versions
+	// of composer
+	Code string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("composer returned %d (%s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("composer returned %d: %s", e.StatusCode, e.Message)
+}
+
+// ComposeFailedError is returned by WaitForCompose() for composes which
+// failed.
+type ComposeFailedError struct {
+	ID     string
+	Reason string
+
+	// Why the compose failed, nil for older versions of composer
+	JobError *ErrorInfo
+}
+
+func (e *ComposeFailedError) Error() string {
+	switch {
+	case e.JobError != nil:
+		return fmt.Sprintf("compose %s failed (%s): %s", e.ID, e.JobError.Code, e.JobError.Reason)
+	case e.Reason != "":
+		return fmt.Sprintf("compose %s failed: %s", e.ID, e.Reason)
+	default:
+		return fmt.Sprintf("compose %s failed", e.ID)
+	}
+}
+
+type contextKey int
+
+const idempotencyKeyKey contextKey = iota
+
+// New returns a client of the cloud API at `server`, which is the URL of the
+// API including its path, such as
+// "https://composer.example.com/api/image-builder-composer/v2".
+func New(server string, opts ...ClientOption) (*Composer, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	editor := client.RequestEditor
+	client.RequestEditor = func(ctx context.Context, req *http.Request) error {
+		if key, ok := ctx.Value(idempotencyKeyKey).(string); ok {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		if editor != nil {
+			return editor(ctx, req)
+		}
+		return nil
+	}
+
+	return &Composer{
+		ClientWithResponses: &ClientWithResponses{client},
+		PollInterval:        10 * time.Second,
+		Retries:             3,
+	}, nil
+}
+
+// WithBearerToken makes the client authenticate with `token`. For tokens
+// which expire, pass an HTTP client which refreshes them to WithHTTPClient()
+// instead.
+func WithBearerToken(token string) ClientOption {
+	return WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// StartCompose requests the compose `request` and returns its id. The
+// request carries an idempotency key, so that retrying it never starts a
+// second compose: requests which fail because of the network or a server
+// error are retried up to `Retries` times.
+func (c *Composer) StartCompose(ctx context.Context, request ComposeRequest) (string, error) {
+	ctx = context.WithValue(ctx, idempotencyKeyKey, uuid.New().String())
+
+	for attempt := 0; ; attempt++ {
+		wait := time.Second << attempt
+
+		response, err := c.ComposeWithResponse(ctx, ComposeJSONRequestBody(request))
+		if err == nil && response.JSON201 != nil {
+			return response.JSON201.Id, nil
+		}
+		if err == nil {
+			err = ResponseError(response.HTTPResponse, response.Body)
+			if !retryable(response.HTTPResponse) {
+				return "", err
+			}
+			if retryAfter, e := strconv.Atoi(response.HTTPResponse.Header.Get("Retry-After")); e == nil {
+				wait = time.Duration(retryAfter) * time.Second
+			}
+		}
+		if attempt >= c.Retries || ctx.Err() != nil {
+			return "", err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Status returns the status of the compose `id`.
+func (c *Composer) Status(ctx context.Context, id string) (*ComposeStatus, error) {
+	response, err := c.ComposeStatusWithResponse(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if response.JSON200 == nil {
+		return nil, ResponseError(response.HTTPResponse, response.Body)
+	}
+	return response.JSON200, nil
+}
+
+// WaitForCompose checks the status of the compose `id` every `PollInterval`
+// until it has finished, and returns its final status. It returns a
+// *ComposeFailedError if the compose failed, and the error of `ctx` along with
+// the last status it got if `ctx` is done before the compose.
+func (c *Composer) WaitForCompose(ctx context.Context, id string) (*ComposeStatus, error) {
+	ticker := time.NewTicker(c.PollInterval)
+	defer ticker.Stop()
+
+	var status *ComposeStatus
+	for {
+		current, err := c.Status(ctx, id)
+		if ctx.Err() != nil {
+			return status, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		status = current
+
+		switch status.ImageStatus.Status {
+		case ImageStatusValue_success:
+			return status, nil
+		case ImageStatusValue_failure:
+			failed := &ComposeFailedError{ID: id}
+			if status.ImageStatus.Error != nil {
+				failed.Reason = *status.ImageStatus.Error
+			}
+			failed.JobError = status.ImageStatus.JobError
+			return status, failed
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckBlueprint returns the problems which the customizations of `request`
+// would cause for each of its images, without starting a compose.
+func (c *Composer) CheckBlueprint(ctx context.Context, request ComposeRequest) (*BlueprintValidation, error) {
+	response, err := c.ValidateBlueprintWithResponse(ctx, ValidateBlueprintJSONRequestBody(request))
+	if err != nil {
+		return nil, err
+	}
+	if response.JSON200 == nil {
+		return nil, ResponseError(response.HTTPResponse, response.Body)
+	}
+	return response.JSON200, nil
+}
+
+// ResponseError returns the *Error of `response` with `body`, which is an
+// ErrorInfo, or the message of the error for older versions of composer.
+func ResponseError(response *http.Response, body []byte) error {
+	var info ErrorInfo
+	if strings.HasPrefix(response.Header.Get("Content-Type"), "application/json") && json.Unmarshal(body, &info) == nil {
+		return &Error{
+			StatusCode: response.StatusCode,
+			Message:    info.Reason,
+			Code:       info.Code,
+		}
+	}
+	return &Error{
+		StatusCode: response.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+}
+
+// retryable returns whether the request of `response` might succeed when it
+// is sent again.
+func retryable(response *http.Response) bool {
+	switch {
+	case response.StatusCode >= 500:
+		return true
+	case response.StatusCode == http.StatusConflict:
+		// the first request with the idempotency key is still running
+		return true
+	case response.StatusCode == http.StatusTooManyRequests:
+		// rate limited, unlike requests over the quota of the tenant
+		return response.Header.Get("Retry-After") != ""
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package cloudclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/osbuild/osbuild-composer/pkg/cloudclient"
+)
+
+func newTestComposer(t *testing.T, handler http.HandlerFunc) *cloudclient.Composer {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := cloudclient.New(server.URL+"/api/image-builder-composer/v2", cloudclient.WithBearerToken("token"))
+	require.NoError(t, err)
+	c.PollInterval = time.Millisecond
+	return c
+}
+
+func TestStartCompose(t *testing.T) {
+	var keys []string
+	c := newTestComposer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/image-builder-composer/v2/compose", r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var request cloudclient.ComposeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		require.Equal(t, "fedora-33", request.Distro)
+
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": "0c5f0f7c-5d1b-4dd3-8d1b-1d6e2e8a4a1f"}`))
+	})
+
+	id, err := c.StartCompose(context.Background(), cloudclient.ComposeRequest{Distro: "fedora-33"})
+	require.NoError(t, err)
+	require.Equal(t, "0c5f0f7c-5d1b-4dd3-8d1b-1d6e2e8a4a1f", id)
+
+	// the retry carries the same idempotency key
+	require.Len(t, keys, 2)
+	require.NotEmpty(t, keys[0])
+	require.Equal(t, keys[0], keys[1])
+}
+
+func TestStartComposeError(t *testing.T) {
+	requests := 0
+	c := newTestComposer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "Unsupported distribution: unknown", http.StatusBadRequest)
+	})
+
+	_, err := c.StartCompose(context.Background(), cloudclient.ComposeRequest{Distro: "unknown"})
+	require.Equal(t, &cloudclient.Error{StatusCode: http.StatusBadRequest, Message: "Unsupported distribution: unknown"}, err)
+	require.Equal(t, 1, requests)
+}
+
+func TestStartComposeErrorInfo(t *testing.T) {
+	c := newTestComposer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code": "bad-request", "reason": "Unsupported distribution: unknown"}`))
+	})
+
+	_, err := c.StartCompose(context.Background(), cloudclient.ComposeRequest{Distro: "unknown"})
+	require.Equal(t, &cloudclient.Error{StatusCode: http.StatusBadRequest, Message: "Unsupported distribution: unknown", Code: "bad-request"}, err)
+	require.EqualError(t, err, "composer returned 400 (bad-request): Unsupported distribution: unknown")
+}
+
+func TestWaitForCompose(t *testing.T) {
+	statuses := []string{"pending", "building", "uploading", "success"}
+	c := newTestComposer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/image-builder-composer/v2/compose/42", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"image_status": {"status": "` + statuses[0] + `"}}`))
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+	})
+
+	status, err := c.WaitForCompose(context.Background(), "42")
+	require.NoError(t, err)
+	require.Equal(t, cloudclient.ImageStatusValue_success, status.ImageStatus.Status)
+}
+
+func TestWaitForComposeFailed(t *testing.T) {
+	c := newTestComposer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"image_status": {"status": "failure", "error": "depsolve failed", "job_error": {"code": "packages-not-found", "reason": "depsolve failed"}}}`))
+	})
+
+	_, err := c.WaitForCompose(context.Background(), "42")
+	require.Equal(t, &cloudclient.ComposeFailedError{
+		ID:       "42",
+		Reason:   "depsolve failed",
+		JobError: &cloudclient.ErrorInfo{Code: "packages-not-found", Reason: "depsolve failed"},
+	}, err)
+	require.EqualError(t, err, "compose 42 failed (packages-not-found): depsolve failed")
+}
+
+func TestWaitForComposeCanceled(t *testing.T) {
+	c := newTestComposer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"image_status": {"status": "building"}}`))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	status, err := c.WaitForCompose(ctx, "42")
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, cloudclient.ImageStatusValue_building, status.ImageStatus.Status)
+}
@@ -0,0 +1,3 @@
+//go:generate go run github.com/deepmap/oapi-codegen/cmd/oapi-codegen --package=cloudclient --generate types,client -o openapi.gen.go ../../internal/cloudapi/v2/openapi.yml
+
+package cloudclient
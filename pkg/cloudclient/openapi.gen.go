@@ -0,0 +1,4161 @@
+// Package cloudclient provides primitives to interact the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen DO NOT EDIT.
+package cloudclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AWSAmi defines model for AWSAmi.
+type AWSAmi struct {
+	Ami    string `json:"ami"`
+	Region string `json:"region"`
+}
+
+// AWSS3UploadRequestOptions defines model for AWSS3UploadRequestOptions.
+type AWSS3UploadRequestOptions struct {
+	Region string `json:"region"`
+
+	// Credentials for uploading to the bucket. Either access keys or a role
+	// is required.
+	S3 AWSUploadRequestOptionsS3 `json:"s3"`
+}
+
+// AWSS3UploadStatus defines model for AWSS3UploadStatus.
+type AWSS3UploadStatus struct {
+	Url string `json:"url"`
+}
+
+// AWSTag defines model for AWSTag.
+type AWSTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// AWSUploadRequestOptions defines model for AWSUploadRequestOptions.
+type AWSUploadRequestOptions struct {
+
+	// Credentials for importing and registering the image. The credentials
+	// of the s3 options are used if these are empty.
+	Ec2    AWSUploadRequestOptionsEc2 `json:"ec2"`
+	Region string                     `json:"region"`
+
+	// Credentials for uploading to the bucket. Either access keys or a role
+	// is required.
+	S3 AWSUploadRequestOptionsS3 `json:"s3"`
+}
+
+// AWSUploadRequestOptionsEc2 defines model for AWSUploadRequestOptionsEc2.
+type AWSUploadRequestOptionsEc2 struct {
+	AccessKeyId *string `json:"access_key_id,omitempty"`
+
+	// Regions to copy the AMI to after it has been registered in the
+	// region of the upload. The copies are tagged and shared like the
+	// AMI itself.
+	CopyToRegions *[]string `json:"copy_to_regions,omitempty"`
+
+	// External ID which the trust policy of the role requires.
+	ExternalId *string `json:"external_id,omitempty"`
+
+	// IAM role to assume for the upload, with the access keys if they
+	// are set, or else with the credentials of the worker.
+	RoleArn         *string `json:"role_arn,omitempty"`
+	SecretAccessKey *string `json:"secret_access_key,omitempty"`
+
+	// Session token of temporary access keys.
+	SessionToken      *string   `json:"session_token,omitempty"`
+	ShareWithAccounts *[]string `json:"share_with_accounts,omitempty"`
+	SnapshotName      *string   `json:"snapshot_name,omitempty"`
+
+	// Tags of the AMIs and their snapshots, in addition to their name.
+	Tags *[]AWSTag `json:"tags,omitempty"`
+}
+
+// AWSUploadRequestOptionsS3 defines model for AWSUploadRequestOptionsS3.
+type AWSUploadRequestOptionsS3 struct {
+	AccessKeyId *string `json:"access_key_id,omitempty"`
+	Bucket      string  `json:"bucket"`
+
+	// External ID which the trust policy of the role requires.
+	ExternalId *string `json:"external_id,omitempty"`
+
+	// IAM role to assume for the upload, with the access keys if they
+	// are set, or else with the credentials of the worker.
+	RoleArn         *string `json:"role_arn,omitempty"`
+	SecretAccessKey *string `json:"secret_access_key,omitempty"`
+
+	// Session token of temporary access keys.
+	SessionToken *string `json:"session_token,omitempty"`
+}
+
+// AWSUploadStatus defines model for AWSUploadStatus.
+type AWSUploadStatus struct {
+	Ami string `json:"ami"`
+
+	// The AMIs of the image in all regions: the one in region,
+	// followed by its copies in the regions of copy_to_regions.
+	Amis   *[]AWSAmi `json:"amis,omitempty"`
+	Region string    `json:"region"`
+}
+
+// ArchitectureImageTypes defines model for ArchitectureImageTypes.
+type ArchitectureImageTypes struct {
+	Architecture string          `json:"architecture"`
+	ImageTypes   []ImageTypeInfo `json:"image_types"`
+}
+
+// Artifact defines model for Artifact.
+type Artifact struct {
+	Name string `json:"name"`
+
+	// Hex-encoded SHA-256 digest of the file, which composer computed
+	// when it received it. Not set for files received by older versions
+	// of composer.
+	Sha256 *string `json:"sha256,omitempty"`
+
+	// Size of the file in bytes
+	Size int64 `json:"size"`
+}
+
+// AzureUploadRequestOptions defines model for AzureUploadRequestOptions.
+type AzureUploadRequestOptions struct {
+
+	// Name of the uploaded image. It must be unique in the given resource group.
+	// If name is omitted from the request, a random one based on a UUID is
+	// generated.
+	ImageName *string `json:"image_name,omitempty"`
+
+	// Location where the image should be uploaded and registered. This link explain
+	// how to list all locations:
+	// https://docs.microsoft.com/en-us/cli/azure/account?view=azure-cli-latest#az_account_list_locations'
+	Location string `json:"location"`
+
+	// Name of the resource group where the image should be uploaded.
+	ResourceGroup string `json:"resource_group"`
+
+	// ID of subscription where the image should be uploaded.
+	SubscriptionId string `json:"subscription_id"`
+
+	// ID of the tenant where the image should be uploaded. This link explains how
+	// to find it in the Azure Portal:
+	// https://docs.microsoft.com/en-us/azure/active-directory/fundamentals/active-directory-how-to-find-tenant
+	TenantId string `json:"tenant_id"`
+}
+
+// AzureUploadStatus defines model for AzureUploadStatus.
+type AzureUploadStatus struct {
+	ImageName string `json:"image_name"`
+}
+
+// BlueprintDiagnostic defines model for BlueprintDiagnostic.
+type BlueprintDiagnostic struct {
+	Architecture string `json:"architecture"`
+
+	// The part of the compose request which has the problem
+	Field     *string `json:"field,omitempty"`
+	ImageType string  `json:"image_type"`
+	Message   string  `json:"message"`
+
+	// Errors make the compose fail, while warnings are about images
+	// which might not be what was intended
+	Severity string `json:"severity"`
+}
+
+// BlueprintValidation defines model for BlueprintValidation.
+type BlueprintValidation struct {
+	Diagnostics []BlueprintDiagnostic `json:"diagnostics"`
+
+	// Whether the compose request has no errors
+	Valid bool `json:"valid"`
+}
+
+// BootMode defines model for BootMode.
+type BootMode string
+
+// List of BootMode
+const (
+	BootMode_hybrid BootMode = "hybrid"
+	BootMode_legacy BootMode = "legacy"
+	BootMode_none   BootMode = "none"
+	BootMode_uefi   BootMode = "uefi"
+)
+
+// Callback defines model for Callback.
+type Callback struct {
+	Secret string `json:"secret"`
+	Url    string `json:"url"`
+}
+
+// CloneResult defines model for CloneResult.
+type CloneResult struct {
+	Id string `json:"id"`
+}
+
+// ComposeEvent defines model for ComposeEvent.
+type ComposeEvent struct {
+	ComposeStatus ComposeStatus `json:"compose_status"`
+
+	// ID of the compose whose status changed.
+	Id string `json:"id"`
+
+	// Why a request or an image failed. Clients may rely on the code,
+	// which doesn't change between versions, but not on the reason. The
+	// codes of failed images are:
+	//   - canceled
+	//   - dependency-failed: the details are the error of the job the
+	//     image depended on, for example depsolving its packages
+	//   - invalid-job
+	//   - packages-not-found
+	//   - depsolve-failed
+	//   - repository-unavailable
+	//   - content-verification-failed
+	//   - ostree-ref-resolution-failed
+	//   - manifest-generation-failed
+	//   - osbuild-failed: the details name the stage which failed
+	//   - worker-error
+	//   - upload-failed: the details name the target
+	//   - koji-init-failed, koji-upload-failed, koji-import-failed,
+	//     koji-tag-failed
+	// The codes of failed requests are bad-request, unauthorized,
+	// forbidden, not-found, conflict, unsupported-media-type,
+	// unprocessable-entity, too-many-requests, and internal-error.
+	JobError *ErrorInfo `json:"job_error,omitempty"`
+
+	// Why the compose failed, if it has.
+	Reason *string          `json:"reason,omitempty"`
+	Status ImageStatusValue `json:"status"`
+}
+
+// ComposeListItem defines model for ComposeListItem.
+type ComposeListItem struct {
+
+	// When the compose was requested
+	CreatedAt time.Time        `json:"created_at"`
+	Id        string           `json:"id"`
+	Status    ImageStatusValue `json:"status"`
+}
+
+// ComposeLog defines model for ComposeLog.
+type ComposeLog struct {
+
+	// Whether the build has finished, after which its log doesn't
+	// grow anymore
+	Finished bool `json:"finished"`
+
+	// The part of the log starting at the requested offset, or the
+	// complete log once the build has finished
+	Log string `json:"log"`
+
+	// The offset to request the rest of the log from
+	Offset int `json:"offset"`
+}
+
+// ComposeManifests defines model for ComposeManifests.
+type ComposeManifests struct {
+
+	// The manifest of each image, or null for images whose manifest
+	// hasn't been generated yet or couldn't be generated
+	Manifests []map[string]interface{} `json:"manifests"`
+}
+
+// ComposeMetadata defines model for ComposeMetadata.
+type ComposeMetadata struct {
+
+	// The files which the worker sent to composer after building the
+	// image, such as the image itself
+	Artifacts *[]Artifact `json:"artifacts,omitempty"`
+
+	// How the image boots: with the legacy boot loader of BIOS or s390x
+	// machines, with UEFI, with both (hybrid), or not at all
+	BootMode            *BootMode                  `json:"boot_mode,omitempty"`
+	ContentVerification *ContentVerificationResult `json:"content_verification,omitempty"`
+
+	// Minor release of the distribution the image was built from, taken
+	// from the version of its release package
+	MinorRelease *string `json:"minor_release,omitempty"`
+
+	// ID (hash) of the built commit
+	OstreeCommit *string `json:"ostree_commit,omitempty"`
+
+	// Package list including NEVRA
+	Packages *[]PackageMetadata `json:"packages,omitempty"`
+}
+
+// ComposeRequest defines model for ComposeRequest.
+type ComposeRequest struct {
+
+	// A URL to which a ComposeEvent is POSTed whenever the status of the
+	// compose changes, from pending until it succeeded or failed. Events
+	// are signed with an HMAC-SHA256 of their body with the secret as
+	// key, which is sent hex-encoded in the X-Composer-Signature header
+	// as 'sha256=<signature>'.
+	Callback *Callback `json:"callback,omitempty"`
+
+	// How the content of the images is verified. By default, only the
+	// packages of repositories with check_gpg are verified. In strict
+	// mode, all repositories must have a gpg_key, which is used to verify
+	// both the signature of the metadata of the repository when
+	// depsolving and the signatures of all of its packages. A package
+	// whose signature cannot be verified fails the compose.
+	ContentVerification *ContentVerification `json:"content_verification,omitempty"`
+	Customizations      *Customizations      `json:"customizations,omitempty"`
+	Distro              string               `json:"distro"`
+
+	// Build the images from the Extended Update Support repositories
+	// of the minor release, which must be set. The content/dist paths
+	// of Red Hat CDN repositories are replaced by content/eus.
+	Eus *bool `json:"eus,omitempty"`
+
+	// Images to build, each with its own upload target. All of them
+	// are built from the same customizations.
+	ImageRequests []ImageRequest `json:"image_requests"`
+
+	// Minor release of a RHEL distribution the images are built from.
+	// It replaces the $releasever variable in the URLs of the
+	// repositories, which defaults to the major release.
+	MinorRelease *string `json:"minor_release,omitempty"`
+}
+
+// ComposeResult defines model for ComposeResult.
+type ComposeResult struct {
+	Id string `json:"id"`
+}
+
+// ComposeStatus defines model for ComposeStatus.
+type ComposeStatus struct {
+	ImageStatus ImageStatus `json:"image_status"`
+
+	// Statuses of the images of a compose with more than one image
+	// request, in the order of the requests. image_status then holds
+	// the status of the whole compose, which failed if any of its
+	// images failed.
+	ImageStatuses *[]ImageStatus `json:"image_statuses,omitempty"`
+}
+
+// ContentVerification defines model for ContentVerification.
+type ContentVerification string
+
+// List of ContentVerification
+const (
+	ContentVerification__default ContentVerification = "default"
+	ContentVerification_strict   ContentVerification = "strict"
+)
+
+// ContentVerificationResult defines model for ContentVerificationResult.
+type ContentVerificationResult struct {
+
+	// How the content of the images is verified. By default, only the
+	// packages of repositories with check_gpg are verified. In strict
+	// mode, all repositories must have a gpg_key, which is used to verify
+	// both the signature of the metadata of the repository when
+	// depsolving and the signatures of all of its packages. A package
+	// whose signature cannot be verified fails the compose.
+	Mode ContentVerification `json:"mode"`
+
+	// Number of packages of the image which were installed without
+	// verifying their signatures
+	UnverifiedPackages int `json:"unverified_packages"`
+
+	// Number of packages of the image whose signatures were verified
+	// when they were installed
+	VerifiedPackages int `json:"verified_packages"`
+}
+
+// Customizations defines model for Customizations.
+type Customizations struct {
+	Packages     *[]string     `json:"packages,omitempty"`
+	Subscription *Subscription `json:"subscription,omitempty"`
+	Users        *[]User       `json:"users,omitempty"`
+}
+
+// Distribution defines model for Distribution.
+type Distribution struct {
+	Architectures []string `json:"architectures"`
+	Name          string   `json:"name"`
+}
+
+// DistributionImageTypes defines model for DistributionImageTypes.
+type DistributionImageTypes struct {
+	Architectures []ArchitectureImageTypes `json:"architectures"`
+	Distribution  string                   `json:"distribution"`
+}
+
+// ErrorInfo defines model for ErrorInfo.
+type ErrorInfo struct {
+	Code string `json:"code"`
+
+	// More information about the error, depending on its code
+	Details *interface{} `json:"details,omitempty"`
+
+	// The end of the output of the osbuild stage which failed
+	OsbuildError *string `json:"osbuild_error,omitempty"`
+	Reason       string  `json:"reason"`
+}
+
+// GCPUploadRequestOptions defines model for GCPUploadRequestOptions.
+type GCPUploadRequestOptions struct {
+
+	// Name of an existing STANDARD Storage class Bucket.
+	Bucket string `json:"bucket"`
+
+	// List of guest OS features to enable on the imported Compute Engine
+	// image. See https://cloud.google.com/compute/docs/images/create-delete-deprecate-private-images#guest-os-features.
+	GuestOsFeatures *[]string `json:"guest_os_features,omitempty"`
+
+	// The name to use for the imported and shared Compute Engine image.
+	// The image name must be unique within the GCP project, which is used
+	// for the OS image upload and import. If not specified a random
+	// 'composer-api-<uuid>' string is used as the image name.
+	ImageName *string `json:"image_name,omitempty"`
+
+	// The GCP region where the OS image will be imported to and shared from.
+	// The value must be a valid GCP location. See https://cloud.google.com/storage/docs/locations.
+	// If not specified, the multi-region location closest to the source
+	// (source Storage Bucket location) is chosen automatically.
+	Region *string `json:"region,omitempty"`
+
+	// List of valid Google accounts to share the imported Compute Engine image with.
+	// Each string must contain a specifier of the account type. Valid formats are:
+	//   - 'user:{emailid}': An email address that represents a specific
+	//     Google account. For example, 'alice@example.com'.
+	//   - 'serviceAccount:{emailid}': An email address that represents a
+	//     service account. For example, 'my-other-app@appspot.gserviceaccount.com'.
+	//   - 'group:{emailid}': An email address that represents a Google group.
+	//     For example, 'admins@example.com'.
+	//   - 'domain:{domain}': The G Suite domain (primary) that represents all
+	//     the users of that domain. For example, 'google.com' or 'example.com'.
+	// If not specified, the imported Compute Engine image is not shared with any
+	// account.
+	ShareWithAccounts *[]string `json:"share_with_accounts,omitempty"`
+
+	// List of IDs of GCP projects to share the imported Compute Engine image
+	// with, for example the service projects of a Shared VPC. Instances in
+	// these projects, including those created by managed instance groups,
+	// can be created from the image.
+	ShareWithProjects *[]string `json:"share_with_projects,omitempty"`
+}
+
+// GCPUploadStatus defines model for GCPUploadStatus.
+type GCPUploadStatus struct {
+	ImageName string `json:"image_name"`
+	ProjectId string `json:"project_id"`
+}
+
+// GenericS3UploadRequestOptions defines model for GenericS3UploadRequestOptions.
+type GenericS3UploadRequestOptions struct {
+
+	// PEM-encoded certificates to trust instead of the system's ones
+	// when connecting to the endpoint.
+	CaBundle *string `json:"ca_bundle,omitempty"`
+
+	// URL of an S3-compatible object storage service, such as MinIO or
+	// Ceph RGW. Buckets are addressed in the path of this URL.
+	Endpoint string `json:"endpoint"`
+	Region   string `json:"region"`
+
+	// Credentials for uploading to the bucket. Either access keys or a role
+	// is required.
+	S3 AWSUploadRequestOptionsS3 `json:"s3"`
+
+	// Don't verify the endpoint's certificate. Only use this for testing.
+	SkipSslVerification *bool `json:"skip_ssl_verification,omitempty"`
+}
+
+// GenericS3UploadStatus defines model for GenericS3UploadStatus.
+type GenericS3UploadStatus struct {
+	Url string `json:"url"`
+}
+
+// ImageProgress defines model for ImageProgress.
+type ImageProgress struct {
+	Current  int     `json:"current"`
+	Percent  int     `json:"percent"`
+	Pipeline *string `json:"pipeline,omitempty"`
+	Stage    string  `json:"stage"`
+	Total    int     `json:"total"`
+}
+
+// ImageRequest defines model for ImageRequest.
+type ImageRequest struct {
+	Architecture string `json:"architecture"`
+
+	// Packages which must not be installed in the image, neither
+	// explicitly nor as dependencies.
+	ExcludePackages *[]string `json:"exclude_packages,omitempty"`
+	ImageType       string    `json:"image_type"`
+	Ostree          *OSTree   `json:"ostree,omitempty"`
+
+	// Repositories which are only used to install the packages of the
+	// image, in addition to the repositories. Unlike those, they are
+	// not used for the build root of the image.
+	PayloadRepositories *[]Repository `json:"payload_repositories,omitempty"`
+	Repositories        []Repository  `json:"repositories"`
+
+	// Targets to upload the image to. The image is built once and
+	// uploaded to each of them. A 'local' upload request keeps the
+	// image in composer, from where it can be downloaded with
+	// /compose/{id}/download.
+	UploadRequests []UploadRequest `json:"upload_requests"`
+}
+
+// ImageStatus defines model for ImageStatus.
+type ImageStatus struct {
+
+	// Why the image failed, if it has. The status of a compose with
+	// more than one image has the error of the first image that
+	// failed. It describes job_error, which should be used instead
+	// by clients which handle errors.
+	Error *string `json:"error,omitempty"`
+
+	// ID of an image of a compose with more than one image request.
+	// The status and metadata of the image can be requested with it
+	// like those of a compose.
+	Id *string `json:"id,omitempty"`
+
+	// Why a request or an image failed. Clients may rely on the code,
+	// which doesn't change between versions, but not on the reason. The
+	// codes of failed images are:
+	//   - canceled
+	//   - dependency-failed: the details are the error of the job the
+	//     image depended on, for example depsolving its packages
+	//   - invalid-job
+	//   - packages-not-found
+	//   - depsolve-failed
+	//   - repository-unavailable
+	//   - content-verification-failed
+	//   - ostree-ref-resolution-failed
+	//   - manifest-generation-failed
+	//   - osbuild-failed: the details name the stage which failed
+	//   - worker-error
+	//   - upload-failed: the details name the target
+	//   - koji-init-failed, koji-upload-failed, koji-import-failed,
+	//     koji-tag-failed
+	// The codes of failed requests are bad-request, unauthorized,
+	// forbidden, not-found, conflict, unsupported-media-type,
+	// unprocessable-entity, too-many-requests, and internal-error.
+	JobError *ErrorInfo `json:"job_error,omitempty"`
+
+	// Progress of an image which is being built: the stage osbuild is
+	// running, its position among all stages of the image, and the
+	// percentage of the stages which have finished. Stages which osbuild
+	// takes from its cache are skipped. The progress of an image whose
+	// build failed names the stage which failed.
+	Progress     *ImageProgress   `json:"progress,omitempty"`
+	Status       ImageStatusValue `json:"status"`
+	UploadStatus *UploadStatus    `json:"upload_status,omitempty"`
+
+	// Statuses of the uploads of an image with more than one upload
+	// request, in the order of upload_requests.
+	UploadStatuses *[]UploadStatus `json:"upload_statuses,omitempty"`
+}
+
+// ImageStatusValue defines model for ImageStatusValue.
+type ImageStatusValue string
+
+// List of ImageStatusValue
+const (
+	ImageStatusValue_building    ImageStatusValue = "building"
+	ImageStatusValue_failure     ImageStatusValue = "failure"
+	ImageStatusValue_pending     ImageStatusValue = "pending"
+	ImageStatusValue_registering ImageStatusValue = "registering"
+	ImageStatusValue_success     ImageStatusValue = "success"
+	ImageStatusValue_uploading   ImageStatusValue = "uploading"
+)
+
+// ImageTypeInfo defines model for ImageTypeInfo.
+type ImageTypeInfo struct {
+
+	// Size of the image in bytes, unless a compose requests a larger one
+	DefaultSize int64  `json:"default_size"`
+	Name        string `json:"name"`
+}
+
+// KojiComposeRequest defines model for KojiComposeRequest.
+type KojiComposeRequest struct {
+	Distro        string             `json:"distro"`
+	ImageRequests []KojiImageRequest `json:"image_requests"`
+	Koji          KojiOptions        `json:"koji"`
+	Name          string             `json:"name"`
+	Release       string             `json:"release"`
+	Version       string             `json:"version"`
+}
+
+// KojiComposeStatus defines model for KojiComposeStatus.
+type KojiComposeStatus struct {
+	ImageStatuses []ImageStatus    `json:"image_statuses"`
+	KojiBuildId   *int             `json:"koji_build_id,omitempty"`
+	Status        ImageStatusValue `json:"status"`
+}
+
+// KojiImageRequest defines model for KojiImageRequest.
+type KojiImageRequest struct {
+	Architecture string       `json:"architecture"`
+	ImageType    string       `json:"image_type"`
+	Repositories []Repository `json:"repositories"`
+}
+
+// KojiOptions defines model for KojiOptions.
+type KojiOptions struct {
+
+	// Import the images as a draft build, which doesn't claim the
+	// NVR of the build and can be promoted to a regular build later.
+	// Draft builds are tagged into draft_tag instead of tag.
+	Draft *bool `json:"draft,omitempty"`
+
+	// Tag to tag the build into once its images have been imported,
+	// if it is a draft build
+	DraftTag *string `json:"draft_tag,omitempty"`
+	Server   string  `json:"server"`
+
+	// Tag to tag the build into once its images have been imported.
+	// The build fails if it can't be tagged, but stays imported.
+	Tag *string `json:"tag,omitempty"`
+
+	// ID of the Koji task the build belongs to
+	TaskId int `json:"task_id"`
+}
+
+// LocalUploadRequestOptions defines model for LocalUploadRequestOptions.
+type LocalUploadRequestOptions map[string]interface{}
+
+// OCIUploadRequestOptions defines model for OCIUploadRequestOptions.
+type OCIUploadRequestOptions struct {
+
+	// Name of an existing Object Storage bucket the image is uploaded to
+	// before importing it. The uploaded object is deleted afterwards.
+	Bucket string `json:"bucket"`
+
+	// OCID of the compartment the image will be created in.
+	Compartment string `json:"compartment"`
+
+	// Display name of the imported custom image. If not specified a random
+	// 'composer-api-<uuid>' string is used as the image name.
+	ImageName *string `json:"image_name,omitempty"`
+
+	// Object Storage namespace of the tenancy the bucket belongs to.
+	Namespace string `json:"namespace"`
+
+	// The OCI region where the image will be imported to.
+	Region string `json:"region"`
+}
+
+// OCIUploadStatus defines model for OCIUploadStatus.
+type OCIUploadStatus struct {
+
+	// OCID of the imported custom image.
+	ImageId string `json:"image_id"`
+	Region  string `json:"region"`
+}
+
+// OSTree defines model for OSTree.
+type OSTree struct {
+
+	// Checksum of the parent commit. Mutually exclusive with url, from
+	// which the parent is resolved otherwise.
+	Parent *string `json:"parent,omitempty"`
+	Ref    *string `json:"ref,omitempty"`
+	Url    *string `json:"url,omitempty"`
+}
+
+// PackageBuild defines model for PackageBuild.
+type PackageBuild struct {
+	Arch      string     `json:"arch"`
+	BuildTime *time.Time `json:"build_time,omitempty"`
+	Epoch     int        `json:"epoch"`
+	Name      string     `json:"name"`
+	Release   string     `json:"release"`
+	Version   string     `json:"version"`
+}
+
+// PackageInfo defines model for PackageInfo.
+type PackageInfo struct {
+	Builds []PackageBuild `json:"builds"`
+
+	// The packages installed with the latest build, including itself
+	Dependencies []PackageBuild `json:"dependencies"`
+	Description  string         `json:"description"`
+	Homepage     string         `json:"homepage"`
+	Name         string         `json:"name"`
+	Summary      string         `json:"summary"`
+}
+
+// PackageMetadata defines model for PackageMetadata.
+type PackageMetadata struct {
+	Arch string `json:"arch"`
+
+	// Checksum of the package which was depsolved for the image, as
+	// listed in its manifest
+	Checksum *string `json:"checksum,omitempty"`
+	Epoch    *string `json:"epoch,omitempty"`
+	Name     string  `json:"name"`
+	Release  string  `json:"release"`
+
+	// URL of the repository the package was depsolved from, its
+	// base URL, metalink, or mirrorlist
+	Repository *string `json:"repository,omitempty"`
+	Sigmd5     string  `json:"sigmd5"`
+	Signature  *string `json:"signature,omitempty"`
+	Type       string  `json:"type"`
+	Version    string  `json:"version"`
+}
+
+// PackageSearchResult defines model for PackageSearchResult.
+type PackageSearchResult struct {
+	Packages []PackageSummary `json:"packages"`
+
+	// Number of matching packages, including the ones beyond the limit
+	Total int `json:"total"`
+}
+
+// PackageSummary defines model for PackageSummary.
+type PackageSummary struct {
+	Name    string `json:"name"`
+	Summary string `json:"summary"`
+
+	// The available versions, as version-release
+	Versions []string `json:"versions"`
+}
+
+// Repository defines model for Repository.
+type Repository struct {
+	Baseurl *string `json:"baseurl,omitempty"`
+
+	// Whether to verify the signatures of the packages of the repository
+	CheckGpg *bool `json:"check_gpg,omitempty"`
+
+	// ASCII-armored GPG key used to verify the packages of the repository
+	GpgKey     *string `json:"gpg_key,omitempty"`
+	Metalink   *string `json:"metalink,omitempty"`
+	Mirrorlist *string `json:"mirrorlist,omitempty"`
+
+	// Whether packages of the repository are exempt from modular filtering
+	ModuleHotfixes *bool `json:"module_hotfixes,omitempty"`
+	Rhsm           bool  `json:"rhsm"`
+}
+
+// Subscription defines model for Subscription.
+type Subscription struct {
+	ActivationKey string `json:"activation_key"`
+	BaseUrl       string `json:"base_url"`
+	Insights      bool   `json:"insights"`
+	Organization  int    `json:"organization"`
+	ServerUrl     string `json:"server_url"`
+}
+
+// UploadRequest defines model for UploadRequest.
+type UploadRequest struct {
+	Options interface{} `json:"options"`
+	Type    UploadTypes `json:"type"`
+}
+
+// UploadStatus defines model for UploadStatus.
+type UploadStatus struct {
+
+	// Result of the upload, which is not set until the upload has
+	// succeeded.
+	Options *interface{} `json:"options,omitempty"`
+	Status  string       `json:"status"`
+	Type    UploadTypes  `json:"type"`
+}
+
+// UploadTypes defines model for UploadTypes.
+type UploadTypes string
+
+// List of UploadTypes
+const (
+	UploadTypes_aws        UploadTypes = "aws"
+	UploadTypes_aws_s3     UploadTypes = "aws.s3"
+	UploadTypes_azure      UploadTypes = "azure"
+	UploadTypes_gcp        UploadTypes = "gcp"
+	UploadTypes_generic_s3 UploadTypes = "generic.s3"
+	UploadTypes_local      UploadTypes = "local"
+	UploadTypes_oci        UploadTypes = "oci"
+)
+
+// User defines model for User.
+type User struct {
+
+	// Day on which the account is disabled, in days since 1970-01-01.
+	ExpireDate *int `json:"expire_date,omitempty"`
+
+	// Expire the password, so that it has to be changed on the first
+	// login.
+	ForcePasswordReset *bool     `json:"force_password_reset,omitempty"`
+	Gid                *int      `json:"gid,omitempty"`
+	Groups             *[]string `json:"groups,omitempty"`
+
+	// SSH public keys, which are added to the authorized keys of the user.
+	Keys *[]string `json:"keys,omitempty"`
+
+	// Disable logging in with a password.
+	Locked *bool  `json:"locked,omitempty"`
+	Name   string `json:"name"`
+	Uid    *int   `json:"uid,omitempty"`
+}
+
+// Version defines model for Version.
+type Version struct {
+	Version string `json:"version"`
+}
+
+// ValidateBlueprintJSONBody defines parameters for ValidateBlueprint.
+type ValidateBlueprintJSONBody ComposeRequest
+
+// ComposeJSONBody defines parameters for Compose.
+type ComposeJSONBody ComposeRequest
+
+// ComposeKojiJSONBody defines parameters for ComposeKoji.
+type ComposeKojiJSONBody KojiComposeRequest
+
+// ComposeCloneJSONBody defines parameters for ComposeClone.
+type ComposeCloneJSONBody UploadRequest
+
+// ComposeLogParams defines parameters for ComposeLog.
+type ComposeLogParams struct {
+
+	// Byte of the log of a running compose to start at
+	Offset *int `json:"offset,omitempty"`
+}
+
+// ComposeSbomParams defines parameters for ComposeSbom.
+type ComposeSbomParams struct {
+
+	// Format of the SBOM
+	Format *string `json:"format,omitempty"`
+}
+
+// ListComposesParams defines parameters for ListComposes.
+type ListComposesParams struct {
+
+	// Only the composes which have finished, successfully or not, or
+	// the ones which are still pending, building or uploading
+	Status *string `json:"status,omitempty"`
+
+	// Only the composes which were requested at or after this time
+	Since *time.Time `json:"since,omitempty"`
+
+	// Only the composes which were requested before this time
+	Until *time.Time `json:"until,omitempty"`
+
+	// Number of composes to skip
+	Offset *int `json:"offset,omitempty"`
+
+	// Maximum number of composes to return, all of them if not given
+	Limit *int `json:"limit,omitempty"`
+}
+
+// SearchPackagesParams defines parameters for SearchPackages.
+type SearchPackagesParams struct {
+
+	// Comma-separated globs which the names of the packages must match
+	Search string `json:"search"`
+
+	// Maximum number of packages to return
+	Limit *int `json:"limit,omitempty"`
+}
+
+// ValidateBlueprintRequestBody defines body for ValidateBlueprint for application/json ContentType.
+type ValidateBlueprintJSONRequestBody ValidateBlueprintJSONBody
+
+// ComposeRequestBody defines body for Compose for application/json ContentType.
+type ComposeJSONRequestBody ComposeJSONBody
+
+// ComposeKojiRequestBody defines body for ComposeKoji for application/json ContentType.
+type ComposeKojiJSONRequestBody ComposeKojiJSONBody
+
+// ComposeCloneRequestBody defines body for ComposeClone for application/json ContentType.
+type ComposeCloneJSONRequestBody ComposeCloneJSONBody
+
+// RequestEditorFn  is the function signature for the RequestEditor callback function
+type RequestEditorFn func(ctx context.Context, req *http.Request) error
+
+// Doer performs HTTP requests.
+//
+// The standard http.Client implements this interface.
+type HttpRequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client which conforms to the OpenAPI3 specification for this service.
+type Client struct {
+	// The endpoint of the server conforming to this interface, with scheme,
+	// https://api.deepmap.com for example.
+	Server string
+
+	// Doer for performing requests, typically a *http.Client with any
+	// customized settings, such as certificate chains.
+	Client HttpRequestDoer
+
+	// A callback for modifying requests which are generated before sending over
+	// the network.
+	RequestEditor RequestEditorFn
+}
+
+// ClientOption allows setting custom parameters during construction
+type ClientOption func(*Client) error
+
+// Creates a new Client, with reasonable defaults
+func NewClient(server string, opts ...ClientOption) (*Client, error) {
+	// create a client with sane default values
+	client := Client{
+		Server: server,
+	}
+	// mutate client and add all optional params
+	for _, o := range opts {
+		if err := o(&client); err != nil {
+			return nil, err
+		}
+	}
+	// ensure the server URL always has a trailing slash
+	if !strings.HasSuffix(client.Server, "/") {
+		client.Server += "/"
+	}
+	// create httpClient, if not already present
+	if client.Client == nil {
+		client.Client = http.DefaultClient
+	}
+	return &client, nil
+}
+
+// WithHTTPClient allows overriding the default Doer, which is
+// automatically created using http.Client. This is useful for tests.
+func WithHTTPClient(doer HttpRequestDoer) ClientOption {
+	return func(c *Client) error {
+		c.Client = doer
+		return nil
+	}
+}
+
+// WithRequestEditorFn allows setting up a callback function, which will be
+// called right before sending the request. This can be used to mutate the request.
+func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
+	return func(c *Client) error {
+		c.RequestEditor = fn
+		return nil
+	}
+}
+
+// The interface specification for the client above.
+type ClientInterface interface {
+	// ValidateBlueprint request  with any body
+	ValidateBlueprintWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error)
+
+	ValidateBlueprint(ctx context.Context, body ValidateBlueprintJSONRequestBody) (*http.Response, error)
+
+	// CloneStatus request
+	CloneStatus(ctx context.Context, id string) (*http.Response, error)
+
+	// Compose request  with any body
+	ComposeWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error)
+
+	Compose(ctx context.Context, body ComposeJSONRequestBody) (*http.Response, error)
+
+	// ComposeKoji request  with any body
+	ComposeKojiWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error)
+
+	ComposeKoji(ctx context.Context, body ComposeKojiJSONRequestBody) (*http.Response, error)
+
+	// KojiComposeStatus request
+	KojiComposeStatus(ctx context.Context, id string) (*http.Response, error)
+
+	// DeleteCompose request
+	DeleteCompose(ctx context.Context, id string) (*http.Response, error)
+
+	// ComposeStatus request
+	ComposeStatus(ctx context.Context, id string) (*http.Response, error)
+
+	// ComposeClone request  with any body
+	ComposeCloneWithBody(ctx context.Context, id string, contentType string, body io.Reader) (*http.Response, error)
+
+	ComposeClone(ctx context.Context, id string, body ComposeCloneJSONRequestBody) (*http.Response, error)
+
+	// ComposeDownload request
+	ComposeDownload(ctx context.Context, id string) (*http.Response, error)
+
+	// ComposeEvents request
+	ComposeEvents(ctx context.Context, id string) (*http.Response, error)
+
+	// ComposeLog request
+	ComposeLog(ctx context.Context, id string, params *ComposeLogParams) (*http.Response, error)
+
+	// ComposeManifests request
+	ComposeManifests(ctx context.Context, id string) (*http.Response, error)
+
+	// ComposeMetadata request
+	ComposeMetadata(ctx context.Context, id string) (*http.Response, error)
+
+	// ComposeProvenance request
+	ComposeProvenance(ctx context.Context, id string) (*http.Response, error)
+
+	// ComposeRetry request
+	ComposeRetry(ctx context.Context, id string) (*http.Response, error)
+
+	// ComposeSbom request
+	ComposeSbom(ctx context.Context, id string, params *ComposeSbomParams) (*http.Response, error)
+
+	// ListComposes request
+	ListComposes(ctx context.Context, params *ListComposesParams) (*http.Response, error)
+
+	// ListDistros request
+	ListDistros(ctx context.Context) (*http.Response, error)
+
+	// SearchPackages request
+	SearchPackages(ctx context.Context, distro string, arch string, params *SearchPackagesParams) (*http.Response, error)
+
+	// GetPackage request
+	GetPackage(ctx context.Context, distro string, arch string, name string) (*http.Response, error)
+
+	// ListDistroImageTypes request
+	ListDistroImageTypes(ctx context.Context, distro string) (*http.Response, error)
+
+	// GetOpenapiJson request
+	GetOpenapiJson(ctx context.Context) (*http.Response, error)
+
+	// SigningKey request
+	SigningKey(ctx context.Context) (*http.Response, error)
+
+	// GetVersion request
+	GetVersion(ctx context.Context) (*http.Response, error)
+}
+
+func (c *Client) ValidateBlueprintWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := NewValidateBlueprintRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ValidateBlueprint(ctx context.Context, body ValidateBlueprintJSONRequestBody) (*http.Response, error) {
+	req, err := NewValidateBlueprintRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CloneStatus(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewCloneStatusRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := NewComposeRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Compose(ctx context.Context, body ComposeJSONRequestBody) (*http.Response, error) {
+	req, err := NewComposeRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeKojiWithBody(ctx context.Context, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := NewComposeKojiRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeKoji(ctx context.Context, body ComposeKojiJSONRequestBody) (*http.Response, error) {
+	req, err := NewComposeKojiRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) KojiComposeStatus(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewKojiComposeStatusRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeleteCompose(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewDeleteComposeRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeStatus(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeStatusRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeCloneWithBody(ctx context.Context, id string, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := NewComposeCloneRequestWithBody(c.Server, id, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeClone(ctx context.Context, id string, body ComposeCloneJSONRequestBody) (*http.Response, error) {
+	req, err := NewComposeCloneRequest(c.Server, id, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeDownload(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeDownloadRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeEvents(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeEventsRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeLog(ctx context.Context, id string, params *ComposeLogParams) (*http.Response, error) {
+	req, err := NewComposeLogRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeManifests(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeManifestsRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeMetadata(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeMetadataRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeProvenance(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeProvenanceRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeRetry(ctx context.Context, id string) (*http.Response, error) {
+	req, err := NewComposeRetryRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ComposeSbom(ctx context.Context, id string, params *ComposeSbomParams) (*http.Response, error) {
+	req, err := NewComposeSbomRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListComposes(ctx context.Context, params *ListComposesParams) (*http.Response, error) {
+	req, err := NewListComposesRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListDistros(ctx context.Context) (*http.Response, error) {
+	req, err := NewListDistrosRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SearchPackages(ctx context.Context, distro string, arch string, params *SearchPackagesParams) (*http.Response, error) {
+	req, err := NewSearchPackagesRequest(c.Server, distro, arch, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetPackage(ctx context.Context, distro string, arch string, name string) (*http.Response, error) {
+	req, err := NewGetPackageRequest(c.Server, distro, arch, name)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListDistroImageTypes(ctx context.Context, distro string) (*http.Response, error) {
+	req, err := NewListDistroImageTypesRequest(c.Server, distro)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetOpenapiJson(ctx context.Context) (*http.Response, error) {
+	req, err := NewGetOpenapiJsonRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SigningKey(ctx context.Context) (*http.Response, error) {
+	req, err := NewSigningKeyRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetVersion(ctx context.Context) (*http.Response, error) {
+	req, err := NewGetVersionRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.RequestEditor != nil {
+		err = c.RequestEditor(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+// NewValidateBlueprintRequest calls the generic ValidateBlueprint builder with application/json body
+func NewValidateBlueprintRequest(server string, body ValidateBlueprintJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewValidateBlueprintRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewValidateBlueprintRequestWithBody generates requests for ValidateBlueprint with any type of body
+func NewValidateBlueprintRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/blueprints/validate")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryUrl.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+	return req, nil
+}
+
+// NewCloneStatusRequest generates requests for CloneStatus
+func NewCloneStatusRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/clones/%s", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeRequest calls the generic Compose builder with application/json body
+func NewComposeRequest(server string, body ComposeJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewComposeRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewComposeRequestWithBody generates requests for Compose with any type of body
+func NewComposeRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryUrl.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+	return req, nil
+}
+
+// NewComposeKojiRequest calls the generic ComposeKoji builder with application/json body
+func NewComposeKojiRequest(server string, body ComposeKojiJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewComposeKojiRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewComposeKojiRequestWithBody generates requests for ComposeKoji with any type of body
+func NewComposeKojiRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/koji")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryUrl.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+	return req, nil
+}
+
+// NewKojiComposeStatusRequest generates requests for KojiComposeStatus
+func NewKojiComposeStatusRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/koji/%s", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewDeleteComposeRequest generates requests for DeleteCompose
+func NewDeleteComposeRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeStatusRequest generates requests for ComposeStatus
+func NewComposeStatusRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeCloneRequest calls the generic ComposeClone builder with application/json body
+func NewComposeCloneRequest(server string, id string, body ComposeCloneJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewComposeCloneRequestWithBody(server, id, "application/json", bodyReader)
+}
+
+// NewComposeCloneRequestWithBody generates requests for ComposeClone with any type of body
+func NewComposeCloneRequestWithBody(server string, id string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/clone", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryUrl.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+	return req, nil
+}
+
+// NewComposeDownloadRequest generates requests for ComposeDownload
+func NewComposeDownloadRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/download", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeEventsRequest generates requests for ComposeEvents
+func NewComposeEventsRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/events", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeLogRequest generates requests for ComposeLog
+func NewComposeLogRequest(server string, id string, params *ComposeLogParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/log", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryUrl.Query()
+
+	if params.Offset != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "offset", *params.Offset); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryUrl.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeManifestsRequest generates requests for ComposeManifests
+func NewComposeManifestsRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/manifests", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeMetadataRequest generates requests for ComposeMetadata
+func NewComposeMetadataRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/metadata", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeProvenanceRequest generates requests for ComposeProvenance
+func NewComposeProvenanceRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/provenance", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeRetryRequest generates requests for ComposeRetry
+func NewComposeRetryRequest(server string, id string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/retry", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewComposeSbomRequest generates requests for ComposeSbom
+func NewComposeSbomRequest(server string, id string, params *ComposeSbomParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/compose/%s/sbom", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryUrl.Query()
+
+	if params.Format != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "format", *params.Format); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryUrl.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListComposesRequest generates requests for ListComposes
+func NewListComposesRequest(server string, params *ListComposesParams) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/composes")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryUrl.Query()
+
+	if params.Status != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "status", *params.Status); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Since != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "since", *params.Since); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Until != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "until", *params.Until); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Offset != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "offset", *params.Offset); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Limit != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "limit", *params.Limit); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryUrl.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListDistrosRequest generates requests for ListDistros
+func NewListDistrosRequest(server string) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/distros")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewSearchPackagesRequest generates requests for SearchPackages
+func NewSearchPackagesRequest(server string, distro string, arch string, params *SearchPackagesParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "distro", distro)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParam("simple", false, "arch", arch)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/distros/%s/architectures/%s/packages", pathParam0, pathParam1)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryUrl.Query()
+
+	if queryFrag, err := runtime.StyleParam("form", true, "search", params.Search); err != nil {
+		return nil, err
+	} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+		return nil, err
+	} else {
+		for k, v := range parsed {
+			for _, v2 := range v {
+				queryValues.Add(k, v2)
+			}
+		}
+	}
+
+	if params.Limit != nil {
+
+		if queryFrag, err := runtime.StyleParam("form", true, "limit", *params.Limit); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryUrl.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetPackageRequest generates requests for GetPackage
+func NewGetPackageRequest(server string, distro string, arch string, name string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "distro", distro)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParam("simple", false, "arch", arch)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam2 string
+
+	pathParam2, err = runtime.StyleParam("simple", false, "name", name)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/distros/%s/architectures/%s/packages/%s", pathParam0, pathParam1, pathParam2)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListDistroImageTypesRequest generates requests for ListDistroImageTypes
+func NewListDistroImageTypesRequest(server string, distro string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParam("simple", false, "distro", distro)
+	if err != nil {
+		return nil, err
+	}
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/distros/%s/image-types", pathParam0)
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetOpenapiJsonRequest generates requests for GetOpenapiJson
+func NewGetOpenapiJsonRequest(server string) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/openapi.json")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewSigningKeyRequest generates requests for SigningKey
+func NewSigningKeyRequest(server string) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/signing-key")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetVersionRequest generates requests for GetVersion
+func NewGetVersionRequest(server string) (*http.Request, error) {
+	var err error
+
+	queryUrl, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := fmt.Sprintf("/version")
+	if basePath[0] == '/' {
+		basePath = basePath[1:]
+	}
+
+	queryUrl, err = queryUrl.Parse(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// ValidateBlueprint request  with any body
+	ValidateBlueprintWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ValidateBlueprintResponse, error)
+
+	ValidateBlueprintWithResponse(ctx context.Context, body ValidateBlueprintJSONRequestBody) (*ValidateBlueprintResponse, error)
+
+	// CloneStatus request
+	CloneStatusWithResponse(ctx context.Context, id string) (*CloneStatusResponse, error)
+
+	// Compose request  with any body
+	ComposeWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ComposeResponse, error)
+
+	ComposeWithResponse(ctx context.Context, body ComposeJSONRequestBody) (*ComposeResponse, error)
+
+	// ComposeKoji request  with any body
+	ComposeKojiWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ComposeKojiResponse, error)
+
+	ComposeKojiWithResponse(ctx context.Context, body ComposeKojiJSONRequestBody) (*ComposeKojiResponse, error)
+
+	// KojiComposeStatus request
+	KojiComposeStatusWithResponse(ctx context.Context, id string) (*KojiComposeStatusResponse, error)
+
+	// DeleteCompose request
+	DeleteComposeWithResponse(ctx context.Context, id string) (*DeleteComposeResponse, error)
+
+	// ComposeStatus request
+	ComposeStatusWithResponse(ctx context.Context, id string) (*ComposeStatusResponse, error)
+
+	// ComposeClone request  with any body
+	ComposeCloneWithBodyWithResponse(ctx context.Context, id string, contentType string, body io.Reader) (*ComposeCloneResponse, error)
+
+	ComposeCloneWithResponse(ctx context.Context, id string, body ComposeCloneJSONRequestBody) (*ComposeCloneResponse, error)
+
+	// ComposeDownload request
+	ComposeDownloadWithResponse(ctx context.Context, id string) (*ComposeDownloadResponse, error)
+
+	// ComposeEvents request
+	ComposeEventsWithResponse(ctx context.Context, id string) (*ComposeEventsResponse, error)
+
+	// ComposeLog request
+	ComposeLogWithResponse(ctx context.Context, id string, params *ComposeLogParams) (*ComposeLogResponse, error)
+
+	// ComposeManifests request
+	ComposeManifestsWithResponse(ctx context.Context, id string) (*ComposeManifestsResponse, error)
+
+	// ComposeMetadata request
+	ComposeMetadataWithResponse(ctx context.Context, id string) (*ComposeMetadataResponse, error)
+
+	// ComposeProvenance request
+	ComposeProvenanceWithResponse(ctx context.Context, id string) (*ComposeProvenanceResponse, error)
+
+	// ComposeRetry request
+	ComposeRetryWithResponse(ctx context.Context, id string) (*ComposeRetryResponse, error)
+
+	// ComposeSbom request
+	ComposeSbomWithResponse(ctx context.Context, id string, params *ComposeSbomParams) (*ComposeSbomResponse, error)
+
+	// ListComposes request
+	ListComposesWithResponse(ctx context.Context, params *ListComposesParams) (*ListComposesResponse, error)
+
+	// ListDistros request
+	ListDistrosWithResponse(ctx context.Context) (*ListDistrosResponse, error)
+
+	// SearchPackages request
+	SearchPackagesWithResponse(ctx context.Context, distro string, arch string, params *SearchPackagesParams) (*SearchPackagesResponse, error)
+
+	// GetPackage request
+	GetPackageWithResponse(ctx context.Context, distro string, arch string, name string) (*GetPackageResponse, error)
+
+	// ListDistroImageTypes request
+	ListDistroImageTypesWithResponse(ctx context.Context, distro string) (*ListDistroImageTypesResponse, error)
+
+	// GetOpenapiJson request
+	GetOpenapiJsonWithResponse(ctx context.Context) (*GetOpenapiJsonResponse, error)
+
+	// SigningKey request
+	SigningKeyWithResponse(ctx context.Context) (*SigningKeyResponse, error)
+
+	// GetVersion request
+	GetVersionWithResponse(ctx context.Context) (*GetVersionResponse, error)
+}
+
+type ValidateBlueprintResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *BlueprintValidation
+	JSON400      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ValidateBlueprintResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ValidateBlueprintResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CloneStatusResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *UploadStatus
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r CloneStatusResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CloneStatusResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *ComposeResult
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeKojiResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *ComposeResult
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeKojiResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeKojiResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type KojiComposeStatusResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *KojiComposeStatus
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r KojiComposeStatusResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r KojiComposeStatusResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteComposeResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteComposeResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteComposeResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeStatusResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ComposeStatus
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeStatusResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeStatusResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeCloneResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *CloneResult
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeCloneResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeCloneResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeDownloadResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeDownloadResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeDownloadResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeEventsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeEventsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeEventsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeLogResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ComposeLog
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeLogResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeLogResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeManifestsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ComposeManifests
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeManifestsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeManifestsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeMetadataResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ComposeMetadata
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeMetadataResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeMetadataResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeProvenanceResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *map[string]interface{}
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeProvenanceResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeProvenanceResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeRetryResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *ComposeResult
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeRetryResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeRetryResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ComposeSbomResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ComposeSbomResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ComposeSbomResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListComposesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]ComposeListItem
+	JSON400      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ListComposesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListComposesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListDistrosResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Distribution
+}
+
+// Status returns HTTPResponse.Status
+func (r ListDistrosResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListDistrosResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SearchPackagesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *PackageSearchResult
+	JSON400      *ErrorInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r SearchPackagesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SearchPackagesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetPackageResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *PackageInfo
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r GetPackageResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetPackageResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListDistroImageTypesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *DistributionImageTypes
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r ListDistroImageTypesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListDistroImageTypesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetOpenapiJsonResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r GetOpenapiJsonResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetOpenapiJsonResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SigningKeyResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON404      *ErrorInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r SigningKeyResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SigningKeyResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetVersionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Version
+}
+
+// Status returns HTTPResponse.Status
+func (r GetVersionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetVersionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// ValidateBlueprintWithBodyWithResponse request with arbitrary body returning *ValidateBlueprintResponse
+func (c *ClientWithResponses) ValidateBlueprintWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ValidateBlueprintResponse, error) {
+	rsp, err := c.ValidateBlueprintWithBody(ctx, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseValidateBlueprintResponse(rsp)
+}
+
+func (c *ClientWithResponses) ValidateBlueprintWithResponse(ctx context.Context, body ValidateBlueprintJSONRequestBody) (*ValidateBlueprintResponse, error) {
+	rsp, err := c.ValidateBlueprint(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseValidateBlueprintResponse(rsp)
+}
+
+// CloneStatusWithResponse request returning *CloneStatusResponse
+func (c *ClientWithResponses) CloneStatusWithResponse(ctx context.Context, id string) (*CloneStatusResponse, error) {
+	rsp, err := c.CloneStatus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCloneStatusResponse(rsp)
+}
+
+// ComposeWithBodyWithResponse request with arbitrary body returning *ComposeResponse
+func (c *ClientWithResponses) ComposeWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ComposeResponse, error) {
+	rsp, err := c.ComposeWithBody(ctx, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeResponse(rsp)
+}
+
+func (c *ClientWithResponses) ComposeWithResponse(ctx context.Context, body ComposeJSONRequestBody) (*ComposeResponse, error) {
+	rsp, err := c.Compose(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeResponse(rsp)
+}
+
+// ComposeKojiWithBodyWithResponse request with arbitrary body returning *ComposeKojiResponse
+func (c *ClientWithResponses) ComposeKojiWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader) (*ComposeKojiResponse, error) {
+	rsp, err := c.ComposeKojiWithBody(ctx, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeKojiResponse(rsp)
+}
+
+func (c *ClientWithResponses) ComposeKojiWithResponse(ctx context.Context, body ComposeKojiJSONRequestBody) (*ComposeKojiResponse, error) {
+	rsp, err := c.ComposeKoji(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeKojiResponse(rsp)
+}
+
+// KojiComposeStatusWithResponse request returning *KojiComposeStatusResponse
+func (c *ClientWithResponses) KojiComposeStatusWithResponse(ctx context.Context, id string) (*KojiComposeStatusResponse, error) {
+	rsp, err := c.KojiComposeStatus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKojiComposeStatusResponse(rsp)
+}
+
+// DeleteComposeWithResponse request returning *DeleteComposeResponse
+func (c *ClientWithResponses) DeleteComposeWithResponse(ctx context.Context, id string) (*DeleteComposeResponse, error) {
+	rsp, err := c.DeleteCompose(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteComposeResponse(rsp)
+}
+
+// ComposeStatusWithResponse request returning *ComposeStatusResponse
+func (c *ClientWithResponses) ComposeStatusWithResponse(ctx context.Context, id string) (*ComposeStatusResponse, error) {
+	rsp, err := c.ComposeStatus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeStatusResponse(rsp)
+}
+
+// ComposeCloneWithBodyWithResponse request with arbitrary body returning *ComposeCloneResponse
+func (c *ClientWithResponses) ComposeCloneWithBodyWithResponse(ctx context.Context, id string, contentType string, body io.Reader) (*ComposeCloneResponse, error) {
+	rsp, err := c.ComposeCloneWithBody(ctx, id, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeCloneResponse(rsp)
+}
+
+func (c *ClientWithResponses) ComposeCloneWithResponse(ctx context.Context, id string, body ComposeCloneJSONRequestBody) (*ComposeCloneResponse, error) {
+	rsp, err := c.ComposeClone(ctx, id, body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeCloneResponse(rsp)
+}
+
+// ComposeDownloadWithResponse request returning *ComposeDownloadResponse
+func (c *ClientWithResponses) ComposeDownloadWithResponse(ctx context.Context, id string) (*ComposeDownloadResponse, error) {
+	rsp, err := c.ComposeDownload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeDownloadResponse(rsp)
+}
+
+// ComposeEventsWithResponse request returning *ComposeEventsResponse
+func (c *ClientWithResponses) ComposeEventsWithResponse(ctx context.Context, id string) (*ComposeEventsResponse, error) {
+	rsp, err := c.ComposeEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeEventsResponse(rsp)
+}
+
+// ComposeLogWithResponse request returning *ComposeLogResponse
+func (c *ClientWithResponses) ComposeLogWithResponse(ctx context.Context, id string, params *ComposeLogParams) (*ComposeLogResponse, error) {
+	rsp, err := c.ComposeLog(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeLogResponse(rsp)
+}
+
+// ComposeManifestsWithResponse request returning *ComposeManifestsResponse
+func (c *ClientWithResponses) ComposeManifestsWithResponse(ctx context.Context, id string) (*ComposeManifestsResponse, error) {
+	rsp, err := c.ComposeManifests(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeManifestsResponse(rsp)
+}
+
+// ComposeMetadataWithResponse request returning *ComposeMetadataResponse
+func (c *ClientWithResponses) ComposeMetadataWithResponse(ctx context.Context, id string) (*ComposeMetadataResponse, error) {
+	rsp, err := c.ComposeMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeMetadataResponse(rsp)
+}
+
+// ComposeProvenanceWithResponse request returning *ComposeProvenanceResponse
+func (c *ClientWithResponses) ComposeProvenanceWithResponse(ctx context.Context, id string) (*ComposeProvenanceResponse, error) {
+	rsp, err := c.ComposeProvenance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeProvenanceResponse(rsp)
+}
+
+// ComposeRetryWithResponse request returning *ComposeRetryResponse
+func (c *ClientWithResponses) ComposeRetryWithResponse(ctx context.Context, id string) (*ComposeRetryResponse, error) {
+	rsp, err := c.ComposeRetry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeRetryResponse(rsp)
+}
+
+// ComposeSbomWithResponse request returning *ComposeSbomResponse
+func (c *ClientWithResponses) ComposeSbomWithResponse(ctx context.Context, id string, params *ComposeSbomParams) (*ComposeSbomResponse, error) {
+	rsp, err := c.ComposeSbom(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseComposeSbomResponse(rsp)
+}
+
+// ListComposesWithResponse request returning *ListComposesResponse
+func (c *ClientWithResponses) ListComposesWithResponse(ctx context.Context, params *ListComposesParams) (*ListComposesResponse, error) {
+	rsp, err := c.ListComposes(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListComposesResponse(rsp)
+}
+
+// ListDistrosWithResponse request returning *ListDistrosResponse
+func (c *ClientWithResponses) ListDistrosWithResponse(ctx context.Context) (*ListDistrosResponse, error) {
+	rsp, err := c.ListDistros(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListDistrosResponse(rsp)
+}
+
+// SearchPackagesWithResponse request returning *SearchPackagesResponse
+func (c *ClientWithResponses) SearchPackagesWithResponse(ctx context.Context, distro string, arch string, params *SearchPackagesParams) (*SearchPackagesResponse, error) {
+	rsp, err := c.SearchPackages(ctx, distro, arch, params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSearchPackagesResponse(rsp)
+}
+
+// GetPackageWithResponse request returning *GetPackageResponse
+func (c *ClientWithResponses) GetPackageWithResponse(ctx context.Context, distro string, arch string, name string) (*GetPackageResponse, error) {
+	rsp, err := c.GetPackage(ctx, distro, arch, name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetPackageResponse(rsp)
+}
+
+// ListDistroImageTypesWithResponse request returning *ListDistroImageTypesResponse
+func (c *ClientWithResponses) ListDistroImageTypesWithResponse(ctx context.Context, distro string) (*ListDistroImageTypesResponse, error) {
+	rsp, err := c.ListDistroImageTypes(ctx, distro)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListDistroImageTypesResponse(rsp)
+}
+
+// GetOpenapiJsonWithResponse request returning *GetOpenapiJsonResponse
+func (c *ClientWithResponses) GetOpenapiJsonWithResponse(ctx context.Context) (*GetOpenapiJsonResponse, error) {
+	rsp, err := c.GetOpenapiJson(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetOpenapiJsonResponse(rsp)
+}
+
+// SigningKeyWithResponse request returning *SigningKeyResponse
+func (c *ClientWithResponses) SigningKeyWithResponse(ctx context.Context) (*SigningKeyResponse, error) {
+	rsp, err := c.SigningKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSigningKeyResponse(rsp)
+}
+
+// GetVersionWithResponse request returning *GetVersionResponse
+func (c *ClientWithResponses) GetVersionWithResponse(ctx context.Context) (*GetVersionResponse, error) {
+	rsp, err := c.GetVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetVersionResponse(rsp)
+}
+
+// ParseValidateBlueprintResponse parses an HTTP response from a ValidateBlueprintWithResponse call
+func ParseValidateBlueprintResponse(rsp *http.Response) (*ValidateBlueprintResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ValidateBlueprintResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest BlueprintValidation
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseCloneStatusResponse parses an HTTP response from a CloneStatusWithResponse call
+func ParseCloneStatusResponse(rsp *http.Response) (*CloneStatusResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CloneStatusResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest UploadStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeResponse parses an HTTP response from a ComposeWithResponse call
+func ParseComposeResponse(rsp *http.Response) (*ComposeResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest ComposeResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeKojiResponse parses an HTTP response from a ComposeKojiWithResponse call
+func ParseComposeKojiResponse(rsp *http.Response) (*ComposeKojiResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeKojiResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest ComposeResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseKojiComposeStatusResponse parses an HTTP response from a KojiComposeStatusWithResponse call
+func ParseKojiComposeStatusResponse(rsp *http.Response) (*KojiComposeStatusResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &KojiComposeStatusResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest KojiComposeStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteComposeResponse parses an HTTP response from a DeleteComposeWithResponse call
+func ParseDeleteComposeResponse(rsp *http.Response) (*DeleteComposeResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteComposeResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeStatusResponse parses an HTTP response from a ComposeStatusWithResponse call
+func ParseComposeStatusResponse(rsp *http.Response) (*ComposeStatusResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeStatusResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ComposeStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeCloneResponse parses an HTTP response from a ComposeCloneWithResponse call
+func ParseComposeCloneResponse(rsp *http.Response) (*ComposeCloneResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeCloneResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest CloneResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeDownloadResponse parses an HTTP response from a ComposeDownloadWithResponse call
+func ParseComposeDownloadResponse(rsp *http.Response) (*ComposeDownloadResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeDownloadResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeEventsResponse parses an HTTP response from a ComposeEventsWithResponse call
+func ParseComposeEventsResponse(rsp *http.Response) (*ComposeEventsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeEventsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeLogResponse parses an HTTP response from a ComposeLogWithResponse call
+func ParseComposeLogResponse(rsp *http.Response) (*ComposeLogResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeLogResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ComposeLog
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeManifestsResponse parses an HTTP response from a ComposeManifestsWithResponse call
+func ParseComposeManifestsResponse(rsp *http.Response) (*ComposeManifestsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeManifestsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ComposeManifests
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeMetadataResponse parses an HTTP response from a ComposeMetadataWithResponse call
+func ParseComposeMetadataResponse(rsp *http.Response) (*ComposeMetadataResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeMetadataResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ComposeMetadata
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeProvenanceResponse parses an HTTP response from a ComposeProvenanceWithResponse call
+func ParseComposeProvenanceResponse(rsp *http.Response) (*ComposeProvenanceResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeProvenanceResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest map[string]interface{}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeRetryResponse parses an HTTP response from a ComposeRetryWithResponse call
+func ParseComposeRetryResponse(rsp *http.Response) (*ComposeRetryResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeRetryResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest ComposeResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseComposeSbomResponse parses an HTTP response from a ComposeSbomWithResponse call
+func ParseComposeSbomResponse(rsp *http.Response) (*ComposeSbomResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ComposeSbomResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListComposesResponse parses an HTTP response from a ListComposesWithResponse call
+func ParseListComposesResponse(rsp *http.Response) (*ListComposesResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListComposesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []ComposeListItem
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListDistrosResponse parses an HTTP response from a ListDistrosWithResponse call
+func ParseListDistrosResponse(rsp *http.Response) (*ListDistrosResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListDistrosResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Distribution
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseSearchPackagesResponse parses an HTTP response from a SearchPackagesWithResponse call
+func ParseSearchPackagesResponse(rsp *http.Response) (*SearchPackagesResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SearchPackagesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest PackageSearchResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetPackageResponse parses an HTTP response from a GetPackageWithResponse call
+func ParseGetPackageResponse(rsp *http.Response) (*GetPackageResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetPackageResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest PackageInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListDistroImageTypesResponse parses an HTTP response from a ListDistroImageTypesWithResponse call
+func ParseListDistroImageTypesResponse(rsp *http.Response) (*ListDistroImageTypesResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListDistroImageTypesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest DistributionImageTypes
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetOpenapiJsonResponse parses an HTTP response from a GetOpenapiJsonWithResponse call
+func ParseGetOpenapiJsonResponse(rsp *http.Response) (*GetOpenapiJsonResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetOpenapiJsonResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	}
+
+	return response, nil
+}
+
+// ParseSigningKeyResponse parses an HTTP response from a SigningKeyWithResponse call
+func ParseSigningKeyResponse(rsp *http.Response) (*SigningKeyResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SigningKeyResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetVersionResponse parses an HTTP response from a GetVersionWithResponse call
+func ParseGetVersionResponse(rsp *http.Response) (*GetVersionResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetVersionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Version
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}